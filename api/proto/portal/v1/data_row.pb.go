@@ -0,0 +1,489 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        (unknown)
+// source: portal/v1/data_row.proto
+
+package portalv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type DataRow struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	DatasetId string                 `protobuf:"bytes,2,opt,name=dataset_id,json=datasetId,proto3" json:"dataset_id,omitempty"`
+	RowIndex  int32                  `protobuf:"varint,3,opt,name=row_index,json=rowIndex,proto3" json:"row_index,omitempty"`
+	// Data is the row as a JSON object
+	Data          string                 `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`
+	CreatedBy     string                 `protobuf:"bytes,5,opt,name=created_by,json=createdBy,proto3" json:"created_by,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DataRow) Reset() {
+	*x = DataRow{}
+	mi := &file_portal_v1_data_row_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DataRow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DataRow) ProtoMessage() {}
+
+func (x *DataRow) ProtoReflect() protoreflect.Message {
+	mi := &file_portal_v1_data_row_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DataRow.ProtoReflect.Descriptor instead.
+func (*DataRow) Descriptor() ([]byte, []int) {
+	return file_portal_v1_data_row_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *DataRow) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DataRow) GetDatasetId() string {
+	if x != nil {
+		return x.DatasetId
+	}
+	return ""
+}
+
+func (x *DataRow) GetRowIndex() int32 {
+	if x != nil {
+		return x.RowIndex
+	}
+	return 0
+}
+
+func (x *DataRow) GetData() string {
+	if x != nil {
+		return x.Data
+	}
+	return ""
+}
+
+func (x *DataRow) GetCreatedBy() string {
+	if x != nil {
+		return x.CreatedBy
+	}
+	return ""
+}
+
+func (x *DataRow) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *DataRow) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type ListDataRowsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DatasetId     string                 `protobuf:"bytes,1,opt,name=dataset_id,json=datasetId,proto3" json:"dataset_id,omitempty"`
+	Page          int32                  `protobuf:"varint,2,opt,name=page,proto3" json:"page,omitempty"`
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	Search        string                 `protobuf:"bytes,4,opt,name=search,proto3" json:"search,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDataRowsRequest) Reset() {
+	*x = ListDataRowsRequest{}
+	mi := &file_portal_v1_data_row_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDataRowsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDataRowsRequest) ProtoMessage() {}
+
+func (x *ListDataRowsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_portal_v1_data_row_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDataRowsRequest.ProtoReflect.Descriptor instead.
+func (*ListDataRowsRequest) Descriptor() ([]byte, []int) {
+	return file_portal_v1_data_row_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListDataRowsRequest) GetDatasetId() string {
+	if x != nil {
+		return x.DatasetId
+	}
+	return ""
+}
+
+func (x *ListDataRowsRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListDataRowsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListDataRowsRequest) GetSearch() string {
+	if x != nil {
+		return x.Search
+	}
+	return ""
+}
+
+type ListDataRowsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rows          []*DataRow             `protobuf:"bytes,1,rep,name=rows,proto3" json:"rows,omitempty"`
+	Meta          *PageMeta              `protobuf:"bytes,2,opt,name=meta,proto3" json:"meta,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDataRowsResponse) Reset() {
+	*x = ListDataRowsResponse{}
+	mi := &file_portal_v1_data_row_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDataRowsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDataRowsResponse) ProtoMessage() {}
+
+func (x *ListDataRowsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_portal_v1_data_row_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDataRowsResponse.ProtoReflect.Descriptor instead.
+func (*ListDataRowsResponse) Descriptor() ([]byte, []int) {
+	return file_portal_v1_data_row_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListDataRowsResponse) GetRows() []*DataRow {
+	if x != nil {
+		return x.Rows
+	}
+	return nil
+}
+
+func (x *ListDataRowsResponse) GetMeta() *PageMeta {
+	if x != nil {
+		return x.Meta
+	}
+	return nil
+}
+
+type DataRowInput struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	RowIndex int32                  `protobuf:"varint,1,opt,name=row_index,json=rowIndex,proto3" json:"row_index,omitempty"`
+	// Data is the row as a JSON object
+	Data          string `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DataRowInput) Reset() {
+	*x = DataRowInput{}
+	mi := &file_portal_v1_data_row_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DataRowInput) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DataRowInput) ProtoMessage() {}
+
+func (x *DataRowInput) ProtoReflect() protoreflect.Message {
+	mi := &file_portal_v1_data_row_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DataRowInput.ProtoReflect.Descriptor instead.
+func (*DataRowInput) Descriptor() ([]byte, []int) {
+	return file_portal_v1_data_row_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *DataRowInput) GetRowIndex() int32 {
+	if x != nil {
+		return x.RowIndex
+	}
+	return 0
+}
+
+func (x *DataRowInput) GetData() string {
+	if x != nil {
+		return x.Data
+	}
+	return ""
+}
+
+type BulkCreateDataRowsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DatasetId     string                 `protobuf:"bytes,1,opt,name=dataset_id,json=datasetId,proto3" json:"dataset_id,omitempty"`
+	Rows          []*DataRowInput        `protobuf:"bytes,2,rep,name=rows,proto3" json:"rows,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BulkCreateDataRowsRequest) Reset() {
+	*x = BulkCreateDataRowsRequest{}
+	mi := &file_portal_v1_data_row_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BulkCreateDataRowsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BulkCreateDataRowsRequest) ProtoMessage() {}
+
+func (x *BulkCreateDataRowsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_portal_v1_data_row_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BulkCreateDataRowsRequest.ProtoReflect.Descriptor instead.
+func (*BulkCreateDataRowsRequest) Descriptor() ([]byte, []int) {
+	return file_portal_v1_data_row_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *BulkCreateDataRowsRequest) GetDatasetId() string {
+	if x != nil {
+		return x.DatasetId
+	}
+	return ""
+}
+
+func (x *BulkCreateDataRowsRequest) GetRows() []*DataRowInput {
+	if x != nil {
+		return x.Rows
+	}
+	return nil
+}
+
+type BulkCreateDataRowsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Created       int32                  `protobuf:"varint,1,opt,name=created,proto3" json:"created,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BulkCreateDataRowsResponse) Reset() {
+	*x = BulkCreateDataRowsResponse{}
+	mi := &file_portal_v1_data_row_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BulkCreateDataRowsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BulkCreateDataRowsResponse) ProtoMessage() {}
+
+func (x *BulkCreateDataRowsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_portal_v1_data_row_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BulkCreateDataRowsResponse.ProtoReflect.Descriptor instead.
+func (*BulkCreateDataRowsResponse) Descriptor() ([]byte, []int) {
+	return file_portal_v1_data_row_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *BulkCreateDataRowsResponse) GetCreated() int32 {
+	if x != nil {
+		return x.Created
+	}
+	return 0
+}
+
+var File_portal_v1_data_row_proto protoreflect.FileDescriptor
+
+const file_portal_v1_data_row_proto_rawDesc = "" +
+	"\n" +
+	"\x18portal/v1/data_row.proto\x12\tportal.v1\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x16portal/v1/common.proto\"\xfe\x01\n" +
+	"\aDataRow\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"dataset_id\x18\x02 \x01(\tR\tdatasetId\x12\x1b\n" +
+	"\trow_index\x18\x03 \x01(\x05R\browIndex\x12\x12\n" +
+	"\x04data\x18\x04 \x01(\tR\x04data\x12\x1d\n" +
+	"\n" +
+	"created_by\x18\x05 \x01(\tR\tcreatedBy\x129\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"v\n" +
+	"\x13ListDataRowsRequest\x12\x1d\n" +
+	"\n" +
+	"dataset_id\x18\x01 \x01(\tR\tdatasetId\x12\x12\n" +
+	"\x04page\x18\x02 \x01(\x05R\x04page\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06search\x18\x04 \x01(\tR\x06search\"g\n" +
+	"\x14ListDataRowsResponse\x12&\n" +
+	"\x04rows\x18\x01 \x03(\v2\x12.portal.v1.DataRowR\x04rows\x12'\n" +
+	"\x04meta\x18\x02 \x01(\v2\x13.portal.v1.PageMetaR\x04meta\"?\n" +
+	"\fDataRowInput\x12\x1b\n" +
+	"\trow_index\x18\x01 \x01(\x05R\browIndex\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\tR\x04data\"g\n" +
+	"\x19BulkCreateDataRowsRequest\x12\x1d\n" +
+	"\n" +
+	"dataset_id\x18\x01 \x01(\tR\tdatasetId\x12+\n" +
+	"\x04rows\x18\x02 \x03(\v2\x17.portal.v1.DataRowInputR\x04rows\"6\n" +
+	"\x1aBulkCreateDataRowsResponse\x12\x18\n" +
+	"\acreated\x18\x01 \x01(\x05R\acreated2\xc4\x01\n" +
+	"\x0eDataRowService\x12O\n" +
+	"\fListDataRows\x12\x1e.portal.v1.ListDataRowsRequest\x1a\x1f.portal.v1.ListDataRowsResponse\x12a\n" +
+	"\x12BulkCreateDataRows\x12$.portal.v1.BulkCreateDataRowsRequest\x1a%.portal.v1.BulkCreateDataRowsResponseB2Z0portal-data-backend/api/proto/portal/v1;portalv1b\x06proto3"
+
+var (
+	file_portal_v1_data_row_proto_rawDescOnce sync.Once
+	file_portal_v1_data_row_proto_rawDescData []byte
+)
+
+func file_portal_v1_data_row_proto_rawDescGZIP() []byte {
+	file_portal_v1_data_row_proto_rawDescOnce.Do(func() {
+		file_portal_v1_data_row_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_portal_v1_data_row_proto_rawDesc), len(file_portal_v1_data_row_proto_rawDesc)))
+	})
+	return file_portal_v1_data_row_proto_rawDescData
+}
+
+var file_portal_v1_data_row_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_portal_v1_data_row_proto_goTypes = []any{
+	(*DataRow)(nil),                    // 0: portal.v1.DataRow
+	(*ListDataRowsRequest)(nil),        // 1: portal.v1.ListDataRowsRequest
+	(*ListDataRowsResponse)(nil),       // 2: portal.v1.ListDataRowsResponse
+	(*DataRowInput)(nil),               // 3: portal.v1.DataRowInput
+	(*BulkCreateDataRowsRequest)(nil),  // 4: portal.v1.BulkCreateDataRowsRequest
+	(*BulkCreateDataRowsResponse)(nil), // 5: portal.v1.BulkCreateDataRowsResponse
+	(*timestamppb.Timestamp)(nil),      // 6: google.protobuf.Timestamp
+	(*PageMeta)(nil),                   // 7: portal.v1.PageMeta
+}
+var file_portal_v1_data_row_proto_depIdxs = []int32{
+	6, // 0: portal.v1.DataRow.created_at:type_name -> google.protobuf.Timestamp
+	6, // 1: portal.v1.DataRow.updated_at:type_name -> google.protobuf.Timestamp
+	0, // 2: portal.v1.ListDataRowsResponse.rows:type_name -> portal.v1.DataRow
+	7, // 3: portal.v1.ListDataRowsResponse.meta:type_name -> portal.v1.PageMeta
+	3, // 4: portal.v1.BulkCreateDataRowsRequest.rows:type_name -> portal.v1.DataRowInput
+	1, // 5: portal.v1.DataRowService.ListDataRows:input_type -> portal.v1.ListDataRowsRequest
+	4, // 6: portal.v1.DataRowService.BulkCreateDataRows:input_type -> portal.v1.BulkCreateDataRowsRequest
+	2, // 7: portal.v1.DataRowService.ListDataRows:output_type -> portal.v1.ListDataRowsResponse
+	5, // 8: portal.v1.DataRowService.BulkCreateDataRows:output_type -> portal.v1.BulkCreateDataRowsResponse
+	7, // [7:9] is the sub-list for method output_type
+	5, // [5:7] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_portal_v1_data_row_proto_init() }
+func file_portal_v1_data_row_proto_init() {
+	if File_portal_v1_data_row_proto != nil {
+		return
+	}
+	file_portal_v1_common_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_portal_v1_data_row_proto_rawDesc), len(file_portal_v1_data_row_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_portal_v1_data_row_proto_goTypes,
+		DependencyIndexes: file_portal_v1_data_row_proto_depIdxs,
+		MessageInfos:      file_portal_v1_data_row_proto_msgTypes,
+	}.Build()
+	File_portal_v1_data_row_proto = out.File
+	file_portal_v1_data_row_proto_goTypes = nil
+	file_portal_v1_data_row_proto_depIdxs = nil
+}
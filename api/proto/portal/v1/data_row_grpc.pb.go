@@ -0,0 +1,169 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: portal/v1/data_row.proto
+
+package portalv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	DataRowService_ListDataRows_FullMethodName       = "/portal.v1.DataRowService/ListDataRows"
+	DataRowService_BulkCreateDataRows_FullMethodName = "/portal.v1.DataRowService/BulkCreateDataRows"
+)
+
+// DataRowServiceClient is the client API for DataRowService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// DataRowService reads and appends the rows of tabular datasets
+type DataRowServiceClient interface {
+	// ListDataRows returns a page of the rows of a dataset by row index
+	ListDataRows(ctx context.Context, in *ListDataRowsRequest, opts ...grpc.CallOption) (*ListDataRowsResponse, error)
+	// BulkCreateDataRows appends rows to a dataset on behalf of the user of
+	// the bearer token in the authorization metadata
+	BulkCreateDataRows(ctx context.Context, in *BulkCreateDataRowsRequest, opts ...grpc.CallOption) (*BulkCreateDataRowsResponse, error)
+}
+
+type dataRowServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDataRowServiceClient(cc grpc.ClientConnInterface) DataRowServiceClient {
+	return &dataRowServiceClient{cc}
+}
+
+func (c *dataRowServiceClient) ListDataRows(ctx context.Context, in *ListDataRowsRequest, opts ...grpc.CallOption) (*ListDataRowsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListDataRowsResponse)
+	err := c.cc.Invoke(ctx, DataRowService_ListDataRows_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataRowServiceClient) BulkCreateDataRows(ctx context.Context, in *BulkCreateDataRowsRequest, opts ...grpc.CallOption) (*BulkCreateDataRowsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BulkCreateDataRowsResponse)
+	err := c.cc.Invoke(ctx, DataRowService_BulkCreateDataRows_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DataRowServiceServer is the server API for DataRowService service.
+// All implementations must embed UnimplementedDataRowServiceServer
+// for forward compatibility.
+//
+// DataRowService reads and appends the rows of tabular datasets
+type DataRowServiceServer interface {
+	// ListDataRows returns a page of the rows of a dataset by row index
+	ListDataRows(context.Context, *ListDataRowsRequest) (*ListDataRowsResponse, error)
+	// BulkCreateDataRows appends rows to a dataset on behalf of the user of
+	// the bearer token in the authorization metadata
+	BulkCreateDataRows(context.Context, *BulkCreateDataRowsRequest) (*BulkCreateDataRowsResponse, error)
+	mustEmbedUnimplementedDataRowServiceServer()
+}
+
+// UnimplementedDataRowServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedDataRowServiceServer struct{}
+
+func (UnimplementedDataRowServiceServer) ListDataRows(context.Context, *ListDataRowsRequest) (*ListDataRowsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDataRows not implemented")
+}
+func (UnimplementedDataRowServiceServer) BulkCreateDataRows(context.Context, *BulkCreateDataRowsRequest) (*BulkCreateDataRowsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BulkCreateDataRows not implemented")
+}
+func (UnimplementedDataRowServiceServer) mustEmbedUnimplementedDataRowServiceServer() {}
+func (UnimplementedDataRowServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeDataRowServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DataRowServiceServer will
+// result in compilation errors.
+type UnsafeDataRowServiceServer interface {
+	mustEmbedUnimplementedDataRowServiceServer()
+}
+
+func RegisterDataRowServiceServer(s grpc.ServiceRegistrar, srv DataRowServiceServer) {
+	// If the following call pancis, it indicates UnimplementedDataRowServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&DataRowService_ServiceDesc, srv)
+}
+
+func _DataRowService_ListDataRows_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDataRowsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataRowServiceServer).ListDataRows(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataRowService_ListDataRows_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataRowServiceServer).ListDataRows(ctx, req.(*ListDataRowsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataRowService_BulkCreateDataRows_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BulkCreateDataRowsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataRowServiceServer).BulkCreateDataRows(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataRowService_BulkCreateDataRows_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataRowServiceServer).BulkCreateDataRows(ctx, req.(*BulkCreateDataRowsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// DataRowService_ServiceDesc is the grpc.ServiceDesc for DataRowService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var DataRowService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "portal.v1.DataRowService",
+	HandlerType: (*DataRowServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListDataRows",
+			Handler:    _DataRowService_ListDataRows_Handler,
+		},
+		{
+			MethodName: "BulkCreateDataRows",
+			Handler:    _DataRowService_BulkCreateDataRows_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "portal/v1/data_row.proto",
+}
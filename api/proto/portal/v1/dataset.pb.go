@@ -0,0 +1,768 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        (unknown)
+// source: portal/v1/dataset.proto
+
+package portalv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetDatasetRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Key:
+	//
+	//	*GetDatasetRequest_Id
+	//	*GetDatasetRequest_Slug
+	Key           isGetDatasetRequest_Key `protobuf_oneof:"key"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDatasetRequest) Reset() {
+	*x = GetDatasetRequest{}
+	mi := &file_portal_v1_dataset_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDatasetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDatasetRequest) ProtoMessage() {}
+
+func (x *GetDatasetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_portal_v1_dataset_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDatasetRequest.ProtoReflect.Descriptor instead.
+func (*GetDatasetRequest) Descriptor() ([]byte, []int) {
+	return file_portal_v1_dataset_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GetDatasetRequest) GetKey() isGetDatasetRequest_Key {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+func (x *GetDatasetRequest) GetId() string {
+	if x != nil {
+		if x, ok := x.Key.(*GetDatasetRequest_Id); ok {
+			return x.Id
+		}
+	}
+	return ""
+}
+
+func (x *GetDatasetRequest) GetSlug() string {
+	if x != nil {
+		if x, ok := x.Key.(*GetDatasetRequest_Slug); ok {
+			return x.Slug
+		}
+	}
+	return ""
+}
+
+type isGetDatasetRequest_Key interface {
+	isGetDatasetRequest_Key()
+}
+
+type GetDatasetRequest_Id struct {
+	Id string `protobuf:"bytes,1,opt,name=id,proto3,oneof"`
+}
+
+type GetDatasetRequest_Slug struct {
+	Slug string `protobuf:"bytes,2,opt,name=slug,proto3,oneof"`
+}
+
+func (*GetDatasetRequest_Id) isGetDatasetRequest_Key() {}
+
+func (*GetDatasetRequest_Slug) isGetDatasetRequest_Key() {}
+
+// ListDatasetsRequest filters datasets like GET /datasets. Empty fields do
+// not filter.
+type ListDatasetsRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Page             int32                  `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
+	Limit            int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	OrganizationId   string                 `protobuf:"bytes,3,opt,name=organization_id,json=organizationId,proto3" json:"organization_id,omitempty"`
+	TopicId          string                 `protobuf:"bytes,4,opt,name=topic_id,json=topicId,proto3" json:"topic_id,omitempty"`
+	BusinessFieldId  string                 `protobuf:"bytes,5,opt,name=business_field_id,json=businessFieldId,proto3" json:"business_field_id,omitempty"`
+	TagId            string                 `protobuf:"bytes,6,opt,name=tag_id,json=tagId,proto3" json:"tag_id,omitempty"`
+	Status           string                 `protobuf:"bytes,7,opt,name=status,proto3" json:"status,omitempty"`
+	ValidationStatus string                 `protobuf:"bytes,8,opt,name=validation_status,json=validationStatus,proto3" json:"validation_status,omitempty"`
+	Classification   string                 `protobuf:"bytes,9,opt,name=classification,proto3" json:"classification,omitempty"`
+	Search           string                 `protobuf:"bytes,10,opt,name=search,proto3" json:"search,omitempty"`
+	SortBy           string                 `protobuf:"bytes,11,opt,name=sort_by,json=sortBy,proto3" json:"sort_by,omitempty"`
+	SortOrder        string                 `protobuf:"bytes,12,opt,name=sort_order,json=sortOrder,proto3" json:"sort_order,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ListDatasetsRequest) Reset() {
+	*x = ListDatasetsRequest{}
+	mi := &file_portal_v1_dataset_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDatasetsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDatasetsRequest) ProtoMessage() {}
+
+func (x *ListDatasetsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_portal_v1_dataset_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDatasetsRequest.ProtoReflect.Descriptor instead.
+func (*ListDatasetsRequest) Descriptor() ([]byte, []int) {
+	return file_portal_v1_dataset_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListDatasetsRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListDatasetsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListDatasetsRequest) GetOrganizationId() string {
+	if x != nil {
+		return x.OrganizationId
+	}
+	return ""
+}
+
+func (x *ListDatasetsRequest) GetTopicId() string {
+	if x != nil {
+		return x.TopicId
+	}
+	return ""
+}
+
+func (x *ListDatasetsRequest) GetBusinessFieldId() string {
+	if x != nil {
+		return x.BusinessFieldId
+	}
+	return ""
+}
+
+func (x *ListDatasetsRequest) GetTagId() string {
+	if x != nil {
+		return x.TagId
+	}
+	return ""
+}
+
+func (x *ListDatasetsRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ListDatasetsRequest) GetValidationStatus() string {
+	if x != nil {
+		return x.ValidationStatus
+	}
+	return ""
+}
+
+func (x *ListDatasetsRequest) GetClassification() string {
+	if x != nil {
+		return x.Classification
+	}
+	return ""
+}
+
+func (x *ListDatasetsRequest) GetSearch() string {
+	if x != nil {
+		return x.Search
+	}
+	return ""
+}
+
+func (x *ListDatasetsRequest) GetSortBy() string {
+	if x != nil {
+		return x.SortBy
+	}
+	return ""
+}
+
+func (x *ListDatasetsRequest) GetSortOrder() string {
+	if x != nil {
+		return x.SortOrder
+	}
+	return ""
+}
+
+type ListDatasetsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Datasets      []*Dataset             `protobuf:"bytes,1,rep,name=datasets,proto3" json:"datasets,omitempty"`
+	Meta          *PageMeta              `protobuf:"bytes,2,opt,name=meta,proto3" json:"meta,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDatasetsResponse) Reset() {
+	*x = ListDatasetsResponse{}
+	mi := &file_portal_v1_dataset_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDatasetsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDatasetsResponse) ProtoMessage() {}
+
+func (x *ListDatasetsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_portal_v1_dataset_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDatasetsResponse.ProtoReflect.Descriptor instead.
+func (*ListDatasetsResponse) Descriptor() ([]byte, []int) {
+	return file_portal_v1_dataset_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListDatasetsResponse) GetDatasets() []*Dataset {
+	if x != nil {
+		return x.Datasets
+	}
+	return nil
+}
+
+func (x *ListDatasetsResponse) GetMeta() *PageMeta {
+	if x != nil {
+		return x.Meta
+	}
+	return nil
+}
+
+// Reference is a taxonomy a dataset is classified with: its topic, business
+// field or a tag
+type Reference struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Slug          string                 `protobuf:"bytes,3,opt,name=slug,proto3" json:"slug,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Reference) Reset() {
+	*x = Reference{}
+	mi := &file_portal_v1_dataset_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Reference) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Reference) ProtoMessage() {}
+
+func (x *Reference) ProtoReflect() protoreflect.Message {
+	mi := &file_portal_v1_dataset_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Reference.ProtoReflect.Descriptor instead.
+func (*Reference) Descriptor() ([]byte, []int) {
+	return file_portal_v1_dataset_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Reference) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Reference) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Reference) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+type Unit struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Symbol        string                 `protobuf:"bytes,3,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Unit) Reset() {
+	*x = Unit{}
+	mi := &file_portal_v1_dataset_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Unit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Unit) ProtoMessage() {}
+
+func (x *Unit) ProtoReflect() protoreflect.Message {
+	mi := &file_portal_v1_dataset_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Unit.ProtoReflect.Descriptor instead.
+func (*Unit) Descriptor() ([]byte, []int) {
+	return file_portal_v1_dataset_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Unit) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Unit) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Unit) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+type Dataset struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name             string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Slug             string                 `protobuf:"bytes,3,opt,name=slug,proto3" json:"slug,omitempty"`
+	Description      string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	Period           string                 `protobuf:"bytes,5,opt,name=period,proto3" json:"period,omitempty"`
+	Unit             *Unit                  `protobuf:"bytes,6,opt,name=unit,proto3" json:"unit,omitempty"`
+	BusinessField    *Reference             `protobuf:"bytes,7,opt,name=business_field,json=businessField,proto3" json:"business_field,omitempty"`
+	Topic            *Reference             `protobuf:"bytes,8,opt,name=topic,proto3" json:"topic,omitempty"`
+	OrganizationId   string                 `protobuf:"bytes,9,opt,name=organization_id,json=organizationId,proto3" json:"organization_id,omitempty"`
+	Classification   string                 `protobuf:"bytes,10,opt,name=classification,proto3" json:"classification,omitempty"`
+	Category         string                 `protobuf:"bytes,11,opt,name=category,proto3" json:"category,omitempty"`
+	DataFixed        bool                   `protobuf:"varint,12,opt,name=data_fixed,json=dataFixed,proto3" json:"data_fixed,omitempty"`
+	ValidationStatus string                 `protobuf:"bytes,13,opt,name=validation_status,json=validationStatus,proto3" json:"validation_status,omitempty"`
+	Status           string                 `protobuf:"bytes,14,opt,name=status,proto3" json:"status,omitempty"`
+	IsHighlight      bool                   `protobuf:"varint,15,opt,name=is_highlight,json=isHighlight,proto3" json:"is_highlight,omitempty"`
+	Tags             []*Reference           `protobuf:"bytes,16,rep,name=tags,proto3" json:"tags,omitempty"`
+	// Names and descriptions by language code
+	Names         map[string]string      `protobuf:"bytes,17,rep,name=names,proto3" json:"names,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Descriptions  map[string]string      `protobuf:"bytes,18,rep,name=descriptions,proto3" json:"descriptions,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	CreatedBy     string                 `protobuf:"bytes,19,opt,name=created_by,json=createdBy,proto3" json:"created_by,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,20,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,21,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Dataset) Reset() {
+	*x = Dataset{}
+	mi := &file_portal_v1_dataset_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Dataset) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Dataset) ProtoMessage() {}
+
+func (x *Dataset) ProtoReflect() protoreflect.Message {
+	mi := &file_portal_v1_dataset_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Dataset.ProtoReflect.Descriptor instead.
+func (*Dataset) Descriptor() ([]byte, []int) {
+	return file_portal_v1_dataset_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Dataset) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Dataset) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Dataset) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+func (x *Dataset) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Dataset) GetPeriod() string {
+	if x != nil {
+		return x.Period
+	}
+	return ""
+}
+
+func (x *Dataset) GetUnit() *Unit {
+	if x != nil {
+		return x.Unit
+	}
+	return nil
+}
+
+func (x *Dataset) GetBusinessField() *Reference {
+	if x != nil {
+		return x.BusinessField
+	}
+	return nil
+}
+
+func (x *Dataset) GetTopic() *Reference {
+	if x != nil {
+		return x.Topic
+	}
+	return nil
+}
+
+func (x *Dataset) GetOrganizationId() string {
+	if x != nil {
+		return x.OrganizationId
+	}
+	return ""
+}
+
+func (x *Dataset) GetClassification() string {
+	if x != nil {
+		return x.Classification
+	}
+	return ""
+}
+
+func (x *Dataset) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *Dataset) GetDataFixed() bool {
+	if x != nil {
+		return x.DataFixed
+	}
+	return false
+}
+
+func (x *Dataset) GetValidationStatus() string {
+	if x != nil {
+		return x.ValidationStatus
+	}
+	return ""
+}
+
+func (x *Dataset) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Dataset) GetIsHighlight() bool {
+	if x != nil {
+		return x.IsHighlight
+	}
+	return false
+}
+
+func (x *Dataset) GetTags() []*Reference {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Dataset) GetNames() map[string]string {
+	if x != nil {
+		return x.Names
+	}
+	return nil
+}
+
+func (x *Dataset) GetDescriptions() map[string]string {
+	if x != nil {
+		return x.Descriptions
+	}
+	return nil
+}
+
+func (x *Dataset) GetCreatedBy() string {
+	if x != nil {
+		return x.CreatedBy
+	}
+	return ""
+}
+
+func (x *Dataset) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Dataset) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+var File_portal_v1_dataset_proto protoreflect.FileDescriptor
+
+const file_portal_v1_dataset_proto_rawDesc = "" +
+	"\n" +
+	"\x17portal/v1/dataset.proto\x12\tportal.v1\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x16portal/v1/common.proto\"B\n" +
+	"\x11GetDatasetRequest\x12\x10\n" +
+	"\x02id\x18\x01 \x01(\tH\x00R\x02id\x12\x14\n" +
+	"\x04slug\x18\x02 \x01(\tH\x00R\x04slugB\x05\n" +
+	"\x03key\"\x83\x03\n" +
+	"\x13ListDatasetsRequest\x12\x12\n" +
+	"\x04page\x18\x01 \x01(\x05R\x04page\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12'\n" +
+	"\x0forganization_id\x18\x03 \x01(\tR\x0eorganizationId\x12\x19\n" +
+	"\btopic_id\x18\x04 \x01(\tR\atopicId\x12*\n" +
+	"\x11business_field_id\x18\x05 \x01(\tR\x0fbusinessFieldId\x12\x15\n" +
+	"\x06tag_id\x18\x06 \x01(\tR\x05tagId\x12\x16\n" +
+	"\x06status\x18\a \x01(\tR\x06status\x12+\n" +
+	"\x11validation_status\x18\b \x01(\tR\x10validationStatus\x12&\n" +
+	"\x0eclassification\x18\t \x01(\tR\x0eclassification\x12\x16\n" +
+	"\x06search\x18\n" +
+	" \x01(\tR\x06search\x12\x17\n" +
+	"\asort_by\x18\v \x01(\tR\x06sortBy\x12\x1d\n" +
+	"\n" +
+	"sort_order\x18\f \x01(\tR\tsortOrder\"o\n" +
+	"\x14ListDatasetsResponse\x12.\n" +
+	"\bdatasets\x18\x01 \x03(\v2\x12.portal.v1.DatasetR\bdatasets\x12'\n" +
+	"\x04meta\x18\x02 \x01(\v2\x13.portal.v1.PageMetaR\x04meta\"C\n" +
+	"\tReference\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04slug\x18\x03 \x01(\tR\x04slug\"B\n" +
+	"\x04Unit\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x16\n" +
+	"\x06symbol\x18\x03 \x01(\tR\x06symbol\"\xb6\a\n" +
+	"\aDataset\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04slug\x18\x03 \x01(\tR\x04slug\x12 \n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\x12\x16\n" +
+	"\x06period\x18\x05 \x01(\tR\x06period\x12#\n" +
+	"\x04unit\x18\x06 \x01(\v2\x0f.portal.v1.UnitR\x04unit\x12;\n" +
+	"\x0ebusiness_field\x18\a \x01(\v2\x14.portal.v1.ReferenceR\rbusinessField\x12*\n" +
+	"\x05topic\x18\b \x01(\v2\x14.portal.v1.ReferenceR\x05topic\x12'\n" +
+	"\x0forganization_id\x18\t \x01(\tR\x0eorganizationId\x12&\n" +
+	"\x0eclassification\x18\n" +
+	" \x01(\tR\x0eclassification\x12\x1a\n" +
+	"\bcategory\x18\v \x01(\tR\bcategory\x12\x1d\n" +
+	"\n" +
+	"data_fixed\x18\f \x01(\bR\tdataFixed\x12+\n" +
+	"\x11validation_status\x18\r \x01(\tR\x10validationStatus\x12\x16\n" +
+	"\x06status\x18\x0e \x01(\tR\x06status\x12!\n" +
+	"\fis_highlight\x18\x0f \x01(\bR\visHighlight\x12(\n" +
+	"\x04tags\x18\x10 \x03(\v2\x14.portal.v1.ReferenceR\x04tags\x123\n" +
+	"\x05names\x18\x11 \x03(\v2\x1d.portal.v1.Dataset.NamesEntryR\x05names\x12H\n" +
+	"\fdescriptions\x18\x12 \x03(\v2$.portal.v1.Dataset.DescriptionsEntryR\fdescriptions\x12\x1d\n" +
+	"\n" +
+	"created_by\x18\x13 \x01(\tR\tcreatedBy\x129\n" +
+	"\n" +
+	"created_at\x18\x14 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\x15 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x1a8\n" +
+	"\n" +
+	"NamesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a?\n" +
+	"\x11DescriptionsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x012\xa1\x01\n" +
+	"\x0eDatasetService\x12>\n" +
+	"\n" +
+	"GetDataset\x12\x1c.portal.v1.GetDatasetRequest\x1a\x12.portal.v1.Dataset\x12O\n" +
+	"\fListDatasets\x12\x1e.portal.v1.ListDatasetsRequest\x1a\x1f.portal.v1.ListDatasetsResponseB2Z0portal-data-backend/api/proto/portal/v1;portalv1b\x06proto3"
+
+var (
+	file_portal_v1_dataset_proto_rawDescOnce sync.Once
+	file_portal_v1_dataset_proto_rawDescData []byte
+)
+
+func file_portal_v1_dataset_proto_rawDescGZIP() []byte {
+	file_portal_v1_dataset_proto_rawDescOnce.Do(func() {
+		file_portal_v1_dataset_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_portal_v1_dataset_proto_rawDesc), len(file_portal_v1_dataset_proto_rawDesc)))
+	})
+	return file_portal_v1_dataset_proto_rawDescData
+}
+
+var file_portal_v1_dataset_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_portal_v1_dataset_proto_goTypes = []any{
+	(*GetDatasetRequest)(nil),     // 0: portal.v1.GetDatasetRequest
+	(*ListDatasetsRequest)(nil),   // 1: portal.v1.ListDatasetsRequest
+	(*ListDatasetsResponse)(nil),  // 2: portal.v1.ListDatasetsResponse
+	(*Reference)(nil),             // 3: portal.v1.Reference
+	(*Unit)(nil),                  // 4: portal.v1.Unit
+	(*Dataset)(nil),               // 5: portal.v1.Dataset
+	nil,                           // 6: portal.v1.Dataset.NamesEntry
+	nil,                           // 7: portal.v1.Dataset.DescriptionsEntry
+	(*PageMeta)(nil),              // 8: portal.v1.PageMeta
+	(*timestamppb.Timestamp)(nil), // 9: google.protobuf.Timestamp
+}
+var file_portal_v1_dataset_proto_depIdxs = []int32{
+	5,  // 0: portal.v1.ListDatasetsResponse.datasets:type_name -> portal.v1.Dataset
+	8,  // 1: portal.v1.ListDatasetsResponse.meta:type_name -> portal.v1.PageMeta
+	4,  // 2: portal.v1.Dataset.unit:type_name -> portal.v1.Unit
+	3,  // 3: portal.v1.Dataset.business_field:type_name -> portal.v1.Reference
+	3,  // 4: portal.v1.Dataset.topic:type_name -> portal.v1.Reference
+	3,  // 5: portal.v1.Dataset.tags:type_name -> portal.v1.Reference
+	6,  // 6: portal.v1.Dataset.names:type_name -> portal.v1.Dataset.NamesEntry
+	7,  // 7: portal.v1.Dataset.descriptions:type_name -> portal.v1.Dataset.DescriptionsEntry
+	9,  // 8: portal.v1.Dataset.created_at:type_name -> google.protobuf.Timestamp
+	9,  // 9: portal.v1.Dataset.updated_at:type_name -> google.protobuf.Timestamp
+	0,  // 10: portal.v1.DatasetService.GetDataset:input_type -> portal.v1.GetDatasetRequest
+	1,  // 11: portal.v1.DatasetService.ListDatasets:input_type -> portal.v1.ListDatasetsRequest
+	5,  // 12: portal.v1.DatasetService.GetDataset:output_type -> portal.v1.Dataset
+	2,  // 13: portal.v1.DatasetService.ListDatasets:output_type -> portal.v1.ListDatasetsResponse
+	12, // [12:14] is the sub-list for method output_type
+	10, // [10:12] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
+}
+
+func init() { file_portal_v1_dataset_proto_init() }
+func file_portal_v1_dataset_proto_init() {
+	if File_portal_v1_dataset_proto != nil {
+		return
+	}
+	file_portal_v1_common_proto_init()
+	file_portal_v1_dataset_proto_msgTypes[0].OneofWrappers = []any{
+		(*GetDatasetRequest_Id)(nil),
+		(*GetDatasetRequest_Slug)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_portal_v1_dataset_proto_rawDesc), len(file_portal_v1_dataset_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_portal_v1_dataset_proto_goTypes,
+		DependencyIndexes: file_portal_v1_dataset_proto_depIdxs,
+		MessageInfos:      file_portal_v1_dataset_proto_msgTypes,
+	}.Build()
+	File_portal_v1_dataset_proto = out.File
+	file_portal_v1_dataset_proto_goTypes = nil
+	file_portal_v1_dataset_proto_depIdxs = nil
+}
@@ -0,0 +1,6 @@
+// Package portalv1 holds the protobuf messages and gRPC services internal
+// consumers call the API through. The Go code is generated from the .proto
+// files next to it with protoc, protoc-gen-go and protoc-gen-go-grpc.
+package portalv1
+
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative portal/v1/common.proto portal/v1/dataset.proto portal/v1/data_row.proto portal/v1/auth.proto
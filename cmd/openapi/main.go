@@ -0,0 +1,31 @@
+// Command openapi writes the OpenAPI specification of the API as JSON
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"portal-data-backend/internal/apidoc"
+)
+
+func main() {
+	output := flag.String("o", "", "file to write the specification to, standard output when empty")
+	version := flag.String("version", "1.0.0", "version of the API in the specification")
+	flag.Parse()
+
+	body, err := json.MarshalIndent(apidoc.Spec(*version), "", "  ")
+	if err != nil {
+		log.Fatalf("Failed to encode API specification: %v", err)
+	}
+	body = append(body, '\n')
+
+	if *output == "" {
+		os.Stdout.Write(body)
+		return
+	}
+	if err := os.WriteFile(*output, body, 0o644); err != nil {
+		log.Fatalf("Failed to write API specification: %v", err)
+	}
+}
@@ -0,0 +1,200 @@
+// Command portalctl runs operator tasks against the database of the portal,
+// like creating the first admin or purging soft deleted records. It builds
+// the modules like the server does, so tasks go through their usecases.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/infrastructure/db"
+	"portal-data-backend/infrastructure/logger"
+	"portal-data-backend/internal/app"
+	"portal-data-backend/internal/bootstrap"
+	"portal-data-backend/internal/modules"
+)
+
+// ctl holds what commands run against
+type ctl struct {
+	env      *bootstrap.Env
+	registry *app.Registry
+}
+
+func main() {
+	opts, args, err := config.ParseCommandLine("portalctl", os.Args[1:])
+	if err != nil {
+		os.Exit(2)
+	}
+
+	c := &ctl{registry: app.NewRegistry(modules.All()...)}
+	if len(args) == 0 || args[0] == "help" {
+		if len(args) > 1 {
+			if command, ok := c.command(args[1]); ok {
+				fmt.Fprintf(os.Stdout, "Usage: portalctl %s\n\n%s\n", command.Usage, command.Summary)
+				return
+			}
+		}
+		c.usage(os.Stdout)
+		return
+	}
+	command, ok := c.command(args[0])
+	if !ok {
+		fmt.Fprintf(os.Stderr, "portalctl: unknown command %q\n\n", args[0])
+		c.usage(os.Stderr)
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Migrations may run before the schema the modules need exists
+	err = c.connect(opts, command.Name != "migrate")
+	if c.env != nil {
+		defer c.env.Close()
+	}
+	if err == nil {
+		err = command.Run(logger.WithContext(ctx, c.env.Logger), os.Stdin, os.Stdout, args[1:])
+	}
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		fmt.Fprintf(os.Stderr, "portalctl %s: %v\n", command.Name, err)
+		os.Exit(1)
+	}
+}
+
+// connect loads the configuration and connects to the database, building
+// the modules on it when withModules is set
+func (c *ctl) connect(opts config.Options, withModules bool) error {
+	env, err := bootstrap.Open(opts)
+	if err != nil {
+		return err
+	}
+	c.env = env
+	if !withModules {
+		return nil
+	}
+	return env.Register(c.registry)
+}
+
+// commands returns the commands of portalctl itself followed by those of
+// the modules
+func (c *ctl) commands() []app.Command {
+	builtin := []app.Command{
+		{
+			Name:    "migrate",
+			Usage:   "migrate [-dir DIR] up | down [N] | version",
+			Summary: "Apply or revert database migrations",
+			Run:     c.migrate,
+		},
+		{
+			Name:    "purge",
+			Usage:   "purge [-older-than DURATION]",
+			Summary: "Delete records in the trash longer than the retention period for good",
+			Run:     c.purge,
+		},
+	}
+	return append(builtin, c.registry.Commands()...)
+}
+
+func (c *ctl) command(name string) (app.Command, bool) {
+	for _, command := range c.commands() {
+		if command.Name == name {
+			return command, true
+		}
+	}
+	return app.Command{}, false
+}
+
+func (c *ctl) usage(out io.Writer) {
+	fmt.Fprintln(out, "Usage: portalctl [-config FILE] [-set KEY=VALUE]... COMMAND [ARGS]")
+	fmt.Fprintln(out, "\nCommands:")
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	for _, command := range c.commands() {
+		fmt.Fprintf(w, "  %s\t%s\n", command.Name, command.Summary)
+	}
+	w.Flush()
+	fmt.Fprintln(out, "\nRun portalctl help COMMAND for the arguments of a command.")
+}
+
+func (c *ctl) migrate(ctx context.Context, in io.Reader, out io.Writer, args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	flags.SetOutput(out)
+	dir := flags.String("dir", "migrations", "directory of the migrations")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	migrator, err := db.NewMigrator(c.env.DB, os.DirFS(*dir))
+	if err != nil {
+		return err
+	}
+
+	switch flags.Arg(0) {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, migration := range applied {
+			fmt.Fprintf(out, "Applied %d_%s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Fprintln(out, "No migrations to apply")
+		}
+		return err
+	case "down":
+		steps := 1
+		if flags.NArg() > 1 {
+			if steps, err = strconv.Atoi(flags.Arg(1)); err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of migrations %q", flags.Arg(1))
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		for _, migration := range reverted {
+			fmt.Fprintf(out, "Reverted %d_%s\n", migration.Version, migration.Name)
+		}
+		return err
+	case "version":
+		version, dirty, err := migrator.Version(ctx)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			fmt.Fprintf(out, "%d (dirty)\n", version)
+		} else {
+			fmt.Fprintln(out, version)
+		}
+		return nil
+	}
+	return errors.New("expected up, down or version")
+}
+
+func (c *ctl) purge(ctx context.Context, in io.Reader, out io.Writer, args []string) error {
+	flags := flag.NewFlagSet("purge", flag.ContinueOnError)
+	flags.SetOutput(out)
+	olderThan := flags.Duration("older-than", c.env.Config.Trash.Retention, "delete records soft deleted longer ago than this, TRASH_RETENTION by default")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	purged, err := c.registry.Purge(ctx, time.Now().Add(-*olderThan))
+	names := make([]string, 0, len(purged))
+	for name := range purged {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(out, "%s: purged %d records\n", name, purged[name])
+	}
+	return err
+}
@@ -0,0 +1,115 @@
+// Command seed provisions a database with the records a new environment or
+// an integration test starts from: an organization with its admin account
+// and the taxonomies, and optionally demo datasets with their rows and
+// visualizations. It can run again on a seeded database; existing records
+// are kept.
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	mathrand "math/rand"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/infrastructure/logger"
+	"portal-data-backend/internal/app"
+	"portal-data-backend/internal/bootstrap"
+	"portal-data-backend/internal/modules"
+	"portal-data-backend/internal/seed"
+)
+
+func main() {
+	flags := flag.NewFlagSet("seed", flag.ExitOnError)
+	configOptions := config.BindFlags(flags)
+	opts := seed.Options{}
+	flags.StringVar(&opts.OrganizationCode, "org-code", "PORTAL", "code of the organization of the admin")
+	flags.StringVar(&opts.OrganizationName, "org-name", "Portal Data", "name of the organization of the admin")
+	flags.StringVar(&opts.AdminRole, "admin-role", "", "role ID of the admin (default the first of AUDIT_ADMIN_ROLES)")
+	flags.StringVar(&opts.AdminName, "admin-name", "Administrator", "full name of the admin")
+	flags.StringVar(&opts.AdminUsername, "admin-username", "admin", "username of the admin")
+	flags.StringVar(&opts.AdminEmail, "admin-email", "admin@example.com", "email of the admin")
+	flags.BoolVar(&opts.Demo, "demo", false, "seed demo datasets with their rows and visualizations")
+	flags.IntVar(&opts.DemoDatasets, "datasets", 0, "how many demo datasets to seed (default one per template)")
+	deterministic := flags.Bool("deterministic", false, "generate the same demo data on every run, from -seed")
+	randSeed := flags.Int64("seed", 1, "seed of the demo data generator with -deterministic")
+	flags.Parse(os.Args[1:])
+
+	env, err := bootstrap.Open(*configOptions)
+	if err != nil {
+		log.Fatalf("Failed to start: %v", err)
+	}
+	defer env.Close()
+
+	if opts.Demo && env.Config.App.Environment == "production" {
+		env.Logger.Fatal("Refusing to seed demo data in production")
+	}
+	if opts.AdminRole == "" {
+		opts.AdminRole = env.Config.Audit.AdminRoles[0]
+	}
+	// The password of a new admin comes from the environment, so it stays
+	// out of the shell history, or is generated and printed once
+	opts.AdminPassword = os.Getenv("SEED_ADMIN_PASSWORD")
+	generated := opts.AdminPassword == ""
+	if generated {
+		if opts.AdminPassword, err = randomPassword(); err != nil {
+			env.Logger.Fatal("Failed to generate admin password: %v", err)
+		}
+	} else if len(opts.AdminPassword) < 8 {
+		env.Logger.Fatal("SEED_ADMIN_PASSWORD must be at least 8 characters")
+	}
+	source := time.Now().UnixNano()
+	if *deterministic {
+		source = *randSeed
+	}
+	opts.Rand = mathrand.New(mathrand.NewSource(source))
+
+	registry := app.NewRegistry(modules.All()...)
+	if err := env.Register(registry); err != nil {
+		env.Logger.Fatal("%v", err)
+	}
+	seeder, err := seed.New(env.Deps.Services)
+	if err != nil {
+		env.Logger.Fatal("%v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	report, err := seeder.Run(logger.WithContext(ctx, env.Logger), opts)
+	if err != nil {
+		env.Logger.Fatal("Failed to seed: %v", err)
+	}
+
+	fmt.Printf("Organization %s: %s\n", opts.OrganizationCode, report.OrganizationID)
+	switch {
+	case !report.AdminCreated:
+		fmt.Printf("Admin %s exists: %s\n", opts.AdminEmail, report.AdminID)
+	case generated:
+		fmt.Printf("Admin %s created: %s, with password %s\n", opts.AdminEmail, report.AdminID, opts.AdminPassword)
+	default:
+		fmt.Printf("Admin %s created: %s\n", opts.AdminEmail, report.AdminID)
+	}
+	for _, taxonomy := range report.Taxonomies {
+		fmt.Printf("%s: %d created, %d updated\n", taxonomy.Name, taxonomy.Created, taxonomy.Updated)
+	}
+	if opts.Demo {
+		fmt.Printf("Demo: %d datasets, %d rows, %d visualizations (seed %d)\n", report.Datasets, report.Rows, report.Visualizations, source)
+	}
+}
+
+// randomPassword generates a password of 128 random bits
+func randomPassword() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.New("no randomness available")
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -4,273 +4,152 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"portal-data-backend/infrastructure/audit"
+	"portal-data-backend/infrastructure/cache"
 	"portal-data-backend/infrastructure/config"
 	"portal-data-backend/infrastructure/db"
+	"portal-data-backend/infrastructure/deprecation"
+	"portal-data-backend/infrastructure/events"
+	"portal-data-backend/infrastructure/grpcserver"
+	"portal-data-backend/infrastructure/health"
+	"portal-data-backend/infrastructure/http/httputil"
 	"portal-data-backend/infrastructure/http/middleware"
+	"portal-data-backend/infrastructure/http/openapi"
 	"portal-data-backend/infrastructure/http/response"
 	"portal-data-backend/infrastructure/logger"
 	"portal-data-backend/infrastructure/security"
-	"portal-data-backend/infrastructure/storage"
+	"portal-data-backend/infrastructure/tenant"
 
-	// Auth module
-	authDelivery "portal-data-backend/internal/auth/delivery/http"
-	authRepo "portal-data-backend/internal/auth/repository"
+	// Modules
+	"portal-data-backend/internal/app"
 	authUsecase "portal-data-backend/internal/auth/usecase"
-
-	// User module
-	userDelivery "portal-data-backend/internal/user/delivery/http"
-	userRepo "portal-data-backend/internal/user/repository"
-	userUsecase "portal-data-backend/internal/user/usecase"
-
-	// Organization module
-	orgDelivery "portal-data-backend/internal/organization/delivery/http"
-	orgRepo "portal-data-backend/internal/organization/repository"
-	orgUsecase "portal-data-backend/internal/organization/usecase"
-
-	// Dataset module
-	datasetDelivery "portal-data-backend/internal/dataset/delivery/http"
-	datasetRepo "portal-data-backend/internal/dataset/repository"
-	datasetUsecase "portal-data-backend/internal/dataset/usecase"
-
-	// Tag module
-	tagDelivery "portal-data-backend/internal/tag/delivery/http"
-	tagRepo "portal-data-backend/internal/tag/repository"
-	tagUsecase "portal-data-backend/internal/tag/usecase"
-
-	// BusinessField module
-	bfDelivery "portal-data-backend/internal/business_field/delivery/http"
-	bfRepo "portal-data-backend/internal/business_field/repository"
-	bfUsecase "portal-data-backend/internal/business_field/usecase"
-
-	// Topic module
-	topicDelivery "portal-data-backend/internal/topic/delivery/http"
-	topicRepo "portal-data-backend/internal/topic/repository"
-	topicUsecase "portal-data-backend/internal/topic/usecase"
-
-	// Unit module
-	unitDelivery "portal-data-backend/internal/unit/delivery/http"
-	unitRepo "portal-data-backend/internal/unit/repository"
-	unitUsecase "portal-data-backend/internal/unit/usecase"
-
-	// Feedback module
-	fbDelivery "portal-data-backend/internal/feedback/delivery/http"
-	fbRepo "portal-data-backend/internal/feedback/repository"
-	fbUsecase "portal-data-backend/internal/feedback/usecase"
-
-	// File module
-	fileDelivery "portal-data-backend/internal/file/delivery/http"
-	fileRepo "portal-data-backend/internal/file/repository"
-	fileUsecase "portal-data-backend/internal/file/usecase"
-
-	// Analytics module
-	analyticsDelivery "portal-data-backend/internal/analytics/delivery/http"
-	analyticsRepo "portal-data-backend/internal/analytics/repository"
-	analyticsUsecase "portal-data-backend/internal/analytics/usecase"
-
-	// Visualization module
-	vizDelivery "portal-data-backend/internal/visualization/delivery/http"
-	vizRepo "portal-data-backend/internal/visualization/repository"
-	vizUsecase "portal-data-backend/internal/visualization/usecase"
-
-	// Publication module
-	pubDelivery "portal-data-backend/internal/publication/delivery/http"
-	pubRepo "portal-data-backend/internal/publication/repository"
-	pubUsecase "portal-data-backend/internal/publication/usecase"
-
-	// Settings module
-	settingsDelivery "portal-data-backend/internal/settings/delivery/http"
-	settingsRepo "portal-data-backend/internal/settings/repository"
+	developerUsecase "portal-data-backend/internal/developer/usecase"
+	"portal-data-backend/internal/modules"
 	settingsUsecase "portal-data-backend/internal/settings/usecase"
 
-	// Notification module
-	notifDelivery "portal-data-backend/internal/notification/delivery/http"
-	notifRepo "portal-data-backend/internal/notification/repository"
-	notifUsecase "portal-data-backend/internal/notification/usecase"
-
-	// DataRow module
-	dataRowDelivery "portal-data-backend/internal/data_row/delivery/http"
-	dataRowRepo "portal-data-backend/internal/data_row/repository"
-	dataRowUsecase "portal-data-backend/internal/data_row/usecase"
-
-	// Desk module
-	deskDelivery "portal-data-backend/internal/desk/delivery/http"
-	deskRepo "portal-data-backend/internal/desk/repository"
-	deskUsecase "portal-data-backend/internal/desk/usecase"
-
-	// Integration module
-	integrationDelivery "portal-data-backend/internal/integration/delivery/http"
-	integrationRepo "portal-data-backend/internal/integration/repository"
-	integrationUsecase "portal-data-backend/internal/integration/usecase"
+	// API documentation
+	"portal-data-backend/internal/apidoc"
 
 	"github.com/go-chi/chi/v5"
 	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+	"google.golang.org/grpc"
+	grpcHealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 func main() {
-	// Load configuration
-	cfg, err := config.Load()
+	// Load configuration from the .env file, the config file, the
+	// environment and the command line
+	configOptions, err := config.ParseFlags(os.Args[0], os.Args[1:])
+	if err != nil {
+		log.Fatalf("Invalid arguments: %v", err)
+	}
+	cfg, err := config.LoadWith(configOptions)
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
+	reloader := config.NewReloader(cfg, configOptions)
 
-	// Initialize logger
-	logger := logger.New(cfg.App.Debug, cfg.App.Environment)
+	// Initialize logger; its level follows configuration reloads
+	appLogger, err := logger.New(cfg.App.LogLevel, cfg.App.Environment)
+	if err != nil {
+		log.Fatalf("Failed to initialize logger: %v", err)
+	}
+	logger.SetDefault(appLogger)
+	reloader.Subscribe(func(cfg *config.Config) {
+		if err := appLogger.SetLevel(cfg.App.LogLevel); err != nil {
+			appLogger.Error("Failed to change log level: %v", err)
+		}
+	})
 
-	logger.Info("Starting %s v%s", cfg.App.Name, cfg.App.Version)
-	logger.Info("Environment: %s", cfg.App.Environment)
+	appLogger.Info("Starting %s v%s", cfg.App.Name, cfg.App.Version)
+	appLogger.Info("Environment: %s", cfg.App.Environment)
 
 	// Initialize database
 	postgres, err := db.NewPostgres(&cfg.Database)
 	if err != nil {
-		logger.Fatal("Failed to connect to database: %v", err)
+		appLogger.Fatal("Failed to connect to database: %v", err)
 	}
 	defer postgres.Close()
 
-	logger.Info("Database connected successfully")
+	appLogger.Info("Database connected successfully")
+
+	// Route public reads to the read replicas, skipping unhealthy ones
+	dbRouter := db.NewRouter(postgres.DB, postgres.Replicas...)
+	if len(postgres.Replicas) > 0 {
+		dbRouter.Check(logger.WithContext(context.Background(), appLogger))
+		appLogger.Info("Reading from %d database replicas", len(postgres.Replicas))
+	}
 
 	// Initialize infrastructure components
 	jwtManager := security.NewJWTManager(&cfg.JWT)
-	passwordHasher := security.NewPasswordHandler()
-
-	// Initialize Auth module
-	userRepository := authRepo.NewUserPostgresRepository(postgres.DB)
-	tokenRepository := authRepo.NewTokenPostgresRepository(postgres.DB)
-
-	authUsecaseInstance := authUsecase.NewAuthUsecase(
-		userRepository,
-		tokenRepository,
-		jwtManager,
-		passwordHasher,
-	)
+	httputil.MaxBodyBytes = cfg.Server.MaxBodyBytes
 
-	authHandler := authDelivery.NewHandler(authUsecaseInstance)
-
-	// Initialize User module
-	userRepositoryInstance := userRepo.NewUserPostgresRepository(postgres.DB)
-	userUsecaseInstance := userUsecase.NewUserUsecase(userRepositoryInstance)
-	userHandler := userDelivery.NewHandler(userUsecaseInstance)
-
-	// Initialize Organization module
-	orgRepository := orgRepo.NewOrgPostgresRepository(postgres.DB)
-	orgUsecaseInstance := orgUsecase.NewOrgUsecase(orgRepository)
-	orgHandler := orgDelivery.NewHandler(orgUsecaseInstance)
-
-	// Initialize Dataset module
-	datasetRepository := datasetRepo.NewDatasetPostgresRepository(postgres.DB)
-	datasetUsecaseInstance := datasetUsecase.NewDatasetUsecase(datasetRepository)
-	datasetHandler := datasetDelivery.NewHandler(datasetUsecaseInstance)
-
-	// Initialize Tag module
-	tagRepository := tagRepo.NewTagPostgresRepository(postgres.DB)
-	tagUsecaseInstance := tagUsecase.NewTagUsecase(tagRepository)
-	tagHandler := tagDelivery.NewHandler(tagUsecaseInstance)
-
-	// Initialize BusinessField module
-	bfRepository := bfRepo.NewBusinessFieldPostgresRepository(postgres.DB)
-	bfUsecaseInstance := bfUsecase.NewBusinessFieldUsecase(bfRepository)
-	bfHandler := bfDelivery.NewHandler(bfUsecaseInstance)
-
-	// Initialize Topic module
-	topicRepository := topicRepo.NewTopicPostgresRepository(postgres.DB)
-	topicUsecaseInstance := topicUsecase.NewTopicUsecase(topicRepository)
-	topicHandler := topicDelivery.NewHandler(topicUsecaseInstance)
-
-	// Initialize Unit module
-	unitRepository := unitRepo.NewUnitPostgresRepository(postgres.DB)
-	unitUsecaseInstance := unitUsecase.NewUnitUsecase(unitRepository)
-	unitHandler := unitDelivery.NewHandler(unitUsecaseInstance)
-
-	// Initialize Feedback module
-	fbRepository := fbRepo.NewFeedbackPostgresRepository(postgres.DB)
-	fbUsecaseInstance := fbUsecase.NewFeedbackUsecase(fbRepository)
-	fbHandler := fbDelivery.NewHandler(fbUsecaseInstance)
-
-	// Initialize File module with MinIO storage
-	minioStorage, err := storage.NewMinIOStorage(
-		cfg.MinIO.Endpoint,
-		cfg.MinIO.AccessKey,
-		cfg.MinIO.SecretKey,
-		cfg.MinIO.Bucket,
-		cfg.MinIO.UseSSL,
-	)
+	// Initialize the optional message broker sink; it receives the events of
+	// every module, next to the modules subscribing to them
+	eventSink, err := events.NewSink(&cfg.Events)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize event sink: %v", err)
+	}
+	if eventSink != nil {
+		defer eventSink.Close()
+		appLogger.Info("Publishing events to %s", cfg.Events.Driver)
+	}
+	eventBus := &events.Bus{}
+	eventBus.Subscribe(eventSink)
+	// Modules record their events in the outbox; the relay hands them to
+	// the bus once their transaction commits
+	outbox := events.NewOutbox(postgres.DB)
+	relay := events.NewRelay(outbox, eventBus, cfg.Events)
+
+	// Initialize the cache; modules cache hot reads in namespaces of it
+	cacheBackend, err := cache.New(&cfg.Cache, &cfg.Redis)
 	if err != nil {
-		logger.Fatal("Failed to connect to MinIO: %v", err)
-	}
-	logger.Info("MinIO connected successfully")
-
-	fileRepository := fileRepo.NewFilePostgresRepository(postgres.DB)
-	fileUsecaseInstance := fileUsecase.NewFileUsecase(fileRepository, minioStorage, "files")
-	fileHandler := fileDelivery.NewHandler(fileUsecaseInstance)
-
-	// Initialize Analytics module
-	analyticsRepository := analyticsRepo.NewAnalyticsPostgresRepository(postgres.DB)
-	analyticsUsecaseInstance := analyticsUsecase.NewAnalyticsUsecase(analyticsRepository)
-	analyticsHandler := analyticsDelivery.NewHandler(analyticsUsecaseInstance)
-
-	// Initialize Visualization module
-	vizRepository := vizRepo.NewVisualizationPostgresRepository(postgres.DB)
-	vizUsecaseInstance := vizUsecase.NewVisualizationUsecase(vizRepository)
-	vizHandler := vizDelivery.NewHandler(vizUsecaseInstance)
-
-	// Initialize Publication module
-	pubRepository := pubRepo.NewPublicationPostgresRepository(postgres.DB)
-	pubUsecaseInstance := pubUsecase.NewPublicationUsecase(pubRepository)
-	pubHandler := pubDelivery.NewHandler(pubUsecaseInstance)
-
-	// Initialize Settings module
-	settingsRepository := settingsRepo.NewSettingsPostgresRepository(postgres.DB)
-	settingsUsecaseInstance := settingsUsecase.NewSettingsUsecase(settingsRepository)
-	settingsHandler := settingsDelivery.NewHandler(settingsUsecaseInstance)
-
-	// Initialize Notification module
-	notifRepository := notifRepo.NewNotificationPostgresRepository(postgres.DB)
-	notifUsecaseInstance := notifUsecase.NewNotificationUsecase(notifRepository)
-	notifHandler := notifDelivery.NewHandler(notifUsecaseInstance)
-
-	// Initialize DataRow module
-	dataRowRepository := dataRowRepo.NewDataRowPostgresRepository(postgres.DB)
-	dataRowUsecaseInstance := dataRowUsecase.NewDataRowUsecase(dataRowRepository)
-	dataRowHandler := dataRowDelivery.NewHandler(dataRowUsecaseInstance)
-
-	// Initialize Desk module
-	deskRepository := deskRepo.NewDeskPostgresRepository(postgres.DB)
-	deskUsecaseInstance := deskUsecase.NewDeskUsecase(deskRepository)
-	deskHandler := deskDelivery.NewHandler(deskUsecaseInstance)
-
-	// Initialize Integration module
-	integrationRepository := integrationRepo.NewIntegrationPostgresRepository(postgres.DB)
-	integrationUsecaseInstance := integrationUsecase.NewIntegrationUsecase(integrationRepository)
-	integrationHandler := integrationDelivery.NewHandler(integrationUsecaseInstance)
+		appLogger.Fatal("Failed to initialize cache: %v", err)
+	}
+	if cacheBackend != nil {
+		appLogger.Info("Caching in %s", cfg.Cache.Driver)
+	}
+	cacheStore := cache.NewStore(cacheBackend, cfg.Cache.KeyPrefix)
+
+	// Dependencies probed by the readiness check; modules add their own.
+	// Replicas are left out as reads fall back to the primary.
+	healthChecks := &health.Registry{}
+	healthChecks.Register("postgres", postgres.DB.PingContext)
+	if cacheBackend != nil {
+		healthChecks.Register("cache", cacheBackend.Ping)
+	}
+
+	// Initialize modules
+	registry := app.NewRegistry(modules.All()...)
+	deps := &app.Deps{
+		Config:   cfg,
+		Logger:   appLogger,
+		DB:       postgres.DB,
+		DBRouter: dbRouter,
+		JWT:      jwtManager,
+		Tx:       db.NewTxManager(postgres.DB),
+		Events:   eventBus,
+		Outbox:   outbox,
+		Cache:    cacheStore,
+		Health:   healthChecks,
+		Reloader: reloader,
+
+		Deprecations: &deprecation.Registry{},
+	}
+	if err := registry.Register(deps); err != nil {
+		appLogger.Fatal("Failed to initialize modules: %v", err)
+	}
 
 	// Setup HTTP router
-	router := setupRouter(
-		cfg,
-		authHandler,
-		userHandler,
-		orgHandler,
-		datasetHandler,
-		tagHandler,
-		bfHandler,
-		topicHandler,
-		unitHandler,
-		fbHandler,
-		fileHandler,
-		analyticsHandler,
-		vizHandler,
-		pubHandler,
-		settingsHandler,
-		notifHandler,
-		dataRowHandler,
-		deskHandler,
-		integrationHandler,
-		jwtManager,
-	)
+	router := setupRouter(cfg, registry, jwtManager, sessionCheck(deps.Services.Accounts), deps.Services.Audit, deps.Services.Tenants, maintenanceStatus(deps.Services.Settings), apiKeys(deps.Services.Developers), middleware.Permissions(deps.Services.Roles.Permissions, cfg.Audit.AdminRoles...), middleware.Deprecations(deps.Deprecations, jwtManager, deps.Services.Deprecations.Record), cacheStore, dbRouter, healthChecks, appLogger)
 
 	// Setup HTTP server
 	server := &http.Server{
@@ -281,236 +160,329 @@ func main() {
 		IdleTimeout:  cfg.Server.IdleTimeout,
 	}
 
+	// Start background workers
+	workerCtx, stopWorkers := context.WithCancel(logger.WithContext(context.Background(), appLogger))
+	defer stopWorkers()
+
+	registry.Run(workerCtx)
+	go registry.RunPurge(workerCtx, cfg.Trash.PurgeInterval, cfg.Trash.Retention)
+	jobsDone := make(chan struct{})
+	go func() {
+		registry.RunJobs(workerCtx)
+		close(jobsDone)
+	}()
+	go dbRouter.Run(workerCtx, cfg.Database.ReplicaCheckInterval)
+	go relay.Run(workerCtx)
+	go reloader.Run(workerCtx)
+	go config.RenewSecrets(workerCtx)
+
 	// Start server in goroutine
 	go func() {
-		logger.Info("Server listening on port %d", cfg.Server.Port)
+		appLogger.Info("Server listening on port %d", cfg.Server.Port)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Fatal("Server failed to start: %v", err)
+			appLogger.Fatal("Server failed to start: %v", err)
 		}
 	}()
 
+	// Start the gRPC server for internal services
+	var grpcServer *grpc.Server
+	if cfg.GRPC.Enabled {
+		grpcServer, err = setupGRPC(cfg, registry, jwtManager, sessionCheck(deps.Services.Accounts), deps.Services.Tenants, appLogger)
+		if err != nil {
+			appLogger.Fatal("Failed to initialize gRPC server: %v", err)
+		}
+		listener, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPC.Port))
+		if err != nil {
+			appLogger.Fatal("Failed to listen for gRPC: %v", err)
+		}
+		go func() {
+			appLogger.Info("gRPC server listening on port %d", cfg.GRPC.Port)
+			if err := grpcServer.Serve(listener); err != nil {
+				appLogger.Fatal("gRPC server failed: %v", err)
+			}
+		}()
+	}
+
 	// Graceful shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	logger.Info("Shutting down server...")
+	appLogger.Info("Shutting down server...")
+	stopWorkers()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
 	if err := server.Shutdown(ctx); err != nil {
-		logger.Error("Server forced to shutdown: %v", err)
+		appLogger.Error("Server forced to shutdown: %v", err)
+	}
+	if grpcServer != nil {
+		grpcServer.GracefulStop()
+	}
+	// Maintenance jobs running are let finish
+	select {
+	case <-jobsDone:
+	case <-ctx.Done():
+		appLogger.Error("Maintenance jobs did not finish before shutdown")
 	}
 
-	logger.Info("Server exited successfully")
+	appLogger.Info("Server exited successfully")
 }
 
-// setupRouter configures and returns the HTTP router
-func setupRouter(
-	cfg *config.Config,
-	authHandler *authDelivery.Handler,
-	userHandler *userDelivery.Handler,
-	orgHandler *orgDelivery.Handler,
-	datasetHandler *datasetDelivery.Handler,
-	tagHandler *tagDelivery.Handler,
-	bfHandler *bfDelivery.Handler,
-	topicHandler *topicDelivery.Handler,
-	unitHandler *unitDelivery.Handler,
-	fbHandler *fbDelivery.Handler,
-	fileHandler *fileDelivery.Handler,
-	analyticsHandler *analyticsDelivery.Handler,
-	vizHandler *vizDelivery.Handler,
-	pubHandler *pubDelivery.Handler,
-	settingsHandler *settingsDelivery.Handler,
-	notifHandler *notifDelivery.Handler,
-	dataRowHandler *dataRowDelivery.Handler,
-	deskHandler *deskDelivery.Handler,
-	integrationHandler *integrationDelivery.Handler,
-	jwtManager *security.JWTManager,
-) *chi.Mux {
-	r := chi.NewRouter()
+// setupGRPC configures the gRPC server with the services of the modules
+func setupGRPC(cfg *config.Config, registry *app.Registry, jwtManager *security.JWTManager, sessions func(ctx context.Context, token string) error, tenants *tenant.Resolver, appLogger *logger.Logger) (*grpc.Server, error) {
+	var interceptors []grpc.UnaryServerInterceptor
+	if cfg.Tenant.Enabled {
+		interceptors = append(interceptors, grpcserver.Tenant(tenants, cfg.Tenant.Header, cfg.Tenant.Default))
+	}
+	interceptors = append(interceptors, grpcserver.ClientInfo, grpcserver.Authenticate(jwtManager, sessions))
 
-	// Middleware
-	r.Use(chiMiddleware.RequestID)
-	r.Use(chiMiddleware.RealIP)
-	r.Use(chiMiddleware.Recoverer)
-	r.Use(chiMiddleware.Timeout(60 * time.Second))
-	r.Use(middleware.Logger(cfg.App.Debug))
-	r.Use(middleware.CORS())
-	r.Use(middleware.ContentType)
+	server, err := grpcserver.New(&cfg.GRPC, appLogger, interceptors...)
+	if err != nil {
+		return nil, err
+	}
+	registry.RegisterGRPC(server)
+	healthpb.RegisterHealthServer(server, grpcHealth.NewServer())
+	return server, nil
+}
 
-	// Health check
-	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
-		response.OK(w, response.CodeSuccess, "Service is healthy", map[string]string{
-			"status":  "ok",
-			"version": cfg.App.Version,
-		})
-	})
+// corsPolicy returns the CORS policy of the API, letting browsers send the
+// tenant header when the deployment hosts several portals
+func corsPolicy(cfg *config.Config) middleware.CORSPolicy {
+	headers := cfg.CORS.AllowedHeaders
+	if cfg.Tenant.Enabled {
+		headers = append(append([]string(nil), headers...), cfg.Tenant.Header)
+	}
+	return middleware.CORSPolicy{
+		Origins:        cfg.CORS.AllowedOrigins,
+		Methods:        cfg.CORS.AllowedMethods,
+		Headers:        headers,
+		ExposedHeaders: cfg.CORS.ExposedHeaders,
+		Credentials:    cfg.CORS.AllowCredentials,
+		MaxAge:         cfg.CORS.MaxAge,
+	}
+}
 
-	// Public auth routes
-	authDelivery.RegisterRoutes(r, authHandler)
+// corsEmbedRules returns the rules giving embedded routes their relaxed
+// policy: reads only, from the embed origins, without credentials
+func corsEmbedRules(cfg *config.Config) []middleware.CORSRule {
+	policy := corsPolicy(cfg)
+	policy.Origins = cfg.CORS.EmbedOrigins
+	policy.Methods = []string{http.MethodGet, http.MethodHead, http.MethodOptions}
+	policy.Credentials = false
+
+	rules := make([]middleware.CORSRule, 0, len(cfg.CORS.EmbedPaths))
+	for _, pattern := range cfg.CORS.EmbedPaths {
+		rules = append(rules, middleware.CORSRule{Pattern: pattern, Policy: policy})
+	}
+	return rules
+}
 
-	// Public routes (no authentication required)
-	r.Group(func(r chi.Router) {
-		// Organizations - public read access
-		r.Route("/organizations", func(r chi.Router) {
-			r.Get("/", orgHandler.List)
-			r.Get("/code/{code}", orgHandler.GetByCode)
-			r.Get("/{id}", orgHandler.GetByID)
-		})
+// trustedProxies returns the networks of FORWARDED_ALLOW_IPS, validated with
+// the config, whose forwarded client IPs are trusted
+func trustedProxies(cfg *config.Config, appLogger *logger.Logger) []*net.IPNet {
+	networks, err := middleware.ParseNetworks(cfg.Server.TrustedProxies)
+	if err != nil {
+		appLogger.Fatal("Invalid FORWARDED_ALLOW_IPS: %v", err)
+	}
+	return networks
+}
 
-		// Datasets - public read access
-		r.Route("/datasets", func(r chi.Router) {
-			r.Get("/", datasetHandler.List)
-			r.Get("/slug/{slug}", datasetHandler.GetBySlug)
-			r.Get("/{id}", datasetHandler.GetByID)
-		})
+// ipAccessRule restricts the routes of IP_ACCESS_PATHS to the networks
+// IP_ACCESS_ALLOW and IP_ACCESS_DENY let in, validated with the config
+func ipAccessRule(cfg *config.Config, appLogger *logger.Logger) middleware.IPAccessRule {
+	allow, err := middleware.ParseNetworks(cfg.IPAccess.Allow)
+	if err != nil {
+		appLogger.Fatal("Invalid IP_ACCESS_ALLOW: %v", err)
+	}
+	deny, err := middleware.ParseNetworks(cfg.IPAccess.Deny)
+	if err != nil {
+		appLogger.Fatal("Invalid IP_ACCESS_DENY: %v", err)
+	}
+	return middleware.IPAccessRule{Prefixes: cfg.IPAccess.Paths, Allow: allow, Deny: deny}
+}
 
-		// Tags - public read access
-		r.Route("/tags", func(r chi.Router) {
-			r.Get("/", tagHandler.List)
-			r.Get("/{id}", tagHandler.GetByID)
-		})
+// routeRateLimit limits each client to the requests of the RATE_LIMIT_GROUPS
+// per window, in buckets kept in RATE_LIMIT_STORE
+func routeRateLimit(cfg *config.Config, jwtManager *security.JWTManager, appLogger *logger.Logger) func(http.Handler) http.Handler {
+	buckets, err := cache.NewBuckets(cfg.RateLimit.Store, &cfg.Redis, cfg.Cache.Timeout)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize rate limits: %v", err)
+	}
+	rules := make([]middleware.RateLimitRule, 0, len(cfg.RateLimit.Groups))
+	for _, group := range cfg.RateLimit.Groups {
+		rules = append(rules, middleware.RateLimitRule{Name: group.Name, Prefixes: group.Paths, Limit: group.Limit, Window: group.Window})
+	}
+	return middleware.RouteRateLimit(buckets, jwtManager, rules...)
+}
 
-		// BusinessFields - public read access
-		r.Route("/business-fields", func(r chi.Router) {
-			r.Get("/", bfHandler.List)
-			r.Get("/{id}", bfHandler.GetByID)
-		})
+// sessionCheck checks the session of access tokens with the accounts,
+// reporting errors.ErrTokenRevoked for those signed out
+func sessionCheck(accounts authUsecase.Usecase) func(ctx context.Context, token string) error {
+	return func(ctx context.Context, token string) error {
+		_, err := accounts.ValidateToken(ctx, token)
+		return err
+	}
+}
 
-		// Topics - public read access
-		r.Route("/topics", func(r chi.Router) {
-			r.Get("/", topicHandler.List)
-			r.Get("/{id}", topicHandler.GetByID)
-		})
+// maintenanceStatus reads the maintenance mode of the API from its setting
+func maintenanceStatus(settings settingsUsecase.Usecase) func(ctx context.Context) (middleware.MaintenanceStatus, error) {
+	return func(ctx context.Context) (middleware.MaintenanceStatus, error) {
+		maintenance, err := settings.GetMaintenance(ctx)
+		if err != nil {
+			return middleware.MaintenanceStatus{}, err
+		}
+		return middleware.MaintenanceStatus{
+			Enabled:    maintenance.Enabled,
+			Message:    maintenance.Message,
+			RetryAfter: time.Duration(maintenance.RetryAfter) * time.Second,
+		}, nil
+	}
+}
 
-		// Units - public read access
-		r.Route("/units", func(r chi.Router) {
-			r.Get("/", unitHandler.List)
-			r.Get("/{id}", unitHandler.GetByID)
-		})
+// apiKeys authenticates the API keys of developer applications, serving
+// them at the rate limit of their plan and counting their usage
+func apiKeys(developers developerUsecase.Usecase) func(http.Handler) http.Handler {
+	authenticate := func(ctx context.Context, key string) (*middleware.APIClient, error) {
+		client, err := developers.Authenticate(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		return &middleware.APIClient{
+			KeyID:         client.KeyID,
+			ApplicationID: client.ApplicationID,
+			Plan:          client.Plan,
+			RateLimit:     client.RateLimit,
+			RateWindow:    client.RateWindow,
+		}, nil
+	}
+	count := func(client *middleware.APIClient, throttled bool) {
+		developers.Count(client.KeyID, throttled)
+	}
+	return middleware.APIKeys(authenticate, count)
+}
 
-		// Visualizations - public read access
-		r.Route("/visualizations", func(r chi.Router) {
-			r.Get("/", vizHandler.List)
-			r.Get("/stats", vizHandler.GetStats)
-			r.Get("/dataset/{datasetId}", vizHandler.GetByDatasetID)
-			r.Get("/organization/{orgId}", vizHandler.GetByOrganizationID)
-			r.Get("/{id}", vizHandler.GetByID)
-		})
+// setupRouter configures and returns the HTTP router
+func setupRouter(cfg *config.Config, registry *app.Registry, jwtManager *security.JWTManager, sessions func(ctx context.Context, token string) error, auditRecorder *audit.Recorder, tenants *tenant.Resolver, maintenance func(ctx context.Context) (middleware.MaintenanceStatus, error), apiKeys func(http.Handler) http.Handler, permissions func(http.Handler) http.Handler, deprecations func(http.Handler) http.Handler, cacheStore *cache.Store, dbRouter *db.Router, healthChecks *health.Registry, appLogger *logger.Logger) *chi.Mux {
+	r := chi.NewRouter()
 
-		// Publications - public read access
-		r.Route("/publications", func(r chi.Router) {
-			r.Get("/", pubHandler.List)
-			r.Get("/dataset/{datasetId}", pubHandler.GetByDatasetID)
-			r.Get("/organization/{orgId}", pubHandler.GetByOrganizationID)
-			r.Get("/{id}", pubHandler.GetByID)
-		})
+	// Middleware
+	r.Use(chiMiddleware.RequestID)
+	r.Use(middleware.CorrelationID)
+	r.Use(middleware.RealIP(trustedProxies(cfg, appLogger)))
+	r.Use(middleware.ClientInfo)
+	r.Use(chiMiddleware.Timeout(cfg.Server.RequestTimeout))
+	r.Use(middleware.Logger(appLogger, !cfg.Privacy.Strict()))
+	r.Use(middleware.Recoverer)
+	if cfg.Server.CompressionLevel > 0 {
+		r.Use(chiMiddleware.Compress(cfg.Server.CompressionLevel, cfg.Server.CompressionTypes...))
+	}
+	r.Use(middleware.CORS(corsPolicy(cfg), corsEmbedRules(cfg)...))
+	r.Use(middleware.ContentType)
+	r.Use(middleware.Locale(cfg.I18n.DefaultLocale))
+	if cfg.Privacy.Strict() {
+		r.Use(middleware.TrackingOptOut)
+	}
 
-		// Analytics - public read access
-		r.Get("/analytics/dashboard", analyticsHandler.GetDashboard)
-		r.Get("/analytics/stats/datasets", analyticsHandler.GetDatasetStats)
-		r.Get("/analytics/stats/organizations", analyticsHandler.GetOrganizationStats)
-		r.Get("/analytics/stats/users", analyticsHandler.GetUserStats)
-		r.Get("/analytics/popular/datasets", analyticsHandler.GetPopularDatasets)
-		r.Get("/analytics/popular/tags", analyticsHandler.GetPopularTags)
-		r.Get("/analytics/trend/datasets", analyticsHandler.GetDatasetTrend)
+	// Unknown routes and methods answer problems like every other error
+	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		response.NotFound(w, response.CodeNotFound, "Route not found", nil)
+	})
+	r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		response.Error(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "Method not allowed", nil)
 	})
 
-	// Protected routes (require authentication)
-	r.Group(func(r chi.Router) {
-		r.Use(middleware.Auth(jwtManager))
-
-		// Auth protected routes
-		r.Post("/auth/revoke-all", authHandler.RevokeAllTokens)
-		r.Get("/me", authHandler.GetCurrentUser)
-
-		// User management
-		userDelivery.RegisterRoutes(r, userHandler)
-
-		// Organization management (write access)
-		r.Route("/organizations", func(r chi.Router) {
-			r.Post("/", orgHandler.Create)
-			r.Put("/{id}", orgHandler.Update)
-			r.Delete("/{id}", orgHandler.Delete)
-			r.Patch("/{id}/status", orgHandler.UpdateStatus)
-		})
-
-		// Dataset management (write access)
-		r.Route("/datasets", func(r chi.Router) {
-			r.Post("/", datasetHandler.Create)
-			r.Put("/{id}", datasetHandler.Update)
-			r.Delete("/{id}", datasetHandler.Delete)
-			r.Patch("/{id}/status", datasetHandler.UpdateStatus)
-		})
-
-		// Tag management (write access)
-		r.Route("/tags", func(r chi.Router) {
-			r.Post("/", tagHandler.Create)
-			r.Put("/{id}", tagHandler.Update)
-			r.Delete("/{id}", tagHandler.Delete)
-		})
-
-		// BusinessField management (write access)
-		r.Route("/business-fields", func(r chi.Router) {
-			r.Post("/", bfHandler.Create)
-			r.Put("/{id}", bfHandler.Update)
-			r.Delete("/{id}", bfHandler.Delete)
-		})
-
-		// Topic management (write access)
-		r.Route("/topics", func(r chi.Router) {
-			r.Post("/", topicHandler.Create)
-			r.Put("/{id}", topicHandler.Update)
-			r.Delete("/{id}", topicHandler.Delete)
-		})
+	// Liveness and readiness probes. /health is the former liveness check.
+	liveness := health.LivenessHandler(cfg.App.Version)
+	r.Get("/healthz", liveness)
+	r.Get("/health", liveness)
+	r.Get("/readyz", health.ReadinessHandler(healthChecks, cfg.Server.HealthCheckTimeout))
 
-		// Unit management (write access)
-		r.Route("/units", func(r chi.Router) {
-			r.Post("/", unitHandler.Create)
-			r.Put("/{id}", unitHandler.Update)
-			r.Delete("/{id}", unitHandler.Delete)
-		})
+	// Cache hit rates per namespace
+	r.Get("/metrics/cache", func(w http.ResponseWriter, r *http.Request) {
+		response.OK(w, response.CodeSuccess, "Cache statistics retrieved", cacheStore.Stats())
+	})
 
-		// Feedback management
-		fbDelivery.RegisterRoutes(r, fbHandler)
+	// Connection pools of the primary database and its replicas
+	r.Get("/metrics/db", func(w http.ResponseWriter, r *http.Request) {
+		response.OK(w, response.CodeSuccess, "Database statistics retrieved", dbRouter.Stats())
+	})
 
-		// File management
-		fileDelivery.RegisterRoutes(r, fileHandler)
+	// API specification and its documentation UI
+	r.Get("/openapi.json", openapi.Handler(apidoc.Spec(cfg.App.Version)))
+	r.Get("/docs", openapi.UIHandler(apidoc.Title, "/openapi.json"))
+
+	// API routes of version 1. Versions are mounted side by side under
+	// /api/<version>; a later version gets its own route function next to
+	// apiV1 and shares the handlers it keeps unchanged.
+	// Authenticated requests that change something are audited
+	authenticate := middleware.Auth(jwtManager, sessions)
+	audited := audit.Middleware(auditRecorder)
+	auth := func(next http.Handler) http.Handler {
+		return authenticate(audited(next))
+	}
+	// Uploads and imports may send larger bodies than the rest of the API
+	bodyLimits := middleware.BodyLimits(cfg.Server.MaxBodyBytes,
+		middleware.BodyLimitRule{Pattern: "/files/upload", Limit: cfg.Server.MaxUploadBytes},
+		middleware.BodyLimitRule{Pattern: "/*/*/icon", Limit: cfg.Server.MaxUploadBytes},
+		middleware.BodyLimitRule{Pattern: "/datasets/import-package", Limit: cfg.Server.MaxUploadBytes},
+		middleware.BodyLimitRule{Pattern: "/*/import", Limit: cfg.Server.MaxImportBytes},
+		middleware.BodyLimitRule{Pattern: "/*/bulk", Limit: cfg.Server.MaxImportBytes},
+		middleware.BodyLimitRule{Pattern: "/integrations/*/ingest", Limit: cfg.Server.MaxImportBytes},
+	)
+	// Admins read soft deleted records with ?include_deleted=true
+	includeDeleted := middleware.IncludeDeleted(jwtManager, cfg.Audit.AdminRoles...)
+	// During maintenance only admins are served; admin routes and sign-ins
+	// stay open so admins can sign in and switch it off
+	maintenanceMode := middleware.Maintenance(maintenance, jwtManager, []string{"/admin/", "/auth/"}, cfg.Audit.AdminRoles...)
+	ipAccess := middleware.IPAccess(ipAccessRule(cfg, appLogger))
+	rateLimits := routeRateLimit(cfg, jwtManager, appLogger)
+	apiV1 := func(r chi.Router) {
+		// Requests are scoped to the portal they are for when the deployment
+		// hosts several
+		if cfg.Tenant.Enabled {
+			r.Use(middleware.Tenant(tenants, cfg.Tenant.Header, cfg.Tenant.Default))
+		}
+		// Sensitive routes only serve clients from the allowed networks
+		if cfg.IPAccess.Enabled() {
+			r.Use(ipAccess)
+		}
+		r.Use(maintenanceMode)
+		// Developer applications are served at the rate limit of the plan of
+		// their API key
+		r.Use(apiKeys)
+		// Usage is counted by channel: the portal, developer applications
+		// and harvesters
+		r.Use(middleware.TrackingChannel(cfg.Analytics.HarvesterAgents))
+		// Each key, user or address is limited per group of routes
+		if len(cfg.RateLimit.Groups) > 0 {
+			r.Use(rateLimits)
+		}
+		// Deprecated routes and parameters announce their sunset, and who
+		// still uses them is counted
+		r.Use(deprecations)
+		r.Use(bodyLimits)
+		r.Use(includeDeleted)
+		// Write routes require the permissions the role of the user grants;
+		// admins hold every permission
+		r.Use(permissions)
+		registry.Routes(r, auth)
+	}
 
-		// Visualization management (write access)
-		r.Route("/visualizations", func(r chi.Router) {
-			r.Post("/", vizHandler.Create)
-			r.Put("/{id}", vizHandler.Update)
-			r.Delete("/{id}", vizHandler.Delete)
-			r.Patch("/{id}/status", vizHandler.UpdateStatus)
-		})
+	r.Route("/api/v1", func(r chi.Router) {
+		r.Use(middleware.APIVersion("v1"))
+		apiV1(r)
+	})
 
-		// Publication management (write access)
-		r.Route("/publications", func(r chi.Router) {
-			r.Post("/", pubHandler.Create)
-			r.Put("/{id}", pubHandler.Update)
-			r.Delete("/{id}", pubHandler.Delete)
-			r.Patch("/{id}/status", pubHandler.UpdateStatus)
-			r.Post("/{id}/download", pubHandler.IncrementDownloadCount)
+	// Legacy unversioned routes serve version 1 until they are switched off
+	if cfg.Server.LegacyRoutes {
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.APIVersion("v1"))
+			r.Use(middleware.Deprecated("/api/v1", cfg.Server.LegacyRoutesSunsetTime()))
+			apiV1(r)
 		})
-
-		// Settings management
-		settingsDelivery.RegisterRoutes(r, settingsHandler)
-
-		// Notification management
-		notifDelivery.RegisterRoutes(r, notifHandler)
-
-		// DataRow management
-		dataRowDelivery.RegisterRoutes(r, dataRowHandler)
-
-		// Desk/Ticket management
-		deskDelivery.RegisterRoutes(r, deskHandler)
-
-		// Integration management
-		integrationDelivery.RegisterRoutes(r, integrationHandler)
-	})
+	}
 
 	return r
 }
@@ -0,0 +1,248 @@
+// Package audit records who changed what. Usecases record their changes with
+// a Recorder, diffing the entity before and after; Middleware records the
+// remaining successful mutating requests so every change leaves an entry.
+// Impersonated requests are all recorded, reads included.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"portal-data-backend/infrastructure/auth"
+	"portal-data-backend/infrastructure/logger"
+
+	"github.com/go-chi/chi/v5"
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+)
+
+// Action is what was done to an entity
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+	// ActionRestore brings back a soft deleted entity
+	ActionRestore Action = "restore"
+	// ActionPurge deletes a soft deleted entity for good
+	ActionPurge Action = "purge"
+	// ActionImpersonate issues an admin a token acting as a user
+	ActionImpersonate Action = "impersonate"
+	// ActionRead reads an entity; only impersonated reads are recorded
+	ActionRead Action = "read"
+)
+
+// Entry is one change in the audit log. EntityType is the name of the
+// entity's API resource, like "datasets". Changes maps each changed field
+// to its old and new value; it is null when the change was only seen as a
+// request. ImpersonatorID is the admin acting as the actor, empty unless the
+// request was impersonated.
+type Entry struct {
+	ID             string          `db:"id" json:"id"`
+	ActorID        string          `db:"actor_id" json:"actor_id"`
+	ActorEmail     string          `db:"actor_email" json:"actor_email"`
+	ImpersonatorID string          `db:"impersonator_id" json:"impersonator_id,omitempty"`
+	OrganizationID string          `db:"organization_id" json:"organization_id"`
+	EntityType     string          `db:"entity_type" json:"entity_type"`
+	EntityID       string          `db:"entity_id" json:"entity_id"`
+	Action         Action          `db:"action" json:"action"`
+	Changes        json.RawMessage `db:"changes" json:"changes"`
+	RequestID      string          `db:"request_id" json:"request_id"`
+	Method         string          `db:"method" json:"method"`
+	Path           string          `db:"path" json:"path"`
+	CreatedAt      time.Time       `db:"created_at" json:"created_at"`
+}
+
+// Change is the old and new value of a field
+type Change struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// Store saves audit entries
+type Store interface {
+	Insert(ctx context.Context, entry *Entry) error
+}
+
+// Recorder records changes in the audit log. A nil Recorder records nothing.
+type Recorder struct {
+	store Store
+}
+
+// NewRecorder creates a recorder saving entries to store
+func NewRecorder(store Store) *Recorder {
+	return &Recorder{store: store}
+}
+
+// Record records that the user of ctx did action to an entity. before and
+// after are the entity before and after the change, nil when it did not
+// exist. The entry is saved in the transaction ctx carries, if any, so it is
+// kept only when the change is. Failures are logged, not returned.
+func (r *Recorder) Record(ctx context.Context, entityType, entityID string, action Action, before, after interface{}) {
+	if r == nil {
+		return
+	}
+	if trail, ok := ctx.Value(trailKey{}).(*trail); ok {
+		trail.recorded = true
+	}
+
+	entry := newEntry(ctx, entityType, entityID, action)
+	if before != nil || after != nil {
+		changes, err := Diff(before, after)
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to diff %s %s for the audit log: %v", entityType, entityID, err)
+		}
+		entry.Changes = changes
+	}
+	if err := r.store.Insert(ctx, entry); err != nil {
+		logger.FromContext(ctx).Error("failed to record %s of %s %s in the audit log: %v", action, entityType, entityID, err)
+	}
+}
+
+func newEntry(ctx context.Context, entityType, entityID string, action Action) *Entry {
+	entry := &Entry{
+		ID:         uuid.New().String(),
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		RequestID:  chiMiddleware.GetReqID(ctx),
+		CreatedAt:  time.Now(),
+	}
+	user := auth.FromContext(ctx)
+	entry.ActorID = user.UserID
+	entry.ActorEmail = user.Email
+	entry.ImpersonatorID = user.ImpersonatorID
+	entry.OrganizationID = user.OrganizationID
+	if trail, ok := ctx.Value(trailKey{}).(*trail); ok {
+		entry.Method = trail.method
+		entry.Path = trail.path
+	}
+	return entry
+}
+
+// Diff returns the fields of the JSON encodings of before and after that
+// differ, as a JSON object of Changes. Either may be nil, for entities
+// created or deleted.
+func Diff(before, after interface{}) (json.RawMessage, error) {
+	old, err := fields(before)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := fields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]Change)
+	for name, value := range old {
+		if newValue, ok := updated[name]; !ok || !reflect.DeepEqual(value, newValue) {
+			changes[name] = Change{Old: value, New: updated[name]}
+		}
+	}
+	for name, value := range updated {
+		if _, ok := old[name]; !ok {
+			changes[name] = Change{New: value}
+		}
+	}
+	return json.Marshal(changes)
+}
+
+// fields decodes the JSON encoding of v into its fields
+func fields(v interface{}) (map[string]interface{}, error) {
+	if v == nil || reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil() {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode entity: %w", err)
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("entity is not a JSON object: %w", err)
+	}
+	return result, nil
+}
+
+type trailKey struct{}
+
+// trail follows a mutating request, noting whether a usecase recorded it
+type trail struct {
+	method   string
+	path     string
+	recorded bool
+}
+
+// Middleware records successful mutating requests no usecase recorded a
+// change for, naming the entity after the route, and successful impersonated
+// reads. It must run after the request is authenticated.
+func Middleware(recorder *Recorder) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			action, ok := methodActions[r.Method]
+			if !ok && auth.FromContext(r.Context()).Impersonated() {
+				action, ok = ActionRead, true
+			}
+			if recorder == nil || !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if action == ActionCreate && strings.HasSuffix(r.URL.Path, "/restore") {
+				action = ActionRestore
+			}
+
+			trail := &trail{method: r.Method, path: r.URL.Path}
+			ctx := context.WithValue(r.Context(), trailKey{}, trail)
+			wrapped := chiMiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(wrapped, r.WithContext(ctx))
+
+			if trail.recorded || wrapped.Status() >= http.StatusBadRequest {
+				return
+			}
+			entityType, entityID := routeEntity(chi.RouteContext(ctx))
+			recorder.Record(ctx, entityType, entityID, action, nil, nil)
+		})
+	}
+}
+
+var methodActions = map[string]Action{
+	http.MethodPost:   ActionCreate,
+	http.MethodPut:    ActionUpdate,
+	http.MethodPatch:  ActionUpdate,
+	http.MethodDelete: ActionDelete,
+}
+
+// routeEntity names the entity of a route after its first segment past the
+// API version, like "datasets" for /api/v1/datasets/{id}/status, with the
+// first URL parameter as its ID
+func routeEntity(rctx *chi.Context) (string, string) {
+	if rctx == nil {
+		return "", ""
+	}
+
+	var entityType string
+	for _, segment := range strings.Split(rctx.RoutePattern(), "/") {
+		if segment == "" || segment == "api" || isVersion(segment) {
+			continue
+		}
+		entityType = segment
+		break
+	}
+
+	// Mounted routers leave "*" parameters behind
+	for i, key := range rctx.URLParams.Keys {
+		if key != "*" && i < len(rctx.URLParams.Values) {
+			return entityType, rctx.URLParams.Values[i]
+		}
+	}
+	return entityType, ""
+}
+
+func isVersion(segment string) bool {
+	return len(segment) > 1 && segment[0] == 'v' && strings.Trim(segment[1:], "0123456789") == ""
+}
@@ -0,0 +1,213 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"portal-data-backend/infrastructure/auth"
+	"portal-data-backend/pkg/client"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type memoryStore struct {
+	entries []*Entry
+}
+
+func (s *memoryStore) Insert(ctx context.Context, entry *Entry) error {
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+type unit struct {
+	ID     string  `json:"id"`
+	Name   string  `json:"name"`
+	Symbol *string `json:"symbol"`
+}
+
+// Test only the fields that changed are in the diff
+func TestDiff(t *testing.T) {
+	symbol := "kg"
+	changes, err := Diff(&unit{ID: "1", Name: "Kilo", Symbol: &symbol}, &unit{ID: "1", Name: "Kilogram"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var diff map[string]Change
+	json.Unmarshal(changes, &diff)
+	if len(diff) != 2 {
+		t.Fatalf("Expected 2 changed fields, got %s", changes)
+	}
+	if diff["name"].Old != "Kilo" || diff["name"].New != "Kilogram" {
+		t.Errorf("Expected name to change from Kilo to Kilogram, got %+v", diff["name"])
+	}
+	if diff["symbol"].Old != "kg" || diff["symbol"].New != nil {
+		t.Errorf("Expected symbol to be cleared, got %+v", diff["symbol"])
+	}
+}
+
+// Test every field of a created entity is in the diff
+func TestDiff_Created(t *testing.T) {
+	var before *unit
+	changes, err := Diff(before, &unit{ID: "1", Name: "Kilogram"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var diff map[string]Change
+	json.Unmarshal(changes, &diff)
+	if len(diff) != 3 || diff["id"].New != "1" || diff["id"].Old != nil {
+		t.Errorf("Expected all fields as new, got %s", changes)
+	}
+}
+
+// Test a recorded entry carries the user and their organization
+func TestRecorder_Record(t *testing.T) {
+	store := &memoryStore{}
+	ctx := auth.WithClaims(context.Background(), auth.Claims{UserID: "user-1", OrganizationID: "org-1"})
+
+	NewRecorder(store).Record(ctx, "units", "1", ActionDelete, &unit{ID: "1", Name: "Kilogram"}, nil)
+
+	if len(store.entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(store.entries))
+	}
+	entry := store.entries[0]
+	if entry.ActorID != "user-1" || entry.OrganizationID != "org-1" {
+		t.Errorf("Expected the entry of user-1 in org-1, got %+v", entry)
+	}
+	if entry.EntityType != "units" || entry.EntityID != "1" || entry.Action != ActionDelete {
+		t.Errorf("Expected the deletion of unit 1, got %+v", entry)
+	}
+}
+
+// memoryAuthStore keeps auth events in memory
+type memoryAuthStore struct {
+	entries []*AuthEntry
+}
+
+func (s *memoryAuthStore) InsertAuth(ctx context.Context, entry *AuthEntry) error {
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+// Test a recorded auth event carries the client of its request, and a nil
+// recorder records nothing
+func TestAuthRecorder_Record(t *testing.T) {
+	store := &memoryAuthStore{}
+	ctx := client.WithInfo(context.Background(), client.Info{IP: "203.0.113.7", UserAgent: "curl/8.0"})
+
+	NewAuthRecorder(store).Record(ctx, AuthEventLoginFailed, "", "who@example.com", "unknown email")
+	var recorder *AuthRecorder
+	recorder.Record(ctx, AuthEventLogin, "user-1", "user@example.com", "password")
+
+	if len(store.entries) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(store.entries))
+	}
+	entry := store.entries[0]
+	if entry.Event != AuthEventLoginFailed || entry.UserID != "" || entry.Email != "who@example.com" {
+		t.Errorf("Expected the failed sign in of who@example.com, got %+v", entry)
+	}
+	if entry.IP != "203.0.113.7" || entry.UserAgent != "curl/8.0" {
+		t.Errorf("Expected the client of the request, got %s %s", entry.IP, entry.UserAgent)
+	}
+}
+
+func newAuditedRouter(recorder *Recorder, handler http.HandlerFunc) http.Handler {
+	r := chi.NewRouter()
+	r.Route("/api/v1", func(r chi.Router) {
+		r.Route("/units", func(r chi.Router) {
+			r.Use(func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					claims := auth.FromContext(r.Context())
+					claims.UserID = "user-1"
+					next.ServeHTTP(w, r.WithContext(auth.WithClaims(r.Context(), claims)))
+				})
+			})
+			r.Use(Middleware(recorder))
+			r.Get("/{id}", handler)
+			r.Post("/{id}/icon", handler)
+		})
+	})
+	return r
+}
+
+// Test a mutating request no usecase recorded is recorded after its route
+func TestMiddleware_RecordsRequest(t *testing.T) {
+	store := &memoryStore{}
+	router := newAuditedRouter(NewRecorder(store), func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/v1/units/unit-1/icon", nil))
+
+	if len(store.entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(store.entries))
+	}
+	entry := store.entries[0]
+	if entry.EntityType != "units" || entry.EntityID != "unit-1" || entry.Action != ActionCreate {
+		t.Errorf("Expected a create of unit unit-1, got %+v", entry)
+	}
+	if entry.ActorID != "user-1" || entry.Method != http.MethodPost || entry.Path != "/api/v1/units/unit-1/icon" {
+		t.Errorf("Expected the request of user-1, got %+v", entry)
+	}
+	if entry.Changes != nil {
+		t.Errorf("Expected no changes, got %s", entry.Changes)
+	}
+}
+
+// Test requests that were recorded, failed or read nothing are not recorded
+// again
+func TestMiddleware_SkipsRequests(t *testing.T) {
+	store := &memoryStore{}
+	recorder := NewRecorder(store)
+
+	recorded := newAuditedRouter(recorder, func(w http.ResponseWriter, r *http.Request) {
+		recorder.Record(r.Context(), "units", "unit-1", ActionUpdate, &unit{Name: "Kilo"}, &unit{Name: "Kilogram"})
+		w.WriteHeader(http.StatusOK)
+	})
+	recorded.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/v1/units/unit-1/icon", nil))
+
+	succeeded := newAuditedRouter(recorder, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	succeeded.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/units/unit-1", nil))
+
+	failed := newAuditedRouter(recorder, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	})
+	failed.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/v1/units/unit-1/icon", nil))
+
+	if len(store.entries) != 1 {
+		t.Fatalf("Expected only the usecase's entry, got %d", len(store.entries))
+	}
+	if store.entries[0].Method != http.MethodPost {
+		t.Errorf("Expected the usecase's entry to carry the request, got %+v", store.entries[0])
+	}
+}
+
+// Test impersonated requests are recorded, reads included, naming the admin
+// impersonating the user
+func TestMiddleware_RecordsImpersonatedRead(t *testing.T) {
+	store := &memoryStore{}
+	router := newAuditedRouter(NewRecorder(store), func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/units/unit-1", nil)
+	req = req.WithContext(auth.WithClaims(req.Context(), auth.Claims{ImpersonatorID: "admin-1"}))
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(store.entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(store.entries))
+	}
+	entry := store.entries[0]
+	if entry.Action != ActionRead || entry.EntityID != "unit-1" {
+		t.Errorf("Expected a read of unit unit-1, got %+v", entry)
+	}
+	if entry.ActorID != "user-1" || entry.ImpersonatorID != "admin-1" {
+		t.Errorf("Expected user-1 impersonated by admin-1, got %+v", entry)
+	}
+}
@@ -0,0 +1,89 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"portal-data-backend/infrastructure/logger"
+	"portal-data-backend/pkg/client"
+
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+)
+
+// AuthEvent is what happened to the session or credentials of a user
+type AuthEvent string
+
+const (
+	AuthEventLogin AuthEvent = "login"
+	// AuthEventLoginFailed is a sign in refused for wrong credentials or an
+	// account that cannot sign in
+	AuthEventLoginFailed    AuthEvent = "login_failed"
+	AuthEventLogout         AuthEvent = "logout"
+	AuthEventTokenRefresh   AuthEvent = "token_refresh"
+	AuthEventPasswordChange AuthEvent = "password_change"
+	// AuthEventPasswordReset sets a password through a mailed reset link
+	AuthEventPasswordReset AuthEvent = "password_reset"
+)
+
+// AuthEvents lists the auth events recorded
+var AuthEvents = []AuthEvent{
+	AuthEventLogin, AuthEventLoginFailed, AuthEventLogout, AuthEventTokenRefresh, AuthEventPasswordChange, AuthEventPasswordReset,
+}
+
+// AuthEntry is one auth event in the security audit log. UserID is empty
+// when no account was found, like failed sign ins with an unknown email,
+// which keep the email tried. Detail says how the user signed in, like
+// "oidc:keycloak", or why they could not.
+type AuthEntry struct {
+	ID        string    `db:"id" json:"id"`
+	Event     AuthEvent `db:"event" json:"event"`
+	UserID    string    `db:"user_id" json:"user_id"`
+	Email     string    `db:"email" json:"email"`
+	IP        string    `db:"ip" json:"ip"`
+	UserAgent string    `db:"user_agent" json:"user_agent"`
+	Detail    string    `db:"detail" json:"detail,omitempty"`
+	RequestID string    `db:"request_id" json:"request_id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// AuthStore saves auth events
+type AuthStore interface {
+	InsertAuth(ctx context.Context, entry *AuthEntry) error
+}
+
+// AuthRecorder records auth events in the security audit log. A nil
+// AuthRecorder records nothing.
+type AuthRecorder struct {
+	store AuthStore
+}
+
+// NewAuthRecorder creates a recorder saving auth events to store
+func NewAuthRecorder(store AuthStore) *AuthRecorder {
+	return &AuthRecorder{store: store}
+}
+
+// Record records event for the user of userID and email, from the client
+// ctx carries. Failures are logged, not returned, so they never fail signing
+// in or out.
+func (r *AuthRecorder) Record(ctx context.Context, event AuthEvent, userID, email, detail string) {
+	if r == nil {
+		return
+	}
+
+	info := client.FromContext(ctx)
+	entry := &AuthEntry{
+		ID:        uuid.New().String(),
+		Event:     event,
+		UserID:    userID,
+		Email:     email,
+		IP:        info.IP,
+		UserAgent: info.UserAgent,
+		Detail:    detail,
+		RequestID: chiMiddleware.GetReqID(ctx),
+		CreatedAt: time.Now(),
+	}
+	if err := r.store.InsertAuth(ctx, entry); err != nil {
+		logger.FromContext(ctx).Error("failed to record %s of %s in the auth audit log: %v", event, email, err)
+	}
+}
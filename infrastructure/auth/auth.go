@@ -0,0 +1,79 @@
+// Package auth carries the signed-in user of a request or call in its
+// context. The Auth HTTP middleware and the Authenticate gRPC interceptor
+// set it from a valid token; handlers, usecases and the audit log read it
+// with FromContext. Contexts without it are anonymous, so reading it never
+// fails.
+package auth
+
+import (
+	"context"
+
+	"portal-data-backend/infrastructure/security"
+)
+
+// Claims is the user a request is made as
+type Claims struct {
+	UserID         string
+	OrganizationID string
+	RoleID         string
+	Email          string
+	// ImpersonatorID and ImpersonatorEmail are the admin acting as the
+	// user, empty unless the request is impersonated
+	ImpersonatorID    string
+	ImpersonatorEmail string
+}
+
+// FromToken returns the claims of the user a token was issued to
+func FromToken(token *security.Claims) Claims {
+	claims := Claims{
+		UserID:         token.UserID,
+		OrganizationID: token.OrganizationID,
+		RoleID:         token.RoleID,
+		Email:          token.Email,
+	}
+	if token.Actor != nil {
+		claims.ImpersonatorID = token.Actor.UserID
+		claims.ImpersonatorEmail = token.Actor.Email
+	}
+	return claims
+}
+
+// Authenticated reports whether c is a signed-in user
+func (c Claims) Authenticated() bool {
+	return c.UserID != ""
+}
+
+// Impersonated reports whether an admin is acting as the user of c
+func (c Claims) Impersonated() bool {
+	return c.ImpersonatorID != ""
+}
+
+// HasRole reports whether the user of c is signed in with one of roles
+func (c Claims) HasRole(roles ...string) bool {
+	for _, role := range roles {
+		if c.RoleID != "" && c.RoleID == role {
+			return true
+		}
+	}
+	return false
+}
+
+type contextKey struct{}
+
+// WithClaims returns a context made as the user of claims
+func WithClaims(ctx context.Context, claims Claims) context.Context {
+	return context.WithValue(ctx, contextKey{}, claims)
+}
+
+// FromContext returns the user ctx is made as, the zero Claims when it is
+// anonymous
+func FromContext(ctx context.Context) Claims {
+	claims, _ := ctx.Value(contextKey{}).(Claims)
+	return claims
+}
+
+// UserID returns the ID of the user ctx is made as, or "" when it is
+// anonymous
+func UserID(ctx context.Context) string {
+	return FromContext(ctx).UserID
+}
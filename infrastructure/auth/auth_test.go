@@ -0,0 +1,30 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"portal-data-backend/infrastructure/security"
+)
+
+// Test the claims of a token are read back from the context, and contexts
+// without them are anonymous
+func TestFromContext(t *testing.T) {
+	if claims := FromContext(context.Background()); claims.Authenticated() || UserID(context.Background()) != "" {
+		t.Errorf("Expected an anonymous context, got %+v", claims)
+	}
+
+	token := &security.Claims{UserID: "user-1", OrganizationID: "org-1", RoleID: "editor", Email: "user@example.com",
+		Actor: &security.Actor{UserID: "admin-1", Email: "admin@example.com"}}
+	ctx := WithClaims(context.Background(), FromToken(token))
+	claims := FromContext(ctx)
+	if !claims.Authenticated() || UserID(ctx) != "user-1" || claims.OrganizationID != "org-1" || claims.Email != "user@example.com" {
+		t.Errorf("Expected the user of the token, got %+v", claims)
+	}
+	if !claims.Impersonated() || claims.ImpersonatorID != "admin-1" || claims.ImpersonatorEmail != "admin@example.com" {
+		t.Errorf("Expected the admin acting as the user, got %+v", claims)
+	}
+	if !claims.HasRole("admin", "editor") || claims.HasRole("admin") || (Claims{}).HasRole("") {
+		t.Errorf("Expected only the role of the token to match")
+	}
+}
@@ -0,0 +1,159 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+)
+
+// Bucket is the state of a token bucket after taking a token from it
+type Bucket struct {
+	// Allowed tells whether there was a token to take
+	Allowed bool
+	// Remaining is how many whole tokens are left
+	Remaining int
+	// RetryAfter is how long until the next token, when none was left
+	RetryAfter time.Duration
+	// Reset is how long until the bucket is full again
+	Reset time.Duration
+}
+
+// Buckets keeps token buckets by key, each holding up to capacity tokens
+// and refilled with capacity tokens per window. Implementations are safe
+// for concurrent use.
+type Buckets interface {
+	// Take takes a token from the bucket of key, a full one when it is new
+	Take(ctx context.Context, key string, capacity int, window time.Duration) (Bucket, error)
+}
+
+// NewBuckets creates the buckets of store: "memory" keeps them in process
+// and "redis" shares them across instances. timeout bounds Redis commands.
+func NewBuckets(store string, redis *config.RedisConfig, timeout time.Duration) (Buckets, error) {
+	switch store {
+	case "", "memory":
+		return NewMemoryBuckets(), nil
+	case "redis":
+		return &redisBuckets{redis: newRedis(fmt.Sprintf("%s:%d", redis.Host, redis.Port), redis.Password, redis.DB, timeout)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported rate limit store %q", store)
+	}
+}
+
+// bucketState is what is left in a bucket with capacity tokens refilled per
+// window, after a token was taken when allowed
+func bucketState(allowed bool, tokens float64, capacity int, window time.Duration) Bucket {
+	perToken := window / time.Duration(capacity)
+	bucket := Bucket{
+		Allowed:   allowed,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     time.Duration((float64(capacity) - tokens) * float64(perToken)),
+	}
+	if !allowed {
+		bucket.RetryAfter = time.Duration((1 - tokens) * float64(perToken))
+	}
+	return bucket
+}
+
+// MemoryBuckets keeps token buckets in process memory
+type MemoryBuckets struct {
+	mu        sync.Mutex
+	buckets   map[string]*memoryBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type memoryBucket struct {
+	tokens  float64
+	updated time.Time
+	window  time.Duration
+}
+
+// NewMemoryBuckets creates empty in-memory buckets
+func NewMemoryBuckets() *MemoryBuckets {
+	return &MemoryBuckets{buckets: map[string]*memoryBucket{}, now: time.Now}
+}
+
+// Take implements Buckets
+func (m *MemoryBuckets) Take(ctx context.Context, key string, capacity int, window time.Duration) (Bucket, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	// Forget the buckets full again, so the map does not grow forever
+	if now.Sub(m.lastSweep) >= time.Minute {
+		for k, b := range m.buckets {
+			if now.Sub(b.updated) >= b.window {
+				delete(m.buckets, k)
+			}
+		}
+		m.lastSweep = now
+	}
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &memoryBucket{tokens: float64(capacity), updated: now}
+		m.buckets[key] = b
+	}
+	b.window = window
+	refill := now.Sub(b.updated).Seconds() * float64(capacity) / window.Seconds()
+	b.tokens = math.Min(float64(capacity), b.tokens+refill)
+	b.updated = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	return bucketState(allowed, b.tokens, capacity, window), nil
+}
+
+// takeScript takes a token from the bucket at KEYS[1] holding up to ARGV[1]
+// tokens refilled per ARGV[2] milliseconds, on the clock of Redis so that
+// instances agree. It returns whether a token was taken and the tokens left.
+const takeScript = `
+local capacity = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'updated')
+local tokens = tonumber(state[1]) or capacity
+local updated = tonumber(state[2]) or now
+tokens = math.min(capacity, tokens + math.max(0, now - updated) * capacity / window)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updated', tostring(now))
+redis.call('PEXPIRE', KEYS[1], window)
+return {allowed, tostring(tokens)}
+`
+
+// redisBuckets keeps token buckets in Redis hashes, updated by a script so
+// that instances take tokens atomically
+type redisBuckets struct {
+	redis *redisCache
+}
+
+// Take implements Buckets
+func (r *redisBuckets) Take(ctx context.Context, key string, capacity int, window time.Duration) (Bucket, error) {
+	reply, err := r.redis.do(ctx, "EVAL", takeScript, "1", key, strconv.Itoa(capacity), strconv.FormatInt(window.Milliseconds(), 10))
+	if err != nil {
+		return Bucket{}, fmt.Errorf("failed to take a token: %w", err)
+	}
+	items, ok := reply.([]interface{})
+	if !ok || len(items) != 2 {
+		return Bucket{}, fmt.Errorf("unexpected redis reply %v to EVAL", reply)
+	}
+	allowed, _ := items[0].(int64)
+	left, _ := items[1].([]byte)
+	tokens, err := strconv.ParseFloat(string(left), 64)
+	if err != nil {
+		return Bucket{}, fmt.Errorf("unexpected redis reply %v to EVAL", reply)
+	}
+	return bucketState(allowed == 1, tokens, capacity, window), nil
+}
@@ -0,0 +1,200 @@
+// Package cache stores computed values for a limited time, in process memory
+// or in Redis so that instances share them. Modules cache through a Namespace,
+// which encodes values as JSON and counts hits and misses.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/infrastructure/logger"
+	"portal-data-backend/infrastructure/tenant"
+)
+
+// Cache stores values by key until their TTL passes. Implementations are
+// safe for concurrent use.
+type Cache interface {
+	// Get returns the value of key, and false when it is not cached
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	// Ping checks the cache can be reached
+	Ping(ctx context.Context) error
+}
+
+// New creates the cache configured in cfg. It returns nil when caching is
+// disabled.
+func New(cfg *config.CacheConfig, redis *config.RedisConfig) (Cache, error) {
+	switch cfg.Driver {
+	case "":
+		return nil, nil
+	case "memory":
+		return NewMemory(), nil
+	case "redis":
+		return newRedis(fmt.Sprintf("%s:%d", redis.Host, redis.Port), redis.Password, redis.DB, cfg.Timeout), nil
+	default:
+		return nil, fmt.Errorf("unsupported cache driver %q", cfg.Driver)
+	}
+}
+
+// Store hands out the namespaces modules cache in and keeps their statistics
+type Store struct {
+	cache  Cache
+	prefix string
+
+	mu         sync.Mutex
+	namespaces map[string]*Namespace
+}
+
+// NewStore creates a store keeping values in c under keys starting with
+// prefix. A nil c disables caching.
+func NewStore(c Cache, prefix string) *Store {
+	return &Store{cache: c, prefix: prefix, namespaces: map[string]*Namespace{}}
+}
+
+// Namespace returns the namespace called name, whose values expire after
+// ttl. It returns nil, which caches nothing, when caching is disabled.
+func (s *Store) Namespace(name string, ttl time.Duration) *Namespace {
+	if s == nil || s.cache == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	namespace := &Namespace{cache: s.cache, prefix: s.prefix + name + ":", name: name, ttl: ttl}
+	if existing, ok := s.namespaces[name]; ok {
+		// Namespaces of the same name share their statistics
+		namespace.stats = existing.stats
+	} else {
+		namespace.stats = &stats{}
+	}
+	s.namespaces[name] = namespace
+	return namespace
+}
+
+// Stats are the lookups of a namespace since the process started
+type Stats struct {
+	Namespace string  `json:"namespace"`
+	Hits      int64   `json:"hits"`
+	Misses    int64   `json:"misses"`
+	Errors    int64   `json:"errors"`
+	HitRate   float64 `json:"hit_rate"`
+}
+
+// Stats returns the statistics of every namespace, ordered by name
+func (s *Store) Stats() []Stats {
+	if s == nil {
+		return []Stats{}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]Stats, 0, len(s.namespaces))
+	for name, namespace := range s.namespaces {
+		result = append(result, namespace.stats.snapshot(name))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Namespace < result[j].Namespace })
+	return result
+}
+
+// Namespace caches one kind of value. Values are encoded as JSON and kept
+// apart per tenant of the context. Cache failures are logged and treated as
+// misses, so callers fall back to the source. A nil namespace caches nothing.
+type Namespace struct {
+	cache  Cache
+	prefix string
+	name   string
+	ttl    time.Duration
+	stats  *stats
+}
+
+// Get decodes the value cached for key into v and reports whether it was
+// cached
+func (n *Namespace) Get(ctx context.Context, key string, v interface{}) bool {
+	if n == nil {
+		return false
+	}
+
+	data, ok, err := n.cache.Get(ctx, n.key(ctx, key))
+	if err == nil && ok {
+		err = json.Unmarshal(data, v)
+	}
+	switch {
+	case err != nil:
+		n.stats.errors.Add(1)
+		n.stats.misses.Add(1)
+		logger.FromContext(ctx).Warn("failed to read %s %s from the cache: %v", n.name, key, err)
+		return false
+	case !ok:
+		n.stats.misses.Add(1)
+		return false
+	}
+	n.stats.hits.Add(1)
+	return true
+}
+
+// Set caches v for key
+func (n *Namespace) Set(ctx context.Context, key string, v interface{}) {
+	if n == nil {
+		return
+	}
+
+	data, err := json.Marshal(v)
+	if err == nil {
+		err = n.cache.Set(ctx, n.key(ctx, key), data, n.ttl)
+	}
+	if err != nil {
+		n.stats.errors.Add(1)
+		logger.FromContext(ctx).Warn("failed to write %s %s to the cache: %v", n.name, key, err)
+	}
+}
+
+// Delete removes the values of keys, so the next Get reads them from the
+// source
+func (n *Namespace) Delete(ctx context.Context, keys ...string) {
+	if n == nil || len(keys) == 0 {
+		return
+	}
+
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = n.key(ctx, key)
+	}
+	if err := n.cache.Delete(ctx, prefixed...); err != nil {
+		n.stats.errors.Add(1)
+		logger.FromContext(ctx).Warn("failed to delete %s %v from the cache: %v", n.name, keys, err)
+	}
+}
+
+// key is the cache key of key, under the tenant of ctx when it has one
+func (n *Namespace) key(ctx context.Context, key string) string {
+	if id := tenant.ID(ctx); id != "" {
+		return n.prefix + id + ":" + key
+	}
+	return n.prefix + key
+}
+
+type stats struct {
+	hits   atomic.Int64
+	misses atomic.Int64
+	errors atomic.Int64
+}
+
+func (s *stats) snapshot(name string) Stats {
+	result := Stats{
+		Namespace: name,
+		Hits:      s.hits.Load(),
+		Misses:    s.misses.Load(),
+		Errors:    s.errors.Load(),
+	}
+	if lookups := result.Hits + result.Misses; lookups > 0 {
+		result.HitRate = float64(result.Hits) / float64(lookups)
+	}
+	return result
+}
@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"portal-data-backend/infrastructure/tenant"
+)
+
+type profile struct {
+	Name string `json:"name"`
+}
+
+// Test a namespace caches values until they are deleted and counts lookups
+func TestNamespace_GetSet(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(NewMemory(), "test:")
+	profiles := store.Namespace("profiles", time.Minute)
+
+	var cached profile
+	if profiles.Get(ctx, "p1", &cached) {
+		t.Fatalf("Expected a miss before the value is set")
+	}
+	profiles.Set(ctx, "p1", profile{Name: "Statistics"})
+	if !profiles.Get(ctx, "p1", &cached) || cached.Name != "Statistics" {
+		t.Fatalf("Expected the cached profile, got %+v", cached)
+	}
+	profiles.Delete(ctx, "p1")
+	if profiles.Get(ctx, "p1", &cached) {
+		t.Errorf("Expected a miss after the value is deleted")
+	}
+
+	stats := store.Stats()
+	if len(stats) != 1 || stats[0].Namespace != "profiles" {
+		t.Fatalf("Expected the profiles stats, got %+v", stats)
+	}
+	if stats[0].Hits != 1 || stats[0].Misses != 2 {
+		t.Errorf("Expected 1 hit and 2 misses, got %+v", stats[0])
+	}
+	if stats[0].HitRate < 0.33 || stats[0].HitRate > 0.34 {
+		t.Errorf("Expected a hit rate of 1/3, got %f", stats[0].HitRate)
+	}
+}
+
+// Test namespaces keep their keys apart
+func TestNamespace_Prefix(t *testing.T) {
+	ctx := context.Background()
+	memory := NewMemory()
+	store := NewStore(memory, "test:")
+	store.Namespace("a", time.Minute).Set(ctx, "key", "a")
+
+	var value string
+	if store.Namespace("b", time.Minute).Get(ctx, "key", &value) {
+		t.Errorf("Expected namespace b not to see the value of a")
+	}
+	if _, ok, _ := memory.Get(ctx, "test:a:key"); !ok {
+		t.Errorf("Expected the value under test:a:key")
+	}
+}
+
+// Test tenants keep their values apart
+func TestNamespace_Tenant(t *testing.T) {
+	ctx := context.Background()
+	memory := NewMemory()
+	settings := NewStore(memory, "test:").Namespace("settings", time.Minute)
+	settings.Set(tenant.WithTenant(ctx, &tenant.Tenant{ID: "jabar"}), "public", "jabar")
+
+	var value string
+	if settings.Get(ctx, "public", &value) {
+		t.Errorf("Expected requests without a tenant not to see the value of jabar")
+	}
+	if _, ok, _ := memory.Get(ctx, "test:settings:jabar:public"); !ok {
+		t.Errorf("Expected the value under test:settings:jabar:public")
+	}
+}
+
+// Test caching is a no-op when disabled
+func TestStore_Disabled(t *testing.T) {
+	ctx := context.Background()
+	namespace := NewStore(nil, "test:").Namespace("profiles", time.Minute)
+	if namespace != nil {
+		t.Fatalf("Expected a nil namespace")
+	}
+
+	namespace.Set(ctx, "p1", profile{Name: "Statistics"})
+	var cached profile
+	if namespace.Get(ctx, "p1", &cached) {
+		t.Errorf("Expected a miss without a cache")
+	}
+	namespace.Delete(ctx, "p1")
+}
+
+// Test memory entries expire after their TTL
+func TestMemory_Expiry(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	memory := NewMemory()
+	memory.now = func() time.Time { return now }
+
+	memory.Set(ctx, "key", []byte("value"), time.Minute)
+	if _, ok, _ := memory.Get(ctx, "key"); !ok {
+		t.Fatalf("Expected the value before it expires")
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok, _ := memory.Get(ctx, "key"); ok {
+		t.Errorf("Expected the value to expire")
+	}
+}
+
+// Test memory buckets hold their capacity and refill over their window
+func TestMemoryBuckets_Take(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	buckets := NewMemoryBuckets()
+	buckets.now = func() time.Time { return now }
+
+	for i := 2; i >= 0; i-- {
+		bucket, _ := buckets.Take(ctx, "client", 3, 3*time.Minute)
+		if !bucket.Allowed || bucket.Remaining != i {
+			t.Fatalf("Expected a token with %d left, got %+v", i, bucket)
+		}
+	}
+	bucket, _ := buckets.Take(ctx, "client", 3, 3*time.Minute)
+	if bucket.Allowed || bucket.RetryAfter != time.Minute || bucket.Reset != 3*time.Minute {
+		t.Errorf("Expected no token until a minute later, got %+v", bucket)
+	}
+	if other, _ := buckets.Take(ctx, "other", 3, 3*time.Minute); !other.Allowed {
+		t.Errorf("Expected clients to have their own buckets")
+	}
+
+	now = now.Add(time.Minute)
+	if bucket, _ := buckets.Take(ctx, "client", 3, 3*time.Minute); !bucket.Allowed || bucket.Remaining != 0 {
+		t.Errorf("Expected a token refilled after a minute, got %+v", bucket)
+	}
+}
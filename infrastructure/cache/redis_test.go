@@ -0,0 +1,145 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis serves GET, SET, DEL, AUTH and SELECT from memory
+type fakeRedis struct {
+	mu       sync.Mutex
+	values   map[string]string
+	commands []string
+}
+
+func startFakeRedis(t *testing.T) (*fakeRedis, string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	server := &fakeRedis{values: map[string]string{}}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return server, listener.Addr().String()
+}
+
+func (s *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		s.commands = append(s.commands, strings.Join(args, " "))
+		var reply string
+		switch strings.ToUpper(args[0]) {
+		case "AUTH", "SELECT":
+			reply = "+OK\r\n"
+		case "SET":
+			s.values[args[1]] = args[2]
+			reply = "+OK\r\n"
+		case "GET":
+			if value, ok := s.values[args[1]]; ok {
+				reply = fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+			} else {
+				reply = "$-1\r\n"
+			}
+		case "DEL":
+			for _, key := range args[1:] {
+				delete(s.values, key)
+			}
+			reply = fmt.Sprintf(":%d\r\n", len(args)-1)
+		default:
+			reply = "-ERR unknown command\r\n"
+		}
+		s.mu.Unlock()
+		conn.Write([]byte(reply))
+	}
+}
+
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	count, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+	args := make([]string, count)
+	for i := range args {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		args[i] = string(data[:size])
+	}
+	return args, nil
+}
+
+// Test the redis cache sets, gets and deletes values with a TTL
+func TestRedis_GetSetDelete(t *testing.T) {
+	server, addr := startFakeRedis(t)
+	c := newRedis(addr, "secret", 2, time.Second)
+	ctx := context.Background()
+
+	if _, ok, err := c.Get(ctx, "missing"); err != nil || ok {
+		t.Fatalf("Expected a miss, got %v, %v", ok, err)
+	}
+	if err := c.Set(ctx, "key", []byte(`{"name":"Statistics"}`), 90*time.Second); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	value, ok, err := c.Get(ctx, "key")
+	if err != nil || !ok || string(value) != `{"name":"Statistics"}` {
+		t.Fatalf("Expected the stored value, got %q, %v, %v", value, ok, err)
+	}
+	if err := c.Delete(ctx, "key"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, ok, _ := c.Get(ctx, "key"); ok {
+		t.Errorf("Expected the value to be deleted")
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	expected := []string{"AUTH secret", "SELECT 2", "GET missing", `SET key {"name":"Statistics"} PX 90000`, "GET key", "DEL key", "GET key"}
+	if strings.Join(server.commands, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected commands %q on one pooled connection, got %q", expected, server.commands)
+	}
+}
+
+// Test an error reply is returned and keeps the connection usable
+func TestRedis_ErrorReply(t *testing.T) {
+	_, addr := startFakeRedis(t)
+	c := newRedis(addr, "", 0, time.Second)
+	ctx := context.Background()
+
+	if _, err := c.do(ctx, "PING"); err == nil || err.Error() != "redis: ERR unknown command" {
+		t.Errorf("Expected the error reply, got %v", err)
+	}
+	if len(c.idle) != 1 {
+		t.Errorf("Expected the connection back in the pool")
+	}
+}
@@ -0,0 +1,116 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"portal-data-backend/infrastructure/logger"
+	"portal-data-backend/infrastructure/tenant"
+)
+
+// SurrogateAll is the surrogate key of every value, purging everything at
+// once
+const SurrogateAll = "all"
+
+// Surrogates version surrogate keys, which name the entities cached values
+// were built from, like "datasets" or "dataset:<id>". Values are cached with
+// the versions of their keys and are stale once one of them changes, so
+// purging a key invalidates every value built from it without listing them.
+// Versions are kept apart per tenant of the context. A nil Surrogates purges
+// nothing and holds no version current.
+type Surrogates struct {
+	cache  Cache
+	prefix string
+	ttl    time.Duration
+}
+
+// Surrogates returns the surrogate keys of values cached for up to ttl. It
+// returns nil when caching is disabled.
+func (s *Store) Surrogates(ttl time.Duration) *Surrogates {
+	if s == nil || s.cache == nil {
+		return nil
+	}
+	return &Surrogates{cache: s.cache, prefix: s.prefix + "surrogate:", ttl: ttl}
+}
+
+// Versions returns the current versions of keys, giving a version to those
+// without one. Versions are kept for another TTL, so they outlive the values
+// about to be cached with them.
+func (s *Surrogates) Versions(ctx context.Context, keys []string) (map[string]string, error) {
+	if s == nil {
+		return nil, nil
+	}
+
+	versions := make(map[string]string, len(keys))
+	for _, key := range keys {
+		data, ok, err := s.cache.Get(ctx, s.key(ctx, key))
+		if err != nil {
+			return nil, err
+		}
+		version := string(data)
+		if !ok {
+			if version, err = newVersion(); err != nil {
+				return nil, err
+			}
+		}
+		if err := s.cache.Set(ctx, s.key(ctx, key), []byte(version), s.ttl); err != nil {
+			return nil, err
+		}
+		versions[key] = version
+	}
+	return versions, nil
+}
+
+// Current reports whether versions are still the versions of their keys.
+// Failing to read a version counts as a change.
+func (s *Surrogates) Current(ctx context.Context, versions map[string]string) bool {
+	if s == nil {
+		return false
+	}
+
+	for key, version := range versions {
+		data, ok, err := s.cache.Get(ctx, s.key(ctx, key))
+		if err != nil {
+			logger.FromContext(ctx).Warn("failed to read surrogate key %s from the cache: %v", key, err)
+			return false
+		}
+		if !ok || string(data) != version {
+			return false
+		}
+	}
+	return true
+}
+
+// Purge invalidates the values cached with any of keys
+func (s *Surrogates) Purge(ctx context.Context, keys ...string) {
+	if s == nil || len(keys) == 0 {
+		return
+	}
+
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = s.key(ctx, key)
+	}
+	if err := s.cache.Delete(ctx, prefixed...); err != nil {
+		logger.FromContext(ctx).Warn("failed to purge surrogate keys %v: %v", keys, err)
+	}
+}
+
+// key is the cache key of the version of key, under the tenant of ctx when
+// it has one
+func (s *Surrogates) key(ctx context.Context, key string) string {
+	if id := tenant.ID(ctx); id != "" {
+		return s.prefix + id + ":" + key
+	}
+	return s.prefix + key
+}
+
+func newVersion() (string, error) {
+	version := make([]byte, 8)
+	if _, err := rand.Read(version); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(version), nil
+}
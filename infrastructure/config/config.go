@@ -2,20 +2,59 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"os"
+	"strings"
 	"time"
-
-	"github.com/joho/godotenv"
 )
 
 // Config holds all configuration for the application
 type Config struct {
-	App      AppConfig
-	Server   ServerConfig
-	Database DatabaseConfig
-	Redis    RedisConfig
-	JWT      JWTConfig
-	MinIO    MinIOConfig
+	App         AppConfig
+	Server      ServerConfig
+	Database    DatabaseConfig
+	Redis       RedisConfig
+	JWT         JWTConfig
+	MinIO       MinIOConfig
+	Webhook     WebhookConfig
+	Harvest     HarvestConfig
+	Scheduler   SchedulerConfig
+	Events      EventsConfig
+	Desk        DeskConfig
+	Review      ReviewConfig
+	Secrets     SecretsConfig
+	SecretStore SecretStoreConfig
+	I18n        I18nConfig
+	Mail        MailConfig
+	Feedback    FeedbackConfig
+	Search      SearchConfig
+	Cache       CacheConfig
+	Audit       AuditConfig
+	Masking     MaskingConfig
+	Tenant      TenantConfig
+	GRPC        GRPCConfig
+	CORS        CORSConfig
+	IPAccess    IPAccessConfig
+	Developer   DeveloperConfig
+	Preview     PreviewConfig
+	Moderation  ModerationConfig
+	Highlight   HighlightConfig
+	LinkCheck   LinkCheckConfig
+	Recovery    RecoveryConfig
+	Password    PasswordPolicyConfig
+	Signup      SignupConfig
+	AuthCaptcha CaptchaConfig
+	Privacy     PrivacyConfig
+	Analytics   AnalyticsConfig
+	RateLimit   RateLimitConfig
+	OIDC        OIDCConfig
+	Trash       TrashConfig
+	Cleanup     CleanupConfig
+	Widget      WidgetConfig
+	Deprecation DeprecationConfig
+	Report      ReportConfig
+	License     LicenseConfig
+	Package     PackageConfig
 }
 
 // AppConfig contains application metadata
@@ -24,6 +63,9 @@ type AppConfig struct {
 	Environment string
 	Debug       bool
 	Version     string
+	// LogLevel is the lowest level logged: "debug", "info", "warn" or
+	// "error". It is reloaded on SIGHUP.
+	LogLevel string
 }
 
 // ServerConfig contains HTTP server configuration
@@ -32,6 +74,30 @@ type ServerConfig struct {
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
 	IdleTimeout  time.Duration
+	// LegacyRoutes keeps serving the API at the root next to /api/v1, marked
+	// deprecated. LegacyRoutesSunset is the date (YYYY-MM-DD) they stop
+	// working, announced to clients; empty when not planned yet.
+	LegacyRoutes       bool
+	LegacyRoutesSunset string
+	// MaxBodyBytes bounds the size of request bodies; MaxUploadBytes those of
+	// file and icon uploads and MaxImportBytes those of imports, bulk
+	// creations and ingests
+	MaxBodyBytes   int64
+	MaxUploadBytes int64
+	MaxImportBytes int64
+	// CompressionLevel is the gzip/deflate level of responses, 0 to disable
+	// compression; only CompressionTypes are compressed
+	CompressionLevel int
+	CompressionTypes []string
+	// HealthCheckTimeout bounds each dependency probe of /readyz
+	HealthCheckTimeout time.Duration
+	// RequestTimeout is the deadline of the context of each request, which
+	// queries and storage calls made for it are canceled at
+	RequestTimeout time.Duration
+	// TrustedProxies are the networks of the proxies whose X-Forwarded-For
+	// and X-Real-IP headers tell the IP of clients. Requests from other peers
+	// are taken as coming from the peer, whatever their headers say.
+	TrustedProxies []string
 }
 
 // DatabaseConfig contains database connection configuration
@@ -45,6 +111,16 @@ type DatabaseConfig struct {
 	MaxOpenConns int
 	MaxIdleConns int
 	MaxLifetime  time.Duration
+	// StatementTimeout makes PostgreSQL abort statements running longer,
+	// including those of background jobs; 0 disables it
+	StatementTimeout time.Duration
+
+	// ReplicaDSNs are the connection strings of read replicas. Public reads
+	// go to them; without replicas everything goes to the primary.
+	ReplicaDSNs []string
+	// ReplicaCheckInterval is how often replicas are checked, so failed ones
+	// are skipped and recovered ones used again
+	ReplicaCheckInterval time.Duration
 }
 
 // RedisConfig contains Redis connection configuration
@@ -60,6 +136,9 @@ type JWTConfig struct {
 	Secret            string
 	AccessTokenExpiry time.Duration
 	RefreshTokenExpiry time.Duration
+	// ImpersonationExpiry is how long the tokens admins impersonate users
+	// with are valid; they cannot be refreshed
+	ImpersonationExpiry time.Duration
 	Issuer            string
 }
 
@@ -70,12 +149,526 @@ type MinIOConfig struct {
 	SecretKey       string
 	Bucket          string
 	UseSSL          bool
+	// Timeout bounds each storage call; UploadTimeout bounds uploads and
+	// downloads, which stream the whole file
+	Timeout       time.Duration
+	UploadTimeout time.Duration
+}
+
+// WebhookConfig contains outbound webhook delivery configuration
+type WebhookConfig struct {
+	Timeout          time.Duration
+	MaxAttempts      int
+	DispatchInterval time.Duration
+	BatchSize        int
+}
+
+// HarvestConfig contains connector harvesting configuration
+type HarvestConfig struct {
+	Timeout    time.Duration
+	MaxRecords int
+}
+
+// SchedulerConfig contains the integration run scheduler configuration.
+// Scheduled and manual runs share one queue served by Workers goroutines.
+// An integration is reported as failing after FailureThreshold consecutive
+// failed runs; its health covers the last HealthWindow runs.
+type SchedulerConfig struct {
+	CheckInterval    time.Duration
+	Jitter           time.Duration // random delay added to every scheduled run
+	Workers          int
+	QueueSize        int
+	FailureThreshold int
+	HealthWindow     int
+}
+
+// EventsConfig contains the optional event sink that publishes domain events
+// to a message broker. Driver is "kafka" (through a Kafka REST Proxy at URL),
+// "nats", or empty to disable the sink. Topics maps event types to topics;
+// when it is empty every event is published to TopicPrefix + event type.
+//
+// Events are first recorded in the outbox, in the transaction of the change
+// raising them. The relay polls it every OutboxPollInterval, handing up to
+// OutboxBatchSize events at a time to the sink and the subscribing modules,
+// and forgets relayed events after OutboxRetention.
+type EventsConfig struct {
+	Driver      string
+	URL         string
+	TopicPrefix string
+	Topics      map[string]string
+	Timeout     time.Duration
+
+	OutboxPollInterval time.Duration
+	OutboxBatchSize    int
+	OutboxRetention    time.Duration
+}
+
+// DeskConfig contains the helpdesk ticket SLAs. SLA maps a ticket priority to
+// how long a ticket may stay unresolved before it is reported as a breach.
+// Requesters filing tickets through the contact form are mailed PublicURL
+// followed by the access token of their ticket. Each client may file tickets
+// and reply through the link PublicRateLimit times per PublicRateWindow.
+type DeskConfig struct {
+	SLA              map[string]time.Duration
+	SLACheckInterval time.Duration
+	PublicURL        string
+	PublicRateLimit  int
+	PublicRateWindow time.Duration
+}
+
+// ReviewConfig contains the review of the datasets, visualizations and
+// publications waiting for one. SLA maps each of those content types to how
+// long an item may wait before its review is overdue; items are at risk once
+// their review is due within Warning. Admins approve the items of
+// TrustedOrganizations in bulk.
+type ReviewConfig struct {
+	SLA                  map[string]time.Duration
+	Warning              time.Duration
+	TrustedOrganizations []string
+}
+
+// I18nConfig contains the locale catalog text is stored in by default.
+// Translations to other languages are served when a client asks for them.
+type I18nConfig struct {
+	DefaultLocale string
+}
+
+// MailConfig contains the SMTP server mail is sent through. Without a Host
+// mail is only logged.
+type MailConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// FeedbackConfig contains the spam protection of anonymous feedback. Each
+// client may submit RateLimit times per RateWindow. With a CaptchaSecret,
+// submissions need a captcha token accepted by CaptchaVerifyURL. Submitters
+// confirm their email through ConfirmURL within ConfirmationTTL.
+type FeedbackConfig struct {
+	RateLimit        int
+	RateWindow       time.Duration
+	CaptchaVerifyURL string
+	CaptchaSecret    string
+	ConfirmURL       string
+	ConfirmationTTL  time.Duration
+}
+
+// SearchConfig contains the search backend. Backend "postgres" searches the
+// database directly. Backend "opensearch" searches Index on the OpenSearch
+// or Elasticsearch cluster at URL, which is kept up to date in the background
+// through a queue of QueueSize dataset changes.
+type SearchConfig struct {
+	Backend   string
+	URL       string
+	Index     string
+	Username  string
+	Password  string
+	Timeout   time.Duration
+	QueueSize int
+}
+
+// CacheConfig contains caching of frequently read values. Driver "memory"
+// caches in each instance, "redis" in the Redis server of RedisConfig so that
+// instances share it, and an empty driver disables caching. Keys start with
+// KeyPrefix; each TTL bounds how stale a cached value of its kind can get.
+type CacheConfig struct {
+	Driver          string
+	KeyPrefix       string
+	Timeout         time.Duration
+	DatasetTTL      time.Duration
+	SettingsTTL     time.Duration
+	OrganizationTTL time.Duration
+	AnalyticsTTL    time.Duration
+	OverviewTTL     time.Duration
+	// MaintenanceTTL bounds how long instances take to notice the
+	// maintenance mode changing
+	MaintenanceTTL time.Duration
+	// ResponseTTL bounds how long public GET responses are cached; changes
+	// purge them sooner through their surrogate keys
+	ResponseTTL time.Duration
+	// RoleTTL bounds how long instances take to notice the permissions of
+	// a role changing
+	RoleTTL time.Duration
+}
+
+// AuditConfig contains the audit log of changes. Users whose role is one of
+// AdminRoles may read and export it.
+type AuditConfig struct {
+	AdminRoles []string
+}
+
+// MaskingConfig contains the masking of the sensitive columns of data rows.
+// Readers whose role is one of PrivilegedRoles see raw values; the admin
+// roles when unset. HashKey keys the hashes of the hash strategy, so values
+// cannot be guessed by hashing candidates; changing it changes every hash.
+type MaskingConfig struct {
+	HashKey         string
+	PrivilegedRoles []string
+}
+
+// DeveloperConfig contains the developer program. Each API key of an
+// application on the free plan may send FreeRateLimit requests per
+// RateWindow, of a partner application PartnerRateLimit; a limit below 1
+// lifts it. Developers register up to MaxApplications applications with up
+// to MaxKeys live keys each. A rotated key keeps working for RotationGrace so
+// clients can switch. Keys are looked up in the cache for KeyCacheTTL, and
+// the usage of keys is written every UsageFlushInterval.
+type DeveloperConfig struct {
+	FreeRateLimit      int
+	PartnerRateLimit   int
+	RateWindow         time.Duration
+	MaxApplications    int
+	MaxKeys            int
+	RotationGrace      time.Duration
+	KeyCacheTTL        time.Duration
+	UsageFlushInterval time.Duration
+}
+
+// PreviewConfig contains the images generated for datasets without one. When
+// Enabled, a dataset left without an image gets a Width by Height chart of
+// the first MaxSeries numeric columns of its first SampleRows rows, or a card
+// with its title in the Brand name and Color when it has no numeric data.
+// Images are rendered by the rendering service at RenderURL, waiting up to
+// Timeout, or as SVG by the application itself when RenderURL is empty.
+// Datasets are queued for a preview in a queue of QueueSize.
+type PreviewConfig struct {
+	Enabled    bool
+	RenderURL  string
+	Timeout    time.Duration
+	Width      int
+	Height     int
+	SampleRows int
+	MaxSeries  int
+	Brand      string
+	Color      string
+	QueueSize  int
+}
+
+// ModerationConfig contains the screening of text the public submits.
+// Submissions mentioning one of Keywords, linking to one of BlockedDomains or
+// to more than MaxLinks addresses are held for a moderator; with HoldAll
+// every submission is. Users whose role is one of ModeratorRoles, the admin
+// roles when unset, work through the held submissions. The heuristics are
+// reloaded on SIGHUP.
+type ModerationConfig struct {
+	Keywords       []string
+	BlockedDomains []string
+	MaxLinks       int
+	HoldAll        bool
+	ModeratorRoles []string
+}
+
+// HighlightConfig contains the curation of the datasets highlighted on the
+// homepage. At most Max datasets are highlighted at a time; a highlight added
+// without an expiry date expires after DefaultTTL, or never when it is zero.
+// Expired highlights are removed every ExpiryInterval.
+type HighlightConfig struct {
+	Max            int
+	DefaultTTL     time.Duration
+	ExpiryInterval time.Duration
+}
+
+// TrashConfig contains the trash deleted records wait in. They can be
+// restored for Retention, after which they are purged for good, with what
+// depends on them, every PurgeInterval. A zero PurgeInterval leaves purging
+// to portalctl.
+type TrashConfig struct {
+	Retention     time.Duration
+	PurgeInterval time.Duration
+}
+
+// CleanupConfig contains the maintenance jobs of the server. Expired and
+// revoked tokens are deleted every TokenInterval, and notifications read
+// longer than NotificationRetention ago every NotificationInterval. A zero
+// interval turns its job off.
+type CleanupConfig struct {
+	TokenInterval         time.Duration
+	NotificationInterval  time.Duration
+	NotificationRetention time.Duration
+}
+
+// WidgetConfig contains the statistics widgets agency websites embed.
+// Browsers and CDNs may keep them for MaxAge; lists show LatestLimit
+// datasets unless asked for fewer or more, up to 20.
+type WidgetConfig struct {
+	MaxAge      time.Duration
+	LatestLimit int
+}
+
+// ReportConfig contains the monthly open data reports. Every CheckInterval
+// the report of the previous month is queued in each of Formats, pdf or
+// xlsx and both when empty, unless it was already; a zero CheckInterval
+// leaves reports to be requested. Reports rank TopOrganizations
+// organizations and queue up to QueueSize reports at a time. PDF reports
+// draw a chart of each of Visualizations, by ID, rendered like previews.
+type ReportConfig struct {
+	Formats          []string
+	CheckInterval    time.Duration
+	TopOrganizations int
+	QueueSize        int
+	Visualizations   []string
+}
+
+// LicenseConfig is the license the datasets of the portal are published
+// under, as described to data package tooling: an identifier like
+// "CC-BY-4.0", its title and the URL of its text
+type LicenseConfig struct {
+	Name  string
+	Title string
+	URL   string
+}
+
+// PackageConfig bounds the dataset packages admins import: up to MaxRows
+// rows, and attached files of up to MaxFileBytes each, SERVER_MAX_UPLOAD_BYTES
+// unless set
+type PackageConfig struct {
+	MaxRows      int
+	MaxFileBytes int64
+}
+
+// DeprecationConfig contains the tracking of the deprecated surfaces of the
+// API. The requests clients make to them are written every
+// UsageFlushInterval.
+type DeprecationConfig struct {
+	UsageFlushInterval time.Duration
+}
+
+// LinkCheckConfig contains the checks of the source URLs datasets reference.
+// Every Interval, up to Batch links last checked longer than Recheck ago are
+// requested, each within Timeout. A zero Interval disables the checks.
+type LinkCheckConfig struct {
+	Interval time.Duration
+	Recheck  time.Duration
+	Batch    int
+	Timeout  time.Duration
+}
+
+// RecoveryConfig contains the recovery of accounts whose password was
+// forgotten. Users are mailed a link to URL with a single use token that
+// expires after TTL. Each client may ask for RateLimit password reset or
+// email verification links per RateWindow.
+type RecoveryConfig struct {
+	URL        string
+	TTL        time.Duration
+	RateLimit  int
+	RateWindow time.Duration
+}
+
+// PasswordPolicyConfig contains the rules the passwords users choose follow.
+// Passwords are at least MinLength characters long and mix MinClasses of
+// lowercase letters, uppercase letters, digits and symbols. With BanCommon,
+// well known passwords are refused, and a new password may not repeat any of
+// the last History passwords of its user, the current one included.
+type PasswordPolicyConfig struct {
+	MinLength  int
+	MinClasses int
+	BanCommon  bool
+	History    int
 }
 
-// Load loads configuration from environment variables
+// SignupConfig contains the registration of users. With
+// RequireVerification, registered users stay pending and cannot sign in
+// until they open the link to VerifyURL they are mailed, which works once
+// within VerificationTTL.
+type SignupConfig struct {
+	RequireVerification bool
+	VerifyURL           string
+	VerificationTTL     time.Duration
+}
+
+// CaptchaConfig contains the captcha protecting the public sign in and
+// registration endpoints from bots. Provider is "recaptcha", "hcaptcha" or
+// "turnstile", whose siteverify endpoint tokens are checked with unless
+// VerifyURL is set. Captchas are only required with a Secret.
+type CaptchaConfig struct {
+	Provider  string
+	VerifyURL string
+	Secret    string
+}
+
+// captchaVerifyURLs are the siteverify endpoints of the captcha providers
+var captchaVerifyURLs = map[string]string{
+	"recaptcha": "https://www.google.com/recaptcha/api/siteverify",
+	"hcaptcha":  "https://api.hcaptcha.com/siteverify",
+	"turnstile": "https://challenges.cloudflare.com/turnstile/v0/siteverify",
+}
+
+// Enabled reports whether captchas are required
+func (c CaptchaConfig) Enabled() bool {
+	return c.Secret != ""
+}
+
+// PrivacyConfig contains the usage analytics compliance mode. Mode is
+// "standard" or "strict": in strict mode, requests sending DNT: 1 or
+// Sec-GPC: 1 are left out of the analytics counters and request logs leave
+// out the client IP. PolicyURL links the privacy policy of the portal from
+// the public privacy manifest.
+type PrivacyConfig struct {
+	Mode      string
+	PolicyURL string
+}
+
+// AnalyticsConfig contains how usage is broken down by channel. Requests
+// whose user agent contains one of HarvesterAgents, ignoring case, are
+// counted as harvesters, those with an API key as developer applications
+// and the others as the portal.
+type AnalyticsConfig struct {
+	HarvesterAgents []string
+}
+
+// RateLimitConfig contains the rate limits of groups of routes. Each client,
+// an API key, a signed-in user or else an address, may send Limit requests
+// per Window to the routes of a group, in bursts of up to Limit, with the
+// buckets kept in Store: "memory" per instance or "redis" shared.
+type RateLimitConfig struct {
+	Store  string
+	Groups []RateLimitGroupConfig
+}
+
+// RateLimitGroupConfig contains the rate limit of the routes whose path
+// below the API version starts with one of Paths. The first group matching
+// a route limits it.
+type RateLimitGroupConfig struct {
+	Name   string
+	Paths  []string
+	Limit  int
+	Window time.Duration
+}
+
+// OIDCConfig contains the sign in with external OpenID Connect providers.
+// Providers send users back to RedirectURL, where {provider} stands for the
+// name of the provider, within LoginTTL of starting to sign in.
+type OIDCConfig struct {
+	RedirectURL string
+	LoginTTL    time.Duration
+	Providers   []OIDCProviderConfig
+}
+
+// OIDCProviderConfig contains an OpenID Connect provider, discovered from its
+// Issuer and signed in to as ClientID. Users signing in are linked to the
+// account with their email when the provider verified it, or TrustEmail
+// vouches for the provider. With Provision, users without an account get one
+// in OrganizationID with RoleID.
+type OIDCProviderConfig struct {
+	Name           string
+	Issuer         string
+	ClientID       string
+	ClientSecret   string
+	Scopes         []string
+	TrustEmail     bool
+	Provision      bool
+	OrganizationID string
+	RoleID         string
+}
+
+// Provider returns the configured provider named name
+func (c *OIDCConfig) Provider(name string) (OIDCProviderConfig, bool) {
+	for _, provider := range c.Providers {
+		if provider.Name == name {
+			return provider, true
+		}
+	}
+	return OIDCProviderConfig{}, false
+}
+
+// TenantConfig contains the hosting of several portals by one deployment.
+// When Enabled, each request is for the tenant named by the Header, or else
+// the tenant its hostname belongs to, or else the Default tenant. Users whose
+// role is one of SuperAdminRoles manage the tenants. Tenants are looked up
+// again after CacheTTL.
+type TenantConfig struct {
+	Enabled         bool
+	Header          string
+	Default         string
+	SuperAdminRoles []string
+	CacheTTL        time.Duration
+}
+
+// GRPCConfig contains the gRPC API internal services call. When Enabled it
+// listens on Port with mutual TLS: it presents CertFile and KeyFile and
+// accepts clients whose certificate ClientCAFile signed. Insecure serves
+// plaintext instead, for development only.
+type GRPCConfig struct {
+	Enabled      bool
+	Port         int
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string
+	Insecure     bool
+}
+
+// CORSConfig contains what browsers may do calling the API from other
+// origins. AllowedOrigins are origins like https://data.example.go.id,
+// https://*.example.go.id for its subdomains, or "*" for any origin; "*"
+// cannot be combined with AllowCredentials. Requests whose path below the API
+// version matches one of EmbedPaths, like /visualizations/*, follow a relaxed
+// policy instead, for pages embedding them: reads from any of EmbedOrigins,
+// without credentials.
+type CORSConfig struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+	EmbedPaths       []string
+	EmbedOrigins     []string
+}
+
+// IPAccessConfig restricts the routes whose path below the API version
+// starts with one of Paths, like /users or /admin/, to clients on the
+// networks of Allow, like an office or VPN range, and keeps out those on the
+// networks of Deny. Networks are CIDR ranges like 10.8.0.0/16 or single
+// addresses. Clients are not restricted while both lists are empty.
+type IPAccessConfig struct {
+	Paths []string
+	Allow []string
+	Deny  []string
+}
+
+// Enabled reports whether any network is allowed or denied
+func (c IPAccessConfig) Enabled() bool {
+	return len(c.Allow) > 0 || len(c.Deny) > 0
+}
+
+// SecretsConfig contains the keys used to encrypt stored credentials.
+// Keys are base64 encoded 32 byte values; PreviousKeys maps retired key IDs
+// to keys that may still be needed to decrypt.
+type SecretsConfig struct {
+	KeyID        string
+	Key          string
+	PreviousKeys map[string]string
+}
+
+// SecretStoreConfig contains the secret stores other values may refer to,
+// as vault://path#key or awssm://name#key, instead of holding credentials.
+// Vault is reached at VaultAddr with VaultToken; AWS Secrets Manager in
+// AWSRegion with the AWS access key. These values cannot be references.
+type SecretStoreConfig struct {
+	VaultAddr          string
+	VaultToken         string
+	VaultNamespace     string
+	AWSRegion          string
+	AWSAccessKeyID     string
+	AWSSecretAccessKey string
+	AWSSessionToken    string
+	Timeout            time.Duration
+}
+
+// Load loads configuration from environment variables and the .env file
 func Load() (*Config, error) {
-	// Load .env file if exists (optional for production)
-	_ = godotenv.Load()
+	return LoadWith(Options{})
+}
+
+// LoadWith loads configuration from the sources of opts, failing with a
+// ValidationError listing every invalid value
+func LoadWith(opts Options) (*Config, error) {
+	if err := applySources(opts); err != nil {
+		return nil, err
+	}
 
 	cfg := &Config{
 		App: AppConfig{
@@ -83,12 +676,23 @@ func Load() (*Config, error) {
 			Environment: getEnv("APP_ENV", "development"),
 			Debug:       getEnv("APP_DEBUG", "true") == "true",
 			Version:     getEnv("APP_VERSION", "1.0.0"),
+			LogLevel:    strings.ToLower(getEnv("APP_LOG_LEVEL", "")),
 		},
 		Server: ServerConfig{
-			Port:         getEnvAsInt("SERVER_PORT", 8080),
-			ReadTimeout:  getEnvAsDuration("SERVER_READ_TIMEOUT", 10*time.Second),
-			WriteTimeout: getEnvAsDuration("SERVER_WRITE_TIMEOUT", 10*time.Second),
-			IdleTimeout:  getEnvAsDuration("SERVER_IDLE_TIMEOUT", 60*time.Second),
+			Port:               getEnvAsInt("SERVER_PORT", 8080),
+			ReadTimeout:        getEnvAsDuration("SERVER_READ_TIMEOUT", 10*time.Second),
+			WriteTimeout:       getEnvAsDuration("SERVER_WRITE_TIMEOUT", 10*time.Second),
+			IdleTimeout:        getEnvAsDuration("SERVER_IDLE_TIMEOUT", 60*time.Second),
+			LegacyRoutes:       getEnv("SERVER_LEGACY_ROUTES", "true") == "true",
+			LegacyRoutesSunset: getEnv("SERVER_LEGACY_ROUTES_SUNSET", ""),
+			MaxBodyBytes:       int64(getEnvAsInt("SERVER_MAX_BODY_BYTES", 1<<20)),
+			MaxUploadBytes:     int64(getEnvAsInt("SERVER_MAX_UPLOAD_BYTES", 32<<20)),
+			MaxImportBytes:     int64(getEnvAsInt("SERVER_MAX_IMPORT_BYTES", 10<<20)),
+			CompressionLevel:   getEnvAsInt("SERVER_COMPRESSION_LEVEL", 5),
+			CompressionTypes:   getEnvAsList("SERVER_COMPRESSION_TYPES"),
+			HealthCheckTimeout: getEnvAsDuration("SERVER_HEALTH_CHECK_TIMEOUT", 2*time.Second),
+			RequestTimeout:     getEnvAsDuration("SERVER_REQUEST_TIMEOUT", 60*time.Second),
+			TrustedProxies:     getEnvAsList("FORWARDED_ALLOW_IPS"),
 		},
 		Database: DatabaseConfig{
 			Host:         getEnv("DB_HOST", "localhost"),
@@ -100,6 +704,11 @@ func Load() (*Config, error) {
 			MaxOpenConns: getEnvAsInt("DB_MAX_OPEN_CONNS", 25),
 			MaxIdleConns: getEnvAsInt("DB_MAX_IDLE_CONNS", 5),
 			MaxLifetime:  getEnvAsDuration("DB_MAX_LIFETIME", 5*time.Minute),
+
+			StatementTimeout: getEnvAsDuration("DB_STATEMENT_TIMEOUT", 30*time.Second),
+
+			ReplicaDSNs:          getEnvAsList("DB_REPLICA_DSNS"),
+			ReplicaCheckInterval: getEnvAsDuration("DB_REPLICA_CHECK_INTERVAL", 10*time.Second),
 		},
 		Redis: RedisConfig{
 			Host:     getEnv("REDIS_HOST", "localhost"),
@@ -111,6 +720,7 @@ func Load() (*Config, error) {
 			Secret:            getEnv("JWT_SECRET", "change-me-in-production"),
 			AccessTokenExpiry: getEnvAsDuration("JWT_ACCESS_EXPIRY", 15*time.Minute),
 			RefreshTokenExpiry: getEnvAsDuration("JWT_REFRESH_EXPIRY", 7*24*time.Hour),
+			ImpersonationExpiry: getEnvAsDuration("JWT_IMPERSONATION_EXPIRY", 10*time.Minute),
 			Issuer:            getEnv("JWT_ISSUER", "portal-data-backend"),
 		},
 		MinIO: MinIOConfig{
@@ -119,7 +729,301 @@ func Load() (*Config, error) {
 			SecretKey: getEnv("MINIO_SECRET_KEY", "minioadmin"),
 			Bucket:    getEnv("MINIO_BUCKET", "portal-data"),
 			UseSSL:    getEnv("MINIO_USE_SSL", "false") == "true",
+
+			Timeout:       getEnvAsDuration("MINIO_TIMEOUT", 10*time.Second),
+			UploadTimeout: getEnvAsDuration("MINIO_UPLOAD_TIMEOUT", 5*time.Minute),
+		},
+		Webhook: WebhookConfig{
+			Timeout:          getEnvAsDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+			MaxAttempts:      getEnvAsInt("WEBHOOK_MAX_ATTEMPTS", 8),
+			DispatchInterval: getEnvAsDuration("WEBHOOK_DISPATCH_INTERVAL", 15*time.Second),
+			BatchSize:        getEnvAsInt("WEBHOOK_BATCH_SIZE", 50),
+		},
+		Harvest: HarvestConfig{
+			Timeout:    getEnvAsDuration("HARVEST_TIMEOUT", 10*time.Minute),
+			MaxRecords: getEnvAsInt("HARVEST_MAX_RECORDS", 10000),
+		},
+		Scheduler: SchedulerConfig{
+			CheckInterval:    getEnvAsDuration("SCHEDULER_CHECK_INTERVAL", 30*time.Second),
+			Jitter:           getEnvAsDuration("SCHEDULER_JITTER", 30*time.Second),
+			Workers:          getEnvAsInt("SCHEDULER_WORKERS", 4),
+			QueueSize:        getEnvAsInt("SCHEDULER_QUEUE_SIZE", 100),
+			FailureThreshold: getEnvAsInt("SCHEDULER_FAILURE_THRESHOLD", 3),
+			HealthWindow:     getEnvAsInt("SCHEDULER_HEALTH_WINDOW", 20),
+		},
+		Events: EventsConfig{
+			Driver:      getEnv("EVENTS_DRIVER", ""),
+			URL:         getEnv("EVENTS_URL", ""),
+			TopicPrefix: getEnv("EVENTS_TOPIC_PREFIX", "portal."),
+			Topics:      getEnvAsMap("EVENTS_TOPICS"),
+			Timeout:     getEnvAsDuration("EVENTS_TIMEOUT", 5*time.Second),
+
+			OutboxPollInterval: getEnvAsDuration("EVENTS_OUTBOX_POLL_INTERVAL", time.Second),
+			OutboxBatchSize:    getEnvAsInt("EVENTS_OUTBOX_BATCH_SIZE", 100),
+			OutboxRetention:    getEnvAsDuration("EVENTS_OUTBOX_RETENTION", 7*24*time.Hour),
+		},
+		Desk: DeskConfig{
+			SLA: getEnvAsDurationMap("DESK_SLA", map[string]time.Duration{
+				"urgent": 4 * time.Hour,
+				"high":   24 * time.Hour,
+				"medium": 72 * time.Hour,
+				"low":    168 * time.Hour,
+			}),
+			SLACheckInterval: getEnvAsDuration("DESK_SLA_CHECK_INTERVAL", 5*time.Minute),
+			PublicURL:        getEnv("DESK_PUBLIC_URL", "http://localhost:8080/api/v1/public/tickets/"),
+			PublicRateLimit:  getEnvAsInt("DESK_PUBLIC_RATE_LIMIT", 5),
+			PublicRateWindow: getEnvAsDuration("DESK_PUBLIC_RATE_WINDOW", time.Hour),
+		},
+		Review: ReviewConfig{
+			SLA: getEnvAsDurationMap("REVIEW_SLA", map[string]time.Duration{
+				"dataset":       72 * time.Hour,
+				"visualization": 48 * time.Hour,
+				"publication":   48 * time.Hour,
+			}),
+			Warning:              getEnvAsDuration("REVIEW_SLA_WARNING", 24*time.Hour),
+			TrustedOrganizations: getEnvAsList("REVIEW_TRUSTED_ORGANIZATIONS"),
+		},
+		Secrets: SecretsConfig{
+			KeyID:        getEnv("SECRETS_KEY_ID", "primary"),
+			Key:          getEnv("SECRETS_ENCRYPTION_KEY", ""),
+			PreviousKeys: getEnvAsMap("SECRETS_PREVIOUS_KEYS"),
+		},
+		SecretStore: SecretStoreConfig{
+			VaultAddr:          getEnv("VAULT_ADDR", ""),
+			VaultToken:         getEnv("VAULT_TOKEN", ""),
+			VaultNamespace:     getEnv("VAULT_NAMESPACE", ""),
+			AWSRegion:          getEnv("AWS_REGION", ""),
+			AWSAccessKeyID:     getEnv("AWS_ACCESS_KEY_ID", ""),
+			AWSSecretAccessKey: getEnv("AWS_SECRET_ACCESS_KEY", ""),
+			AWSSessionToken:    getEnv("AWS_SESSION_TOKEN", ""),
+			Timeout:            getEnvAsDuration("SECRET_STORE_TIMEOUT", 10*time.Second),
+		},
+		I18n: I18nConfig{
+			DefaultLocale: getEnv("I18N_DEFAULT_LOCALE", "id"),
+		},
+		Mail: MailConfig{
+			Host:     getEnv("MAIL_HOST", ""),
+			Port:     getEnvAsInt("MAIL_PORT", 587),
+			Username: getEnv("MAIL_USERNAME", ""),
+			Password: getEnv("MAIL_PASSWORD", ""),
+			From:     getEnv("MAIL_FROM", "no-reply@localhost"),
+		},
+		Feedback: FeedbackConfig{
+			RateLimit:        getEnvAsInt("FEEDBACK_RATE_LIMIT", 5),
+			RateWindow:       getEnvAsDuration("FEEDBACK_RATE_WINDOW", time.Hour),
+			CaptchaVerifyURL: getEnv("FEEDBACK_CAPTCHA_VERIFY_URL", "https://www.google.com/recaptcha/api/siteverify"),
+			CaptchaSecret:    getEnv("FEEDBACK_CAPTCHA_SECRET", ""),
+			ConfirmURL:       getEnv("FEEDBACK_CONFIRM_URL", "http://localhost:8080/api/v1/feedbacks/confirm"),
+			ConfirmationTTL:  getEnvAsDuration("FEEDBACK_CONFIRMATION_TTL", 48*time.Hour),
+		},
+		Search: SearchConfig{
+			Backend:   getEnv("SEARCH_BACKEND", "postgres"),
+			URL:       getEnv("SEARCH_URL", ""),
+			Index:     getEnv("SEARCH_INDEX", "portal-datasets"),
+			Username:  getEnv("SEARCH_USERNAME", ""),
+			Password:  getEnv("SEARCH_PASSWORD", ""),
+			Timeout:   getEnvAsDuration("SEARCH_TIMEOUT", 10*time.Second),
+			QueueSize: getEnvAsInt("SEARCH_QUEUE_SIZE", 1000),
+		},
+		Cache: CacheConfig{
+			Driver:          getEnv("CACHE_DRIVER", "memory"),
+			KeyPrefix:       getEnv("CACHE_KEY_PREFIX", "portal:"),
+			Timeout:         getEnvAsDuration("CACHE_TIMEOUT", 200*time.Millisecond),
+			DatasetTTL:      getEnvAsDuration("CACHE_DATASET_TTL", 5*time.Minute),
+			SettingsTTL:     getEnvAsDuration("CACHE_SETTINGS_TTL", 5*time.Minute),
+			OrganizationTTL: getEnvAsDuration("CACHE_ORGANIZATION_TTL", 10*time.Minute),
+			AnalyticsTTL:    getEnvAsDuration("CACHE_ANALYTICS_TTL", time.Minute),
+			OverviewTTL:     getEnvAsDuration("CACHE_OVERVIEW_TTL", 30*time.Second),
+			MaintenanceTTL:  getEnvAsDuration("CACHE_MAINTENANCE_TTL", 10*time.Second),
+			ResponseTTL:     getEnvAsDuration("CACHE_RESPONSE_TTL", time.Minute),
+			RoleTTL:         getEnvAsDuration("CACHE_ROLE_TTL", time.Minute),
 		},
+		Audit: AuditConfig{
+			AdminRoles: getEnvAsList("AUDIT_ADMIN_ROLES"),
+		},
+		Masking: MaskingConfig{
+			HashKey:         getEnv("MASKING_HASH_KEY", ""),
+			PrivilegedRoles: getEnvAsList("MASKING_PRIVILEGED_ROLES"),
+		},
+		Tenant: TenantConfig{
+			Enabled:         getEnv("TENANT_ENABLED", "false") == "true",
+			Header:          getEnv("TENANT_HEADER", "X-Tenant-ID"),
+			Default:         getEnv("TENANT_DEFAULT", "default"),
+			SuperAdminRoles: getEnvAsList("TENANT_SUPER_ADMIN_ROLES"),
+			CacheTTL:        getEnvAsDuration("TENANT_CACHE_TTL", time.Minute),
+		},
+		GRPC: GRPCConfig{
+			Enabled:      getEnv("GRPC_ENABLED", "false") == "true",
+			Port:         getEnvAsInt("GRPC_PORT", 9090),
+			CertFile:     getEnv("GRPC_TLS_CERT_FILE", ""),
+			KeyFile:      getEnv("GRPC_TLS_KEY_FILE", ""),
+			ClientCAFile: getEnv("GRPC_TLS_CLIENT_CA_FILE", ""),
+			Insecure:     getEnv("GRPC_INSECURE", "false") == "true",
+		},
+		CORS: CORSConfig{
+			AllowedOrigins:   getEnvAsList("CORS_ALLOWED_ORIGINS"),
+			AllowedMethods:   getEnvAsList("CORS_ALLOWED_METHODS"),
+			AllowedHeaders:   getEnvAsList("CORS_ALLOWED_HEADERS"),
+			ExposedHeaders:   getEnvAsList("CORS_EXPOSED_HEADERS"),
+			AllowCredentials: getEnv("CORS_ALLOW_CREDENTIALS", "false") == "true",
+			MaxAge:           getEnvAsDuration("CORS_MAX_AGE", 24*time.Hour),
+			EmbedPaths:       getEnvAsList("CORS_EMBED_PATHS"),
+			EmbedOrigins:     getEnvAsList("CORS_EMBED_ALLOWED_ORIGINS"),
+		},
+		IPAccess: IPAccessConfig{
+			Paths: getEnvAsList("IP_ACCESS_PATHS"),
+			Allow: getEnvAsList("IP_ACCESS_ALLOW"),
+			Deny:  getEnvAsList("IP_ACCESS_DENY"),
+		},
+		Developer: DeveloperConfig{
+			FreeRateLimit:      getEnvAsInt("DEVELOPER_FREE_RATE_LIMIT", 60),
+			PartnerRateLimit:   getEnvAsInt("DEVELOPER_PARTNER_RATE_LIMIT", 600),
+			RateWindow:         getEnvAsDuration("DEVELOPER_RATE_WINDOW", time.Minute),
+			MaxApplications:    getEnvAsInt("DEVELOPER_MAX_APPLICATIONS", 5),
+			MaxKeys:            getEnvAsInt("DEVELOPER_MAX_KEYS", 3),
+			RotationGrace:      getEnvAsDuration("DEVELOPER_ROTATION_GRACE", 24*time.Hour),
+			KeyCacheTTL:        getEnvAsDuration("DEVELOPER_KEY_CACHE_TTL", time.Minute),
+			UsageFlushInterval: getEnvAsDuration("DEVELOPER_USAGE_FLUSH_INTERVAL", time.Minute),
+		},
+		Preview: PreviewConfig{
+			Enabled:    getEnv("PREVIEW_ENABLED", "true") == "true",
+			RenderURL:  getEnv("PREVIEW_RENDER_URL", ""),
+			Timeout:    getEnvAsDuration("PREVIEW_TIMEOUT", 10*time.Second),
+			Width:      getEnvAsInt("PREVIEW_WIDTH", 1200),
+			Height:     getEnvAsInt("PREVIEW_HEIGHT", 630),
+			SampleRows: getEnvAsInt("PREVIEW_SAMPLE_ROWS", 50),
+			MaxSeries:  getEnvAsInt("PREVIEW_MAX_SERIES", 3),
+			Brand:      getEnv("PREVIEW_BRAND", "Portal Data"),
+			Color:      getEnv("PREVIEW_COLOR", "#1d4ed8"),
+			QueueSize:  getEnvAsInt("PREVIEW_QUEUE_SIZE", 100),
+		},
+		Moderation: ModerationConfig{
+			Keywords:       getEnvAsList("MODERATION_KEYWORDS"),
+			BlockedDomains: getEnvAsList("MODERATION_BLOCKED_DOMAINS"),
+			MaxLinks:       getEnvAsInt("MODERATION_MAX_LINKS", 2),
+			HoldAll:        getEnv("MODERATION_HOLD_ALL", "false") == "true",
+			ModeratorRoles: getEnvAsList("MODERATION_ROLES"),
+		},
+		Highlight: HighlightConfig{
+			Max:            getEnvAsInt("HIGHLIGHT_MAX", 8),
+			DefaultTTL:     getEnvAsDuration("HIGHLIGHT_DEFAULT_TTL", 0),
+			ExpiryInterval: getEnvAsDuration("HIGHLIGHT_EXPIRY_INTERVAL", time.Minute),
+		},
+		Trash: TrashConfig{
+			Retention:     getEnvAsDuration("TRASH_RETENTION", 30*24*time.Hour),
+			PurgeInterval: getEnvAsDuration("TRASH_PURGE_INTERVAL", time.Hour),
+		},
+		Cleanup: CleanupConfig{
+			TokenInterval:         getEnvAsDuration("CLEANUP_TOKEN_INTERVAL", time.Hour),
+			NotificationInterval:  getEnvAsDuration("CLEANUP_NOTIFICATION_INTERVAL", 24*time.Hour),
+			NotificationRetention: getEnvAsDuration("CLEANUP_NOTIFICATION_RETENTION", 90*24*time.Hour),
+		},
+		Widget: WidgetConfig{
+			MaxAge:      getEnvAsDuration("WIDGET_MAX_AGE", 5*time.Minute),
+			LatestLimit: getEnvAsInt("WIDGET_LATEST_LIMIT", 5),
+		},
+		Deprecation: DeprecationConfig{
+			UsageFlushInterval: getEnvAsDuration("DEPRECATION_USAGE_FLUSH_INTERVAL", time.Minute),
+		},
+		Report: ReportConfig{
+			Formats:          getEnvAsList("REPORT_FORMATS"),
+			CheckInterval:    getEnvAsDuration("REPORT_CHECK_INTERVAL", time.Hour),
+			TopOrganizations: getEnvAsInt("REPORT_TOP_ORGANIZATIONS", 10),
+			QueueSize:        getEnvAsInt("REPORT_QUEUE_SIZE", 20),
+			Visualizations:   getEnvAsList("REPORT_VISUALIZATIONS"),
+		},
+		License: LicenseConfig{
+			Name:  getEnv("LICENSE_NAME", "CC-BY-4.0"),
+			Title: getEnv("LICENSE_TITLE", "Creative Commons Attribution 4.0"),
+			URL:   getEnv("LICENSE_URL", "https://creativecommons.org/licenses/by/4.0/"),
+		},
+		Package: PackageConfig{
+			MaxRows:      getEnvAsInt("PACKAGE_MAX_ROWS", 1000000),
+			MaxFileBytes: int64(getEnvAsInt("PACKAGE_MAX_FILE_BYTES", 0)),
+		},
+		LinkCheck: LinkCheckConfig{
+			Interval: getEnvAsDuration("LINK_CHECK_INTERVAL", time.Hour),
+			Recheck:  getEnvAsDuration("LINK_CHECK_RECHECK", 24*time.Hour),
+			Batch:    getEnvAsInt("LINK_CHECK_BATCH", 50),
+			Timeout:  getEnvAsDuration("LINK_CHECK_TIMEOUT", 10*time.Second),
+		},
+		Recovery: RecoveryConfig{
+			URL:        getEnv("PASSWORD_RESET_URL", "http://localhost:3000/reset-password"),
+			TTL:        getEnvAsDuration("PASSWORD_RESET_TTL", time.Hour),
+			RateLimit:  getEnvAsInt("PASSWORD_RESET_RATE_LIMIT", 5),
+			RateWindow: getEnvAsDuration("PASSWORD_RESET_RATE_WINDOW", time.Hour),
+		},
+		Password: PasswordPolicyConfig{
+			MinLength:  getEnvAsInt("PASSWORD_MIN_LENGTH", 8),
+			MinClasses: getEnvAsInt("PASSWORD_MIN_CLASSES", 2),
+			BanCommon:  getEnv("PASSWORD_BAN_COMMON", "true") == "true",
+			History:    getEnvAsInt("PASSWORD_HISTORY", 3),
+		},
+		Signup: SignupConfig{
+			RequireVerification: getEnv("EMAIL_VERIFICATION_REQUIRED", "false") == "true",
+			VerifyURL:           getEnv("EMAIL_VERIFICATION_URL", "http://localhost:3000/verify-email"),
+			VerificationTTL:     getEnvAsDuration("EMAIL_VERIFICATION_TTL", 48*time.Hour),
+		},
+		AuthCaptcha: CaptchaConfig{
+			Provider:  getEnv("AUTH_CAPTCHA_PROVIDER", "recaptcha"),
+			VerifyURL: getEnv("AUTH_CAPTCHA_VERIFY_URL", ""),
+			Secret:    getEnv("AUTH_CAPTCHA_SECRET", ""),
+		},
+		Privacy: PrivacyConfig{
+			Mode:      getEnv("PRIVACY_MODE", "standard"),
+			PolicyURL: getEnv("PRIVACY_POLICY_URL", ""),
+		},
+		Analytics: AnalyticsConfig{
+			HarvesterAgents: getEnvAsList("ANALYTICS_HARVESTER_AGENTS"),
+		},
+		RateLimit: RateLimitConfig{
+			Store:  getEnv("RATE_LIMIT_STORE", "memory"),
+			Groups: getRateLimitGroups(),
+		},
+		OIDC: OIDCConfig{
+			RedirectURL: getEnv("OIDC_REDIRECT_URL", "http://localhost:8080/api/v1/auth/oidc/{provider}/callback"),
+			LoginTTL:    getEnvAsDuration("OIDC_LOGIN_TTL", 10*time.Minute),
+			Providers:   getOIDCProviders(),
+		},
+	}
+	if cfg.App.LogLevel == "" {
+		cfg.App.LogLevel = "info"
+		if cfg.App.Debug {
+			cfg.App.LogLevel = "debug"
+		}
+	}
+	if len(cfg.Audit.AdminRoles) == 0 {
+		cfg.Audit.AdminRoles = []string{"admin"}
+	}
+	if len(cfg.Masking.PrivilegedRoles) == 0 {
+		cfg.Masking.PrivilegedRoles = cfg.Audit.AdminRoles
+	}
+	if len(cfg.Moderation.ModeratorRoles) == 0 {
+		cfg.Moderation.ModeratorRoles = cfg.Audit.AdminRoles
+	}
+	if len(cfg.Tenant.SuperAdminRoles) == 0 {
+		cfg.Tenant.SuperAdminRoles = []string{"superadmin"}
+	}
+	if len(cfg.Server.CompressionTypes) == 0 {
+		cfg.Server.CompressionTypes = []string{"application/json", "application/problem+json", "text/csv", "text/html", "text/plain"}
+	}
+	if cfg.AuthCaptcha.VerifyURL == "" {
+		cfg.AuthCaptcha.VerifyURL = captchaVerifyURLs[cfg.AuthCaptcha.Provider]
+	}
+	if len(cfg.Analytics.HarvesterAgents) == 0 {
+		cfg.Analytics.HarvesterAgents = []string{"harvest", "ckan", "dcat", "bot", "crawler", "spider"}
+	}
+	if cfg.Package.MaxFileBytes == 0 {
+		cfg.Package.MaxFileBytes = cfg.Server.MaxUploadBytes
+	}
+	if len(cfg.IPAccess.Paths) == 0 {
+		cfg.IPAccess.Paths = []string{"/users", "/settings", "/integrations", "/admin/"}
+	}
+	cfg.CORS.setDefaults()
+
+	// Resolve secret references before validating the values they hold
+	if err := cfg.resolveSecrets(); err != nil {
+		return nil, err
 	}
 
 	// Validate required configuration
@@ -130,25 +1034,256 @@ func Load() (*Config, error) {
 	return cfg, nil
 }
 
-// Validate validates the configuration
+// ValidationError reports every invalid value of a configuration
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%d invalid configuration values:\n  - %s", len(e.Problems), strings.Join(e.Problems, "\n  - "))
+}
+
+// Validate validates the configuration, returning a ValidationError listing
+// every problem found
 func (c *Config) Validate() error {
-	if c.Database.Host == "" {
-		return fmt.Errorf("database host is required")
+	var problems []string
+	require := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
 	}
-	if c.Database.User == "" {
-		return fmt.Errorf("database user is required")
+	production := c.App.Environment == "production"
+
+	switch c.App.LogLevel {
+	case "debug", "info", "warn", "error":
+	default:
+		problems = append(problems, fmt.Sprintf("APP_LOG_LEVEL must be debug, info, warn or error, got %q", c.App.LogLevel))
 	}
-	if c.Database.Database == "" {
-		return fmt.Errorf("database name is required")
+
+	require(c.Database.Host != "", "DB_HOST is required")
+	require(c.Database.Port > 0, "DB_PORT must be positive")
+	require(c.Database.User != "", "DB_USER is required")
+	require(c.Database.Database != "", "DB_NAME is required")
+	require(len(c.Database.ReplicaDSNs) == 0 || c.Database.ReplicaCheckInterval > 0, "DB_REPLICA_CHECK_INTERVAL must be positive")
+	require(c.Database.StatementTimeout >= 0, "DB_STATEMENT_TIMEOUT must not be negative")
+
+	require(c.JWT.Secret != "", "JWT_SECRET is required")
+	require(!production || c.JWT.Secret != "change-me-in-production", "JWT_SECRET must be set in production")
+	require(c.JWT.AccessTokenExpiry > 0 && c.JWT.RefreshTokenExpiry > 0, "JWT_ACCESS_EXPIRY and JWT_REFRESH_EXPIRY must be positive")
+	require(c.JWT.ImpersonationExpiry > 0 && c.JWT.ImpersonationExpiry <= time.Hour, "JWT_IMPERSONATION_EXPIRY must be positive and at most 1h")
+
+	require(c.MinIO.Endpoint != "", "MINIO_ENDPOINT is required")
+	require(c.MinIO.Bucket != "", "MINIO_BUCKET is required")
+	require(c.MinIO.AccessKey != "" && c.MinIO.SecretKey != "", "MINIO_ACCESS_KEY and MINIO_SECRET_KEY are required")
+	require(!production || c.MinIO.SecretKey != "minioadmin", "MINIO_SECRET_KEY must be set in production")
+	require(c.MinIO.Timeout > 0 && c.MinIO.UploadTimeout > 0, "MINIO_TIMEOUT and MINIO_UPLOAD_TIMEOUT must be positive")
+
+	require(c.Secrets.Key != "" || !production, "SECRETS_ENCRYPTION_KEY must be set in production")
+	require(c.Masking.HashKey != "" || !production, "MASKING_HASH_KEY must be set in production")
+
+	if c.Server.LegacyRoutesSunset != "" {
+		_, err := time.Parse("2006-01-02", c.Server.LegacyRoutesSunset)
+		require(err == nil, "SERVER_LEGACY_ROUTES_SUNSET must be a YYYY-MM-DD date, got %q", c.Server.LegacyRoutesSunset)
 	}
-	if c.JWT.Secret == "" || c.JWT.Secret == "change-me-in-production" {
-		if c.App.Environment == "production" {
-			return fmt.Errorf("JWT secret must be set in production")
-		}
+	require(c.Server.Port > 0 && c.Server.Port < 65536, "SERVER_PORT must be a port number, got %d", c.Server.Port)
+	require(c.Server.MaxBodyBytes > 0, "SERVER_MAX_BODY_BYTES must be positive")
+	require(c.Server.MaxUploadBytes > 0, "SERVER_MAX_UPLOAD_BYTES must be positive")
+	require(c.Server.MaxImportBytes > 0, "SERVER_MAX_IMPORT_BYTES must be positive")
+	require(c.Server.CompressionLevel >= 0 && c.Server.CompressionLevel <= 9, "SERVER_COMPRESSION_LEVEL must be between 0 and 9")
+	require(c.Server.HealthCheckTimeout > 0, "SERVER_HEALTH_CHECK_TIMEOUT must be positive")
+	require(c.Server.RequestTimeout > 0, "SERVER_REQUEST_TIMEOUT must be positive")
+	require(c.Package.MaxRows > 0, "PACKAGE_MAX_ROWS must be positive")
+	require(c.Package.MaxFileBytes > 0, "PACKAGE_MAX_FILE_BYTES must be positive")
+	for _, network := range c.Server.TrustedProxies {
+		require(validNetwork(network), "FORWARDED_ALLOW_IPS must list the CIDR ranges or IP addresses of trusted proxies, got %q", network)
+	}
+
+	require(c.SecretStore.VaultAddr == "" || c.SecretStore.VaultToken != "", "VAULT_TOKEN is required when VAULT_ADDR is set")
+	require(c.SecretStore.AWSRegion == "" || (c.SecretStore.AWSAccessKeyID != "" && c.SecretStore.AWSSecretAccessKey != ""),
+		"AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are required when AWS_REGION is set")
+	require(c.Feedback.RateWindow > 0, "FEEDBACK_RATE_WINDOW must be positive")
+	require(c.Desk.PublicRateWindow > 0, "DESK_PUBLIC_RATE_WINDOW must be positive")
+	require(c.Developer.RateWindow > 0, "DEVELOPER_RATE_WINDOW must be positive")
+	require(c.Developer.MaxApplications > 0 && c.Developer.MaxKeys > 0, "DEVELOPER_MAX_APPLICATIONS and DEVELOPER_MAX_KEYS must be positive")
+	require(c.Developer.RotationGrace >= 0, "DEVELOPER_ROTATION_GRACE must not be negative")
+	require(c.Developer.UsageFlushInterval > 0, "DEVELOPER_USAGE_FLUSH_INTERVAL must be positive")
+	require(c.Preview.Width > 0 && c.Preview.Height > 0, "PREVIEW_WIDTH and PREVIEW_HEIGHT must be positive")
+	require(c.Preview.SampleRows > 0 && c.Preview.MaxSeries > 0, "PREVIEW_SAMPLE_ROWS and PREVIEW_MAX_SERIES must be positive")
+	require(c.Preview.Timeout > 0, "PREVIEW_TIMEOUT must be positive")
+	require(c.Moderation.MaxLinks >= 0, "MODERATION_MAX_LINKS must not be negative")
+	require(c.Highlight.Max > 0, "HIGHLIGHT_MAX must be positive")
+	require(c.Highlight.DefaultTTL >= 0, "HIGHLIGHT_DEFAULT_TTL must not be negative")
+	require(c.Highlight.ExpiryInterval > 0, "HIGHLIGHT_EXPIRY_INTERVAL must be positive")
+	require(c.Trash.Retention > 0, "TRASH_RETENTION must be positive")
+	require(c.Trash.PurgeInterval >= 0, "TRASH_PURGE_INTERVAL must not be negative")
+	require(c.Cleanup.TokenInterval >= 0, "CLEANUP_TOKEN_INTERVAL must not be negative")
+	require(c.Cleanup.NotificationInterval >= 0, "CLEANUP_NOTIFICATION_INTERVAL must not be negative")
+	require(c.Cleanup.NotificationRetention > 0, "CLEANUP_NOTIFICATION_RETENTION must be positive")
+	require(c.Widget.MaxAge >= 0, "WIDGET_MAX_AGE must not be negative")
+	require(c.Widget.LatestLimit > 0 && c.Widget.LatestLimit <= 20, "WIDGET_LATEST_LIMIT must be between 1 and 20")
+	for _, contentType := range []string{"dataset", "visualization", "publication"} {
+		require(c.Review.SLA[contentType] > 0, "REVIEW_SLA must set a positive SLA for "+contentType)
+	}
+	require(c.Review.Warning >= 0, "REVIEW_SLA_WARNING must not be negative")
+	require(c.Deprecation.UsageFlushInterval > 0, "DEPRECATION_USAGE_FLUSH_INTERVAL must be positive")
+	for _, format := range c.Report.Formats {
+		require(format == "pdf" || format == "xlsx", "REPORT_FORMATS must list pdf or xlsx, got "+format)
+	}
+	require(c.Report.CheckInterval >= 0, "REPORT_CHECK_INTERVAL must not be negative")
+	require(c.Report.TopOrganizations > 0 && c.Report.QueueSize > 0, "REPORT_TOP_ORGANIZATIONS and REPORT_QUEUE_SIZE must be positive")
+	require(c.LinkCheck.Interval >= 0, "LINK_CHECK_INTERVAL must not be negative")
+	require(c.LinkCheck.Recheck > 0, "LINK_CHECK_RECHECK must be positive")
+	require(c.LinkCheck.Batch > 0, "LINK_CHECK_BATCH must be positive")
+	require(c.LinkCheck.Timeout > 0, "LINK_CHECK_TIMEOUT must be positive")
+	require(c.Recovery.TTL > 0, "PASSWORD_RESET_TTL must be positive")
+	require(c.Recovery.RateWindow > 0, "PASSWORD_RESET_RATE_WINDOW must be positive")
+	require(c.Password.MinLength >= 8 && c.Password.MinLength <= 72, "PASSWORD_MIN_LENGTH must be between 8 and 72")
+	require(c.Password.MinClasses >= 0 && c.Password.MinClasses <= 4, "PASSWORD_MIN_CLASSES must be between 0 and 4")
+	require(c.Password.History >= 0 && c.Password.History <= 24, "PASSWORD_HISTORY must be between 0 and 24")
+	require(c.Signup.VerificationTTL > 0, "EMAIL_VERIFICATION_TTL must be positive")
+	require(!c.AuthCaptcha.Enabled() || c.AuthCaptcha.VerifyURL != "",
+		"AUTH_CAPTCHA_PROVIDER must be recaptcha, hcaptcha or turnstile unless AUTH_CAPTCHA_VERIFY_URL is set, got %q", c.AuthCaptcha.Provider)
+	require(c.Events.Driver == "" || c.Events.URL != "", "EVENTS_URL is required when EVENTS_DRIVER is set")
+	require(c.Events.OutboxPollInterval > 0, "EVENTS_OUTBOX_POLL_INTERVAL must be positive")
+	require(c.Events.OutboxBatchSize > 0, "EVENTS_OUTBOX_BATCH_SIZE must be positive")
+	require(c.Events.OutboxRetention > 0, "EVENTS_OUTBOX_RETENTION must be positive")
+	switch c.Search.Backend {
+	case "postgres":
+	case "opensearch":
+		require(c.Search.URL != "", "SEARCH_URL is required for the opensearch backend")
+	default:
+		problems = append(problems, fmt.Sprintf("SEARCH_BACKEND %q is not supported", c.Search.Backend))
+	}
+	switch c.Cache.Driver {
+	case "", "memory", "redis":
+	default:
+		problems = append(problems, fmt.Sprintf("CACHE_DRIVER %q is not supported", c.Cache.Driver))
+	}
+	switch c.Privacy.Mode {
+	case "standard", "strict":
+	default:
+		problems = append(problems, fmt.Sprintf("PRIVACY_MODE %q is not supported", c.Privacy.Mode))
+	}
+
+	switch c.RateLimit.Store {
+	case "memory", "redis":
+	default:
+		problems = append(problems, fmt.Sprintf("RATE_LIMIT_STORE %q is not supported", c.RateLimit.Store))
+	}
+	for _, group := range c.RateLimit.Groups {
+		key := "RATE_LIMIT_" + strings.ToUpper(group.Name)
+		require(len(group.Paths) > 0, key+"_PATHS is required")
+		require(group.Limit > 0, key+"_LIMIT must be positive")
+		require(group.Window > 0, key+"_WINDOW must be positive")
+	}
+
+	require(c.OIDC.LoginTTL > 0, "OIDC_LOGIN_TTL must be positive")
+	for _, provider := range c.OIDC.Providers {
+		key := "OIDC_" + strings.ToUpper(provider.Name)
+		require(provider.Issuer != "", key+"_ISSUER is required")
+		require(provider.ClientID != "", key+"_CLIENT_ID is required")
+		require(!provider.Provision || (provider.OrganizationID != "" && provider.RoleID != ""),
+			key+"_ORGANIZATION_ID and "+key+"_ROLE_ID are required when "+key+"_PROVISION is set")
+	}
+
+	if c.Tenant.Enabled {
+		require(c.Tenant.Header != "", "TENANT_HEADER is required when TENANT_ENABLED is set")
+		require(c.Tenant.Default != "", "TENANT_DEFAULT is required when TENANT_ENABLED is set")
+		require(c.Tenant.CacheTTL > 0, "TENANT_CACHE_TTL must be positive")
+	}
+
+	for _, origin := range append(c.CORS.AllowedOrigins, c.CORS.EmbedOrigins...) {
+		require(validOrigin(origin), "CORS origins must be * or like https://example.org or https://*.example.org, got %q", origin)
+	}
+	require(!c.CORS.AllowCredentials || !contains(c.CORS.AllowedOrigins, "*"), "CORS_ALLOWED_ORIGINS must list origins rather than * when CORS_ALLOW_CREDENTIALS is set")
+	require(c.CORS.MaxAge >= 0, "CORS_MAX_AGE must not be negative")
+
+	for _, path := range c.IPAccess.Paths {
+		require(strings.HasPrefix(path, "/"), "IP_ACCESS_PATHS must start with /, got %q", path)
+	}
+	for _, network := range append(c.IPAccess.Allow, c.IPAccess.Deny...) {
+		require(validNetwork(network), "IP_ACCESS_ALLOW and IP_ACCESS_DENY must list CIDR ranges like 10.8.0.0/16 or IP addresses, got %q", network)
+	}
+
+	if c.GRPC.Enabled {
+		require(c.GRPC.Port > 0 && c.GRPC.Port < 65536 && c.GRPC.Port != c.Server.Port, "GRPC_PORT must be a port number other than SERVER_PORT, got %d", c.GRPC.Port)
+		require(c.GRPC.Insecure || (c.GRPC.CertFile != "" && c.GRPC.KeyFile != "" && c.GRPC.ClientCAFile != ""),
+			"GRPC_TLS_CERT_FILE, GRPC_TLS_KEY_FILE and GRPC_TLS_CLIENT_CA_FILE are required unless GRPC_INSECURE is set")
+		require(!production || !c.GRPC.Insecure, "GRPC_INSECURE must not be set in production")
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
 	}
 	return nil
 }
 
+// setDefaults fills the lists left unset with the defaults, which let any
+// origin read and write without credentials
+func (c *CORSConfig) setDefaults() {
+	if len(c.AllowedOrigins) == 0 {
+		c.AllowedOrigins = []string{"*"}
+	}
+	if len(c.AllowedMethods) == 0 {
+		c.AllowedMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	}
+	if len(c.AllowedHeaders) == 0 {
+		c.AllowedHeaders = []string{"Content-Type", "Authorization", "Accept-Language", "Idempotency-Key", "If-None-Match"}
+	}
+	if len(c.ExposedHeaders) == 0 {
+		c.ExposedHeaders = []string{"Content-Length", "Content-Type", "X-Request-ID"}
+	}
+	if len(c.EmbedPaths) == 0 {
+		c.EmbedPaths = []string{"/visualizations", "/visualizations/*", "/visualizations/*/*", "/widgets/*", "/widgets/*/*/*"}
+	}
+	if len(c.EmbedOrigins) == 0 {
+		c.EmbedOrigins = []string{"*"}
+	}
+}
+
+// validOrigin reports whether origin is "*" or a scheme and host, possibly
+// with a port and a leading wildcard label
+func validOrigin(origin string) bool {
+	if origin == "*" {
+		return true
+	}
+	scheme, host, ok := strings.Cut(origin, "://")
+	if !ok || scheme == "" || host == "" || strings.ContainsAny(host, "/?#") {
+		return false
+	}
+	host = strings.TrimPrefix(host, "*.")
+	return host != "" && !strings.Contains(host, "*")
+}
+
+func validNetwork(network string) bool {
+	if strings.Contains(network, "/") {
+		_, _, err := net.ParseCIDR(network)
+		return err == nil
+	}
+	return net.ParseIP(network) != nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// LegacyRoutesSunsetTime returns the date legacy routes stop working, or the
+// zero time when none is set
+func (c *ServerConfig) LegacyRoutesSunsetTime() time.Time {
+	sunset, _ := time.Parse("2006-01-02", c.LegacyRoutesSunset)
+	return sunset
+}
+
+// Strict reports whether the strict privacy mode is on
+func (c *PrivacyConfig) Strict() bool {
+	return c.Mode == "strict"
+}
+
 // DSN returns the PostgreSQL Data Source Name
 func (c *DatabaseConfig) DSN() string {
 	return fmt.Sprintf(
@@ -191,3 +1326,89 @@ func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 	}
 	return defaultValue
 }
+
+// getEnvAsList parses "value,value2", skipping empty values
+func getEnvAsList(key string) []string {
+	var result []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// getOIDCProviders reads the providers OIDC_PROVIDERS names from the
+// OIDC_<NAME>_* keys. Google needs no issuer.
+func getOIDCProviders() []OIDCProviderConfig {
+	var providers []OIDCProviderConfig
+	for _, name := range getEnvAsList("OIDC_PROVIDERS") {
+		name = strings.ToLower(name)
+		key := "OIDC_" + strings.ToUpper(name)
+		defaultIssuer := ""
+		if name == "google" {
+			defaultIssuer = "https://accounts.google.com"
+		}
+		scopes := getEnvAsList(key + "_SCOPES")
+		if len(scopes) == 0 {
+			scopes = []string{"openid", "email", "profile"}
+		}
+		providers = append(providers, OIDCProviderConfig{
+			Name:           name,
+			Issuer:         getEnv(key+"_ISSUER", defaultIssuer),
+			ClientID:       getEnv(key+"_CLIENT_ID", ""),
+			ClientSecret:   getEnv(key+"_CLIENT_SECRET", ""),
+			Scopes:         scopes,
+			TrustEmail:     getEnv(key+"_TRUST_EMAIL", "false") == "true",
+			Provision:      getEnv(key+"_PROVISION", "false") == "true",
+			OrganizationID: getEnv(key+"_ORGANIZATION_ID", ""),
+			RoleID:         getEnv(key+"_ROLE_ID", ""),
+		})
+	}
+	return providers
+}
+
+// getRateLimitGroups reads the groups RATE_LIMIT_GROUPS names from the
+// RATE_LIMIT_<NAME>_* keys
+func getRateLimitGroups() []RateLimitGroupConfig {
+	var groups []RateLimitGroupConfig
+	for _, name := range getEnvAsList("RATE_LIMIT_GROUPS") {
+		name = strings.ToLower(name)
+		key := "RATE_LIMIT_" + strings.ToUpper(name)
+		groups = append(groups, RateLimitGroupConfig{
+			Name:   name,
+			Paths:  getEnvAsList(key + "_PATHS"),
+			Limit:  getEnvAsInt(key+"_LIMIT", 0),
+			Window: getEnvAsDuration(key+"_WINDOW", time.Minute),
+		})
+	}
+	return groups
+}
+
+// getEnvAsMap parses "key=value,key2=value2"
+func getEnvAsMap(key string) map[string]string {
+	result := make(map[string]string)
+	for _, pair := range strings.Split(os.Getenv(key), ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if ok && k != "" {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// getEnvAsDurationMap parses "key=duration,key2=duration2", e.g. "high=24h"
+func getEnvAsDurationMap(key string, defaultValue map[string]time.Duration) map[string]time.Duration {
+	values := getEnvAsMap(key)
+	if len(values) == 0 {
+		return defaultValue
+	}
+
+	result := make(map[string]time.Duration, len(values))
+	for k, v := range values {
+		if duration, err := time.ParseDuration(v); err == nil {
+			result[k] = duration
+		}
+	}
+	return result
+}
@@ -0,0 +1,243 @@
+package config
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// isolate unsets keys for the test and restores them, with the values files
+// set, once it is done
+func isolate(t *testing.T, keys ...string) {
+	t.Helper()
+	for _, key := range keys {
+		if value, ok := os.LookupEnv(key); ok {
+			t.Cleanup(func() { os.Setenv(key, value) })
+		} else {
+			t.Cleanup(func() { os.Unsetenv(key) })
+		}
+		os.Unsetenv(key)
+	}
+	saved := fileValues
+	fileValues = make(map[string]string)
+	t.Cleanup(func() { fileValues = saved })
+}
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "portal.env")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Expected to write the config file, got %v", err)
+	}
+	return path
+}
+
+// Test validation reports every invalid value at once
+func TestValidate_ReportsEveryProblem(t *testing.T) {
+	isolate(t, "CONFIG_FILE")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Expected the defaults to be valid, got %v", err)
+	}
+
+	cfg.App.Environment = "production"
+	cfg.Database.Host = ""
+	cfg.MinIO.Endpoint = ""
+	cfg.App.LogLevel = "verbose"
+
+	var validationErr *ValidationError
+	if err := cfg.Validate(); !errors.As(err, &validationErr) {
+		t.Fatalf("Expected a ValidationError, got %v", err)
+	}
+	for _, want := range []string{"APP_LOG_LEVEL", "DB_HOST", "JWT_SECRET", "MINIO_ENDPOINT", "MINIO_SECRET_KEY", "SECRETS_ENCRYPTION_KEY", "MASKING_HASH_KEY"} {
+		if !strings.Contains(validationErr.Error(), want) {
+			t.Errorf("Expected the report to name %s, got %s", want, validationErr.Error())
+		}
+	}
+}
+
+// Test CORS origins must be well formed and listed when credentials are sent
+func TestValidate_CORS(t *testing.T) {
+	isolate(t, "CONFIG_FILE", "CORS_ALLOWED_ORIGINS", "CORS_ALLOW_CREDENTIALS")
+	os.Setenv("CORS_ALLOWED_ORIGINS", "https://data.example.org,https://*.example.go.id")
+	os.Setenv("CORS_ALLOW_CREDENTIALS", "true")
+	if _, err := Load(); err != nil {
+		t.Fatalf("Expected listed origins with credentials to be valid, got %v", err)
+	}
+
+	for origins, want := range map[string]string{
+		"*":                    "CORS_ALLOW_CREDENTIALS",
+		"data.example.org":     `"data.example.org"`,
+		"https://example.org/": `"https://example.org/"`,
+		"https://a.*.org":      `"https://a.*.org"`,
+	} {
+		os.Setenv("CORS_ALLOWED_ORIGINS", origins)
+		if _, err := Load(); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected %s to be rejected naming %s, got %v", origins, want, err)
+		}
+	}
+}
+
+// Test the captcha of the auth endpoints verifies with its provider's
+// endpoint, unless another is set for unknown providers
+func TestValidate_AuthCaptcha(t *testing.T) {
+	isolate(t, "CONFIG_FILE", "AUTH_CAPTCHA_PROVIDER", "AUTH_CAPTCHA_VERIFY_URL", "AUTH_CAPTCHA_SECRET")
+	os.Setenv("AUTH_CAPTCHA_PROVIDER", "turnstile")
+	os.Setenv("AUTH_CAPTCHA_SECRET", "s3cret")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Expected a known provider to be valid, got %v", err)
+	}
+	if !cfg.AuthCaptcha.Enabled() || cfg.AuthCaptcha.VerifyURL != captchaVerifyURLs["turnstile"] {
+		t.Errorf("Expected the endpoint of Turnstile, got %+v", cfg.AuthCaptcha)
+	}
+
+	os.Setenv("AUTH_CAPTCHA_PROVIDER", "friendly")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "AUTH_CAPTCHA_PROVIDER") {
+		t.Errorf("Expected an unknown provider to be rejected, got %v", err)
+	}
+	os.Setenv("AUTH_CAPTCHA_VERIFY_URL", "https://captcha.example.org/siteverify")
+	if _, err := Load(); err != nil {
+		t.Errorf("Expected an unknown provider with an endpoint to be valid, got %v", err)
+	}
+}
+
+// Test rate limit groups are read from their keys and need paths and a limit
+func TestValidate_RateLimitGroups(t *testing.T) {
+	isolate(t, "CONFIG_FILE", "RATE_LIMIT_STORE", "RATE_LIMIT_GROUPS", "RATE_LIMIT_SEARCH_PATHS", "RATE_LIMIT_SEARCH_LIMIT", "RATE_LIMIT_SEARCH_WINDOW")
+	os.Setenv("RATE_LIMIT_GROUPS", "search")
+	os.Setenv("RATE_LIMIT_SEARCH_PATHS", "/search,/datasets/search")
+	os.Setenv("RATE_LIMIT_SEARCH_LIMIT", "30")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Expected a complete group to be valid, got %v", err)
+	}
+	if group := cfg.RateLimit.Groups[0]; group.Name != "search" || len(group.Paths) != 2 || group.Limit != 30 || group.Window != time.Minute {
+		t.Errorf("Expected the search group with a minute window, got %+v", group)
+	}
+
+	os.Unsetenv("RATE_LIMIT_SEARCH_LIMIT")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "RATE_LIMIT_SEARCH_LIMIT") {
+		t.Errorf("Expected a group without a limit to be rejected, got %v", err)
+	}
+	os.Setenv("RATE_LIMIT_SEARCH_LIMIT", "30")
+	os.Setenv("RATE_LIMIT_STORE", "memcached")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "RATE_LIMIT_STORE") {
+		t.Errorf("Expected an unknown store to be rejected, got %v", err)
+	}
+}
+
+// Test the environment overrides the config file and flags override both
+func TestLoadWith_Layers(t *testing.T) {
+	isolate(t, "CONFIG_FILE", "SERVER_PORT", "DB_NAME", "APP_LOG_LEVEL")
+	file := writeFile(t, "SERVER_PORT=9000\nDB_NAME=from_file\nAPP_LOG_LEVEL=warn\n")
+	os.Setenv("DB_NAME", "from_env")
+	os.Setenv("APP_LOG_LEVEL", "error")
+
+	cfg, err := LoadWith(Options{File: file, Flags: map[string]string{"APP_LOG_LEVEL": "debug"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if cfg.Server.Port != 9000 {
+		t.Errorf("Expected the port of the file, got %d", cfg.Server.Port)
+	}
+	if cfg.Database.Database != "from_env" {
+		t.Errorf("Expected the database name of the environment, got %s", cfg.Database.Database)
+	}
+	if cfg.App.LogLevel != "debug" {
+		t.Errorf("Expected the log level of the flag, got %s", cfg.App.LogLevel)
+	}
+}
+
+// Test -set flags are parsed as overrides
+func TestParseFlags(t *testing.T) {
+	opts, err := ParseFlags("server", []string{"-config", "portal.env", "-set", "SERVER_PORT=9090", "-set", "APP_LOG_LEVEL=warn"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if opts.File != "portal.env" || opts.Flags["SERVER_PORT"] != "9090" || opts.Flags["APP_LOG_LEVEL"] != "warn" {
+		t.Errorf("Expected the file and both overrides, got %+v", opts)
+	}
+	if _, err := ParseFlags("server", []string{"-set", "SERVER_PORT"}); err == nil {
+		t.Errorf("Expected an error for an override without a value")
+	}
+	if _, err := ParseFlags("server", []string{"migrate"}); err == nil {
+		t.Errorf("Expected an error for an argument the server does not take")
+	}
+
+	_, rest, err := ParseCommandLine("portalctl", []string{"-set", "DB_NAME=portal", "migrate", "-dir", "migrations", "up"})
+	if err != nil || len(rest) != 4 || rest[0] != "migrate" {
+		t.Errorf("Expected the command and its arguments to be left, got %v and %v", rest, err)
+	}
+}
+
+// Test a reload applies edits of the file to the reloadable values only
+func TestReloader_Reload(t *testing.T) {
+	isolate(t, "CONFIG_FILE", "SERVER_PORT", "APP_LOG_LEVEL", "FEEDBACK_RATE_LIMIT")
+	file := writeFile(t, "APP_LOG_LEVEL=info\nFEEDBACK_RATE_LIMIT=5\n")
+	opts := Options{File: file}
+	cfg, err := LoadWith(opts)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	reloader := NewReloader(cfg, opts)
+	var reloaded *Config
+	reloader.Subscribe(func(cfg *Config) { reloaded = cfg })
+
+	if err := os.WriteFile(file, []byte("APP_LOG_LEVEL=debug\nFEEDBACK_RATE_LIMIT=10\nSERVER_PORT=9000\n"), 0o600); err != nil {
+		t.Fatalf("Expected to edit the config file, got %v", err)
+	}
+	pending, err := reloader.Reload()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if reloaded == nil || reloaded.App.LogLevel != "debug" || reloaded.Feedback.RateLimit != 10 {
+		t.Fatalf("Expected the new log level and rate limit, got %+v", reloaded)
+	}
+	if reloaded.Server.Port != cfg.Server.Port || !pending {
+		t.Errorf("Expected the port change to wait for a restart, got port %d and pending %v", reloaded.Server.Port, pending)
+	}
+
+	if err := os.WriteFile(file, []byte("APP_LOG_LEVEL=verbose\n"), 0o600); err != nil {
+		t.Fatalf("Expected to edit the config file, got %v", err)
+	}
+	if _, err := reloader.Reload(); err == nil {
+		t.Errorf("Expected an invalid log level to be rejected")
+	}
+}
+
+// Test values referring to a secret are replaced by the secret
+func TestLoadWith_ResolvesSecrets(t *testing.T) {
+	isolate(t, "CONFIG_FILE", "VAULT_ADDR", "VAULT_TOKEN", "DB_USER", "DB_PASSWORD", "DB_REPLICA_DSNS")
+	saved := resolver
+	resolver = nil
+	t.Cleanup(func() { resolver = saved })
+
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"lease_id":"database/creds/portal/abc","lease_duration":3600,"renewable":true,"data":{"username":"v-portal","password":"s3cret"}}`))
+	}))
+	defer vault.Close()
+
+	os.Setenv("VAULT_ADDR", vault.URL)
+	os.Setenv("VAULT_TOKEN", "root")
+	os.Setenv("DB_USER", "vault://database/creds/portal#username")
+	os.Setenv("DB_PASSWORD", "vault://database/creds/portal#password")
+	os.Setenv("DB_REPLICA_DSNS", "postgres://replica:5432/portal")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if cfg.Database.User != "v-portal" || cfg.Database.Password != "s3cret" {
+		t.Errorf("Expected the credentials of the secret, got %s and %s", cfg.Database.User, cfg.Database.Password)
+	}
+	if cfg.Database.ReplicaDSNs[0] != "postgres://replica:5432/portal" {
+		t.Errorf("Expected other URLs to be kept, got %s", cfg.Database.ReplicaDSNs[0])
+	}
+}
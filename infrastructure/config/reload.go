@@ -0,0 +1,86 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"syscall"
+
+	"portal-data-backend/infrastructure/logger"
+)
+
+// Reloader reloads configuration on SIGHUP while the server runs. Only the
+// values safe to change in flight are applied, the log level, the rate
+// limits and the password policy; the others take effect on restart.
+type Reloader struct {
+	opts Options
+
+	mu          sync.Mutex
+	current     Config
+	subscribers []func(cfg *Config)
+}
+
+// NewReloader creates a reloader of cfg, loaded from the sources of opts
+func NewReloader(cfg *Config, opts Options) *Reloader {
+	return &Reloader{opts: opts, current: *cfg}
+}
+
+// Subscribe calls fn with the configuration after every reload
+func (r *Reloader) Subscribe(fn func(cfg *Config)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subscribers = append(r.subscribers, fn)
+}
+
+// Reload loads the configuration again and hands its reloadable values to
+// the subscribers. An invalid configuration is rejected as a whole. pending
+// reports whether other values changed, which need a restart.
+func (r *Reloader) Reload() (pending bool, err error) {
+	loaded, err := LoadWith(r.opts)
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.Lock()
+	next := r.current
+	next.App.LogLevel = loaded.App.LogLevel
+	next.Feedback.RateLimit = loaded.Feedback.RateLimit
+	next.Feedback.RateWindow = loaded.Feedback.RateWindow
+	next.Password = loaded.Password
+	pending = !reflect.DeepEqual(next, *loaded)
+	r.current = next
+	subscribers := append([]func(cfg *Config){}, r.subscribers...)
+	r.mu.Unlock()
+
+	for _, fn := range subscribers {
+		fn(&next)
+	}
+	return pending, nil
+}
+
+// Run reloads the configuration on every SIGHUP until ctx is done
+func (r *Reloader) Run(ctx context.Context) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	log := logger.FromContext(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+			pending, err := r.Reload()
+			if err != nil {
+				log.Error("Failed to reload configuration, keeping the current one: %v", err)
+				continue
+			}
+			log.Info("Configuration reloaded")
+			if pending {
+				log.Warn("Configuration changes other than the log level and rate limits take effect on restart")
+			}
+		}
+	}
+}
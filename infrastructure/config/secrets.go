@@ -0,0 +1,112 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"portal-data-backend/infrastructure/secrets"
+)
+
+var (
+	resolverMu sync.Mutex
+	// resolver reads the secrets values refer to. It is kept across loads,
+	// so reloads reuse the secrets, and the leases, read at startup.
+	resolver *secrets.Resolver
+)
+
+// secretResolver returns the resolver of the stores of c, creating it on
+// the first load
+func (c *Config) secretResolver() *secrets.Resolver {
+	resolverMu.Lock()
+	defer resolverMu.Unlock()
+
+	if resolver == nil {
+		providers := make(map[string]secrets.Provider)
+		store := c.SecretStore
+		if store.VaultAddr != "" {
+			providers[secrets.SchemeVault] = secrets.NewVault(store.VaultAddr, store.VaultToken, store.VaultNamespace, store.Timeout)
+		}
+		if store.AWSRegion != "" {
+			providers[secrets.SchemeAWS] = secrets.NewAWSSecretsManager(store.AWSRegion, store.AWSAccessKeyID, store.AWSSecretAccessKey, store.AWSSessionToken, store.Timeout)
+		}
+		resolver = secrets.NewResolver(providers)
+	}
+	return resolver
+}
+
+// secretReference is a value referring to a secret, with how to replace it
+type secretReference struct {
+	ref string
+	set func(value string)
+}
+
+// resolveSecrets replaces the values of c referring to a secret with the
+// secret. Values in lists and maps are resolved as well; the values of
+// SecretStore cannot be references.
+func (c *Config) resolveSecrets() error {
+	var refs []secretReference
+	collectReferences(reflect.ValueOf(c).Elem(), &refs)
+	if len(refs) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*c.SecretStore.Timeout)
+	defer cancel()
+
+	r := c.secretResolver()
+	for _, ref := range refs {
+		value, err := r.Resolve(ctx, ref.ref)
+		if err != nil {
+			return fmt.Errorf("failed to resolve secret: %w", err)
+		}
+		ref.set(value)
+	}
+	return nil
+}
+
+// collectReferences appends the string values below v referring to a secret
+func collectReferences(v reflect.Value, refs *[]secretReference) {
+	switch v.Kind() {
+	case reflect.String:
+		if v.CanSet() && secrets.IsReference(v.String()) {
+			*refs = append(*refs, secretReference{ref: v.String(), set: v.SetString})
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if field.IsExported() && field.Type != reflect.TypeOf(SecretStoreConfig{}) {
+				collectReferences(v.Field(i), refs)
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			collectReferences(v.Index(i), refs)
+		}
+	case reflect.Map:
+		if v.Type().Elem().Kind() != reflect.String {
+			return
+		}
+		for _, key := range v.MapKeys() {
+			if value := v.MapIndex(key).String(); secrets.IsReference(value) {
+				*refs = append(*refs, secretReference{ref: value, set: func(resolved string) {
+					v.SetMapIndex(key, reflect.ValueOf(resolved).Convert(v.Type().Elem()))
+				}})
+			}
+		}
+	}
+}
+
+// RenewSecrets renews the leases of the dynamic secrets values refer to, like
+// database credentials of the Vault database engine, until ctx is done. It
+// returns at once when no value refers to one.
+func RenewSecrets(ctx context.Context) {
+	resolverMu.Lock()
+	r := resolver
+	resolverMu.Unlock()
+
+	if r != nil {
+		r.KeepAlive(ctx)
+	}
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/joho/godotenv"
+)
+
+// Options selects the sources configuration is layered from. Each source
+// overrides the ones before it: defaults, the .env file, File, the
+// environment and Flags.
+type Options struct {
+	// File is a dotenv file of KEY=VALUE lines; CONFIG_FILE names it when
+	// empty
+	File string
+	// Flags are KEY=VALUE overrides given on the command line
+	Flags map[string]string
+}
+
+// ParseFlags parses the command line of the server: -config names the
+// configuration file and each -set KEY=VALUE overrides a value, e.g.
+// -set SERVER_PORT=9090
+func ParseFlags(name string, args []string) (Options, error) {
+	opts, rest, err := ParseCommandLine(name, args)
+	if err != nil {
+		return Options{}, err
+	}
+	if len(rest) > 0 {
+		return Options{}, fmt.Errorf("unexpected argument %q", rest[0])
+	}
+	return opts, nil
+}
+
+// ParseCommandLine parses the configuration flags of ParseFlags up to the
+// first argument that is not a flag and returns the arguments from there on
+func ParseCommandLine(name string, args []string) (Options, []string, error) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	opts := BindFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return Options{}, nil, err
+	}
+	return *opts, flags.Args(), nil
+}
+
+// BindFlags defines the configuration flags of ParseFlags on flags, for
+// commands with flags of their own. The options are set once flags are
+// parsed.
+func BindFlags(flags *flag.FlagSet) *Options {
+	opts := &Options{Flags: make(map[string]string)}
+	flags.StringVar(&opts.File, "config", "", "configuration file of KEY=VALUE lines")
+	flags.Func("set", "override a configuration value, as KEY=VALUE (repeatable)", func(value string) error {
+		key, val, ok := strings.Cut(value, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("expected KEY=VALUE, got %q", value)
+		}
+		opts.Flags[key] = val
+		return nil
+	})
+	return opts
+}
+
+var (
+	sourcesMu sync.Mutex
+	// fileValues are the values files set in the environment, which a
+	// reload may replace as the environment did not set them itself
+	fileValues = make(map[string]string)
+)
+
+// applySources sets the values of the files and flags of opts in the
+// environment, which Load reads. Values the environment sets itself are only
+// overridden by flags.
+func applySources(opts Options) error {
+	sourcesMu.Lock()
+	defer sourcesMu.Unlock()
+
+	values, err := godotenv.Read()
+	if errors.Is(err, fs.ErrNotExist) {
+		values = make(map[string]string)
+	} else if err != nil {
+		return fmt.Errorf("failed to read .env: %w", err)
+	}
+
+	file := opts.File
+	if file == "" {
+		file = lookupEnv("CONFIG_FILE", values)
+	}
+	if file != "" {
+		layer, err := godotenv.Read(file)
+		if err != nil {
+			return fmt.Errorf("failed to read config file %s: %w", file, err)
+		}
+		for key, value := range layer {
+			values[key] = value
+		}
+	}
+
+	// Drop the values of an earlier load the files no longer set
+	for key, value := range fileValues {
+		if _, ok := values[key]; !ok && os.Getenv(key) == value {
+			os.Unsetenv(key)
+		}
+	}
+	applied := make(map[string]string, len(values))
+	for key, value := range values {
+		if _, fromFile := fileValues[key]; !fromFile {
+			if _, set := os.LookupEnv(key); set {
+				continue
+			}
+		}
+		os.Setenv(key, value)
+		applied[key] = value
+	}
+	fileValues = applied
+
+	for key, value := range opts.Flags {
+		os.Setenv(key, value)
+	}
+	return nil
+}
+
+// lookupEnv returns the value of key in the environment, or in the values
+// read from files when the environment does not set it
+func lookupEnv(key string, values map[string]string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		if _, fromFile := fileValues[key]; !fromFile {
+			return value
+		}
+	}
+	return values[key]
+}
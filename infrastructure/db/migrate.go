@@ -0,0 +1,188 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Migration is a schema change read from its <version>_<name>.up.sql and
+// .down.sql files
+type Migration struct {
+	Version uint64
+	Name    string
+	up      string
+	down    string
+}
+
+// Migrator applies the migrations of a directory. It keeps the version in
+// the schema_migrations table of golang-migrate, so the two can be used on
+// the same database.
+type Migrator struct {
+	db         *sqlx.DB
+	migrations []Migration
+}
+
+// NewMigrator reads the migrations of fsys
+func NewMigrator(db *sqlx.DB, fsys fs.FS) (*Migrator, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[uint64]*Migration)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".sql") {
+			continue
+		}
+		base := strings.TrimSuffix(name, ".sql")
+		direction := path.Ext(base)
+		if direction != ".up" && direction != ".down" {
+			continue
+		}
+		base = strings.TrimSuffix(base, direction)
+		prefix, label, _ := strings.Cut(base, "_")
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration %s: %w", name, err)
+		}
+
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", name, err)
+		}
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: label}
+			byVersion[version] = migration
+		}
+		if direction == ".up" {
+			migration.up = string(content)
+		} else {
+			migration.down = string(content)
+		}
+	}
+
+	m := &Migrator{db: db}
+	for _, migration := range byVersion {
+		m.migrations = append(m.migrations, *migration)
+	}
+	sort.Slice(m.migrations, func(i, j int) bool { return m.migrations[i].Version < m.migrations[j].Version })
+	return m, nil
+}
+
+// Version returns the version of the database, 0 before the first
+// migration. A dirty version was left by a golang-migrate run that failed
+// and needs fixing by hand.
+func (m *Migrator) Version(ctx context.Context) (version uint64, dirty bool, err error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return 0, false, err
+	}
+	err = m.db.QueryRowxContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to get schema version: %w", err)
+	}
+	return version, dirty, nil
+}
+
+// Up applies the migrations newer than the version of the database and
+// returns them. Each migration runs in a transaction with its version
+// change, so a failing one leaves the database at the previous version.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	version, err := m.cleanVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	for _, migration := range m.migrations {
+		if migration.Version <= version {
+			continue
+		}
+		if err := m.apply(ctx, migration.up, migration.Version, true); err != nil {
+			return applied, fmt.Errorf("failed to apply migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		applied = append(applied, migration)
+	}
+	return applied, nil
+}
+
+// Down reverts the last steps migrations applied and returns them
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	version, err := m.cleanVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var reverted []Migration
+	for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+		migration := m.migrations[i]
+		if migration.Version > version {
+			continue
+		}
+		var previous uint64
+		if i > 0 {
+			previous = m.migrations[i-1].Version
+		}
+		if err := m.apply(ctx, migration.down, previous, previous > 0); err != nil {
+			return reverted, fmt.Errorf("failed to revert migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		reverted = append(reverted, migration)
+	}
+	return reverted, nil
+}
+
+// cleanVersion returns the version of the database, failing when it is dirty
+func (m *Migrator) cleanVersion(ctx context.Context) (uint64, error) {
+	version, dirty, err := m.Version(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if dirty {
+		return 0, fmt.Errorf("database is dirty at version %d; fix it and force the version with golang-migrate", version)
+	}
+	return version, nil
+}
+
+// apply runs statements and moves the database to version in one
+// transaction. Without keep the database is left without a version.
+func (m *Migrator) apply(ctx context.Context, statements string, version uint64, keep bool) error {
+	return WithinTx(ctx, m.db, func(ctx context.Context) error {
+		conn := Conn(ctx, m.db)
+		if strings.TrimSpace(statements) != "" {
+			if _, err := conn.ExecContext(ctx, statements); err != nil {
+				return err
+			}
+		}
+		if _, err := conn.ExecContext(ctx, `DELETE FROM schema_migrations`); err != nil {
+			return fmt.Errorf("failed to clear schema version: %w", err)
+		}
+		if !keep {
+			return nil
+		}
+		if _, err := conn.ExecContext(ctx, `INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)`, version); err != nil {
+			return fmt.Errorf("failed to set schema version: %w", err)
+		}
+		return nil
+	})
+}
+
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	_, err := m.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL)`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	return nil
+}
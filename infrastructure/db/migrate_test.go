@@ -0,0 +1,97 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var testMigrations = fstest.MapFS{
+	"1_datasets.up.sql":     {Data: []byte("CREATE TABLE datasets (id text)")},
+	"1_datasets.down.sql":   {Data: []byte("DROP TABLE datasets")},
+	"2_audit_logs.up.sql":   {Data: []byte("CREATE TABLE audit_logs (id text)")},
+	"2_audit_logs.down.sql": {Data: []byte("DROP TABLE audit_logs")},
+	"README.md":             {Data: []byte("not a migration")},
+}
+
+// Test Up applies only the migrations newer than the database, each in a
+// transaction with its version
+func TestMigrator_Up(t *testing.T) {
+	sqlDB, mock := newMockDB(t)
+	migrator, err := NewMigrator(sqlDB, testMigrations)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT version, dirty FROM schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).AddRow(1, false))
+	mock.ExpectBegin()
+	mock.ExpectExec("CREATE TABLE audit_logs").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM schema_migrations").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO schema_migrations").WithArgs(uint64(2)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	applied, err := migrator.Up(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(applied) != 1 || applied[0].Version != 2 || applied[0].Name != "audit_logs" {
+		t.Errorf("Expected migration 2 to be applied, got %+v", applied)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
+
+// Test reverting the first migration leaves the database without a version
+func TestMigrator_Down(t *testing.T) {
+	sqlDB, mock := newMockDB(t)
+	migrator, err := NewMigrator(sqlDB, testMigrations)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT version, dirty FROM schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).AddRow(2, false))
+	mock.ExpectBegin()
+	mock.ExpectExec("DROP TABLE audit_logs").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM schema_migrations").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO schema_migrations").WithArgs(uint64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec("DROP TABLE datasets").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM schema_migrations").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	reverted, err := migrator.Down(context.Background(), 5)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(reverted) != 2 {
+		t.Errorf("Expected both migrations to be reverted, got %d", len(reverted))
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
+
+// Test a dirty database is left alone
+func TestMigrator_Dirty(t *testing.T) {
+	sqlDB, mock := newMockDB(t)
+	migrator, err := NewMigrator(sqlDB, testMigrations)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT version, dirty FROM schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).AddRow(1, true))
+
+	if _, err := migrator.Up(context.Background()); err == nil {
+		t.Errorf("Expected an error for a dirty database")
+	}
+}
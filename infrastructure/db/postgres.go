@@ -3,6 +3,8 @@ package db
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
 	"portal-data-backend/infrastructure/config"
@@ -14,11 +16,13 @@ import (
 // Postgres wraps sqlx.DB with additional functionality
 type Postgres struct {
 	DB *sqlx.DB
+	// Replicas are the read replicas, in configuration order
+	Replicas []*sqlx.DB
 }
 
 // NewPostgres creates a new PostgreSQL connection
 func NewPostgres(cfg *config.DatabaseConfig) (*Postgres, error) {
-	dsn := cfg.DSN()
+	dsn := withStatementTimeout(cfg.DSN(), cfg.StatementTimeout)
 
 	db, err := sqlx.Connect("postgres", dsn)
 	if err != nil {
@@ -38,11 +42,55 @@ func NewPostgres(cfg *config.DatabaseConfig) (*Postgres, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	return &Postgres{DB: db}, nil
+	// Replicas connect lazily; one that is down at startup is skipped by the
+	// router until it comes up
+	replicas := make([]*sqlx.DB, 0, len(cfg.ReplicaDSNs))
+	for i, replicaDSN := range cfg.ReplicaDSNs {
+		replica, err := sqlx.Open("postgres", withStatementTimeout(replicaDSN, cfg.StatementTimeout))
+		if err != nil {
+			for _, opened := range replicas {
+				opened.Close()
+			}
+			db.Close()
+			return nil, fmt.Errorf("failed to open database replica %d: %w", i+1, err)
+		}
+		replica.SetMaxOpenConns(cfg.MaxOpenConns)
+		replica.SetMaxIdleConns(cfg.MaxIdleConns)
+		replica.SetConnMaxLifetime(cfg.MaxLifetime)
+		replicas = append(replicas, replica)
+	}
+
+	return &Postgres{DB: db, Replicas: replicas}, nil
+}
+
+// withStatementTimeout sets the statement_timeout of the sessions of dsn, a
+// key=value or postgres:// connection string, unless it sets one itself.
+// Queries canceled through their context stop at once; the timeout also
+// bounds the queries of background jobs and those PostgreSQL keeps running
+// after a connection is lost.
+func withStatementTimeout(dsn string, timeout time.Duration) string {
+	if timeout <= 0 || strings.Contains(dsn, "statement_timeout") {
+		return dsn
+	}
+	ms := fmt.Sprint(timeout.Milliseconds())
+	if strings.Contains(dsn, "://") {
+		parsed, err := url.Parse(dsn)
+		if err != nil {
+			return dsn
+		}
+		query := parsed.Query()
+		query.Set("statement_timeout", ms)
+		parsed.RawQuery = query.Encode()
+		return parsed.String()
+	}
+	return dsn + " statement_timeout=" + ms
 }
 
-// Close closes the database connection
+// Close closes the database connections
 func (p *Postgres) Close() error {
+	for _, replica := range p.Replicas {
+		replica.Close()
+	}
 	return p.DB.Close()
 }
 
@@ -0,0 +1,25 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+// Test the statement timeout is added to both forms of connection strings,
+// leaving those setting their own alone
+func TestWithStatementTimeout(t *testing.T) {
+	cases := map[string]string{
+		"host=db dbname=portal":                          "host=db dbname=portal statement_timeout=30000",
+		"postgres://replica:5432/portal?sslmode=disable": "postgres://replica:5432/portal?sslmode=disable&statement_timeout=30000",
+		"host=db statement_timeout=5000":                 "host=db statement_timeout=5000",
+	}
+	for dsn, want := range cases {
+		if got := withStatementTimeout(dsn, 30*time.Second); got != want {
+			t.Errorf("Expected %s, got %s", want, got)
+		}
+	}
+
+	if got := withStatementTimeout("host=db", 0); got != "host=db" {
+		t.Errorf("Expected no timeout when disabled, got %s", got)
+	}
+}
@@ -0,0 +1,262 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"portal-data-backend/infrastructure/logger"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Router sends read-only queries to the healthy replicas in turn and
+// everything else to the primary. A replica that loses its connection or fails
+// a health check is skipped until a later check succeeds; with no healthy
+// replica, reads go to the primary.
+type Router struct {
+	primary  *sqlx.DB
+	replicas []*replica
+	next     atomic.Uint64
+}
+
+type replica struct {
+	name    string
+	db      *sqlx.DB
+	healthy atomic.Bool
+}
+
+// NewRouter creates a router over primary and its replicas. Replicas count as
+// healthy until a query or Check finds otherwise.
+func NewRouter(primary *sqlx.DB, replicas ...*sqlx.DB) *Router {
+	r := &Router{primary: primary}
+	for i, db := range replicas {
+		rep := &replica{name: fmt.Sprintf("replica-%d", i+1), db: db}
+		rep.healthy.Store(true)
+		r.replicas = append(r.replicas, rep)
+	}
+	return r
+}
+
+// Primary returns the primary database
+func (r *Router) Primary() *sqlx.DB {
+	return r.primary
+}
+
+// Write returns where queries that write, or must see the latest writes, run:
+// the transaction ctx carries or the primary
+func (r *Router) Write(ctx context.Context) Executor {
+	return Conn(ctx, r.primary)
+}
+
+// Read returns where a read-only query runs: the transaction ctx carries, a
+// healthy replica, or the primary. A read failing because its replica is
+// unreachable is retried on the primary.
+func (r *Router) Read(ctx context.Context) Executor {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		return state.tx
+	}
+	if rep := r.pick(); rep != nil {
+		return &replicaExecutor{replica: rep, primary: r.primary}
+	}
+	return r.primary
+}
+
+// WithinTx implements Transactor on the primary
+func (r *Router) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return WithinTx(ctx, r.primary, fn)
+}
+
+// pick returns the next healthy replica, or nil when there is none
+func (r *Router) pick() *replica {
+	for range r.replicas {
+		rep := r.replicas[(r.next.Add(1)-1)%uint64(len(r.replicas))]
+		if rep.healthy.Load() {
+			return rep
+		}
+	}
+	return nil
+}
+
+// Check pings every replica and records which are healthy
+func (r *Router) Check(ctx context.Context) {
+	for _, rep := range r.replicas {
+		pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		err := rep.db.PingContext(pingCtx)
+		cancel()
+		rep.setHealthy(ctx, err)
+	}
+}
+
+// Run checks the replicas every interval until ctx is done
+func (r *Router) Run(ctx context.Context, interval time.Duration) {
+	if len(r.replicas) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.Check(ctx)
+		}
+	}
+}
+
+// PoolStats describe the connection pool of the primary or a replica
+type PoolStats struct {
+	Name               string `json:"name"`
+	Role               string `json:"role"`
+	Healthy            bool   `json:"healthy"`
+	MaxOpenConnections int    `json:"max_open_connections"`
+	OpenConnections    int    `json:"open_connections"`
+	InUse              int    `json:"in_use"`
+	Idle               int    `json:"idle"`
+	WaitCount          int64  `json:"wait_count"`
+	WaitDurationMs     int64  `json:"wait_duration_ms"`
+}
+
+// Stats returns the pool statistics of the primary followed by the replicas
+func (r *Router) Stats() []PoolStats {
+	stats := []PoolStats{poolStats("primary", "primary", true, r.primary)}
+	for _, rep := range r.replicas {
+		stats = append(stats, poolStats(rep.name, "replica", rep.healthy.Load(), rep.db))
+	}
+	return stats
+}
+
+func poolStats(name, role string, healthy bool, db *sqlx.DB) PoolStats {
+	s := db.Stats()
+	return PoolStats{
+		Name:               name,
+		Role:               role,
+		Healthy:            healthy,
+		MaxOpenConnections: s.MaxOpenConnections,
+		OpenConnections:    s.OpenConnections,
+		InUse:              s.InUse,
+		Idle:               s.Idle,
+		WaitCount:          s.WaitCount,
+		WaitDurationMs:     s.WaitDuration.Milliseconds(),
+	}
+}
+
+// setHealthy records the outcome of using the replica, logging changes
+func (rep *replica) setHealthy(ctx context.Context, err error) {
+	healthy := err == nil
+	if rep.healthy.Swap(healthy) == healthy {
+		return
+	}
+	if healthy {
+		logger.FromContext(ctx).Info("database %s is healthy again", rep.name)
+	} else {
+		logger.FromContext(ctx).Warn("database %s is unhealthy, reading from the primary: %v", rep.name, err)
+	}
+}
+
+// replicaExecutor runs reads on a replica and retries them on the primary
+// when the replica is unreachable. Writes go to the primary.
+type replicaExecutor struct {
+	replica *replica
+	primary *sqlx.DB
+}
+
+// fallback reports whether err means the replica is unreachable, marking it
+// unhealthy if so
+func (e *replicaExecutor) fallback(ctx context.Context, err error) bool {
+	if !IsConnectionError(err) || ctx.Err() != nil {
+		return false
+	}
+	e.replica.setHealthy(ctx, err)
+	return true
+}
+
+func (e *replicaExecutor) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	err := e.replica.db.GetContext(ctx, dest, query, args...)
+	if e.fallback(ctx, err) {
+		return e.primary.GetContext(ctx, dest, query, args...)
+	}
+	return err
+}
+
+func (e *replicaExecutor) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	err := e.replica.db.SelectContext(ctx, dest, query, args...)
+	if e.fallback(ctx, err) {
+		return e.primary.SelectContext(ctx, dest, query, args...)
+	}
+	return err
+}
+
+func (e *replicaExecutor) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	rows, err := e.replica.db.QueryContext(ctx, query, args...)
+	if e.fallback(ctx, err) {
+		return e.primary.QueryContext(ctx, query, args...)
+	}
+	return rows, err
+}
+
+func (e *replicaExecutor) QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error) {
+	rows, err := e.replica.db.QueryxContext(ctx, query, args...)
+	if e.fallback(ctx, err) {
+		return e.primary.QueryxContext(ctx, query, args...)
+	}
+	return rows, err
+}
+
+func (e *replicaExecutor) QueryRowxContext(ctx context.Context, query string, args ...interface{}) *sqlx.Row {
+	row := e.replica.db.QueryRowxContext(ctx, query, args...)
+	if e.fallback(ctx, row.Err()) {
+		return e.primary.QueryRowxContext(ctx, query, args...)
+	}
+	return row
+}
+
+func (e *replicaExecutor) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return e.primary.ExecContext(ctx, query, args...)
+}
+
+func (e *replicaExecutor) NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
+	return e.primary.NamedExecContext(ctx, query, arg)
+}
+
+func (e *replicaExecutor) DriverName() string {
+	return e.replica.db.DriverName()
+}
+
+func (e *replicaExecutor) Rebind(query string) string {
+	return e.replica.db.Rebind(query)
+}
+
+func (e *replicaExecutor) BindNamed(query string, arg interface{}) (string, []interface{}, error) {
+	return e.replica.db.BindNamed(query, arg)
+}
+
+// IsConnectionError reports whether err means the database could not be
+// reached, as opposed to the query failing
+func IsConnectionError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		// Connection exceptions, shutdowns and a server not yet accepting
+		// connections
+		switch pqErr.Code {
+		case "57P01", "57P02", "57P03":
+			return true
+		}
+		return pqErr.Code.Class() == "08"
+	}
+	var netErr net.Error
+	return errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) || errors.As(err, &netErr)
+}
@@ -0,0 +1,141 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+func newMockReplica(t *testing.T) (*sqlx.DB, sqlmock.Sqlmock) {
+	t.Helper()
+
+	conn, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return sqlx.NewDb(conn, "postgres"), mock
+}
+
+func countRows(n int) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"count"}).AddRow(n)
+}
+
+// Test reads take turns on the replicas while writes go to the primary
+func TestRouter_ReadsOnReplicas(t *testing.T) {
+	primary, primaryMock := newMockDB(t)
+	replica1, replica1Mock := newMockReplica(t)
+	replica2, replica2Mock := newMockReplica(t)
+	router := NewRouter(primary, replica1, replica2)
+	ctx := context.Background()
+
+	replica1Mock.ExpectQuery("SELECT COUNT").WillReturnRows(countRows(1))
+	replica2Mock.ExpectQuery("SELECT COUNT").WillReturnRows(countRows(2))
+	primaryMock.ExpectExec("UPDATE datasets").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	var first, second int
+	if err := router.Read(ctx).GetContext(ctx, &first, "SELECT COUNT(*) FROM datasets"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := router.Read(ctx).GetContext(ctx, &second, "SELECT COUNT(*) FROM datasets"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if first != 1 || second != 2 {
+		t.Errorf("Expected reads from replica 1 then 2, got %d then %d", first, second)
+	}
+	if _, err := router.Write(ctx).ExecContext(ctx, "UPDATE datasets SET status = 'published'"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for name, mock := range map[string]sqlmock.Sqlmock{"primary": primaryMock, "replica-1": replica1Mock, "replica-2": replica2Mock} {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("Unmet expectations on %s: %v", name, err)
+		}
+	}
+}
+
+// Test a read on an unreachable replica is retried on the primary, which
+// serves reads until the replica passes a check again
+func TestRouter_FallbackToPrimary(t *testing.T) {
+	primary, primaryMock := newMockDB(t)
+	replica, replicaMock := newMockReplica(t)
+	router := NewRouter(primary, replica)
+	ctx := context.Background()
+
+	replicaMock.ExpectQuery("SELECT COUNT").
+		WillReturnError(&net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")})
+	primaryMock.ExpectQuery("SELECT COUNT").WillReturnRows(countRows(7))
+
+	var count int
+	if err := router.Read(ctx).GetContext(ctx, &count, "SELECT COUNT(*) FROM datasets"); err != nil {
+		t.Fatalf("Expected the primary to answer, got %v", err)
+	}
+	if count != 7 {
+		t.Errorf("Expected 7 from the primary, got %d", count)
+	}
+	if stats := router.Stats(); stats[1].Healthy {
+		t.Errorf("Expected the replica to be unhealthy, got %+v", stats[1])
+	}
+	if router.Read(ctx) != Executor(primary) {
+		t.Errorf("Expected reads on the primary while the replica is unhealthy")
+	}
+
+	replicaMock.ExpectPing()
+	router.Check(ctx)
+	if stats := router.Stats(); !stats[1].Healthy {
+		t.Errorf("Expected the replica to be healthy after a successful check")
+	}
+	if err := primaryMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations on the primary: %v", err)
+	}
+	if err := replicaMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations on the replica: %v", err)
+	}
+}
+
+// Test a failing query is not retried and leaves the replica healthy
+func TestRouter_QueryErrorNotRetried(t *testing.T) {
+	primary, primaryMock := newMockDB(t)
+	replica, replicaMock := newMockReplica(t)
+	router := NewRouter(primary, replica)
+	ctx := context.Background()
+
+	replicaMock.ExpectQuery("SELECT").WillReturnError(&pq.Error{Code: "42703", Message: "column does not exist"})
+
+	var count int
+	if err := router.Read(ctx).GetContext(ctx, &count, "SELECT missing FROM datasets"); err == nil {
+		t.Fatalf("Expected the query error")
+	}
+	if stats := router.Stats(); !stats[1].Healthy {
+		t.Errorf("Expected the replica to stay healthy")
+	}
+	if err := primaryMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Expected no primary queries: %v", err)
+	}
+}
+
+// Test reads inside a transaction run in it
+func TestRouter_ReadInTransaction(t *testing.T) {
+	primary, primaryMock := newMockDB(t)
+	replica, _ := newMockReplica(t)
+	router := NewRouter(primary, replica)
+
+	primaryMock.ExpectBegin()
+	primaryMock.ExpectCommit()
+
+	err := router.WithinTx(context.Background(), func(ctx context.Context) error {
+		if _, ok := router.Read(ctx).(*sqlx.Tx); !ok {
+			t.Errorf("Expected reads in the transaction")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"portal-data-backend/pkg/errors"
+)
+
+// Soft deleted rows have their deleted_at set instead of being deleted. Reads
+// leave them out unless the context asks for them with WithDeleted, Restore
+// brings them back and PurgeDeleted deletes them for good once they are old
+// enough.
+
+type deletedKey struct{}
+
+// WithDeleted returns a context whose reads include soft deleted rows
+func WithDeleted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, deletedKey{}, true)
+}
+
+// IncludesDeleted reports whether reads with ctx include soft deleted rows
+func IncludesDeleted(ctx context.Context) bool {
+	included, _ := ctx.Value(deletedKey{}).(bool)
+	return included
+}
+
+// NotDeleted is the condition leaving out the soft deleted rows of column,
+// the deleted_at column of a table, or TRUE when ctx includes them
+func NotDeleted(ctx context.Context, column string) string {
+	if IncludesDeleted(ctx) {
+		return "TRUE"
+	}
+	return column + " IS NULL"
+}
+
+// SoftDelete marks the row id of table deleted. It returns ErrNotFound when
+// the row does not exist or is already deleted.
+func SoftDelete(ctx context.Context, db Executor, table, id string) error {
+	result, err := db.ExecContext(ctx, `UPDATE `+table+` SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete from %s: %w", table, err)
+	}
+	return expectRow(result.RowsAffected())
+}
+
+// Restore brings back the soft deleted row id of table. It returns
+// ErrNotFound when the row does not exist or is not deleted.
+func Restore(ctx context.Context, db Executor, table, id string) error {
+	result, err := db.ExecContext(ctx, `UPDATE `+table+` SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL`, id)
+	if err != nil {
+		return fmt.Errorf("failed to restore in %s: %w", table, err)
+	}
+	return expectRow(result.RowsAffected())
+}
+
+// PurgeDeleted deletes the rows of table soft deleted before before for good
+// and returns how many it deleted
+func PurgeDeleted(ctx context.Context, db Executor, table string, before time.Time) (int64, error) {
+	result, err := db.ExecContext(ctx, `DELETE FROM `+table+` WHERE deleted_at IS NOT NULL AND deleted_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge %s: %w", table, err)
+	}
+	return result.RowsAffected()
+}
+
+func expectRow(rows int64, err error) error {
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
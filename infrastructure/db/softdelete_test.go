@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// Test soft deleted rows are left out unless the context includes them
+func TestNotDeleted(t *testing.T) {
+	ctx := context.Background()
+	if got := NotDeleted(ctx, "d.deleted_at"); got != "d.deleted_at IS NULL" {
+		t.Errorf("Expected deleted rows to be left out, got %s", got)
+	}
+	if got := NotDeleted(WithDeleted(ctx), "d.deleted_at"); got != "TRUE" {
+		t.Errorf("Expected deleted rows to be included, got %s", got)
+	}
+}
+
+// Test deleting and restoring touch live and deleted rows respectively
+func TestSoftDelete_Restore(t *testing.T) {
+	sqlDB, mock := newMockDB(t)
+	ctx := context.Background()
+
+	mock.ExpectExec(`UPDATE datasets SET deleted_at = NOW\(\) WHERE id = \$1 AND deleted_at IS NULL`).
+		WithArgs("dataset-1").WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := SoftDelete(ctx, sqlDB, "datasets", "dataset-1"); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	mock.ExpectExec(`UPDATE datasets SET deleted_at = NULL WHERE id = \$1 AND deleted_at IS NOT NULL`).
+		WithArgs("dataset-1").WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := Restore(ctx, sqlDB, "datasets", "dataset-1"); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	mock.ExpectExec(`UPDATE datasets SET deleted_at = NULL`).
+		WithArgs("dataset-1").WillReturnResult(sqlmock.NewResult(0, 0))
+	if err := Restore(ctx, sqlDB, "datasets", "dataset-1"); !errors.Is(err, pkgErrors.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a row that is not deleted, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
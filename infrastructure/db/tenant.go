@@ -0,0 +1,45 @@
+package db
+
+import (
+	"context"
+
+	"portal-data-backend/infrastructure/tenant"
+
+	"github.com/lib/pq"
+)
+
+// Rows of a hosted portal are kept to the tenant the context is scoped to.
+// Tenant IDs are validated codes, quoted as literals so that the conditions
+// compose like NotDeleted without renumbering placeholders.
+
+// InTenant is the condition keeping the rows of column, the tenant_id
+// column of a table, to the tenant of ctx, or TRUE when ctx is not scoped
+func InTenant(ctx context.Context, column string) string {
+	id := tenant.ID(ctx)
+	if id == "" {
+		return "TRUE"
+	}
+	return column + " = " + pq.QuoteLiteral(id)
+}
+
+// InTenantOrganizations is the condition keeping the rows of column, an
+// organization_id column, to the organizations of the tenant of ctx, or
+// TRUE when ctx is not scoped
+func InTenantOrganizations(ctx context.Context, column string) string {
+	id := tenant.ID(ctx)
+	if id == "" {
+		return "TRUE"
+	}
+	return column + " IN (SELECT id FROM organizations WHERE tenant_id = " + pq.QuoteLiteral(id) + ")"
+}
+
+// InTenantOrGlobal is the condition keeping the rows of column, a nullable
+// tenant_id column, to the rows shared by every tenant and those of the
+// tenant of ctx, or to the shared rows when ctx is not scoped
+func InTenantOrGlobal(ctx context.Context, column string) string {
+	id := tenant.ID(ctx)
+	if id == "" {
+		return column + " IS NULL"
+	}
+	return "(" + column + " IS NULL OR " + column + " = " + pq.QuoteLiteral(id) + ")"
+}
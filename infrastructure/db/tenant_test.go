@@ -0,0 +1,30 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"portal-data-backend/infrastructure/tenant"
+)
+
+// Test tenant conditions scope queries only when the context has a tenant
+func TestTenantConditions(t *testing.T) {
+	ctx := context.Background()
+	if got := InTenant(ctx, "tenant_id"); got != "TRUE" {
+		t.Errorf("Expected unscoped rows to be kept, got %s", got)
+	}
+	if got := InTenantOrGlobal(ctx, "tenant_id"); got != "tenant_id IS NULL" {
+		t.Errorf("Expected only shared rows without a tenant, got %s", got)
+	}
+
+	ctx = tenant.WithTenant(ctx, &tenant.Tenant{ID: "jabar"})
+	if got := InTenant(ctx, "tenant_id"); got != "tenant_id = 'jabar'" {
+		t.Errorf("Expected rows of the tenant, got %s", got)
+	}
+	if got := InTenantOrganizations(ctx, "d.organization_id"); got != "d.organization_id IN (SELECT id FROM organizations WHERE tenant_id = 'jabar')" {
+		t.Errorf("Expected rows of the organizations of the tenant, got %s", got)
+	}
+	if got := InTenantOrGlobal(ctx, "tenant_id"); got != "(tenant_id IS NULL OR tenant_id = 'jabar')" {
+		t.Errorf("Expected shared rows and rows of the tenant, got %s", got)
+	}
+}
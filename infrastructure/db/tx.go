@@ -0,0 +1,160 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Executor runs queries. *sqlx.DB and *sqlx.Tx both implement it.
+type Executor interface {
+	sqlx.ExtContext
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error)
+}
+
+// Transactor runs functions in a database transaction
+type Transactor interface {
+	// WithinTx runs fn in a transaction. Repositories given the context fn
+	// receives run their queries in that transaction.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// TxManager runs units of work spanning several repositories in one
+// transaction
+type TxManager struct {
+	db *sqlx.DB
+}
+
+// NewTxManager creates a transaction manager for db
+func NewTxManager(db *sqlx.DB) *TxManager {
+	return &TxManager{db: db}
+}
+
+// WithinTx implements Transactor
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return WithinTx(ctx, m.db, fn)
+}
+
+type txKey struct{}
+
+// txState is the transaction a context carries and the work waiting for it
+// to commit
+type txState struct {
+	tx          *sqlx.Tx
+	afterCommit []func()
+	savepoints  int
+}
+
+// WithinTx runs fn in a transaction on db and commits it when fn succeeds.
+// When ctx already carries a transaction fn joins it, and the outermost call
+// commits or rolls back. An error or panic in fn rolls the transaction back.
+func WithinTx(ctx context.Context, db *sqlx.DB, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*txState); ok {
+		return fn(ctx)
+	}
+
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	state := &txState{tx: tx}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, state)); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("tx error: %v, rollback error: %w", err, rbErr)
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	for _, hook := range state.afterCommit {
+		hook()
+	}
+	return nil
+}
+
+// Conn returns the transaction ctx carries, or db when it carries none.
+// Repositories run every query on it.
+func Conn(ctx context.Context, db *sqlx.DB) Executor {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		return state.tx
+	}
+	return db
+}
+
+// AfterCommit runs fn once the transaction ctx carries commits, or right away
+// when ctx carries none. Work reaching outside the database, like publishing
+// events, goes through it so a rolled back transaction leaves no trace.
+func AfterCommit(ctx context.Context, fn func()) {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		state.afterCommit = append(state.afterCommit, fn)
+		return
+	}
+	fn()
+}
+
+// Savepoint runs fn in a savepoint of the transaction ctx carries. When fn
+// fails, its queries and after-commit work are rolled back and the
+// transaction stays usable for the rest of the unit of work. Without a
+// transaction fn just runs.
+func Savepoint(ctx context.Context, fn func(ctx context.Context) error) error {
+	state, ok := ctx.Value(txKey{}).(*txState)
+	if !ok {
+		return fn(ctx)
+	}
+
+	state.savepoints++
+	name := fmt.Sprintf("sp_%d", state.savepoints)
+	if _, err := state.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("failed to create savepoint: %w", err)
+	}
+	hooks := len(state.afterCommit)
+
+	if err := fn(ctx); err != nil {
+		if _, rbErr := state.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); rbErr != nil {
+			return fmt.Errorf("savepoint error: %v, rollback error: %w", err, rbErr)
+		}
+		state.afterCommit = state.afterCommit[:hooks]
+		return err
+	}
+	if _, err := state.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("failed to release savepoint: %w", err)
+	}
+	return nil
+}
+
+// Each runs fn for each of ids in one transaction of tx, each in a
+// savepoint. It returns the errors of the ids fn failed on, whose work is
+// rolled back while the work of the others commits. The error is set when
+// the transaction itself fails, and then nothing is applied.
+func Each(ctx context.Context, tx Transactor, ids []string, fn func(ctx context.Context, id string) error) (map[string]error, error) {
+	failed := make(map[string]error)
+	err := tx.WithinTx(ctx, func(ctx context.Context) error {
+		for _, id := range ids {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := Savepoint(ctx, func(ctx context.Context) error { return fn(ctx, id) }); err != nil {
+				failed[id] = err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return failed, nil
+}
@@ -0,0 +1,154 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func newMockDB(t *testing.T) (*sqlx.DB, sqlmock.Sqlmock) {
+	t.Helper()
+
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return sqlx.NewDb(conn, "postgres"), mock
+}
+
+// Test queries of a unit of work run in its transaction, which commits
+func TestWithinTx_Commit(t *testing.T) {
+	sqlDB, mock := newMockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO datasets").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("UPDATE organizations").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	committed := false
+	err := NewTxManager(sqlDB).WithinTx(context.Background(), func(ctx context.Context) error {
+		if _, ok := Conn(ctx, sqlDB).(*sqlx.Tx); !ok {
+			t.Errorf("Expected Conn to return the transaction")
+		}
+		if _, err := Conn(ctx, sqlDB).ExecContext(ctx, "INSERT INTO datasets (id) VALUES ($1)", "dataset-1"); err != nil {
+			return err
+		}
+		AfterCommit(ctx, func() { committed = true })
+		_, err := Conn(ctx, sqlDB).ExecContext(ctx, "UPDATE organizations SET total_datasets = total_datasets + 1")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !committed {
+		t.Errorf("Expected the after-commit work to run")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
+
+// Test a failing unit of work is rolled back and its after-commit work dropped
+func TestWithinTx_Rollback(t *testing.T) {
+	sqlDB, mock := newMockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO datasets").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectRollback()
+
+	failure := errors.New("counter update failed")
+	committed := false
+	err := WithinTx(context.Background(), sqlDB, func(ctx context.Context) error {
+		if _, err := Conn(ctx, sqlDB).ExecContext(ctx, "INSERT INTO datasets (id) VALUES ($1)", "dataset-1"); err != nil {
+			return err
+		}
+		AfterCommit(ctx, func() { committed = true })
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Errorf("Expected the unit of work's error, got %v", err)
+	}
+	if committed {
+		t.Errorf("Expected no after-commit work after a rollback")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
+
+// Test a nested unit of work joins the outer transaction
+func TestWithinTx_Nested(t *testing.T) {
+	sqlDB, mock := newMockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM data_rows").WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	err := WithinTx(context.Background(), sqlDB, func(ctx context.Context) error {
+		outer := Conn(ctx, sqlDB)
+		return WithinTx(ctx, sqlDB, func(ctx context.Context) error {
+			if Conn(ctx, sqlDB) != outer {
+				t.Errorf("Expected the nested unit of work to share the transaction")
+			}
+			_, err := Conn(ctx, sqlDB).ExecContext(ctx, "DELETE FROM data_rows WHERE dataset_id = $1", "dataset-1")
+			return err
+		})
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
+
+// Test without a transaction queries run on the database and after-commit
+// work runs right away
+func TestConn_WithoutTx(t *testing.T) {
+	sqlDB, _ := newMockDB(t)
+	ctx := context.Background()
+
+	if Conn(ctx, sqlDB) != sqlDB {
+		t.Errorf("Expected Conn to return the database")
+	}
+	ran := false
+	AfterCommit(ctx, func() { ran = true })
+	if !ran {
+		t.Errorf("Expected the after-commit work to run right away")
+	}
+}
+
+// Test the items of Each failing are rolled back to their savepoint while
+// the others commit with their after-commit work
+func TestEach_RollsBackFailedItems(t *testing.T) {
+	sqlDB, mock := newMockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectExec("SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE datasets").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("RELEASE SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SAVEPOINT sp_2").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE datasets").WillReturnError(errors.New("invalid status"))
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT sp_2").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	var published []string
+	failed, err := Each(context.Background(), NewTxManager(sqlDB), []string{"dataset-1", "dataset-2"}, func(ctx context.Context, id string) error {
+		AfterCommit(ctx, func() { published = append(published, id) })
+		_, err := Conn(ctx, sqlDB).ExecContext(ctx, "UPDATE datasets SET status = $1 WHERE id = $2", "published", id)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(failed) != 1 || failed["dataset-2"] == nil {
+		t.Errorf("Expected dataset-2 to fail, got %v", failed)
+	}
+	if len(published) != 1 || published[0] != "dataset-1" {
+		t.Errorf("Expected only the after-commit work of dataset-1, got %v", published)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
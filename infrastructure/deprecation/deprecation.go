@@ -0,0 +1,117 @@
+// Package deprecation keeps the registry of the routes and query parameters
+// of the API that are deprecated, with when they stop working and what
+// replaces them. Modules declare their deprecations as they register.
+package deprecation
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Notice deprecates the routes matching Method and Pattern, or only their
+// Param query parameter when it is set
+type Notice struct {
+	// Method is the HTTP method of the routes, every method when empty
+	Method string `json:"method,omitempty"`
+	// Pattern is a path.Match pattern of the route path below the API
+	// version, like "/datasets/*/status"
+	Pattern string `json:"pattern"`
+	// Param narrows the notice to requests carrying the query parameter
+	Param string `json:"param,omitempty"`
+	// Since is when the surface was deprecated; zero when not dated
+	Since time.Time `json:"since,omitempty"`
+	// Sunset is when the surface stops working; zero when not planned yet
+	Sunset time.Time `json:"sunset,omitempty"`
+	// Successor links to what replaces the surface, if anything does
+	Successor string `json:"successor,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// ID identifies the notice in usage records, like
+// "GET /datasets/*/status?sort"
+func (n Notice) ID() string {
+	id := strings.TrimSpace(n.Method + " " + n.Pattern)
+	if n.Param != "" {
+		id += "?" + n.Param
+	}
+	return id
+}
+
+// Announce sets the Deprecation, Sunset and Link headers announcing notices
+// on h. The earliest date of the notices is announced for each header, and
+// a Link to the successor of each.
+func Announce(h http.Header, notices []Notice) {
+	var since, sunset time.Time
+	for _, notice := range notices {
+		if !notice.Since.IsZero() && (since.IsZero() || notice.Since.Before(since)) {
+			since = notice.Since
+		}
+		if !notice.Sunset.IsZero() && (sunset.IsZero() || notice.Sunset.Before(sunset)) {
+			sunset = notice.Sunset
+		}
+		if notice.Successor != "" {
+			h.Add("Link", "<"+notice.Successor+`>; rel="successor-version"`)
+		}
+	}
+
+	// RFC 9745 dates deprecations as structured field dates, in seconds
+	if since.IsZero() {
+		h.Set("Deprecation", "true")
+	} else {
+		h.Set("Deprecation", "@"+strconv.FormatInt(since.Unix(), 10))
+	}
+	if !sunset.IsZero() {
+		h.Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+	}
+}
+
+// matches reports whether the notice covers a request of method to
+// routePath with query
+func (n Notice) matches(method, routePath string, query url.Values) bool {
+	if n.Method != "" && !strings.EqualFold(n.Method, method) {
+		return false
+	}
+	if ok, _ := path.Match(n.Pattern, routePath); !ok {
+		return false
+	}
+	return n.Param == "" || query.Has(n.Param)
+}
+
+// Registry holds the deprecation notices of the API in declaration order
+type Registry struct {
+	mu      sync.RWMutex
+	notices []Notice
+}
+
+// Declare adds notices to the registry
+func (r *Registry) Declare(notices ...Notice) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.notices = append(r.notices, notices...)
+}
+
+// Notices returns the notices of the registry
+func (r *Registry) Notices() []Notice {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]Notice(nil), r.notices...)
+}
+
+// Match returns the notices covering a request of method to routePath, the
+// path below the API version, with query
+func (r *Registry) Match(method, routePath string, query url.Values) []Notice {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var matched []Notice
+	for _, notice := range r.notices {
+		if notice.matches(method, routePath, query) {
+			matched = append(matched, notice)
+		}
+	}
+	return matched
+}
@@ -0,0 +1,67 @@
+// Package document writes tabular documents, like reports, as PDF or XLSX
+// files without a rendering service.
+package document
+
+import (
+	"fmt"
+	"io"
+)
+
+// Format is a file format documents are written in
+type Format string
+
+const (
+	FormatPDF  Format = "pdf"
+	FormatXLSX Format = "xlsx"
+)
+
+// Formats lists the formats documents are written in
+var Formats = []Format{FormatPDF, FormatXLSX}
+
+// Valid reports whether f is one of Formats
+func (f Format) Valid() bool {
+	for _, format := range Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// ContentType returns the media type of files of the format
+func (f Format) ContentType() string {
+	if f == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "application/pdf"
+}
+
+// Document is a title and sections of tables. In a PDF the sections follow
+// each other; in an XLSX each is a worksheet.
+type Document struct {
+	Title    string
+	Subtitle string
+	Sections []Section
+}
+
+// Section is a table under a heading. Cells that are numbers are written
+// as numbers in an XLSX. Image is a PNG or JPEG image, like a chart, drawn
+// between the heading and the table of a PDF; XLSX leave it out.
+type Section struct {
+	Heading string
+	Image   []byte
+	Columns []string
+	Rows    [][]string
+}
+
+// Write writes doc to w in format
+func Write(w io.Writer, doc *Document, format Format) error {
+	switch format {
+	case FormatPDF:
+		return WritePDF(w, doc)
+	case FormatXLSX:
+		return WriteXLSX(w, doc)
+	default:
+		return fmt.Errorf("unknown document format %q", format)
+	}
+}
@@ -0,0 +1,127 @@
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func testDocument(rows int) *Document {
+	section := Section{Heading: "Top organizations", Columns: []string{"Organization", "Datasets"}}
+	for i := 0; i < rows; i++ {
+		section.Rows = append(section.Rows, []string{"Dinas (Kominfo) Café", strconv.Itoa(i)})
+	}
+	return &Document{
+		Title:    "Open data report",
+		Subtitle: "September 2026",
+		Sections: []Section{section, {Heading: "Top organizations", Columns: []string{"Tickets"}, Rows: [][]string{{"12"}}}},
+	}
+}
+
+// Test PDFs flow onto more pages as needed, with an escaped text and a
+// cross-reference table locating every object
+func TestWritePDF(t *testing.T) {
+	var b bytes.Buffer
+	if err := WritePDF(&b, testDocument(120)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	pdf := b.String()
+
+	if !strings.HasPrefix(pdf, "%PDF-1.4") || !strings.HasSuffix(pdf, "%%EOF\n") {
+		t.Fatalf("Expected a PDF, got %q", pdf[:20])
+	}
+	if !strings.Contains(pdf, `(Dinas \(Kominfo\) Caf\351)`) {
+		t.Errorf("Expected escaped cells in WinAnsiEncoding")
+	}
+	if !strings.Contains(pdf, "/Count 3") {
+		t.Errorf("Expected 120 rows over 3 pages")
+	}
+
+	for _, offset := range regexp.MustCompile(`(\d{10}) 00000 n`).FindAllStringSubmatch(pdf, -1) {
+		at, _ := strconv.Atoi(offset[1])
+		if !regexp.MustCompile(`^\d+ 0 obj`).MatchString(pdf[at:]) {
+			t.Errorf("Expected an object at offset %d, got %q", at, pdf[at:at+10])
+		}
+	}
+}
+
+// Test images of sections are drawn from their RGB samples, and images that
+// are not PNG or JPEG are refused
+func TestWritePDF_Image(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	img.Set(0, 0, color.RGBA{R: 0x1d, G: 0x4e, B: 0xd8, A: 0xff})
+	var encoded bytes.Buffer
+	png.Encode(&encoded, img)
+
+	doc := testDocument(2)
+	doc.Sections[1].Image = encoded.Bytes()
+	var b bytes.Buffer
+	if err := WritePDF(&b, doc); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	pdf := b.String()
+	if !strings.Contains(pdf, "/XObject << /Im1 7 0 R >>") || !strings.Contains(pdf, "/Im1 Do") {
+		t.Errorf("Expected the image to be drawn on the page")
+	}
+	if !strings.Contains(pdf, "/Subtype /Image /Width 4 /Height 2 /ColorSpace /DeviceRGB") {
+		t.Errorf("Expected an RGB image of 4x2 pixels")
+	}
+	for _, offset := range regexp.MustCompile(`(\d{10}) 00000 n`).FindAllStringSubmatch(pdf, -1) {
+		at, _ := strconv.Atoi(offset[1])
+		if !regexp.MustCompile(`^\d+ 0 obj`).MatchString(pdf[at:]) {
+			t.Errorf("Expected an object at offset %d, got %q", at, pdf[at:at+10])
+		}
+	}
+
+	doc.Sections[1].Image = []byte("<svg/>")
+	if err := WritePDF(io.Discard, doc); err == nil {
+		t.Errorf("Expected an SVG image to be refused")
+	}
+}
+
+// Test XLSX workbooks have a worksheet per section with unique names and
+// numbers written as numbers
+func TestWriteXLSX(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteXLSX(&b, testDocument(2)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	if err != nil {
+		t.Fatalf("Expected a zip archive, got %v", err)
+	}
+	files := map[string]string{}
+	for _, file := range archive.File {
+		r, _ := file.Open()
+		content, _ := io.ReadAll(r)
+		files[file.Name] = string(content)
+	}
+
+	if !strings.Contains(files["xl/workbook.xml"], `name="Top organizations"`) || !strings.Contains(files["xl/workbook.xml"], `name="Top organizations 2"`) {
+		t.Errorf("Expected a uniquely named worksheet per section, got %s", files["xl/workbook.xml"])
+	}
+	sheet := files["xl/worksheets/sheet1.xml"]
+	if !strings.Contains(sheet, `<c r="B3"><v>1</v></c>`) || !strings.Contains(sheet, `<t>Datasets</t>`) {
+		t.Errorf("Expected the columns as text and numbers as numbers, got %s", sheet)
+	}
+	if _, ok := files["[Content_Types].xml"]; !ok {
+		t.Errorf("Expected the content types of the package")
+	}
+}
+
+// Test columns are named like spreadsheets do
+func TestColumnName(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 701: "ZZ", 702: "AAA"} {
+		if got := columnName(i); got != want {
+			t.Errorf("Expected column %d named %s, got %s", i, want, got)
+		}
+	}
+}
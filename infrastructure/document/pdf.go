@@ -0,0 +1,206 @@
+package document
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"math"
+	"strings"
+)
+
+// A4 pages in points, with their margin
+const (
+	pageWidth  = 595
+	pageHeight = 842
+	pageMargin = 40
+)
+
+// pdfWriter lays a document out on A4 pages in Helvetica
+type pdfWriter struct {
+	pages   []*bytes.Buffer
+	current *bytes.Buffer
+	y       float64
+	images  []pdfImage
+}
+
+// pdfImage is an image as the RGB samples of its pixels, compressed
+type pdfImage struct {
+	width, height int
+	samples       []byte
+}
+
+// WritePDF writes doc to w as a PDF of A4 pages
+func WritePDF(w io.Writer, doc *Document) error {
+	p := &pdfWriter{}
+	p.newPage()
+
+	p.line(doc.Title, pageMargin, "F2", 18)
+	if doc.Subtitle != "" {
+		p.line(doc.Subtitle, pageMargin, "F1", 10)
+	}
+	for _, section := range doc.Sections {
+		p.space(14)
+		p.line(section.Heading, pageMargin, "F2", 13)
+		if len(section.Image) > 0 {
+			if err := p.image(section.Image); err != nil {
+				return fmt.Errorf("failed to draw the image of %s: %w", section.Heading, err)
+			}
+		}
+		p.space(4)
+		p.row(section.Columns, "F2")
+		for _, row := range section.Rows {
+			p.row(row, "F1")
+		}
+	}
+	return p.write(w)
+}
+
+func (p *pdfWriter) newPage() {
+	p.current = &bytes.Buffer{}
+	p.pages = append(p.pages, p.current)
+	p.y = pageHeight - pageMargin
+}
+
+// space moves down by height, onto a new page when the page is full
+func (p *pdfWriter) space(height float64) {
+	p.y -= height
+	if p.y < pageMargin {
+		p.newPage()
+		p.y -= height
+	}
+}
+
+// line writes text at x in font and size on a line of its own
+func (p *pdfWriter) line(text string, x float64, font string, size float64) {
+	p.space(size + 4)
+	fmt.Fprintf(p.current, "BT /%s %g Tf %g %g Td (%s) Tj ET\n", font, size, x, p.y, pdfString(text))
+}
+
+// row writes cells in columns sharing the width of the page
+func (p *pdfWriter) row(cells []string, font string) {
+	if len(cells) == 0 {
+		return
+	}
+	const size = 9
+	width := float64(pageWidth-2*pageMargin) / float64(len(cells))
+	// Helvetica is about half as wide as it is high
+	fits := int(width/(size*0.55)) - 1
+
+	p.space(size + 5)
+	for i, cell := range cells {
+		if runes := []rune(cell); len(runes) > fits && fits > 1 {
+			cell = string(runes[:fits-1]) + "…"
+		}
+		x := pageMargin + float64(i)*width
+		fmt.Fprintf(p.current, "BT /%s %d Tf %g %g Td (%s) Tj ET\n", font, size, x, p.y, pdfString(cell))
+	}
+}
+
+// image draws a PNG or JPEG image as wide as the page, or half as high as
+// it, on a page of its own when it does not fit. Transparent pixels are
+// drawn over white.
+func (p *pdfWriter) image(data []byte) error {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	bounds := img.Bounds()
+	if bounds.Empty() {
+		return fmt.Errorf("the image is empty")
+	}
+
+	var samples bytes.Buffer
+	compressor := zlib.NewWriter(&samples)
+	row := make([]byte, 0, 3*bounds.Dx())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		row = row[:0]
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			row = append(row, byte((r+0xffff-a)>>8), byte((g+0xffff-a)>>8), byte((b+0xffff-a)>>8))
+		}
+		compressor.Write(row)
+	}
+	if err := compressor.Close(); err != nil {
+		return err
+	}
+	p.images = append(p.images, pdfImage{width: bounds.Dx(), height: bounds.Dy(), samples: samples.Bytes()})
+
+	scale := math.Min(float64(pageWidth-2*pageMargin)/float64(bounds.Dx()), float64(pageHeight-2*pageMargin)/2/float64(bounds.Dy()))
+	width, height := float64(bounds.Dx())*scale, float64(bounds.Dy())*scale
+	p.space(6 + height)
+	fmt.Fprintf(p.current, "q %.2f 0 0 %.2f %d %.2f cm /Im%d Do Q\n", width, height, pageMargin, p.y, len(p.images))
+	return nil
+}
+
+// write writes the objects of the pages and the cross-reference table
+// locating them
+func (p *pdfWriter) write(w io.Writer) error {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	// Objects 1 to 4 are the catalog, the page tree and the fonts; each
+	// page is followed by its content stream, and the images follow the
+	// pages
+	kids := make([]string, len(p.pages))
+	for i := range p.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	images := make([]string, len(p.images))
+	for i := range p.images {
+		images[i] = fmt.Sprintf("/Im%d %d 0 R", i+1, 5+2*len(p.pages)+i)
+	}
+	out.WriteString("%PDF-1.4\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(p.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, page := range p.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> /XObject << %s >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, strings.Join(images, " "), 6+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+	}
+	for _, img := range p.images {
+		object(fmt.Sprintf("<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /FlateDecode /Length %d >>\nstream\n%s\nendstream",
+			img.width, img.height, len(img.samples), img.samples))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+// pdfString escapes text for a PDF string in WinAnsiEncoding. Characters
+// it cannot encode are written as "?".
+func pdfString(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '…':
+			b.WriteString(`\205`)
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, `\%03o`, r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
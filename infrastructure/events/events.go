@@ -0,0 +1,141 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+
+	"github.com/google/uuid"
+)
+
+// Publisher emits domain events. Modules depend on it through their own
+// EventPublisher interfaces, which have the same method.
+type Publisher interface {
+	Publish(ctx context.Context, eventType string, data interface{}) error
+}
+
+// Sink publishes events to a message broker
+type Sink interface {
+	Publisher
+	Close() error
+}
+
+// Envelope is the message published for every event
+type Envelope struct {
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+// NewSink creates the sink configured in cfg. It returns nil when no driver is set.
+func NewSink(cfg *config.EventsConfig) (Sink, error) {
+	router := &topicRouter{prefix: cfg.TopicPrefix, topics: cfg.Topics}
+
+	switch cfg.Driver {
+	case "":
+		return nil, nil
+	case "kafka":
+		return newKafkaSink(cfg.URL, cfg.Timeout, router), nil
+	case "nats":
+		return newNATSSink(cfg.URL, cfg.Timeout, router)
+	default:
+		return nil, fmt.Errorf("unsupported events driver %q", cfg.Driver)
+	}
+}
+
+// Fanout returns a publisher that sends every event to all publishers. Nil
+// publishers are skipped, and a failing publisher does not stop the others.
+func Fanout(publishers ...Publisher) Publisher {
+	var targets fanout
+	for _, publisher := range publishers {
+		if publisher != nil {
+			targets = append(targets, publisher)
+		}
+	}
+	return targets
+}
+
+type fanout []Publisher
+
+// Bus is a publisher that subscribers are added to after it is handed out,
+// so modules can be given it before every subscriber is built. Subscribe
+// during startup only; it is not safe to call while events are published.
+type Bus struct {
+	subscribers fanout
+}
+
+// Subscribe adds a publisher that receives every event. Nil publishers are skipped.
+func (b *Bus) Subscribe(publisher Publisher) {
+	if publisher != nil {
+		b.subscribers = append(b.subscribers, publisher)
+	}
+}
+
+// Publish sends the event to all subscribers. A failing subscriber does not
+// stop the others.
+func (b *Bus) Publish(ctx context.Context, eventType string, data interface{}) error {
+	return b.subscribers.Publish(ctx, eventType, data)
+}
+
+func (f fanout) Publish(ctx context.Context, eventType string, data interface{}) error {
+	var errs []error
+	for _, publisher := range f {
+		if err := publisher.Publish(ctx, eventType, data); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// topicRouter decides which topic an event type is published to
+type topicRouter struct {
+	prefix string
+	topics map[string]string
+}
+
+// topic returns the topic of eventType, or false when the event is not routed.
+// With an explicit topic map only the listed events are published.
+func (r *topicRouter) topic(eventType string) (string, bool) {
+	if len(r.topics) == 0 {
+		return r.prefix + eventType, true
+	}
+	topic, ok := r.topics[eventType]
+	return topic, ok && topic != ""
+}
+
+// Meta identifies an event. Events relayed from the outbox carry the ID and
+// time they were recorded with, so consumers recognize redeliveries.
+type Meta struct {
+	ID         string
+	OccurredAt time.Time
+}
+
+type metaKey struct{}
+
+// WithMeta returns a context publishing events with meta
+func WithMeta(ctx context.Context, meta Meta) context.Context {
+	return context.WithValue(ctx, metaKey{}, meta)
+}
+
+// MetaFrom returns the meta ctx publishes events with, if any
+func MetaFrom(ctx context.Context) (Meta, bool) {
+	meta, ok := ctx.Value(metaKey{}).(Meta)
+	return meta, ok
+}
+
+func newEnvelope(ctx context.Context, eventType string, data interface{}) Envelope {
+	meta, ok := MetaFrom(ctx)
+	if !ok {
+		meta = Meta{ID: uuid.New().String(), OccurredAt: time.Now().UTC()}
+	}
+	return Envelope{
+		ID:         meta.ID,
+		Type:       eventType,
+		OccurredAt: meta.OccurredAt,
+		Data:       data,
+	}
+}
@@ -0,0 +1,141 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+)
+
+type publishFunc func(ctx context.Context, eventType string, data interface{}) error
+
+func (f publishFunc) Publish(ctx context.Context, eventType string, data interface{}) error {
+	return f(ctx, eventType, data)
+}
+
+// Test the kafka sink produces an enveloped record to the prefixed topic
+func TestKafkaSink_Publish(t *testing.T) {
+	var path, contentType string
+	var produced kafkaProduceRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		contentType = r.Header.Get("Content-Type")
+		json.NewDecoder(r.Body).Decode(&produced)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sink, err := NewSink(&config.EventsConfig{Driver: "kafka", URL: server.URL, TopicPrefix: "portal.", Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer sink.Close()
+
+	if err := sink.Publish(context.Background(), "dataset.published", map[string]string{"id": "dataset-1"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if path != "/topics/portal.dataset.published" {
+		t.Errorf("Expected topic path /topics/portal.dataset.published, got %s", path)
+	}
+	if contentType != kafkaContentType {
+		t.Errorf("Expected content type %s, got %s", kafkaContentType, contentType)
+	}
+	if len(produced.Records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(produced.Records))
+	}
+	record := produced.Records[0]
+	if record.Value.Type != "dataset.published" || record.Key != record.Value.ID || record.Key == "" {
+		t.Errorf("Unexpected record: %+v", record)
+	}
+}
+
+// Test an explicit topic map only publishes the listed events
+func TestKafkaSink_TopicMap(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"error_code": 42202, "message": "schema mismatch"}`))
+	}))
+	defer server.Close()
+
+	sink, _ := NewSink(&config.EventsConfig{
+		Driver:  "kafka",
+		URL:     server.URL,
+		Topics:  map[string]string{"rows.imported": "imports"},
+		Timeout: time.Second,
+	})
+
+	if err := sink.Publish(context.Background(), "user.registered", nil); err != nil {
+		t.Errorf("Expected unmapped event to be skipped, got %v", err)
+	}
+	err := sink.Publish(context.Background(), "rows.imported", nil)
+	if err == nil || err.Error() != "kafka topic imports rejected event: schema mismatch" {
+		t.Errorf("Expected the proxy error message, got %v", err)
+	}
+	if len(paths) != 1 || paths[0] != "/topics/imports" {
+		t.Errorf("Expected only /topics/imports to be called, got %v", paths)
+	}
+}
+
+// Test no sink is created without a driver
+func TestNewSink_Disabled(t *testing.T) {
+	sink, err := NewSink(&config.EventsConfig{})
+	if err != nil || sink != nil {
+		t.Errorf("Expected no sink and no error, got %v, %v", sink, err)
+	}
+	if _, err := NewSink(&config.EventsConfig{Driver: "rabbitmq"}); err == nil {
+		t.Error("Expected error for an unsupported driver")
+	}
+}
+
+// Test fanout skips nil publishers and keeps publishing after a failure
+func TestFanout_PublishesToAll(t *testing.T) {
+	var calls []string
+	failing := publishFunc(func(ctx context.Context, eventType string, data interface{}) error {
+		calls = append(calls, "failing")
+		return errors.New("broker down")
+	})
+	working := publishFunc(func(ctx context.Context, eventType string, data interface{}) error {
+		calls = append(calls, "working")
+		return nil
+	})
+
+	// A disabled sink is a nil Sink, as returned by NewSink without a driver
+	var disabled Sink
+	publisher := Fanout(failing, disabled, working)
+
+	err := publisher.Publish(context.Background(), "user.registered", nil)
+	if err == nil || err.Error() != "broker down" {
+		t.Errorf("Expected the failing publisher error, got %v", err)
+	}
+	if len(calls) != 2 || calls[0] != "failing" || calls[1] != "working" {
+		t.Errorf("Expected both publishers to be called, got %v", calls)
+	}
+}
+
+// Test the bus publishes to subscribers added after it was handed out
+func TestBus_PublishesToLaterSubscribers(t *testing.T) {
+	bus := &Bus{}
+	var publisher Publisher = bus
+
+	var received []string
+	var disabled Sink
+	bus.Subscribe(disabled)
+	bus.Subscribe(publishFunc(func(ctx context.Context, eventType string, data interface{}) error {
+		received = append(received, eventType)
+		return nil
+	}))
+
+	if err := publisher.Publish(context.Background(), "dataset.created", nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(received) != 1 || received[0] != "dataset.created" {
+		t.Errorf("Expected the subscriber to receive dataset.created, got %v", received)
+	}
+}
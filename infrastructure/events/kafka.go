@@ -0,0 +1,88 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// kafkaContentType is the embedded JSON format of the Kafka REST Proxy v2 API
+const kafkaContentType = "application/vnd.kafka.json.v2+json"
+
+// kafkaSink produces events through a Kafka REST Proxy, so the application
+// needs no native Kafka client. Records are keyed by event ID.
+type kafkaSink struct {
+	baseURL string
+	client  *http.Client
+	router  *topicRouter
+}
+
+type kafkaRecord struct {
+	Key   string   `json:"key"`
+	Value Envelope `json:"value"`
+}
+
+type kafkaProduceRequest struct {
+	Records []kafkaRecord `json:"records"`
+}
+
+type kafkaErrorResponse struct {
+	ErrorCode int    `json:"error_code"`
+	Message   string `json:"message"`
+}
+
+func newKafkaSink(baseURL string, timeout time.Duration, router *topicRouter) *kafkaSink {
+	return &kafkaSink{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: timeout},
+		router:  router,
+	}
+}
+
+func (s *kafkaSink) Publish(ctx context.Context, eventType string, data interface{}) error {
+	topic, ok := s.router.topic(eventType)
+	if !ok {
+		return nil
+	}
+
+	envelope := newEnvelope(ctx, eventType, data)
+	body, err := json.Marshal(kafkaProduceRequest{Records: []kafkaRecord{{Key: envelope.ID, Value: envelope}}})
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	endpoint := s.baseURL + "/topics/" + url.PathEscape(topic)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid kafka url: %w", err)
+	}
+	req.Header.Set("Content-Type", kafkaContentType)
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to publish to kafka topic %s: %w", topic, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var decoded kafkaErrorResponse
+		raw, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(raw, &decoded) == nil && decoded.Message != "" {
+			return fmt.Errorf("kafka topic %s rejected event: %s", topic, decoded.Message)
+		}
+		return fmt.Errorf("kafka topic %s rejected event with status %d", topic, resp.StatusCode)
+	}
+	return nil
+}
+
+func (s *kafkaSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
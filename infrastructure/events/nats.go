@@ -0,0 +1,45 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// natsSink publishes events to NATS subjects named after their topic
+type natsSink struct {
+	conn   *nats.Conn
+	router *topicRouter
+}
+
+func newNATSSink(serverURL string, timeout time.Duration, router *topicRouter) (*natsSink, error) {
+	conn, err := nats.Connect(serverURL, nats.Name("portal-data-backend"), nats.Timeout(timeout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to nats: %w", err)
+	}
+	return &natsSink{conn: conn, router: router}, nil
+}
+
+func (s *natsSink) Publish(ctx context.Context, eventType string, data interface{}) error {
+	subject, ok := s.router.topic(eventType)
+	if !ok {
+		return nil
+	}
+
+	payload, err := json.Marshal(newEnvelope(ctx, eventType, data))
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+	if err := s.conn.Publish(subject, payload); err != nil {
+		return fmt.Errorf("failed to publish to nats subject %s: %w", subject, err)
+	}
+	return nil
+}
+
+func (s *natsSink) Close() error {
+	// Drain flushes buffered messages before closing
+	return s.conn.Drain()
+}
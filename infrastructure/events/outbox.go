@@ -0,0 +1,214 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/infrastructure/db"
+	"portal-data-backend/infrastructure/logger"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	// outboxBaseBackoff is the delay before relaying a failed event again; it
+	// doubles per attempt
+	outboxBaseBackoff = 5 * time.Second
+	// outboxMaxBackoff caps the delay between two attempts
+	outboxMaxBackoff = time.Hour
+	// maxOutboxError bounds how much of a relay error is kept
+	maxOutboxError = 1024
+)
+
+// Outbox is a publisher recording events in the outbox_events table, in the
+// transaction ctx carries, so an event exists exactly when the change that
+// raised it commits. A Relay hands recorded events on.
+type Outbox struct {
+	db *sqlx.DB
+	// wake tells the relay of this process that events were recorded
+	wake chan struct{}
+	now  func() time.Time
+}
+
+// NewOutbox creates an outbox recording events in db
+func NewOutbox(db *sqlx.DB) *Outbox {
+	return &Outbox{db: db, wake: make(chan struct{}, 1), now: time.Now}
+}
+
+// Publish implements Publisher. It fails when the event cannot be encoded
+// or recorded, which also fails the transaction ctx carries.
+func (o *Outbox) Publish(ctx context.Context, eventType string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	now := o.now().UTC()
+	query := `
+		INSERT INTO outbox_events (id, event_type, payload, occurred_at, next_attempt_at)
+		VALUES ($1, $2, $3, $4, $4)
+	`
+	if _, err := db.Conn(ctx, o.db).ExecContext(ctx, query, uuid.New().String(), eventType, payload, now); err != nil {
+		return fmt.Errorf("failed to record %s event: %w", eventType, err)
+	}
+	db.AfterCommit(ctx, o.notify)
+	return nil
+}
+
+func (o *Outbox) notify() {
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+}
+
+// outboxEvent is a recorded event waiting to be relayed
+type outboxEvent struct {
+	ID         string    `db:"id"`
+	Type       string    `db:"event_type"`
+	Payload    []byte    `db:"payload"`
+	OccurredAt time.Time `db:"occurred_at"`
+	Attempts   int       `db:"attempts"`
+}
+
+// Relay hands the events of an outbox to a publisher, usually the Bus, at
+// least once and oldest first. Events failing to publish are retried with a
+// growing delay. Relays of several instances share the
+// outbox; each event is locked by the relay handling it.
+type Relay struct {
+	outbox *Outbox
+	target Publisher
+	cfg    config.EventsConfig
+}
+
+// NewRelay creates a relay handing the events of outbox to target
+func NewRelay(outbox *Outbox, target Publisher, cfg config.EventsConfig) *Relay {
+	return &Relay{outbox: outbox, target: target, cfg: cfg}
+}
+
+// Run relays events until ctx is canceled: those recorded by this process
+// right after their transaction commits, the others within a poll interval
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.OutboxPollInterval)
+	defer ticker.Stop()
+
+	lastCleanup := r.outbox.now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-r.outbox.wake:
+		}
+
+		// Relay batches back to back until the backlog is drained
+		for {
+			relayed, err := r.RelayDue(ctx)
+			if err != nil {
+				logger.FromContext(ctx).Error("outbox relay failed: %v", err)
+				break
+			}
+			if relayed < r.cfg.OutboxBatchSize {
+				break
+			}
+		}
+
+		if r.outbox.now().Sub(lastCleanup) >= time.Hour {
+			lastCleanup = r.outbox.now()
+			if _, err := r.Cleanup(ctx); err != nil {
+				logger.FromContext(ctx).Error("outbox cleanup failed: %v", err)
+			}
+		}
+	}
+}
+
+// RelayDue publishes a batch of the events due, returning how many were
+// handled, published or not. Each is published in a savepoint of the
+// transaction marking it, so subscribers writing to the database, like the
+// webhook engine, commit their work with the mark.
+func (r *Relay) RelayDue(ctx context.Context) (int, error) {
+	var handled int
+	err := db.WithinTx(ctx, r.outbox.db, func(ctx context.Context) error {
+		conn := db.Conn(ctx, r.outbox.db)
+		now := r.outbox.now().UTC()
+
+		var due []outboxEvent
+		query := `
+			SELECT id, event_type, payload, occurred_at, attempts
+			FROM outbox_events
+			WHERE published_at IS NULL AND next_attempt_at <= $1
+			ORDER BY occurred_at, id
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		`
+		if err := conn.SelectContext(ctx, &due, query, now, r.cfg.OutboxBatchSize); err != nil {
+			return fmt.Errorf("failed to list due events: %w", err)
+		}
+
+		for _, event := range due {
+			err := db.Savepoint(ctx, func(ctx context.Context) error {
+				meta := Meta{ID: event.ID, OccurredAt: event.OccurredAt.UTC()}
+				return r.target.Publish(WithMeta(ctx, meta), event.Type, json.RawMessage(event.Payload))
+			})
+			if err := r.mark(ctx, conn, &event, now, err); err != nil {
+				return err
+			}
+			handled++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return handled, nil
+}
+
+// mark records the outcome of publishing event
+func (r *Relay) mark(ctx context.Context, conn db.Executor, event *outboxEvent, now time.Time, publishErr error) error {
+	attempts := event.Attempts + 1
+	if publishErr == nil {
+		query := `UPDATE outbox_events SET published_at = $2, attempts = $3, last_error = NULL WHERE id = $1`
+		if _, err := conn.ExecContext(ctx, query, event.ID, now, attempts); err != nil {
+			return fmt.Errorf("failed to mark event %s relayed: %w", event.ID, err)
+		}
+		return nil
+	}
+
+	logger.FromContext(ctx).Warn("failed to relay %s event %s (attempt %d): %v", event.Type, event.ID, attempts, publishErr)
+	message := publishErr.Error()
+	if len(message) > maxOutboxError {
+		message = message[:maxOutboxError]
+	}
+	query := `UPDATE outbox_events SET attempts = $2, next_attempt_at = $3, last_error = $4 WHERE id = $1`
+	if _, err := conn.ExecContext(ctx, query, event.ID, attempts, now.Add(outboxBackoff(attempts)), message); err != nil {
+		return fmt.Errorf("failed to reschedule event %s: %w", event.ID, err)
+	}
+	return nil
+}
+
+// Cleanup deletes the events relayed longer than the retention ago,
+// returning how many were deleted
+func (r *Relay) Cleanup(ctx context.Context) (int64, error) {
+	before := r.outbox.now().UTC().Add(-r.cfg.OutboxRetention)
+	result, err := db.Conn(ctx, r.outbox.db).ExecContext(ctx, `DELETE FROM outbox_events WHERE published_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete relayed events: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// outboxBackoff returns the delay before the attempt following the given one
+func outboxBackoff(attempts int) time.Duration {
+	delay := outboxBaseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= outboxMaxBackoff {
+			return outboxMaxBackoff
+		}
+	}
+	return delay
+}
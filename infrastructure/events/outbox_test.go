@@ -0,0 +1,114 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/infrastructure/db"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func newMockOutbox(t *testing.T) (*Outbox, sqlmock.Sqlmock) {
+	t.Helper()
+
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	outbox := NewOutbox(sqlx.NewDb(conn, "postgres"))
+	outbox.now = func() time.Time { return time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC) }
+	return outbox, mock
+}
+
+// Test events are recorded in the transaction of the change and wake the
+// relay once it commits
+func TestOutbox_Publish(t *testing.T) {
+	outbox, mock := newMockOutbox(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO outbox_events").
+		WithArgs(sqlmock.AnyArg(), "dataset.published", []byte(`{"id":"dataset-1"}`), outbox.now().UTC()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := db.WithinTx(context.Background(), outbox.db, func(ctx context.Context) error {
+		if err := outbox.Publish(ctx, "dataset.published", map[string]string{"id": "dataset-1"}); err != nil {
+			return err
+		}
+		if len(outbox.wake) != 0 {
+			t.Errorf("Expected the relay to wait for the commit")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(outbox.wake) != 1 {
+		t.Errorf("Expected the relay to be woken after the commit")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
+
+// Test relayed events keep their recorded ID and payload; failed ones are
+// rescheduled while the others are marked relayed
+func TestRelay_RelayDue(t *testing.T) {
+	outbox, mock := newMockOutbox(t)
+	now := outbox.now().UTC()
+	occurred := now.Add(-time.Minute)
+
+	var relayed []Meta
+	target := publishFunc(func(ctx context.Context, eventType string, data interface{}) error {
+		meta, _ := MetaFrom(ctx)
+		relayed = append(relayed, meta)
+		if eventType == "ticket.created" {
+			return errors.New("broker unavailable")
+		}
+		if payload, ok := data.(json.RawMessage); !ok || string(payload) != `{"id":"dataset-1"}` {
+			t.Errorf("Expected the recorded payload, got %v", data)
+		}
+		return nil
+	})
+	relay := NewRelay(outbox, target, config.EventsConfig{OutboxBatchSize: 10})
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, event_type, payload, occurred_at, attempts FROM outbox_events").
+		WithArgs(now, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "event_type", "payload", "occurred_at", "attempts"}).
+			AddRow("e1", "dataset.published", []byte(`{"id":"dataset-1"}`), occurred, 0).
+			AddRow("e2", "ticket.created", []byte(`{"id":"ticket-1"}`), occurred, 2))
+	mock.ExpectExec("SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("RELEASE SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE outbox_events SET published_at").
+		WithArgs("e1", now, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("SAVEPOINT sp_2").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT sp_2").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE outbox_events SET attempts").
+		WithArgs("e2", 3, now.Add(20*time.Second), "broker unavailable").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	handled, err := relay.RelayDue(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if handled != 2 {
+		t.Errorf("Expected 2 events handled, got %d", handled)
+	}
+	if len(relayed) != 2 || relayed[0].ID != "e1" || !relayed[0].OccurredAt.Equal(occurred) {
+		t.Errorf("Expected the events to keep their recorded ID and time, got %+v", relayed)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
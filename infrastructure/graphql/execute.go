@@ -0,0 +1,576 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Request is a GraphQL request as clients post it
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// Response is the result of a request. Data is absent when the request
+// could not be executed at all, and fields that failed are null with an
+// entry in Errors.
+type Response struct {
+	Data   interface{} `json:"data,omitempty"`
+	Errors []*Error    `json:"errors,omitempty"`
+}
+
+// Error is an error of a request or of one of its fields
+type Error struct {
+	Message   string        `json:"message"`
+	Locations []Location    `json:"locations,omitempty"`
+	Path      []interface{} `json:"path,omitempty"`
+	// Err is the error a resolver returned
+	Err error `json:"-"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Location is a position in the query, counted from 1
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// ErrorPresenter turns the error a resolver returned into the message sent
+// to the client, like hiding internal errors
+type ErrorPresenter func(ctx context.Context, err error) string
+
+// Execute runs the query of req. present may be nil, then resolver errors
+// are sent as they are.
+func (s *Schema) Execute(ctx context.Context, req *Request, present ErrorPresenter) *Response {
+	doc, err := parse(req.Query)
+	if err != nil {
+		return &Response{Errors: []*Error{requestError(req.Query, err)}}
+	}
+
+	op, err := doc.operation(req.OperationName)
+	if err != nil {
+		return &Response{Errors: []*Error{requestError(req.Query, err)}}
+	}
+
+	e := &executor{ctx: ctx, source: req.Query, doc: doc, op: op, present: present}
+	if e.variables, err = op.coerceVariables(req.Variables); err != nil {
+		return &Response{Errors: []*Error{requestError(req.Query, err)}}
+	}
+	v := &validator{executor: e, maxDepth: s.maxDepth(), visiting: map[string]bool{}}
+	v.selections(s.Query, op.selections, 1)
+	if len(v.errors) > 0 {
+		return &Response{Errors: v.errors}
+	}
+
+	data := newOrderedMap()
+	queue := e.executeFields(s.Query, nil, e.collect(s.Query, op.selections), nil, data)
+	for len(queue) > 0 {
+		var next []job
+		for _, j := range queue {
+			next = append(next, e.complete(j)...)
+		}
+		queue = next
+	}
+	return &Response{Data: data, Errors: e.errors}
+}
+
+func requestError(source string, err error) *Error {
+	gqlErr := &Error{Message: err.Error()}
+	if syntaxErr, ok := err.(*syntaxError); ok {
+		gqlErr.Locations = []Location{location(source, syntaxErr.pos)}
+	}
+	return gqlErr
+}
+
+func location(source string, pos int) Location {
+	if pos > len(source) {
+		pos = len(source)
+	}
+	before := source[:pos]
+	line := strings.Count(before, "\n") + 1
+	return Location{Line: line, Column: pos - strings.LastIndexByte(before, '\n')}
+}
+
+// operation picks the operation to run
+func (d *document) operation(name string) (*operation, error) {
+	var op *operation
+	switch {
+	case name != "":
+		for _, candidate := range d.operations {
+			if candidate.name == name {
+				op = candidate
+			}
+		}
+		if op == nil {
+			return nil, fmt.Errorf("Unknown operation named %q.", name)
+		}
+	case len(d.operations) > 1:
+		return nil, fmt.Errorf("Must provide operation name if query contains multiple operations.")
+	default:
+		op = d.operations[0]
+	}
+	if op.kind != "query" {
+		return nil, fmt.Errorf("Only queries are supported, not %ss.", op.kind)
+	}
+	return op, nil
+}
+
+// coerceVariables returns the values of the variables of op, given or
+// defaulted. Their types are checked against the arguments they are used in.
+func (op *operation) coerceVariables(given map[string]interface{}) (map[string]interface{}, error) {
+	values := make(map[string]interface{}, len(op.variables))
+	for _, def := range op.variables {
+		value, ok := given[def.name]
+		if !ok && def.hasDef {
+			value, ok = def.def, true
+		}
+		if (!ok || value == nil) && def.typ.nonNull {
+			return nil, fmt.Errorf("Variable \"$%s\" of required type %q was not provided.", def.name, def.typ.String())
+		}
+		if ok {
+			values[def.name] = value
+		}
+	}
+	return values, nil
+}
+
+type executor struct {
+	ctx       context.Context
+	source    string
+	doc       *document
+	op        *operation
+	variables map[string]interface{}
+	present   ErrorPresenter
+	errors    []*Error
+}
+
+// collectedField is a field of the response: the fields of the query with
+// the same response key, merged
+type collectedField struct {
+	key    string
+	fields []*field
+}
+
+// collect lists the fields selections select on objects of type o, in order
+func (e *executor) collect(o *Object, selections []selection) []*collectedField {
+	var collected []*collectedField
+	byKey := map[string]*collectedField{}
+	var walk func(selections []selection)
+	walk = func(selections []selection) {
+		for _, sel := range selections {
+			switch sel := sel.(type) {
+			case *field:
+				if !e.included(sel.directives) {
+					continue
+				}
+				cf, ok := byKey[sel.key()]
+				if !ok {
+					cf = &collectedField{key: sel.key()}
+					byKey[cf.key] = cf
+					collected = append(collected, cf)
+				}
+				cf.fields = append(cf.fields, sel)
+			case *fragmentSpread:
+				frag := e.doc.fragments[sel.name]
+				if frag != nil && frag.on == o.Name && e.included(sel.directives) && e.included(frag.directives) {
+					walk(frag.selections)
+				}
+			case *inlineFragment:
+				if (sel.on == "" || sel.on == o.Name) && e.included(sel.directives) {
+					walk(sel.selections)
+				}
+			}
+		}
+	}
+	walk(selections)
+	return collected
+}
+
+// included applies the @skip and @include directives
+func (e *executor) included(directives []*directive) bool {
+	for _, d := range directives {
+		if d.name != "skip" && d.name != "include" {
+			continue
+		}
+		var condition bool
+		for _, arg := range d.arguments {
+			if arg.name == "if" {
+				condition, _ = e.resolveValue(arg.value).(bool)
+			}
+		}
+		if d.name == "skip" && condition || d.name == "include" && !condition {
+			return false
+		}
+	}
+	return true
+}
+
+// resolveValue replaces the variables of a value with their value
+func (e *executor) resolveValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case variable:
+		return e.variables[string(v)]
+	case enumValue:
+		return string(v)
+	case []interface{}:
+		resolved := make([]interface{}, len(v))
+		for i, item := range v {
+			resolved[i] = e.resolveValue(item)
+		}
+		return resolved
+	case map[string]interface{}:
+		resolved := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			resolved[k] = e.resolveValue(item)
+		}
+		return resolved
+	}
+	return value
+}
+
+func (e *executor) arguments(def *Field, f *field) (map[string]interface{}, error) {
+	args := make(map[string]interface{}, len(def.Args))
+	for _, argDef := range def.Args {
+		var value interface{}
+		for _, arg := range f.arguments {
+			if arg.name == argDef.Name {
+				value = e.resolveValue(arg.value)
+			}
+		}
+		if value == nil {
+			value = argDef.Default
+		}
+		coerced, err := coerce(argDef.Type, value)
+		if err != nil {
+			return nil, fmt.Errorf("Argument %q has an invalid value: %v.", argDef.Name, err)
+		}
+		if coerced != nil {
+			args[argDef.Name] = coerced
+		}
+	}
+	return args, nil
+}
+
+// job is a field value to complete into the response
+type job struct {
+	typ    Type
+	value  interface{}
+	fields []*field
+	path   []interface{}
+	set    func(interface{})
+}
+
+// executeFields resolves the fields of an object into out and returns the
+// values to complete
+func (e *executor) executeFields(o *Object, source interface{}, fields []*collectedField, path []interface{}, out *orderedMap) []job {
+	var jobs []job
+	for _, cf := range fields {
+		f := cf.fields[0]
+		key := cf.key
+		fieldPath := appendPath(path, key)
+		if f.name == "__typename" {
+			out.set(key, o.Name)
+			continue
+		}
+
+		def := o.Field(f.name)
+		args, err := e.arguments(def, f)
+		if err != nil {
+			e.fail(f, fieldPath, err)
+			out.set(key, nil)
+			continue
+		}
+
+		var value interface{}
+		if def.Resolve != nil {
+			value, err = def.Resolve(Params{Context: e.ctx, Source: source, Args: args})
+		} else {
+			value = defaultResolve(source, def.Name)
+		}
+		out.set(key, nil)
+		if err != nil {
+			e.fail(f, fieldPath, err)
+			continue
+		}
+
+		jobs = append(jobs, job{
+			typ:    def.Type,
+			value:  value,
+			fields: cf.fields,
+			path:   fieldPath,
+			set:    func(v interface{}) { out.set(key, v) },
+		})
+	}
+	return jobs
+}
+
+// complete puts the value of j into the response. It returns the fields to
+// resolve next when the value is an object.
+func (e *executor) complete(j job) []job {
+	value := j.value
+	if thunk, ok := value.(Thunk); ok {
+		var err error
+		if value, err = thunk(); err != nil {
+			e.fail(j.fields[0], j.path, err)
+			return nil
+		}
+	}
+	if isNil(value) {
+		return nil
+	}
+
+	switch t := j.typ.(type) {
+	case *Scalar:
+		serialized, err := serialize(t, value)
+		if err != nil {
+			e.fail(j.fields[0], j.path, err)
+			return nil
+		}
+		j.set(serialized)
+		return nil
+
+	case *List:
+		v := reflect.ValueOf(value)
+		for v.Kind() == reflect.Pointer {
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			e.fail(j.fields[0], j.path, fmt.Errorf("expected a list, got %T", value))
+			return nil
+		}
+		items := make([]interface{}, v.Len())
+		j.set(items)
+		var jobs []job
+		for i := range items {
+			i := i
+			item := v.Index(i)
+			if item.Kind() == reflect.Struct && item.CanAddr() {
+				item = item.Addr()
+			}
+			jobs = append(jobs, e.complete(job{
+				typ:    t.Of,
+				value:  item.Interface(),
+				fields: j.fields,
+				path:   appendPath(j.path, i),
+				set:    func(v interface{}) { items[i] = v },
+			})...)
+		}
+		return jobs
+
+	case *Object:
+		out := newOrderedMap()
+		j.set(out)
+		var selections []selection
+		for _, f := range j.fields {
+			selections = append(selections, f.selections...)
+		}
+		return e.executeFields(t, value, e.collect(t, selections), j.path, out)
+	}
+	return nil
+}
+
+func (e *executor) fail(f *field, path []interface{}, err error) {
+	message := err.Error()
+	if e.present != nil {
+		message = e.present(e.ctx, err)
+	}
+	e.errors = append(e.errors, &Error{
+		Message:   message,
+		Locations: []Location{location(e.source, f.pos)},
+		Path:      path,
+		Err:       err,
+	})
+}
+
+func appendPath(path []interface{}, key interface{}) []interface{} {
+	return append(append(make([]interface{}, 0, len(path)+1), path...), key)
+}
+
+func isNil(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
+
+// validator checks the fields of a query exist before it runs
+type validator struct {
+	*executor
+	maxDepth int
+	visiting map[string]bool
+	errors   []*Error
+}
+
+func (v *validator) fail(pos int, format string, args ...interface{}) {
+	v.errors = append(v.errors, &Error{
+		Message:   fmt.Sprintf(format, args...),
+		Locations: []Location{location(v.source, pos)},
+	})
+}
+
+func (v *validator) selections(o *Object, selections []selection, depth int) {
+	if depth > v.maxDepth {
+		v.fail(position(selections[0]), "Query is nested deeper than %d levels.", v.maxDepth)
+		return
+	}
+
+	for _, sel := range selections {
+		switch sel := sel.(type) {
+		case *field:
+			v.field(o, sel, depth)
+		case *fragmentSpread:
+			frag, ok := v.doc.fragments[sel.name]
+			if !ok {
+				v.fail(sel.pos, "Unknown fragment %q.", sel.name)
+				continue
+			}
+			if frag.on != o.Name {
+				v.fail(sel.pos, "Fragment %q cannot be spread here as objects of type %q can never be of type %q.", sel.name, o.Name, frag.on)
+				continue
+			}
+			if v.visiting[frag.name] {
+				v.fail(sel.pos, "Cannot spread fragment %q within itself.", sel.name)
+				continue
+			}
+			v.visiting[frag.name] = true
+			v.selections(o, frag.selections, depth)
+			delete(v.visiting, frag.name)
+		case *inlineFragment:
+			if sel.on != "" && sel.on != o.Name {
+				v.fail(sel.pos, "Fragment cannot be spread here as objects of type %q can never be of type %q.", o.Name, sel.on)
+				continue
+			}
+			v.selections(o, sel.selections, depth)
+		}
+	}
+}
+
+func position(sel selection) int {
+	switch sel := sel.(type) {
+	case *field:
+		return sel.pos
+	case *fragmentSpread:
+		return sel.pos
+	case *inlineFragment:
+		return sel.pos
+	}
+	return 0
+}
+
+func (v *validator) field(o *Object, f *field, depth int) {
+	if f.name == "__typename" {
+		if len(f.selections) > 0 {
+			v.fail(f.pos, "Field \"__typename\" must not have a selection since type \"String\" has no subfields.")
+		}
+		return
+	}
+
+	def := o.Field(f.name)
+	if def == nil {
+		v.fail(f.pos, "Cannot query field %q on type %q.", f.name, o.Name)
+		return
+	}
+	for _, arg := range f.arguments {
+		known := false
+		for _, argDef := range def.Args {
+			known = known || argDef.Name == arg.name
+		}
+		if !known {
+			v.fail(arg.pos, "Unknown argument %q on field \"%s.%s\".", arg.name, o.Name, f.name)
+		}
+		if name, ok := arg.value.(variable); ok && !v.variableDefined(string(name)) {
+			v.fail(arg.pos, "Variable \"$%s\" is not defined.", name)
+		}
+	}
+
+	t := def.Type
+	for {
+		list, ok := t.(*List)
+		if !ok {
+			break
+		}
+		t = list.Of
+	}
+	object, isObject := t.(*Object)
+	switch {
+	case isObject && len(f.selections) == 0:
+		v.fail(f.pos, "Field %q of type %q must have a selection of subfields.", f.name, def.Type.String())
+	case !isObject && len(f.selections) > 0:
+		v.fail(f.pos, "Field %q must not have a selection since type %q has no subfields.", f.name, def.Type.String())
+	case isObject:
+		v.selections(object, f.selections, depth+1)
+	}
+}
+
+func (v *validator) variableDefined(name string) bool {
+	for _, def := range v.op.variables {
+		if def.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// orderedMap is a JSON object keeping the order of its keys, which is the
+// order fields were queried in
+type orderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func newOrderedMap() *orderedMap {
+	return &orderedMap{values: map[string]interface{}{}}
+}
+
+func (m *orderedMap) set(key string, value interface{}) {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+// Get returns the value of key
+func (m *orderedMap) Get(key string) interface{} {
+	return m.values[key]
+}
+
+// MarshalJSON implements json.Marshaler
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(k)
+		b.WriteByte(':')
+		v, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(v)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
@@ -0,0 +1,197 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testOrg struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type testDataset struct {
+	ID             string    `json:"id"`
+	Name           string    `json:"name"`
+	OrganizationID string    `json:"organization_id"`
+	CreatedAt      time.Time `json:"created_at"`
+	Description    *string   `json:"description,omitempty"`
+}
+
+// testSchema serves datasets whose organizations are loaded in batches,
+// counting the batches in batches. Requests run with the context newContext
+// returns, which holds their loader.
+func testSchema(batches *int) (schema *Schema, newContext func() context.Context) {
+	orgs := map[string]*testOrg{"o1": {"o1", "Bappeda"}, "o2": {"o2", "Diskominfo"}}
+	description := "Population by district"
+	datasets := []testDataset{
+		{"d1", "Population", "o1", time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), &description},
+		{"d2", "Schools", "o2", time.Date(2026, 2, 3, 4, 5, 6, 0, time.UTC), nil},
+		{"d3", "Hospitals", "o1", time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC), nil},
+	}
+
+	type loaderKey struct{}
+	organization := &Object{Name: "Organization", Fields: []*Field{
+		{Name: "id", Type: ID},
+		{Name: "name", Type: String},
+	}}
+	dataset := &Object{Name: "Dataset", Fields: []*Field{
+		{Name: "id", Type: ID},
+		{Name: "name", Type: String},
+		{Name: "description", Type: String},
+		{Name: "createdAt", Type: Time},
+		{Name: "organization", Type: organization, Resolve: func(p Params) (interface{}, error) {
+			loader := p.Context.Value(loaderKey{}).(*Loader[string, *testOrg])
+			return loader.Load(p.Context, p.Source.(*testDataset).OrganizationID), nil
+		}},
+	}}
+	query := &Object{Name: "Query", Fields: []*Field{
+		{Name: "datasets", Type: NewList(dataset), Args: []*Argument{{Name: "limit", Type: Int, Default: 10}}, Resolve: func(p Params) (interface{}, error) {
+			limit := p.Int("limit")
+			if limit > len(datasets) {
+				limit = len(datasets)
+			}
+			return datasets[:limit], nil
+		}},
+		{Name: "dataset", Type: dataset, Args: []*Argument{{Name: "id", Type: ID}}, Resolve: func(p Params) (interface{}, error) {
+			for i := range datasets {
+				if datasets[i].ID == p.String("id") {
+					return &datasets[i], nil
+				}
+			}
+			return nil, fmt.Errorf("dataset %s not found", p.String("id"))
+		}},
+	}}
+
+	newContext = func() context.Context {
+		return context.WithValue(context.Background(), loaderKey{}, NewLoader(func(ctx context.Context, ids []string) (map[string]*testOrg, error) {
+			*batches++
+			found := map[string]*testOrg{}
+			for _, id := range ids {
+				found[id] = orgs[id]
+			}
+			return found, nil
+		}))
+	}
+	return &Schema{Query: query, MaxDepth: 3}, newContext
+}
+
+func execute(t *testing.T, req *Request) (string, int) {
+	t.Helper()
+	var batches int
+	schema, newContext := testSchema(&batches)
+	resp := schema.Execute(newContext(), req, nil)
+	body, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatalf("Failed to marshal response: %v", err)
+	}
+	return string(body), batches
+}
+
+// Test the organizations of every dataset of a list load in one batch
+func TestExecute_BatchesLoads(t *testing.T) {
+	got, batches := execute(t, &Request{Query: `{ datasets { id organization { name } } }`})
+
+	want := `{"data":{"datasets":[{"id":"d1","organization":{"name":"Bappeda"}},{"id":"d2","organization":{"name":"Diskominfo"}},{"id":"d3","organization":{"name":"Bappeda"}}]}}`
+	if got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+	if batches != 1 {
+		t.Errorf("Expected 1 batch, got %d", batches)
+	}
+}
+
+// Test aliases, variables, fragments, directives and scalars
+func TestExecute_QueryLanguage(t *testing.T) {
+	got, _ := execute(t, &Request{
+		Query: `
+			query Catalog($id: ID!, $limit: Int = 1, $withOrg: Boolean!) {
+				first: dataset(id: $id) { ...details }
+				datasets(limit: $limit) {
+					... on Dataset { name }
+					organization @include(if: $withOrg) { id }
+					__typename
+				}
+			}
+			fragment details on Dataset { name description createdAt missing: description }`,
+		Variables: map[string]interface{}{"id": "d1", "withOrg": false},
+	})
+
+	want := `{"data":{"first":{"name":"Population","description":"Population by district","createdAt":"2026-01-02T03:04:05Z","missing":"Population by district"},"datasets":[{"name":"Population","__typename":"Dataset"}]}}`
+	if got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+}
+
+// Test a failing field is null with an error at its path
+func TestExecute_FieldError(t *testing.T) {
+	got, _ := execute(t, &Request{Query: `{ dataset(id: "d9") { id } datasets(limit: 1) { id } }`})
+
+	want := `{"data":{"dataset":null,"datasets":[{"id":"d1"}]},"errors":[{"message":"dataset d9 not found","locations":[{"line":1,"column":3}],"path":["dataset"]}]}`
+	if got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+}
+
+// Test invalid queries are rejected before running
+func TestExecute_RequestErrors(t *testing.T) {
+	var batches int
+	schema, newContext := testSchema(&batches)
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{`{ datasets { id `, "Syntax Error: Unexpected end of document."},
+		{`{ datasets { title } }`, `Cannot query field "title" on type "Dataset".`},
+		{`{ datasets(first: 2) { id } }`, `Unknown argument "first" on field "Query.datasets".`},
+		{`{ datasets }`, `Field "datasets" of type "[Dataset]" must have a selection of subfields.`},
+		{`{ datasets { id { x } } }`, `Field "id" must not have a selection since type "ID" has no subfields.`},
+		{`{ datasets { ...missing } }`, `Unknown fragment "missing".`},
+		{`{ datasets { ...a } } fragment a on Dataset { ...a }`, `Cannot spread fragment "a" within itself.`},
+		{`{ datasets { organization { x: organization { id } } } }`, `Cannot query field "organization" on type "Organization".`},
+		{`mutation { datasets { id } }`, "Only queries are supported, not mutations."},
+		{`query ($id: ID!) { dataset(id: $id) { id } }`, `Variable "$id" of required type "ID!" was not provided.`},
+		{`{ dataset(id: $id) { id } }`, `Variable "$id" is not defined.`},
+	}
+
+	for _, tt := range tests {
+		resp := schema.Execute(newContext(), &Request{Query: tt.query}, nil)
+		if resp.Data != nil || len(resp.Errors) == 0 || resp.Errors[0].Message != tt.want {
+			body, _ := json.Marshal(resp)
+			t.Errorf("Expected %q for %s, got %s", tt.want, tt.query, body)
+		}
+	}
+}
+
+// Test queries nesting deeper than the maximum depth are rejected
+func TestExecute_MaxDepth(t *testing.T) {
+	var batches int
+	schema, newContext := testSchema(&batches)
+	schema.MaxDepth = 1
+
+	resp := schema.Execute(newContext(), &Request{Query: `{ datasets { organization { id } } }`}, nil)
+	if len(resp.Errors) != 1 || resp.Errors[0].Message != "Query is nested deeper than 1 levels." {
+		t.Errorf("Expected a depth error, got %+v", resp.Errors)
+	}
+}
+
+// Test the schema is described in SDL
+func TestSchema_SDL(t *testing.T) {
+	var batches int
+	schema, _ := testSchema(&batches)
+	sdl := schema.SDL()
+
+	for _, want := range []string{
+		"scalar Time\n",
+		"type Query {\n  datasets(limit: Int = 10): [Dataset]\n  dataset(id: ID): Dataset\n}\n",
+		"type Organization {\n  id: ID\n  name: String\n}\n",
+	} {
+		if !strings.Contains(sdl, want) {
+			t.Errorf("Expected the SDL to contain %q, got:\n%s", want, sdl)
+		}
+	}
+}
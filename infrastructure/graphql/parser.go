@@ -0,0 +1,657 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// The document a request holds: its operations and fragments. Values of
+// arguments are Go values: int, float64, string, bool, nil, []interface{},
+// map[string]interface{}, variable for $name references and enumValue for
+// bare names.
+type document struct {
+	operations []*operation
+	fragments  map[string]*fragment
+}
+
+type operation struct {
+	kind       string
+	name       string
+	variables  []*variableDefinition
+	selections []selection
+	pos        int
+}
+
+type variableDefinition struct {
+	name   string
+	typ    *typeRef
+	def    interface{}
+	hasDef bool
+	pos    int
+}
+
+type typeRef struct {
+	name    string
+	of      *typeRef
+	nonNull bool
+}
+
+func (t *typeRef) String() string {
+	s := t.name
+	if t.of != nil {
+		s = "[" + t.of.String() + "]"
+	}
+	if t.nonNull {
+		s += "!"
+	}
+	return s
+}
+
+type selection interface{}
+
+type field struct {
+	alias      string
+	name       string
+	arguments  []*argument
+	directives []*directive
+	selections []selection
+	pos        int
+}
+
+// key is the name of the field in the response
+func (f *field) key() string {
+	if f.alias != "" {
+		return f.alias
+	}
+	return f.name
+}
+
+type fragmentSpread struct {
+	name       string
+	directives []*directive
+	pos        int
+}
+
+type inlineFragment struct {
+	on         string
+	directives []*directive
+	selections []selection
+	pos        int
+}
+
+type fragment struct {
+	name       string
+	on         string
+	directives []*directive
+	selections []selection
+	pos        int
+}
+
+type argument struct {
+	name  string
+	value interface{}
+	pos   int
+}
+
+type directive struct {
+	name      string
+	arguments []*argument
+}
+
+type variable string
+
+type enumValue string
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunctuator
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+// syntaxError is an error in the text of a document
+type syntaxError struct {
+	pos     int
+	message string
+}
+
+func (e *syntaxError) Error() string {
+	return "Syntax Error: " + e.message
+}
+
+// lex splits source into tokens, dropping whitespace, commas and comments
+func lex(source string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(source); {
+		c := source[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+		case c == 0xEF && strings.HasPrefix(source[i:], "\uFEFF"):
+			i += 3
+		case c == '#':
+			for i < len(source) && source[i] != '\n' && source[i] != '\r' {
+				i++
+			}
+		case strings.HasPrefix(source[i:], "..."):
+			tokens = append(tokens, token{tokenPunctuator, "...", i})
+			i += 3
+		case strings.IndexByte("!$()&:=@[]{}|", c) >= 0:
+			tokens = append(tokens, token{tokenPunctuator, string(c), i})
+			i++
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			start := i
+			for i < len(source) && isNameChar(source[i]) {
+				i++
+			}
+			tokens = append(tokens, token{tokenName, source[start:i], start})
+		case c == '-' || c >= '0' && c <= '9':
+			tok, next, err := lexNumber(source, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, tok)
+			i = next
+		case c == '"':
+			tok, next, err := lexString(source, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, tok)
+			i = next
+		default:
+			r, _ := utf8.DecodeRuneInString(source[i:])
+			return nil, &syntaxError{i, fmt.Sprintf("Unexpected character %q.", r)}
+		}
+	}
+	return append(tokens, token{tokenEOF, "", len(source)}), nil
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+func lexNumber(source string, start int) (token, int, error) {
+	i := start
+	digits := func() int {
+		n := 0
+		for i < len(source) && source[i] >= '0' && source[i] <= '9' {
+			i++
+			n++
+		}
+		return n
+	}
+
+	if source[i] == '-' {
+		i++
+	}
+	if digits() == 0 {
+		return token{}, 0, &syntaxError{start, "Invalid number."}
+	}
+	kind := tokenInt
+	if i < len(source) && source[i] == '.' {
+		i++
+		kind = tokenFloat
+		if digits() == 0 {
+			return token{}, 0, &syntaxError{start, "Invalid number."}
+		}
+	}
+	if i < len(source) && (source[i] == 'e' || source[i] == 'E') {
+		i++
+		kind = tokenFloat
+		if i < len(source) && (source[i] == '+' || source[i] == '-') {
+			i++
+		}
+		if digits() == 0 {
+			return token{}, 0, &syntaxError{start, "Invalid number."}
+		}
+	}
+	if i < len(source) && isNameChar(source[i]) {
+		return token{}, 0, &syntaxError{start, "Invalid number."}
+	}
+	return token{kind, source[start:i], start}, i, nil
+}
+
+func lexString(source string, start int) (token, int, error) {
+	if strings.HasPrefix(source[start:], `"""`) {
+		end := strings.Index(source[start+3:], `"""`)
+		if end < 0 {
+			return token{}, 0, &syntaxError{start, "Unterminated string."}
+		}
+		value := strings.TrimSpace(source[start+3 : start+3+end])
+		return token{tokenString, value, start}, start + 3 + end + 3, nil
+	}
+
+	var b strings.Builder
+	for i := start + 1; i < len(source); {
+		c := source[i]
+		switch c {
+		case '"':
+			return token{tokenString, b.String(), start}, i + 1, nil
+		case '\n', '\r':
+			return token{}, 0, &syntaxError{start, "Unterminated string."}
+		case '\\':
+			if i+1 >= len(source) {
+				return token{}, 0, &syntaxError{start, "Unterminated string."}
+			}
+			switch esc := source[i+1]; esc {
+			case '"', '\\', '/':
+				b.WriteByte(esc)
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				if i+6 > len(source) {
+					return token{}, 0, &syntaxError{i, "Invalid unicode escape."}
+				}
+				code, err := strconv.ParseUint(source[i+2:i+6], 16, 32)
+				if err != nil {
+					return token{}, 0, &syntaxError{i, "Invalid unicode escape."}
+				}
+				b.WriteRune(rune(code))
+				i += 4
+			default:
+				return token{}, 0, &syntaxError{i, fmt.Sprintf("Invalid escape sequence \\%c.", esc)}
+			}
+			i += 2
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return token{}, 0, &syntaxError{start, "Unterminated string."}
+}
+
+type parser struct {
+	tokens []token
+	i      int
+}
+
+// parse parses the document in source
+func parse(source string) (*document, error) {
+	tokens, err := lex(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	doc := &document{fragments: make(map[string]*fragment)}
+	for p.peek().kind != tokenEOF {
+		tok := p.peek()
+		switch {
+		case tok.value == "{":
+			selections, err := p.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, &operation{kind: "query", selections: selections, pos: tok.pos})
+		case tok.kind == tokenName && (tok.value == "query" || tok.value == "mutation" || tok.value == "subscription"):
+			op, err := p.operation()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, op)
+		case tok.kind == tokenName && tok.value == "fragment":
+			frag, err := p.fragment()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := doc.fragments[frag.name]; ok {
+				return nil, &syntaxError{frag.pos, fmt.Sprintf("There can be only one fragment named %q.", frag.name)}
+			}
+			doc.fragments[frag.name] = frag
+		default:
+			return nil, p.unexpected()
+		}
+	}
+	if len(doc.operations) == 0 {
+		return nil, &syntaxError{0, "Document has no operation."}
+	}
+	return doc, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.i]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.i]
+	if tok.kind != tokenEOF {
+		p.i++
+	}
+	return tok
+}
+
+func (p *parser) unexpected() error {
+	tok := p.peek()
+	if tok.kind == tokenEOF {
+		return &syntaxError{tok.pos, "Unexpected end of document."}
+	}
+	return &syntaxError{tok.pos, fmt.Sprintf("Unexpected %q.", tok.value)}
+}
+
+// skip consumes the punctuator value when it comes next
+func (p *parser) skip(value string) bool {
+	if tok := p.peek(); tok.kind == tokenPunctuator && tok.value == value {
+		p.i++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(value string) error {
+	if !p.skip(value) {
+		return p.unexpected()
+	}
+	return nil
+}
+
+func (p *parser) name() (string, error) {
+	if p.peek().kind != tokenName {
+		return "", p.unexpected()
+	}
+	return p.next().value, nil
+}
+
+func (p *parser) operation() (*operation, error) {
+	op := &operation{kind: p.peek().value, pos: p.next().pos}
+	if p.peek().kind == tokenName {
+		op.name = p.next().value
+	}
+	if p.skip("(") {
+		for !p.skip(")") {
+			def, err := p.variableDefinition()
+			if err != nil {
+				return nil, err
+			}
+			op.variables = append(op.variables, def)
+		}
+	}
+	if _, err := p.directives(); err != nil {
+		return nil, err
+	}
+	selections, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+	op.selections = selections
+	return op, nil
+}
+
+func (p *parser) variableDefinition() (*variableDefinition, error) {
+	pos := p.peek().pos
+	if err := p.expect("$"); err != nil {
+		return nil, err
+	}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	typ, err := p.typeRef()
+	if err != nil {
+		return nil, err
+	}
+	def := &variableDefinition{name: name, typ: typ, pos: pos}
+	if p.skip("=") {
+		if def.def, err = p.value(true); err != nil {
+			return nil, err
+		}
+		def.hasDef = true
+	}
+	return def, nil
+}
+
+func (p *parser) typeRef() (*typeRef, error) {
+	t := &typeRef{}
+	if p.skip("[") {
+		of, err := p.typeRef()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		t.of = of
+	} else {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		t.name = name
+	}
+	t.nonNull = p.skip("!")
+	return t, nil
+}
+
+func (p *parser) fragment() (*fragment, error) {
+	frag := &fragment{pos: p.next().pos}
+	var err error
+	if frag.name, err = p.name(); err != nil {
+		return nil, err
+	}
+	if on, err := p.name(); err != nil || on != "on" {
+		return nil, &syntaxError{p.peek().pos, `Expected "on".`}
+	}
+	if frag.on, err = p.name(); err != nil {
+		return nil, err
+	}
+	if frag.directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	if frag.selections, err = p.selectionSet(); err != nil {
+		return nil, err
+	}
+	return frag, nil
+}
+
+func (p *parser) selectionSet() ([]selection, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var selections []selection
+	for !p.skip("}") {
+		sel, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, sel)
+	}
+	if len(selections) == 0 {
+		return nil, &syntaxError{p.tokens[p.i-1].pos, "Selection set is empty."}
+	}
+	return selections, nil
+}
+
+func (p *parser) selection() (selection, error) {
+	pos := p.peek().pos
+	if !p.skip("...") {
+		return p.field()
+	}
+
+	if tok := p.peek(); tok.kind == tokenName && tok.value != "on" {
+		spread := &fragmentSpread{name: p.next().value, pos: pos}
+		var err error
+		if spread.directives, err = p.directives(); err != nil {
+			return nil, err
+		}
+		return spread, nil
+	}
+
+	inline := &inlineFragment{pos: pos}
+	if tok := p.peek(); tok.kind == tokenName && tok.value == "on" {
+		p.next()
+		on, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		inline.on = on
+	}
+	var err error
+	if inline.directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	if inline.selections, err = p.selectionSet(); err != nil {
+		return nil, err
+	}
+	return inline, nil
+}
+
+func (p *parser) field() (*field, error) {
+	f := &field{pos: p.peek().pos}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if p.skip(":") {
+		f.alias = name
+		if name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	f.name = name
+	if f.arguments, err = p.arguments(false); err != nil {
+		return nil, err
+	}
+	if f.directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	if p.peek().value == "{" && p.peek().kind == tokenPunctuator {
+		if f.selections, err = p.selectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+func (p *parser) arguments(constant bool) ([]*argument, error) {
+	if !p.skip("(") {
+		return nil, nil
+	}
+	var args []*argument
+	for !p.skip(")") {
+		arg := &argument{pos: p.peek().pos}
+		var err error
+		if arg.name, err = p.name(); err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		if arg.value, err = p.value(constant); err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	return args, nil
+}
+
+func (p *parser) directives() ([]*directive, error) {
+	var directives []*directive
+	for p.skip("@") {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		args, err := p.arguments(false)
+		if err != nil {
+			return nil, err
+		}
+		directives = append(directives, &directive{name: name, arguments: args})
+	}
+	return directives, nil
+}
+
+// value parses a value; constant values may not reference variables
+func (p *parser) value(constant bool) (interface{}, error) {
+	tok := p.peek()
+	switch tok.kind {
+	case tokenInt:
+		p.next()
+		n, err := strconv.Atoi(tok.value)
+		if err != nil {
+			return nil, &syntaxError{tok.pos, fmt.Sprintf("Integer %s is out of range.", tok.value)}
+		}
+		return n, nil
+	case tokenFloat:
+		p.next()
+		f, err := strconv.ParseFloat(tok.value, 64)
+		if err != nil {
+			return nil, &syntaxError{tok.pos, fmt.Sprintf("Invalid float %s.", tok.value)}
+		}
+		return f, nil
+	case tokenString:
+		p.next()
+		return tok.value, nil
+	case tokenName:
+		p.next()
+		switch tok.value {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+		return enumValue(tok.value), nil
+	}
+
+	switch {
+	case tok.value == "$" && !constant:
+		p.next()
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		return variable(name), nil
+	case tok.value == "[":
+		p.next()
+		list := []interface{}{}
+		for !p.skip("]") {
+			v, err := p.value(constant)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return list, nil
+	case tok.value == "{":
+		p.next()
+		object := map[string]interface{}{}
+		for !p.skip("}") {
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			if object[name], err = p.value(constant); err != nil {
+				return nil, err
+			}
+		}
+		return object, nil
+	}
+	return nil, p.unexpected()
+}
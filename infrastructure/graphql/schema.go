@@ -0,0 +1,393 @@
+// Package graphql serves read-only GraphQL queries over a schema declared in
+// Go. It supports the query language clients use to fetch data: fields with
+// arguments and aliases, variables, fragments and the @skip and @include
+// directives. Mutations, subscriptions and introspection beyond __typename
+// are not supported; Schema.SDL describes the schema for client tooling.
+//
+// Fields are resolved a level of the response at a time, so resolvers
+// returning a Thunk, like Loader.Load does, have their loads batched across
+// every object of the level.
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Type is the type of a field: a *Scalar, an *Object or a *List of them
+type Type interface {
+	String() string
+}
+
+// Scalar is a leaf type
+type Scalar struct {
+	Name        string
+	Description string
+}
+
+func (s *Scalar) String() string {
+	return s.Name
+}
+
+// The scalars of the schema. Time is serialized as an RFC 3339 string and
+// JSON as the value itself.
+var (
+	String  = &Scalar{Name: "String"}
+	Int     = &Scalar{Name: "Int"}
+	Float   = &Scalar{Name: "Float"}
+	Boolean = &Scalar{Name: "Boolean"}
+	ID      = &Scalar{Name: "ID"}
+	Time    = &Scalar{Name: "Time", Description: "An RFC 3339 timestamp"}
+	JSON    = &Scalar{Name: "JSON", Description: "Any JSON value"}
+)
+
+// List is a list of values of a type
+type List struct {
+	Of Type
+}
+
+func (l *List) String() string {
+	return "[" + l.Of.String() + "]"
+}
+
+// NewList returns a list of of
+func NewList(of Type) *List {
+	return &List{Of: of}
+}
+
+// Object is a type with fields
+type Object struct {
+	Name        string
+	Description string
+	Fields      []*Field
+}
+
+func (o *Object) String() string {
+	return o.Name
+}
+
+// Field returns the field named name, or nil when there is none
+func (o *Object) Field(name string) *Field {
+	for _, f := range o.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// Field is a field of an object
+type Field struct {
+	Name        string
+	Description string
+	Type        Type
+	Args        []*Argument
+	// Resolve returns the value of the field, or a Thunk returning it. When
+	// nil the value is read from the source: the entry of a map named like
+	// the field, or the struct field whose JSON name is the field name in
+	// snake case.
+	Resolve func(p Params) (interface{}, error)
+}
+
+// Argument is an argument of a field. Arguments are scalars or lists of
+// scalars; those not given take Default.
+type Argument struct {
+	Name        string
+	Description string
+	Type        Type
+	Default     interface{}
+}
+
+// Params are what a field is resolved from
+type Params struct {
+	Context context.Context
+	// Source is the value of the object the field belongs to, nil for the
+	// fields of the query type
+	Source interface{}
+	// Args holds the arguments given or defaulted, coerced to string, int,
+	// float64, bool or a []interface{} of them
+	Args map[string]interface{}
+}
+
+// String returns the string argument name, or "" when it is not set
+func (p Params) String(name string) string {
+	s, _ := p.Args[name].(string)
+	return s
+}
+
+// Int returns the int argument name, or 0 when it is not set
+func (p Params) Int(name string) int {
+	n, _ := p.Args[name].(int)
+	return n
+}
+
+// Thunk is a value resolved later, once every field of the level of the
+// response has been resolved
+type Thunk func() (interface{}, error)
+
+// Schema is the types a GraphQL endpoint serves, starting from Query
+type Schema struct {
+	Query *Object
+	// MaxDepth bounds how deeply queries may nest selections; 0 means 10
+	MaxDepth int
+}
+
+func (s *Schema) maxDepth() int {
+	if s.MaxDepth > 0 {
+		return s.MaxDepth
+	}
+	return 10
+}
+
+// SDL describes the schema in the GraphQL schema definition language
+func (s *Schema) SDL() string {
+	var objects []*Object
+	scalars := map[string]*Scalar{}
+	seen := map[string]bool{}
+	var visit func(t Type)
+	visit = func(t Type) {
+		switch t := t.(type) {
+		case *List:
+			visit(t.Of)
+		case *Scalar:
+			scalars[t.Name] = t
+		case *Object:
+			if seen[t.Name] {
+				return
+			}
+			seen[t.Name] = true
+			objects = append(objects, t)
+			for _, f := range t.Fields {
+				visit(f.Type)
+				for _, arg := range f.Args {
+					visit(arg.Type)
+				}
+			}
+		}
+	}
+	visit(s.Query)
+
+	var b strings.Builder
+	builtin := map[string]bool{"String": true, "Int": true, "Float": true, "Boolean": true, "ID": true}
+	var names []string
+	for name := range scalars {
+		if !builtin[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		writeDescription(&b, "", scalars[name].Description)
+		fmt.Fprintf(&b, "scalar %s\n\n", name)
+	}
+
+	for i, o := range objects {
+		writeDescription(&b, "", o.Description)
+		fmt.Fprintf(&b, "type %s {\n", o.Name)
+		for _, f := range o.Fields {
+			writeDescription(&b, "  ", f.Description)
+			b.WriteString("  " + f.Name)
+			if len(f.Args) > 0 {
+				args := make([]string, len(f.Args))
+				for j, arg := range f.Args {
+					args[j] = arg.Name + ": " + arg.Type.String()
+					if arg.Default != nil {
+						args[j] += " = " + literal(arg.Default)
+					}
+				}
+				b.WriteString("(" + strings.Join(args, ", ") + ")")
+			}
+			b.WriteString(": " + f.Type.String() + "\n")
+		}
+		b.WriteString("}\n")
+		if i < len(objects)-1 {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+func writeDescription(b *strings.Builder, indent, description string) {
+	if description != "" {
+		b.WriteString(indent + strconv.Quote(description) + "\n")
+	}
+}
+
+func literal(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return strconv.Quote(s)
+	}
+	return fmt.Sprint(v)
+}
+
+// defaultResolve reads the field name of source
+func defaultResolve(source interface{}, name string) interface{} {
+	if m, ok := source.(map[string]interface{}); ok {
+		return m[name]
+	}
+
+	v := reflect.ValueOf(source)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	jsonName := snakeCase(name)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if tag == jsonName || tag == "" && strings.EqualFold(f.Name, name) {
+			return v.Field(i).Interface()
+		}
+	}
+	return nil
+}
+
+// snakeCase turns a field name like validationStatus into validation_status
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// serialize converts the value of a scalar field for the response
+func serialize(scalar *Scalar, value interface{}) (interface{}, error) {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	value = v.Interface()
+
+	switch scalar {
+	case Time:
+		t, ok := value.(time.Time)
+		if !ok {
+			return nil, fmt.Errorf("Time cannot represent %T", value)
+		}
+		return t.Format(time.RFC3339), nil
+	case Int:
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return v.Int(), nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return int64(v.Uint()), nil
+		}
+		return nil, fmt.Errorf("Int cannot represent %T", value)
+	case Float:
+		switch v.Kind() {
+		case reflect.Float32, reflect.Float64:
+			return v.Float(), nil
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return float64(v.Int()), nil
+		}
+		return nil, fmt.Errorf("Float cannot represent %T", value)
+	case Boolean:
+		if v.Kind() != reflect.Bool {
+			return nil, fmt.Errorf("Boolean cannot represent %T", value)
+		}
+		return v.Bool(), nil
+	case String, ID:
+		if v.Kind() == reflect.String {
+			return v.String(), nil
+		}
+		if scalar == ID && v.Kind() >= reflect.Int && v.Kind() <= reflect.Int64 {
+			return strconv.FormatInt(v.Int(), 10), nil
+		}
+		return nil, fmt.Errorf("%s cannot represent %T", scalar.Name, value)
+	}
+	return value, nil
+}
+
+// coerce converts the value of an argument to type t
+func coerce(t Type, value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	if list, ok := t.(*List); ok {
+		values, ok := value.([]interface{})
+		if !ok {
+			values = []interface{}{value}
+		}
+		coerced := make([]interface{}, len(values))
+		for i, v := range values {
+			c, err := coerce(list.Of, v)
+			if err != nil {
+				return nil, err
+			}
+			coerced[i] = c
+		}
+		return coerced, nil
+	}
+
+	scalar, ok := t.(*Scalar)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an input type", t)
+	}
+	switch scalar {
+	case Int:
+		switch n := value.(type) {
+		case int:
+			return n, nil
+		case float64:
+			if n == float64(int(n)) {
+				return int(n), nil
+			}
+		}
+	case Float:
+		switch n := value.(type) {
+		case int:
+			return float64(n), nil
+		case float64:
+			return n, nil
+		}
+	case Boolean:
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+	case String:
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+	case ID:
+		switch id := value.(type) {
+		case string:
+			return id, nil
+		case int:
+			return strconv.Itoa(id), nil
+		case float64:
+			if id == float64(int(id)) {
+				return strconv.Itoa(int(id)), nil
+			}
+		}
+	default:
+		return value, nil
+	}
+	return nil, fmt.Errorf("%s cannot represent %s", scalar.Name, literal(value))
+}
@@ -0,0 +1,194 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"portal-data-backend/infrastructure/auth"
+	"portal-data-backend/infrastructure/http/problem"
+	"portal-data-backend/infrastructure/logger"
+	"portal-data-backend/infrastructure/security"
+	"portal-data-backend/infrastructure/tenant"
+	"portal-data-backend/pkg/client"
+	"portal-data-backend/pkg/errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Logging logs every call with the request logger of its context
+func Logging(base *logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		callLogger := base.With("method", info.FullMethod)
+
+		resp, err := handler(logger.WithContext(ctx, callLogger), req)
+
+		callLogger.With(
+			"code", status.Code(err).String(),
+			"duration_ms", time.Since(start).Milliseconds(),
+		).Info("call completed")
+		return resp, err
+	}
+}
+
+// Recovery answers calls whose handler panics with INTERNAL
+func Recovery(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			logger.FromContext(ctx).Error("panic: %v\n%s", rec, debug.Stack())
+			err = status.Error(codes.Internal, "internal server error")
+		}
+	}()
+	return handler(ctx, req)
+}
+
+// Status converts the errors of handlers to gRPC statuses. Errors map like
+// they do for REST, through problem.Default; those without a mapping are
+// internal errors, logged and not sent.
+func Status(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	resp, err := handler(ctx, req)
+	if err == nil {
+		return resp, nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return resp, err
+	}
+
+	mapping, ok := problem.Default.Lookup(err)
+	if !ok || mapping.Status >= http.StatusInternalServerError && mapping.Status != http.StatusServiceUnavailable {
+		logger.FromContext(ctx).Error("%s failed: %v", info.FullMethod, err)
+		if !ok {
+			return nil, status.Error(codes.Internal, "internal server error")
+		}
+	}
+	message := mapping.Message
+	if message == "" {
+		message = err.Error()
+	}
+	return nil, status.Error(Code(mapping.Status), message)
+}
+
+// Code is the gRPC code of an HTTP status
+func Code(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	default:
+		return codes.Internal
+	}
+}
+
+// Tenant scopes calls to the tenant named by the header metadata, or else
+// fallback, like the Tenant middleware does for HTTP requests
+func Tenant(resolver *tenant.Resolver, header, fallback string) grpc.UnaryServerInterceptor {
+	header = strings.ToLower(header)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		t, err := resolver.Resolve(ctx, firstMetadata(ctx, header), "", fallback)
+		if errors.Is(err, errors.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, "portal not found")
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		ctx = tenant.WithTenant(ctx, t)
+		return handler(logger.WithContext(ctx, logger.FromContext(ctx).With("tenant", t.ID)), req)
+	}
+}
+
+// ClientInfo stores the address and user agent of the caller in the call
+// context, like the ClientInfo middleware does for HTTP requests
+func ClientInfo(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	var ip string
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		ip = p.Addr.String()
+		if host, _, err := net.SplitHostPort(ip); err == nil {
+			ip = host
+		}
+	}
+	return handler(client.WithInfo(ctx, client.Info{IP: ip, UserAgent: firstMetadata(ctx, "user-agent")}), req)
+}
+
+// Authenticate signs calls carrying a bearer token in their authorization
+// metadata in as its user, with the context values the HTTP Auth middleware
+// sets, checking the session of the token with sessions like Auth does.
+// Calls without a token go through anonymously; services requiring a user
+// check for one.
+func Authenticate(jwtManager *security.JWTManager, sessions func(ctx context.Context, token string) error) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		authorization := firstMetadata(ctx, "authorization")
+		if authorization == "" {
+			return handler(ctx, req)
+		}
+
+		token, ok := strings.CutPrefix(authorization, "Bearer ")
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "invalid authorization metadata format")
+		}
+		claims, err := jwtManager.ValidateToken(token)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "invalid token")
+		}
+		if t := tenant.FromContext(ctx); t != nil && !claims.IssuedBy(t.ID) {
+			return nil, status.Error(codes.Unauthenticated, "token was issued by another portal")
+		}
+		if sessions != nil {
+			if err := sessions(ctx, token); err != nil {
+				if errors.Is(err, errors.ErrTokenRevoked) {
+					return nil, status.Error(codes.Unauthenticated, "token revoked")
+				}
+				logger.FromContext(ctx).Error("Failed to check the session of a token: %v", err)
+				return nil, status.Error(codes.Internal, "failed to check the session")
+			}
+		}
+
+		user := auth.FromToken(claims)
+		ctx = auth.WithClaims(ctx, user)
+		callLogger := logger.FromContext(ctx).With("user_id", user.UserID, "org_id", user.OrganizationID)
+		if user.Impersonated() {
+			callLogger = callLogger.With("impersonator_id", user.ImpersonatorID)
+		}
+		ctx = logger.WithContext(ctx, callLogger)
+		return handler(ctx, req)
+	}
+}
+
+// UserID returns the ID of the user a call was authenticated as, and
+// UNAUTHENTICATED when it carries no token
+func UserID(ctx context.Context) (string, error) {
+	userID := auth.UserID(ctx)
+	if userID == "" {
+		return "", status.Error(codes.Unauthenticated, "authorization metadata required")
+	}
+	return userID, nil
+}
+
+func firstMetadata(ctx context.Context, key string) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
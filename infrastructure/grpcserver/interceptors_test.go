@@ -0,0 +1,115 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"portal-data-backend/infrastructure/auth"
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/infrastructure/security"
+	"portal-data-backend/infrastructure/tenant"
+	"portal-data-backend/pkg/errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var info = &grpc.UnaryServerInfo{FullMethod: "/portal.v1.DatasetService/GetDataset"}
+
+// Test errors map to the gRPC code matching the HTTP status REST answers
+func TestStatus_MapsErrors(t *testing.T) {
+	tests := []struct {
+		err  error
+		want codes.Code
+	}{
+		{fmt.Errorf("failed to get dataset: %w", errors.ErrNotFound), codes.NotFound},
+		{errors.ErrInvalidInput, codes.InvalidArgument},
+		{errors.ErrAlreadyExists, codes.AlreadyExists},
+		{errors.ErrForbidden, codes.PermissionDenied},
+		{status.Error(codes.Unauthenticated, "no token"), codes.Unauthenticated},
+		{fmt.Errorf("connection refused"), codes.Internal},
+	}
+
+	for _, tt := range tests {
+		_, err := Status(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, tt.err
+		})
+		if got := status.Code(err); got != tt.want {
+			t.Errorf("Expected %v for %v, got %v", tt.want, tt.err, got)
+		}
+	}
+}
+
+// Test internal errors are not sent to clients
+func TestStatus_HidesInternalErrors(t *testing.T) {
+	_, err := Status(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, fmt.Errorf("dial tcp 10.0.0.5:5432: connection refused")
+	})
+	if msg := status.Convert(err).Message(); msg != "internal server error" {
+		t.Errorf("Expected a generic message, got %q", msg)
+	}
+}
+
+// Test a panicking handler answers INTERNAL
+func TestRecovery(t *testing.T) {
+	_, err := Recovery(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		panic("boom")
+	})
+	if status.Code(err) != codes.Internal {
+		t.Errorf("Expected INTERNAL, got %v", status.Code(err))
+	}
+}
+
+func authenticate(t *testing.T, ctx context.Context, authorization string) (string, error) {
+	t.Helper()
+	jwtManager := security.NewJWTManager(&config.JWTConfig{Secret: "secret", AccessTokenExpiry: time.Hour, RefreshTokenExpiry: time.Hour, Issuer: "test"})
+	pair, err := jwtManager.GenerateTokenPair("user-1", "org-1", "role-1", "user@example.com", "jabar")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	if authorization == "" {
+		authorization = "Bearer " + pair.AccessToken
+	}
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", authorization))
+
+	var userID string
+	_, err = Authenticate(jwtManager, nil)(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		userID = auth.UserID(ctx)
+		return nil, nil
+	})
+	return userID, err
+}
+
+// Test a bearer token signs the call in as its user
+func TestAuthenticate(t *testing.T) {
+	userID, err := authenticate(t, context.Background(), "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if userID != "user-1" {
+		t.Errorf("Expected user-1, got %q", userID)
+	}
+}
+
+// Test invalid tokens and tokens of another portal are rejected
+func TestAuthenticate_Rejects(t *testing.T) {
+	if _, err := authenticate(t, context.Background(), "Bearer nonsense"); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected UNAUTHENTICATED for an invalid token, got %v", err)
+	}
+
+	ctx := tenant.WithTenant(context.Background(), &tenant.Tenant{ID: "jatim"})
+	if _, err := authenticate(t, ctx, ""); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected UNAUTHENTICATED for another portal's token, got %v", err)
+	}
+}
+
+// Test calls requiring a user fail without one
+func TestUserID(t *testing.T) {
+	if _, err := UserID(context.Background()); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected UNAUTHENTICATED, got %v", err)
+	}
+}
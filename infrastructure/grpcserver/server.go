@@ -0,0 +1,59 @@
+// Package grpcserver serves the gRPC API internal services call. It shares
+// the usecases of the REST API: modules register their gRPC services on the
+// server, which authenticates callers with mutual TLS and answers errors
+// with the gRPC status matching the HTTP status REST would answer.
+package grpcserver
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/infrastructure/logger"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// New creates the gRPC server of cfg. Calls are logged and recovered from
+// panics, then go through interceptors in order.
+func New(cfg *config.GRPCConfig, log *logger.Logger, interceptors ...grpc.UnaryServerInterceptor) (*grpc.Server, error) {
+	creds, err := transportCredentials(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	chain := append([]grpc.UnaryServerInterceptor{Logging(log), Recovery, Status}, interceptors...)
+	return grpc.NewServer(grpc.Creds(creds), grpc.ChainUnaryInterceptor(chain...)), nil
+}
+
+// transportCredentials requires clients to present a certificate signed by
+// the client CA of cfg, unless cfg is insecure
+func transportCredentials(cfg *config.GRPCConfig) (credentials.TransportCredentials, error) {
+	if cfg.Insecure {
+		return insecure.NewCredentials(), nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load gRPC certificate: %w", err)
+	}
+	caPEM, err := os.ReadFile(cfg.ClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gRPC client CA: %w", err)
+	}
+	clientCAs := x509.NewCertPool()
+	if !clientCAs.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("gRPC client CA %s holds no certificate", cfg.ClientCAFile)
+	}
+
+	return credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+		MinVersion:   tls.VersionTLS12,
+	}), nil
+}
@@ -0,0 +1,132 @@
+// Package health probes the dependencies of the API for readiness checks.
+// Dependencies are registered as named checks; Run probes them all at once
+// and reports the status and latency of each.
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"portal-data-backend/infrastructure/http/response"
+)
+
+// Check probes a dependency, returning an error when it cannot serve
+type Check func(ctx context.Context) error
+
+// Status values of a dependency and of the service
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// Result is the outcome of probing one dependency
+type Result struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report is the outcome of probing every dependency. Status is down when
+// any dependency is.
+type Report struct {
+	Status string   `json:"status"`
+	Checks []Result `json:"checks"`
+}
+
+// Ready reports whether every dependency is up
+func (r *Report) Ready() bool {
+	return r.Status == StatusUp
+}
+
+// Registry holds the checks of the dependencies in registration order
+type Registry struct {
+	mu     sync.Mutex
+	names  []string
+	checks []Check
+}
+
+// Register adds the check of the dependency called name
+func (r *Registry) Register(name string, check Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.names = append(r.names, name)
+	r.checks = append(r.checks, check)
+}
+
+// Run probes every dependency concurrently, giving each at most timeout
+func (r *Registry) Run(ctx context.Context, timeout time.Duration) *Report {
+	r.mu.Lock()
+	names, checks := r.names, r.checks
+	r.mu.Unlock()
+
+	report := &Report{Status: StatusUp, Checks: make([]Result, len(checks))}
+	var wg sync.WaitGroup
+	for i := range checks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			report.Checks[i] = probe(ctx, names[i], checks[i], timeout)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+	return report
+}
+
+func probe(ctx context.Context, name string, check Check, timeout time.Duration) Result {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// A check ignoring ctx is abandoned when it times out
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- check(ctx) }()
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	result := Result{
+		Name:      name,
+		Status:    StatusUp,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// LivenessHandler answers that the process serves requests, without probing
+// its dependencies
+func LivenessHandler(version string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response.OK(w, response.CodeSuccess, "Service is alive", map[string]string{
+			"status":  "ok",
+			"version": version,
+		})
+	}
+}
+
+// ReadinessHandler probes the dependencies of registry, answering 200 when
+// all are up and 503 otherwise, with the report either way
+func ReadinessHandler(registry *Registry, timeout time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := registry.Run(r.Context(), timeout)
+		if !report.Ready() {
+			response.JSON(w, http.StatusServiceUnavailable, response.CodeServiceUnavailable, "Service is not ready", report)
+			return
+		}
+		response.OK(w, response.CodeSuccess, "Service is ready", report)
+	}
+}
@@ -0,0 +1,93 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func up(ctx context.Context) error {
+	return nil
+}
+
+// Test the report lists every dependency in registration order and is down
+// when one is
+func TestRegistry_Run(t *testing.T) {
+	registry := &Registry{}
+	registry.Register("postgres", up)
+	registry.Register("minio", func(ctx context.Context) error {
+		return errors.New("bucket files does not exist")
+	})
+
+	report := registry.Run(context.Background(), time.Second)
+
+	if report.Ready() || report.Status != StatusDown {
+		t.Errorf("Expected the report to be down, got %s", report.Status)
+	}
+	if len(report.Checks) != 2 || report.Checks[0].Name != "postgres" || report.Checks[1].Name != "minio" {
+		t.Fatalf("Expected postgres then minio, got %+v", report.Checks)
+	}
+	if report.Checks[0].Status != StatusUp || report.Checks[0].Error != "" {
+		t.Errorf("Expected postgres to be up, got %+v", report.Checks[0])
+	}
+	if report.Checks[1].Status != StatusDown || report.Checks[1].Error != "bucket files does not exist" {
+		t.Errorf("Expected minio to be down with its error, got %+v", report.Checks[1])
+	}
+}
+
+// Test a check that does not return in time is down
+func TestRegistry_RunTimeout(t *testing.T) {
+	registry := &Registry{}
+	release := make(chan struct{})
+	defer close(release)
+	registry.Register("cache", func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+
+	start := time.Now()
+	report := registry.Run(context.Background(), 20*time.Millisecond)
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the check to be abandoned, waited %s", elapsed)
+	}
+	if report.Checks[0].Status != StatusDown || report.Checks[0].Error != context.DeadlineExceeded.Error() {
+		t.Errorf("Expected the cache to time out, got %+v", report.Checks[0])
+	}
+}
+
+// Test readiness answers 503 while a dependency is down and 200 once all are up
+func TestReadinessHandler(t *testing.T) {
+	healthy := false
+	registry := &Registry{}
+	registry.Register("job_queue", func(ctx context.Context) error {
+		if !healthy {
+			return errors.New("run queue is not served")
+		}
+		return nil
+	})
+	handler := ReadinessHandler(registry, time.Second)
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503, got %d", w.Code)
+	}
+	var body struct {
+		Data Report `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || len(body.Data.Checks) != 1 {
+		t.Fatalf("Expected the report in the body, got %s", w.Body.String())
+	}
+
+	healthy = true
+	w = httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+}
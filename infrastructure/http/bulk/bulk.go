@@ -0,0 +1,77 @@
+// Package bulk answers requests applying an operation to a list of records
+// at once, reporting the outcome for each record.
+package bulk
+
+import (
+	"fmt"
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/problem"
+	"portal-data-backend/infrastructure/http/response"
+	"portal-data-backend/infrastructure/logger"
+)
+
+// Request lists the IDs of the records a bulk operation applies to, at most
+// 500
+type Request struct {
+	IDs []string `json:"ids" validate:"required,min=1,max=500,unique,dive,required"`
+}
+
+// Result is the outcome of a bulk operation for one record. Status is the
+// HTTP status the operation would have answered for the record alone.
+type Result struct {
+	ID     string `json:"id"`
+	Status int    `json:"status"`
+	Code   string `json:"code,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Response reports the outcome of a bulk operation, with a result per
+// record in the order of the request
+type Response struct {
+	Succeeded int      `json:"succeeded"`
+	Failed    int      `json:"failed"`
+	Results   []Result `json:"results"`
+}
+
+// NewResponse collects the outcome of an operation on ids, of which those in
+// failed failed. Their errors are mapped with mapper; errors without a
+// mapping, or mapping to a server error, are logged and hidden.
+func NewResponse(r *http.Request, mapper *problem.Mapper, ids []string, failed map[string]error) *Response {
+	resp := &Response{Results: make([]Result, 0, len(ids))}
+	for _, id := range ids {
+		err, ok := failed[id]
+		if !ok {
+			resp.Succeeded++
+			resp.Results = append(resp.Results, Result{ID: id, Status: http.StatusOK})
+			continue
+		}
+
+		resp.Failed++
+		mapping, ok := mapper.Lookup(err)
+		if !ok || mapping.Status >= http.StatusInternalServerError {
+			logger.FromContext(r.Context()).Error("%s %s failed for %s: %v", r.Method, r.URL.Path, id, err)
+			if !ok {
+				mapping = problem.Mapping{
+					Status:  http.StatusInternalServerError,
+					Code:    response.CodeInternalServerError,
+					Message: "Internal server error",
+				}
+			}
+		}
+		message := mapping.Message
+		if message == "" {
+			message = err.Error()
+		}
+		resp.Results = append(resp.Results, Result{ID: id, Status: mapping.Status, Code: mapping.Code, Error: message})
+	}
+	return resp
+}
+
+// Write sends the outcome of an operation on ids, of which those in failed
+// failed, as a 200 response. The message says how many records subject,
+// like "datasets updated", applies to.
+func Write(w http.ResponseWriter, r *http.Request, mapper *problem.Mapper, ids []string, failed map[string]error, subject string) {
+	resp := NewResponse(r, mapper, ids, failed)
+	response.OK(w, response.CodeSuccess, fmt.Sprintf("%d of %d %s", resp.Succeeded, len(ids), subject), resp)
+}
@@ -0,0 +1,42 @@
+package bulk
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"portal-data-backend/infrastructure/http/problem"
+	"portal-data-backend/infrastructure/http/response"
+	pkgErrors "portal-data-backend/pkg/errors"
+)
+
+// Test results follow the order of the request and map their errors, hiding
+// internal ones
+func TestNewResponse(t *testing.T) {
+	mapper := problem.Default.With(
+		problem.Mapping{Err: pkgErrors.ErrNotFound, Status: http.StatusNotFound, Code: response.CodeNotFound, Message: "Dataset not found"},
+	)
+	r := httptest.NewRequest(http.MethodPatch, "/datasets/bulk-status", nil)
+	failed := map[string]error{
+		"d2": fmt.Errorf("failed to get dataset: %w", pkgErrors.ErrNotFound),
+		"d3": errors.New("connection reset"),
+	}
+
+	got := NewResponse(r, mapper, []string{"d3", "d1", "d2"}, failed)
+
+	want := &Response{
+		Succeeded: 1,
+		Failed:    2,
+		Results: []Result{
+			{ID: "d3", Status: http.StatusInternalServerError, Code: response.CodeInternalServerError, Error: "Internal server error"},
+			{ID: "d1", Status: http.StatusOK},
+			{ID: "d2", Status: http.StatusNotFound, Code: response.CodeNotFound, Error: "Dataset not found"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+}
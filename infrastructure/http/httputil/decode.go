@@ -0,0 +1,190 @@
+// Package httputil decodes and validates request bodies the same way for
+// every handler.
+package httputil
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"portal-data-backend/infrastructure/http/response"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// MaxBodyBytes bounds the size of the JSON bodies Decode reads, unless the
+// route group of the request sets its own limit with WithBodyLimit. Set it at
+// startup, before requests are served.
+var MaxBodyBytes int64 = 1 << 20
+
+var validate = newValidator()
+
+// newValidator creates a validator naming fields as they appear in JSON
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+	return v
+}
+
+// Decode reads the JSON body of r into a new T and validates it. Bodies
+// larger than the body limit, with unknown fields or trailing data are
+// rejected. When it returns false, Decode has written the error response.
+func Decode[T any](w http.ResponseWriter, r *http.Request) (*T, bool) {
+	dst := new(T)
+	if !DecodeInto(w, r, dst) {
+		return nil, false
+	}
+	return dst, true
+}
+
+// DecodeInto is Decode for a value the caller allocated, like an anonymous
+// struct. dst must be a pointer.
+func DecodeInto(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	if err := decode(w, r, dst); err != nil {
+		if BodyTooLarge(w, err) {
+			return false
+		}
+		response.BadRequest(w, response.CodeBadRequest, "Invalid request body: "+err.Error(), nil)
+		return false
+	}
+	return Validate(w, dst)
+}
+
+func decode(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, BodyLimit(r.Context())))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(dst); err != nil {
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		switch {
+		case errors.Is(err, io.EOF):
+			return errors.New("body is empty")
+		case errors.Is(err, io.ErrUnexpectedEOF):
+			return errors.New("body is not valid JSON")
+		case errors.As(err, &syntaxErr):
+			return fmt.Errorf("body is not valid JSON at offset %d", syntaxErr.Offset)
+		case errors.As(err, &typeErr) && typeErr.Field != "":
+			return fmt.Errorf("%s must be %s", typeErr.Field, jsonType(typeErr.Type))
+		case errors.As(err, &typeErr):
+			return fmt.Errorf("body must be %s", jsonType(typeErr.Type))
+		}
+		// Unknown fields and bodies over the limit are reported as is
+		if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			return fmt.Errorf("unknown field %s", field)
+		}
+		return err
+	}
+	if decoder.More() {
+		return errors.New("body must hold a single JSON value")
+	}
+	return nil
+}
+
+// Validate checks v against its validate tags. When it returns false,
+// Validate has written the validation error response.
+func Validate(w http.ResponseWriter, v interface{}) bool {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return true
+	}
+
+	if err := validate.Struct(v); err != nil {
+		response.ValidationError(w, response.CodeValidationFailed, "Validation failed", ValidationErrors(err))
+		return false
+	}
+	return true
+}
+
+// ValidationErrors describes each field failing validation in err
+func ValidationErrors(err error) []response.ErrorDetail {
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return []response.ErrorDetail{{Message: err.Error()}}
+	}
+
+	details := make([]response.ErrorDetail, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		details = append(details, response.ErrorDetail{
+			Field:   fieldErr.Field(),
+			Message: validationMessage(fieldErr),
+		})
+	}
+	return details
+}
+
+func validationMessage(fieldErr validator.FieldError) string {
+	field, param := fieldErr.Field(), fieldErr.Param()
+	switch fieldErr.Tag() {
+	case "required":
+		return field + " is required"
+	case "email":
+		return field + " must be a valid email address"
+	case "url":
+		return field + " must be a valid URL"
+	case "uuid", "uuid4":
+		return field + " must be a valid UUID"
+	case "alphanum":
+		return field + " must contain only alphanumeric characters"
+	case "oneof":
+		return field + " must be one of: " + param
+	case "min", "gte":
+		return field + " must be at least " + param + unit(fieldErr.Kind())
+	case "max", "lte":
+		return field + " must be at most " + param + unit(fieldErr.Kind())
+	case "len":
+		return field + " must be exactly " + param + unit(fieldErr.Kind())
+	case "gt":
+		return field + " must be greater than " + param
+	case "lt":
+		return field + " must be less than " + param
+	default:
+		return field + " is invalid"
+	}
+}
+
+// unit names what a length rule on a value of kind counts
+func unit(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return " items"
+	default:
+		return ""
+	}
+}
+
+// jsonType names the JSON type a Go type is decoded from
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
@@ -0,0 +1,106 @@
+package httputil
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"portal-data-backend/infrastructure/http/response"
+)
+
+type createRequest struct {
+	Name  string   `json:"name" validate:"required,min=3"`
+	Email string   `json:"email" validate:"omitempty,email"`
+	Tags  []string `json:"tags" validate:"max=2"`
+}
+
+func decodeBody(t *testing.T, body string) (*createRequest, *httptest.ResponseRecorder) {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	req, _ := Decode[createRequest](w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+	return req, w
+}
+
+func errorResponse(t *testing.T, w *httptest.ResponseRecorder) response.ErrorResponse {
+	t.Helper()
+
+	var resp response.ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Expected a JSON error response, got %s", w.Body.String())
+	}
+	return resp
+}
+
+// Test a valid body is decoded
+func TestDecode(t *testing.T) {
+	req, w := decodeBody(t, `{"name": "Population", "tags": ["census"]}`)
+	if req == nil {
+		t.Fatalf("Expected the request, got %d %s", w.Code, w.Body.String())
+	}
+	if req.Name != "Population" || len(req.Tags) != 1 {
+		t.Errorf("Expected the decoded request, got %+v", req)
+	}
+}
+
+// Test malformed bodies are rejected as bad requests
+func TestDecode_Invalid(t *testing.T) {
+	cases := map[string]string{
+		"empty":         ``,
+		"syntax":        `{"name": }`,
+		"truncated":     `{"name": "Population"`,
+		"unknown field": `{"name": "Population", "owner": "me"}`,
+		"wrong type":    `{"name": 42}`,
+		"trailing data": `{"name": "Population"} {}`,
+	}
+	for name, body := range cases {
+		req, w := decodeBody(t, body)
+		if req != nil || w.Code != http.StatusBadRequest {
+			t.Errorf("%s: Expected status 400, got %d", name, w.Code)
+			continue
+		}
+		if resp := errorResponse(t, w); !strings.HasPrefix(resp.Detail, "Invalid request body: ") {
+			t.Errorf("%s: Expected the reason in the message, got %q", name, resp.Detail)
+		}
+	}
+}
+
+// Test bodies over the limit are rejected as too large
+func TestDecode_TooLarge(t *testing.T) {
+	limit := MaxBodyBytes
+	MaxBodyBytes = 16
+	defer func() { MaxBodyBytes = limit }()
+
+	_, w := decodeBody(t, `{"name": "A name longer than the limit"}`)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("Expected status 413, got %d", w.Code)
+	}
+	if resp := errorResponse(t, w); resp.Code != response.CodeRequestTooLarge {
+		t.Errorf("Expected code %s, got %s", response.CodeRequestTooLarge, resp.Code)
+	}
+}
+
+// Test validation failures name the JSON fields
+func TestDecode_ValidationFailed(t *testing.T) {
+	_, w := decodeBody(t, `{"name": "ab", "email": "nope", "tags": ["a", "b", "c"]}`)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected status 422, got %d", w.Code)
+	}
+
+	messages := make(map[string]string)
+	for _, detail := range errorResponse(t, w).Errors {
+		messages[detail.Field] = detail.Message
+	}
+	expected := map[string]string{
+		"name":  "name must be at least 3 characters",
+		"email": "email must be a valid email address",
+		"tags":  "tags must be at most 2 items",
+	}
+	for field, message := range expected {
+		if messages[field] != message {
+			t.Errorf("Expected %q for %s, got %q", message, field, messages[field])
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package httputil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/response"
+)
+
+type bodyLimitKey struct{}
+
+// WithBodyLimit sets the body limit of the request of ctx
+func WithBodyLimit(ctx context.Context, limit int64) context.Context {
+	return context.WithValue(ctx, bodyLimitKey{}, limit)
+}
+
+// BodyLimit returns the body limit of the request of ctx, MaxBodyBytes when
+// none is set
+func BodyLimit(ctx context.Context) int64 {
+	if limit, ok := ctx.Value(bodyLimitKey{}).(int64); ok {
+		return limit
+	}
+	return MaxBodyBytes
+}
+
+// BodyTooLarge reports whether err comes from reading a body past its limit,
+// and writes the 413 response when it does
+func BodyTooLarge(w http.ResponseWriter, err error) bool {
+	var tooLarge *http.MaxBytesError
+	if !errors.As(err, &tooLarge) {
+		return false
+	}
+	RejectTooLarge(w, tooLarge.Limit)
+	return true
+}
+
+// RejectTooLarge writes the response to a body over limit
+func RejectTooLarge(w http.ResponseWriter, limit int64) {
+	response.Error(w, http.StatusRequestEntityTooLarge, response.CodeRequestTooLarge,
+		fmt.Sprintf("Request body must be at most %d bytes", limit), nil)
+}
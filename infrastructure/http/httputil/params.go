@@ -0,0 +1,27 @@
+package httputil
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/response"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// UUIDParam returns the URL parameter name of r, which must be a UUID. IDs
+// that are not would only fail once the database casts them, after the work
+// leading up to the query. When it returns false, UUIDParam has written the
+// error response.
+func UUIDParam(w http.ResponseWriter, r *http.Request, name string) (string, bool) {
+	value := chi.URLParam(r, name)
+	if value == "" {
+		response.BadRequest(w, response.CodeBadRequest, "Path parameter "+name+" is required", nil)
+		return "", false
+	}
+	if _, err := uuid.Parse(value); err != nil || len(value) != 36 {
+		response.BadRequest(w, response.CodeBadRequest, "Path parameter "+name+" must be a UUID", []response.ErrorDetail{{Field: name, Message: "not a UUID: " + value}})
+		return "", false
+	}
+	return value, true
+}
@@ -0,0 +1,51 @@
+package httputil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Test UUID path parameters are returned and anything else is rejected
+// before reaching the handler's work
+func TestUUIDParam(t *testing.T) {
+	var got string
+	router := chi.NewRouter()
+	router.Get("/datasets/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, ok := UUIDParam(w, r, "id")
+		if !ok {
+			return
+		}
+		got = id
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		id     string
+		status int
+	}{
+		{"6f1c2a7e-4b3d-4e8f-9a10-2b3c4d5e6f70", http.StatusOK},
+		{"not-a-uuid", http.StatusBadRequest},
+		{"6f1c2a7e4b3d4e8f9a102b3c4d5e6f70", http.StatusBadRequest},
+		{"6f1c2a7e-4b3d-4e8f-9a10-2b3c4d5e6f7", http.StatusBadRequest},
+		{"1%27%20OR%201=1", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		got = ""
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/datasets/"+tt.id, nil))
+		if w.Code != tt.status {
+			t.Errorf("Expected status %d for %s, got %d", tt.status, tt.id, w.Code)
+		}
+		if tt.status == http.StatusBadRequest {
+			if got != "" {
+				t.Errorf("Expected the handler to stop for %s, got %s", tt.id, got)
+			}
+			if resp := errorResponse(t, w); len(resp.Errors) != 1 || resp.Errors[0].Field != "id" {
+				t.Errorf("Expected the parameter named in the error, got %+v", resp)
+			}
+		}
+	}
+}
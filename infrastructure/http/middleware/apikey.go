@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"portal-data-backend/infrastructure/http/response"
+	"portal-data-backend/infrastructure/logger"
+	pkgErrors "portal-data-backend/pkg/errors"
+)
+
+// APIKeyHeader carries the API key of a developer application
+const APIKeyHeader = "X-API-Key"
+
+// APIClient is the developer application an API key authenticates
+type APIClient struct {
+	KeyID         string
+	ApplicationID string
+	Plan          string
+	// RateLimit requests of the key are served per RateWindow; a limit
+	// below 1 lifts it
+	RateLimit  int
+	RateWindow time.Duration
+}
+
+type apiClientKey struct{}
+
+// APIClientFrom returns the client of the API key a request was sent with,
+// or nil
+func APIClientFrom(ctx context.Context) *APIClient {
+	client, _ := ctx.Value(apiClientKey{}).(*APIClient)
+	return client
+}
+
+// APIKeys authenticates the requests carrying an API key with authenticate
+// and serves each key up to the rate limit of its plan, answering the rest
+// with 429. Every request with a key is handed to count, throttled or not.
+// Requests without a key pass through; keys authenticate rejects with
+// errors.ErrUnauthorized are answered 401. Clients that can send no other
+// header, like WebDAV clients, send the key as the password of Basic auth.
+func APIKeys(authenticate func(ctx context.Context, key string) (*APIClient, error), count func(client *APIClient, throttled bool)) func(http.Handler) http.Handler {
+	var mu sync.Mutex
+	limiters := map[string]*RateLimiter{}
+	limiter := func(client *APIClient) *RateLimiter {
+		mu.Lock()
+		defer mu.Unlock()
+		// Limiters are kept per plan and limit, so keys of one plan share
+		// the settings of their limiter
+		name := fmt.Sprintf("%s/%d/%s", client.Plan, client.RateLimit, client.RateWindow)
+		l, ok := limiters[name]
+		if !ok {
+			l = NewRateLimiter(client.RateLimit, client.RateWindow)
+			limiters[name] = l
+		}
+		return l
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(APIKeyHeader)
+			if key == "" {
+				_, key, _ = r.BasicAuth()
+			}
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := r.Context()
+			client, err := authenticate(ctx, key)
+			if err != nil {
+				if errors.Is(err, pkgErrors.ErrUnauthorized) {
+					response.Unauthorized(w, response.CodeUnauthorized, "Invalid API key", nil)
+					return
+				}
+				logger.FromContext(ctx).Error("Failed to authenticate API key: %v", err)
+				response.InternalError(w, response.CodeInternalServerError, "Failed to authenticate API key", nil)
+				return
+			}
+
+			if wait := limiter(client).allow(client.KeyID, time.Now()); wait > 0 {
+				count(client, true)
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				response.Error(w, http.StatusTooManyRequests, response.CodeTooManyRequests, "Rate limit of the API key exceeded, please try again later", nil)
+				return
+			}
+			count(client, false)
+
+			// Log the rest of the request as the application
+			ctx = context.WithValue(ctx, apiClientKey{}, client)
+			ctx = logger.WithContext(ctx, logger.FromContext(ctx).With("app_id", client.ApplicationID))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	pkgErrors "portal-data-backend/pkg/errors"
+)
+
+// Test API keys are authenticated, limited to the rate limit of their plan
+// and counted
+func TestAPIKeys(t *testing.T) {
+	clients := map[string]*APIClient{
+		"free-key":    {KeyID: "key-1", ApplicationID: "app-1", Plan: "free", RateLimit: 2, RateWindow: time.Minute},
+		"partner-key": {KeyID: "key-2", ApplicationID: "app-2", Plan: "partner", RateLimit: 5, RateWindow: time.Minute},
+	}
+	authenticate := func(ctx context.Context, key string) (*APIClient, error) {
+		if client, ok := clients[key]; ok {
+			return client, nil
+		}
+		return nil, pkgErrors.ErrUnauthorized
+	}
+	counted := map[string]int{}
+	throttled := map[string]int{}
+	count := func(client *APIClient, limited bool) {
+		counted[client.KeyID]++
+		if limited {
+			throttled[client.KeyID]++
+		}
+	}
+
+	var served *APIClient
+	handler := APIKeys(authenticate, count)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served = APIClientFrom(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+	get := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/datasets", nil)
+		if key != "" {
+			req.Header.Set(APIKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := get(""); w.Code != http.StatusOK || served != nil {
+		t.Errorf("Expected a request without a key to pass through, got %d", w.Code)
+	}
+	if w := get("unknown-key"); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for an unknown key, got %d", w.Code)
+	}
+
+	// Keys are also sent as the password of Basic auth
+	req := httptest.NewRequest(http.MethodGet, "/dav/", nil)
+	req.SetBasicAuth("analyst", "partner-key")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if served == nil || served.ApplicationID != "app-2" {
+		t.Errorf("Expected the client of the Basic auth password in the context, got %+v", served)
+	}
+
+	for i := 0; i < 2; i++ {
+		if w := get("free-key"); w.Code != http.StatusOK {
+			t.Errorf("Expected request %d of the free key to be served, got %d", i+1, w.Code)
+		}
+	}
+	if served == nil || served.ApplicationID != "app-1" {
+		t.Errorf("Expected the client of the key in the context, got %+v", served)
+	}
+	w := get("free-key")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Errorf("Expected 429 with Retry-After over the free plan, got %d", w.Code)
+	}
+
+	for i := 0; i < 2; i++ {
+		if w := get("partner-key"); w.Code != http.StatusOK {
+			t.Errorf("Expected request %d of the partner key to be served, got %d", i+1, w.Code)
+		}
+	}
+
+	if counted["key-1"] != 3 || throttled["key-1"] != 1 || counted["key-2"] != 3 || throttled["key-2"] != 0 {
+		t.Errorf("Expected 3 requests with 1 throttled and 3 requests, got %v and %v", counted, throttled)
+	}
+}
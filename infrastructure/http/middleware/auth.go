@@ -5,13 +5,21 @@ import (
 	"net/http"
 	"strings"
 
+	"portal-data-backend/infrastructure/auth"
 	"portal-data-backend/infrastructure/http/response"
+	"portal-data-backend/infrastructure/logger"
 	"portal-data-backend/infrastructure/security"
+	"portal-data-backend/infrastructure/tenant"
 	"portal-data-backend/pkg/errors"
 )
 
-// Auth middleware validates JWT tokens
-func Auth(jwtManager *security.JWTManager) func(http.Handler) http.Handler {
+// Auth middleware validates JWT tokens and makes the request as their user,
+// read with auth.FromContext. The claims of impersonated requests carry the
+// admin acting as the user. Tokens valid by their signature are then checked
+// against their session with sessions, which reports errors.ErrTokenRevoked
+// for those signed out, like the other sessions of a user changing their
+// password; a nil sessions checks none.
+func Auth(jwtManager *security.JWTManager, sessions func(ctx context.Context, token string) error) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Get Authorization header
@@ -41,13 +49,56 @@ func Auth(jwtManager *security.JWTManager) func(http.Handler) http.Handler {
 				return
 			}
 
+			// Tokens are only valid at the portal that issued them
+			if t := tenant.FromContext(r.Context()); t != nil && !claims.IssuedBy(t.ID) {
+				response.Unauthorized(w, response.CodeUnauthorized, "Token was issued by another portal", nil)
+				return
+			}
+
+			if sessions != nil {
+				if err := sessions(r.Context(), token); err != nil {
+					if errors.Is(err, errors.ErrTokenRevoked) {
+						response.Unauthorized(w, response.CodeUnauthorized, "Token revoked", nil)
+						return
+					}
+					logger.FromContext(r.Context()).Error("Failed to check the session of a token: %v", err)
+					response.InternalError(w, response.CodeInternalServerError, "Failed to check the session", nil)
+					return
+				}
+			}
+
 			// Add user info to context
-			ctx := context.WithValue(r.Context(), "user_id", claims.UserID)
-			ctx = context.WithValue(ctx, "organization_id", claims.OrganizationID)
-			ctx = context.WithValue(ctx, "role_id", claims.RoleID)
-			ctx = context.WithValue(ctx, "email", claims.Email)
+			user := auth.FromToken(claims)
+			ctx := auth.WithClaims(r.Context(), user)
+
+			// Log the rest of the request as the user
+			requestLogger := logger.FromContext(ctx).With("user_id", user.UserID, "org_id", user.OrganizationID)
+			if user.Impersonated() {
+				requestLogger = requestLogger.With("impersonator_id", user.ImpersonatorID)
+			}
+			ctx = logger.WithContext(ctx, requestLogger)
 
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
+
+// Impersonator returns the ID of the admin impersonating the user of ctx,
+// empty when the request is not impersonated. Auth sets it.
+func Impersonator(ctx context.Context) string {
+	return auth.FromContext(ctx).ImpersonatorID
+}
+
+// RequireRole lets through users signed in with one of roles. It must run
+// after Auth.
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if auth.FromContext(r.Context()).HasRole(roles...) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			response.Forbidden(w, response.CodeForbidden, "You are not allowed to access this resource", nil)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/infrastructure/security"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Test tokens valid by their signature are refused once their session is
+// signed out, like after the user changes their password elsewhere
+func TestAuth_Sessions(t *testing.T) {
+	jwtManager := security.NewJWTManager(&config.JWTConfig{Secret: "secret", AccessTokenExpiry: time.Hour, RefreshTokenExpiry: time.Hour, Issuer: "test"})
+	token := func() string {
+		pair, err := jwtManager.GenerateTokenPair("user-1", "org-1", "role-1", "user@example.com", "")
+		if err != nil {
+			t.Fatalf("Failed to generate token: %v", err)
+		}
+		return pair.AccessToken
+	}
+	current, old, broken := token(), token(), token()
+	sessions := func(ctx context.Context, token string) error {
+		switch token {
+		case old:
+			return pkgErrors.ErrTokenRevoked
+		case broken:
+			return errors.New("database is down")
+		}
+		return nil
+	}
+
+	r := chi.NewRouter()
+	r.With(Auth(jwtManager, sessions)).Get("/me", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+
+	for name, tc := range map[string]struct {
+		token string
+		want  int
+	}{
+		"current session":      {token: current, want: http.StatusOK},
+		"signed out session":   {token: old, want: http.StatusUnauthorized},
+		"failed session check": {token: broken, want: http.StatusInternalServerError},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+tc.token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tc.want {
+			t.Errorf("Expected %d for %s, got %d", tc.want, name, w.Code)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"path"
+
+	"portal-data-backend/infrastructure/http/httputil"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// BodyLimitRule sets the body limit of the routes matching Pattern, a
+// path.Match pattern of the route path below the API version, like
+// "/*/import"
+type BodyLimitRule struct {
+	Pattern string
+	Limit   int64
+}
+
+// BodyLimits bounds request bodies to the limit of the first rule matching
+// the route, defaultLimit when none does. Bodies declaring a larger
+// Content-Length are rejected before they are read; the others fail when
+// read past the limit. The limit is passed on to httputil.Decode.
+func BodyLimits(defaultLimit int64, rules ...BodyLimitRule) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			limit := defaultLimit
+			routePath := routePath(r)
+			for _, rule := range rules {
+				if ok, _ := path.Match(rule.Pattern, routePath); ok {
+					limit = rule.Limit
+					break
+				}
+			}
+
+			if r.ContentLength > limit {
+				httputil.RejectTooLarge(w, limit)
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+			next.ServeHTTP(w, r.WithContext(httputil.WithBodyLimit(r.Context(), limit)))
+		})
+	}
+}
+
+// routePath returns the path of r below the router it is mounted on
+func routePath(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePath != "" {
+		return rctx.RoutePath
+	}
+	return r.URL.Path
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"portal-data-backend/infrastructure/http/httputil"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// newLimitedRouter serves routes reading their whole body and answering the
+// limit they were given
+func newLimitedRouter() http.Handler {
+	read := func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			if !httputil.BodyTooLarge(w, err) {
+				w.WriteHeader(http.StatusBadRequest)
+			}
+			return
+		}
+		w.Write([]byte(strconv.FormatInt(httputil.BodyLimit(r.Context()), 10)))
+	}
+
+	r := chi.NewRouter()
+	r.Route("/api/v1", func(r chi.Router) {
+		r.Use(BodyLimits(8, BodyLimitRule{Pattern: "/*/import", Limit: 32}))
+		r.Post("/units", read)
+		r.Post("/units/import", read)
+	})
+	return r
+}
+
+// Test the first rule matching the route sets its limit
+func TestBodyLimits_RouteGroups(t *testing.T) {
+	router := newLimitedRouter()
+
+	for path, limit := range map[string]string{"/api/v1/units": "8", "/api/v1/units/import": "32"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, strings.NewReader("small")))
+		if w.Code != http.StatusOK || w.Body.String() != limit {
+			t.Errorf("Expected %s to be limited to %s bytes, got %d %s", path, limit, w.Code, w.Body.String())
+		}
+	}
+}
+
+// Test bodies over the limit are rejected, whether declared or read
+func TestBodyLimits_TooLarge(t *testing.T) {
+	router := newLimitedRouter()
+
+	declared := httptest.NewRequest(http.MethodPost, "/api/v1/units", strings.NewReader("far too large"))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, declared)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status 413 for a declared length, got %d", w.Code)
+	}
+
+	chunked := httptest.NewRequest(http.MethodPost, "/api/v1/units", strings.NewReader("far too large"))
+	chunked.ContentLength = -1
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, chunked)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status 413 for an unknown length, got %d", w.Code)
+	}
+}
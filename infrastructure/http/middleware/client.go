@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"net/http"
+
+	"portal-data-backend/pkg/client"
+)
+
+// ClientInfo stores the IP address and user agent of the client in the
+// request context. It must run after the RealIP middleware for the IP of
+// clients behind a trusted proxy.
+func ClientInfo(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := client.WithInfo(r.Context(), client.Info{IP: clientIP(r), UserAgent: r.UserAgent()})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
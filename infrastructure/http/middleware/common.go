@@ -1,58 +1,78 @@
 package middleware
 
 import (
-	"log"
 	"net/http"
+	"runtime/debug"
 	"time"
 
+	"portal-data-backend/infrastructure/http/response"
+	"portal-data-backend/infrastructure/logger"
+
 	"github.com/go-chi/chi/v5/middleware"
 )
 
-// Logger is a middleware that logs HTTP requests
-func Logger(debug bool) func(http.Handler) http.Handler {
+// Logger gives each request a logger carrying its request ID, available
+// through logger.FromContext, and logs the request when it completes. The
+// client IP is logged only with remoteIP.
+func Logger(base *logger.Logger, remoteIP bool) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
-		if debug {
-			return middleware.Logger(next)
-		}
-
-		// Simple logger for production
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
+			requestLogger := base.With("request_id", middleware.GetReqID(r.Context()))
 
 			// Create a custom response writer to capture status code
 			wrapped := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
 
-			next.ServeHTTP(wrapped, r)
+			next.ServeHTTP(wrapped, r.WithContext(logger.WithContext(r.Context(), requestLogger)))
 
-			duration := time.Since(start)
-			log.Printf("%s %s %d %v",
-				r.Method,
-				r.URL.Path,
-				wrapped.Status(),
-				duration,
-			)
+			status := wrapped.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			fields := []interface{}{
+				"method", r.Method,
+				"path", r.URL.Path,
+				"status", status,
+				"duration_ms", time.Since(start).Milliseconds(),
+			}
+			if remoteIP {
+				fields = append(fields, "remote_ip", r.RemoteAddr)
+			}
+			requestLogger.With(fields...).Info("request completed")
 		})
 	}
 }
 
-// CORS is a middleware that handles CORS
-func CORS() func(http.Handler) http.Handler {
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			w.Header().Set("Access-Control-Allow-Origin", "*")
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-			w.Header().Set("Access-Control-Expose-Headers", "Content-Length, Content-Type")
-			w.Header().Set("Access-Control-Max-Age", "86400")
+// CorrelationID returns the request ID in the X-Request-ID header, which
+// error responses repeat, so clients can quote it when reporting a problem.
+// It must run after chi's RequestID middleware.
+func CorrelationID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id := middleware.GetReqID(r.Context()); id != "" {
+			w.Header().Set(response.RequestIDHeader, id)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
 
-			if r.Method == http.MethodOptions {
-				w.WriteHeader(http.StatusOK)
+// Recoverer answers panics with an internal server error, logging the panic
+// and its stack
+func Recoverer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
 				return
 			}
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+			logger.FromContext(r.Context()).Error("panic: %v\n%s", rec, debug.Stack())
+			response.InternalError(w, response.CodeInternalServerError, "Internal server error", nil)
+		}()
 
-			next.ServeHTTP(w, r)
-		})
-	}
+		next.ServeHTTP(w, r)
+	})
 }
 
 // ContentType is a middleware that ensures content type is JSON
@@ -68,7 +88,7 @@ func ContentType(next http.Handler) http.Handler {
 		if r.Method == http.MethodPost || r.Method == http.MethodPut || r.Method == http.MethodPatch {
 			ct := r.Header.Get("Content-Type")
 			if ct != "" && !stringsContains(ct, "application/json") {
-				http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+				response.Error(w, http.StatusUnsupportedMediaType, response.CodeUnsupportedMediaType, "Content-Type must be application/json", nil)
 				return
 			}
 		}
@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSPolicy sets what browsers may do calling the API from other origins.
+// Origins are exact origins like https://data.example.go.id, wildcard
+// subdomains like https://*.example.go.id, or "*" for any origin.
+type CORSPolicy struct {
+	Origins        []string
+	Methods        []string
+	Headers        []string
+	ExposedHeaders []string
+	Credentials    bool
+	MaxAge         time.Duration
+}
+
+// CORSRule sets the policy of the requests matching Pattern, a path.Match
+// pattern of the path below the API version, like "/visualizations/*"
+type CORSRule struct {
+	Pattern string
+	Policy  CORSPolicy
+}
+
+// CORS answers preflight requests and sets the CORS headers of responses
+// following the policy of the first rule matching the request,
+// defaultPolicy when none does. Requests from origins the policy does not
+// allow get no CORS headers, which browsers take as a refusal.
+func CORS(defaultPolicy CORSPolicy, rules ...CORSRule) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			policy := defaultPolicy
+			apiPath := versionlessPath(r.URL.Path)
+			for _, rule := range rules {
+				if ok, _ := path.Match(rule.Pattern, apiPath); ok {
+					policy = rule.Policy
+					break
+				}
+			}
+
+			origin := r.Header.Get("Origin")
+			allowed := origin != "" && policy.allows(origin)
+			if allowed {
+				w.Header().Add("Vary", "Origin")
+				if policy.allowsAny() && !policy.Credentials {
+					w.Header().Set("Access-Control-Allow-Origin", "*")
+				} else {
+					w.Header().Set("Access-Control-Allow-Origin", origin)
+				}
+				if policy.Credentials {
+					w.Header().Set("Access-Control-Allow-Credentials", "true")
+				}
+				if len(policy.ExposedHeaders) > 0 {
+					w.Header().Set("Access-Control-Expose-Headers", strings.Join(policy.ExposedHeaders, ", "))
+				}
+			}
+
+			// OPTIONS requests without an origin are not preflights, like
+			// those of WebDAV clients, and are left to the route
+			if r.Method == http.MethodOptions && origin != "" {
+				if allowed {
+					w.Header().Set("Access-Control-Allow-Methods", strings.Join(policy.Methods, ", "))
+					w.Header().Set("Access-Control-Allow-Headers", strings.Join(policy.Headers, ", "))
+					w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(policy.MaxAge.Seconds())))
+				}
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// allows reports whether p lets origin call the API
+func (p CORSPolicy) allows(origin string) bool {
+	origin = strings.ToLower(origin)
+	for _, allowed := range p.Origins {
+		allowed = strings.ToLower(allowed)
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+		// https://*.example.org matches https://data.example.org and
+		// https://a.b.example.org, but not https://example.org
+		if prefix, suffix, ok := strings.Cut(allowed, "*"); ok {
+			if len(origin) > len(prefix)+len(suffix) &&
+				strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (p CORSPolicy) allowsAny() bool {
+	for _, origin := range p.Origins {
+		if origin == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// versionlessPath strips the /api/v1 style prefix of the versioned routes
+// from p, so rules match versioned and legacy routes alike
+func versionlessPath(p string) string {
+	rest, ok := strings.CutPrefix(p, "/api/v")
+	if !ok {
+		return p
+	}
+	if i := strings.IndexByte(rest, '/'); i >= 0 {
+		return rest[i:]
+	}
+	return "/"
+}
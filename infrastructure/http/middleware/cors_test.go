@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newCORSHandler() http.Handler {
+	return CORS(
+		CORSPolicy{
+			Origins:     []string{"https://data.example.org", "https://*.example.go.id"},
+			Methods:     []string{"GET", "POST"},
+			Headers:     []string{"Content-Type", "Authorization"},
+			Credentials: true,
+			MaxAge:      time.Hour,
+		},
+		CORSRule{Pattern: "/visualizations/*", Policy: CORSPolicy{Origins: []string{"*"}, Methods: []string{"GET"}}},
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+}
+
+func corsRequest(method, path, origin string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, nil)
+	r.Header.Set("Origin", origin)
+	w := httptest.NewRecorder()
+	newCORSHandler().ServeHTTP(w, r)
+	return w
+}
+
+// Test listed origins and subdomains of wildcard origins are echoed back
+func TestCORS_AllowedOrigins(t *testing.T) {
+	for _, origin := range []string{"https://data.example.org", "https://DATA.example.org", "https://bappeda.example.go.id", "https://a.b.example.go.id"} {
+		w := corsRequest(http.MethodGet, "/api/v1/datasets", origin)
+		if got := w.Header().Get("Access-Control-Allow-Origin"); got != origin {
+			t.Errorf("Expected %s to be allowed, got %q", origin, got)
+		}
+		if got := w.Header().Get("Access-Control-Allow-Credentials"); got != "true" {
+			t.Errorf("Expected credentials to be allowed for %s, got %q", origin, got)
+		}
+		if w.Code != http.StatusNoContent {
+			t.Errorf("Expected the request to reach the handler, got %d", w.Code)
+		}
+	}
+}
+
+// Test other origins get no CORS headers
+func TestCORS_RejectedOrigins(t *testing.T) {
+	for _, origin := range []string{"https://evil.org", "https://example.go.id", "http://data.example.org", "https://data.example.org.evil.org"} {
+		w := corsRequest(http.MethodGet, "/api/v1/datasets", origin)
+		if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+			t.Errorf("Expected %s to be rejected, got %q", origin, got)
+		}
+	}
+}
+
+// Test preflight requests are answered with the policy of the route
+func TestCORS_Preflight(t *testing.T) {
+	w := corsRequest(http.MethodOptions, "/api/v1/datasets", "https://data.example.org")
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	for header, want := range map[string]string{
+		"Access-Control-Allow-Methods": "GET, POST",
+		"Access-Control-Allow-Headers": "Content-Type, Authorization",
+		"Access-Control-Max-Age":       "3600",
+	} {
+		if got := w.Header().Get(header); got != want {
+			t.Errorf("Expected %s %q, got %q", header, want, got)
+		}
+	}
+}
+
+// Test embed routes, versioned or not, follow the relaxed policy
+func TestCORS_EmbedRule(t *testing.T) {
+	for _, path := range []string{"/api/v1/visualizations/v1", "/visualizations/v1"} {
+		w := corsRequest(http.MethodOptions, path, "https://news.example.com")
+		if got := w.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+			t.Errorf("Expected any origin to embed %s, got %q", path, got)
+		}
+		if got := w.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+			t.Errorf("Expected no credentials for %s, got %q", path, got)
+		}
+		if got := w.Header().Get("Access-Control-Allow-Methods"); got != "GET" {
+			t.Errorf("Expected only GET for %s, got %q", path, got)
+		}
+	}
+}
+
+// Test OPTIONS requests without an origin, like those of WebDAV clients,
+// reach the handler
+func TestCORS_OptionsWithoutOrigin(t *testing.T) {
+	w := httptest.NewRecorder()
+	newCORSHandler().ServeHTTP(w, httptest.NewRequest(http.MethodOptions, "/api/v1/dav/", nil))
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected the request to reach the handler, got %d", w.Code)
+	}
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"portal-data-backend/infrastructure/db"
+	"portal-data-backend/infrastructure/http/response"
+	"portal-data-backend/infrastructure/security"
+	"portal-data-backend/infrastructure/tenant"
+	"portal-data-backend/pkg/errors"
+)
+
+// IncludeDeleted lets users signed in with one of adminRoles read soft
+// deleted records by adding include_deleted=true to a GET request. Other
+// requests are left as they are; asking without such a token is refused.
+func IncludeDeleted(jwtManager *security.JWTManager, adminRoles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet || r.URL.Query().Get("include_deleted") != "true" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok {
+				response.Unauthorized(w, response.CodeUnauthorized, "Sign in to include deleted records", nil)
+				return
+			}
+			claims, err := jwtManager.ValidateToken(token)
+			if t := tenant.FromContext(r.Context()); err == nil && t != nil && !claims.IssuedBy(t.ID) {
+				err = errors.ErrInvalidToken
+			}
+			if err != nil {
+				response.Unauthorized(w, response.CodeUnauthorized, "Invalid token", nil)
+				return
+			}
+			for _, role := range adminRoles {
+				if claims.RoleID == role {
+					next.ServeHTTP(w, r.WithContext(db.WithDeleted(r.Context())))
+					return
+				}
+			}
+			response.Forbidden(w, response.CodeForbidden, "Only admins may include deleted records", nil)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"portal-data-backend/infrastructure/deprecation"
+	"portal-data-backend/infrastructure/security"
+)
+
+// Deprecations announces the notices of registry covering a request with
+// Deprecation, Sunset and Link headers on its response, and hands each
+// notice to record with the client using it: "application:<id>" for the
+// application of an API key, "user:<id>" for the user of a valid token, or
+// "ip:<address>".
+func Deprecations(registry *deprecation.Registry, jwtManager *security.JWTManager, record func(ctx context.Context, notice deprecation.Notice, client string)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			notices := registry.Match(r.Method, routePath(r), r.URL.Query())
+			if len(notices) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			deprecation.Announce(w.Header(), notices)
+			client := deprecatedClient(r, jwtManager)
+			for _, notice := range notices {
+				record(r.Context(), notice, client)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// deprecatedClient identifies the client sending r, before auth runs
+func deprecatedClient(r *http.Request, jwtManager *security.JWTManager) string {
+	if client := APIClientFrom(r.Context()); client != nil {
+		return "application:" + client.ApplicationID
+	}
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		if claims, err := jwtManager.ValidateToken(token); err == nil {
+			return "user:" + claims.UserID
+		}
+	}
+	return "ip:" + clientIP(r)
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/infrastructure/deprecation"
+	"portal-data-backend/infrastructure/security"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Test deprecated routes and parameters are announced and their use is
+// recorded per client, leaving the rest of the API alone
+func TestDeprecations(t *testing.T) {
+	jwtManager := security.NewJWTManager(&config.JWTConfig{Secret: "secret", AccessTokenExpiry: time.Hour, RefreshTokenExpiry: time.Hour, Issuer: "test"})
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	registry := &deprecation.Registry{}
+	registry.Declare(
+		deprecation.Notice{Method: http.MethodGet, Pattern: "/stats/*", Since: since, Sunset: sunset, Successor: "/api/v1/widgets/dataset-count"},
+		deprecation.Notice{Pattern: "/datasets", Param: "q", Sunset: sunset.AddDate(1, 0, 0)},
+	)
+	recorded := map[string]int{}
+	record := func(ctx context.Context, notice deprecation.Notice, client string) {
+		recorded[notice.ID()+" "+client]++
+	}
+
+	r := chi.NewRouter()
+	r.Route("/api/v1", func(r chi.Router) {
+		r.Use(Deprecations(registry, jwtManager, record))
+		ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+		r.Get("/stats/count", ok)
+		r.Get("/datasets", ok)
+	})
+
+	get := func(target, authorization string) http.Header {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Header()
+	}
+
+	header := get("/api/v1/stats/count", "")
+	if got := header.Get("Deprecation"); got != "@1767225600" {
+		t.Errorf("Expected the deprecation date, got %q", got)
+	}
+	if got := header.Get("Sunset"); got != "Thu, 31 Dec 2026 00:00:00 GMT" {
+		t.Errorf("Expected the sunset date, got %q", got)
+	}
+	if got := header.Get("Link"); got != `</api/v1/widgets/dataset-count>; rel="successor-version"` {
+		t.Errorf("Expected a link to the successor, got %q", got)
+	}
+
+	if header := get("/api/v1/datasets", ""); header.Get("Deprecation") != "" {
+		t.Errorf("Expected datasets without q not to be deprecated, got %q", header.Get("Deprecation"))
+	}
+	pair, err := jwtManager.GenerateTokenPair("user-1", "org-1", "role-1", "user@example.com", "")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	if header := get("/api/v1/datasets?q=health", "Bearer "+pair.AccessToken); header.Get("Deprecation") != "true" {
+		t.Errorf("Expected the q parameter to be deprecated, got %q", header.Get("Deprecation"))
+	}
+
+	want := map[string]int{
+		"GET /stats/* ip:192.0.2.1": 1,
+		"/datasets?q user:user-1":   1,
+	}
+	if len(recorded) != len(want) {
+		t.Errorf("Expected %v recorded, got %v", want, recorded)
+	}
+	for key, count := range want {
+		if recorded[key] != count {
+			t.Errorf("Expected %s recorded %d times, got %d", key, count, recorded[key])
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Test unchanged responses are revalidated without a body, and changed or
+// failed ones are served in full
+func TestETag(t *testing.T) {
+	count := "1"
+	r := chi.NewRouter()
+	r.With(ETag).Get("/count", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"count":` + count + `}`))
+	})
+	r.With(ETag).Get("/missing", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"not found"}`))
+	})
+
+	get := func(path, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	first := get("/count", "")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" || first.Body.String() != `{"count":1}` {
+		t.Fatalf("Expected a tagged response, got %d %q %q", first.Code, etag, first.Body.String())
+	}
+
+	for _, header := range []string{etag, `"other", ` + etag, "W/" + etag, "*"} {
+		if w := get("/count", header); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+			t.Errorf("Expected 304 without a body for If-None-Match %s, got %d %q", header, w.Code, w.Body.String())
+		}
+	}
+
+	count = "2"
+	if w := get("/count", etag); w.Code != http.StatusOK || w.Header().Get("ETag") == etag || w.Body.String() != `{"count":2}` {
+		t.Errorf("Expected the changed response with a new ETag, got %d %q %q", w.Code, w.Header().Get("ETag"), w.Body.String())
+	}
+
+	if w := get("/missing", "*"); w.Code != http.StatusNotFound || w.Header().Get("ETag") != "" || w.Body.Len() == 0 {
+		t.Errorf("Expected the failure untagged, got %d %q", w.Code, w.Header().Get("ETag"))
+	}
+}
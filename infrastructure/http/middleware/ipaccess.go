@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"portal-data-backend/infrastructure/http/response"
+	"portal-data-backend/infrastructure/logger"
+)
+
+// IPAccessRule restricts the routes whose path below the API version starts
+// with one of Prefixes, like "/users" or "/admin/", to clients in Allow, any
+// client when Allow is empty, that are not in Deny
+type IPAccessRule struct {
+	Prefixes []string
+	Allow    []*net.IPNet
+	Deny     []*net.IPNet
+}
+
+// matches reports whether the rule covers routePath
+func (rule IPAccessRule) matches(routePath string) bool {
+	return matchesPrefix(rule.Prefixes, routePath)
+}
+
+// matchesPrefix reports whether routePath starts with one of prefixes. A
+// prefix without a trailing "/" covers the route itself and those below it.
+func matchesPrefix(prefixes []string, routePath string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasSuffix(prefix, "/") {
+			if strings.HasPrefix(routePath, prefix) {
+				return true
+			}
+			continue
+		}
+		if routePath == prefix || strings.HasPrefix(routePath, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// admits reports whether the client at ip may call the routes of the rule
+func (rule IPAccessRule) admits(ip net.IP) bool {
+	if ip == nil {
+		return len(rule.Allow) == 0 && len(rule.Deny) == 0
+	}
+	if containsIP(rule.Deny, ip) {
+		return false
+	}
+	return len(rule.Allow) == 0 || containsIP(rule.Allow, ip)
+}
+
+// IPAccess answers 403 to the clients the first rule matching the route
+// does not admit. It must run after the RealIP middleware for the IP of
+// clients behind a trusted proxy.
+func IPAccess(rules ...IPAccessRule) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			routePath := routePath(r)
+			for _, rule := range rules {
+				if !rule.matches(routePath) {
+					continue
+				}
+				ip := clientIP(r)
+				if !rule.admits(net.ParseIP(ip)) {
+					logger.FromContext(r.Context()).Warn("Denied %s %s to client %s by IP access rules", r.Method, routePath, ip)
+					response.Forbidden(w, response.CodeIPNotAllowed, "Access from your network is not allowed", []response.ErrorDetail{
+						{Field: "ip", Message: "IP address " + ip + " is not allowed to access " + routePath},
+					})
+					return
+				}
+				break
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ParseNetworks parses CIDR ranges like 10.0.0.0/8, taking single addresses
+// like 203.0.113.7 as ranges of one address
+func ParseNetworks(values []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(values))
+	for _, value := range values {
+		if !strings.Contains(value, "/") {
+			ip := net.ParseIP(value)
+			if ip == nil {
+				return nil, &net.ParseError{Type: "IP address", Text: value}
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+func containsIP(networks []*net.IPNet, ip net.IP) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
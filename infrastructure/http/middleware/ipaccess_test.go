@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"portal-data-backend/infrastructure/http/response"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Test restricted routes only serve clients from allowed networks that are
+// not denied, and other routes serve every client
+func TestIPAccess(t *testing.T) {
+	allow, err := ParseNetworks([]string{"10.8.0.0/16", "203.0.113.7"})
+	if err != nil {
+		t.Fatalf("Failed to parse networks: %v", err)
+	}
+	deny, err := ParseNetworks([]string{"10.8.66.0/24"})
+	if err != nil {
+		t.Fatalf("Failed to parse networks: %v", err)
+	}
+
+	r := chi.NewRouter()
+	r.Route("/api/v1", func(r chi.Router) {
+		r.Use(IPAccess(IPAccessRule{Prefixes: []string{"/users", "/admin/"}, Allow: allow, Deny: deny}))
+		ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+		r.Get("/users", ok)
+		r.Get("/users/{id}", ok)
+		r.Get("/users-directory", ok)
+		r.Get("/admin/maintenance", ok)
+		r.Get("/datasets", ok)
+	})
+
+	for name, tc := range map[string]struct {
+		path, remoteAddr string
+		want             int
+	}{
+		"office network":      {path: "/api/v1/users", remoteAddr: "10.8.1.20:4000", want: http.StatusOK},
+		"single address":      {path: "/api/v1/users/1", remoteAddr: "203.0.113.7:4000", want: http.StatusOK},
+		"outside network":     {path: "/api/v1/users/1", remoteAddr: "198.51.100.1:4000", want: http.StatusForbidden},
+		"denied range":        {path: "/api/v1/users", remoteAddr: "10.8.66.5:4000", want: http.StatusForbidden},
+		"admin route":         {path: "/api/v1/admin/maintenance", remoteAddr: "198.51.100.1:4000", want: http.StatusForbidden},
+		"unrestricted route":  {path: "/api/v1/datasets", remoteAddr: "198.51.100.1:4000", want: http.StatusOK},
+		"prefix of a segment": {path: "/api/v1/users-directory", remoteAddr: "198.51.100.1:4000", want: http.StatusOK},
+	} {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		req.RemoteAddr = tc.remoteAddr
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tc.want {
+			t.Errorf("Expected %d for %s, got %d", tc.want, name, w.Code)
+			continue
+		}
+		if tc.want == http.StatusForbidden {
+			var problem response.ErrorResponse
+			if err := json.NewDecoder(w.Body).Decode(&problem); err != nil || problem.Code != response.CodeIPNotAllowed {
+				t.Errorf("Expected %s problem for %s, got %q (%v)", response.CodeIPNotAllowed, name, problem.Code, err)
+			}
+		}
+	}
+}
+
+// Test clients outside the allowed networks are not let in by claiming an
+// allowed IP in forwarded headers, which only trusted proxies are believed on
+func TestIPAccess_SpoofedHeader(t *testing.T) {
+	allow, err := ParseNetworks([]string{"10.8.0.0/16"})
+	if err != nil {
+		t.Fatalf("Failed to parse networks: %v", err)
+	}
+	proxies, err := ParseNetworks([]string{"172.16.0.10"})
+	if err != nil {
+		t.Fatalf("Failed to parse networks: %v", err)
+	}
+
+	r := chi.NewRouter()
+	r.Use(RealIP(proxies))
+	r.Route("/api/v1", func(r chi.Router) {
+		r.Use(IPAccess(IPAccessRule{Prefixes: []string{"/users"}, Allow: allow}))
+		r.Get("/users", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	})
+
+	for name, tc := range map[string]struct {
+		remoteAddr, forwardedFor, realIP string
+		want                             int
+	}{
+		"spoofed forwarded for":          {remoteAddr: "198.51.100.1:4000", forwardedFor: "10.8.1.20", want: http.StatusForbidden},
+		"spoofed real ip":                {remoteAddr: "198.51.100.1:4000", realIP: "10.8.1.20", want: http.StatusForbidden},
+		"through the proxy":              {remoteAddr: "172.16.0.10:4000", forwardedFor: "10.8.1.20", want: http.StatusOK},
+		"spoofed through the proxy":      {remoteAddr: "172.16.0.10:4000", forwardedFor: "10.8.1.20, 198.51.100.1", want: http.StatusForbidden},
+		"outside client through a proxy": {remoteAddr: "172.16.0.10:4000", realIP: "198.51.100.1", want: http.StatusForbidden},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/users", nil)
+		req.RemoteAddr = tc.remoteAddr
+		if tc.forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", tc.forwardedFor)
+		}
+		if tc.realIP != "" {
+			req.Header.Set("X-Real-IP", tc.realIP)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tc.want {
+			t.Errorf("Expected %d for %s, got %d", tc.want, name, w.Code)
+		}
+	}
+}
+
+// Test invalid networks are rejected
+func TestParseNetworks(t *testing.T) {
+	for _, value := range []string{"10.8.0.0/33", "office", "10.8.0"} {
+		if _, err := ParseNetworks([]string{value}); err == nil {
+			t.Errorf("Expected %q to be rejected", value)
+		}
+	}
+}
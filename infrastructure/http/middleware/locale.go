@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"net/http"
+
+	"portal-data-backend/pkg/i18n"
+)
+
+// Locale stores the languages a client prefers in the request context for
+// localized catalog text. The lang query parameter overrides Accept-Language.
+func Locale(defaultLocale string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			langs := i18n.Negotiate(r.URL.Query().Get("lang"), r.Header.Get("Accept-Language"), defaultLocale)
+			w.Header().Add("Vary", "Accept-Language")
+
+			next.ServeHTTP(w, r.WithContext(i18n.WithLanguages(r.Context(), langs)))
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"portal-data-backend/infrastructure/http/response"
+	"portal-data-backend/infrastructure/logger"
+	"portal-data-backend/infrastructure/security"
+	"portal-data-backend/infrastructure/tenant"
+)
+
+// MaintenanceStatus is the maintenance mode of the API
+type MaintenanceStatus struct {
+	Enabled bool
+	// Message is shown to clients, a default one when empty
+	Message string
+	// RetryAfter is how long clients are told to wait
+	RetryAfter time.Duration
+}
+
+// Maintenance answers 503 with a Retry-After header while status reports the
+// API under maintenance. Users signed in with one of adminRoles are still
+// served, as are the routes below the API version starting with one of
+// allowed, like "/admin/", so admins can sign in and switch it off. When
+// status fails the error is logged and requests are served.
+func Maintenance(status func(ctx context.Context) (MaintenanceStatus, error), jwtManager *security.JWTManager, allowed []string, adminRoles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			current, err := status(r.Context())
+			if err != nil {
+				logger.FromContext(r.Context()).Error("Failed to read maintenance mode: %v", err)
+			}
+			if err != nil || !current.Enabled || maintenanceAllowed(r, allowed) || hasAdminToken(r, jwtManager, adminRoles) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			message := current.Message
+			if message == "" {
+				message = "The service is under maintenance, please try again later"
+			}
+			if current.RetryAfter > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(int(current.RetryAfter.Seconds())))
+			}
+			response.Error(w, http.StatusServiceUnavailable, response.CodeServiceUnavailable, message, nil)
+		})
+	}
+}
+
+// maintenanceAllowed reports whether the route of r is served during
+// maintenance
+func maintenanceAllowed(r *http.Request, allowed []string) bool {
+	routePath := routePath(r)
+	for _, prefix := range allowed {
+		if strings.HasPrefix(routePath, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasAdminToken reports whether r carries a valid token of the portal of the
+// request for one of adminRoles
+func hasAdminToken(r *http.Request, jwtManager *security.JWTManager, adminRoles []string) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+	claims, err := jwtManager.ValidateToken(token)
+	if err != nil {
+		return false
+	}
+	if t := tenant.FromContext(r.Context()); t != nil && !claims.IssuedBy(t.ID) {
+		return false
+	}
+	for _, role := range adminRoles {
+		if claims.RoleID == role {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/infrastructure/security"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Test only admins and allowed routes are served during maintenance
+func TestMaintenance(t *testing.T) {
+	jwtManager := security.NewJWTManager(&config.JWTConfig{Secret: "secret", AccessTokenExpiry: time.Hour, RefreshTokenExpiry: time.Hour, Issuer: "test"})
+	token := func(role string) string {
+		pair, err := jwtManager.GenerateTokenPair("user-1", "org-1", role, "user@example.com", "")
+		if err != nil {
+			t.Fatalf("Failed to generate token: %v", err)
+		}
+		return "Bearer " + pair.AccessToken
+	}
+	status := func(ctx context.Context) (MaintenanceStatus, error) {
+		return MaintenanceStatus{Enabled: true, RetryAfter: 2 * time.Minute}, nil
+	}
+
+	r := chi.NewRouter()
+	r.Route("/api/v1", func(r chi.Router) {
+		r.Use(Maintenance(status, jwtManager, []string{"/admin/", "/auth/"}, "admin"))
+		ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+		r.Get("/datasets", ok)
+		r.Post("/auth/login", ok)
+		r.Get("/admin/maintenance", ok)
+	})
+
+	for name, tc := range map[string]struct {
+		method, path, authorization string
+		want                        int
+	}{
+		"visitor":       {method: http.MethodGet, path: "/api/v1/datasets", want: http.StatusServiceUnavailable},
+		"user":          {method: http.MethodGet, path: "/api/v1/datasets", authorization: token("viewer"), want: http.StatusServiceUnavailable},
+		"admin":         {method: http.MethodGet, path: "/api/v1/datasets", authorization: token("admin"), want: http.StatusOK},
+		"sign in":       {method: http.MethodPost, path: "/api/v1/auth/login", want: http.StatusOK},
+		"admin route":   {method: http.MethodGet, path: "/api/v1/admin/maintenance", want: http.StatusOK},
+		"invalid token": {method: http.MethodGet, path: "/api/v1/datasets", authorization: "Bearer invalid", want: http.StatusServiceUnavailable},
+	} {
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		if tc.authorization != "" {
+			req.Header.Set("Authorization", tc.authorization)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tc.want {
+			t.Errorf("Expected %d for %s, got %d", tc.want, name, w.Code)
+		}
+		if tc.want == http.StatusServiceUnavailable && w.Header().Get("Retry-After") != "120" {
+			t.Errorf("Expected Retry-After 120 for %s, got %q", name, w.Header().Get("Retry-After"))
+		}
+	}
+}
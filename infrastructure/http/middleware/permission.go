@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"portal-data-backend/infrastructure/auth"
+	"portal-data-backend/infrastructure/http/response"
+	"portal-data-backend/infrastructure/logger"
+)
+
+type permissionsKey struct{}
+
+// permissionChecker reports whether the role roleID grants permission
+type permissionChecker func(ctx context.Context, roleID, permission string) (bool, error)
+
+// Permissions lets RequirePermission check the permissions of the role of
+// the signed-in user, which lookup returns. Users signed in with one of
+// adminRoles hold every permission.
+func Permissions(lookup func(ctx context.Context, roleID string) ([]string, error), adminRoles ...string) func(http.Handler) http.Handler {
+	check := permissionChecker(func(ctx context.Context, roleID, permission string) (bool, error) {
+		for _, role := range adminRoles {
+			if roleID == role {
+				return true, nil
+			}
+		}
+		granted, err := lookup(ctx, roleID)
+		if err != nil {
+			return false, err
+		}
+		for _, p := range granted {
+			if p == permission {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), permissionsKey{}, check)))
+		})
+	}
+}
+
+// RequirePermission lets through users whose role grants permission, like
+// "datasets:write". It must run after Auth, on routes below Permissions;
+// without it every request is refused.
+func RequirePermission(permission string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			roleID := auth.FromContext(r.Context()).RoleID
+			check, _ := r.Context().Value(permissionsKey{}).(permissionChecker)
+			if roleID == "" || check == nil {
+				response.Forbidden(w, response.CodeForbidden, "You are not allowed to access this resource", nil)
+				return
+			}
+
+			allowed, err := check(r.Context(), roleID, permission)
+			if err != nil {
+				logger.FromContext(r.Context()).Error("Failed to check permission %s: %v", permission, err)
+				response.InternalError(w, response.CodeInternalServerError, "Failed to check permissions", nil)
+				return
+			}
+			if !allowed {
+				response.Forbidden(w, response.CodeForbidden, "Your role does not grant the "+permission+" permission", nil)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/infrastructure/security"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Test write routes are served to roles granting the permission and to
+// admins only
+func TestRequirePermission(t *testing.T) {
+	jwtManager := security.NewJWTManager(&config.JWTConfig{Secret: "secret", AccessTokenExpiry: time.Hour, RefreshTokenExpiry: time.Hour, Issuer: "test"})
+	token := func(role string) string {
+		pair, err := jwtManager.GenerateTokenPair("user-1", "org-1", role, "user@example.com", "")
+		if err != nil {
+			t.Fatalf("Failed to generate token: %v", err)
+		}
+		return "Bearer " + pair.AccessToken
+	}
+	lookups := 0
+	lookup := func(ctx context.Context, roleID string) ([]string, error) {
+		lookups++
+		switch roleID {
+		case "editor":
+			return []string{"datasets:write"}, nil
+		case "broken":
+			return nil, errors.New("database is down")
+		}
+		return nil, nil
+	}
+
+	r := chi.NewRouter()
+	r.Use(Permissions(lookup, "admin"))
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+	r.Get("/datasets", ok)
+	r.With(Auth(jwtManager, nil), RequirePermission("datasets:write")).Post("/datasets", ok)
+
+	for name, tc := range map[string]struct {
+		method, authorization string
+		want                  int
+	}{
+		"visitor reads":  {method: http.MethodGet, want: http.StatusOK},
+		"visitor writes": {method: http.MethodPost, want: http.StatusUnauthorized},
+		"editor":         {method: http.MethodPost, authorization: token("editor"), want: http.StatusOK},
+		"viewer":         {method: http.MethodPost, authorization: token("viewer"), want: http.StatusForbidden},
+		"admin":          {method: http.MethodPost, authorization: token("admin"), want: http.StatusOK},
+		"failed lookup":  {method: http.MethodPost, authorization: token("broken"), want: http.StatusInternalServerError},
+	} {
+		req := httptest.NewRequest(tc.method, "/datasets", nil)
+		if tc.authorization != "" {
+			req.Header.Set("Authorization", tc.authorization)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tc.want {
+			t.Errorf("Expected %d for %s, got %d", tc.want, name, w.Code)
+		}
+	}
+	if lookups != 3 {
+		t.Errorf("Expected permissions looked up for 3 requests, got %d", lookups)
+	}
+
+	// Without Permissions every request is refused
+	bare := chi.NewRouter()
+	bare.With(Auth(jwtManager, nil), RequirePermission("datasets:write")).Post("/datasets", ok)
+	req := httptest.NewRequest(http.MethodPost, "/datasets", nil)
+	req.Header.Set("Authorization", token("editor"))
+	w := httptest.NewRecorder()
+	bare.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 without Permissions, got %d", w.Code)
+	}
+}
@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"portal-data-backend/infrastructure/http/response"
+)
+
+// RateLimit allows each client limit requests per window and answers the
+// rest with 429. Clients are told apart by their remote address, which the
+// RealIP middleware sets from the headers of trusted proxies only. A limit
+// below 1 disables the check.
+func RateLimit(limit int, window time.Duration) func(http.Handler) http.Handler {
+	return NewRateLimiter(limit, window).Handler
+}
+
+// RateLimiter counts requests per client in fixed windows. Its limit can be
+// changed while it serves requests.
+type RateLimiter struct {
+	mu        sync.Mutex
+	limit     int
+	window    time.Duration
+	clients   map[string]*rateWindow
+	lastSweep time.Time
+}
+
+// NewRateLimiter creates a limiter allowing each client limit requests per
+// window; a limit below 1 disables it
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{limit: limit, window: window, clients: make(map[string]*rateWindow)}
+}
+
+// Set changes the limit of l. Windows already started keep their start.
+func (l *RateLimiter) Set(limit int, window time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limit = limit
+	l.window = window
+}
+
+// Handler answers the requests over the limit with 429
+func (l *RateLimiter) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if wait := l.allow(clientIP(r), time.Now()); wait > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			response.Error(w, http.StatusTooManyRequests, response.CodeTooManyRequests, "Too many requests, please try again later", nil)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// allow records a request of client and returns how long it has to wait if
+// the request is over the limit, or 0
+func (l *RateLimiter) allow(client string, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.limit < 1 {
+		return 0
+	}
+
+	// Forget clients whose window is over, so the map does not grow forever
+	if now.Sub(l.lastSweep) >= l.window {
+		for key, w := range l.clients {
+			if now.Sub(w.start) >= l.window {
+				delete(l.clients, key)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	w, ok := l.clients[client]
+	if !ok || now.Sub(w.start) >= l.window {
+		w = &rateWindow{start: now}
+		l.clients[client] = w
+	}
+	if w.count >= l.limit {
+		return w.start.Add(l.window).Sub(now)
+	}
+	w.count++
+	return 0
+}
+
+// clientIP returns the host of the remote address of r
+func clientIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// RealIP sets the remote address of requests coming through one of the
+// proxies of trusted to the client IP they forward in X-Forwarded-For or
+// X-Real-IP. The headers of other peers are ignored, so clients cannot pass
+// themselves off as another IP to the IP access rules and rate limits.
+func RealIP(trusted []*net.IPNet) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ip := forwardedIP(r, trusted); ip != "" {
+				r.RemoteAddr = ip
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// forwardedIP returns the client IP a trusted proxy forwarded r for, empty
+// when r does not come from one. X-Forwarded-For is read from the right,
+// skipping the trusted proxies the request went through, since clients may
+// put any address at its left.
+func forwardedIP(r *http.Request, trusted []*net.IPNet) string {
+	peer := net.ParseIP(clientIP(r))
+	if peer == nil || !containsIP(trusted, peer) {
+		return ""
+	}
+
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		hops := strings.Split(forwarded, ",")
+		ip := ""
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := net.ParseIP(strings.TrimSpace(hops[i]))
+			if hop == nil {
+				break
+			}
+			ip = hop.String()
+			if !containsIP(trusted, hop) {
+				break
+			}
+		}
+		return ip
+	}
+	if ip := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); ip != nil {
+		return ip.String()
+	}
+	return ""
+}
@@ -0,0 +1,148 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+
+	"portal-data-backend/infrastructure/cache"
+	"portal-data-backend/infrastructure/logger"
+)
+
+// SurrogateKeyHeader lists the surrogate keys of a response, separated by
+// spaces, as CDNs read them
+const SurrogateKeyHeader = "Surrogate-Key"
+
+// cachedResponse is a response as the response cache keeps it, with the
+// versions of its surrogate keys when it was built
+type cachedResponse struct {
+	Status   int               `json:"status"`
+	Header   http.Header       `json:"header"`
+	Body     []byte            `json:"body"`
+	Versions map[string]string `json:"versions"`
+}
+
+// ResponseCache returns middleware caching the responses of public GETs in
+// responses, by URL and Accept-Language. Responses are tagged with the
+// surrogate keys the middleware is given, those the handler adds with
+// SurrogateKeys and cache.SurrogateAll, and served from the cache until one
+// of their keys is purged. Requests carrying a token and responses other
+// than 200 OK are not cached. Without a cache it passes requests through.
+func ResponseCache(responses *cache.Namespace, surrogates *cache.Surrogates) func(keys ...string) func(http.Handler) http.Handler {
+	return func(keys ...string) func(http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler {
+			if responses == nil || surrogates == nil {
+				return next
+			}
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet || r.Header.Get("Authorization") != "" {
+					next.ServeHTTP(w, r)
+					return
+				}
+
+				ctx := r.Context()
+				key := r.URL.RequestURI() + "|" + r.Header.Get("Accept-Language")
+				var cached cachedResponse
+				if responses.Get(ctx, key, &cached) && surrogates.Current(ctx, cached.Versions) {
+					writeCached(w, &cached, "HIT")
+					return
+				}
+
+				// Versions are taken before the handler reads anything, so a
+				// purge while it runs leaves its response stale
+				tags := append([]string{cache.SurrogateAll}, keys...)
+				versions, err := surrogates.Versions(ctx, tags)
+				if err != nil {
+					logger.FromContext(ctx).Warn("failed to read surrogate keys %v: %v", tags, err)
+					next.ServeHTTP(w, r)
+					return
+				}
+
+				w.Header().Set("X-Cache", "MISS")
+				recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK, before: w.Header().Clone()}
+				next.ServeHTTP(recorder, r)
+				if recorder.status != http.StatusOK || recorder.header == nil {
+					return
+				}
+				store(ctx, responses, surrogates, key, recorder, versions)
+			})
+		}
+	}
+}
+
+// SurrogateKeys tags the response of w with keys, like "dataset:<id>", so
+// purging any of them invalidates it
+func SurrogateKeys(w http.ResponseWriter, keys ...string) {
+	existing := w.Header().Get(SurrogateKeyHeader)
+	w.Header().Set(SurrogateKeyHeader, strings.TrimSpace(existing+" "+strings.Join(keys, " ")))
+}
+
+// store caches the response recorder wrote, tagged with the keys it was
+// given and those its handler added
+func store(ctx context.Context, responses *cache.Namespace, surrogates *cache.Surrogates, key string, recorder *responseRecorder, versions map[string]string) {
+	if added := strings.Fields(recorder.header.Get(SurrogateKeyHeader)); len(added) > 0 {
+		addedVersions, err := surrogates.Versions(ctx, added)
+		if err != nil {
+			logger.FromContext(ctx).Warn("failed to read surrogate keys %v: %v", added, err)
+			return
+		}
+		for k, version := range addedVersions {
+			versions[k] = version
+		}
+	}
+
+	responses.Set(ctx, key, cachedResponse{
+		Status:   recorder.status,
+		Header:   recorder.header,
+		Body:     recorder.body.Bytes(),
+		Versions: versions,
+	})
+}
+
+func writeCached(w http.ResponseWriter, cached *cachedResponse, status string) {
+	for name, values := range cached.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set("X-Cache", status)
+	w.WriteHeader(cached.Status)
+	w.Write(cached.Body)
+}
+
+// responseRecorder copies the response written through it. header keeps
+// the headers the handler set, leaving out those of the middleware around
+// it, which was in before, and of the writers below, like compression.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	before http.Header
+	header http.Header
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.header == nil {
+		r.status = status
+		r.header = http.Header{}
+		for name, values := range r.Header() {
+			if !slices.Equal(r.before[name], values) {
+				r.header[name] = slices.Clone(values)
+			}
+		}
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.header == nil {
+		r.WriteHeader(http.StatusOK)
+	}
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap lets response helpers reach the writers below
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
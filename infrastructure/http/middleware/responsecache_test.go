@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"portal-data-backend/infrastructure/cache"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Test public GETs are served from the cache until one of their surrogate
+// keys is purged
+func TestResponseCache(t *testing.T) {
+	store := cache.NewStore(cache.NewMemory(), "test:")
+	surrogates := store.Surrogates(time.Minute)
+	cached := ResponseCache(store.Namespace("responses", time.Minute), surrogates)
+
+	calls := 0
+	r := chi.NewRouter()
+	r.With(cached("datasets")).Get("/datasets", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":[]}`))
+	})
+	r.With(cached()).Get("/datasets/{id}", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		SurrogateKeys(w, "dataset:"+chi.URLParam(r, "id"))
+		w.Write([]byte(`{"data":{}}`))
+	})
+	r.With(cached()).Get("/missing", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	get := func(path, authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+	expect := func(rec *httptest.ResponseRecorder, status string, wantCalls int) {
+		t.Helper()
+		if got := rec.Header().Get("X-Cache"); got != status {
+			t.Errorf("Expected X-Cache %s, got %q", status, got)
+		}
+		if calls != wantCalls {
+			t.Errorf("Expected %d handler calls, got %d", wantCalls, calls)
+		}
+	}
+
+	expect(get("/datasets", ""), "MISS", 1)
+	rec := get("/datasets", "")
+	expect(rec, "HIT", 1)
+	if rec.Body.String() != `{"data":[]}` || rec.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("Expected the cached body and headers, got %q and %q", rec.Body.String(), rec.Header().Get("Content-Type"))
+	}
+
+	expect(get("/datasets", "Bearer token"), "", 2)
+
+	surrogates.Purge(context.Background(), "datasets")
+	expect(get("/datasets", ""), "MISS", 3)
+
+	expect(get("/datasets/ds-1", ""), "MISS", 4)
+	rec = get("/datasets/ds-1", "")
+	expect(rec, "HIT", 4)
+	if rec.Header().Get(SurrogateKeyHeader) != "dataset:ds-1" {
+		t.Errorf("Expected the surrogate key of the dataset, got %q", rec.Header().Get(SurrogateKeyHeader))
+	}
+	surrogates.Purge(context.Background(), "dataset:ds-2")
+	expect(get("/datasets/ds-1", ""), "HIT", 4)
+	surrogates.Purge(context.Background(), "dataset:ds-1")
+	expect(get("/datasets/ds-1", ""), "MISS", 5)
+
+	surrogates.Purge(context.Background(), cache.SurrogateAll)
+	expect(get("/datasets", ""), "MISS", 6)
+
+	get("/missing", "")
+	expect(get("/missing", ""), "MISS", 8)
+}
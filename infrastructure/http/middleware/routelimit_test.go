@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"portal-data-backend/infrastructure/cache"
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/infrastructure/security"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Test limited routes allow each user, key and address their own requests
+// and tell the limit in headers
+func TestRouteRateLimit(t *testing.T) {
+	jwtManager := security.NewJWTManager(&config.JWTConfig{Secret: "secret", AccessTokenExpiry: time.Hour, RefreshTokenExpiry: time.Hour, Issuer: "test"})
+	token := func(userID string) string {
+		pair, err := jwtManager.GenerateTokenPair(userID, "org-1", "role-1", "user@example.com", "")
+		if err != nil {
+			t.Fatalf("Failed to generate token: %v", err)
+		}
+		return "Bearer " + pair.AccessToken
+	}
+
+	r := chi.NewRouter()
+	r.Route("/api/v1", func(r chi.Router) {
+		r.Use(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if key := r.Header.Get(APIKeyHeader); key != "" {
+					r = r.WithContext(context.WithValue(r.Context(), apiClientKey{}, &APIClient{KeyID: key}))
+				}
+				next.ServeHTTP(w, r)
+			})
+		})
+		r.Use(RouteRateLimit(cache.NewMemoryBuckets(), jwtManager,
+			RateLimitRule{Name: "search", Prefixes: []string{"/search"}, Limit: 2, Window: time.Minute},
+			RateLimitRule{Name: "api", Prefixes: []string{"/"}, Limit: 5, Window: time.Minute},
+		))
+		ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+		r.Get("/search", ok)
+		r.Get("/datasets", ok)
+	})
+	get := func(path, authorization, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = "198.51.100.1:4000"
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		if key != "" {
+			req.Header.Set(APIKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	alice := token("user-1")
+	for i, remaining := range []string{"1", "0"} {
+		w := get("/api/v1/search", alice, "")
+		if w.Code != http.StatusOK || w.Header().Get("X-RateLimit-Limit") != "2" || w.Header().Get("X-RateLimit-Remaining") != remaining {
+			t.Fatalf("Expected search %d to pass with %s left, got %d %v", i, remaining, w.Code, w.Header())
+		}
+	}
+	w := get("/api/v1/search", alice, "")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "30" || w.Header().Get("X-RateLimit-Reset") != "60" {
+		t.Errorf("Expected 429 retrying after 30s, got %d %v", w.Code, w.Header())
+	}
+
+	// Other users, keys and anonymous clients have their own buckets, and
+	// the search group does not take from the general one
+	if w := get("/api/v1/search", token("user-2"), ""); w.Code != http.StatusOK {
+		t.Errorf("Expected another user to be served, got %d", w.Code)
+	}
+	if w := get("/api/v1/search", alice, "key-1"); w.Code != http.StatusOK {
+		t.Errorf("Expected an API key to be limited apart from the user, got %d", w.Code)
+	}
+	if w := get("/api/v1/search", "Bearer invalid", ""); w.Code != http.StatusOK {
+		t.Errorf("Expected an invalid token to be limited by address, got %d", w.Code)
+	}
+	if w := get("/api/v1/datasets", alice, ""); w.Code != http.StatusOK || w.Header().Get("X-RateLimit-Remaining") != "4" {
+		t.Errorf("Expected the general group to have its own bucket, got %d %v", w.Code, w.Header())
+	}
+}
+
+// Test anonymous clients do not get fresh buckets by forging forwarded
+// headers, which count only from trusted proxies
+func TestRouteRateLimit_SpoofedHeader(t *testing.T) {
+	proxies, err := ParseNetworks([]string{"172.16.0.10"})
+	if err != nil {
+		t.Fatalf("Failed to parse networks: %v", err)
+	}
+
+	r := chi.NewRouter()
+	r.Use(RealIP(proxies))
+	r.Route("/api/v1", func(r chi.Router) {
+		r.Use(RouteRateLimit(cache.NewMemoryBuckets(), nil, RateLimitRule{Name: "api", Prefixes: []string{"/"}, Limit: 1, Window: time.Minute}))
+		r.Get("/datasets", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	})
+	get := func(remoteAddr, forwardedFor string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/datasets", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := get("198.51.100.1:4000", "203.0.113.1"); code != http.StatusOK {
+		t.Fatalf("Expected the first request to be served, got %d", code)
+	}
+	if code := get("198.51.100.1:4000", "203.0.113.2"); code != http.StatusTooManyRequests {
+		t.Errorf("Expected a forged header to take from the bucket of the peer, got %d", code)
+	}
+
+	// Behind the proxy, the clients it forwards have their own buckets
+	if code := get("172.16.0.10:4000", "203.0.113.1"); code != http.StatusOK {
+		t.Errorf("Expected a client of the proxy to be served, got %d", code)
+	}
+	if code := get("172.16.0.10:4000", "203.0.113.2"); code != http.StatusOK {
+		t.Errorf("Expected another client of the proxy to be served, got %d", code)
+	}
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/response"
+)
+
+// Shape lets clients control the size of the responses of a route: the
+// fields query parameter picks the fields of the resources it returns, and
+// expand adds the relations of relations to them, loaded in one call per
+// relation. Mount it on reads after the middleware their context needs.
+func Shape(relations response.Relations) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query := r.URL.Query()
+			shape, err := response.ParseShape(query.Get("fields"), query.Get("expand"), relations)
+			if err != nil {
+				response.BadRequest(w, response.CodeBadRequest, "Invalid response shape: "+err.Error(), nil)
+				return
+			}
+			next.ServeHTTP(response.WithShape(w, r.Context(), shape), r)
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"portal-data-backend/infrastructure/http/response"
+)
+
+type shapeOrg struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Code string `json:"code"`
+}
+
+type shapeDataset struct {
+	ID             string  `json:"id"`
+	Name           string  `json:"name"`
+	OrganizationID string  `json:"organization_id"`
+	Description    *string `json:"description,omitempty"`
+}
+
+// newShapedHandler serves a page of datasets whose organizations and tags
+// expand, counting the loads of organizations in loads
+func newShapedHandler(loads *int) http.Handler {
+	orgs := map[string]*shapeOrg{"o1": {"o1", "Bappeda", "BPD"}}
+	relations := response.Relations{
+		"organization": {
+			Key: "organization_id",
+			Load: response.LoadBy(func(ctx context.Context, ids []string) ([]*shapeOrg, error) {
+				*loads++
+				var found []*shapeOrg
+				for _, id := range ids {
+					if org, ok := orgs[id]; ok {
+						found = append(found, org)
+					}
+				}
+				return found, nil
+			}, func(org *shapeOrg) string { return org.ID }),
+		},
+		"tags": {
+			Key:  "id",
+			Many: true,
+			Load: response.LoadGrouped(func(ctx context.Context, ids []string) (map[string][]string, error) {
+				return map[string][]string{"d1": {"population"}}, nil
+			}),
+		},
+	}
+
+	return Shape(relations)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response.OK(w, response.CodeSuccess, "Datasets retrieved successfully", map[string]interface{}{
+			"datasets": []shapeDataset{
+				{ID: "d1", Name: "Population", OrganizationID: "o1"},
+				{ID: "d2", Name: "Schools", OrganizationID: "o1"},
+				{ID: "d3", Name: "Hospitals", OrganizationID: "o9"},
+			},
+			"meta": map[string]int{"page": 1, "total": 3},
+		})
+	}))
+}
+
+func shapedGet(handler http.Handler, query string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/datasets?"+query, nil))
+	return w
+}
+
+// Test responses are left as they are without fields and expand
+func TestShape_Unshaped(t *testing.T) {
+	var loads int
+	w := shapedGet(newShapedHandler(&loads), "")
+
+	want := `"datasets":[{"id":"d1","name":"Population","organization_id":"o1"}`
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), want) {
+		t.Errorf("Expected the datasets as they are, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+// Test fields picks the fields of the resources of a list, not of its meta
+func TestShape_Fields(t *testing.T) {
+	var loads int
+	w := shapedGet(newShapedHandler(&loads), "fields=id,missing")
+
+	want := `"data":{"datasets":[{"id":"d1"},{"id":"d2"},{"id":"d3"}],"meta":{"page":1,"total":3}}`
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), want) {
+		t.Errorf("Expected %s, got %d %s", want, w.Code, w.Body.String())
+	}
+}
+
+// Test relations expand in one load, and nested fields select their fields
+func TestShape_Expand(t *testing.T) {
+	var loads int
+	w := shapedGet(newShapedHandler(&loads), "expand=organization,tags&fields=name,organization.name")
+
+	want := `"datasets":[` +
+		`{"name":"Population","organization":{"name":"Bappeda"},"tags":["population"]},` +
+		`{"name":"Schools","organization":{"name":"Bappeda"},"tags":[]},` +
+		`{"name":"Hospitals","organization":null,"tags":[]}]`
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), want) {
+		t.Errorf("Expected %s, got %d %s", want, w.Code, w.Body.String())
+	}
+	if loads != 1 {
+		t.Errorf("Expected organizations to load once, got %d", loads)
+	}
+}
+
+// Test unknown relations and malformed fields are rejected
+func TestShape_Invalid(t *testing.T) {
+	var loads int
+	handler := newShapedHandler(&loads)
+
+	for query, want := range map[string]string{
+		"expand=files":              `cannot expand \"files\", expected one of organization, tags`,
+		"fields=id,Name":            `invalid field \"Name\"`,
+		"fields=organization..name": `invalid field \"organization..name\"`,
+	} {
+		w := shapedGet(handler, query)
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), want) {
+			t.Errorf("Expected 400 with %s for %s, got %d %s", want, query, w.Code, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/response"
+	"portal-data-backend/infrastructure/logger"
+	"portal-data-backend/infrastructure/tenant"
+	"portal-data-backend/pkg/errors"
+)
+
+// Tenant scopes requests to the portal they are for: the tenant named by
+// header, or else the tenant of the request hostname, or else fallback.
+// Requests naming an unknown or disabled tenant are refused.
+func Tenant(resolver *tenant.Resolver, header, fallback string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", header)
+
+			t, err := resolver.Resolve(r.Context(), r.Header.Get(header), r.Host, fallback)
+			if errors.Is(err, errors.ErrNotFound) {
+				response.NotFound(w, response.CodeNotFound, "Portal not found", nil)
+				return
+			}
+			if err != nil {
+				logger.FromContext(r.Context()).Error("failed to resolve tenant: %v", err)
+				response.InternalError(w, response.CodeInternalServerError, "Failed to resolve portal", nil)
+				return
+			}
+
+			ctx := tenant.WithTenant(r.Context(), t)
+			ctx = logger.WithContext(ctx, logger.FromContext(ctx).With("tenant", t.ID))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+
+	"portal-data-backend/pkg/tracking"
+)
+
+// TrackingOptOut records in the request context that a client sending DNT: 1
+// or Sec-GPC: 1 opted out of usage analytics, so its requests are not counted
+func TrackingOptOut(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if tracking.HeaderOptOut(r.Header) {
+			r = r.WithContext(tracking.WithOptOut(r.Context()))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// TrackingChannel records in the request context the channel its usage is
+// counted in: harvesters, whose user agent contains one of harvesterAgents,
+// developer applications calling with an API key, and the portal otherwise.
+// It must run after APIKeys.
+func TrackingChannel(harvesterAgents []string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			channel := tracking.ChannelPortal
+			switch {
+			case tracking.HarvesterAgent(r.UserAgent(), harvesterAgents):
+				channel = tracking.ChannelHarvester
+			case APIClientFrom(r.Context()) != nil:
+				channel = tracking.ChannelAPI
+			}
+			next.ServeHTTP(w, r.WithContext(tracking.WithChannel(r.Context(), channel)))
+		})
+	}
+}
@@ -0,0 +1,602 @@
+// Package openapi assembles an OpenAPI 3 specification from the routes each
+// module describes. Request and response schemas are derived from the Go
+// types the handlers decode and encode, so they follow changes to those types.
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Document is an OpenAPI 3 document
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Servers    []Server            `json:"servers,omitempty"`
+	Tags       []Tag               `json:"tags,omitempty"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info describes the API
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// Server is a base URL the paths are relative to
+type Server struct {
+	URL string `json:"url"`
+}
+
+// Tag groups the operations of a module
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem holds the operations of a path keyed by lower case HTTP method
+type PathItem map[string]*Operation
+
+// Components holds the schemas, responses and security schemes operations
+// refer to
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	Responses       map[string]Response        `json:"responses"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes"`
+}
+
+// SecurityScheme describes how a client authenticates
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	In           string `json:"in,omitempty"`
+	Name         string `json:"name,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+// Schema describes a JSON value
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+}
+
+// Parameter describes a path, query or header parameter
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody describes the body of a request
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response describes a response of an operation
+type Response struct {
+	Ref         string               `json:"$ref,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType holds the schema of a body in one content type
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Security schemes operations can require
+const (
+	BearerAuth  = "bearerAuth"
+	IngestToken = "ingestToken"
+	APIKey      = "apiKey"
+)
+
+// Operation describes one route. Operations require a bearer token unless
+// marked Public.
+type Operation struct {
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary"`
+	OperationID string                `json:"operationId"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security"`
+
+	spec *Spec
+}
+
+// Spec collects the operations modules describe into a Document
+type Spec struct {
+	doc   *Document
+	names map[reflect.Type]string
+}
+
+// New creates an empty specification of an API served under serverURL
+func New(title, version, serverURL string) *Spec {
+	return &Spec{
+		doc: &Document{
+			OpenAPI: "3.0.3",
+			Info:    Info{Title: title, Version: version},
+			Servers: []Server{{URL: serverURL}},
+			Paths:   map[string]PathItem{},
+			Components: Components{
+				Schemas: map[string]*Schema{
+					"Response": {
+						Type: "object",
+						Properties: map[string]*Schema{
+							"code":    {Type: "string"},
+							"message": {Type: "string"},
+						},
+						Required: []string{"code", "message"},
+					},
+					// RFC 7807 problem details
+					"ErrorResponse": {
+						Type: "object",
+						Properties: map[string]*Schema{
+							"type":       {Type: "string"},
+							"title":      {Type: "string"},
+							"status":     {Type: "integer", Format: "int32"},
+							"detail":     {Type: "string"},
+							"instance":   {Type: "string"},
+							"code":       {Type: "string"},
+							"request_id": {Type: "string"},
+							"errors": {Type: "array", Items: &Schema{
+								Type: "object",
+								Properties: map[string]*Schema{
+									"field":   {Type: "string"},
+									"message": {Type: "string"},
+								},
+							}},
+						},
+						Required: []string{"type", "title", "status", "detail", "code"},
+					},
+				},
+				Responses: map[string]Response{
+					"Error": {Description: "Error", Content: map[string]MediaType{
+						"application/problem+json": {Schema: &Schema{Ref: "#/components/schemas/ErrorResponse"}},
+					}},
+				},
+				SecuritySchemes: map[string]*SecurityScheme{
+					BearerAuth:  {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+					IngestToken: {Type: "apiKey", In: "header", Name: "X-Integration-Token", Description: "Ingest token of an integration"},
+					APIKey:      {Type: "apiKey", In: "header", Name: "X-API-Key", Description: "API key of a developer application, sent with any request to be served at the rate limit of its plan"},
+				},
+			},
+		},
+		names: map[reflect.Type]string{},
+	}
+}
+
+// Document returns the assembled document with its tags sorted by name
+func (s *Spec) Document() *Document {
+	sort.Slice(s.doc.Tags, func(i, j int) bool { return s.doc.Tags[i].Name < s.doc.Tags[j].Name })
+	return s.doc
+}
+
+// Group adds operations under one tag
+type Group struct {
+	spec *Spec
+	tag  string
+}
+
+// Tag starts the operations of a module
+func (s *Spec) Tag(name, description string) *Group {
+	s.doc.Tags = append(s.doc.Tags, Tag{Name: name, Description: description})
+	return &Group{spec: s, tag: name}
+}
+
+// Get adds a GET operation
+func (g *Group) Get(path, summary string) *Operation {
+	return g.add(http.MethodGet, path, summary)
+}
+
+// Post adds a POST operation
+func (g *Group) Post(path, summary string) *Operation {
+	return g.add(http.MethodPost, path, summary)
+}
+
+// Put adds a PUT operation
+func (g *Group) Put(path, summary string) *Operation {
+	return g.add(http.MethodPut, path, summary)
+}
+
+// Patch adds a PATCH operation
+func (g *Group) Patch(path, summary string) *Operation {
+	return g.add(http.MethodPatch, path, summary)
+}
+
+// Delete adds a DELETE operation
+func (g *Group) Delete(path, summary string) *Operation {
+	return g.add(http.MethodDelete, path, summary)
+}
+
+// Head adds a HEAD operation
+func (g *Group) Head(path, summary string) *Operation {
+	return g.add(http.MethodHead, path, summary)
+}
+
+// Options adds an OPTIONS operation
+func (g *Group) Options(path, summary string) *Operation {
+	return g.add(http.MethodOptions, path, summary)
+}
+
+func (g *Group) add(method, path, summary string) *Operation {
+	op := &Operation{
+		Tags:        []string{g.tag},
+		Summary:     summary,
+		OperationID: operationID(method, path),
+		Responses: map[string]Response{
+			"default": {Ref: "#/components/responses/Error"},
+		},
+		Security: []map[string][]string{{BearerAuth: {}}},
+		spec:     g.spec,
+	}
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			op.Parameters = append(op.Parameters, Parameter{
+				Name: strings.Trim(segment, "{}"), In: "path", Required: true, Schema: &Schema{Type: "string"},
+			})
+		}
+	}
+
+	item, ok := g.spec.doc.Paths[path]
+	if !ok {
+		item = PathItem{}
+		g.spec.doc.Paths[path] = item
+	}
+	item[strings.ToLower(method)] = op
+	return op
+}
+
+// Public marks an operation as open to anonymous clients
+func (o *Operation) Public() *Operation {
+	o.Security = []map[string][]string{}
+	return o
+}
+
+// Secured replaces the bearer token with another security scheme
+func (o *Operation) Secured(scheme string) *Operation {
+	o.Security = []map[string][]string{{scheme: {}}}
+	return o
+}
+
+// Query adds the fields of the struct v as query parameters. When only is
+// given, just the fields it names are added.
+func (o *Operation) Query(v interface{}, only ...string) *Operation {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	for _, field := range fields(t) {
+		if len(only) > 0 && !contains(only, field.name) {
+			continue
+		}
+		schema := o.spec.schemaOf(field.Type)
+		if field.enum != nil {
+			schema.Enum = field.enum
+		}
+		o.Parameters = append(o.Parameters, Parameter{Name: field.name, In: "query", Required: field.required, Schema: schema})
+	}
+	return o
+}
+
+// Param adds a string query parameter
+func (o *Operation) Param(name string, required bool) *Operation {
+	o.Parameters = append(o.Parameters, Parameter{Name: name, In: "query", Required: required, Schema: &Schema{Type: "string"}})
+	return o
+}
+
+// IntParam adds an integer query parameter
+func (o *Operation) IntParam(name string, required bool) *Operation {
+	o.Parameters = append(o.Parameters, Parameter{Name: name, In: "query", Required: required, Schema: &Schema{Type: "integer", Format: "int32"}})
+	return o
+}
+
+// Shaped adds the fields and expand query parameters of the routes whose
+// responses clients shape, expand taking relations
+func (o *Operation) Shaped(relations ...string) *Operation {
+	o.Parameters = append(o.Parameters, Parameter{
+		Name:        "fields",
+		In:          "query",
+		Description: "Comma separated fields of the resources to return, like id,name,organization.name",
+		Schema:      &Schema{Type: "string"},
+	})
+	if len(relations) > 0 {
+		o.Parameters = append(o.Parameters, Parameter{
+			Name:        "expand",
+			In:          "query",
+			Description: "Comma separated relations to add to the resources: " + strings.Join(relations, ", "),
+			Schema:      &Schema{Type: "string"},
+		})
+	}
+	return o
+}
+
+// Header adds a string header parameter
+func (o *Operation) Header(name string, required bool) *Operation {
+	o.Parameters = append(o.Parameters, Parameter{Name: name, In: "header", Required: required, Schema: &Schema{Type: "string"}})
+	return o
+}
+
+// Body sets the JSON request body to the schema of v
+func (o *Operation) Body(v interface{}) *Operation {
+	o.RequestBody = &RequestBody{Required: true, Content: jsonContent(o.spec.schemaOf(reflect.TypeOf(v)))}
+	return o
+}
+
+// RawBody sets the request body to an uninterpreted body in any of
+// contentTypes
+func (o *Operation) RawBody(contentTypes ...string) *Operation {
+	content := make(map[string]MediaType, len(contentTypes))
+	for _, contentType := range contentTypes {
+		content[contentType] = MediaType{Schema: &Schema{Type: "string"}}
+	}
+	o.RequestBody = &RequestBody{Required: true, Content: content}
+	return o
+}
+
+// Upload sets the request body to a multipart form with a file field and
+// optional text fields
+func (o *Operation) Upload(fileField string, textFields ...string) *Operation {
+	form := &Schema{
+		Type:       "object",
+		Properties: map[string]*Schema{fileField: {Type: "string", Format: "binary"}},
+		Required:   []string{fileField},
+	}
+	for _, field := range textFields {
+		form.Properties[field] = &Schema{Type: "string"}
+	}
+	o.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{"multipart/form-data": {Schema: form}}}
+	return o
+}
+
+// Returns adds a response wrapping v in the standard response envelope. A nil
+// v is a response without data.
+func (o *Operation) Returns(status int, v interface{}) *Operation {
+	envelope := &Schema{Ref: "#/components/schemas/Response"}
+	if v != nil {
+		envelope = &Schema{AllOf: []*Schema{envelope, {
+			Type:       "object",
+			Properties: map[string]*Schema{"data": o.spec.schemaOf(reflect.TypeOf(v))},
+		}}}
+	}
+	o.Responses[fmt.Sprint(status)] = Response{Description: http.StatusText(status), Content: jsonContent(envelope)}
+	return o
+}
+
+// ReturnsBody adds a JSON response of v itself, outside the standard
+// envelope, for endpoints following the format of another protocol
+func (o *Operation) ReturnsBody(status int, v interface{}) *Operation {
+	o.Responses[fmt.Sprint(status)] = Response{Description: http.StatusText(status), Content: jsonContent(o.spec.schemaOf(reflect.TypeOf(v)))}
+	return o
+}
+
+// ReturnsFile adds a response with a body of any of contentTypes
+func (o *Operation) ReturnsFile(status int, contentTypes ...string) *Operation {
+	content := make(map[string]MediaType, len(contentTypes))
+	for _, contentType := range contentTypes {
+		content[contentType] = MediaType{Schema: &Schema{Type: "string", Format: "binary"}}
+	}
+	o.Responses[fmt.Sprint(status)] = Response{Description: http.StatusText(status), Content: content}
+	return o
+}
+
+// ReturnsNothing adds a response without a body
+func (o *Operation) ReturnsNothing(status int) *Operation {
+	o.Responses[fmt.Sprint(status)] = Response{Description: http.StatusText(status)}
+	return o
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	durationType      = reflect.TypeOf(time.Duration(0))
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// schemaOf derives the schema of t. Named structs become components.
+func (s *Spec) schemaOf(t reflect.Type) *Schema {
+	switch {
+	case t == nil:
+		return &Schema{}
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == durationType:
+		return &Schema{Type: "integer", Format: "int64"}
+	case t == rawMessageType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		schema := s.schemaOf(t.Elem())
+		if schema.Ref != "" {
+			return schema
+		}
+		schema.Nullable = true
+		return schema
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			if t.Implements(textMarshalerType) {
+				return &Schema{Type: "string"}
+			}
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.structSchema(t)
+		}
+		return s.component(t)
+	}
+	return &Schema{}
+}
+
+// component registers the named struct t once and refers to it
+func (s *Spec) component(t reflect.Type) *Schema {
+	name, ok := s.names[t]
+	if !ok {
+		name = componentName(t)
+		for taken := true; taken; {
+			taken = false
+			for other, otherName := range s.names {
+				if otherName == name && other != t {
+					name += "_"
+					taken = true
+				}
+			}
+		}
+		s.names[t] = name
+		s.doc.Components.Schemas[name] = s.structSchema(t)
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func (s *Spec) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for _, field := range fields(t) {
+		property := s.schemaOf(field.Type)
+		if field.enum != nil && property.Ref == "" {
+			property.Enum = field.enum
+		}
+		schema.Properties[field.name] = property
+		if field.required {
+			schema.Required = append(schema.Required, field.name)
+		}
+	}
+	return schema
+}
+
+// field is a struct field as it appears in JSON
+type field struct {
+	reflect.StructField
+	name     string
+	required bool
+	enum     []string
+}
+
+// fields lists the JSON fields of the struct t, including those of embedded
+// structs. A field is required when it is validated as required.
+func fields(t reflect.Type) []field {
+	var result []field
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			embedded := f.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				result = append(result, fields(embedded)...)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		rules := strings.Split(f.Tag.Get("validate"), ",")
+		required := false
+		var enum []string
+		for _, rule := range rules {
+			switch {
+			case rule == "required":
+				required = true
+			case strings.HasPrefix(rule, "oneof="):
+				enum = strings.Fields(strings.TrimPrefix(rule, "oneof="))
+			}
+		}
+		result = append(result, field{StructField: f, name: name, required: required, enum: enum})
+	}
+	return result
+}
+
+// componentName names a struct after its module and type, e.g. dataset.Tag
+// for the Tag of internal/dataset/domain
+func componentName(t reflect.Type) string {
+	path := strings.TrimSuffix(strings.TrimSuffix(t.PkgPath(), "/domain"), "/delivery/http")
+	module := path[strings.LastIndex(path, "/")+1:]
+	if module == "" {
+		return t.Name()
+	}
+	return module + "." + t.Name()
+}
+
+// operationID names an operation after its method and path, e.g.
+// getDatasetsById for GET /datasets/{id}
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, "{") {
+			b.WriteString("By")
+			segment = strings.Trim(segment, "{}")
+		}
+		upper := true
+		for _, r := range segment {
+			if r == '-' || r == '_' {
+				upper = true
+				continue
+			}
+			if upper {
+				r = unicode.ToUpper(r)
+				upper = false
+			}
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonContent(schema *Schema) map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: schema}}
+}
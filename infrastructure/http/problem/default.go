@@ -0,0 +1,88 @@
+package problem
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+
+	"portal-data-backend/infrastructure/db"
+	"portal-data-backend/infrastructure/http/response"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"github.com/lib/pq"
+)
+
+// Default maps the errors every module shares
+var Default = NewMapper(append(sharedMappings, databaseMappings...)...)
+
+// sharedMappings map the general errors of pkg/errors. Usecases wrap
+// ErrInvalidInput, ErrValidation, ErrInUse, ErrAlreadyExists and ErrForbidden
+// with messages meant for clients.
+var sharedMappings = []Mapping{
+	{Err: pkgErrors.ErrNotFound, Status: http.StatusNotFound, Code: response.CodeNotFound, Message: "Resource not found"},
+	{Err: pkgErrors.ErrInvalidInput, Status: http.StatusBadRequest, Code: response.CodeBadRequest},
+	{Err: pkgErrors.ErrValidation, Status: http.StatusUnprocessableEntity, Code: response.CodeValidationFailed},
+	{Err: pkgErrors.ErrInUse, Status: http.StatusConflict, Code: response.CodeConflict},
+	{Err: pkgErrors.ErrAlreadyExists, Status: http.StatusConflict, Code: response.CodeConflict},
+	{Err: pkgErrors.ErrUnauthorized, Status: http.StatusUnauthorized, Code: response.CodeUnauthorized, Message: "Authentication required"},
+	{Err: pkgErrors.ErrForbidden, Status: http.StatusForbidden, Code: response.CodeForbidden},
+}
+
+// databaseMappings map the errors of queries that reach the handlers, so a
+// violated constraint is the client's fault rather than a server error
+var databaseMappings = []Mapping{
+	{Err: sql.ErrNoRows, Status: http.StatusNotFound, Code: response.CodeNotFound, Message: "Resource not found"},
+	{Match: pqError("unique_violation"), Status: http.StatusConflict, Code: response.CodeConflict, Message: "Resource already exists"},
+	{Match: foreignKeyViolation(true), Status: http.StatusConflict, Code: response.CodeConflict, Message: "Resource is in use"},
+	{Match: foreignKeyViolation(false), Status: http.StatusUnprocessableEntity, Code: response.CodeInvalidReference, Message: "A referenced resource does not exist"},
+	{Match: pqError("not_null_violation"), Status: http.StatusUnprocessableEntity, Code: response.CodeValidationFailed, Message: "Validation failed", Details: requiredColumn},
+	{Match: pqError("check_violation"), Status: http.StatusUnprocessableEntity, Code: response.CodeValidationFailed, Message: "A value is not allowed"},
+	{Match: pqError("string_data_right_truncation"), Status: http.StatusUnprocessableEntity, Code: response.CodeValidationFailed, Message: "A value is too long"},
+	{Match: pqError("invalid_text_representation"), Status: http.StatusBadRequest, Code: response.CodeBadRequest, Message: "A value or identifier is malformed"},
+	{Match: pqError("serialization_failure", "deadlock_detected"), Status: http.StatusConflict, Code: response.CodeConflict, Message: "The resource was changed concurrently, please retry"},
+	{Match: pqError("query_canceled"), Status: http.StatusGatewayTimeout, Code: response.CodeTimeout, Message: "The request timed out"},
+	{Err: context.DeadlineExceeded, Status: http.StatusGatewayTimeout, Code: response.CodeTimeout, Message: "The request timed out"},
+	// Queries of requests whose client went away fail with their context;
+	// nobody reads the answer, and it is no server error to log
+	{Err: context.Canceled, Status: response.StatusClientClosedRequest, Code: response.CodeCanceled, Message: "The request was canceled"},
+	{Match: db.IsConnectionError, Status: http.StatusServiceUnavailable, Code: response.CodeServiceUnavailable, Message: "The database is unavailable, please retry"},
+}
+
+// pqError matches PostgreSQL errors with one of the condition names
+func pqError(names ...string) func(err error) bool {
+	return func(err error) bool {
+		var pqErr *pq.Error
+		if !errors.As(err, &pqErr) {
+			return false
+		}
+		for _, name := range names {
+			if pqErr.Code.Name() == name {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// foreignKeyViolation matches violated foreign keys, either by deleting a
+// referenced row or by referencing a missing one
+func foreignKeyViolation(deleting bool) func(err error) bool {
+	return func(err error) bool {
+		var pqErr *pq.Error
+		if !errors.As(err, &pqErr) || pqErr.Code.Name() != "foreign_key_violation" {
+			return false
+		}
+		// PostgreSQL reports deletions as "update or delete on table ..."
+		return strings.HasPrefix(pqErr.Message, "update or delete") == deleting
+	}
+}
+
+func requiredColumn(err error) []response.ErrorDetail {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr.Column == "" {
+		return nil
+	}
+	return []response.ErrorDetail{{Field: pqErr.Column, Message: pqErr.Column + " is required"}}
+}
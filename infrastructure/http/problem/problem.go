@@ -0,0 +1,108 @@
+// Package problem maps errors to RFC 7807 problem responses. Default maps
+// the shared errors of pkg/errors and the database; modules layer their own
+// mappings on top of it with With.
+package problem
+
+import (
+	"errors"
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/response"
+	"portal-data-backend/infrastructure/logger"
+
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+// Mapping maps the errors matching Err, or Match when set, to a response
+type Mapping struct {
+	Err   error
+	Match func(err error) bool
+
+	Status int
+	Code   string
+	// Message is the detail of the response; empty uses the error's message,
+	// for errors written to be shown to clients
+	Message string
+	// Details optionally describes the fields the error is about
+	Details func(err error) []response.ErrorDetail
+}
+
+func (m *Mapping) matches(err error) bool {
+	if m.Match != nil {
+		return m.Match(err)
+	}
+	return errors.Is(err, m.Err)
+}
+
+// Mapper maps errors to responses with its mappings, in the order they were
+// registered, then with the mappings of its parent
+type Mapper struct {
+	parent   *Mapper
+	mappings []Mapping
+}
+
+// NewMapper creates a mapper with mappings
+func NewMapper(mappings ...Mapping) *Mapper {
+	return &Mapper{mappings: mappings}
+}
+
+// Register adds mappings. Register before requests are served.
+func (m *Mapper) Register(mappings ...Mapping) {
+	m.mappings = append(m.mappings, mappings...)
+}
+
+// With creates a mapper trying mappings before the ones of m
+func (m *Mapper) With(mappings ...Mapping) *Mapper {
+	return &Mapper{parent: m, mappings: mappings}
+}
+
+// Lookup returns the mapping of err
+func (m *Mapper) Lookup(err error) (Mapping, bool) {
+	for mapper := m; mapper != nil; mapper = mapper.parent {
+		for _, mapping := range mapper.mappings {
+			if mapping.matches(err) {
+				return mapping, true
+			}
+		}
+	}
+	return Mapping{}, false
+}
+
+// Write sends the response err maps to. Errors without a mapping are
+// internal server errors; their message is logged, not sent.
+func (m *Mapper) Write(w http.ResponseWriter, r *http.Request, err error) {
+	mapping, ok := m.Lookup(err)
+	if !ok {
+		mapping = Mapping{
+			Status:  http.StatusInternalServerError,
+			Code:    response.CodeInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+	if mapping.Status >= http.StatusInternalServerError {
+		logger.FromContext(r.Context()).Error("%s %s failed: %v", r.Method, r.URL.Path, err)
+	}
+
+	detail := mapping.Message
+	if detail == "" {
+		detail = err.Error()
+	}
+	var details []response.ErrorDetail
+	if mapping.Details != nil {
+		details = mapping.Details(err)
+	}
+
+	response.Problem(w, response.ErrorResponse{
+		Status:    mapping.Status,
+		Code:      mapping.Code,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		RequestID: chiMiddleware.GetReqID(r.Context()),
+		Errors:    details,
+	})
+}
+
+// Write sends the response err maps to with Default
+func Write(w http.ResponseWriter, r *http.Request, err error) {
+	Default.Write(w, r, err)
+}
@@ -0,0 +1,106 @@
+package problem
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"portal-data-backend/infrastructure/http/response"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/lib/pq"
+)
+
+func write(t *testing.T, mapper *Mapper, err error) (*httptest.ResponseRecorder, response.ErrorResponse) {
+	t.Helper()
+
+	r := httptest.NewRequest(http.MethodDelete, "/api/v1/units/1", nil)
+	r = r.WithContext(context.WithValue(r.Context(), chiMiddleware.RequestIDKey, "req-1"))
+	w := httptest.NewRecorder()
+	mapper.Write(w, r, err)
+
+	var problem response.ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("Expected a problem, got %s", w.Body.String())
+	}
+	return w, problem
+}
+
+// Test a problem carries its status, code, type and correlation ID
+func TestMapper_Write(t *testing.T) {
+	w, problem := write(t, Default, fmt.Errorf("failed to delete unit: %w", pkgErrors.ErrNotFound))
+
+	if w.Code != http.StatusNotFound || problem.Status != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d and %d", w.Code, problem.Status)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != response.ProblemContentType {
+		t.Errorf("Expected Content-Type %s, got %s", response.ProblemContentType, ct)
+	}
+	if problem.Code != response.CodeNotFound || problem.Type != "urn:portal-data:problem:not-found" {
+		t.Errorf("Expected the not found code and type, got %s and %s", problem.Code, problem.Type)
+	}
+	if problem.Title != "Not Found" || problem.Detail != "Resource not found" {
+		t.Errorf("Expected the title and detail of a missing resource, got %q and %q", problem.Title, problem.Detail)
+	}
+	if problem.RequestID != "req-1" || problem.Instance != "/api/v1/units/1" {
+		t.Errorf("Expected the request ID and path, got %q and %q", problem.RequestID, problem.Instance)
+	}
+}
+
+// Test module mappings take precedence over the shared ones
+func TestMapper_With(t *testing.T) {
+	mapper := Default.With(Mapping{Err: pkgErrors.ErrNotFound, Status: http.StatusNotFound, Code: response.CodeNotFound, Message: "Unit not found"})
+
+	if _, problem := write(t, mapper, pkgErrors.ErrNotFound); problem.Detail != "Unit not found" {
+		t.Errorf("Expected the module's message, got %q", problem.Detail)
+	}
+	if _, problem := write(t, mapper, fmt.Errorf("%w: unit is used by 3 datasets", pkgErrors.ErrInUse)); problem.Status != http.StatusConflict {
+		t.Errorf("Expected the shared mapping to answer 409, got %d", problem.Status)
+	}
+}
+
+// Test database errors map to the client errors they stand for
+func TestMapper_DatabaseErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"unique", &pq.Error{Code: "23505"}, http.StatusConflict, response.CodeConflict},
+		{"referenced", &pq.Error{Code: "23503", Message: `update or delete on table "units" violates foreign key constraint`}, http.StatusConflict, response.CodeConflict},
+		{"missing reference", &pq.Error{Code: "23503", Message: `insert or update on table "datasets" violates foreign key constraint`}, http.StatusUnprocessableEntity, response.CodeInvalidReference},
+		{"not null", &pq.Error{Code: "23502", Column: "name"}, http.StatusUnprocessableEntity, response.CodeValidationFailed},
+		{"malformed", &pq.Error{Code: "22P02"}, http.StatusBadRequest, response.CodeBadRequest},
+		{"timeout", context.DeadlineExceeded, http.StatusGatewayTimeout, response.CodeTimeout},
+		{"canceled", context.Canceled, response.StatusClientClosedRequest, response.CodeCanceled},
+	}
+	for _, c := range cases {
+		_, problem := write(t, Default, fmt.Errorf("database error: %w", c.err))
+		if problem.Status != c.status || problem.Code != c.code {
+			t.Errorf("%s: Expected %d %s, got %d %s", c.name, c.status, c.code, problem.Status, problem.Code)
+		}
+	}
+
+	_, problem := write(t, Default, &pq.Error{Code: "23502", Column: "name"})
+	if len(problem.Errors) != 1 || problem.Errors[0].Field != "name" {
+		t.Errorf("Expected the missing column as a field error, got %+v", problem.Errors)
+	}
+}
+
+// Test unmapped errors are internal server errors that hide their message
+func TestMapper_Unmapped(t *testing.T) {
+	w, problem := write(t, Default, errors.New("pq: password authentication failed for user admin"))
+
+	if w.Code != http.StatusInternalServerError || problem.Code != response.CodeInternalServerError {
+		t.Errorf("Expected 500 %s, got %d %s", response.CodeInternalServerError, w.Code, problem.Code)
+	}
+	if problem.Detail != "Internal server error" {
+		t.Errorf("Expected the error's message to be hidden, got %q", problem.Detail)
+	}
+}
@@ -3,6 +3,9 @@ package response
 import (
 	"encoding/json"
 	"net/http"
+	"strings"
+
+	"portal-data-backend/infrastructure/logger"
 )
 
 // Response is the standard API response structure
@@ -27,13 +30,34 @@ type ErrorDetail struct {
 	Message string `json:"message"`
 }
 
-// ErrorResponse is the standard error response structure
+// ErrorResponse is the standard error response, an RFC 7807 problem details
+// object. Code is a stable, machine-readable error code; Type is derived
+// from it. RequestID correlates the response with the server's logs.
 type ErrorResponse struct {
-	Code    string        `json:"code"`
-	Message string        `json:"message"`
-	Details []ErrorDetail `json:"details,omitempty"`
+	Type      string        `json:"type"`
+	Title     string        `json:"title"`
+	Status    int           `json:"status"`
+	Detail    string        `json:"detail"`
+	Instance  string        `json:"instance,omitempty"`
+	Code      string        `json:"code"`
+	RequestID string        `json:"request_id,omitempty"`
+	Errors    []ErrorDetail `json:"errors,omitempty"`
 }
 
+// ProblemContentType is the media type of error responses
+const ProblemContentType = "application/problem+json"
+
+// RequestIDHeader carries the request ID of a response. Error responses
+// repeat it in their body.
+const RequestIDHeader = "X-Request-ID"
+
+// StatusClientClosedRequest is the status of requests whose client went away
+// before they were answered, after nginx's
+const StatusClientClosedRequest = 499
+
+// ProblemTypeBase prefixes the kebab-cased code of a problem to form its type
+const ProblemTypeBase = "urn:portal-data:problem:"
+
 // Response codes
 const (
 	CodeSuccess              = "OPERATION_SUCCESSFUL"
@@ -49,10 +73,22 @@ const (
 	CodeInternalServerError   = "INTERNAL_SERVER_ERROR"
 	CodeServiceUnavailable   = "SERVICE_UNAVAILABLE"
 	CodeTooManyRequests      = "TOO_MANY_REQUESTS"
+	CodeRequestTooLarge      = "REQUEST_TOO_LARGE"
+	CodeUnsupportedMediaType = "UNSUPPORTED_MEDIA_TYPE"
+	CodeMethodNotAllowed     = "METHOD_NOT_ALLOWED"
+	CodeInvalidReference     = "INVALID_REFERENCE"
+	CodeTimeout              = "TIMEOUT"
+	CodeCanceled             = "REQUEST_CANCELED"
+	CodeIPNotAllowed         = "IP_NOT_ALLOWED"
+	CodeCaptchaFailed        = "CAPTCHA_FAILED"
 )
 
 // JSON sends a JSON response
 func JSON(w http.ResponseWriter, statusCode int, code, message string, data interface{}) {
+	data, ok := shaped(w, data)
+	if !ok {
+		return
+	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
 
@@ -67,6 +103,10 @@ func JSON(w http.ResponseWriter, statusCode int, code, message string, data inte
 
 // JSONWithMeta sends a JSON response with pagination metadata
 func JSONWithMeta(w http.ResponseWriter, statusCode int, code, message string, data interface{}, meta Meta) {
+	data, ok := shaped(w, data)
+	if !ok {
+		return
+	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
 
@@ -80,6 +120,22 @@ func JSONWithMeta(w http.ResponseWriter, statusCode int, code, message string, d
 	_ = json.NewEncoder(w).Encode(resp)
 }
 
+// shaped applies the shape of w to data. When it returns false, shaped has
+// written the error response.
+func shaped(w http.ResponseWriter, data interface{}) (interface{}, bool) {
+	writer := shapeOf(w)
+	if writer == nil {
+		return data, true
+	}
+	data, err := writer.shape.Apply(writer.ctx, data)
+	if err != nil {
+		logger.FromContext(writer.ctx).Error("failed to shape response: %v", err)
+		InternalError(w, CodeInternalServerError, "Internal server error", nil)
+		return nil, false
+	}
+	return data, true
+}
+
 // Created sends a 201 Created response
 func Created(w http.ResponseWriter, code, message string, data interface{}) {
 	JSON(w, http.StatusCreated, code, message, data)
@@ -132,14 +188,31 @@ func InternalError(w http.ResponseWriter, code, message string, details []ErrorD
 
 // Error sends an error response
 func Error(w http.ResponseWriter, statusCode int, code, message string, details []ErrorDetail) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-
-	resp := ErrorResponse{
-		Code:    code,
-		Message: message,
-		Details: details,
+	Problem(w, ErrorResponse{
+		Status: statusCode,
+		Code:   code,
+		Detail: message,
+		Errors: details,
+	})
+}
+
+// Problem sends problem as an error response, deriving its type and title
+// when missing and taking its request ID from the response headers
+func Problem(w http.ResponseWriter, problem ErrorResponse) {
+	if problem.Type == "" {
+		problem.Type = ProblemTypeBase + strings.ToLower(strings.ReplaceAll(problem.Code, "_", "-"))
+	}
+	if problem.Title == "" && problem.Status == StatusClientClosedRequest {
+		problem.Title = "Client Closed Request"
+	} else if problem.Title == "" {
+		problem.Title = http.StatusText(problem.Status)
+	}
+	if problem.RequestID == "" {
+		problem.RequestID = w.Header().Get(RequestIDHeader)
 	}
 
-	_ = json.NewEncoder(w).Encode(resp)
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(problem.Status)
+
+	_ = json.NewEncoder(w).Encode(problem)
 }
@@ -0,0 +1,319 @@
+package response
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Relation is a resource related to the resources of a response, which
+// clients expand into them with the expand query parameter
+type Relation struct {
+	// Key is the field of a resource holding the key the relation is loaded
+	// by, like organization_id
+	Key string
+	// Many marks relations holding a list, which expand to an empty list
+	// rather than null when nothing is found
+	Many bool
+	// Load loads the related values of keys in one call, by key
+	Load func(ctx context.Context, keys []string) (map[string]interface{}, error)
+}
+
+// Relations are the relations of the resources of a route by name
+type Relations map[string]Relation
+
+// LoadBy adapts a batch lookup returning records to Relation.Load, keying
+// the records with key
+func LoadBy[T any](lookup func(ctx context.Context, keys []string) ([]T, error), key func(T) string) func(ctx context.Context, keys []string) (map[string]interface{}, error) {
+	return func(ctx context.Context, keys []string) (map[string]interface{}, error) {
+		records, err := lookup(ctx, keys)
+		if err != nil {
+			return nil, err
+		}
+		values := make(map[string]interface{}, len(records))
+		for _, record := range records {
+			values[key(record)] = record
+		}
+		return values, nil
+	}
+}
+
+// LoadGrouped adapts a batch lookup returning values by key to Relation.Load
+func LoadGrouped[T any](lookup func(ctx context.Context, keys []string) (map[string]T, error)) func(ctx context.Context, keys []string) (map[string]interface{}, error) {
+	return func(ctx context.Context, keys []string) (map[string]interface{}, error) {
+		grouped, err := lookup(ctx, keys)
+		if err != nil {
+			return nil, err
+		}
+		values := make(map[string]interface{}, len(grouped))
+		for key, value := range grouped {
+			values[key] = value
+		}
+		return values, nil
+	}
+}
+
+// fieldSet selects fields by name. A nil set selects every field, and so
+// does a name mapped to nil.
+type fieldSet map[string]fieldSet
+
+// Shape selects the fields of the resources of a response and the
+// relations expanded into them
+type Shape struct {
+	fields fieldSet
+	expand []string
+	// relations holds the relations named by expand
+	relations Relations
+}
+
+var fieldPath = regexp.MustCompile(`^[a-z0-9_]+(\.[a-z0-9_]+)*$`)
+
+// ParseShape parses the fields and expand query parameters. fields is a
+// comma separated list of fields, where organization.name selects a field
+// of a nested object; expand is a comma separated list of relations.
+// Unknown fields are left out of responses, unknown relations are rejected.
+func ParseShape(fields, expand string, relations Relations) (*Shape, error) {
+	shape := &Shape{relations: Relations{}}
+
+	for _, name := range splitList(expand) {
+		relation, ok := relations[name]
+		if !ok {
+			if len(relations) == 0 {
+				return nil, fmt.Errorf("expand is not supported here")
+			}
+			names := make([]string, 0, len(relations))
+			for name := range relations {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("cannot expand %q, expected one of %s", name, strings.Join(names, ", "))
+		}
+		if _, ok := shape.relations[name]; !ok {
+			shape.expand = append(shape.expand, name)
+			shape.relations[name] = relation
+		}
+	}
+
+	paths := splitList(fields)
+	if len(paths) == 0 {
+		return shape, nil
+	}
+	shape.fields = fieldSet{}
+	for _, path := range paths {
+		if !fieldPath.MatchString(path) {
+			return nil, fmt.Errorf("invalid field %q", path)
+		}
+		shape.fields.add(strings.Split(path, "."))
+	}
+	// Expanded relations are part of the response even when fields leaves
+	// them out
+	for _, name := range shape.expand {
+		if _, ok := shape.fields[name]; !ok {
+			shape.fields[name] = nil
+		}
+	}
+	return shape, nil
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func (s fieldSet) add(path []string) {
+	name := path[0]
+	sub, seen := s[name]
+	if seen && sub == nil {
+		// The whole field is selected already
+		return
+	}
+	if len(path) == 1 {
+		s[name] = nil
+		return
+	}
+	if sub == nil {
+		sub = fieldSet{}
+		s[name] = sub
+	}
+	sub.add(path[1:])
+}
+
+// IsZero reports whether s leaves responses as they are
+func (s *Shape) IsZero() bool {
+	return s == nil || (s.fields == nil && len(s.expand) == 0)
+}
+
+// Apply shapes data, the data of a response. It applies to data itself
+// when it is a resource or a list of resources. For lists with pagination
+// metadata, objects holding a meta field, it applies to the resources of
+// their list fields and leaves the other fields alone.
+func (s *Shape) Apply(ctx context.Context, data interface{}) (interface{}, error) {
+	if s.IsZero() || data == nil {
+		return data, nil
+	}
+
+	value, err := normalize(data)
+	if err != nil {
+		return nil, err
+	}
+	resources := resourcesOf(value)
+	for _, name := range s.expand {
+		if err := s.expandInto(ctx, name, resources); err != nil {
+			return nil, err
+		}
+	}
+	if s.fields != nil {
+		for _, resource := range resources {
+			s.fields.selectFrom(resource)
+		}
+	}
+	return value, nil
+}
+
+func (s *Shape) expandInto(ctx context.Context, name string, resources []map[string]interface{}) error {
+	relation := s.relations[name]
+
+	var keys []string
+	seen := make(map[string]bool)
+	for _, resource := range resources {
+		key, ok := resource[relation.Key].(string)
+		if ok && key != "" && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	var values map[string]interface{}
+	if len(keys) > 0 {
+		loaded, err := relation.Load(ctx, keys)
+		if err != nil {
+			return fmt.Errorf("failed to expand %s: %w", name, err)
+		}
+		if values, err = normalizeValues(loaded); err != nil {
+			return err
+		}
+	}
+	for _, resource := range resources {
+		key, _ := resource[relation.Key].(string)
+		value, ok := values[key]
+		if !ok && relation.Many {
+			value = []interface{}{}
+		}
+		resource[name] = value
+	}
+	return nil
+}
+
+func (s fieldSet) selectFrom(resource map[string]interface{}) {
+	for name, value := range resource {
+		sub, ok := s[name]
+		switch {
+		case !ok:
+			delete(resource, name)
+		case sub != nil:
+			switch value := value.(type) {
+			case map[string]interface{}:
+				sub.selectFrom(value)
+			case []interface{}:
+				for _, item := range value {
+					if item, ok := item.(map[string]interface{}); ok {
+						sub.selectFrom(item)
+					}
+				}
+			}
+		}
+	}
+}
+
+// resourcesOf returns the resources of the data of a response
+func resourcesOf(value interface{}) []map[string]interface{} {
+	var resources []map[string]interface{}
+	switch value := value.(type) {
+	case []interface{}:
+		for _, item := range value {
+			if item, ok := item.(map[string]interface{}); ok {
+				resources = append(resources, item)
+			}
+		}
+	case map[string]interface{}:
+		if _, ok := value["meta"]; !ok {
+			return []map[string]interface{}{value}
+		}
+		for name, field := range value {
+			if list, ok := field.([]interface{}); ok && name != "meta" {
+				resources = append(resources, resourcesOf(list)...)
+			}
+		}
+	}
+	return resources
+}
+
+// normalize turns v into the maps, slices and values JSON decodes to, so
+// shapes apply alike to every type
+func normalize(v interface{}) (interface{}, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode response: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return value, nil
+}
+
+func normalizeValues(values map[string]interface{}) (map[string]interface{}, error) {
+	value, err := normalize(values)
+	if err != nil {
+		return nil, err
+	}
+	normalized, _ := value.(map[string]interface{})
+	return normalized, nil
+}
+
+// shapedWriter is a response writer whose JSON responses are shaped
+type shapedWriter struct {
+	http.ResponseWriter
+	ctx   context.Context
+	shape *Shape
+}
+
+// Unwrap returns the writer w wraps, for http.ResponseController
+func (w *shapedWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// WithShape returns a writer whose JSON responses are shaped by shape.
+// Relations are loaded with ctx, the context of the request.
+func WithShape(w http.ResponseWriter, ctx context.Context, shape *Shape) http.ResponseWriter {
+	if shape.IsZero() {
+		return w
+	}
+	return &shapedWriter{ResponseWriter: w, ctx: ctx, shape: shape}
+}
+
+// shapeOf returns the writer shaping the responses of w, or nil
+func shapeOf(w http.ResponseWriter) *shapedWriter {
+	for {
+		switch writer := w.(type) {
+		case *shapedWriter:
+			return writer
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = writer.Unwrap()
+		default:
+			return nil
+		}
+	}
+}
@@ -1,75 +1,151 @@
 package logger
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
+	"sort"
+	"sync/atomic"
 )
 
-// Logger is a simple structured logger
+// Logger writes structured log records through log/slog. Messages are
+// formatted printf-style; fields added with With are attached to every record
+// as attributes.
 type Logger struct {
-	debug   bool
-	env     string
-	infoLog *log.Logger
-	errorLog *log.Logger
-	debugLog *log.Logger
-}
-
-// New creates a new logger
-func New(debug bool, env string) *Logger {
-	return &Logger{
-		debug:   debug,
-		env:     env,
-		infoLog: log.New(os.Stdout, "[INFO] ", log.LstdFlags|log.Lmsgprefix),
-		errorLog: log.New(os.Stderr, "[ERROR] ", log.LstdFlags|log.Lmsgprefix),
-		debugLog: log.New(os.Stdout, "[DEBUG] ", log.LstdFlags|log.Lmsgprefix),
+	slog  *slog.Logger
+	level *slog.LevelVar
+}
+
+// New creates a logger writing JSON records in production and text records
+// elsewhere. Records below level ("debug", "info", "warn" or "error") are
+// dropped; SetLevel changes it while running.
+func New(level string, env string) (*Logger, error) {
+	var levelVar slog.LevelVar
+	if err := levelVar.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q", level)
+	}
+	options := &slog.HandlerOptions{Level: &levelVar}
+
+	var handler slog.Handler = slog.NewTextHandler(os.Stdout, options)
+	if env == "production" {
+		handler = slog.NewJSONHandler(os.Stdout, options)
 	}
+	return &Logger{slog: slog.New(handler), level: &levelVar}, nil
+}
+
+// NewWithHandler creates a logger writing records to handler
+func NewWithHandler(handler slog.Handler) *Logger {
+	return &Logger{slog: slog.New(handler)}
+}
+
+// SetLevel changes the level of l and of the loggers derived from it. Loggers
+// created with NewWithHandler keep the level of their handler.
+func (l *Logger) SetLevel(level string) error {
+	if l.level == nil {
+		return fmt.Errorf("logger level is set by its handler")
+	}
+	if err := l.level.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("invalid log level %q", level)
+	}
+	return nil
 }
 
 // Info logs an info message
 func (l *Logger) Info(format string, args ...interface{}) {
-	l.infoLog.Printf(format, args...)
+	l.log(slog.LevelInfo, format, args)
+}
+
+// Warn logs a warning message
+func (l *Logger) Warn(format string, args ...interface{}) {
+	l.log(slog.LevelWarn, format, args)
 }
 
 // Error logs an error message
 func (l *Logger) Error(format string, args ...interface{}) {
-	l.errorLog.Printf(format, args...)
+	l.log(slog.LevelError, format, args)
 }
 
 // Debug logs a debug message
 func (l *Logger) Debug(format string, args ...interface{}) {
-	if l.debug {
-		l.debugLog.Printf(format, args...)
-	}
+	l.log(slog.LevelDebug, format, args)
 }
 
-// Fatal logs a fatal message and exits
+// Fatal logs an error message and exits
 func (l *Logger) Fatal(format string, args ...interface{}) {
-	l.errorLog.Fatalf(format, args...)
+	l.log(slog.LevelError, format, args)
+	os.Exit(1)
+}
+
+// With returns a logger that adds fields, given as key-value pairs, to
+// every record
+func (l *Logger) With(fields ...interface{}) *Logger {
+	return &Logger{slog: l.slog.With(fields...), level: l.level}
 }
 
-// WithFields logs with fields (simplified)
-func (l *Logger) WithFields(fields map[string]interface{}) *FieldLogger {
-	return &FieldLogger{
-		logger: l,
-		fields: fields,
+// WithFields returns a logger that adds fields to every record
+func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
 	}
+	sort.Strings(keys)
+
+	args := make([]interface{}, 0, len(fields)*2)
+	for _, key := range keys {
+		args = append(args, key, fields[key])
+	}
+	return l.With(args...)
 }
 
-// FieldLogger is a logger with predefined fields
-type FieldLogger struct {
-	logger *Logger
-	fields map[string]interface{}
+// Slog returns the underlying slog logger
+func (l *Logger) Slog() *slog.Logger {
+	return l.slog
 }
 
-// Info logs an info message with fields
-func (fl *FieldLogger) Info(format string, args ...interface{}) {
-	msg := fmt.Sprintf(format, args...)
-	fl.logger.Info("%s %v", msg, fl.fields)
+func (l *Logger) log(level slog.Level, format string, args []interface{}) {
+	ctx := context.Background()
+	if !l.slog.Enabled(ctx, level) {
+		return
+	}
+
+	message := format
+	if len(args) > 0 {
+		message = fmt.Sprintf(format, args...)
+	}
+	l.slog.Log(ctx, level, message)
 }
 
-// Error logs an error message with fields
-func (fl *FieldLogger) Error(format string, args ...interface{}) {
-	msg := fmt.Sprintf(format, args...)
-	fl.logger.Error("%s %v", msg, fl.fields)
+type contextKey struct{}
+
+var defaultLogger atomic.Pointer[Logger]
+
+// SetDefault sets the logger FromContext returns for contexts without one.
+// The standard library log package writes through it as well.
+func SetDefault(l *Logger) {
+	defaultLogger.Store(l)
+	slog.SetDefault(l.slog)
+}
+
+// Default returns the default logger
+func Default() *Logger {
+	if l := defaultLogger.Load(); l != nil {
+		return l
+	}
+	return &Logger{slog: slog.Default()}
+}
+
+// WithContext returns a copy of ctx carrying l
+func WithContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger ctx carries, or the default logger. Requests
+// carry a logger with their request ID and, once authenticated, their user
+// and organization.
+func FromContext(ctx context.Context) *Logger {
+	if l, ok := ctx.Value(contextKey{}).(*Logger); ok {
+		return l
+	}
+	return Default()
 }
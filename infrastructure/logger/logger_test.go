@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+// Test records carry the formatted message and the fields of the logger
+func TestLogger_WritesFields(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithHandler(slog.NewJSONHandler(&buf, nil))
+
+	l.With("request_id", "req-1").With("user_id", "user-1").Error("failed to publish %s event", "dataset.created")
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Expected a JSON record, got %q", buf.String())
+	}
+	if record["msg"] != "failed to publish dataset.created event" {
+		t.Errorf("Expected the formatted message, got %v", record["msg"])
+	}
+	if record["level"] != "ERROR" {
+		t.Errorf("Expected level ERROR, got %v", record["level"])
+	}
+	if record["request_id"] != "req-1" || record["user_id"] != "user-1" {
+		t.Errorf("Expected the request_id and user_id fields, got %v", record)
+	}
+}
+
+// Test debug records are dropped unless the level allows them
+func TestLogger_Debug(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithHandler(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+
+	l.Debug("not written")
+	if buf.Len() != 0 {
+		t.Errorf("Expected no output, got %q", buf.String())
+	}
+}
+
+// Test changing the level applies to the loggers derived before the change
+func TestLogger_SetLevel(t *testing.T) {
+	l, err := New("info", "test")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	derived := l.With("request_id", "req-1")
+
+	if err := l.SetLevel("debug"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !derived.Slog().Enabled(context.Background(), slog.LevelDebug) {
+		t.Errorf("Expected debug records to be written after the change")
+	}
+	if err := l.SetLevel("verbose"); err == nil {
+		t.Errorf("Expected an error for an unknown level")
+	}
+}
+
+// Test the logger in a context is returned, and the default one otherwise
+func TestFromContext(t *testing.T) {
+	l := NewWithHandler(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	ctx := WithContext(context.Background(), l)
+
+	if FromContext(ctx) != l {
+		t.Errorf("Expected the logger of the context")
+	}
+	if FromContext(context.Background()) == nil {
+		t.Errorf("Expected the default logger for a context without one")
+	}
+}
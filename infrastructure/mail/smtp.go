@@ -0,0 +1,59 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/infrastructure/logger"
+)
+
+// Sender sends plain text mail through an SMTP server
+type Sender struct {
+	cfg config.MailConfig
+}
+
+// NewSender creates a sender. Without a host in cfg, mail is only logged.
+func NewSender(cfg config.MailConfig) *Sender {
+	return &Sender{cfg: cfg}
+}
+
+// Send sends a plain text mail to a single recipient
+func (s *Sender) Send(ctx context.Context, to, subject, body string) error {
+	if strings.ContainsAny(to, "\r\n") || strings.ContainsAny(subject, "\r\n") {
+		return fmt.Errorf("mail header must not contain line breaks")
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if s.cfg.Host == "" {
+		logger.FromContext(ctx).Info("mail is not configured, not sending %q to %s:\n%s", subject, to, body)
+		return nil
+	}
+
+	message := strings.Join([]string{
+		"From: " + s.cfg.From,
+		"To: " + to,
+		"Subject: " + subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		body,
+	}, "\r\n")
+
+	var auth smtp.Auth
+	if s.cfg.Username != "" {
+		auth = smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)
+	}
+
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	if err := smtp.SendMail(addr, auth, s.cfg.From, []string{to}, []byte(message)); err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+	return nil
+}
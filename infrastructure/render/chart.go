@@ -0,0 +1,95 @@
+package render
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// Chart returns up to maxSeries numeric columns of rows, JSON objects like
+// the data of dataset rows, in the order of the columns of the first row,
+// labelled by their first text column. It returns no series when rows have
+// no numeric column.
+func Chart(rows []string, maxSeries int) ([]string, []Series) {
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	values := make([]map[string]interface{}, 0, len(rows))
+	for _, row := range rows {
+		var data map[string]interface{}
+		if err := json.Unmarshal([]byte(row), &data); err == nil {
+			values = append(values, data)
+		}
+	}
+
+	var series []Series
+	label := ""
+	for _, column := range columns(rows[0]) {
+		s, ok := numericColumn(values, column)
+		switch {
+		case ok && len(series) < maxSeries:
+			series = append(series, s)
+		case !ok && label == "":
+			label = column
+		}
+	}
+	if len(series) == 0 {
+		return nil, nil
+	}
+
+	labels := make([]string, len(values))
+	for i, data := range values {
+		labels[i] = strconv.Itoa(i + 1)
+		if text, ok := data[label].(string); ok && label != "" {
+			labels[i] = text
+		}
+	}
+	return labels, series
+}
+
+// numericColumn returns the values of column when every value of it is a
+// number or null, and at least one is a number
+func numericColumn(rows []map[string]interface{}, column string) (Series, bool) {
+	s := Series{Name: column, Values: make([]float64, len(rows))}
+	numbers := 0
+	for i, data := range rows {
+		switch v := data[column].(type) {
+		case nil:
+		case float64:
+			s.Values[i] = v
+			numbers++
+		case string:
+			f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return s, false
+			}
+			s.Values[i] = f
+			numbers++
+		default:
+			return s, false
+		}
+	}
+	return s, numbers > 0
+}
+
+// columns returns the keys of a JSON object in the order they are written
+func columns(data string) []string {
+	decoder := json.NewDecoder(strings.NewReader(data))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil
+	}
+	var keys []string
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return keys
+		}
+		key, _ := token.(string)
+		keys = append(keys, key)
+		var skipped json.RawMessage
+		if err := decoder.Decode(&skipped); err != nil {
+			return keys
+		}
+	}
+	return keys
+}
@@ -0,0 +1,107 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// pngRenderer draws cards as PNG without any service, in the layout of the
+// SVG cards. The standard library draws no text, so the images carry no
+// titles, legends or brand: documents embedding them write those
+// themselves.
+type pngRenderer struct{}
+
+func (pngRenderer) Render(ctx context.Context, card *Card) (*Image, error) {
+	w, h := card.Width, card.Height
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	white := color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+
+	if len(card.Series) == 0 {
+		fill(img, img.Bounds(), parseColor(card.Color))
+	} else {
+		fill(img, img.Bounds(), white)
+		drawChartPNG(img, card, float64(w), float64(h))
+	}
+
+	var b bytes.Buffer
+	if err := png.Encode(&b, img); err != nil {
+		return nil, err
+	}
+	return &Image{Data: b.Bytes(), ContentType: "image/png"}, nil
+}
+
+// drawChartPNG draws the band of the brand colour over a chart of the
+// series like drawChart: bars for one series, lines for several
+func drawChartPNG(img *image.RGBA, card *Card, w, h float64) {
+	margin := w / 20
+	band := h / 5
+	size := band / 3
+	fill(img, rect(0, 0, w, band), parseColor(card.Color))
+
+	left, right := margin, w-margin
+	top, bottom := band+margin/2, h-margin*1.5
+	low, high := bounds(card.Series)
+	y := func(v float64) float64 { return bottom - (v-low)/(high-low)*(bottom-top) }
+	zero := y(math.Max(low, 0))
+	fill(img, rect(left, zero-1, right, zero+1), color.RGBA{R: 0xcb, G: 0xd5, B: 0xe1, A: 0xff})
+
+	count := 0
+	for _, s := range card.Series {
+		count = max(count, len(s.Values))
+	}
+	step := (right - left) / float64(max(count, 1))
+	for i, s := range card.Series {
+		c := parseColor(seriesColor(card, i))
+		if len(card.Series) == 1 {
+			for j, v := range s.Values {
+				x := left + float64(j)*step + step*0.15
+				fill(img, rect(x, math.Min(y(v), zero), x+step*0.7, math.Max(y(v), zero)), c)
+			}
+		} else {
+			for j := 1; j < len(s.Values); j++ {
+				x0, x1 := left+float64(j-1)*step+step/2, left+float64(j)*step+step/2
+				stroke(img, x0, y(s.Values[j-1]), x1, y(s.Values[j]), 4, c)
+			}
+		}
+
+		// The swatches of the legend, without the names of the series
+		x := left + float64(i)*(w-2*margin)/float64(len(card.Series)+1)
+		fill(img, rect(x, h-margin*0.9, x+size/2, h-margin*0.9+size/2), c)
+	}
+}
+
+func rect(x0, y0, x1, y1 float64) image.Rectangle {
+	return image.Rect(int(math.Round(x0)), int(math.Round(y0)), int(math.Round(x1)), int(math.Round(y1)))
+}
+
+func fill(img *image.RGBA, r image.Rectangle, c color.Color) {
+	draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+// stroke draws a line of width from (x0, y0) to (x1, y1) as squares along it
+func stroke(img *image.RGBA, x0, y0, x1, y1, width float64, c color.Color) {
+	steps := int(math.Ceil(math.Hypot(x1-x0, y1-y0)))
+	for i := 0; i <= steps; i++ {
+		t := float64(i) / float64(max(steps, 1))
+		x, y := x0+(x1-x0)*t, y0+(y1-y0)*t
+		fill(img, rect(x-width/2, y-width/2, x+width/2, y+width/2), c)
+	}
+}
+
+// parseColor parses colours like #1d4ed8, grey when they are not
+func parseColor(value string) color.RGBA {
+	hex := strings.TrimPrefix(value, "#")
+	if len(hex) == 6 {
+		if rgb, err := strconv.ParseUint(hex, 16, 32); err == nil {
+			return color.RGBA{R: uint8(rgb >> 16), G: uint8(rgb >> 8), B: uint8(rgb), A: 0xff}
+		}
+	}
+	return color.RGBA{R: 0x64, G: 0x74, B: 0x8b, A: 0xff}
+}
@@ -0,0 +1,79 @@
+// Package render draws the preview images of datasets.
+package render
+
+import (
+	"context"
+
+	"portal-data-backend/infrastructure/config"
+)
+
+// Formats of the images cards are rendered to
+const (
+	FormatSVG = "svg"
+	FormatPNG = "png"
+)
+
+// Card is what a preview image shows. A card with series is drawn as a chart
+// of them; without series it is a card with the title in the brand. Format
+// is the image asked for, SVG when empty.
+type Card struct {
+	Title    string   `json:"title"`
+	Subtitle string   `json:"subtitle,omitempty"`
+	Brand    string   `json:"brand"`
+	Color    string   `json:"color"`
+	Width    int      `json:"width"`
+	Height   int      `json:"height"`
+	Labels   []string `json:"labels,omitempty"` // one label per value of the series
+	Series   []Series `json:"series,omitempty"`
+	Format   string   `json:"format,omitempty"`
+}
+
+// Series is a named column of values
+type Series struct {
+	Name   string    `json:"name"`
+	Values []float64 `json:"values"`
+}
+
+// Image is a rendered card
+type Image struct {
+	Data        []byte
+	ContentType string
+}
+
+// Extension returns the file extension of the image, with its dot
+func (i *Image) Extension() string {
+	switch i.ContentType {
+	case "image/png":
+		return ".png"
+	case "image/jpeg":
+		return ".jpg"
+	case "image/webp":
+		return ".webp"
+	default:
+		return ".svg"
+	}
+}
+
+// Renderer renders cards to images
+type Renderer interface {
+	Render(ctx context.Context, card *Card) (*Image, error)
+}
+
+// NewRenderer creates the renderer of the configured rendering service, or
+// the built-in renderer drawing SVG and PNG when there is none
+func NewRenderer(cfg *config.PreviewConfig) Renderer {
+	if cfg.RenderURL == "" {
+		return builtinRenderer{}
+	}
+	return newServiceRenderer(cfg)
+}
+
+// builtinRenderer draws cards in their format without any service
+type builtinRenderer struct{}
+
+func (builtinRenderer) Render(ctx context.Context, card *Card) (*Image, error) {
+	if card.Format == FormatPNG {
+		return pngRenderer{}.Render(ctx, card)
+	}
+	return svgRenderer{}.Render(ctx, card)
+}
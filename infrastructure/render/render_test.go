@@ -0,0 +1,101 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+)
+
+// Test the built-in renderer draws charts of series and title cards as SVG
+func TestSVGRenderer(t *testing.T) {
+	renderer := NewRenderer(&config.PreviewConfig{})
+
+	chart, err := renderer.Render(context.Background(), &Card{
+		Title:  "Rainfall <2025>",
+		Brand:  "Portal",
+		Color:  "#1d4ed8",
+		Width:  1200,
+		Height: 630,
+		Labels: []string{"Jan", "Feb"},
+		Series: []Series{{Name: "mm", Values: []float64{12, -3}}, {Name: "days", Values: []float64{4, 6}}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	svg := string(chart.Data)
+	if chart.ContentType != "image/svg+xml" || chart.Extension() != ".svg" {
+		t.Errorf("Expected an SVG image, got %s", chart.ContentType)
+	}
+	if strings.Count(svg, "<polyline") != 2 || !strings.Contains(svg, "Rainfall &lt;2025&gt;") {
+		t.Errorf("Expected a line per series under the escaped title, got %s", svg)
+	}
+
+	card, _ := renderer.Render(context.Background(), &Card{Title: strings.Repeat("population ", 40), Brand: "Portal", Color: "#1d4ed8", Width: 1200, Height: 630})
+	if strings.Contains(string(card.Data), "<polyline") || strings.Count(string(card.Data), "population") > 60 {
+		t.Errorf("Expected a title card with the title cut, got %s", card.Data)
+	}
+}
+
+// Test the built-in renderer draws PNG when asked, with the bars of a series
+// in the brand colour
+func TestPNGRenderer(t *testing.T) {
+	chart, err := NewRenderer(&config.PreviewConfig{}).Render(context.Background(), &Card{
+		Title:  "Rainfall",
+		Color:  "#1d4ed8",
+		Width:  400,
+		Height: 200,
+		Series: []Series{{Name: "mm", Values: []float64{12, 3}}},
+		Format: FormatPNG,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(chart.Data))
+	if err != nil || chart.Extension() != ".png" {
+		t.Fatalf("Expected a PNG image, got %s (%v)", chart.ContentType, err)
+	}
+	if img.Bounds().Dx() != 400 || img.Bounds().Dy() != 200 {
+		t.Errorf("Expected a 400x200 image, got %v", img.Bounds())
+	}
+	// The first bar rises from the axis near the bottom to the top of the plot
+	if r, g, b, _ := img.At(60, 140).RGBA(); r>>8 != 0x1d || g>>8 != 0x4e || b>>8 != 0xd8 {
+		t.Errorf("Expected the first bar in the brand colour, got %x %x %x", r>>8, g>>8, b>>8)
+	}
+}
+
+// Test the rendering service is sent the card and its image is returned
+func TestServiceRenderer(t *testing.T) {
+	var received Card
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		if received.Title == "broken" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"error":"no"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("png"))
+	}))
+	defer server.Close()
+
+	renderer := NewRenderer(&config.PreviewConfig{RenderURL: server.URL, Timeout: time.Second})
+	image, err := renderer.Render(context.Background(), &Card{Title: "Rainfall", Width: 600})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if string(image.Data) != "png" || image.Extension() != ".png" || received.Width != 600 {
+		t.Errorf("Expected the PNG of the card, got %s %q for %+v", image.ContentType, image.Data, received)
+	}
+
+	if _, err := renderer.Render(context.Background(), &Card{Title: "broken"}); err == nil {
+		t.Error("Expected an error for a response that is not an image")
+	}
+}
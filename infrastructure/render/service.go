@@ -0,0 +1,65 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"portal-data-backend/infrastructure/config"
+)
+
+// maxImageBytes bounds the images read from the rendering service
+const maxImageBytes = 10 << 20
+
+// serviceRenderer renders cards with the rendering service, posting the card
+// as JSON and reading the image from the response
+type serviceRenderer struct {
+	url    string
+	client *http.Client
+}
+
+func newServiceRenderer(cfg *config.PreviewConfig) *serviceRenderer {
+	return &serviceRenderer{
+		url:    cfg.RenderURL,
+		client: &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+func (s *serviceRenderer) Render(ctx context.Context, card *Card) (*Image, error) {
+	body, err := json.Marshal(card)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call rendering service: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("rendering service answered with status %d", resp.StatusCode)
+	}
+
+	contentType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if !strings.HasPrefix(contentType, "image/") {
+		return nil, fmt.Errorf("rendering service answered with %q instead of an image", contentType)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rendered image: %w", err)
+	}
+	if len(data) > maxImageBytes {
+		return nil, fmt.Errorf("rendered image is larger than %d bytes", maxImageBytes)
+	}
+	return &Image{Data: data, ContentType: contentType}, nil
+}
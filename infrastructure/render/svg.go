@@ -0,0 +1,158 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"math"
+	"strings"
+)
+
+// seriesColors colour the series after the first, which takes the brand
+// colour
+var seriesColors = []string{"#f59e0b", "#10b981", "#ef4444", "#8b5cf6", "#0ea5e9"}
+
+// svgRenderer draws cards as SVG without any service
+type svgRenderer struct{}
+
+func (svgRenderer) Render(ctx context.Context, card *Card) (*Image, error) {
+	var b bytes.Buffer
+	w, h := float64(card.Width), float64(card.Height)
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="Helvetica, Arial, sans-serif">`, card.Width, card.Height, card.Width, card.Height)
+
+	if len(card.Series) == 0 {
+		drawTitleCard(&b, card, w, h)
+	} else {
+		drawChart(&b, card, w, h)
+	}
+
+	b.WriteString(`</svg>`)
+	return &Image{Data: b.Bytes(), ContentType: "image/svg+xml"}, nil
+}
+
+// drawTitleCard draws the title over the brand colour, with the brand below
+func drawTitleCard(b *bytes.Buffer, card *Card, w, h float64) {
+	margin := w / 15
+	size := h / 9
+	fmt.Fprintf(b, `<rect width="100%%" height="100%%" fill="%s"/>`, escape(card.Color))
+
+	lines := wrap(card.Title, int((w-2*margin)/(size*0.55)), 3)
+	y := h/2 - float64(len(lines)-1)*size*0.6
+	for _, line := range lines {
+		fmt.Fprintf(b, `<text x="%.0f" y="%.0f" font-size="%.0f" font-weight="bold" fill="#ffffff">%s</text>`, margin, y, size, escape(line))
+		y += size * 1.2
+	}
+	if card.Subtitle != "" {
+		fmt.Fprintf(b, `<text x="%.0f" y="%.0f" font-size="%.0f" fill="#ffffff" fill-opacity="0.8">%s</text>`, margin, y+size*0.2, size*0.5, escape(card.Subtitle))
+	}
+	fmt.Fprintf(b, `<text x="%.0f" y="%.0f" font-size="%.0f" fill="#ffffff" fill-opacity="0.8">%s</text>`, margin, h-margin/1.5, size*0.45, escape(card.Brand))
+}
+
+// drawChart draws the title in a band of the brand colour over a chart of
+// the series: bars for one series, lines for several
+func drawChart(b *bytes.Buffer, card *Card, w, h float64) {
+	margin := w / 20
+	band := h / 5
+	size := band / 3
+	fmt.Fprintf(b, `<rect width="100%%" height="100%%" fill="#ffffff"/>`)
+	fmt.Fprintf(b, `<rect width="100%%" height="%.0f" fill="%s"/>`, band, escape(card.Color))
+	title := wrap(card.Title, int((w-2*margin)/(size*0.55)), 1)
+	fmt.Fprintf(b, `<text x="%.0f" y="%.0f" font-size="%.0f" font-weight="bold" fill="#ffffff">%s</text>`, margin, band/2+size/3, size, escape(title[0]))
+
+	// The plot leaves room for the legend and the brand below it
+	left, right := margin, w-margin
+	top, bottom := band+margin/2, h-margin*1.5
+	low, high := bounds(card.Series)
+	y := func(v float64) float64 { return bottom - (v-low)/(high-low)*(bottom-top) }
+	fmt.Fprintf(b, `<line x1="%.0f" y1="%.1f" x2="%.0f" y2="%.1f" stroke="#cbd5e1" stroke-width="2"/>`, left, y(math.Max(low, 0)), right, y(math.Max(low, 0)))
+
+	count := 0
+	for _, s := range card.Series {
+		count = max(count, len(s.Values))
+	}
+	step := (right - left) / float64(max(count, 1))
+	for i, s := range card.Series {
+		color := seriesColor(card, i)
+		if len(card.Series) == 1 {
+			zero := y(math.Max(low, 0))
+			for j, v := range s.Values {
+				top := math.Min(y(v), zero)
+				fmt.Fprintf(b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"/>`, left+float64(j)*step+step*0.15, top, step*0.7, math.Abs(zero-y(v)), escape(color))
+			}
+		} else {
+			points := make([]string, len(s.Values))
+			for j, v := range s.Values {
+				points[j] = fmt.Sprintf("%.1f,%.1f", left+float64(j)*step+step/2, y(v))
+			}
+			fmt.Fprintf(b, `<polyline points="%s" fill="none" stroke="%s" stroke-width="4" stroke-linejoin="round"/>`, strings.Join(points, " "), escape(color))
+		}
+
+		x := left + float64(i)*(w-2*margin)/float64(len(card.Series)+1)
+		fmt.Fprintf(b, `<rect x="%.0f" y="%.0f" width="%.0f" height="%.0f" fill="%s"/>`, x, h-margin*0.9, size/2, size/2, escape(color))
+		fmt.Fprintf(b, `<text x="%.0f" y="%.0f" font-size="%.0f" fill="#334155">%s</text>`, x+size*0.7, h-margin*0.9+size/2, size/2, escape(s.Name))
+	}
+	fmt.Fprintf(b, `<text x="%.0f" y="%.0f" font-size="%.0f" fill="#64748b" text-anchor="end">%s</text>`, right, h-margin*0.9+size/2, size/2, escape(card.Brand))
+}
+
+// seriesColor returns the colour of the i-th series
+func seriesColor(card *Card, i int) string {
+	if i == 0 {
+		return card.Color
+	}
+	return seriesColors[(i-1)%len(seriesColors)]
+}
+
+// bounds returns the range the values of series are plotted in, which
+// includes zero so bars start from it
+func bounds(series []Series) (float64, float64) {
+	low, high := 0.0, 0.0
+	for _, s := range series {
+		for _, v := range s.Values {
+			low, high = math.Min(low, v), math.Max(high, v)
+		}
+	}
+	if high == low {
+		high = low + 1
+	}
+	return low, high
+}
+
+// wrap splits text in up to lines lines of about width characters, ending
+// the last with an ellipsis when the text does not fit
+func wrap(text string, width, lines int) []string {
+	width = max(width, 1)
+	var wrapped []string
+	var line string
+	for _, word := range strings.Fields(text) {
+		switch {
+		case line == "":
+			line = word
+		case len([]rune(line))+1+len([]rune(word)) <= width:
+			line += " " + word
+		default:
+			wrapped = append(wrapped, line)
+			line = word
+		}
+	}
+	wrapped = append(wrapped, line)
+
+	if len(wrapped) > lines {
+		wrapped = wrapped[:lines]
+		last := []rune(wrapped[lines-1])
+		if len(last) >= width {
+			last = last[:max(width-1, 0)]
+		}
+		wrapped[lines-1] = string(last) + "…"
+	}
+	for i, line := range wrapped {
+		if runes := []rune(line); len(runes) > width+1 {
+			wrapped[i] = string(runes[:width]) + "…"
+		}
+	}
+	return wrapped
+}
+
+func escape(text string) string {
+	return html.EscapeString(text)
+}
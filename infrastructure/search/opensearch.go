@@ -0,0 +1,233 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+)
+
+// openSearchMappings are the field types of the dataset index. Filters match
+// keyword fields exactly; text fields are analyzed for full text search.
+var openSearchMappings = map[string]interface{}{
+	"mappings": map[string]interface{}{
+		"properties": map[string]interface{}{
+			"id":                map[string]string{"type": "keyword"},
+			"title":             map[string]string{"type": "text"},
+			"description":       map[string]string{"type": "text"},
+			"translations":      map[string]string{"type": "text"},
+			"tags":              map[string]string{"type": "text"},
+			"tag_ids":           map[string]string{"type": "keyword"},
+			"organization_id":   map[string]string{"type": "keyword"},
+			"topic_id":          map[string]string{"type": "keyword"},
+			"business_field_id": map[string]string{"type": "keyword"},
+			"status":            map[string]string{"type": "keyword"},
+			"validation_status": map[string]string{"type": "keyword"},
+			"classification":    map[string]string{"type": "keyword"},
+			"updated_at":        map[string]string{"type": "date"},
+			"indexed_at":        map[string]string{"type": "date"},
+		},
+	},
+}
+
+// openSearchFields are the searched fields with their boosts
+var openSearchFields = []string{"title^3", "tags^2", "translations^2", "description"}
+
+// openSearchIndex keeps datasets in an OpenSearch or Elasticsearch index
+// through its REST API, so the application needs no native client
+type openSearchIndex struct {
+	baseURL  string
+	index    string
+	username string
+	password string
+	client   *http.Client
+}
+
+func newOpenSearchIndex(cfg *config.SearchConfig) *openSearchIndex {
+	return &openSearchIndex{
+		baseURL:  strings.TrimRight(cfg.URL, "/"),
+		index:    cfg.Index,
+		username: cfg.Username,
+		password: cfg.Password,
+		client:   &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+func (o *openSearchIndex) EnsureIndex(ctx context.Context) error {
+	resp, err := o.do(ctx, http.MethodHead, "/"+url.PathEscape(o.index), "", nil)
+	if err != nil {
+		return fmt.Errorf("failed to check search index: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	if resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("failed to check search index: status %d", resp.StatusCode)
+	}
+
+	body, err := json.Marshal(openSearchMappings)
+	if err != nil {
+		return err
+	}
+	return o.call(ctx, http.MethodPut, "/"+url.PathEscape(o.index), "application/json", body, nil)
+}
+
+func (o *openSearchIndex) Upsert(ctx context.Context, docs []Document) error {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, doc := range docs {
+		action := map[string]map[string]string{"index": {"_index": o.index, "_id": doc.ID}}
+		if err := encoder.Encode(action); err != nil {
+			return err
+		}
+		if err := encoder.Encode(doc); err != nil {
+			return err
+		}
+	}
+
+	var result struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			ID    string          `json:"_id"`
+			Error json.RawMessage `json:"error"`
+		} `json:"items"`
+	}
+	if err := o.call(ctx, http.MethodPost, "/_bulk", "application/x-ndjson", body.Bytes(), &result); err != nil {
+		return fmt.Errorf("failed to index documents: %w", err)
+	}
+	if result.Errors {
+		for _, item := range result.Items {
+			for _, action := range item {
+				if len(action.Error) > 0 {
+					return fmt.Errorf("failed to index document %s: %s", action.ID, action.Error)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (o *openSearchIndex) Delete(ctx context.Context, id string) error {
+	resp, err := o.do(ctx, http.MethodDelete, "/"+url.PathEscape(o.index)+"/_doc/"+url.PathEscape(id), "", nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete document %s: %w", id, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("failed to delete document %s: %s", id, readError(resp))
+	}
+	return nil
+}
+
+func (o *openSearchIndex) DeleteIndexedBefore(ctx context.Context, t time.Time) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"query": map[string]interface{}{
+			"range": map[string]interface{}{"indexed_at": map[string]interface{}{"lt": t.UTC().Format(time.RFC3339Nano)}},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if err := o.call(ctx, http.MethodPost, "/"+url.PathEscape(o.index)+"/_delete_by_query", "application/json", body, nil); err != nil {
+		return fmt.Errorf("failed to delete stale documents: %w", err)
+	}
+	return nil
+}
+
+func (o *openSearchIndex) Search(ctx context.Context, q *Query) (*Result, error) {
+	filters := make([]interface{}, 0, len(q.Filters))
+	for field, value := range q.Filters {
+		filters = append(filters, map[string]interface{}{"term": map[string]string{field: value}})
+	}
+	boolQuery := map[string]interface{}{"filter": filters}
+	if strings.TrimSpace(q.Text) != "" {
+		boolQuery["must"] = map[string]interface{}{
+			"multi_match": map[string]interface{}{
+				"query":     q.Text,
+				"fields":    openSearchFields,
+				"fuzziness": "AUTO",
+			},
+		}
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"from":             q.Offset,
+		"size":             q.Limit,
+		"track_total_hits": true,
+		"_source":          false,
+		"query":            map[string]interface{}{"bool": boolQuery},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Hits struct {
+			Total struct {
+				Value int `json:"value"`
+			} `json:"total"`
+			Hits []struct {
+				ID string `json:"_id"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := o.call(ctx, http.MethodPost, "/"+url.PathEscape(o.index)+"/_search", "application/json", body, &response); err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+
+	result := &Result{IDs: make([]string, len(response.Hits.Hits)), Total: response.Hits.Total.Value}
+	for i, hit := range response.Hits.Hits {
+		result.IDs[i] = hit.ID
+	}
+	return result, nil
+}
+
+// call sends a request and decodes a successful JSON response into out, if given
+func (o *openSearchIndex) call(ctx context.Context, method, path, contentType string, body []byte, out interface{}) error {
+	resp, err := o.do(ctx, method, path, contentType, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s: %s", method, path, readError(resp))
+	}
+	if out == nil {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (o *openSearchIndex) do(ctx context.Context, method, path, contentType string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, o.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if o.username != "" {
+		req.SetBasicAuth(o.username, o.password)
+	}
+	return o.client.Do(req)
+}
+
+// readError returns the status and start of the body of a failed response
+func readError(resp *http.Response) string {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Sprintf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+}
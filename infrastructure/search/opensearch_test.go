@@ -0,0 +1,87 @@
+package search
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+)
+
+func newTestIndex(t *testing.T, handler http.HandlerFunc) Index {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	index, err := NewIndex(&config.SearchConfig{Backend: "opensearch", URL: server.URL, Index: "datasets", Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return index
+}
+
+// Test the postgres backend has no index
+func TestNewIndex_Postgres(t *testing.T) {
+	index, err := NewIndex(&config.SearchConfig{Backend: "postgres"})
+	if err != nil || index != nil {
+		t.Errorf("Expected no index and no error, got %v, %v", index, err)
+	}
+}
+
+// Test documents are sent as one bulk request and failed items are reported
+func TestOpenSearchIndex_Upsert(t *testing.T) {
+	var lines []string
+	index := newTestIndex(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/_bulk" || r.Header.Get("Content-Type") != "application/x-ndjson" {
+			t.Errorf("Unexpected request %s %s", r.URL.Path, r.Header.Get("Content-Type"))
+		}
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		w.Write([]byte(`{"errors":true,"items":[{"index":{"_id":"a"}},{"index":{"_id":"b","error":{"type":"mapper_parsing_exception"}}}]}`))
+	})
+
+	err := index.Upsert(context.Background(), []Document{{ID: "a", Title: "Rainfall"}, {ID: "b", Title: "Population"}})
+	if err == nil || !strings.Contains(err.Error(), "document b") {
+		t.Errorf("Expected the failed document to be reported, got %v", err)
+	}
+	if len(lines) != 4 {
+		t.Fatalf("Expected 4 bulk lines, got %d", len(lines))
+	}
+	if lines[0] != `{"index":{"_id":"a","_index":"datasets"}}` {
+		t.Errorf("Unexpected bulk action %s", lines[0])
+	}
+}
+
+// Test a search sends the text and filters and returns the hits in order
+func TestOpenSearchIndex_Search(t *testing.T) {
+	var body map[string]interface{}
+	index := newTestIndex(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/datasets/_search" {
+			t.Errorf("Expected /datasets/_search, got %s", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"hits":{"total":{"value":12},"hits":[{"_id":"b"},{"_id":"a"}]}}`))
+	})
+
+	result, err := index.Search(context.Background(), &Query{Text: "rainfall", Filters: map[string]string{"status": "published"}, Limit: 2, Offset: 4})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Total != 12 || len(result.IDs) != 2 || result.IDs[0] != "b" {
+		t.Errorf("Unexpected result %+v", result)
+	}
+
+	if body["from"] != float64(4) || body["size"] != float64(2) {
+		t.Errorf("Expected from 4 and size 2, got %v and %v", body["from"], body["size"])
+	}
+	query, _ := json.Marshal(body["query"])
+	if !strings.Contains(string(query), `"rainfall"`) || !strings.Contains(string(query), `{"term":{"status":"published"}}`) {
+		t.Errorf("Expected the text and filter in the query, got %s", query)
+	}
+}
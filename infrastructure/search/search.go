@@ -0,0 +1,68 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+)
+
+// Document is a dataset as it is kept in the search index
+type Document struct {
+	ID               string    `json:"id"`
+	Title            string    `json:"title"`
+	Description      string    `json:"description"`
+	Translations     []string  `json:"translations"` // localized titles and descriptions
+	Tags             []string  `json:"tags"`
+	TagIDs           []string  `json:"tag_ids"`
+	OrganizationID   string    `json:"organization_id"`
+	TopicID          string    `json:"topic_id"`
+	BusinessFieldID  string    `json:"business_field_id"`
+	Status           string    `json:"status"`
+	ValidationStatus string    `json:"validation_status"`
+	Classification   string    `json:"classification"`
+	UpdatedAt        time.Time `json:"updated_at"`
+	IndexedAt        time.Time `json:"indexed_at"`
+}
+
+// Query is a full text search. Filters maps document fields to the value
+// they must have.
+type Query struct {
+	Text    string
+	Filters map[string]string
+	Limit   int
+	Offset  int
+}
+
+// Result is a page of matching document IDs, best match first
+type Result struct {
+	IDs   []string
+	Total int
+}
+
+// Index is a search index of datasets
+type Index interface {
+	// EnsureIndex creates the index with its mappings if it does not exist
+	EnsureIndex(ctx context.Context) error
+	// Upsert adds or replaces documents
+	Upsert(ctx context.Context, docs []Document) error
+	// Delete removes a document; removing a missing document is not an error
+	Delete(ctx context.Context, id string) error
+	// DeleteIndexedBefore removes the documents last indexed before t
+	DeleteIndexedBefore(ctx context.Context, t time.Time) error
+	Search(ctx context.Context, q *Query) (*Result, error)
+}
+
+// NewIndex creates the index of the configured backend. It returns nil for
+// the postgres backend, which searches the database directly.
+func NewIndex(cfg *config.SearchConfig) (Index, error) {
+	switch cfg.Backend {
+	case "", "postgres":
+		return nil, nil
+	case "opensearch":
+		return newOpenSearchIndex(cfg), nil
+	default:
+		return nil, fmt.Errorf("unsupported search backend %q", cfg.Backend)
+	}
+}
@@ -0,0 +1,238 @@
+// Package secrets resolves references to secrets kept in a secret store, so
+// configuration holds references instead of credentials. A reference is
+// scheme://path#key: vault://database/creds/portal#password is the key
+// password of the Vault secret at database/creds/portal, and
+// awssm://portal/minio#secret_key the key secret_key of the JSON AWS Secrets
+// Manager secret portal/minio. Without a key the secret must hold one value.
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"portal-data-backend/infrastructure/logger"
+)
+
+// Schemes of the supported secret stores
+const (
+	SchemeVault = "vault"
+	SchemeAWS   = "awssm"
+)
+
+// Secret is a secret read from a store
+type Secret struct {
+	// Data holds the keys of a structured secret; Value is the value of an
+	// unstructured one
+	Data  map[string]string
+	Value string
+	// LeaseID identifies the lease of a dynamic secret, which expires after
+	// LeaseDuration unless renewed
+	LeaseID       string
+	LeaseDuration time.Duration
+	Renewable     bool
+}
+
+// Provider reads the secrets of a store
+type Provider interface {
+	Read(ctx context.Context, path string) (*Secret, error)
+}
+
+// LeaseRenewer is implemented by providers of dynamic secrets. Renew extends
+// a lease by increment and returns its new duration, which the store may
+// cap.
+type LeaseRenewer interface {
+	Renew(ctx context.Context, leaseID string, increment time.Duration) (time.Duration, error)
+}
+
+// IsReference reports whether value refers to a secret of a supported store
+func IsReference(value string) bool {
+	scheme, _, ok := strings.Cut(value, "://")
+	return ok && (scheme == SchemeVault || scheme == SchemeAWS)
+}
+
+// Resolver resolves references through the providers of their scheme. Each
+// secret is read once, so the keys of one dynamic secret, like the user and
+// password of database credentials, come from the same lease.
+type Resolver struct {
+	providers map[string]Provider
+
+	mu      sync.Mutex
+	secrets map[string]*leasedSecret
+}
+
+// leasedSecret is a secret read by the resolver with the schedule of its
+// lease
+type leasedSecret struct {
+	ref       string
+	secret    *Secret
+	provider  Provider
+	renewAt   time.Time
+	expiresAt time.Time
+}
+
+// NewResolver creates a resolver reading secrets through providers, keyed by
+// scheme
+func NewResolver(providers map[string]Provider) *Resolver {
+	return &Resolver{providers: providers, secrets: make(map[string]*leasedSecret)}
+}
+
+// Resolve returns the value ref refers to
+func (r *Resolver) Resolve(ctx context.Context, ref string) (string, error) {
+	scheme, rest, ok := strings.Cut(ref, "://")
+	if !ok {
+		return "", fmt.Errorf("invalid secret reference %q", ref)
+	}
+	path, key, _ := strings.Cut(rest, "#")
+	if path == "" {
+		return "", fmt.Errorf("secret reference %q has no path", ref)
+	}
+
+	secret, err := r.read(ctx, scheme, path)
+	if err != nil {
+		return "", err
+	}
+	if key == "" {
+		if secret.Value != "" || len(secret.Data) == 0 {
+			return secret.Value, nil
+		}
+		if len(secret.Data) == 1 {
+			for _, value := range secret.Data {
+				return value, nil
+			}
+		}
+		return "", fmt.Errorf("secret %s://%s holds several keys, reference one with #key", scheme, path)
+	}
+	value, ok := secret.Data[key]
+	if !ok {
+		return "", fmt.Errorf("secret %s://%s has no key %s", scheme, path, key)
+	}
+	return value, nil
+}
+
+func (r *Resolver) read(ctx context.Context, scheme, path string) (*Secret, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ref := scheme + "://" + path
+	if leased, ok := r.secrets[ref]; ok {
+		return leased.secret, nil
+	}
+
+	provider := r.providers[scheme]
+	if provider == nil {
+		return nil, fmt.Errorf("no secret store is configured for %s references", scheme)
+	}
+	secret, err := provider.Read(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret %s: %w", ref, err)
+	}
+
+	leased := &leasedSecret{ref: ref, secret: secret, provider: provider}
+	if secret.LeaseID != "" && secret.LeaseDuration > 0 {
+		leased.schedule(time.Now(), secret.LeaseDuration)
+	}
+	r.secrets[ref] = leased
+	return secret, nil
+}
+
+// schedule renews the lease at two thirds of its duration
+func (s *leasedSecret) schedule(now time.Time, duration time.Duration) {
+	s.expiresAt = now.Add(duration)
+	s.renewAt = now.Add(duration * 2 / 3)
+}
+
+// KeepAlive renews the leases of the dynamic secrets read until ctx is done;
+// it returns at once when there are none. A lease that cannot be renewed is
+// retried until it expires, and one at its maximum duration is left to
+// expire, both logged: their credentials stop working when they expire.
+func (r *Resolver) KeepAlive(ctx context.Context) {
+	for {
+		next, ok := r.renewDue(ctx, time.Now())
+		if !ok {
+			return
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// renewDue renews the leases due at now and returns when the next one is
+// due, or false when no lease is left to renew
+func (r *Resolver) renewDue(ctx context.Context, now time.Time) (time.Time, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	log := logger.FromContext(ctx)
+	var next time.Time
+	for _, leased := range r.leased() {
+		if !leased.renewAt.After(now) {
+			r.renew(ctx, leased, now, log)
+		}
+		if leased.renewAt.IsZero() {
+			continue
+		}
+		if next.IsZero() || leased.renewAt.Before(next) {
+			next = leased.renewAt
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// leased returns the secrets with a lease to renew, in reference order
+func (r *Resolver) leased() []*leasedSecret {
+	var leased []*leasedSecret
+	for _, s := range r.secrets {
+		if !s.renewAt.IsZero() {
+			leased = append(leased, s)
+		}
+	}
+	sort.Slice(leased, func(i, j int) bool { return leased[i].ref < leased[j].ref })
+	return leased
+}
+
+func (r *Resolver) renew(ctx context.Context, leased *leasedSecret, now time.Time, log *logger.Logger) {
+	renewer, ok := leased.provider.(LeaseRenewer)
+	if !ok || !leased.secret.Renewable {
+		log.Warn("Secret %s is not renewable and expires at %s", leased.ref, leased.expiresAt.Format(time.RFC3339))
+		leased.renewAt = time.Time{}
+		return
+	}
+
+	duration, err := renewer.Renew(ctx, leased.secret.LeaseID, leased.secret.LeaseDuration)
+	if err != nil {
+		if remaining := leased.expiresAt.Sub(now); remaining > time.Second {
+			log.Error("Failed to renew the lease of secret %s, retrying: %v", leased.ref, err)
+			leased.renewAt = now.Add(remaining / 3)
+			return
+		}
+		log.Error("Failed to renew the lease of secret %s, it expired: %v", leased.ref, err)
+		leased.renewAt = time.Time{}
+		return
+	}
+
+	expiresAt := now.Add(duration)
+	if !expiresAt.After(leased.expiresAt) {
+		log.Warn("Secret %s reached its maximum lease duration and expires at %s", leased.ref, expiresAt.Format(time.RFC3339))
+		leased.expiresAt = expiresAt
+		leased.renewAt = time.Time{}
+		return
+	}
+	leased.schedule(now, duration)
+}
+
+// readError describes the error response of a secret store
+func readError(resp *http.Response) string {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Sprintf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+}
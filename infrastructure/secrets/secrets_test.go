@@ -0,0 +1,199 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeProvider struct {
+	secrets  map[string]*Secret
+	reads    int
+	renewals []string
+	renewErr error
+	duration time.Duration
+}
+
+func (p *fakeProvider) Read(ctx context.Context, path string) (*Secret, error) {
+	p.reads++
+	secret, ok := p.secrets[path]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return secret, nil
+}
+
+func (p *fakeProvider) Renew(ctx context.Context, leaseID string, increment time.Duration) (time.Duration, error) {
+	p.renewals = append(p.renewals, leaseID)
+	return p.duration, p.renewErr
+}
+
+// Test references resolve to the keys of their secret, reading each secret
+// once
+func TestResolver_Resolve(t *testing.T) {
+	provider := &fakeProvider{secrets: map[string]*Secret{
+		"database/creds/portal": {Data: map[string]string{"username": "v-portal", "password": "s3cret"}},
+		"portal/jwt":            {Value: "signing-key"},
+	}}
+	resolver := NewResolver(map[string]Provider{SchemeVault: provider})
+	ctx := context.Background()
+
+	user, err := resolver.Resolve(ctx, "vault://database/creds/portal#username")
+	if err != nil || user != "v-portal" {
+		t.Errorf("Expected the username, got %q and %v", user, err)
+	}
+	password, err := resolver.Resolve(ctx, "vault://database/creds/portal#password")
+	if err != nil || password != "s3cret" {
+		t.Errorf("Expected the password, got %q and %v", password, err)
+	}
+	if provider.reads != 1 {
+		t.Errorf("Expected the secret to be read once, got %d reads", provider.reads)
+	}
+	if value, err := resolver.Resolve(ctx, "vault://portal/jwt"); err != nil || value != "signing-key" {
+		t.Errorf("Expected the value of the secret, got %q and %v", value, err)
+	}
+
+	if _, err := resolver.Resolve(ctx, "vault://database/creds/portal"); err == nil {
+		t.Errorf("Expected an error without a key for a secret holding several")
+	}
+	if _, err := resolver.Resolve(ctx, "vault://database/creds/portal#token"); err == nil {
+		t.Errorf("Expected an error for a missing key")
+	}
+	if _, err := resolver.Resolve(ctx, "awssm://portal/minio#secret_key"); err == nil {
+		t.Errorf("Expected an error for a store that is not configured")
+	}
+}
+
+// Test only the schemes of supported stores are references
+func TestIsReference(t *testing.T) {
+	for value, want := range map[string]bool{
+		"vault://secret/data/portal#key": true,
+		"awssm://portal/minio":           true,
+		"postgres://replica:5432/portal": false,
+		"plain-password":                 false,
+	} {
+		if got := IsReference(value); got != want {
+			t.Errorf("Expected IsReference(%q) to be %v, got %v", value, want, got)
+		}
+	}
+}
+
+// Test leases are renewed at two thirds of their duration until they reach
+// their maximum
+func TestResolver_RenewDue(t *testing.T) {
+	provider := &fakeProvider{
+		secrets: map[string]*Secret{
+			"database/creds/portal": {Data: map[string]string{"password": "s3cret"}, LeaseID: "lease-1", LeaseDuration: time.Hour, Renewable: true},
+		},
+		duration: time.Hour,
+	}
+	resolver := NewResolver(map[string]Provider{SchemeVault: provider})
+	ctx := context.Background()
+	if _, err := resolver.Resolve(ctx, "vault://database/creds/portal#password"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	now := time.Now()
+	next, ok := resolver.renewDue(ctx, now)
+	if !ok || len(provider.renewals) != 0 || next.Sub(now) > 40*time.Minute || next.Sub(now) < 39*time.Minute {
+		t.Fatalf("Expected the renewal in 40 minutes, got %s and %d renewals", next.Sub(now), len(provider.renewals))
+	}
+
+	now = next
+	next, ok = resolver.renewDue(ctx, now)
+	if !ok || len(provider.renewals) != 1 || provider.renewals[0] != "lease-1" || next.Sub(now) != 40*time.Minute {
+		t.Fatalf("Expected lease-1 renewed for another hour, got %v and %s", provider.renewals, next.Sub(now))
+	}
+
+	// The store caps the lease at its maximum duration
+	provider.duration = 10 * time.Minute
+	if _, ok := resolver.renewDue(ctx, next); ok {
+		t.Errorf("Expected no renewal to be left once the lease is at its maximum")
+	}
+}
+
+// Test Vault secrets are read through the HTTP API, unwrapping KV v2 secrets
+func TestVault_Read(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/portal/minio":
+			w.Write([]byte(`{"data":{"data":{"access_key":"portal","secret_key":"minio-secret"},"metadata":{"version":3}}}`))
+		case "/v1/database/creds/portal":
+			w.Write([]byte(`{"lease_id":"database/creds/portal/abc","lease_duration":3600,"renewable":true,"data":{"username":"v-portal","password":"s3cret"}}`))
+		case "/v1/sys/leases/renew":
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			if r.Method != http.MethodPut || body["lease_id"] != "database/creds/portal/abc" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"lease_id":"database/creds/portal/abc","lease_duration":1800,"renewable":true}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	vault := NewVault(server.URL, "root", "", time.Second)
+	ctx := context.Background()
+
+	minio, err := vault.Read(ctx, "secret/data/portal/minio")
+	if err != nil || minio.Data["secret_key"] != "minio-secret" {
+		t.Errorf("Expected the KV secret, got %+v and %v", minio, err)
+	}
+	creds, err := vault.Read(ctx, "database/creds/portal")
+	if err != nil || creds.Data["username"] != "v-portal" || creds.LeaseDuration != time.Hour || !creds.Renewable {
+		t.Errorf("Expected the leased credentials, got %+v and %v", creds, err)
+	}
+	if duration, err := vault.Renew(ctx, creds.LeaseID, time.Hour); err != nil || duration != 30*time.Minute {
+		t.Errorf("Expected the lease renewed for 30 minutes, got %s and %v", duration, err)
+	}
+	if _, err := vault.Read(ctx, "secret/data/missing"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Expected a 404 error, got %v", err)
+	}
+}
+
+// Test requests are signed as in the get-vanilla case of the AWS Signature
+// Version 4 test suite
+func TestSignV4(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	req.Header = http.Header{}
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+	signV4(req, nil, "service", "us-east-1", "AKIDEXAMPLE", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", now)
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+}
+
+// Test JSON secrets of Secrets Manager expose their keys
+func TestAWSSecretsManager_Read(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" || !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"Name":"portal/minio","SecretString":"{\"access_key\":\"portal\",\"secret_key\":\"minio-secret\"}"}`))
+	}))
+	defer server.Close()
+
+	aws := NewAWSSecretsManager("ap-southeast-3", "AKIDEXAMPLE", "secret", "", time.Second)
+	aws.endpoint = server.URL
+
+	secret, err := aws.Read(context.Background(), "portal/minio")
+	if err != nil || secret.Data["secret_key"] != "minio-secret" {
+		t.Errorf("Expected the keys of the secret, got %+v and %v", secret, err)
+	}
+}
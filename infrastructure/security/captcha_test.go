@@ -0,0 +1,41 @@
+package security
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Test tokens are checked with the siteverify endpoint
+func TestCaptchaVerifier_Verify(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.PostForm.Get("secret") != "s3cret" || r.PostForm.Get("remoteip") != "203.0.113.7" {
+			t.Errorf("Expected the secret and remote IP to be sent, got %v", r.PostForm)
+		}
+		json.NewEncoder(w).Encode(map[string]bool{"success": r.PostForm.Get("response") == "solved"})
+	}))
+	defer server.Close()
+
+	v := NewCaptchaVerifier(server.URL, "s3cret")
+	if err := v.Verify(context.Background(), "solved", "203.0.113.7"); err != nil {
+		t.Errorf("Expected a solved captcha to pass, got %v", err)
+	}
+	if err := v.Verify(context.Background(), "guessed", "203.0.113.7"); !errors.Is(err, ErrCaptchaFailed) {
+		t.Errorf("Expected ErrCaptchaFailed, got %v", err)
+	}
+	if err := v.Verify(context.Background(), "", "203.0.113.7"); !errors.Is(err, ErrCaptchaFailed) {
+		t.Errorf("Expected ErrCaptchaFailed for a missing token, got %v", err)
+	}
+}
+
+// Test every token passes without a secret
+func TestCaptchaVerifier_Disabled(t *testing.T) {
+	v := NewCaptchaVerifier("http://127.0.0.1:0", "")
+	if err := v.Verify(context.Background(), "", ""); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
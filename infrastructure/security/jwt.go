@@ -5,17 +5,20 @@ import (
 	"time"
 
 	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/infrastructure/tenant"
 	"portal-data-backend/pkg/errors"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 // JWTManager handles JWT token operations
 type JWTManager struct {
-	secret             []byte
-	accessTokenExpiry  time.Duration
-	refreshTokenExpiry time.Duration
-	issuer             string
+	secret              []byte
+	accessTokenExpiry   time.Duration
+	refreshTokenExpiry  time.Duration
+	impersonationExpiry time.Duration
+	issuer              string
 }
 
 // Claims represents JWT claims
@@ -24,9 +27,31 @@ type Claims struct {
 	OrganizationID string `json:"organization_id"`
 	RoleID         string `json:"role_id"`
 	Email          string `json:"email"`
+	// TenantID is the portal the token was issued by, empty when the
+	// deployment hosts a single portal
+	TenantID string `json:"tenant_id,omitempty"`
+	// Actor is the admin acting as the user of the token, nil unless the
+	// token impersonates the user
+	Actor *Actor `json:"act,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// Actor is the user acting on behalf of the subject of a token, after the
+// act claim of RFC 8693
+type Actor struct {
+	UserID string `json:"sub"`
+	Email  string `json:"email,omitempty"`
+}
+
+// IssuedBy reports whether the claims were issued by the tenant id. Tokens
+// issued before tenants were enabled belong to the default tenant.
+func (c *Claims) IssuedBy(id string) bool {
+	if c.TenantID == "" {
+		return id == tenant.DefaultID
+	}
+	return c.TenantID == id
+}
+
 // TokenPair contains access and refresh tokens
 type TokenPair struct {
 	AccessToken  string `json:"access_token"`
@@ -38,25 +63,27 @@ type TokenPair struct {
 // NewJWTManager creates a new JWT manager
 func NewJWTManager(cfg *config.JWTConfig) *JWTManager {
 	return &JWTManager{
-		secret:             []byte(cfg.Secret),
-		accessTokenExpiry:  cfg.AccessTokenExpiry,
-		refreshTokenExpiry: cfg.RefreshTokenExpiry,
-		issuer:             cfg.Issuer,
+		secret:              []byte(cfg.Secret),
+		accessTokenExpiry:   cfg.AccessTokenExpiry,
+		refreshTokenExpiry:  cfg.RefreshTokenExpiry,
+		impersonationExpiry: cfg.ImpersonationExpiry,
+		issuer:              cfg.Issuer,
 	}
 }
 
-// GenerateTokenPair generates access and refresh tokens
-func (j *JWTManager) GenerateTokenPair(userID, organizationID, roleID, email string) (*TokenPair, error) {
+// GenerateTokenPair generates access and refresh tokens valid for the
+// tenant tenantID, which is empty when the deployment hosts a single portal
+func (j *JWTManager) GenerateTokenPair(userID, organizationID, roleID, email, tenantID string) (*TokenPair, error) {
 	if userID == "" {
 		return nil, errors.New("user_id is required")
 	}
 
-	accessToken, err := j.generateAccessToken(userID, organizationID, roleID, email)
+	accessToken, err := j.generateAccessToken(userID, organizationID, roleID, email, tenantID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
 
-	refreshToken, err := j.generateRefreshToken(userID, organizationID, roleID, email)
+	refreshToken, err := j.generateRefreshToken(userID, organizationID, roleID, email, tenantID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
 	}
@@ -69,8 +96,45 @@ func (j *JWTManager) GenerateTokenPair(userID, organizationID, roleID, email str
 	}, nil
 }
 
+// GenerateImpersonationToken generates an access token for the user
+// userID on behalf of actor. It expires after the impersonation expiry and
+// comes without a refresh token.
+func (j *JWTManager) GenerateImpersonationToken(userID, organizationID, roleID, email, tenantID string, actor Actor) (*TokenPair, error) {
+	if userID == "" || actor.UserID == "" {
+		return nil, errors.New("user_id and actor are required")
+	}
+
+	now := time.Now()
+	claims := Claims{
+		UserID:         userID,
+		OrganizationID: organizationID,
+		RoleID:         roleID,
+		Email:          email,
+		TenantID:       tenantID,
+		Actor:          &actor,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    j.issuer,
+			Subject:   userID,
+			ExpiresAt: jwt.NewNumericDate(now.Add(j.impersonationExpiry)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+		},
+	}
+
+	accessToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(j.secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate impersonation token: %w", err)
+	}
+
+	return &TokenPair{
+		AccessToken: accessToken,
+		ExpiresIn:   int64(j.impersonationExpiry.Seconds()),
+		TokenType:   "Bearer",
+	}, nil
+}
+
 // generateAccessToken generates an access token
-func (j *JWTManager) generateAccessToken(userID, organizationID, roleID, email string) (string, error) {
+func (j *JWTManager) generateAccessToken(userID, organizationID, roleID, email, tenantID string) (string, error) {
 	now := time.Now()
 	expiresAt := now.Add(j.accessTokenExpiry)
 
@@ -79,7 +143,9 @@ func (j *JWTManager) generateAccessToken(userID, organizationID, roleID, email s
 		OrganizationID: organizationID,
 		RoleID:         roleID,
 		Email:          email,
+		TenantID:       tenantID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
 			Issuer:    j.issuer,
 			Subject:   userID,
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
@@ -93,7 +159,7 @@ func (j *JWTManager) generateAccessToken(userID, organizationID, roleID, email s
 }
 
 // generateRefreshToken generates a refresh token
-func (j *JWTManager) generateRefreshToken(userID, organizationID, roleID, email string) (string, error) {
+func (j *JWTManager) generateRefreshToken(userID, organizationID, roleID, email, tenantID string) (string, error) {
 	now := time.Now()
 	expiresAt := now.Add(j.refreshTokenExpiry)
 
@@ -102,7 +168,9 @@ func (j *JWTManager) generateRefreshToken(userID, organizationID, roleID, email
 		OrganizationID: organizationID,
 		RoleID:         roleID,
 		Email:          email,
+		TenantID:       tenantID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
 			Issuer:    j.issuer,
 			Subject:   userID,
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
@@ -149,8 +217,12 @@ func (j *JWTManager) RefreshAccessToken(refreshToken string) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("invalid refresh token: %w", err)
 	}
+	// Impersonation ends when its token expires
+	if claims.Actor != nil {
+		return "", fmt.Errorf("invalid refresh token: %w", errors.ErrInvalidToken)
+	}
 
-	return j.generateAccessToken(claims.UserID, claims.OrganizationID, claims.RoleID, claims.Email)
+	return j.generateAccessToken(claims.UserID, claims.OrganizationID, claims.RoleID, claims.Email, claims.TenantID)
 }
 
 // GetUserIDFromToken extracts user ID from token
@@ -0,0 +1,150 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// testIssuer serves the discovery document, signing keys and token endpoint
+// of a provider issuing the ID token claims returns
+type testIssuer struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	claims func(nonce string) jwt.MapClaims
+	nonce  string
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Expected to generate a key, got %v", err)
+	}
+	issuer := &testIssuer{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 issuer.server.URL,
+			"authorization_endpoint": issuer.server.URL + "/authorize",
+			"token_endpoint":         issuer.server.URL + "/token",
+			"jwks_uri":               issuer.server.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kid": "k1",
+			"kty": "RSA",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.PostForm.Get("code") != "c0de" || r.PostForm.Get("code_verifier") != "verifier" || r.PostForm.Get("client_secret") != "s3cret" {
+			http.Error(w, `{"error": "invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, issuer.claims(issuer.nonce))
+		token.Header["kid"] = "k1"
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Errorf("Expected to sign the ID token, got %v", err)
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id_token": signed})
+	})
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+
+	issuer.claims = func(nonce string) jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":            issuer.server.URL,
+			"aud":            "portal",
+			"sub":            "user-1",
+			"exp":            time.Now().Add(time.Hour).Unix(),
+			"nonce":          nonce,
+			"email":          "Siti@Example.com",
+			"email_verified": "true",
+			"name":           "Siti",
+		}
+	}
+	return issuer
+}
+
+func (i *testIssuer) provider() *Provider {
+	return NewProvider(config.OIDCProviderConfig{
+		Name:         "keycloak",
+		Issuer:       i.server.URL,
+		ClientID:     "portal",
+		ClientSecret: "s3cret",
+		Scopes:       []string{"openid", "email"},
+	}, "https://data.example.com/auth/oidc/{provider}/callback")
+}
+
+// Test the sign in URL carries the state, nonce and PKCE challenge
+func TestProvider_AuthCodeURL(t *testing.T) {
+	issuer := newTestIssuer(t)
+
+	raw, err := issuer.provider().AuthCodeURL(context.Background(), "st4te", "n0nce", "verifier")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("Expected a URL, got %v", err)
+	}
+	query := u.Query()
+	if u.Path != "/authorize" || query.Get("state") != "st4te" || query.Get("nonce") != "n0nce" || query.Get("scope") != "openid email" {
+		t.Errorf("Expected the authorization endpoint with the state, nonce and scopes, got %s", raw)
+	}
+	if query.Get("redirect_uri") != "https://data.example.com/auth/oidc/keycloak/callback" {
+		t.Errorf("Expected the redirect URL of the provider, got %s", query.Get("redirect_uri"))
+	}
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" || query.Get("code_challenge") == "verifier" {
+		t.Errorf("Expected an S256 code challenge, got %s", raw)
+	}
+}
+
+// Test codes are exchanged for the verified claims of the ID token
+func TestProvider_Exchange(t *testing.T) {
+	issuer := newTestIssuer(t)
+	issuer.nonce = "n0nce"
+	provider := issuer.provider()
+
+	claims, err := provider.Exchange(context.Background(), "c0de", "verifier", "n0nce")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if claims.Subject != "user-1" || claims.Email != "siti@example.com" || !claims.EmailVerified || claims.Name != "Siti" {
+		t.Errorf("Expected the claims of the ID token, got %+v", claims)
+	}
+
+	if _, err := provider.Exchange(context.Background(), "c0de", "verifier", "other"); !errors.Is(err, ErrInvalidIDToken) {
+		t.Errorf("Expected ErrInvalidIDToken for another nonce, got %v", err)
+	}
+	if _, err := provider.Exchange(context.Background(), "c0de", "guessed", "n0nce"); err == nil {
+		t.Errorf("Expected an error for the wrong code verifier")
+	}
+
+	issuer.claims = func(nonce string) jwt.MapClaims {
+		return jwt.MapClaims{"iss": issuer.server.URL, "aud": "someone-else", "sub": "user-1", "exp": time.Now().Add(time.Hour).Unix(), "nonce": nonce}
+	}
+	if _, err := provider.Exchange(context.Background(), "c0de", "verifier", "n0nce"); !errors.Is(err, ErrInvalidIDToken) {
+		t.Errorf("Expected ErrInvalidIDToken for another audience, got %v", err)
+	}
+}
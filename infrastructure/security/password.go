@@ -1,7 +1,14 @@
 package security
 
 import (
+	_ "embed"
 	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+
+	"portal-data-backend/infrastructure/config"
 
 	"golang.org/x/crypto/bcrypt"
 )
@@ -9,20 +16,56 @@ import (
 var (
 	// ErrPasswordTooShort is returned when password is too short
 	ErrPasswordTooShort = errors.New("password must be at least 8 characters")
+	// ErrPasswordCommon is returned when password is a well known one
+	ErrPasswordCommon = errors.New("password is too common")
 )
 
-// PasswordHandler handles password hashing and verification
+// commonPasswords lists well known passwords, one per line in lowercase
+//
+//go:embed common_passwords.txt
+var commonPasswords string
+
+// commonPasswordSet holds commonPasswords by password
+var commonPasswordSet = func() map[string]bool {
+	set := make(map[string]bool)
+	for _, password := range strings.Fields(commonPasswords) {
+		set[password] = true
+	}
+	return set
+}()
+
+// PasswordHandler handles password hashing and verification, and checks
+// passwords against the password policy
 type PasswordHandler struct {
 	cost int
+
+	mu     sync.RWMutex
+	policy config.PasswordPolicyConfig
 }
 
-// NewPasswordHandler creates a new password handler
+// NewPasswordHandler creates a new password handler. Until SetPolicy is
+// called passwords only need to be 8 characters long.
 func NewPasswordHandler() *PasswordHandler {
 	return &PasswordHandler{
-		cost: bcrypt.DefaultCost,
+		cost:   bcrypt.DefaultCost,
+		policy: config.PasswordPolicyConfig{MinLength: 8},
 	}
 }
 
+// SetPolicy changes the password policy passwords are checked against
+func (p *PasswordHandler) SetPolicy(policy config.PasswordPolicyConfig) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.policy = policy
+}
+
+// Policy returns the password policy passwords are checked against
+func (p *PasswordHandler) Policy() config.PasswordPolicyConfig {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.policy
+}
+
 // Hash generates a bcrypt hash from a password
 func (p *PasswordHandler) Hash(password string) (string, error) {
 	if len(password) < 8 {
@@ -43,10 +86,41 @@ func (p *PasswordHandler) Verify(password, hash string) bool {
 	return err == nil
 }
 
-// ValidatePassword validates password strength
+// ValidatePassword checks password against the length, character classes
+// and common passwords of the password policy. Reuse of former passwords is
+// left to the caller, which knows them.
 func (p *PasswordHandler) ValidatePassword(password string) error {
+	policy := p.Policy()
 	if len(password) < 8 {
 		return ErrPasswordTooShort
 	}
+	if len([]rune(password)) < policy.MinLength {
+		return fmt.Errorf("password must be at least %d characters", policy.MinLength)
+	}
+	if classes := passwordClasses(password); classes < policy.MinClasses {
+		return fmt.Errorf("password must mix at least %d of lowercase letters, uppercase letters, digits and symbols", policy.MinClasses)
+	}
+	if policy.BanCommon && commonPasswordSet[strings.ToLower(password)] {
+		return ErrPasswordCommon
+	}
 	return nil
 }
+
+// passwordClasses counts the classes of characters password mixes:
+// lowercase letters, uppercase letters, digits and symbols
+func passwordClasses(password string) int {
+	var lower, upper, digit, symbol int
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			symbol = 1
+		}
+	}
+	return lower + upper + digit + symbol
+}
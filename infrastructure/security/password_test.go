@@ -0,0 +1,34 @@
+package security
+
+import (
+	"testing"
+
+	"portal-data-backend/infrastructure/config"
+)
+
+// Test passwords are checked against the length, character classes and
+// common passwords of the policy
+func TestPasswordHandler_ValidatePassword(t *testing.T) {
+	p := NewPasswordHandler()
+	p.SetPolicy(config.PasswordPolicyConfig{MinLength: 10, MinClasses: 3, BanCommon: true})
+
+	for password, valid := range map[string]bool{
+		"Short1!":         false,
+		"alllowercase":    false,
+		"lowercase12345":  false,
+		"Lowercase12345":  true,
+		"lower case 123":  true,
+		"Indonesia123":    false,
+		"INDONESIA123abc": true,
+		"Password1234":    false,
+	} {
+		if err := p.ValidatePassword(password); (err == nil) != valid {
+			t.Errorf("Expected %q valid %v, got %v", password, valid, err)
+		}
+	}
+
+	p.SetPolicy(config.PasswordPolicyConfig{MinLength: 8})
+	if err := p.ValidatePassword("password123"); err != nil {
+		t.Errorf("Expected a common password allowed without BanCommon, got %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package security
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"portal-data-backend/infrastructure/config"
+)
+
+// encryptedPrefix marks a value produced by SecretCipher. Values without it are
+// treated as legacy plaintext.
+const encryptedPrefix = "enc:v1:"
+
+var (
+	// ErrUnknownSecretKey is returned when a value was encrypted with a key that is no longer configured
+	ErrUnknownSecretKey = errors.New("secret was encrypted with an unknown key")
+	// ErrInvalidCiphertext is returned when an encrypted value is malformed or fails authentication
+	ErrInvalidCiphertext = errors.New("invalid secret ciphertext")
+)
+
+// SecretCipher encrypts secrets at rest with AES-256-GCM. Each ciphertext
+// carries the ID of the key that produced it, so retired keys can still
+// decrypt while everything new is written with the primary key.
+type SecretCipher struct {
+	primaryID string
+	keys      map[string]cipher.AEAD
+}
+
+// NewSecretCipher creates a cipher that encrypts with keys[primaryID] and
+// decrypts with any key in keys. Keys must be 32 bytes.
+func NewSecretCipher(primaryID string, keys map[string][]byte) (*SecretCipher, error) {
+	if _, ok := keys[primaryID]; !ok {
+		return nil, fmt.Errorf("primary secret key %q is not configured", primaryID)
+	}
+
+	c := &SecretCipher{
+		primaryID: primaryID,
+		keys:      make(map[string]cipher.AEAD, len(keys)),
+	}
+	for id, key := range keys {
+		if strings.Contains(id, ":") {
+			return nil, fmt.Errorf("secret key id %q must not contain ':'", id)
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("secret key %q must be 32 bytes, got %d", id, len(key))
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		c.keys[id] = aead
+	}
+	return c, nil
+}
+
+// NewSecretCipherFromConfig creates a cipher from base64 encoded keys in config
+func NewSecretCipherFromConfig(cfg *config.SecretsConfig) (*SecretCipher, error) {
+	keys := make(map[string][]byte, len(cfg.PreviousKeys)+1)
+	for id, encoded := range cfg.PreviousKeys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secret key %q: %w", id, err)
+		}
+		keys[id] = key
+	}
+
+	key, err := base64.StdEncoding.DecodeString(cfg.Key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secret key %q: %w", cfg.KeyID, err)
+	}
+	keys[cfg.KeyID] = key
+
+	return NewSecretCipher(cfg.KeyID, keys)
+}
+
+// Encrypt encrypts plaintext with the primary key
+func (c *SecretCipher) Encrypt(plaintext string) (string, error) {
+	aead := c.keys[c.primaryID]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	// The key ID is authenticated so a ciphertext cannot be replayed under another key
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(c.primaryID))
+	return encryptedPrefix + c.primaryID + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt reverses Encrypt. Values that were never encrypted are returned unchanged.
+func (c *SecretCipher) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+
+	keyID, encoded, ok := strings.Cut(strings.TrimPrefix(value, encryptedPrefix), ":")
+	if !ok {
+		return "", ErrInvalidCiphertext
+	}
+	aead, ok := c.keys[keyID]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownSecretKey, keyID)
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", ErrInvalidCiphertext
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(keyID))
+	if err != nil {
+		return "", ErrInvalidCiphertext
+	}
+	return string(plaintext), nil
+}
+
+// NeedsReencryption reports whether value is plaintext or encrypted with a non-primary key
+func (c *SecretCipher) NeedsReencryption(value string) bool {
+	return !strings.HasPrefix(value, encryptedPrefix+c.primaryID+":")
+}
+
+// IsEncrypted reports whether value was produced by a SecretCipher
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, encryptedPrefix)
+}
@@ -0,0 +1,83 @@
+package security
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, 32)
+}
+
+// Test values round-trip and ciphertexts are not deterministic
+func TestSecretCipher_RoundTrip(t *testing.T) {
+	c, err := NewSecretCipher("k1", map[string][]byte{"k1": testKey(1)})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	first, _ := c.Encrypt("s3cret")
+	second, _ := c.Encrypt("s3cret")
+	if first == second {
+		t.Error("Expected different ciphertexts for the same plaintext")
+	}
+	if !IsEncrypted(first) {
+		t.Errorf("Expected %q to be recognized as encrypted", first)
+	}
+
+	plaintext, err := c.Decrypt(first)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if plaintext != "s3cret" {
+		t.Errorf("Expected 's3cret', got %q", plaintext)
+	}
+}
+
+// Test legacy plaintext passes through Decrypt unchanged
+func TestSecretCipher_LegacyPlaintext(t *testing.T) {
+	c, _ := NewSecretCipher("k1", map[string][]byte{"k1": testKey(1)})
+
+	plaintext, err := c.Decrypt("plain-api-key")
+	if err != nil || plaintext != "plain-api-key" {
+		t.Errorf("Expected plaintext passthrough, got %q (%v)", plaintext, err)
+	}
+	if !c.NeedsReencryption("plain-api-key") {
+		t.Error("Expected plaintext to need re-encryption")
+	}
+}
+
+// Test a retired key still decrypts after rotation and is flagged for re-encryption
+func TestSecretCipher_KeyRotation(t *testing.T) {
+	old, _ := NewSecretCipher("k1", map[string][]byte{"k1": testKey(1)})
+	ciphertext, _ := old.Encrypt("s3cret")
+
+	rotated, err := NewSecretCipher("k2", map[string][]byte{"k1": testKey(1), "k2": testKey(2)})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !rotated.NeedsReencryption(ciphertext) {
+		t.Error("Expected ciphertext from retired key to need re-encryption")
+	}
+	plaintext, err := rotated.Decrypt(ciphertext)
+	if err != nil || plaintext != "s3cret" {
+		t.Errorf("Expected retired key to decrypt, got %q (%v)", plaintext, err)
+	}
+
+	withoutOld, _ := NewSecretCipher("k2", map[string][]byte{"k2": testKey(2)})
+	if _, err := withoutOld.Decrypt(ciphertext); !errors.Is(err, ErrUnknownSecretKey) {
+		t.Errorf("Expected ErrUnknownSecretKey, got %v", err)
+	}
+}
+
+// Test tampered ciphertexts are rejected
+func TestSecretCipher_Tampered(t *testing.T) {
+	c, _ := NewSecretCipher("k1", map[string][]byte{"k1": testKey(1)})
+	ciphertext, _ := c.Encrypt("s3cret")
+
+	tampered := ciphertext[:len(ciphertext)-4] + "AAAA"
+	if _, err := c.Decrypt(tampered); !errors.Is(err, ErrInvalidCiphertext) {
+		t.Errorf("Expected ErrInvalidCiphertext, got %v", err)
+	}
+}
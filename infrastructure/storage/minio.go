@@ -6,6 +6,7 @@ import (
 	"io"
 	"time"
 
+	"portal-data-backend/infrastructure/config"
 	"portal-data-backend/internal/file/domain"
 
 	"github.com/minio/minio-go/v7"
@@ -15,40 +16,51 @@ import (
 type minioStorage struct {
 	client *minio.Client
 	bucket string
+	// timeout bounds each call, uploadTimeout uploads and downloads
+	timeout       time.Duration
+	uploadTimeout time.Duration
 }
 
-func NewMinIOStorage(endpoint, accessKey, secretKey, bucket string, useSSL bool) (domain.StorageService, error) {
-	client, err := minio.New(endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(accessKey, secretKey, ""),
-		Secure: useSSL,
+// NewMinIOStorage connects to the bucket of cfg, creating it when missing.
+// Calls end at the deadline of their context or at the timeouts of cfg,
+// whichever comes first.
+func NewMinIOStorage(cfg *config.MinIOConfig) (domain.StorageService, error) {
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create minio client: %w", err)
 	}
 
 	// Create bucket if it doesn't exist
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
 	defer cancel()
 
-	exists, err := client.BucketExists(ctx, bucket)
+	exists, err := client.BucketExists(ctx, cfg.Bucket)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check bucket existence: %w", err)
 	}
 
 	if !exists {
-		err = client.MakeBucket(ctx, bucket, minio.MakeBucketOptions{})
+		err = client.MakeBucket(ctx, cfg.Bucket, minio.MakeBucketOptions{})
 		if err != nil {
 			return nil, fmt.Errorf("failed to create bucket: %w", err)
 		}
 	}
 
 	return &minioStorage{
-		client: client,
-		bucket: bucket,
+		client:        client,
+		bucket:        cfg.Bucket,
+		timeout:       cfg.Timeout,
+		uploadTimeout: cfg.UploadTimeout,
 	}, nil
 }
 
 func (s *minioStorage) Upload(ctx context.Context, fileName string, reader io.Reader, contentType string, path string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.uploadTimeout)
+	defer cancel()
+
 	_, err := s.client.PutObject(ctx, s.bucket, path, reader, -1, minio.PutObjectOptions{
 		ContentType: contentType,
 	})
@@ -59,6 +71,9 @@ func (s *minioStorage) Upload(ctx context.Context, fileName string, reader io.Re
 }
 
 func (s *minioStorage) Delete(ctx context.Context, path string) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
 	err := s.client.RemoveObject(ctx, s.bucket, path, minio.RemoveObjectOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to delete file: %w", err)
@@ -66,10 +81,53 @@ func (s *minioStorage) Delete(ctx context.Context, path string) error {
 	return nil
 }
 
+func (s *minioStorage) Open(ctx context.Context, path string) (io.ReadCloser, error) {
+	// Downloads stream the whole file like uploads do
+	ctx, cancel := context.WithTimeout(ctx, s.uploadTimeout)
+
+	object, err := s.client.GetObject(ctx, s.bucket, path, minio.GetObjectOptions{})
+	if err == nil {
+		_, err = object.Stat()
+	}
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	return &cancelReader{ReadCloser: object, cancel: cancel}, nil
+}
+
+// cancelReader cancels the context of a download once it is closed
+type cancelReader struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (r *cancelReader) Close() error {
+	defer r.cancel()
+	return r.ReadCloser.Close()
+}
+
 func (s *minioStorage) GetURL(ctx context.Context, path string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
 	presignedURL, err := s.client.PresignedGetObject(ctx, s.bucket, path, time.Hour*24, nil)
 	if err != nil {
 		return "", fmt.Errorf("failed to get presigned URL: %w", err)
 	}
 	return presignedURL.String(), nil
 }
+
+func (s *minioStorage) Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	exists, err := s.client.BucketExists(ctx, s.bucket)
+	if err != nil {
+		return fmt.Errorf("failed to access bucket: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("bucket %s does not exist", s.bucket)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+//go:build integration
+
+package storage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"portal-data-backend/internal/testenv"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(testenv.Main(m))
+}
+
+// Test uploaded files are served from their presigned URL until deleted
+func TestMinIOStorage(t *testing.T) {
+	storage, err := NewMinIOStorage(testenv.MinIO(t))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	ctx := context.Background()
+
+	if err := storage.Ping(ctx); err != nil {
+		t.Fatalf("Expected the bucket to be created, got %v", err)
+	}
+
+	path, err := storage.Upload(ctx, "penduduk.csv", strings.NewReader("kecamatan,jumlah\nCoblong,131000\n"), "text/csv", "datasets/penduduk.csv")
+	if err != nil {
+		t.Fatalf("Expected no error uploading, got %v", err)
+	}
+	url, err := storage.GetURL(ctx, path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if body := get(t, url); body != "kecamatan,jumlah\nCoblong,131000\n" {
+		t.Errorf("Expected the uploaded file, got %q", body)
+	}
+
+	if err := storage.Delete(ctx, path); err != nil {
+		t.Fatalf("Expected no error deleting, got %v", err)
+	}
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 after deleting, got %d", resp.StatusCode)
+	}
+}
+
+func get(t *testing.T, url string) string {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("Failed to get %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", url, err)
+	}
+	return string(body)
+}
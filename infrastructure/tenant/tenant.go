@@ -0,0 +1,160 @@
+// Package tenant tells which portal a request is for when one deployment
+// hosts several. A tenant is resolved from the request hostname or header
+// and carried in the context; repositories scope their queries to it and
+// caches keep its values apart. Without a tenant in the context nothing is
+// scoped, which is how single portal deployments run.
+package tenant
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"portal-data-backend/pkg/errors"
+)
+
+// DefaultID is the tenant existing records belong to
+const DefaultID = "default"
+
+// Tenant statuses; only active tenants are served
+const (
+	StatusActive   = "active"
+	StatusDisabled = "disabled"
+)
+
+// Tenant is a portal hosted by the deployment
+type Tenant struct {
+	// ID is a short lowercase code like "jabar", also used in cache keys
+	ID     string   `json:"id"`
+	Name   string   `json:"name"`
+	Hosts  []string `json:"hosts"`
+	Status string   `json:"status"`
+	// Branding is how the frontend presents the portal
+	Branding Branding `json:"branding"`
+}
+
+// Branding is the look of a portal
+type Branding struct {
+	Title          string `json:"title,omitempty"`
+	LogoURL        string `json:"logo_url,omitempty"`
+	FaviconURL     string `json:"favicon_url,omitempty"`
+	PrimaryColor   string `json:"primary_color,omitempty"`
+	SecondaryColor string `json:"secondary_color,omitempty"`
+}
+
+var idPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// ValidID reports whether id can name a tenant. IDs are embedded in queries
+// and cache keys, so nothing else is accepted.
+func ValidID(id string) bool {
+	return idPattern.MatchString(id)
+}
+
+type contextKey struct{}
+
+// WithTenant returns a context scoped to t
+func WithTenant(ctx context.Context, t *Tenant) context.Context {
+	return context.WithValue(ctx, contextKey{}, t)
+}
+
+// FromContext returns the tenant ctx is scoped to, or nil when it is not
+func FromContext(ctx context.Context) *Tenant {
+	t, _ := ctx.Value(contextKey{}).(*Tenant)
+	return t
+}
+
+// ID returns the ID of the tenant ctx is scoped to, or "" when it is not
+func ID(ctx context.Context) string {
+	if t := FromContext(ctx); t != nil {
+		return t.ID
+	}
+	return ""
+}
+
+// Store lists the tenants resolvers look up
+type Store interface {
+	// ListActive returns the active tenants
+	ListActive(ctx context.Context) ([]*Tenant, error)
+}
+
+// Resolver looks tenants up by ID or hostname. Tenants are few and rarely
+// change, so they are all loaded at once and kept for ttl; Invalidate drops
+// them when they change.
+type Resolver struct {
+	store Store
+	ttl   time.Duration
+
+	mu       sync.Mutex
+	byID     map[string]*Tenant
+	byHost   map[string]*Tenant
+	loadedAt time.Time
+}
+
+// NewResolver creates a resolver looking tenants up in store
+func NewResolver(store Store, ttl time.Duration) *Resolver {
+	return &Resolver{store: store, ttl: ttl}
+}
+
+// Resolve returns the tenant id names, or when id is empty the tenant host
+// belongs to, falling back to the tenant fallback names. It returns
+// ErrNotFound when no active tenant matches.
+func (r *Resolver) Resolve(ctx context.Context, id, host, fallback string) (*Tenant, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.byID == nil || time.Since(r.loadedAt) > r.ttl {
+		if err := r.load(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if id != "" {
+		if t, ok := r.byID[id]; ok {
+			return t, nil
+		}
+		return nil, errors.ErrNotFound
+	}
+	if t, ok := r.byHost[normalizeHost(host)]; ok {
+		return t, nil
+	}
+	if t, ok := r.byID[fallback]; ok {
+		return t, nil
+	}
+	return nil, errors.ErrNotFound
+}
+
+// Invalidate makes the next lookup load the tenants again
+func (r *Resolver) Invalidate() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.byID = nil
+}
+
+func (r *Resolver) load(ctx context.Context) error {
+	tenants, err := r.store.ListActive(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.byID = make(map[string]*Tenant, len(tenants))
+	r.byHost = make(map[string]*Tenant)
+	for _, t := range tenants {
+		r.byID[t.ID] = t
+		for _, host := range t.Hosts {
+			r.byHost[normalizeHost(host)] = t
+		}
+	}
+	r.loadedAt = time.Now()
+	return nil
+}
+
+// normalizeHost lowercases host and strips its port
+func normalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if i := strings.LastIndexByte(host, ':'); i >= 0 && !strings.Contains(host[i:], "]") {
+		host = host[:i]
+	}
+	return host
+}
@@ -0,0 +1,62 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	pkgErrors "portal-data-backend/pkg/errors"
+)
+
+type stubStore struct {
+	tenants []*Tenant
+	loads   int
+}
+
+func (s *stubStore) ListActive(ctx context.Context) ([]*Tenant, error) {
+	s.loads++
+	return s.tenants, nil
+}
+
+// Test tenants are resolved by ID, then hostname, then the fallback
+func TestResolver_Resolve(t *testing.T) {
+	store := &stubStore{tenants: []*Tenant{
+		{ID: DefaultID, Hosts: []string{"data.example.id"}},
+		{ID: "jabar", Hosts: []string{"Data.Jabar.example.id"}},
+	}}
+	resolver := NewResolver(store, time.Minute)
+	ctx := context.Background()
+
+	cases := []struct {
+		id, host, want string
+	}{
+		{"jabar", "data.example.id", "jabar"},
+		{"", "data.jabar.example.id:8080", "jabar"},
+		{"", "unknown.example.id", DefaultID},
+	}
+	for _, c := range cases {
+		got, err := resolver.Resolve(ctx, c.id, c.host, DefaultID)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if got.ID != c.want {
+			t.Errorf("Expected tenant %s for %q at %q, got %s", c.want, c.id, c.host, got.ID)
+		}
+	}
+
+	if _, err := resolver.Resolve(ctx, "jatim", "", DefaultID); !errors.Is(err, pkgErrors.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an unknown tenant, got %v", err)
+	}
+	if store.loads != 1 {
+		t.Errorf("Expected tenants to be loaded once, got %d loads", store.loads)
+	}
+
+	resolver.Invalidate()
+	if _, err := resolver.Resolve(ctx, "jabar", "", DefaultID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if store.loads != 2 {
+		t.Errorf("Expected tenants to be loaded again after Invalidate, got %d loads", store.loads)
+	}
+}
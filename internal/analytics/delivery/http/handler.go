@@ -1,13 +1,14 @@
 package http
 
 import (
-	"errors"
 	"net/http"
 	"strconv"
 
+	"portal-data-backend/internal/analytics/domain"
 	"portal-data-backend/internal/analytics/usecase"
+	"portal-data-backend/infrastructure/http/middleware"
+	"portal-data-backend/infrastructure/http/problem"
 	"portal-data-backend/infrastructure/http/response"
-	pkgErrors "portal-data-backend/pkg/errors"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -25,7 +26,7 @@ func NewHandler(analyticsUsecase usecase.Usecase) *Handler {
 func (h *Handler) GetDashboard(w http.ResponseWriter, r *http.Request) {
 	dashboard, err := h.analyticsUsecase.GetDashboard(r.Context())
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -35,7 +36,7 @@ func (h *Handler) GetDashboard(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) GetDatasetStats(w http.ResponseWriter, r *http.Request) {
 	stats, err := h.analyticsUsecase.GetDatasetStats(r.Context())
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -45,7 +46,7 @@ func (h *Handler) GetDatasetStats(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) GetOrganizationStats(w http.ResponseWriter, r *http.Request) {
 	stats, err := h.analyticsUsecase.GetOrganizationStats(r.Context())
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -55,7 +56,7 @@ func (h *Handler) GetOrganizationStats(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) GetUserStats(w http.ResponseWriter, r *http.Request) {
 	stats, err := h.analyticsUsecase.GetUserStats(r.Context())
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -67,7 +68,7 @@ func (h *Handler) GetPopularDatasets(w http.ResponseWriter, r *http.Request) {
 
 	datasets, err := h.analyticsUsecase.GetPopularDatasets(r.Context(), limit)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -79,7 +80,7 @@ func (h *Handler) GetPopularTags(w http.ResponseWriter, r *http.Request) {
 
 	tags, err := h.analyticsUsecase.GetPopularTags(r.Context(), limit)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -92,24 +93,73 @@ func (h *Handler) GetDatasetTrend(w http.ResponseWriter, r *http.Request) {
 
 	trend, err := h.analyticsUsecase.GetDatasetTrend(r.Context(), period, limit)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
 	response.OK(w, response.CodeSuccess, "Dataset trend retrieved successfully", trend)
 }
 
-func (h *Handler) handleError(w http.ResponseWriter, err error) {
-	if err == nil {
+// GetFeedbackReport summarizes feedback between start_date and end_date
+// (YYYY-MM-DD, inclusive) per period: daily, weekly or monthly
+func (h *Handler) GetFeedbackReport(w http.ResponseWriter, r *http.Request) {
+	req := parseStatsRequest(r)
+	limit := parseIntQuery(r, "limit", 10)
+
+	report, err := h.analyticsUsecase.GetFeedbackReport(r.Context(), req, limit)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Feedback report retrieved successfully", report)
+}
+
+// GetTrafficReport breaks the views and downloads between start_date and
+// end_date (YYYY-MM-DD, inclusive) down by channel, per period
+func (h *Handler) GetTrafficReport(w http.ResponseWriter, r *http.Request) {
+	report, err := h.analyticsUsecase.GetTrafficReport(r.Context(), parseStatsRequest(r))
+	if err != nil {
+		h.handleError(w, r, err)
 		return
 	}
 
-	switch {
-	case errors.Is(err, pkgErrors.ErrNotFound):
-		response.NotFound(w, response.CodeNotFound, "Resource not found", nil)
-	default:
-		response.InternalError(w, response.CodeInternalServerError, "Internal server error", nil)
+	response.OK(w, response.CodeSuccess, "Traffic report retrieved successfully", report)
+}
+
+// GetOverview summarizes the work waiting on operators for the ops dashboard
+func (h *Handler) GetOverview(w http.ResponseWriter, r *http.Request) {
+	overview, err := h.analyticsUsecase.GetOverview(r.Context())
+	if err != nil {
+		h.handleError(w, r, err)
+		return
 	}
+
+	response.OK(w, response.CodeSuccess, "Overview retrieved successfully", overview)
+}
+
+// GetPrivacyManifest describes the usage data the portal keeps about its
+// visitors
+func (h *Handler) GetPrivacyManifest(w http.ResponseWriter, r *http.Request) {
+	response.OK(w, response.CodeSuccess, "Privacy manifest retrieved successfully", h.analyticsUsecase.GetPrivacyManifest(r.Context()))
+}
+
+func (h *Handler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	problem.Write(w, r, err)
+}
+
+// parseStatsRequest reads the period and dates of a report from the query
+func parseStatsRequest(r *http.Request) *domain.GetStatsRequest {
+	req := &domain.GetStatsRequest{
+		Period: r.URL.Query().Get("period"),
+	}
+	if startDate := r.URL.Query().Get("start_date"); startDate != "" {
+		req.StartDate = &startDate
+	}
+	if endDate := r.URL.Query().Get("end_date"); endDate != "" {
+		req.EndDate = &endDate
+	}
+	return req
 }
 
 func parseIntQuery(r *http.Request, key string, defaultValue int) int {
@@ -121,7 +171,12 @@ func parseIntQuery(r *http.Request, key string, defaultValue int) int {
 	return defaultValue
 }
 
-func RegisterRoutes(r chi.Router, handler *Handler) {
+// RegisterRoutes registers analytics routes. The feedback and traffic
+// reports require authentication and the overview one of adminRoles; the other reports and
+// the privacy manifest are public.
+func RegisterRoutes(r chi.Router, handler *Handler, auth func(http.Handler) http.Handler, adminRoles []string) {
+	r.Get("/privacy", handler.GetPrivacyManifest)
+
 	r.Route("/analytics", func(r chi.Router) {
 		r.Get("/dashboard", handler.GetDashboard)
 		r.Get("/stats/datasets", handler.GetDatasetStats)
@@ -130,5 +185,17 @@ func RegisterRoutes(r chi.Router, handler *Handler) {
 		r.Get("/popular/datasets", handler.GetPopularDatasets)
 		r.Get("/popular/tags", handler.GetPopularTags)
 		r.Get("/trend/datasets", handler.GetDatasetTrend)
+
+		r.Group(func(r chi.Router) {
+			r.Use(auth)
+			r.Get("/feedback", handler.GetFeedbackReport)
+			r.Get("/traffic", handler.GetTrafficReport)
+		})
+	})
+
+	r.Route("/admin/overview", func(r chi.Router) {
+		r.Use(auth)
+		r.Use(middleware.RequireRole(adminRoles...))
+		r.Get("/", handler.GetOverview)
 	})
 }
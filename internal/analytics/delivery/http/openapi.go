@@ -0,0 +1,30 @@
+package http
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	"portal-data-backend/internal/analytics/domain"
+)
+
+// Describe adds the analytics routes to the API specification
+func Describe(spec *openapi.Spec) {
+	api := spec.Tag("analytics", "Portal statistics")
+	api.Get("/analytics/dashboard", "Get dashboard").Public().Returns(http.StatusOK, domain.DashboardResponse{})
+	api.Get("/analytics/stats/datasets", "Get dataset statistics").Public().Returns(http.StatusOK, domain.DatasetStats{})
+	api.Get("/analytics/stats/organizations", "Get organization statistics").Public().Returns(http.StatusOK, domain.OrganizationStats{})
+	api.Get("/analytics/stats/users", "Get user statistics").Public().Returns(http.StatusOK, domain.UserStats{})
+	api.Get("/analytics/popular/datasets", "List popular datasets").Public().IntParam("limit", false).Returns(http.StatusOK, []domain.PopularDataset{})
+	api.Get("/analytics/popular/tags", "List popular tags").Public().IntParam("limit", false).Returns(http.StatusOK, []domain.TagStats{})
+	api.Get("/analytics/trend/datasets", "Get dataset trend").Public().
+		Param("period", false).IntParam("limit", false).
+		Returns(http.StatusOK, []domain.TimeSeriesData{})
+	api.Get("/analytics/feedback", "Get feedback report").
+		Query(domain.GetStatsRequest{}).IntParam("limit", false).
+		Returns(http.StatusOK, domain.FeedbackReport{})
+	api.Get("/analytics/traffic", "Get the traffic report by channel").
+		Query(domain.GetStatsRequest{}).
+		Returns(http.StatusOK, domain.TrafficReport{})
+	api.Get("/privacy", "Get the privacy manifest").Public().Returns(http.StatusOK, domain.PrivacyManifest{})
+	api.Get("/admin/overview", "Get the overview of the ops dashboard").Returns(http.StatusOK, domain.Overview{})
+}
@@ -59,6 +59,9 @@ type DashboardResponse struct {
 	PopularDatasets  []PopularDataset    `json:"popular_datasets"`
 	PopularTags      []TagStats          `json:"popular_tags"`
 	DatasetTrend     []TimeSeriesData    `json:"dataset_trend"`
+	// Traffic breaks the views and downloads of the last 30 days down by
+	// channel
+	Traffic *TrafficReport `json:"traffic"`
 }
 
 // GetStatsRequest represents query parameters for stats
@@ -67,3 +70,163 @@ type GetStatsRequest struct {
 	EndDate   *string `json:"end_date,omitempty"`
 	Period    string  `json:"period,omitempty"` // daily, weekly, monthly
 }
+
+// FeedbackVolume is the number of feedback of a category and status received
+// in one period
+type FeedbackVolume struct {
+	Date     string `db:"date" json:"date"`
+	Category string `db:"category" json:"category"`
+	Status   string `db:"status" json:"status"`
+	Count    int64  `db:"count" json:"count"`
+}
+
+// FeedbackCount is the number of feedback with one category or status
+type FeedbackCount struct {
+	Key   string `json:"key"`
+	Count int64  `json:"count"`
+}
+
+// FeedbackDataset is a dataset ranked by the feedback it received
+type FeedbackDataset struct {
+	DatasetID     string  `db:"dataset_id" json:"dataset_id"`
+	Name          string  `db:"name" json:"name"`
+	Count         int64   `db:"count" json:"count"`
+	AverageRating float64 `db:"average_rating" json:"average_rating"`
+}
+
+// FeedbackResolution summarizes the feedback resolved in a period
+type FeedbackResolution struct {
+	ResolvedCount          int64    `db:"resolved_count" json:"resolved_count"`
+	AverageResolutionHours *float64 `db:"average_resolution_hours" json:"average_resolution_hours"` // nil when nothing was resolved
+}
+
+// FeedbackReport summarizes the feedback received from StartDate up to and
+// including EndDate, for the periodic review of data governance
+type FeedbackReport struct {
+	StartDate   string              `json:"start_date"`
+	EndDate     string              `json:"end_date"`
+	Period      string              `json:"period"`
+	Total       int64               `json:"total"`
+	ByCategory  []FeedbackCount     `json:"by_category"`
+	ByStatus    []FeedbackCount     `json:"by_status"`
+	Volume      []FeedbackVolume    `json:"volume"`
+	TopDatasets []FeedbackDataset   `json:"top_datasets"`
+	Resolution  *FeedbackResolution `json:"resolution"`
+}
+
+// Traffic metrics, counted per record, day and channel
+const (
+	MetricView     = "view"
+	MetricDownload = "download"
+)
+
+// TrafficCounter counts one more Metric of a record on Day from Channel:
+// portal, api or harvester
+type TrafficCounter struct {
+	Day        time.Time `db:"day"`
+	Channel    string    `db:"channel"`
+	EntityType string    `db:"entity_type"`
+	EntityID   string    `db:"entity_id"`
+	Metric     string    `db:"metric"`
+}
+
+// TrafficVolume is the number of views and downloads from a channel in one
+// period
+type TrafficVolume struct {
+	Date      string `db:"date" json:"date"`
+	Channel   string `db:"channel" json:"channel"`
+	Views     int64  `db:"views" json:"views"`
+	Downloads int64  `db:"downloads" json:"downloads"`
+}
+
+// ChannelTraffic is the number of views and downloads from a channel
+type ChannelTraffic struct {
+	Channel   string `json:"channel"`
+	Views     int64  `json:"views"`
+	Downloads int64  `json:"downloads"`
+}
+
+// TrafficReport breaks the views and downloads from StartDate up to and
+// including EndDate down by channel, to tell people from machines
+type TrafficReport struct {
+	StartDate string           `json:"start_date"`
+	EndDate   string           `json:"end_date"`
+	Period    string           `json:"period"`
+	ByChannel []ChannelTraffic `json:"by_channel"`
+	Volume    []TrafficVolume  `json:"volume"`
+}
+
+// Overview summarizes the work waiting on the operators of the portal, for
+// the ops dashboard
+type Overview struct {
+	Reviews        ReviewCounts      `json:"reviews"`
+	Tickets        TicketCounts      `json:"tickets"`
+	Integrations   IntegrationCounts `json:"integrations"`
+	Jobs           JobCounts         `json:"jobs"`
+	Storage        StorageUsage      `json:"storage"`
+	RecentActivity []Activity        `json:"recent_activity"`
+	GeneratedAt    time.Time         `json:"generated_at"`
+}
+
+// ReviewCounts counts the records waiting for a moderator
+type ReviewCounts struct {
+	PendingDatasets int64 `db:"pending_datasets" json:"pending_datasets"` // validation pending
+	PendingFeedback int64 `db:"pending_feedback" json:"pending_feedback"` // moderation pending
+}
+
+// TicketCounts counts the desk tickets not resolved yet
+type TicketCounts struct {
+	Open        int64 `db:"open" json:"open"`
+	InProgress  int64 `db:"in_progress" json:"in_progress"`
+	SLABreached int64 `db:"sla_breached" json:"sla_breached"` // open or in progress past their SLA
+}
+
+// IntegrationCounts counts the integrations needing attention. Failed runs
+// and dead deliveries are those of the last 24 hours.
+type IntegrationCounts struct {
+	Errored        int64 `db:"errored" json:"errored"`
+	FailedRuns     int64 `db:"failed_runs" json:"failed_runs"`
+	DeadDeliveries int64 `db:"dead_deliveries" json:"dead_deliveries"`
+}
+
+// JobCounts is the depth of the queues of background work
+type JobCounts struct {
+	RunningRuns       int64 `db:"running_runs" json:"running_runs"`             // harvest runs in progress
+	PendingDeliveries int64 `db:"pending_deliveries" json:"pending_deliveries"` // webhook deliveries due or retrying
+	PendingEvents     int64 `db:"pending_events" json:"pending_events"`         // outbox events not relayed yet
+}
+
+// StorageUsage is the size of the uploaded files kept
+type StorageUsage struct {
+	Files int64 `db:"files" json:"files"`
+	Bytes int64 `db:"bytes" json:"bytes"`
+}
+
+// Activity is a change recorded in the audit log
+type Activity struct {
+	ID         string    `db:"id" json:"id"`
+	ActorEmail string    `db:"actor_email" json:"actor_email"`
+	EntityType string    `db:"entity_type" json:"entity_type"`
+	EntityID   string    `db:"entity_id" json:"entity_id"`
+	Action     string    `db:"action" json:"action"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+}
+
+// PrivacyManifest describes the usage data the portal keeps about its
+// visitors, for deployments under strict privacy rules to point to
+type PrivacyManifest struct {
+	Mode               string          `json:"mode"` // standard or strict
+	Cookies            bool            `json:"cookies"`
+	VisitorIdentifiers bool            `json:"visitor_identifiers"` // IP addresses, user agents or hashes of them
+	HonorsDoNotTrack   bool            `json:"honors_do_not_track"` // DNT and Sec-GPC headers
+	LogsClientIP       bool            `json:"logs_client_ip"`      // in request logs
+	Collected          []CollectedData `json:"collected"`
+	PolicyURL          string          `json:"policy_url,omitempty"`
+}
+
+// CollectedData is a kind of usage data the portal keeps
+type CollectedData struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Storage     string `json:"storage"` // aggregate_counter
+}
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"time"
 )
 
 type Repository interface {
@@ -11,4 +12,24 @@ type Repository interface {
 	GetPopularDatasets(ctx context.Context, limit int) ([]PopularDataset, error)
 	GetPopularTags(ctx context.Context, limit int) ([]TagStats, error)
 	GetDatasetTrend(ctx context.Context, period string, limit int) ([]TimeSeriesData, error)
+
+	// Feedback reports cover the feedback created from start until before end.
+	// Rejected and unconfirmed anonymous feedback is left out.
+	GetFeedbackVolume(ctx context.Context, period string, start, end time.Time) ([]FeedbackVolume, error)
+	GetTopFeedbackDatasets(ctx context.Context, start, end time.Time, limit int) ([]FeedbackDataset, error)
+	GetFeedbackResolution(ctx context.Context, start, end time.Time) (*FeedbackResolution, error)
+
+	// Traffic is counted per record, day and channel. Volumes cover the
+	// days from start until before end.
+	IncrementTraffic(ctx context.Context, counter *TrafficCounter) error
+	GetTrafficVolume(ctx context.Context, period string, start, end time.Time) ([]TrafficVolume, error)
+
+	// The overview counts what is waiting across modules. Failed integration
+	// work is counted from since on.
+	GetReviewCounts(ctx context.Context) (*ReviewCounts, error)
+	GetTicketCounts(ctx context.Context) (*TicketCounts, error)
+	GetIntegrationCounts(ctx context.Context, since time.Time) (*IntegrationCounts, error)
+	GetJobCounts(ctx context.Context) (*JobCounts, error)
+	GetStorageUsage(ctx context.Context) (*StorageUsage, error)
+	GetRecentActivity(ctx context.Context, limit int) ([]Activity, error)
 }
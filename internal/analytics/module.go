@@ -0,0 +1,51 @@
+// Package analytics is the module reporting usage and content statistics.
+package analytics
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	delivery "portal-data-backend/internal/analytics/delivery/http"
+	"portal-data-backend/internal/analytics/repository"
+	"portal-data-backend/internal/analytics/usecase"
+	"portal-data-backend/internal/app"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Module serves the analytics reports and the overview of the ops
+// dashboard
+type Module struct {
+	handler    *delivery.Handler
+	adminRoles []string
+}
+
+// Name implements app.Module
+func (m *Module) Name() string {
+	return "analytics"
+}
+
+// Register implements app.Module
+func (m *Module) Register(deps *app.Deps) error {
+	repo := repository.NewAnalyticsPostgresRepository(deps.DB)
+	analytics := usecase.NewAnalyticsUsecase(
+		repo,
+		deps.Cache.Namespace("analytics", deps.Config.Cache.AnalyticsTTL),
+		deps.Cache.Namespace("overview", deps.Config.Cache.OverviewTTL),
+		deps.Config.Privacy,
+	)
+	deps.Services.Analytics = analytics
+	m.handler = delivery.NewHandler(analytics)
+	m.adminRoles = deps.Config.Audit.AdminRoles
+	return nil
+}
+
+// Routes implements app.Module
+func (m *Module) Routes(r chi.Router, auth func(http.Handler) http.Handler) {
+	delivery.RegisterRoutes(r, m.handler, auth, m.adminRoles)
+}
+
+// Describe implements app.Module
+func (m *Module) Describe(spec *openapi.Spec) {
+	delivery.Describe(spec)
+}
@@ -3,9 +3,12 @@ package repository
 import (
 	"context"
 	"fmt"
+	"time"
 
 	analyticsDomain "portal-data-backend/internal/analytics/domain"
 
+	"portal-data-backend/infrastructure/db"
+
 	"github.com/jmoiron/sqlx"
 )
 
@@ -32,7 +35,7 @@ func (r *analyticsPostgresRepository) GetDatasetStats(ctx context.Context) (*ana
 	`
 
 	var stats analyticsDomain.DatasetStats
-	err := r.db.GetContext(ctx, &stats, query)
+	err := db.Conn(ctx, r.db).GetContext(ctx, &stats, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get dataset stats: %w", err)
 	}
@@ -52,7 +55,7 @@ func (r *analyticsPostgresRepository) GetOrganizationStats(ctx context.Context)
 	`
 
 	var stats analyticsDomain.OrganizationStats
-	err := r.db.GetContext(ctx, &stats, query)
+	err := db.Conn(ctx, r.db).GetContext(ctx, &stats, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get organization stats: %w", err)
 	}
@@ -72,7 +75,7 @@ func (r *analyticsPostgresRepository) GetUserStats(ctx context.Context) (*analyt
 	`
 
 	var stats analyticsDomain.UserStats
-	err := r.db.GetContext(ctx, &stats, query)
+	err := db.Conn(ctx, r.db).GetContext(ctx, &stats, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user stats: %w", err)
 	}
@@ -96,7 +99,7 @@ func (r *analyticsPostgresRepository) GetPopularDatasets(ctx context.Context, li
 	`
 
 	var datasets []analyticsDomain.PopularDataset
-	err := r.db.SelectContext(ctx, &datasets, query, limit)
+	err := db.Conn(ctx, r.db).SelectContext(ctx, &datasets, query, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get popular datasets: %w", err)
 	}
@@ -120,7 +123,7 @@ func (r *analyticsPostgresRepository) GetPopularTags(ctx context.Context, limit
 	`
 
 	var tags []analyticsDomain.TagStats
-	err := r.db.SelectContext(ctx, &tags, query, limit)
+	err := db.Conn(ctx, r.db).SelectContext(ctx, &tags, query, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get popular tags: %w", err)
 	}
@@ -156,10 +159,243 @@ func (r *analyticsPostgresRepository) GetDatasetTrend(ctx context.Context, perio
 	`, interval, limit*2, interval)
 
 	var trend []analyticsDomain.TimeSeriesData
-	err := r.db.SelectContext(ctx, &trend, query, limit)
+	err := db.Conn(ctx, r.db).SelectContext(ctx, &trend, query, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get dataset trend: %w", err)
 	}
 
 	return trend, nil
 }
+
+// reportIntervals maps the periods of reports to DATE_TRUNC fields
+var reportIntervals = map[string]string{
+	"daily":   "day",
+	"weekly":  "week",
+	"monthly": "month",
+}
+
+func (r *analyticsPostgresRepository) GetFeedbackVolume(ctx context.Context, period string, start, end time.Time) ([]analyticsDomain.FeedbackVolume, error) {
+	interval, ok := reportIntervals[period]
+	if !ok {
+		interval = "month"
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			TO_CHAR(DATE_TRUNC('%s', created_at), 'YYYY-MM-DD') as date,
+			category,
+			status,
+			COUNT(*) as count
+		FROM feedbacks
+		WHERE created_at >= $1 AND created_at < $2
+			AND moderation_status = 'approved'
+		GROUP BY 1, category, status
+		ORDER BY date ASC, category ASC, status ASC
+	`, interval)
+
+	volume := []analyticsDomain.FeedbackVolume{}
+	err := db.Conn(ctx, r.db).SelectContext(ctx, &volume, query, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feedback volume: %w", err)
+	}
+
+	return volume, nil
+}
+
+func (r *analyticsPostgresRepository) IncrementTraffic(ctx context.Context, counter *analyticsDomain.TrafficCounter) error {
+	query := `
+		INSERT INTO traffic_counters (day, channel, entity_type, entity_id, metric, count)
+		VALUES (:day, :channel, :entity_type, :entity_id, :metric, 1)
+		ON CONFLICT (day, channel, entity_type, entity_id, metric)
+		DO UPDATE SET count = traffic_counters.count + 1
+	`
+	if _, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, counter); err != nil {
+		return fmt.Errorf("failed to increment traffic counter: %w", err)
+	}
+	return nil
+}
+
+func (r *analyticsPostgresRepository) GetTrafficVolume(ctx context.Context, period string, start, end time.Time) ([]analyticsDomain.TrafficVolume, error) {
+	interval, ok := reportIntervals[period]
+	if !ok {
+		interval = "month"
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			TO_CHAR(DATE_TRUNC('%s', day), 'YYYY-MM-DD') as date,
+			channel,
+			COALESCE(SUM(count) FILTER (WHERE metric = 'view'), 0) as views,
+			COALESCE(SUM(count) FILTER (WHERE metric = 'download'), 0) as downloads
+		FROM traffic_counters
+		WHERE day >= $1 AND day < $2
+		GROUP BY 1, channel
+		ORDER BY date ASC, channel ASC
+	`, interval)
+
+	volume := []analyticsDomain.TrafficVolume{}
+	err := db.Conn(ctx, r.db).SelectContext(ctx, &volume, query, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get traffic volume: %w", err)
+	}
+
+	return volume, nil
+}
+
+func (r *analyticsPostgresRepository) GetTopFeedbackDatasets(ctx context.Context, start, end time.Time, limit int) ([]analyticsDomain.FeedbackDataset, error) {
+	query := `
+		SELECT
+			d.id as dataset_id,
+			d.name,
+			COUNT(*) as count,
+			AVG(f.rating)::float8 as average_rating
+		FROM feedbacks f
+		JOIN datasets d ON d.id = f.dataset_id
+		WHERE f.created_at >= $1 AND f.created_at < $2
+			AND f.moderation_status = 'approved'
+		GROUP BY d.id, d.name
+		ORDER BY count DESC, d.name ASC
+		LIMIT $3
+	`
+
+	datasets := []analyticsDomain.FeedbackDataset{}
+	err := db.Conn(ctx, r.db).SelectContext(ctx, &datasets, query, start, end, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get top feedback datasets: %w", err)
+	}
+
+	return datasets, nil
+}
+
+func (r *analyticsPostgresRepository) GetFeedbackResolution(ctx context.Context, start, end time.Time) (*analyticsDomain.FeedbackResolution, error) {
+	query := `
+		SELECT
+			COUNT(*) as resolved_count,
+			AVG(EXTRACT(EPOCH FROM resolved_at - created_at) / 3600)::float8 as average_resolution_hours
+		FROM feedbacks
+		WHERE created_at >= $1 AND created_at < $2
+			AND moderation_status = 'approved'
+			AND resolved_at IS NOT NULL
+	`
+
+	var resolution analyticsDomain.FeedbackResolution
+	err := db.Conn(ctx, r.db).GetContext(ctx, &resolution, query, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feedback resolution: %w", err)
+	}
+
+	return &resolution, nil
+}
+
+func (r *analyticsPostgresRepository) GetReviewCounts(ctx context.Context) (*analyticsDomain.ReviewCounts, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM datasets
+			 WHERE deleted_at IS NULL AND validation_status = 'pending') as pending_datasets,
+			(SELECT COUNT(*) FROM feedbacks
+			 WHERE moderation_status = 'pending') as pending_feedback
+	`
+
+	var counts analyticsDomain.ReviewCounts
+	err := db.Conn(ctx, r.db).GetContext(ctx, &counts, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count pending reviews: %w", err)
+	}
+
+	return &counts, nil
+}
+
+func (r *analyticsPostgresRepository) GetTicketCounts(ctx context.Context) (*analyticsDomain.TicketCounts, error) {
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE status = 'open') as open,
+			COUNT(*) FILTER (WHERE status = 'in_progress') as in_progress,
+			COUNT(*) FILTER (WHERE sla_breached_at IS NOT NULL) as sla_breached
+		FROM tickets
+		WHERE deleted_at IS NULL AND status IN ('open', 'in_progress')
+	`
+
+	var counts analyticsDomain.TicketCounts
+	err := db.Conn(ctx, r.db).GetContext(ctx, &counts, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count open tickets: %w", err)
+	}
+
+	return &counts, nil
+}
+
+func (r *analyticsPostgresRepository) GetIntegrationCounts(ctx context.Context, since time.Time) (*analyticsDomain.IntegrationCounts, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM integrations
+			 WHERE deleted_at IS NULL AND status = 'error') as errored,
+			(SELECT COUNT(*) FROM integration_runs
+			 WHERE status = 'failed' AND started_at >= $1) as failed_runs,
+			(SELECT COUNT(*) FROM integration_deliveries
+			 WHERE status = 'dead' AND updated_at >= $1) as dead_deliveries
+	`
+
+	var counts analyticsDomain.IntegrationCounts
+	err := db.Conn(ctx, r.db).GetContext(ctx, &counts, query, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count failed integrations: %w", err)
+	}
+
+	return &counts, nil
+}
+
+func (r *analyticsPostgresRepository) GetJobCounts(ctx context.Context) (*analyticsDomain.JobCounts, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM integration_runs
+			 WHERE status = 'running') as running_runs,
+			(SELECT COUNT(*) FROM integration_deliveries
+			 WHERE status IN ('pending', 'failed')) as pending_deliveries,
+			(SELECT COUNT(*) FROM outbox_events
+			 WHERE published_at IS NULL) as pending_events
+	`
+
+	var counts analyticsDomain.JobCounts
+	err := db.Conn(ctx, r.db).GetContext(ctx, &counts, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count queued jobs: %w", err)
+	}
+
+	return &counts, nil
+}
+
+func (r *analyticsPostgresRepository) GetStorageUsage(ctx context.Context) (*analyticsDomain.StorageUsage, error) {
+	query := `
+		SELECT
+			COUNT(*) as files,
+			COALESCE(SUM(size), 0) as bytes
+		FROM files
+		WHERE status <> 'deleted'
+	`
+
+	var usage analyticsDomain.StorageUsage
+	err := db.Conn(ctx, r.db).GetContext(ctx, &usage, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get storage usage: %w", err)
+	}
+
+	return &usage, nil
+}
+
+func (r *analyticsPostgresRepository) GetRecentActivity(ctx context.Context, limit int) ([]analyticsDomain.Activity, error) {
+	query := `
+		SELECT id, actor_email, entity_type, entity_id, action, created_at
+		FROM audit_logs
+		WHERE action <> 'read'
+		ORDER BY created_at DESC
+		LIMIT $1
+	`
+
+	activity := []analyticsDomain.Activity{}
+	err := db.Conn(ctx, r.db).SelectContext(ctx, &activity, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent activity: %w", err)
+	}
+
+	return activity, nil
+}
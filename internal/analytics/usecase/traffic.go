@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"portal-data-backend/internal/analytics/domain"
+	"portal-data-backend/pkg/tracking"
+)
+
+// dashboardTrafficDays is how many days back the dashboard breaks traffic
+// down by channel
+const dashboardTrafficDays = 30
+
+// RecordTraffic counts a view or download of a record from the channel of
+// the request of ctx, unless its client opted out of analytics
+func (u *analyticsUsecase) RecordTraffic(ctx context.Context, entityType, entityID, metric string) error {
+	if tracking.OptedOut(ctx) {
+		return nil
+	}
+	counter := &domain.TrafficCounter{
+		Day:        u.now().UTC().Truncate(24 * time.Hour),
+		Channel:    string(tracking.ChannelOf(ctx)),
+		EntityType: entityType,
+		EntityID:   entityID,
+		Metric:     metric,
+	}
+	if err := u.repo.IncrementTraffic(ctx, counter); err != nil {
+		return fmt.Errorf("failed to record traffic: %w", err)
+	}
+	return nil
+}
+
+func (u *analyticsUsecase) GetTrafficReport(ctx context.Context, req *domain.GetStatsRequest) (*domain.TrafficReport, error) {
+	period, start, end, err := u.reportDates(req)
+	if err != nil {
+		return nil, err
+	}
+	return u.trafficReport(ctx, period, start, end)
+}
+
+// trafficReport breaks the traffic from start up to and including end down
+// by channel, listing every channel even without traffic
+func (u *analyticsUsecase) trafficReport(ctx context.Context, period string, start, end time.Time) (*domain.TrafficReport, error) {
+	volume, err := u.repo.GetTrafficVolume(ctx, period, start, end.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get traffic report: %w", err)
+	}
+
+	byChannel := make([]domain.ChannelTraffic, len(tracking.Channels))
+	for i, channel := range tracking.Channels {
+		byChannel[i].Channel = string(channel)
+		for _, v := range volume {
+			if v.Channel == byChannel[i].Channel {
+				byChannel[i].Views += v.Views
+				byChannel[i].Downloads += v.Downloads
+			}
+		}
+	}
+
+	return &domain.TrafficReport{
+		StartDate: start.Format("2006-01-02"),
+		EndDate:   end.Format("2006-01-02"),
+		Period:    period,
+		ByChannel: byChannel,
+		Volume:    volume,
+	}, nil
+}
@@ -3,8 +3,13 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"sort"
+	"time"
 
+	"portal-data-backend/infrastructure/cache"
+	"portal-data-backend/infrastructure/config"
 	"portal-data-backend/internal/analytics/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
 )
 
 type Usecase interface {
@@ -15,19 +20,63 @@ type Usecase interface {
 	GetPopularDatasets(ctx context.Context, limit int) ([]domain.PopularDataset, error)
 	GetPopularTags(ctx context.Context, limit int) ([]domain.TagStats, error)
 	GetDatasetTrend(ctx context.Context, period string, limit int) ([]domain.TimeSeriesData, error)
+	// GetFeedbackReport summarizes feedback between the dates of req, ranking
+	// at most limit datasets by feedback received
+	GetFeedbackReport(ctx context.Context, req *domain.GetStatsRequest, limit int) (*domain.FeedbackReport, error)
+	// GetTrafficReport breaks the views and downloads between the dates of
+	// req down by channel: portal, api and harvester
+	GetTrafficReport(ctx context.Context, req *domain.GetStatsRequest) (*domain.TrafficReport, error)
+	// RecordTraffic counts a view or download of a record of entityType,
+	// like "publication", from the channel of the request of ctx
+	RecordTraffic(ctx context.Context, entityType, entityID, metric string) error
+	// GetOverview summarizes the work waiting on operators across modules
+	GetOverview(ctx context.Context) (*domain.Overview, error)
+	// GetPrivacyManifest describes the usage data kept about visitors in the
+	// configured privacy mode
+	GetPrivacyManifest(ctx context.Context) *domain.PrivacyManifest
 }
 
+const (
+	// dashboardKey is the cache key of the dashboard
+	dashboardKey = "dashboard"
+	// overviewKey is the cache key of the overview
+	overviewKey = "overview"
+	// overviewActivity is how many audit log entries the overview lists
+	overviewActivity = 10
+	// overviewFailureWindow is how far back the overview counts failed
+	// integration work
+	overviewFailureWindow = 24 * time.Hour
+)
+
 type analyticsUsecase struct {
-	repo domain.Repository
+	repo       domain.Repository
+	dashboards *cache.Namespace
+	overviews  *cache.Namespace
+	privacy    config.PrivacyConfig
+	now        func() time.Time
 }
 
-func NewAnalyticsUsecase(repo domain.Repository) Usecase {
+// NewAnalyticsUsecase creates a new analytics usecase. dashboards caches the
+// dashboard and overviews the overview; both may be nil. privacy is the
+// mode the privacy manifest describes.
+func NewAnalyticsUsecase(repo domain.Repository, dashboards, overviews *cache.Namespace, privacy config.PrivacyConfig) Usecase {
 	return &analyticsUsecase{
-		repo: repo,
+		repo:       repo,
+		dashboards: dashboards,
+		overviews:  overviews,
+		privacy:    privacy,
+		now:        time.Now,
 	}
 }
 
+// GetDashboard returns the dashboard statistics. They are cached until they
+// expire, so the dashboard may lag behind recent changes by up to its TTL.
 func (u *analyticsUsecase) GetDashboard(ctx context.Context) (*domain.DashboardResponse, error) {
+	var cached domain.DashboardResponse
+	if u.dashboards.Get(ctx, dashboardKey, &cached) {
+		return &cached, nil
+	}
+
 	// Get all stats in parallel for better performance
 	type result struct {
 		datasetStats     *domain.DatasetStats
@@ -36,6 +85,7 @@ func (u *analyticsUsecase) GetDashboard(ctx context.Context) (*domain.DashboardR
 		popularDatasets  []domain.PopularDataset
 		popularTags      []domain.TagStats
 		datasetTrend     []domain.TimeSeriesData
+		traffic          *domain.TrafficReport
 		err              error
 	}
 
@@ -83,6 +133,14 @@ func (u *analyticsUsecase) GetDashboard(ctx context.Context) (*domain.DashboardR
 			return
 		}
 
+		// Break the traffic of the last 30 days down by channel
+		today := u.now().UTC().Truncate(24 * time.Hour)
+		r.traffic, r.err = u.trafficReport(ctx, "daily", today.AddDate(0, 0, 1-dashboardTrafficDays), today)
+		if r.err != nil {
+			resultChan <- r
+			return
+		}
+
 		resultChan <- r
 	}()
 
@@ -91,14 +149,64 @@ func (u *analyticsUsecase) GetDashboard(ctx context.Context) (*domain.DashboardR
 		return nil, fmt.Errorf("failed to get dashboard data: %w", r.err)
 	}
 
-	return &domain.DashboardResponse{
+	dashboard := &domain.DashboardResponse{
 		DatasetStats:      r.datasetStats,
 		OrganizationStats: r.organizationStats,
 		UserStats:         r.userStats,
 		PopularDatasets:   r.popularDatasets,
 		PopularTags:       r.popularTags,
 		DatasetTrend:      r.datasetTrend,
-	}, nil
+		Traffic:           r.traffic,
+	}
+	u.dashboards.Set(ctx, dashboardKey, dashboard)
+	return dashboard, nil
+}
+
+// GetOverview returns the overview. It is cached until it expires, so counts
+// may lag behind by up to its TTL; GeneratedAt tells when they were taken.
+func (u *analyticsUsecase) GetOverview(ctx context.Context) (*domain.Overview, error) {
+	var cached domain.Overview
+	if u.overviews.Get(ctx, overviewKey, &cached) {
+		return &cached, nil
+	}
+
+	now := u.now().UTC()
+	reviews, err := u.repo.GetReviewCounts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get overview: %w", err)
+	}
+	tickets, err := u.repo.GetTicketCounts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get overview: %w", err)
+	}
+	integrations, err := u.repo.GetIntegrationCounts(ctx, now.Add(-overviewFailureWindow))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get overview: %w", err)
+	}
+	jobs, err := u.repo.GetJobCounts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get overview: %w", err)
+	}
+	storage, err := u.repo.GetStorageUsage(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get overview: %w", err)
+	}
+	activity, err := u.repo.GetRecentActivity(ctx, overviewActivity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get overview: %w", err)
+	}
+
+	overview := &domain.Overview{
+		Reviews:        *reviews,
+		Tickets:        *tickets,
+		Integrations:   *integrations,
+		Jobs:           *jobs,
+		Storage:        *storage,
+		RecentActivity: activity,
+		GeneratedAt:    now,
+	}
+	u.overviews.Set(ctx, overviewKey, overview)
+	return overview, nil
 }
 
 func (u *analyticsUsecase) GetDatasetStats(ctx context.Context) (*domain.DatasetStats, error) {
@@ -172,3 +280,125 @@ func (u *analyticsUsecase) GetDatasetTrend(ctx context.Context, period string, l
 	}
 	return trend, nil
 }
+
+// reportRanges are the default report lengths per period, ending today
+var reportRanges = map[string]func(end time.Time) time.Time{
+	"daily":   func(end time.Time) time.Time { return end.AddDate(0, 0, -30) },
+	"weekly":  func(end time.Time) time.Time { return end.AddDate(0, 0, -12*7) },
+	"monthly": func(end time.Time) time.Time { return end.AddDate(-1, 0, 0) },
+}
+
+// reportDates returns the period of a report on req, monthly by default,
+// and its first and last day. Without dates the report covers the default
+// range of its period, ending today.
+func (u *analyticsUsecase) reportDates(req *domain.GetStatsRequest) (period string, start, end time.Time, err error) {
+	period = req.Period
+	if period == "" {
+		period = "monthly"
+	}
+	startOf, ok := reportRanges[period]
+	if !ok {
+		return "", start, end, fmt.Errorf("%w: period must be daily, weekly or monthly", pkgErrors.ErrInvalidInput)
+	}
+
+	end = u.now().UTC().Truncate(24 * time.Hour)
+	if req.EndDate != nil {
+		if end, err = time.Parse("2006-01-02", *req.EndDate); err != nil {
+			return "", start, end, fmt.Errorf("%w: end_date must be a date like 2006-01-02", pkgErrors.ErrInvalidInput)
+		}
+	}
+	start = startOf(end).AddDate(0, 0, 1)
+	if req.StartDate != nil {
+		if start, err = time.Parse("2006-01-02", *req.StartDate); err != nil {
+			return "", start, end, fmt.Errorf("%w: start_date must be a date like 2006-01-02", pkgErrors.ErrInvalidInput)
+		}
+	}
+	if start.After(end) {
+		return "", start, end, fmt.Errorf("%w: start_date must not be after end_date", pkgErrors.ErrInvalidInput)
+	}
+	return period, start, end, nil
+}
+
+func (u *analyticsUsecase) GetFeedbackReport(ctx context.Context, req *domain.GetStatsRequest, limit int) (*domain.FeedbackReport, error) {
+	period, start, end, err := u.reportDates(req)
+	if err != nil {
+		return nil, err
+	}
+	if limit < 1 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	// Dates are inclusive, the repository takes an exclusive end
+	until := end.AddDate(0, 0, 1)
+
+	volume, err := u.repo.GetFeedbackVolume(ctx, period, start, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feedback report: %w", err)
+	}
+	topDatasets, err := u.repo.GetTopFeedbackDatasets(ctx, start, until, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feedback report: %w", err)
+	}
+	resolution, err := u.repo.GetFeedbackResolution(ctx, start, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feedback report: %w", err)
+	}
+
+	report := &domain.FeedbackReport{
+		StartDate:   start.Format("2006-01-02"),
+		EndDate:     end.Format("2006-01-02"),
+		Period:      period,
+		Volume:      volume,
+		TopDatasets: topDatasets,
+		Resolution:  resolution,
+	}
+	byCategory := map[string]int64{}
+	byStatus := map[string]int64{}
+	for _, v := range volume {
+		report.Total += v.Count
+		byCategory[v.Category] += v.Count
+		byStatus[v.Status] += v.Count
+	}
+	report.ByCategory = feedbackCounts(byCategory)
+	report.ByStatus = feedbackCounts(byStatus)
+
+	return report, nil
+}
+
+// feedbackCounts orders counts from most to least feedback
+func feedbackCounts(counts map[string]int64) []domain.FeedbackCount {
+	result := make([]domain.FeedbackCount, 0, len(counts))
+	for key, count := range counts {
+		result = append(result, domain.FeedbackCount{Key: key, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
+
+// GetPrivacyManifest describes the usage data kept about visitors. Views and
+// downloads are only ever kept as counters; the strict mode leaves out the
+// requests of clients opting out and the client IP of request logs.
+func (u *analyticsUsecase) GetPrivacyManifest(ctx context.Context) *domain.PrivacyManifest {
+	strict := u.privacy.Strict()
+	return &domain.PrivacyManifest{
+		Mode:               u.privacy.Mode,
+		Cookies:            false,
+		VisitorIdentifiers: false,
+		HonorsDoNotTrack:   strict,
+		LogsClientIP:       !strict,
+		Collected: []domain.CollectedData{
+			{Name: "publication_views", Description: "Number of times each publication was viewed", Storage: "aggregate_counter"},
+			{Name: "publication_downloads", Description: "Number of times each publication was downloaded", Storage: "aggregate_counter"},
+			{Name: "traffic_by_channel", Description: "Number of views and downloads of each publication per day from the portal, API keys or harvesters, told apart by API key and user agent", Storage: "aggregate_counter"},
+		},
+		PolicyURL: u.privacy.PolicyURL,
+	}
+}
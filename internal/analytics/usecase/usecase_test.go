@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"portal-data-backend/infrastructure/cache"
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/internal/analytics/domain"
+	"portal-data-backend/pkg/tracking"
+)
+
+// overviewRepository serves the counts of the overview, counting lookups
+type overviewRepository struct {
+	domain.Repository
+	lookups int
+	since   time.Time
+}
+
+func (r *overviewRepository) GetReviewCounts(ctx context.Context) (*domain.ReviewCounts, error) {
+	r.lookups++
+	return &domain.ReviewCounts{PendingDatasets: 3, PendingFeedback: 1}, nil
+}
+
+func (r *overviewRepository) GetTicketCounts(ctx context.Context) (*domain.TicketCounts, error) {
+	return &domain.TicketCounts{Open: 4, SLABreached: 1}, nil
+}
+
+func (r *overviewRepository) GetIntegrationCounts(ctx context.Context, since time.Time) (*domain.IntegrationCounts, error) {
+	r.since = since
+	return &domain.IntegrationCounts{Errored: 1}, nil
+}
+
+func (r *overviewRepository) GetJobCounts(ctx context.Context) (*domain.JobCounts, error) {
+	return &domain.JobCounts{PendingEvents: 12}, nil
+}
+
+func (r *overviewRepository) GetStorageUsage(ctx context.Context) (*domain.StorageUsage, error) {
+	return &domain.StorageUsage{Files: 2, Bytes: 2048}, nil
+}
+
+func (r *overviewRepository) GetRecentActivity(ctx context.Context, limit int) ([]domain.Activity, error) {
+	return []domain.Activity{{ID: "a1", Action: "create"}}, nil
+}
+
+// Test the overview counts failures of the last day and is served from the
+// cache until it expires
+func TestGetOverview(t *testing.T) {
+	repo := &overviewRepository{}
+	store := cache.NewStore(cache.NewMemory(), "test:")
+	u := NewAnalyticsUsecase(repo, nil, store.Namespace("overview", time.Minute), config.PrivacyConfig{Mode: "standard"}).(*analyticsUsecase)
+	now := time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)
+	u.now = func() time.Time { return now }
+
+	overview, err := u.GetOverview(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if overview.Reviews.PendingDatasets != 3 || overview.Jobs.PendingEvents != 12 || len(overview.RecentActivity) != 1 {
+		t.Errorf("Expected the counts of the repository, got %+v", overview)
+	}
+	if !repo.since.Equal(now.Add(-24 * time.Hour)) {
+		t.Errorf("Expected failures since %v, got %v", now.Add(-24*time.Hour), repo.since)
+	}
+
+	cached, err := u.GetOverview(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if repo.lookups != 1 {
+		t.Errorf("Expected the overview to be cached, got %d lookups", repo.lookups)
+	}
+	if !cached.GeneratedAt.Equal(now) || cached.Storage.Bytes != 2048 {
+		t.Errorf("Expected the cached overview, got %+v", cached)
+	}
+}
+
+// Test the privacy manifest follows the privacy mode
+func TestGetPrivacyManifest(t *testing.T) {
+	standard := NewAnalyticsUsecase(nil, nil, nil, config.PrivacyConfig{Mode: "standard"}).GetPrivacyManifest(context.Background())
+	if standard.HonorsDoNotTrack || !standard.LogsClientIP {
+		t.Errorf("Expected the standard mode to count every request and log client IPs, got %+v", standard)
+	}
+
+	strict := NewAnalyticsUsecase(nil, nil, nil, config.PrivacyConfig{Mode: "strict", PolicyURL: "https://portal.example/privacy"}).GetPrivacyManifest(context.Background())
+	if !strict.HonorsDoNotTrack || strict.LogsClientIP || strict.PolicyURL != "https://portal.example/privacy" {
+		t.Errorf("Expected the strict mode to honor DNT and not log client IPs, got %+v", strict)
+	}
+	for _, data := range strict.Collected {
+		if data.Storage != "aggregate_counter" {
+			t.Errorf("Expected only aggregate counters, got %+v", data)
+		}
+	}
+}
+
+// trafficRepository keeps the traffic counters it is handed
+type trafficRepository struct {
+	domain.Repository
+	counters []domain.TrafficCounter
+	volume   []domain.TrafficVolume
+	end      time.Time
+}
+
+func (r *trafficRepository) IncrementTraffic(ctx context.Context, counter *domain.TrafficCounter) error {
+	r.counters = append(r.counters, *counter)
+	return nil
+}
+
+func (r *trafficRepository) GetTrafficVolume(ctx context.Context, period string, start, end time.Time) ([]domain.TrafficVolume, error) {
+	r.end = end
+	return r.volume, nil
+}
+
+// Test traffic is counted in the channel of the request unless its client
+// opted out, and reported by channel
+func TestTraffic(t *testing.T) {
+	repo := &trafficRepository{volume: []domain.TrafficVolume{
+		{Date: "2026-10-01", Channel: "portal", Views: 10, Downloads: 2},
+		{Date: "2026-10-01", Channel: "harvester", Views: 40},
+		{Date: "2026-10-02", Channel: "portal", Views: 5, Downloads: 1},
+	}}
+	u := NewAnalyticsUsecase(repo, nil, nil, config.PrivacyConfig{Mode: "strict"}).(*analyticsUsecase)
+	now := time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)
+	u.now = func() time.Time { return now }
+
+	ctx := tracking.WithChannel(context.Background(), tracking.ChannelHarvester)
+	if err := u.RecordTraffic(ctx, "publication", "p1", domain.MetricView); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := u.RecordTraffic(tracking.WithOptOut(ctx), "publication", "p1", domain.MetricView); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(repo.counters) != 1 || repo.counters[0].Channel != "harvester" || !repo.counters[0].Day.Equal(now.Truncate(24*time.Hour)) {
+		t.Errorf("Expected one harvester view today, got %+v", repo.counters)
+	}
+
+	endDate := "2026-10-02"
+	report, err := u.GetTrafficReport(context.Background(), &domain.GetStatsRequest{Period: "daily", EndDate: &endDate})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := []domain.ChannelTraffic{{Channel: "portal", Views: 15, Downloads: 3}, {Channel: "api"}, {Channel: "harvester", Views: 40}}
+	for i, channel := range want {
+		if report.ByChannel[i] != channel {
+			t.Errorf("Expected %+v, got %+v", channel, report.ByChannel[i])
+		}
+	}
+	if report.StartDate != "2026-09-03" || !repo.end.Equal(time.Date(2026, 10, 3, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the 30 days up to and including the end date, got %s until before %v", report.StartDate, repo.end)
+	}
+}
@@ -0,0 +1,27 @@
+// Package apidoc assembles the OpenAPI specification of the API from the
+// routes each module describes. api/openapi.json is generated from it; run
+// go generate after changing routes or the types they exchange.
+package apidoc
+
+//go:generate go run ../../cmd/openapi -o ../../api/openapi.json
+
+import (
+	"portal-data-backend/infrastructure/http/openapi"
+	"portal-data-backend/internal/app"
+	"portal-data-backend/internal/modules"
+)
+
+// Title is the title of the API
+const Title = "Portal Data API"
+
+// BasePath is the path the API is served under
+const BasePath = "/api/v1"
+
+// Spec returns the specification of version 1 of the API. Describing routes
+// needs no dependencies, so the modules are not registered.
+func Spec(version string) *openapi.Document {
+	spec := openapi.New(Title, version, BasePath)
+
+	app.NewRegistry(modules.All()...).Describe(spec)
+	return spec.Document()
+}
@@ -0,0 +1,127 @@
+package apidoc_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	"portal-data-backend/internal/apidoc"
+	"portal-data-backend/internal/app"
+	"portal-data-backend/internal/modules"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Test the committed specification matches the one generated from the code
+func TestSpec_UpToDate(t *testing.T) {
+	generated, err := json.MarshalIndent(apidoc.Spec("1.0.0"), "", "  ")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	committed, err := os.ReadFile("../../api/openapi.json")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !bytes.Equal(append(generated, '\n'), committed) {
+		t.Errorf("Expected api/openapi.json to be up to date, run go generate ./internal/apidoc")
+	}
+}
+
+// Test the specification describes exactly the routes the modules register
+func TestSpec_DescribesAllRoutes(t *testing.T) {
+	r := chi.NewRouter()
+	passthrough := func(next http.Handler) http.Handler { return next }
+	app.NewRegistry(modules.All()...).Routes(r, passthrough)
+
+	// Methods OpenAPI has no field for, like the PROPFIND of WebDAV, cannot
+	// be described
+	describable := map[string]bool{
+		http.MethodGet: true, http.MethodPut: true, http.MethodPost: true, http.MethodDelete: true,
+		http.MethodOptions: true, http.MethodHead: true, http.MethodPatch: true, http.MethodTrace: true,
+	}
+	registered := map[string]bool{}
+	err := chi.Walk(r, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if !describable[method] {
+			return nil
+		}
+		route = strings.ReplaceAll(route, "/*", "")
+		if len(route) > 1 {
+			route = strings.TrimSuffix(route, "/")
+		}
+		registered[method+" "+route] = true
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	described := map[string]bool{}
+	for path, item := range apidoc.Spec("1.0.0").Paths {
+		for method := range item {
+			described[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+
+	for route := range registered {
+		if !described[route] {
+			t.Errorf("Expected %s to be described", route)
+		}
+	}
+	for route := range described {
+		if !registered[route] {
+			t.Errorf("Expected %s to be a registered route", route)
+		}
+	}
+}
+
+// Test the specification requires a bearer token for exactly the routes that
+// go through auth
+func TestSpec_DescribesAuthentication(t *testing.T) {
+	requireAuth := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		})
+	}
+	r := chi.NewRouter()
+	app.NewRegistry(modules.All()...).Routes(r, requireAuth)
+
+	for path, item := range apidoc.Spec("1.0.0").Paths {
+		for method, operation := range item {
+			bearer, apiKey := false, false
+			for _, requirement := range operation.Security {
+				if _, ok := requirement[openapi.BearerAuth]; ok {
+					bearer = true
+				}
+				if _, ok := requirement[openapi.APIKey]; ok {
+					apiKey = true
+				}
+			}
+
+			// Routes open to API keys only refuse requests without one
+			// with 401 as well
+			method = strings.ToUpper(method)
+			if stopped := stoppedByAuth(r, method, path); stopped != bearer && !(stopped && apiKey) {
+				t.Errorf("Expected %s %s to go through auth: %v, got %v", method, path, bearer, stopped)
+			}
+		}
+	}
+}
+
+// stoppedByAuth reports whether auth stops a request to the route. The
+// modules are not registered, so a request reaching a handler panics.
+func stoppedByAuth(r http.Handler, method, path string) (stopped bool) {
+	defer func() {
+		recover()
+	}()
+
+	target := strings.NewReplacer("{", "", "}", "").Replace(path)
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, httptest.NewRequest(method, target, nil))
+	return recorder.Code == http.StatusUnauthorized
+}
@@ -0,0 +1,365 @@
+// Package app composes the API from its modules. Each module builds itself
+// from the shared dependencies in Register, provides the services other
+// modules use, and mounts its own routes; adding a module is one entry in
+// modules.All.
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"portal-data-backend/infrastructure/audit"
+	"portal-data-backend/infrastructure/cache"
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/infrastructure/db"
+	"portal-data-backend/infrastructure/deprecation"
+	"portal-data-backend/infrastructure/events"
+	"portal-data-backend/infrastructure/health"
+	"portal-data-backend/infrastructure/http/openapi"
+	"portal-data-backend/infrastructure/logger"
+	"portal-data-backend/infrastructure/security"
+	"portal-data-backend/infrastructure/tenant"
+	analyticsUsecase "portal-data-backend/internal/analytics/usecase"
+	authUsecase "portal-data-backend/internal/auth/usecase"
+	businessFieldUsecase "portal-data-backend/internal/business_field/usecase"
+	dataRowUsecase "portal-data-backend/internal/data_row/usecase"
+	datasetDomain "portal-data-backend/internal/dataset/domain"
+	datasetUsecase "portal-data-backend/internal/dataset/usecase"
+	deprecationUsecase "portal-data-backend/internal/deprecation/usecase"
+	developerUsecase "portal-data-backend/internal/developer/usecase"
+	templateUsecase "portal-data-backend/internal/message_template/usecase"
+	moderationUsecase "portal-data-backend/internal/moderation/usecase"
+	fileUsecase "portal-data-backend/internal/file/usecase"
+	notifUsecase "portal-data-backend/internal/notification/usecase"
+	orgUsecase "portal-data-backend/internal/organization/usecase"
+	publicationUsecase "portal-data-backend/internal/publication/usecase"
+	roleUsecase "portal-data-backend/internal/role/usecase"
+	settingsUsecase "portal-data-backend/internal/settings/usecase"
+	tagUsecase "portal-data-backend/internal/tag/usecase"
+	topicUsecase "portal-data-backend/internal/topic/usecase"
+	userUsecase "portal-data-backend/internal/user/usecase"
+	unitUsecase "portal-data-backend/internal/unit/usecase"
+	vizUsecase "portal-data-backend/internal/visualization/usecase"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jmoiron/sqlx"
+	"google.golang.org/grpc"
+)
+
+// Module is a feature of the API with its own storage, usecases and routes
+type Module interface {
+	// Name identifies the module in logs and errors
+	Name() string
+	// Register builds the module from deps and sets the services it provides
+	Register(deps *Deps) error
+	// Routes mounts the module's routes. Routes that require a signed-in
+	// user go through auth.
+	Routes(r chi.Router, auth func(http.Handler) http.Handler)
+	// Describe adds the module's routes to the API specification
+	Describe(spec *openapi.Spec)
+}
+
+// Runner is implemented by modules with background work. Run blocks until
+// ctx is done.
+type Runner interface {
+	Run(ctx context.Context)
+}
+
+// Command is an operator command of portalctl, run with the arguments
+// following its name. It reads input from in and reports to out.
+type Command struct {
+	Name    string
+	Usage   string
+	Summary string
+	Run     func(ctx context.Context, in io.Reader, out io.Writer, args []string) error
+}
+
+// Commander is implemented by modules offering operator commands
+type Commander interface {
+	Commands() []Command
+}
+
+// Purger is implemented by modules soft deleting records. Purge deletes the
+// records soft deleted before before for good and returns how many it
+// deleted.
+type Purger interface {
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Job is a maintenance task of a module, run every Interval by the server.
+// Run returns how many records it cleaned up. A zero Interval turns the job
+// off.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) (int64, error)
+}
+
+// Scheduler is implemented by modules with maintenance jobs
+type Scheduler interface {
+	Jobs() []Job
+}
+
+// GRPCRegistrar is implemented by modules serving gRPC services to internal
+// consumers
+type GRPCRegistrar interface {
+	RegisterGRPC(s grpc.ServiceRegistrar)
+}
+
+// Deps holds what modules are built from: shared infrastructure and the
+// services modules provide to each other
+type Deps struct {
+	Config *config.Config
+	Logger *logger.Logger
+	DB     *sqlx.DB
+	JWT    *security.JWTManager
+
+	// DBRouter sends public reads to the read replicas of DB
+	DBRouter *db.Router
+
+	// Tx runs work spanning several repositories in one transaction
+	Tx db.Transactor
+
+	// Events receives the domain events of every module. Modules that
+	// consume events subscribe to it in Register.
+	Events *events.Bus
+	// Outbox records domain events in the transaction of the change raising
+	// them; they reach Events once it commits. Modules publish to it rather
+	// than to Events.
+	Outbox *events.Outbox
+
+	// Cache hands out cache namespaces; they are nil when caching is
+	// disabled, and a nil namespace always misses.
+	Cache *cache.Store
+
+	// Health probes the dependencies for readiness checks. Modules register
+	// the checks of the dependencies they own in Register.
+	Health *health.Registry
+
+	// Deprecations lists the deprecated routes and parameters of the API.
+	// Modules deprecating some of theirs declare them in Register.
+	Deprecations *deprecation.Registry
+
+	// Reloader hands out the configuration reloaded on SIGHUP. Modules
+	// applying reloadable values subscribe to it in Register.
+	Reloader *config.Reloader
+
+	Services Services
+}
+
+// Services are the usecases modules provide to each other, and to commands
+// like seed once every module is registered. A module sets the services it
+// provides in Register, so they are available to the modules registered
+// after it.
+type Services struct {
+	Accounts       authUsecase.Usecase
+	Users          userUsecase.Usecase
+	Organizations  orgUsecase.Usecase
+	Datasets       datasetUsecase.Usecase
+	DataRows       dataRowUsecase.Usecase
+	Files          fileUsecase.Usecase
+	Topics         topicUsecase.Usecase
+	BusinessFields businessFieldUsecase.Usecase
+	Units          unitUsecase.Usecase
+	Tags           tagUsecase.Usecase
+	Visualizations vizUsecase.Usecase
+	Publications   publicationUsecase.Usecase
+	Notifications  notifUsecase.Usecase
+	Settings       settingsUsecase.Usecase
+
+	// DatasetSearcher is nil when datasets are searched in the database
+	DatasetSearcher datasetDomain.Searcher
+	// OrganizationCounters keeps the dataset counters of organizations
+	OrganizationCounters datasetDomain.OrganizationCounter
+	// Audit records changes in the audit log
+	Audit *audit.Recorder
+	// AuthAudit records sign ins, sign outs and password changes in the
+	// auth audit log
+	AuthAudit *audit.AuthRecorder
+	// Tenants resolves the portal requests are for
+	Tenants *tenant.Resolver
+	// Developers authenticates the API keys of developer applications
+	Developers developerUsecase.Usecase
+	// Moderation screens the text the public submits
+	Moderation moderationUsecase.Usecase
+	// Templates renders the mail and notifications modules send
+	Templates templateUsecase.Usecase
+	// Roles resolves the permissions the roles of users grant
+	Roles roleUsecase.Usecase
+	// Deprecations counts the use of the deprecated surfaces of the API
+	Deprecations deprecationUsecase.Usecase
+	// Analytics counts the views and downloads of records by the channel
+	// they came from
+	Analytics analyticsUsecase.Usecase
+}
+
+// MissingServiceError reports a module registered before a module whose
+// service it needs
+func MissingServiceError(service string) error {
+	return fmt.Errorf("the %s service is not registered, register the module providing it first", service)
+}
+
+// Registry holds the modules of the API in registration order
+type Registry struct {
+	modules []Module
+}
+
+// NewRegistry creates a registry of modules
+func NewRegistry(modules ...Module) *Registry {
+	return &Registry{modules: modules}
+}
+
+// Register registers every module in order
+func (r *Registry) Register(deps *Deps) error {
+	for _, module := range r.modules {
+		if err := module.Register(deps); err != nil {
+			return fmt.Errorf("failed to register %s module: %w", module.Name(), err)
+		}
+	}
+	return nil
+}
+
+// Routes mounts the routes of every module on router
+func (r *Registry) Routes(router chi.Router, auth func(http.Handler) http.Handler) {
+	for _, module := range r.modules {
+		module.Routes(router, auth)
+	}
+}
+
+// Describe adds the routes of every module to spec
+func (r *Registry) Describe(spec *openapi.Spec) {
+	for _, module := range r.modules {
+		module.Describe(spec)
+	}
+}
+
+// Commands returns the operator commands of every module
+func (r *Registry) Commands() []Command {
+	var commands []Command
+	for _, module := range r.modules {
+		if commander, ok := module.(Commander); ok {
+			commands = append(commands, commander.Commands()...)
+		}
+	}
+	return commands
+}
+
+// RegisterGRPC registers the gRPC services of every module on s
+func (r *Registry) RegisterGRPC(s grpc.ServiceRegistrar) {
+	for _, module := range r.modules {
+		if registrar, ok := module.(GRPCRegistrar); ok {
+			registrar.RegisterGRPC(s)
+		}
+	}
+}
+
+// Purge deletes the records soft deleted before before in every module,
+// returning how many each module deleted. It goes on past a failing module
+// and returns the first error.
+func (r *Registry) Purge(ctx context.Context, before time.Time) (map[string]int64, error) {
+	purged := make(map[string]int64)
+	var firstErr error
+	for _, module := range r.modules {
+		purger, ok := module.(Purger)
+		if !ok {
+			continue
+		}
+		count, err := purger.Purge(ctx, before)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to purge %s module: %w", module.Name(), err)
+			}
+			continue
+		}
+		purged[module.Name()] = count
+	}
+	return purged, firstErr
+}
+
+// RunPurge purges the records soft deleted longer than retention ago in
+// every module each interval until ctx is done. A zero interval leaves
+// purging to portalctl.
+func (r *Registry) RunPurge(ctx context.Context, interval, retention time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := r.Purge(ctx, time.Now().Add(-retention))
+			if err != nil {
+				logger.FromContext(ctx).Error("trash purge failed: %v", err)
+			}
+			for name, count := range purged {
+				if count > 0 {
+					logger.FromContext(ctx).Info("purged %d records of the %s module from the trash", count, name)
+				}
+			}
+		}
+	}
+}
+
+// RunJobs runs the maintenance jobs of every module on their intervals until
+// ctx is done. A job running then is let finish, and RunJobs returns once
+// every job has stopped.
+func (r *Registry) RunJobs(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, module := range r.modules {
+		scheduler, ok := module.(Scheduler)
+		if !ok {
+			continue
+		}
+		for _, job := range scheduler.Jobs() {
+			if job.Interval <= 0 {
+				continue
+			}
+			wg.Add(1)
+			go func(name string, job Job) {
+				defer wg.Done()
+				runJob(ctx, name, job)
+			}(module.Name(), job)
+		}
+	}
+	wg.Wait()
+}
+
+// runJob runs job of module every interval until ctx is done
+func runJob(ctx context.Context, module string, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Shutting down waits for the run rather than cutting it short
+			count, err := job.Run(context.WithoutCancel(ctx))
+			if err != nil {
+				logger.FromContext(ctx).Error("%s job of the %s module failed: %v", job.Name, module, err)
+			} else if count > 0 {
+				logger.FromContext(ctx).Info("%s job of the %s module cleaned up %d records", job.Name, module, count)
+			}
+		}
+	}
+}
+
+// Run starts the background work of every module and returns. The work
+// stops when ctx is done.
+func (r *Registry) Run(ctx context.Context) {
+	for _, module := range r.modules {
+		if runner, ok := module.(Runner); ok {
+			go runner.Run(ctx)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"portal-data-backend/infrastructure/http/middleware"
+	"portal-data-backend/infrastructure/http/problem"
+	"portal-data-backend/infrastructure/http/response"
+	auditDomain "portal-data-backend/internal/audit/domain"
+	"portal-data-backend/internal/audit/usecase"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	auditUsecase usecase.Usecase
+}
+
+func NewHandler(auditUsecase usecase.Usecase) *Handler {
+	return &Handler{auditUsecase: auditUsecase}
+}
+
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	req := listRequest(r)
+	req.Page = parseIntQuery(r, "page", 1)
+	req.Limit = parseIntQuery(r, "limit", 20)
+
+	resp, err := h.auditUsecase.List(r.Context(), req)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Audit logs retrieved successfully", resp)
+}
+
+func (h *Handler) Export(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	data, err := h.auditUsecase.Export(r.Context(), listRequest(r), format)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	if format == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", `attachment; filename="audit-logs.json"`)
+	} else {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="audit-logs.csv"`)
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// ListAuth lists the auth events of users, filtered by user, event and
+// date range
+func (h *Handler) ListAuth(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	req := &auditDomain.ListAuthAuditRequest{
+		Page:   parseIntQuery(r, "page", 1),
+		Limit:  parseIntQuery(r, "limit", 20),
+		UserID: query.Get("user_id"),
+		Event:  query.Get("event"),
+		From:   query.Get("from"),
+		To:     query.Get("to"),
+	}
+
+	resp, err := h.auditUsecase.ListAuth(r.Context(), req)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Auth audit events retrieved successfully", resp)
+}
+
+// listRequest reads the audit log filters of r
+func listRequest(r *http.Request) *auditDomain.ListAuditLogsRequest {
+	query := r.URL.Query()
+	return &auditDomain.ListAuditLogsRequest{
+		ActorID:        query.Get("actor_id"),
+		ImpersonatorID: query.Get("impersonator_id"),
+		OrganizationID: query.Get("organization_id"),
+		EntityType:     query.Get("entity_type"),
+		EntityID:       query.Get("entity_id"),
+		Action:         query.Get("action"),
+		From:           query.Get("from"),
+		To:             query.Get("to"),
+	}
+}
+
+func (h *Handler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	problem.Write(w, r, err)
+}
+
+func parseIntQuery(r *http.Request, key string, defaultValue int) int {
+	if value := r.URL.Query().Get(key); value != "" {
+		if intValue, err := strconv.Atoi(value); err == nil {
+			return intValue
+		}
+	}
+	return defaultValue
+}
+
+// RegisterRoutes registers the audit log and auth audit log routes, open to
+// users with one of adminRoles
+func RegisterRoutes(r chi.Router, handler *Handler, auth func(http.Handler) http.Handler, adminRoles []string) {
+	r.Route("/admin/audit-logs", func(r chi.Router) {
+		r.Use(auth)
+		r.Use(middleware.RequireRole(adminRoles...))
+		r.Get("/", handler.List)
+		r.Get("/export", handler.Export)
+	})
+	r.Route("/admin/auth-audit", func(r chi.Router) {
+		r.Use(auth)
+		r.Use(middleware.RequireRole(adminRoles...))
+		r.Get("/", handler.ListAuth)
+	})
+}
@@ -0,0 +1,19 @@
+package http
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	auditDomain "portal-data-backend/internal/audit/domain"
+)
+
+// Describe adds the audit log routes to the API specification
+func Describe(spec *openapi.Spec) {
+	api := spec.Tag("audit", "Audit log of changes, for administrators")
+	api.Get("/admin/audit-logs", "List audit logs").Query(auditDomain.ListAuditLogsRequest{}).Returns(http.StatusOK, auditDomain.AuditLogListResponse{})
+	api.Get("/admin/audit-logs/export", "Export audit logs as CSV or JSON").
+		Query(auditDomain.ListAuditLogsRequest{}, "actor_id", "impersonator_id", "organization_id", "entity_type", "entity_id", "action", "from", "to").
+		Param("format", false).
+		ReturnsFile(http.StatusOK, "text/csv")
+	api.Get("/admin/auth-audit", "List sign ins, sign outs and password changes").Query(auditDomain.ListAuthAuditRequest{}).Returns(http.StatusOK, auditDomain.AuthAuditListResponse{})
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"time"
+
+	"portal-data-backend/infrastructure/audit"
+)
+
+// ListAuditLogsRequest filters the audit log. From and To are RFC 3339 times
+// or dates; a date as To includes the whole day.
+type ListAuditLogsRequest struct {
+	Page           int    `json:"page" validate:"min=1"`
+	Limit          int    `json:"limit" validate:"min=1,max=100"`
+	ActorID        string `json:"actor_id,omitempty"`
+	ImpersonatorID string `json:"impersonator_id,omitempty"`
+	OrganizationID string `json:"organization_id,omitempty"`
+	EntityType     string `json:"entity_type,omitempty"`
+	EntityID       string `json:"entity_id,omitempty"`
+	Action         string `json:"action,omitempty" validate:"omitempty,oneof=create update delete restore purge impersonate read"`
+	From           string `json:"from,omitempty"`
+	To             string `json:"to,omitempty"`
+}
+
+// Filter selects audit entries; empty fields match every entry. Entries are
+// from From on and before To.
+type Filter struct {
+	ActorID        string
+	ImpersonatorID string
+	OrganizationID string
+	EntityType     string
+	EntityID       string
+	Action         string
+	From           *time.Time
+	To             *time.Time
+}
+
+// AuditLogListResponse is a page of the audit log, newest first
+type AuditLogListResponse struct {
+	AuditLogs []*audit.Entry `json:"audit_logs"`
+	Meta      ListMeta       `json:"meta"`
+}
+
+// ListAuthAuditRequest filters the auth audit log like ListAuditLogsRequest
+// filters the audit log
+type ListAuthAuditRequest struct {
+	Page   int    `json:"page" validate:"min=1"`
+	Limit  int    `json:"limit" validate:"min=1,max=100"`
+	UserID string `json:"user_id,omitempty"`
+	Event  string `json:"event,omitempty" validate:"omitempty,oneof=login login_failed logout token_refresh password_change password_reset"`
+	From   string `json:"from,omitempty"`
+	To     string `json:"to,omitempty"`
+}
+
+// AuthFilter selects auth events; empty fields match every event. Events
+// are from From on and before To.
+type AuthFilter struct {
+	UserID string
+	Event  string
+	From   *time.Time
+	To     *time.Time
+}
+
+// AuthAuditListResponse is a page of the auth audit log, newest first
+type AuthAuditListResponse struct {
+	Events []*audit.AuthEntry `json:"events"`
+	Meta   ListMeta           `json:"meta"`
+}
+
+type ListMeta struct {
+	Page      int `json:"page"`
+	Limit     int `json:"limit"`
+	Total     int `json:"total"`
+	TotalPage int `json:"total_page"`
+}
@@ -0,0 +1,19 @@
+package domain
+
+import (
+	"context"
+
+	"portal-data-backend/infrastructure/audit"
+)
+
+type Repository interface {
+	audit.Store
+	// List returns the entries matching filter, newest first, and how many
+	// match in total
+	List(ctx context.Context, filter *Filter, limit, offset int) ([]*audit.Entry, int, error)
+
+	audit.AuthStore
+	// ListAuth returns the auth events matching filter, newest first, and
+	// how many match in total
+	ListAuth(ctx context.Context, filter *AuthFilter, limit, offset int) ([]*audit.AuthEntry, int, error)
+}
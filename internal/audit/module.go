@@ -0,0 +1,47 @@
+// Package audit is the module keeping the audit log of changes.
+package audit
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/audit"
+	"portal-data-backend/infrastructure/http/openapi"
+	"portal-data-backend/internal/app"
+	delivery "portal-data-backend/internal/audit/delivery/http"
+	"portal-data-backend/internal/audit/repository"
+	"portal-data-backend/internal/audit/usecase"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Module keeps the audit log and provides the recorder other modules record
+// their changes with, and the one the auth module records auth events with
+type Module struct {
+	handler    *delivery.Handler
+	adminRoles []string
+}
+
+// Name implements app.Module
+func (m *Module) Name() string {
+	return "audit"
+}
+
+// Register implements app.Module
+func (m *Module) Register(deps *app.Deps) error {
+	repo := repository.NewAuditPostgresRepository(deps.DB)
+	deps.Services.Audit = audit.NewRecorder(repo)
+	deps.Services.AuthAudit = audit.NewAuthRecorder(repo)
+	m.handler = delivery.NewHandler(usecase.NewAuditUsecase(repo))
+	m.adminRoles = deps.Config.Audit.AdminRoles
+	return nil
+}
+
+// Routes implements app.Module
+func (m *Module) Routes(r chi.Router, auth func(http.Handler) http.Handler) {
+	delivery.RegisterRoutes(r, m.handler, auth, m.adminRoles)
+}
+
+// Describe implements app.Module
+func (m *Module) Describe(spec *openapi.Spec) {
+	delivery.Describe(spec)
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"portal-data-backend/infrastructure/audit"
+	"portal-data-backend/infrastructure/db"
+	"portal-data-backend/internal/audit/domain"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type auditPostgresRepository struct {
+	db *sqlx.DB
+}
+
+func NewAuditPostgresRepository(db *sqlx.DB) domain.Repository {
+	return &auditPostgresRepository{db: db}
+}
+
+// Insert saves an entry in the transaction of ctx, if any
+func (r *auditPostgresRepository) Insert(ctx context.Context, entry *audit.Entry) error {
+	query := `
+		INSERT INTO audit_logs (
+			id, actor_id, actor_email, impersonator_id, organization_id, entity_type, entity_id,
+			action, changes, request_id, method, path, created_at
+		) VALUES (
+			:id, :actor_id, :actor_email, :impersonator_id, :organization_id, :entity_type, :entity_id,
+			:action, :changes, :request_id, :method, :path, :created_at
+		)
+	`
+	if _, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, entry); err != nil {
+		return fmt.Errorf("failed to insert audit log: %w", err)
+	}
+	return nil
+}
+
+func (r *auditPostgresRepository) List(ctx context.Context, filter *domain.Filter, limit, offset int) ([]*audit.Entry, int, error) {
+	whereClause := "WHERE 1=1"
+	args := []interface{}{}
+	argCount := 1
+
+	equals := []struct{ column, value string }{
+		{"actor_id", filter.ActorID},
+		{"impersonator_id", filter.ImpersonatorID},
+		{"organization_id", filter.OrganizationID},
+		{"entity_type", filter.EntityType},
+		{"entity_id", filter.EntityID},
+		{"action", filter.Action},
+	}
+	for _, field := range equals {
+		if field.value != "" {
+			whereClause += fmt.Sprintf(" AND %s = $%d", field.column, argCount)
+			args = append(args, field.value)
+			argCount++
+		}
+	}
+	if filter.From != nil {
+		whereClause += fmt.Sprintf(" AND created_at >= $%d", argCount)
+		args = append(args, *filter.From)
+		argCount++
+	}
+	if filter.To != nil {
+		whereClause += fmt.Sprintf(" AND created_at < $%d", argCount)
+		args = append(args, *filter.To)
+		argCount++
+	}
+
+	countQuery := "SELECT COUNT(*) FROM audit_logs " + whereClause
+	var total int
+	if err := db.Conn(ctx, r.db).GetContext(ctx, &total, countQuery, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit logs: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, actor_id, actor_email, impersonator_id, organization_id, entity_type, entity_id,
+		       action, changes, request_id, method, path, created_at
+		FROM audit_logs %s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d OFFSET $%d
+	`, whereClause, argCount, argCount+1)
+	args = append(args, limit, offset)
+
+	var entries []*audit.Entry
+	if err := db.Conn(ctx, r.db).SelectContext(ctx, &entries, query, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to list audit logs: %w", err)
+	}
+	return entries, total, nil
+}
+
+// InsertAuth saves an auth event outside of any transaction, so failed
+// sign ins are kept whatever happens to the request
+func (r *auditPostgresRepository) InsertAuth(ctx context.Context, entry *audit.AuthEntry) error {
+	query := `
+		INSERT INTO auth_audit (id, event, user_id, email, ip, user_agent, detail, request_id, created_at)
+		VALUES (:id, :event, :user_id, :email, :ip, :user_agent, :detail, :request_id, :created_at)
+	`
+	if _, err := r.db.NamedExecContext(ctx, query, entry); err != nil {
+		return fmt.Errorf("failed to insert auth audit event: %w", err)
+	}
+	return nil
+}
+
+func (r *auditPostgresRepository) ListAuth(ctx context.Context, filter *domain.AuthFilter, limit, offset int) ([]*audit.AuthEntry, int, error) {
+	whereClause := "WHERE 1=1"
+	args := []interface{}{}
+	argCount := 1
+
+	equals := []struct{ column, value string }{
+		{"user_id", filter.UserID},
+		{"event", filter.Event},
+	}
+	for _, field := range equals {
+		if field.value != "" {
+			whereClause += fmt.Sprintf(" AND %s = $%d", field.column, argCount)
+			args = append(args, field.value)
+			argCount++
+		}
+	}
+	if filter.From != nil {
+		whereClause += fmt.Sprintf(" AND created_at >= $%d", argCount)
+		args = append(args, *filter.From)
+		argCount++
+	}
+	if filter.To != nil {
+		whereClause += fmt.Sprintf(" AND created_at < $%d", argCount)
+		args = append(args, *filter.To)
+		argCount++
+	}
+
+	countQuery := "SELECT COUNT(*) FROM auth_audit " + whereClause
+	var total int
+	if err := db.Conn(ctx, r.db).GetContext(ctx, &total, countQuery, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to count auth audit events: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, event, user_id, email, ip, user_agent, detail, request_id, created_at
+		FROM auth_audit %s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d OFFSET $%d
+	`, whereClause, argCount, argCount+1)
+	args = append(args, limit, offset)
+
+	var entries []*audit.AuthEntry
+	if err := db.Conn(ctx, r.db).SelectContext(ctx, &entries, query, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to list auth audit events: %w", err)
+	}
+	return entries, total, nil
+}
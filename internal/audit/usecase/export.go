@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"portal-data-backend/infrastructure/audit"
+	"portal-data-backend/internal/audit/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+)
+
+// MaxExportEntries bounds the entries of one export; narrow the filters to
+// export more
+const MaxExportEntries = 100000
+
+// exportBatch is how many entries an export reads at a time
+const exportBatch = 1000
+
+// csvColumns are the columns of an audit log export
+var csvColumns = []string{
+	"id", "created_at", "actor_id", "actor_email", "impersonator_id", "organization_id",
+	"entity_type", "entity_id", "action", "changes", "request_id", "method", "path",
+}
+
+func (u *auditUsecase) Export(ctx context.Context, req *domain.ListAuditLogsRequest, format string) ([]byte, error) {
+	if format != "" && format != "csv" && format != "json" {
+		return nil, fmt.Errorf("%w: unsupported export format %q", pkgErrors.ErrInvalidInput, format)
+	}
+
+	filter, err := toFilter(req)
+	if err != nil {
+		return nil, err
+	}
+	// Entries recorded while exporting would shift the batches
+	if filter.To == nil {
+		now := time.Now()
+		filter.To = &now
+	}
+
+	var entries []*audit.Entry
+	for len(entries) < MaxExportEntries {
+		batch, _, err := u.auditRepo.List(ctx, filter, exportBatch, len(entries))
+		if err != nil {
+			return nil, fmt.Errorf("failed to export audit logs: %w", err)
+		}
+		entries = append(entries, batch...)
+		if len(batch) < exportBatch {
+			break
+		}
+	}
+	if len(entries) > MaxExportEntries {
+		entries = entries[:MaxExportEntries]
+	}
+
+	if format == "json" {
+		if entries == nil {
+			entries = []*audit.Entry{}
+		}
+		data, err := json.Marshal(entries)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export audit logs: %w", err)
+		}
+		return data, nil
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write(csvColumns)
+	for _, entry := range entries {
+		writer.Write([]string{
+			entry.ID, entry.CreatedAt.UTC().Format(time.RFC3339), entry.ActorID, entry.ActorEmail, entry.ImpersonatorID, entry.OrganizationID,
+			entry.EntityType, entry.EntityID, string(entry.Action), string(entry.Changes), entry.RequestID, entry.Method, entry.Path,
+		})
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("failed to export audit logs: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,18 @@
+package usecase
+
+import (
+	"context"
+
+	"portal-data-backend/internal/audit/domain"
+)
+
+type Usecase interface {
+	List(ctx context.Context, req *domain.ListAuditLogsRequest) (*domain.AuditLogListResponse, error)
+	// Export writes the entries matching req, newest first, as CSV or, with
+	// format "json", a JSON array. Paging in req is ignored; at most
+	// MaxExportEntries are exported.
+	Export(ctx context.Context, req *domain.ListAuditLogsRequest, format string) ([]byte, error)
+	// ListAuth lists the sign ins, sign outs, token refreshes and password
+	// changes of users, newest first
+	ListAuth(ctx context.Context, req *domain.ListAuthAuditRequest) (*domain.AuthAuditListResponse, error)
+}
@@ -0,0 +1,161 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"portal-data-backend/infrastructure/audit"
+	"portal-data-backend/internal/audit/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+)
+
+type auditUsecase struct {
+	auditRepo domain.Repository
+}
+
+func NewAuditUsecase(auditRepo domain.Repository) Usecase {
+	return &auditUsecase{auditRepo: auditRepo}
+}
+
+func (u *auditUsecase) List(ctx context.Context, req *domain.ListAuditLogsRequest) (*domain.AuditLogListResponse, error) {
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.Limit < 1 {
+		req.Limit = 20
+	}
+	if req.Limit > 100 {
+		req.Limit = 100
+	}
+
+	filter, err := toFilter(req)
+	if err != nil {
+		return nil, err
+	}
+
+	offset := (req.Page - 1) * req.Limit
+	entries, total, err := u.auditRepo.List(ctx, filter, req.Limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit logs: %w", err)
+	}
+
+	return &domain.AuditLogListResponse{
+		AuditLogs: entries,
+		Meta: domain.ListMeta{
+			Page:      req.Page,
+			Limit:     req.Limit,
+			Total:     total,
+			TotalPage: int(math.Ceil(float64(total) / float64(req.Limit))),
+		},
+	}, nil
+}
+
+// toFilter validates the filters of req
+func toFilter(req *domain.ListAuditLogsRequest) (*domain.Filter, error) {
+	filter := &domain.Filter{
+		ActorID:        req.ActorID,
+		ImpersonatorID: req.ImpersonatorID,
+		OrganizationID: req.OrganizationID,
+		EntityType:     req.EntityType,
+		EntityID:       req.EntityID,
+		Action:         req.Action,
+	}
+
+	var err error
+	filter.From, filter.To, err = parseRange(req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+func (u *auditUsecase) ListAuth(ctx context.Context, req *domain.ListAuthAuditRequest) (*domain.AuthAuditListResponse, error) {
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.Limit < 1 {
+		req.Limit = 20
+	}
+	if req.Limit > 100 {
+		req.Limit = 100
+	}
+
+	filter := &domain.AuthFilter{UserID: req.UserID}
+	if req.Event != "" {
+		if !isAuthEvent(req.Event) {
+			return nil, fmt.Errorf("%w: event must be one of %v", pkgErrors.ErrInvalidInput, audit.AuthEvents)
+		}
+		filter.Event = req.Event
+	}
+	var err error
+	filter.From, filter.To, err = parseRange(req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+
+	offset := (req.Page - 1) * req.Limit
+	events, total, err := u.auditRepo.ListAuth(ctx, filter, req.Limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list auth audit events: %w", err)
+	}
+
+	return &domain.AuthAuditListResponse{
+		Events: events,
+		Meta: domain.ListMeta{
+			Page:      req.Page,
+			Limit:     req.Limit,
+			Total:     total,
+			TotalPage: int(math.Ceil(float64(total) / float64(req.Limit))),
+		},
+	}, nil
+}
+
+func isAuthEvent(event string) bool {
+	for _, known := range audit.AuthEvents {
+		if event == string(known) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRange validates the times from and to of a filter, either of which
+// may be empty; a date as to includes the whole day
+func parseRange(fromValue, toValue string) (*time.Time, *time.Time, error) {
+	var from, to *time.Time
+	if fromValue != "" {
+		t, _, err := parseTime(fromValue)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: from: %v", pkgErrors.ErrInvalidInput, err)
+		}
+		from = &t
+	}
+	if toValue != "" {
+		t, isDate, err := parseTime(toValue)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: to: %v", pkgErrors.ErrInvalidInput, err)
+		}
+		if isDate {
+			t = t.AddDate(0, 0, 1)
+		}
+		to = &t
+	}
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, nil, fmt.Errorf("%w: from must be before to", pkgErrors.ErrInvalidInput)
+	}
+	return from, to, nil
+}
+
+// parseTime parses an RFC 3339 time or a date, reporting which it was
+func parseTime(value string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, false, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("%q is neither an RFC 3339 time nor a YYYY-MM-DD date", value)
+	}
+	return t, true, nil
+}
@@ -0,0 +1,111 @@
+// Package cli holds the portalctl commands of the auth module.
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"portal-data-backend/internal/app"
+	"portal-data-backend/internal/auth/domain"
+	"portal-data-backend/internal/auth/usecase"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Commands returns the account commands. Admins get adminRole unless
+// another role is given.
+func Commands(authUsecase usecase.Usecase, adminRole string) []app.Command {
+	return []app.Command{
+		{
+			Name:    "create-admin",
+			Usage:   "create-admin -org ID -name NAME -username USERNAME -email EMAIL [-role ID] < password",
+			Summary: "Create an admin user, reading the password from stdin",
+			Run: func(ctx context.Context, in io.Reader, out io.Writer, args []string) error {
+				return createAdmin(ctx, authUsecase, adminRole, in, out, args)
+			},
+		},
+		{
+			Name:    "reset-password",
+			Usage:   "reset-password -email EMAIL < password",
+			Summary: "Set a new password for a user and sign them out everywhere",
+			Run: func(ctx context.Context, in io.Reader, out io.Writer, args []string) error {
+				return resetPassword(ctx, authUsecase, in, out, args)
+			},
+		},
+	}
+}
+
+func createAdmin(ctx context.Context, authUsecase usecase.Usecase, adminRole string, in io.Reader, out io.Writer, args []string) error {
+	req := &domain.RegisterRequest{}
+	flags := flag.NewFlagSet("create-admin", flag.ContinueOnError)
+	flags.SetOutput(out)
+	flags.StringVar(&req.OrganizationID, "org", "", "organization ID of the user")
+	flags.StringVar(&req.RoleID, "role", adminRole, "role ID of the user")
+	flags.StringVar(&req.Name, "name", "", "full name of the user")
+	flags.StringVar(&req.Username, "username", "", "username of the user")
+	flags.StringVar(&req.Email, "email", "", "email of the user")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	password, err := readPassword(in)
+	if err != nil {
+		return err
+	}
+	req.Password = password
+	if err := validator.New().Struct(req); err != nil {
+		return fmt.Errorf("invalid user: %w", err)
+	}
+
+	user, err := authUsecase.CreateUser(ctx, req)
+	if err != nil {
+		return fmt.Errorf("failed to create admin: %w", err)
+	}
+	fmt.Fprintf(out, "Created user %s (%s) with role %s\n", user.Username, user.ID, req.RoleID)
+	return nil
+}
+
+func resetPassword(ctx context.Context, authUsecase usecase.Usecase, in io.Reader, out io.Writer, args []string) error {
+	flags := flag.NewFlagSet("reset-password", flag.ContinueOnError)
+	flags.SetOutput(out)
+	email := flags.String("email", "", "email of the user")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *email == "" {
+		return errors.New("email is required")
+	}
+
+	password, err := readPassword(in)
+	if err != nil {
+		return err
+	}
+	if len(password) < 8 {
+		return errors.New("password must be at least 8 characters")
+	}
+
+	if err := authUsecase.SetPassword(ctx, *email, password); err != nil {
+		return fmt.Errorf("failed to reset password: %w", err)
+	}
+	fmt.Fprintf(out, "Reset the password of %s\n", *email)
+	return nil
+}
+
+// readPassword reads the password from the first line of in, so it stays
+// out of the shell history
+func readPassword(in io.Reader) (string, error) {
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+	password := strings.TrimRight(line, "\r\n")
+	if password == "" {
+		return "", errors.New("password is required on stdin")
+	}
+	return password, nil
+}
@@ -0,0 +1,65 @@
+// Package grpc lets internal services validate tokens over gRPC
+package grpc
+
+import (
+	"context"
+
+	portalv1 "portal-data-backend/api/proto/portal/v1"
+	"portal-data-backend/infrastructure/tenant"
+	"portal-data-backend/internal/auth/domain"
+	"portal-data-backend/internal/auth/usecase"
+	"portal-data-backend/pkg/errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements portalv1.AuthServiceServer
+type Server struct {
+	portalv1.UnimplementedAuthServiceServer
+	accounts usecase.Usecase
+}
+
+// NewServer creates an auth gRPC server
+func NewServer(accounts usecase.Usecase) *Server {
+	return &Server{accounts: accounts}
+}
+
+// ValidateToken returns the claims of a valid access token. Tokens that are
+// invalid, expired, revoked or issued by another portal are UNAUTHENTICATED.
+func (s *Server) ValidateToken(ctx context.Context, req *portalv1.ValidateTokenRequest) (*portalv1.ValidateTokenResponse, error) {
+	if req.GetToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "token is required")
+	}
+
+	claims, err := s.accounts.ValidateToken(ctx, req.GetToken())
+	switch {
+	case errors.Is(err, errors.ErrTokenExpired):
+		return nil, status.Error(codes.Unauthenticated, "token expired")
+	case errors.Is(err, errors.ErrTokenRevoked):
+		return nil, status.Error(codes.Unauthenticated, "token revoked")
+	case errors.Is(err, errors.ErrInvalidToken):
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	case err != nil:
+		return nil, err
+	}
+	if t := tenant.FromContext(ctx); t != nil && !issuedBy(claims, t.ID) {
+		return nil, status.Error(codes.Unauthenticated, "token was issued by another portal")
+	}
+
+	return &portalv1.ValidateTokenResponse{
+		UserId:         claims.UserID,
+		OrganizationId: claims.OrganizationID,
+		RoleId:         claims.RoleID,
+		Email:          claims.Email,
+		TenantId:       claims.TenantID,
+	}, nil
+}
+
+// issuedBy is security.Claims.IssuedBy for the claims of the usecase
+func issuedBy(claims *domain.TokenClaims, id string) bool {
+	if claims.TenantID == "" {
+		return id == tenant.DefaultID
+	}
+	return claims.TenantID == id
+}
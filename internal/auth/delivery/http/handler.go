@@ -1,29 +1,51 @@
 package http
 
 import (
-	"encoding/json"
+	"context"
 	"net/http"
+	"strings"
 
+	"portal-data-backend/infrastructure/auth"
+	"portal-data-backend/infrastructure/http/httputil"
+	"portal-data-backend/infrastructure/http/problem"
 	"portal-data-backend/infrastructure/http/response"
+	"portal-data-backend/infrastructure/security"
 	"portal-data-backend/internal/auth/usecase"
+	"portal-data-backend/pkg/client"
 	"portal-data-backend/pkg/errors"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/go-playground/validator/v10"
 )
 
+// CaptchaVerifier checks the captcha solved by the client signing in or
+// registering, like security.CaptchaVerifier
+type CaptchaVerifier interface {
+	Verify(ctx context.Context, token, remoteIP string) error
+}
+
 // Handler handles HTTP requests for auth
 type Handler struct {
 	authUsecase usecase.Usecase
-	validator   *validator.Validate
+	captcha     CaptchaVerifier
 }
 
-// NewHandler creates a new auth handler
-func NewHandler(authUsecase usecase.Usecase) *Handler {
+// NewHandler creates a new auth handler. Signing in and registering need a
+// captcha token captcha accepts.
+func NewHandler(authUsecase usecase.Usecase, captcha CaptchaVerifier) *Handler {
 	return &Handler{
 		authUsecase: authUsecase,
-		validator:   validator.New(),
+		captcha:     captcha,
+	}
+}
+
+// verifyCaptcha checks the captcha token of the request, answering it when
+// the token is rejected
+func (h *Handler) verifyCaptcha(w http.ResponseWriter, r *http.Request, token string) bool {
+	if err := h.captcha.Verify(r.Context(), token, client.FromContext(r.Context()).IP); err != nil {
+		h.handleError(w, r, err)
+		return false
 	}
+	return true
 }
 
 // Login handles user login
@@ -38,20 +60,14 @@ func NewHandler(authUsecase usecase.Usecase) *Handler {
 // @Failure 401 {object} response.ErrorResponse
 // @Router /auth/login [post]
 func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
-	var req LoginRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, response.CodeBadRequest, "Invalid request body", nil)
-		return
-	}
-
-	if err := h.validator.Struct(&req); err != nil {
-		response.ValidationError(w, response.CodeValidationFailed, "Validation failed", h.formatValidationErrors(err))
+	req, ok := httputil.Decode[LoginRequest](w, r)
+	if !ok || !h.verifyCaptcha(w, r, req.CaptchaToken) {
 		return
 	}
 
 	authResp, err := h.authUsecase.Login(r.Context(), req.ToDomain())
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -73,27 +89,25 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 // @Failure 409 {object} response.ErrorResponse
 // @Router /auth/register [post]
 func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
-	var req RegisterRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, response.CodeBadRequest, "Invalid request body", nil)
-		return
-	}
-
-	if err := h.validator.Struct(&req); err != nil {
-		response.ValidationError(w, response.CodeValidationFailed, "Validation failed", h.formatValidationErrors(err))
+	req, ok := httputil.Decode[RegisterRequest](w, r)
+	if !ok || !h.verifyCaptcha(w, r, req.CaptchaToken) {
 		return
 	}
 
 	authResp, err := h.authUsecase.Register(r.Context(), req.ToDomain())
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
 	httpResp := &AuthResponse{}
 	httpResp.FromDomain(authResp)
 
-	response.Created(w, response.CodeCreated, "Registration successful", httpResp)
+	message := "Registration successful"
+	if authResp.VerificationRequired {
+		message = "Registration successful, verify your email to sign in"
+	}
+	response.Created(w, response.CodeCreated, message, httpResp)
 }
 
 // Logout handles user logout
@@ -108,14 +122,8 @@ func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 // @Failure 401 {object} response.ErrorResponse
 // @Router /auth/logout [post]
 func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
-	var req LogoutRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, response.CodeBadRequest, "Invalid request body", nil)
-		return
-	}
-
-	if err := h.validator.Struct(&req); err != nil {
-		response.ValidationError(w, response.CodeValidationFailed, "Validation failed", h.formatValidationErrors(err))
+	req, ok := httputil.Decode[LogoutRequest](w, r)
+	if !ok {
 		return
 	}
 
@@ -126,7 +134,7 @@ func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.authUsecase.Logout(r.Context(), accessToken, req.RefreshToken); err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -145,27 +153,193 @@ func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
 // @Failure 401 {object} response.ErrorResponse
 // @Router /auth/refresh [post]
 func (h *Handler) RefreshToken(w http.ResponseWriter, r *http.Request) {
-	var req RefreshTokenRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, response.CodeBadRequest, "Invalid request body", nil)
+	req, ok := httputil.Decode[RefreshTokenRequest](w, r)
+	if !ok {
 		return
 	}
 
-	if err := h.validator.Struct(&req); err != nil {
-		response.ValidationError(w, response.CodeValidationFailed, "Validation failed", h.formatValidationErrors(err))
+	authResp, err := h.authUsecase.RefreshToken(r.Context(), req.RefreshToken)
+	if err != nil {
+		h.handleError(w, r, err)
 		return
 	}
 
-	authResp, err := h.authUsecase.RefreshToken(r.Context(), req.RefreshToken)
+	httpResp := &AuthResponse{}
+	httpResp.FromDomain(authResp)
+
+	response.OK(w, response.CodeSuccess, "Token refreshed successfully", httpResp)
+}
+
+// ForgotPassword handles asking for a password reset link
+// @Summary Forgot Password
+// @Description Mail a password reset link to the user with the email
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body ForgotPasswordRequest true "Email"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 429 {object} response.ErrorResponse
+// @Router /auth/forgot-password [post]
+func (h *Handler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	req, ok := httputil.Decode[ForgotPasswordRequest](w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.authUsecase.RequestPasswordReset(r.Context(), req.ToDomain()); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	// The same answer whether or not the email has an account
+	response.OK(w, response.CodeSuccess, "Password reset requested", MessageResponse{Message: "If the email is registered, a reset link has been sent to it"})
+}
+
+// ResetPassword handles setting a new password with a reset link
+// @Summary Reset Password
+// @Description Set a new password with the token of a reset link
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body ResetPasswordRequest true "Token and new password"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Router /auth/reset-password [post]
+func (h *Handler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	req, ok := httputil.Decode[ResetPasswordRequest](w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.authUsecase.ResetPassword(r.Context(), req.ToDomain()); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Password reset successfully", MessageResponse{Message: "Password has been reset, sign in with the new one"})
+}
+
+// VerifyEmail handles verifying an email with a verification link
+// @Summary Verify Email
+// @Description Verify the email of a user with the token of a verification link
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body VerifyEmailRequest true "Token"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Router /auth/verify-email [post]
+func (h *Handler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	req, ok := httputil.Decode[VerifyEmailRequest](w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.authUsecase.VerifyEmail(r.Context(), req.ToDomain()); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Email verified successfully", MessageResponse{Message: "Email has been verified, sign in to continue"})
+}
+
+// ResendVerification handles asking for a new verification link
+// @Summary Resend Verification
+// @Description Mail a new verification link to the pending user with the email
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body ResendVerificationRequest true "Email"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 429 {object} response.ErrorResponse
+// @Router /auth/resend-verification [post]
+func (h *Handler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	req, ok := httputil.Decode[ResendVerificationRequest](w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.authUsecase.ResendVerification(r.Context(), req.ToDomain()); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	// The same answer whether or not the email awaits verification
+	response.OK(w, response.CodeSuccess, "Verification requested", MessageResponse{Message: "If the email awaits verification, a new link has been sent to it"})
+}
+
+// OIDCProviders handles listing the external sign in providers
+// @Summary List Sign In Providers
+// @Description List the external providers users may sign in with
+// @Tags auth
+// @Produce json
+// @Success 200 {object} OIDCProvidersResponse
+// @Router /auth/oidc [get]
+func (h *Handler) OIDCProviders(w http.ResponseWriter, r *http.Request) {
+	providers := h.authUsecase.OIDCProviders(r.Context())
+	response.OK(w, response.CodeSuccess, "Providers retrieved successfully", OIDCProvidersResponse{Providers: providers})
+}
+
+// OIDCLogin handles starting to sign in with an external provider
+// @Summary Sign In With Provider
+// @Description Redirect to the sign in page of an external provider
+// @Tags auth
+// @Param provider path string true "Provider name"
+// @Success 302
+// @Failure 404 {object} response.ErrorResponse
+// @Router /auth/oidc/{provider}/login [get]
+func (h *Handler) OIDCLogin(w http.ResponseWriter, r *http.Request) {
+	authURL, err := h.authUsecase.StartOIDCLogin(r.Context(), chi.URLParam(r, "provider"))
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// OIDCCallback handles users coming back from an external provider
+// @Summary Finish Sign In With Provider
+// @Description Sign in the user an external provider sent back
+// @Tags auth
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Param code query string true "Authorization code"
+// @Param state query string true "State of the sign in"
+// @Success 200 {object} AuthResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Router /auth/oidc/{provider}/callback [get]
+func (h *Handler) OIDCCallback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	// The provider sends users back with an error when they cancel or it
+	// turns them away
+	if reason := query.Get("error"); reason != "" {
+		if description := query.Get("error_description"); description != "" {
+			reason += ": " + description
+		}
+		response.BadRequest(w, response.CodeBadRequest, "Sign in failed at the provider", []response.ErrorDetail{{Field: "error", Message: reason}})
+		return
+	}
+	req := &OIDCCallbackRequest{Code: query.Get("code"), State: query.Get("state")}
+	if req.Code == "" || req.State == "" {
+		response.BadRequest(w, response.CodeBadRequest, "Code and state are required", nil)
+		return
+	}
+
+	authResp, err := h.authUsecase.FinishOIDCLogin(r.Context(), chi.URLParam(r, "provider"), req.ToDomain())
+	if err != nil {
+		h.handleError(w, r, err)
 		return
 	}
 
 	httpResp := &AuthResponse{}
 	httpResp.FromDomain(authResp)
 
-	response.OK(w, response.CodeSuccess, "Token refreshed successfully", httpResp)
+	response.OK(w, response.CodeSuccess, "Login successful", httpResp)
 }
 
 // RevokeAllTokens handles revoking all user tokens
@@ -179,14 +353,14 @@ func (h *Handler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 // @Router /auth/revoke-all [post]
 func (h *Handler) RevokeAllTokens(w http.ResponseWriter, r *http.Request) {
 	// Get user ID from context (set by auth middleware)
-	userID := r.Context().Value("user_id").(string)
+	userID := auth.UserID(r.Context())
 	if userID == "" {
 		response.Unauthorized(w, response.CodeUnauthorized, "Unauthorized", nil)
 		return
 	}
 
 	if err := h.authUsecase.RevokeAllTokens(r.Context(), userID); err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -204,7 +378,7 @@ func (h *Handler) RevokeAllTokens(w http.ResponseWriter, r *http.Request) {
 // @Router /me [get]
 func (h *Handler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
 	// Get user ID from context (set by auth middleware)
-	userID := r.Context().Value("user_id").(string)
+	userID := auth.UserID(r.Context())
 	if userID == "" {
 		response.Unauthorized(w, response.CodeUnauthorized, "Unauthorized", nil)
 		return
@@ -212,7 +386,7 @@ func (h *Handler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
 
 	userInfo, err := h.authUsecase.GetCurrentUser(r.Context(), userID)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -229,77 +403,79 @@ func (h *Handler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
 	response.OK(w, response.CodeSuccess, "User retrieved successfully", httpResp)
 }
 
-// handleError handles errors and returns appropriate HTTP responses
-func (h *Handler) handleError(w http.ResponseWriter, err error) {
-	if err == nil {
+// ChangePassword handles a signed-in user replacing their password
+// @Summary Change Password
+// @Description Replace the password of the current user, signing out their other sessions
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Router /me/password [post]
+func (h *Handler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	if userID == "" {
+		response.Unauthorized(w, response.CodeUnauthorized, "Unauthorized", nil)
 		return
 	}
-
-	switch {
-	case errors.Is(err, errors.ErrInvalidCredentials):
-		response.Unauthorized(w, response.CodeUnauthorized, "Invalid credentials", nil)
-	case errors.Is(err, errors.ErrUserDisabled):
-		response.Forbidden(w, response.CodeForbidden, "User account is disabled", nil)
-	case errors.Is(err, errors.ErrEmailTaken):
-		response.Conflict(w, response.CodeConflict, "Email already registered", nil)
-	case errors.Is(err, errors.ErrUsernameTaken):
-		response.Conflict(w, response.CodeConflict, "Username already taken", nil)
-	case errors.Is(err, errors.ErrInvalidToken):
-		response.Unauthorized(w, response.CodeUnauthorized, "Invalid token", nil)
-	case errors.Is(err, errors.ErrTokenExpired):
-		response.Unauthorized(w, response.CodeUnauthorized, "Token expired", nil)
-	case errors.Is(err, errors.ErrTokenRevoked):
-		response.Unauthorized(w, response.CodeUnauthorized, "Token revoked", nil)
-	case errors.Is(err, errors.ErrNotFound):
-		response.NotFound(w, response.CodeNotFound, "Resource not found", nil)
-	default:
-		response.InternalError(w, response.CodeInternalServerError, "Internal server error", nil)
+	req, ok := httputil.Decode[ChangePasswordRequest](w, r)
+	if !ok {
+		return
 	}
-}
 
-// formatValidationErrors formats validation errors into ErrorDetail slice
-func (h *Handler) formatValidationErrors(err error) []response.ErrorDetail {
-	var details []response.ErrorDetail
-
-	if validationErrors, ok := err.(validator.ValidationErrors); ok {
-		for _, fieldErr := range validationErrors {
-			details = append(details, response.ErrorDetail{
-				Field:   fieldErr.Field(),
-				Message: h.getValidationErrorMessage(fieldErr),
-			})
-		}
+	// The session changing the password stays signed in
+	accessToken := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if err := h.authUsecase.ChangePassword(r.Context(), userID, accessToken, req.ToDomain()); err != nil {
+		h.handleError(w, r, err)
+		return
 	}
 
-	return details
+	response.OK(w, response.CodeSuccess, "Password changed successfully", MessageResponse{Message: "Password has been changed, other sessions were signed out"})
 }
 
-// getValidationErrorMessage returns a user-friendly validation error message
-func (h *Handler) getValidationErrorMessage(fieldErr validator.FieldError) string {
-	switch fieldErr.Tag() {
-	case "required":
-		return fieldErr.Field() + " is required"
-	case "email":
-		return fieldErr.Field() + " must be a valid email"
-	case "min":
-		return fieldErr.Field() + " must be at least " + fieldErr.Param() + " characters"
-	case "max":
-		return fieldErr.Field() + " must be at most " + fieldErr.Param() + " characters"
-	case "alphanum":
-		return fieldErr.Field() + " must contain only alphanumeric characters"
-	default:
-		return fieldErr.Field() + " is invalid"
-	}
+// handleError handles errors and returns appropriate HTTP responses
+// errorMapper maps the errors of the auth module on top of the shared ones
+var errorMapper = problem.Default.With(
+	problem.Mapping{Err: errors.ErrInvalidCredentials, Status: http.StatusUnauthorized, Code: response.CodeUnauthorized, Message: "Invalid credentials"},
+	problem.Mapping{Err: errors.ErrEmailNotVerified, Status: http.StatusForbidden, Code: response.CodeForbidden, Message: "Email address is not verified"},
+	problem.Mapping{Err: errors.ErrUserDisabled, Status: http.StatusForbidden, Code: response.CodeForbidden, Message: "User account is disabled"},
+	problem.Mapping{Err: errors.ErrEmailTaken, Status: http.StatusConflict, Code: response.CodeConflict, Message: "Email already registered"},
+	problem.Mapping{Err: errors.ErrUsernameTaken, Status: http.StatusConflict, Code: response.CodeConflict, Message: "Username already taken"},
+	problem.Mapping{Err: errors.ErrInvalidToken, Status: http.StatusUnauthorized, Code: response.CodeUnauthorized, Message: "Invalid token"},
+	problem.Mapping{Err: errors.ErrTokenExpired, Status: http.StatusUnauthorized, Code: response.CodeUnauthorized, Message: "Token expired"},
+	problem.Mapping{Err: errors.ErrTokenRevoked, Status: http.StatusUnauthorized, Code: response.CodeUnauthorized, Message: "Token revoked"},
+	problem.Mapping{Err: security.ErrCaptchaFailed, Status: http.StatusBadRequest, Code: response.CodeCaptchaFailed, Message: "Captcha verification failed"},
+)
+
+func (h *Handler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	errorMapper.Write(w, r, err)
 }
 
-// RegisterRoutes registers auth routes
-func RegisterRoutes(r chi.Router, handler *Handler) {
+// formatValidationErrors formats validation errors into ErrorDetail slice
+// getValidationErrorMessage returns a user-friendly validation error message
+// RegisterRoutes registers auth routes. Signing in and out, verifying an
+// email and resetting a forgotten password are public, with the mailed links
+// limited by linkLimit; the routes acting on the current user go through
+// auth.
+func RegisterRoutes(r chi.Router, handler *Handler, auth, linkLimit func(http.Handler) http.Handler) {
 	r.Route("/auth", func(r chi.Router) {
 		r.Post("/login", handler.Login)
 		r.Post("/register", handler.Register)
 		r.Post("/logout", handler.Logout)
 		r.Post("/refresh", handler.RefreshToken)
-		r.Post("/revoke-all", handler.RevokeAllTokens)
+		r.With(linkLimit).Post("/forgot-password", handler.ForgotPassword)
+		r.Post("/reset-password", handler.ResetPassword)
+		r.Post("/verify-email", handler.VerifyEmail)
+		r.With(linkLimit).Post("/resend-verification", handler.ResendVerification)
+		r.With(auth).Post("/revoke-all", handler.RevokeAllTokens)
+		r.Get("/oidc", handler.OIDCProviders)
+		r.Get("/oidc/{provider}/login", handler.OIDCLogin)
+		r.Get("/oidc/{provider}/callback", handler.OIDCCallback)
 	})
 
-	r.Get("/me", handler.GetCurrentUser)
+	r.With(auth).Get("/me", handler.GetCurrentUser)
+	r.With(auth).Post("/me/password", handler.ChangePassword)
 }
@@ -0,0 +1,26 @@
+package http
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+)
+
+// Describe adds the auth routes to the API specification
+func Describe(spec *openapi.Spec) {
+	api := spec.Tag("auth", "Sign in, registration and tokens")
+	api.Post("/auth/login", "Login").Public().Body(LoginRequest{}).Returns(http.StatusOK, AuthResponse{})
+	api.Post("/auth/register", "Register").Public().Body(RegisterRequest{}).Returns(http.StatusCreated, AuthResponse{})
+	api.Post("/auth/logout", "Logout").Public().Body(LogoutRequest{}).Returns(http.StatusOK, MessageResponse{})
+	api.Post("/auth/refresh", "Refresh access token").Public().Body(RefreshTokenRequest{}).Returns(http.StatusOK, AuthResponse{})
+	api.Post("/auth/forgot-password", "Ask for a password reset link").Public().Body(ForgotPasswordRequest{}).Returns(http.StatusOK, MessageResponse{})
+	api.Post("/auth/reset-password", "Reset password").Public().Body(ResetPasswordRequest{}).Returns(http.StatusOK, MessageResponse{})
+	api.Post("/auth/verify-email", "Verify email").Public().Body(VerifyEmailRequest{}).Returns(http.StatusOK, MessageResponse{})
+	api.Post("/auth/resend-verification", "Ask for a new email verification link").Public().Body(ResendVerificationRequest{}).Returns(http.StatusOK, MessageResponse{})
+	api.Get("/auth/oidc", "List external sign in providers").Public().Returns(http.StatusOK, OIDCProvidersResponse{})
+	api.Get("/auth/oidc/{provider}/login", "Sign in with an external provider").Public().ReturnsNothing(http.StatusFound)
+	api.Get("/auth/oidc/{provider}/callback", "Finish signing in with an external provider").Public().Query(OIDCCallbackRequest{}).Returns(http.StatusOK, AuthResponse{})
+	api.Post("/auth/revoke-all", "Revoke all tokens of the current user").Returns(http.StatusOK, MessageResponse{})
+	api.Get("/me", "Get current user").Returns(http.StatusOK, UserInfo{})
+	api.Post("/me/password", "Change the password of the current user").Body(ChangePasswordRequest{}).Returns(http.StatusOK, MessageResponse{})
+}
@@ -6,8 +6,9 @@ import (
 
 // LoginRequest represents HTTP request for login
 type LoginRequest struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=8"`
+	Email        string `json:"email" validate:"required,email"`
+	Password     string `json:"password" validate:"required,min=8"`
+	CaptchaToken string `json:"captcha_token,omitempty"`
 }
 
 // ToDomain converts HTTP request to domain
@@ -30,6 +31,7 @@ type RegisterRequest struct {
 	Password       string `json:"password" validate:"required,min=8"`
 	Address        string `json:"address,omitempty" validate:"omitempty"`
 	Phone          string `json:"phone,omitempty" validate:"omitempty"`
+	CaptchaToken   string `json:"captcha_token,omitempty"`
 }
 
 // ToDomain converts HTTP request to domain
@@ -64,3 +66,85 @@ func (r *RefreshTokenRequest) ToDomain() *domain.RefreshTokenRequest {
 type LogoutRequest struct {
 	RefreshToken string `json:"refresh_token" validate:"required"`
 }
+
+// ForgotPasswordRequest represents HTTP request for a password reset link
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ToDomain converts HTTP request to domain
+func (r *ForgotPasswordRequest) ToDomain() *domain.ForgotPasswordRequest {
+	return &domain.ForgotPasswordRequest{
+		Email: r.Email,
+	}
+}
+
+// ResetPasswordRequest represents HTTP request for setting a new password
+// with the token of a reset link
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8"`
+}
+
+// ToDomain converts HTTP request to domain
+func (r *ResetPasswordRequest) ToDomain() *domain.ResetPasswordRequest {
+	return &domain.ResetPasswordRequest{
+		Token:    r.Token,
+		Password: r.Password,
+	}
+}
+
+// ChangePasswordRequest represents HTTP request for a signed-in user
+// replacing their password
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=8"`
+}
+
+// ToDomain converts HTTP request to domain
+func (r *ChangePasswordRequest) ToDomain() *domain.ChangePasswordRequest {
+	return &domain.ChangePasswordRequest{
+		CurrentPassword: r.CurrentPassword,
+		NewPassword:     r.NewPassword,
+	}
+}
+
+// VerifyEmailRequest represents HTTP request for verifying an email
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// ToDomain converts HTTP request to domain
+func (r *VerifyEmailRequest) ToDomain() *domain.VerifyEmailRequest {
+	return &domain.VerifyEmailRequest{
+		Token: r.Token,
+	}
+}
+
+// ResendVerificationRequest represents HTTP request for a new verification
+// link
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ToDomain converts HTTP request to domain
+func (r *ResendVerificationRequest) ToDomain() *domain.ResendVerificationRequest {
+	return &domain.ResendVerificationRequest{
+		Email: r.Email,
+	}
+}
+
+// OIDCCallbackRequest represents the query users come back from an external
+// provider with
+type OIDCCallbackRequest struct {
+	Code  string `json:"code" validate:"required"`
+	State string `json:"state" validate:"required"`
+}
+
+// ToDomain converts HTTP request to domain
+func (r *OIDCCallbackRequest) ToDomain() *domain.OIDCCallbackRequest {
+	return &domain.OIDCCallbackRequest{
+		Code:  r.Code,
+		State: r.State,
+	}
+}
@@ -11,6 +11,9 @@ type AuthResponse struct {
 	RefreshToken string   `json:"refresh_token"`
 	ExpiresIn    int64    `json:"expires_in"`
 	TokenType    string   `json:"token_type"`
+	// VerificationRequired is set, without tokens, when the user has to
+	// verify their email before signing in
+	VerificationRequired bool `json:"verification_required,omitempty"`
 }
 
 // FromDomain converts domain response to HTTP response
@@ -28,6 +31,7 @@ func (r *AuthResponse) FromDomain(resp *domain.AuthResponse) {
 	r.RefreshToken = resp.RefreshToken
 	r.ExpiresIn = resp.ExpiresIn
 	r.TokenType = resp.TokenType
+	r.VerificationRequired = resp.VerificationRequired
 }
 
 // UserInfo represents user information in HTTP response
@@ -45,3 +49,8 @@ type UserInfo struct {
 type MessageResponse struct {
 	Message string `json:"message"`
 }
+
+// OIDCProvidersResponse lists the external providers users may sign in with
+type OIDCProvidersResponse struct {
+	Providers []string `json:"providers"`
+}
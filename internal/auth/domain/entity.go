@@ -27,6 +27,7 @@ type User struct {
 type UserStatus string
 
 const (
+	UserStatusPending  UserStatus = "pending"
 	UserStatusActive   UserStatus = "active"
 	UserStatusInactive UserStatus = "inactive"
 	UserStatusSuspended UserStatus = "suspended"
@@ -38,15 +39,16 @@ func (u *User) IsActive() bool {
 	return u.Status == UserStatusActive
 }
 
-// Token represents a refresh token entity
+// Token represents an issued token pair. Only the SHA-256 digests of the
+// JWTs are kept, so the stored tokens cannot be used to sign in.
 type Token struct {
-	ID           string    `db:"id" json:"id"`
-	UserID       string    `db:"user_id" json:"user_id"`
-	AccessToken  string    `db:"access_token" json:"access_token"`
-	RefreshToken string    `db:"refresh_token" json:"refresh_token"`
-	ExpiresAt    time.Time `db:"expires_at" json:"expires_at"`
-	Revoked      bool      `db:"revoked" json:"revoked"`
-	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+	ID               string    `db:"id" json:"id"`
+	UserID           string    `db:"user_id" json:"user_id"`
+	AccessTokenHash  string    `db:"access_token_hash" json:"-"`
+	RefreshTokenHash string    `db:"refresh_token_hash" json:"-"`
+	ExpiresAt        time.Time `db:"expires_at" json:"expires_at"`
+	Revoked          bool      `db:"revoked" json:"revoked"`
+	CreatedAt        time.Time `db:"created_at" json:"created_at"`
 }
 
 // IsExpired checks if token is expired
@@ -59,6 +61,63 @@ func (t *Token) IsValid() bool {
 	return !t.Revoked && !t.IsExpired()
 }
 
+// PasswordResetToken lets a user who forgot their password set a new one.
+// Only the SHA-256 digest of the token mailed to them is kept; the token is
+// used once, before ExpiresAt.
+type PasswordResetToken struct {
+	ID        string     `db:"id" json:"id"`
+	UserID    string     `db:"user_id" json:"user_id"`
+	TokenHash string     `db:"token_hash" json:"-"`
+	ExpiresAt time.Time  `db:"expires_at" json:"expires_at"`
+	UsedAt    *time.Time `db:"used_at" json:"used_at,omitempty"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+}
+
+// PasswordHistory is a password a user had before their current one, kept
+// as its bcrypt hash so the password policy can refuse reusing it
+type PasswordHistory struct {
+	ID           string    `db:"id" json:"id"`
+	UserID       string    `db:"user_id" json:"user_id"`
+	PasswordHash string    `db:"password_hash" json:"-"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+}
+
+// EmailVerificationToken confirms a registered user owns their email. Only
+// the SHA-256 digest of the token mailed to them is kept; the token is used
+// once, before ExpiresAt.
+type EmailVerificationToken struct {
+	ID        string     `db:"id" json:"id"`
+	UserID    string     `db:"user_id" json:"user_id"`
+	TokenHash string     `db:"token_hash" json:"-"`
+	ExpiresAt time.Time  `db:"expires_at" json:"expires_at"`
+	UsedAt    *time.Time `db:"used_at" json:"used_at,omitempty"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+}
+
+// Identity links a user to their account at an external OpenID Connect
+// provider, which knows them by Subject
+type Identity struct {
+	ID        string    `db:"id" json:"id"`
+	UserID    string    `db:"user_id" json:"user_id"`
+	Provider  string    `db:"provider" json:"provider"`
+	Subject   string    `db:"subject" json:"subject"`
+	Email     string    `db:"email" json:"email"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// OIDCLogin is a sign in started with an external provider, waiting for the
+// user to come back. Only the SHA-256 digest of its state is kept; it is
+// finished once, before ExpiresAt.
+type OIDCLogin struct {
+	ID        string    `db:"id" json:"id"`
+	Provider  string    `db:"provider" json:"provider"`
+	StateHash string    `db:"state_hash" json:"-"`
+	Nonce     string    `db:"nonce" json:"-"`
+	Verifier  string    `db:"code_verifier" json:"-"`
+	ExpiresAt time.Time `db:"expires_at" json:"expires_at"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
 // LoginRequest represents login input
 type LoginRequest struct {
 	Email    string `json:"email" validate:"required,email"`
@@ -84,6 +143,44 @@ type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" validate:"required"`
 }
 
+// ForgotPasswordRequest represents the input asking for a password reset link
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ResetPasswordRequest represents the input setting a new password with the
+// token of a reset link
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8"`
+}
+
+// ChangePasswordRequest represents the input of a signed-in user replacing
+// their password
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=8"`
+}
+
+// VerifyEmailRequest represents the input verifying an email with the token
+// of a verification link
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// ResendVerificationRequest represents the input asking for a new
+// verification link
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// OIDCCallbackRequest represents the input users come back from an external
+// provider with
+type OIDCCallbackRequest struct {
+	Code  string `json:"code" validate:"required"`
+	State string `json:"state" validate:"required"`
+}
+
 // AuthResponse represents authentication response
 type AuthResponse struct {
 	User         UserInfo    `json:"user"`
@@ -91,6 +188,9 @@ type AuthResponse struct {
 	RefreshToken string      `json:"refresh_token"`
 	ExpiresIn    int64       `json:"expires_in"`
 	TokenType    string      `json:"token_type"`
+	// VerificationRequired is set, without tokens, when the user has to
+	// verify their email before signing in
+	VerificationRequired bool `json:"verification_required,omitempty"`
 }
 
 // UserInfo represents user information in auth response
@@ -112,6 +212,8 @@ type TokenClaims struct {
 	Name           string
 	Username       string
 	Email          string
+	// TenantID is the portal that issued the token
+	TenantID string
 }
 
 // ToUserInfo converts User to UserInfo
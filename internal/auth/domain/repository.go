@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"time"
 )
 
 // UserRepository defines the interface for user data operations
@@ -39,11 +40,13 @@ type TokenRepository interface {
 	// CreateToken creates a new token
 	CreateToken(ctx context.Context, token *Token) error
 
-	// GetTokenByRefreshToken retrieves a token by refresh token
-	GetTokenByRefreshToken(ctx context.Context, refreshToken string) (*Token, error)
+	// GetTokenByRefreshTokenHash retrieves a token by the digest of its
+	// refresh token
+	GetTokenByRefreshTokenHash(ctx context.Context, refreshTokenHash string) (*Token, error)
 
-	// GetTokenByAccessToken retrieves a token by access token
-	GetTokenByAccessToken(ctx context.Context, accessToken string) (*Token, error)
+	// GetTokenByAccessTokenHash retrieves a token by the digest of its access
+	// token
+	GetTokenByAccessTokenHash(ctx context.Context, accessTokenHash string) (*Token, error)
 
 	// RevokeToken revokes a token by ID
 	RevokeToken(ctx context.Context, id string) error
@@ -51,9 +54,98 @@ type TokenRepository interface {
 	// RevokeUserTokens revokes all tokens for a user
 	RevokeUserTokens(ctx context.Context, userID string) error
 
+	// RevokeOtherUserTokens revokes the tokens of a user but the one with ID
+	// keepID
+	RevokeOtherUserTokens(ctx context.Context, userID, keepID string) error
+
 	// DeleteToken deletes a token by ID
 	DeleteToken(ctx context.Context, id string) error
 
-	// CleanupExpiredTokens deletes expired tokens
-	CleanupExpiredTokens(ctx context.Context) error
+	// CleanupExpiredTokens deletes expired and revoked tokens, returning how
+	// many it deleted
+	CleanupExpiredTokens(ctx context.Context) (int64, error)
+}
+
+// PasswordResetRepository defines the interface for password reset token
+// data operations
+type PasswordResetRepository interface {
+	// CreatePasswordResetToken stores a new password reset token
+	CreatePasswordResetToken(ctx context.Context, token *PasswordResetToken) error
+
+	// ConsumePasswordResetToken marks the token with the digest tokenHash
+	// used and returns it, unless it was used already or expired by now
+	ConsumePasswordResetToken(ctx context.Context, tokenHash string, now time.Time) (*PasswordResetToken, error)
+
+	// DeleteUserPasswordResetTokens deletes the password reset tokens of a
+	// user
+	DeleteUserPasswordResetTokens(ctx context.Context, userID string) error
+
+	// DeleteExpiredPasswordResetTokens deletes the tokens used or expired by
+	// now, returning how many it deleted
+	DeleteExpiredPasswordResetTokens(ctx context.Context, now time.Time) (int64, error)
+}
+
+// PasswordHistoryRepository defines the interface for the data operations
+// of the passwords users had before their current one
+type PasswordHistoryRepository interface {
+	// ListPasswordHistory retrieves the hashes of the limit last passwords of
+	// a user before their current one, the latest first
+	ListPasswordHistory(ctx context.Context, userID string, limit int) ([]string, error)
+
+	// AddPasswordHistory stores a password a user had, keeping only their
+	// keep latest ones
+	AddPasswordHistory(ctx context.Context, entry *PasswordHistory, keep int) error
+}
+
+// EmailVerificationRepository defines the interface for email verification
+// token data operations
+type EmailVerificationRepository interface {
+	// CreateEmailVerificationToken stores a new email verification token
+	CreateEmailVerificationToken(ctx context.Context, token *EmailVerificationToken) error
+
+	// ConsumeEmailVerificationToken marks the token with the digest
+	// tokenHash used and returns it, unless it was used already or expired
+	// by now
+	ConsumeEmailVerificationToken(ctx context.Context, tokenHash string, now time.Time) (*EmailVerificationToken, error)
+
+	// DeleteUserEmailVerificationTokens deletes the email verification
+	// tokens of a user
+	DeleteUserEmailVerificationTokens(ctx context.Context, userID string) error
+
+	// DeleteExpiredEmailVerificationTokens deletes the tokens used or
+	// expired by now, returning how many it deleted
+	DeleteExpiredEmailVerificationTokens(ctx context.Context, now time.Time) (int64, error)
+}
+
+// IdentityRepository defines the interface for the data operations of the
+// identities linking users to external providers
+type IdentityRepository interface {
+	// GetIdentity retrieves the identity the provider knows by subject
+	GetIdentity(ctx context.Context, provider, subject string) (*Identity, error)
+
+	// CreateIdentity links a user to an external provider
+	CreateIdentity(ctx context.Context, identity *Identity) error
+}
+
+// OIDCLoginRepository defines the interface for the data operations of the
+// sign ins started with external providers
+type OIDCLoginRepository interface {
+	// CreateOIDCLogin stores a started sign in, deleting the ones that
+	// expired by its creation
+	CreateOIDCLogin(ctx context.Context, login *OIDCLogin) error
+
+	// ConsumeOIDCLogin deletes the sign in with provider and the digest
+	// stateHash and returns it, unless it expired by now
+	ConsumeOIDCLogin(ctx context.Context, provider, stateHash string, now time.Time) (*OIDCLogin, error)
+}
+
+// EventPublisher emits account events to interested integrations and sinks
+type EventPublisher interface {
+	Publish(ctx context.Context, eventType string, data interface{}) error
 }
+
+// Account event types
+const (
+	EventUserRegistered = "user.registered"
+	EventUserVerified   = "user.verified"
+)
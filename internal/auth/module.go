@@ -0,0 +1,106 @@
+// Package auth is the module signing users in and issuing their tokens.
+package auth
+
+import (
+	"net/http"
+	"time"
+
+	portalv1 "portal-data-backend/api/proto/portal/v1"
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/infrastructure/http/middleware"
+	"portal-data-backend/infrastructure/http/openapi"
+	"portal-data-backend/infrastructure/mail"
+	"portal-data-backend/infrastructure/security"
+	"portal-data-backend/infrastructure/security/oidc"
+	"portal-data-backend/internal/app"
+	"portal-data-backend/internal/auth/delivery/cli"
+	authgrpc "portal-data-backend/internal/auth/delivery/grpc"
+	delivery "portal-data-backend/internal/auth/delivery/http"
+	"portal-data-backend/internal/auth/repository"
+	"portal-data-backend/internal/auth/usecase"
+
+	"github.com/go-chi/chi/v5"
+	"google.golang.org/grpc"
+)
+
+// Module signs users in and out
+type Module struct {
+	handler   *delivery.Handler
+	usecase   usecase.Usecase
+	adminRole string
+	// tokenCleanup is how often unusable tokens are deleted
+	tokenCleanup time.Duration
+	// linkLimiter limits how often each client asks for mailed links
+	linkLimiter *middleware.RateLimiter
+}
+
+// Name implements app.Module
+func (m *Module) Name() string {
+	return "auth"
+}
+
+// Register implements app.Module
+func (m *Module) Register(deps *app.Deps) error {
+	if deps.Services.Templates == nil {
+		return app.MissingServiceError("message template")
+	}
+	deps.Services.Templates.Define(usecase.Messages...)
+
+	users := repository.NewUserPostgresRepository(deps.DB)
+	tokens := repository.NewTokenPostgresRepository(deps.DB)
+	resets := repository.NewPasswordResetPostgresRepository(deps.DB)
+	verifications := repository.NewEmailVerificationPostgresRepository(deps.DB)
+	history := repository.NewPasswordHistoryPostgresRepository(deps.DB)
+	identities := repository.NewIdentityPostgresRepository(deps.DB)
+	logins := repository.NewOIDCLoginPostgresRepository(deps.DB)
+	providers := make(map[string]usecase.IdentityProvider, len(deps.Config.OIDC.Providers))
+	for _, provider := range deps.Config.OIDC.Providers {
+		providers[provider.Name] = oidc.NewProvider(provider, deps.Config.OIDC.RedirectURL)
+	}
+	passwords := security.NewPasswordHandler()
+	passwords.SetPolicy(deps.Config.Password)
+	authUsecase := usecase.NewAuthUsecase(users, tokens, resets, verifications, history, deps.JWT, passwords, deps.Outbox,
+		mail.NewSender(deps.Config.Mail), deps.Services.Templates, deps.Config.Recovery, deps.Config.Signup,
+		identities, logins, providers, deps.Config.OIDC, deps.Services.AuthAudit)
+	m.handler = delivery.NewHandler(authUsecase, security.NewCaptchaVerifier(deps.Config.AuthCaptcha.VerifyURL, deps.Config.AuthCaptcha.Secret))
+	m.usecase = authUsecase
+	deps.Services.Accounts = authUsecase
+	m.tokenCleanup = deps.Config.Cleanup.TokenInterval
+	if roles := deps.Config.Audit.AdminRoles; len(roles) > 0 {
+		m.adminRole = roles[0]
+	}
+
+	// The mailed link rate limit and the password policy follow
+	// configuration reloads
+	m.linkLimiter = middleware.NewRateLimiter(deps.Config.Recovery.RateLimit, deps.Config.Recovery.RateWindow)
+	deps.Reloader.Subscribe(func(cfg *config.Config) {
+		m.linkLimiter.Set(cfg.Recovery.RateLimit, cfg.Recovery.RateWindow)
+		passwords.SetPolicy(cfg.Password)
+	})
+	return nil
+}
+
+// Routes implements app.Module
+func (m *Module) Routes(r chi.Router, auth func(http.Handler) http.Handler) {
+	delivery.RegisterRoutes(r, m.handler, auth, m.linkLimiter.Handler)
+}
+
+// Describe implements app.Module
+func (m *Module) Describe(spec *openapi.Spec) {
+	delivery.Describe(spec)
+}
+
+// RegisterGRPC implements app.GRPCRegistrar
+func (m *Module) RegisterGRPC(s grpc.ServiceRegistrar) {
+	portalv1.RegisterAuthServiceServer(s, authgrpc.NewServer(m.usecase))
+}
+
+// Jobs implements app.Scheduler
+func (m *Module) Jobs() []app.Job {
+	return []app.Job{{Name: "token cleanup", Interval: m.tokenCleanup, Run: m.usecase.CleanupExpiredTokens}}
+}
+
+// Commands implements app.Commander
+func (m *Module) Commands() []app.Command {
+	return cli.Commands(m.usecase, m.adminRole)
+}
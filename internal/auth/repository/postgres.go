@@ -2,9 +2,11 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"time"
 
+	"portal-data-backend/infrastructure/db"
 	"portal-data-backend/internal/auth/domain"
 	"portal-data-backend/pkg/errors"
 
@@ -27,11 +29,10 @@ func (r *userPostgresRepository) GetUserByID(ctx context.Context, id string) (*d
 		SELECT id, organization_id, role_id, name, username, employee_id, position,
 		       email, password_hash, address, phone, thumbnail, status, created_at, updated_at
 		FROM users
-		WHERE id = $1 AND status != 'deleted'
-	`
+		WHERE id = $1 AND status != 'deleted' AND ` + db.InTenantOrganizations(ctx, "organization_id")
 
 	var user domain.User
-	err := r.db.GetContext(ctx, &user, query, id)
+	err := db.Conn(ctx, r.db).GetContext(ctx, &user, query, id)
 	if err != nil {
 		return nil, r.handleError(err)
 	}
@@ -45,11 +46,10 @@ func (r *userPostgresRepository) GetUserByEmail(ctx context.Context, email strin
 		SELECT id, organization_id, role_id, name, username, employee_id, position,
 		       email, password_hash, address, phone, thumbnail, status, created_at, updated_at
 		FROM users
-		WHERE email = $1 AND status != 'deleted'
-	`
+		WHERE email = $1 AND status != 'deleted' AND ` + db.InTenantOrganizations(ctx, "organization_id")
 
 	var user domain.User
-	err := r.db.GetContext(ctx, &user, query, email)
+	err := db.Conn(ctx, r.db).GetContext(ctx, &user, query, email)
 	if err != nil {
 		return nil, r.handleError(err)
 	}
@@ -63,11 +63,10 @@ func (r *userPostgresRepository) GetUserByUsername(ctx context.Context, username
 		SELECT id, organization_id, role_id, name, username, employee_id, position,
 		       email, password_hash, address, phone, thumbnail, status, created_at, updated_at
 		FROM users
-		WHERE username = $1 AND status != 'deleted'
-	`
+		WHERE username = $1 AND status != 'deleted' AND ` + db.InTenantOrganizations(ctx, "organization_id")
 
 	var user domain.User
-	err := r.db.GetContext(ctx, &user, query, username)
+	err := db.Conn(ctx, r.db).GetContext(ctx, &user, query, username)
 	if err != nil {
 		return nil, r.handleError(err)
 	}
@@ -87,7 +86,7 @@ func (r *userPostgresRepository) CreateUser(ctx context.Context, user *domain.Us
 		)
 	`
 
-	_, err := r.db.NamedExecContext(ctx, query, user)
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, user)
 	if err != nil {
 		return fmt.Errorf("failed to create user: %w", err)
 	}
@@ -117,7 +116,7 @@ func (r *userPostgresRepository) UpdateUser(ctx context.Context, user *domain.Us
 		WHERE id = :id
 	`
 
-	result, err := r.db.NamedExecContext(ctx, query, user)
+	result, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, user)
 	if err != nil {
 		return fmt.Errorf("failed to update user: %w", err)
 	}
@@ -138,7 +137,7 @@ func (r *userPostgresRepository) DeleteUser(ctx context.Context, id string) erro
 		WHERE id = $1
 	`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := db.Conn(ctx, r.db).ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete user: %w", err)
 	}
@@ -156,7 +155,7 @@ func (r *userPostgresRepository) ListUsers(ctx context.Context, limit, offset in
 	// Get total count
 	countQuery := `SELECT COUNT(*) FROM users WHERE status != 'deleted'`
 	var total int
-	err := r.db.GetContext(ctx, &total, countQuery)
+	err := db.Conn(ctx, r.db).GetContext(ctx, &total, countQuery)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count users: %w", err)
 	}
@@ -172,7 +171,7 @@ func (r *userPostgresRepository) ListUsers(ctx context.Context, limit, offset in
 	`
 
 	var users []*domain.User
-	err = r.db.SelectContext(ctx, &users, query, limit, offset)
+	err = db.Conn(ctx, r.db).SelectContext(ctx, &users, query, limit, offset)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to list users: %w", err)
 	}
@@ -185,7 +184,7 @@ func (r *userPostgresRepository) IsEmailExists(ctx context.Context, email string
 	query := `SELECT EXISTS(SELECT 1 FROM users WHERE email = $1 AND status != 'deleted')`
 
 	var exists bool
-	err := r.db.GetContext(ctx, &exists, query, email)
+	err := db.Conn(ctx, r.db).GetContext(ctx, &exists, query, email)
 	if err != nil {
 		return false, fmt.Errorf("failed to check email existence: %w", err)
 	}
@@ -198,7 +197,7 @@ func (r *userPostgresRepository) IsUsernameExists(ctx context.Context, username
 	query := `SELECT EXISTS(SELECT 1 FROM users WHERE username = $1 AND status != 'deleted')`
 
 	var exists bool
-	err := r.db.GetContext(ctx, &exists, query, username)
+	err := db.Conn(ctx, r.db).GetContext(ctx, &exists, query, username)
 	if err != nil {
 		return false, fmt.Errorf("failed to check username existence: %w", err)
 	}
@@ -228,11 +227,11 @@ func NewTokenPostgresRepository(db *sqlx.DB) domain.TokenRepository {
 // CreateToken creates a new token
 func (r *tokenPostgresRepository) CreateToken(ctx context.Context, token *domain.Token) error {
 	query := `
-		INSERT INTO tokens (id, user_id, access_token, refresh_token, expires_at, revoked, created_at)
-		VALUES (:id, :user_id, :access_token, :refresh_token, :expires_at, :revoked, :created_at)
+		INSERT INTO tokens (id, user_id, access_token_hash, refresh_token_hash, expires_at, revoked, created_at)
+		VALUES (:id, :user_id, :access_token_hash, :refresh_token_hash, :expires_at, :revoked, :created_at)
 	`
 
-	_, err := r.db.NamedExecContext(ctx, query, token)
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, token)
 	if err != nil {
 		return fmt.Errorf("failed to create token: %w", err)
 	}
@@ -240,18 +239,18 @@ func (r *tokenPostgresRepository) CreateToken(ctx context.Context, token *domain
 	return nil
 }
 
-// GetTokenByRefreshToken retrieves a token by refresh token
-func (r *tokenPostgresRepository) GetTokenByRefreshToken(ctx context.Context, refreshToken string) (*domain.Token, error) {
+// GetTokenByRefreshTokenHash retrieves a token by the digest of its refresh token
+func (r *tokenPostgresRepository) GetTokenByRefreshTokenHash(ctx context.Context, refreshTokenHash string) (*domain.Token, error) {
 	query := `
-		SELECT id, user_id, access_token, refresh_token, expires_at, revoked, created_at
+		SELECT id, user_id, access_token_hash, refresh_token_hash, expires_at, revoked, created_at
 		FROM tokens
-		WHERE refresh_token = $1
+		WHERE refresh_token_hash = $1
 		ORDER BY created_at DESC
 		LIMIT 1
 	`
 
 	var token domain.Token
-	err := r.db.GetContext(ctx, &token, query, refreshToken)
+	err := db.Conn(ctx, r.db).GetContext(ctx, &token, query, refreshTokenHash)
 	if err != nil {
 		return nil, r.handleError(err)
 	}
@@ -259,18 +258,18 @@ func (r *tokenPostgresRepository) GetTokenByRefreshToken(ctx context.Context, re
 	return &token, nil
 }
 
-// GetTokenByAccessToken retrieves a token by access token
-func (r *tokenPostgresRepository) GetTokenByAccessToken(ctx context.Context, accessToken string) (*domain.Token, error) {
+// GetTokenByAccessTokenHash retrieves a token by the digest of its access token
+func (r *tokenPostgresRepository) GetTokenByAccessTokenHash(ctx context.Context, accessTokenHash string) (*domain.Token, error) {
 	query := `
-		SELECT id, user_id, access_token, refresh_token, expires_at, revoked, created_at
+		SELECT id, user_id, access_token_hash, refresh_token_hash, expires_at, revoked, created_at
 		FROM tokens
-		WHERE access_token = $1
+		WHERE access_token_hash = $1
 		ORDER BY created_at DESC
 		LIMIT 1
 	`
 
 	var token domain.Token
-	err := r.db.GetContext(ctx, &token, query, accessToken)
+	err := db.Conn(ctx, r.db).GetContext(ctx, &token, query, accessTokenHash)
 	if err != nil {
 		return nil, r.handleError(err)
 	}
@@ -282,7 +281,7 @@ func (r *tokenPostgresRepository) GetTokenByAccessToken(ctx context.Context, acc
 func (r *tokenPostgresRepository) RevokeToken(ctx context.Context, id string) error {
 	query := `UPDATE tokens SET revoked = true WHERE id = $1`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := db.Conn(ctx, r.db).ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to revoke token: %w", err)
 	}
@@ -299,7 +298,20 @@ func (r *tokenPostgresRepository) RevokeToken(ctx context.Context, id string) er
 func (r *tokenPostgresRepository) RevokeUserTokens(ctx context.Context, userID string) error {
 	query := `UPDATE tokens SET revoked = true WHERE user_id = $1`
 
-	_, err := r.db.ExecContext(ctx, query, userID)
+	_, err := db.Conn(ctx, r.db).ExecContext(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke user tokens: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeOtherUserTokens revokes the tokens of a user but the one with ID
+// keepID
+func (r *tokenPostgresRepository) RevokeOtherUserTokens(ctx context.Context, userID, keepID string) error {
+	query := `UPDATE tokens SET revoked = true WHERE user_id = $1 AND id <> $2`
+
+	_, err := db.Conn(ctx, r.db).ExecContext(ctx, query, userID, keepID)
 	if err != nil {
 		return fmt.Errorf("failed to revoke user tokens: %w", err)
 	}
@@ -311,7 +323,7 @@ func (r *tokenPostgresRepository) RevokeUserTokens(ctx context.Context, userID s
 func (r *tokenPostgresRepository) DeleteToken(ctx context.Context, id string) error {
 	query := `DELETE FROM tokens WHERE id = $1`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := db.Conn(ctx, r.db).ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete token: %w", err)
 	}
@@ -324,16 +336,16 @@ func (r *tokenPostgresRepository) DeleteToken(ctx context.Context, id string) er
 	return nil
 }
 
-// CleanupExpiredTokens deletes expired tokens
-func (r *tokenPostgresRepository) CleanupExpiredTokens(ctx context.Context) error {
+// CleanupExpiredTokens deletes expired and revoked tokens
+func (r *tokenPostgresRepository) CleanupExpiredTokens(ctx context.Context) (int64, error) {
 	query := `DELETE FROM tokens WHERE expires_at < NOW() OR revoked = true`
 
-	_, err := r.db.ExecContext(ctx, query)
+	result, err := db.Conn(ctx, r.db).ExecContext(ctx, query)
 	if err != nil {
-		return fmt.Errorf("failed to cleanup expired tokens: %w", err)
+		return 0, fmt.Errorf("failed to cleanup expired tokens: %w", err)
 	}
 
-	return nil
+	return result.RowsAffected()
 }
 
 // handleError handles database errors for token repository
@@ -344,3 +356,300 @@ func (r *tokenPostgresRepository) handleError(err error) error {
 
 	return errors.Wrap(err, "database error")
 }
+
+// passwordResetPostgresRepository implements PasswordResetRepository for
+// PostgreSQL
+type passwordResetPostgresRepository struct {
+	db *sqlx.DB
+}
+
+// NewPasswordResetPostgresRepository creates a new password reset token
+// repository
+func NewPasswordResetPostgresRepository(db *sqlx.DB) domain.PasswordResetRepository {
+	return &passwordResetPostgresRepository{db: db}
+}
+
+// CreatePasswordResetToken stores a new password reset token
+func (r *passwordResetPostgresRepository) CreatePasswordResetToken(ctx context.Context, token *domain.PasswordResetToken) error {
+	query := `
+		INSERT INTO password_reset_tokens (id, user_id, token_hash, expires_at, used_at, created_at)
+		VALUES (:id, :user_id, :token_hash, :expires_at, :used_at, :created_at)
+	`
+
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, token)
+	if err != nil {
+		return fmt.Errorf("failed to create password reset token: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumePasswordResetToken marks an unused, unexpired token used in the
+// same statement that finds it, so a token is only ever consumed once
+func (r *passwordResetPostgresRepository) ConsumePasswordResetToken(ctx context.Context, tokenHash string, now time.Time) (*domain.PasswordResetToken, error) {
+	query := `
+		UPDATE password_reset_tokens
+		SET used_at = $2
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > $2
+		RETURNING id, user_id, token_hash, expires_at, used_at, created_at
+	`
+
+	var token domain.PasswordResetToken
+	err := db.Conn(ctx, r.db).GetContext(ctx, &token, query, tokenHash, now)
+	if err == sql.ErrNoRows {
+		return nil, errors.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume password reset token: %w", err)
+	}
+
+	return &token, nil
+}
+
+// DeleteUserPasswordResetTokens deletes the password reset tokens of a user
+func (r *passwordResetPostgresRepository) DeleteUserPasswordResetTokens(ctx context.Context, userID string) error {
+	query := `DELETE FROM password_reset_tokens WHERE user_id = $1`
+
+	_, err := db.Conn(ctx, r.db).ExecContext(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete password reset tokens: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpiredPasswordResetTokens deletes the tokens used or expired by now
+func (r *passwordResetPostgresRepository) DeleteExpiredPasswordResetTokens(ctx context.Context, now time.Time) (int64, error) {
+	query := `DELETE FROM password_reset_tokens WHERE used_at IS NOT NULL OR expires_at <= $1`
+
+	result, err := db.Conn(ctx, r.db).ExecContext(ctx, query, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired password reset tokens: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// passwordHistoryPostgresRepository implements PasswordHistoryRepository for
+// PostgreSQL
+type passwordHistoryPostgresRepository struct {
+	db *sqlx.DB
+}
+
+// NewPasswordHistoryPostgresRepository creates a new password history
+// repository
+func NewPasswordHistoryPostgresRepository(db *sqlx.DB) domain.PasswordHistoryRepository {
+	return &passwordHistoryPostgresRepository{db: db}
+}
+
+// ListPasswordHistory retrieves the hashes of the last passwords of a user
+func (r *passwordHistoryPostgresRepository) ListPasswordHistory(ctx context.Context, userID string, limit int) ([]string, error) {
+	query := `
+		SELECT password_hash FROM password_history
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+
+	var hashes []string
+	if err := db.Conn(ctx, r.db).SelectContext(ctx, &hashes, query, userID, limit); err != nil {
+		return nil, fmt.Errorf("failed to list password history: %w", err)
+	}
+
+	return hashes, nil
+}
+
+// AddPasswordHistory stores a former password of a user and deletes the
+// ones past the keep latest
+func (r *passwordHistoryPostgresRepository) AddPasswordHistory(ctx context.Context, entry *domain.PasswordHistory, keep int) error {
+	conn := db.Conn(ctx, r.db)
+	query := `
+		INSERT INTO password_history (id, user_id, password_hash, created_at)
+		VALUES (:id, :user_id, :password_hash, :created_at)
+	`
+	if _, err := conn.NamedExecContext(ctx, query, entry); err != nil {
+		return fmt.Errorf("failed to add password history: %w", err)
+	}
+
+	query = `
+		DELETE FROM password_history
+		WHERE user_id = $1 AND id NOT IN (
+			SELECT id FROM password_history WHERE user_id = $1 ORDER BY created_at DESC LIMIT $2
+		)
+	`
+	if _, err := conn.ExecContext(ctx, query, entry.UserID, keep); err != nil {
+		return fmt.Errorf("failed to trim password history: %w", err)
+	}
+
+	return nil
+}
+
+// emailVerificationPostgresRepository implements EmailVerificationRepository
+// for PostgreSQL
+type emailVerificationPostgresRepository struct {
+	db *sqlx.DB
+}
+
+// NewEmailVerificationPostgresRepository creates a new email verification
+// token repository
+func NewEmailVerificationPostgresRepository(db *sqlx.DB) domain.EmailVerificationRepository {
+	return &emailVerificationPostgresRepository{db: db}
+}
+
+// CreateEmailVerificationToken stores a new email verification token
+func (r *emailVerificationPostgresRepository) CreateEmailVerificationToken(ctx context.Context, token *domain.EmailVerificationToken) error {
+	query := `
+		INSERT INTO email_verification_tokens (id, user_id, token_hash, expires_at, used_at, created_at)
+		VALUES (:id, :user_id, :token_hash, :expires_at, :used_at, :created_at)
+	`
+
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, token)
+	if err != nil {
+		return fmt.Errorf("failed to create email verification token: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumeEmailVerificationToken marks an unused, unexpired token used in the
+// same statement that finds it, so a token is only ever consumed once
+func (r *emailVerificationPostgresRepository) ConsumeEmailVerificationToken(ctx context.Context, tokenHash string, now time.Time) (*domain.EmailVerificationToken, error) {
+	query := `
+		UPDATE email_verification_tokens
+		SET used_at = $2
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > $2
+		RETURNING id, user_id, token_hash, expires_at, used_at, created_at
+	`
+
+	var token domain.EmailVerificationToken
+	err := db.Conn(ctx, r.db).GetContext(ctx, &token, query, tokenHash, now)
+	if err == sql.ErrNoRows {
+		return nil, errors.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume email verification token: %w", err)
+	}
+
+	return &token, nil
+}
+
+// DeleteUserEmailVerificationTokens deletes the email verification tokens
+// of a user
+func (r *emailVerificationPostgresRepository) DeleteUserEmailVerificationTokens(ctx context.Context, userID string) error {
+	query := `DELETE FROM email_verification_tokens WHERE user_id = $1`
+
+	_, err := db.Conn(ctx, r.db).ExecContext(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete email verification tokens: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpiredEmailVerificationTokens deletes the tokens used or expired by
+// now
+func (r *emailVerificationPostgresRepository) DeleteExpiredEmailVerificationTokens(ctx context.Context, now time.Time) (int64, error) {
+	query := `DELETE FROM email_verification_tokens WHERE used_at IS NOT NULL OR expires_at <= $1`
+
+	result, err := db.Conn(ctx, r.db).ExecContext(ctx, query, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired email verification tokens: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// identityPostgresRepository implements IdentityRepository for PostgreSQL
+type identityPostgresRepository struct {
+	db *sqlx.DB
+}
+
+// NewIdentityPostgresRepository creates a new identity repository
+func NewIdentityPostgresRepository(db *sqlx.DB) domain.IdentityRepository {
+	return &identityPostgresRepository{db: db}
+}
+
+// GetIdentity retrieves the identity the provider knows by subject
+func (r *identityPostgresRepository) GetIdentity(ctx context.Context, provider, subject string) (*domain.Identity, error) {
+	query := `
+		SELECT id, user_id, provider, subject, email, created_at
+		FROM user_identities
+		WHERE provider = $1 AND subject = $2
+	`
+
+	var identity domain.Identity
+	err := db.Conn(ctx, r.db).GetContext(ctx, &identity, query, provider, subject)
+	if err == sql.ErrNoRows {
+		return nil, errors.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get identity: %w", err)
+	}
+
+	return &identity, nil
+}
+
+// CreateIdentity links a user to an external provider
+func (r *identityPostgresRepository) CreateIdentity(ctx context.Context, identity *domain.Identity) error {
+	query := `
+		INSERT INTO user_identities (id, user_id, provider, subject, email, created_at)
+		VALUES (:id, :user_id, :provider, :subject, :email, :created_at)
+	`
+
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, identity)
+	if err != nil {
+		return fmt.Errorf("failed to create identity: %w", err)
+	}
+
+	return nil
+}
+
+// oidcLoginPostgresRepository implements OIDCLoginRepository for PostgreSQL
+type oidcLoginPostgresRepository struct {
+	db *sqlx.DB
+}
+
+// NewOIDCLoginPostgresRepository creates a new OpenID Connect sign in
+// repository
+func NewOIDCLoginPostgresRepository(db *sqlx.DB) domain.OIDCLoginRepository {
+	return &oidcLoginPostgresRepository{db: db}
+}
+
+// CreateOIDCLogin stores a started sign in, deleting the expired ones
+func (r *oidcLoginPostgresRepository) CreateOIDCLogin(ctx context.Context, login *domain.OIDCLogin) error {
+	conn := db.Conn(ctx, r.db)
+	if _, err := conn.ExecContext(ctx, `DELETE FROM oidc_logins WHERE expires_at <= $1`, login.CreatedAt); err != nil {
+		return fmt.Errorf("failed to delete expired oidc logins: %w", err)
+	}
+
+	query := `
+		INSERT INTO oidc_logins (id, provider, state_hash, nonce, code_verifier, expires_at, created_at)
+		VALUES (:id, :provider, :state_hash, :nonce, :code_verifier, :expires_at, :created_at)
+	`
+	if _, err := conn.NamedExecContext(ctx, query, login); err != nil {
+		return fmt.Errorf("failed to create oidc login: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumeOIDCLogin deletes an unexpired sign in in the same statement that
+// finds it, so a sign in is only ever finished once
+func (r *oidcLoginPostgresRepository) ConsumeOIDCLogin(ctx context.Context, provider, stateHash string, now time.Time) (*domain.OIDCLogin, error) {
+	query := `
+		DELETE FROM oidc_logins
+		WHERE provider = $1 AND state_hash = $2 AND expires_at > $3
+		RETURNING id, provider, state_hash, nonce, code_verifier, expires_at, created_at
+	`
+
+	var login domain.OIDCLogin
+	err := db.Conn(ctx, r.db).GetContext(ctx, &login, query, provider, stateHash, now)
+	if err == sql.ErrNoRows {
+		return nil, errors.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume oidc login: %w", err)
+	}
+
+	return &login, nil
+}
@@ -2,11 +2,21 @@ package usecase
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
+	"net/url"
 	"time"
 
+	"portal-data-backend/infrastructure/audit"
+	"portal-data-backend/infrastructure/config"
 	"portal-data-backend/internal/auth/domain"
+	templateDomain "portal-data-backend/internal/message_template/domain"
+	"portal-data-backend/infrastructure/logger"
 	"portal-data-backend/infrastructure/security"
+	"portal-data-backend/infrastructure/tenant"
 	"portal-data-backend/pkg/errors"
 
 	"github.com/google/uuid"
@@ -16,22 +26,68 @@ import (
 type authUsecase struct {
 	userRepo       domain.UserRepository
 	tokenRepo      domain.TokenRepository
+	resetRepo      domain.PasswordResetRepository
+	verifyRepo     domain.EmailVerificationRepository
+	historyRepo    domain.PasswordHistoryRepository
 	jwtManager     *security.JWTManager
 	passwordHasher *security.PasswordHandler
+	events         domain.EventPublisher
+	mailer         MailSender
+	messages       MessageRenderer
+	recovery       config.RecoveryConfig
+	signup         config.SignupConfig
+	identityRepo   domain.IdentityRepository
+	loginRepo      domain.OIDCLoginRepository
+	providers      map[string]IdentityProvider
+	oidc           config.OIDCConfig
+	authAudit      *audit.AuthRecorder
 }
 
-// NewAuthUsecase creates a new auth usecase
+// NewAuthUsecase creates a new auth usecase. events may be nil, as may
+// historyRepo, leaving former passwords unchecked. Password
+// reset and email verification links are mailed through mailer, worded by
+// messages, and expire as recovery and signup set; signup also decides whether registered users
+// verify their email before signing in. Users sign in with the external
+// providers, keyed by name, as oidc configures them. Sign ins, sign outs,
+// token refreshes and password changes are recorded through authAudit,
+// which may be nil.
 func NewAuthUsecase(
 	userRepo domain.UserRepository,
 	tokenRepo domain.TokenRepository,
+	resetRepo domain.PasswordResetRepository,
+	verifyRepo domain.EmailVerificationRepository,
+	historyRepo domain.PasswordHistoryRepository,
 	jwtManager *security.JWTManager,
 	passwordHasher *security.PasswordHandler,
+	events domain.EventPublisher,
+	mailer MailSender,
+	messages MessageRenderer,
+	recovery config.RecoveryConfig,
+	signup config.SignupConfig,
+	identityRepo domain.IdentityRepository,
+	loginRepo domain.OIDCLoginRepository,
+	providers map[string]IdentityProvider,
+	oidc config.OIDCConfig,
+	authAudit *audit.AuthRecorder,
 ) Usecase {
 	return &authUsecase{
 		userRepo:       userRepo,
 		tokenRepo:      tokenRepo,
+		resetRepo:      resetRepo,
+		verifyRepo:     verifyRepo,
+		historyRepo:    historyRepo,
 		jwtManager:     jwtManager,
 		passwordHasher: passwordHasher,
+		events:         events,
+		mailer:         mailer,
+		messages:       messages,
+		recovery:       recovery,
+		signup:         signup,
+		identityRepo:   identityRepo,
+		loginRepo:      loginRepo,
+		providers:      providers,
+		oidc:           oidc,
+		authAudit:      authAudit,
 	}
 }
 
@@ -41,6 +97,7 @@ func (a *authUsecase) Login(ctx context.Context, req *domain.LoginRequest) (*dom
 	user, err := a.userRepo.GetUserByEmail(ctx, req.Email)
 	if err != nil {
 		if errors.Is(err, errors.ErrNotFound) {
+			a.authAudit.Record(ctx, audit.AuthEventLoginFailed, "", req.Email, "unknown email")
 			return nil, errors.ErrInvalidCredentials
 		}
 		return nil, fmt.Errorf("failed to get user: %w", err)
@@ -48,20 +105,32 @@ func (a *authUsecase) Login(ctx context.Context, req *domain.LoginRequest) (*dom
 
 	// Verify password
 	if !a.passwordHasher.Verify(req.Password, user.PasswordHash) {
+		a.authAudit.Record(ctx, audit.AuthEventLoginFailed, user.ID, user.Email, "wrong password")
 		return nil, errors.ErrInvalidCredentials
 	}
 
 	// Check if user is active
+	if user.Status == domain.UserStatusPending {
+		a.authAudit.Record(ctx, audit.AuthEventLoginFailed, user.ID, user.Email, "email not verified")
+		return nil, errors.ErrEmailNotVerified
+	}
 	if !user.IsActive() {
+		a.authAudit.Record(ctx, audit.AuthEventLoginFailed, user.ID, user.Email, "user disabled")
 		return nil, errors.ErrUserDisabled
 	}
 
-	// Generate tokens
+	return a.issueTokens(ctx, user, "password")
+}
+
+// issueTokens signs user in, storing the refresh token of the pair it
+// returns, and records how they signed in
+func (a *authUsecase) issueTokens(ctx context.Context, user *domain.User, method string) (*domain.AuthResponse, error) {
 	tokenPair, err := a.jwtManager.GenerateTokenPair(
 		user.ID,
 		user.OrganizationID,
 		user.RoleID,
 		user.Email,
+		tenant.ID(ctx),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate tokens: %w", err)
@@ -69,18 +138,19 @@ func (a *authUsecase) Login(ctx context.Context, req *domain.LoginRequest) (*dom
 
 	// Store refresh token in database
 	token := &domain.Token{
-		ID:           uuid.New().String(),
-		UserID:       user.ID,
-		AccessToken:  tokenPair.AccessToken,
-		RefreshToken: tokenPair.RefreshToken,
-		ExpiresAt:    time.Now().Add(24 * time.Hour * 7), // 7 days
-		Revoked:      false,
-		CreatedAt:    time.Now(),
+		ID:               uuid.New().String(),
+		UserID:           user.ID,
+		AccessTokenHash:  hashToken(tokenPair.AccessToken),
+		RefreshTokenHash: hashToken(tokenPair.RefreshToken),
+		ExpiresAt:        time.Now().Add(24 * time.Hour * 7), // 7 days
+		Revoked:          false,
+		CreatedAt:        time.Now(),
 	}
 
 	if err := a.tokenRepo.CreateToken(ctx, token); err != nil {
 		return nil, fmt.Errorf("failed to store token: %w", err)
 	}
+	a.authAudit.Record(ctx, audit.AuthEventLogin, user.ID, user.Email, method)
 
 	return &domain.AuthResponse{
 		User:         user.ToUserInfo(),
@@ -91,8 +161,87 @@ func (a *authUsecase) Login(ctx context.Context, req *domain.LoginRequest) (*dom
 	}, nil
 }
 
-// Register creates a new user account
+// Register creates a new user account. When email verification is
+// required the user is left pending and mailed a verification link instead
+// of being signed in.
 func (a *authUsecase) Register(ctx context.Context, req *domain.RegisterRequest) (*domain.AuthResponse, error) {
+	if err := a.checkPassword(req.Password); err != nil {
+		return nil, err
+	}
+	status := domain.UserStatusActive
+	if a.signup.RequireVerification {
+		status = domain.UserStatusPending
+	}
+	user, err := a.createUser(ctx, req, status)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Status == domain.UserStatusPending {
+		// The user can ask for another link when this one is not delivered
+		if err := a.sendVerification(ctx, user); err != nil {
+			logger.FromContext(ctx).Error("failed to send verification link to user %s: %v", user.ID, err)
+		}
+		userInfo := user.ToUserInfo()
+		a.publish(ctx, domain.EventUserRegistered, userInfo)
+		return &domain.AuthResponse{User: userInfo, VerificationRequired: true}, nil
+	}
+
+	// Generate tokens
+	tokenPair, err := a.jwtManager.GenerateTokenPair(
+		user.ID,
+		user.OrganizationID,
+		user.RoleID,
+		user.Email,
+		tenant.ID(ctx),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate tokens: %w", err)
+	}
+
+	// Store refresh token in database
+	token := &domain.Token{
+		ID:               uuid.New().String(),
+		UserID:           user.ID,
+		AccessTokenHash:  hashToken(tokenPair.AccessToken),
+		RefreshTokenHash: hashToken(tokenPair.RefreshToken),
+		ExpiresAt:        time.Now().Add(24 * time.Hour * 7),
+		Revoked:          false,
+		CreatedAt:        time.Now(),
+	}
+
+	if err := a.tokenRepo.CreateToken(ctx, token); err != nil {
+		return nil, fmt.Errorf("failed to store token: %w", err)
+	}
+	a.authAudit.Record(ctx, audit.AuthEventLogin, user.ID, user.Email, "register")
+
+	userInfo := user.ToUserInfo()
+	a.publish(ctx, domain.EventUserRegistered, userInfo)
+
+	return &domain.AuthResponse{
+		User:         userInfo,
+		AccessToken:  tokenPair.AccessToken,
+		RefreshToken: tokenPair.RefreshToken,
+		ExpiresIn:    tokenPair.ExpiresIn,
+		TokenType:    tokenPair.TokenType,
+	}, nil
+}
+
+// CreateUser creates a user account without signing it in
+func (a *authUsecase) CreateUser(ctx context.Context, req *domain.RegisterRequest) (*domain.UserInfo, error) {
+	user, err := a.createUser(ctx, req, domain.UserStatusActive)
+	if err != nil {
+		return nil, err
+	}
+
+	userInfo := user.ToUserInfo()
+	a.publish(ctx, domain.EventUserRegistered, userInfo)
+	return &userInfo, nil
+}
+
+// createUser checks the email and username are free and stores the user
+// with status
+func (a *authUsecase) createUser(ctx context.Context, req *domain.RegisterRequest, status domain.UserStatus) (*domain.User, error) {
 	// Check if email already exists
 	exists, err := a.userRepo.IsEmailExists(ctx, req.Email)
 	if err != nil {
@@ -126,7 +275,7 @@ func (a *authUsecase) Register(ctx context.Context, req *domain.RegisterRequest)
 		Username:       req.Username,
 		Email:          req.Email,
 		PasswordHash:   passwordHash,
-		Status:         domain.UserStatusActive,
+		Status:         status,
 		CreatedAt:      time.Now(),
 		UpdatedAt:      time.Now(),
 	}
@@ -147,46 +296,313 @@ func (a *authUsecase) Register(ctx context.Context, req *domain.RegisterRequest)
 	if err := a.userRepo.CreateUser(ctx, user); err != nil {
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
+	return user, nil
+}
 
-	// Generate tokens
-	tokenPair, err := a.jwtManager.GenerateTokenPair(
-		user.ID,
-		user.OrganizationID,
-		user.RoleID,
-		user.Email,
-	)
+// SetPassword sets the password of the user with email and revokes their
+// tokens, signing them out everywhere
+func (a *authUsecase) SetPassword(ctx context.Context, email, password string) error {
+	user, err := a.userRepo.GetUserByEmail(ctx, email)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate tokens: %w", err)
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if err := a.setPassword(ctx, user, password, ""); err != nil {
+		return err
 	}
+	a.authAudit.Record(ctx, audit.AuthEventPasswordChange, user.ID, user.Email, "set by an administrator")
+	return nil
+}
 
-	// Store refresh token in database
-	token := &domain.Token{
-		ID:           uuid.New().String(),
-		UserID:       user.ID,
-		AccessToken:  tokenPair.AccessToken,
-		RefreshToken: tokenPair.RefreshToken,
-		ExpiresAt:    time.Now().Add(24 * time.Hour * 7),
-		Revoked:      false,
-		CreatedAt:    time.Now(),
+// RequestPasswordReset mails a single use reset link to the user with the
+// email of req. Unknown emails and inactive users are not told apart from
+// the others, so the answer does not reveal who has an account. Links mailed
+// before stop working.
+func (a *authUsecase) RequestPasswordReset(ctx context.Context, req *domain.ForgotPasswordRequest) error {
+	user, err := a.userRepo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, errors.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if !user.IsActive() {
+		return nil
 	}
 
-	if err := a.tokenRepo.CreateToken(ctx, token); err != nil {
-		return nil, fmt.Errorf("failed to store token: %w", err)
+	token, err := newLinkToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate password reset token: %w", err)
 	}
 
-	return &domain.AuthResponse{
-		User:         user.ToUserInfo(),
-		AccessToken:  tokenPair.AccessToken,
-		RefreshToken: tokenPair.RefreshToken,
-		ExpiresIn:    tokenPair.ExpiresIn,
-		TokenType:    tokenPair.TokenType,
-	}, nil
+	if err := a.resetRepo.DeleteUserPasswordResetTokens(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to delete password reset tokens: %w", err)
+	}
+	now := time.Now()
+	reset := &domain.PasswordResetToken{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: now.Add(a.recovery.TTL),
+		CreatedAt: now,
+	}
+	if err := a.resetRepo.CreatePasswordResetToken(ctx, reset); err != nil {
+		return fmt.Errorf("failed to store password reset token: %w", err)
+	}
+
+	msg, err := a.messages.Render(ctx, templateDomain.ChannelEmail, MessagePasswordReset, map[string]string{
+		"name": user.Name,
+		"link": a.recovery.URL + "?token=" + url.QueryEscape(token),
+		"ttl":  a.recovery.TTL.String(),
+	})
+	if err != nil {
+		return err
+	}
+	if err := a.mailer.Send(ctx, user.Email, msg.Subject, msg.Body); err != nil {
+		return fmt.Errorf("failed to send password reset link: %w", err)
+	}
+	return nil
+}
+
+// ResetPassword consumes the token of a reset link and sets the new
+// password of its user, revoking their tokens and any other reset link. A
+// password breaking the policy leaves the link unused, but one the user had
+// lately is only found once the link is used up.
+func (a *authUsecase) ResetPassword(ctx context.Context, req *domain.ResetPasswordRequest) error {
+	if err := a.checkPassword(req.Password); err != nil {
+		return err
+	}
+	reset, err := a.resetRepo.ConsumePasswordResetToken(ctx, hashToken(req.Token), time.Now())
+	if err != nil {
+		if errors.Is(err, errors.ErrNotFound) {
+			return fmt.Errorf("%w: reset link is invalid or has expired", errors.ErrInvalidInput)
+		}
+		return fmt.Errorf("failed to consume password reset token: %w", err)
+	}
+
+	user, err := a.userRepo.GetUserByID(ctx, reset.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if !user.IsActive() {
+		return errors.ErrUserDisabled
+	}
+	if err := a.checkReuse(ctx, user, req.Password); err != nil {
+		return err
+	}
+
+	if err := a.setPassword(ctx, user, req.Password, ""); err != nil {
+		return err
+	}
+	if err := a.resetRepo.DeleteUserPasswordResetTokens(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to delete password reset tokens: %w", err)
+	}
+	a.authAudit.Record(ctx, audit.AuthEventPasswordReset, user.ID, user.Email, "")
+	return nil
+}
+
+// ChangePassword verifies the current password of the user and sets the new
+// one, revoking their tokens but the one of the session changing it
+func (a *authUsecase) ChangePassword(ctx context.Context, userID, accessToken string, req *domain.ChangePasswordRequest) error {
+	user, err := a.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if !user.IsActive() {
+		return errors.ErrUserDisabled
+	}
+	if !a.passwordHasher.Verify(req.CurrentPassword, user.PasswordHash) {
+		return fmt.Errorf("%w: current password is incorrect", errors.ErrInvalidInput)
+	}
+	if err := a.checkPassword(req.NewPassword); err != nil {
+		return err
+	}
+	if req.NewPassword == req.CurrentPassword {
+		return fmt.Errorf("%w: new password must differ from the current one", errors.ErrInvalidInput)
+	}
+	if err := a.checkReuse(ctx, user, req.NewPassword); err != nil {
+		return err
+	}
+
+	// The session changing the password stays signed in; without a stored
+	// token every session is signed out
+	session, err := a.tokenRepo.GetTokenByAccessTokenHash(ctx, hashToken(accessToken))
+	if err != nil && !errors.Is(err, errors.ErrNotFound) {
+		return fmt.Errorf("failed to get stored token: %w", err)
+	}
+	keepID := ""
+	if session != nil && session.UserID == user.ID {
+		keepID = session.ID
+	}
+	if err := a.setPassword(ctx, user, req.NewPassword, keepID); err != nil {
+		return err
+	}
+	a.authAudit.Record(ctx, audit.AuthEventPasswordChange, user.ID, user.Email, "")
+	return nil
+}
+
+// VerifyEmail consumes the token of a verification link and activates its
+// user if they are still pending
+func (a *authUsecase) VerifyEmail(ctx context.Context, req *domain.VerifyEmailRequest) error {
+	verification, err := a.verifyRepo.ConsumeEmailVerificationToken(ctx, hashToken(req.Token), time.Now())
+	if err != nil {
+		if errors.Is(err, errors.ErrNotFound) {
+			return fmt.Errorf("%w: verification link is invalid or has expired", errors.ErrInvalidInput)
+		}
+		return fmt.Errorf("failed to consume email verification token: %w", err)
+	}
+
+	user, err := a.userRepo.GetUserByID(ctx, verification.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.Status == domain.UserStatusPending {
+		user.Status = domain.UserStatusActive
+		if err := a.userRepo.UpdateUser(ctx, user); err != nil {
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+		a.publish(ctx, domain.EventUserVerified, user.ToUserInfo())
+	}
+
+	if err := a.verifyRepo.DeleteUserEmailVerificationTokens(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to delete email verification tokens: %w", err)
+	}
+	return nil
+}
+
+// ResendVerification mails a new verification link to the pending user
+// with the email of req. Like RequestPasswordReset it answers the same for
+// every email.
+func (a *authUsecase) ResendVerification(ctx context.Context, req *domain.ResendVerificationRequest) error {
+	user, err := a.userRepo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, errors.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.Status != domain.UserStatusPending {
+		return nil
+	}
+	return a.sendVerification(ctx, user)
+}
+
+// sendVerification mails user a single use verification link, replacing the
+// links mailed before
+func (a *authUsecase) sendVerification(ctx context.Context, user *domain.User) error {
+	token, err := newLinkToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate email verification token: %w", err)
+	}
+
+	if err := a.verifyRepo.DeleteUserEmailVerificationTokens(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to delete email verification tokens: %w", err)
+	}
+	now := time.Now()
+	verification := &domain.EmailVerificationToken{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: now.Add(a.signup.VerificationTTL),
+		CreatedAt: now,
+	}
+	if err := a.verifyRepo.CreateEmailVerificationToken(ctx, verification); err != nil {
+		return fmt.Errorf("failed to store email verification token: %w", err)
+	}
+
+	msg, err := a.messages.Render(ctx, templateDomain.ChannelEmail, MessageEmailVerification, map[string]string{
+		"name": user.Name,
+		"link": a.signup.VerifyURL + "?token=" + url.QueryEscape(token),
+		"ttl":  a.signup.VerificationTTL.String(),
+	})
+	if err != nil {
+		return err
+	}
+	if err := a.mailer.Send(ctx, user.Email, msg.Subject, msg.Body); err != nil {
+		return fmt.Errorf("failed to send verification link: %w", err)
+	}
+	return nil
+}
+
+// checkPassword refuses password when it breaks the password policy
+func (a *authUsecase) checkPassword(password string) error {
+	if err := a.passwordHasher.ValidatePassword(password); err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrInvalidInput, err)
+	}
+	return nil
+}
+
+// checkReuse refuses password when it is one of the last passwords of user
+// the password policy keeps, the current one included
+func (a *authUsecase) checkReuse(ctx context.Context, user *domain.User, password string) error {
+	history := a.passwordHasher.Policy().History
+	if history == 0 {
+		return nil
+	}
+	hashes := []string{user.PasswordHash}
+	if history > 1 && a.historyRepo != nil {
+		former, err := a.historyRepo.ListPasswordHistory(ctx, user.ID, history-1)
+		if err != nil {
+			return fmt.Errorf("failed to list password history: %w", err)
+		}
+		hashes = append(hashes, former...)
+	}
+	for _, hash := range hashes {
+		if a.passwordHasher.Verify(password, hash) {
+			return fmt.Errorf("%w: password must differ from the last %d passwords", errors.ErrInvalidInput, history)
+		}
+	}
+	return nil
+}
+
+// setPassword stores the hash of password as the password of user and
+// revokes their tokens, but the one with ID keepID when it is set. The
+// password it replaces joins the history the password policy keeps.
+func (a *authUsecase) setPassword(ctx context.Context, user *domain.User, password, keepID string) error {
+	passwordHash, err := a.passwordHasher.Hash(password)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	if history := a.passwordHasher.Policy().History; history > 1 && a.historyRepo != nil && user.PasswordHash != "" {
+		entry := &domain.PasswordHistory{
+			ID:           uuid.New().String(),
+			UserID:       user.ID,
+			PasswordHash: user.PasswordHash,
+			CreatedAt:    time.Now(),
+		}
+		if err := a.historyRepo.AddPasswordHistory(ctx, entry, history-1); err != nil {
+			return fmt.Errorf("failed to add password history: %w", err)
+		}
+	}
+	user.PasswordHash = passwordHash
+	if err := a.userRepo.UpdateUser(ctx, user); err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	if keepID != "" {
+		err = a.tokenRepo.RevokeOtherUserTokens(ctx, user.ID, keepID)
+	} else {
+		err = a.tokenRepo.RevokeUserTokens(ctx, user.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to revoke tokens: %w", err)
+	}
+	return nil
+}
+
+// publish emits an event without failing the operation that triggered it
+func (a *authUsecase) publish(ctx context.Context, eventType string, data interface{}) {
+	if a.events == nil {
+		return
+	}
+	if err := a.events.Publish(ctx, eventType, data); err != nil {
+		logger.FromContext(ctx).Error("failed to publish %s event: %v", eventType, err)
+	}
 }
 
 // Logout logs out a user by revoking their tokens
 func (a *authUsecase) Logout(ctx context.Context, accessToken, refreshToken string) error {
 	// Get token by refresh token
-	token, err := a.tokenRepo.GetTokenByRefreshToken(ctx, refreshToken)
+	token, err := a.tokenRepo.GetTokenByRefreshTokenHash(ctx, hashToken(refreshToken))
 	if err != nil {
 		if errors.Is(err, errors.ErrNotFound) {
 			return errors.ErrInvalidToken
@@ -195,7 +611,7 @@ func (a *authUsecase) Logout(ctx context.Context, accessToken, refreshToken stri
 	}
 
 	// Verify access token matches
-	if token.AccessToken != accessToken {
+	if token.AccessTokenHash != hashToken(accessToken) {
 		return errors.ErrInvalidToken
 	}
 
@@ -204,13 +620,18 @@ func (a *authUsecase) Logout(ctx context.Context, accessToken, refreshToken stri
 		return fmt.Errorf("failed to revoke token: %w", err)
 	}
 
+	email := ""
+	if user, err := a.userRepo.GetUserByID(ctx, token.UserID); err == nil {
+		email = user.Email
+	}
+	a.authAudit.Record(ctx, audit.AuthEventLogout, token.UserID, email, "")
 	return nil
 }
 
 // RefreshToken refreshes an access token using a refresh token
 func (a *authUsecase) RefreshToken(ctx context.Context, refreshToken string) (*domain.AuthResponse, error) {
 	// Get stored token
-	storedToken, err := a.tokenRepo.GetTokenByRefreshToken(ctx, refreshToken)
+	storedToken, err := a.tokenRepo.GetTokenByRefreshTokenHash(ctx, hashToken(refreshToken))
 	if err != nil {
 		if errors.Is(err, errors.ErrNotFound) {
 			return nil, errors.ErrInvalidToken
@@ -235,6 +656,7 @@ func (a *authUsecase) RefreshToken(ctx context.Context, refreshToken string) (*d
 		user.OrganizationID,
 		user.RoleID,
 		user.Email,
+		tenant.ID(ctx),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate new tokens: %w", err)
@@ -247,18 +669,19 @@ func (a *authUsecase) RefreshToken(ctx context.Context, refreshToken string) (*d
 
 	// Store new refresh token
 	newToken := &domain.Token{
-		ID:           uuid.New().String(),
-		UserID:       user.ID,
-		AccessToken:  tokenPair.AccessToken,
-		RefreshToken: tokenPair.RefreshToken,
-		ExpiresAt:    time.Now().Add(24 * time.Hour * 7),
-		Revoked:      false,
-		CreatedAt:    time.Now(),
+		ID:               uuid.New().String(),
+		UserID:           user.ID,
+		AccessTokenHash:  hashToken(tokenPair.AccessToken),
+		RefreshTokenHash: hashToken(tokenPair.RefreshToken),
+		ExpiresAt:        time.Now().Add(24 * time.Hour * 7),
+		Revoked:          false,
+		CreatedAt:        time.Now(),
 	}
 
 	if err := a.tokenRepo.CreateToken(ctx, newToken); err != nil {
 		return nil, fmt.Errorf("failed to store new token: %w", err)
 	}
+	a.authAudit.Record(ctx, audit.AuthEventTokenRefresh, user.ID, user.Email, "")
 
 	return &domain.AuthResponse{
 		User:         user.ToUserInfo(),
@@ -277,6 +700,24 @@ func (a *authUsecase) RevokeAllTokens(ctx context.Context, userID string) error
 	return nil
 }
 
+// CleanupExpiredTokens deletes the tokens that can no longer be used
+func (a *authUsecase) CleanupExpiredTokens(ctx context.Context) (int64, error) {
+	now := time.Now()
+	sessions, err := a.tokenRepo.CleanupExpiredTokens(ctx)
+	if err != nil {
+		return 0, err
+	}
+	resets, err := a.resetRepo.DeleteExpiredPasswordResetTokens(ctx, now)
+	if err != nil {
+		return sessions, err
+	}
+	verifications, err := a.verifyRepo.DeleteExpiredEmailVerificationTokens(ctx, now)
+	if err != nil {
+		return sessions + resets, err
+	}
+	return sessions + resets + verifications, nil
+}
+
 // ValidateToken validates a token and returns the claims
 func (a *authUsecase) ValidateToken(ctx context.Context, token string) (*domain.TokenClaims, error) {
 	claims, err := a.jwtManager.ValidateToken(token)
@@ -284,12 +725,15 @@ func (a *authUsecase) ValidateToken(ctx context.Context, token string) (*domain.
 		return nil, err
 	}
 
-	// Check if token is stored and not revoked
-	storedToken, err := a.tokenRepo.GetTokenByAccessToken(ctx, token)
+	// Tokens of sessions are stored until they are signed out. Impersonation
+	// tokens are not stored and last until they expire.
+	storedToken, err := a.tokenRepo.GetTokenByAccessTokenHash(ctx, hashToken(token))
 	if err != nil && !errors.Is(err, errors.ErrNotFound) {
 		return nil, fmt.Errorf("failed to get stored token: %w", err)
 	}
-
+	if storedToken == nil && claims.Actor == nil {
+		return nil, errors.ErrTokenRevoked
+	}
 	if storedToken != nil && !storedToken.IsValid() {
 		return nil, errors.ErrTokenRevoked
 	}
@@ -299,6 +743,7 @@ func (a *authUsecase) ValidateToken(ctx context.Context, token string) (*domain.
 		OrganizationID: claims.OrganizationID,
 		RoleID:         claims.RoleID,
 		Email:          claims.Email,
+		TenantID:       claims.TenantID,
 	}, nil
 }
 
@@ -312,3 +757,31 @@ func (a *authUsecase) GetCurrentUser(ctx context.Context, userID string) (*domai
 	info := user.ToUserInfo()
 	return &info, nil
 }
+
+// GetUserByEmail retrieves a user by email
+func (a *authUsecase) GetUserByEmail(ctx context.Context, email string) (*domain.UserInfo, error) {
+	user, err := a.userRepo.GetUserByEmail(ctx, email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	info := user.ToUserInfo()
+	return &info, nil
+}
+
+// newLinkToken returns a random token for a link mailed to a user
+func newLinkToken() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// hashToken returns the digest tokens are stored and looked up by. JWTs are
+// long random strings, so an unsalted SHA-256 is enough to keep the stored
+// digests from being turned back into tokens.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
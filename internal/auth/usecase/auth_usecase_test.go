@@ -2,14 +2,21 @@ package usecase_test
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
+	"portal-data-backend/infrastructure/audit"
 	"portal-data-backend/infrastructure/config"
 	"portal-data-backend/infrastructure/security"
 	"portal-data-backend/internal/auth/domain"
 	"portal-data-backend/internal/auth/usecase"
+	templateDomain "portal-data-backend/internal/message_template/domain"
+	"portal-data-backend/pkg/client"
 	pkgerrors "portal-data-backend/pkg/errors"
 
 	"github.com/google/uuid"
@@ -111,8 +118,8 @@ type mockTokenRepository struct {
 	tokens map[string]*domain.Token
 
 	createTokenFunc       func(ctx context.Context, token *domain.Token) error
-	getTokenByRefreshFunc func(ctx context.Context, refreshToken string) (*domain.Token, error)
-	getTokenByAccessFunc  func(ctx context.Context, accessToken string) (*domain.Token, error)
+	getTokenByRefreshFunc func(ctx context.Context, refreshTokenHash string) (*domain.Token, error)
+	getTokenByAccessFunc  func(ctx context.Context, accessTokenHash string) (*domain.Token, error)
 	revokeTokenFunc       func(ctx context.Context, id string) error
 	revokeUserTokensFunc  func(ctx context.Context, userID string) error
 }
@@ -125,24 +132,24 @@ func (m *mockTokenRepository) CreateToken(ctx context.Context, token *domain.Tok
 	return nil
 }
 
-func (m *mockTokenRepository) GetTokenByRefreshToken(ctx context.Context, refreshToken string) (*domain.Token, error) {
+func (m *mockTokenRepository) GetTokenByRefreshTokenHash(ctx context.Context, refreshTokenHash string) (*domain.Token, error) {
 	if m.getTokenByRefreshFunc != nil {
-		return m.getTokenByRefreshFunc(ctx, refreshToken)
+		return m.getTokenByRefreshFunc(ctx, refreshTokenHash)
 	}
 	for _, token := range m.tokens {
-		if token.RefreshToken == refreshToken {
+		if token.RefreshTokenHash == refreshTokenHash {
 			return token, nil
 		}
 	}
 	return nil, pkgerrors.ErrNotFound
 }
 
-func (m *mockTokenRepository) GetTokenByAccessToken(ctx context.Context, accessToken string) (*domain.Token, error) {
+func (m *mockTokenRepository) GetTokenByAccessTokenHash(ctx context.Context, accessTokenHash string) (*domain.Token, error) {
 	if m.getTokenByAccessFunc != nil {
-		return m.getTokenByAccessFunc(ctx, accessToken)
+		return m.getTokenByAccessFunc(ctx, accessTokenHash)
 	}
 	for _, token := range m.tokens {
-		if token.AccessToken == accessToken {
+		if token.AccessTokenHash == accessTokenHash {
 			return token, nil
 		}
 	}
@@ -172,14 +179,168 @@ func (m *mockTokenRepository) RevokeUserTokens(ctx context.Context, userID strin
 	return nil
 }
 
+func (m *mockTokenRepository) RevokeOtherUserTokens(ctx context.Context, userID, keepID string) error {
+	for _, token := range m.tokens {
+		if token.UserID == userID && token.ID != keepID {
+			token.Revoked = true
+		}
+	}
+	return nil
+}
+
 func (m *mockTokenRepository) DeleteToken(ctx context.Context, id string) error {
 	return nil
 }
 
-func (m *mockTokenRepository) CleanupExpiredTokens(ctx context.Context) error {
+func (m *mockTokenRepository) CleanupExpiredTokens(ctx context.Context) (int64, error) {
+	var deleted int64
+	for id, token := range m.tokens {
+		if token.Revoked || token.ExpiresAt.Before(time.Now()) {
+			delete(m.tokens, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// mockPasswordResetRepository is an in-memory PasswordResetRepository
+type mockPasswordResetRepository struct {
+	tokens map[string]*domain.PasswordResetToken
+}
+
+func newMockPasswordResetRepository() *mockPasswordResetRepository {
+	return &mockPasswordResetRepository{tokens: make(map[string]*domain.PasswordResetToken)}
+}
+
+func (m *mockPasswordResetRepository) CreatePasswordResetToken(ctx context.Context, token *domain.PasswordResetToken) error {
+	m.tokens[token.TokenHash] = token
+	return nil
+}
+
+func (m *mockPasswordResetRepository) ConsumePasswordResetToken(ctx context.Context, tokenHash string, now time.Time) (*domain.PasswordResetToken, error) {
+	token, ok := m.tokens[tokenHash]
+	if !ok || token.UsedAt != nil || !token.ExpiresAt.After(now) {
+		return nil, pkgerrors.ErrNotFound
+	}
+	token.UsedAt = &now
+	return token, nil
+}
+
+func (m *mockPasswordResetRepository) DeleteUserPasswordResetTokens(ctx context.Context, userID string) error {
+	for hash, token := range m.tokens {
+		if token.UserID == userID {
+			delete(m.tokens, hash)
+		}
+	}
+	return nil
+}
+
+func (m *mockPasswordResetRepository) DeleteExpiredPasswordResetTokens(ctx context.Context, now time.Time) (int64, error) {
+	var deleted int64
+	for hash, token := range m.tokens {
+		if token.UsedAt != nil || !token.ExpiresAt.After(now) {
+			delete(m.tokens, hash)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// mockPasswordHistoryRepository is an in-memory PasswordHistoryRepository
+type mockPasswordHistoryRepository struct {
+	// hashes are the former password hashes by user, the latest first
+	hashes map[string][]string
+}
+
+func (m *mockPasswordHistoryRepository) ListPasswordHistory(ctx context.Context, userID string, limit int) ([]string, error) {
+	hashes := m.hashes[userID]
+	if len(hashes) > limit {
+		hashes = hashes[:limit]
+	}
+	return hashes, nil
+}
+
+func (m *mockPasswordHistoryRepository) AddPasswordHistory(ctx context.Context, entry *domain.PasswordHistory, keep int) error {
+	hashes := append([]string{entry.PasswordHash}, m.hashes[entry.UserID]...)
+	if len(hashes) > keep {
+		hashes = hashes[:keep]
+	}
+	m.hashes[entry.UserID] = hashes
+	return nil
+}
+
+// mockEmailVerificationRepository is an in-memory
+// EmailVerificationRepository
+type mockEmailVerificationRepository struct {
+	tokens map[string]*domain.EmailVerificationToken
+}
+
+func newMockEmailVerificationRepository() *mockEmailVerificationRepository {
+	return &mockEmailVerificationRepository{tokens: make(map[string]*domain.EmailVerificationToken)}
+}
+
+func (m *mockEmailVerificationRepository) CreateEmailVerificationToken(ctx context.Context, token *domain.EmailVerificationToken) error {
+	m.tokens[token.TokenHash] = token
+	return nil
+}
+
+func (m *mockEmailVerificationRepository) ConsumeEmailVerificationToken(ctx context.Context, tokenHash string, now time.Time) (*domain.EmailVerificationToken, error) {
+	token, ok := m.tokens[tokenHash]
+	if !ok || token.UsedAt != nil || !token.ExpiresAt.After(now) {
+		return nil, pkgerrors.ErrNotFound
+	}
+	token.UsedAt = &now
+	return token, nil
+}
+
+func (m *mockEmailVerificationRepository) DeleteUserEmailVerificationTokens(ctx context.Context, userID string) error {
+	for hash, token := range m.tokens {
+		if token.UserID == userID {
+			delete(m.tokens, hash)
+		}
+	}
+	return nil
+}
+
+func (m *mockEmailVerificationRepository) DeleteExpiredEmailVerificationTokens(ctx context.Context, now time.Time) (int64, error) {
+	var deleted int64
+	for hash, token := range m.tokens {
+		if token.UsedAt != nil || !token.ExpiresAt.After(now) {
+			delete(m.tokens, hash)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// mockMessageRenderer renders the built-in wording of the messages
+type mockMessageRenderer struct{}
+
+func (mockMessageRenderer) Render(ctx context.Context, channel templateDomain.Channel, key string, vars map[string]string) (*templateDomain.Message, error) {
+	for _, def := range usecase.Messages {
+		if def.Channel == channel && def.Key == key {
+			return templateDomain.Render(def.Subject, def.Body, vars)
+		}
+	}
+	return nil, fmt.Errorf("undefined message %s", key)
+}
+
+// mockMailSender records the mail it is asked to send
+type mockMailSender struct {
+	sent []string
+}
+
+func (m *mockMailSender) Send(ctx context.Context, to, subject, body string) error {
+	m.sent = append(m.sent, body)
 	return nil
 }
 
+// hashToken returns the SHA-256 digest tokens are stored by
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
 // Helper function to create a test user with hashed password
 func createTestUser(id, email, password string) (*domain.User, error) {
 	hasher := security.NewPasswordHandler()
@@ -237,7 +398,7 @@ func TestLogin_Success(t *testing.T) {
 	passwordHasher := security.NewPasswordHandler()
 
 	// Create usecase
-	authUsecase := usecase.NewAuthUsecase(userRepo, tokenRepo, jwtManager, passwordHasher)
+	authUsecase := usecase.NewAuthUsecase(userRepo, tokenRepo, newMockPasswordResetRepository(), newMockEmailVerificationRepository(), nil, jwtManager, passwordHasher, nil, &mockMailSender{}, mockMessageRenderer{}, config.RecoveryConfig{}, config.SignupConfig{}, nil, nil, nil, config.OIDCConfig{}, nil)
 
 	// Execute
 	req := &domain.LoginRequest{
@@ -305,7 +466,7 @@ func TestLogin_InvalidCredentials(t *testing.T) {
 	passwordHasher := security.NewPasswordHandler()
 
 	// Create usecase
-	authUsecase := usecase.NewAuthUsecase(userRepo, tokenRepo, jwtManager, passwordHasher)
+	authUsecase := usecase.NewAuthUsecase(userRepo, tokenRepo, newMockPasswordResetRepository(), newMockEmailVerificationRepository(), nil, jwtManager, passwordHasher, nil, &mockMailSender{}, mockMessageRenderer{}, config.RecoveryConfig{}, config.SignupConfig{}, nil, nil, nil, config.OIDCConfig{}, nil)
 
 	// Execute with wrong password
 	req := &domain.LoginRequest{
@@ -329,6 +490,74 @@ func TestLogin_InvalidCredentials(t *testing.T) {
 	}
 }
 
+// mockAuthStore keeps auth events in memory
+type mockAuthStore struct {
+	entries []*audit.AuthEntry
+}
+
+func (m *mockAuthStore) InsertAuth(ctx context.Context, entry *audit.AuthEntry) error {
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+// Test failed and successful sign ins are recorded in the auth audit log
+// with their client
+func TestLogin_RecordsAuthEvents(t *testing.T) {
+	ctx := client.WithInfo(context.Background(), client.Info{IP: "203.0.113.7", UserAgent: "portal-test"})
+
+	user, err := createTestUser(uuid.New().String(), "test@example.com", "password123")
+	if err != nil {
+		t.Fatalf("Failed to create test user: %v", err)
+	}
+	userRepo := &mockUserRepository{
+		users: map[string]*domain.User{user.ID: user},
+		getUserByEmailFunc: func(ctx context.Context, email string) (*domain.User, error) {
+			if email == user.Email {
+				return user, nil
+			}
+			return nil, pkgerrors.ErrNotFound
+		},
+	}
+	jwtManager := security.NewJWTManager(&config.JWTConfig{
+		Secret:             "test-secret-key-for-testing",
+		AccessTokenExpiry:  time.Hour,
+		RefreshTokenExpiry: 24 * time.Hour,
+		Issuer:             "test",
+	})
+	store := &mockAuthStore{}
+	authUsecase := usecase.NewAuthUsecase(userRepo, &mockTokenRepository{tokens: make(map[string]*domain.Token)}, newMockPasswordResetRepository(), newMockEmailVerificationRepository(),
+		nil, jwtManager, security.NewPasswordHandler(), nil, &mockMailSender{}, mockMessageRenderer{}, config.RecoveryConfig{}, config.SignupConfig{},
+		nil, nil, nil, config.OIDCConfig{}, audit.NewAuthRecorder(store))
+
+	authUsecase.Login(ctx, &domain.LoginRequest{Email: "nobody@example.com", Password: "password123"})
+	authUsecase.Login(ctx, &domain.LoginRequest{Email: user.Email, Password: "wrongpassword"})
+	if _, err := authUsecase.Login(ctx, &domain.LoginRequest{Email: user.Email, Password: "password123"}); err != nil {
+		t.Fatalf("Failed to login: %v", err)
+	}
+
+	want := []struct {
+		event  audit.AuthEvent
+		userID string
+		email  string
+	}{
+		{audit.AuthEventLoginFailed, "", "nobody@example.com"},
+		{audit.AuthEventLoginFailed, user.ID, user.Email},
+		{audit.AuthEventLogin, user.ID, user.Email},
+	}
+	if len(store.entries) != len(want) {
+		t.Fatalf("Expected %d auth events, got %d", len(want), len(store.entries))
+	}
+	for i, w := range want {
+		entry := store.entries[i]
+		if entry.Event != w.event || entry.UserID != w.userID || entry.Email != w.email {
+			t.Errorf("Expected %s of %q, got %s of %q", w.event, w.email, entry.Event, entry.Email)
+		}
+		if entry.IP != "203.0.113.7" || entry.UserAgent != "portal-test" {
+			t.Errorf("Expected the client of the request, got %s %s", entry.IP, entry.UserAgent)
+		}
+	}
+}
+
 // Test Register Success
 func TestRegister_Success(t *testing.T) {
 	ctx := context.Background()
@@ -361,7 +590,7 @@ func TestRegister_Success(t *testing.T) {
 	passwordHasher := security.NewPasswordHandler()
 
 	// Create usecase
-	authUsecase := usecase.NewAuthUsecase(userRepo, tokenRepo, jwtManager, passwordHasher)
+	authUsecase := usecase.NewAuthUsecase(userRepo, tokenRepo, newMockPasswordResetRepository(), newMockEmailVerificationRepository(), nil, jwtManager, passwordHasher, nil, &mockMailSender{}, mockMessageRenderer{}, config.RecoveryConfig{}, config.SignupConfig{}, nil, nil, nil, config.OIDCConfig{}, nil)
 
 	// Execute
 	req := &domain.RegisterRequest{
@@ -419,7 +648,7 @@ func TestRegister_EmailAlreadyExists(t *testing.T) {
 	passwordHasher := security.NewPasswordHandler()
 
 	// Create usecase
-	authUsecase := usecase.NewAuthUsecase(userRepo, tokenRepo, jwtManager, passwordHasher)
+	authUsecase := usecase.NewAuthUsecase(userRepo, tokenRepo, newMockPasswordResetRepository(), newMockEmailVerificationRepository(), nil, jwtManager, passwordHasher, nil, &mockMailSender{}, mockMessageRenderer{}, config.RecoveryConfig{}, config.SignupConfig{}, nil, nil, nil, config.OIDCConfig{}, nil)
 
 	// Execute
 	req := &domain.RegisterRequest{
@@ -474,26 +703,26 @@ func TestRefreshToken_Success(t *testing.T) {
 	jwtManager := security.NewJWTManager(jwtConfig)
 
 	// Generate initial token pair
-	tokenPair, err := jwtManager.GenerateTokenPair(user.ID, user.OrganizationID, user.RoleID, user.Email)
+	tokenPair, err := jwtManager.GenerateTokenPair(user.ID, user.OrganizationID, user.RoleID, user.Email, "")
 	if err != nil {
 		t.Fatalf("Failed to generate token pair: %v", err)
 	}
 
 	// Create stored token
 	storedToken := &domain.Token{
-		ID:           uuid.New().String(),
-		UserID:       user.ID,
-		AccessToken:  tokenPair.AccessToken,
-		RefreshToken: tokenPair.RefreshToken,
-		ExpiresAt:    time.Now().Add(24 * time.Hour),
-		Revoked:      false,
-		CreatedAt:    time.Now(),
+		ID:               uuid.New().String(),
+		UserID:           user.ID,
+		AccessTokenHash:  hashToken(tokenPair.AccessToken),
+		RefreshTokenHash: hashToken(tokenPair.RefreshToken),
+		ExpiresAt:        time.Now().Add(24 * time.Hour),
+		Revoked:          false,
+		CreatedAt:        time.Now(),
 	}
 
 	tokenRepo := &mockTokenRepository{
 		tokens: map[string]*domain.Token{storedToken.ID: storedToken},
-		getTokenByRefreshFunc: func(ctx context.Context, refreshToken string) (*domain.Token, error) {
-			if refreshToken == tokenPair.RefreshToken {
+		getTokenByRefreshFunc: func(ctx context.Context, refreshTokenHash string) (*domain.Token, error) {
+			if refreshTokenHash == hashToken(tokenPair.RefreshToken) {
 				return storedToken, nil
 			}
 			return nil, pkgerrors.ErrNotFound
@@ -509,7 +738,7 @@ func TestRefreshToken_Success(t *testing.T) {
 	passwordHasher := security.NewPasswordHandler()
 
 	// Create usecase
-	authUsecase := usecase.NewAuthUsecase(userRepo, tokenRepo, jwtManager, passwordHasher)
+	authUsecase := usecase.NewAuthUsecase(userRepo, tokenRepo, newMockPasswordResetRepository(), newMockEmailVerificationRepository(), nil, jwtManager, passwordHasher, nil, &mockMailSender{}, mockMessageRenderer{}, config.RecoveryConfig{}, config.SignupConfig{}, nil, nil, nil, config.OIDCConfig{}, nil)
 
 	// Execute
 	resp, err := authUsecase.RefreshToken(ctx, tokenPair.RefreshToken)
@@ -527,3 +756,355 @@ func TestRefreshToken_Success(t *testing.T) {
 		t.Error("Expected new access token, got empty string")
 	}
 }
+
+// Test only digests of the issued tokens are stored, and sessions are found
+// and revoked by them
+func TestTokens_StoredAsHashes(t *testing.T) {
+	ctx := context.Background()
+
+	user, err := createTestUser(uuid.New().String(), "test@example.com", "password123")
+	if err != nil {
+		t.Fatalf("Failed to create test user: %v", err)
+	}
+	userRepo := &mockUserRepository{
+		users: map[string]*domain.User{user.ID: user},
+		getUserByEmailFunc: func(ctx context.Context, email string) (*domain.User, error) {
+			return user, nil
+		},
+	}
+	tokenRepo := &mockTokenRepository{tokens: make(map[string]*domain.Token)}
+	jwtManager := security.NewJWTManager(&config.JWTConfig{
+		Secret:             "test-secret-key-for-testing",
+		AccessTokenExpiry:  time.Hour,
+		RefreshTokenExpiry: 24 * time.Hour,
+		Issuer:             "test",
+	})
+	authUsecase := usecase.NewAuthUsecase(userRepo, tokenRepo, newMockPasswordResetRepository(), newMockEmailVerificationRepository(), nil, jwtManager, security.NewPasswordHandler(), nil, &mockMailSender{}, mockMessageRenderer{}, config.RecoveryConfig{}, config.SignupConfig{}, nil, nil, nil, config.OIDCConfig{}, nil)
+
+	resp, err := authUsecase.Login(ctx, &domain.LoginRequest{Email: user.Email, Password: "password123"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(tokenRepo.tokens) != 1 {
+		t.Fatalf("Expected 1 stored token, got %d", len(tokenRepo.tokens))
+	}
+	for _, stored := range tokenRepo.tokens {
+		if stored.AccessTokenHash != hashToken(resp.AccessToken) || stored.RefreshTokenHash != hashToken(resp.RefreshToken) {
+			t.Errorf("Expected the digests of the tokens to be stored, got %+v", stored)
+		}
+	}
+
+	if _, err := authUsecase.ValidateToken(ctx, resp.AccessToken); err != nil {
+		t.Errorf("Expected the access token to be valid, got %v", err)
+	}
+	if err := authUsecase.Logout(ctx, resp.RefreshToken, resp.RefreshToken); !errors.Is(err, pkgerrors.ErrInvalidToken) {
+		t.Errorf("Expected a mismatched access token to be refused, got %v", err)
+	}
+	if err := authUsecase.Logout(ctx, resp.AccessToken, resp.RefreshToken); err != nil {
+		t.Fatalf("Expected no error logging out, got %v", err)
+	}
+	if _, err := authUsecase.ValidateToken(ctx, resp.AccessToken); !errors.Is(err, pkgerrors.ErrTokenRevoked) {
+		t.Errorf("Expected the access token to be revoked, got %v", err)
+	}
+}
+
+// Test a mailed reset link sets the password once, signing the user out,
+// while unknown emails get no link
+func TestPasswordReset(t *testing.T) {
+	ctx := context.Background()
+
+	user, err := createTestUser(uuid.New().String(), "test@example.com", "password123")
+	if err != nil {
+		t.Fatalf("Failed to create test user: %v", err)
+	}
+	userRepo := &mockUserRepository{users: map[string]*domain.User{user.ID: user}}
+	tokenRepo := &mockTokenRepository{tokens: map[string]*domain.Token{
+		"session": {ID: "session", UserID: user.ID, ExpiresAt: time.Now().Add(time.Hour)},
+	}}
+	resetRepo := newMockPasswordResetRepository()
+	mailer := &mockMailSender{}
+	authUsecase := usecase.NewAuthUsecase(userRepo, tokenRepo, resetRepo, newMockEmailVerificationRepository(), nil, nil, security.NewPasswordHandler(), nil, mailer, mockMessageRenderer{},
+		config.RecoveryConfig{URL: "https://portal.example/reset", TTL: time.Hour}, config.SignupConfig{}, nil, nil, nil, config.OIDCConfig{}, nil)
+
+	if err := authUsecase.RequestPasswordReset(ctx, &domain.ForgotPasswordRequest{Email: "nobody@example.com"}); err != nil {
+		t.Fatalf("Expected no error for an unknown email, got %v", err)
+	}
+	if len(mailer.sent) != 0 {
+		t.Fatalf("Expected no mail for an unknown email, got %d", len(mailer.sent))
+	}
+
+	if err := authUsecase.RequestPasswordReset(ctx, &domain.ForgotPasswordRequest{Email: user.Email}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(mailer.sent) != 1 {
+		t.Fatalf("Expected 1 mail, got %d", len(mailer.sent))
+	}
+	_, link, _ := strings.Cut(mailer.sent[0], "https://portal.example/reset?token=")
+	token, _, _ := strings.Cut(link, "\n")
+	if len(resetRepo.tokens) != 1 || resetRepo.tokens[hashToken(token)] == nil {
+		t.Fatalf("Expected the digest of the mailed token to be stored, got %v", resetRepo.tokens)
+	}
+
+	if err := authUsecase.ResetPassword(ctx, &domain.ResetPasswordRequest{Token: "forged", Password: "newpassword"}); !errors.Is(err, pkgerrors.ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for an unknown token, got %v", err)
+	}
+	if err := authUsecase.ResetPassword(ctx, &domain.ResetPasswordRequest{Token: token, Password: "newpassword"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !security.NewPasswordHandler().Verify("newpassword", user.PasswordHash) {
+		t.Errorf("Expected the new password to be set")
+	}
+	if !tokenRepo.tokens["session"].Revoked {
+		t.Errorf("Expected the tokens of the user to be revoked")
+	}
+	if err := authUsecase.ResetPassword(ctx, &domain.ResetPasswordRequest{Token: token, Password: "otherpassword"}); !errors.Is(err, pkgerrors.ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput using a token twice, got %v", err)
+	}
+}
+
+// Test cleaning up deletes the sessions and links that can no longer be
+// used, and keeps the others
+func TestCleanupExpiredTokens(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	used := now.Add(-time.Minute)
+
+	tokenRepo := &mockTokenRepository{tokens: map[string]*domain.Token{
+		"active":  {ID: "active", ExpiresAt: now.Add(time.Hour)},
+		"expired": {ID: "expired", ExpiresAt: now.Add(-time.Hour)},
+		"revoked": {ID: "revoked", ExpiresAt: now.Add(time.Hour), Revoked: true},
+	}}
+	resetRepo := newMockPasswordResetRepository()
+	resetRepo.tokens["pending"] = &domain.PasswordResetToken{ExpiresAt: now.Add(time.Hour)}
+	resetRepo.tokens["used"] = &domain.PasswordResetToken{ExpiresAt: now.Add(time.Hour), UsedAt: &used}
+	verifyRepo := newMockEmailVerificationRepository()
+	verifyRepo.tokens["expired"] = &domain.EmailVerificationToken{ExpiresAt: now.Add(-time.Hour)}
+	authUsecase := usecase.NewAuthUsecase(&mockUserRepository{}, tokenRepo, resetRepo, verifyRepo, nil, nil, security.NewPasswordHandler(), nil, &mockMailSender{}, mockMessageRenderer{},
+		config.RecoveryConfig{}, config.SignupConfig{}, nil, nil, nil, config.OIDCConfig{}, nil)
+
+	deleted, err := authUsecase.CleanupExpiredTokens(ctx)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if deleted != 4 {
+		t.Errorf("Expected 4 tokens deleted, got %d", deleted)
+	}
+	if len(tokenRepo.tokens) != 1 || tokenRepo.tokens["active"] == nil {
+		t.Errorf("Expected the active session kept, got %v", tokenRepo.tokens)
+	}
+	if len(resetRepo.tokens) != 1 || resetRepo.tokens["pending"] == nil {
+		t.Errorf("Expected the pending reset token kept, got %v", resetRepo.tokens)
+	}
+	if len(verifyRepo.tokens) != 0 {
+		t.Errorf("Expected the expired verification token deleted, got %v", verifyRepo.tokens)
+	}
+}
+
+// Test a signed-in user changes their password with their current one,
+// signing out their other sessions only
+func TestChangePassword(t *testing.T) {
+	ctx := context.Background()
+
+	user, err := createTestUser(uuid.New().String(), "test@example.com", "password123")
+	if err != nil {
+		t.Fatalf("Failed to create test user: %v", err)
+	}
+	userRepo := &mockUserRepository{users: map[string]*domain.User{user.ID: user}}
+	tokenRepo := &mockTokenRepository{tokens: map[string]*domain.Token{
+		"current": {ID: "current", UserID: user.ID, AccessTokenHash: hashToken("current-access"), ExpiresAt: time.Now().Add(time.Hour)},
+		"other":   {ID: "other", UserID: user.ID, AccessTokenHash: hashToken("other-access"), ExpiresAt: time.Now().Add(time.Hour)},
+	}}
+	authUsecase := usecase.NewAuthUsecase(userRepo, tokenRepo, newMockPasswordResetRepository(), newMockEmailVerificationRepository(), nil, nil, security.NewPasswordHandler(), nil, &mockMailSender{}, mockMessageRenderer{}, config.RecoveryConfig{}, config.SignupConfig{}, nil, nil, nil, config.OIDCConfig{}, nil)
+
+	for name, req := range map[string]*domain.ChangePasswordRequest{
+		"wrong current password": {CurrentPassword: "wrongpassword", NewPassword: "newpassword"},
+		"too short":              {CurrentPassword: "password123", NewPassword: "short"},
+		"unchanged":              {CurrentPassword: "password123", NewPassword: "password123"},
+	} {
+		if err := authUsecase.ChangePassword(ctx, user.ID, "current-access", req); !errors.Is(err, pkgerrors.ErrInvalidInput) {
+			t.Errorf("Expected ErrInvalidInput for %s, got %v", name, err)
+		}
+	}
+	if tokenRepo.tokens["other"].Revoked {
+		t.Fatalf("Expected no session signed out by a refused change")
+	}
+
+	if err := authUsecase.ChangePassword(ctx, user.ID, "current-access", &domain.ChangePasswordRequest{CurrentPassword: "password123", NewPassword: "newpassword"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !security.NewPasswordHandler().Verify("newpassword", user.PasswordHash) {
+		t.Errorf("Expected the new password to be set")
+	}
+	if tokenRepo.tokens["current"].Revoked {
+		t.Errorf("Expected the session changing the password to stay signed in")
+	}
+	if !tokenRepo.tokens["other"].Revoked {
+		t.Errorf("Expected the other sessions to be signed out")
+	}
+}
+
+// Test the access tokens of the other sessions are refused once the user
+// changes their password, before they expire
+func TestChangePassword_RevokesAccessTokens(t *testing.T) {
+	ctx := context.Background()
+
+	user, err := createTestUser(uuid.New().String(), "test@example.com", "password123")
+	if err != nil {
+		t.Fatalf("Failed to create test user: %v", err)
+	}
+	userRepo := &mockUserRepository{
+		users: map[string]*domain.User{user.ID: user},
+		getUserByEmailFunc: func(ctx context.Context, email string) (*domain.User, error) {
+			return user, nil
+		},
+	}
+	tokenRepo := &mockTokenRepository{tokens: make(map[string]*domain.Token)}
+	jwtManager := security.NewJWTManager(&config.JWTConfig{
+		Secret:             "test-secret-key-for-testing",
+		AccessTokenExpiry:  time.Hour,
+		RefreshTokenExpiry: 24 * time.Hour,
+		Issuer:             "test",
+	})
+	authUsecase := usecase.NewAuthUsecase(userRepo, tokenRepo, newMockPasswordResetRepository(), newMockEmailVerificationRepository(), nil, jwtManager, security.NewPasswordHandler(), nil, &mockMailSender{}, mockMessageRenderer{}, config.RecoveryConfig{}, config.SignupConfig{}, nil, nil, nil, config.OIDCConfig{}, nil)
+
+	login := &domain.LoginRequest{Email: user.Email, Password: "password123"}
+	laptop, err := authUsecase.Login(ctx, login)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	phone, err := authUsecase.Login(ctx, login)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if laptop.AccessToken == phone.AccessToken {
+		t.Fatalf("Expected each session its own access token")
+	}
+
+	err = authUsecase.ChangePassword(ctx, user.ID, laptop.AccessToken, &domain.ChangePasswordRequest{CurrentPassword: "password123", NewPassword: "newpassword"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := authUsecase.ValidateToken(ctx, phone.AccessToken); !errors.Is(err, pkgerrors.ErrTokenRevoked) {
+		t.Errorf("Expected the access token of the other session to be refused, got %v", err)
+	}
+	if _, err := authUsecase.ValidateToken(ctx, laptop.AccessToken); err != nil {
+		t.Errorf("Expected the access token changing the password to stay valid, got %v", err)
+	}
+
+	// Valid tokens no session was stored for are refused as well
+	unstored, err := jwtManager.GenerateTokenPair(user.ID, "", "", user.Email, "")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	if _, err := authUsecase.ValidateToken(ctx, unstored.AccessToken); !errors.Is(err, pkgerrors.ErrTokenRevoked) {
+		t.Errorf("Expected a token without a session to be refused, got %v", err)
+	}
+}
+
+// Test passwords follow the password policy and do not repeat the last ones
+// of their user
+func TestChangePassword_Policy(t *testing.T) {
+	ctx := context.Background()
+
+	user, err := createTestUser(uuid.New().String(), "test@example.com", "First-pass1")
+	if err != nil {
+		t.Fatalf("Failed to create test user: %v", err)
+	}
+	userRepo := &mockUserRepository{users: map[string]*domain.User{user.ID: user}}
+	tokenRepo := &mockTokenRepository{tokens: map[string]*domain.Token{}}
+	passwords := security.NewPasswordHandler()
+	passwords.SetPolicy(config.PasswordPolicyConfig{MinLength: 10, MinClasses: 3, BanCommon: true, History: 3})
+	authUsecase := usecase.NewAuthUsecase(userRepo, tokenRepo, newMockPasswordResetRepository(), newMockEmailVerificationRepository(),
+		&mockPasswordHistoryRepository{hashes: map[string][]string{}}, nil, passwords, nil, &mockMailSender{}, mockMessageRenderer{},
+		config.RecoveryConfig{}, config.SignupConfig{}, nil, nil, nil, config.OIDCConfig{}, nil)
+
+	change := func(current, next string) error {
+		return authUsecase.ChangePassword(ctx, user.ID, "", &domain.ChangePasswordRequest{CurrentPassword: current, NewPassword: next})
+	}
+	for _, weak := range []string{"Short-1", "onlylowercase", "Password1234"} {
+		if err := change("First-pass1", weak); !errors.Is(err, pkgerrors.ErrInvalidInput) {
+			t.Errorf("Expected %q refused by the policy, got %v", weak, err)
+		}
+	}
+
+	if err := change("First-pass1", "Second-pass2"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := change("Second-pass2", "Third-pass3"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := change("Third-pass3", "First-pass1"); !errors.Is(err, pkgerrors.ErrInvalidInput) {
+		t.Errorf("Expected one of the last 3 passwords refused, got %v", err)
+	}
+	if err := change("Third-pass3", "Fourth-pass4"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := change("Fourth-pass4", "First-pass1"); err != nil {
+		t.Errorf("Expected a password older than the last 3 allowed, got %v", err)
+	}
+}
+
+// Test registered users stay pending until they verify their email when
+// verification is required
+func TestRegister_RequiresVerification(t *testing.T) {
+	ctx := context.Background()
+
+	userRepo := &mockUserRepository{users: map[string]*domain.User{}}
+	tokenRepo := &mockTokenRepository{tokens: make(map[string]*domain.Token)}
+	verifyRepo := newMockEmailVerificationRepository()
+	mailer := &mockMailSender{}
+	jwtManager := security.NewJWTManager(&config.JWTConfig{
+		Secret:             "test-secret-key-for-testing",
+		AccessTokenExpiry:  time.Hour,
+		RefreshTokenExpiry: 24 * time.Hour,
+		Issuer:             "test",
+	})
+	authUsecase := usecase.NewAuthUsecase(userRepo, tokenRepo, newMockPasswordResetRepository(), verifyRepo, nil, jwtManager, security.NewPasswordHandler(), nil, mailer, mockMessageRenderer{},
+		config.RecoveryConfig{}, config.SignupConfig{RequireVerification: true, VerifyURL: "https://portal.example/verify", VerificationTTL: time.Hour}, nil, nil, nil, config.OIDCConfig{}, nil)
+
+	resp, err := authUsecase.Register(ctx, &domain.RegisterRequest{
+		OrganizationID: uuid.New().String(),
+		RoleID:         uuid.New().String(),
+		Name:           "Test User",
+		Username:       "testuser",
+		Email:          "test@example.com",
+		Password:       "password123",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !resp.VerificationRequired || resp.AccessToken != "" {
+		t.Errorf("Expected verification to be required without tokens, got %+v", resp)
+	}
+	user := userRepo.users[resp.User.ID]
+	if user == nil || user.Status != domain.UserStatusPending {
+		t.Fatalf("Expected the user to be pending, got %+v", user)
+	}
+
+	login := &domain.LoginRequest{Email: "test@example.com", Password: "password123"}
+	if _, err := authUsecase.Login(ctx, login); !errors.Is(err, pkgerrors.ErrEmailNotVerified) {
+		t.Errorf("Expected ErrEmailNotVerified signing in before verifying, got %v", err)
+	}
+
+	if err := authUsecase.ResendVerification(ctx, &domain.ResendVerificationRequest{Email: user.Email}); err != nil {
+		t.Fatalf("Expected no error resending, got %v", err)
+	}
+	if len(mailer.sent) != 2 || len(verifyRepo.tokens) != 1 {
+		t.Fatalf("Expected 2 mails and only the last link to work, got %d mails and %d tokens", len(mailer.sent), len(verifyRepo.tokens))
+	}
+	_, link, _ := strings.Cut(mailer.sent[1], "https://portal.example/verify?token=")
+	token, _, _ := strings.Cut(link, "\n")
+
+	if err := authUsecase.VerifyEmail(ctx, &domain.VerifyEmailRequest{Token: token}); err != nil {
+		t.Fatalf("Expected no error verifying, got %v", err)
+	}
+	if user.Status != domain.UserStatusActive {
+		t.Errorf("Expected the user to be active, got %s", user.Status)
+	}
+	if err := authUsecase.VerifyEmail(ctx, &domain.VerifyEmailRequest{Token: token}); !errors.Is(err, pkgerrors.ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput using a link twice, got %v", err)
+	}
+	if _, err := authUsecase.Login(ctx, login); err != nil {
+		t.Errorf("Expected to sign in once verified, got %v", err)
+	}
+}
@@ -0,0 +1,34 @@
+package usecase
+
+import templateDomain "portal-data-backend/internal/message_template/domain"
+
+// Keys of the messages users are sent
+const (
+	MessagePasswordReset     = "auth.password_reset"
+	MessageEmailVerification = "auth.email_verification"
+)
+
+// Messages are the messages users are sent, with their built-in wording.
+// The module defines them with the message template registry.
+var Messages = []templateDomain.Definition{
+	{
+		Key:         MessagePasswordReset,
+		Channel:     templateDomain.ChannelEmail,
+		Description: "Password reset link mailed to a user who forgot their password",
+		Variables:   []string{"name", "link", "ttl"},
+		Subject:     "Reset your password",
+		Body: "Hello {{.name}},\n\n" +
+			"Someone asked to reset the password of your account. To choose a new one, open this link within {{.ttl}}:\n{{.link}}\n\n" +
+			"The link works once. If you did not ask for it, ignore this message; your password stays as it is.",
+	},
+	{
+		Key:         MessageEmailVerification,
+		Channel:     templateDomain.ChannelEmail,
+		Description: "Email verification link mailed to a user who registered",
+		Variables:   []string{"name", "link", "ttl"},
+		Subject:     "Verify your email address",
+		Body: "Hello {{.name}},\n\n" +
+			"Welcome! To finish creating your account, confirm your email address by opening this link within {{.ttl}}:\n{{.link}}\n\n" +
+			"If you did not register, ignore this message.",
+	},
+}
@@ -0,0 +1,238 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"portal-data-backend/infrastructure/audit"
+	"portal-data-backend/infrastructure/logger"
+	"portal-data-backend/infrastructure/security/oidc"
+	"portal-data-backend/internal/auth/domain"
+	"portal-data-backend/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// IdentityProvider signs users in with an external OpenID Connect provider
+type IdentityProvider interface {
+	// AuthCodeURL returns the URL users sign in at
+	AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error)
+
+	// Exchange trades the code users come back with for their verified
+	// claims
+	Exchange(ctx context.Context, code, verifier, nonce string) (*oidc.Claims, error)
+}
+
+// OIDCProviders returns the names of the external providers users may sign
+// in with
+func (a *authUsecase) OIDCProviders(ctx context.Context) []string {
+	names := make([]string, 0, len(a.providers))
+	for name := range a.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// StartOIDCLogin starts signing in with provider, returning the URL the user
+// signs in at. The state, nonce and code verifier of the sign in are kept
+// until the user comes back or it expires.
+func (a *authUsecase) StartOIDCLogin(ctx context.Context, provider string) (string, error) {
+	idp, ok := a.providers[provider]
+	if !ok {
+		return "", fmt.Errorf("%w: unknown provider %q", errors.ErrNotFound, provider)
+	}
+
+	var secrets [3]string
+	for i := range secrets {
+		secret, err := oidc.NewSecret()
+		if err != nil {
+			return "", fmt.Errorf("failed to generate oidc login secret: %w", err)
+		}
+		secrets[i] = secret
+	}
+	state, nonce, verifier := secrets[0], secrets[1], secrets[2]
+
+	authURL, err := idp.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	login := &domain.OIDCLogin{
+		ID:        uuid.New().String(),
+		Provider:  provider,
+		StateHash: hashToken(state),
+		Nonce:     nonce,
+		Verifier:  verifier,
+		ExpiresAt: now.Add(a.oidc.LoginTTL),
+		CreatedAt: now,
+	}
+	if err := a.loginRepo.CreateOIDCLogin(ctx, login); err != nil {
+		return "", fmt.Errorf("failed to store oidc login: %w", err)
+	}
+	return authURL, nil
+}
+
+// FinishOIDCLogin signs in the user coming back from provider. They are
+// found by their identity at the provider, or else linked by email to the
+// account using it, or else given an account when the provider provisions
+// users.
+func (a *authUsecase) FinishOIDCLogin(ctx context.Context, provider string, req *domain.OIDCCallbackRequest) (*domain.AuthResponse, error) {
+	idp, ok := a.providers[provider]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown provider %q", errors.ErrNotFound, provider)
+	}
+	cfg, _ := a.oidc.Provider(provider)
+
+	login, err := a.loginRepo.ConsumeOIDCLogin(ctx, provider, hashToken(req.State), time.Now())
+	if err != nil {
+		if errors.Is(err, errors.ErrNotFound) {
+			return nil, fmt.Errorf("%w: sign in is invalid or has expired, start it again", errors.ErrInvalidInput)
+		}
+		return nil, fmt.Errorf("failed to consume oidc login: %w", err)
+	}
+
+	method := "oidc:" + provider
+	claims, err := idp.Exchange(ctx, req.Code, login.Verifier, login.Nonce)
+	if err != nil {
+		logger.FromContext(ctx).Warn("sign in with %s failed: %v", provider, err)
+		a.authAudit.Record(ctx, audit.AuthEventLoginFailed, "", "", method+": exchange failed")
+		return nil, errors.ErrInvalidCredentials
+	}
+
+	user, err := a.oidcUser(ctx, provider, claims, cfg.TrustEmail, cfg.Provision, cfg.OrganizationID, cfg.RoleID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive() {
+		a.authAudit.Record(ctx, audit.AuthEventLoginFailed, user.ID, user.Email, method+": user disabled")
+		return nil, errors.ErrUserDisabled
+	}
+	return a.issueTokens(ctx, user, method)
+}
+
+// oidcUser finds or provisions the user claims describe
+func (a *authUsecase) oidcUser(ctx context.Context, provider string, claims *oidc.Claims, trustEmail, provision bool, organizationID, roleID string) (*domain.User, error) {
+	identity, err := a.identityRepo.GetIdentity(ctx, provider, claims.Subject)
+	if err == nil {
+		user, err := a.userRepo.GetUserByID(ctx, identity.UserID)
+		if errors.Is(err, errors.ErrNotFound) {
+			return nil, errors.ErrInvalidCredentials
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+		return user, nil
+	}
+	if !errors.Is(err, errors.ErrNotFound) {
+		return nil, fmt.Errorf("failed to get identity: %w", err)
+	}
+
+	// Only an email the provider vouches for links or provisions an account
+	if claims.Email == "" || !(claims.EmailVerified || trustEmail) {
+		return nil, errors.ErrEmailNotVerified
+	}
+
+	user, err := a.userRepo.GetUserByEmail(ctx, claims.Email)
+	switch {
+	case err == nil:
+		if user.Status == domain.UserStatusPending {
+			// The provider verified the email the user registered with
+			user.Status = domain.UserStatusActive
+			if err := a.userRepo.UpdateUser(ctx, user); err != nil {
+				return nil, fmt.Errorf("failed to update user: %w", err)
+			}
+			a.publish(ctx, domain.EventUserVerified, user.ToUserInfo())
+		}
+	case errors.Is(err, errors.ErrNotFound):
+		if !provision {
+			return nil, errors.ErrInvalidCredentials
+		}
+		if user, err = a.provisionUser(ctx, claims, organizationID, roleID); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	identity = &domain.Identity{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		Provider:  provider,
+		Subject:   claims.Subject,
+		Email:     claims.Email,
+		CreatedAt: time.Now(),
+	}
+	if err := a.identityRepo.CreateIdentity(ctx, identity); err != nil {
+		return nil, fmt.Errorf("failed to link identity: %w", err)
+	}
+	return user, nil
+}
+
+// provisionUser creates an active account for a user signing in with a
+// provider for the first time. Its password is random; the user can set one
+// through a password reset link.
+func (a *authUsecase) provisionUser(ctx context.Context, claims *oidc.Claims, organizationID, roleID string) (*domain.User, error) {
+	password, err := newLinkToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate password: %w", err)
+	}
+	username, err := a.freeUsername(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+	name := claims.Name
+	if name == "" {
+		name = username
+	}
+
+	user, err := a.createUser(ctx, &domain.RegisterRequest{
+		OrganizationID: organizationID,
+		RoleID:         roleID,
+		Name:           name,
+		Username:       username,
+		Email:          claims.Email,
+		Password:       password,
+	}, domain.UserStatusActive)
+	if err != nil {
+		return nil, err
+	}
+	a.publish(ctx, domain.EventUserRegistered, user.ToUserInfo())
+	return user, nil
+}
+
+// freeUsername derives an unused alphanumeric username from the preferred
+// username of claims or their email
+func (a *authUsecase) freeUsername(ctx context.Context, claims *oidc.Claims) (string, error) {
+	base := claims.Username
+	if base == "" {
+		base, _, _ = strings.Cut(claims.Email, "@")
+	}
+	base = strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, base)
+	for len(base) < 3 {
+		base += "0"
+	}
+
+	candidate := base
+	for i := 2; i < 100; i++ {
+		exists, err := a.userRepo.IsUsernameExists(ctx, candidate)
+		if err != nil {
+			return "", fmt.Errorf("failed to check username existence: %w", err)
+		}
+		if !exists {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s%d", base, i)
+	}
+	return "", errors.ErrUsernameTaken
+}
@@ -0,0 +1,189 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/infrastructure/security"
+	"portal-data-backend/infrastructure/security/oidc"
+	"portal-data-backend/internal/auth/domain"
+	"portal-data-backend/internal/auth/usecase"
+	pkgerrors "portal-data-backend/pkg/errors"
+)
+
+// mockIdentityRepository is an in-memory IdentityRepository
+type mockIdentityRepository struct {
+	identities map[string]*domain.Identity
+}
+
+func (m *mockIdentityRepository) GetIdentity(ctx context.Context, provider, subject string) (*domain.Identity, error) {
+	identity, ok := m.identities[provider+"/"+subject]
+	if !ok {
+		return nil, pkgerrors.ErrNotFound
+	}
+	return identity, nil
+}
+
+func (m *mockIdentityRepository) CreateIdentity(ctx context.Context, identity *domain.Identity) error {
+	m.identities[identity.Provider+"/"+identity.Subject] = identity
+	return nil
+}
+
+// mockOIDCLoginRepository is an in-memory OIDCLoginRepository
+type mockOIDCLoginRepository struct {
+	logins map[string]*domain.OIDCLogin
+}
+
+func (m *mockOIDCLoginRepository) CreateOIDCLogin(ctx context.Context, login *domain.OIDCLogin) error {
+	m.logins[login.StateHash] = login
+	return nil
+}
+
+func (m *mockOIDCLoginRepository) ConsumeOIDCLogin(ctx context.Context, provider, stateHash string, now time.Time) (*domain.OIDCLogin, error) {
+	login, ok := m.logins[stateHash]
+	if !ok || login.Provider != provider || !login.ExpiresAt.After(now) {
+		return nil, pkgerrors.ErrNotFound
+	}
+	delete(m.logins, stateHash)
+	return login, nil
+}
+
+// mockIdentityProvider signs in whoever claims describes, once the nonce of
+// the sign in comes back
+type mockIdentityProvider struct {
+	claims oidc.Claims
+	nonce  string
+}
+
+func (m *mockIdentityProvider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	m.nonce = nonce
+	return "https://idp.example/authorize?" + url.Values{"state": {state}}.Encode(), nil
+}
+
+func (m *mockIdentityProvider) Exchange(ctx context.Context, code, verifier, nonce string) (*oidc.Claims, error) {
+	if code != "c0de" || nonce != m.nonce || verifier == "" {
+		return nil, oidc.ErrInvalidIDToken
+	}
+	claims := m.claims
+	return &claims, nil
+}
+
+// oidcFixture is an auth usecase with a keycloak provider
+type oidcFixture struct {
+	usecase    usecase.Usecase
+	users      *mockUserRepository
+	identities *mockIdentityRepository
+	provider   *mockIdentityProvider
+}
+
+func newOIDCFixture(t *testing.T, cfg config.OIDCProviderConfig) *oidcFixture {
+	t.Helper()
+	cfg.Name = "keycloak"
+	f := &oidcFixture{
+		users:      &mockUserRepository{users: map[string]*domain.User{}},
+		identities: &mockIdentityRepository{identities: map[string]*domain.Identity{}},
+		provider:   &mockIdentityProvider{claims: oidc.Claims{Subject: "kc-1", Email: "siti@example.com", EmailVerified: true, Name: "Siti", Username: "siti.n"}},
+	}
+	jwtManager := security.NewJWTManager(&config.JWTConfig{
+		Secret:             "test-secret-key-for-testing",
+		AccessTokenExpiry:  time.Hour,
+		RefreshTokenExpiry: 24 * time.Hour,
+		Issuer:             "test",
+	})
+	f.usecase = usecase.NewAuthUsecase(f.users, &mockTokenRepository{tokens: make(map[string]*domain.Token)}, newMockPasswordResetRepository(), newMockEmailVerificationRepository(),
+		nil, jwtManager, security.NewPasswordHandler(), nil, &mockMailSender{}, mockMessageRenderer{}, config.RecoveryConfig{}, config.SignupConfig{},
+		f.identities, &mockOIDCLoginRepository{logins: map[string]*domain.OIDCLogin{}}, map[string]usecase.IdentityProvider{"keycloak": f.provider},
+		config.OIDCConfig{LoginTTL: time.Minute, Providers: []config.OIDCProviderConfig{cfg}}, nil)
+	return f
+}
+
+// signIn starts signing in and comes back with the state of the sign in
+func (f *oidcFixture) signIn(t *testing.T) (*domain.AuthResponse, error) {
+	t.Helper()
+	ctx := context.Background()
+	authURL, err := f.usecase.StartOIDCLogin(ctx, "keycloak")
+	if err != nil {
+		t.Fatalf("Expected no error starting to sign in, got %v", err)
+	}
+	parsed, _ := url.Parse(authURL)
+	return f.usecase.FinishOIDCLogin(ctx, "keycloak", &domain.OIDCCallbackRequest{Code: "c0de", State: parsed.Query().Get("state")})
+}
+
+// Test users signing in with a provider are linked by their verified email
+func TestOIDCLogin_LinksByEmail(t *testing.T) {
+	f := newOIDCFixture(t, config.OIDCProviderConfig{})
+	user, _ := createTestUser("user-1", "siti@example.com", "password123")
+	user.Status = domain.UserStatusPending
+	f.users.users[user.ID] = user
+
+	resp, err := f.signIn(t)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.User.ID != user.ID || resp.AccessToken == "" {
+		t.Errorf("Expected tokens for the user with the email, got %+v", resp)
+	}
+	if user.Status != domain.UserStatusActive {
+		t.Errorf("Expected the provider to verify the pending user, got %s", user.Status)
+	}
+	if identity := f.identities.identities["keycloak/kc-1"]; identity == nil || identity.UserID != user.ID {
+		t.Fatalf("Expected the identity to be linked to the user, got %+v", identity)
+	}
+
+	// The link holds once the email at the provider changes
+	f.provider.claims.Email = "siti@elsewhere.example"
+	if resp, err := f.signIn(t); err != nil || resp.User.ID != user.ID {
+		t.Errorf("Expected the linked user to sign in, got %+v, %v", resp, err)
+	}
+}
+
+// Test users new to the portal get an account when the provider provisions
+// them
+func TestOIDCLogin_Provisions(t *testing.T) {
+	f := newOIDCFixture(t, config.OIDCProviderConfig{})
+	if _, err := f.signIn(t); !errors.Is(err, pkgerrors.ErrInvalidCredentials) {
+		t.Fatalf("Expected ErrInvalidCredentials without provisioning, got %v", err)
+	}
+
+	f = newOIDCFixture(t, config.OIDCProviderConfig{Provision: true, OrganizationID: "org-1", RoleID: "role-1"})
+	taken, _ := createTestUser("user-1", "other@example.com", "password123")
+	taken.Username = "sitin"
+	f.users.users[taken.ID] = taken
+
+	resp, err := f.signIn(t)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	user := f.users.users[resp.User.ID]
+	if user == nil || user.Status != domain.UserStatusActive || user.OrganizationID != "org-1" || user.RoleID != "role-1" {
+		t.Fatalf("Expected an active user in the configured organization and role, got %+v", user)
+	}
+	if user.Username != "sitin2" || user.Name != "Siti" || user.Email != "siti@example.com" {
+		t.Errorf("Expected the username, name and email from the claims, got %+v", user)
+	}
+}
+
+// Test unverified emails neither link nor provision accounts, and states
+// work once
+func TestOIDCLogin_Rejects(t *testing.T) {
+	ctx := context.Background()
+	f := newOIDCFixture(t, config.OIDCProviderConfig{Provision: true, OrganizationID: "org-1", RoleID: "role-1"})
+	f.provider.claims.EmailVerified = false
+	if _, err := f.signIn(t); !errors.Is(err, pkgerrors.ErrEmailNotVerified) {
+		t.Errorf("Expected ErrEmailNotVerified, got %v", err)
+	}
+	if len(f.users.users) != 0 {
+		t.Errorf("Expected no user to be provisioned, got %d", len(f.users.users))
+	}
+
+	if _, err := f.usecase.FinishOIDCLogin(ctx, "keycloak", &domain.OIDCCallbackRequest{Code: "c0de", State: "guessed"}); !errors.Is(err, pkgerrors.ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for an unknown state, got %v", err)
+	}
+	if _, err := f.usecase.StartOIDCLogin(ctx, "github"); !errors.Is(err, pkgerrors.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an unknown provider, got %v", err)
+	}
+}
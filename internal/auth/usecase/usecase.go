@@ -4,8 +4,21 @@ import (
 	"context"
 
 	"portal-data-backend/internal/auth/domain"
+	templateDomain "portal-data-backend/internal/message_template/domain"
 )
 
+// MailSender sends mail to users, such as their password reset and email
+// verification links
+type MailSender interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// MessageRenderer renders the wording of the mail users are sent from the
+// templates of the message template module
+type MessageRenderer interface {
+	Render(ctx context.Context, channel templateDomain.Channel, key string, vars map[string]string) (*templateDomain.Message, error)
+}
+
 // Usecase defines the interface for auth business logic
 type Usecase interface {
 	// Login authenticates a user and returns tokens
@@ -14,6 +27,45 @@ type Usecase interface {
 	// Register creates a new user account
 	Register(ctx context.Context, req *domain.RegisterRequest) (*domain.AuthResponse, error)
 
+	// CreateUser creates a user account without signing it in
+	CreateUser(ctx context.Context, req *domain.RegisterRequest) (*domain.UserInfo, error)
+
+	// SetPassword sets the password of a user and signs them out everywhere
+	SetPassword(ctx context.Context, email, password string) error
+
+	// RequestPasswordReset mails a password reset link to the user with the
+	// email of req, if there is one
+	RequestPasswordReset(ctx context.Context, req *domain.ForgotPasswordRequest) error
+
+	// ResetPassword sets a new password with the token of a reset link and
+	// signs the user out everywhere
+	ResetPassword(ctx context.Context, req *domain.ResetPasswordRequest) error
+
+	// ChangePassword replaces the password of a signed-in user once their
+	// current one is verified, signing them out everywhere but the session
+	// of accessToken
+	ChangePassword(ctx context.Context, userID, accessToken string, req *domain.ChangePasswordRequest) error
+
+	// VerifyEmail verifies the email of a user with the token of a
+	// verification link, letting a pending user sign in
+	VerifyEmail(ctx context.Context, req *domain.VerifyEmailRequest) error
+
+	// ResendVerification mails a new verification link to the pending user
+	// with the email of req, if there is one
+	ResendVerification(ctx context.Context, req *domain.ResendVerificationRequest) error
+
+	// OIDCProviders returns the names of the external providers users may
+	// sign in with
+	OIDCProviders(ctx context.Context) []string
+
+	// StartOIDCLogin starts signing in with an external provider, returning
+	// the URL the user signs in at
+	StartOIDCLogin(ctx context.Context, provider string) (string, error)
+
+	// FinishOIDCLogin signs in the user coming back from an external
+	// provider, linking or provisioning their account
+	FinishOIDCLogin(ctx context.Context, provider string, req *domain.OIDCCallbackRequest) (*domain.AuthResponse, error)
+
 	// Logout logs out a user by revoking their tokens
 	Logout(ctx context.Context, accessToken, refreshToken string) error
 
@@ -23,9 +75,18 @@ type Usecase interface {
 	// RevokeAllTokens revokes all tokens for a user
 	RevokeAllTokens(ctx context.Context, userID string) error
 
-	// ValidateToken validates a token and returns the claims
+	// CleanupExpiredTokens deletes the tokens that can no longer be used:
+	// expired or revoked sessions and used or expired password reset and
+	// email verification tokens. It returns how many it deleted.
+	CleanupExpiredTokens(ctx context.Context) (int64, error)
+
+	// ValidateToken validates a token and returns the claims, failing with
+	// errors.ErrTokenRevoked once its session is signed out
 	ValidateToken(ctx context.Context, token string) (*domain.TokenClaims, error)
 
 	// GetCurrentUser retrieves the current user by ID
 	GetCurrentUser(ctx context.Context, userID string) (*domain.UserInfo, error)
+
+	// GetUserByEmail retrieves a user by email
+	GetUserByEmail(ctx context.Context, email string) (*domain.UserInfo, error)
 }
@@ -0,0 +1,93 @@
+// Package bootstrap builds the modules of the API outside the server, for
+// commands like portalctl and seed that run tasks through the usecases.
+package bootstrap
+
+import (
+	"fmt"
+
+	"portal-data-backend/infrastructure/cache"
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/infrastructure/db"
+	"portal-data-backend/infrastructure/deprecation"
+	"portal-data-backend/infrastructure/events"
+	"portal-data-backend/infrastructure/health"
+	"portal-data-backend/infrastructure/logger"
+	"portal-data-backend/infrastructure/security"
+	"portal-data-backend/internal/app"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Env is the configuration, database and, once built, modules a command
+// runs against
+type Env struct {
+	Config *config.Config
+	Logger *logger.Logger
+	DB     *sqlx.DB
+	// Deps is nil until the modules are built
+	Deps *app.Deps
+
+	opts config.Options
+}
+
+// Open loads the configuration of opts and connects to the primary
+// database, without a statement timeout. Read replicas are not used;
+// commands read their own writes.
+func Open(opts config.Options) (*Env, error) {
+	cfg, err := config.LoadWith(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	appLogger, err := logger.New(cfg.App.LogLevel, cfg.App.Environment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize logger: %w", err)
+	}
+	logger.SetDefault(appLogger)
+
+	// Migrations and maintenance tasks run statements longer than requests
+	// may, and are stopped by their operator instead
+	cfg.Database.StatementTimeout = 0
+	postgres, err := db.NewPostgres(&cfg.Database)
+	if err != nil {
+		return nil, err
+	}
+	for _, replica := range postgres.Replicas {
+		replica.Close()
+	}
+	return &Env{Config: cfg, Logger: appLogger, DB: postgres.DB, opts: opts}, nil
+}
+
+// Register builds the modules of registry like the server does. Their events
+// are recorded in the outbox, which the server relays.
+func (e *Env) Register(registry *app.Registry) error {
+	cacheBackend, err := cache.New(&e.Config.Cache, &e.Config.Redis)
+	if err != nil {
+		return fmt.Errorf("failed to initialize cache: %w", err)
+	}
+
+	deps := &app.Deps{
+		Config:   e.Config,
+		Logger:   e.Logger,
+		DB:       e.DB,
+		DBRouter: db.NewRouter(e.DB),
+		JWT:      security.NewJWTManager(&e.Config.JWT),
+		Tx:       db.NewTxManager(e.DB),
+		Events:   &events.Bus{},
+		Outbox:   events.NewOutbox(e.DB),
+		Cache:    cache.NewStore(cacheBackend, e.Config.Cache.KeyPrefix),
+		Health:   &health.Registry{},
+		Reloader: config.NewReloader(e.Config, e.opts),
+
+		Deprecations: &deprecation.Registry{},
+	}
+	if err := registry.Register(deps); err != nil {
+		return fmt.Errorf("failed to initialize modules: %w", err)
+	}
+	e.Deps = deps
+	return nil
+}
+
+// Close closes the database
+func (e *Env) Close() {
+	e.DB.Close()
+}
@@ -1,42 +1,41 @@
 package http
 
 import (
-	"encoding/json"
-	"errors"
 	"net/http"
 	"strconv"
 
+	"portal-data-backend/infrastructure/auth"
 	bfDomain "portal-data-backend/internal/business_field/domain"
 	"portal-data-backend/internal/business_field/usecase"
+	"portal-data-backend/infrastructure/http/httputil"
+	"portal-data-backend/infrastructure/http/middleware"
+	"portal-data-backend/infrastructure/http/problem"
 	"portal-data-backend/infrastructure/http/response"
+	roleDomain "portal-data-backend/internal/role/domain"
 	pkgErrors "portal-data-backend/pkg/errors"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/go-playground/validator/v10"
 )
 
 type Handler struct {
 	bfUsecase usecase.Usecase
-	validator  *validator.Validate
 }
 
 func NewHandler(bfUsecase usecase.Usecase) *Handler {
 	return &Handler{
 		bfUsecase: bfUsecase,
-		validator: validator.New(),
 	}
 }
 
 func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Business Field ID is required", nil)
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
 	bf, err := h.bfUsecase.GetByID(r.Context(), id)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -49,10 +48,13 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 		Limit: parseIntQuery(r, "limit", 20),
 		Search: r.URL.Query().Get("search"),
 	}
+	if featured, err := strconv.ParseBool(r.URL.Query().Get("featured")); err == nil {
+		req.Featured = &featured
+	}
 
 	resp, err := h.bfUsecase.List(r.Context(), req)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -60,20 +62,14 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
-	var req bfDomain.CreateBusinessFieldRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, response.CodeBadRequest, "Invalid request body", nil)
-		return
-	}
-
-	if err := h.validator.Struct(&req); err != nil {
-		response.ValidationError(w, response.CodeValidationFailed, "Validation failed", h.formatValidationErrors(err))
+	req, ok := httputil.Decode[bfDomain.CreateBusinessFieldRequest](w, r)
+	if !ok {
 		return
 	}
 
-	bf, err := h.bfUsecase.Create(r.Context(), &req)
+	bf, err := h.bfUsecase.Create(r.Context(), req)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -81,26 +77,19 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Business Field ID is required", nil)
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
-	var req bfDomain.UpdateBusinessFieldRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, response.CodeBadRequest, "Invalid request body", nil)
+	req, ok := httputil.Decode[bfDomain.UpdateBusinessFieldRequest](w, r)
+	if !ok {
 		return
 	}
 
-	if err := h.validator.Struct(&req); err != nil {
-		response.ValidationError(w, response.CodeValidationFailed, "Validation failed", h.formatValidationErrors(err))
-		return
-	}
-
-	bf, err := h.bfUsecase.Update(r.Context(), id, &req)
+	bf, err := h.bfUsecase.Update(r.Context(), id, req)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -108,55 +97,101 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Business Field ID is required", nil)
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
-	if err := h.bfUsecase.Delete(r.Context(), id); err != nil {
-		h.handleError(w, err)
+	var reassignTo *string
+	if target := r.URL.Query().Get("reassign_to"); target != "" {
+		reassignTo = &target
+	}
+
+	if err := h.bfUsecase.Delete(r.Context(), id, reassignTo); err != nil {
+		h.handleError(w, r, err)
 		return
 	}
 
 	response.OK(w, response.CodeSuccess, "Business field deleted successfully", nil)
 }
 
-func (h *Handler) handleError(w http.ResponseWriter, err error) {
-	if err == nil {
+func (h *Handler) UploadIcon(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
-	switch {
-	case errors.Is(err, pkgErrors.ErrNotFound):
-		response.NotFound(w, response.CodeNotFound, "Business field not found", nil)
-	default:
-		response.InternalError(w, response.CodeInternalServerError, "Internal server error", nil)
+	if err := r.ParseMultipartForm(4 << 20); err != nil {
+		if httputil.BodyTooLarge(w, err) {
+			return
+		}
+		response.BadRequest(w, response.CodeBadRequest, "Failed to parse form data", nil)
+		return
 	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		response.BadRequest(w, response.CodeBadRequest, "File is required", nil)
+		return
+	}
+	defer file.Close()
+
+	userID := auth.UserID(r.Context())
+
+	bf, err := h.bfUsecase.UploadIcon(r.Context(), id, header.Filename, header.Size, header.Header.Get("Content-Type"), file, userID)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Business field icon uploaded successfully", bf)
 }
 
-func (h *Handler) formatValidationErrors(err error) []response.ErrorDetail {
-	var details []response.ErrorDetail
-	if validationErrors, ok := err.(validator.ValidationErrors); ok {
-		for _, fieldErr := range validationErrors {
-			details = append(details, response.ErrorDetail{
-				Field:   fieldErr.Field(),
-				Message: h.getValidationErrorMessage(fieldErr),
-			})
-		}
+func (h *Handler) Export(w http.ResponseWriter, r *http.Request) {
+	data, err := h.bfUsecase.Export(r.Context())
+	if err != nil {
+		h.handleError(w, r, err)
+		return
 	}
-	return details
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="business-fields.csv"`)
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
 }
 
-func (h *Handler) getValidationErrorMessage(fieldErr validator.FieldError) string {
-	switch fieldErr.Tag() {
-	case "required":
-		return fieldErr.Field() + " is required"
-	case "min":
-		return fieldErr.Field() + " must be at least " + fieldErr.Param() + " characters"
-	default:
-		return fieldErr.Field() + " is invalid"
+func (h *Handler) Import(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(10 << 20); err != nil {
+		if httputil.BodyTooLarge(w, err) {
+			return
+		}
+		response.BadRequest(w, response.CodeBadRequest, "Failed to parse form data", nil)
+		return
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		response.BadRequest(w, response.CodeBadRequest, "File is required", nil)
+		return
+	}
+	defer file.Close()
+
+	result, err := h.bfUsecase.Import(r.Context(), file)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
 	}
+
+	response.OK(w, response.CodeSuccess, "Business fields imported successfully", result)
+}
+
+// errorMapper maps the errors of the business field module on top of the shared ones
+var errorMapper = problem.Default.With(
+	problem.Mapping{Err: pkgErrors.ErrNotFound, Status: http.StatusNotFound, Code: response.CodeNotFound, Message: "Business field not found"},
+)
+
+func (h *Handler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	errorMapper.Write(w, r, err)
 }
 
 func parseIntQuery(r *http.Request, key string, defaultValue int) int {
@@ -168,12 +203,22 @@ func parseIntQuery(r *http.Request, key string, defaultValue int) int {
 	return defaultValue
 }
 
-func RegisterRoutes(r chi.Router, handler *Handler) {
+// RegisterRoutes registers business field routes. Reads are public; writes
+// go through auth and require the taxonomies:write permission.
+func RegisterRoutes(r chi.Router, handler *Handler, auth func(http.Handler) http.Handler) {
 	r.Route("/business-fields", func(r chi.Router) {
 		r.Get("/", handler.List)
-		r.Post("/", handler.Create)
 		r.Get("/{id}", handler.GetByID)
-		r.Put("/{id}", handler.Update)
-		r.Delete("/{id}", handler.Delete)
+
+		r.Group(func(r chi.Router) {
+			r.Use(auth)
+			r.Use(middleware.RequirePermission(roleDomain.PermissionTaxonomiesWrite))
+			r.Post("/", handler.Create)
+			r.Get("/export", handler.Export)
+			r.Post("/import", handler.Import)
+			r.Put("/{id}", handler.Update)
+			r.Post("/{id}/icon", handler.UploadIcon)
+			r.Delete("/{id}", handler.Delete)
+		})
 	})
 }
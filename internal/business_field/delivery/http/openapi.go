@@ -0,0 +1,21 @@
+package http
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	bfDomain "portal-data-backend/internal/business_field/domain"
+)
+
+// Describe adds the business field routes to the API specification
+func Describe(spec *openapi.Spec) {
+	api := spec.Tag("business-fields", "Business fields of datasets")
+	api.Get("/business-fields", "List business fields").Public().Query(bfDomain.ListBusinessFieldsRequest{}).Returns(http.StatusOK, bfDomain.BusinessFieldListResponse{})
+	api.Post("/business-fields", "Create business field").Body(bfDomain.CreateBusinessFieldRequest{}).Returns(http.StatusCreated, bfDomain.BusinessFieldResponse{})
+	api.Get("/business-fields/export", "Export business fields as CSV").ReturnsFile(http.StatusOK, "text/csv")
+	api.Post("/business-fields/import", "Import business fields from CSV").Upload("file").Returns(http.StatusOK, bfDomain.ImportResult{})
+	api.Get("/business-fields/{id}", "Get business field").Public().Returns(http.StatusOK, bfDomain.BusinessFieldResponse{})
+	api.Put("/business-fields/{id}", "Update business field").Body(bfDomain.UpdateBusinessFieldRequest{}).Returns(http.StatusOK, bfDomain.BusinessFieldResponse{})
+	api.Post("/business-fields/{id}/icon", "Upload business field icon").Upload("file").Returns(http.StatusOK, bfDomain.BusinessFieldResponse{})
+	api.Delete("/business-fields/{id}", "Delete business field").Param("reassign_to", false).Returns(http.StatusOK, nil)
+}
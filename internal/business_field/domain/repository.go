@@ -7,8 +7,17 @@ import (
 // Repository defines the interface for business field data operations
 type Repository interface {
 	GetByID(ctx context.Context, id string) (*BusinessField, error)
-	List(ctx context.Context, search string, limit, offset int) ([]*BusinessField, int, error)
+	// GetByIDs retrieves the business fields with ids in one query
+	GetByIDs(ctx context.Context, ids []string) ([]*BusinessField, error)
+	List(ctx context.Context, filter *BusinessFieldFilter, limit, offset int) ([]*BusinessField, int, error)
+	// ListAll returns every business field in display order
+	ListAll(ctx context.Context) ([]*BusinessField, error)
 	Create(ctx context.Context, bf *BusinessField) error
 	Update(ctx context.Context, bf *BusinessField) error
 	Delete(ctx context.Context, id string) error
+	// CountDatasets counts the datasets referencing the business field
+	CountDatasets(ctx context.Context, id string) (int, error)
+	// ReassignAndDelete moves the datasets of a business field to the business field targetID
+	// and deletes it in one transaction
+	ReassignAndDelete(ctx context.Context, id, targetID string) error
 }
@@ -0,0 +1,47 @@
+// Package businessfield is the module managing business fields of datasets.
+package businessfield
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	"portal-data-backend/internal/app"
+	delivery "portal-data-backend/internal/business_field/delivery/http"
+	"portal-data-backend/internal/business_field/repository"
+	"portal-data-backend/internal/business_field/usecase"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Module manages business fields
+type Module struct {
+	handler *delivery.Handler
+}
+
+// Name implements app.Module
+func (m *Module) Name() string {
+	return "business_field"
+}
+
+// Register implements app.Module
+func (m *Module) Register(deps *app.Deps) error {
+	if deps.Services.Files == nil {
+		return app.MissingServiceError("file")
+	}
+
+	repo := repository.NewBusinessFieldPostgresRepository(deps.DB)
+	businessFields := usecase.NewBusinessFieldUsecase(repo, deps.Services.Files, deps.Services.Audit)
+	deps.Services.BusinessFields = businessFields
+	m.handler = delivery.NewHandler(businessFields)
+	return nil
+}
+
+// Routes implements app.Module
+func (m *Module) Routes(r chi.Router, auth func(http.Handler) http.Handler) {
+	delivery.RegisterRoutes(r, m.handler, auth)
+}
+
+// Describe implements app.Module
+func (m *Module) Describe(spec *openapi.Spec) {
+	delivery.Describe(spec)
+}
@@ -2,8 +2,10 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 
+	"portal-data-backend/infrastructure/db"
 	"portal-data-backend/internal/business_field/domain"
 	"portal-data-backend/pkg/errors"
 
@@ -19,39 +21,62 @@ func NewBusinessFieldPostgresRepository(db *sqlx.DB) domain.Repository {
 }
 
 func (r *businessFieldPostgresRepository) GetByID(ctx context.Context, id string) (*domain.BusinessField, error) {
-	query := `SELECT id, name, slug, created_at FROM business_fields WHERE id = $1`
+	query := `SELECT id, name, slug, names, icon_url, display_order, is_featured, created_at FROM business_fields WHERE id = $1`
 	var bf domain.BusinessField
-	err := r.db.GetContext(ctx, &bf, query, id)
+	err := db.Conn(ctx, r.db).GetContext(ctx, &bf, query, id)
 	if err != nil {
 		return nil, r.handleError(err)
 	}
 	return &bf, nil
 }
 
-func (r *businessFieldPostgresRepository) List(ctx context.Context, search string, limit, offset int) ([]*domain.BusinessField, int, error) {
+func (r *businessFieldPostgresRepository) GetByIDs(ctx context.Context, ids []string) ([]*domain.BusinessField, error) {
+	businessFields := []*domain.BusinessField{}
+	if len(ids) == 0 {
+		return businessFields, nil
+	}
+
+	query, args, err := sqlx.In(`SELECT id, name, slug, names, icon_url, display_order, is_featured, created_at FROM business_fields WHERE id IN (?)`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get business fields: %w", err)
+	}
+
+	conn := db.Conn(ctx, r.db)
+	if err := conn.SelectContext(ctx, &businessFields, conn.Rebind(query), args...); err != nil {
+		return nil, fmt.Errorf("failed to get business fields: %w", err)
+	}
+	return businessFields, nil
+}
+
+func (r *businessFieldPostgresRepository) List(ctx context.Context, filter *domain.BusinessFieldFilter, limit, offset int) ([]*domain.BusinessField, int, error) {
 	whereClause := "WHERE 1=1"
 	args := []interface{}{}
 	argCount := 1
 
-	if search != "" {
+	if filter.Search != "" {
 		whereClause += fmt.Sprintf(" AND (name ILIKE $%d OR slug ILIKE $%d)", argCount, argCount)
-		searchTerm := "%" + search + "%"
+		searchTerm := "%" + filter.Search + "%"
 		args = append(args, searchTerm, searchTerm)
 		argCount += 2
 	}
+	if filter.Featured != nil {
+		whereClause += fmt.Sprintf(" AND is_featured = $%d", argCount)
+		args = append(args, *filter.Featured)
+		argCount++
+	}
 
 	countQuery := "SELECT COUNT(*) FROM business_fields " + whereClause
 	var total int
-	err := r.db.GetContext(ctx, &total, countQuery, args...)
+	err := db.Conn(ctx, r.db).GetContext(ctx, &total, countQuery, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count business fields: %w", err)
 	}
 
-	query := "SELECT id, name, slug, created_at FROM business_fields " + whereClause + " ORDER BY name ASC LIMIT $" + fmt.Sprintf("%d", argCount) + " OFFSET $" + fmt.Sprintf("%d", argCount+1)
+	query := "SELECT id, name, slug, names, icon_url, display_order, is_featured, created_at FROM business_fields " + whereClause + " ORDER BY display_order ASC, name ASC LIMIT $" + fmt.Sprintf("%d", argCount) + " OFFSET $" + fmt.Sprintf("%d", argCount+1)
 	args = append(args, limit, offset)
 
 	var bfs []*domain.BusinessField
-	err = r.db.SelectContext(ctx, &bfs, query, args...)
+	err = db.Conn(ctx, r.db).SelectContext(ctx, &bfs, query, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to list business fields: %w", err)
 	}
@@ -59,9 +84,19 @@ func (r *businessFieldPostgresRepository) List(ctx context.Context, search strin
 	return bfs, total, nil
 }
 
+func (r *businessFieldPostgresRepository) ListAll(ctx context.Context) ([]*domain.BusinessField, error) {
+	query := `SELECT id, name, slug, names, icon_url, display_order, is_featured, created_at FROM business_fields ORDER BY display_order ASC, name ASC`
+	var bfs []*domain.BusinessField
+	err := db.Conn(ctx, r.db).SelectContext(ctx, &bfs, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list business fields: %w", err)
+	}
+	return bfs, nil
+}
+
 func (r *businessFieldPostgresRepository) Create(ctx context.Context, bf *domain.BusinessField) error {
-	query := `INSERT INTO business_fields (id, name, slug, created_at) VALUES (:id, :name, :slug, :created_at)`
-	_, err := r.db.NamedExecContext(ctx, query, bf)
+	query := `INSERT INTO business_fields (id, name, slug, names, icon_url, display_order, is_featured, created_at) VALUES (:id, :name, :slug, :names, :icon_url, :display_order, :is_featured, :created_at)`
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, bf)
 	if err != nil {
 		return fmt.Errorf("failed to create business field: %w", err)
 	}
@@ -69,8 +104,8 @@ func (r *businessFieldPostgresRepository) Create(ctx context.Context, bf *domain
 }
 
 func (r *businessFieldPostgresRepository) Update(ctx context.Context, bf *domain.BusinessField) error {
-	query := `UPDATE business_fields SET name = :name, slug = :slug WHERE id = :id`
-	result, err := r.db.NamedExecContext(ctx, query, bf)
+	query := `UPDATE business_fields SET name = :name, slug = :slug, names = :names, icon_url = :icon_url, display_order = :display_order, is_featured = :is_featured WHERE id = :id`
+	result, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, bf)
 	if err != nil {
 		return fmt.Errorf("failed to update business field: %w", err)
 	}
@@ -84,7 +119,7 @@ func (r *businessFieldPostgresRepository) Update(ctx context.Context, bf *domain
 
 func (r *businessFieldPostgresRepository) Delete(ctx context.Context, id string) error {
 	query := `DELETE FROM business_fields WHERE id = $1`
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := db.Conn(ctx, r.db).ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete business field: %w", err)
 	}
@@ -96,9 +131,51 @@ func (r *businessFieldPostgresRepository) Delete(ctx context.Context, id string)
 	return nil
 }
 
+func (r *businessFieldPostgresRepository) CountDatasets(ctx context.Context, id string) (int, error) {
+	query := `SELECT COUNT(*) FROM datasets WHERE business_field_id = $1`
+	var count int
+	if err := db.Conn(ctx, r.db).GetContext(ctx, &count, query, id); err != nil {
+		return 0, fmt.Errorf("failed to count business field datasets: %w", err)
+	}
+	return count, nil
+}
+
+func (r *businessFieldPostgresRepository) ReassignAndDelete(ctx context.Context, id, targetID string) error {
+	return db.WithinTx(ctx, r.db, func(ctx context.Context) error {
+		tx := db.Conn(ctx, r.db)
+
+		var exists bool
+		if err := tx.GetContext(ctx, &exists, `SELECT EXISTS (SELECT 1 FROM business_fields WHERE id = $1)`, targetID); err != nil {
+			return fmt.Errorf("failed to get business field: %w", err)
+		}
+		if !exists {
+			return fmt.Errorf("%w: business field %s to reassign datasets to does not exist", errors.ErrInvalidInput, targetID)
+		}
+
+		_, err := tx.ExecContext(ctx, `UPDATE datasets SET business_field_id = $2, updated_at = NOW() WHERE business_field_id = $1`, id, targetID)
+		if err != nil {
+			return fmt.Errorf("failed to reassign datasets: %w", err)
+		}
+
+		result, err := tx.ExecContext(ctx, `DELETE FROM business_fields WHERE id = $1`, id)
+		if err != nil {
+			return fmt.Errorf("failed to delete business field: %w", err)
+		}
+
+		rows, _ := result.RowsAffected()
+		if rows == 0 {
+			return errors.ErrNotFound
+		}
+		return nil
+	})
+}
+
 func (r *businessFieldPostgresRepository) handleError(err error) error {
 	if err == nil {
 		return nil
 	}
+	if err == sql.ErrNoRows {
+		return errors.ErrNotFound
+	}
 	return errors.Wrap(err, "database error")
 }
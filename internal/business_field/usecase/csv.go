@@ -0,0 +1,208 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"portal-data-backend/internal/business_field/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+	"portal-data-backend/pkg/i18n"
+
+	"github.com/google/uuid"
+)
+
+// csvColumns are the columns of a business field export. One name_<lang>
+// column follows per language with a localized name.
+var csvColumns = []string{"id", "name", "slug", "display_order", "is_featured", "icon_url"}
+
+const namePrefix = "name_"
+
+func (u *businessFieldUsecase) Export(ctx context.Context) ([]byte, error) {
+	bfs, err := u.bfRepo.ListAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export business fields: %w", err)
+	}
+
+	responses := make([]*domain.BusinessFieldResponse, len(bfs))
+	languages := map[string]bool{}
+	for i, bf := range bfs {
+		responses[i] = u.toResponse(bf, nil)
+		for lang := range responses[i].Names {
+			languages[lang] = true
+		}
+	}
+	langs := make([]string, 0, len(languages))
+	for lang := range languages {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	header := append([]string{}, csvColumns...)
+	for _, lang := range langs {
+		header = append(header, namePrefix+lang)
+	}
+	writer.Write(header)
+
+	for _, bf := range responses {
+		iconURL := ""
+		if bf.IconURL != nil {
+			iconURL = *bf.IconURL
+		}
+		row := []string{bf.ID, bf.Name, bf.Slug, strconv.Itoa(bf.DisplayOrder), strconv.FormatBool(bf.IsFeatured), iconURL}
+		for _, lang := range langs {
+			row = append(row, bf.Names[lang])
+		}
+		writer.Write(row)
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("failed to export business fields: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (u *businessFieldUsecase) Import(ctx context.Context, reader io.Reader) (*domain.ImportResult, error) {
+	rows, err := readCSV(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	bfs, err := u.bfRepo.ListAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to import business fields: %w", err)
+	}
+	bySlug := make(map[string]*domain.BusinessField, len(bfs))
+	for _, bf := range bfs {
+		bySlug[bf.Slug] = bf
+	}
+
+	result := &domain.ImportResult{}
+	seen := map[string]int{}
+	for i, row := range rows {
+		line := i + 2
+		skip := func(format string, args ...interface{}) {
+			result.Skipped++
+			result.Errors = append(result.Errors, domain.ImportError{Row: line, Message: fmt.Sprintf(format, args...)})
+		}
+
+		name := strings.TrimSpace(row["name"])
+		if len([]rune(name)) < 2 {
+			skip("name must be at least 2 characters")
+			continue
+		}
+		slug := strings.TrimSpace(row["slug"])
+		if slug == "" {
+			slug = u.generateSlug(name)
+		}
+		if first, ok := seen[slug]; ok {
+			skip("slug %s already appears on row %d", slug, first)
+			continue
+		}
+		seen[slug] = line
+
+		bf, exists := bySlug[slug]
+		if !exists {
+			bf = &domain.BusinessField{ID: uuid.New().String(), Names: "{}", CreatedAt: time.Now()}
+		}
+		bf.Name = name
+		bf.Slug = slug
+
+		if value, ok := row["display_order"]; ok && strings.TrimSpace(value) != "" {
+			order, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				skip("display_order %q is not a number", value)
+				continue
+			}
+			bf.DisplayOrder = order
+		}
+		if value, ok := row["is_featured"]; ok && strings.TrimSpace(value) != "" {
+			featured, err := strconv.ParseBool(strings.TrimSpace(value))
+			if err != nil {
+				skip("is_featured %q is not true or false", value)
+				continue
+			}
+			bf.IsFeatured = featured
+		}
+		if iconURL := strings.TrimSpace(row["icon_url"]); iconURL != "" {
+			bf.IconURL = &iconURL
+		}
+		if names := rowNames(row); len(names) > 0 {
+			if bf.Names, err = i18n.Encode(names); err != nil {
+				return nil, err
+			}
+		}
+
+		if exists {
+			if err := u.bfRepo.Update(ctx, bf); err != nil {
+				return nil, fmt.Errorf("failed to update business field %s: %w", slug, err)
+			}
+			result.Updated++
+			continue
+		}
+		if err := u.bfRepo.Create(ctx, bf); err != nil {
+			return nil, fmt.Errorf("failed to create business field %s: %w", slug, err)
+		}
+		bySlug[slug] = bf
+		result.Created++
+	}
+
+	return result, nil
+}
+
+// rowNames collects the localized names of the name_<lang> columns of a row
+func rowNames(row map[string]string) map[string]string {
+	names := map[string]string{}
+	for column, value := range row {
+		if lang := strings.TrimPrefix(column, namePrefix); lang != column && strings.TrimSpace(value) != "" {
+			names[lang] = strings.TrimSpace(value)
+		}
+	}
+	return names
+}
+
+// readCSV reads CSV rows keyed by their lower case column name. The first row
+// holds the column names and must include name.
+func readCSV(reader io.Reader) ([]map[string]string, error) {
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV: %w", err)
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(body, []byte("\xef\xbb\xbf")))).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", pkgErrors.ErrInvalidInput, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: CSV is empty", pkgErrors.ErrInvalidInput)
+	}
+
+	header := records[0]
+	hasName := false
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(header[i]))
+		hasName = hasName || header[i] == "name"
+	}
+	if !hasName {
+		return nil, fmt.Errorf("%w: CSV has no name column", pkgErrors.ErrInvalidInput)
+	}
+
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(header))
+		for i, column := range header {
+			row[column] = record[i]
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
@@ -2,15 +2,39 @@ package usecase
 
 import (
 	"context"
+	"io"
 
 	"portal-data-backend/internal/business_field/domain"
+	fileDomain "portal-data-backend/internal/file/domain"
 )
 
+// BusinessFieldReader is the part of the business field module other modules
+// read business fields through
+type BusinessFieldReader interface {
+	// GetByIDs loads the business fields with ids in one query, skipping unknown
+	// ones
+	GetByIDs(ctx context.Context, ids []string) ([]*domain.BusinessFieldResponse, error)
+}
+
 // Usecase defines the interface for business field business logic
 type Usecase interface {
+	BusinessFieldReader
 	GetByID(ctx context.Context, id string) (*domain.BusinessFieldResponse, error)
 	List(ctx context.Context, req *domain.ListBusinessFieldsRequest) (*domain.BusinessFieldListResponse, error)
 	Create(ctx context.Context, req *domain.CreateBusinessFieldRequest) (*domain.BusinessFieldResponse, error)
 	Update(ctx context.Context, id string, req *domain.UpdateBusinessFieldRequest) (*domain.BusinessFieldResponse, error)
-	Delete(ctx context.Context, id string) error
+	// Delete refuses to delete a business field datasets still reference unless
+	// reassignTo names the business field to move them to
+	Delete(ctx context.Context, id string, reassignTo *string) error
+	// UploadIcon stores an image through the file module and sets it as the icon of a business field
+	UploadIcon(ctx context.Context, id string, fileName string, fileSize int64, mimeType string, reader io.Reader, userID string) (*domain.BusinessFieldResponse, error)
+	// Export writes every business field as CSV
+	Export(ctx context.Context) ([]byte, error)
+	// Import creates or, matched on slug, updates business fields from CSV
+	Import(ctx context.Context, reader io.Reader) (*domain.ImportResult, error)
+}
+
+// IconUploader is the part of the file module business field icons are stored through
+type IconUploader interface {
+	Upload(ctx context.Context, fileName string, fileSize int64, mimeType string, reader io.Reader, datasetID *string, userID string) (*fileDomain.UploadResponse, error)
 }
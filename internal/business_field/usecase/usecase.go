@@ -3,21 +3,39 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"io"
 	"math"
 	"strings"
 	"time"
 
+	"portal-data-backend/infrastructure/audit"
 	"portal-data-backend/internal/business_field/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+	"portal-data-backend/pkg/i18n"
 
 	"github.com/google/uuid"
 )
 
+// maxIconSize is the largest icon image accepted, in bytes
+const maxIconSize = 2 << 20
+
+// iconTypes lists the image types accepted as icons
+var iconTypes = map[string]bool{
+	"image/png":     true,
+	"image/jpeg":    true,
+	"image/webp":    true,
+	"image/svg+xml": true,
+}
+
 type businessFieldUsecase struct {
 	bfRepo domain.Repository
+	icons  IconUploader
+	audit  *audit.Recorder
 }
 
-func NewBusinessFieldUsecase(bfRepo domain.Repository) Usecase {
-	return &businessFieldUsecase{bfRepo: bfRepo}
+// NewBusinessFieldUsecase creates the business field usecase. recorder may be nil.
+func NewBusinessFieldUsecase(bfRepo domain.Repository, icons IconUploader, recorder *audit.Recorder) Usecase {
+	return &businessFieldUsecase{bfRepo: bfRepo, icons: icons, audit: recorder}
 }
 
 func (u *businessFieldUsecase) GetByID(ctx context.Context, id string) (*domain.BusinessFieldResponse, error) {
@@ -25,7 +43,21 @@ func (u *businessFieldUsecase) GetByID(ctx context.Context, id string) (*domain.
 	if err != nil {
 		return nil, fmt.Errorf("failed to get business field: %w", err)
 	}
-	return u.toResponse(bf), nil
+	return u.toResponse(bf, i18n.Languages(ctx)), nil
+}
+
+func (u *businessFieldUsecase) GetByIDs(ctx context.Context, ids []string) ([]*domain.BusinessFieldResponse, error) {
+	bfs, err := u.bfRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get business fields: %w", err)
+	}
+
+	langs := i18n.Languages(ctx)
+	responses := make([]*domain.BusinessFieldResponse, len(bfs))
+	for i, bf := range bfs {
+		responses[i] = u.toResponse(bf, langs)
+	}
+	return responses, nil
 }
 
 func (u *businessFieldUsecase) List(ctx context.Context, req *domain.ListBusinessFieldsRequest) (*domain.BusinessFieldListResponse, error) {
@@ -38,14 +70,15 @@ func (u *businessFieldUsecase) List(ctx context.Context, req *domain.ListBusines
 
 	offset := (req.Page - 1) * req.Limit
 
-	bfs, total, err := u.bfRepo.List(ctx, req.Search, req.Limit, offset)
+	filter := &domain.BusinessFieldFilter{Search: req.Search, Featured: req.Featured}
+	bfs, total, err := u.bfRepo.List(ctx, filter, req.Limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list business fields: %w", err)
 	}
 
 	responses := make([]domain.BusinessFieldResponse, len(bfs))
 	for i, bf := range bfs {
-		responses[i] = *u.toResponse(bf)
+		responses[i] = *u.toResponse(bf, i18n.Languages(ctx))
 	}
 
 	totalPage := int(math.Ceil(float64(total) / float64(req.Limit)))
@@ -62,18 +95,27 @@ func (u *businessFieldUsecase) List(ctx context.Context, req *domain.ListBusines
 }
 
 func (u *businessFieldUsecase) Create(ctx context.Context, req *domain.CreateBusinessFieldRequest) (*domain.BusinessFieldResponse, error) {
+	names, err := i18n.Encode(req.Names)
+	if err != nil {
+		return nil, err
+	}
+
 	bf := &domain.BusinessField{
-		ID:        uuid.New().String(),
-		Name:      req.Name,
-		Slug:      u.generateSlug(req.Name),
-		CreatedAt: time.Now(),
+		ID:           uuid.New().String(),
+		Name:         req.Name,
+		Slug:         u.generateSlug(req.Name),
+		Names:        names,
+		DisplayOrder: req.DisplayOrder,
+		IsFeatured:   req.IsFeatured,
+		CreatedAt:    time.Now(),
 	}
 
 	if err := u.bfRepo.Create(ctx, bf); err != nil {
 		return nil, fmt.Errorf("failed to create business field: %w", err)
 	}
+	u.audit.Record(ctx, "business-fields", bf.ID, audit.ActionCreate, nil, bf)
 
-	return u.toResponse(bf), nil
+	return u.toResponse(bf, nil), nil
 }
 
 func (u *businessFieldUsecase) Update(ctx context.Context, id string, req *domain.UpdateBusinessFieldRequest) (*domain.BusinessFieldResponse, error) {
@@ -81,30 +123,104 @@ func (u *businessFieldUsecase) Update(ctx context.Context, id string, req *domai
 	if err != nil {
 		return nil, fmt.Errorf("failed to get business field: %w", err)
 	}
+	before := *bf
 
 	bf.Name = req.Name
 	bf.Slug = u.generateSlug(req.Name)
+	if req.Names != nil {
+		if bf.Names, err = i18n.Encode(req.Names); err != nil {
+			return nil, err
+		}
+	}
+	if req.DisplayOrder != nil {
+		bf.DisplayOrder = *req.DisplayOrder
+	}
+	if req.IsFeatured != nil {
+		bf.IsFeatured = *req.IsFeatured
+	}
 
 	if err := u.bfRepo.Update(ctx, bf); err != nil {
 		return nil, fmt.Errorf("failed to update business field: %w", err)
 	}
+	u.audit.Record(ctx, "business-fields", bf.ID, audit.ActionUpdate, &before, bf)
 
-	return u.toResponse(bf), nil
+	return u.toResponse(bf, nil), nil
 }
 
-func (u *businessFieldUsecase) Delete(ctx context.Context, id string) error {
+func (u *businessFieldUsecase) Delete(ctx context.Context, id string, reassignTo *string) error {
+	bf, err := u.bfRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get business field: %w", err)
+	}
+
+	if reassignTo != nil {
+		if *reassignTo == id {
+			return fmt.Errorf("%w: cannot reassign datasets to the business field being deleted", pkgErrors.ErrInvalidInput)
+		}
+		if err := u.bfRepo.ReassignAndDelete(ctx, id, *reassignTo); err != nil {
+			return fmt.Errorf("failed to delete business field: %w", err)
+		}
+		u.audit.Record(ctx, "business-fields", id, audit.ActionDelete, bf, nil)
+		return nil
+	}
+
+	count, err := u.bfRepo.CountDatasets(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete business field: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("%w: business field is used by %d datasets, pass reassign_to to move them to another business field", pkgErrors.ErrInUse, count)
+	}
+
 	if err := u.bfRepo.Delete(ctx, id); err != nil {
 		return fmt.Errorf("failed to delete business field: %w", err)
 	}
+	u.audit.Record(ctx, "business-fields", id, audit.ActionDelete, bf, nil)
 	return nil
 }
 
-func (u *businessFieldUsecase) toResponse(bf *domain.BusinessField) *domain.BusinessFieldResponse {
+func (u *businessFieldUsecase) UploadIcon(ctx context.Context, id string, fileName string, fileSize int64, mimeType string, reader io.Reader, userID string) (*domain.BusinessFieldResponse, error) {
+	if !iconTypes[mimeType] {
+		return nil, fmt.Errorf("%w: icon must be a PNG, JPEG, WebP or SVG image", pkgErrors.ErrInvalidInput)
+	}
+	if fileSize > maxIconSize {
+		return nil, fmt.Errorf("%w: icon must be at most %d KB", pkgErrors.ErrInvalidInput, maxIconSize>>10)
+	}
+
+	bf, err := u.bfRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get business field: %w", err)
+	}
+	before := *bf
+
+	file, err := u.icons.Upload(ctx, fileName, fileSize, mimeType, reader, nil, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload business field icon: %w", err)
+	}
+
+	bf.IconURL = &file.Path
+	if err := u.bfRepo.Update(ctx, bf); err != nil {
+		return nil, fmt.Errorf("failed to update business field: %w", err)
+	}
+	u.audit.Record(ctx, "business-fields", bf.ID, audit.ActionUpdate, &before, bf)
+
+	return u.toResponse(bf, nil), nil
+}
+
+// toResponse converts a business field, naming it in the first of langs it has a name in
+func (u *businessFieldUsecase) toResponse(bf *domain.BusinessField, langs []string) *domain.BusinessFieldResponse {
+	names := i18n.Decode(bf.Names)
+	name := i18n.Pick(names, langs, bf.Name)
+
 	return &domain.BusinessFieldResponse{
-		ID:        bf.ID,
-		Name:      bf.Name,
-		Slug:      bf.Slug,
-		CreatedAt: bf.CreatedAt,
+		ID:           bf.ID,
+		Name:         name,
+		Slug:         bf.Slug,
+		Names:        names,
+		IconURL:      bf.IconURL,
+		DisplayOrder: bf.DisplayOrder,
+		IsFeatured:   bf.IsFeatured,
+		CreatedAt:    bf.CreatedAt,
 	}
 }
 
@@ -0,0 +1,55 @@
+package graphql
+
+import (
+	"context"
+
+	"portal-data-backend/infrastructure/graphql"
+	"portal-data-backend/internal/app"
+	datasetDomain "portal-data-backend/internal/dataset/domain"
+	orgDomain "portal-data-backend/internal/organization/domain"
+	tagDomain "portal-data-backend/internal/tag/domain"
+)
+
+// loaders batch the lookups of the records a query references, so listing
+// datasets with their organizations takes one query for the organizations
+// rather than one per dataset
+type loaders struct {
+	organizations *graphql.Loader[string, *orgDomain.OrganizationResponse]
+	datasets      *graphql.Loader[string, *datasetDomain.DatasetResponse]
+	tags          *graphql.Loader[string, []tagDomain.TagResponse]
+}
+
+type loadersKey struct{}
+
+// WithLoaders returns a context holding the loaders of one request
+func WithLoaders(ctx context.Context, services *app.Services) context.Context {
+	return context.WithValue(ctx, loadersKey{}, &loaders{
+		organizations: graphql.NewLoader(func(ctx context.Context, ids []string) (map[string]*orgDomain.OrganizationResponse, error) {
+			orgs, err := services.Organizations.GetByIDs(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			byID := make(map[string]*orgDomain.OrganizationResponse, len(orgs))
+			for _, org := range orgs {
+				byID[org.ID] = org
+			}
+			return byID, nil
+		}),
+		datasets: graphql.NewLoader(func(ctx context.Context, ids []string) (map[string]*datasetDomain.DatasetResponse, error) {
+			datasets, err := services.Datasets.GetByIDs(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			byID := make(map[string]*datasetDomain.DatasetResponse, len(datasets))
+			for _, dataset := range datasets {
+				byID[dataset.ID] = dataset
+			}
+			return byID, nil
+		}),
+		tags: graphql.NewLoader(services.Datasets.TagsByDatasetIDs),
+	})
+}
+
+func loadersOf(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
@@ -0,0 +1,352 @@
+// Package graphql describes the public catalog as a GraphQL schema resolved
+// with the usecases of the modules owning its records
+package graphql
+
+import (
+	"fmt"
+
+	"portal-data-backend/infrastructure/graphql"
+	"portal-data-backend/internal/app"
+	datasetDomain "portal-data-backend/internal/dataset/domain"
+	orgDomain "portal-data-backend/internal/organization/domain"
+	pubDomain "portal-data-backend/internal/publication/domain"
+	vizDomain "portal-data-backend/internal/visualization/domain"
+	"portal-data-backend/pkg/errors"
+)
+
+// maxLimit bounds the page size of lists, like the REST endpoints do
+const maxLimit = 100
+
+// NewSchema creates the catalog schema. Requests must run with a context
+// from WithLoaders.
+func NewSchema(services *app.Services) *graphql.Schema {
+	pageMeta := &graphql.Object{Name: "PageMeta", Description: "A page of a list", Fields: []*graphql.Field{
+		{Name: "page", Type: graphql.Int},
+		{Name: "limit", Type: graphql.Int},
+		{Name: "total", Type: graphql.Int},
+		{Name: "totalPage", Type: graphql.Int},
+	}}
+	reference := func(name string) *graphql.Object {
+		return &graphql.Object{Name: name, Fields: []*graphql.Field{
+			{Name: "id", Type: graphql.ID},
+			{Name: "name", Type: graphql.String},
+			{Name: "slug", Type: graphql.String},
+		}}
+	}
+	unit := &graphql.Object{Name: "Unit", Fields: []*graphql.Field{
+		{Name: "id", Type: graphql.ID},
+		{Name: "name", Type: graphql.String},
+		{Name: "symbol", Type: graphql.String},
+	}}
+
+	organization := &graphql.Object{Name: "Organization", Description: "A government body publishing data", Fields: []*graphql.Field{
+		{Name: "id", Type: graphql.ID},
+		{Name: "code", Type: graphql.String},
+		{Name: "name", Type: graphql.String},
+		{Name: "slug", Type: graphql.String},
+		{Name: "description", Type: graphql.String},
+		{Name: "logoUrl", Type: graphql.String},
+		{Name: "websiteUrl", Type: graphql.String},
+		{Name: "email", Type: graphql.String},
+		{Name: "phoneNumber", Type: graphql.String},
+		{Name: "address", Type: graphql.String},
+		{Name: "totalDatasets", Type: graphql.Int},
+		{Name: "publicDatasets", Type: graphql.Int},
+		{Name: "status", Type: graphql.String},
+		{Name: "names", Type: graphql.JSON, Description: "Names by language code"},
+		{Name: "descriptions", Type: graphql.JSON, Description: "Descriptions by language code"},
+		{Name: "createdAt", Type: graphql.Time},
+		{Name: "updatedAt", Type: graphql.Time},
+	}}
+
+	dataset := &graphql.Object{Name: "Dataset", Description: "A published table of data", Fields: []*graphql.Field{
+		{Name: "id", Type: graphql.ID},
+		{Name: "name", Type: graphql.String},
+		{Name: "slug", Type: graphql.String},
+		{Name: "description", Type: graphql.String},
+		{Name: "coverageStart", Type: graphql.String, Description: "First date the data covers, like 2006-01-02"},
+		{Name: "coverageEnd", Type: graphql.String, Description: "Last date the data covers, like 2006-01-02"},
+		{Name: "frequency", Type: graphql.String, Description: "How often the data is collected"},
+		{Name: "image", Type: graphql.String},
+		{Name: "classification", Type: graphql.String},
+		{Name: "category", Type: graphql.String},
+		{Name: "dataFixed", Type: graphql.Boolean},
+		{Name: "validationStatus", Type: graphql.String},
+		{Name: "status", Type: graphql.String},
+		{Name: "isHighlight", Type: graphql.Boolean},
+		{Name: "names", Type: graphql.JSON, Description: "Names by language code"},
+		{Name: "descriptions", Type: graphql.JSON, Description: "Descriptions by language code"},
+		{Name: "createdAt", Type: graphql.Time},
+		{Name: "updatedAt", Type: graphql.Time},
+		{Name: "unit", Type: unit},
+		{Name: "topic", Type: reference("Topic")},
+		{Name: "businessField", Type: reference("BusinessField")},
+		{Name: "organization", Type: organization, Resolve: func(p graphql.Params) (interface{}, error) {
+			return loadersOf(p.Context).organizations.Load(p.Context, p.Source.(*datasetDomain.DatasetResponse).OrganizationID), nil
+		}},
+		{Name: "tags", Type: graphql.NewList(reference("Tag")), Resolve: func(p graphql.Params) (interface{}, error) {
+			return loadersOf(p.Context).tags.Load(p.Context, p.Source.(*datasetDomain.DatasetResponse).ID), nil
+		}},
+	}}
+
+	publication := &graphql.Object{Name: "Publication", Description: "A report based on datasets", Fields: []*graphql.Field{
+		{Name: "id", Type: graphql.ID},
+		{Name: "title", Type: graphql.String},
+		{Name: "description", Type: graphql.String},
+		{Name: "content", Type: graphql.String},
+		{Name: "doi", Type: graphql.String},
+		{Name: "publisher", Type: graphql.String},
+		{Name: "publishedDate", Type: graphql.Time},
+		{Name: "authors", Type: graphql.String},
+		{Name: "tags", Type: graphql.String},
+		{Name: "status", Type: graphql.String},
+		{Name: "isFeatured", Type: graphql.Boolean},
+		{Name: "viewCount", Type: graphql.Int},
+		{Name: "downloadCount", Type: graphql.Int},
+		{Name: "createdAt", Type: graphql.Time},
+		{Name: "updatedAt", Type: graphql.Time},
+		{Name: "dataset", Type: dataset, Resolve: func(p graphql.Params) (interface{}, error) {
+			return loadDataset(p, p.Source.(*pubDomain.PublicationInfo).DatasetID), nil
+		}},
+		{Name: "organization", Type: organization, Resolve: func(p graphql.Params) (interface{}, error) {
+			return loadOrganization(p, p.Source.(*pubDomain.PublicationInfo).OrganizationID), nil
+		}},
+	}}
+
+	visualization := &graphql.Object{Name: "Visualization", Description: "A chart or map of a dataset", Fields: []*graphql.Field{
+		{Name: "id", Type: graphql.ID},
+		{Name: "title", Type: graphql.String},
+		{Name: "description", Type: graphql.String},
+		{Name: "type", Type: graphql.String},
+		{Name: "config", Type: graphql.String, Description: "The chart configuration as JSON"},
+		{Name: "isHighlight", Type: graphql.Boolean},
+		{Name: "status", Type: graphql.String},
+		{Name: "createdAt", Type: graphql.Time},
+		{Name: "updatedAt", Type: graphql.Time},
+		{Name: "dataset", Type: dataset, Resolve: func(p graphql.Params) (interface{}, error) {
+			return loadDataset(p, p.Source.(*vizDomain.VisualizationInfo).DatasetID), nil
+		}},
+		{Name: "organization", Type: organization, Resolve: func(p graphql.Params) (interface{}, error) {
+			return loadOrganization(p, p.Source.(*vizDomain.VisualizationInfo).OrganizationID), nil
+		}},
+	}}
+
+	page := func(name string, item *graphql.Object) *graphql.Object {
+		return &graphql.Object{Name: name, Fields: []*graphql.Field{
+			{Name: "items", Type: graphql.NewList(item)},
+			{Name: "meta", Type: pageMeta},
+		}}
+	}
+	paging := func(args ...*graphql.Argument) []*graphql.Argument {
+		return append([]*graphql.Argument{
+			{Name: "page", Type: graphql.Int, Default: 1},
+			{Name: "limit", Type: graphql.Int, Default: 20, Description: fmt.Sprintf("At most %d", maxLimit)},
+		}, args...)
+	}
+	arg := func(name string, t graphql.Type) *graphql.Argument {
+		return &graphql.Argument{Name: name, Type: t}
+	}
+
+	query := &graphql.Object{Name: "Query", Fields: []*graphql.Field{
+		{
+			Name: "dataset", Type: dataset, Description: "A dataset by ID or slug",
+			Args: []*graphql.Argument{arg("id", graphql.ID), arg("slug", graphql.String)},
+			Resolve: func(p graphql.Params) (interface{}, error) {
+				switch {
+				case p.String("id") != "":
+					return services.Datasets.GetByID(p.Context, p.String("id"))
+				case p.String("slug") != "":
+					return services.Datasets.GetBySlug(p.Context, p.String("slug"))
+				}
+				return nil, fmt.Errorf("id or slug is required: %w", errors.ErrInvalidInput)
+			},
+		},
+		{
+			Name: "datasets", Type: page("DatasetPage", dataset),
+			Args: paging(arg("organizationId", graphql.ID), arg("topicId", graphql.ID), arg("businessFieldId", graphql.ID), arg("tagId", graphql.ID),
+				arg("status", graphql.String), arg("validationStatus", graphql.String), arg("classification", graphql.String),
+				arg("coverageFrom", graphql.String), arg("coverageTo", graphql.String), arg("frequency", graphql.String),
+				arg("search", graphql.String), arg("sortBy", graphql.String), arg("sortOrder", graphql.String)),
+			Resolve: func(p graphql.Params) (interface{}, error) {
+				number, limit := pageOf(p)
+				resp, err := services.Datasets.List(p.Context, &datasetDomain.ListDatasetsRequest{
+					Page:             number,
+					Limit:            limit,
+					OrganizationID:   p.String("organizationId"),
+					TopicID:          p.String("topicId"),
+					BusinessFieldID:  p.String("businessFieldId"),
+					TagID:            p.String("tagId"),
+					Status:           p.String("status"),
+					ValidationStatus: p.String("validationStatus"),
+					Classification:   p.String("classification"),
+					CoverageFrom:     p.String("coverageFrom"),
+					CoverageTo:       p.String("coverageTo"),
+					Frequency:        p.String("frequency"),
+					Search:           p.String("search"),
+					SortBy:           p.String("sortBy"),
+					SortOrder:        p.String("sortOrder"),
+				})
+				if err != nil {
+					return nil, err
+				}
+				return pageResult(resp.Datasets, resp.Meta.Page, resp.Meta.Limit, resp.Meta.Total, resp.Meta.TotalPage), nil
+			},
+		},
+		{
+			Name: "organization", Type: organization, Description: "An organization by ID, slug or code",
+			Args: []*graphql.Argument{arg("id", graphql.ID), arg("slug", graphql.String), arg("code", graphql.String)},
+			Resolve: func(p graphql.Params) (interface{}, error) {
+				switch {
+				case p.String("id") != "":
+					return services.Organizations.GetByID(p.Context, p.String("id"))
+				case p.String("slug") != "":
+					return services.Organizations.GetBySlug(p.Context, p.String("slug"))
+				case p.String("code") != "":
+					return services.Organizations.GetByCode(p.Context, p.String("code"))
+				}
+				return nil, fmt.Errorf("id, slug or code is required: %w", errors.ErrInvalidInput)
+			},
+		},
+		{
+			Name: "organizations", Type: page("OrganizationPage", organization),
+			Args: paging(arg("status", graphql.String), arg("search", graphql.String), arg("sortBy", graphql.String), arg("sortOrder", graphql.String)),
+			Resolve: func(p graphql.Params) (interface{}, error) {
+				number, limit := pageOf(p)
+				resp, err := services.Organizations.List(p.Context, &orgDomain.ListOrganizationsRequest{
+					Page:      number,
+					Limit:     limit,
+					Status:    p.String("status"),
+					Search:    p.String("search"),
+					SortBy:    p.String("sortBy"),
+					SortOrder: p.String("sortOrder"),
+				})
+				if err != nil {
+					return nil, err
+				}
+				return pageResult(resp.Organizations, resp.Meta.Page, resp.Meta.Limit, resp.Meta.Total, resp.Meta.TotalPage), nil
+			},
+		},
+		{
+			Name: "publication", Type: publication,
+			Args: []*graphql.Argument{arg("id", graphql.ID)},
+			Resolve: func(p graphql.Params) (interface{}, error) {
+				if p.String("id") == "" {
+					return nil, fmt.Errorf("id is required: %w", errors.ErrInvalidInput)
+				}
+				return services.Publications.GetByID(p.Context, p.String("id"))
+			},
+		},
+		{
+			Name: "publications", Type: page("PublicationPage", publication),
+			Args: paging(arg("datasetId", graphql.ID), arg("organizationId", graphql.ID), arg("status", graphql.String),
+				arg("isFeatured", graphql.Boolean), arg("search", graphql.String)),
+			Resolve: func(p graphql.Params) (interface{}, error) {
+				number, limit := pageOf(p)
+				resp, err := services.Publications.List(p.Context, &pubDomain.ListPublicationsRequest{
+					Page:           number,
+					Limit:          limit,
+					DatasetID:      optional(p.String("datasetId")),
+					OrganizationID: optional(p.String("organizationId")),
+					Status:         optional(p.String("status")),
+					IsFeatured:     optionalBool(p, "isFeatured"),
+					Search:         p.String("search"),
+				})
+				if err != nil {
+					return nil, err
+				}
+				return pageResult(resp.Publications, resp.Meta.Page, resp.Meta.Limit, resp.Meta.Total, resp.Meta.TotalPage), nil
+			},
+		},
+		{
+			Name: "visualization", Type: visualization,
+			Args: []*graphql.Argument{arg("id", graphql.ID)},
+			Resolve: func(p graphql.Params) (interface{}, error) {
+				if p.String("id") == "" {
+					return nil, fmt.Errorf("id is required: %w", errors.ErrInvalidInput)
+				}
+				return services.Visualizations.GetByID(p.Context, p.String("id"))
+			},
+		},
+		{
+			Name: "visualizations", Type: page("VisualizationPage", visualization),
+			Args: paging(arg("datasetId", graphql.ID), arg("organizationId", graphql.ID), arg("topicId", graphql.ID), arg("type", graphql.String),
+				arg("status", graphql.String), arg("isHighlight", graphql.Boolean), arg("search", graphql.String)),
+			Resolve: func(p graphql.Params) (interface{}, error) {
+				number, limit := pageOf(p)
+				resp, err := services.Visualizations.List(p.Context, &vizDomain.ListVisualizationsRequest{
+					Page:           number,
+					Limit:          limit,
+					DatasetID:      optional(p.String("datasetId")),
+					OrganizationID: optional(p.String("organizationId")),
+					TopicID:        optional(p.String("topicId")),
+					Type:           optional(p.String("type")),
+					Status:         optional(p.String("status")),
+					IsHighlight:    optionalBool(p, "isHighlight"),
+					Search:         p.String("search"),
+				})
+				if err != nil {
+					return nil, err
+				}
+				return pageResult(resp.Visualizations, resp.Meta.Page, resp.Meta.Limit, resp.Meta.Total, resp.Meta.TotalPage), nil
+			},
+		},
+	}}
+
+	return &graphql.Schema{Query: query, MaxDepth: 8}
+}
+
+// pageOf returns the page and limit arguments, bounding the limit
+func pageOf(p graphql.Params) (int, int) {
+	number, limit := p.Int("page"), p.Int("limit")
+	if number < 1 {
+		number = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+	return number, limit
+}
+
+func pageResult(items interface{}, page, limit, total, totalPage int) map[string]interface{} {
+	return map[string]interface{}{
+		"items": items,
+		"meta": map[string]interface{}{
+			"page":      page,
+			"limit":     limit,
+			"total":     total,
+			"totalPage": totalPage,
+		},
+	}
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func optionalBool(p graphql.Params, name string) *bool {
+	b, ok := p.Args[name].(bool)
+	if !ok {
+		return nil
+	}
+	return &b
+}
+
+func loadDataset(p graphql.Params, id *string) interface{} {
+	if id == nil {
+		return nil
+	}
+	return loadersOf(p.Context).datasets.Load(p.Context, *id)
+}
+
+func loadOrganization(p graphql.Params, id *string) interface{} {
+	if id == nil {
+		return nil
+	}
+	return loadersOf(p.Context).organizations.Load(p.Context, *id)
+}
@@ -0,0 +1,73 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"portal-data-backend/infrastructure/graphql"
+	"portal-data-backend/infrastructure/http/httputil"
+	"portal-data-backend/infrastructure/http/problem"
+	"portal-data-backend/infrastructure/logger"
+	"portal-data-backend/internal/app"
+	catalog "portal-data-backend/internal/catalog/delivery/graphql"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Handler serves the catalog GraphQL endpoint
+type Handler struct {
+	schema   *graphql.Schema
+	services *app.Services
+}
+
+// NewHandler creates a handler for schema, whose loaders use services
+func NewHandler(schema *graphql.Schema, services *app.Services) *Handler {
+	return &Handler{
+		schema:   schema,
+		services: services,
+	}
+}
+
+// Query runs a GraphQL request. Like other GraphQL servers, it answers 200
+// with the errors in the body once the request is decoded.
+func (h *Handler) Query(w http.ResponseWriter, r *http.Request) {
+	req, ok := httputil.Decode[graphql.Request](w, r)
+	if !ok {
+		return
+	}
+
+	resp := h.schema.Execute(catalog.WithLoaders(r.Context(), h.services), req, presentError)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logger.FromContext(r.Context()).Error("failed to write GraphQL response: %v", err)
+	}
+}
+
+// Schema sends the schema in the GraphQL schema definition language
+func (h *Handler) Schema(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte(h.schema.SDL()))
+}
+
+// presentError sends the message of errors the REST endpoints would report
+// to the client, and hides the others as they do
+func presentError(ctx context.Context, err error) string {
+	mapping, ok := problem.Default.Lookup(err)
+	if !ok || mapping.Status >= http.StatusInternalServerError {
+		logger.FromContext(ctx).Error("GraphQL field failed: %v", err)
+		if !ok {
+			return "Internal server error"
+		}
+	}
+	if mapping.Message != "" {
+		return mapping.Message
+	}
+	return err.Error()
+}
+
+// RegisterRoutes registers the GraphQL routes, which are public
+func RegisterRoutes(r chi.Router, handler *Handler) {
+	r.Post("/graphql", handler.Query)
+	r.Get("/graphql/schema", handler.Schema)
+}
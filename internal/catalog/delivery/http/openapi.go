@@ -0,0 +1,15 @@
+package http
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/graphql"
+	"portal-data-backend/infrastructure/http/openapi"
+)
+
+// Describe adds the GraphQL routes to the API specification
+func Describe(spec *openapi.Spec) {
+	api := spec.Tag("graphql", "GraphQL queries over the public catalog")
+	api.Post("/graphql", "Run a GraphQL query").Public().Body(graphql.Request{}).ReturnsBody(http.StatusOK, graphql.Response{})
+	api.Get("/graphql/schema", "Get the GraphQL schema").Public().ReturnsFile(http.StatusOK, "text/plain")
+}
@@ -0,0 +1,53 @@
+// Package catalog is the module serving the public catalog over GraphQL.
+package catalog
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	"portal-data-backend/internal/app"
+	"portal-data-backend/internal/catalog/delivery/graphql"
+	delivery "portal-data-backend/internal/catalog/delivery/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Module serves datasets, organizations, publications and visualizations
+// with the usecases of their modules
+type Module struct {
+	handler *delivery.Handler
+}
+
+// Name implements app.Module
+func (m *Module) Name() string {
+	return "catalog"
+}
+
+// Register implements app.Module
+func (m *Module) Register(deps *app.Deps) error {
+	if deps.Services.Datasets == nil {
+		return app.MissingServiceError("dataset")
+	}
+	if deps.Services.Organizations == nil {
+		return app.MissingServiceError("organization")
+	}
+	if deps.Services.Publications == nil {
+		return app.MissingServiceError("publication")
+	}
+	if deps.Services.Visualizations == nil {
+		return app.MissingServiceError("visualization")
+	}
+
+	m.handler = delivery.NewHandler(graphql.NewSchema(&deps.Services), &deps.Services)
+	return nil
+}
+
+// Routes implements app.Module
+func (m *Module) Routes(r chi.Router, auth func(http.Handler) http.Handler) {
+	delivery.RegisterRoutes(r, m.handler)
+}
+
+// Describe implements app.Module
+func (m *Module) Describe(spec *openapi.Spec) {
+	delivery.Describe(spec)
+}
@@ -0,0 +1,108 @@
+// Package grpc serves data rows to internal services over gRPC
+package grpc
+
+import (
+	"context"
+
+	portalv1 "portal-data-backend/api/proto/portal/v1"
+	"portal-data-backend/infrastructure/auth"
+	"portal-data-backend/infrastructure/grpcserver"
+	"portal-data-backend/internal/data_row/domain"
+	"portal-data-backend/internal/data_row/usecase"
+	datasetDomain "portal-data-backend/internal/dataset/domain"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Server implements portalv1.DataRowServiceServer
+type Server struct {
+	portalv1.UnimplementedDataRowServiceServer
+	dataRows   usecase.Usecase
+	datasets   datasetDomain.Authorizer
+	adminRoles []string
+}
+
+// NewServer creates a data row gRPC server. Appending rows requires the edit
+// role on the dataset, which users with one of adminRoles have on every
+// dataset.
+func NewServer(dataRows usecase.Usecase, datasets datasetDomain.Authorizer, adminRoles []string) *Server {
+	return &Server{dataRows: dataRows, datasets: datasets, adminRoles: adminRoles}
+}
+
+// ListDataRows returns a page of the rows of a dataset
+func (s *Server) ListDataRows(ctx context.Context, req *portalv1.ListDataRowsRequest) (*portalv1.ListDataRowsResponse, error) {
+	if req.GetDatasetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "dataset_id is required")
+	}
+	if req.GetLimit() > 1000 {
+		return nil, status.Error(codes.InvalidArgument, "limit must be at most 1000")
+	}
+
+	resp, err := s.dataRows.List(ctx, &domain.ListDataRowsRequest{
+		Page:      int(req.GetPage()),
+		Limit:     int(req.GetLimit()),
+		DatasetID: req.GetDatasetId(),
+		Search:    req.GetSearch(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]*portalv1.DataRow, len(resp.Rows))
+	for i, row := range resp.Rows {
+		rows[i] = &portalv1.DataRow{
+			Id:        row.ID,
+			DatasetId: row.DatasetID,
+			RowIndex:  int32(row.RowIndex),
+			Data:      row.Data,
+			CreatedBy: row.CreatedBy,
+			CreatedAt: timestamppb.New(row.CreatedAt),
+			UpdatedAt: timestamppb.New(row.UpdatedAt),
+		}
+	}
+	return &portalv1.ListDataRowsResponse{
+		Rows: rows,
+		Meta: &portalv1.PageMeta{
+			Page:      int32(resp.Meta.Page),
+			Limit:     int32(resp.Meta.Limit),
+			Total:     int32(resp.Meta.Total),
+			TotalPage: int32(resp.Meta.TotalPage),
+		},
+	}, nil
+}
+
+// BulkCreateDataRows appends rows to a dataset as the user of the call, who
+// needs the edit role on it
+func (s *Server) BulkCreateDataRows(ctx context.Context, req *portalv1.BulkCreateDataRowsRequest) (*portalv1.BulkCreateDataRowsResponse, error) {
+	userID, err := grpcserver.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetDatasetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "dataset_id is required")
+	}
+	if len(req.GetRows()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "rows must not be empty")
+	}
+
+	rows := make([]domain.DataRowDataInput, len(req.GetRows()))
+	for i, row := range req.GetRows() {
+		if row.GetRowIndex() < 0 || row.GetData() == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "row %d needs a row_index of at least 0 and data", i)
+		}
+		rows[i] = domain.DataRowDataInput{RowIndex: int(row.GetRowIndex()), Data: row.GetData()}
+	}
+
+	user := auth.FromContext(ctx)
+	caller := datasetDomain.Caller{UserID: user.UserID, OrganizationID: user.OrganizationID, Admin: user.HasRole(s.adminRoles...)}
+	if err := s.datasets.Authorize(ctx, req.GetDatasetId(), caller, datasetDomain.CollaboratorEdit); err != nil {
+		return nil, err
+	}
+
+	if err := s.dataRows.BulkCreate(ctx, &domain.BulkCreateDataRowsRequest{DatasetID: req.GetDatasetId(), Rows: rows}, userID); err != nil {
+		return nil, err
+	}
+	return &portalv1.BulkCreateDataRowsResponse{Created: int32(len(rows))}, nil
+}
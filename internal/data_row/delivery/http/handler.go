@@ -1,42 +1,49 @@
 package http
 
 import (
-	"encoding/json"
-	"errors"
 	"net/http"
 	"strconv"
 
+	"portal-data-backend/infrastructure/auth"
 	dataRowDomain "portal-data-backend/internal/data_row/domain"
 	"portal-data-backend/internal/data_row/usecase"
+	datasetDomain "portal-data-backend/internal/dataset/domain"
+	"portal-data-backend/infrastructure/http/httputil"
+	"portal-data-backend/infrastructure/http/middleware"
+	"portal-data-backend/infrastructure/http/problem"
 	"portal-data-backend/infrastructure/http/response"
+	roleDomain "portal-data-backend/internal/role/domain"
 	pkgErrors "portal-data-backend/pkg/errors"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/go-playground/validator/v10"
 )
 
 type Handler struct {
 	dataRowUsecase usecase.Usecase
-	validator       *validator.Validate
+	datasets       datasetDomain.Authorizer
+	adminRoles     []string
 }
 
-func NewHandler(dataRowUsecase usecase.Usecase) *Handler {
+// NewHandler creates a data row handler. Changing the rows of a dataset
+// requires the edit role on it, which users with one of adminRoles have on
+// every dataset.
+func NewHandler(dataRowUsecase usecase.Usecase, datasets datasetDomain.Authorizer, adminRoles []string) *Handler {
 	return &Handler{
 		dataRowUsecase: dataRowUsecase,
-		validator:       validator.New(),
+		datasets:       datasets,
+		adminRoles:     adminRoles,
 	}
 }
 
 func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Data row ID is required", nil)
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
 	row, err := h.dataRowUsecase.GetByID(r.Context(), id)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -44,9 +51,8 @@ func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
-	datasetID := chi.URLParam(r, "datasetId")
-	if datasetID == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Dataset ID is required", nil)
+	datasetID, ok := httputil.UUIDParam(w, r, "datasetId")
+	if !ok {
 		return
 	}
 
@@ -59,7 +65,7 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 
 	resp, err := h.dataRowUsecase.List(r.Context(), req)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -67,22 +73,25 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
-	var req dataRowDomain.CreateDataRowRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, response.CodeBadRequest, "Invalid request body", nil)
+	datasetID, ok := httputil.UUIDParam(w, r, "datasetId")
+	if !ok {
 		return
 	}
 
-	if err := h.validator.Struct(&req); err != nil {
-		response.ValidationError(w, response.CodeValidationFailed, "Validation failed", h.formatValidationErrors(err))
+	req, ok := httputil.Decode[dataRowDomain.CreateDataRowRequest](w, r)
+	if !ok {
+		return
+	}
+	if req.DatasetID != datasetID {
+		response.BadRequest(w, response.CodeBadRequest, "dataset_id must be the dataset of the path", nil)
 		return
 	}
 
-	userID, _ := r.Context().Value("user_id").(string)
+	userID := auth.UserID(r.Context())
 
-	row, err := h.dataRowUsecase.Create(r.Context(), &req, userID)
+	row, err := h.dataRowUsecase.Create(r.Context(), req, userID)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -90,21 +99,24 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) BulkCreate(w http.ResponseWriter, r *http.Request) {
-	var req dataRowDomain.BulkCreateDataRowsRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, response.CodeBadRequest, "Invalid request body", nil)
+	datasetID, ok := httputil.UUIDParam(w, r, "datasetId")
+	if !ok {
 		return
 	}
 
-	if err := h.validator.Struct(&req); err != nil {
-		response.ValidationError(w, response.CodeValidationFailed, "Validation failed", h.formatValidationErrors(err))
+	req, ok := httputil.Decode[dataRowDomain.BulkCreateDataRowsRequest](w, r)
+	if !ok {
+		return
+	}
+	if req.DatasetID != datasetID {
+		response.BadRequest(w, response.CodeBadRequest, "dataset_id must be the dataset of the path", nil)
 		return
 	}
 
-	userID, _ := r.Context().Value("user_id").(string)
+	userID := auth.UserID(r.Context())
 
-	if err := h.dataRowUsecase.BulkCreate(r.Context(), &req, userID); err != nil {
-		h.handleError(w, err)
+	if err := h.dataRowUsecase.BulkCreate(r.Context(), req, userID); err != nil {
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -112,26 +124,23 @@ func (h *Handler) BulkCreate(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Data row ID is required", nil)
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
-	var req dataRowDomain.UpdateDataRowRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, response.CodeBadRequest, "Invalid request body", nil)
+	req, ok := httputil.Decode[dataRowDomain.UpdateDataRowRequest](w, r)
+	if !ok {
 		return
 	}
 
-	if err := h.validator.Struct(&req); err != nil {
-		response.ValidationError(w, response.CodeValidationFailed, "Validation failed", h.formatValidationErrors(err))
+	if !h.authorizeRow(w, r, id) {
 		return
 	}
 
-	row, err := h.dataRowUsecase.Update(r.Context(), id, &req)
+	row, err := h.dataRowUsecase.Update(r.Context(), id, req)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -139,14 +148,17 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Data row ID is required", nil)
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if !h.authorizeRow(w, r, id) {
 		return
 	}
 
 	if err := h.dataRowUsecase.Delete(r.Context(), id); err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -154,14 +166,13 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) DeleteByDatasetID(w http.ResponseWriter, r *http.Request) {
-	datasetID := chi.URLParam(r, "datasetId")
-	if datasetID == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Dataset ID is required", nil)
+	datasetID, ok := httputil.UUIDParam(w, r, "datasetId")
+	if !ok {
 		return
 	}
 
 	if err := h.dataRowUsecase.DeleteByDatasetID(r.Context(), datasetID); err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -169,56 +180,101 @@ func (h *Handler) DeleteByDatasetID(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) GetStats(w http.ResponseWriter, r *http.Request) {
-	datasetID := chi.URLParam(r, "datasetId")
-	if datasetID == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Dataset ID is required", nil)
+	datasetID, ok := httputil.UUIDParam(w, r, "datasetId")
+	if !ok {
 		return
 	}
 
 	stats, err := h.dataRowUsecase.GetStats(r.Context(), datasetID)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
 	response.OK(w, response.CodeSuccess, "Data row stats retrieved successfully", stats)
 }
 
-func (h *Handler) handleError(w http.ResponseWriter, err error) {
-	if err == nil {
+func (h *Handler) GetMasks(w http.ResponseWriter, r *http.Request) {
+	datasetID, ok := httputil.UUIDParam(w, r, "datasetId")
+	if !ok {
 		return
 	}
 
-	switch {
-	case errors.Is(err, pkgErrors.ErrNotFound):
-		response.NotFound(w, response.CodeNotFound, "Data row not found", nil)
-	default:
-		response.InternalError(w, response.CodeInternalServerError, "Internal server error", nil)
+	masks, err := h.dataRowUsecase.GetMasks(r.Context(), datasetID)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
 	}
+
+	response.OK(w, response.CodeSuccess, "Column masks retrieved successfully", masks)
 }
 
-func (h *Handler) formatValidationErrors(err error) []response.ErrorDetail {
-	var details []response.ErrorDetail
-	if validationErrors, ok := err.(validator.ValidationErrors); ok {
-		for _, fieldErr := range validationErrors {
-			details = append(details, response.ErrorDetail{
-				Field:   fieldErr.Field(),
-				Message: h.getValidationErrorMessage(fieldErr),
-			})
-		}
+func (h *Handler) UpdateMasks(w http.ResponseWriter, r *http.Request) {
+	datasetID, ok := httputil.UUIDParam(w, r, "datasetId")
+	if !ok {
+		return
+	}
+
+	req, ok := httputil.Decode[dataRowDomain.UpdateColumnMasksRequest](w, r)
+	if !ok {
+		return
+	}
+
+	masks, err := h.dataRowUsecase.UpdateMasks(r.Context(), datasetID, req)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Column masks updated successfully", masks)
+}
+
+// errorMapper maps the errors of the data row module on top of the shared ones
+var errorMapper = problem.Default.With(
+	problem.Mapping{Err: pkgErrors.ErrNotFound, Status: http.StatusNotFound, Code: response.CodeNotFound, Message: "Data row not found"},
+)
+
+func (h *Handler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	errorMapper.Write(w, r, err)
+}
+
+func (h *Handler) caller(r *http.Request) datasetDomain.Caller {
+	user := auth.FromContext(r.Context())
+	return datasetDomain.Caller{
+		UserID:         user.UserID,
+		OrganizationID: user.OrganizationID,
+		Admin:          user.HasRole(h.adminRoles...),
 	}
-	return details
 }
 
-func (h *Handler) getValidationErrorMessage(fieldErr validator.FieldError) string {
-	switch fieldErr.Tag() {
-	case "required":
-		return fieldErr.Field() + " is required"
-	case "min":
-		return fieldErr.Field() + " must be at least " + fieldErr.Param() + " characters"
-	default:
-		return fieldErr.Field() + " is invalid"
+// requireEdit lets through the callers with the edit role on the dataset
+// {datasetId}. It must run after auth.
+func (h *Handler) requireEdit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		datasetID, ok := httputil.UUIDParam(w, r, "datasetId")
+		if !ok {
+			return
+		}
+		if err := h.datasets.Authorize(r.Context(), datasetID, h.caller(r), datasetDomain.CollaboratorEdit); err != nil {
+			h.handleError(w, r, err)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authorizeRow checks the caller has the edit role on the dataset of the row
+// id, writing the error and returning false when not
+func (h *Handler) authorizeRow(w http.ResponseWriter, r *http.Request, id string) bool {
+	row, err := h.dataRowUsecase.GetByID(r.Context(), id)
+	if err == nil {
+		err = h.datasets.Authorize(r.Context(), row.DatasetID, h.caller(r), datasetDomain.CollaboratorEdit)
+	}
+	if err != nil {
+		h.handleError(w, r, err)
+		return false
 	}
+	return true
 }
 
 func parseIntQuery(r *http.Request, key string, defaultValue int) int {
@@ -230,17 +286,28 @@ func parseIntQuery(r *http.Request, key string, defaultValue int) int {
 	return defaultValue
 }
 
-func RegisterRoutes(r chi.Router, handler *Handler) {
+// RegisterRoutes registers data row routes, which all go through auth.
+// Changing data rows and their masks requires the data_rows:write
+// permission and the edit role on their dataset.
+func RegisterRoutes(r chi.Router, handler *Handler, auth func(http.Handler) http.Handler) {
+	write := middleware.RequirePermission(roleDomain.PermissionDataRowsWrite)
 	r.Route("/datasets/{datasetId}/data-rows", func(r chi.Router) {
+		r.Use(auth)
 		r.Get("/", handler.List)
-		r.Post("/", handler.Create)
-		r.Post("/bulk", handler.BulkCreate)
 		r.Get("/stats", handler.GetStats)
-		r.Delete("/", handler.DeleteByDatasetID)
+		r.Get("/masks", handler.GetMasks)
+		r.Group(func(r chi.Router) {
+			r.Use(write, handler.requireEdit)
+			r.Post("/", handler.Create)
+			r.Post("/bulk", handler.BulkCreate)
+			r.Put("/masks", handler.UpdateMasks)
+			r.Delete("/", handler.DeleteByDatasetID)
+		})
 	})
 	r.Route("/data-rows", func(r chi.Router) {
+		r.Use(auth)
 		r.Get("/{id}", handler.GetByID)
-		r.Put("/{id}", handler.Update)
-		r.Delete("/{id}", handler.Delete)
+		r.With(write).Put("/{id}", handler.Update)
+		r.With(write).Delete("/{id}", handler.Delete)
 	})
 }
@@ -0,0 +1,163 @@
+package http_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"portal-data-backend/infrastructure/auth"
+	"portal-data-backend/infrastructure/http/middleware"
+	delivery "portal-data-backend/internal/data_row/delivery/http"
+	"portal-data-backend/internal/data_row/domain"
+	"portal-data-backend/internal/data_row/usecase"
+	datasetDomain "portal-data-backend/internal/dataset/domain"
+	roleDomain "portal-data-backend/internal/role/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	datasetID = "6f1c2a8e-3b7d-4c5e-9a1f-2d3e4f5a6b7c"
+	rowID     = "0d9e8f7a-6b5c-4d3e-8f2a-1b0c9d8e7f6a"
+)
+
+// mockUsecase counts the writes it is asked for; its one row belongs to
+// datasetID
+type mockUsecase struct {
+	usecase.Usecase
+	writes int
+}
+
+func (m *mockUsecase) GetByID(ctx context.Context, id string) (*domain.DataRowInfo, error) {
+	if id != rowID {
+		return nil, pkgErrors.ErrNotFound
+	}
+	return &domain.DataRowInfo{ID: rowID, DatasetID: datasetID}, nil
+}
+
+func (m *mockUsecase) Create(ctx context.Context, req *domain.CreateDataRowRequest, userID string) (*domain.DataRowInfo, error) {
+	m.writes++
+	return &domain.DataRowInfo{DatasetID: req.DatasetID}, nil
+}
+
+func (m *mockUsecase) Update(ctx context.Context, id string, req *domain.UpdateDataRowRequest) (*domain.DataRowInfo, error) {
+	m.writes++
+	return &domain.DataRowInfo{ID: id, DatasetID: datasetID}, nil
+}
+
+func (m *mockUsecase) Delete(ctx context.Context, id string) error {
+	m.writes++
+	return nil
+}
+
+func (m *mockUsecase) DeleteByDatasetID(ctx context.Context, datasetID string) error {
+	m.writes++
+	return nil
+}
+
+func (m *mockUsecase) UpdateMasks(ctx context.Context, datasetID string, req *domain.UpdateColumnMasksRequest) (*domain.ColumnMasksResponse, error) {
+	m.writes++
+	return &domain.ColumnMasksResponse{}, nil
+}
+
+// grants authorizes the users it grants a role on datasetID
+type grants map[string]datasetDomain.CollaboratorRole
+
+func (g grants) Authorize(ctx context.Context, id string, caller datasetDomain.Caller, role datasetDomain.CollaboratorRole) error {
+	if caller.Admin || (id == datasetID && g[caller.UserID].Grants(role)) {
+		return nil
+	}
+	return fmt.Errorf("%w: you need the %s role on the dataset", pkgErrors.ErrForbidden, role)
+}
+
+// Test only the collaborators with the edit role on the dataset change its
+// rows, even when their role grants data_rows:write
+func TestDataRows_RequireEdit(t *testing.T) {
+	rows := &mockUsecase{}
+	// The tests sign in as the user of their header
+	signIn := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims := auth.Claims{UserID: r.Header.Get("X-User"), RoleID: "editor"}
+			next.ServeHTTP(w, r.WithContext(auth.WithClaims(r.Context(), claims)))
+		})
+	}
+	lookup := func(ctx context.Context, roleID string) ([]string, error) {
+		return []string{roleDomain.PermissionDataRowsWrite}, nil
+	}
+	collaborators := grants{"viewer": datasetDomain.CollaboratorView, "editor": datasetDomain.CollaboratorEdit}
+
+	r := chi.NewRouter()
+	r.Use(middleware.Permissions(lookup, "admin"))
+	delivery.RegisterRoutes(r, delivery.NewHandler(rows, collaborators, []string{"admin"}), signIn)
+
+	writes := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		{"creating a row", http.MethodPost, "/datasets/" + datasetID + "/data-rows/", `{"dataset_id": "` + datasetID + `", "row_index": 1, "data": "{}"}`},
+		{"updating the masks", http.MethodPut, "/datasets/" + datasetID + "/data-rows/masks", `{"masks": []}`},
+		{"deleting the rows", http.MethodDelete, "/datasets/" + datasetID + "/data-rows/", ""},
+		{"updating a row", http.MethodPut, "/data-rows/" + rowID, `{"data": "{}"}`},
+		{"deleting a row", http.MethodDelete, "/data-rows/" + rowID, ""},
+	}
+	users := []struct {
+		user   string
+		status int
+	}{
+		{"viewer", http.StatusForbidden},
+		{"stranger", http.StatusForbidden},
+		{"editor", 0},
+	}
+	for _, write := range writes {
+		for _, u := range users {
+			t.Run(write.name+" as "+u.user, func(t *testing.T) {
+				req := httptest.NewRequest(write.method, write.path, strings.NewReader(write.body))
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set("X-User", u.user)
+				rec := httptest.NewRecorder()
+				r.ServeHTTP(rec, req)
+				if u.status != 0 && rec.Code != u.status {
+					t.Errorf("Expected %d, got %d: %s", u.status, rec.Code, rec.Body.String())
+				}
+				if u.status == 0 && rec.Code >= http.StatusBadRequest {
+					t.Errorf("Expected the write to succeed, got %d: %s", rec.Code, rec.Body.String())
+				}
+			})
+		}
+	}
+	if rows.writes != len(writes) {
+		t.Errorf("Expected the editor alone to write, got %d writes", rows.writes)
+	}
+}
+
+// Test rows are only created in the dataset of the path
+func TestDataRows_CreateInPathDataset(t *testing.T) {
+	rows := &mockUsecase{}
+	signIn := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(auth.WithClaims(r.Context(), auth.Claims{UserID: "editor", RoleID: "admin"})))
+		})
+	}
+
+	r := chi.NewRouter()
+	r.Use(middleware.Permissions(func(ctx context.Context, roleID string) ([]string, error) { return nil, nil }, "admin"))
+	delivery.RegisterRoutes(r, delivery.NewHandler(rows, grants{"editor": datasetDomain.CollaboratorEdit}, nil), signIn)
+
+	body := strings.NewReader(`{"dataset_id": "` + rowID + `", "row_index": 1, "data": "{}"}`)
+	req := httptest.NewRequest(http.MethodPost, "/datasets/"+datasetID+"/data-rows/", body)
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected %d, got %d: %s", http.StatusBadRequest, rec.Code, rec.Body.String())
+	}
+	if rows.writes != 0 {
+		t.Errorf("Expected no row created, got %d writes", rows.writes)
+	}
+}
@@ -0,0 +1,27 @@
+package http
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	dataRowDomain "portal-data-backend/internal/data_row/domain"
+)
+
+// Describe adds the data row routes to the API specification
+func Describe(spec *openapi.Spec) {
+	api := spec.Tag("data-rows", "Rows of dataset data")
+	api.Get("/datasets/{datasetId}/data-rows", "List data rows of a dataset").
+		Query(dataRowDomain.ListDataRowsRequest{}, "page", "limit", "search").
+		Returns(http.StatusOK, dataRowDomain.DataRowListResponse{})
+	api.Post("/datasets/{datasetId}/data-rows", "Create data row").Body(dataRowDomain.CreateDataRowRequest{}).Returns(http.StatusCreated, dataRowDomain.DataRowInfo{})
+	api.Post("/datasets/{datasetId}/data-rows/bulk", "Create data rows in bulk").Body(dataRowDomain.BulkCreateDataRowsRequest{}).Returns(http.StatusCreated, nil)
+	api.Get("/datasets/{datasetId}/data-rows/stats", "Get data row statistics of a dataset").Returns(http.StatusOK, dataRowDomain.DataRowStats{})
+	api.Get("/datasets/{datasetId}/data-rows/masks", "List the masked columns of a dataset").Returns(http.StatusOK, dataRowDomain.ColumnMasksResponse{})
+	api.Put("/datasets/{datasetId}/data-rows/masks", "Replace the masked columns of a dataset").
+		Body(dataRowDomain.UpdateColumnMasksRequest{}).
+		Returns(http.StatusOK, dataRowDomain.ColumnMasksResponse{})
+	api.Delete("/datasets/{datasetId}/data-rows", "Delete all data rows of a dataset").Returns(http.StatusOK, nil)
+	api.Get("/data-rows/{id}", "Get data row").Returns(http.StatusOK, dataRowDomain.DataRowInfo{})
+	api.Put("/data-rows/{id}", "Update data row").Body(dataRowDomain.UpdateDataRowRequest{}).Returns(http.StatusOK, dataRowDomain.DataRowInfo{})
+	api.Delete("/data-rows/{id}", "Delete data row").Returns(http.StatusOK, nil)
+}
@@ -14,6 +14,15 @@ type DataRow struct {
 	DeletedAt *time.Time  `db:"deleted_at" json:"deleted_at,omitempty"`
 }
 
+// DataRowMatch is a row matching a full-text search. Highlights is a JSON
+// object of the cells that matched, by column, with the matching words
+// wrapped in <mark> tags.
+type DataRowMatch struct {
+	DataRow
+	Rank       float64 `db:"rank"`
+	Highlights string  `db:"highlights"`
+}
+
 // ListDataRowsRequest represents list data rows input
 type ListDataRowsRequest struct {
 	Page      int    `json:"page" validate:"min=1"`
@@ -56,6 +65,10 @@ type DataRowInfo struct {
 	CreatedBy string    `json:"created_by"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	// Rank and Highlights are set on the rows of a search, Rank ordering
+	// them by relevance
+	Rank       float64           `json:"rank,omitempty"`
+	Highlights map[string]string `json:"highlights,omitempty"`
 }
 
 // DataRowListResponse represents paginated data row list
@@ -74,6 +87,54 @@ type ListMeta struct {
 
 // DataRowStats represents data row statistics
 type DataRowStats struct {
-	TotalRows   int64     `json:"total_rows"`
-	LastUpdated time.Time `json:"last_updated"`
+	TotalRows   int64     `db:"total_rows" json:"total_rows"`
+	LastUpdated time.Time `db:"last_updated" json:"last_updated"`
+}
+
+// MaskStrategy tells how the values of a sensitive column are masked for
+// readers without a privileged role
+type MaskStrategy string
+
+const (
+	// MaskHash replaces values with a keyed hash, so equal values still
+	// match across rows
+	MaskHash MaskStrategy = "hash"
+	// MaskRedact replaces values with RedactedValue
+	MaskRedact MaskStrategy = "redact"
+	// MaskBucket replaces numbers with the range of BucketSize they fall in,
+	// like "100-200", and redacts other values
+	MaskBucket MaskStrategy = "bucket"
+)
+
+// RedactedValue replaces the values masks redact
+const RedactedValue = "***"
+
+// ColumnMask marks a column of the rows of a dataset as sensitive
+type ColumnMask struct {
+	DatasetID  string       `db:"dataset_id" json:"-"`
+	Column     string       `db:"column_name" json:"column"`
+	Strategy   MaskStrategy `db:"strategy" json:"strategy"`
+	BucketSize float64      `db:"bucket_size" json:"bucket_size,omitempty"`
+	CreatedAt  time.Time    `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time    `db:"updated_at" json:"updated_at"`
+}
+
+// UpdateColumnMasksRequest replaces the masks of the columns of a dataset;
+// columns left out are no longer masked
+type UpdateColumnMasksRequest struct {
+	Masks []ColumnMaskInput `json:"masks" validate:"max=200,dive"`
+}
+
+// ColumnMaskInput represents a column mask input. BucketSize is required by
+// the bucket strategy.
+type ColumnMaskInput struct {
+	Column     string       `json:"column" validate:"required,max=255"`
+	Strategy   MaskStrategy `json:"strategy" validate:"required,oneof=hash redact bucket"`
+	BucketSize float64      `json:"bucket_size,omitempty" validate:"required_if=Strategy bucket,gte=0"`
+}
+
+// ColumnMasksResponse lists the masked columns of a dataset
+type ColumnMasksResponse struct {
+	DatasetID string       `json:"dataset_id"`
+	Masks     []ColumnMask `json:"masks"`
 }
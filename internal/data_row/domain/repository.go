@@ -7,6 +7,7 @@ import (
 type Repository interface {
 	GetByID(ctx context.Context, id string) (*DataRow, error)
 	List(ctx context.Context, filter *DataRowFilter, limit, offset int) ([]*DataRow, int, error)
+	Search(ctx context.Context, filter *DataRowFilter, limit, offset int) ([]*DataRowMatch, int, error)
 	Create(ctx context.Context, row *DataRow) error
 	BulkCreate(ctx context.Context, rows []*DataRow) error
 	Update(ctx context.Context, id string, row *DataRow) error
@@ -16,7 +17,27 @@ type Repository interface {
 	GetStats(ctx context.Context, datasetID string) (*DataRowStats, error)
 }
 
+// DataRowFilter selects the rows of a dataset. Search is a full-text query
+// over the values of the rows, in web search syntax; the values of
+// HiddenColumns are left out of the match.
 type DataRowFilter struct {
-	DatasetID string
-	Search    string
+	DatasetID     string
+	Search        string
+	HiddenColumns []string
+}
+
+// EventPublisher emits data row events to interested integrations and sinks
+type EventPublisher interface {
+	Publish(ctx context.Context, eventType string, data interface{}) error
+}
+
+// Data row event types
+const (
+	EventRowsImported = "rows.imported"
+)
+
+// MaskRepository stores the masks of the sensitive columns of datasets
+type MaskRepository interface {
+	ListMasks(ctx context.Context, datasetID string) ([]ColumnMask, error)
+	ReplaceMasks(ctx context.Context, datasetID string, masks []ColumnMask) error
 }
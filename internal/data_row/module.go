@@ -0,0 +1,79 @@
+// Package datarow is the module storing the rows of tabular datasets.
+package datarow
+
+import (
+	"context"
+	"crypto/sha256"
+	"net/http"
+	"time"
+
+	portalv1 "portal-data-backend/api/proto/portal/v1"
+	"portal-data-backend/infrastructure/db"
+	"portal-data-backend/infrastructure/http/openapi"
+	"portal-data-backend/internal/app"
+	datarowgrpc "portal-data-backend/internal/data_row/delivery/grpc"
+	delivery "portal-data-backend/internal/data_row/delivery/http"
+	"portal-data-backend/internal/data_row/repository"
+	"portal-data-backend/internal/data_row/usecase"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jmoiron/sqlx"
+	"google.golang.org/grpc"
+)
+
+// Module stores data rows and provides them to other modules
+type Module struct {
+	handler *delivery.Handler
+	server  *datarowgrpc.Server
+	db      *sqlx.DB
+}
+
+// Name implements app.Module
+func (m *Module) Name() string {
+	return "data_row"
+}
+
+// Register implements app.Module
+func (m *Module) Register(deps *app.Deps) error {
+	if deps.Services.Datasets == nil {
+		return app.MissingServiceError("dataset")
+	}
+	m.db = deps.DB
+	repo := repository.NewDataRowPostgresRepository(deps.DB)
+	masks := repository.NewMaskPostgresRepository(deps.DB)
+
+	hashKey := []byte(deps.Config.Masking.HashKey)
+	if len(hashKey) == 0 {
+		// Only reachable outside production; config validation requires a key there
+		deps.Logger.Info("MASKING_HASH_KEY not set, deriving a development key from the JWT secret")
+		devKey := sha256.Sum256([]byte("masking:" + deps.Config.JWT.Secret))
+		hashKey = devKey[:]
+	}
+	masking := usecase.Masking{HashKey: hashKey, PrivilegedRoles: deps.Config.Masking.PrivilegedRoles}
+
+	dataRows := usecase.NewDataRowUsecase(repo, masks, deps.Tx, deps.Outbox, deps.Services.Audit, masking)
+	deps.Services.DataRows = dataRows
+	m.handler = delivery.NewHandler(dataRows, deps.Services.Datasets, deps.Config.Audit.AdminRoles)
+	m.server = datarowgrpc.NewServer(dataRows, deps.Services.Datasets, deps.Config.Audit.AdminRoles)
+	return nil
+}
+
+// Routes implements app.Module
+func (m *Module) Routes(r chi.Router, auth func(http.Handler) http.Handler) {
+	delivery.RegisterRoutes(r, m.handler, auth)
+}
+
+// Describe implements app.Module
+func (m *Module) Describe(spec *openapi.Spec) {
+	delivery.Describe(spec)
+}
+
+// RegisterGRPC implements app.GRPCRegistrar
+func (m *Module) RegisterGRPC(s grpc.ServiceRegistrar) {
+	portalv1.RegisterDataRowServiceServer(s, m.server)
+}
+
+// Purge implements app.Purger
+func (m *Module) Purge(ctx context.Context, before time.Time) (int64, error) {
+	return db.PurgeDeleted(ctx, m.db, "data_rows", before)
+}
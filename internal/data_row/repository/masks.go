@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	dataRowDomain "portal-data-backend/internal/data_row/domain"
+
+	"portal-data-backend/infrastructure/db"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type maskPostgresRepository struct {
+	db *sqlx.DB
+}
+
+// NewMaskPostgresRepository creates a repository of the column masks of
+// datasets
+func NewMaskPostgresRepository(db *sqlx.DB) dataRowDomain.MaskRepository {
+	return &maskPostgresRepository{db: db}
+}
+
+func (r *maskPostgresRepository) ListMasks(ctx context.Context, datasetID string) ([]dataRowDomain.ColumnMask, error) {
+	query := `
+		SELECT dataset_id, column_name, strategy, bucket_size, created_at, updated_at
+		FROM dataset_column_masks
+		WHERE dataset_id = $1
+		ORDER BY column_name ASC
+	`
+
+	masks := []dataRowDomain.ColumnMask{}
+	if err := db.Conn(ctx, r.db).SelectContext(ctx, &masks, query, datasetID); err != nil {
+		return nil, fmt.Errorf("failed to list column masks: %w", err)
+	}
+	return masks, nil
+}
+
+// ReplaceMasks deletes the masks of the dataset and creates masks in their
+// place; callers run it in a transaction
+func (r *maskPostgresRepository) ReplaceMasks(ctx context.Context, datasetID string, masks []dataRowDomain.ColumnMask) error {
+	conn := db.Conn(ctx, r.db)
+	if _, err := conn.ExecContext(ctx, `DELETE FROM dataset_column_masks WHERE dataset_id = $1`, datasetID); err != nil {
+		return fmt.Errorf("failed to delete column masks: %w", err)
+	}
+	if len(masks) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO dataset_column_masks (dataset_id, column_name, strategy, bucket_size, created_at, updated_at)
+		VALUES (:dataset_id, :column_name, :strategy, :bucket_size, :created_at, :updated_at)
+	`
+	if _, err := conn.NamedExecContext(ctx, query, masks); err != nil {
+		return fmt.Errorf("failed to create column masks: %w", err)
+	}
+	return nil
+}
@@ -8,7 +8,11 @@ import (
 
 	dataRowDomain "portal-data-backend/internal/data_row/domain"
 
+	"portal-data-backend/infrastructure/db"
+	"portal-data-backend/pkg/errors"
+
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type dataRowPostgresRepository struct {
@@ -27,7 +31,7 @@ func (r *dataRowPostgresRepository) GetByID(ctx context.Context, id string) (*da
 	`
 
 	var row dataRowDomain.DataRow
-	err := r.db.GetContext(ctx, &row, query, id)
+	err := db.Conn(ctx, r.db).GetContext(ctx, &row, query, id)
 	if err != nil {
 		return nil, r.handleError(err)
 	}
@@ -40,14 +44,18 @@ func (r *dataRowPostgresRepository) List(ctx context.Context, filter *dataRowDom
 	argCount := 2
 
 	if filter.Search != "" {
-		whereClause += fmt.Sprintf(" AND data::text ILIKE $%d", argCount)
-		args = append(args, "%"+filter.Search+"%")
+		whereClause += fmt.Sprintf(" AND %s @@ websearch_to_tsquery('simple', $%d)", searchVector(filter, argCount+1), argCount)
+		args = append(args, filter.Search)
 		argCount++
+		if len(filter.HiddenColumns) > 0 {
+			args = append(args, pq.Array(filter.HiddenColumns))
+			argCount++
+		}
 	}
 
 	countQuery := "SELECT COUNT(*) FROM data_rows " + whereClause
 	var total int
-	err := r.db.GetContext(ctx, &total, countQuery, args...)
+	err := db.Conn(ctx, r.db).GetContext(ctx, &total, countQuery, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count data rows: %w", err)
 	}
@@ -60,7 +68,7 @@ func (r *dataRowPostgresRepository) List(ctx context.Context, filter *dataRowDom
 	args = append(args, limit, offset)
 
 	var rows []*dataRowDomain.DataRow
-	err = r.db.SelectContext(ctx, &rows, query, args...)
+	err = db.Conn(ctx, r.db).SelectContext(ctx, &rows, query, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to list data rows: %w", err)
 	}
@@ -68,13 +76,70 @@ func (r *dataRowPostgresRepository) List(ctx context.Context, filter *dataRowDom
 	return rows, total, nil
 }
 
+// Search lists the rows matching the full-text query filter.Search, the most
+// relevant first, with the matching cells highlighted
+func (r *dataRowPostgresRepository) Search(ctx context.Context, filter *dataRowDomain.DataRowFilter, limit, offset int) ([]*dataRowDomain.DataRowMatch, int, error) {
+	hidden := filter.HiddenColumns
+	if hidden == nil {
+		hidden = []string{}
+	}
+	args := []interface{}{filter.DatasetID, filter.Search, pq.Array(hidden)}
+	whereClause := "WHERE deleted_at IS NULL AND dataset_id = $1 AND " + searchVector(filter, 3) + " @@ query"
+
+	// The count only reads the hidden columns out of the vector
+	countArgs := args
+	if len(hidden) == 0 {
+		countArgs = args[:2]
+	}
+	countQuery := "SELECT COUNT(*) FROM data_rows, websearch_to_tsquery('simple', $2) query " + whereClause
+	var total int
+	err := db.Conn(ctx, r.db).GetContext(ctx, &total, countQuery, countArgs...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count matching data rows: %w", err)
+	}
+
+	query := `
+		SELECT id, dataset_id, row_index, data, created_by, created_at, updated_at, deleted_at,
+			ts_rank(` + searchVector(filter, 3) + `, query) AS rank,
+			COALESCE((
+				SELECT jsonb_object_agg(cell.key, cell.headline)
+				FROM (
+					SELECT key, ts_headline('simple', value, query, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') AS headline
+					FROM jsonb_each_text(data)
+					WHERE NOT key = ANY($3::text[])
+				) cell
+				WHERE strpos(cell.headline, '<mark>') > 0
+			), '{}')::text AS highlights
+		FROM data_rows, websearch_to_tsquery('simple', $2) query
+	` + whereClause + " ORDER BY rank DESC, row_index ASC LIMIT $4 OFFSET $5"
+	args = append(args, limit, offset)
+
+	var rows []*dataRowDomain.DataRowMatch
+	err = db.Conn(ctx, r.db).SelectContext(ctx, &rows, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search data rows: %w", err)
+	}
+
+	return rows, total, nil
+}
+
+// searchVector is the full-text vector of the rows filter searches: the
+// indexed vector of all their values, or the values outside the hidden
+// columns, passed as the text array parameter $hiddenArg
+func searchVector(filter *dataRowDomain.DataRowFilter, hiddenArg int) string {
+	if len(filter.HiddenColumns) == 0 {
+		return "search_vector"
+	}
+	return fmt.Sprintf(`jsonb_to_tsvector('simple', data - $%d::text[], '["string", "numeric"]')`, hiddenArg)
+}
+
 func (r *dataRowPostgresRepository) Create(ctx context.Context, row *dataRowDomain.DataRow) error {
 	query := `
 		INSERT INTO data_rows (id, dataset_id, row_index, data, created_by, created_at, updated_at)
 		VALUES (:id, :dataset_id, :row_index, :data, :created_by, :created_at, :updated_at)
 	`
 
-	_, err := r.db.NamedExecContext(ctx, query, row)
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, row)
 	if err != nil {
 		return fmt.Errorf("failed to create data row: %w", err)
 	}
@@ -87,7 +152,7 @@ func (r *dataRowPostgresRepository) BulkCreate(ctx context.Context, rows []*data
 		VALUES (:id, :dataset_id, :row_index, :data, :created_by, :created_at, :updated_at)
 	`
 
-	_, err := r.db.NamedExecContext(ctx, query, rows)
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, rows)
 	if err != nil {
 		return fmt.Errorf("failed to bulk create data rows: %w", err)
 	}
@@ -102,7 +167,7 @@ func (r *dataRowPostgresRepository) Update(ctx context.Context, id string, row *
 	`
 
 	row.ID = id
-	_, err := r.db.NamedExecContext(ctx, query, row)
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, row)
 	if err != nil {
 		return fmt.Errorf("failed to update data row: %w", err)
 	}
@@ -111,7 +176,7 @@ func (r *dataRowPostgresRepository) Update(ctx context.Context, id string, row *
 
 func (r *dataRowPostgresRepository) Delete(ctx context.Context, id string) error {
 	query := `UPDATE data_rows SET deleted_at = $1 WHERE id = $2`
-	_, err := r.db.ExecContext(ctx, query, time.Now(), id)
+	_, err := db.Conn(ctx, r.db).ExecContext(ctx, query, time.Now(), id)
 	if err != nil {
 		return fmt.Errorf("failed to delete data row: %w", err)
 	}
@@ -120,7 +185,7 @@ func (r *dataRowPostgresRepository) Delete(ctx context.Context, id string) error
 
 func (r *dataRowPostgresRepository) DeleteByDatasetID(ctx context.Context, datasetID string) error {
 	query := `UPDATE data_rows SET deleted_at = $1 WHERE dataset_id = $2`
-	_, err := r.db.ExecContext(ctx, query, time.Now(), datasetID)
+	_, err := db.Conn(ctx, r.db).ExecContext(ctx, query, time.Now(), datasetID)
 	if err != nil {
 		return fmt.Errorf("failed to delete data rows by dataset: %w", err)
 	}
@@ -135,7 +200,7 @@ func (r *dataRowPostgresRepository) GetByRowIndex(ctx context.Context, datasetID
 	`
 
 	var row dataRowDomain.DataRow
-	err := r.db.GetContext(ctx, &row, query, datasetID, rowIndex)
+	err := db.Conn(ctx, r.db).GetContext(ctx, &row, query, datasetID, rowIndex)
 	if err != nil {
 		return nil, r.handleError(err)
 	}
@@ -152,7 +217,7 @@ func (r *dataRowPostgresRepository) GetStats(ctx context.Context, datasetID stri
 	`
 
 	var stats dataRowDomain.DataRowStats
-	err := r.db.GetContext(ctx, &stats, query, datasetID)
+	err := db.Conn(ctx, r.db).GetContext(ctx, &stats, query, datasetID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get data row stats: %w", err)
 	}
@@ -165,7 +230,7 @@ func (r *dataRowPostgresRepository) handleError(err error) error {
 		return nil
 	}
 	if err == sql.ErrNoRows {
-		return fmt.Errorf("data row not found")
+		return errors.ErrNotFound
 	}
 	return fmt.Errorf("database error: %w", err)
 }
@@ -0,0 +1,191 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	dataRowDomain "portal-data-backend/internal/data_row/domain"
+	"portal-data-backend/internal/testenv"
+	pkgErrors "portal-data-backend/pkg/errors"
+)
+
+const populationDatasetID = "20000000-0000-0000-0000-000000000001"
+
+func TestMain(m *testing.M) {
+	os.Exit(testenv.Main(m))
+}
+
+func newTestRepository(t *testing.T) dataRowDomain.Repository {
+	t.Helper()
+	conn := testenv.Postgres(t)
+	testenv.Fixtures(t, conn, "catalog", "data_rows")
+	return NewDataRowPostgresRepository(conn)
+}
+
+// listResult is a page of data rows as golden files record it
+type listResult struct {
+	Total int                      `json:"total"`
+	Rows  []*dataRowDomain.DataRow `json:"rows"`
+}
+
+// Test rows are read in order, leaving out deleted rows
+func TestDataRowPostgresRepository_Reads(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	lists := map[string]listResult{}
+	for name, filter := range map[string]*dataRowDomain.DataRowFilter{
+		"all":    {DatasetID: populationDatasetID},
+		"search": {DatasetID: populationDatasetID, Search: "sukajadi"},
+	} {
+		rows, total, err := repo.List(ctx, filter, 10, 0)
+		if err != nil {
+			t.Fatalf("Expected no error listing %s, got %v", name, err)
+		}
+		lists[name] = listResult{Total: total, Rows: rows}
+	}
+	testenv.Golden(t, "data_row_lists", lists)
+
+	row, err := repo.GetByRowIndex(ctx, populationDatasetID, 1)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if row.ID != "70000000-0000-0000-0000-000000000002" {
+		t.Errorf("Expected the second row, got %s", row.ID)
+	}
+	if _, err := repo.GetByID(ctx, "70000000-0000-0000-0000-000000000004"); !errors.Is(err, pkgErrors.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a deleted row, got %v", err)
+	}
+
+	stats, err := repo.GetStats(ctx, populationDatasetID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	testenv.Golden(t, "data_row_stats", stats)
+}
+
+// Test full-text search ranks the matching rows and highlights the matching
+// cells, leaving out hidden columns
+func TestDataRowPostgresRepository_Search(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	matches, total, err := repo.Search(ctx, &dataRowDomain.DataRowFilter{DatasetID: populationDatasetID, Search: "sukajadi"}, 10, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if total != 1 || len(matches) != 1 || matches[0].ID != "70000000-0000-0000-0000-000000000002" {
+		t.Fatalf("Expected the Sukajadi row, got %d rows of %d", len(matches), total)
+	}
+	if matches[0].Rank <= 0 || matches[0].Highlights != `{"kecamatan": "<mark>Sukajadi</mark>"}` {
+		t.Errorf("Expected a ranked match highlighting the district, got %v and %s", matches[0].Rank, matches[0].Highlights)
+	}
+
+	matches, total, err = repo.Search(ctx, &dataRowDomain.DataRowFilter{DatasetID: populationDatasetID, Search: "cidadap or 131000"}, 10, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if total != 2 || len(matches) != 2 || matches[0].RowIndex != 0 || matches[1].RowIndex != 2 {
+		t.Errorf("Expected the Coblong and Cidadap rows, got %d rows of %d", len(matches), total)
+	} else if matches[0].Highlights != `{"jumlah": "<mark>131000</mark>"}` {
+		t.Errorf("Expected the population highlighted, got %s", matches[0].Highlights)
+	}
+
+	hidden := &dataRowDomain.DataRowFilter{DatasetID: populationDatasetID, Search: "sukajadi", HiddenColumns: []string{"kecamatan"}}
+	if _, total, err := repo.Search(ctx, hidden, 10, 0); err != nil || total != 0 {
+		t.Errorf("Expected no match in a hidden column, got %d (%v)", total, err)
+	}
+	hidden.Search = "sukajadi OR 58000"
+	matches, _, err = repo.Search(ctx, hidden, 10, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(matches) != 1 || matches[0].Highlights != `{"jumlah": "<mark>58000</mark>"}` {
+		t.Errorf("Expected the Cidadap row with no hidden cell highlighted, got %d rows", len(matches))
+	}
+}
+
+// Test imported rows read back as written, and rows are deleted alone or
+// with their dataset
+func TestDataRowPostgresRepository_Writes(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	datasetID := "20000000-0000-0000-0000-000000000002"
+	created := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	rows := []*dataRowDomain.DataRow{
+		{ID: "70000000-0000-0000-0000-000000000005", DatasetID: datasetID, RowIndex: 0, Data: `{"tahun": 2024, "cakupan": 91.5}`, CreatedBy: "90000000-0000-0000-0000-000000000001", CreatedAt: created, UpdatedAt: created},
+		{ID: "70000000-0000-0000-0000-000000000006", DatasetID: datasetID, RowIndex: 1, Data: `{"tahun": 2025, "cakupan": 93.2}`, CreatedBy: "90000000-0000-0000-0000-000000000001", CreatedAt: created, UpdatedAt: created},
+	}
+	if err := repo.BulkCreate(ctx, rows); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	imported, total, err := repo.List(ctx, &dataRowDomain.DataRowFilter{DatasetID: datasetID}, 10, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	testenv.Golden(t, "data_row_imported", listResult{Total: total, Rows: imported})
+
+	update := &dataRowDomain.DataRow{RowIndex: 1, Data: `{"tahun": 2025, "cakupan": 94.0}`, UpdatedAt: created.Add(time.Hour)}
+	if err := repo.Update(ctx, rows[1].ID, update); err != nil {
+		t.Fatalf("Expected no error updating, got %v", err)
+	}
+	got, err := repo.GetByID(ctx, rows[1].ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got.Data != `{"tahun": 2025, "cakupan": 94.0}` || !got.UpdatedAt.Equal(update.UpdatedAt) {
+		t.Errorf("Expected the updated row, got %+v", got)
+	}
+
+	if err := repo.Delete(ctx, rows[0].ID); err != nil {
+		t.Fatalf("Expected no error deleting, got %v", err)
+	}
+	if _, err := repo.GetByID(ctx, rows[0].ID); !errors.Is(err, pkgErrors.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a deleted row, got %v", err)
+	}
+
+	if err := repo.DeleteByDatasetID(ctx, datasetID); err != nil {
+		t.Fatalf("Expected no error deleting the rows of the dataset, got %v", err)
+	}
+	stats, err := repo.GetStats(ctx, datasetID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if stats.TotalRows != 0 {
+		t.Errorf("Expected no rows left, got %d", stats.TotalRows)
+	}
+}
+
+// Test the masks of a dataset are replaced as a whole
+func TestMaskPostgresRepository_ReplaceMasks(t *testing.T) {
+	conn := testenv.Postgres(t)
+	testenv.Fixtures(t, conn, "catalog")
+	repo := NewMaskPostgresRepository(conn)
+	ctx := context.Background()
+
+	created := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	masks := []dataRowDomain.ColumnMask{
+		{DatasetID: populationDatasetID, Column: "nik", Strategy: dataRowDomain.MaskHash, CreatedAt: created, UpdatedAt: created},
+		{DatasetID: populationDatasetID, Column: "jumlah", Strategy: dataRowDomain.MaskBucket, BucketSize: 1000, CreatedAt: created, UpdatedAt: created},
+	}
+	if err := repo.ReplaceMasks(ctx, populationDatasetID, masks); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := repo.ReplaceMasks(ctx, populationDatasetID, masks[1:]); err != nil {
+		t.Fatalf("Expected no error replacing, got %v", err)
+	}
+
+	got, err := repo.ListMasks(ctx, populationDatasetID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(got) != 1 || got[0].Column != "jumlah" || got[0].BucketSize != 1000 {
+		t.Errorf("Expected only the jumlah mask, got %+v", got)
+	}
+}
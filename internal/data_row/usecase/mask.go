@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"strconv"
+
+	"portal-data-backend/internal/data_row/domain"
+)
+
+// Masking configures how rows are masked: HashKey keys the hashes of the
+// hash strategy and readers whose role is one of PrivilegedRoles see raw
+// values
+type Masking struct {
+	HashKey         []byte
+	PrivilegedRoles []string
+}
+
+// privileged reports whether role reads raw values
+func (m Masking) privileged(role string) bool {
+	for _, privileged := range m.PrivilegedRoles {
+		if role != "" && role == privileged {
+			return true
+		}
+	}
+	return false
+}
+
+// maskData returns the JSON data of a row with the columns of masks masked.
+// Data that is not a JSON object cannot tell its sensitive fields apart, so
+// none of it is shown.
+func (m Masking) maskData(data string, masks []domain.ColumnMask) string {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(data)))
+	decoder.UseNumber()
+	var row map[string]interface{}
+	if err := decoder.Decode(&row); err != nil || row == nil {
+		return "{}"
+	}
+
+	changed := false
+	for _, mask := range masks {
+		value, ok := row[mask.Column]
+		if !ok || value == nil {
+			continue
+		}
+		row[mask.Column] = m.maskValue(value, mask)
+		changed = true
+	}
+	if !changed {
+		return data
+	}
+
+	masked, err := json.Marshal(row)
+	if err != nil {
+		return "{}"
+	}
+	return string(masked)
+}
+
+func (m Masking) maskValue(value interface{}, mask domain.ColumnMask) interface{} {
+	switch mask.Strategy {
+	case domain.MaskHash:
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return domain.RedactedValue
+		}
+		mac := hmac.New(sha256.New, m.HashKey)
+		mac.Write(encoded)
+		return hex.EncodeToString(mac.Sum(nil))
+	case domain.MaskBucket:
+		return bucket(value, mask.BucketSize)
+	default:
+		return domain.RedactedValue
+	}
+}
+
+// bucket returns the range of size value falls in, redacting values that
+// are not numbers
+func bucket(value interface{}, size float64) interface{} {
+	var number float64
+	switch v := value.(type) {
+	case json.Number:
+		parsed, err := v.Float64()
+		if err != nil {
+			return domain.RedactedValue
+		}
+		number = parsed
+	case string:
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil || math.IsNaN(parsed) || math.IsInf(parsed, 0) {
+			return domain.RedactedValue
+		}
+		number = parsed
+	default:
+		return domain.RedactedValue
+	}
+	if size <= 0 {
+		return domain.RedactedValue
+	}
+
+	low := math.Floor(number/size) * size
+	return formatNumber(low) + "-" + formatNumber(low+size)
+}
+
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
@@ -0,0 +1,164 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"portal-data-backend/infrastructure/auth"
+	"portal-data-backend/internal/data_row/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+)
+
+// rowStore holds the rows and column masks of one dataset
+type rowStore struct {
+	domain.Repository
+	rows  []*domain.DataRow
+	masks []domain.ColumnMask
+	// searched is the filter of the last search
+	searched *domain.DataRowFilter
+}
+
+func (s *rowStore) List(ctx context.Context, filter *domain.DataRowFilter, limit, offset int) ([]*domain.DataRow, int, error) {
+	return s.rows, len(s.rows), nil
+}
+
+func (s *rowStore) Search(ctx context.Context, filter *domain.DataRowFilter, limit, offset int) ([]*domain.DataRowMatch, int, error) {
+	s.searched = filter
+	matches := make([]*domain.DataRowMatch, len(s.rows))
+	for i, row := range s.rows {
+		matches[i] = &domain.DataRowMatch{DataRow: *row, Rank: 0.1, Highlights: `{"kota": "<mark>Bandung</mark>"}`}
+	}
+	return matches, len(matches), nil
+}
+
+func (s *rowStore) GetByID(ctx context.Context, id string) (*domain.DataRow, error) {
+	for _, row := range s.rows {
+		if row.ID == id {
+			return row, nil
+		}
+	}
+	return nil, pkgErrors.ErrNotFound
+}
+
+func (s *rowStore) ListMasks(ctx context.Context, datasetID string) ([]domain.ColumnMask, error) {
+	return s.masks, nil
+}
+
+func (s *rowStore) ReplaceMasks(ctx context.Context, datasetID string, masks []domain.ColumnMask) error {
+	s.masks = masks
+	return nil
+}
+
+// mockTransactor runs work without a transaction
+type mockTransactor struct{}
+
+func (mockTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func newMaskedUsecase(store *rowStore) Usecase {
+	return NewDataRowUsecase(store, store, mockTransactor{}, nil, nil, Masking{HashKey: []byte("key"), PrivilegedRoles: []string{"admin"}})
+}
+
+// Test readers without a privileged role get the sensitive columns masked
+// while privileged ones get raw values
+func TestDataRowUsecase_ListMasked(t *testing.T) {
+	store := &rowStore{rows: []*domain.DataRow{
+		{ID: "row-1", DatasetID: "ds-1", Data: `{"nik":"3273012345","nama":"Asep","usia":37,"gaji":"7250000.5","kota":"Bandung"}`},
+		{ID: "row-2", DatasetID: "ds-1", Data: `{"nik":"3273012345","nama":"Euis","usia":"n/a","gaji":null}`},
+	}}
+	u := newMaskedUsecase(store)
+	_, err := u.UpdateMasks(context.Background(), "ds-1", &domain.UpdateColumnMasksRequest{Masks: []domain.ColumnMaskInput{
+		{Column: "nik", Strategy: domain.MaskHash},
+		{Column: "nama", Strategy: domain.MaskRedact},
+		{Column: "usia", Strategy: domain.MaskBucket, BucketSize: 10},
+		{Column: "gaji", Strategy: domain.MaskBucket, BucketSize: 1000000},
+	}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	ctx := auth.WithClaims(context.Background(), auth.Claims{RoleID: "viewer"})
+	resp, err := u.List(ctx, &domain.ListDataRowsRequest{DatasetID: "ds-1"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var first, second map[string]interface{}
+	json.Unmarshal([]byte(resp.Rows[0].Data), &first)
+	json.Unmarshal([]byte(resp.Rows[1].Data), &second)
+
+	if first["nik"] == "3273012345" || first["nik"] != second["nik"] {
+		t.Errorf("Expected equal hashes in place of the NIK, got %v and %v", first["nik"], second["nik"])
+	}
+	if first["nama"] != domain.RedactedValue || first["kota"] != "Bandung" {
+		t.Errorf("Expected the name redacted and the city kept, got %v", first)
+	}
+	if first["usia"] != "30-40" || first["gaji"] != "7000000-8000000" {
+		t.Errorf("Expected the age and salary in buckets, got %v and %v", first["usia"], first["gaji"])
+	}
+	if second["usia"] != domain.RedactedValue || second["gaji"] != nil {
+		t.Errorf("Expected a non-number redacted and null kept, got %v and %v", second["usia"], second["gaji"])
+	}
+
+	row, err := u.GetByID(auth.WithClaims(context.Background(), auth.Claims{RoleID: "admin"}), "row-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if row.Data != store.rows[0].Data {
+		t.Errorf("Expected an admin to get the raw row, got %s", row.Data)
+	}
+	row, _ = u.GetByID(context.Background(), "row-1")
+	if row.Data == store.rows[0].Data {
+		t.Errorf("Expected an anonymous reader to get a masked row, got %s", row.Data)
+	}
+}
+
+// Test readers without a privileged role cannot search the masked columns,
+// and get the matching rows masked with their highlights
+func TestDataRowUsecase_SearchMasked(t *testing.T) {
+	store := &rowStore{rows: []*domain.DataRow{
+		{ID: "row-1", DatasetID: "ds-1", Data: `{"nik":"3273012345","kota":"Bandung"}`},
+	}}
+	u := newMaskedUsecase(store)
+	_, err := u.UpdateMasks(context.Background(), "ds-1", &domain.UpdateColumnMasksRequest{Masks: []domain.ColumnMaskInput{
+		{Column: "nik", Strategy: domain.MaskRedact},
+	}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	ctx := auth.WithClaims(context.Background(), auth.Claims{RoleID: "viewer"})
+	resp, err := u.List(ctx, &domain.ListDataRowsRequest{DatasetID: "ds-1", Search: "bandung"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(store.searched.HiddenColumns) != 1 || store.searched.HiddenColumns[0] != "nik" {
+		t.Errorf("Expected the masked column left out of the search, got %v", store.searched.HiddenColumns)
+	}
+	row := resp.Rows[0]
+	if row.Data != `{"kota":"Bandung","nik":"***"}` || row.Highlights["kota"] != "<mark>Bandung</mark>" || row.Rank != 0.1 {
+		t.Errorf("Expected a masked, highlighted match, got %+v", row)
+	}
+
+	ctx = auth.WithClaims(context.Background(), auth.Claims{RoleID: "admin"})
+	if _, err := u.List(ctx, &domain.ListDataRowsRequest{DatasetID: "ds-1", Search: "3273012345"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(store.searched.HiddenColumns) != 0 {
+		t.Errorf("Expected an admin to search every column, got %v hidden", store.searched.HiddenColumns)
+	}
+}
+
+// Test a column cannot be masked twice
+func TestDataRowUsecase_UpdateMasksDuplicate(t *testing.T) {
+	u := newMaskedUsecase(&rowStore{})
+	_, err := u.UpdateMasks(context.Background(), "ds-1", &domain.UpdateColumnMasksRequest{Masks: []domain.ColumnMaskInput{
+		{Column: "nik", Strategy: domain.MaskHash},
+		{Column: "nik", Strategy: domain.MaskRedact},
+	}})
+	if !errors.Is(err, pkgErrors.ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput, got %v", err)
+	}
+}
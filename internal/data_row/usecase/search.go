@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"portal-data-backend/infrastructure/auth"
+	"portal-data-backend/internal/data_row/domain"
+)
+
+// search lists the rows of a dataset matching the full-text query of req,
+// the most relevant first. Callers reading masked values cannot search the
+// masked columns, or matching rows would tell their raw values.
+func (u *dataRowUsecase) search(ctx context.Context, req *domain.ListDataRowsRequest, offset int) (*domain.DataRowListResponse, error) {
+	hidden, err := u.hiddenColumns(ctx, req.DatasetID)
+	if err != nil {
+		return nil, err
+	}
+	filter := &domain.DataRowFilter{
+		DatasetID:     req.DatasetID,
+		Search:        req.Search,
+		HiddenColumns: hidden,
+	}
+
+	matches, total, err := u.repo.Search(ctx, filter, req.Limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search data rows: %w", err)
+	}
+
+	infos := make([]domain.DataRowInfo, len(matches))
+	for i, match := range matches {
+		infos[i] = *u.toInfo(&match.DataRow)
+		infos[i].Rank = match.Rank
+		if err := json.Unmarshal([]byte(match.Highlights), &infos[i].Highlights); err != nil {
+			return nil, fmt.Errorf("failed to decode highlights of data row %s: %w", match.ID, err)
+		}
+	}
+	if err := u.mask(ctx, req.DatasetID, infos); err != nil {
+		return nil, err
+	}
+
+	return &domain.DataRowListResponse{
+		Rows: infos,
+		Meta: domain.ListMeta{
+			Page:      req.Page,
+			Limit:     req.Limit,
+			Total:     total,
+			TotalPage: int(math.Ceil(float64(total) / float64(req.Limit))),
+		},
+	}, nil
+}
+
+// hiddenColumns returns the masked columns of a dataset the caller in ctx
+// reads masked, none for privileged roles
+func (u *dataRowUsecase) hiddenColumns(ctx context.Context, datasetID string) ([]string, error) {
+	role := auth.FromContext(ctx).RoleID
+	if u.masking.privileged(role) {
+		return nil, nil
+	}
+
+	masks, err := u.masks.ListMasks(ctx, datasetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get column masks: %w", err)
+	}
+	columns := make([]string, len(masks))
+	for i, mask := range masks {
+		columns[i] = mask.Column
+	}
+	return columns, nil
+}
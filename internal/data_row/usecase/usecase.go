@@ -6,7 +6,11 @@ import (
 	"math"
 	"time"
 
+	"portal-data-backend/infrastructure/audit"
+	"portal-data-backend/infrastructure/auth"
+	"portal-data-backend/infrastructure/db"
 	"portal-data-backend/internal/data_row/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
 
 	"github.com/google/uuid"
 )
@@ -20,15 +24,32 @@ type Usecase interface {
 	Delete(ctx context.Context, id string) error
 	DeleteByDatasetID(ctx context.Context, datasetID string) error
 	GetStats(ctx context.Context, datasetID string) (*domain.DataRowStats, error)
+
+	// Masks of the sensitive columns of a dataset, applied to the rows read
+	// by callers without a privileged role
+	GetMasks(ctx context.Context, datasetID string) (*domain.ColumnMasksResponse, error)
+	UpdateMasks(ctx context.Context, datasetID string, req *domain.UpdateColumnMasksRequest) (*domain.ColumnMasksResponse, error)
 }
 
 type dataRowUsecase struct {
-	repo domain.Repository
+	repo    domain.Repository
+	masks   domain.MaskRepository
+	tx      db.Transactor
+	events  domain.EventPublisher
+	audit   *audit.Recorder
+	masking Masking
 }
 
-func NewDataRowUsecase(repo domain.Repository) Usecase {
+// NewDataRowUsecase creates a new data row usecase. events and recorder may
+// be nil.
+func NewDataRowUsecase(repo domain.Repository, masks domain.MaskRepository, tx db.Transactor, events domain.EventPublisher, recorder *audit.Recorder, masking Masking) Usecase {
 	return &dataRowUsecase{
-		repo: repo,
+		repo:    repo,
+		masks:   masks,
+		tx:      tx,
+		events:  events,
+		audit:   recorder,
+		masking: masking,
 	}
 }
 
@@ -37,7 +58,11 @@ func (u *dataRowUsecase) GetByID(ctx context.Context, id string) (*domain.DataRo
 	if err != nil {
 		return nil, fmt.Errorf("failed to get data row: %w", err)
 	}
-	return u.toInfo(row), nil
+	infos := []domain.DataRowInfo{*u.toInfo(row)}
+	if err := u.mask(ctx, row.DatasetID, infos); err != nil {
+		return nil, err
+	}
+	return &infos[0], nil
 }
 
 func (u *dataRowUsecase) List(ctx context.Context, req *domain.ListDataRowsRequest) (*domain.DataRowListResponse, error) {
@@ -49,10 +74,12 @@ func (u *dataRowUsecase) List(ctx context.Context, req *domain.ListDataRowsReque
 	}
 
 	offset := (req.Page - 1) * req.Limit
+	if req.Search != "" {
+		return u.search(ctx, req, offset)
+	}
 
 	filter := &domain.DataRowFilter{
 		DatasetID: req.DatasetID,
-		Search:    req.Search,
 	}
 
 	rows, total, err := u.repo.List(ctx, filter, req.Limit, offset)
@@ -64,6 +91,9 @@ func (u *dataRowUsecase) List(ctx context.Context, req *domain.ListDataRowsReque
 	for i, row := range rows {
 		infos[i] = *u.toInfo(row)
 	}
+	if err := u.mask(ctx, req.DatasetID, infos); err != nil {
+		return nil, err
+	}
 
 	totalPage := int(math.Ceil(float64(total) / float64(req.Limit)))
 
@@ -113,11 +143,16 @@ func (u *dataRowUsecase) BulkCreate(ctx context.Context, req *domain.BulkCreateD
 		}
 	}
 
-	if err := u.repo.BulkCreate(ctx, rows); err != nil {
-		return fmt.Errorf("failed to bulk create data rows: %w", err)
-	}
-
-	return nil
+	return u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.repo.BulkCreate(ctx, rows); err != nil {
+			return fmt.Errorf("failed to bulk create data rows: %w", err)
+		}
+		return u.publish(ctx, domain.EventRowsImported, map[string]interface{}{
+			"dataset_id":  req.DatasetID,
+			"rows":        len(rows),
+			"imported_by": userID,
+		})
+	})
 }
 
 func (u *dataRowUsecase) Update(ctx context.Context, id string, req *domain.UpdateDataRowRequest) (*domain.DataRowInfo, error) {
@@ -139,7 +174,11 @@ func (u *dataRowUsecase) Update(ctx context.Context, id string, req *domain.Upda
 		return nil, fmt.Errorf("failed to update data row: %w", err)
 	}
 
-	return u.toInfo(existing), nil
+	infos := []domain.DataRowInfo{*u.toInfo(existing)}
+	if err := u.mask(ctx, existing.DatasetID, infos); err != nil {
+		return nil, err
+	}
+	return &infos[0], nil
 }
 
 func (u *dataRowUsecase) Delete(ctx context.Context, id string) error {
@@ -164,6 +203,87 @@ func (u *dataRowUsecase) GetStats(ctx context.Context, datasetID string) (*domai
 	return stats, nil
 }
 
+func (u *dataRowUsecase) GetMasks(ctx context.Context, datasetID string) (*domain.ColumnMasksResponse, error) {
+	masks, err := u.masks.ListMasks(ctx, datasetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get column masks: %w", err)
+	}
+	return &domain.ColumnMasksResponse{DatasetID: datasetID, Masks: masks}, nil
+}
+
+func (u *dataRowUsecase) UpdateMasks(ctx context.Context, datasetID string, req *domain.UpdateColumnMasksRequest) (*domain.ColumnMasksResponse, error) {
+	var masks []domain.ColumnMask
+	err := u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := u.masks.ListMasks(ctx, datasetID)
+		if err != nil {
+			return fmt.Errorf("failed to get column masks: %w", err)
+		}
+		created := make(map[string]time.Time, len(before))
+		for _, mask := range before {
+			created[mask.Column] = mask.CreatedAt
+		}
+
+		now := time.Now()
+		masks = make([]domain.ColumnMask, 0, len(req.Masks))
+		seen := make(map[string]bool, len(req.Masks))
+		for _, input := range req.Masks {
+			if seen[input.Column] {
+				return fmt.Errorf("%w: column %s is masked twice", pkgErrors.ErrInvalidInput, input.Column)
+			}
+			seen[input.Column] = true
+
+			mask := domain.ColumnMask{
+				DatasetID:  datasetID,
+				Column:     input.Column,
+				Strategy:   input.Strategy,
+				BucketSize: input.BucketSize,
+				CreatedAt:  now,
+				UpdatedAt:  now,
+			}
+			if createdAt, ok := created[input.Column]; ok {
+				mask.CreatedAt = createdAt
+			}
+			if mask.Strategy != domain.MaskBucket {
+				mask.BucketSize = 0
+			}
+			masks = append(masks, mask)
+		}
+		if err := u.masks.ReplaceMasks(ctx, datasetID, masks); err != nil {
+			return fmt.Errorf("failed to update column masks: %w", err)
+		}
+		u.audit.Record(ctx, "dataset_column_masks", datasetID, audit.ActionUpdate,
+			&domain.ColumnMasksResponse{DatasetID: datasetID, Masks: before},
+			&domain.ColumnMasksResponse{DatasetID: datasetID, Masks: masks})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.ColumnMasksResponse{DatasetID: datasetID, Masks: masks}, nil
+}
+
+// mask masks the sensitive columns of rows of the dataset, unless the
+// caller's role is privileged
+func (u *dataRowUsecase) mask(ctx context.Context, datasetID string, rows []domain.DataRowInfo) error {
+	role := auth.FromContext(ctx).RoleID
+	if len(rows) == 0 || u.masking.privileged(role) {
+		return nil
+	}
+
+	masks, err := u.masks.ListMasks(ctx, datasetID)
+	if err != nil {
+		return fmt.Errorf("failed to get column masks: %w", err)
+	}
+	if len(masks) == 0 {
+		return nil
+	}
+	for i := range rows {
+		rows[i].Data = u.masking.maskData(rows[i].Data, masks)
+	}
+	return nil
+}
+
 func (u *dataRowUsecase) toInfo(row *domain.DataRow) *domain.DataRowInfo {
 	return &domain.DataRowInfo{
 		ID:        row.ID,
@@ -175,3 +295,15 @@ func (u *dataRowUsecase) toInfo(row *domain.DataRow) *domain.DataRowInfo {
 		UpdatedAt: row.UpdatedAt,
 	}
 }
+
+// publish records an event in the outbox, in the transaction ctx carries,
+// so the event is emitted exactly when the change commits
+func (u *dataRowUsecase) publish(ctx context.Context, eventType string, data interface{}) error {
+	if u.events == nil {
+		return nil
+	}
+	if err := u.events.Publish(ctx, eventType, data); err != nil {
+		return fmt.Errorf("failed to publish %s event: %w", eventType, err)
+	}
+	return nil
+}
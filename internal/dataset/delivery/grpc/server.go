@@ -0,0 +1,130 @@
+// Package grpc serves datasets to internal services over gRPC
+package grpc
+
+import (
+	"context"
+
+	portalv1 "portal-data-backend/api/proto/portal/v1"
+	"portal-data-backend/internal/dataset/domain"
+	"portal-data-backend/internal/dataset/usecase"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Server implements portalv1.DatasetServiceServer
+type Server struct {
+	portalv1.UnimplementedDatasetServiceServer
+	datasets usecase.Usecase
+}
+
+// NewServer creates a dataset gRPC server
+func NewServer(datasets usecase.Usecase) *Server {
+	return &Server{datasets: datasets}
+}
+
+// GetDataset returns a dataset by ID or slug
+func (s *Server) GetDataset(ctx context.Context, req *portalv1.GetDatasetRequest) (*portalv1.Dataset, error) {
+	var (
+		dataset *domain.DatasetResponse
+		err     error
+	)
+	switch key := req.GetKey().(type) {
+	case *portalv1.GetDatasetRequest_Id:
+		dataset, err = s.datasets.GetByID(ctx, key.Id)
+	case *portalv1.GetDatasetRequest_Slug:
+		dataset, err = s.datasets.GetBySlug(ctx, key.Slug)
+	default:
+		return nil, status.Error(codes.InvalidArgument, "id or slug is required")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toDataset(dataset), nil
+}
+
+// ListDatasets returns a page of datasets, 20 by default like GET /datasets
+func (s *Server) ListDatasets(ctx context.Context, req *portalv1.ListDatasetsRequest) (*portalv1.ListDatasetsResponse, error) {
+	page, limit := int(req.GetPage()), int(req.GetLimit())
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+
+	resp, err := s.datasets.List(ctx, &domain.ListDatasetsRequest{
+		Page:             page,
+		Limit:            limit,
+		OrganizationID:   req.GetOrganizationId(),
+		TopicID:          req.GetTopicId(),
+		BusinessFieldID:  req.GetBusinessFieldId(),
+		TagID:            req.GetTagId(),
+		Status:           req.GetStatus(),
+		ValidationStatus: req.GetValidationStatus(),
+		Classification:   req.GetClassification(),
+		Search:           req.GetSearch(),
+		SortBy:           req.GetSortBy(),
+		SortOrder:        req.GetSortOrder(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	datasets := make([]*portalv1.Dataset, len(resp.Datasets))
+	for i := range resp.Datasets {
+		datasets[i] = toDataset(&resp.Datasets[i])
+	}
+	return &portalv1.ListDatasetsResponse{
+		Datasets: datasets,
+		Meta: &portalv1.PageMeta{
+			Page:      int32(resp.Meta.Page),
+			Limit:     int32(resp.Meta.Limit),
+			Total:     int32(resp.Meta.Total),
+			TotalPage: int32(resp.Meta.TotalPage),
+		},
+	}, nil
+}
+
+func toDataset(d *domain.DatasetResponse) *portalv1.Dataset {
+	dataset := &portalv1.Dataset{
+		Id:               d.ID,
+		Name:             d.Name,
+		Slug:             d.Slug,
+		Description:      value(d.Description),
+		Period:           domain.FormatPeriod(d.CoverageStart, d.CoverageEnd),
+		OrganizationId:   d.OrganizationID,
+		Classification:   d.Classification,
+		Category:         d.Category,
+		DataFixed:        d.DataFixed,
+		ValidationStatus: d.ValidationStatus,
+		Status:           d.Status,
+		IsHighlight:      d.IsHighlight,
+		Names:            d.Names,
+		Descriptions:     d.Descriptions,
+		CreatedBy:        d.CreatedBy,
+		CreatedAt:        timestamppb.New(d.CreatedAt),
+		UpdatedAt:        timestamppb.New(d.UpdatedAt),
+	}
+	if d.Unit != nil {
+		dataset.Unit = &portalv1.Unit{Id: d.Unit.ID, Name: d.Unit.Name, Symbol: d.Unit.Symbol}
+	}
+	if d.BusinessField != nil {
+		dataset.BusinessField = &portalv1.Reference{Id: d.BusinessField.ID, Name: d.BusinessField.Name, Slug: d.BusinessField.Slug}
+	}
+	if d.Topic != nil {
+		dataset.Topic = &portalv1.Reference{Id: d.Topic.ID, Name: d.Topic.Name, Slug: d.Topic.Slug}
+	}
+	for _, tag := range d.Tags {
+		dataset.Tags = append(dataset.Tags, &portalv1.Reference{Id: tag.ID, Name: tag.Name, Slug: tag.Slug})
+	}
+	return dataset
+}
+
+func value(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,95 @@
+package http
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/auth"
+	"portal-data-backend/infrastructure/http/httputil"
+	"portal-data-backend/infrastructure/http/response"
+	datasetDomain "portal-data-backend/internal/dataset/domain"
+)
+
+// caller returns the signed-in user making the request
+func (h *Handler) caller(r *http.Request) datasetDomain.Caller {
+	user := auth.FromContext(r.Context())
+	return datasetDomain.Caller{
+		UserID:         user.UserID,
+		OrganizationID: user.OrganizationID,
+		Admin:          user.HasRole(h.adminRoles...),
+	}
+}
+
+// requireRole lets through the owners of the dataset {id}, admins and the
+// collaborators granted role on it. It must run after auth.
+func (h *Handler) requireRole(role datasetDomain.CollaboratorRole) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id, ok := httputil.UUIDParam(w, r, "id")
+			if !ok {
+				return
+			}
+			if err := h.datasetUsecase.Authorize(r.Context(), id, h.caller(r), role); err != nil {
+				h.handleError(w, r, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ListCollaborators handles listing the collaborators of a dataset
+func (h *Handler) ListCollaborators(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	collaborators, err := h.datasetUsecase.ListCollaborators(r.Context(), id, h.caller(r))
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Collaborators retrieved successfully", collaborators)
+}
+
+// AddCollaborator handles granting a role on a dataset to a user or
+// organization
+func (h *Handler) AddCollaborator(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	req, ok := httputil.Decode[datasetDomain.AddCollaboratorRequest](w, r)
+	if !ok {
+		return
+	}
+
+	collaborator, err := h.datasetUsecase.AddCollaborator(r.Context(), id, req, h.caller(r))
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.Created(w, response.CodeCreated, "Collaborator added successfully", collaborator)
+}
+
+// RemoveCollaborator handles revoking a grant on a dataset
+func (h *Handler) RemoveCollaborator(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+	collaboratorID, ok := httputil.UUIDParam(w, r, "collaboratorId")
+	if !ok {
+		return
+	}
+
+	if err := h.datasetUsecase.RemoveCollaborator(r.Context(), id, collaboratorID, h.caller(r)); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Collaborator removed successfully", nil)
+}
@@ -1,48 +1,52 @@
 package http
 
 import (
-	"encoding/json"
-	"errors"
 	"net/http"
 	"strconv"
 
+	"portal-data-backend/infrastructure/auth"
 	datasetDomain "portal-data-backend/internal/dataset/domain"
 	"portal-data-backend/internal/dataset/usecase"
+	"portal-data-backend/infrastructure/http/bulk"
+	"portal-data-backend/infrastructure/http/httputil"
+	"portal-data-backend/infrastructure/http/middleware"
+	"portal-data-backend/infrastructure/http/problem"
 	"portal-data-backend/infrastructure/http/response"
+	roleDomain "portal-data-backend/internal/role/domain"
 	pkgErrors "portal-data-backend/pkg/errors"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/go-playground/validator/v10"
 )
 
 // Handler handles HTTP requests for dataset
 type Handler struct {
 	datasetUsecase usecase.Usecase
-	validator      *validator.Validate
+	adminRoles     []string
 }
 
-// NewHandler creates a new dataset handler
-func NewHandler(datasetUsecase usecase.Usecase) *Handler {
+// NewHandler creates a new dataset handler. Users with one of adminRoles
+// may write any dataset.
+func NewHandler(datasetUsecase usecase.Usecase, adminRoles []string) *Handler {
 	return &Handler{
 		datasetUsecase: datasetUsecase,
-		validator:      validator.New(),
+		adminRoles:     adminRoles,
 	}
 }
 
 // GetByID handles getting a dataset by ID
 func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Dataset ID is required", nil)
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
 	dataset, err := h.datasetUsecase.GetByID(r.Context(), id)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
+	middleware.SurrogateKeys(w, datasetDomain.SurrogateKey(dataset.ID))
 	response.OK(w, response.CodeSuccess, "Dataset retrieved successfully", dataset)
 }
 
@@ -56,10 +60,11 @@ func (h *Handler) GetBySlug(w http.ResponseWriter, r *http.Request) {
 
 	dataset, err := h.datasetUsecase.GetBySlug(r.Context(), slug)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
+	middleware.SurrogateKeys(w, datasetDomain.SurrogateKey(dataset.ID))
 	response.OK(w, response.CodeSuccess, "Dataset retrieved successfully", dataset)
 }
 
@@ -75,6 +80,10 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 		Status:           r.URL.Query().Get("status"),
 		ValidationStatus: r.URL.Query().Get("validation_status"),
 		Classification:   r.URL.Query().Get("classification"),
+		LinkStatus:       r.URL.Query().Get("link_status"),
+		CoverageFrom:     r.URL.Query().Get("coverage_from"),
+		CoverageTo:       r.URL.Query().Get("coverage_to"),
+		Frequency:        r.URL.Query().Get("frequency"),
 		Search:           r.URL.Query().Get("search"),
 		SortBy:           r.URL.Query().Get("sort_by"),
 		SortOrder:        r.URL.Query().Get("sort_order"),
@@ -82,7 +91,7 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 
 	resp, err := h.datasetUsecase.List(r.Context(), req)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -91,28 +100,22 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 
 // Create handles creating a new dataset
 func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
-	var req datasetDomain.CreateDatasetRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, response.CodeBadRequest, "Invalid request body", nil)
-		return
-	}
-
-	if err := h.validator.Struct(&req); err != nil {
-		response.ValidationError(w, response.CodeValidationFailed, "Validation failed", h.formatValidationErrors(err))
+	req, ok := httputil.Decode[datasetDomain.CreateDatasetRequest](w, r)
+	if !ok {
 		return
 	}
 
 	// Get creator ID and organization ID from context
-	creatorID, _ := r.Context().Value("user_id").(string)
-	orgID, _ := r.Context().Value("organization_id").(string)
+	creatorID := auth.UserID(r.Context())
+	orgID := auth.FromContext(r.Context()).OrganizationID
 	if orgID == "" {
 		response.BadRequest(w, response.CodeBadRequest, "Organization ID is required", nil)
 		return
 	}
 
-	dataset, err := h.datasetUsecase.Create(r.Context(), &req, creatorID, orgID)
+	dataset, err := h.datasetUsecase.Create(r.Context(), req, creatorID, orgID)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -121,29 +124,22 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 
 // Update handles updating a dataset
 func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Dataset ID is required", nil)
-		return
-	}
-
-	var req datasetDomain.UpdateDatasetRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, response.CodeBadRequest, "Invalid request body", nil)
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
-	if err := h.validator.Struct(&req); err != nil {
-		response.ValidationError(w, response.CodeValidationFailed, "Validation failed", h.formatValidationErrors(err))
+	req, ok := httputil.Decode[datasetDomain.UpdateDatasetRequest](w, r)
+	if !ok {
 		return
 	}
 
 	// Get updater ID from context
-	updaterID, _ := r.Context().Value("user_id").(string)
+	updaterID := auth.UserID(r.Context())
 
-	dataset, err := h.datasetUsecase.Update(r.Context(), id, &req, updaterID)
+	dataset, err := h.datasetUsecase.Update(r.Context(), id, req, updaterID)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -152,84 +148,213 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 
 // Delete handles deleting a dataset
 func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Dataset ID is required", nil)
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
 	if err := h.datasetUsecase.Delete(r.Context(), id); err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
 	response.OK(w, response.CodeSuccess, "Dataset deleted successfully", nil)
 }
 
+// Restore handles restoring a deleted dataset
+func (h *Handler) Restore(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.datasetUsecase.Restore(r.Context(), id); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Dataset restored successfully", nil)
+}
+
+// ListTrash handles listing the deleted datasets waiting to be purged
+func (h *Handler) ListTrash(w http.ResponseWriter, r *http.Request) {
+	req := &datasetDomain.ListTrashRequest{
+		Page:           parseIntQuery(r, "page", 1),
+		Limit:          parseIntQuery(r, "limit", 20),
+		OrganizationID: r.URL.Query().Get("organization_id"),
+	}
+
+	resp, err := h.datasetUsecase.ListTrash(r.Context(), req)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Trash retrieved successfully", resp)
+}
+
+// Quality handles the quality dashboard: how many datasets fail each lint
+// rule and their lint reports
+func (h *Handler) Quality(w http.ResponseWriter, r *http.Request) {
+	req := &datasetDomain.ListLintReportsRequest{
+		Page:           parseIntQuery(r, "page", 1),
+		Limit:          parseIntQuery(r, "limit", 20),
+		OrganizationID: r.URL.Query().Get("organization_id"),
+		Rule:           r.URL.Query().Get("rule"),
+	}
+
+	resp, err := h.datasetUsecase.Quality(r.Context(), req)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Quality report retrieved successfully", resp)
+}
+
 // UpdateStatus handles updating dataset status
 func (h *Handler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Dataset ID is required", nil)
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
 	var req struct {
 		Status datasetDomain.DatasetStatus `json:"status" validate:"required"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, response.CodeBadRequest, "Invalid request body", nil)
+	if !httputil.DecodeInto(w, r, &req) {
 		return
 	}
 
-	if err := h.validator.Struct(&req); err != nil {
-		response.ValidationError(w, response.CodeValidationFailed, "Validation failed", h.formatValidationErrors(err))
+	if err := h.datasetUsecase.UpdateStatus(r.Context(), id, req.Status); err != nil {
+		h.handleError(w, r, err)
 		return
 	}
 
-	if err := h.datasetUsecase.UpdateStatus(r.Context(), id, req.Status); err != nil {
-		h.handleError(w, err)
+	response.OK(w, response.CodeSuccess, "Dataset status updated successfully", nil)
+}
+
+// bulkStatusRequest updates the status of several datasets
+type bulkStatusRequest struct {
+	bulk.Request
+	Status datasetDomain.DatasetStatus `json:"status" validate:"required,oneof=draft published archived"`
+}
+
+// BulkUpdateStatus updates the status of several datasets in one
+// transaction, answering the outcome for each
+func (h *Handler) BulkUpdateStatus(w http.ResponseWriter, r *http.Request) {
+	req, ok := httputil.Decode[bulkStatusRequest](w, r)
+	if !ok {
 		return
 	}
 
-	response.OK(w, response.CodeSuccess, "Dataset status updated successfully", nil)
+	failed, err := h.datasetUsecase.BulkUpdateStatus(r.Context(), req.IDs, req.Status)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	bulk.Write(w, r, errorMapper, req.IDs, failed, "dataset statuses updated")
+}
+
+// ListHighlights handles listing the published highlighted datasets in
+// their order
+func (h *Handler) ListHighlights(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.datasetUsecase.ListHighlights(r.Context(), true)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Highlights retrieved successfully", resp)
+}
+
+// ListAllHighlights handles listing every highlighted dataset for curation
+func (h *Handler) ListAllHighlights(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.datasetUsecase.ListHighlights(r.Context(), false)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Highlights retrieved successfully", resp)
 }
 
-func (h *Handler) handleError(w http.ResponseWriter, err error) {
-	if err == nil {
+// AddHighlight handles highlighting a dataset
+func (h *Handler) AddHighlight(w http.ResponseWriter, r *http.Request) {
+	req, ok := httputil.Decode[datasetDomain.AddHighlightRequest](w, r)
+	if !ok {
 		return
 	}
 
-	switch {
-	case errors.Is(err, pkgErrors.ErrNotFound):
-		response.NotFound(w, response.CodeNotFound, "Dataset not found", nil)
-	default:
-		response.InternalError(w, response.CodeInternalServerError, "Internal server error", nil)
+	userID := auth.UserID(r.Context())
+
+	highlight, err := h.datasetUsecase.AddHighlight(r.Context(), req, userID)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
 	}
+
+	response.Created(w, response.CodeCreated, "Dataset highlighted successfully", highlight)
 }
 
-func (h *Handler) formatValidationErrors(err error) []response.ErrorDetail {
-	var details []response.ErrorDetail
-	if validationErrors, ok := err.(validator.ValidationErrors); ok {
-		for _, fieldErr := range validationErrors {
-			details = append(details, response.ErrorDetail{
-				Field:   fieldErr.Field(),
-				Message: h.getValidationErrorMessage(fieldErr),
-			})
-		}
+// UpdateHighlight handles changing when a highlight expires
+func (h *Handler) UpdateHighlight(w http.ResponseWriter, r *http.Request) {
+	datasetID, ok := httputil.UUIDParam(w, r, "datasetId")
+	if !ok {
+		return
 	}
-	return details
+
+	req, ok := httputil.Decode[datasetDomain.UpdateHighlightRequest](w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.datasetUsecase.UpdateHighlight(r.Context(), datasetID, req); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Highlight updated successfully", nil)
 }
 
-func (h *Handler) getValidationErrorMessage(fieldErr validator.FieldError) string {
-	switch fieldErr.Tag() {
-	case "required":
-		return fieldErr.Field() + " is required"
-	case "min":
-		return fieldErr.Field() + " must be at least " + fieldErr.Param() + " characters"
-	default:
-		return fieldErr.Field() + " is invalid"
+// RemoveHighlight handles no longer highlighting a dataset
+func (h *Handler) RemoveHighlight(w http.ResponseWriter, r *http.Request) {
+	datasetID, ok := httputil.UUIDParam(w, r, "datasetId")
+	if !ok {
+		return
+	}
+
+	if err := h.datasetUsecase.RemoveHighlight(r.Context(), datasetID); err != nil {
+		h.handleError(w, r, err)
+		return
 	}
+
+	response.OK(w, response.CodeSuccess, "Highlight removed successfully", nil)
+}
+
+// OrderHighlights handles reordering the highlighted datasets
+func (h *Handler) OrderHighlights(w http.ResponseWriter, r *http.Request) {
+	req, ok := httputil.Decode[datasetDomain.OrderHighlightsRequest](w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.datasetUsecase.OrderHighlights(r.Context(), req); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Highlights ordered successfully", nil)
+}
+
+// errorMapper maps the errors of the dataset module on top of the shared ones
+var errorMapper = problem.Default.With(
+	problem.Mapping{Err: pkgErrors.ErrNotFound, Status: http.StatusNotFound, Code: response.CodeNotFound, Message: "Dataset not found"},
+)
+
+func (h *Handler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	errorMapper.Write(w, r, err)
 }
 
 func parseIntQuery(r *http.Request, key string, defaultValue int) int {
@@ -241,15 +366,51 @@ func parseIntQuery(r *http.Request, key string, defaultValue int) int {
 	return defaultValue
 }
 
-// RegisterRoutes registers dataset routes
-func RegisterRoutes(r chi.Router, handler *Handler) {
+// RegisterRoutes registers dataset routes. Reads are public, cached by
+// cached and may expand relations; writes go through auth and require the
+// datasets:write permission, and the trash, the quality dashboard,
+// restoring, bulk updates and curating the highlights are left to users
+// with one of adminRoles. Writing a dataset also requires owning it or a
+// collaborator role on it: edit to update it, publish to change its status
+// or delete it.
+func RegisterRoutes(r chi.Router, handler *Handler, auth func(http.Handler) http.Handler, relations response.Relations, adminRoles []string, cached func(keys ...string) func(http.Handler) http.Handler) {
 	r.Route("/datasets", func(r chi.Router) {
-		r.Get("/", handler.List)
-		r.Post("/", handler.Create)
-		r.Get("/slug/{slug}", handler.GetBySlug)
-		r.Get("/{id}", handler.GetByID)
-		r.Put("/{id}", handler.Update)
-		r.Delete("/{id}", handler.Delete)
-		r.Patch("/{id}/status", handler.UpdateStatus)
+		shape := middleware.Shape(relations)
+		r.With(cached(datasetDomain.SurrogateKeyDatasets), shape).Get("/", handler.List)
+		r.With(cached(datasetDomain.SurrogateKeyDatasets)).Get("/highlights", handler.ListHighlights)
+		r.With(cached(), shape).Get("/slug/{slug}", handler.GetBySlug)
+		r.With(cached(), shape).Get("/{id}", handler.GetByID)
+
+		r.Group(func(r chi.Router) {
+			r.Use(auth)
+			r.Use(middleware.RequirePermission(roleDomain.PermissionDatasetsWrite))
+			r.Post("/", handler.Create)
+			r.With(handler.requireRole(datasetDomain.CollaboratorEdit)).Put("/{id}", handler.Update)
+			r.With(handler.requireRole(datasetDomain.CollaboratorPublish)).Delete("/{id}", handler.Delete)
+			r.With(middleware.RequireRole(adminRoles...)).Get("/trash", handler.ListTrash)
+			r.With(middleware.RequireRole(adminRoles...)).Get("/quality", handler.Quality)
+			r.With(middleware.RequireRole(adminRoles...)).Post("/{id}/restore", handler.Restore)
+			r.With(handler.requireRole(datasetDomain.CollaboratorPublish)).Patch("/{id}/status", handler.UpdateStatus)
+			r.With(middleware.RequireRole(adminRoles...)).Patch("/bulk-status", handler.BulkUpdateStatus)
+		})
+
+		// Owners manage the collaborators of their datasets, which may list
+		// them
+		r.Group(func(r chi.Router) {
+			r.Use(auth)
+			r.Get("/{id}/collaborators", handler.ListCollaborators)
+			r.With(middleware.RequirePermission(roleDomain.PermissionDatasetsWrite)).Post("/{id}/collaborators", handler.AddCollaborator)
+			r.With(middleware.RequirePermission(roleDomain.PermissionDatasetsWrite)).Delete("/{id}/collaborators/{collaboratorId}", handler.RemoveCollaborator)
+		})
+	})
+
+	r.Route("/admin/highlights", func(r chi.Router) {
+		r.Use(auth)
+		r.Use(middleware.RequireRole(adminRoles...))
+		r.Get("/", handler.ListAllHighlights)
+		r.Post("/", handler.AddHighlight)
+		r.Put("/order", handler.OrderHighlights)
+		r.Put("/{datasetId}", handler.UpdateHighlight)
+		r.Delete("/{datasetId}", handler.RemoveHighlight)
 	})
 }
@@ -0,0 +1,38 @@
+package http
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/bulk"
+	"portal-data-backend/infrastructure/http/openapi"
+	datasetDomain "portal-data-backend/internal/dataset/domain"
+)
+
+// Describe adds the dataset routes to the API specification
+func Describe(spec *openapi.Spec) {
+	api := spec.Tag("datasets", "Datasets and their metadata")
+	api.Get("/datasets", "List datasets").Public().Shaped("organization", "tags", "files").
+		Query(datasetDomain.ListDatasetsRequest{}).Param("include_deleted", false).
+		Returns(http.StatusOK, datasetDomain.DatasetListResponse{})
+	api.Post("/datasets", "Create dataset").Body(datasetDomain.CreateDatasetRequest{}).Returns(http.StatusCreated, datasetDomain.DatasetResponse{})
+	api.Get("/datasets/slug/{slug}", "Get dataset by slug").Public().Shaped("organization", "tags", "files").Param("include_deleted", false).Returns(http.StatusOK, datasetDomain.DatasetResponse{})
+	api.Get("/datasets/{id}", "Get dataset").Public().Shaped("organization", "tags", "files").Param("include_deleted", false).Returns(http.StatusOK, datasetDomain.DatasetResponse{})
+	api.Put("/datasets/{id}", "Update dataset").Body(datasetDomain.UpdateDatasetRequest{}).Returns(http.StatusOK, datasetDomain.DatasetResponse{})
+	api.Delete("/datasets/{id}", "Delete dataset").Returns(http.StatusOK, nil)
+	api.Get("/datasets/trash", "List deleted datasets").Query(datasetDomain.ListTrashRequest{}).Returns(http.StatusOK, datasetDomain.TrashListResponse{})
+	api.Get("/datasets/quality", "Get the metadata quality dashboard").Query(datasetDomain.ListLintReportsRequest{}).Returns(http.StatusOK, datasetDomain.QualityResponse{})
+	api.Post("/datasets/{id}/restore", "Restore deleted dataset").Returns(http.StatusOK, nil)
+	api.Patch("/datasets/{id}/status", "Update dataset status").Body(struct {
+		Status datasetDomain.DatasetStatus `json:"status" validate:"required"`
+	}{}).Returns(http.StatusOK, nil)
+	api.Get("/datasets/{id}/collaborators", "List dataset collaborators").Returns(http.StatusOK, []datasetDomain.Collaborator{})
+	api.Post("/datasets/{id}/collaborators", "Grant a role on a dataset").Body(datasetDomain.AddCollaboratorRequest{}).Returns(http.StatusCreated, datasetDomain.Collaborator{})
+	api.Delete("/datasets/{id}/collaborators/{collaboratorId}", "Revoke a role on a dataset").Returns(http.StatusOK, nil)
+	api.Patch("/datasets/bulk-status", "Update the status of several datasets").Body(bulkStatusRequest{}).Returns(http.StatusOK, bulk.Response{})
+	api.Get("/datasets/highlights", "List highlighted datasets").Public().Returns(http.StatusOK, datasetDomain.HighlightListResponse{})
+	api.Get("/admin/highlights", "List every highlighted dataset").Returns(http.StatusOK, datasetDomain.HighlightListResponse{})
+	api.Post("/admin/highlights", "Highlight dataset").Body(datasetDomain.AddHighlightRequest{}).Returns(http.StatusCreated, datasetDomain.HighlightResponse{})
+	api.Put("/admin/highlights/order", "Order highlighted datasets").Body(datasetDomain.OrderHighlightsRequest{}).Returns(http.StatusOK, nil)
+	api.Put("/admin/highlights/{datasetId}", "Update highlight").Body(datasetDomain.UpdateHighlightRequest{}).Returns(http.StatusOK, nil)
+	api.Delete("/admin/highlights/{datasetId}", "Remove highlight").Returns(http.StatusOK, nil)
+}
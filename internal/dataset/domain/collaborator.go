@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// CollaboratorRole is what a collaborator may do with a dataset. Each role
+// grants the ones before it: publish grants edit, which grants view.
+type CollaboratorRole string
+
+const (
+	CollaboratorView    CollaboratorRole = "view"
+	CollaboratorEdit    CollaboratorRole = "edit"
+	CollaboratorPublish CollaboratorRole = "publish"
+)
+
+// CollaboratorRoles lists the roles, each granting the ones before it
+var CollaboratorRoles = []CollaboratorRole{CollaboratorView, CollaboratorEdit, CollaboratorPublish}
+
+// Grants reports whether r grants role
+func (r CollaboratorRole) Grants(role CollaboratorRole) bool {
+	return r.rank() >= role.rank() && role.rank() > 0
+}
+
+func (r CollaboratorRole) rank() int {
+	for i, role := range CollaboratorRoles {
+		if r == role {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// GranteeType is who a dataset is shared with: a user, or every member of
+// an organization
+type GranteeType string
+
+const (
+	GranteeUser         GranteeType = "user"
+	GranteeOrganization GranteeType = "organization"
+)
+
+// Collaborator is a grant of Role on a dataset to a user or organization
+// other than its owners, the organization of the dataset and its creator
+type Collaborator struct {
+	ID          string           `db:"id" json:"id"`
+	DatasetID   string           `db:"dataset_id" json:"dataset_id"`
+	GranteeType GranteeType      `db:"grantee_type" json:"grantee_type"`
+	GranteeID   string           `db:"grantee_id" json:"grantee_id"`
+	Role        CollaboratorRole `db:"role" json:"role"`
+	GrantedBy   *string          `db:"granted_by" json:"granted_by,omitempty"`
+	CreatedAt   time.Time        `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time        `db:"updated_at" json:"updated_at"`
+}
+
+// AddCollaboratorRequest grants Role on a dataset to a user or organization,
+// replacing the role granted to them before
+type AddCollaboratorRequest struct {
+	GranteeType string `json:"grantee_type" validate:"required,oneof=user organization"`
+	GranteeID   string `json:"grantee_id" validate:"required,uuid"`
+	Role        string `json:"role" validate:"required,oneof=view edit publish"`
+}
+
+// Caller is the signed-in user acting on a dataset. Admins may do anything
+// with any dataset.
+type Caller struct {
+	UserID         string
+	OrganizationID string
+	Admin          bool
+}
+
+// Authorizer checks that a caller holds a role on a dataset. The modules
+// storing what belongs to a dataset, like its rows, files and
+// visualizations, check CollaboratorEdit with it before changing them.
+type Authorizer interface {
+	Authorize(ctx context.Context, id string, caller Caller, role CollaboratorRole) error
+}
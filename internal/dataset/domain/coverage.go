@@ -0,0 +1,118 @@
+package domain
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency is how often the data of a dataset is collected
+type Frequency string
+
+const (
+	FrequencyDaily      Frequency = "daily"
+	FrequencyWeekly     Frequency = "weekly"
+	FrequencyMonthly    Frequency = "monthly"
+	FrequencyQuarterly  Frequency = "quarterly"
+	FrequencySemiannual Frequency = "semiannual"
+	FrequencyAnnual     Frequency = "annual"
+	FrequencyIrregular  Frequency = "irregular"
+)
+
+// Frequencies lists the frequencies datasets may have
+var Frequencies = []Frequency{
+	FrequencyDaily, FrequencyWeekly, FrequencyMonthly, FrequencyQuarterly,
+	FrequencySemiannual, FrequencyAnnual, FrequencyIrregular,
+}
+
+// Valid reports whether f is one of Frequencies
+func (f Frequency) Valid() bool {
+	for _, frequency := range Frequencies {
+		if f == frequency {
+			return true
+		}
+	}
+	return false
+}
+
+// frequencyWords are the words periods name frequencies with
+var frequencyWords = map[string]Frequency{
+	"harian": FrequencyDaily, "daily": FrequencyDaily,
+	"mingguan": FrequencyWeekly, "weekly": FrequencyWeekly,
+	"bulanan": FrequencyMonthly, "monthly": FrequencyMonthly,
+	"triwulanan": FrequencyQuarterly, "quarterly": FrequencyQuarterly,
+	"semesteran": FrequencySemiannual, "semiannual": FrequencySemiannual,
+	"tahunan": FrequencyAnnual, "annual": FrequencyAnnual, "yearly": FrequencyAnnual,
+	"tidak tetap": FrequencyIrregular, "irregular": FrequencyIrregular,
+}
+
+// ParseFrequency reads the frequency a free text period names, like
+// "tahunan" or "monthly"
+func ParseFrequency(period string) (Frequency, bool) {
+	frequency, ok := frequencyWords[strings.ToLower(strings.TrimSpace(period))]
+	return frequency, ok
+}
+
+var (
+	// periodSeparator separates the ends of a period, like "2020 - 2023" or
+	// "2020 s.d. 2023"
+	periodSeparator = `\s*(?:-|–|—|/|s\.?\s?d\.?|sampai|to)\s*`
+	periodYears     = regexp.MustCompile(`(?i)^(?:tahun\s+)?(\d{4})(?:` + periodSeparator + `(\d{4}))?$`)
+	periodDates     = regexp.MustCompile(`(?i)^(\d{4}-\d{2}-\d{2})(?:` + periodSeparator + `(\d{4}-\d{2}-\d{2}))?$`)
+)
+
+// ParsePeriod reads the coverage of a free text period naming years or
+// dates, like "2020", "Tahun 2021", "2020 - 2023", "2020 s.d. 2023" or
+// "2020-01-01/2023-06-30". Years cover from January 1 to December 31. It
+// reports false for other periods, and for periods ending before they start.
+func ParsePeriod(period string) (start, end time.Time, ok bool) {
+	period = strings.TrimSpace(period)
+	if m := periodYears.FindStringSubmatch(period); m != nil {
+		first, _ := strconv.Atoi(m[1])
+		last := first
+		if m[2] != "" {
+			last, _ = strconv.Atoi(m[2])
+		}
+		start = time.Date(first, time.January, 1, 0, 0, 0, 0, time.UTC)
+		end = time.Date(last, time.December, 31, 0, 0, 0, 0, time.UTC)
+		return start, end, !end.Before(start)
+	}
+	if m := periodDates.FindStringSubmatch(period); m != nil {
+		if m[2] == "" {
+			m[2] = m[1]
+		}
+		var err error
+		if start, err = time.Parse(time.DateOnly, m[1]); err != nil {
+			return time.Time{}, time.Time{}, false
+		}
+		if end, err = time.Parse(time.DateOnly, m[2]); err != nil {
+			return time.Time{}, time.Time{}, false
+		}
+		return start, end, !end.Before(start)
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+// FormatPeriod writes a coverage of dates like 2006-01-02 as a period:
+// whole years like "2020-2023", other dates like "2020-01-01/2023-06-30",
+// with ".." for an open end. It is empty without coverage.
+func FormatPeriod(start, end *string) string {
+	if start == nil && end == nil {
+		return ""
+	}
+	if start != nil && end != nil && strings.HasSuffix(*start, "-01-01") && strings.HasSuffix(*end, "-12-31") {
+		first, last := strings.TrimSuffix(*start, "-01-01"), strings.TrimSuffix(*end, "-12-31")
+		if first == last {
+			return first
+		}
+		return first + "-" + last
+	}
+	date := func(value *string) string {
+		if value == nil {
+			return ".."
+		}
+		return *value
+	}
+	return date(start) + "/" + date(end)
+}
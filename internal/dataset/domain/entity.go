@@ -2,6 +2,11 @@ package domain
 
 import (
 	"time"
+
+	businessFieldDomain "portal-data-backend/internal/business_field/domain"
+	tagDomain "portal-data-backend/internal/tag/domain"
+	topicDomain "portal-data-backend/internal/topic/domain"
+	unitDomain "portal-data-backend/internal/unit/domain"
 )
 
 // Dataset represents a dataset entity
@@ -10,7 +15,11 @@ type Dataset struct {
 	Name              string        `db:"name" json:"name"`
 	Slug              string        `db:"slug" json:"slug"`
 	Description       *string       `db:"description" json:"description,omitempty"`
-	Period            *string       `db:"period" json:"period,omitempty"`
+	// CoverageStart and CoverageEnd bound the time span the data covers;
+	// either is nil when open
+	CoverageStart     *time.Time    `db:"coverage_start" json:"coverage_start,omitempty"`
+	CoverageEnd       *time.Time    `db:"coverage_end" json:"coverage_end,omitempty"`
+	Frequency         *Frequency    `db:"frequency" json:"frequency,omitempty"`
 	UnitID            *string       `db:"unit_id" json:"unit_id,omitempty"`
 	BusinessFieldID   *string       `db:"business_field_id" json:"business_field_id,omitempty"`
 	Image             *string       `db:"image" json:"image,omitempty"`
@@ -28,12 +37,17 @@ type Dataset struct {
 	UpdatedAt         time.Time     `db:"updated_at" json:"updated_at"`
 	IsHighlight       bool          `db:"is_highlight" json:"is_highlight"`
 	Status            DatasetStatus `db:"status" json:"status"`
+	Names             string        `db:"names" json:"names"`               // JSON object of names by language code
+	Descriptions      string        `db:"descriptions" json:"descriptions"` // JSON object of descriptions by language code
+	DeletedAt         *time.Time    `db:"deleted_at" json:"deleted_at,omitempty"`
+	SourceURL         *string       `db:"source_url" json:"source_url,omitempty"`
+	LinkStatus        *LinkStatus   `db:"link_status" json:"link_status,omitempty"` // nil until the source URL is checked
+	LinkCheckedAt     *time.Time    `db:"link_checked_at" json:"link_checked_at,omitempty"`
+	LinkError         *string       `db:"link_error" json:"link_error,omitempty"`
 
-	// Relations
-	Tags              []Tag         `json:"tags,omitempty"`
-	Unit              *Unit         `json:"unit,omitempty"`
-	BusinessField     *BusinessField `json:"business_field,omitempty"`
-	Topic             *Topic        `json:"topic,omitempty"`
+	// Relations. The tags, unit, business field and topic of a dataset are
+	// read from the modules owning them.
+	TagIDs            []string      `json:"tag_ids,omitempty"`
 	Organization      *OrganizationSummary `json:"organization,omitempty"`
 }
 
@@ -55,36 +69,50 @@ const (
 	DatasetStatusArchived  DatasetStatus = "archived"
 )
 
-// Tag represents a tag entity
-type Tag struct {
-	ID        string    `db:"id" json:"id"`
-	Name      string    `db:"name" json:"name"`
-	Slug      string    `db:"slug" json:"slug"`
-	CreatedAt time.Time `db:"created_at" json:"created_at"`
+// LinkStatus is the result of the last check of the source URL of a dataset
+type LinkStatus string
+
+const (
+	LinkStatusOK     LinkStatus = "ok"
+	LinkStatusBroken LinkStatus = "broken"
+)
+
+// LinkCheck is a source URL due for a check, with what its owner is told
+// when it breaks
+type LinkCheck struct {
+	DatasetID  string      `db:"id"`
+	Name       string      `db:"name"`
+	Slug       string      `db:"slug"`
+	SourceURL  string      `db:"source_url"`
+	CreatedBy  string      `db:"created_by"`
+	TenantID   string      `db:"tenant_id"`
+	LinkStatus *LinkStatus `db:"link_status"`
 }
 
-// Unit represents a unit of measurement
-type Unit struct {
-	ID          string    `db:"id" json:"id"`
-	Name        string    `db:"name" json:"name"`
-	Symbol      string    `db:"symbol" json:"symbol"`
-	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+// TrashedDataset is a deleted dataset in the trash, which can be restored
+// until PurgeAt
+type TrashedDataset struct {
+	ID             string    `db:"id" json:"id"`
+	Name           string    `db:"name" json:"name"`
+	Slug           string    `db:"slug" json:"slug"`
+	OrganizationID string    `db:"organization_id" json:"organization_id"`
+	Status         string    `db:"status" json:"status"`
+	DeletedAt      time.Time `db:"deleted_at" json:"deleted_at"`
+	PurgeAt        time.Time `db:"-" json:"purge_at"`
 }
 
-// BusinessField represents a business field
-type BusinessField struct {
-	ID        string    `db:"id" json:"id"`
-	Name      string    `db:"name" json:"name"`
-	Slug      string    `db:"slug" json:"slug"`
-	CreatedAt time.Time `db:"created_at" json:"created_at"`
+// ListTrashRequest represents list trash input
+type ListTrashRequest struct {
+	Page           int    `json:"page" validate:"min=1"`
+	Limit          int    `json:"limit" validate:"min=1,max=100"`
+	OrganizationID string `json:"organization_id,omitempty"`
 }
 
-// Topic represents a topic
-type Topic struct {
-	ID        string    `db:"id" json:"id"`
-	Name      string    `db:"name" json:"name"`
-	Slug      string    `db:"slug" json:"slug"`
-	CreatedAt time.Time `db:"created_at" json:"created_at"`
+// TrashListResponse represents a page of the deleted datasets, the most
+// recently deleted first
+type TrashListResponse struct {
+	Datasets []TrashedDataset `json:"datasets"`
+	Meta     ListMeta         `json:"meta"`
 }
 
 // OrganizationSummary represents a summary of organization
@@ -98,12 +126,15 @@ type OrganizationSummary struct {
 type CreateDatasetRequest struct {
 	Name            string   `json:"name" validate:"required,min=2"`
 	Description     string   `json:"description,omitempty"`
-	Period          string   `json:"period,omitempty"`
+	CoverageStart   string   `json:"coverage_start,omitempty"` // date like 2006-01-02
+	CoverageEnd     string   `json:"coverage_end,omitempty"`   // date like 2006-01-02, not before CoverageStart
+	Frequency       string   `json:"frequency,omitempty"`
 	UnitID          string   `json:"unit_id,omitempty"`
 	BusinessFieldID string   `json:"business_field_id,omitempty"`
 	Image           string   `json:"image,omitempty"`
 	TopicID         string   `json:"topic_id,omitempty"`
 	ReferenceID     string   `json:"reference_id,omitempty"`
+	SourceURL       string   `json:"source_url,omitempty"` // absolute http or https URL of the source
 	Classification  string   `json:"classification" validate:"required"`
 	Category        string   `json:"category" validate:"required"`
 	DataFixed       bool     `json:"data_fixed"`
@@ -111,18 +142,23 @@ type CreateDatasetRequest struct {
 	Metadata        string   `json:"metadatas,omitempty"`
 	TagIDs          []string `json:"tag_ids,omitempty"`
 	IsHighlight     bool     `json:"is_highlight"`
+	Names           map[string]string `json:"names,omitempty"`
+	Descriptions    map[string]string `json:"descriptions,omitempty"`
 }
 
 // UpdateDatasetRequest represents dataset update input
 type UpdateDatasetRequest struct {
 	Name            string   `json:"name" validate:"required,min=2"`
 	Description     string   `json:"description,omitempty"`
-	Period          string   `json:"period,omitempty"`
+	CoverageStart   string   `json:"coverage_start,omitempty"` // date like 2006-01-02
+	CoverageEnd     string   `json:"coverage_end,omitempty"`   // date like 2006-01-02, not before CoverageStart
+	Frequency       string   `json:"frequency,omitempty"`
 	UnitID          string   `json:"unit_id,omitempty"`
 	BusinessFieldID string   `json:"business_field_id,omitempty"`
 	Image           string   `json:"image,omitempty"`
 	TopicID         string   `json:"topic_id,omitempty"`
 	ReferenceID     string   `json:"reference_id,omitempty"`
+	SourceURL       string   `json:"source_url,omitempty"` // absolute http or https URL of the source
 	Classification  string   `json:"classification" validate:"required"`
 	Category        string   `json:"category" validate:"required"`
 	DataFixed       bool     `json:"data_fixed"`
@@ -130,6 +166,8 @@ type UpdateDatasetRequest struct {
 	Metadata        string   `json:"metadatas,omitempty"`
 	TagIDs          []string `json:"tag_ids,omitempty"`
 	IsHighlight     bool     `json:"is_highlight"`
+	Names           map[string]string `json:"names,omitempty"`
+	Descriptions    map[string]string `json:"descriptions,omitempty"`
 }
 
 // ListDatasetsRequest represents list datasets input
@@ -143,22 +181,31 @@ type ListDatasetsRequest struct {
 	Status          string `json:"status,omitempty"`
 	ValidationStatus string `json:"validation_status,omitempty"`
 	Classification  string `json:"classification,omitempty"`
+	LinkStatus      string `json:"link_status,omitempty"`
+	// CoverageFrom and CoverageTo, dates like 2006-01-02, keep the datasets
+	// whose coverage overlaps them
+	CoverageFrom    string `json:"coverage_from,omitempty"`
+	CoverageTo      string `json:"coverage_to,omitempty"`
+	Frequency       string `json:"frequency,omitempty"`
 	Search          string `json:"search,omitempty"`
 	SortBy          string `json:"sort_by,omitempty"`
 	SortOrder       string `json:"sort_order,omitempty"`
 }
 
-// DatasetResponse represents dataset response
+// DatasetResponse represents dataset response. Read endpoints return name
+// and description in the language the client prefers when the dataset has it.
 type DatasetResponse struct {
 	ID               string              `json:"id"`
 	Name             string              `json:"name"`
 	Slug             string              `json:"slug"`
 	Description      *string             `json:"description,omitempty"`
-	Period           *string             `json:"period,omitempty"`
-	Unit             *Unit               `json:"unit,omitempty"`
-	BusinessField    *BusinessField      `json:"business_field,omitempty"`
+	CoverageStart    *string             `json:"coverage_start,omitempty"` // date like 2006-01-02
+	CoverageEnd      *string             `json:"coverage_end,omitempty"`
+	Frequency        *Frequency          `json:"frequency,omitempty"`
+	Unit             *unitDomain.UnitResponse `json:"unit,omitempty"`
+	BusinessField    *businessFieldDomain.BusinessFieldResponse `json:"business_field,omitempty"`
 	Image            *string             `json:"image,omitempty"`
-	Topic            *Topic              `json:"topic,omitempty"`
+	Topic            *topicDomain.TopicResponse `json:"topic,omitempty"`
 	OrganizationID   string              `json:"organization_id"`
 	ReferenceID      *string             `json:"reference_id,omitempty"`
 	Classification   string              `json:"classification"`
@@ -172,13 +219,85 @@ type DatasetResponse struct {
 	UpdatedAt        time.Time           `json:"updated_at"`
 	IsHighlight      bool                `json:"is_highlight"`
 	Status           string              `json:"status"`
-	Tags             []Tag               `json:"tags,omitempty"`
+	Tags             []tagDomain.TagResponse `json:"tags,omitempty"`
+	Names            map[string]string   `json:"names"`
+	Descriptions     map[string]string   `json:"descriptions"`
+	// DeletedAt is set on deleted datasets, which only admins list
+	DeletedAt        *time.Time          `json:"deleted_at,omitempty"`
+	SourceURL        *string             `json:"source_url,omitempty"`
+	// LinkStatus, LinkCheckedAt and LinkError report the last check of the
+	// source URL
+	LinkStatus       *LinkStatus         `json:"link_status,omitempty"`
+	LinkCheckedAt    *time.Time          `json:"link_checked_at,omitempty"`
+	LinkError        *string             `json:"link_error,omitempty"`
+	// LintWarnings are the metadata checks the dataset fails, returned when
+	// it is created or updated
+	LintWarnings     []LintWarning       `json:"lint_warnings,omitempty"`
 }
 
 // DatasetListResponse represents paginated dataset list
 type DatasetListResponse struct {
-	Datasets []DatasetResponse `json:"datasets"`
-	Meta     ListMeta          `json:"meta"`
+	Datasets    []DatasetResponse  `json:"datasets"`
+	Meta        ListMeta           `json:"meta"`
+	Suggestions *SearchSuggestions `json:"suggestions,omitempty"` // set when a search finds few datasets
+}
+
+// SearchSuggestions represents "did you mean" hints for a search with few
+// results: corrected queries and datasets with a similar title
+type SearchSuggestions struct {
+	Queries  []string            `json:"queries"`
+	Datasets []DatasetSuggestion `json:"datasets"`
+}
+
+// DatasetSuggestion represents a dataset whose title is close to a search
+type DatasetSuggestion struct {
+	ID         string  `db:"id" json:"id"`
+	Name       string  `db:"name" json:"name"`
+	Slug       string  `db:"slug" json:"slug"`
+	Similarity float64 `db:"similarity" json:"similarity"`
+}
+
+// Highlight is the place of a dataset among those highlighted on the
+// homepage, in ascending Position. Highlights are removed once ExpiresAt
+// passes.
+type Highlight struct {
+	DatasetID string     `db:"dataset_id" json:"dataset_id"`
+	Position  int        `db:"position" json:"position"`
+	ExpiresAt *time.Time `db:"expires_at" json:"expires_at,omitempty"`
+	CreatedBy *string    `db:"created_by" json:"created_by,omitempty"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+}
+
+// AddHighlightRequest represents highlight dataset input. Without ExpiresAt
+// the highlight expires after the configured default, if any.
+type AddHighlightRequest struct {
+	DatasetID string     `json:"dataset_id" validate:"required,uuid"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// UpdateHighlightRequest represents highlight update input. A missing
+// ExpiresAt keeps the highlight until it is removed.
+type UpdateHighlightRequest struct {
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// OrderHighlightsRequest represents the order of the highlighted datasets,
+// first shown first. It lists every highlighted dataset.
+type OrderHighlightsRequest struct {
+	DatasetIDs []string `json:"dataset_ids" validate:"required,min=1,dive,uuid"`
+}
+
+// HighlightResponse represents a highlighted dataset
+type HighlightResponse struct {
+	Highlight
+	Dataset DatasetResponse `json:"dataset"`
+}
+
+// HighlightListResponse represents the highlighted datasets in their order,
+// and how many may be highlighted at a time
+type HighlightListResponse struct {
+	Highlights []HighlightResponse `json:"highlights"`
+	Max        int                 `json:"max"`
 }
 
 // ListMeta represents pagination metadata
@@ -0,0 +1,82 @@
+package domain
+
+import "time"
+
+// LintRule is a check of the metadata of datasets
+type LintRule string
+
+const (
+	LintMissingDescription  LintRule = "missing_description"
+	LintNoTags              LintRule = "no_tags"
+	LintNoLicense           LintRule = "no_license"
+	LintStalePeriod         LintRule = "stale_period"
+	LintNonDescriptiveTitle LintRule = "non_descriptive_title"
+)
+
+// LintRules lists the checks datasets are linted with
+var LintRules = []LintRule{
+	LintMissingDescription, LintNoTags, LintNoLicense, LintStalePeriod, LintNonDescriptiveTitle,
+}
+
+// Valid reports whether r is one of LintRules
+func (r LintRule) Valid() bool {
+	for _, rule := range LintRules {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// LintWarning is a check the metadata of a dataset fails, on Field. Warnings
+// do not keep datasets from being saved.
+type LintWarning struct {
+	Rule    LintRule `json:"rule"`
+	Field   string   `json:"field"`
+	Message string   `json:"message"`
+}
+
+// LintReport is the lint of a dataset when it was last saved, kept for the
+// quality dashboard
+type LintReport struct {
+	DatasetID      string    `db:"dataset_id"`
+	Name           string    `db:"name"`
+	Slug           string    `db:"slug"`
+	OrganizationID string    `db:"organization_id"`
+	Warnings       string    `db:"warnings"` // JSON of the warnings
+	WarningCount   int       `db:"warning_count"`
+	CheckedAt      time.Time `db:"checked_at"`
+}
+
+// LintReportFilter keeps the lint reports of the datasets of
+// OrganizationID, and those failing Rule, when they are set
+type LintReportFilter struct {
+	OrganizationID string
+	Rule           string
+}
+
+// ListLintReportsRequest represents list lint reports input
+type ListLintReportsRequest struct {
+	Page           int    `json:"page" validate:"min=1"`
+	Limit          int    `json:"limit" validate:"min=1,max=100"`
+	OrganizationID string `json:"organization_id,omitempty"`
+	Rule           string `json:"rule,omitempty"`
+}
+
+// LintReportResponse represents the lint of a dataset
+type LintReportResponse struct {
+	DatasetID      string        `json:"dataset_id"`
+	Name           string        `json:"name"`
+	Slug           string        `json:"slug"`
+	OrganizationID string        `json:"organization_id"`
+	Warnings       []LintWarning `json:"warnings"`
+	CheckedAt      time.Time     `json:"checked_at"`
+}
+
+// QualityResponse represents the quality dashboard: how many datasets fail
+// each rule, and a page of lint reports, those with the most warnings first
+type QualityResponse struct {
+	Rules   map[LintRule]int     `json:"rules"`
+	Reports []LintReportResponse `json:"reports"`
+	Meta    ListMeta             `json:"meta"`
+}
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"time"
 )
 
 // Repository defines the interface for dataset data operations
@@ -9,6 +10,14 @@ type Repository interface {
 	// GetByID retrieves a dataset by ID
 	GetByID(ctx context.Context, id string) (*Dataset, error)
 
+	// GetByIDs retrieves the datasets with ids, without their tag IDs, in no
+	// particular order. IDs of no dataset are left out.
+	GetByIDs(ctx context.Context, ids []string) ([]*Dataset, error)
+
+	// TagIDsByDatasetIDs retrieves the IDs of the tags of the datasets with
+	// ids by dataset
+	TagIDsByDatasetIDs(ctx context.Context, ids []string) (map[string][]string, error)
+
 	// GetBySlug retrieves a dataset by slug
 	GetBySlug(ctx context.Context, slug string) (*Dataset, error)
 
@@ -24,11 +33,109 @@ type Repository interface {
 	// Delete soft deletes a dataset
 	Delete(ctx context.Context, id string) error
 
+	// Restore brings back a soft deleted dataset
+	Restore(ctx context.Context, id string) error
+
+	// Trash retrieves the deleted datasets, of the organization orgID unless
+	// it is empty, the most recently deleted first
+	Trash(ctx context.Context, orgID string, limit, offset int) ([]*TrashedDataset, int, error)
+
+	// DeletedBefore retrieves the IDs of up to limit datasets of every tenant
+	// deleted before before, the longest deleted first
+	DeletedBefore(ctx context.Context, before time.Time, limit int) ([]string, error)
+
+	// Purge deletes a deleted dataset for good, with its data rows. It
+	// returns ErrNotFound when the dataset does not exist or is not deleted.
+	Purge(ctx context.Context, id string) error
+
 	// UpdateStatus updates dataset status
 	UpdateStatus(ctx context.Context, id string, status DatasetStatus) error
 
+	// Transfer moves a dataset that is not deleted to the organization orgID
+	Transfer(ctx context.Context, id, orgID, updaterID string) error
+
+	// SetValidationStatus sets the validation status of a dataset that is
+	// not deleted
+	SetValidationStatus(ctx context.Context, id string, status ValidationStatus, updaterID string) error
+
+	// SetImage sets the image of a dataset that has none or still has
+	// previous, reporting whether it did
+	SetImage(ctx context.Context, id, image, previous string) (bool, error)
+
+	// Highlights retrieves the highlights of datasets that are not deleted, in
+	// their order
+	Highlights(ctx context.Context) ([]*Highlight, error)
+
+	// AddHighlight highlights a dataset after the others, setting the
+	// position of highlight. It returns ErrAlreadyExists when the dataset is
+	// highlighted already.
+	AddHighlight(ctx context.Context, highlight *Highlight) error
+
+	// SetHighlightExpiry sets when the highlight of a dataset expires
+	SetHighlightExpiry(ctx context.Context, datasetID string, expiresAt *time.Time) error
+
+	// RemoveHighlight stops highlighting a dataset
+	RemoveHighlight(ctx context.Context, datasetID string) error
+
+	// OrderHighlights numbers the highlights of datasetIDs in their order
+	OrderHighlights(ctx context.Context, datasetIDs []string) error
+
+	// ExpireHighlights removes the highlights of every tenant that expired by
+	// now, returning the tenant of each of their datasets by dataset ID
+	ExpireHighlights(ctx context.Context, now time.Time) (map[string]string, error)
+
+	// LinksToCheck retrieves up to limit source URLs of datasets of every
+	// tenant that were not checked since checkedBefore, the least recently
+	// checked first
+	LinksToCheck(ctx context.Context, checkedBefore time.Time, limit int) ([]*LinkCheck, error)
+
+	// SetLinkStatus records the result of a check of the source URL of a
+	// dataset. linkError is nil when the link works.
+	SetLinkStatus(ctx context.Context, id string, status LinkStatus, linkError *string, checkedAt time.Time) error
+
+	// SaveLintReport stores the lint report of a dataset, replacing the
+	// previous one
+	SaveLintReport(ctx context.Context, report *LintReport) error
+
+	// LintReports retrieves the lint reports of datasets that are not
+	// deleted matching filter, those with the most warnings first
+	LintReports(ctx context.Context, filter *LintReportFilter, limit, offset int) ([]*LintReport, int, error)
+
+	// LintRuleCounts counts the datasets that are not deleted failing each
+	// lint rule, of the organization orgID unless it is empty
+	LintRuleCounts(ctx context.Context, orgID string) (map[string]int, error)
+
+	// ListCollaborators retrieves the collaborators of a dataset, those
+	// granted first first
+	ListCollaborators(ctx context.Context, datasetID string) ([]*Collaborator, error)
+
+	// SaveCollaborator grants a role on a dataset to its grantee, replacing
+	// the role granted to them before, and sets the stored ID and times on
+	// collaborator
+	SaveCollaborator(ctx context.Context, collaborator *Collaborator) error
+
+	// DeleteCollaborator revokes the grant id on a dataset
+	DeleteCollaborator(ctx context.Context, datasetID, id string) error
+
+	// CollaboratorRoles retrieves the roles a dataset grants the user userID,
+	// directly or to their organization orgID
+	CollaboratorRoles(ctx context.Context, datasetID, userID, orgID string) ([]CollaboratorRole, error)
+
 	// GetByOrganizationID retrieves datasets by organization ID
 	GetByOrganizationID(ctx context.Context, orgID string, limit, offset int) ([]*Dataset, int, error)
+
+	// SimilarTitles retrieves datasets matching filter whose name is close to
+	// text, most similar first. The Search of filter is ignored.
+	SimilarTitles(ctx context.Context, filter *DatasetFilter, text string, limit int) ([]DatasetSuggestion, error)
+
+	// SimilarNames retrieves datasets matching filter whose whole name is
+	// close to name, most similar first. The Search of filter is ignored.
+	SimilarNames(ctx context.Context, filter *DatasetFilter, name string, limit int) ([]DatasetSuggestion, error)
+
+	// SimilarWords retrieves words of the names of datasets matching filter
+	// that are spelled close to word, most similar first. The Search of filter
+	// is ignored.
+	SimilarWords(ctx context.Context, filter *DatasetFilter, word string, limit int) ([]string, error)
 }
 
 // DatasetFilter represents filter options for listing datasets
@@ -40,5 +147,47 @@ type DatasetFilter struct {
 	Status           string
 	ValidationStatus string
 	Classification   string
+	LinkStatus       string
+	// CoverageFrom and CoverageTo keep the datasets whose coverage overlaps
+	// them, when they are set
+	CoverageFrom     *time.Time
+	CoverageTo       *time.Time
+	Frequency        string
 	Search           string
 }
+
+// Searcher finds datasets in a search index. It returns the IDs of a page of
+// datasets matching filter, best match first, and the number of matches.
+type Searcher interface {
+	Search(ctx context.Context, filter *DatasetFilter, limit, offset int) ([]string, int, error)
+}
+
+// OrganizationCounter keeps the dataset counters of organizations
+type OrganizationCounter interface {
+	IncrementDatasetCount(ctx context.Context, id string, isPublic bool) error
+	DecrementDatasetCount(ctx context.Context, id string, isPublic bool) error
+}
+
+// EventPublisher emits dataset lifecycle events to interested integrations
+type EventPublisher interface {
+	Publish(ctx context.Context, eventType string, data interface{}) error
+}
+
+// Dataset lifecycle event types
+const (
+	EventDatasetCreated       = "dataset.created"
+	EventDatasetUpdated       = "dataset.updated"
+	EventDatasetDeleted       = "dataset.deleted"
+	EventDatasetRestored      = "dataset.restored"
+	EventDatasetStatusChanged = "dataset.status_changed"
+	EventDatasetPublished     = "dataset.published"
+	EventDatasetPendingReview = "dataset.pending_review" // validation status moved to pending
+)
+
+// SurrogateKeyDatasets tags cached responses listing datasets
+const SurrogateKeyDatasets = "datasets"
+
+// SurrogateKey tags cached responses showing the dataset of id
+func SurrogateKey(id string) string {
+	return "dataset:" + id
+}
@@ -0,0 +1,125 @@
+// Package dataset is the module managing datasets and their metadata.
+package dataset
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"portal-data-backend/infrastructure/cache"
+	"portal-data-backend/infrastructure/http/middleware"
+	"portal-data-backend/infrastructure/http/openapi"
+	"portal-data-backend/infrastructure/http/response"
+	portalv1 "portal-data-backend/api/proto/portal/v1"
+	"portal-data-backend/internal/app"
+	datasetgrpc "portal-data-backend/internal/dataset/delivery/grpc"
+	delivery "portal-data-backend/internal/dataset/delivery/http"
+	"portal-data-backend/internal/dataset/repository"
+	"portal-data-backend/internal/dataset/usecase"
+	orgDomain "portal-data-backend/internal/organization/domain"
+
+	"github.com/go-chi/chi/v5"
+	"google.golang.org/grpc"
+)
+
+// Module manages datasets and provides them to other modules
+type Module struct {
+	handler    *delivery.Handler
+	server     *datasetgrpc.Server
+	relations  response.Relations
+	adminRoles []string
+	responses  *cache.Namespace
+	surrogates *cache.Surrogates
+	usecase    usecase.Usecase
+}
+
+// Name implements app.Module
+func (m *Module) Name() string {
+	return "dataset"
+}
+
+// Register implements app.Module
+func (m *Module) Register(deps *app.Deps) error {
+	if deps.Services.OrganizationCounters == nil {
+		return app.MissingServiceError("organization counters")
+	}
+	if deps.Services.Organizations == nil {
+		return app.MissingServiceError("organization")
+	}
+	if deps.Services.Files == nil {
+		return app.MissingServiceError("file")
+	}
+	if deps.Services.Users == nil {
+		return app.MissingServiceError("user")
+	}
+	if deps.Services.Templates == nil {
+		return app.MissingServiceError("message template")
+	}
+	switch {
+	case deps.Services.Tags == nil:
+		return app.MissingServiceError("tag")
+	case deps.Services.Units == nil:
+		return app.MissingServiceError("unit")
+	case deps.Services.BusinessFields == nil:
+		return app.MissingServiceError("business field")
+	case deps.Services.Topics == nil:
+		return app.MissingServiceError("topic")
+	}
+
+	repo := repository.NewDatasetPostgresRepository(deps.DBRouter)
+	m.responses = deps.Cache.Namespace("responses", deps.Config.Cache.ResponseTTL)
+	m.surrogates = deps.Cache.Surrogates(deps.Config.Cache.ResponseTTL)
+	datasets := usecase.NewDatasetUsecase(repo, deps.Services.OrganizationCounters, deps.Tx, deps.Outbox, deps.Services.DatasetSearcher,
+		deps.Cache.Namespace("datasets", deps.Config.Cache.DatasetTTL), m.surrogates, deps.Services.Audit, deps.Config.Highlight,
+		deps.Services.Notifications, deps.Services.Templates, deps.Config.LinkCheck, deps.Services.Files, deps.Config.Trash,
+		usecase.Taxonomies{
+			Tags:           deps.Services.Tags,
+			Units:          deps.Services.Units,
+			BusinessFields: deps.Services.BusinessFields,
+			Topics:         deps.Services.Topics,
+		},
+		usecase.Grantees{Users: deps.Services.Users, Organizations: deps.Services.Organizations})
+	deps.Services.Templates.Define(usecase.Messages...)
+	deps.Services.Datasets = datasets
+	m.usecase = datasets
+	m.handler = delivery.NewHandler(datasets, deps.Config.Audit.AdminRoles)
+	m.server = datasetgrpc.NewServer(datasets)
+	m.relations = response.Relations{
+		"organization": {
+			Key: "organization_id",
+			Load: response.LoadBy(deps.Services.Organizations.GetByIDs, func(org *orgDomain.OrganizationResponse) string {
+				return org.ID
+			}),
+		},
+		"tags":  {Key: "id", Many: true, Load: response.LoadGrouped(datasets.TagsByDatasetIDs)},
+		"files": {Key: "id", Many: true, Load: response.LoadGrouped(deps.Services.Files.GetByDatasetIDs)},
+	}
+	m.adminRoles = deps.Config.Audit.AdminRoles
+	return nil
+}
+
+// Routes implements app.Module
+func (m *Module) Routes(r chi.Router, auth func(http.Handler) http.Handler) {
+	delivery.RegisterRoutes(r, m.handler, auth, m.relations, m.adminRoles, middleware.ResponseCache(m.responses, m.surrogates))
+}
+
+// Describe implements app.Module
+func (m *Module) Describe(spec *openapi.Spec) {
+	delivery.Describe(spec)
+}
+
+// Run implements app.Runner
+func (m *Module) Run(ctx context.Context) {
+	go m.usecase.RunLinkChecks(ctx)
+	m.usecase.Run(ctx)
+}
+
+// Purge implements app.Purger
+func (m *Module) Purge(ctx context.Context, before time.Time) (int64, error) {
+	return m.usecase.PurgeDeleted(ctx, before)
+}
+
+// RegisterGRPC implements app.GRPCRegistrar
+func (m *Module) RegisterGRPC(s grpc.ServiceRegistrar) {
+	portalv1.RegisterDatasetServiceServer(s, m.server)
+}
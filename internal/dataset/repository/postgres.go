@@ -5,94 +5,168 @@ import (
 	"database/sql"
 	"fmt"
 	"strings"
+	"time"
 
+	"portal-data-backend/infrastructure/db"
 	"portal-data-backend/internal/dataset/domain"
 	"portal-data-backend/pkg/errors"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
-// datasetPostgresRepository implements Repository for PostgreSQL
+// datasetPostgresRepository implements Repository for PostgreSQL. The public
+// reads, GetBySlug, GetByIDs, TagIDsByDatasetIDs, List and the suggestions, go
+// to read replicas and may lag briefly behind writes.
 type datasetPostgresRepository struct {
-	db *sqlx.DB
+	db *db.Router
 }
 
 // NewDatasetPostgresRepository creates a new dataset repository
-func NewDatasetPostgresRepository(db *sqlx.DB) domain.Repository {
-	return &datasetPostgresRepository{db: db}
+func NewDatasetPostgresRepository(router *db.Router) domain.Repository {
+	return &datasetPostgresRepository{db: router}
 }
 
 func (r *datasetPostgresRepository) GetByID(ctx context.Context, id string) (*domain.Dataset, error) {
-	query := `
+	query := fmt.Sprintf(`
 		SELECT
-			d.id, d.name, d.slug, d.description, d.period, d.unit_id, d.business_field_id,
+			d.id, d.name, d.slug, d.description, d.coverage_start, d.coverage_end, d.frequency,
+			d.unit_id, d.business_field_id,
 			d.image, d.topic_id, d.organization_id, d.reference_id, d.classification,
 			d.category, d.data_fixed, d.validation_status, d.metadatas, d.created_by,
 			d.updated_by, d.created_at, d.updated_at, d.is_highlight, d.status,
-			o.id as org_id, o.name as org_name, o.slug as org_slug,
-			u.id as unit_id, u.name as unit_name, u.symbol as unit_symbol,
-			bf.id as bf_id, bf.name as bf_name, bf.slug as bf_slug,
-			t.id as topic_id, t.name as topic_name, t.slug as topic_slug
+			d.names, d.descriptions, d.deleted_at,
+			d.source_url, d.link_status, d.link_checked_at, d.link_error,
+			o.name as org_name, o.slug as org_slug
 		FROM datasets d
 		LEFT JOIN organizations o ON d.organization_id = o.id
-		LEFT JOIN units u ON d.unit_id = u.id
-		LEFT JOIN business_fields bf ON d.business_field_id = bf.id
-		LEFT JOIN topics t ON d.topic_id = t.id
-		WHERE d.id = $1
-	`
+		WHERE d.id = $1 AND %s AND %s
+	`, db.NotDeleted(ctx, "d.deleted_at"), db.InTenantOrganizations(ctx, "d.organization_id"))
 
-	dataset, err := r.scanDataset(ctx, query, id)
+	conn := r.db.Write(ctx)
+	dataset, err := r.scanDataset(ctx, conn, query, id)
 	if err != nil {
 		return nil, err
 	}
 
-	// Get tags
-	tags, err := r.getTagsByDatasetID(ctx, id)
+	tagIDs, err := r.getTagIDsByDatasetID(ctx, conn, id)
 	if err == nil {
-		dataset.Tags = tags
+		dataset.TagIDs = tagIDs
 	}
 
 	return dataset, nil
 }
 
 func (r *datasetPostgresRepository) GetBySlug(ctx context.Context, slug string) (*domain.Dataset, error) {
-	query := `
+	query := fmt.Sprintf(`
 		SELECT
-			d.id, d.name, d.slug, d.description, d.period, d.unit_id, d.business_field_id,
+			d.id, d.name, d.slug, d.description, d.coverage_start, d.coverage_end, d.frequency,
+			d.unit_id, d.business_field_id,
 			d.image, d.topic_id, d.organization_id, d.reference_id, d.classification,
 			d.category, d.data_fixed, d.validation_status, d.metadatas, d.created_by,
 			d.updated_by, d.created_at, d.updated_at, d.is_highlight, d.status,
-			o.id as org_id, o.name as org_name, o.slug as org_slug,
-			u.id as unit_id, u.name as unit_name, u.symbol as unit_symbol,
-			bf.id as bf_id, bf.name as bf_name, bf.slug as bf_slug,
-			t.id as topic_id, t.name as topic_name, t.slug as topic_slug
+			d.names, d.descriptions, d.deleted_at,
+			d.source_url, d.link_status, d.link_checked_at, d.link_error,
+			o.name as org_name, o.slug as org_slug
 		FROM datasets d
 		LEFT JOIN organizations o ON d.organization_id = o.id
-		LEFT JOIN units u ON d.unit_id = u.id
-		LEFT JOIN business_fields bf ON d.business_field_id = bf.id
-		LEFT JOIN topics t ON d.topic_id = t.id
-		WHERE d.slug = $1
-	`
+		WHERE d.slug = $1 AND %s AND %s
+	`, db.NotDeleted(ctx, "d.deleted_at"), db.InTenantOrganizations(ctx, "d.organization_id"))
 
-	dataset, err := r.scanDataset(ctx, query, slug)
+	conn := r.db.Read(ctx)
+	dataset, err := r.scanDataset(ctx, conn, query, slug)
 	if err != nil {
 		return nil, err
 	}
 
-	tags, err := r.getTagsByDatasetID(ctx, dataset.ID)
+	tagIDs, err := r.getTagIDsByDatasetID(ctx, conn, dataset.ID)
 	if err == nil {
-		dataset.Tags = tags
+		dataset.TagIDs = tagIDs
 	}
 
 	return dataset, nil
 }
 
+func (r *datasetPostgresRepository) GetByIDs(ctx context.Context, ids []string) ([]*domain.Dataset, error) {
+	datasets := []*domain.Dataset{}
+	if len(ids) == 0 {
+		return datasets, nil
+	}
+
+	query, args, err := sqlx.In(fmt.Sprintf(`
+		SELECT
+			d.id, d.name, d.slug, d.description, d.coverage_start, d.coverage_end, d.frequency,
+			d.unit_id, d.business_field_id,
+			d.image, d.topic_id, d.organization_id, d.reference_id, d.classification,
+			d.category, d.data_fixed, d.validation_status, d.metadatas, d.created_by,
+			d.updated_by, d.created_at, d.updated_at, d.is_highlight, d.status,
+			d.names, d.descriptions, d.deleted_at,
+			d.source_url, d.link_status, d.link_checked_at, d.link_error,
+			o.name as org_name, o.slug as org_slug
+		FROM datasets d
+		LEFT JOIN organizations o ON d.organization_id = o.id
+		WHERE d.id IN (?) AND %s AND %s
+	`, db.NotDeleted(ctx, "d.deleted_at"), db.InTenantOrganizations(ctx, "d.organization_id")), ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get datasets: %w", err)
+	}
+
+	conn := r.db.Read(ctx)
+	rows, err := conn.QueryContext(ctx, conn.Rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get datasets: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		dataset, err := r.scanRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		datasets = append(datasets, dataset)
+	}
+	return datasets, rows.Err()
+}
+
+func (r *datasetPostgresRepository) TagIDsByDatasetIDs(ctx context.Context, ids []string) (map[string][]string, error) {
+	tagIDs := make(map[string][]string, len(ids))
+	if len(ids) == 0 {
+		return tagIDs, nil
+	}
+
+	query, args, err := sqlx.In(`
+		SELECT dataset_id, tag_id
+		FROM dataset_tag_link
+		WHERE dataset_id IN (?)
+		ORDER BY tag_id
+	`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dataset tags: %w", err)
+	}
+
+	conn := r.db.Read(ctx)
+	rows, err := conn.QueryContext(ctx, conn.Rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dataset tags: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var datasetID, tagID string
+		if err := rows.Scan(&datasetID, &tagID); err != nil {
+			return nil, fmt.Errorf("failed to scan dataset tag: %w", err)
+		}
+		tagIDs[datasetID] = append(tagIDs[datasetID], tagID)
+	}
+	return tagIDs, rows.Err()
+}
+
 func (r *datasetPostgresRepository) List(ctx context.Context, filter *domain.DatasetFilter, limit, offset int, sortBy, sortOrder string) ([]*domain.Dataset, int, error) {
-	whereClause, args := r.buildWhereClause(filter)
+	whereClause, args := r.buildWhereClause(ctx, filter)
 
 	countQuery := "SELECT COUNT(*) FROM datasets d " + whereClause
 	var total int
-	err := r.db.GetContext(ctx, &total, countQuery, args...)
+	err := r.db.Read(ctx).GetContext(ctx, &total, countQuery, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count datasets: %w", err)
 	}
@@ -100,24 +174,21 @@ func (r *datasetPostgresRepository) List(ctx context.Context, filter *domain.Dat
 	orderClause := r.buildOrderClause(sortBy, sortOrder)
 	query := `
 		SELECT
-			d.id, d.name, d.slug, d.description, d.period, d.unit_id, d.business_field_id,
+			d.id, d.name, d.slug, d.description, d.coverage_start, d.coverage_end, d.frequency,
+			d.unit_id, d.business_field_id,
 			d.image, d.topic_id, d.organization_id, d.reference_id, d.classification,
 			d.category, d.data_fixed, d.validation_status, d.metadatas, d.created_by,
 			d.updated_by, d.created_at, d.updated_at, d.is_highlight, d.status,
-			o.id as org_id, o.name as org_name, o.slug as org_slug,
-			u.id as unit_id, u.name as unit_name, u.symbol as unit_symbol,
-			bf.id as bf_id, bf.name as bf_name, bf.slug as bf_slug,
-			t.id as topic_id, t.name as topic_name, t.slug as topic_slug
+			d.names, d.descriptions, d.deleted_at,
+			d.source_url, d.link_status, d.link_checked_at, d.link_error,
+			o.name as org_name, o.slug as org_slug
 		FROM datasets d
 		LEFT JOIN organizations o ON d.organization_id = o.id
-		LEFT JOIN units u ON d.unit_id = u.id
-		LEFT JOIN business_fields bf ON d.business_field_id = bf.id
-		LEFT JOIN topics t ON d.topic_id = t.id
 	` + whereClause + " " + orderClause + " LIMIT $" + fmt.Sprintf("%d", len(args)+1) + " OFFSET $" + fmt.Sprintf("%d", len(args)+2)
 
 	args = append(args, limit, offset)
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := r.db.Read(ctx).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to list datasets: %w", err)
 	}
@@ -136,95 +207,195 @@ func (r *datasetPostgresRepository) List(ctx context.Context, filter *domain.Dat
 }
 
 func (r *datasetPostgresRepository) Create(ctx context.Context, dataset *domain.Dataset, tagIDs []string) error {
-	tx, err := r.db.BeginTxx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback()
-
-	insertQuery := `
-		INSERT INTO datasets (
-			id, name, slug, description, period, unit_id, business_field_id, image,
-			topic_id, organization_id, reference_id, classification, category,
-			data_fixed, validation_status, metadatas, created_by, updated_by,
-			created_at, updated_at, is_highlight, status
-		) VALUES (
-			:id, :name, :slug, :description, :period, :unit_id, :business_field_id, :image,
-			:topic_id, :organization_id, :reference_id, :classification, :category,
-			:data_fixed, :validation_status, :metadatas, :created_by, :updated_by,
-			:created_at, :updated_at, :is_highlight, :status
-		)
-	`
+	return r.db.WithinTx(ctx, func(ctx context.Context) error {
+		tx := r.db.Write(ctx)
+
+		insertQuery := `
+			INSERT INTO datasets (
+				id, name, slug, description, coverage_start, coverage_end, frequency,
+				unit_id, business_field_id, image,
+				topic_id, organization_id, reference_id, classification, category,
+				data_fixed, validation_status, metadatas, created_by, updated_by,
+				created_at, updated_at, is_highlight, status, names, descriptions, source_url
+			) VALUES (
+				:id, :name, :slug, :description, :coverage_start, :coverage_end, :frequency,
+				:unit_id, :business_field_id, :image,
+				:topic_id, :organization_id, :reference_id, :classification, :category,
+				:data_fixed, :validation_status, :metadatas, :created_by, :updated_by,
+				:created_at, :updated_at, :is_highlight, :status, :names, :descriptions, :source_url
+			)
+		`
+
+		_, err := tx.NamedExecContext(ctx, insertQuery, dataset)
+		if err != nil {
+			return fmt.Errorf("failed to create dataset: %w", err)
+		}
 
-	_, err = tx.NamedExecContext(ctx, insertQuery, dataset)
-	if err != nil {
-		return fmt.Errorf("failed to create dataset: %w", err)
-	}
+		// Insert tags
+		if len(tagIDs) > 0 {
+			for _, tagID := range tagIDs {
+				_, err = tx.ExecContext(ctx, `INSERT INTO dataset_tag_link (dataset_id, tag_id) VALUES ($1, $2)`, dataset.ID, tagID)
+				if err != nil {
+					return fmt.Errorf("failed to link tags: %w", err)
+				}
+			}
+		}
 
-	// Insert tags
-	if len(tagIDs) > 0 {
-		for _, tagID := range tagIDs {
-			_, err = tx.ExecContext(ctx, `INSERT INTO dataset_tag_link (dataset_id, tag_id) VALUES ($1, $2)`, dataset.ID, tagID)
-			if err != nil {
-				return fmt.Errorf("failed to link tags: %w", err)
+		return nil
+	})
+}
+
+func (r *datasetPostgresRepository) Update(ctx context.Context, dataset *domain.Dataset, tagIDs []string) error {
+	return r.db.WithinTx(ctx, func(ctx context.Context) error {
+		tx := r.db.Write(ctx)
+
+		updateQuery := `
+			UPDATE datasets SET
+				name = :name, slug = :slug, description = :description,
+				coverage_start = :coverage_start, coverage_end = :coverage_end, frequency = :frequency,
+				unit_id = :unit_id, business_field_id = :business_field_id, image = :image,
+				topic_id = :topic_id, reference_id = :reference_id, classification = :classification,
+				category = :category, data_fixed = :data_fixed, validation_status = :validation_status,
+				metadatas = :metadatas, updated_by = :updated_by, updated_at = :updated_at,
+				is_highlight = :is_highlight, status = :status,
+				names = :names, descriptions = :descriptions,
+				link_status = CASE WHEN source_url IS DISTINCT FROM :source_url THEN NULL ELSE link_status END,
+				link_checked_at = CASE WHEN source_url IS DISTINCT FROM :source_url THEN NULL ELSE link_checked_at END,
+				link_error = CASE WHEN source_url IS DISTINCT FROM :source_url THEN NULL ELSE link_error END,
+				source_url = :source_url
+			WHERE id = :id
+		`
+
+		result, err := tx.NamedExecContext(ctx, updateQuery, dataset)
+		if err != nil {
+			return fmt.Errorf("failed to update dataset: %w", err)
+		}
+
+		rows, _ := result.RowsAffected()
+		if rows == 0 {
+			return errors.ErrNotFound
+		}
+
+		// Update tags - delete existing and insert new
+		_, err = tx.ExecContext(ctx, `DELETE FROM dataset_tag_link WHERE dataset_id = $1`, dataset.ID)
+		if err != nil {
+			return fmt.Errorf("failed to delete existing tags: %w", err)
+		}
+
+		if len(tagIDs) > 0 {
+			for _, tagID := range tagIDs {
+				_, err = tx.ExecContext(ctx, `INSERT INTO dataset_tag_link (dataset_id, tag_id) VALUES ($1, $2)`, dataset.ID, tagID)
+				if err != nil {
+					return fmt.Errorf("failed to link tags: %w", err)
+				}
 			}
 		}
+
+		return nil
+	})
+}
+
+func (r *datasetPostgresRepository) Delete(ctx context.Context, id string) error {
+	return db.SoftDelete(ctx, r.db.Write(ctx), "datasets", id)
+}
+
+func (r *datasetPostgresRepository) Restore(ctx context.Context, id string) error {
+	return db.Restore(ctx, r.db.Write(ctx), "datasets", id)
+}
+
+func (r *datasetPostgresRepository) Trash(ctx context.Context, orgID string, limit, offset int) ([]*domain.TrashedDataset, int, error) {
+	where := fmt.Sprintf(`WHERE d.deleted_at IS NOT NULL AND %s AND ($1 = '' OR d.organization_id::text = $1)`,
+		db.InTenantOrganizations(ctx, "d.organization_id"))
+
+	var total int
+	if err := r.db.Read(ctx).GetContext(ctx, &total, `SELECT COUNT(*) FROM datasets d `+where, orgID); err != nil {
+		return nil, 0, fmt.Errorf("failed to count deleted datasets: %w", err)
 	}
 
-	return tx.Commit()
+	query := `
+		SELECT d.id, d.name, d.slug, d.organization_id, d.status, d.deleted_at
+		FROM datasets d
+		` + where + `
+		ORDER BY d.deleted_at DESC, d.id
+		LIMIT $2 OFFSET $3
+	`
+	datasets := []*domain.TrashedDataset{}
+	if err := r.db.Read(ctx).SelectContext(ctx, &datasets, query, orgID, limit, offset); err != nil {
+		return nil, 0, fmt.Errorf("failed to list deleted datasets: %w", err)
+	}
+	return datasets, total, nil
 }
 
-func (r *datasetPostgresRepository) Update(ctx context.Context, dataset *domain.Dataset, tagIDs []string) error {
-	tx, err := r.db.BeginTxx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback()
-
-	updateQuery := `
-		UPDATE datasets SET
-			name = :name, slug = :slug, description = :description, period = :period,
-			unit_id = :unit_id, business_field_id = :business_field_id, image = :image,
-			topic_id = :topic_id, reference_id = :reference_id, classification = :classification,
-			category = :category, data_fixed = :data_fixed, validation_status = :validation_status,
-			metadatas = :metadatas, updated_by = :updated_by, updated_at = :updated_at,
-			is_highlight = :is_highlight, status = :status
-		WHERE id = :id
+func (r *datasetPostgresRepository) DeletedBefore(ctx context.Context, before time.Time, limit int) ([]string, error) {
+	query := `
+		SELECT id FROM datasets
+		WHERE deleted_at IS NOT NULL AND deleted_at < $1
+		ORDER BY deleted_at, id
+		LIMIT $2
 	`
 
-	result, err := tx.NamedExecContext(ctx, updateQuery, dataset)
-	if err != nil {
-		return fmt.Errorf("failed to update dataset: %w", err)
+	ids := []string{}
+	if err := r.db.Write(ctx).SelectContext(ctx, &ids, query, before, limit); err != nil {
+		return nil, fmt.Errorf("failed to list datasets to purge: %w", err)
 	}
+	return ids, nil
+}
 
+func (r *datasetPostgresRepository) Purge(ctx context.Context, id string) error {
+	conn := r.db.Write(ctx)
+	if _, err := conn.ExecContext(ctx, `DELETE FROM data_rows WHERE dataset_id = $1`, id); err != nil {
+		return fmt.Errorf("failed to purge dataset rows: %w", err)
+	}
+
+	// Tags, highlights, masks and previews of the dataset go with it
+	result, err := conn.ExecContext(ctx, `DELETE FROM datasets WHERE id = $1 AND deleted_at IS NOT NULL`, id)
+	if err != nil {
+		return fmt.Errorf("failed to purge dataset: %w", err)
+	}
 	rows, _ := result.RowsAffected()
 	if rows == 0 {
 		return errors.ErrNotFound
 	}
+	return nil
+}
 
-	// Update tags - delete existing and insert new
-	_, err = tx.ExecContext(ctx, `DELETE FROM dataset_tag_link WHERE dataset_id = $1`, dataset.ID)
+func (r *datasetPostgresRepository) UpdateStatus(ctx context.Context, id string, status domain.DatasetStatus) error {
+	query := `UPDATE datasets SET status = $1, updated_at = NOW() WHERE id = $2`
+	result, err := r.db.Write(ctx).ExecContext(ctx, query, status, id)
 	if err != nil {
-		return fmt.Errorf("failed to delete existing tags: %w", err)
+		return fmt.Errorf("failed to update dataset status: %w", err)
 	}
 
-	if len(tagIDs) > 0 {
-		for _, tagID := range tagIDs {
-			_, err = tx.ExecContext(ctx, `INSERT INTO dataset_tag_link (dataset_id, tag_id) VALUES ($1, $2)`, dataset.ID, tagID)
-			if err != nil {
-				return fmt.Errorf("failed to link tags: %w", err)
-			}
-		}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.ErrNotFound
 	}
+	return nil
+}
 
-	return tx.Commit()
+func (r *datasetPostgresRepository) Transfer(ctx context.Context, id, orgID, updaterID string) error {
+	query := `
+		UPDATE datasets SET organization_id = $1, updated_by = $2, updated_at = NOW()
+		WHERE id = $3 AND deleted_at IS NULL`
+	result, err := r.db.Write(ctx).ExecContext(ctx, query, orgID, updaterID, id)
+	if err != nil {
+		return fmt.Errorf("failed to transfer dataset: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
 }
 
-func (r *datasetPostgresRepository) Delete(ctx context.Context, id string) error {
-	query := `UPDATE datasets SET status = 'archived', updated_at = NOW() WHERE id = $1`
-	result, err := r.db.ExecContext(ctx, query, id)
+func (r *datasetPostgresRepository) SetValidationStatus(ctx context.Context, id string, status domain.ValidationStatus, updaterID string) error {
+	query := `
+		UPDATE datasets SET validation_status = $1, updated_by = $2, updated_at = NOW()
+		WHERE id = $3 AND deleted_at IS NULL`
+	result, err := r.db.Write(ctx).ExecContext(ctx, query, status, updaterID, id)
 	if err != nil {
-		return fmt.Errorf("failed to delete dataset: %w", err)
+		return fmt.Errorf("failed to set dataset validation status: %w", err)
 	}
 
 	rows, _ := result.RowsAffected()
@@ -234,11 +405,117 @@ func (r *datasetPostgresRepository) Delete(ctx context.Context, id string) error
 	return nil
 }
 
-func (r *datasetPostgresRepository) UpdateStatus(ctx context.Context, id string, status domain.DatasetStatus) error {
-	query := `UPDATE datasets SET status = $1, updated_at = NOW() WHERE id = $2`
-	result, err := r.db.ExecContext(ctx, query, status, id)
+func (r *datasetPostgresRepository) SetImage(ctx context.Context, id, image, previous string) (bool, error) {
+	query := `
+		UPDATE datasets SET image = $1
+		WHERE id = $2 AND (image IS NULL OR image = '' OR image = $3) AND deleted_at IS NULL`
+	result, err := r.db.Write(ctx).ExecContext(ctx, query, image, id, previous)
 	if err != nil {
-		return fmt.Errorf("failed to update dataset status: %w", err)
+		return false, fmt.Errorf("failed to set dataset image: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+func (r *datasetPostgresRepository) SaveLintReport(ctx context.Context, report *domain.LintReport) error {
+	query := `
+		INSERT INTO dataset_lint_reports (dataset_id, warnings, warning_count, checked_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (dataset_id) DO UPDATE
+		SET warnings = EXCLUDED.warnings, warning_count = EXCLUDED.warning_count, checked_at = EXCLUDED.checked_at
+	`
+	if _, err := r.db.Write(ctx).ExecContext(ctx, query, report.DatasetID, report.Warnings, report.WarningCount, report.CheckedAt); err != nil {
+		return fmt.Errorf("failed to save dataset lint report: %w", err)
+	}
+	return nil
+}
+
+func (r *datasetPostgresRepository) LintReports(ctx context.Context, filter *domain.LintReportFilter, limit, offset int) ([]*domain.LintReport, int, error) {
+	where := fmt.Sprintf(`
+		WHERE d.deleted_at IS NULL AND %s
+		AND ($1 = '' OR d.organization_id::text = $1)
+		AND ($2 = '' OR l.warnings @> jsonb_build_array(jsonb_build_object('rule', $2::text)))
+	`, db.InTenantOrganizations(ctx, "d.organization_id"))
+	from := `FROM dataset_lint_reports l INNER JOIN datasets d ON d.id = l.dataset_id ` + where
+
+	var total int
+	if err := r.db.Read(ctx).GetContext(ctx, &total, `SELECT COUNT(*) `+from, filter.OrganizationID, filter.Rule); err != nil {
+		return nil, 0, fmt.Errorf("failed to count dataset lint reports: %w", err)
+	}
+
+	query := `
+		SELECT l.dataset_id, d.name, d.slug, d.organization_id, l.warnings, l.warning_count, l.checked_at
+		` + from + `
+		ORDER BY l.warning_count DESC, l.checked_at DESC, l.dataset_id
+		LIMIT $3 OFFSET $4
+	`
+	reports := []*domain.LintReport{}
+	if err := r.db.Read(ctx).SelectContext(ctx, &reports, query, filter.OrganizationID, filter.Rule, limit, offset); err != nil {
+		return nil, 0, fmt.Errorf("failed to list dataset lint reports: %w", err)
+	}
+	return reports, total, nil
+}
+
+func (r *datasetPostgresRepository) LintRuleCounts(ctx context.Context, orgID string) (map[string]int, error) {
+	query := fmt.Sprintf(`
+		SELECT w.warning->>'rule' AS rule, COUNT(DISTINCT l.dataset_id) AS count
+		FROM dataset_lint_reports l
+		INNER JOIN datasets d ON d.id = l.dataset_id
+		CROSS JOIN LATERAL jsonb_array_elements(l.warnings) AS w(warning)
+		WHERE d.deleted_at IS NULL AND %s AND ($1 = '' OR d.organization_id::text = $1)
+		GROUP BY 1
+	`, db.InTenantOrganizations(ctx, "d.organization_id"))
+
+	var rows []struct {
+		Rule  string `db:"rule"`
+		Count int    `db:"count"`
+	}
+	if err := r.db.Read(ctx).SelectContext(ctx, &rows, query, orgID); err != nil {
+		return nil, fmt.Errorf("failed to count dataset lint warnings: %w", err)
+	}
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.Rule] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *datasetPostgresRepository) ListCollaborators(ctx context.Context, datasetID string) ([]*domain.Collaborator, error) {
+	query := `
+		SELECT id, dataset_id, grantee_type, grantee_id, role, granted_by, created_at, updated_at
+		FROM dataset_collaborators
+		WHERE dataset_id = $1
+		ORDER BY created_at, id
+	`
+	collaborators := []*domain.Collaborator{}
+	if err := r.db.Read(ctx).SelectContext(ctx, &collaborators, query, datasetID); err != nil {
+		return nil, fmt.Errorf("failed to list dataset collaborators: %w", err)
+	}
+	return collaborators, nil
+}
+
+func (r *datasetPostgresRepository) SaveCollaborator(ctx context.Context, collaborator *domain.Collaborator) error {
+	query := `
+		INSERT INTO dataset_collaborators (id, dataset_id, grantee_type, grantee_id, role, granted_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+		ON CONFLICT (dataset_id, grantee_type, grantee_id) DO UPDATE
+		SET role = EXCLUDED.role, granted_by = EXCLUDED.granted_by, updated_at = EXCLUDED.updated_at
+		RETURNING id, created_at, updated_at
+	`
+	row := r.db.Write(ctx).QueryRowxContext(ctx, query, collaborator.ID, collaborator.DatasetID, collaborator.GranteeType,
+		collaborator.GranteeID, collaborator.Role, collaborator.GrantedBy, collaborator.UpdatedAt)
+	if err := row.Scan(&collaborator.ID, &collaborator.CreatedAt, &collaborator.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to save dataset collaborator: %w", err)
+	}
+	return nil
+}
+
+func (r *datasetPostgresRepository) DeleteCollaborator(ctx context.Context, datasetID, id string) error {
+	query := `DELETE FROM dataset_collaborators WHERE dataset_id = $1 AND id = $2`
+	result, err := r.db.Write(ctx).ExecContext(ctx, query, datasetID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete dataset collaborator: %w", err)
 	}
 
 	rows, _ := result.RowsAffected()
@@ -248,15 +525,238 @@ func (r *datasetPostgresRepository) UpdateStatus(ctx context.Context, id string,
 	return nil
 }
 
+// CollaboratorRoles reads from the primary, as it authorizes writes that
+// must not be let through by a replica lagging behind a revoked grant
+func (r *datasetPostgresRepository) CollaboratorRoles(ctx context.Context, datasetID, userID, orgID string) ([]domain.CollaboratorRole, error) {
+	query := `
+		SELECT role FROM dataset_collaborators
+		WHERE dataset_id = $1
+		AND ((grantee_type = 'user' AND grantee_id::text = $2) OR (grantee_type = 'organization' AND grantee_id::text = $3))
+	`
+	roles := []domain.CollaboratorRole{}
+	if err := r.db.Write(ctx).SelectContext(ctx, &roles, query, datasetID, userID, orgID); err != nil {
+		return nil, fmt.Errorf("failed to get dataset collaborator roles: %w", err)
+	}
+	return roles, nil
+}
+
 func (r *datasetPostgresRepository) GetByOrganizationID(ctx context.Context, orgID string, limit, offset int) ([]*domain.Dataset, int, error) {
 	filter := &domain.DatasetFilter{OrganizationID: orgID}
 	return r.List(ctx, filter, limit, offset, "created_at", "DESC")
 }
 
+func (r *datasetPostgresRepository) SimilarTitles(ctx context.Context, filter *domain.DatasetFilter, text string, limit int) ([]domain.DatasetSuggestion, error) {
+	whereClause, args := r.buildWhereClause(ctx, withoutSearch(filter))
+	textArg := len(args) + 1
+	query := fmt.Sprintf(`
+		SELECT d.id, d.name, d.slug, word_similarity($%d, d.name) AS similarity
+		FROM datasets d
+		%s AND $%d <%% d.name
+		ORDER BY similarity DESC, d.name
+		LIMIT $%d
+	`, textArg, whereClause, textArg, textArg+1)
+	args = append(args, text, limit)
+
+	var suggestions []domain.DatasetSuggestion
+	if err := r.db.Read(ctx).SelectContext(ctx, &suggestions, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to find similar dataset titles: %w", err)
+	}
+	return suggestions, nil
+}
+
+func (r *datasetPostgresRepository) SimilarNames(ctx context.Context, filter *domain.DatasetFilter, name string, limit int) ([]domain.DatasetSuggestion, error) {
+	whereClause, args := r.buildWhereClause(ctx, withoutSearch(filter))
+	nameArg := len(args) + 1
+	query := fmt.Sprintf(`
+		SELECT d.id, d.name, d.slug, similarity($%d, d.name) AS similarity
+		FROM datasets d
+		%s AND d.name %% $%d
+		ORDER BY similarity DESC, d.name
+		LIMIT $%d
+	`, nameArg, whereClause, nameArg, nameArg+1)
+	args = append(args, name, limit)
+
+	var suggestions []domain.DatasetSuggestion
+	if err := r.db.Read(ctx).SelectContext(ctx, &suggestions, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to find similar dataset names: %w", err)
+	}
+	return suggestions, nil
+}
+
+func (r *datasetPostgresRepository) SimilarWords(ctx context.Context, filter *domain.DatasetFilter, word string, limit int) ([]string, error) {
+	whereClause, args := r.buildWhereClause(ctx, withoutSearch(filter))
+	wordArg := len(args) + 1
+	query := fmt.Sprintf(`
+		SELECT w.word
+		FROM (
+			SELECT DISTINCT regexp_split_to_table(lower(d.name), '[^[:alnum:]]+') AS word
+			FROM datasets d
+			%s
+		) w
+		WHERE length(w.word) > 2 AND w.word %% $%d
+		ORDER BY similarity(w.word, $%d) DESC, w.word
+		LIMIT $%d
+	`, whereClause, wordArg, wordArg, wordArg+1)
+	args = append(args, strings.ToLower(word), limit)
+
+	var words []string
+	if err := r.db.Read(ctx).SelectContext(ctx, &words, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to find similar words: %w", err)
+	}
+	return words, nil
+}
+
+func (r *datasetPostgresRepository) Highlights(ctx context.Context) ([]*domain.Highlight, error) {
+	query := fmt.Sprintf(`
+		SELECT h.dataset_id, h.position, h.expires_at, h.created_by, h.created_at
+		FROM dataset_highlights h
+		INNER JOIN datasets d ON d.id = h.dataset_id
+		WHERE %s AND %s
+		ORDER BY h.position, h.created_at
+	`, db.NotDeleted(ctx, "d.deleted_at"), db.InTenantOrganizations(ctx, "d.organization_id"))
+
+	highlights := []*domain.Highlight{}
+	if err := r.db.Write(ctx).SelectContext(ctx, &highlights, query); err != nil {
+		return nil, fmt.Errorf("failed to list dataset highlights: %w", err)
+	}
+	return highlights, nil
+}
+
+func (r *datasetPostgresRepository) AddHighlight(ctx context.Context, highlight *domain.Highlight) error {
+	return r.db.WithinTx(ctx, func(ctx context.Context) error {
+		tx := r.db.Write(ctx)
+
+		query := `
+			INSERT INTO dataset_highlights (dataset_id, position, expires_at, created_by, created_at)
+			VALUES ($1, (SELECT COALESCE(MAX(position), 0) + 1 FROM dataset_highlights), $2, $3, $4)
+			ON CONFLICT (dataset_id) DO NOTHING
+			RETURNING position
+		`
+		err := tx.GetContext(ctx, &highlight.Position, query,
+			highlight.DatasetID, highlight.ExpiresAt, highlight.CreatedBy, highlight.CreatedAt)
+		if err == sql.ErrNoRows {
+			return errors.ErrAlreadyExists
+		}
+		if err != nil {
+			return fmt.Errorf("failed to add dataset highlight: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `UPDATE datasets SET is_highlight = true WHERE id = $1`, highlight.DatasetID); err != nil {
+			return fmt.Errorf("failed to highlight dataset: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *datasetPostgresRepository) SetHighlightExpiry(ctx context.Context, datasetID string, expiresAt *time.Time) error {
+	result, err := r.db.Write(ctx).ExecContext(ctx, `UPDATE dataset_highlights SET expires_at = $1 WHERE dataset_id = $2`, expiresAt, datasetID)
+	if err != nil {
+		return fmt.Errorf("failed to update dataset highlight: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+func (r *datasetPostgresRepository) RemoveHighlight(ctx context.Context, datasetID string) error {
+	return r.db.WithinTx(ctx, func(ctx context.Context) error {
+		tx := r.db.Write(ctx)
+
+		result, err := tx.ExecContext(ctx, `DELETE FROM dataset_highlights WHERE dataset_id = $1`, datasetID)
+		if err != nil {
+			return fmt.Errorf("failed to remove dataset highlight: %w", err)
+		}
+		rows, _ := result.RowsAffected()
+		if rows == 0 {
+			return errors.ErrNotFound
+		}
+
+		if _, err := tx.ExecContext(ctx, `UPDATE datasets SET is_highlight = false WHERE id = $1`, datasetID); err != nil {
+			return fmt.Errorf("failed to unhighlight dataset: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *datasetPostgresRepository) OrderHighlights(ctx context.Context, datasetIDs []string) error {
+	query := `
+		UPDATE dataset_highlights
+		SET position = array_position($1::text[], dataset_id::text)
+		WHERE dataset_id::text = ANY($1::text[])
+	`
+	if _, err := r.db.Write(ctx).ExecContext(ctx, query, pq.Array(datasetIDs)); err != nil {
+		return fmt.Errorf("failed to order dataset highlights: %w", err)
+	}
+	return nil
+}
+
+// ExpireHighlights removes the expired highlights of every tenant
+func (r *datasetPostgresRepository) ExpireHighlights(ctx context.Context, now time.Time) (map[string]string, error) {
+	query := `
+		WITH expired AS (
+			DELETE FROM dataset_highlights WHERE expires_at <= $1 RETURNING dataset_id
+		)
+		UPDATE datasets d SET is_highlight = false
+		FROM expired, organizations o
+		WHERE d.id = expired.dataset_id AND o.id = d.organization_id
+		RETURNING d.id, o.tenant_id
+	`
+
+	var rows []struct {
+		DatasetID string `db:"id"`
+		TenantID  string `db:"tenant_id"`
+	}
+	if err := r.db.Write(ctx).SelectContext(ctx, &rows, query, now); err != nil {
+		return nil, fmt.Errorf("failed to expire dataset highlights: %w", err)
+	}
+
+	tenants := make(map[string]string, len(rows))
+	for _, row := range rows {
+		tenants[row.DatasetID] = row.TenantID
+	}
+	return tenants, nil
+}
+
+// LinksToCheck retrieves the source URLs due for a check in every tenant
+func (r *datasetPostgresRepository) LinksToCheck(ctx context.Context, checkedBefore time.Time, limit int) ([]*domain.LinkCheck, error) {
+	query := `
+		SELECT d.id, d.name, d.slug, d.source_url, d.created_by, d.link_status, o.tenant_id
+		FROM datasets d
+		INNER JOIN organizations o ON o.id = d.organization_id
+		WHERE d.source_url IS NOT NULL AND d.deleted_at IS NULL
+			AND (d.link_checked_at IS NULL OR d.link_checked_at < $1)
+		ORDER BY d.link_checked_at NULLS FIRST, d.id
+		LIMIT $2
+	`
+
+	links := []*domain.LinkCheck{}
+	if err := r.db.Write(ctx).SelectContext(ctx, &links, query, checkedBefore, limit); err != nil {
+		return nil, fmt.Errorf("failed to list dataset links to check: %w", err)
+	}
+	return links, nil
+}
+
+func (r *datasetPostgresRepository) SetLinkStatus(ctx context.Context, id string, status domain.LinkStatus, linkError *string, checkedAt time.Time) error {
+	query := `UPDATE datasets SET link_status = $1, link_error = $2, link_checked_at = $3 WHERE id = $4`
+	result, err := r.db.Write(ctx).ExecContext(ctx, query, status, linkError, checkedAt, id)
+	if err != nil {
+		return fmt.Errorf("failed to set dataset link status: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
 // Helper functions
 
-func (r *datasetPostgresRepository) scanDataset(ctx context.Context, query string, arg interface{}) (*domain.Dataset, error) {
-	row := r.db.QueryRowxContext(ctx, query, arg)
+func (r *datasetPostgresRepository) scanDataset(ctx context.Context, conn db.Executor, query string, arg interface{}) (*domain.Dataset, error) {
+	row := conn.QueryRowxContext(ctx, query, arg)
 	dataset, err := r.scanRowFromQueryx(row)
 	if err != nil {
 		return nil, r.handleError(err)
@@ -267,18 +767,17 @@ func (r *datasetPostgresRepository) scanDataset(ctx context.Context, query strin
 func (r *datasetPostgresRepository) scanRow(rows *sql.Rows) (*domain.Dataset, error) {
 	var dataset domain.Dataset
 	var orgName, orgSlug *string
-	var unitName, unitSymbol *string
-	var bfName, bfSlug *string
-	var topicName, topicSlug *string
 
 	err := rows.Scan(
-		&dataset.ID, &dataset.Name, &dataset.Slug, &dataset.Description, &dataset.Period,
+		&dataset.ID, &dataset.Name, &dataset.Slug, &dataset.Description,
+		&dataset.CoverageStart, &dataset.CoverageEnd, &dataset.Frequency,
 		&dataset.UnitID, &dataset.BusinessFieldID, &dataset.Image, &dataset.TopicID,
 		&dataset.OrganizationID, &dataset.ReferenceID, &dataset.Classification,
 		&dataset.Category, &dataset.DataFixed, &dataset.ValidationStatus, &dataset.Metadata,
 		&dataset.CreatedBy, &dataset.UpdatedBy, &dataset.CreatedAt, &dataset.UpdatedAt,
-		&dataset.IsHighlight, &dataset.Status,
-		&orgName, &orgSlug, &unitName, &unitSymbol, &bfName, &bfSlug, &topicName, &topicSlug,
+		&dataset.IsHighlight, &dataset.Status, &dataset.Names, &dataset.Descriptions, &dataset.DeletedAt,
+		&dataset.SourceURL, &dataset.LinkStatus, &dataset.LinkCheckedAt, &dataset.LinkError,
+		&orgName, &orgSlug,
 	)
 	if err != nil {
 		return nil, err
@@ -292,27 +791,6 @@ func (r *datasetPostgresRepository) scanRow(rows *sql.Rows) (*domain.Dataset, er
 			Slug: *orgSlug,
 		}
 	}
-	if unitName != nil {
-		dataset.Unit = &domain.Unit{
-			ID:    *dataset.UnitID,
-			Name:  *unitName,
-			Symbol: *unitSymbol,
-		}
-	}
-	if bfName != nil {
-		dataset.BusinessField = &domain.BusinessField{
-			ID:   *dataset.BusinessFieldID,
-			Name: *bfName,
-			Slug: *bfSlug,
-		}
-	}
-	if topicName != nil {
-		dataset.Topic = &domain.Topic{
-			ID:   *dataset.TopicID,
-			Name: *topicName,
-			Slug: *topicSlug,
-		}
-	}
 
 	return &dataset, nil
 }
@@ -320,18 +798,17 @@ func (r *datasetPostgresRepository) scanRow(rows *sql.Rows) (*domain.Dataset, er
 func (r *datasetPostgresRepository) scanRowFromQueryx(row *sqlx.Row) (*domain.Dataset, error) {
 	var dataset domain.Dataset
 	var orgName, orgSlug *string
-	var unitName, unitSymbol *string
-	var bfName, bfSlug *string
-	var topicName, topicSlug *string
 
 	err := row.Scan(
-		&dataset.ID, &dataset.Name, &dataset.Slug, &dataset.Description, &dataset.Period,
+		&dataset.ID, &dataset.Name, &dataset.Slug, &dataset.Description,
+		&dataset.CoverageStart, &dataset.CoverageEnd, &dataset.Frequency,
 		&dataset.UnitID, &dataset.BusinessFieldID, &dataset.Image, &dataset.TopicID,
 		&dataset.OrganizationID, &dataset.ReferenceID, &dataset.Classification,
 		&dataset.Category, &dataset.DataFixed, &dataset.ValidationStatus, &dataset.Metadata,
 		&dataset.CreatedBy, &dataset.UpdatedBy, &dataset.CreatedAt, &dataset.UpdatedAt,
-		&dataset.IsHighlight, &dataset.Status,
-		&orgName, &orgSlug, &unitName, &unitSymbol, &bfName, &bfSlug, &topicName, &topicSlug,
+		&dataset.IsHighlight, &dataset.Status, &dataset.Names, &dataset.Descriptions, &dataset.DeletedAt,
+		&dataset.SourceURL, &dataset.LinkStatus, &dataset.LinkCheckedAt, &dataset.LinkError,
+		&orgName, &orgSlug,
 	)
 	if err != nil {
 		return nil, err
@@ -345,49 +822,23 @@ func (r *datasetPostgresRepository) scanRowFromQueryx(row *sqlx.Row) (*domain.Da
 			Slug: *orgSlug,
 		}
 	}
-	if unitName != nil {
-		dataset.Unit = &domain.Unit{
-			ID:    *dataset.UnitID,
-			Name:  *unitName,
-			Symbol: *unitSymbol,
-		}
-	}
-	if bfName != nil {
-		dataset.BusinessField = &domain.BusinessField{
-			ID:   *dataset.BusinessFieldID,
-			Name: *bfName,
-			Slug: *bfSlug,
-		}
-	}
-	if topicName != nil {
-		dataset.Topic = &domain.Topic{
-			ID:   *dataset.TopicID,
-			Name: *topicName,
-			Slug: *topicSlug,
-		}
-	}
 
 	return &dataset, nil
 }
 
-func (r *datasetPostgresRepository) getTagsByDatasetID(ctx context.Context, datasetID string) ([]domain.Tag, error) {
-	query := `
-		SELECT t.id, t.name, t.slug
-		FROM tags t
-		INNER JOIN dataset_tag_link dtl ON t.id = dtl.tag_id
-		WHERE dtl.dataset_id = $1
-	`
+func (r *datasetPostgresRepository) getTagIDsByDatasetID(ctx context.Context, conn db.Executor, datasetID string) ([]string, error) {
+	query := `SELECT tag_id FROM dataset_tag_link WHERE dataset_id = $1 ORDER BY tag_id`
 
-	var tags []domain.Tag
-	err := r.db.SelectContext(ctx, &tags, query, datasetID)
+	var tagIDs []string
+	err := conn.SelectContext(ctx, &tagIDs, query, datasetID)
 	if err != nil {
 		return nil, err
 	}
-	return tags, nil
+	return tagIDs, nil
 }
 
-func (r *datasetPostgresRepository) buildWhereClause(filter *domain.DatasetFilter) (string, []interface{}) {
-	whereClause := "WHERE 1=1"
+func (r *datasetPostgresRepository) buildWhereClause(ctx context.Context, filter *domain.DatasetFilter) (string, []interface{}) {
+	whereClause := "WHERE " + db.NotDeleted(ctx, "d.deleted_at") + " AND " + db.InTenantOrganizations(ctx, "d.organization_id")
 	args := []interface{}{}
 	argCount := 1
 
@@ -425,11 +876,32 @@ func (r *datasetPostgresRepository) buildWhereClause(filter *domain.DatasetFilte
 		args = append(args, filter.Classification)
 		argCount++
 	}
+	if filter.LinkStatus != "" {
+		whereClause += fmt.Sprintf(" AND d.link_status = $%d", argCount)
+		args = append(args, filter.LinkStatus)
+		argCount++
+	}
+	// An open end of a coverage reaches any date, while datasets without
+	// coverage are left out
+	if filter.CoverageFrom != nil {
+		whereClause += fmt.Sprintf(" AND (d.coverage_end >= $%d OR d.coverage_end IS NULL AND d.coverage_start IS NOT NULL)", argCount)
+		args = append(args, *filter.CoverageFrom)
+		argCount++
+	}
+	if filter.CoverageTo != nil {
+		whereClause += fmt.Sprintf(" AND (d.coverage_start <= $%d OR d.coverage_start IS NULL AND d.coverage_end IS NOT NULL)", argCount)
+		args = append(args, *filter.CoverageTo)
+		argCount++
+	}
+	if filter.Frequency != "" {
+		whereClause += fmt.Sprintf(" AND d.frequency = $%d", argCount)
+		args = append(args, filter.Frequency)
+		argCount++
+	}
 	if filter.Search != "" {
 		whereClause += fmt.Sprintf(" AND (d.name ILIKE $%d OR d.description ILIKE $%d)", argCount, argCount)
-		searchTerm := "%" + filter.Search + "%"
-		args = append(args, searchTerm, searchTerm)
-		argCount += 2
+		args = append(args, "%"+filter.Search+"%")
+		argCount++
 	}
 	if filter.TagID != "" {
 		whereClause += fmt.Sprintf(" AND EXISTS(SELECT 1 FROM dataset_tag_link dtl WHERE dtl.dataset_id = d.id AND dtl.tag_id = $%d)", argCount)
@@ -440,6 +912,16 @@ func (r *datasetPostgresRepository) buildWhereClause(filter *domain.DatasetFilte
 	return whereClause, args
 }
 
+// withoutSearch returns a copy of filter without its search text
+func withoutSearch(filter *domain.DatasetFilter) *domain.DatasetFilter {
+	if filter == nil {
+		return nil
+	}
+	copied := *filter
+	copied.Search = ""
+	return &copied
+}
+
 func (r *datasetPostgresRepository) buildOrderClause(sortBy, sortOrder string) string {
 	allowedColumns := map[string]bool{
 		"name":        true,
@@ -0,0 +1,472 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"portal-data-backend/infrastructure/db"
+	"portal-data-backend/infrastructure/tenant"
+	"portal-data-backend/internal/dataset/domain"
+	"portal-data-backend/internal/testenv"
+	pkgErrors "portal-data-backend/pkg/errors"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(testenv.Main(m))
+}
+
+func newTestRepository(t *testing.T) domain.Repository {
+	t.Helper()
+	conn := testenv.Postgres(t)
+	testenv.Fixtures(t, conn, "catalog")
+	return NewDatasetPostgresRepository(db.NewRouter(conn))
+}
+
+// listResult is a page of datasets as golden files record it
+type listResult struct {
+	Total    int               `json:"total"`
+	Datasets []*domain.Dataset `json:"datasets"`
+}
+
+// Test datasets are read with their relations and tags, leaving out deleted
+// datasets and those of other tenants
+func TestDatasetPostgresRepository_Reads(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	dataset, err := repo.GetByID(ctx, "20000000-0000-0000-0000-000000000001")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	testenv.Golden(t, "dataset", dataset)
+
+	bySlug, err := repo.GetBySlug(ctx, "cakupan-imunisasi-dasar")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(bySlug.TagIDs) != 2 || bySlug.TagIDs[1] != "60000000-0000-0000-0000-000000000002" || bySlug.BusinessFieldID == nil {
+		t.Errorf("Expected the dataset with its tag IDs and business field, got %+v", bySlug)
+	}
+
+	lists := map[string]listResult{}
+	for name, list := range map[string]struct {
+		ctx    context.Context
+		filter *domain.DatasetFilter
+	}{
+		"all":          {ctx: ctx},
+		"organization": {ctx: ctx, filter: &domain.DatasetFilter{OrganizationID: "10000000-0000-0000-0000-000000000001"}},
+		"search":       {ctx: ctx, filter: &domain.DatasetFilter{Search: "penduduk", Status: "published"}},
+		"tag":          {ctx: ctx, filter: &domain.DatasetFilter{TagID: "60000000-0000-0000-0000-000000000001"}},
+		"tenant":       {ctx: tenant.WithTenant(ctx, &tenant.Tenant{ID: "jabar"})},
+	} {
+		datasets, total, err := repo.List(list.ctx, list.filter, 10, 0, "name", "asc")
+		if err != nil {
+			t.Fatalf("Expected no error listing %s, got %v", name, err)
+		}
+		lists[name] = listResult{Total: total, Datasets: datasets}
+	}
+	testenv.Golden(t, "dataset_lists", lists)
+
+	// Coverages overlapping the dates match, open ends reaching any date
+	from, to := time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC), time.Date(2022, time.December, 31, 0, 0, 0, 0, time.UTC)
+	later, earlier := time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC), time.Date(2015, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for name, want := range map[string]struct {
+		filter *domain.DatasetFilter
+		ids    []string
+	}{
+		"overlapping": {filter: &domain.DatasetFilter{CoverageFrom: &from, CoverageTo: &to}, ids: []string{"20000000-0000-0000-0000-000000000004", "20000000-0000-0000-0000-000000000001"}},
+		"later":       {filter: &domain.DatasetFilter{CoverageFrom: &later}, ids: []string{"20000000-0000-0000-0000-000000000004"}},
+		"earlier":     {filter: &domain.DatasetFilter{CoverageTo: &earlier}, ids: []string{"20000000-0000-0000-0000-000000000004"}},
+		"frequency":   {filter: &domain.DatasetFilter{Frequency: "annual"}, ids: []string{"20000000-0000-0000-0000-000000000002", "20000000-0000-0000-0000-000000000004", "20000000-0000-0000-0000-000000000001"}},
+		"monthly":     {filter: &domain.DatasetFilter{Frequency: "monthly"}},
+	} {
+		datasets, _, err := repo.List(ctx, want.filter, 10, 0, "name", "asc")
+		if err != nil {
+			t.Fatalf("Expected no error listing %s, got %v", name, err)
+		}
+		var ids []string
+		for _, dataset := range datasets {
+			ids = append(ids, dataset.ID)
+		}
+		if !reflect.DeepEqual(ids, want.ids) {
+			t.Errorf("Expected %v for %s coverage, got %v", want.ids, name, ids)
+		}
+	}
+
+	byIDs, err := repo.GetByIDs(tenant.WithTenant(ctx, &tenant.Tenant{ID: "jabar"}), []string{
+		"20000000-0000-0000-0000-000000000001",
+		"20000000-0000-0000-0000-000000000004",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(byIDs) != 1 || byIDs[0].ID != "20000000-0000-0000-0000-000000000004" {
+		t.Errorf("Expected only the dataset of the tenant, got %+v", byIDs)
+	}
+
+	words, err := repo.SimilarWords(ctx, nil, "pendudk", 5)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(words, []string{"penduduk"}) {
+		t.Errorf("Expected [penduduk], got %v", words)
+	}
+
+	similar, err := repo.SimilarNames(ctx, nil, strings.ToUpper(dataset.Name), 5)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(similar) == 0 || similar[0].ID != dataset.ID || similar[0].Similarity != 1 {
+		t.Errorf("Expected the dataset as the closest name, got %+v", similar)
+	}
+}
+
+// Test created datasets read back as written, with the tags they are
+// updated with, and deleted ones are restored
+func TestDatasetPostgresRepository_Writes(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	created := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	unitID := "30000000-0000-0000-0000-000000000001"
+	dataset := &domain.Dataset{
+		ID:               "20000000-0000-0000-0000-000000000006",
+		Name:             "Angka Partisipasi Sekolah",
+		Slug:             "angka-partisipasi-sekolah",
+		UnitID:           &unitID,
+		OrganizationID:   "10000000-0000-0000-0000-000000000002",
+		Classification:   "public",
+		Category:         "statistik",
+		ValidationStatus: domain.ValidationStatusPending,
+		CreatedBy:        "90000000-0000-0000-0000-000000000001",
+		CreatedAt:        created,
+		UpdatedAt:        created,
+		Status:           domain.DatasetStatusDraft,
+		Names:            `{}`,
+		Descriptions:     `{"en": "School enrollment rate"}`,
+	}
+	if err := repo.Create(ctx, dataset, []string{"60000000-0000-0000-0000-000000000002"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	got, err := repo.GetByID(ctx, dataset.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	testenv.Golden(t, "dataset_created", got)
+
+	dataset.Status = domain.DatasetStatusPublished
+	dataset.UpdatedAt = created.Add(time.Hour)
+	if err := repo.Update(ctx, dataset, []string{"60000000-0000-0000-0000-000000000001"}); err != nil {
+		t.Fatalf("Expected no error updating, got %v", err)
+	}
+	got, err = repo.GetByID(ctx, dataset.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got.Status != domain.DatasetStatusPublished || len(got.TagIDs) != 1 || got.TagIDs[0] != "60000000-0000-0000-0000-000000000001" {
+		t.Errorf("Expected the published dataset with its new tag, got %+v", got)
+	}
+
+	if err := repo.Delete(ctx, dataset.ID); err != nil {
+		t.Fatalf("Expected no error deleting, got %v", err)
+	}
+	if _, err := repo.GetByID(ctx, dataset.ID); err == nil {
+		t.Errorf("Expected deleted dataset to be left out")
+	}
+	if _, err := repo.GetByID(db.WithDeleted(ctx), dataset.ID); err != nil {
+		t.Errorf("Expected deleted dataset to be read with WithDeleted, got %v", err)
+	}
+	if err := repo.Restore(ctx, dataset.ID); err != nil {
+		t.Fatalf("Expected no error restoring, got %v", err)
+	}
+
+	missing := &domain.Dataset{ID: "20000000-0000-0000-0000-000000000009", Names: `{}`, Descriptions: `{}`}
+	if err := repo.Update(ctx, missing, nil); !errors.Is(err, pkgErrors.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound updating a missing dataset, got %v", err)
+	}
+	if err := repo.UpdateStatus(ctx, missing.ID, domain.DatasetStatusArchived); !errors.Is(err, pkgErrors.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound updating the status of a missing dataset, got %v", err)
+	}
+}
+
+// Test highlights are numbered in the order they are added or arranged,
+// keep is_highlight in step and are removed once they expire
+func TestDatasetPostgresRepository_Highlights(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	now := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	expires := now.Add(time.Hour)
+	first := &domain.Highlight{DatasetID: "20000000-0000-0000-0000-000000000001", ExpiresAt: &expires, CreatedAt: now}
+	second := &domain.Highlight{DatasetID: "20000000-0000-0000-0000-000000000002", CreatedAt: now}
+	for _, highlight := range []*domain.Highlight{first, second} {
+		if err := repo.AddHighlight(ctx, highlight); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if first.Position != 1 || second.Position != 2 {
+		t.Errorf("Expected positions 1 and 2, got %d and %d", first.Position, second.Position)
+	}
+	if err := repo.AddHighlight(ctx, &domain.Highlight{DatasetID: first.DatasetID, CreatedAt: now}); !errors.Is(err, pkgErrors.ErrAlreadyExists) {
+		t.Errorf("Expected ErrAlreadyExists highlighting a dataset twice, got %v", err)
+	}
+
+	if err := repo.OrderHighlights(ctx, []string{second.DatasetID, first.DatasetID}); err != nil {
+		t.Fatalf("Expected no error ordering, got %v", err)
+	}
+	highlights, err := repo.Highlights(ctx)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(highlights) != 2 || highlights[0].DatasetID != second.DatasetID || highlights[1].DatasetID != first.DatasetID {
+		t.Errorf("Expected the highlights in their new order, got %+v", highlights)
+	}
+	if other, err := repo.Highlights(tenant.WithTenant(ctx, &tenant.Tenant{ID: "jabar"})); err != nil || len(other) != 0 {
+		t.Errorf("Expected no highlights for another tenant, got %+v, %v", other, err)
+	}
+
+	expired, err := repo.ExpireHighlights(ctx, expires)
+	if err != nil {
+		t.Fatalf("Expected no error expiring, got %v", err)
+	}
+	if !reflect.DeepEqual(expired, map[string]string{first.DatasetID: "default"}) {
+		t.Errorf("Expected the first highlight to expire, got %v", expired)
+	}
+	dataset, err := repo.GetByID(ctx, first.DatasetID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if dataset.IsHighlight {
+		t.Errorf("Expected the expired dataset to no longer be highlighted")
+	}
+
+	if err := repo.SetHighlightExpiry(ctx, first.DatasetID, nil); !errors.Is(err, pkgErrors.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound updating a removed highlight, got %v", err)
+	}
+	if err := repo.RemoveHighlight(ctx, second.DatasetID); err != nil {
+		t.Fatalf("Expected no error removing, got %v", err)
+	}
+	if err := repo.RemoveHighlight(ctx, second.DatasetID); !errors.Is(err, pkgErrors.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound removing a removed highlight, got %v", err)
+	}
+}
+
+// Test source URLs are due for a check until checked, datasets are filtered
+// by their link status and changing the URL clears its last check
+func TestDatasetPostgresRepository_Links(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	dataset, err := repo.GetByID(ctx, "20000000-0000-0000-0000-000000000001")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	source := "https://bps.example.com/penduduk"
+	dataset.SourceURL = &source
+	if err := repo.Update(ctx, dataset, nil); err != nil {
+		t.Fatalf("Expected no error updating, got %v", err)
+	}
+
+	now := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	links, err := repo.LinksToCheck(ctx, now, 10)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(links) != 1 || links[0].DatasetID != dataset.ID || links[0].SourceURL != source || links[0].TenantID != "default" || links[0].LinkStatus != nil {
+		t.Fatalf("Expected the unchecked source URL to be due, got %+v", links)
+	}
+
+	linkError := "responded 404 Not Found"
+	if err := repo.SetLinkStatus(ctx, dataset.ID, domain.LinkStatusBroken, &linkError, now); err != nil {
+		t.Fatalf("Expected no error setting the link status, got %v", err)
+	}
+	if links, err := repo.LinksToCheck(ctx, now, 10); err != nil || len(links) != 0 {
+		t.Errorf("Expected no source URL due right after its check, got %+v, %v", links, err)
+	}
+	broken, total, err := repo.List(ctx, &domain.DatasetFilter{LinkStatus: string(domain.LinkStatusBroken)}, 10, 0, "name", "asc")
+	if err != nil {
+		t.Fatalf("Expected no error listing, got %v", err)
+	}
+	if total != 1 || broken[0].LinkError == nil || *broken[0].LinkError != linkError {
+		t.Errorf("Expected the dataset with its broken link, got %d %+v", total, broken)
+	}
+
+	moved := "https://bps.example.com/kependudukan"
+	dataset.SourceURL = &moved
+	if err := repo.Update(ctx, dataset, nil); err != nil {
+		t.Fatalf("Expected no error updating, got %v", err)
+	}
+	got, err := repo.GetByID(ctx, dataset.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got.LinkStatus != nil || got.LinkCheckedAt != nil || got.LinkError != nil {
+		t.Errorf("Expected a new source URL to clear the last check, got %+v", got)
+	}
+
+	if err := repo.SetLinkStatus(ctx, "20000000-0000-0000-0000-000000000009", domain.LinkStatusOK, nil, now); !errors.Is(err, pkgErrors.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound checking the link of a missing dataset, got %v", err)
+	}
+}
+
+// Test lint reports replace the previous one of their dataset and are
+// listed and counted by rule within the tenant, leaving out deleted datasets
+func TestDatasetPostgresRepository_LintReports(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+	checkedAt := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+
+	for id, warnings := range map[string]string{
+		"20000000-0000-0000-0000-000000000001": `[{"rule": "no_license", "field": "metadatas", "message": ""}]`,
+		"20000000-0000-0000-0000-000000000002": `[{"rule": "missing_description", "field": "description", "message": ""}, {"rule": "no_license", "field": "metadatas", "message": ""}]`,
+		"20000000-0000-0000-0000-000000000004": `[{"rule": "no_tags", "field": "tag_ids", "message": ""}]`,
+		"20000000-0000-0000-0000-000000000005": `[{"rule": "no_license", "field": "metadatas", "message": ""}]`,
+	} {
+		report := &domain.LintReport{DatasetID: id, Warnings: warnings, WarningCount: strings.Count(warnings, "rule"), CheckedAt: checkedAt}
+		if err := repo.SaveLintReport(ctx, report); err != nil {
+			t.Fatalf("Expected no error saving the report of %s, got %v", id, err)
+		}
+	}
+	fixed := &domain.LintReport{DatasetID: "20000000-0000-0000-0000-000000000001", Warnings: `[]`, CheckedAt: checkedAt.Add(time.Hour)}
+	if err := repo.SaveLintReport(ctx, fixed); err != nil {
+		t.Fatalf("Expected no error replacing a report, got %v", err)
+	}
+
+	scoped := tenant.WithTenant(ctx, &tenant.Tenant{ID: "default"})
+	reports, total, err := repo.LintReports(scoped, &domain.LintReportFilter{}, 10, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if total != 2 || reports[0].DatasetID != "20000000-0000-0000-0000-000000000002" || reports[0].WarningCount != 2 || reports[0].Name != "Cakupan Imunisasi Dasar" {
+		t.Errorf("Expected the reports of the tenant, most warnings first, got %d %+v", total, reports)
+	}
+	if reports[1].WarningCount != 0 || !reports[1].CheckedAt.Equal(fixed.CheckedAt) {
+		t.Errorf("Expected the replaced report, got %+v", reports[1])
+	}
+
+	failing, total, err := repo.LintReports(scoped, &domain.LintReportFilter{Rule: string(domain.LintNoLicense)}, 10, 0)
+	if err != nil || total != 1 || failing[0].DatasetID != "20000000-0000-0000-0000-000000000002" {
+		t.Errorf("Expected the dataset without a license, got %d %+v (%v)", total, failing, err)
+	}
+
+	counts, err := repo.LintRuleCounts(scoped, "")
+	if err != nil {
+		t.Fatalf("Expected no error counting, got %v", err)
+	}
+	if want := map[string]int{"missing_description": 1, "no_license": 1}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("Expected %v, got %v", want, counts)
+	}
+	if counts, err := repo.LintRuleCounts(scoped, "10000000-0000-0000-0000-000000000001"); err != nil || len(counts) != 0 {
+		t.Errorf("Expected no warnings in the organization, got %v (%v)", counts, err)
+	}
+}
+
+// Test collaborator grants replace the previous role of their grantee and
+// are matched to users directly or through their organization
+func TestDatasetPostgresRepository_Collaborators(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+	datasetID := "20000000-0000-0000-0000-000000000001"
+	grantedAt := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+
+	user := &domain.Collaborator{ID: "70000000-0000-0000-0000-000000000001", DatasetID: datasetID, GranteeType: domain.GranteeUser,
+		GranteeID: "90000000-0000-0000-0000-000000000005", Role: domain.CollaboratorView, UpdatedAt: grantedAt}
+	org := &domain.Collaborator{ID: "70000000-0000-0000-0000-000000000002", DatasetID: datasetID, GranteeType: domain.GranteeOrganization,
+		GranteeID: "10000000-0000-0000-0000-000000000002", Role: domain.CollaboratorEdit, UpdatedAt: grantedAt}
+	for _, collaborator := range []*domain.Collaborator{user, org} {
+		if err := repo.SaveCollaborator(ctx, collaborator); err != nil {
+			t.Fatalf("Expected no error granting %s, got %v", collaborator.GranteeID, err)
+		}
+	}
+	promoted := &domain.Collaborator{ID: "70000000-0000-0000-0000-000000000003", DatasetID: datasetID, GranteeType: domain.GranteeUser,
+		GranteeID: user.GranteeID, Role: domain.CollaboratorPublish, UpdatedAt: grantedAt.Add(time.Hour)}
+	if err := repo.SaveCollaborator(ctx, promoted); err != nil {
+		t.Fatalf("Expected no error replacing a grant, got %v", err)
+	}
+	if promoted.ID != user.ID || !promoted.CreatedAt.Equal(grantedAt) {
+		t.Errorf("Expected the grant of the user to be kept, got %+v", promoted)
+	}
+
+	collaborators, err := repo.ListCollaborators(ctx, datasetID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(collaborators) != 2 || collaborators[0].Role != domain.CollaboratorPublish || collaborators[1].GranteeType != domain.GranteeOrganization {
+		t.Errorf("Expected both grants, the user promoted, got %+v", collaborators)
+	}
+
+	roles, err := repo.CollaboratorRoles(ctx, datasetID, user.GranteeID, org.GranteeID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(roles) != 2 {
+		t.Errorf("Expected the roles granted to the user and their organization, got %v", roles)
+	}
+	if roles, err := repo.CollaboratorRoles(ctx, datasetID, "90000000-0000-0000-0000-000000000006", "10000000-0000-0000-0000-000000000003"); err != nil || len(roles) != 0 {
+		t.Errorf("Expected no roles for others, got %v (%v)", roles, err)
+	}
+
+	if err := repo.DeleteCollaborator(ctx, datasetID, org.ID); err != nil {
+		t.Fatalf("Expected no error revoking, got %v", err)
+	}
+	if err := repo.DeleteCollaborator(ctx, datasetID, org.ID); !errors.Is(err, pkgErrors.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound revoking twice, got %v", err)
+	}
+}
+
+// Test deleted datasets are listed in the trash and purged for good with
+// their data rows, once deleted long enough
+func TestDatasetPostgresRepository_Trash(t *testing.T) {
+	conn := testenv.Postgres(t)
+	testenv.Fixtures(t, conn, "catalog", "data_rows")
+	repo := NewDatasetPostgresRepository(db.NewRouter(conn))
+	ctx := context.Background()
+
+	trash, total, err := repo.Trash(ctx, "", 10, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if total != 1 || trash[0].ID != "20000000-0000-0000-0000-000000000005" || trash[0].DeletedAt.IsZero() {
+		t.Fatalf("Expected the deleted dataset in the trash, got %d %+v", total, trash)
+	}
+	if _, total, err := repo.Trash(ctx, "10000000-0000-0000-0000-000000000002", 10, 0); err != nil || total != 0 {
+		t.Errorf("Expected no deleted dataset of another organization, got %d, %v", total, err)
+	}
+
+	population := "20000000-0000-0000-0000-000000000001"
+	if err := repo.Purge(ctx, population); !errors.Is(err, pkgErrors.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound purging a dataset that is not deleted, got %v", err)
+	}
+	if err := repo.Delete(ctx, population); err != nil {
+		t.Fatalf("Expected no error deleting, got %v", err)
+	}
+
+	ids, err := repo.DeletedBefore(ctx, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), 10)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(ids) != 1 || ids[0] != "20000000-0000-0000-0000-000000000005" {
+		t.Fatalf("Expected only the dataset deleted before then to be due, got %v", ids)
+	}
+	if ids, err := repo.DeletedBefore(ctx, time.Now().Add(time.Hour), 10); err != nil || len(ids) != 2 {
+		t.Fatalf("Expected both deleted datasets to be due later, got %v, %v", ids, err)
+	}
+
+	if err := repo.Purge(ctx, population); err != nil {
+		t.Fatalf("Expected no error purging, got %v", err)
+	}
+	if _, err := repo.GetByID(db.WithDeleted(ctx), population); !errors.Is(err, pkgErrors.ErrNotFound) {
+		t.Errorf("Expected the purged dataset to be gone, got %v", err)
+	}
+	var rows int
+	if err := conn.Get(&rows, `SELECT COUNT(*) FROM data_rows WHERE dataset_id = $1`, population); err != nil || rows != 0 {
+		t.Errorf("Expected the rows of the purged dataset to be gone, got %d, %v", rows, err)
+	}
+}
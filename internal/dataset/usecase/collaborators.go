@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"portal-data-backend/infrastructure/audit"
+	"portal-data-backend/internal/dataset/domain"
+	orgDomain "portal-data-backend/internal/organization/domain"
+	userDomain "portal-data-backend/internal/user/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// UserReader is the part of the user module datasets are shared with
+type UserReader interface {
+	GetUserByID(ctx context.Context, id string) (*userDomain.UserInfo, error)
+}
+
+// OrganizationReader is the part of the organization module datasets are
+// shared with
+type OrganizationReader interface {
+	GetByID(ctx context.Context, id string) (*orgDomain.OrganizationResponse, error)
+}
+
+// Grantees are the modules the users and organizations datasets are shared
+// with are read from
+type Grantees struct {
+	Users         UserReader
+	Organizations OrganizationReader
+}
+
+// Authorize lets caller do what role grants with the dataset id. Admins and
+// the owners of the dataset, its organization and its creator, may do
+// anything; others need a collaborator grant of role, to them or to their
+// organization.
+func (u *datasetUsecase) Authorize(ctx context.Context, id string, caller domain.Caller, role domain.CollaboratorRole) error {
+	if caller.Admin {
+		return nil
+	}
+	dataset, err := u.datasetRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get dataset: %w", err)
+	}
+	if owns(dataset, caller) {
+		return nil
+	}
+
+	granted, err := u.datasetRepo.CollaboratorRoles(ctx, id, caller.UserID, caller.OrganizationID)
+	if err != nil {
+		return err
+	}
+	for _, grant := range granted {
+		if grant.Grants(role) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: you need the %s role on the dataset", pkgErrors.ErrForbidden, role)
+}
+
+// owns reports whether caller created dataset or belongs to its organization
+func owns(dataset *domain.Dataset, caller domain.Caller) bool {
+	return (caller.OrganizationID != "" && dataset.OrganizationID == caller.OrganizationID) ||
+		(caller.UserID != "" && dataset.CreatedBy == caller.UserID)
+}
+
+// ListCollaborators retrieves the collaborators of a dataset for its
+// owners and collaborators
+func (u *datasetUsecase) ListCollaborators(ctx context.Context, id string, caller domain.Caller) ([]*domain.Collaborator, error) {
+	if err := u.Authorize(ctx, id, caller, domain.CollaboratorView); err != nil {
+		return nil, err
+	}
+	return u.datasetRepo.ListCollaborators(ctx, id)
+}
+
+// AddCollaborator grants a role on a dataset to a user or organization,
+// replacing the role granted to them before. Only owners and admins manage
+// the collaborators of a dataset.
+func (u *datasetUsecase) AddCollaborator(ctx context.Context, id string, req *domain.AddCollaboratorRequest, caller domain.Caller) (*domain.Collaborator, error) {
+	dataset, err := u.manageable(ctx, id, caller)
+	if err != nil {
+		return nil, err
+	}
+
+	collaborator := &domain.Collaborator{
+		ID:          uuid.New().String(),
+		DatasetID:   dataset.ID,
+		GranteeType: domain.GranteeType(req.GranteeType),
+		GranteeID:   req.GranteeID,
+		Role:        domain.CollaboratorRole(req.Role),
+		UpdatedAt:   time.Now(),
+	}
+	if caller.UserID != "" {
+		collaborator.GrantedBy = &caller.UserID
+	}
+	if err := u.checkGrantee(ctx, dataset, collaborator); err != nil {
+		return nil, err
+	}
+
+	err = u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.datasetRepo.SaveCollaborator(ctx, collaborator); err != nil {
+			return err
+		}
+		u.audit.Record(ctx, "dataset_collaborators", collaborator.ID, audit.ActionCreate, nil, collaborator)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return collaborator, nil
+}
+
+// RemoveCollaborator revokes the grant collaboratorID on a dataset
+func (u *datasetUsecase) RemoveCollaborator(ctx context.Context, id, collaboratorID string, caller domain.Caller) error {
+	if _, err := u.manageable(ctx, id, caller); err != nil {
+		return err
+	}
+	collaborators, err := u.datasetRepo.ListCollaborators(ctx, id)
+	if err != nil {
+		return err
+	}
+	var revoked *domain.Collaborator
+	for _, collaborator := range collaborators {
+		if collaborator.ID == collaboratorID {
+			revoked = collaborator
+		}
+	}
+	if revoked == nil {
+		return fmt.Errorf("%w: dataset %s has no collaborator %s", pkgErrors.ErrNotFound, id, collaboratorID)
+	}
+
+	return u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.datasetRepo.DeleteCollaborator(ctx, id, collaboratorID); err != nil {
+			return err
+		}
+		u.audit.Record(ctx, "dataset_collaborators", collaboratorID, audit.ActionDelete, revoked, nil)
+		return nil
+	})
+}
+
+// manageable returns the dataset id when caller may manage its
+// collaborators
+func (u *datasetUsecase) manageable(ctx context.Context, id string, caller domain.Caller) (*domain.Dataset, error) {
+	dataset, err := u.datasetRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dataset: %w", err)
+	}
+	if !caller.Admin && !owns(dataset, caller) {
+		return nil, fmt.Errorf("%w: only the owners of a dataset manage its collaborators", pkgErrors.ErrForbidden)
+	}
+	return dataset, nil
+}
+
+// checkGrantee checks the grantee of collaborator exists and is not an
+// owner of dataset already
+func (u *datasetUsecase) checkGrantee(ctx context.Context, dataset *domain.Dataset, collaborator *domain.Collaborator) error {
+	var err error
+	switch collaborator.GranteeType {
+	case domain.GranteeUser:
+		if collaborator.GranteeID == dataset.CreatedBy {
+			return fmt.Errorf("%w: the user created the dataset and owns it already", pkgErrors.ErrInvalidInput)
+		}
+		_, err = u.grantees.Users.GetUserByID(ctx, collaborator.GranteeID)
+	case domain.GranteeOrganization:
+		if collaborator.GranteeID == dataset.OrganizationID {
+			return fmt.Errorf("%w: the organization owns the dataset already", pkgErrors.ErrInvalidInput)
+		}
+		_, err = u.grantees.Organizations.GetByID(ctx, collaborator.GranteeID)
+	}
+	if errors.Is(err, pkgErrors.ErrNotFound) {
+		return fmt.Errorf("%w: %s %s does not exist", pkgErrors.ErrInvalidInput, collaborator.GranteeType, collaborator.GranteeID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get %s: %w", collaborator.GranteeType, err)
+	}
+	return nil
+}
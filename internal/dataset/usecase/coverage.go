@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"fmt"
+	"time"
+
+	"portal-data-backend/internal/dataset/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+)
+
+// setCoverage sets the coverage and frequency of dataset from the dates like
+// 2006-01-02 and the frequency of a create or update request. Empty values
+// leave them unset.
+func setCoverage(dataset *domain.Dataset, start, end, frequency string) error {
+	coverageStart, err := parseDate("coverage_start", start)
+	if err != nil {
+		return err
+	}
+	coverageEnd, err := parseDate("coverage_end", end)
+	if err != nil {
+		return err
+	}
+	if coverageStart != nil && coverageEnd != nil && coverageEnd.Before(*coverageStart) {
+		return fmt.Errorf("%w: coverage_end must not be before coverage_start", pkgErrors.ErrInvalidInput)
+	}
+	coverageFrequency, err := parseFrequency(frequency)
+	if err != nil {
+		return err
+	}
+
+	dataset.CoverageStart, dataset.CoverageEnd, dataset.Frequency = coverageStart, coverageEnd, coverageFrequency
+	return nil
+}
+
+// coverageFilter adds the coverage and frequency filters of req to filter
+func coverageFilter(filter *domain.DatasetFilter, req *domain.ListDatasetsRequest) error {
+	from, err := parseDate("coverage_from", req.CoverageFrom)
+	if err != nil {
+		return err
+	}
+	to, err := parseDate("coverage_to", req.CoverageTo)
+	if err != nil {
+		return err
+	}
+	if from != nil && to != nil && to.Before(*from) {
+		return fmt.Errorf("%w: coverage_to must not be before coverage_from", pkgErrors.ErrInvalidInput)
+	}
+	if _, err := parseFrequency(req.Frequency); err != nil {
+		return err
+	}
+
+	filter.CoverageFrom, filter.CoverageTo, filter.Frequency = from, to, req.Frequency
+	return nil
+}
+
+// parseDate reads the date like 2006-01-02 of field, nil when it is empty
+func parseDate(field, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s must be a date like 2006-01-02", pkgErrors.ErrInvalidInput, field)
+	}
+	return &date, nil
+}
+
+// parseFrequency reads a frequency, nil when it is empty
+func parseFrequency(value string) (*domain.Frequency, error) {
+	if value == "" {
+		return nil, nil
+	}
+	frequency := domain.Frequency(value)
+	if !frequency.Valid() {
+		return nil, fmt.Errorf("%w: frequency must be one of %v", pkgErrors.ErrInvalidInput, domain.Frequencies)
+	}
+	return &frequency, nil
+}
+
+// formatDate writes date like 2006-01-02, nil when it is
+func formatDate(date *time.Time) *string {
+	if date == nil {
+		return nil
+	}
+	formatted := date.Format(time.DateOnly)
+	return &formatted
+}
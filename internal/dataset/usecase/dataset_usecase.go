@@ -2,25 +2,79 @@ package usecase
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"math"
+	"net/http"
 	"strings"
 	"time"
 
+	"portal-data-backend/infrastructure/audit"
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/infrastructure/cache"
+	"portal-data-backend/infrastructure/db"
+	"portal-data-backend/infrastructure/tenant"
+	"portal-data-backend/infrastructure/logger"
 	"portal-data-backend/internal/dataset/domain"
+	"portal-data-backend/pkg/i18n"
 
 	"github.com/google/uuid"
 )
 
 // datasetUsecase implements Usecase interface
 type datasetUsecase struct {
-	datasetRepo domain.Repository
+	datasetRepo   domain.Repository
+	orgs          domain.OrganizationCounter
+	tx            db.Transactor
+	events        domain.EventPublisher
+	searcher      domain.Searcher
+	bySlug        *cache.Namespace
+	surrogates    *cache.Surrogates
+	audit         *audit.Recorder
+	highlights    config.HighlightConfig
+	notifications NotificationSender
+	messages      MessageRenderer
+	linkCheck     config.LinkCheckConfig
+	client        *http.Client
+	files         FileRemover
+	trash         config.TrashConfig
+	taxonomies    Taxonomies
+	grantees      Grantees
 }
 
-// NewDatasetUsecase creates a new dataset usecase
-func NewDatasetUsecase(datasetRepo domain.Repository) Usecase {
+// NewDatasetUsecase creates a new dataset usecase. Creating and deleting a
+// dataset updates the counters of its organization in the same transaction.
+// events may be nil. searcher may be nil, then the repository searches
+// datasets itself. bySlug caches datasets looked up by slug and may be nil,
+// as may surrogates, which purges the cached responses showing datasets.
+// recorder audits changes in their transaction and may be nil. highlights
+// caps and expires the datasets highlighted on the homepage. Source URLs are
+// checked as linkCheck configures; owners of datasets whose source breaks are
+// notified through notifications, which may be nil. Deleted datasets stay in
+// the trash as trash configures; purging them deletes their files through
+// files, which may be nil. The tags, unit, business field and topic of
+// datasets are read through taxonomies, and the users and organizations
+// datasets are shared with through grantees.
+func NewDatasetUsecase(datasetRepo domain.Repository, orgs domain.OrganizationCounter, tx db.Transactor, events domain.EventPublisher, searcher domain.Searcher, bySlug *cache.Namespace, surrogates *cache.Surrogates, recorder *audit.Recorder, highlights config.HighlightConfig, notifications NotificationSender, messages MessageRenderer, linkCheck config.LinkCheckConfig, files FileRemover, trash config.TrashConfig, taxonomies Taxonomies, grantees Grantees) Usecase {
 	return &datasetUsecase{
-		datasetRepo: datasetRepo,
+		datasetRepo:   datasetRepo,
+		orgs:          orgs,
+		tx:            tx,
+		events:        events,
+		searcher:      searcher,
+		bySlug:        bySlug,
+		surrogates:    surrogates,
+		audit:         recorder,
+		highlights:    highlights,
+		notifications: notifications,
+		messages:      messages,
+		linkCheck:     linkCheck,
+		client:        &http.Client{Timeout: linkCheck.Timeout},
+		files:         files,
+		trash:         trash,
+		taxonomies:    taxonomies,
+		grantees:      grantees,
 	}
 }
 
@@ -29,15 +83,55 @@ func (u *datasetUsecase) GetByID(ctx context.Context, id string) (*domain.Datase
 	if err != nil {
 		return nil, fmt.Errorf("failed to get dataset: %w", err)
 	}
-	return u.toResponse(dataset), nil
+	responses, err := u.toResponses(ctx, dataset)
+	if err != nil {
+		return nil, err
+	}
+	return u.localize(responses[0], i18n.Languages(ctx)), nil
 }
 
+// GetByIDs loads the datasets in one query. Their tags are left out, to be
+// loaded with TagsByDatasetIDs when needed.
+func (u *datasetUsecase) GetByIDs(ctx context.Context, ids []string) ([]*domain.DatasetResponse, error) {
+	datasets, err := u.datasetRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get datasets: %w", err)
+	}
+
+	responses, err := u.toResponses(ctx, datasets...)
+	if err != nil {
+		return nil, err
+	}
+	langs := i18n.Languages(ctx)
+	for _, resp := range responses {
+		u.localize(resp, langs)
+	}
+	return responses, nil
+}
+
+// GetBySlug returns the dataset with slug. Datasets are cached untranslated
+// and translated per request. Reads including deleted datasets skip the
+// cache, which only holds live ones.
 func (u *datasetUsecase) GetBySlug(ctx context.Context, slug string) (*domain.DatasetResponse, error) {
+	cacheable := !db.IncludesDeleted(ctx)
+	var resp domain.DatasetResponse
+	if cacheable && u.bySlug.Get(ctx, slug, &resp) {
+		return u.localize(&resp, i18n.Languages(ctx)), nil
+	}
+
 	dataset, err := u.datasetRepo.GetBySlug(ctx, slug)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get dataset: %w", err)
 	}
-	return u.toResponse(dataset), nil
+	responses, err := u.toResponses(ctx, dataset)
+	if err != nil {
+		return nil, err
+	}
+	cached := responses[0]
+	if cacheable {
+		u.bySlug.Set(ctx, slug, cached)
+	}
+	return u.localize(cached, i18n.Languages(ctx)), nil
 }
 
 func (u *datasetUsecase) List(ctx context.Context, req *domain.ListDatasetsRequest) (*domain.DatasetListResponse, error) {
@@ -61,8 +155,12 @@ func (u *datasetUsecase) List(ctx context.Context, req *domain.ListDatasetsReque
 		Status:           req.Status,
 		ValidationStatus: req.ValidationStatus,
 		Classification:   req.Classification,
+		LinkStatus:       req.LinkStatus,
 		Search:           req.Search,
 	}
+	if err := coverageFilter(filter, req); err != nil {
+		return nil, err
+	}
 
 	sortBy := req.SortBy
 	if sortBy == "" {
@@ -73,19 +171,33 @@ func (u *datasetUsecase) List(ctx context.Context, req *domain.ListDatasetsReque
 		sortOrder = "DESC"
 	}
 
-	datasets, total, err := u.datasetRepo.List(ctx, filter, req.Limit, offset, sortBy, sortOrder)
+	var datasets []*domain.Dataset
+	var total int
+	var err error
+	// The search index only holds live datasets and does not know tenants,
+	// link checks or coverage
+	if filter.Search != "" && u.searcher != nil && !db.IncludesDeleted(ctx) && tenant.ID(ctx) == "" && filter.LinkStatus == "" &&
+		filter.CoverageFrom == nil && filter.CoverageTo == nil && filter.Frequency == "" {
+		datasets, total, err = u.search(ctx, filter, req.Limit, offset)
+	} else {
+		datasets, total, err = u.datasetRepo.List(ctx, filter, req.Limit, offset, sortBy, sortOrder)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to list datasets: %w", err)
 	}
 
-	responses := make([]domain.DatasetResponse, len(datasets))
-	for i, ds := range datasets {
-		responses[i] = *u.toResponse(ds)
+	converted, err := u.toResponses(ctx, datasets...)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]domain.DatasetResponse, len(converted))
+	for i, resp := range converted {
+		responses[i] = *u.localize(resp, i18n.Languages(ctx))
 	}
 
 	totalPage := int(math.Ceil(float64(total) / float64(req.Limit)))
 
-	return &domain.DatasetListResponse{
+	resp := &domain.DatasetListResponse{
 		Datasets: responses,
 		Meta: domain.ListMeta{
 			Page:      req.Page,
@@ -93,10 +205,120 @@ func (u *datasetUsecase) List(ctx context.Context, req *domain.ListDatasetsReque
 			Total:     total,
 			TotalPage: totalPage,
 		},
-	}, nil
+	}
+	if filter.Search != "" && total < suggestBelow {
+		resp.Suggestions = u.suggest(ctx, filter)
+	}
+	return resp, nil
+}
+
+// search lists the datasets found by the searcher, best match first. Datasets
+// the index still has but the database no longer does are left out.
+func (u *datasetUsecase) search(ctx context.Context, filter *domain.DatasetFilter, limit, offset int) ([]*domain.Dataset, int, error) {
+	ids, total, err := u.searcher.Search(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	datasets := make([]*domain.Dataset, 0, len(ids))
+	for _, id := range ids {
+		dataset, err := u.datasetRepo.GetByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			return nil, 0, err
+		}
+		datasets = append(datasets, dataset)
+	}
+	return datasets, total, nil
+}
+
+// Limits of search suggestions. Searches finding fewer datasets than
+// suggestBelow get suggestions.
+const (
+	suggestBelow     = 3
+	maxSuggestTerms  = 5
+	maxQuerySuggests = 3
+	maxTitleSuggests = 5
+	wordsPerTerm     = 3
+)
+
+// suggest finds "did you mean" hints for the search of filter: the search with
+// misspelled words replaced by close words of dataset names, and datasets
+// whose name is close to the search. It returns nil when there are none.
+// Failures are logged, the search results are still useful without hints.
+func (u *datasetUsecase) suggest(ctx context.Context, filter *domain.DatasetFilter) *domain.SearchSuggestions {
+	text := strings.ToLower(strings.TrimSpace(filter.Search))
+	terms := strings.Fields(text)
+	if len(terms) > maxSuggestTerms {
+		terms = terms[:maxSuggestTerms]
+	}
+
+	// best holds the correction of each term, alternatives the other close words
+	best := make([]string, len(terms))
+	alternatives := make([][]string, len(terms))
+	for i, term := range terms {
+		best[i] = term
+		words, err := u.datasetRepo.SimilarWords(ctx, filter, term, wordsPerTerm)
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to suggest words for %q: %v", term, err)
+			return nil
+		}
+		if len(words) == 0 || contains(words, term) {
+			continue
+		}
+		best[i] = words[0]
+		alternatives[i] = words[1:]
+	}
+
+	var queries []string
+	addQuery := func(words []string) {
+		query := strings.Join(words, " ")
+		if query != strings.Join(terms, " ") && !contains(queries, query) && len(queries) < maxQuerySuggests {
+			queries = append(queries, query)
+		}
+	}
+	addQuery(best)
+	for i := range terms {
+		for _, word := range alternatives[i] {
+			words := append([]string{}, best...)
+			words[i] = word
+			addQuery(words)
+		}
+	}
+
+	datasets, err := u.datasetRepo.SimilarTitles(ctx, filter, text, maxTitleSuggests)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to suggest titles for %q: %v", text, err)
+		return nil
+	}
+
+	if len(queries) == 0 && len(datasets) == 0 {
+		return nil
+	}
+	if queries == nil {
+		queries = []string{}
+	}
+	if datasets == nil {
+		datasets = []domain.DatasetSuggestion{}
+	}
+	return &domain.SearchSuggestions{Queries: queries, Datasets: datasets}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
 }
 
 func (u *datasetUsecase) Create(ctx context.Context, req *domain.CreateDatasetRequest, creatorID, orgID string) (*domain.DatasetResponse, error) {
+	if err := validateSourceURL(req.SourceURL); err != nil {
+		return nil, err
+	}
 	now := time.Now()
 
 	validationStatus := domain.ValidationStatusPending
@@ -123,8 +345,8 @@ func (u *datasetUsecase) Create(ctx context.Context, req *domain.CreateDatasetRe
 	if req.Description != "" {
 		dataset.Description = &req.Description
 	}
-	if req.Period != "" {
-		dataset.Period = &req.Period
+	if err := setCoverage(dataset, req.CoverageStart, req.CoverageEnd, req.Frequency); err != nil {
+		return nil, err
 	}
 	if req.UnitID != "" {
 		dataset.UnitID = &req.UnitID
@@ -144,26 +366,73 @@ func (u *datasetUsecase) Create(ctx context.Context, req *domain.CreateDatasetRe
 	if req.Metadata != "" {
 		dataset.Metadata = &req.Metadata
 	}
-
-	if err := u.datasetRepo.Create(ctx, dataset, req.TagIDs); err != nil {
-		return nil, fmt.Errorf("failed to create dataset: %w", err)
-	}
-
-	// Fetch full dataset with relations
-	fullDataset, err := u.datasetRepo.GetByID(ctx, dataset.ID)
+	if req.SourceURL != "" {
+		dataset.SourceURL = &req.SourceURL
+	}
+	if err := u.setTranslations(dataset, req.Names, req.Descriptions); err != nil {
+		return nil, err
+	}
+
+	var resp *domain.DatasetResponse
+	var warnings []domain.LintWarning
+	err := u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.datasetRepo.Create(ctx, dataset, req.TagIDs); err != nil {
+			return fmt.Errorf("failed to create dataset: %w", err)
+		}
+		if err := u.orgs.IncrementDatasetCount(ctx, orgID, isPublic(dataset)); err != nil {
+			return fmt.Errorf("failed to update organization counters: %w", err)
+		}
+		if dataset.IsHighlight {
+			if _, err := u.addHighlight(ctx, dataset, nil, creatorID); err != nil {
+				return err
+			}
+		}
+
+		// Fetch full dataset with relations
+		fullDataset, err := u.datasetRepo.GetByID(ctx, dataset.ID)
+		if err != nil {
+			return fmt.Errorf("failed to fetch created dataset: %w", err)
+		}
+		u.audit.Record(ctx, "datasets", dataset.ID, audit.ActionCreate, nil, fullDataset)
+		warnings, err = u.saveLint(ctx, fullDataset, now)
+		if err != nil {
+			return err
+		}
+
+		responses, err := u.toResponses(ctx, fullDataset)
+		if err != nil {
+			return err
+		}
+		resp = responses[0]
+		if err := u.publish(ctx, domain.EventDatasetCreated, resp); err != nil {
+			return err
+		}
+		if dataset.ValidationStatus == domain.ValidationStatusPending {
+			return u.publish(ctx, domain.EventDatasetPendingReview, resp)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch created dataset: %w", err)
+		return nil, err
 	}
+	resp.LintWarnings = warnings
+	u.purge(ctx, dataset)
 
-	return u.toResponse(fullDataset), nil
+	return resp, nil
 }
 
 func (u *datasetUsecase) Update(ctx context.Context, id string, req *domain.UpdateDatasetRequest, updaterID string) (*domain.DatasetResponse, error) {
+	if err := validateSourceURL(req.SourceURL); err != nil {
+		return nil, err
+	}
 	dataset, err := u.datasetRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get dataset: %w", err)
 	}
 
+	before := *dataset
+	previousSlug := dataset.Slug
+	wasPublic := isPublic(dataset)
 	dataset.Name = req.Name
 	dataset.Slug = u.generateSlug(req.Name)
 	dataset.Classification = req.Classification
@@ -180,10 +449,8 @@ func (u *datasetUsecase) Update(ctx context.Context, id string, req *domain.Upda
 	} else {
 		dataset.Description = nil
 	}
-	if req.Period != "" {
-		dataset.Period = &req.Period
-	} else {
-		dataset.Period = nil
+	if err := setCoverage(dataset, req.CoverageStart, req.CoverageEnd, req.Frequency); err != nil {
+		return nil, err
 	}
 	if req.UnitID != "" {
 		dataset.UnitID = &req.UnitID
@@ -215,35 +482,206 @@ func (u *datasetUsecase) Update(ctx context.Context, id string, req *domain.Upda
 	} else {
 		dataset.Metadata = nil
 	}
+	if req.SourceURL != "" {
+		dataset.SourceURL = &req.SourceURL
+	} else {
+		dataset.SourceURL = nil
+	}
+	if err := u.setTranslations(dataset, req.Names, req.Descriptions); err != nil {
+		return nil, err
+	}
 
+	previousValidation := dataset.ValidationStatus
 	if req.ValidationStatus != "" {
 		dataset.ValidationStatus = domain.ValidationStatus(req.ValidationStatus)
 	}
 
-	if err := u.datasetRepo.Update(ctx, dataset, req.TagIDs); err != nil {
-		return nil, fmt.Errorf("failed to update dataset: %w", err)
-	}
-
-	// Fetch full dataset with relations
-	fullDataset, err := u.datasetRepo.GetByID(ctx, dataset.ID)
+	var resp *domain.DatasetResponse
+	var warnings []domain.LintWarning
+	err = u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.datasetRepo.Update(ctx, dataset, req.TagIDs); err != nil {
+			return fmt.Errorf("failed to update dataset: %w", err)
+		}
+		if wasPublic != isPublic(dataset) && dataset.Status != domain.DatasetStatusArchived {
+			// Move the dataset between the public and non-public counters
+			if err := u.orgs.DecrementDatasetCount(ctx, dataset.OrganizationID, wasPublic); err != nil {
+				return fmt.Errorf("failed to update organization counters: %w", err)
+			}
+			if err := u.orgs.IncrementDatasetCount(ctx, dataset.OrganizationID, isPublic(dataset)); err != nil {
+				return fmt.Errorf("failed to update organization counters: %w", err)
+			}
+		}
+		if err := u.syncHighlight(ctx, dataset, before.IsHighlight, updaterID); err != nil {
+			return err
+		}
+
+		// Fetch full dataset with relations
+		fullDataset, err := u.datasetRepo.GetByID(ctx, dataset.ID)
+		if err != nil {
+			return fmt.Errorf("failed to fetch updated dataset: %w", err)
+		}
+		u.audit.Record(ctx, "datasets", dataset.ID, audit.ActionUpdate, &before, fullDataset)
+		warnings, err = u.saveLint(ctx, fullDataset, dataset.UpdatedAt)
+		if err != nil {
+			return err
+		}
+
+		responses, err := u.toResponses(ctx, fullDataset)
+		if err != nil {
+			return err
+		}
+		resp = responses[0]
+		if err := u.publish(ctx, domain.EventDatasetUpdated, resp); err != nil {
+			return err
+		}
+		if dataset.ValidationStatus == domain.ValidationStatusPending && previousValidation != domain.ValidationStatusPending {
+			return u.publish(ctx, domain.EventDatasetPendingReview, resp)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch updated dataset: %w", err)
+		return nil, err
 	}
+	resp.LintWarnings = warnings
+	u.bySlug.Delete(ctx, previousSlug, dataset.Slug)
+	u.purge(ctx, dataset)
 
-	return u.toResponse(fullDataset), nil
+	return resp, nil
 }
 
 func (u *datasetUsecase) Delete(ctx context.Context, id string) error {
-	if err := u.datasetRepo.Delete(ctx, id); err != nil {
-		return fmt.Errorf("failed to delete dataset: %w", err)
+	dataset, err := u.datasetRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get dataset: %w", err)
+	}
+	err = u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.datasetRepo.Delete(ctx, id); err != nil {
+			return fmt.Errorf("failed to delete dataset: %w", err)
+		}
+		u.audit.Record(ctx, "datasets", id, audit.ActionDelete, dataset, nil)
+		if err := u.recount(ctx, dataset, domain.DatasetStatusArchived); err != nil {
+			return err
+		}
+		return u.publish(ctx, domain.EventDatasetDeleted, map[string]string{"id": id})
+	})
+	if err != nil {
+		return err
 	}
+	u.bySlug.Delete(ctx, dataset.Slug)
+	u.purge(ctx, dataset)
+	return nil
+}
+
+// Restore brings back a deleted dataset with the status it had. It counts
+// towards its organization again unless it was archived.
+func (u *datasetUsecase) Restore(ctx context.Context, id string) error {
+	dataset, err := u.datasetRepo.GetByID(db.WithDeleted(ctx), id)
+	if err != nil {
+		return fmt.Errorf("failed to get dataset: %w", err)
+	}
+	restored := *dataset
+	restored.DeletedAt = nil
+	err = u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.datasetRepo.Restore(ctx, id); err != nil {
+			return fmt.Errorf("failed to restore dataset: %w", err)
+		}
+		u.audit.Record(ctx, "datasets", id, audit.ActionRestore, dataset, &restored)
+		if dataset.Status != domain.DatasetStatusArchived {
+			if err := u.orgs.IncrementDatasetCount(ctx, dataset.OrganizationID, isPublic(dataset)); err != nil {
+				return fmt.Errorf("failed to update organization counters: %w", err)
+			}
+		}
+		responses, err := u.toResponses(ctx, &restored)
+		if err != nil {
+			return err
+		}
+		return u.publish(ctx, domain.EventDatasetRestored, responses[0])
+	})
+	if err != nil {
+		return err
+	}
+	u.bySlug.Delete(ctx, dataset.Slug)
+	u.purge(ctx, dataset)
 	return nil
 }
 
 func (u *datasetUsecase) UpdateStatus(ctx context.Context, id string, status domain.DatasetStatus) error {
+	return u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		return u.updateStatus(ctx, id, status)
+	})
+}
+
+// BulkUpdateStatus updates the status of the datasets of ids in one
+// transaction, returning the errors of those it could not update
+func (u *datasetUsecase) BulkUpdateStatus(ctx context.Context, ids []string, status domain.DatasetStatus) (map[string]error, error) {
+	return db.Each(ctx, u.tx, ids, func(ctx context.Context, id string) error {
+		return u.updateStatus(ctx, id, status)
+	})
+}
+
+// updateStatus updates the status of a dataset in the transaction ctx
+// carries, recording its events in it. Its cache follows the commit.
+func (u *datasetUsecase) updateStatus(ctx context.Context, id string, status domain.DatasetStatus) error {
+	dataset, err := u.datasetRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get dataset: %w", err)
+	}
 	if err := u.datasetRepo.UpdateStatus(ctx, id, status); err != nil {
 		return fmt.Errorf("failed to update dataset status: %w", err)
 	}
+	updated := *dataset
+	updated.Status = status
+	u.audit.Record(ctx, "datasets", id, audit.ActionUpdate, dataset, &updated)
+	if err := u.recount(ctx, dataset, status); err != nil {
+		return err
+	}
+
+	db.AfterCommit(ctx, func() { u.bySlug.Delete(ctx, dataset.Slug) })
+	u.purge(ctx, dataset)
+	if err := u.publish(ctx, domain.EventDatasetStatusChanged, map[string]string{"id": id, "status": string(status)}); err != nil {
+		return err
+	}
+
+	if status == domain.DatasetStatusPublished {
+		dataset.Status = status
+		dataset.UpdatedAt = time.Now()
+		responses, err := u.toResponses(ctx, dataset)
+		if err != nil {
+			return err
+		}
+		return u.publish(ctx, domain.EventDatasetPublished, responses[0])
+	}
+	return nil
+}
+
+func (u *datasetUsecase) SetGeneratedImage(ctx context.Context, id, image, previous string) (bool, error) {
+	dataset, err := u.datasetRepo.GetByID(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to get dataset: %w", err)
+	}
+	set, err := u.datasetRepo.SetImage(ctx, id, image, previous)
+	if err != nil || !set {
+		return false, err
+	}
+
+	u.bySlug.Delete(ctx, dataset.Slug)
+	u.purge(ctx, dataset)
+	return true, nil
+}
+
+// recount takes a dataset off the counters of its organization when it is
+// archived, and puts it back when it is restored
+func (u *datasetUsecase) recount(ctx context.Context, dataset *domain.Dataset, status domain.DatasetStatus) error {
+	var err error
+	switch {
+	case dataset.Status != domain.DatasetStatusArchived && status == domain.DatasetStatusArchived:
+		err = u.orgs.DecrementDatasetCount(ctx, dataset.OrganizationID, isPublic(dataset))
+	case dataset.Status == domain.DatasetStatusArchived && status != domain.DatasetStatusArchived:
+		err = u.orgs.IncrementDatasetCount(ctx, dataset.OrganizationID, isPublic(dataset))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update organization counters: %w", err)
+	}
 	return nil
 }
 
@@ -262,9 +700,13 @@ func (u *datasetUsecase) GetByOrganizationID(ctx context.Context, orgID string,
 		return nil, fmt.Errorf("failed to get organization datasets: %w", err)
 	}
 
-	responses := make([]domain.DatasetResponse, len(datasets))
-	for i, ds := range datasets {
-		responses[i] = *u.toResponse(ds)
+	converted, err := u.toResponses(ctx, datasets...)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]domain.DatasetResponse, len(converted))
+	for i, resp := range converted {
+		responses[i] = *u.localize(resp, i18n.Languages(ctx))
 	}
 
 	totalPage := int(math.Ceil(float64(total) / float64(limit)))
@@ -280,13 +722,23 @@ func (u *datasetUsecase) GetByOrganizationID(ctx context.Context, orgID string,
 	}, nil
 }
 
+func (u *datasetUsecase) FindSimilar(ctx context.Context, name, orgID string, limit int) ([]domain.DatasetSuggestion, error) {
+	datasets, err := u.datasetRepo.SimilarNames(ctx, &domain.DatasetFilter{OrganizationID: orgID}, name, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find similar datasets: %w", err)
+	}
+	return datasets, nil
+}
+
 func (u *datasetUsecase) toResponse(dataset *domain.Dataset) *domain.DatasetResponse {
 	resp := &domain.DatasetResponse{
 		ID:               dataset.ID,
 		Name:             dataset.Name,
 		Slug:             dataset.Slug,
 		Description:      dataset.Description,
-		Period:           dataset.Period,
+		CoverageStart:    formatDate(dataset.CoverageStart),
+		CoverageEnd:      formatDate(dataset.CoverageEnd),
+		Frequency:        dataset.Frequency,
 		OrganizationID:   dataset.OrganizationID,
 		ReferenceID:      dataset.ReferenceID,
 		Classification:   dataset.Classification,
@@ -300,16 +752,82 @@ func (u *datasetUsecase) toResponse(dataset *domain.Dataset) *domain.DatasetResp
 		UpdatedAt:        dataset.UpdatedAt,
 		IsHighlight:      dataset.IsHighlight,
 		Status:           string(dataset.Status),
-		Tags:             dataset.Tags,
-		Unit:             dataset.Unit,
-		BusinessField:    dataset.BusinessField,
-		Topic:            dataset.Topic,
 		Image:            dataset.Image,
+		Names:            i18n.Decode(dataset.Names),
+		Descriptions:     i18n.Decode(dataset.Descriptions),
+		DeletedAt:        dataset.DeletedAt,
+		SourceURL:        dataset.SourceURL,
+		LinkStatus:       dataset.LinkStatus,
+		LinkCheckedAt:    dataset.LinkCheckedAt,
+		LinkError:        dataset.LinkError,
 	}
 
 	return resp
 }
 
+// localize replaces the name and description of a response, and the names of
+// its business field and topic, with their translation to the first of langs
+// they have
+func (u *datasetUsecase) localize(resp *domain.DatasetResponse, langs []string) *domain.DatasetResponse {
+	resp.Name = i18n.Pick(resp.Names, langs, resp.Name)
+	if resp.BusinessField != nil {
+		resp.BusinessField.Name = i18n.Pick(resp.BusinessField.Names, langs, resp.BusinessField.Name)
+	}
+	if resp.Topic != nil {
+		resp.Topic.Name = i18n.Pick(resp.Topic.Names, langs, resp.Topic.Name)
+	}
+	if resp.Description != nil {
+		description := i18n.Pick(resp.Descriptions, langs, *resp.Description)
+		resp.Description = &description
+	} else if description := i18n.Pick(resp.Descriptions, langs, ""); description != "" {
+		resp.Description = &description
+	}
+	return resp
+}
+
+// setTranslations stores the localized names and descriptions of a request.
+// Nil maps keep the stored translations.
+func (u *datasetUsecase) setTranslations(dataset *domain.Dataset, names, descriptions map[string]string) error {
+	var err error
+	if names != nil || dataset.Names == "" {
+		if dataset.Names, err = i18n.Encode(names); err != nil {
+			return err
+		}
+	}
+	if descriptions != nil || dataset.Descriptions == "" {
+		if dataset.Descriptions, err = i18n.Encode(descriptions); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// purge invalidates the cached responses showing dataset once the
+// transaction ctx carries commits
+func (u *datasetUsecase) purge(ctx context.Context, dataset *domain.Dataset) {
+	db.AfterCommit(ctx, func() {
+		u.surrogates.Purge(ctx, domain.SurrogateKeyDatasets, domain.SurrogateKey(dataset.ID))
+	})
+}
+
+// publish records an event in the outbox, in the transaction ctx carries,
+// so the event is emitted exactly when the change commits
+func (u *datasetUsecase) publish(ctx context.Context, eventType string, data interface{}) error {
+	if u.events == nil {
+		return nil
+	}
+	if err := u.events.Publish(ctx, eventType, data); err != nil {
+		return fmt.Errorf("failed to publish %s event: %w", eventType, err)
+	}
+	return nil
+}
+
+// isPublic reports whether a dataset counts towards the public datasets of
+// its organization
+func isPublic(dataset *domain.Dataset) bool {
+	return dataset.Classification == "public"
+}
+
 func (u *datasetUsecase) generateSlug(name string) string {
 	slug := strings.ToLower(name)
 	slug = strings.ReplaceAll(slug, " ", "-")
@@ -0,0 +1,237 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"portal-data-backend/infrastructure/audit"
+	"portal-data-backend/infrastructure/db"
+	"portal-data-backend/infrastructure/logger"
+	"portal-data-backend/infrastructure/tenant"
+	"portal-data-backend/internal/dataset/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+)
+
+// ListHighlights returns the highlighted datasets in their order. With
+// publishedOnly, highlights of datasets that are not published are left out.
+func (u *datasetUsecase) ListHighlights(ctx context.Context, publishedOnly bool) (*domain.HighlightListResponse, error) {
+	highlights, err := u.datasetRepo.Highlights(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(highlights))
+	for i, highlight := range highlights {
+		ids[i] = highlight.DatasetID
+	}
+	datasets, err := u.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*domain.DatasetResponse, len(datasets))
+	for _, dataset := range datasets {
+		byID[dataset.ID] = dataset
+	}
+
+	resp := &domain.HighlightListResponse{Highlights: []domain.HighlightResponse{}, Max: u.highlights.Max}
+	for _, highlight := range highlights {
+		dataset, ok := byID[highlight.DatasetID]
+		if !ok || (publishedOnly && dataset.Status != string(domain.DatasetStatusPublished)) {
+			continue
+		}
+		resp.Highlights = append(resp.Highlights, domain.HighlightResponse{Highlight: *highlight, Dataset: *dataset})
+	}
+	return resp, nil
+}
+
+// AddHighlight highlights a dataset after the ones already highlighted
+func (u *datasetUsecase) AddHighlight(ctx context.Context, req *domain.AddHighlightRequest, userID string) (*domain.HighlightResponse, error) {
+	dataset, err := u.datasetRepo.GetByID(ctx, req.DatasetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dataset: %w", err)
+	}
+
+	var highlight *domain.Highlight
+	err = u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		highlight, err = u.addHighlight(ctx, dataset, req.ExpiresAt, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dataset.IsHighlight = true
+	responses, err := u.toResponses(ctx, dataset)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.HighlightResponse{Highlight: *highlight, Dataset: *responses[0]}, nil
+}
+
+// addHighlight highlights dataset in the transaction ctx carries, unless as
+// many datasets as allowed are highlighted already. Without expiresAt the
+// highlight expires after the configured default, if any.
+func (u *datasetUsecase) addHighlight(ctx context.Context, dataset *domain.Dataset, expiresAt *time.Time, userID string) (*domain.Highlight, error) {
+	now := time.Now()
+	if expiresAt == nil && u.highlights.DefaultTTL > 0 {
+		expires := now.Add(u.highlights.DefaultTTL)
+		expiresAt = &expires
+	}
+	if expiresAt != nil && !expiresAt.After(now) {
+		return nil, fmt.Errorf("%w: expires_at must be in the future", pkgErrors.ErrInvalidInput)
+	}
+
+	highlights, err := u.datasetRepo.Highlights(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, highlight := range highlights {
+		if highlight.DatasetID == dataset.ID {
+			return nil, fmt.Errorf("%w: the dataset is highlighted already", pkgErrors.ErrAlreadyExists)
+		}
+	}
+	if len(highlights) >= u.highlights.Max {
+		return nil, fmt.Errorf("%w: at most %d datasets can be highlighted at a time", pkgErrors.ErrInvalidInput, u.highlights.Max)
+	}
+
+	highlight := &domain.Highlight{
+		DatasetID: dataset.ID,
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+	}
+	if userID != "" {
+		highlight.CreatedBy = &userID
+	}
+	if err := u.datasetRepo.AddHighlight(ctx, highlight); err != nil {
+		return nil, err
+	}
+	u.audit.Record(ctx, "dataset_highlights", dataset.ID, audit.ActionCreate, nil, highlight)
+	u.purge(ctx, dataset)
+	return highlight, nil
+}
+
+// syncHighlight highlights dataset or stops highlighting it when an update
+// changed whether it is highlighted, in the transaction ctx carries
+func (u *datasetUsecase) syncHighlight(ctx context.Context, dataset *domain.Dataset, wasHighlight bool, userID string) error {
+	switch {
+	case dataset.IsHighlight && !wasHighlight:
+		_, err := u.addHighlight(ctx, dataset, nil, userID)
+		return err
+	case !dataset.IsHighlight && wasHighlight:
+		if err := u.removeHighlight(ctx, dataset.ID); err != nil && !errors.Is(err, pkgErrors.ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdateHighlight changes when the highlight of a dataset expires
+func (u *datasetUsecase) UpdateHighlight(ctx context.Context, datasetID string, req *domain.UpdateHighlightRequest) error {
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return fmt.Errorf("%w: expires_at must be in the future", pkgErrors.ErrInvalidInput)
+	}
+
+	return u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.datasetRepo.SetHighlightExpiry(ctx, datasetID, req.ExpiresAt); err != nil {
+			return err
+		}
+		u.audit.Record(ctx, "dataset_highlights", datasetID, audit.ActionUpdate, nil, req)
+		u.purge(ctx, &domain.Dataset{ID: datasetID})
+		return nil
+	})
+}
+
+// RemoveHighlight stops highlighting a dataset
+func (u *datasetUsecase) RemoveHighlight(ctx context.Context, datasetID string) error {
+	return u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		return u.removeHighlight(ctx, datasetID)
+	})
+}
+
+func (u *datasetUsecase) removeHighlight(ctx context.Context, datasetID string) error {
+	if err := u.datasetRepo.RemoveHighlight(ctx, datasetID); err != nil {
+		return err
+	}
+	u.audit.Record(ctx, "dataset_highlights", datasetID, audit.ActionDelete, nil, nil)
+	u.purge(ctx, &domain.Dataset{ID: datasetID})
+	return nil
+}
+
+// OrderHighlights puts the highlighted datasets in the order req lists them
+func (u *datasetUsecase) OrderHighlights(ctx context.Context, req *domain.OrderHighlightsRequest) error {
+	return u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		highlights, err := u.datasetRepo.Highlights(ctx)
+		if err != nil {
+			return err
+		}
+
+		current := make([]string, len(highlights))
+		for i, highlight := range highlights {
+			current[i] = highlight.DatasetID
+		}
+		ordered := append([]string(nil), req.DatasetIDs...)
+		sort.Strings(current)
+		sort.Strings(ordered)
+		if len(current) != len(ordered) {
+			return fmt.Errorf("%w: dataset_ids must list each highlighted dataset once", pkgErrors.ErrInvalidInput)
+		}
+		for i := range current {
+			if current[i] != ordered[i] {
+				return fmt.Errorf("%w: dataset_ids must list each highlighted dataset once", pkgErrors.ErrInvalidInput)
+			}
+		}
+
+		if err := u.datasetRepo.OrderHighlights(ctx, req.DatasetIDs); err != nil {
+			return err
+		}
+		u.audit.Record(ctx, "dataset_highlights", "", audit.ActionUpdate, nil, req)
+		db.AfterCommit(ctx, func() {
+			u.surrogates.Purge(ctx, domain.SurrogateKeyDatasets)
+		})
+		return nil
+	})
+}
+
+// ExpireHighlights removes the highlights whose expiry date has passed,
+// returning how many it removed. The cached responses showing the datasets
+// are purged for their tenant, and for deployments serving a single portal.
+func (u *datasetUsecase) ExpireHighlights(ctx context.Context) (int, error) {
+	expired, err := u.datasetRepo.ExpireHighlights(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	for datasetID, tenantID := range expired {
+		keys := []string{domain.SurrogateKeyDatasets, domain.SurrogateKey(datasetID)}
+		u.surrogates.Purge(ctx, keys...)
+		u.surrogates.Purge(tenant.WithTenant(ctx, &tenant.Tenant{ID: tenantID}), keys...)
+	}
+	return len(expired), nil
+}
+
+// Run removes expired highlights every configured interval until ctx is
+// done
+func (u *datasetUsecase) Run(ctx context.Context) {
+	if u.highlights.ExpiryInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(u.highlights.ExpiryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if n, err := u.ExpireHighlights(ctx); err != nil {
+				logger.FromContext(ctx).Error("dataset highlight expiry failed: %v", err)
+			} else if n > 0 {
+				logger.FromContext(ctx).Info("expired %d dataset highlights", n)
+			}
+		}
+	}
+}
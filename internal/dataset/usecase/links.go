@@ -0,0 +1,167 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"portal-data-backend/infrastructure/logger"
+	"portal-data-backend/infrastructure/tenant"
+	"portal-data-backend/internal/dataset/domain"
+	templateDomain "portal-data-backend/internal/message_template/domain"
+	notifDomain "portal-data-backend/internal/notification/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+)
+
+// NotificationSender is the part of the notification module the owners of
+// datasets are told about broken source URLs through
+type NotificationSender interface {
+	Create(ctx context.Context, req *notifDomain.CreateNotificationRequest) (*notifDomain.NotificationInfo, error)
+}
+
+// MessageRenderer renders the wording of the notifications owners are sent
+// from the templates of the message template module
+type MessageRenderer interface {
+	Render(ctx context.Context, channel templateDomain.Channel, key string, vars map[string]string) (*templateDomain.Message, error)
+}
+
+// validateSourceURL checks that the source URL of a dataset is an absolute
+// http or https URL. An empty URL is valid, the dataset has no source.
+func validateSourceURL(raw string) error {
+	if raw == "" {
+		return nil
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+		return fmt.Errorf("%w: source_url must be an absolute http or https URL", pkgErrors.ErrInvalidInput)
+	}
+	if parsed.User != nil {
+		return fmt.Errorf("%w: source_url must not hold credentials", pkgErrors.ErrInvalidInput)
+	}
+	return nil
+}
+
+// CheckLinks requests the source URLs due for a check, recording whether each
+// works. Owners are notified when the source of their dataset breaks. It
+// returns how many links it found broken.
+func (u *datasetUsecase) CheckLinks(ctx context.Context) (int, error) {
+	links, err := u.datasetRepo.LinksToCheck(ctx, time.Now().Add(-u.linkCheck.Recheck), u.linkCheck.Batch)
+	if err != nil {
+		return 0, err
+	}
+
+	broken := 0
+	for _, link := range links {
+		if ctx.Err() != nil {
+			break
+		}
+
+		status, linkError := domain.LinkStatusOK, (*string)(nil)
+		if err := u.checkLink(ctx, link.SourceURL); err != nil {
+			message := err.Error()
+			status, linkError = domain.LinkStatusBroken, &message
+			broken++
+		}
+
+		tenantCtx := tenant.WithTenant(ctx, &tenant.Tenant{ID: link.TenantID})
+		if err := u.datasetRepo.SetLinkStatus(ctx, link.DatasetID, status, linkError, time.Now()); err != nil {
+			logger.FromContext(ctx).Error("failed to record the link check of dataset %s: %v", link.DatasetID, err)
+			continue
+		}
+		if link.LinkStatus == nil || *link.LinkStatus != status {
+			keys := []string{domain.SurrogateKeyDatasets, domain.SurrogateKey(link.DatasetID)}
+			u.bySlug.Delete(tenantCtx, link.Slug)
+			u.surrogates.Purge(tenantCtx, keys...)
+			u.surrogates.Purge(ctx, keys...)
+		}
+		if status == domain.LinkStatusBroken && (link.LinkStatus == nil || *link.LinkStatus != domain.LinkStatusBroken) {
+			u.notifyBrokenLink(tenantCtx, link, *linkError)
+		}
+	}
+	return broken, nil
+}
+
+// checkLink requests raw, falling back from HEAD to GET for servers that do
+// not answer HEAD requests. Responses from 400 up count as broken.
+func (u *datasetUsecase) checkLink(ctx context.Context, raw string) error {
+	status, err := u.request(ctx, http.MethodHead, raw)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented) {
+		status, err = u.request(ctx, http.MethodGet, raw)
+	}
+	if err != nil {
+		return err
+	}
+	if status >= http.StatusBadRequest {
+		return fmt.Errorf("responded %d %s", status, http.StatusText(status))
+	}
+	return nil
+}
+
+func (u *datasetUsecase) request(ctx context.Context, method, raw string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, raw, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	return resp.StatusCode, nil
+}
+
+// notifyBrokenLink tells the owner of a dataset its source URL broke.
+// Failures are logged, the check is recorded either way.
+func (u *datasetUsecase) notifyBrokenLink(ctx context.Context, link *domain.LinkCheck, linkError string) {
+	if u.notifications == nil || link.CreatedBy == "" {
+		return
+	}
+
+	msg, err := u.messages.Render(ctx, templateDomain.ChannelNotification, MessageBrokenLink, map[string]string{
+		"name":  link.Name,
+		"url":   link.SourceURL,
+		"error": linkError,
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to notify the owner of dataset %s: %v", link.DatasetID, err)
+		return
+	}
+	req := &notifDomain.CreateNotificationRequest{
+		UserID:   link.CreatedBy,
+		Title:    msg.Subject,
+		Message:  msg.Body,
+		Type:     string(notifDomain.NotificationTypeWarning),
+		Category: string(notifDomain.NotificationCategoryDataset),
+	}
+	if _, err := u.notifications.Create(ctx, req); err != nil {
+		logger.FromContext(ctx).Error("failed to notify the owner of dataset %s: %v", link.DatasetID, err)
+	}
+}
+
+// RunLinkChecks checks the source URLs due for a check every configured
+// interval until ctx is done
+func (u *datasetUsecase) RunLinkChecks(ctx context.Context) {
+	if u.linkCheck.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(u.linkCheck.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if n, err := u.CheckLinks(ctx); err != nil {
+				logger.FromContext(ctx).Error("dataset link check failed: %v", err)
+			} else if n > 0 {
+				logger.FromContext(ctx).Info("found %d broken dataset links", n)
+			}
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode"
+
+	"portal-data-backend/internal/dataset/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+)
+
+// genericTitleWords are the words that say nothing of what a dataset holds
+var genericTitleWords = map[string]bool{
+	"data": true, "dataset": true, "datasets": true, "tabel": true, "table": true,
+	"sheet": true, "sheet1": true, "baru": true, "new": true, "test": true,
+	"tes": true, "contoh": true, "sample": true, "untitled": true, "copy": true,
+	"final": true, "draft": true,
+}
+
+// fileExtensions are the extensions of titles that are the names of the
+// uploaded files
+var fileExtensions = []string{".csv", ".xls", ".xlsx", ".json", ".xml", ".pdf", ".zip"}
+
+// frequencyPeriods are the years, months and days between collections of
+// data at each frequency. Irregular data is never stale.
+var frequencyPeriods = map[domain.Frequency][3]int{
+	domain.FrequencyDaily:      {0, 0, 1},
+	domain.FrequencyWeekly:     {0, 0, 7},
+	domain.FrequencyMonthly:    {0, 1, 0},
+	domain.FrequencyQuarterly:  {0, 3, 0},
+	domain.FrequencySemiannual: {0, 6, 0},
+	domain.FrequencyAnnual:     {1, 0, 0},
+}
+
+// lint checks the metadata of dataset as saved by now, returning the checks
+// it fails in the order of domain.LintRules
+func lint(dataset *domain.Dataset, now time.Time) []domain.LintWarning {
+	warnings := []domain.LintWarning{}
+	warn := func(rule domain.LintRule, field, message string) {
+		warnings = append(warnings, domain.LintWarning{Rule: rule, Field: field, Message: message})
+	}
+
+	description := ""
+	if dataset.Description != nil {
+		description = strings.TrimSpace(*dataset.Description)
+	}
+	switch {
+	case description == "":
+		warn(domain.LintMissingDescription, "description", "Describe what the data holds, where it comes from and how it was collected")
+	case strings.EqualFold(description, strings.TrimSpace(dataset.Name)):
+		warn(domain.LintMissingDescription, "description", "The description only repeats the title")
+	}
+
+	if len(dataset.TagIDs) == 0 {
+		warn(domain.LintNoTags, "tag_ids", "Tag the dataset so that it is found by subject")
+	}
+
+	if !hasLicense(dataset.Metadata) {
+		warn(domain.LintNoLicense, "metadatas", `Name the license of the data as "license" in metadatas`)
+	}
+
+	if period, ok := stalePeriod(dataset, now); ok {
+		warn(domain.LintStalePeriod, "coverage_end", fmt.Sprintf("The data covers up to %s but is collected %s; add the data of the periods since", period, *dataset.Frequency))
+	}
+
+	if !descriptiveTitle(dataset.Name) {
+		warn(domain.LintNonDescriptiveTitle, "name", "Title the dataset with what it measures, like \"Jumlah Penduduk per Kecamatan\"")
+	}
+	return warnings
+}
+
+// hasLicense reports whether metadata is a JSON object naming a license
+func hasLicense(metadata *string) bool {
+	if metadata == nil {
+		return false
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(*metadata), &fields); err != nil {
+		return false
+	}
+	license, _ := fields["license"].(string)
+	return strings.TrimSpace(license) != ""
+}
+
+// stalePeriod returns the end of the coverage of dataset when, by now, the
+// data of a whole period after it should have been added for its frequency
+func stalePeriod(dataset *domain.Dataset, now time.Time) (string, bool) {
+	if dataset.CoverageEnd == nil || dataset.Frequency == nil {
+		return "", false
+	}
+	period, ok := frequencyPeriods[*dataset.Frequency]
+	if !ok {
+		return "", false
+	}
+	// The data of the period after the coverage is due once that period is
+	// over, so it is late a period after that
+	due := dataset.CoverageEnd.AddDate(2*period[0], 2*period[1], 2*period[2])
+	return dataset.CoverageEnd.Format(time.DateOnly), now.After(due)
+}
+
+// descriptiveTitle reports whether title has two words or more that say
+// what the data is, and is not the name of a file
+func descriptiveTitle(title string) bool {
+	lower := strings.ToLower(strings.TrimSpace(title))
+	for _, extension := range fileExtensions {
+		if strings.HasSuffix(lower, extension) {
+			return false
+		}
+	}
+
+	words := strings.FieldsFunc(lower, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	meaningful := 0
+	for _, word := range words {
+		if genericTitleWords[word] || strings.IndexFunc(word, unicode.IsLetter) < 0 {
+			continue
+		}
+		meaningful++
+	}
+	return meaningful >= 2
+}
+
+// saveLint lints dataset and stores its report in the transaction ctx
+// carries, returning the warnings
+func (u *datasetUsecase) saveLint(ctx context.Context, dataset *domain.Dataset, now time.Time) ([]domain.LintWarning, error) {
+	warnings := lint(dataset, now)
+	encoded, err := json.Marshal(warnings)
+	if err != nil {
+		return nil, err
+	}
+	report := &domain.LintReport{
+		DatasetID:    dataset.ID,
+		Warnings:     string(encoded),
+		WarningCount: len(warnings),
+		CheckedAt:    now,
+	}
+	if err := u.datasetRepo.SaveLintReport(ctx, report); err != nil {
+		return nil, fmt.Errorf("failed to save lint report: %w", err)
+	}
+	return warnings, nil
+}
+
+// Quality retrieves how many datasets fail each lint rule and a page of
+// their lint reports, those with the most warnings first
+func (u *datasetUsecase) Quality(ctx context.Context, req *domain.ListLintReportsRequest) (*domain.QualityResponse, error) {
+	if req.Rule != "" && !domain.LintRule(req.Rule).Valid() {
+		return nil, fmt.Errorf("%w: rule must be one of %v", pkgErrors.ErrInvalidInput, domain.LintRules)
+	}
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.Limit < 1 || req.Limit > 100 {
+		req.Limit = 20
+	}
+
+	filter := &domain.LintReportFilter{OrganizationID: req.OrganizationID, Rule: req.Rule}
+	counts, err := u.datasetRepo.LintRuleCounts(ctx, req.OrganizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count lint warnings: %w", err)
+	}
+	reports, total, err := u.datasetRepo.LintReports(ctx, filter, req.Limit, (req.Page-1)*req.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list lint reports: %w", err)
+	}
+
+	resp := &domain.QualityResponse{
+		Rules:   make(map[domain.LintRule]int, len(domain.LintRules)),
+		Reports: make([]domain.LintReportResponse, len(reports)),
+		Meta: domain.ListMeta{
+			Page:      req.Page,
+			Limit:     req.Limit,
+			Total:     total,
+			TotalPage: int(math.Ceil(float64(total) / float64(req.Limit))),
+		},
+	}
+	for _, rule := range domain.LintRules {
+		resp.Rules[rule] = counts[string(rule)]
+	}
+	for i, report := range reports {
+		warnings := []domain.LintWarning{}
+		if err := json.Unmarshal([]byte(report.Warnings), &warnings); err != nil {
+			return nil, fmt.Errorf("failed to read lint report of dataset %s: %w", report.DatasetID, err)
+		}
+		resp.Reports[i] = domain.LintReportResponse{
+			DatasetID:      report.DatasetID,
+			Name:           report.Name,
+			Slug:           report.Slug,
+			OrganizationID: report.OrganizationID,
+			Warnings:       warnings,
+			CheckedAt:      report.CheckedAt,
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"portal-data-backend/internal/dataset/domain"
+)
+
+// Test each lint rule warns on the metadata it checks and a well described
+// dataset passes them all
+func TestLint(t *testing.T) {
+	now := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	text := func(value string) *string { return &value }
+	date := func(value string) *time.Time {
+		d, _ := time.Parse(time.DateOnly, value)
+		return &d
+	}
+	annual, monthly, irregular := domain.FrequencyAnnual, domain.FrequencyMonthly, domain.FrequencyIrregular
+	good := func() *domain.Dataset {
+		return &domain.Dataset{
+			Name:        "Jumlah Penduduk per Kecamatan",
+			Description: text("Jumlah penduduk menurut kecamatan dari hasil registrasi"),
+			TagIDs:      []string{"tag-1"},
+			Metadata:    text(`{"license": "CC-BY-4.0"}`),
+			CoverageEnd: date("2025-12-31"),
+			Frequency:   &annual,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		change func(d *domain.Dataset)
+		rules  []domain.LintRule
+	}{
+		{"well described", func(d *domain.Dataset) {}, nil},
+		{"no description", func(d *domain.Dataset) { d.Description = text("  ") }, []domain.LintRule{domain.LintMissingDescription}},
+		{"description repeating the title", func(d *domain.Dataset) { d.Description = text("jumlah penduduk per kecamatan") }, []domain.LintRule{domain.LintMissingDescription}},
+		{"no tags", func(d *domain.Dataset) { d.TagIDs = nil }, []domain.LintRule{domain.LintNoTags}},
+		{"no metadata", func(d *domain.Dataset) { d.Metadata = nil }, []domain.LintRule{domain.LintNoLicense}},
+		{"metadata without license", func(d *domain.Dataset) { d.Metadata = text(`{"source": "BPS"}`) }, []domain.LintRule{domain.LintNoLicense}},
+		{"annual data two years old", func(d *domain.Dataset) { d.CoverageEnd = date("2024-10-15") }, []domain.LintRule{domain.LintStalePeriod}},
+		{"annual data of last year", func(d *domain.Dataset) { d.CoverageEnd = date("2024-12-31") }, nil},
+		{"monthly data three months old", func(d *domain.Dataset) { d.Frequency = &monthly; d.CoverageEnd = date("2026-07-31") }, []domain.LintRule{domain.LintStalePeriod}},
+		{"irregular data", func(d *domain.Dataset) { d.Frequency = &irregular; d.CoverageEnd = date("2010-12-31") }, nil},
+		{"generic title", func(d *domain.Dataset) { d.Name = "Data Baru" }, []domain.LintRule{domain.LintNonDescriptiveTitle}},
+		{"file name title", func(d *domain.Dataset) { d.Name = "penduduk_kecamatan_2024.xlsx" }, []domain.LintRule{domain.LintNonDescriptiveTitle}},
+		{"one word title", func(d *domain.Dataset) { d.Name = "Penduduk 2024" }, []domain.LintRule{domain.LintNonDescriptiveTitle}},
+		{"everything missing", func(d *domain.Dataset) {
+			*d = domain.Dataset{Name: "Dataset 1"}
+		}, []domain.LintRule{domain.LintMissingDescription, domain.LintNoTags, domain.LintNoLicense, domain.LintNonDescriptiveTitle}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dataset := good()
+			tt.change(dataset)
+			warnings := lint(dataset, now)
+			if len(warnings) != len(tt.rules) {
+				t.Fatalf("Expected rules %v, got %+v", tt.rules, warnings)
+			}
+			for i, rule := range tt.rules {
+				if warnings[i].Rule != rule || warnings[i].Field == "" || warnings[i].Message == "" {
+					t.Errorf("Expected warning %d to be %s with a field and message, got %+v", i, rule, warnings[i])
+				}
+			}
+		})
+	}
+}
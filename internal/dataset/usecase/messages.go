@@ -0,0 +1,22 @@
+package usecase
+
+import templateDomain "portal-data-backend/internal/message_template/domain"
+
+// Keys of the notifications the owners of datasets are sent
+const (
+	MessageBrokenLink = "dataset.broken_link"
+)
+
+// Messages are the notifications the owners of datasets are sent, with their
+// built-in wording. The module defines them with the message template
+// registry.
+var Messages = []templateDomain.Definition{
+	{
+		Key:         MessageBrokenLink,
+		Channel:     templateDomain.ChannelNotification,
+		Description: "Alert that the source URL of a dataset stopped working",
+		Variables:   []string{"name", "url", "error"},
+		Subject:     "Dataset source link broken",
+		Body:        "The source of {{.name}} at {{.url}} no longer works: {{.error}}",
+	},
+}
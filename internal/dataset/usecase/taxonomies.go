@@ -0,0 +1,154 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	businessFieldDomain "portal-data-backend/internal/business_field/domain"
+	businessFieldUsecase "portal-data-backend/internal/business_field/usecase"
+	"portal-data-backend/internal/dataset/domain"
+	tagDomain "portal-data-backend/internal/tag/domain"
+	tagUsecase "portal-data-backend/internal/tag/usecase"
+	topicDomain "portal-data-backend/internal/topic/domain"
+	topicUsecase "portal-data-backend/internal/topic/usecase"
+	unitDomain "portal-data-backend/internal/unit/domain"
+	unitUsecase "portal-data-backend/internal/unit/usecase"
+	"portal-data-backend/pkg/i18n"
+)
+
+// Taxonomies are the modules the tags, unit, business field and topic of
+// datasets are read from
+type Taxonomies struct {
+	Tags           tagUsecase.TagReader
+	Units          unitUsecase.UnitReader
+	BusinessFields businessFieldUsecase.BusinessFieldReader
+	Topics         topicUsecase.TopicReader
+}
+
+// TagsByDatasetIDs retrieves the tags of the datasets with ids by dataset,
+// by name
+func (u *datasetUsecase) TagsByDatasetIDs(ctx context.Context, ids []string) (map[string][]tagDomain.TagResponse, error) {
+	tagIDs, err := u.datasetRepo.TagIDsByDatasetIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dataset tags: %w", err)
+	}
+
+	var all []string
+	for _, ids := range tagIDs {
+		all = append(all, ids...)
+	}
+	tags, err := readByID(ctx, u.taxonomies.Tags.GetByIDs, all, func(tag *tagDomain.TagResponse) string { return tag.ID })
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+
+	byDataset := make(map[string][]tagDomain.TagResponse, len(tagIDs))
+	for datasetID, ids := range tagIDs {
+		byDataset[datasetID] = sortedTags(tags, ids)
+	}
+	return byDataset, nil
+}
+
+// toResponses converts datasets to responses with their tags, unit, business
+// field and topic. Those are read untranslated, as localize translates them
+// with the dataset.
+func (u *datasetUsecase) toResponses(ctx context.Context, datasets ...*domain.Dataset) ([]*domain.DatasetResponse, error) {
+	ctx = i18n.WithLanguages(ctx, nil)
+
+	var tagIDs, unitIDs, businessFieldIDs, topicIDs []string
+	for _, dataset := range datasets {
+		tagIDs = append(tagIDs, dataset.TagIDs...)
+		if dataset.UnitID != nil {
+			unitIDs = append(unitIDs, *dataset.UnitID)
+		}
+		if dataset.BusinessFieldID != nil {
+			businessFieldIDs = append(businessFieldIDs, *dataset.BusinessFieldID)
+		}
+		if dataset.TopicID != nil {
+			topicIDs = append(topicIDs, *dataset.TopicID)
+		}
+	}
+
+	tags, err := readByID(ctx, u.taxonomies.Tags.GetByIDs, tagIDs, func(tag *tagDomain.TagResponse) string { return tag.ID })
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+	units, err := readByID(ctx, u.taxonomies.Units.GetByIDs, unitIDs, func(unit *unitDomain.UnitResponse) string { return unit.ID })
+	if err != nil {
+		return nil, fmt.Errorf("failed to get units: %w", err)
+	}
+	businessFields, err := readByID(ctx, u.taxonomies.BusinessFields.GetByIDs, businessFieldIDs, func(bf *businessFieldDomain.BusinessFieldResponse) string { return bf.ID })
+	if err != nil {
+		return nil, fmt.Errorf("failed to get business fields: %w", err)
+	}
+	topics, err := readByID(ctx, u.taxonomies.Topics.GetByIDs, topicIDs, func(topic *topicDomain.TopicResponse) string { return topic.ID })
+	if err != nil {
+		return nil, fmt.Errorf("failed to get topics: %w", err)
+	}
+
+	responses := make([]*domain.DatasetResponse, len(datasets))
+	for i, dataset := range datasets {
+		resp := u.toResponse(dataset)
+		resp.Tags = sortedTags(tags, dataset.TagIDs)
+		if dataset.UnitID != nil {
+			resp.Unit = copyOf(units[*dataset.UnitID])
+		}
+		if dataset.BusinessFieldID != nil {
+			resp.BusinessField = copyOf(businessFields[*dataset.BusinessFieldID])
+		}
+		if dataset.TopicID != nil {
+			resp.Topic = copyOf(topics[*dataset.TopicID])
+		}
+		responses[i] = resp
+	}
+	return responses, nil
+}
+
+// readByID reads the records with ids, each once, by ID
+func readByID[T any](ctx context.Context, read func(ctx context.Context, ids []string) ([]*T, error), ids []string, key func(*T) string) (map[string]*T, error) {
+	byID := make(map[string]*T, len(ids))
+	if len(ids) == 0 {
+		return byID, nil
+	}
+
+	seen := make(map[string]bool, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	records, err := read(ctx, unique)
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		byID[key(record)] = record
+	}
+	return byID, nil
+}
+
+// sortedTags returns the tags with ids by name, leaving out unknown ones
+func sortedTags(tags map[string]*tagDomain.TagResponse, ids []string) []tagDomain.TagResponse {
+	var sorted []tagDomain.TagResponse
+	for _, id := range ids {
+		if tag, ok := tags[id]; ok {
+			sorted = append(sorted, *tag)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	return sorted
+}
+
+// copyOf copies a record shared by several datasets, so localizing one
+// leaves the others alone
+func copyOf[T any](record *T) *T {
+	if record == nil {
+		return nil
+	}
+	c := *record
+	return &c
+}
@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"portal-data-backend/infrastructure/audit"
+	"portal-data-backend/infrastructure/db"
+	"portal-data-backend/internal/dataset/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+)
+
+// Transfer moves a dataset to another organization in the transaction ctx
+// carries, if any. Unless the dataset is archived, it stops counting towards
+// its organization and counts towards orgID.
+func (u *datasetUsecase) Transfer(ctx context.Context, id, orgID, updaterID string) (*domain.DatasetResponse, error) {
+	var resp *domain.DatasetResponse
+	var dataset *domain.Dataset
+	err := u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		dataset, err = u.datasetRepo.GetByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get dataset: %w", err)
+		}
+		if dataset.OrganizationID == orgID {
+			return fmt.Errorf("%w: the dataset belongs to the organization already", pkgErrors.ErrInvalidInput)
+		}
+
+		if err := u.datasetRepo.Transfer(ctx, id, orgID, updaterID); err != nil {
+			return err
+		}
+		if dataset.Status != domain.DatasetStatusArchived {
+			if err := u.orgs.DecrementDatasetCount(ctx, dataset.OrganizationID, isPublic(dataset)); err != nil {
+				return fmt.Errorf("failed to update organization counters: %w", err)
+			}
+			if err := u.orgs.IncrementDatasetCount(ctx, orgID, isPublic(dataset)); err != nil {
+				return fmt.Errorf("failed to update organization counters: %w", err)
+			}
+		}
+
+		transferred, err := u.datasetRepo.GetByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to fetch transferred dataset: %w", err)
+		}
+		u.audit.Record(ctx, "datasets", id, audit.ActionUpdate, dataset, transferred)
+
+		responses, err := u.toResponses(ctx, transferred)
+		if err != nil {
+			return err
+		}
+		resp = responses[0]
+		return u.publish(ctx, domain.EventDatasetUpdated, resp)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	db.AfterCommit(ctx, func() { u.bySlug.Delete(ctx, dataset.Slug) })
+	u.purge(ctx, dataset)
+	return resp, nil
+}
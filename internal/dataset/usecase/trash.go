@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"portal-data-backend/infrastructure/audit"
+	"portal-data-backend/infrastructure/logger"
+	"portal-data-backend/internal/dataset/domain"
+	fileDomain "portal-data-backend/internal/file/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+)
+
+// purgeBatch is how many datasets a purge deletes between lookups
+const purgeBatch = 100
+
+// FileRemover is the part of the file module the files of purged datasets
+// are deleted through
+type FileRemover interface {
+	GetByDatasetIDs(ctx context.Context, datasetIDs []string) (map[string][]fileDomain.FileInfo, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// ListTrash returns a page of the deleted datasets with when each is purged
+func (u *datasetUsecase) ListTrash(ctx context.Context, req *domain.ListTrashRequest) (*domain.TrashListResponse, error) {
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.Limit < 1 || req.Limit > 100 {
+		req.Limit = 20
+	}
+
+	datasets, total, err := u.datasetRepo.Trash(ctx, req.OrganizationID, req.Limit, (req.Page-1)*req.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deleted datasets: %w", err)
+	}
+
+	resp := &domain.TrashListResponse{
+		Datasets: make([]domain.TrashedDataset, len(datasets)),
+		Meta: domain.ListMeta{
+			Page:      req.Page,
+			Limit:     req.Limit,
+			Total:     total,
+			TotalPage: int(math.Ceil(float64(total) / float64(req.Limit))),
+		},
+	}
+	for i, dataset := range datasets {
+		dataset.PurgeAt = dataset.DeletedAt.Add(u.trash.Retention)
+		resp.Datasets[i] = *dataset
+	}
+	return resp, nil
+}
+
+// PurgeDeleted deletes the datasets deleted before before for good, with
+// their files and data rows, returning how many it deleted. A dataset whose
+// files cannot be deleted stays in the trash until the next purge.
+func (u *datasetUsecase) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+	for {
+		ids, err := u.datasetRepo.DeletedBefore(ctx, before, purgeBatch)
+		if err != nil {
+			return purged, err
+		}
+
+		progressed := false
+		for _, id := range ids {
+			if err := u.purgeDataset(ctx, id); err != nil {
+				logger.FromContext(ctx).Error("failed to purge dataset %s: %v", id, err)
+				continue
+			}
+			purged++
+			progressed = true
+		}
+		if len(ids) < purgeBatch || !progressed {
+			return purged, nil
+		}
+	}
+}
+
+// purgeDataset deletes the files of a deleted dataset, then the dataset with
+// its data rows
+func (u *datasetUsecase) purgeDataset(ctx context.Context, id string) error {
+	if u.files != nil {
+		files, err := u.files.GetByDatasetIDs(ctx, []string{id})
+		if err != nil {
+			return fmt.Errorf("failed to get files: %w", err)
+		}
+		for _, file := range files[id] {
+			if err := u.files.Delete(ctx, file.ID); err != nil && !pkgErrors.Is(err, pkgErrors.ErrNotFound) {
+				return fmt.Errorf("failed to delete file %s: %w", file.ID, err)
+			}
+		}
+	}
+
+	return u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.datasetRepo.Purge(ctx, id); err != nil {
+			return err
+		}
+		u.audit.Record(ctx, "datasets", id, audit.ActionPurge, nil, nil)
+		return nil
+	})
+}
@@ -2,8 +2,10 @@ package usecase
 
 import (
 	"context"
+	"time"
 
 	"portal-data-backend/internal/dataset/domain"
+	tagDomain "portal-data-backend/internal/tag/domain"
 )
 
 // Usecase defines the interface for dataset business logic
@@ -11,6 +13,12 @@ type Usecase interface {
 	// GetByID retrieves a dataset by ID
 	GetByID(ctx context.Context, id string) (*domain.DatasetResponse, error)
 
+	// GetByIDs retrieves the datasets with ids, in no particular order
+	GetByIDs(ctx context.Context, ids []string) ([]*domain.DatasetResponse, error)
+
+	// TagsByDatasetIDs retrieves the tags of the datasets with ids by dataset
+	TagsByDatasetIDs(ctx context.Context, ids []string) (map[string][]tagDomain.TagResponse, error)
+
 	// GetBySlug retrieves a dataset by slug
 	GetBySlug(ctx context.Context, slug string) (*domain.DatasetResponse, error)
 
@@ -26,9 +34,89 @@ type Usecase interface {
 	// Delete soft deletes a dataset
 	Delete(ctx context.Context, id string) error
 
+	// Restore brings back a soft deleted dataset
+	Restore(ctx context.Context, id string) error
+
+	// Quality retrieves how many datasets fail each lint rule and a page of
+	// their lint reports for the quality dashboard
+	Quality(ctx context.Context, req *domain.ListLintReportsRequest) (*domain.QualityResponse, error)
+
+	// ListTrash retrieves a page of the deleted datasets with when each is
+	// purged
+	ListTrash(ctx context.Context, req *domain.ListTrashRequest) (*domain.TrashListResponse, error)
+
+	// PurgeDeleted deletes the datasets deleted before before for good, with
+	// their files and data rows, returning how many it deleted
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+
+	// Authorize returns pkg/errors.ErrForbidden unless caller is an admin,
+	// owns the dataset id or is granted role on it as a collaborator
+	Authorize(ctx context.Context, id string, caller domain.Caller, role domain.CollaboratorRole) error
+
+	// ListCollaborators retrieves the collaborators of a dataset
+	ListCollaborators(ctx context.Context, id string, caller domain.Caller) ([]*domain.Collaborator, error)
+
+	// AddCollaborator grants a role on a dataset to a user or organization,
+	// replacing the role granted to them before
+	AddCollaborator(ctx context.Context, id string, req *domain.AddCollaboratorRequest, caller domain.Caller) (*domain.Collaborator, error)
+
+	// RemoveCollaborator revokes the grant collaboratorID on a dataset
+	RemoveCollaborator(ctx context.Context, id, collaboratorID string, caller domain.Caller) error
+
 	// UpdateStatus updates dataset status
 	UpdateStatus(ctx context.Context, id string, status domain.DatasetStatus) error
 
+	// Transfer moves a dataset to the organization orgID, which owns it from
+	// then on
+	Transfer(ctx context.Context, id, orgID, updaterID string) (*domain.DatasetResponse, error)
+	// Validate sets the validation status of a dataset, as its reviewer
+	// decides
+	Validate(ctx context.Context, id string, status domain.ValidationStatus, updaterID string) (*domain.DatasetResponse, error)
+	// BulkUpdateStatus updates the status of several datasets in one
+	// transaction, returning the errors of those it could not update by ID
+	BulkUpdateStatus(ctx context.Context, ids []string, status domain.DatasetStatus) (map[string]error, error)
+
+	// SetGeneratedImage sets a generated image on a dataset that has no
+	// image or still has the previous generated one, reporting whether it
+	// did. The dataset is not otherwise updated.
+	SetGeneratedImage(ctx context.Context, id, image, previous string) (bool, error)
+
 	// GetByOrganizationID retrieves datasets by organization ID
 	GetByOrganizationID(ctx context.Context, orgID string, page, limit int) (*domain.DatasetListResponse, error)
+
+	// FindSimilar retrieves up to limit datasets whose name is close to name,
+	// most similar first, within the organization orgID unless it is empty
+	FindSimilar(ctx context.Context, name, orgID string, limit int) ([]domain.DatasetSuggestion, error)
+
+	// ListHighlights retrieves the highlighted datasets in their order, only
+	// the published ones with publishedOnly
+	ListHighlights(ctx context.Context, publishedOnly bool) (*domain.HighlightListResponse, error)
+
+	// AddHighlight highlights a dataset after the ones already highlighted,
+	// unless as many datasets as allowed are highlighted already
+	AddHighlight(ctx context.Context, req *domain.AddHighlightRequest, userID string) (*domain.HighlightResponse, error)
+
+	// UpdateHighlight changes when the highlight of a dataset expires
+	UpdateHighlight(ctx context.Context, datasetID string, req *domain.UpdateHighlightRequest) error
+
+	// RemoveHighlight stops highlighting a dataset
+	RemoveHighlight(ctx context.Context, datasetID string) error
+
+	// OrderHighlights puts the highlighted datasets in the order req lists
+	// them
+	OrderHighlights(ctx context.Context, req *domain.OrderHighlightsRequest) error
+
+	// ExpireHighlights removes the highlights whose expiry date has passed,
+	// returning how many it removed
+	ExpireHighlights(ctx context.Context) (int, error)
+
+	// Run removes expired highlights periodically until ctx is done
+	Run(ctx context.Context)
+
+	// CheckLinks checks the source URLs due for a check, notifying the owners
+	// of datasets whose source broke, and returns how many are broken
+	CheckLinks(ctx context.Context) (int, error)
+
+	// RunLinkChecks checks source URLs periodically until ctx is done
+	RunLinkChecks(ctx context.Context)
 }
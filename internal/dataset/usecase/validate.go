@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"portal-data-backend/infrastructure/audit"
+	"portal-data-backend/infrastructure/db"
+	"portal-data-backend/internal/dataset/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+)
+
+// Validate sets the validation status of a dataset in the transaction ctx
+// carries, if any. Moving it back to pending asks for another review.
+func (u *datasetUsecase) Validate(ctx context.Context, id string, status domain.ValidationStatus, updaterID string) (*domain.DatasetResponse, error) {
+	switch status {
+	case domain.ValidationStatusValid, domain.ValidationStatusInvalid, domain.ValidationStatusPending:
+	default:
+		return nil, fmt.Errorf("%w: validation status must be valid, invalid or pending", pkgErrors.ErrInvalidInput)
+	}
+
+	var resp *domain.DatasetResponse
+	var dataset *domain.Dataset
+	err := u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		dataset, err = u.datasetRepo.GetByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get dataset: %w", err)
+		}
+
+		if err := u.datasetRepo.SetValidationStatus(ctx, id, status, updaterID); err != nil {
+			return err
+		}
+		validated, err := u.datasetRepo.GetByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to fetch validated dataset: %w", err)
+		}
+		u.audit.Record(ctx, "datasets", id, audit.ActionUpdate, dataset, validated)
+
+		responses, err := u.toResponses(ctx, validated)
+		if err != nil {
+			return err
+		}
+		resp = responses[0]
+		if err := u.publish(ctx, domain.EventDatasetUpdated, resp); err != nil {
+			return err
+		}
+		if status == domain.ValidationStatusPending && dataset.ValidationStatus != domain.ValidationStatusPending {
+			return u.publish(ctx, domain.EventDatasetPendingReview, resp)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	db.AfterCommit(ctx, func() { u.bySlug.Delete(ctx, dataset.Slug) })
+	u.purge(ctx, dataset)
+	return resp, nil
+}
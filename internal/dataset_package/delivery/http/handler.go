@@ -0,0 +1,139 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"portal-data-backend/infrastructure/auth"
+	"portal-data-backend/infrastructure/http/httputil"
+	"portal-data-backend/infrastructure/http/middleware"
+	"portal-data-backend/infrastructure/http/problem"
+	"portal-data-backend/infrastructure/http/response"
+	"portal-data-backend/infrastructure/logger"
+	"portal-data-backend/internal/dataset_package/usecase"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Handler handles HTTP requests for dataset packages
+type Handler struct {
+	packageUsecase usecase.Usecase
+}
+
+// NewHandler creates a new dataset package handler
+func NewHandler(packageUsecase usecase.Usecase) *Handler {
+	return &Handler{
+		packageUsecase: packageUsecase,
+	}
+}
+
+// Export streams the package of a dataset as a zip file
+func (h *Handler) Export(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	manifest, err := h.packageUsecase.Manifest(r.Context(), id)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+manifest.Dataset.Slug+`.zip"`)
+	w.WriteHeader(http.StatusOK)
+	// The response has started, so a failure can only cut it short
+	if err := h.packageUsecase.Export(r.Context(), manifest, w); err != nil {
+		logger.FromContext(r.Context()).Error("Failed to export dataset package %s: %v", manifest.Dataset.ID, err)
+	}
+}
+
+// DataPackage sends the Frictionless descriptor of the package of a dataset,
+// as is, so that data package tooling can read it
+func (h *Handler) DataPackage(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	manifest, err := h.packageUsecase.Manifest(r.Context(), id)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	pkg, err := h.packageUsecase.DataPackage(r.Context(), manifest)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(pkg); err != nil {
+		logger.FromContext(r.Context()).Error("failed to write data package %s: %v", manifest.Dataset.ID, err)
+	}
+}
+
+// Import creates a dataset from an uploaded package, in the organization of
+// the organization_id form field or else of the caller
+func (h *Handler) Import(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		if httputil.BodyTooLarge(w, err) {
+			return
+		}
+		response.BadRequest(w, response.CodeBadRequest, "Failed to parse form data", nil)
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		response.BadRequest(w, response.CodeBadRequest, "File is required", nil)
+		return
+	}
+	defer file.Close()
+
+	userID := auth.UserID(r.Context())
+	orgID := r.FormValue("organization_id")
+	if orgID == "" {
+		orgID = auth.FromContext(r.Context()).OrganizationID
+	}
+	if orgID == "" {
+		response.BadRequest(w, response.CodeBadRequest, "Organization ID is required", nil)
+		return
+	}
+
+	result, err := h.packageUsecase.Import(r.Context(), file, header.Size, userID, orgID)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.Created(w, response.CodeCreated, "Dataset package imported successfully", result)
+}
+
+// errorMapper maps the errors of the dataset package module on top of the
+// shared ones
+var errorMapper = problem.Default.With(
+	problem.Mapping{Err: pkgErrors.ErrNotFound, Status: http.StatusNotFound, Code: response.CodeNotFound, Message: "Dataset not found"},
+)
+
+func (h *Handler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	errorMapper.Write(w, r, err)
+}
+
+// RegisterRoutes registers dataset package routes, open to users with one of
+// adminRoles but for data package descriptors, which describe rows any user
+// can read
+func RegisterRoutes(r chi.Router, handler *Handler, auth func(http.Handler) http.Handler, adminRoles []string) {
+	r.Group(func(r chi.Router) {
+		r.Use(auth)
+		r.Get("/datasets/{id}/datapackage.json", handler.DataPackage)
+	})
+	r.Group(func(r chi.Router) {
+		r.Use(auth)
+		r.Use(middleware.RequireRole(adminRoles...))
+		r.Get("/datasets/{id}/package", handler.Export)
+		r.Post("/datasets/import-package", handler.Import)
+	})
+}
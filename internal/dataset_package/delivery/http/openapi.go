@@ -0,0 +1,16 @@
+package http
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	"portal-data-backend/internal/dataset_package/domain"
+)
+
+// Describe adds the dataset package routes to the API specification
+func Describe(spec *openapi.Spec) {
+	api := spec.Tag("dataset packages", "Datasets with their rows and files as zip packages")
+	api.Get("/datasets/{id}/package", "Export a dataset with its rows and files").ReturnsFile(http.StatusOK, "application/zip")
+	api.Get("/datasets/{id}/datapackage.json", "Get the Frictionless data package descriptor of a dataset").ReturnsBody(http.StatusOK, domain.DataPackage{})
+	api.Post("/datasets/import-package", "Import a dataset package").Upload("file", "organization_id").Returns(http.StatusCreated, domain.ImportResult{})
+}
@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"time"
+)
+
+// DataPackagePath is the Frictionless descriptor of a package, next to
+// metadata.json, so that data package tooling can validate packages
+const DataPackagePath = "datapackage.json"
+
+// Frictionless profiles of descriptors
+const (
+	ProfileDataPackage     = "data-package"
+	ProfileDataResource    = "data-resource"
+	ProfileTabularResource = "tabular-data-resource"
+)
+
+// DataPackage is a Frictionless Data Package descriptor of a dataset. Its
+// resources are the rows, with their schema, and the files of the dataset,
+// at their paths in the package.
+type DataPackage struct {
+	Profile      string        `json:"profile"`
+	Name         string        `json:"name"`
+	ID           string        `json:"id"`
+	Title        string        `json:"title"`
+	Description  string        `json:"description,omitempty"`
+	Keywords     []string      `json:"keywords,omitempty"`
+	Created      time.Time     `json:"created"`
+	Licenses     []License     `json:"licenses,omitempty"`
+	Sources      []Source      `json:"sources,omitempty"`
+	Contributors []Contributor `json:"contributors,omitempty"`
+	Resources    []Resource    `json:"resources"`
+}
+
+// License is a license the data is published under
+type License struct {
+	Name  string `json:"name,omitempty"`
+	Path  string `json:"path,omitempty"`
+	Title string `json:"title,omitempty"`
+}
+
+// Source is where the data comes from
+type Source struct {
+	Title string `json:"title"`
+	Path  string `json:"path,omitempty"`
+}
+
+// Contributor is a party responsible for the data, like the organization
+// publishing it
+type Contributor struct {
+	Title string `json:"title"`
+	Role  string `json:"role"`
+}
+
+// Resource is a file of a data package. Tabular resources have a schema.
+type Resource struct {
+	Profile   string       `json:"profile"`
+	Name      string       `json:"name"`
+	Path      string       `json:"path"`
+	Title     string       `json:"title,omitempty"`
+	Format    string       `json:"format,omitempty"`
+	MediaType string       `json:"mediatype,omitempty"`
+	Encoding  string       `json:"encoding,omitempty"`
+	Bytes     int64        `json:"bytes,omitempty"`
+	Schema    *TableSchema `json:"schema,omitempty"`
+}
+
+// TableSchema is the Table Schema of a tabular resource
+type TableSchema struct {
+	Fields []Field `json:"fields"`
+}
+
+// Field is a column of a Table Schema, typed with the types of Table Schema
+// like "string", "number", "boolean" or "any"
+type Field struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"time"
+
+	datasetDomain "portal-data-backend/internal/dataset/domain"
+)
+
+// Version is the version of the package format exports write and imports
+// read
+const Version = 1
+
+// Paths of the entries of a package
+const (
+	ManifestPath = "metadata.json"
+	RowsPath     = "data/rows.csv"
+	FilesDir     = "files/"
+)
+
+// Manifest is the metadata.json of a dataset package: the dataset, the
+// columns of its rows and its files
+type Manifest struct {
+	Version    int                           `json:"version"`
+	ExportedAt time.Time                     `json:"exported_at"`
+	Dataset    datasetDomain.DatasetResponse `json:"dataset"`
+	Rows       RowsEntry                     `json:"rows"`
+	Files      []FileEntry                   `json:"files"`
+}
+
+// RowsEntry describes the CSV of the rows of the dataset, in row order
+type RowsEntry struct {
+	Path    string   `json:"path"`
+	Count   int      `json:"count"`
+	Columns []Column `json:"columns"`
+}
+
+// ColumnType tells how the cells of a column encode the values of rows
+type ColumnType string
+
+const (
+	// ColumnTypeString cells are strings as they are
+	ColumnTypeString ColumnType = "string"
+	// ColumnTypeNumber cells are JSON numbers
+	ColumnTypeNumber ColumnType = "number"
+	// ColumnTypeBoolean cells are true or false
+	ColumnTypeBoolean ColumnType = "boolean"
+	// ColumnTypeJSON cells are JSON values, for columns mixing types or
+	// holding objects, arrays, nulls or empty strings
+	ColumnTypeJSON ColumnType = "json"
+)
+
+// Column is a field of the rows of the dataset. Rows without the field have
+// an empty cell.
+type Column struct {
+	Name string     `json:"name"`
+	Type ColumnType `json:"type"`
+}
+
+// FileEntry is a file of the dataset stored at Path in the package. ID is
+// the file in the portal it was exported from.
+type FileEntry struct {
+	ID       string `json:"id"`
+	Path     string `json:"path"`
+	Name     string `json:"name"`
+	MimeType string `json:"mime_type"`
+	Size     int64  `json:"size"`
+}
+
+// ImportResult reports the dataset an import created. Files failing to
+// upload are listed in FailedFiles rather than failing the import.
+type ImportResult struct {
+	Dataset     *datasetDomain.DatasetResponse `json:"dataset"`
+	Rows        int                            `json:"rows"`
+	Files       int                            `json:"files"`
+	FailedFiles []string                       `json:"failed_files,omitempty"`
+}
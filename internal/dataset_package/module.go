@@ -0,0 +1,73 @@
+// Package datasetpackage is the module moving datasets between portals as
+// zip packages of their metadata, rows and files.
+package datasetpackage
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	"portal-data-backend/internal/app"
+	delivery "portal-data-backend/internal/dataset_package/delivery/http"
+	"portal-data-backend/internal/dataset_package/usecase"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Module exports and imports dataset packages with the usecases of the
+// modules owning their parts
+type Module struct {
+	handler    *delivery.Handler
+	adminRoles []string
+}
+
+// Name implements app.Module
+func (m *Module) Name() string {
+	return "dataset_package"
+}
+
+// Register implements app.Module
+func (m *Module) Register(deps *app.Deps) error {
+	services := deps.Services
+	switch {
+	case services.Datasets == nil:
+		return app.MissingServiceError("dataset")
+	case services.DataRows == nil:
+		return app.MissingServiceError("data row")
+	case services.Files == nil:
+		return app.MissingServiceError("file")
+	case services.Organizations == nil:
+		return app.MissingServiceError("organization")
+	case services.Topics == nil:
+		return app.MissingServiceError("topic")
+	case services.Units == nil:
+		return app.MissingServiceError("unit")
+	case services.BusinessFields == nil:
+		return app.MissingServiceError("business field")
+	case services.Tags == nil:
+		return app.MissingServiceError("tag")
+	}
+
+	packages := usecase.NewPackageUsecase(deps.Tx, usecase.Stores{
+		Datasets:       services.Datasets,
+		DataRows:       services.DataRows,
+		Files:          services.Files,
+		Organizations:  services.Organizations,
+		Topics:         services.Topics,
+		Units:          services.Units,
+		BusinessFields: services.BusinessFields,
+		Tags:           services.Tags,
+	}, deps.Config.License, deps.Config.Package)
+	m.handler = delivery.NewHandler(packages)
+	m.adminRoles = deps.Config.Audit.AdminRoles
+	return nil
+}
+
+// Routes implements app.Module
+func (m *Module) Routes(r chi.Router, auth func(http.Handler) http.Handler) {
+	delivery.RegisterRoutes(r, m.handler, auth, m.adminRoles)
+}
+
+// Describe implements app.Module
+func (m *Module) Describe(spec *openapi.Spec) {
+	delivery.Describe(spec)
+}
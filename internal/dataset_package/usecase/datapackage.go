@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"portal-data-backend/internal/dataset_package/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+)
+
+// fieldTypes are the Table Schema types of the column types. JSON cells
+// may hold any JSON value.
+var fieldTypes = map[domain.ColumnType]string{
+	domain.ColumnTypeString:  "string",
+	domain.ColumnTypeNumber:  "number",
+	domain.ColumnTypeBoolean: "boolean",
+	domain.ColumnTypeJSON:    "any",
+}
+
+func (u *packageUsecase) DataPackage(ctx context.Context, manifest *domain.Manifest) (*domain.DataPackage, error) {
+	dataset := manifest.Dataset
+	pkg := &domain.DataPackage{
+		Profile:   domain.ProfileDataPackage,
+		Name:      resourceName(dataset.Slug, dataset.ID),
+		ID:        dataset.ID,
+		Title:     dataset.Name,
+		Created:   dataset.CreatedAt,
+		Resources: []domain.Resource{},
+	}
+	if dataset.Description != nil {
+		pkg.Description = *dataset.Description
+	}
+	for _, tag := range dataset.Tags {
+		pkg.Keywords = append(pkg.Keywords, tag.Name)
+	}
+	if u.license.Name != "" || u.license.URL != "" {
+		pkg.Licenses = []domain.License{{Name: u.license.Name, Path: u.license.URL, Title: u.license.Title}}
+	}
+	if dataset.SourceURL != nil && *dataset.SourceURL != "" {
+		pkg.Sources = []domain.Source{{Title: *dataset.SourceURL, Path: *dataset.SourceURL}}
+	}
+
+	org, err := u.stores.Organizations.GetByID(ctx, dataset.OrganizationID)
+	switch {
+	case err == nil:
+		pkg.Contributors = []domain.Contributor{{Title: org.Name, Role: "publisher"}}
+	case !errors.Is(err, pkgErrors.ErrNotFound):
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	fields := make([]domain.Field, len(manifest.Rows.Columns))
+	for i, column := range manifest.Rows.Columns {
+		fields[i] = domain.Field{Name: column.Name, Type: fieldTypes[column.Type]}
+	}
+	pkg.Resources = append(pkg.Resources, domain.Resource{
+		Profile:   domain.ProfileTabularResource,
+		Name:      "rows",
+		Path:      manifest.Rows.Path,
+		Title:     dataset.Name,
+		Format:    "csv",
+		MediaType: "text/csv",
+		Encoding:  "utf-8",
+		Schema:    &domain.TableSchema{Fields: fields},
+	})
+
+	used := map[string]bool{"rows": true}
+	for _, file := range manifest.Files {
+		name := resourceName(strings.TrimSuffix(file.Name, path.Ext(file.Name)), file.ID)
+		unique := name
+		for n := 2; used[unique]; n++ {
+			unique = fmt.Sprintf("%s-%d", name, n)
+		}
+		used[unique] = true
+
+		pkg.Resources = append(pkg.Resources, domain.Resource{
+			Profile:   domain.ProfileDataResource,
+			Name:      unique,
+			Path:      file.Path,
+			Title:     file.Name,
+			Format:    strings.ToLower(strings.TrimPrefix(path.Ext(file.Path), ".")),
+			MediaType: file.MimeType,
+			Bytes:     file.Size,
+		})
+	}
+	return pkg, nil
+}
+
+// resourceName turns value into a name Frictionless accepts, lower case
+// letters, digits and "-", "_" or ".", or returns fallback when nothing of
+// value is left
+func resourceName(value, fallback string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(value) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_', r == '.':
+			b.WriteRune(r)
+			dash = false
+		case b.Len() > 0 && !dash:
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	if name := strings.Trim(b.String(), "-."); name != "" {
+		return name
+	}
+	return strings.ToLower(fallback)
+}
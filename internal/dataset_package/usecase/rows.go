@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"portal-data-backend/internal/dataset_package/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+)
+
+// columnSet collects the columns of rows, typing each by the values it
+// holds. A column holding values of several types, or values a plain cell
+// cannot tell apart from a missing one, is typed json.
+type columnSet map[string]domain.ColumnType
+
+func (c columnSet) add(row map[string]interface{}) {
+	for name, value := range row {
+		valueType := columnType(value)
+		if existing, ok := c[name]; ok && existing != valueType {
+			valueType = domain.ColumnTypeJSON
+		}
+		c[name] = valueType
+	}
+}
+
+// list returns the columns ordered by name
+func (c columnSet) list() []domain.Column {
+	columns := make([]domain.Column, 0, len(c))
+	for name, columnType := range c {
+		columns = append(columns, domain.Column{Name: name, Type: columnType})
+	}
+	sort.Slice(columns, func(i, j int) bool { return columns[i].Name < columns[j].Name })
+	return columns
+}
+
+func columnType(value interface{}) domain.ColumnType {
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return domain.ColumnTypeJSON
+		}
+		return domain.ColumnTypeString
+	case json.Number:
+		return domain.ColumnTypeNumber
+	case bool:
+		return domain.ColumnTypeBoolean
+	default:
+		return domain.ColumnTypeJSON
+	}
+}
+
+// decodeRow decodes the data of a row, keeping numbers as they are written
+func decodeRow(data string) (map[string]interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(data)))
+	decoder.UseNumber()
+	var row map[string]interface{}
+	if err := decoder.Decode(&row); err != nil {
+		return nil, err
+	}
+	return row, nil
+}
+
+// encodeRow returns the cells of row for columns, empty for the fields it
+// does not have
+func encodeRow(columns []domain.Column, row map[string]interface{}) ([]string, error) {
+	cells := make([]string, len(columns))
+	for i, column := range columns {
+		value, ok := row[column.Name]
+		if !ok {
+			continue
+		}
+		// Rows may change between listing the columns and writing them
+		if column.Type != domain.ColumnTypeJSON && columnType(value) != column.Type {
+			return nil, fmt.Errorf("%s changed type during the export", column.Name)
+		}
+		switch column.Type {
+		case domain.ColumnTypeString:
+			cells[i] = value.(string)
+		case domain.ColumnTypeNumber:
+			cells[i] = value.(json.Number).String()
+		case domain.ColumnTypeBoolean:
+			cells[i] = strconv.FormatBool(value.(bool))
+		default:
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode %s: %w", column.Name, err)
+			}
+			cells[i] = string(encoded)
+		}
+	}
+	return cells, nil
+}
+
+// parseRow returns the data of the row with cells, leaving out the fields
+// of empty cells
+func parseRow(columns []domain.Column, cells []string) (string, error) {
+	row := make(map[string]interface{}, len(columns))
+	for i, column := range columns {
+		cell := cells[i]
+		if cell == "" {
+			continue
+		}
+		switch column.Type {
+		case domain.ColumnTypeString:
+			row[column.Name] = cell
+		case domain.ColumnTypeNumber:
+			if _, err := strconv.ParseFloat(cell, 64); err != nil {
+				return "", fmt.Errorf("%w: %s is not a number: %q", pkgErrors.ErrInvalidInput, column.Name, cell)
+			}
+			row[column.Name] = json.Number(cell)
+		case domain.ColumnTypeBoolean:
+			value, err := strconv.ParseBool(cell)
+			if err != nil {
+				return "", fmt.Errorf("%w: %s is not a boolean: %q", pkgErrors.ErrInvalidInput, column.Name, cell)
+			}
+			row[column.Name] = value
+		case domain.ColumnTypeJSON:
+			if !json.Valid([]byte(cell)) {
+				return "", fmt.Errorf("%w: %s is not JSON: %q", pkgErrors.ErrInvalidInput, column.Name, cell)
+			}
+			row[column.Name] = json.RawMessage(cell)
+		default:
+			return "", fmt.Errorf("%w: column %s has unknown type %q", pkgErrors.ErrInvalidInput, column.Name, column.Type)
+		}
+	}
+
+	data, err := json.Marshal(row)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode row: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,577 @@
+package usecase
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/infrastructure/db"
+	businessFieldDomain "portal-data-backend/internal/business_field/domain"
+	dataRowDomain "portal-data-backend/internal/data_row/domain"
+	datasetDomain "portal-data-backend/internal/dataset/domain"
+	"portal-data-backend/internal/dataset_package/domain"
+	fileDomain "portal-data-backend/internal/file/domain"
+	orgDomain "portal-data-backend/internal/organization/domain"
+	tagDomain "portal-data-backend/internal/tag/domain"
+	topicDomain "portal-data-backend/internal/topic/domain"
+	unitDomain "portal-data-backend/internal/unit/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+)
+
+// rowBatch is how many rows are read or written at a time
+const rowBatch = 1000
+
+// DatasetStore is the part of the dataset module packages are read from and
+// created through
+type DatasetStore interface {
+	GetByID(ctx context.Context, id string) (*datasetDomain.DatasetResponse, error)
+	Create(ctx context.Context, req *datasetDomain.CreateDatasetRequest, creatorID, orgID string) (*datasetDomain.DatasetResponse, error)
+}
+
+// DataRowStore is the part of the data row module rows are exported and
+// imported through
+type DataRowStore interface {
+	List(ctx context.Context, req *dataRowDomain.ListDataRowsRequest) (*dataRowDomain.DataRowListResponse, error)
+	BulkCreate(ctx context.Context, req *dataRowDomain.BulkCreateDataRowsRequest, userID string) error
+}
+
+// FileStore is the part of the file module files are exported and imported
+// through
+type FileStore interface {
+	GetByDatasetID(ctx context.Context, datasetID string, page, limit int) (*fileDomain.FileListResponse, error)
+	Open(ctx context.Context, id string) (*fileDomain.FileInfo, io.ReadCloser, error)
+	Upload(ctx context.Context, fileName string, fileSize int64, mimeType string, reader io.Reader, datasetID *string, userID string) (*fileDomain.UploadResponse, error)
+}
+
+// OrganizationStore is the part of the organization module the publishers
+// of data packages are read from
+type OrganizationStore interface {
+	GetByID(ctx context.Context, id string) (*orgDomain.OrganizationResponse, error)
+}
+
+// TopicStore is the part of the topic module imports resolve topics through
+type TopicStore interface {
+	List(ctx context.Context, req *topicDomain.ListTopicsRequest) (*topicDomain.TopicListResponse, error)
+	Create(ctx context.Context, req *topicDomain.CreateTopicRequest) (*topicDomain.TopicResponse, error)
+}
+
+// UnitStore is the part of the unit module imports resolve units through
+type UnitStore interface {
+	List(ctx context.Context, req *unitDomain.ListUnitsRequest) (*unitDomain.UnitListResponse, error)
+	Create(ctx context.Context, req *unitDomain.CreateUnitRequest) (*unitDomain.UnitResponse, error)
+}
+
+// BusinessFieldStore is the part of the business field module imports
+// resolve business fields through
+type BusinessFieldStore interface {
+	List(ctx context.Context, req *businessFieldDomain.ListBusinessFieldsRequest) (*businessFieldDomain.BusinessFieldListResponse, error)
+	Create(ctx context.Context, req *businessFieldDomain.CreateBusinessFieldRequest) (*businessFieldDomain.BusinessFieldResponse, error)
+}
+
+// TagStore is the part of the tag module imports resolve tags through
+type TagStore interface {
+	List(ctx context.Context, req *tagDomain.ListTagsRequest) (*tagDomain.TagListResponse, error)
+	Create(ctx context.Context, req *tagDomain.CreateTagRequest) (*tagDomain.TagResponse, error)
+}
+
+// Usecase exports a dataset with its rows and files as a zip package and
+// imports such packages, to move content between portals
+type Usecase interface {
+	// Manifest describes the package of the dataset with id. It fails
+	// before anything is written, so callers can still report errors.
+	Manifest(ctx context.Context, id string) (*domain.Manifest, error)
+	// Export writes the package of manifest to w
+	Export(ctx context.Context, manifest *domain.Manifest, w io.Writer) error
+	// DataPackage describes the package of manifest as a Frictionless data
+	// package, for tooling to validate the rows and files of the dataset
+	DataPackage(ctx context.Context, manifest *domain.Manifest) (*domain.DataPackage, error)
+	// Import creates the dataset of the package in r, of size bytes, in the
+	// organization orgID with its rows and files
+	Import(ctx context.Context, r io.ReaderAt, size int64, userID, orgID string) (*domain.ImportResult, error)
+}
+
+// Stores are the modules packages read and write through
+type Stores struct {
+	Datasets       DatasetStore
+	DataRows       DataRowStore
+	Files          FileStore
+	Organizations  OrganizationStore
+	Topics         TopicStore
+	Units          UnitStore
+	BusinessFields BusinessFieldStore
+	Tags           TagStore
+}
+
+type packageUsecase struct {
+	tx      db.Transactor
+	stores  Stores
+	license config.LicenseConfig
+	limits  config.PackageConfig
+	now     func() time.Time
+}
+
+// NewPackageUsecase creates the dataset package usecase. Imports create the
+// dataset and its rows in one transaction of tx, refusing packages beyond
+// limits. Data packages are published under license.
+func NewPackageUsecase(tx db.Transactor, stores Stores, license config.LicenseConfig, limits config.PackageConfig) Usecase {
+	return &packageUsecase{tx: tx, stores: stores, license: license, limits: limits, now: time.Now}
+}
+
+func (u *packageUsecase) Manifest(ctx context.Context, id string) (*domain.Manifest, error) {
+	dataset, err := u.stores.Datasets.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dataset: %w", err)
+	}
+
+	manifest := &domain.Manifest{
+		Version:    domain.Version,
+		ExportedAt: u.now().UTC(),
+		Dataset:    *dataset,
+		Rows:       domain.RowsEntry{Path: domain.RowsPath},
+		Files:      []domain.FileEntry{},
+	}
+
+	columns := columnSet{}
+	err = u.eachRow(ctx, id, func(row map[string]interface{}) error {
+		columns.add(row)
+		manifest.Rows.Count++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	manifest.Rows.Columns = columns.list()
+
+	for page := 1; ; page++ {
+		resp, err := u.stores.Files.GetByDatasetID(ctx, id, page, 100)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list dataset files: %w", err)
+		}
+		for _, file := range resp.Files {
+			manifest.Files = append(manifest.Files, domain.FileEntry{
+				ID:       file.ID,
+				Path:     domain.FilesDir + file.ID + file.Extension,
+				Name:     file.OriginalName,
+				MimeType: file.MimeType,
+				Size:     file.Size,
+			})
+		}
+		if page >= resp.Meta.TotalPage {
+			break
+		}
+	}
+	return manifest, nil
+}
+
+// Export writes the rows, the files and last metadata.json, whose row count
+// is the number of rows written, and datapackage.json. Fields added to rows
+// since the manifest was made are left out.
+func (u *packageUsecase) Export(ctx context.Context, manifest *domain.Manifest, w io.Writer) error {
+	archive := zip.NewWriter(w)
+
+	entry, err := archive.Create(manifest.Rows.Path)
+	if err != nil {
+		return fmt.Errorf("failed to write rows: %w", err)
+	}
+	rows := csv.NewWriter(entry)
+	header := make([]string, len(manifest.Rows.Columns))
+	for i, column := range manifest.Rows.Columns {
+		header[i] = column.Name
+	}
+	if err := rows.Write(header); err != nil {
+		return fmt.Errorf("failed to write rows: %w", err)
+	}
+	manifest.Rows.Count = 0
+	err = u.eachRow(ctx, manifest.Dataset.ID, func(row map[string]interface{}) error {
+		cells, err := encodeRow(manifest.Rows.Columns, row)
+		if err != nil {
+			return err
+		}
+		manifest.Rows.Count++
+		return rows.Write(cells)
+	})
+	if err != nil {
+		return err
+	}
+	rows.Flush()
+	if err := rows.Error(); err != nil {
+		return fmt.Errorf("failed to write rows: %w", err)
+	}
+
+	for _, file := range manifest.Files {
+		if err := u.exportFile(ctx, archive, file); err != nil {
+			return err
+		}
+	}
+
+	entry, err = archive.Create(domain.ManifestPath)
+	if err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	encoder := json.NewEncoder(entry)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(manifest); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	pkg, err := u.DataPackage(ctx, manifest)
+	if err != nil {
+		return err
+	}
+	entry, err = archive.Create(domain.DataPackagePath)
+	if err != nil {
+		return fmt.Errorf("failed to write data package: %w", err)
+	}
+	encoder = json.NewEncoder(entry)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(pkg); err != nil {
+		return fmt.Errorf("failed to write data package: %w", err)
+	}
+	return archive.Close()
+}
+
+func (u *packageUsecase) exportFile(ctx context.Context, archive *zip.Writer, file domain.FileEntry) error {
+	_, content, err := u.stores.Files.Open(ctx, file.ID)
+	if err != nil {
+		return fmt.Errorf("failed to export file %s: %w", file.Name, err)
+	}
+	defer content.Close()
+
+	entry, err := archive.Create(file.Path)
+	if err != nil {
+		return fmt.Errorf("failed to export file %s: %w", file.Name, err)
+	}
+	if _, err := io.Copy(entry, content); err != nil {
+		return fmt.Errorf("failed to export file %s: %w", file.Name, err)
+	}
+	return nil
+}
+
+// eachRow calls fn with the data of each row of the dataset, in row order
+func (u *packageUsecase) eachRow(ctx context.Context, datasetID string, fn func(row map[string]interface{}) error) error {
+	for page := 1; ; page++ {
+		resp, err := u.stores.DataRows.List(ctx, &dataRowDomain.ListDataRowsRequest{Page: page, Limit: rowBatch, DatasetID: datasetID})
+		if err != nil {
+			return fmt.Errorf("failed to list data rows: %w", err)
+		}
+		for _, info := range resp.Rows {
+			row, err := decodeRow(info.Data)
+			if err != nil {
+				return fmt.Errorf("row %d is not a JSON object: %w", info.RowIndex, err)
+			}
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+		if page >= resp.Meta.TotalPage {
+			return nil
+		}
+	}
+}
+
+// Import validates the manifest, creates the dataset with its taxonomy and
+// rows in one transaction, then uploads the files. The dataset is created
+// as a draft pending validation, like any new dataset.
+func (u *packageUsecase) Import(ctx context.Context, r io.ReaderAt, size int64, userID, orgID string) (*domain.ImportResult, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: the package is not a zip file: %v", pkgErrors.ErrInvalidInput, err)
+	}
+	entries := make(map[string]*zip.File, len(archive.File))
+	for _, file := range archive.File {
+		entries[file.Name] = file
+	}
+
+	manifest, err := readManifest(entries[domain.ManifestPath])
+	if err != nil {
+		return nil, err
+	}
+	if manifest.Rows.Count > u.limits.MaxRows {
+		return nil, fmt.Errorf("%w: the package has more than %d rows", pkgErrors.ErrInvalidInput, u.limits.MaxRows)
+	}
+	rowsEntry := entries[manifest.Rows.Path]
+	if rowsEntry == nil {
+		return nil, fmt.Errorf("%w: the package has no %s", pkgErrors.ErrInvalidInput, manifest.Rows.Path)
+	}
+
+	result := &domain.ImportResult{}
+	err = u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		req, err := u.createRequest(ctx, &manifest.Dataset)
+		if err != nil {
+			return err
+		}
+		dataset, err := u.stores.Datasets.Create(ctx, req, userID, orgID)
+		if err != nil {
+			return fmt.Errorf("failed to create dataset: %w", err)
+		}
+		result.Dataset = dataset
+
+		result.Rows, err = u.importRows(ctx, rowsEntry, manifest.Rows, dataset.ID, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Storage takes no part in the transaction, so files are uploaded once
+	// the dataset exists and a failing one does not undo the import
+	for _, file := range manifest.Files {
+		if err := u.importFile(ctx, entries[file.Path], file, result.Dataset.ID, userID); err != nil {
+			result.FailedFiles = append(result.FailedFiles, fmt.Sprintf("%s: %v", file.Name, err))
+			continue
+		}
+		result.Files++
+	}
+	return result, nil
+}
+
+func readManifest(entry *zip.File) (*domain.Manifest, error) {
+	if entry == nil {
+		return nil, fmt.Errorf("%w: the package has no %s", pkgErrors.ErrInvalidInput, domain.ManifestPath)
+	}
+	content, err := entry.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read %s: %v", pkgErrors.ErrInvalidInput, domain.ManifestPath, err)
+	}
+	defer content.Close()
+
+	var manifest domain.Manifest
+	if err := json.NewDecoder(content).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("%w: failed to read %s: %v", pkgErrors.ErrInvalidInput, domain.ManifestPath, err)
+	}
+	if manifest.Version != domain.Version {
+		return nil, fmt.Errorf("%w: package version %d is not supported", pkgErrors.ErrInvalidInput, manifest.Version)
+	}
+	if manifest.Dataset.Name == "" {
+		return nil, fmt.Errorf("%w: the package has no dataset", pkgErrors.ErrInvalidInput)
+	}
+	return &manifest, nil
+}
+
+// importRows writes the rows of the CSV entry to the dataset in batches,
+// numbering them in the order they come
+func (u *packageUsecase) importRows(ctx context.Context, entry *zip.File, rows domain.RowsEntry, datasetID, userID string) (int, error) {
+	content, err := entry.Open()
+	if err != nil {
+		return 0, fmt.Errorf("%w: failed to read %s: %v", pkgErrors.ErrInvalidInput, rows.Path, err)
+	}
+	defer content.Close()
+
+	reader := csv.NewReader(content)
+	reader.FieldsPerRecord = len(rows.Columns)
+	header, err := reader.Read()
+	if err != nil && !(errors.Is(err, io.EOF) && len(rows.Columns) == 0) {
+		return 0, fmt.Errorf("%w: failed to read the header of %s: %v", pkgErrors.ErrInvalidInput, rows.Path, err)
+	}
+	for i, column := range rows.Columns {
+		if header[i] != column.Name {
+			return 0, fmt.Errorf("%w: column %d of %s is %q, not %q", pkgErrors.ErrInvalidInput, i+1, rows.Path, header[i], column.Name)
+		}
+	}
+
+	count := 0
+	batch := make([]dataRowDomain.DataRowDataInput, 0, rowBatch)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := u.stores.DataRows.BulkCreate(ctx, &dataRowDomain.BulkCreateDataRowsRequest{DatasetID: datasetID, Rows: batch}, userID)
+		if err != nil {
+			return fmt.Errorf("failed to create data rows: %w", err)
+		}
+		batch = batch[:0]
+		return nil
+	}
+	add := func(data string) error {
+		if count == u.limits.MaxRows {
+			return fmt.Errorf("%w: %s has more than %d rows", pkgErrors.ErrInvalidInput, rows.Path, u.limits.MaxRows)
+		}
+		batch = append(batch, dataRowDomain.DataRowDataInput{RowIndex: count, Data: data})
+		count++
+		if len(batch) == rowBatch {
+			return flush()
+		}
+		return nil
+	}
+
+	// Rows without columns are empty lines, which CSV readers skip
+	if len(rows.Columns) == 0 {
+		for count < rows.Count {
+			if err := add("{}"); err != nil {
+				return 0, err
+			}
+		}
+		return count, flush()
+	}
+
+	for {
+		cells, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("%w: failed to read %s: %v", pkgErrors.ErrInvalidInput, rows.Path, err)
+		}
+		data, err := parseRow(rows.Columns, cells)
+		if err != nil {
+			return 0, fmt.Errorf("row %d: %w", count+1, err)
+		}
+		if err := add(data); err != nil {
+			return 0, err
+		}
+	}
+	return count, flush()
+}
+
+func (u *packageUsecase) importFile(ctx context.Context, entry *zip.File, file domain.FileEntry, datasetID, userID string) error {
+	if entry == nil {
+		return fmt.Errorf("missing from the package")
+	}
+	if entry.UncompressedSize64 > uint64(u.limits.MaxFileBytes) {
+		return fmt.Errorf("larger than %d bytes", u.limits.MaxFileBytes)
+	}
+	content, err := entry.Open()
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	// The entry is read no further than its declared size, so a package
+	// understating it cannot inflate into storage
+	size := int64(entry.UncompressedSize64)
+	_, err = u.stores.Files.Upload(ctx, file.Name, size, file.MimeType, io.LimitReader(content, size), &datasetID, userID)
+	return err
+}
+
+// createRequest returns the request creating dataset in this portal. Its
+// topic, unit, business field and tags are looked up by name, and created
+// when missing; the dataset it refers to is not carried over.
+func (u *packageUsecase) createRequest(ctx context.Context, dataset *datasetDomain.DatasetResponse) (*datasetDomain.CreateDatasetRequest, error) {
+	req := &datasetDomain.CreateDatasetRequest{
+		Name:           dataset.Name,
+		Classification: dataset.Classification,
+		Category:       dataset.Category,
+		DataFixed:      dataset.DataFixed,
+		IsHighlight:    dataset.IsHighlight,
+		Names:          dataset.Names,
+		Descriptions:   dataset.Descriptions,
+	}
+	if dataset.Description != nil {
+		req.Description = *dataset.Description
+	}
+	if dataset.CoverageStart != nil {
+		req.CoverageStart = *dataset.CoverageStart
+	}
+	if dataset.CoverageEnd != nil {
+		req.CoverageEnd = *dataset.CoverageEnd
+	}
+	if dataset.Frequency != nil {
+		req.Frequency = string(*dataset.Frequency)
+	}
+	if dataset.Image != nil {
+		req.Image = *dataset.Image
+	}
+	if dataset.Metadata != nil {
+		req.Metadata = *dataset.Metadata
+	}
+
+	var err error
+	if dataset.Topic != nil {
+		if req.TopicID, err = u.resolveTopic(ctx, dataset.Topic.Name); err != nil {
+			return nil, err
+		}
+	}
+	if dataset.Unit != nil {
+		if req.UnitID, err = u.resolveUnit(ctx, dataset.Unit); err != nil {
+			return nil, err
+		}
+	}
+	if dataset.BusinessField != nil {
+		if req.BusinessFieldID, err = u.resolveBusinessField(ctx, dataset.BusinessField.Name); err != nil {
+			return nil, err
+		}
+	}
+	for _, tag := range dataset.Tags {
+		tagID, err := u.resolveTag(ctx, tag.Name)
+		if err != nil {
+			return nil, err
+		}
+		req.TagIDs = append(req.TagIDs, tagID)
+	}
+	return req, nil
+}
+
+func (u *packageUsecase) resolveTopic(ctx context.Context, name string) (string, error) {
+	resp, err := u.stores.Topics.List(ctx, &topicDomain.ListTopicsRequest{Page: 1, Limit: 100, Search: name})
+	if err != nil {
+		return "", fmt.Errorf("failed to find topic %q: %w", name, err)
+	}
+	for _, topic := range resp.Topics {
+		if strings.EqualFold(topic.Name, name) {
+			return topic.ID, nil
+		}
+	}
+	topic, err := u.stores.Topics.Create(ctx, &topicDomain.CreateTopicRequest{Name: name})
+	if err != nil {
+		return "", fmt.Errorf("failed to create topic %q: %w", name, err)
+	}
+	return topic.ID, nil
+}
+
+func (u *packageUsecase) resolveUnit(ctx context.Context, unit *unitDomain.UnitResponse) (string, error) {
+	resp, err := u.stores.Units.List(ctx, &unitDomain.ListUnitsRequest{Page: 1, Limit: 100, Search: unit.Name})
+	if err != nil {
+		return "", fmt.Errorf("failed to find unit %q: %w", unit.Name, err)
+	}
+	for _, existing := range resp.Units {
+		if strings.EqualFold(existing.Name, unit.Name) {
+			return existing.ID, nil
+		}
+	}
+	created, err := u.stores.Units.Create(ctx, &unitDomain.CreateUnitRequest{Name: unit.Name, Symbol: unit.Symbol})
+	if err != nil {
+		return "", fmt.Errorf("failed to create unit %q: %w", unit.Name, err)
+	}
+	return created.ID, nil
+}
+
+func (u *packageUsecase) resolveBusinessField(ctx context.Context, name string) (string, error) {
+	resp, err := u.stores.BusinessFields.List(ctx, &businessFieldDomain.ListBusinessFieldsRequest{Page: 1, Limit: 100, Search: name})
+	if err != nil {
+		return "", fmt.Errorf("failed to find business field %q: %w", name, err)
+	}
+	for _, field := range resp.BusinessFields {
+		if strings.EqualFold(field.Name, name) {
+			return field.ID, nil
+		}
+	}
+	field, err := u.stores.BusinessFields.Create(ctx, &businessFieldDomain.CreateBusinessFieldRequest{Name: name})
+	if err != nil {
+		return "", fmt.Errorf("failed to create business field %q: %w", name, err)
+	}
+	return field.ID, nil
+}
+
+func (u *packageUsecase) resolveTag(ctx context.Context, name string) (string, error) {
+	resp, err := u.stores.Tags.List(ctx, &tagDomain.ListTagsRequest{Page: 1, Limit: 100, Search: name})
+	if err != nil {
+		return "", fmt.Errorf("failed to find tag %q: %w", name, err)
+	}
+	for _, tag := range resp.Tags {
+		if strings.EqualFold(tag.Name, name) {
+			return tag.ID, nil
+		}
+	}
+	tag, err := u.stores.Tags.Create(ctx, &tagDomain.CreateTagRequest{Name: name})
+	if err != nil {
+		return "", fmt.Errorf("failed to create tag %q: %w", name, err)
+	}
+	return tag.ID, nil
+}
@@ -0,0 +1,388 @@
+package usecase
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+	businessFieldDomain "portal-data-backend/internal/business_field/domain"
+	dataRowDomain "portal-data-backend/internal/data_row/domain"
+	datasetDomain "portal-data-backend/internal/dataset/domain"
+	"portal-data-backend/internal/dataset_package/domain"
+	fileDomain "portal-data-backend/internal/file/domain"
+	orgDomain "portal-data-backend/internal/organization/domain"
+	tagDomain "portal-data-backend/internal/tag/domain"
+	topicDomain "portal-data-backend/internal/topic/domain"
+	unitDomain "portal-data-backend/internal/unit/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+)
+
+// portal holds the datasets, rows, files and taxonomy of one portal
+type portal struct {
+	datasets map[string]*datasetDomain.DatasetResponse
+	created  *datasetDomain.CreateDatasetRequest
+	rows     map[string][]dataRowDomain.DataRowInfo
+	files    map[string][]fileDomain.FileInfo
+	contents map[string]string
+	topics   []topicDomain.TopicResponse
+	tags     []tagDomain.TagResponse
+}
+
+func newPortal() *portal {
+	return &portal{
+		datasets: map[string]*datasetDomain.DatasetResponse{},
+		rows:     map[string][]dataRowDomain.DataRowInfo{},
+		files:    map[string][]fileDomain.FileInfo{},
+		contents: map[string]string{},
+	}
+}
+
+func (p *portal) GetByID(ctx context.Context, id string) (*datasetDomain.DatasetResponse, error) {
+	dataset, ok := p.datasets[id]
+	if !ok {
+		return nil, pkgErrors.ErrNotFound
+	}
+	return dataset, nil
+}
+
+func (p *portal) Create(ctx context.Context, req *datasetDomain.CreateDatasetRequest, creatorID, orgID string) (*datasetDomain.DatasetResponse, error) {
+	p.created = req
+	dataset := &datasetDomain.DatasetResponse{ID: "imported", Name: req.Name, OrganizationID: orgID, Status: "draft"}
+	p.datasets[dataset.ID] = dataset
+	return dataset, nil
+}
+
+func (p *portal) List(ctx context.Context, req *dataRowDomain.ListDataRowsRequest) (*dataRowDomain.DataRowListResponse, error) {
+	rows := p.rows[req.DatasetID]
+	start := min((req.Page-1)*req.Limit, len(rows))
+	end := min(start+req.Limit, len(rows))
+	return &dataRowDomain.DataRowListResponse{
+		Rows: rows[start:end],
+		Meta: dataRowDomain.ListMeta{Page: req.Page, Limit: req.Limit, Total: len(rows), TotalPage: (len(rows) + req.Limit - 1) / req.Limit},
+	}, nil
+}
+
+func (p *portal) BulkCreate(ctx context.Context, req *dataRowDomain.BulkCreateDataRowsRequest, userID string) error {
+	for _, row := range req.Rows {
+		p.rows[req.DatasetID] = append(p.rows[req.DatasetID], dataRowDomain.DataRowInfo{DatasetID: req.DatasetID, RowIndex: row.RowIndex, Data: row.Data})
+	}
+	return nil
+}
+
+func (p *portal) GetByDatasetID(ctx context.Context, datasetID string, page, limit int) (*fileDomain.FileListResponse, error) {
+	return &fileDomain.FileListResponse{Files: p.files[datasetID], Meta: fileDomain.ListMeta{Page: 1, TotalPage: 1}}, nil
+}
+
+func (p *portal) Open(ctx context.Context, id string) (*fileDomain.FileInfo, io.ReadCloser, error) {
+	content, ok := p.contents[id]
+	if !ok {
+		return nil, nil, pkgErrors.ErrNotFound
+	}
+	return &fileDomain.FileInfo{ID: id}, io.NopCloser(strings.NewReader(content)), nil
+}
+
+func (p *portal) Upload(ctx context.Context, fileName string, fileSize int64, mimeType string, reader io.Reader, datasetID *string, userID string) (*fileDomain.UploadResponse, error) {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	id := "file-" + fileName
+	p.files[*datasetID] = append(p.files[*datasetID], fileDomain.FileInfo{ID: id, OriginalName: fileName, MimeType: mimeType, Size: fileSize})
+	p.contents[id] = string(content)
+	return &fileDomain.UploadResponse{ID: id}, nil
+}
+
+// topicStore finds existing topics and records created ones
+type topicStore struct{ p *portal }
+
+func (s topicStore) List(ctx context.Context, req *topicDomain.ListTopicsRequest) (*topicDomain.TopicListResponse, error) {
+	return &topicDomain.TopicListResponse{Topics: s.p.topics}, nil
+}
+
+func (s topicStore) Create(ctx context.Context, req *topicDomain.CreateTopicRequest) (*topicDomain.TopicResponse, error) {
+	topic := topicDomain.TopicResponse{ID: "topic-" + req.Name, Name: req.Name}
+	s.p.topics = append(s.p.topics, topic)
+	return &topic, nil
+}
+
+// tagStore finds existing tags and records created ones
+type tagStore struct{ p *portal }
+
+func (s tagStore) List(ctx context.Context, req *tagDomain.ListTagsRequest) (*tagDomain.TagListResponse, error) {
+	return &tagDomain.TagListResponse{Tags: s.p.tags}, nil
+}
+
+func (s tagStore) Create(ctx context.Context, req *tagDomain.CreateTagRequest) (*tagDomain.TagResponse, error) {
+	tag := tagDomain.TagResponse{ID: "tag-" + req.Name, Name: req.Name}
+	s.p.tags = append(s.p.tags, tag)
+	return &tag, nil
+}
+
+// organizationStore holds the publisher org-1 only
+type organizationStore struct{}
+
+func (organizationStore) GetByID(ctx context.Context, id string) (*orgDomain.OrganizationResponse, error) {
+	if id != "org-1" {
+		return nil, pkgErrors.ErrNotFound
+	}
+	return &orgDomain.OrganizationResponse{ID: id, Name: "Bappeda Kota Bandung"}, nil
+}
+
+type unitStore struct{}
+
+func (unitStore) List(ctx context.Context, req *unitDomain.ListUnitsRequest) (*unitDomain.UnitListResponse, error) {
+	return &unitDomain.UnitListResponse{}, nil
+}
+
+func (unitStore) Create(ctx context.Context, req *unitDomain.CreateUnitRequest) (*unitDomain.UnitResponse, error) {
+	return &unitDomain.UnitResponse{ID: "unit-" + req.Name}, nil
+}
+
+type businessFieldStore struct{}
+
+func (businessFieldStore) List(ctx context.Context, req *businessFieldDomain.ListBusinessFieldsRequest) (*businessFieldDomain.BusinessFieldListResponse, error) {
+	return &businessFieldDomain.BusinessFieldListResponse{}, nil
+}
+
+func (businessFieldStore) Create(ctx context.Context, req *businessFieldDomain.CreateBusinessFieldRequest) (*businessFieldDomain.BusinessFieldResponse, error) {
+	return &businessFieldDomain.BusinessFieldResponse{ID: "field-" + req.Name}, nil
+}
+
+// mockTransactor runs work without a transaction
+type mockTransactor struct{}
+
+func (mockTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func newTestUsecase(p *portal) *packageUsecase {
+	u := NewPackageUsecase(mockTransactor{}, Stores{
+		Datasets:       p,
+		DataRows:       p,
+		Files:          p,
+		Organizations:  organizationStore{},
+		Topics:         topicStore{p},
+		Units:          unitStore{},
+		BusinessFields: businessFieldStore{},
+		Tags:           tagStore{p},
+	}, config.LicenseConfig{Name: "CC-BY-4.0", URL: "https://creativecommons.org/licenses/by/4.0/"}, config.PackageConfig{MaxRows: 5, MaxFileBytes: 16}).(*packageUsecase)
+	u.now = func() time.Time { return time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC) }
+	return u
+}
+
+// Test a package recreates the dataset elsewhere with the same rows, files
+// and taxonomy by name
+func TestPackageUsecase_RoundTrip(t *testing.T) {
+	source := newPortal()
+	description := "Jumlah penduduk per kecamatan"
+	source.datasets["ds-1"] = &datasetDomain.DatasetResponse{
+		ID:             "ds-1",
+		Name:           "Jumlah Penduduk",
+		Slug:           "jumlah-penduduk",
+		Description:    &description,
+		Topic:          &topicDomain.TopicResponse{ID: "t-1", Name: "Kependudukan"},
+		Classification: "public",
+		Category:       "statistik",
+		Tags:           []tagDomain.TagResponse{{ID: "tag-1", Name: "penduduk"}},
+	}
+	rows := []string{
+		`{"kecamatan":"Coblong","jumlah":131000,"aktif":true,"catatan":""}`,
+		`{"kecamatan":"Sukajadi","jumlah":104500.5,"aktif":false,"wilayah":{"kota":"Bandung"}}`,
+		`{"kecamatan":"Cidadap, Utara","jumlah":"n/a"}`,
+	}
+	for i, data := range rows {
+		source.rows["ds-1"] = append(source.rows["ds-1"], dataRowDomain.DataRowInfo{DatasetID: "ds-1", RowIndex: i, Data: data})
+	}
+	source.files["ds-1"] = []fileDomain.FileInfo{{ID: "f-1", OriginalName: "penduduk.pdf", Extension: ".pdf", MimeType: "application/pdf", Size: 7}}
+	source.contents["f-1"] = "%PDF-1."
+
+	manifest, err := newTestUsecase(source).Manifest(context.Background(), "ds-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	wantColumns := []domain.Column{
+		{Name: "aktif", Type: domain.ColumnTypeBoolean},
+		{Name: "catatan", Type: domain.ColumnTypeJSON},
+		{Name: "jumlah", Type: domain.ColumnTypeJSON},
+		{Name: "kecamatan", Type: domain.ColumnTypeString},
+		{Name: "wilayah", Type: domain.ColumnTypeJSON},
+	}
+	if !reflect.DeepEqual(manifest.Rows.Columns, wantColumns) || manifest.Rows.Count != 3 {
+		t.Errorf("Expected 3 rows with columns %v, got %d with %v", wantColumns, manifest.Rows.Count, manifest.Rows.Columns)
+	}
+
+	var pkg bytes.Buffer
+	if err := newTestUsecase(source).Export(context.Background(), manifest, &pkg); err != nil {
+		t.Fatalf("Expected no error exporting, got %v", err)
+	}
+
+	target := newPortal()
+	target.topics = []topicDomain.TopicResponse{{ID: "t-9", Name: "kependudukan"}}
+	result, err := newTestUsecase(target).Import(context.Background(), bytes.NewReader(pkg.Bytes()), int64(pkg.Len()), "user-1", "org-2")
+	if err != nil {
+		t.Fatalf("Expected no error importing, got %v", err)
+	}
+	if result.Dataset.OrganizationID != "org-2" || result.Rows != 3 || result.Files != 1 || len(result.FailedFiles) != 0 {
+		t.Errorf("Expected the dataset in org-2 with 3 rows and 1 file, got %+v", result)
+	}
+	if target.created.TopicID != "t-9" || !reflect.DeepEqual(target.created.TagIDs, []string{"tag-penduduk"}) || target.created.Description != description {
+		t.Errorf("Expected the existing topic and a new tag, got %+v", target.created)
+	}
+	for i, row := range target.rows["imported"] {
+		want, _ := decodeRow(rows[i])
+		got, _ := decodeRow(row.Data)
+		if row.RowIndex != i || !reflect.DeepEqual(got, want) {
+			t.Errorf("Expected row %d to be %s, got %d %s", i, rows[i], row.RowIndex, row.Data)
+		}
+	}
+	if target.contents["file-penduduk.pdf"] != "%PDF-1." {
+		t.Errorf("Expected the file to be uploaded, got %v", target.contents)
+	}
+}
+
+// Test the data package describes the rows with their schema and the files
+// at their paths in the package, under names unique within it
+func TestPackageUsecase_DataPackage(t *testing.T) {
+	p := newPortal()
+	p.datasets["ds-1"] = &datasetDomain.DatasetResponse{
+		ID:             "ds-1",
+		Name:           "Jumlah Penduduk",
+		Slug:           "jumlah-penduduk",
+		OrganizationID: "org-1",
+		Tags:           []tagDomain.TagResponse{{ID: "tag-1", Name: "penduduk"}},
+	}
+	p.rows["ds-1"] = []dataRowDomain.DataRowInfo{{DatasetID: "ds-1", Data: `{"kecamatan":"Coblong","jumlah":131000}`}}
+	p.files["ds-1"] = []fileDomain.FileInfo{
+		{ID: "f-1", OriginalName: "Rows.csv", Extension: ".csv", MimeType: "text/csv", Size: 7},
+		{ID: "f-2", OriginalName: "rows.pdf", Extension: ".pdf", MimeType: "application/pdf", Size: 9},
+	}
+
+	u := newTestUsecase(p)
+	manifest, err := u.Manifest(context.Background(), "ds-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	pkg, err := u.DataPackage(context.Background(), manifest)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if pkg.Name != "jumlah-penduduk" || !reflect.DeepEqual(pkg.Keywords, []string{"penduduk"}) || len(pkg.Licenses) != 1 || pkg.Licenses[0].Name != "CC-BY-4.0" {
+		t.Errorf("Expected the dataset with its tags and license, got %+v", pkg)
+	}
+	if len(pkg.Contributors) != 1 || pkg.Contributors[0].Title != "Bappeda Kota Bandung" || pkg.Contributors[0].Role != "publisher" {
+		t.Errorf("Expected the organization as publisher, got %+v", pkg.Contributors)
+	}
+	if len(pkg.Resources) != 3 {
+		t.Fatalf("Expected the rows and 2 files, got %+v", pkg.Resources)
+	}
+	rows := pkg.Resources[0]
+	wantFields := []domain.Field{{Name: "jumlah", Type: "number"}, {Name: "kecamatan", Type: "string"}}
+	if rows.Profile != domain.ProfileTabularResource || rows.Path != domain.RowsPath || rows.Schema == nil || !reflect.DeepEqual(rows.Schema.Fields, wantFields) {
+		t.Errorf("Expected the rows with fields %v, got %+v", wantFields, rows)
+	}
+	for i, want := range []string{"rows-2", "rows-3"} {
+		file := pkg.Resources[i+1]
+		if file.Name != want || file.Path != manifest.Files[i].Path {
+			t.Errorf("Expected file %d named %s at %s, got %s at %s", i, want, manifest.Files[i].Path, file.Name, file.Path)
+		}
+	}
+}
+
+// Test packages that are not zip files or lack a manifest are invalid input
+func TestPackageUsecase_ImportInvalid(t *testing.T) {
+	u := newTestUsecase(newPortal())
+
+	for name, content := range map[string]string{
+		"not a zip": "kecamatan,jumlah\n",
+		"empty zip": "PK\x05\x06" + strings.Repeat("\x00", 18),
+	} {
+		_, err := u.Import(context.Background(), strings.NewReader(content), int64(len(content)), "user-1", "org-1")
+		if !errors.Is(err, pkgErrors.ErrInvalidInput) {
+			t.Errorf("Expected ErrInvalidInput for %s, got %v", name, err)
+		}
+	}
+}
+
+// packageOf zips manifest with the entries of contents
+func packageOf(t *testing.T, manifest domain.Manifest, contents map[string]string) *bytes.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	entry, err := archive.Create(domain.ManifestPath)
+	if err == nil {
+		err = json.NewEncoder(entry).Encode(manifest)
+	}
+	for path, content := range contents {
+		if err != nil {
+			break
+		}
+		if entry, err = archive.Create(path); err == nil {
+			_, err = io.WriteString(entry, content)
+		}
+	}
+	if err == nil {
+		err = archive.Close()
+	}
+	if err != nil {
+		t.Fatalf("Failed to zip package: %v", err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+// Test imports refuse more rows than allowed, whatever the manifest claims,
+// and files larger than allowed
+func TestPackageUsecase_ImportLimits(t *testing.T) {
+	manifest := domain.Manifest{
+		Version: domain.Version,
+		Dataset: datasetDomain.DatasetResponse{Name: "Jumlah Penduduk"},
+		Rows:    domain.RowsEntry{Path: domain.RowsPath},
+	}
+
+	for name, tc := range map[string]struct {
+		count int
+		rows  string
+		cols  []domain.Column
+	}{
+		"rows without columns": {count: 1 << 30},
+		"rows of a CSV":        {count: 2, rows: "jumlah\n1\n2\n3\n4\n5\n6\n", cols: []domain.Column{{Name: "jumlah", Type: domain.ColumnTypeNumber}}},
+	} {
+		p := newPortal()
+		manifest.Rows.Count, manifest.Rows.Columns = tc.count, tc.cols
+		pkg := packageOf(t, manifest, map[string]string{domain.RowsPath: tc.rows})
+		_, err := newTestUsecase(p).Import(context.Background(), pkg, pkg.Size(), "user-1", "org-1")
+		if !errors.Is(err, pkgErrors.ErrInvalidInput) {
+			t.Errorf("Expected ErrInvalidInput for %s, got %v", name, err)
+		}
+		if len(p.rows["imported"]) > 5 {
+			t.Errorf("Expected at most 5 rows for %s, got %d", name, len(p.rows["imported"]))
+		}
+	}
+
+	p := newPortal()
+	manifest.Rows.Count, manifest.Rows.Columns = 0, nil
+	manifest.Files = []domain.FileEntry{
+		{Path: "files/small.txt", Name: "small.txt", MimeType: "text/plain"},
+		{Path: "files/large.txt", Name: "large.txt", MimeType: "text/plain"},
+	}
+	pkg := packageOf(t, manifest, map[string]string{
+		domain.RowsPath:   "",
+		"files/small.txt": "kecamatan",
+		"files/large.txt": strings.Repeat("0", 1<<20),
+	})
+	result, err := newTestUsecase(p).Import(context.Background(), pkg, pkg.Size(), "user-1", "org-1")
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if result.Files != 1 || len(result.FailedFiles) != 1 || !strings.HasPrefix(result.FailedFiles[0], "large.txt") {
+		t.Errorf("Expected the large file alone to fail, got %+v", result)
+	}
+}
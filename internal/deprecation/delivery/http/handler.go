@@ -0,0 +1,56 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"portal-data-backend/infrastructure/http/middleware"
+	"portal-data-backend/infrastructure/http/problem"
+	"portal-data-backend/infrastructure/http/response"
+	deprecationDomain "portal-data-backend/internal/deprecation/domain"
+	"portal-data-backend/internal/deprecation/usecase"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	deprecationUsecase usecase.Usecase
+}
+
+func NewHandler(deprecationUsecase usecase.Usecase) *Handler {
+	return &Handler{
+		deprecationUsecase: deprecationUsecase,
+	}
+}
+
+// Report lists the deprecated surfaces of the API with the clients that
+// used them over the last days
+func (h *Handler) Report(w http.ResponseWriter, r *http.Request) {
+	req := &deprecationDomain.ReportRequest{Days: 30}
+	if value := r.URL.Query().Get("days"); value != "" {
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 1 || days > 365 {
+			response.BadRequest(w, response.CodeBadRequest, "days must be a number between 1 and 365", nil)
+			return
+		}
+		req.Days = days
+	}
+
+	report, err := h.deprecationUsecase.Report(r.Context(), req)
+	if err != nil {
+		problem.Default.Write(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Deprecation report retrieved successfully", report)
+}
+
+// RegisterRoutes registers the deprecation report for users with one of
+// adminRoles
+func RegisterRoutes(r chi.Router, handler *Handler, auth func(http.Handler) http.Handler, adminRoles []string) {
+	r.Route("/admin/deprecations", func(r chi.Router) {
+		r.Use(auth)
+		r.Use(middleware.RequireRole(adminRoles...))
+		r.Get("/", handler.Report)
+	})
+}
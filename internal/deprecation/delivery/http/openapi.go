@@ -0,0 +1,16 @@
+package http
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	deprecationDomain "portal-data-backend/internal/deprecation/domain"
+)
+
+// Describe adds the deprecation routes to the API specification
+func Describe(spec *openapi.Spec) {
+	api := spec.Tag("deprecations", "Deprecated routes and parameters and the clients still using them")
+	api.Get("/admin/deprecations", "Get deprecation report").
+		Query(deprecationDomain.ReportRequest{}, "days").
+		Returns(http.StatusOK, deprecationDomain.Report{})
+}
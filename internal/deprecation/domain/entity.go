@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"time"
+
+	"portal-data-backend/infrastructure/deprecation"
+)
+
+// Usage counts the requests a client made to a deprecated surface on a day
+type Usage struct {
+	NoticeID   string    `db:"notice_id"`
+	Client     string    `db:"client"`
+	Day        time.Time `db:"day"`
+	Requests   int64     `db:"requests"`
+	LastUsedAt time.Time `db:"last_used_at"`
+}
+
+// ClientUsage represents the requests a client made to a deprecated surface
+// over the period of a report
+type ClientUsage struct {
+	NoticeID    string    `db:"notice_id" json:"-"`
+	Client      string    `db:"client" json:"client"`
+	Requests    int64     `db:"requests" json:"requests"`
+	FirstUsedOn time.Time `db:"first_used_on" json:"first_used_on"`
+	LastUsedAt  time.Time `db:"last_used_at" json:"last_used_at"`
+}
+
+// ReportRequest represents deprecation report input: the usage of the last
+// Days days
+type ReportRequest struct {
+	Days int `json:"days" validate:"min=1,max=365"`
+}
+
+// Report represents the deprecated surfaces of the API and the clients
+// still using them
+type Report struct {
+	From    time.Time      `json:"from"`
+	Notices []NoticeReport `json:"notices"`
+}
+
+// NoticeReport represents a deprecated surface and its use, the clients
+// making the most requests first
+type NoticeReport struct {
+	ID string `json:"id"`
+	deprecation.Notice
+	Requests int64         `json:"requests"`
+	Clients  []ClientUsage `json:"clients"`
+}
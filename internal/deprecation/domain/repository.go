@@ -0,0 +1,14 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Repository defines the interface for deprecation usage data access
+type Repository interface {
+	// AddUsage adds counted usage to the usage recorded
+	AddUsage(ctx context.Context, usage []*Usage) error
+	// ListUsage sums the usage recorded since from per surface and client
+	ListUsage(ctx context.Context, from time.Time) ([]*ClientUsage, error)
+}
@@ -0,0 +1,54 @@
+// Package deprecation is the module tracking the use of the deprecated
+// routes and parameters of the API.
+package deprecation
+
+import (
+	"context"
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	"portal-data-backend/internal/app"
+	delivery "portal-data-backend/internal/deprecation/delivery/http"
+	"portal-data-backend/internal/deprecation/repository"
+	"portal-data-backend/internal/deprecation/usecase"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Module counts the requests clients make to the surfaces modules declare
+// deprecated and reports them to admins
+type Module struct {
+	usecase    usecase.Usecase
+	handler    *delivery.Handler
+	adminRoles []string
+}
+
+// Name implements app.Module
+func (m *Module) Name() string {
+	return "deprecation"
+}
+
+// Register implements app.Module
+func (m *Module) Register(deps *app.Deps) error {
+	repo := repository.NewDeprecationPostgresRepository(deps.DB)
+	m.usecase = usecase.NewDeprecationUsecase(repo, deps.Deprecations, deps.Config.Deprecation)
+	m.handler = delivery.NewHandler(m.usecase)
+	m.adminRoles = deps.Config.Audit.AdminRoles
+	deps.Services.Deprecations = m.usecase
+	return nil
+}
+
+// Routes implements app.Module
+func (m *Module) Routes(r chi.Router, auth func(http.Handler) http.Handler) {
+	delivery.RegisterRoutes(r, m.handler, auth, m.adminRoles)
+}
+
+// Describe implements app.Module
+func (m *Module) Describe(spec *openapi.Spec) {
+	delivery.Describe(spec)
+}
+
+// Run implements app.Runner
+func (m *Module) Run(ctx context.Context) {
+	m.usecase.Run(ctx)
+}
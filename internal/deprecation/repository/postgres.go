@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"portal-data-backend/infrastructure/db"
+	deprecationDomain "portal-data-backend/internal/deprecation/domain"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type deprecationPostgresRepository struct {
+	db *sqlx.DB
+}
+
+func NewDeprecationPostgresRepository(db *sqlx.DB) deprecationDomain.Repository {
+	return &deprecationPostgresRepository{db: db}
+}
+
+func (r *deprecationPostgresRepository) AddUsage(ctx context.Context, usage []*deprecationDomain.Usage) error {
+	query := `
+		INSERT INTO deprecation_usage (notice_id, client, day, requests, last_used_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (notice_id, client, day) DO UPDATE
+		SET requests = deprecation_usage.requests + EXCLUDED.requests,
+		    last_used_at = GREATEST(deprecation_usage.last_used_at, EXCLUDED.last_used_at)
+	`
+
+	conn := db.Conn(ctx, r.db)
+	for _, u := range usage {
+		if _, err := conn.ExecContext(ctx, query, u.NoticeID, u.Client, u.Day, u.Requests, u.LastUsedAt); err != nil {
+			return fmt.Errorf("failed to add deprecation usage: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *deprecationPostgresRepository) ListUsage(ctx context.Context, from time.Time) ([]*deprecationDomain.ClientUsage, error) {
+	query := `
+		SELECT notice_id, client, SUM(requests) AS requests, MIN(day) AS first_used_on, MAX(last_used_at) AS last_used_at
+		FROM deprecation_usage
+		WHERE day >= $1
+		GROUP BY notice_id, client
+		ORDER BY requests DESC, client
+	`
+
+	usage := []*deprecationDomain.ClientUsage{}
+	if err := db.Conn(ctx, r.db).SelectContext(ctx, &usage, query, from); err != nil {
+		return nil, fmt.Errorf("failed to list deprecation usage: %w", err)
+	}
+	return usage, nil
+}
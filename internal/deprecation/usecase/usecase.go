@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/infrastructure/deprecation"
+	"portal-data-backend/infrastructure/logger"
+	"portal-data-backend/internal/deprecation/domain"
+)
+
+// Usecase counts the use of the deprecated surfaces of the API per client
+// and reports it, so clients can be told before a surface is switched off
+type Usecase interface {
+	// Record counts a request of client to the surface of notice. Counts are
+	// kept in memory and written by Run.
+	Record(ctx context.Context, notice deprecation.Notice, client string)
+	// Report lists the deprecated surfaces with their use over the last days
+	Report(ctx context.Context, req *domain.ReportRequest) (*domain.Report, error)
+	// Run writes the counted usage every UsageFlushInterval until ctx is done
+	Run(ctx context.Context)
+}
+
+type deprecationUsecase struct {
+	repo     domain.Repository
+	registry *deprecation.Registry
+	cfg      config.DeprecationConfig
+	now      func() time.Time
+
+	mu     sync.Mutex
+	usage  map[string]*domain.Usage // by notice, client and day
+	logged map[string]bool          // buckets whose first use was logged
+}
+
+// NewDeprecationUsecase creates the deprecation usecase reporting the
+// notices of registry
+func NewDeprecationUsecase(repo domain.Repository, registry *deprecation.Registry, cfg config.DeprecationConfig) Usecase {
+	return &deprecationUsecase{
+		repo:     repo,
+		registry: registry,
+		cfg:      cfg,
+		now:      time.Now,
+		usage:    map[string]*domain.Usage{},
+		logged:   map[string]bool{},
+	}
+}
+
+func (u *deprecationUsecase) Record(ctx context.Context, notice deprecation.Notice, client string) {
+	now := u.now().UTC()
+	day := now.Truncate(24 * time.Hour)
+	id := notice.ID()
+	bucket := id + "|" + client + "|" + day.Format(time.DateOnly)
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	usage, ok := u.usage[bucket]
+	if !ok {
+		usage = &domain.Usage{NoticeID: id, Client: client, Day: day}
+		u.usage[bucket] = usage
+	}
+	usage.Requests++
+	usage.LastUsedAt = now
+
+	// Each client is logged once a day per surface
+	if !u.logged[bucket] {
+		u.logged[bucket] = true
+		logger.FromContext(ctx).Warn("deprecated %s used by %s", id, client)
+	}
+}
+
+func (u *deprecationUsecase) Report(ctx context.Context, req *domain.ReportRequest) (*domain.Report, error) {
+	if req.Days < 1 {
+		req.Days = 30
+	}
+	from := u.now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 1-req.Days)
+
+	usage, err := u.repo.ListUsage(ctx, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deprecation usage: %w", err)
+	}
+	byNotice := map[string][]*domain.ClientUsage{}
+	for _, clientUsage := range usage {
+		byNotice[clientUsage.NoticeID] = append(byNotice[clientUsage.NoticeID], clientUsage)
+	}
+
+	notices := u.registry.Notices()
+	report := &domain.Report{From: from, Notices: make([]domain.NoticeReport, len(notices))}
+	for i, notice := range notices {
+		noticeReport := domain.NoticeReport{ID: notice.ID(), Notice: notice, Clients: []domain.ClientUsage{}}
+		for _, clientUsage := range byNotice[noticeReport.ID] {
+			noticeReport.Requests += clientUsage.Requests
+			noticeReport.Clients = append(noticeReport.Clients, *clientUsage)
+		}
+		report.Notices[i] = noticeReport
+	}
+	return report, nil
+}
+
+func (u *deprecationUsecase) Run(ctx context.Context) {
+	ticker := time.NewTicker(u.cfg.UsageFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Write what was counted since the last flush before stopping
+			u.flush(context.WithoutCancel(ctx))
+			return
+		case <-ticker.C:
+			u.flush(ctx)
+		}
+	}
+}
+
+// flush writes the counted usage. Counts that fail to be written are kept
+// for the next flush.
+func (u *deprecationUsecase) flush(ctx context.Context) {
+	today := u.now().UTC().Format(time.DateOnly)
+
+	u.mu.Lock()
+	pending := u.usage
+	u.usage = map[string]*domain.Usage{}
+	for bucket := range u.logged {
+		if bucket[len(bucket)-len(today):] != today {
+			delete(u.logged, bucket)
+		}
+	}
+	u.mu.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
+	usage := make([]*domain.Usage, 0, len(pending))
+	for _, counted := range pending {
+		usage = append(usage, counted)
+	}
+	if err := u.repo.AddUsage(ctx, usage); err != nil {
+		logger.FromContext(ctx).Error("failed to write deprecation usage: %v", err)
+		u.mu.Lock()
+		for bucket, counted := range pending {
+			if current, ok := u.usage[bucket]; ok {
+				current.Requests += counted.Requests
+				if counted.LastUsedAt.After(current.LastUsedAt) {
+					current.LastUsedAt = counted.LastUsedAt
+				}
+				continue
+			}
+			u.usage[bucket] = counted
+		}
+		u.mu.Unlock()
+	}
+}
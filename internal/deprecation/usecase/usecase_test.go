@@ -0,0 +1,128 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/infrastructure/deprecation"
+	"portal-data-backend/internal/deprecation/domain"
+	"portal-data-backend/internal/deprecation/usecase"
+)
+
+// mockRepository is an in-memory implementation of Repository
+type mockRepository struct {
+	usage map[string]*domain.Usage
+	fail  bool
+}
+
+func newMockRepository() *mockRepository {
+	return &mockRepository{usage: map[string]*domain.Usage{}}
+}
+
+func (m *mockRepository) AddUsage(ctx context.Context, usage []*domain.Usage) error {
+	if m.fail {
+		return errors.New("database is down")
+	}
+	for _, counted := range usage {
+		key := counted.NoticeID + "|" + counted.Client + "|" + counted.Day.Format(time.DateOnly)
+		if existing, ok := m.usage[key]; ok {
+			existing.Requests += counted.Requests
+			continue
+		}
+		copied := *counted
+		m.usage[key] = &copied
+	}
+	return nil
+}
+
+func (m *mockRepository) ListUsage(ctx context.Context, from time.Time) ([]*domain.ClientUsage, error) {
+	byClient := map[string]*domain.ClientUsage{}
+	var usage []*domain.ClientUsage
+	for _, counted := range m.usage {
+		if counted.Day.Before(from) {
+			continue
+		}
+		key := counted.NoticeID + "|" + counted.Client
+		clientUsage, ok := byClient[key]
+		if !ok {
+			clientUsage = &domain.ClientUsage{NoticeID: counted.NoticeID, Client: counted.Client, FirstUsedOn: counted.Day}
+			byClient[key] = clientUsage
+			usage = append(usage, clientUsage)
+		}
+		clientUsage.Requests += counted.Requests
+	}
+	return usage, nil
+}
+
+// stop runs u until it writes the usage counted so far
+func stop(u usecase.Usecase) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	u.Run(ctx)
+}
+
+// Test usage is counted per client, written when Run stops and reported for
+// every deprecated surface
+func TestDeprecation_Report(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	registry := &deprecation.Registry{}
+	stats := deprecation.Notice{Method: "GET", Pattern: "/stats", Successor: "/api/v1/statistics"}
+	query := deprecation.Notice{Pattern: "/datasets", Param: "q"}
+	registry.Declare(stats, query)
+	u := usecase.NewDeprecationUsecase(repo, registry, config.DeprecationConfig{UsageFlushInterval: time.Hour})
+
+	u.Record(ctx, stats, "application:app-1")
+	u.Record(ctx, stats, "application:app-1")
+	u.Record(ctx, stats, "ip:192.0.2.1")
+	stop(u)
+
+	report, err := u.Report(ctx, &domain.ReportRequest{})
+	if err != nil {
+		t.Fatalf("Failed to report: %v", err)
+	}
+	if len(report.Notices) != 2 {
+		t.Fatalf("Expected both notices reported, got %d", len(report.Notices))
+	}
+	byID := map[string]domain.NoticeReport{}
+	for _, notice := range report.Notices {
+		byID[notice.ID] = notice
+	}
+	if got := byID[stats.ID()]; got.Requests != 3 || len(got.Clients) != 2 {
+		t.Errorf("Expected 3 requests by 2 clients of %s, got %d by %d", stats.ID(), got.Requests, len(got.Clients))
+	}
+	if got := byID[query.ID()]; got.Requests != 0 || got.Clients == nil {
+		t.Errorf("Expected %s reported unused with no clients, got %+v", query.ID(), got)
+	}
+}
+
+// Test usage failing to be written is kept for the next flush
+func TestDeprecation_FlushRetries(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	registry := &deprecation.Registry{}
+	notice := deprecation.Notice{Pattern: "/stats"}
+	registry.Declare(notice)
+	u := usecase.NewDeprecationUsecase(repo, registry, config.DeprecationConfig{UsageFlushInterval: time.Hour})
+
+	repo.fail = true
+	u.Record(ctx, notice, "user:user-1")
+	stop(u)
+	if len(repo.usage) != 0 {
+		t.Fatalf("Expected nothing written while the database is down")
+	}
+
+	repo.fail = false
+	u.Record(ctx, notice, "user:user-1")
+	stop(u)
+	report, err := u.Report(ctx, &domain.ReportRequest{Days: 1})
+	if err != nil {
+		t.Fatalf("Failed to report: %v", err)
+	}
+	if got := report.Notices[0].Requests; got != 2 {
+		t.Errorf("Expected both requests written, got %d", got)
+	}
+}
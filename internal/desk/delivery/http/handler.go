@@ -1,42 +1,43 @@
 package http
 
 import (
-	"encoding/json"
-	"errors"
+	"net"
 	"net/http"
 	"strconv"
 
+	"portal-data-backend/infrastructure/auth"
 	deskDomain "portal-data-backend/internal/desk/domain"
 	"portal-data-backend/internal/desk/usecase"
+	"portal-data-backend/infrastructure/http/bulk"
+	"portal-data-backend/infrastructure/http/httputil"
+	"portal-data-backend/infrastructure/http/middleware"
+	"portal-data-backend/infrastructure/http/problem"
 	"portal-data-backend/infrastructure/http/response"
+	roleDomain "portal-data-backend/internal/role/domain"
 	pkgErrors "portal-data-backend/pkg/errors"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/go-playground/validator/v10"
 )
 
 type Handler struct {
 	deskUsecase usecase.Usecase
-	validator   *validator.Validate
 }
 
 func NewHandler(deskUsecase usecase.Usecase) *Handler {
 	return &Handler{
 		deskUsecase: deskUsecase,
-		validator:   validator.New(),
 	}
 }
 
 func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Ticket ID is required", nil)
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
 	ticket, err := h.deskUsecase.GetByID(r.Context(), id)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -69,7 +70,7 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 
 	resp, err := h.deskUsecase.List(r.Context(), req)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -77,22 +78,16 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
-	var req deskDomain.CreateTicketRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, response.CodeBadRequest, "Invalid request body", nil)
+	req, ok := httputil.Decode[deskDomain.CreateTicketRequest](w, r)
+	if !ok {
 		return
 	}
 
-	if err := h.validator.Struct(&req); err != nil {
-		response.ValidationError(w, response.CodeValidationFailed, "Validation failed", h.formatValidationErrors(err))
-		return
-	}
-
-	userID, _ := r.Context().Value("user_id").(string)
+	userID := auth.UserID(r.Context())
 
-	ticket, err := h.deskUsecase.Create(r.Context(), &req, userID)
+	ticket, err := h.deskUsecase.Create(r.Context(), req, userID)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -100,26 +95,19 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Ticket ID is required", nil)
-		return
-	}
-
-	var req deskDomain.UpdateTicketRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, response.CodeBadRequest, "Invalid request body", nil)
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
-	if err := h.validator.Struct(&req); err != nil {
-		response.ValidationError(w, response.CodeValidationFailed, "Validation failed", h.formatValidationErrors(err))
+	req, ok := httputil.Decode[deskDomain.UpdateTicketRequest](w, r)
+	if !ok {
 		return
 	}
 
-	ticket, err := h.deskUsecase.Update(r.Context(), id, &req)
+	ticket, err := h.deskUsecase.Update(r.Context(), id, req)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -127,42 +115,49 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Ticket ID is required", nil)
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
 	if err := h.deskUsecase.Delete(r.Context(), id); err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
 	response.OK(w, response.CodeSuccess, "Ticket deleted successfully", nil)
 }
 
-func (h *Handler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Ticket ID is required", nil)
+// Restore handles restoring a deleted ticket
+func (h *Handler) Restore(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
-	var req struct {
-		Status string `json:"status" validate:"required"`
+	if err := h.deskUsecase.Restore(r.Context(), id); err != nil {
+		h.handleError(w, r, err)
+		return
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, response.CodeBadRequest, "Invalid request body", nil)
+
+	response.OK(w, response.CodeSuccess, "Ticket restored successfully", nil)
+}
+
+func (h *Handler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
-	if err := h.validator.Struct(&req); err != nil {
-		response.ValidationError(w, response.CodeValidationFailed, "Validation failed", h.formatValidationErrors(err))
+	var req struct {
+		Status string `json:"status" validate:"required"`
+	}
+	if !httputil.DecodeInto(w, r, &req) {
 		return
 	}
 
 	if err := h.deskUsecase.UpdateStatus(r.Context(), id, req.Status); err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -170,68 +165,146 @@ func (h *Handler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) AssignTicket(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Ticket ID is required", nil)
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
 	var req struct {
 		AssignedTo string `json:"assigned_to" validate:"required"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, response.CodeBadRequest, "Invalid request body", nil)
+	if !httputil.DecodeInto(w, r, &req) {
 		return
 	}
 
-	if err := h.validator.Struct(&req); err != nil {
-		response.ValidationError(w, response.CodeValidationFailed, "Validation failed", h.formatValidationErrors(err))
+	if err := h.deskUsecase.AssignTicket(r.Context(), id, req.AssignedTo); err != nil {
+		h.handleError(w, r, err)
 		return
 	}
 
-	if err := h.deskUsecase.AssignTicket(r.Context(), id, req.AssignedTo); err != nil {
-		h.handleError(w, err)
+	response.OK(w, response.CodeSuccess, "Ticket assigned successfully", nil)
+}
+
+// bulkAssignRequest assigns several tickets to one user
+type bulkAssignRequest struct {
+	bulk.Request
+	AssignedTo string `json:"assigned_to" validate:"required"`
+}
+
+// BulkAssign assigns several tickets in one transaction, answering the
+// outcome for each
+func (h *Handler) BulkAssign(w http.ResponseWriter, r *http.Request) {
+	req, ok := httputil.Decode[bulkAssignRequest](w, r)
+	if !ok {
 		return
 	}
 
-	response.OK(w, response.CodeSuccess, "Ticket assigned successfully", nil)
+	failed, err := h.deskUsecase.BulkAssign(r.Context(), req.IDs, req.AssignedTo)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	bulk.Write(w, r, errorMapper, req.IDs, failed, "tickets assigned")
 }
 
-func (h *Handler) handleError(w http.ResponseWriter, err error) {
-	if err == nil {
+// Reply adds a reply of staff to a ticket, public or internal
+func (h *Handler) Reply(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
-	switch {
-	case errors.Is(err, pkgErrors.ErrNotFound):
-		response.NotFound(w, response.CodeNotFound, "Ticket not found", nil)
-	default:
-		response.InternalError(w, response.CodeInternalServerError, "Internal server error", nil)
+	req, ok := httputil.Decode[deskDomain.CreateTicketReplyRequest](w, r)
+	if !ok {
+		return
 	}
+
+	userID := auth.UserID(r.Context())
+
+	reply, err := h.deskUsecase.Reply(r.Context(), id, req, userID)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.Created(w, response.CodeCreated, "Ticket reply created successfully", reply)
 }
 
-func (h *Handler) formatValidationErrors(err error) []response.ErrorDetail {
-	var details []response.ErrorDetail
-	if validationErrors, ok := err.(validator.ValidationErrors); ok {
-		for _, fieldErr := range validationErrors {
-			details = append(details, response.ErrorDetail{
-				Field:   fieldErr.Field(),
-				Message: h.getValidationErrorMessage(fieldErr),
-			})
-		}
+// ListReplies lists every reply to a ticket, oldest first
+func (h *Handler) ListReplies(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	resp, err := h.deskUsecase.ListReplies(r.Context(), id)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Ticket replies retrieved successfully", resp)
+}
+
+// CreatePublic files a ticket from the contact form of a requester who is
+// not signed in. The requester is mailed the link to follow it.
+func (h *Handler) CreatePublic(w http.ResponseWriter, r *http.Request) {
+	req, ok := httputil.Decode[deskDomain.CreatePublicTicketRequest](w, r)
+	if !ok {
+		return
+	}
+
+	remoteIP, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remoteIP = r.RemoteAddr
+	}
+
+	if err := h.deskUsecase.CreatePublic(r.Context(), req, remoteIP); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.JSON(w, http.StatusAccepted, response.CodeSuccess, "Request received, follow it through the link sent to your email", nil)
+}
+
+// GetPublic shows the ticket of the access token in the path to its
+// requester. The token is a secret, so the response is never stored.
+func (h *Handler) GetPublic(w http.ResponseWriter, r *http.Request) {
+	ticket, err := h.deskUsecase.GetPublic(r.Context(), chi.URLParam(r, "token"))
+	if err != nil {
+		h.handleError(w, r, err)
+		return
 	}
-	return details
+
+	w.Header().Set("Cache-Control", "no-store")
+	response.OK(w, response.CodeSuccess, "Ticket retrieved successfully", ticket)
 }
 
-func (h *Handler) getValidationErrorMessage(fieldErr validator.FieldError) string {
-	switch fieldErr.Tag() {
-	case "required":
-		return fieldErr.Field() + " is required"
-	case "min":
-		return fieldErr.Field() + " must be at least " + fieldErr.Param() + " characters"
-	default:
-		return fieldErr.Field() + " is invalid"
+// ReplyPublic adds a reply of the requester holding the access token in the
+// path. It is shown once moderation approves it.
+func (h *Handler) ReplyPublic(w http.ResponseWriter, r *http.Request) {
+	req, ok := httputil.Decode[deskDomain.CreatePublicReplyRequest](w, r)
+	if !ok {
+		return
+	}
+
+	reply, err := h.deskUsecase.ReplyPublic(r.Context(), chi.URLParam(r, "token"), req)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
 	}
+
+	response.Created(w, response.CodeCreated, "Ticket reply created successfully", reply)
+}
+
+// errorMapper maps the errors of the desk module on top of the shared ones
+var errorMapper = problem.Default.With(
+	problem.Mapping{Err: pkgErrors.ErrNotFound, Status: http.StatusNotFound, Code: response.CodeNotFound, Message: "Ticket not found"},
+)
+
+func (h *Handler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	errorMapper.Write(w, r, err)
 }
 
 func parseIntQuery(r *http.Request, key string, defaultValue int) int {
@@ -243,14 +316,33 @@ func parseIntQuery(r *http.Request, key string, defaultValue int) int {
 	return defaultValue
 }
 
-func RegisterRoutes(r chi.Router, handler *Handler) {
+// RegisterRoutes registers ticket routes, which go through auth except the
+// public ones requesters follow their tickets through. Filing tickets and
+// replying through the public link go through publicLimit, which should rate
+// limit them. Signed-in users file and read tickets; changing, assigning and
+// deleting them and their replies, internal notes among them, take
+// tickets:write. Restoring and bulk assigning are left to users with one of
+// adminRoles.
+func RegisterRoutes(r chi.Router, handler *Handler, auth, publicLimit func(http.Handler) http.Handler, adminRoles []string) {
+	r.Route("/public/tickets", func(r chi.Router) {
+		r.With(publicLimit).Post("/", handler.CreatePublic)
+		r.Get("/{token}", handler.GetPublic)
+		r.With(publicLimit).Post("/{token}/replies", handler.ReplyPublic)
+	})
+
 	r.Route("/tickets", func(r chi.Router) {
+		r.Use(auth)
+		write := middleware.RequirePermission(roleDomain.PermissionTicketsWrite)
 		r.Get("/", handler.List)
 		r.Post("/", handler.Create)
 		r.Get("/{id}", handler.GetByID)
-		r.Put("/{id}", handler.Update)
-		r.Delete("/{id}", handler.Delete)
-		r.Patch("/{id}/status", handler.UpdateStatus)
-		r.Patch("/{id}/assign", handler.AssignTicket)
+		r.With(write).Put("/{id}", handler.Update)
+		r.With(write).Delete("/{id}", handler.Delete)
+		r.With(middleware.RequireRole(adminRoles...)).Post("/{id}/restore", handler.Restore)
+		r.With(write).Patch("/{id}/status", handler.UpdateStatus)
+		r.With(write).Patch("/{id}/assign", handler.AssignTicket)
+		r.With(write).Get("/{id}/replies", handler.ListReplies)
+		r.With(write).Post("/{id}/replies", handler.Reply)
+		r.With(middleware.RequireRole(adminRoles...)).Patch("/bulk-assign", handler.BulkAssign)
 	})
 }
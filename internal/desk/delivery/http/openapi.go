@@ -0,0 +1,34 @@
+package http
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/bulk"
+	"portal-data-backend/infrastructure/http/openapi"
+	deskDomain "portal-data-backend/internal/desk/domain"
+)
+
+// Describe adds the ticket routes to the API specification
+func Describe(spec *openapi.Spec) {
+	api := spec.Tag("tickets", "Help desk tickets")
+	api.Get("/tickets", "List tickets").Query(deskDomain.ListTicketsRequest{}).Param("include_deleted", false).Returns(http.StatusOK, deskDomain.TicketListResponse{})
+	api.Post("/tickets", "Create ticket").Body(deskDomain.CreateTicketRequest{}).Returns(http.StatusCreated, deskDomain.TicketInfo{})
+	api.Get("/tickets/{id}", "Get ticket").Param("include_deleted", false).Returns(http.StatusOK, deskDomain.TicketInfo{})
+	api.Put("/tickets/{id}", "Update ticket").Body(deskDomain.UpdateTicketRequest{}).Returns(http.StatusOK, deskDomain.TicketInfo{})
+	api.Delete("/tickets/{id}", "Delete ticket").Returns(http.StatusOK, nil)
+	api.Post("/tickets/{id}/restore", "Restore deleted ticket").Returns(http.StatusOK, nil)
+	api.Patch("/tickets/{id}/status", "Update ticket status").Body(struct {
+		Status string `json:"status" validate:"required"`
+	}{}).Returns(http.StatusOK, nil)
+	api.Patch("/tickets/{id}/assign", "Assign ticket").Body(struct {
+		AssignedTo string `json:"assigned_to" validate:"required"`
+	}{}).Returns(http.StatusOK, nil)
+	api.Patch("/tickets/bulk-assign", "Assign several tickets").Body(bulkAssignRequest{}).Returns(http.StatusOK, bulk.Response{})
+	api.Get("/tickets/{id}/replies", "List ticket replies").Returns(http.StatusOK, deskDomain.TicketRepliesResponse{})
+	api.Post("/tickets/{id}/replies", "Reply to ticket").Body(deskDomain.CreateTicketReplyRequest{}).Returns(http.StatusCreated, deskDomain.TicketReply{})
+
+	api.Post("/public/tickets", "File ticket from the contact form").Public().Body(deskDomain.CreatePublicTicketRequest{}).Returns(http.StatusAccepted, nil)
+	api.Get("/public/tickets/{token}", "Follow ticket through its access token").Public().Returns(http.StatusOK, deskDomain.PublicTicketInfo{})
+	api.Post("/public/tickets/{token}/replies", "Reply to ticket through its access token").Public().
+		Body(deskDomain.CreatePublicReplyRequest{}).Returns(http.StatusCreated, deskDomain.PublicReplyInfo{})
+}
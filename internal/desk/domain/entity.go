@@ -2,23 +2,56 @@ package domain
 
 import "time"
 
-// Ticket represents a helpdesk ticket
+// Ticket represents a helpdesk ticket. Tickets filed through the contact
+// form have no user but the email of their requester, who follows them
+// through the link of an access token.
 type Ticket struct {
-	ID          string     `db:"id" json:"id"`
-	Title       string     `db:"title" json:"title"`
-	Description string     `db:"description" json:"description"`
-	Status      string     `db:"status" json:"status"`
-	Priority    string     `db:"priority" json:"priority"`
-	Category    string     `db:"category" json:"category"`
-	UserID      string     `db:"user_id" json:"user_id"`
-	AssignedTo  *string    `db:"assigned_to" json:"assigned_to,omitempty"`
-	ResolvedAt  *time.Time `db:"resolved_at" json:"resolved_at,omitempty"`
-	CreatedBy   string     `db:"created_by" json:"created_by"`
-	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
-	UpdatedAt   time.Time  `db:"updated_at" json:"updated_at"`
-	DeletedAt   *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
+	ID              string     `db:"id" json:"id"`
+	Title           string     `db:"title" json:"title"`
+	Description     string     `db:"description" json:"description"`
+	Status          string     `db:"status" json:"status"`
+	Priority        string     `db:"priority" json:"priority"`
+	Category        string     `db:"category" json:"category"`
+	UserID          *string    `db:"user_id" json:"user_id,omitempty"`
+	RequesterEmail  *string    `db:"requester_email" json:"requester_email,omitempty"`
+	AccessTokenHash *string    `db:"access_token_hash" json:"-"` // SHA-256 of the token of the public link
+	AssignedTo      *string    `db:"assigned_to" json:"assigned_to,omitempty"`
+	ResolvedAt      *time.Time `db:"resolved_at" json:"resolved_at,omitempty"`
+	SLABreachedAt   *time.Time `db:"sla_breached_at" json:"sla_breached_at,omitempty"` // set once when the SLA breach is reported
+	CreatedBy       *string    `db:"created_by" json:"created_by,omitempty"`
+	CreatedAt       time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt       time.Time  `db:"updated_at" json:"updated_at"`
+	DeletedAt       *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
 }
 
+// ContentTypeTicketReply is the content type the replies of requesters are
+// screened as
+const ContentTypeTicketReply = "ticket_reply"
+
+// TicketReply is a reply to a ticket by staff, with UserID, or by its
+// requester through the public link, with AuthorEmail. Requesters only see
+// public replies, and none that moderation rejected.
+type TicketReply struct {
+	ID               string           `db:"id" json:"id"`
+	TicketID         string           `db:"ticket_id" json:"ticket_id"`
+	UserID           *string          `db:"user_id" json:"user_id,omitempty"`
+	AuthorEmail      *string          `db:"author_email" json:"author_email,omitempty"`
+	Message          string           `db:"message" json:"message"`
+	IsPublic         bool             `db:"is_public" json:"is_public"`
+	ModerationStatus ModerationStatus `db:"moderation_status" json:"moderation_status"`
+	CreatedAt        time.Time        `db:"created_at" json:"created_at"`
+}
+
+// ModerationStatus represents how far a reply of a requester is through
+// moderation; replies of staff are approved
+type ModerationStatus string
+
+const (
+	ModerationStatusPending  ModerationStatus = "pending"
+	ModerationStatusApproved ModerationStatus = "approved"
+	ModerationStatusRejected ModerationStatus = "rejected"
+)
+
 // TicketStatus represents ticket status
 type TicketStatus string
 
@@ -70,6 +103,17 @@ type CreateTicketRequest struct {
 	AssignedTo  *string `json:"assigned_to,omitempty"`
 }
 
+// CreatePublicTicketRequest represents a ticket filed through the contact
+// form by a requester who is not signed in. Website is a honeypot that
+// people leave empty.
+type CreatePublicTicketRequest struct {
+	Email       string `json:"email" validate:"required,email,max=254"`
+	Title       string `json:"title" validate:"required,min=2,max=200"`
+	Description string `json:"description" validate:"required,max=5000"`
+	Category    string `json:"category,omitempty" validate:"omitempty,oneof=technical data_request report other"`
+	Website     string `json:"website,omitempty"`
+}
+
 // UpdateTicketRequest represents update ticket input
 type UpdateTicketRequest struct {
 	Title       *string `json:"title" validate:"omitempty,min=2,max=200"`
@@ -82,18 +126,64 @@ type UpdateTicketRequest struct {
 
 // TicketInfo represents ticket information for API responses
 type TicketInfo struct {
-	ID          string     `json:"id"`
-	Title       string     `json:"title"`
-	Description string     `json:"description"`
-	Status      string     `json:"status"`
-	Priority    string     `json:"priority"`
-	Category    string     `json:"category"`
-	UserID      string     `json:"user_id"`
-	AssignedTo  *string    `json:"assigned_to,omitempty"`
-	ResolvedAt  *time.Time `json:"resolved_at,omitempty"`
-	CreatedBy   string     `json:"created_by"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
+	ID             string     `json:"id"`
+	Title          string     `json:"title"`
+	Description    string     `json:"description"`
+	Status         string     `json:"status"`
+	Priority       string     `json:"priority"`
+	Category       string     `json:"category"`
+	UserID         *string    `json:"user_id,omitempty"`
+	RequesterEmail *string    `json:"requester_email,omitempty"`
+	AssignedTo     *string    `json:"assigned_to,omitempty"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+	SLABreachedAt  *time.Time `json:"sla_breached_at,omitempty"`
+	CreatedBy      *string    `json:"created_by,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	// DeletedAt is set on deleted tickets, which only admins list
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// CreateTicketReplyRequest represents a reply of staff to a ticket. Public
+// replies are shown to the requester, others are internal notes.
+type CreateTicketReplyRequest struct {
+	Message  string `json:"message" validate:"required,max=5000"`
+	IsPublic bool   `json:"is_public"`
+}
+
+// CreatePublicReplyRequest represents a reply of the requester through the
+// public link
+type CreatePublicReplyRequest struct {
+	Message string `json:"message" validate:"required,min=2,max=5000"`
+}
+
+// TicketRepliesResponse represents the replies to a ticket, oldest first
+type TicketRepliesResponse struct {
+	Replies []TicketReply `json:"replies"`
+}
+
+// PublicTicketInfo represents a ticket as its requester sees it through the
+// public link: its status and the public replies, without staff identities
+type PublicTicketInfo struct {
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	Status      string            `json:"status"`
+	Category    string            `json:"category"`
+	ResolvedAt  *time.Time        `json:"resolved_at,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+	Replies     []PublicReplyInfo `json:"replies"`
+}
+
+// PublicReplyInfo represents a reply shown to the requester. FromRequester
+// tells their own replies from those of staff; ModerationStatus is pending
+// on a reply of theirs still waiting for a moderator.
+type PublicReplyInfo struct {
+	ID               string    `json:"id"`
+	Message          string    `json:"message"`
+	FromRequester    bool      `json:"from_requester"`
+	ModerationStatus string    `json:"moderation_status,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
 }
 
 // TicketListResponse represents paginated ticket list
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"time"
 )
 
 type Repository interface {
@@ -10,8 +11,24 @@ type Repository interface {
 	Create(ctx context.Context, ticket *Ticket) error
 	Update(ctx context.Context, id string, ticket *Ticket) error
 	Delete(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) error
 	UpdateStatus(ctx context.Context, id string, status string) error
 	AssignTicket(ctx context.Context, id string, assignedTo string) error
+	// ListOverdue returns open or in progress tickets of a priority created
+	// before createdBefore whose SLA breach has not been reported yet
+	ListOverdue(ctx context.Context, priority string, createdBefore time.Time, limit int) ([]*Ticket, error)
+	// MarkSLABreached records the breach and reports false if it was already recorded
+	MarkSLABreached(ctx context.Context, id string, at time.Time) (bool, error)
+	// GetByAccessToken returns the ticket whose access token hashes to
+	// tokenHash, failing with errors.ErrNotFound when there is none
+	GetByAccessToken(ctx context.Context, tokenHash string) (*Ticket, error)
+
+	CreateReply(ctx context.Context, reply *TicketReply) error
+	// ListReplies returns the replies to a ticket oldest first: the public
+	// ones approved or pending moderation when public is set, else those of
+	// staff and the approved ones of the requester
+	ListReplies(ctx context.Context, ticketID string, public bool) ([]*TicketReply, error)
+	UpdateReplyModeration(ctx context.Context, id string, status ModerationStatus) error
 }
 
 type TicketFilter struct {
@@ -22,3 +39,14 @@ type TicketFilter struct {
 	Category   *string
 	Search     string
 }
+
+// EventPublisher emits ticket events to interested integrations and sinks
+type EventPublisher interface {
+	Publish(ctx context.Context, eventType string, data interface{}) error
+}
+
+// Ticket event types
+const (
+	EventTicketCreated     = "ticket.created"
+	EventTicketSLABreached = "ticket.sla_breached"
+)
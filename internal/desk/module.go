@@ -0,0 +1,75 @@
+// Package desk is the help desk module tracking support tickets.
+package desk
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"portal-data-backend/infrastructure/db"
+	"portal-data-backend/infrastructure/http/middleware"
+	"portal-data-backend/infrastructure/http/openapi"
+	"portal-data-backend/infrastructure/mail"
+	"portal-data-backend/internal/app"
+	delivery "portal-data-backend/internal/desk/delivery/http"
+	"portal-data-backend/internal/desk/domain"
+	"portal-data-backend/internal/desk/repository"
+	"portal-data-backend/internal/desk/usecase"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jmoiron/sqlx"
+)
+
+// Module tracks support tickets and checks their SLAs in the background
+type Module struct {
+	usecase     usecase.Usecase
+	handler     *delivery.Handler
+	db          *sqlx.DB
+	adminRoles  []string
+	publicLimit *middleware.RateLimiter
+}
+
+// Name implements app.Module
+func (m *Module) Name() string {
+	return "desk"
+}
+
+// Register implements app.Module
+func (m *Module) Register(deps *app.Deps) error {
+	if deps.Services.Templates == nil {
+		return app.MissingServiceError("message template")
+	}
+	deps.Services.Templates.Define(usecase.Messages...)
+
+	cfg := deps.Config.Desk
+	m.db = deps.DB
+	repo := repository.NewDeskPostgresRepository(deps.DB)
+	m.usecase = usecase.NewDeskUsecase(repo, deps.Tx, deps.Outbox, deps.Services.Moderation, mail.NewSender(deps.Config.Mail), deps.Services.Templates, cfg)
+	m.handler = delivery.NewHandler(m.usecase)
+	m.adminRoles = deps.Config.Audit.AdminRoles
+	m.publicLimit = middleware.NewRateLimiter(cfg.PublicRateLimit, cfg.PublicRateWindow)
+	if deps.Services.Moderation != nil {
+		deps.Services.Moderation.Handle(domain.ContentTypeTicketReply, m.usecase.ApplyModeration)
+	}
+	return nil
+}
+
+// Routes implements app.Module
+func (m *Module) Routes(r chi.Router, auth func(http.Handler) http.Handler) {
+	delivery.RegisterRoutes(r, m.handler, auth, m.publicLimit.Handler, m.adminRoles)
+}
+
+// Describe implements app.Module
+func (m *Module) Describe(spec *openapi.Spec) {
+	delivery.Describe(spec)
+}
+
+// Purge implements app.Purger
+func (m *Module) Purge(ctx context.Context, before time.Time) (int64, error) {
+	return db.PurgeDeleted(ctx, m.db, "tickets", before)
+}
+
+// Run implements app.Runner
+func (m *Module) Run(ctx context.Context) {
+	m.usecase.Run(ctx)
+}
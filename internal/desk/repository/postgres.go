@@ -8,6 +8,9 @@ import (
 
 	deskDomain "portal-data-backend/internal/desk/domain"
 
+	"portal-data-backend/infrastructure/db"
+	"portal-data-backend/pkg/errors"
+
 	"github.com/jmoiron/sqlx"
 )
 
@@ -21,14 +24,13 @@ func NewDeskPostgresRepository(db *sqlx.DB) deskDomain.Repository {
 
 func (r *deskPostgresRepository) GetByID(ctx context.Context, id string) (*deskDomain.Ticket, error) {
 	query := `
-		SELECT id, title, description, status, priority, category, user_id, assigned_to,
-		       resolved_at, created_by, created_at, updated_at, deleted_at
+		SELECT id, title, description, status, priority, category, user_id, requester_email, assigned_to,
+		       resolved_at, sla_breached_at, created_by, created_at, updated_at, deleted_at
 		FROM tickets
-		WHERE id = $1 AND deleted_at IS NULL
-	`
+		WHERE id = $1 AND ` + db.NotDeleted(ctx, "deleted_at")
 
 	var ticket deskDomain.Ticket
-	err := r.db.GetContext(ctx, &ticket, query, id)
+	err := db.Conn(ctx, r.db).GetContext(ctx, &ticket, query, id)
 	if err != nil {
 		return nil, r.handleError(err)
 	}
@@ -36,7 +38,7 @@ func (r *deskPostgresRepository) GetByID(ctx context.Context, id string) (*deskD
 }
 
 func (r *deskPostgresRepository) List(ctx context.Context, filter *deskDomain.TicketFilter, limit, offset int) ([]*deskDomain.Ticket, int, error) {
-	whereClause := "WHERE deleted_at IS NULL"
+	whereClause := "WHERE " + db.NotDeleted(ctx, "deleted_at")
 	args := []interface{}{}
 	argCount := 1
 
@@ -76,21 +78,21 @@ func (r *deskPostgresRepository) List(ctx context.Context, filter *deskDomain.Ti
 
 	countQuery := "SELECT COUNT(*) FROM tickets " + whereClause
 	var total int
-	err := r.db.GetContext(ctx, &total, countQuery, args...)
+	err := db.Conn(ctx, r.db).GetContext(ctx, &total, countQuery, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count tickets: %w", err)
 	}
 
 	query := `
-		SELECT id, title, description, status, priority, category, user_id, assigned_to,
-		       resolved_at, created_by, created_at, updated_at, deleted_at
+		SELECT id, title, description, status, priority, category, user_id, requester_email, assigned_to,
+		       resolved_at, sla_breached_at, created_by, created_at, updated_at, deleted_at
 		FROM tickets
 	` + whereClause + " ORDER BY created_at DESC LIMIT $" + fmt.Sprintf("%d", argCount) + " OFFSET $" + fmt.Sprintf("%d", argCount+1)
 
 	args = append(args, limit, offset)
 
 	var tickets []*deskDomain.Ticket
-	err = r.db.SelectContext(ctx, &tickets, query, args...)
+	err = db.Conn(ctx, r.db).SelectContext(ctx, &tickets, query, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to list tickets: %w", err)
 	}
@@ -100,13 +102,13 @@ func (r *deskPostgresRepository) List(ctx context.Context, filter *deskDomain.Ti
 
 func (r *deskPostgresRepository) Create(ctx context.Context, ticket *deskDomain.Ticket) error {
 	query := `
-		INSERT INTO tickets (id, title, description, status, priority, category, user_id, assigned_to,
-		                    created_by, created_at, updated_at)
-		VALUES (:id, :title, :description, :status, :priority, :category, :user_id, :assigned_to,
-		        :created_by, :created_at, :updated_at)
+		INSERT INTO tickets (id, title, description, status, priority, category, user_id, requester_email,
+		                    access_token_hash, assigned_to, created_by, created_at, updated_at)
+		VALUES (:id, :title, :description, :status, :priority, :category, :user_id, :requester_email,
+		        :access_token_hash, :assigned_to, :created_by, :created_at, :updated_at)
 	`
 
-	_, err := r.db.NamedExecContext(ctx, query, ticket)
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, ticket)
 	if err != nil {
 		return fmt.Errorf("failed to create ticket: %w", err)
 	}
@@ -122,7 +124,7 @@ func (r *deskPostgresRepository) Update(ctx context.Context, id string, ticket *
 	`
 
 	ticket.ID = id
-	_, err := r.db.NamedExecContext(ctx, query, ticket)
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, ticket)
 	if err != nil {
 		return fmt.Errorf("failed to update ticket: %w", err)
 	}
@@ -130,17 +132,16 @@ func (r *deskPostgresRepository) Update(ctx context.Context, id string, ticket *
 }
 
 func (r *deskPostgresRepository) Delete(ctx context.Context, id string) error {
-	query := `UPDATE tickets SET deleted_at = $1 WHERE id = $2`
-	_, err := r.db.ExecContext(ctx, query, time.Now(), id)
-	if err != nil {
-		return fmt.Errorf("failed to delete ticket: %w", err)
-	}
-	return nil
+	return db.SoftDelete(ctx, db.Conn(ctx, r.db), "tickets", id)
+}
+
+func (r *deskPostgresRepository) Restore(ctx context.Context, id string) error {
+	return db.Restore(ctx, db.Conn(ctx, r.db), "tickets", id)
 }
 
 func (r *deskPostgresRepository) UpdateStatus(ctx context.Context, id string, status string) error {
 	query := `UPDATE tickets SET status = $1, updated_at = $2 WHERE id = $3`
-	_, err := r.db.ExecContext(ctx, query, status, time.Now(), id)
+	_, err := db.Conn(ctx, r.db).ExecContext(ctx, query, status, time.Now(), id)
 	if err != nil {
 		return fmt.Errorf("failed to update ticket status: %w", err)
 	}
@@ -149,19 +150,118 @@ func (r *deskPostgresRepository) UpdateStatus(ctx context.Context, id string, st
 
 func (r *deskPostgresRepository) AssignTicket(ctx context.Context, id string, assignedTo string) error {
 	query := `UPDATE tickets SET assigned_to = $1, updated_at = $2 WHERE id = $3`
-	_, err := r.db.ExecContext(ctx, query, assignedTo, time.Now(), id)
+	_, err := db.Conn(ctx, r.db).ExecContext(ctx, query, assignedTo, time.Now(), id)
 	if err != nil {
 		return fmt.Errorf("failed to assign ticket: %w", err)
 	}
 	return nil
 }
 
+func (r *deskPostgresRepository) ListOverdue(ctx context.Context, priority string, createdBefore time.Time, limit int) ([]*deskDomain.Ticket, error) {
+	query := `
+		SELECT id, title, description, status, priority, category, user_id, requester_email, assigned_to,
+		       resolved_at, sla_breached_at, created_by, created_at, updated_at, deleted_at
+		FROM tickets
+		WHERE deleted_at IS NULL AND sla_breached_at IS NULL
+		  AND status IN ($1, $2) AND priority = $3 AND created_at < $4
+		ORDER BY created_at ASC
+		LIMIT $5
+	`
+
+	var tickets []*deskDomain.Ticket
+	err := db.Conn(ctx, r.db).SelectContext(ctx, &tickets, query,
+		deskDomain.TicketStatusOpen,
+		deskDomain.TicketStatusInProgress,
+		priority,
+		createdBefore,
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list overdue tickets: %w", err)
+	}
+	return tickets, nil
+}
+
+func (r *deskPostgresRepository) MarkSLABreached(ctx context.Context, id string, at time.Time) (bool, error) {
+	query := `UPDATE tickets SET sla_breached_at = $1 WHERE id = $2 AND sla_breached_at IS NULL`
+	result, err := db.Conn(ctx, r.db).ExecContext(ctx, query, at, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark ticket sla breached: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+func (r *deskPostgresRepository) GetByAccessToken(ctx context.Context, tokenHash string) (*deskDomain.Ticket, error) {
+	query := `
+		SELECT id, title, description, status, priority, category, user_id, requester_email, assigned_to,
+		       resolved_at, sla_breached_at, created_by, created_at, updated_at, deleted_at
+		FROM tickets
+		WHERE access_token_hash = $1 AND deleted_at IS NULL`
+
+	var ticket deskDomain.Ticket
+	err := db.Conn(ctx, r.db).GetContext(ctx, &ticket, query, tokenHash)
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+	return &ticket, nil
+}
+
+func (r *deskPostgresRepository) CreateReply(ctx context.Context, reply *deskDomain.TicketReply) error {
+	query := `
+		INSERT INTO ticket_replies (id, ticket_id, user_id, author_email, message, is_public, moderation_status, created_at)
+		VALUES (:id, :ticket_id, :user_id, :author_email, :message, :is_public, :moderation_status, :created_at)
+	`
+
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, reply)
+	if err != nil {
+		return fmt.Errorf("failed to create ticket reply: %w", err)
+	}
+	return nil
+}
+
+func (r *deskPostgresRepository) ListReplies(ctx context.Context, ticketID string, public bool) ([]*deskDomain.TicketReply, error) {
+	query := `
+		SELECT id, ticket_id, user_id, author_email, message, is_public, moderation_status, created_at
+		FROM ticket_replies
+		WHERE ticket_id = $1`
+	args := []interface{}{ticketID}
+	if public {
+		query += ` AND is_public AND moderation_status IN ($2, $3)`
+		args = append(args, deskDomain.ModerationStatusApproved, deskDomain.ModerationStatusPending)
+	} else {
+		query += ` AND (user_id IS NOT NULL OR moderation_status = $2)`
+		args = append(args, deskDomain.ModerationStatusApproved)
+	}
+	query += ` ORDER BY created_at ASC`
+
+	replies := []*deskDomain.TicketReply{}
+	err := db.Conn(ctx, r.db).SelectContext(ctx, &replies, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ticket replies: %w", err)
+	}
+	return replies, nil
+}
+
+func (r *deskPostgresRepository) UpdateReplyModeration(ctx context.Context, id string, status deskDomain.ModerationStatus) error {
+	query := `UPDATE ticket_replies SET moderation_status = $1 WHERE id = $2`
+	result, err := db.Conn(ctx, r.db).ExecContext(ctx, query, status, id)
+	if err != nil {
+		return fmt.Errorf("failed to moderate ticket reply: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("ticket reply not found: %w", errors.ErrNotFound)
+	}
+	return nil
+}
+
 func (r *deskPostgresRepository) handleError(err error) error {
 	if err == nil {
 		return nil
 	}
 	if err == sql.ErrNoRows {
-		return fmt.Errorf("ticket not found")
+		return fmt.Errorf("ticket not found: %w", errors.ErrNotFound)
 	}
 	return fmt.Errorf("database error: %w", err)
 }
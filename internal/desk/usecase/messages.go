@@ -0,0 +1,33 @@
+package usecase
+
+import templateDomain "portal-data-backend/internal/message_template/domain"
+
+// Keys of the messages requesters are sent
+const (
+	MessageTicketLink = "desk.ticket_link"
+	MessageReply      = "desk.reply"
+)
+
+// Messages are the mails requesters who filed a ticket through the contact
+// form are sent, with their built-in wording. The module defines them with
+// the message template registry.
+var Messages = []templateDomain.Definition{
+	{
+		Key:         MessageTicketLink,
+		Channel:     templateDomain.ChannelEmail,
+		Description: "Link to follow a ticket mailed to its requester",
+		Variables:   []string{"title", "link"},
+		Subject:     "We received your request",
+		Body: "Thank you for contacting us about \"{{.title}}\".\n\n" +
+			"You can follow its progress and reply to us at this link:\n{{.link}}\n\n" +
+			"Keep the link to yourself, anyone who has it can read and reply to your request.",
+	},
+	{
+		Key:         MessageReply,
+		Channel:     templateDomain.ChannelEmail,
+		Description: "Reply of staff to the ticket of a requester",
+		Variables:   []string{"title", "message"},
+		Subject:     "New reply to your request",
+		Body:        "{{.message}}\n\nReply through the link you received when you filed \"{{.title}}\".",
+	},
+}
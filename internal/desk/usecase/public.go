@@ -0,0 +1,274 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+
+	"portal-data-backend/infrastructure/logger"
+	"portal-data-backend/internal/desk/domain"
+	templateDomain "portal-data-backend/internal/message_template/domain"
+	moderationDomain "portal-data-backend/internal/moderation/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// Moderator is the part of the moderation module the replies of requesters
+// are screened by
+type Moderator interface {
+	Submit(ctx context.Context, sub *moderationDomain.Submission) (moderationDomain.Status, error)
+}
+
+// MailSender sends mail to the requesters of tickets filed through the
+// contact form
+type MailSender interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// MessageRenderer renders the wording of the mail requesters are sent from
+// the templates of the message template module
+type MessageRenderer interface {
+	Render(ctx context.Context, channel templateDomain.Channel, key string, vars map[string]string) (*templateDomain.Message, error)
+}
+
+func (u *deskUsecase) Reply(ctx context.Context, id string, req *domain.CreateTicketReplyRequest, userID string) (*domain.TicketReply, error) {
+	ticket, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ticket: %w", err)
+	}
+
+	reply := &domain.TicketReply{
+		ID:               uuid.New().String(),
+		TicketID:         ticket.ID,
+		UserID:           &userID,
+		Message:          req.Message,
+		IsPublic:         req.IsPublic,
+		ModerationStatus: domain.ModerationStatusApproved,
+		CreatedAt:        u.now(),
+	}
+	if err := u.repo.CreateReply(ctx, reply); err != nil {
+		return nil, fmt.Errorf("failed to reply to ticket: %w", err)
+	}
+
+	if reply.IsPublic {
+		u.mailRequester(ctx, ticket, reply)
+	}
+	return reply, nil
+}
+
+func (u *deskUsecase) ListReplies(ctx context.Context, id string) (*domain.TicketRepliesResponse, error) {
+	if _, err := u.repo.GetByID(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to get ticket: %w", err)
+	}
+
+	replies, err := u.repo.ListReplies(ctx, id, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ticket replies: %w", err)
+	}
+	resp := &domain.TicketRepliesResponse{Replies: make([]domain.TicketReply, len(replies))}
+	for i, reply := range replies {
+		resp.Replies[i] = *reply
+	}
+	return resp, nil
+}
+
+func (u *deskUsecase) CreatePublic(ctx context.Context, req *domain.CreatePublicTicketRequest, remoteIP string) error {
+	// Only bots fill in the honeypot. They are not told their ticket was dropped.
+	if req.Website != "" {
+		logger.FromContext(ctx).Warn("dropped ticket from %s: honeypot filled in", remoteIP)
+		return nil
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return fmt.Errorf("failed to generate access token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(secret)
+	tokenHash := hashAccessToken(token)
+
+	category := req.Category
+	if category == "" {
+		category = string(domain.TicketCategoryOther)
+	}
+	now := u.now()
+	ticket := &domain.Ticket{
+		ID:              uuid.New().String(),
+		Title:           req.Title,
+		Description:     req.Description,
+		Status:          string(domain.TicketStatusOpen),
+		Priority:        string(domain.TicketPriorityMedium),
+		Category:        category,
+		RequesterEmail:  &req.Email,
+		AccessTokenHash: &tokenHash,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	// The link is the only way to follow the ticket, so it is not filed
+	// unless its requester could be mailed the link
+	return u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.repo.Create(ctx, ticket); err != nil {
+			return fmt.Errorf("failed to create ticket: %w", err)
+		}
+		if err := u.publish(ctx, domain.EventTicketCreated, u.toInfo(ticket)); err != nil {
+			return err
+		}
+
+		msg, err := u.messages.Render(ctx, templateDomain.ChannelEmail, MessageTicketLink, map[string]string{
+			"title": ticket.Title,
+			"link":  u.cfg.PublicURL + token,
+		})
+		if err == nil {
+			err = u.mailer.Send(ctx, req.Email, msg.Subject, msg.Body)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to send ticket link: %w", err)
+		}
+		return nil
+	})
+}
+
+func (u *deskUsecase) GetPublic(ctx context.Context, token string) (*domain.PublicTicketInfo, error) {
+	ticket, err := u.ticketByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	replies, err := u.repo.ListReplies(ctx, ticket.ID, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ticket replies: %w", err)
+	}
+
+	info := &domain.PublicTicketInfo{
+		Title:       ticket.Title,
+		Description: ticket.Description,
+		Status:      ticket.Status,
+		Category:    ticket.Category,
+		ResolvedAt:  ticket.ResolvedAt,
+		CreatedAt:   ticket.CreatedAt,
+		UpdatedAt:   ticket.UpdatedAt,
+		Replies:     make([]domain.PublicReplyInfo, len(replies)),
+	}
+	for i, reply := range replies {
+		info.Replies[i] = *toPublicReply(reply)
+	}
+	return info, nil
+}
+
+func (u *deskUsecase) ReplyPublic(ctx context.Context, token string, req *domain.CreatePublicReplyRequest) (*domain.PublicReplyInfo, error) {
+	ticket, err := u.ticketByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if ticket.Status == string(domain.TicketStatusClosed) {
+		return nil, fmt.Errorf("%w: the ticket is closed", pkgErrors.ErrInvalidInput)
+	}
+
+	reply := &domain.TicketReply{
+		ID:          uuid.New().String(),
+		TicketID:    ticket.ID,
+		AuthorEmail: ticket.RequesterEmail,
+		Message:     req.Message,
+		IsPublic:    true,
+		CreatedAt:   u.now(),
+	}
+
+	// Replies the screening holds are shown to staff once a moderator
+	// approves them
+	err = u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		status, err := u.submit(ctx, reply)
+		if err != nil {
+			return err
+		}
+		reply.ModerationStatus = status
+		if err := u.repo.CreateReply(ctx, reply); err != nil {
+			return fmt.Errorf("failed to reply to ticket: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toPublicReply(reply), nil
+}
+
+func (u *deskUsecase) ApplyModeration(ctx context.Context, id string, status moderationDomain.Status) error {
+	if err := u.repo.UpdateReplyModeration(ctx, id, domain.ModerationStatus(status)); err != nil {
+		return fmt.Errorf("failed to moderate ticket reply: %w", err)
+	}
+	return nil
+}
+
+// ticketByToken returns the ticket of an access token. Unknown tokens are
+// not found like missing tickets, so they tell nothing.
+func (u *deskUsecase) ticketByToken(ctx context.Context, token string) (*domain.Ticket, error) {
+	if token == "" {
+		return nil, fmt.Errorf("failed to get ticket: %w", pkgErrors.ErrNotFound)
+	}
+	ticket, err := u.repo.GetByAccessToken(ctx, hashAccessToken(token))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ticket: %w", err)
+	}
+	return ticket, nil
+}
+
+// submit screens a reply of a requester and returns the moderation status it
+// starts with, approved without moderation
+func (u *deskUsecase) submit(ctx context.Context, reply *domain.TicketReply) (domain.ModerationStatus, error) {
+	if u.moderation == nil {
+		return domain.ModerationStatusApproved, nil
+	}
+
+	status, err := u.moderation.Submit(ctx, &moderationDomain.Submission{
+		ContentType: domain.ContentTypeTicketReply,
+		ContentID:   reply.ID,
+		Text:        reply.Message,
+		AuthorEmail: reply.AuthorEmail,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to screen ticket reply: %w", err)
+	}
+	return domain.ModerationStatus(status), nil
+}
+
+// mailRequester mails a public reply of staff to the requester of a ticket
+// filed through the contact form
+func (u *deskUsecase) mailRequester(ctx context.Context, ticket *domain.Ticket, reply *domain.TicketReply) {
+	if ticket.RequesterEmail == nil {
+		return
+	}
+	msg, err := u.messages.Render(ctx, templateDomain.ChannelEmail, MessageReply, map[string]string{
+		"title":   ticket.Title,
+		"message": reply.Message,
+	})
+	if err == nil {
+		err = u.mailer.Send(ctx, *ticket.RequesterEmail, msg.Subject, msg.Body)
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to mail the requester of ticket %s: %v", ticket.ID, err)
+	}
+}
+
+// toPublicReply returns a reply as the requester sees it, without the staff
+// member who wrote it
+func toPublicReply(reply *domain.TicketReply) *domain.PublicReplyInfo {
+	info := &domain.PublicReplyInfo{
+		ID:            reply.ID,
+		Message:       reply.Message,
+		FromRequester: reply.UserID == nil,
+		CreatedAt:     reply.CreatedAt,
+	}
+	if info.FromRequester {
+		info.ModerationStatus = string(reply.ModerationStatus)
+	}
+	return info
+}
+
+func hashAccessToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
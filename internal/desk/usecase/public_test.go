@@ -0,0 +1,213 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/internal/desk/domain"
+	"portal-data-backend/internal/desk/usecase"
+	templateDomain "portal-data-backend/internal/message_template/domain"
+	moderationDomain "portal-data-backend/internal/moderation/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+)
+
+// mockRepository is an in-memory implementation of Repository keeping the
+// tickets and replies the public flow needs
+type mockRepository struct {
+	domain.Repository
+	tickets []*domain.Ticket
+	replies []*domain.TicketReply
+}
+
+func (m *mockRepository) GetByID(ctx context.Context, id string) (*domain.Ticket, error) {
+	for _, ticket := range m.tickets {
+		if ticket.ID == id {
+			return ticket, nil
+		}
+	}
+	return nil, pkgErrors.ErrNotFound
+}
+
+func (m *mockRepository) Create(ctx context.Context, ticket *domain.Ticket) error {
+	m.tickets = append(m.tickets, ticket)
+	return nil
+}
+
+func (m *mockRepository) GetByAccessToken(ctx context.Context, tokenHash string) (*domain.Ticket, error) {
+	for _, ticket := range m.tickets {
+		if ticket.AccessTokenHash != nil && *ticket.AccessTokenHash == tokenHash {
+			return ticket, nil
+		}
+	}
+	return nil, pkgErrors.ErrNotFound
+}
+
+func (m *mockRepository) CreateReply(ctx context.Context, reply *domain.TicketReply) error {
+	m.replies = append(m.replies, reply)
+	return nil
+}
+
+func (m *mockRepository) ListReplies(ctx context.Context, ticketID string, public bool) ([]*domain.TicketReply, error) {
+	var replies []*domain.TicketReply
+	for _, reply := range m.replies {
+		if reply.TicketID != ticketID {
+			continue
+		}
+		if public && (!reply.IsPublic || reply.ModerationStatus == domain.ModerationStatusRejected) {
+			continue
+		}
+		if !public && reply.UserID == nil && reply.ModerationStatus != domain.ModerationStatusApproved {
+			continue
+		}
+		replies = append(replies, reply)
+	}
+	return replies, nil
+}
+
+func (m *mockRepository) UpdateReplyModeration(ctx context.Context, id string, status domain.ModerationStatus) error {
+	for _, reply := range m.replies {
+		if reply.ID == id {
+			reply.ModerationStatus = status
+			return nil
+		}
+	}
+	return pkgErrors.ErrNotFound
+}
+
+type mockTransactor struct{}
+
+func (mockTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// mockModerator holds the texts mentioning "casino"
+type mockModerator struct{}
+
+func (mockModerator) Submit(ctx context.Context, sub *moderationDomain.Submission) (moderationDomain.Status, error) {
+	if strings.Contains(sub.Text, "casino") {
+		return moderationDomain.StatusPending, nil
+	}
+	return moderationDomain.StatusApproved, nil
+}
+
+type mail struct{ to, subject, body string }
+
+type mockMailer struct {
+	sent []mail
+}
+
+func (m *mockMailer) Send(ctx context.Context, to, subject, body string) error {
+	m.sent = append(m.sent, mail{to, subject, body})
+	return nil
+}
+
+// mockMessages renders the variables of a message as its body
+type mockMessages struct{}
+
+func (mockMessages) Render(ctx context.Context, channel templateDomain.Channel, key string, vars map[string]string) (*templateDomain.Message, error) {
+	body := ""
+	for _, name := range []string{"title", "link", "message"} {
+		body += vars[name] + "\n"
+	}
+	return &templateDomain.Message{Subject: key, Body: body}, nil
+}
+
+// Test requesters follow and reply to tickets filed through the contact form
+// with the token of the link they are mailed, and only see public replies
+func TestDesk_PublicTicket(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockRepository{}
+	mailer := &mockMailer{}
+	desk := usecase.NewDeskUsecase(repo, mockTransactor{}, nil, mockModerator{}, mailer, mockMessages{}, config.DeskConfig{PublicURL: "https://portal.example/tickets/"})
+
+	err := desk.CreatePublic(ctx, &domain.CreatePublicTicketRequest{Email: "ana@example.com", Title: "Missing data", Description: "The 2024 rows are gone"}, "203.0.113.7")
+	if err != nil {
+		t.Fatalf("CreatePublic: %v", err)
+	}
+	if len(repo.tickets) != 1 || len(mailer.sent) != 1 {
+		t.Fatalf("Expected one ticket and one mail, got %d and %d", len(repo.tickets), len(mailer.sent))
+	}
+	ticket := repo.tickets[0]
+	if ticket.UserID != nil || ticket.Category != string(domain.TicketCategoryOther) || ticket.Priority != string(domain.TicketPriorityMedium) {
+		t.Errorf("Expected a medium ticket of no user in the other category, got %+v", ticket)
+	}
+
+	_, link, _ := strings.Cut(mailer.sent[0].body, "https://portal.example/tickets/")
+	token, _, _ := strings.Cut(link, "\n")
+	if mailer.sent[0].to != "ana@example.com" || token == "" {
+		t.Fatalf("Expected the link to be mailed to the requester, got %+v", mailer.sent[0])
+	}
+	if ticket.AccessTokenHash == nil || *ticket.AccessTokenHash == token {
+		t.Errorf("Expected only the hash of the token to be kept, got %v", ticket.AccessTokenHash)
+	}
+
+	if _, err := desk.Reply(ctx, ticket.ID, &domain.CreateTicketReplyRequest{Message: "Checking the import log"}, "staff-1"); err != nil {
+		t.Fatalf("Reply: %v", err)
+	}
+	if _, err := desk.Reply(ctx, ticket.ID, &domain.CreateTicketReplyRequest{Message: "We restored the rows", IsPublic: true}, "staff-1"); err != nil {
+		t.Fatalf("Reply: %v", err)
+	}
+	if len(mailer.sent) != 2 || !strings.Contains(mailer.sent[1].body, "We restored the rows") {
+		t.Errorf("Expected the public reply to be mailed to the requester, got %+v", mailer.sent)
+	}
+
+	reply, err := desk.ReplyPublic(ctx, token, &domain.CreatePublicReplyRequest{Message: "Thanks, visit my casino"})
+	if err != nil {
+		t.Fatalf("ReplyPublic: %v", err)
+	}
+	if !reply.FromRequester || reply.ModerationStatus != string(domain.ModerationStatusPending) {
+		t.Errorf("Expected the reply to be held for moderation, got %+v", reply)
+	}
+
+	view, err := desk.GetPublic(ctx, token)
+	if err != nil {
+		t.Fatalf("GetPublic: %v", err)
+	}
+	if view.Title != "Missing data" || view.Status != string(domain.TicketStatusOpen) || len(view.Replies) != 2 {
+		t.Fatalf("Expected the open ticket with two public replies, got %+v", view)
+	}
+	if view.Replies[0].Message != "We restored the rows" || view.Replies[0].FromRequester || view.Replies[0].ModerationStatus != "" {
+		t.Errorf("Expected the public reply of staff first, got %+v", view.Replies[0])
+	}
+
+	staff, err := desk.ListReplies(ctx, ticket.ID)
+	if err != nil {
+		t.Fatalf("ListReplies: %v", err)
+	}
+	if len(staff.Replies) != 2 {
+		t.Errorf("Expected staff to see their two replies without the held one, got %+v", staff.Replies)
+	}
+	if err := desk.ApplyModeration(ctx, reply.ID, moderationDomain.StatusApproved); err != nil {
+		t.Fatalf("ApplyModeration: %v", err)
+	}
+	if staff, err = desk.ListReplies(ctx, ticket.ID); err != nil || len(staff.Replies) != 3 {
+		t.Errorf("Expected staff to see the reply once approved, got %+v (%v)", staff, err)
+	}
+
+	if _, err := desk.GetPublic(ctx, "not-a-token"); !errors.Is(err, pkgErrors.ErrNotFound) {
+		t.Errorf("Expected an unknown token to be not found, got %v", err)
+	}
+
+	ticket.Status = string(domain.TicketStatusClosed)
+	if _, err := desk.ReplyPublic(ctx, token, &domain.CreatePublicReplyRequest{Message: "One more thing"}); !errors.Is(err, pkgErrors.ErrInvalidInput) {
+		t.Errorf("Expected replies to closed tickets to be refused, got %v", err)
+	}
+}
+
+// Test tickets of bots filling in the honeypot are dropped silently
+func TestDesk_CreatePublicHoneypot(t *testing.T) {
+	repo := &mockRepository{}
+	mailer := &mockMailer{}
+	desk := usecase.NewDeskUsecase(repo, mockTransactor{}, nil, nil, mailer, mockMessages{}, config.DeskConfig{})
+
+	err := desk.CreatePublic(context.Background(), &domain.CreatePublicTicketRequest{Email: "bot@example.com", Title: "Offer", Description: "Buy now", Website: "http://spam.example"}, "203.0.113.7")
+	if err != nil {
+		t.Fatalf("CreatePublic: %v", err)
+	}
+	if len(repo.tickets) != 0 || len(mailer.sent) != 0 {
+		t.Errorf("Expected the ticket to be dropped, got %d tickets and %d mails", len(repo.tickets), len(mailer.sent))
+	}
+}
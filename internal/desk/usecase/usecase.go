@@ -6,7 +6,11 @@ import (
 	"math"
 	"time"
 
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/infrastructure/db"
+	"portal-data-backend/infrastructure/logger"
 	"portal-data-backend/internal/desk/domain"
+	moderationDomain "portal-data-backend/internal/moderation/domain"
 
 	"github.com/google/uuid"
 )
@@ -17,17 +21,63 @@ type Usecase interface {
 	Create(ctx context.Context, req *domain.CreateTicketRequest, userID string) (*domain.TicketInfo, error)
 	Update(ctx context.Context, id string, req *domain.UpdateTicketRequest) (*domain.TicketInfo, error)
 	Delete(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) error
 	UpdateStatus(ctx context.Context, id string, status string) error
 	AssignTicket(ctx context.Context, id string, assignedTo string) error
+	// BulkAssign assigns several tickets in one transaction, returning the
+	// errors of those it could not assign by ID
+	BulkAssign(ctx context.Context, ids []string, assignedTo string) (map[string]error, error)
+	// CheckSLA reports every unresolved ticket past the SLA of its priority and
+	// returns how many breaches were reported
+	CheckSLA(ctx context.Context) (int, error)
+	// Run checks ticket SLAs periodically until ctx is cancelled
+	Run(ctx context.Context)
+
+	// Reply adds a reply of staff to a ticket. The requester of a ticket
+	// filed through the contact form is mailed public replies.
+	Reply(ctx context.Context, id string, req *domain.CreateTicketReplyRequest, userID string) (*domain.TicketReply, error)
+	// ListReplies returns the replies to a ticket, internal ones included.
+	// Replies of the requester are left out until a moderator approves them.
+	ListReplies(ctx context.Context, id string) (*domain.TicketRepliesResponse, error)
+	// CreatePublic files a ticket from the contact form and mails its
+	// requester the link to follow it
+	CreatePublic(ctx context.Context, req *domain.CreatePublicTicketRequest, remoteIP string) error
+	// GetPublic returns the ticket of an access token as its requester sees it
+	GetPublic(ctx context.Context, token string) (*domain.PublicTicketInfo, error)
+	// ReplyPublic adds a reply of the requester holding an access token,
+	// screened by moderation before it is shown
+	ReplyPublic(ctx context.Context, token string, req *domain.CreatePublicReplyRequest) (*domain.PublicReplyInfo, error)
+	// ApplyModeration shows or hides a reply of a requester as the
+	// moderation queue decides it
+	ApplyModeration(ctx context.Context, id string, status moderationDomain.Status) error
 }
 
+// slaBatchSize bounds how many breaches of one priority are reported per check
+const slaBatchSize = 100
+
 type deskUsecase struct {
-	repo domain.Repository
+	repo       domain.Repository
+	tx         db.Transactor
+	events     domain.EventPublisher
+	moderation Moderator
+	mailer     MailSender
+	messages   MessageRenderer
+	cfg        config.DeskConfig
+	now        func() time.Time
 }
 
-func NewDeskUsecase(repo domain.Repository) Usecase {
+// NewDeskUsecase creates a new desk usecase. events may be nil. moderation
+// may be nil, then replies of requesters are shown right away.
+func NewDeskUsecase(repo domain.Repository, tx db.Transactor, events domain.EventPublisher, moderation Moderator, mailer MailSender, messages MessageRenderer, cfg config.DeskConfig) Usecase {
 	return &deskUsecase{
-		repo: repo,
+		repo:       repo,
+		tx:         tx,
+		events:     events,
+		moderation: moderation,
+		mailer:     mailer,
+		messages:   messages,
+		cfg:        cfg,
+		now:        time.Now,
 	}
 }
 
@@ -90,18 +140,24 @@ func (u *deskUsecase) Create(ctx context.Context, req *domain.CreateTicketReques
 		Status:      string(domain.TicketStatusOpen),
 		Priority:    req.Priority,
 		Category:    req.Category,
-		UserID:      userID,
+		UserID:      &userID,
 		AssignedTo:  req.AssignedTo,
-		CreatedBy:   userID,
+		CreatedBy:   &userID,
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
 
-	if err := u.repo.Create(ctx, ticket); err != nil {
-		return nil, fmt.Errorf("failed to create ticket: %w", err)
+	info := u.toInfo(ticket)
+	err := u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.repo.Create(ctx, ticket); err != nil {
+			return fmt.Errorf("failed to create ticket: %w", err)
+		}
+		return u.publish(ctx, domain.EventTicketCreated, info)
+	})
+	if err != nil {
+		return nil, err
 	}
-
-	return u.toInfo(ticket), nil
+	return info, nil
 }
 
 func (u *deskUsecase) Update(ctx context.Context, id string, req *domain.UpdateTicketRequest) (*domain.TicketInfo, error) {
@@ -150,6 +206,13 @@ func (u *deskUsecase) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+func (u *deskUsecase) Restore(ctx context.Context, id string) error {
+	if err := u.repo.Restore(ctx, id); err != nil {
+		return fmt.Errorf("failed to restore ticket: %w", err)
+	}
+	return nil
+}
+
 func (u *deskUsecase) UpdateStatus(ctx context.Context, id string, status string) error {
 	if err := u.repo.UpdateStatus(ctx, id, status); err != nil {
 		return fmt.Errorf("failed to update ticket status: %w", err)
@@ -164,19 +227,105 @@ func (u *deskUsecase) AssignTicket(ctx context.Context, id string, assignedTo st
 	return nil
 }
 
+func (u *deskUsecase) BulkAssign(ctx context.Context, ids []string, assignedTo string) (map[string]error, error) {
+	return db.Each(ctx, u.tx, ids, func(ctx context.Context, id string) error {
+		// Assigning reports no missing tickets by itself
+		if _, err := u.repo.GetByID(ctx, id); err != nil {
+			return fmt.Errorf("failed to get ticket: %w", err)
+		}
+		return u.AssignTicket(ctx, id, assignedTo)
+	})
+}
+
+func (u *deskUsecase) CheckSLA(ctx context.Context) (int, error) {
+	now := u.now()
+	reported := 0
+
+	for priority, sla := range u.cfg.SLA {
+		if sla <= 0 {
+			continue
+		}
+
+		tickets, err := u.repo.ListOverdue(ctx, priority, now.Add(-sla), slaBatchSize)
+		if err != nil {
+			return reported, fmt.Errorf("failed to list overdue tickets: %w", err)
+		}
+
+		for _, ticket := range tickets {
+			// Only the instance that records the breach reports it, in the
+			// transaction recording it
+			var marked bool
+			err := u.tx.WithinTx(ctx, func(ctx context.Context) error {
+				var err error
+				if marked, err = u.repo.MarkSLABreached(ctx, ticket.ID, now); err != nil {
+					return fmt.Errorf("failed to mark sla breach: %w", err)
+				}
+				if !marked {
+					return nil
+				}
+				ticket.SLABreachedAt = &now
+				return u.publish(ctx, domain.EventTicketSLABreached, u.toInfo(ticket))
+			})
+			if err != nil {
+				return reported, err
+			}
+			if marked {
+				reported++
+			}
+		}
+	}
+
+	return reported, nil
+}
+
+func (u *deskUsecase) Run(ctx context.Context) {
+	if len(u.cfg.SLA) == 0 || u.cfg.SLACheckInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(u.cfg.SLACheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := u.CheckSLA(ctx); err != nil {
+				logger.FromContext(ctx).Error("ticket sla check failed: %v", err)
+			}
+		}
+	}
+}
+
+// publish records an event in the outbox, in the transaction ctx carries,
+// so the event is emitted exactly when the change commits
+func (u *deskUsecase) publish(ctx context.Context, eventType string, data interface{}) error {
+	if u.events == nil {
+		return nil
+	}
+	if err := u.events.Publish(ctx, eventType, data); err != nil {
+		return fmt.Errorf("failed to publish %s event: %w", eventType, err)
+	}
+	return nil
+}
+
 func (u *deskUsecase) toInfo(ticket *domain.Ticket) *domain.TicketInfo {
 	return &domain.TicketInfo{
-		ID:          ticket.ID,
-		Title:       ticket.Title,
-		Description: ticket.Description,
-		Status:      ticket.Status,
-		Priority:    ticket.Priority,
-		Category:    ticket.Category,
-		UserID:      ticket.UserID,
-		AssignedTo:  ticket.AssignedTo,
-		ResolvedAt:  ticket.ResolvedAt,
-		CreatedBy:   ticket.CreatedBy,
-		CreatedAt:   ticket.CreatedAt,
-		UpdatedAt:   ticket.UpdatedAt,
+		ID:             ticket.ID,
+		Title:          ticket.Title,
+		Description:    ticket.Description,
+		Status:         ticket.Status,
+		Priority:       ticket.Priority,
+		Category:       ticket.Category,
+		UserID:         ticket.UserID,
+		RequesterEmail: ticket.RequesterEmail,
+		AssignedTo:     ticket.AssignedTo,
+		ResolvedAt:     ticket.ResolvedAt,
+		SLABreachedAt:  ticket.SLABreachedAt,
+		CreatedBy:      ticket.CreatedBy,
+		CreatedAt:      ticket.CreatedAt,
+		UpdatedAt:      ticket.UpdatedAt,
+		DeletedAt:      ticket.DeletedAt,
 	}
 }
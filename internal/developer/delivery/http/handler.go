@@ -0,0 +1,296 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"portal-data-backend/infrastructure/auth"
+	"portal-data-backend/infrastructure/http/httputil"
+	"portal-data-backend/infrastructure/http/middleware"
+	"portal-data-backend/infrastructure/http/problem"
+	"portal-data-backend/infrastructure/http/response"
+	developerDomain "portal-data-backend/internal/developer/domain"
+	"portal-data-backend/internal/developer/usecase"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	developerUsecase usecase.Usecase
+}
+
+func NewHandler(developerUsecase usecase.Usecase) *Handler {
+	return &Handler{
+		developerUsecase: developerUsecase,
+	}
+}
+
+func (h *Handler) Plans(w http.ResponseWriter, r *http.Request) {
+	response.OK(w, response.CodeSuccess, "Plans retrieved successfully", h.developerUsecase.Plans(r.Context()))
+}
+
+func (h *Handler) RegisterApplication(w http.ResponseWriter, r *http.Request) {
+	req, ok := httputil.Decode[developerDomain.RegisterApplicationRequest](w, r)
+	if !ok {
+		return
+	}
+
+	userID := auth.UserID(r.Context())
+
+	app, err := h.developerUsecase.RegisterApplication(r.Context(), req, userID)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.Created(w, response.CodeCreated, "Application registered successfully", app)
+}
+
+func (h *Handler) ListApplications(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+
+	apps, err := h.developerUsecase.ListApplications(r.Context(), userID)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Applications retrieved successfully", apps)
+}
+
+func (h *Handler) GetApplication(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	userID := auth.UserID(r.Context())
+
+	app, err := h.developerUsecase.GetApplication(r.Context(), id, userID)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Application retrieved successfully", app)
+}
+
+func (h *Handler) UpdateApplication(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	req, ok := httputil.Decode[developerDomain.UpdateApplicationRequest](w, r)
+	if !ok {
+		return
+	}
+
+	userID := auth.UserID(r.Context())
+
+	app, err := h.developerUsecase.UpdateApplication(r.Context(), id, req, userID)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Application updated successfully", app)
+}
+
+func (h *Handler) ListKeys(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	userID := auth.UserID(r.Context())
+
+	keys, err := h.developerUsecase.ListKeys(r.Context(), id, userID)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "API keys retrieved successfully", keys)
+}
+
+func (h *Handler) CreateKey(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	req, ok := httputil.Decode[developerDomain.CreateAPIKeyRequest](w, r)
+	if !ok {
+		return
+	}
+
+	userID := auth.UserID(r.Context())
+
+	key, err := h.developerUsecase.CreateKey(r.Context(), id, req, userID)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.Created(w, response.CodeCreated, "API key created successfully", key)
+}
+
+// RotateKey issues a key replacing another; the replaced key keeps working
+// for the rotation grace period
+func (h *Handler) RotateKey(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+	keyID, ok := httputil.UUIDParam(w, r, "keyId")
+	if !ok {
+		return
+	}
+
+	userID := auth.UserID(r.Context())
+
+	key, err := h.developerUsecase.RotateKey(r.Context(), id, keyID, userID)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.Created(w, response.CodeCreated, "API key rotated successfully", key)
+}
+
+func (h *Handler) RevokeKey(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+	keyID, ok := httputil.UUIDParam(w, r, "keyId")
+	if !ok {
+		return
+	}
+
+	userID := auth.UserID(r.Context())
+
+	if err := h.developerUsecase.RevokeKey(r.Context(), id, keyID, userID); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "API key revoked successfully", nil)
+}
+
+func (h *Handler) Usage(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	req := &developerDomain.UsageRequest{
+		From: r.URL.Query().Get("from"),
+		To:   r.URL.Query().Get("to"),
+	}
+
+	userID := auth.UserID(r.Context())
+
+	usage, err := h.developerUsecase.Usage(r.Context(), id, req, userID)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Usage retrieved successfully", usage)
+}
+
+func (h *Handler) ListAllApplications(w http.ResponseWriter, r *http.Request) {
+	req := &developerDomain.ListApplicationsRequest{
+		Page:   parseIntQuery(r, "page", 1),
+		Limit:  parseIntQuery(r, "limit", 20),
+		Search: r.URL.Query().Get("search"),
+	}
+
+	// Parse optional filters
+	if userID := r.URL.Query().Get("user_id"); userID != "" {
+		req.UserID = &userID
+	}
+	if plan := r.URL.Query().Get("plan"); plan != "" {
+		req.Plan = &plan
+	}
+
+	resp, err := h.developerUsecase.ListAllApplications(r.Context(), req)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Applications retrieved successfully", resp)
+}
+
+func (h *Handler) UpdatePlan(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	req, ok := httputil.Decode[developerDomain.UpdatePlanRequest](w, r)
+	if !ok {
+		return
+	}
+
+	app, err := h.developerUsecase.UpdatePlan(r.Context(), id, req.Plan)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Application plan updated successfully", app)
+}
+
+// errorMapper maps the errors of the developer module on top of the shared ones
+var errorMapper = problem.Default.With(
+	problem.Mapping{Err: pkgErrors.ErrNotFound, Status: http.StatusNotFound, Code: response.CodeNotFound, Message: "Application not found"},
+)
+
+func (h *Handler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	errorMapper.Write(w, r, err)
+}
+
+func parseIntQuery(r *http.Request, key string, defaultValue int) int {
+	if value := r.URL.Query().Get(key); value != "" {
+		if intValue, err := strconv.Atoi(value); err == nil {
+			return intValue
+		}
+	}
+	return defaultValue
+}
+
+// RegisterRoutes registers developer routes. Signed-in users manage their
+// own applications and keys; users with one of adminRoles list every
+// application and assign plans.
+func RegisterRoutes(r chi.Router, handler *Handler, auth func(http.Handler) http.Handler, adminRoles []string) {
+	r.Route("/developer", func(r chi.Router) {
+		r.Get("/plans", handler.Plans)
+
+		r.Group(func(r chi.Router) {
+			r.Use(auth)
+			r.Get("/applications", handler.ListApplications)
+			r.Post("/applications", handler.RegisterApplication)
+			r.Get("/applications/{id}", handler.GetApplication)
+			r.Put("/applications/{id}", handler.UpdateApplication)
+			r.Get("/applications/{id}/usage", handler.Usage)
+
+			// API keys
+			r.Get("/applications/{id}/keys", handler.ListKeys)
+			r.Post("/applications/{id}/keys", handler.CreateKey)
+			r.Post("/applications/{id}/keys/{keyId}/rotate", handler.RotateKey)
+			r.Delete("/applications/{id}/keys/{keyId}", handler.RevokeKey)
+		})
+	})
+
+	r.Route("/admin/developer", func(r chi.Router) {
+		r.Use(auth)
+		r.Use(middleware.RequireRole(adminRoles...))
+		r.Get("/applications", handler.ListAllApplications)
+		r.Put("/applications/{id}/plan", handler.UpdatePlan)
+	})
+}
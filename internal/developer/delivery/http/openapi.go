@@ -0,0 +1,30 @@
+package http
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	developerDomain "portal-data-backend/internal/developer/domain"
+)
+
+// Describe adds the developer routes to the API specification
+func Describe(spec *openapi.Spec) {
+	api := spec.Tag("developer", "Applications and API keys of the developer program")
+	api.Get("/developer/plans", "List rate plans").Public().Returns(http.StatusOK, []developerDomain.PlanInfo{})
+	api.Get("/developer/applications", "List my applications").Returns(http.StatusOK, []developerDomain.ApplicationInfo{})
+	api.Post("/developer/applications", "Register application").Body(developerDomain.RegisterApplicationRequest{}).Returns(http.StatusCreated, developerDomain.ApplicationInfo{})
+	api.Get("/developer/applications/{id}", "Get application").Returns(http.StatusOK, developerDomain.ApplicationInfo{})
+	api.Put("/developer/applications/{id}", "Update application").Body(developerDomain.UpdateApplicationRequest{}).Returns(http.StatusOK, developerDomain.ApplicationInfo{})
+	api.Get("/developer/applications/{id}/usage", "Get application usage").
+		Query(developerDomain.UsageRequest{}, "from", "to").
+		Returns(http.StatusOK, developerDomain.UsageResponse{})
+	api.Get("/developer/applications/{id}/keys", "List API keys").Returns(http.StatusOK, []developerDomain.APIKeyInfo{})
+	api.Post("/developer/applications/{id}/keys", "Create API key").Body(developerDomain.CreateAPIKeyRequest{}).Returns(http.StatusCreated, developerDomain.APIKeyCreated{})
+	api.Post("/developer/applications/{id}/keys/{keyId}/rotate", "Rotate API key").Returns(http.StatusCreated, developerDomain.APIKeyCreated{})
+	api.Delete("/developer/applications/{id}/keys/{keyId}", "Revoke API key").Returns(http.StatusOK, nil)
+
+	api.Get("/admin/developer/applications", "List every application").
+		Query(developerDomain.ListApplicationsRequest{}, "page", "limit", "user_id", "plan", "search").
+		Returns(http.StatusOK, developerDomain.ApplicationListResponse{})
+	api.Put("/admin/developer/applications/{id}/plan", "Change application plan").Body(developerDomain.UpdatePlanRequest{}).Returns(http.StatusOK, developerDomain.ApplicationInfo{})
+}
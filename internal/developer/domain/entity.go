@@ -0,0 +1,179 @@
+package domain
+
+import "time"
+
+// Application is an application an external developer registered to call
+// the API with API keys
+type Application struct {
+	ID          string    `db:"id" json:"id"`
+	UserID      string    `db:"user_id" json:"user_id"`
+	Name        string    `db:"name" json:"name"`
+	Description *string   `db:"description" json:"description,omitempty"`
+	Website     *string   `db:"website" json:"website,omitempty"`
+	Plan        string    `db:"plan" json:"plan"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// Plan is the rate plan of an application
+type Plan string
+
+const (
+	PlanFree    Plan = "free"
+	PlanPartner Plan = "partner"
+)
+
+// APIKey authenticates the requests of an application. Only a hash of the
+// key is stored.
+type APIKey struct {
+	ID            string     `db:"id" json:"id"`
+	ApplicationID string     `db:"application_id" json:"application_id"`
+	Name          string     `db:"name" json:"name"`
+	KeyHash       string     `db:"key_hash" json:"-"` // SHA-256 of the key
+	Prefix        string     `db:"prefix" json:"prefix"`
+	LastUsedAt    *time.Time `db:"last_used_at" json:"last_used_at,omitempty"`
+	ExpiresAt     *time.Time `db:"expires_at" json:"expires_at,omitempty"`
+	RevokedAt     *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+	CreatedAt     time.Time  `db:"created_at" json:"created_at"`
+}
+
+// Live reports whether the key authenticates requests at now
+func (k *APIKey) Live(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || k.ExpiresAt.After(now))
+}
+
+// Usage counts the requests sent with an API key on one day, and those of
+// them refused over the rate limit
+type Usage struct {
+	KeyID      string    `db:"key_id" json:"key_id"`
+	Day        time.Time `db:"day" json:"day"`
+	Requests   int64     `db:"requests" json:"requests"`
+	Throttled  int64     `db:"throttled" json:"throttled"`
+	LastUsedAt time.Time `db:"-" json:"-"`
+}
+
+// Client is the application an API key authenticates, with the limit of its
+// plan
+type Client struct {
+	KeyID         string        `json:"key_id"`
+	ApplicationID string        `json:"application_id"`
+	Plan          string        `json:"plan"`
+	RateLimit     int           `json:"rate_limit"`
+	RateWindow    time.Duration `json:"rate_window"`
+	ExpiresAt     *time.Time    `json:"expires_at,omitempty"`
+}
+
+// RegisterApplicationRequest represents register application input
+type RegisterApplicationRequest struct {
+	Name        string  `json:"name" validate:"required,min=2,max=100"`
+	Description *string `json:"description,omitempty" validate:"omitempty,max=1000"`
+	Website     *string `json:"website,omitempty" validate:"omitempty,url,max=255"`
+}
+
+// UpdateApplicationRequest represents update application input
+type UpdateApplicationRequest struct {
+	Name        string  `json:"name" validate:"required,min=2,max=100"`
+	Description *string `json:"description,omitempty" validate:"omitempty,max=1000"`
+	Website     *string `json:"website,omitempty" validate:"omitempty,url,max=255"`
+}
+
+// UpdatePlanRequest represents the plan an admin moves an application to
+type UpdatePlanRequest struct {
+	Plan Plan `json:"plan" validate:"required,oneof=free partner"`
+}
+
+// ListApplicationsRequest represents list applications input
+type ListApplicationsRequest struct {
+	Page   int     `json:"page" validate:"min=1"`
+	Limit  int     `json:"limit" validate:"min=1,max=100"`
+	UserID *string `json:"user_id,omitempty"`
+	Plan   *string `json:"plan,omitempty"`
+	Search string  `json:"search,omitempty"`
+}
+
+// CreateAPIKeyRequest represents create API key input
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" validate:"required,min=2,max=100"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// UsageRequest represents the days usage is read for, as YYYY-MM-DD. The
+// last 30 days when unset.
+type UsageRequest struct {
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+}
+
+// PlanInfo represents a rate plan for API responses. A rate limit of 0 is
+// unlimited.
+type PlanInfo struct {
+	Name              string `json:"name"`
+	RateLimit         int    `json:"rate_limit"`
+	RateWindowSeconds int    `json:"rate_window_seconds"`
+}
+
+// ApplicationInfo represents application information for API responses
+type ApplicationInfo struct {
+	Application
+	Limits PlanInfo `json:"limits"`
+}
+
+// ApplicationListResponse represents paginated application list
+type ApplicationListResponse struct {
+	Applications []ApplicationInfo `json:"applications"`
+	Meta         ListMeta          `json:"meta"`
+}
+
+// APIKeyInfo represents API key information for API responses
+type APIKeyInfo struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// APIKeyCreated is returned once when a key is created or rotated. The key
+// itself cannot be retrieved again.
+type APIKeyCreated struct {
+	APIKeyInfo
+	Key string `json:"key"`
+}
+
+// UsageDay represents the requests of an application on one day
+type UsageDay struct {
+	Day       string `json:"day"`
+	Requests  int64  `json:"requests"`
+	Throttled int64  `json:"throttled"`
+}
+
+// KeyUsage represents the requests sent with one key over the period
+type KeyUsage struct {
+	KeyID     string `json:"key_id"`
+	Name      string `json:"name"`
+	Prefix    string `json:"prefix"`
+	Requests  int64  `json:"requests"`
+	Throttled int64  `json:"throttled"`
+}
+
+// UsageResponse represents the usage of an application over a period
+type UsageResponse struct {
+	ApplicationID string     `json:"application_id"`
+	Limits        PlanInfo   `json:"limits"`
+	From          string     `json:"from"`
+	To            string     `json:"to"`
+	Requests      int64      `json:"requests"`
+	Throttled     int64      `json:"throttled"`
+	Days          []UsageDay `json:"days"`
+	Keys          []KeyUsage `json:"keys"`
+}
+
+// ListMeta represents pagination metadata
+type ListMeta struct {
+	Page      int `json:"page"`
+	Limit     int `json:"limit"`
+	Total     int `json:"total"`
+	TotalPage int `json:"total_page"`
+}
@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type Repository interface {
+	GetApplication(ctx context.Context, id string) (*Application, error)
+	ListApplications(ctx context.Context, filter *ApplicationFilter, limit, offset int) ([]*Application, int, error)
+	CreateApplication(ctx context.Context, app *Application) error
+	UpdateApplication(ctx context.Context, app *Application) error
+	UpdatePlan(ctx context.Context, id string, plan Plan, at time.Time) error
+	CountApplications(ctx context.Context, userID string) (int, error)
+
+	CreateKey(ctx context.Context, key *APIKey) error
+	GetKey(ctx context.Context, applicationID, id string) (*APIKey, error)
+	GetKeyByHash(ctx context.Context, keyHash string) (*APIKey, error)
+	ListKeys(ctx context.Context, applicationID string) ([]*APIKey, error)
+	// CountLiveKeys counts the keys of an application authenticating at now
+	CountLiveKeys(ctx context.Context, applicationID string, now time.Time) (int, error)
+	RevokeKey(ctx context.Context, applicationID, id string, at time.Time) error
+	// ExpireKey moves the expiry of a live key to at, unless it expires earlier
+	ExpireKey(ctx context.Context, applicationID, id string, at time.Time) error
+
+	// AddUsage adds the counts of usage to those stored and moves the last
+	// use of their keys forward
+	AddUsage(ctx context.Context, usage []*Usage) error
+	// ListUsage returns the daily usage of the keys of an application
+	// between from and to, inclusive
+	ListUsage(ctx context.Context, applicationID string, from, to time.Time) ([]*Usage, error)
+}
+
+type ApplicationFilter struct {
+	UserID *string
+	Plan   *string
+	Search string
+}
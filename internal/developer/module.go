@@ -0,0 +1,55 @@
+// Package developer is the module of the developer program, letting external
+// developers register applications and call the API with their own keys.
+package developer
+
+import (
+	"context"
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	"portal-data-backend/internal/app"
+	delivery "portal-data-backend/internal/developer/delivery/http"
+	"portal-data-backend/internal/developer/repository"
+	"portal-data-backend/internal/developer/usecase"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Module manages developer applications and their API keys, and writes the
+// usage of keys in the background
+type Module struct {
+	usecase    usecase.Usecase
+	handler    *delivery.Handler
+	adminRoles []string
+}
+
+// Name implements app.Module
+func (m *Module) Name() string {
+	return "developer"
+}
+
+// Register implements app.Module
+func (m *Module) Register(deps *app.Deps) error {
+	cfg := deps.Config.Developer
+	repo := repository.NewDeveloperPostgresRepository(deps.DB)
+	m.usecase = usecase.NewDeveloperUsecase(repo, deps.Cache.Namespace("api_keys", cfg.KeyCacheTTL), cfg)
+	m.handler = delivery.NewHandler(m.usecase)
+	m.adminRoles = deps.Config.Audit.AdminRoles
+	deps.Services.Developers = m.usecase
+	return nil
+}
+
+// Routes implements app.Module
+func (m *Module) Routes(r chi.Router, auth func(http.Handler) http.Handler) {
+	delivery.RegisterRoutes(r, m.handler, auth, m.adminRoles)
+}
+
+// Describe implements app.Module
+func (m *Module) Describe(spec *openapi.Spec) {
+	delivery.Describe(spec)
+}
+
+// Run implements app.Runner
+func (m *Module) Run(ctx context.Context) {
+	m.usecase.Run(ctx)
+}
@@ -0,0 +1,280 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	developerDomain "portal-data-backend/internal/developer/domain"
+
+	"portal-data-backend/infrastructure/db"
+	"portal-data-backend/pkg/errors"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type developerPostgresRepository struct {
+	db *sqlx.DB
+}
+
+func NewDeveloperPostgresRepository(db *sqlx.DB) developerDomain.Repository {
+	return &developerPostgresRepository{db: db}
+}
+
+const applicationColumns = `id, user_id, name, description, website, plan, created_at, updated_at`
+
+const keyColumns = `id, application_id, name, key_hash, prefix, last_used_at, expires_at, revoked_at, created_at`
+
+func (r *developerPostgresRepository) GetApplication(ctx context.Context, id string) (*developerDomain.Application, error) {
+	query := `SELECT ` + applicationColumns + ` FROM developer_applications WHERE id = $1`
+
+	var app developerDomain.Application
+	err := db.Conn(ctx, r.db).GetContext(ctx, &app, query, id)
+	if err != nil {
+		return nil, r.handleError(err, "application")
+	}
+	return &app, nil
+}
+
+func (r *developerPostgresRepository) ListApplications(ctx context.Context, filter *developerDomain.ApplicationFilter, limit, offset int) ([]*developerDomain.Application, int, error) {
+	whereClause := "WHERE TRUE"
+	args := []interface{}{}
+	argCount := 1
+
+	if filter != nil {
+		if filter.UserID != nil {
+			whereClause += fmt.Sprintf(" AND user_id = $%d", argCount)
+			args = append(args, *filter.UserID)
+			argCount++
+		}
+		if filter.Plan != nil {
+			whereClause += fmt.Sprintf(" AND plan = $%d", argCount)
+			args = append(args, *filter.Plan)
+			argCount++
+		}
+		if filter.Search != "" {
+			whereClause += fmt.Sprintf(" AND (name ILIKE $%d OR website ILIKE $%d)", argCount, argCount)
+			args = append(args, "%"+filter.Search+"%")
+			argCount++
+		}
+	}
+
+	countQuery := "SELECT COUNT(*) FROM developer_applications " + whereClause
+	var total int
+	err := db.Conn(ctx, r.db).GetContext(ctx, &total, countQuery, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count applications: %w", err)
+	}
+
+	query := `SELECT ` + applicationColumns + ` FROM developer_applications ` + whereClause +
+		fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", argCount, argCount+1)
+	args = append(args, limit, offset)
+
+	var apps []*developerDomain.Application
+	err = db.Conn(ctx, r.db).SelectContext(ctx, &apps, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list applications: %w", err)
+	}
+
+	return apps, total, nil
+}
+
+func (r *developerPostgresRepository) CreateApplication(ctx context.Context, app *developerDomain.Application) error {
+	query := `
+		INSERT INTO developer_applications (id, user_id, name, description, website, plan, created_at, updated_at)
+		VALUES (:id, :user_id, :name, :description, :website, :plan, :created_at, :updated_at)
+	`
+
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, app)
+	if err != nil {
+		return fmt.Errorf("failed to create application: %w", err)
+	}
+	return nil
+}
+
+func (r *developerPostgresRepository) UpdateApplication(ctx context.Context, app *developerDomain.Application) error {
+	query := `
+		UPDATE developer_applications
+		SET name = :name, description = :description, website = :website, updated_at = :updated_at
+		WHERE id = :id
+	`
+
+	result, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, app)
+	if err != nil {
+		return fmt.Errorf("failed to update application: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("application not found: %w", errors.ErrNotFound)
+	}
+	return nil
+}
+
+func (r *developerPostgresRepository) UpdatePlan(ctx context.Context, id string, plan developerDomain.Plan, at time.Time) error {
+	query := `UPDATE developer_applications SET plan = $1, updated_at = $2 WHERE id = $3`
+
+	result, err := db.Conn(ctx, r.db).ExecContext(ctx, query, string(plan), at, id)
+	if err != nil {
+		return fmt.Errorf("failed to update application plan: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("application not found: %w", errors.ErrNotFound)
+	}
+	return nil
+}
+
+func (r *developerPostgresRepository) CountApplications(ctx context.Context, userID string) (int, error) {
+	var count int
+	err := db.Conn(ctx, r.db).GetContext(ctx, &count, `SELECT COUNT(*) FROM developer_applications WHERE user_id = $1`, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count applications: %w", err)
+	}
+	return count, nil
+}
+
+func (r *developerPostgresRepository) CreateKey(ctx context.Context, key *developerDomain.APIKey) error {
+	query := `
+		INSERT INTO developer_api_keys (id, application_id, name, key_hash, prefix, expires_at, created_at)
+		VALUES (:id, :application_id, :name, :key_hash, :prefix, :expires_at, :created_at)
+	`
+
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, key)
+	if err != nil {
+		return fmt.Errorf("failed to create api key: %w", err)
+	}
+	return nil
+}
+
+func (r *developerPostgresRepository) GetKey(ctx context.Context, applicationID, id string) (*developerDomain.APIKey, error) {
+	query := `SELECT ` + keyColumns + ` FROM developer_api_keys WHERE application_id = $1 AND id = $2`
+
+	var key developerDomain.APIKey
+	err := db.Conn(ctx, r.db).GetContext(ctx, &key, query, applicationID, id)
+	if err != nil {
+		return nil, r.handleError(err, "api key")
+	}
+	return &key, nil
+}
+
+func (r *developerPostgresRepository) GetKeyByHash(ctx context.Context, keyHash string) (*developerDomain.APIKey, error) {
+	query := `SELECT ` + keyColumns + ` FROM developer_api_keys WHERE key_hash = $1`
+
+	var key developerDomain.APIKey
+	err := db.Conn(ctx, r.db).GetContext(ctx, &key, query, keyHash)
+	if err != nil {
+		return nil, r.handleError(err, "api key")
+	}
+	return &key, nil
+}
+
+func (r *developerPostgresRepository) ListKeys(ctx context.Context, applicationID string) ([]*developerDomain.APIKey, error) {
+	query := `SELECT ` + keyColumns + ` FROM developer_api_keys WHERE application_id = $1 ORDER BY created_at DESC`
+
+	var keys []*developerDomain.APIKey
+	err := db.Conn(ctx, r.db).SelectContext(ctx, &keys, query, applicationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	return keys, nil
+}
+
+func (r *developerPostgresRepository) CountLiveKeys(ctx context.Context, applicationID string, now time.Time) (int, error) {
+	query := `
+		SELECT COUNT(*) FROM developer_api_keys
+		WHERE application_id = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > $2)
+	`
+
+	var count int
+	err := db.Conn(ctx, r.db).GetContext(ctx, &count, query, applicationID, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count api keys: %w", err)
+	}
+	return count, nil
+}
+
+func (r *developerPostgresRepository) RevokeKey(ctx context.Context, applicationID, id string, at time.Time) error {
+	query := `UPDATE developer_api_keys SET revoked_at = $1 WHERE application_id = $2 AND id = $3 AND revoked_at IS NULL`
+
+	result, err := db.Conn(ctx, r.db).ExecContext(ctx, query, at, applicationID, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("api key not found: %w", errors.ErrNotFound)
+	}
+	return nil
+}
+
+func (r *developerPostgresRepository) ExpireKey(ctx context.Context, applicationID, id string, at time.Time) error {
+	query := `
+		UPDATE developer_api_keys SET expires_at = LEAST(COALESCE(expires_at, $1), $1)
+		WHERE application_id = $2 AND id = $3 AND revoked_at IS NULL
+	`
+
+	result, err := db.Conn(ctx, r.db).ExecContext(ctx, query, at, applicationID, id)
+	if err != nil {
+		return fmt.Errorf("failed to expire api key: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("api key not found: %w", errors.ErrNotFound)
+	}
+	return nil
+}
+
+func (r *developerPostgresRepository) AddUsage(ctx context.Context, usage []*developerDomain.Usage) error {
+	// Keys deleted with their application since are skipped
+	usageQuery := `
+		INSERT INTO developer_api_usage (key_id, day, requests, throttled)
+		SELECT id, $2, $3, $4 FROM developer_api_keys WHERE id = $1
+		ON CONFLICT (key_id, day) DO UPDATE
+		SET requests = developer_api_usage.requests + EXCLUDED.requests,
+		    throttled = developer_api_usage.throttled + EXCLUDED.throttled
+	`
+	touchQuery := `UPDATE developer_api_keys SET last_used_at = GREATEST(COALESCE(last_used_at, $1), $1) WHERE id = $2`
+
+	conn := db.Conn(ctx, r.db)
+	for _, u := range usage {
+		if _, err := conn.ExecContext(ctx, usageQuery, u.KeyID, u.Day, u.Requests, u.Throttled); err != nil {
+			return fmt.Errorf("failed to add api key usage: %w", err)
+		}
+		if _, err := conn.ExecContext(ctx, touchQuery, u.LastUsedAt, u.KeyID); err != nil {
+			return fmt.Errorf("failed to touch api key: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *developerPostgresRepository) ListUsage(ctx context.Context, applicationID string, from, to time.Time) ([]*developerDomain.Usage, error) {
+	query := `
+		SELECT u.key_id, u.day, u.requests, u.throttled
+		FROM developer_api_usage u
+		JOIN developer_api_keys k ON k.id = u.key_id
+		WHERE k.application_id = $1 AND u.day BETWEEN $2 AND $3
+		ORDER BY u.day, u.key_id
+	`
+
+	var usage []*developerDomain.Usage
+	err := db.Conn(ctx, r.db).SelectContext(ctx, &usage, query, applicationID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api key usage: %w", err)
+	}
+	return usage, nil
+}
+
+func (r *developerPostgresRepository) handleError(err error, resource string) error {
+	if err == nil {
+		return nil
+	}
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("%s not found: %w", resource, errors.ErrNotFound)
+	}
+	return fmt.Errorf("database error: %w", err)
+}
@@ -0,0 +1,562 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"portal-data-backend/infrastructure/cache"
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/infrastructure/logger"
+	"portal-data-backend/internal/developer/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// apiKeyPrefix marks API keys so they are recognisable in logs and secret scanners
+const apiKeyPrefix = "pdk_"
+
+// usageDays is how many days usage is read for by default
+const usageDays = 30
+
+type Usecase interface {
+	// Plans lists the rate plans applications are on
+	Plans(ctx context.Context) []domain.PlanInfo
+
+	// RegisterApplication registers an application of a developer on the free plan
+	RegisterApplication(ctx context.Context, req *domain.RegisterApplicationRequest, userID string) (*domain.ApplicationInfo, error)
+	// ListApplications lists the applications of a developer
+	ListApplications(ctx context.Context, userID string) ([]domain.ApplicationInfo, error)
+	GetApplication(ctx context.Context, id, userID string) (*domain.ApplicationInfo, error)
+	UpdateApplication(ctx context.Context, id string, req *domain.UpdateApplicationRequest, userID string) (*domain.ApplicationInfo, error)
+
+	CreateKey(ctx context.Context, applicationID string, req *domain.CreateAPIKeyRequest, userID string) (*domain.APIKeyCreated, error)
+	ListKeys(ctx context.Context, applicationID, userID string) ([]*domain.APIKeyInfo, error)
+	// RotateKey issues a key replacing a live one, which keeps working for
+	// the rotation grace period
+	RotateKey(ctx context.Context, applicationID, keyID, userID string) (*domain.APIKeyCreated, error)
+	RevokeKey(ctx context.Context, applicationID, keyID, userID string) error
+	// Usage returns the daily requests of the keys of an application
+	Usage(ctx context.Context, applicationID string, req *domain.UsageRequest, userID string) (*domain.UsageResponse, error)
+
+	// ListAllApplications lists the applications of every developer, for admins
+	ListAllApplications(ctx context.Context, req *domain.ListApplicationsRequest) (*domain.ApplicationListResponse, error)
+	// UpdatePlan moves an application to another rate plan
+	UpdatePlan(ctx context.Context, id string, plan domain.Plan) (*domain.ApplicationInfo, error)
+
+	// Authenticate returns the client of a live API key, or ErrUnauthorized
+	Authenticate(ctx context.Context, key string) (*domain.Client, error)
+	// Count counts a request sent with an API key. Counts are written in the
+	// background.
+	Count(keyID string, throttled bool)
+	// Run writes the counted usage periodically until ctx is cancelled
+	Run(ctx context.Context)
+}
+
+type developerUsecase struct {
+	repo domain.Repository
+	keys *cache.Namespace
+	cfg  config.DeveloperConfig
+	now  func() time.Time
+
+	mu    sync.Mutex
+	usage map[string]*domain.Usage
+}
+
+// NewDeveloperUsecase creates a new developer usecase. keys caches the
+// clients of API keys and may be nil.
+func NewDeveloperUsecase(repo domain.Repository, keys *cache.Namespace, cfg config.DeveloperConfig) Usecase {
+	return &developerUsecase{
+		repo:  repo,
+		keys:  keys,
+		cfg:   cfg,
+		now:   time.Now,
+		usage: map[string]*domain.Usage{},
+	}
+}
+
+func (u *developerUsecase) Plans(ctx context.Context) []domain.PlanInfo {
+	return []domain.PlanInfo{u.planInfo(string(domain.PlanFree)), u.planInfo(string(domain.PlanPartner))}
+}
+
+func (u *developerUsecase) RegisterApplication(ctx context.Context, req *domain.RegisterApplicationRequest, userID string) (*domain.ApplicationInfo, error) {
+	count, err := u.repo.CountApplications(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= u.cfg.MaxApplications {
+		return nil, fmt.Errorf("%w: a developer may register up to %d applications", pkgErrors.ErrInvalidInput, u.cfg.MaxApplications)
+	}
+
+	now := u.now()
+	app := &domain.Application{
+		ID:          uuid.New().String(),
+		UserID:      userID,
+		Name:        req.Name,
+		Description: req.Description,
+		Website:     req.Website,
+		Plan:        string(domain.PlanFree),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := u.repo.CreateApplication(ctx, app); err != nil {
+		return nil, err
+	}
+	return u.toApplicationInfo(app), nil
+}
+
+func (u *developerUsecase) ListApplications(ctx context.Context, userID string) ([]domain.ApplicationInfo, error) {
+	apps, _, err := u.repo.ListApplications(ctx, &domain.ApplicationFilter{UserID: &userID}, u.cfg.MaxApplications, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]domain.ApplicationInfo, len(apps))
+	for i, app := range apps {
+		infos[i] = *u.toApplicationInfo(app)
+	}
+	return infos, nil
+}
+
+func (u *developerUsecase) GetApplication(ctx context.Context, id, userID string) (*domain.ApplicationInfo, error) {
+	app, err := u.owned(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	return u.toApplicationInfo(app), nil
+}
+
+func (u *developerUsecase) UpdateApplication(ctx context.Context, id string, req *domain.UpdateApplicationRequest, userID string) (*domain.ApplicationInfo, error) {
+	app, err := u.owned(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	app.Name = req.Name
+	app.Description = req.Description
+	app.Website = req.Website
+	app.UpdatedAt = u.now()
+
+	if err := u.repo.UpdateApplication(ctx, app); err != nil {
+		return nil, err
+	}
+	return u.toApplicationInfo(app), nil
+}
+
+func (u *developerUsecase) CreateKey(ctx context.Context, applicationID string, req *domain.CreateAPIKeyRequest, userID string) (*domain.APIKeyCreated, error) {
+	app, err := u.owned(ctx, applicationID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := u.now()
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+		return nil, fmt.Errorf("%w: expires_at must be in the future", pkgErrors.ErrInvalidInput)
+	}
+	if err := u.checkKeyLimit(ctx, app.ID, now); err != nil {
+		return nil, err
+	}
+
+	return u.issueKey(ctx, app.ID, req.Name, req.ExpiresAt, now)
+}
+
+func (u *developerUsecase) ListKeys(ctx context.Context, applicationID, userID string) ([]*domain.APIKeyInfo, error) {
+	app, err := u.owned(ctx, applicationID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := u.repo.ListKeys(ctx, app.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]*domain.APIKeyInfo, len(keys))
+	for i, key := range keys {
+		infos[i] = toAPIKeyInfo(key)
+	}
+	return infos, nil
+}
+
+func (u *developerUsecase) RotateKey(ctx context.Context, applicationID, keyID, userID string) (*domain.APIKeyCreated, error) {
+	app, err := u.owned(ctx, applicationID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := u.now()
+	old, err := u.repo.GetKey(ctx, app.ID, keyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+	if !old.Live(now) {
+		return nil, fmt.Errorf("%w: only live keys can be rotated", pkgErrors.ErrInvalidInput)
+	}
+
+	// The replacement counts against the limit while the old key lives on
+	if u.cfg.RotationGrace > 0 {
+		if err := u.checkKeyLimit(ctx, app.ID, now); err != nil {
+			return nil, err
+		}
+	}
+
+	created, err := u.issueKey(ctx, app.ID, old.Name, old.ExpiresAt, now)
+	if err != nil {
+		return nil, err
+	}
+	if u.cfg.RotationGrace > 0 {
+		err = u.repo.ExpireKey(ctx, app.ID, old.ID, now.Add(u.cfg.RotationGrace))
+	} else {
+		err = u.repo.RevokeKey(ctx, app.ID, old.ID, now)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to retire rotated api key: %w", err)
+	}
+	u.keys.Delete(ctx, old.KeyHash)
+	return created, nil
+}
+
+func (u *developerUsecase) RevokeKey(ctx context.Context, applicationID, keyID, userID string) error {
+	app, err := u.owned(ctx, applicationID, userID)
+	if err != nil {
+		return err
+	}
+
+	key, err := u.repo.GetKey(ctx, app.ID, keyID)
+	if err != nil {
+		return fmt.Errorf("failed to get api key: %w", err)
+	}
+	if err := u.repo.RevokeKey(ctx, app.ID, key.ID, u.now()); err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+	u.keys.Delete(ctx, key.KeyHash)
+	return nil
+}
+
+func (u *developerUsecase) Usage(ctx context.Context, applicationID string, req *domain.UsageRequest, userID string) (*domain.UsageResponse, error) {
+	app, err := u.owned(ctx, applicationID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	to := u.now().UTC().Truncate(24 * time.Hour)
+	if req.To != "" {
+		if to, err = time.Parse(time.DateOnly, req.To); err != nil {
+			return nil, fmt.Errorf("%w: to must be a YYYY-MM-DD date", pkgErrors.ErrInvalidInput)
+		}
+	}
+	from := to.AddDate(0, 0, -(usageDays - 1))
+	if req.From != "" {
+		if from, err = time.Parse(time.DateOnly, req.From); err != nil {
+			return nil, fmt.Errorf("%w: from must be a YYYY-MM-DD date", pkgErrors.ErrInvalidInput)
+		}
+	}
+	if from.After(to) {
+		return nil, fmt.Errorf("%w: from must not be after to", pkgErrors.ErrInvalidInput)
+	}
+	if to.Sub(from) >= 366*24*time.Hour {
+		return nil, fmt.Errorf("%w: usage is read for up to a year at a time", pkgErrors.ErrInvalidInput)
+	}
+
+	keys, err := u.repo.ListKeys(ctx, app.ID)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := u.repo.ListUsage(ctx, app.ID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &domain.UsageResponse{
+		ApplicationID: app.ID,
+		Limits:        u.planInfo(app.Plan),
+		From:          from.Format(time.DateOnly),
+		To:            to.Format(time.DateOnly),
+		Days:          []domain.UsageDay{},
+		Keys:          make([]domain.KeyUsage, len(keys)),
+	}
+	byKey := make(map[string]*domain.KeyUsage, len(keys))
+	for i, key := range keys {
+		resp.Keys[i] = domain.KeyUsage{KeyID: key.ID, Name: key.Name, Prefix: key.Prefix}
+		byKey[key.ID] = &resp.Keys[i]
+	}
+
+	// Usage is ordered by day, so the days are built in order
+	for _, day := range usage {
+		date := day.Day.Format(time.DateOnly)
+		if n := len(resp.Days); n == 0 || resp.Days[n-1].Day != date {
+			resp.Days = append(resp.Days, domain.UsageDay{Day: date})
+		}
+		last := &resp.Days[len(resp.Days)-1]
+		last.Requests += day.Requests
+		last.Throttled += day.Throttled
+		resp.Requests += day.Requests
+		resp.Throttled += day.Throttled
+		if key, ok := byKey[day.KeyID]; ok {
+			key.Requests += day.Requests
+			key.Throttled += day.Throttled
+		}
+	}
+	return resp, nil
+}
+
+func (u *developerUsecase) ListAllApplications(ctx context.Context, req *domain.ListApplicationsRequest) (*domain.ApplicationListResponse, error) {
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.Limit < 1 {
+		req.Limit = 20
+	}
+
+	offset := (req.Page - 1) * req.Limit
+
+	filter := &domain.ApplicationFilter{
+		UserID: req.UserID,
+		Plan:   req.Plan,
+		Search: req.Search,
+	}
+
+	apps, total, err := u.repo.ListApplications(ctx, filter, req.Limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]domain.ApplicationInfo, len(apps))
+	for i, app := range apps {
+		infos[i] = *u.toApplicationInfo(app)
+	}
+
+	totalPage := int(math.Ceil(float64(total) / float64(req.Limit)))
+
+	return &domain.ApplicationListResponse{
+		Applications: infos,
+		Meta: domain.ListMeta{
+			Page:      req.Page,
+			Limit:     req.Limit,
+			Total:     total,
+			TotalPage: totalPage,
+		},
+	}, nil
+}
+
+func (u *developerUsecase) UpdatePlan(ctx context.Context, id string, plan domain.Plan) (*domain.ApplicationInfo, error) {
+	now := u.now()
+	if err := u.repo.UpdatePlan(ctx, id, plan, now); err != nil {
+		return nil, err
+	}
+	app, err := u.repo.GetApplication(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cached clients of the keys carry the limit of the former plan
+	keys, err := u.repo.ListKeys(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([]string, len(keys))
+	for i, key := range keys {
+		hashes[i] = key.KeyHash
+	}
+	u.keys.Delete(ctx, hashes...)
+
+	return u.toApplicationInfo(app), nil
+}
+
+// Authenticate reports every failure to find a live key as unauthorized, so
+// callers cannot tell revoked keys from unknown ones
+func (u *developerUsecase) Authenticate(ctx context.Context, key string) (*domain.Client, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return nil, pkgErrors.ErrUnauthorized
+	}
+
+	hash := hashAPIKey(key)
+	var client domain.Client
+	if !u.keys.Get(ctx, hash, &client) {
+		stored, err := u.repo.GetKeyByHash(ctx, hash)
+		if err != nil {
+			if errors.Is(err, pkgErrors.ErrNotFound) {
+				return nil, pkgErrors.ErrUnauthorized
+			}
+			return nil, fmt.Errorf("failed to get api key: %w", err)
+		}
+		if stored.RevokedAt != nil {
+			return nil, pkgErrors.ErrUnauthorized
+		}
+		app, err := u.repo.GetApplication(ctx, stored.ApplicationID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get application: %w", err)
+		}
+
+		limits := u.planInfo(app.Plan)
+		client = domain.Client{
+			KeyID:         stored.ID,
+			ApplicationID: app.ID,
+			Plan:          app.Plan,
+			RateLimit:     limits.RateLimit,
+			RateWindow:    u.cfg.RateWindow,
+			ExpiresAt:     stored.ExpiresAt,
+		}
+		u.keys.Set(ctx, hash, client)
+	}
+
+	if client.ExpiresAt != nil && !client.ExpiresAt.After(u.now()) {
+		return nil, pkgErrors.ErrUnauthorized
+	}
+	return &client, nil
+}
+
+func (u *developerUsecase) Count(keyID string, throttled bool) {
+	now := u.now().UTC()
+	day := now.Truncate(24 * time.Hour)
+	bucket := keyID + "|" + day.Format(time.DateOnly)
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	usage, ok := u.usage[bucket]
+	if !ok {
+		usage = &domain.Usage{KeyID: keyID, Day: day}
+		u.usage[bucket] = usage
+	}
+	usage.Requests++
+	if throttled {
+		usage.Throttled++
+	}
+	usage.LastUsedAt = now
+}
+
+func (u *developerUsecase) Run(ctx context.Context) {
+	ticker := time.NewTicker(u.cfg.UsageFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Write what was counted since the last flush before stopping
+			u.flush(context.WithoutCancel(ctx))
+			return
+		case <-ticker.C:
+			u.flush(ctx)
+		}
+	}
+}
+
+// flush writes the counted usage. Counts that fail to be written are kept
+// for the next flush.
+func (u *developerUsecase) flush(ctx context.Context) {
+	u.mu.Lock()
+	pending := u.usage
+	u.usage = map[string]*domain.Usage{}
+	u.mu.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
+	usage := make([]*domain.Usage, 0, len(pending))
+	for _, counted := range pending {
+		usage = append(usage, counted)
+	}
+	if err := u.repo.AddUsage(ctx, usage); err != nil {
+		logger.FromContext(ctx).Error("failed to write api key usage: %v", err)
+		u.mu.Lock()
+		for bucket, counted := range pending {
+			if current, ok := u.usage[bucket]; ok {
+				current.Requests += counted.Requests
+				current.Throttled += counted.Throttled
+				continue
+			}
+			u.usage[bucket] = counted
+		}
+		u.mu.Unlock()
+	}
+}
+
+// owned returns the application of a developer. Applications of other
+// developers are reported as not found.
+func (u *developerUsecase) owned(ctx context.Context, id, userID string) (*domain.Application, error) {
+	app, err := u.repo.GetApplication(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if app.UserID != userID {
+		return nil, fmt.Errorf("application not found: %w", pkgErrors.ErrNotFound)
+	}
+	return app, nil
+}
+
+func (u *developerUsecase) checkKeyLimit(ctx context.Context, applicationID string, now time.Time) error {
+	count, err := u.repo.CountLiveKeys(ctx, applicationID, now)
+	if err != nil {
+		return err
+	}
+	if count >= u.cfg.MaxKeys {
+		return fmt.Errorf("%w: an application may have up to %d live keys", pkgErrors.ErrInvalidInput, u.cfg.MaxKeys)
+	}
+	return nil
+}
+
+func (u *developerUsecase) issueKey(ctx context.Context, applicationID, name string, expiresAt *time.Time, now time.Time) (*domain.APIKeyCreated, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate api key: %w", err)
+	}
+	raw := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	key := &domain.APIKey{
+		ID:            uuid.New().String(),
+		ApplicationID: applicationID,
+		Name:          name,
+		KeyHash:       hashAPIKey(raw),
+		Prefix:        raw[:len(apiKeyPrefix)+6],
+		ExpiresAt:     expiresAt,
+		CreatedAt:     now,
+	}
+
+	if err := u.repo.CreateKey(ctx, key); err != nil {
+		return nil, err
+	}
+	return &domain.APIKeyCreated{APIKeyInfo: *toAPIKeyInfo(key), Key: raw}, nil
+}
+
+// planInfo returns the limits of plan; unknown plans get those of the free plan
+func (u *developerUsecase) planInfo(plan string) domain.PlanInfo {
+	limit := u.cfg.FreeRateLimit
+	if plan == string(domain.PlanPartner) {
+		limit = u.cfg.PartnerRateLimit
+	}
+	if limit < 0 {
+		limit = 0
+	}
+	return domain.PlanInfo{Name: plan, RateLimit: limit, RateWindowSeconds: int(u.cfg.RateWindow.Seconds())}
+}
+
+func (u *developerUsecase) toApplicationInfo(app *domain.Application) *domain.ApplicationInfo {
+	return &domain.ApplicationInfo{Application: *app, Limits: u.planInfo(app.Plan)}
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func toAPIKeyInfo(key *domain.APIKey) *domain.APIKeyInfo {
+	return &domain.APIKeyInfo{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		LastUsedAt: key.LastUsedAt,
+		ExpiresAt:  key.ExpiresAt,
+		RevokedAt:  key.RevokedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
@@ -0,0 +1,288 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/internal/developer/domain"
+	"portal-data-backend/internal/developer/usecase"
+	pkgerrors "portal-data-backend/pkg/errors"
+)
+
+// mockRepository is an in-memory implementation of Repository
+type mockRepository struct {
+	apps  map[string]*domain.Application
+	keys  map[string]*domain.APIKey
+	usage map[string]*domain.Usage
+}
+
+func newMockRepository() *mockRepository {
+	return &mockRepository{apps: map[string]*domain.Application{}, keys: map[string]*domain.APIKey{}, usage: map[string]*domain.Usage{}}
+}
+
+func (m *mockRepository) GetApplication(ctx context.Context, id string) (*domain.Application, error) {
+	app, ok := m.apps[id]
+	if !ok {
+		return nil, pkgerrors.ErrNotFound
+	}
+	copied := *app
+	return &copied, nil
+}
+
+func (m *mockRepository) ListApplications(ctx context.Context, filter *domain.ApplicationFilter, limit, offset int) ([]*domain.Application, int, error) {
+	var apps []*domain.Application
+	for _, app := range m.apps {
+		if filter.UserID == nil || app.UserID == *filter.UserID {
+			apps = append(apps, app)
+		}
+	}
+	return apps, len(apps), nil
+}
+
+func (m *mockRepository) CreateApplication(ctx context.Context, app *domain.Application) error {
+	m.apps[app.ID] = app
+	return nil
+}
+
+func (m *mockRepository) UpdateApplication(ctx context.Context, app *domain.Application) error {
+	m.apps[app.ID] = app
+	return nil
+}
+
+func (m *mockRepository) UpdatePlan(ctx context.Context, id string, plan domain.Plan, at time.Time) error {
+	app, ok := m.apps[id]
+	if !ok {
+		return pkgerrors.ErrNotFound
+	}
+	app.Plan = string(plan)
+	return nil
+}
+
+func (m *mockRepository) CountApplications(ctx context.Context, userID string) (int, error) {
+	_, count, err := m.ListApplications(ctx, &domain.ApplicationFilter{UserID: &userID}, 0, 0)
+	return count, err
+}
+
+func (m *mockRepository) CreateKey(ctx context.Context, key *domain.APIKey) error {
+	m.keys[key.ID] = key
+	return nil
+}
+
+func (m *mockRepository) GetKey(ctx context.Context, applicationID, id string) (*domain.APIKey, error) {
+	key, ok := m.keys[id]
+	if !ok || key.ApplicationID != applicationID {
+		return nil, pkgerrors.ErrNotFound
+	}
+	return key, nil
+}
+
+func (m *mockRepository) GetKeyByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	for _, key := range m.keys {
+		if key.KeyHash == keyHash {
+			return key, nil
+		}
+	}
+	return nil, pkgerrors.ErrNotFound
+}
+
+func (m *mockRepository) ListKeys(ctx context.Context, applicationID string) ([]*domain.APIKey, error) {
+	var keys []*domain.APIKey
+	for _, key := range m.keys {
+		if key.ApplicationID == applicationID {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (m *mockRepository) CountLiveKeys(ctx context.Context, applicationID string, now time.Time) (int, error) {
+	count := 0
+	for _, key := range m.keys {
+		if key.ApplicationID == applicationID && key.Live(now) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *mockRepository) RevokeKey(ctx context.Context, applicationID, id string, at time.Time) error {
+	key, err := m.GetKey(ctx, applicationID, id)
+	if err != nil {
+		return err
+	}
+	key.RevokedAt = &at
+	return nil
+}
+
+func (m *mockRepository) ExpireKey(ctx context.Context, applicationID, id string, at time.Time) error {
+	key, err := m.GetKey(ctx, applicationID, id)
+	if err != nil {
+		return err
+	}
+	key.ExpiresAt = &at
+	return nil
+}
+
+func (m *mockRepository) AddUsage(ctx context.Context, usage []*domain.Usage) error {
+	for _, u := range usage {
+		bucket := u.KeyID + "|" + u.Day.Format(time.DateOnly)
+		stored, ok := m.usage[bucket]
+		if !ok {
+			stored = &domain.Usage{KeyID: u.KeyID, Day: u.Day}
+			m.usage[bucket] = stored
+		}
+		stored.Requests += u.Requests
+		stored.Throttled += u.Throttled
+	}
+	return nil
+}
+
+func (m *mockRepository) ListUsage(ctx context.Context, applicationID string, from, to time.Time) ([]*domain.Usage, error) {
+	var usage []*domain.Usage
+	for _, u := range m.usage {
+		if m.keys[u.KeyID].ApplicationID == applicationID && !u.Day.Before(from) && !u.Day.After(to) {
+			usage = append(usage, u)
+		}
+	}
+	sort.Slice(usage, func(i, j int) bool { return usage[i].Day.Before(usage[j].Day) })
+	return usage, nil
+}
+
+func newDeveloperUsecase(repo *mockRepository, grace time.Duration) usecase.Usecase {
+	return usecase.NewDeveloperUsecase(repo, nil, config.DeveloperConfig{
+		FreeRateLimit:      60,
+		PartnerRateLimit:   600,
+		RateWindow:         time.Minute,
+		MaxApplications:    2,
+		MaxKeys:            2,
+		RotationGrace:      grace,
+		UsageFlushInterval: time.Minute,
+	})
+}
+
+// Test developers register applications and authenticate with their keys
+// at the limit of the plan of the application
+func TestDeveloper_KeysFollowPlan(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	u := newDeveloperUsecase(repo, time.Hour)
+
+	app, err := u.RegisterApplication(ctx, &domain.RegisterApplicationRequest{Name: "Weather app"}, "user-1")
+	if err != nil {
+		t.Fatalf("Expected the application to be registered, got %v", err)
+	}
+	if app.Plan != string(domain.PlanFree) || app.Limits.RateLimit != 60 {
+		t.Errorf("Expected a free application limited to 60 requests, got %s and %d", app.Plan, app.Limits.RateLimit)
+	}
+
+	created, err := u.CreateKey(ctx, app.ID, &domain.CreateAPIKeyRequest{Name: "production"}, "user-1")
+	if err != nil {
+		t.Fatalf("Expected the key to be created, got %v", err)
+	}
+	client, err := u.Authenticate(ctx, created.Key)
+	if err != nil {
+		t.Fatalf("Expected the key to authenticate, got %v", err)
+	}
+	if client.ApplicationID != app.ID || client.RateLimit != 60 {
+		t.Errorf("Expected the client of the application at the free limit, got %+v", client)
+	}
+
+	if _, err := u.UpdatePlan(ctx, app.ID, domain.PlanPartner); err != nil {
+		t.Fatalf("Expected the plan to be updated, got %v", err)
+	}
+	client, _ = u.Authenticate(ctx, created.Key)
+	if client == nil || client.RateLimit != 600 {
+		t.Errorf("Expected the partner limit after the plan changed, got %+v", client)
+	}
+
+	if _, err := u.Authenticate(ctx, "pdk_unknown"); !errors.Is(err, pkgerrors.ErrUnauthorized) {
+		t.Errorf("Expected an unknown key to be unauthorized, got %v", err)
+	}
+	if _, err := u.ListKeys(ctx, app.ID, "user-2"); !errors.Is(err, pkgerrors.ErrNotFound) {
+		t.Errorf("Expected another developer not to find the application, got %v", err)
+	}
+}
+
+// Test rotated keys keep working for the grace period, and revoked keys stop
+// at once
+func TestDeveloper_RotateAndRevoke(t *testing.T) {
+	ctx := context.Background()
+
+	for name, grace := range map[string]time.Duration{"with grace": time.Hour, "without grace": 0} {
+		repo := newMockRepository()
+		u := newDeveloperUsecase(repo, grace)
+		app, _ := u.RegisterApplication(ctx, &domain.RegisterApplicationRequest{Name: "Weather app"}, "user-1")
+		old, _ := u.CreateKey(ctx, app.ID, &domain.CreateAPIKeyRequest{Name: "production"}, "user-1")
+
+		rotated, err := u.RotateKey(ctx, app.ID, old.ID, "user-1")
+		if err != nil {
+			t.Fatalf("Expected the key to rotate %s, got %v", name, err)
+		}
+		if rotated.Key == old.Key || rotated.Name != "production" {
+			t.Errorf("Expected a new key with the same name %s, got %+v", name, rotated.APIKeyInfo)
+		}
+		if _, err := u.Authenticate(ctx, rotated.Key); err != nil {
+			t.Errorf("Expected the new key to authenticate %s, got %v", name, err)
+		}
+		_, err = u.Authenticate(ctx, old.Key)
+		if grace > 0 && err != nil {
+			t.Errorf("Expected the old key to work during the grace period, got %v", err)
+		}
+		if grace == 0 && !errors.Is(err, pkgerrors.ErrUnauthorized) {
+			t.Errorf("Expected the old key to stop without grace, got %v", err)
+		}
+
+		if err := u.RevokeKey(ctx, app.ID, rotated.ID, "user-1"); err != nil {
+			t.Fatalf("Expected the key to be revoked %s, got %v", name, err)
+		}
+		if _, err := u.Authenticate(ctx, rotated.Key); !errors.Is(err, pkgerrors.ErrUnauthorized) {
+			t.Errorf("Expected the revoked key to be unauthorized %s, got %v", name, err)
+		}
+	}
+}
+
+// Test counted requests are written on flush and summed per day and key
+func TestDeveloper_Usage(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	u := newDeveloperUsecase(repo, time.Hour)
+
+	app, _ := u.RegisterApplication(ctx, &domain.RegisterApplicationRequest{Name: "Weather app"}, "user-1")
+	first, _ := u.CreateKey(ctx, app.ID, &domain.CreateAPIKeyRequest{Name: "production"}, "user-1")
+	second, _ := u.CreateKey(ctx, app.ID, &domain.CreateAPIKeyRequest{Name: "staging"}, "user-1")
+	if _, err := u.CreateKey(ctx, app.ID, &domain.CreateAPIKeyRequest{Name: "one too many"}, "user-1"); !errors.Is(err, pkgerrors.ErrInvalidInput) {
+		t.Errorf("Expected keys over the limit to be refused, got %v", err)
+	}
+
+	u.Count(first.ID, false)
+	u.Count(first.ID, false)
+	u.Count(first.ID, true)
+	u.Count(second.ID, false)
+
+	// Run writes the counts when it stops
+	stopped, cancel := context.WithCancel(ctx)
+	cancel()
+	u.Run(stopped)
+
+	usage, err := u.Usage(ctx, app.ID, &domain.UsageRequest{}, "user-1")
+	if err != nil {
+		t.Fatalf("Expected the usage, got %v", err)
+	}
+	if usage.Requests != 4 || usage.Throttled != 1 || len(usage.Days) != 1 {
+		t.Errorf("Expected 4 requests with 1 throttled on one day, got %d, %d and %d days", usage.Requests, usage.Throttled, len(usage.Days))
+	}
+	for _, key := range usage.Keys {
+		want := map[string]int64{first.ID: 3, second.ID: 1}[key.KeyID]
+		if key.Requests != want {
+			t.Errorf("Expected %d requests of key %s, got %d", want, key.Name, key.Requests)
+		}
+	}
+
+	if _, err := u.Usage(ctx, app.ID, &domain.UsageRequest{From: "2026-02-01", To: "2026-01-01"}, "user-1"); !errors.Is(err, pkgerrors.ErrInvalidInput) {
+		t.Errorf("Expected a reversed period to be invalid, got %v", err)
+	}
+}
@@ -1,42 +1,42 @@
 package http
 
 import (
-	"encoding/json"
-	"errors"
+	"net"
 	"net/http"
 	"strconv"
 
+	"portal-data-backend/infrastructure/auth"
 	fbDomain "portal-data-backend/internal/feedback/domain"
 	"portal-data-backend/internal/feedback/usecase"
+	"portal-data-backend/infrastructure/http/httputil"
+	"portal-data-backend/infrastructure/http/middleware"
+	"portal-data-backend/infrastructure/http/problem"
 	"portal-data-backend/infrastructure/http/response"
+	roleDomain "portal-data-backend/internal/role/domain"
 	pkgErrors "portal-data-backend/pkg/errors"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/go-playground/validator/v10"
 )
 
 type Handler struct {
 	fbUsecase usecase.Usecase
-	validator  *validator.Validate
 }
 
 func NewHandler(fbUsecase usecase.Usecase) *Handler {
 	return &Handler{
 		fbUsecase: fbUsecase,
-		validator:  validator.New(),
 	}
 }
 
 func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Feedback ID is required", nil)
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
 	fb, err := h.fbUsecase.GetByID(r.Context(), id)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -62,10 +62,16 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 	if status := r.URL.Query().Get("status"); status != "" {
 		req.Status = &status
 	}
+	if isPublic, err := strconv.ParseBool(r.URL.Query().Get("is_public")); err == nil {
+		req.IsPublic = &isPublic
+	}
+	if moderationStatus := r.URL.Query().Get("moderation_status"); moderationStatus != "" {
+		req.ModerationStatus = &moderationStatus
+	}
 
 	resp, err := h.fbUsecase.List(r.Context(), req)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -73,49 +79,93 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
-	var req fbDomain.CreateFeedbackRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, response.CodeBadRequest, "Invalid request body", nil)
+	req, ok := httputil.Decode[fbDomain.CreateFeedbackRequest](w, r)
+	if !ok {
 		return
 	}
 
-	if err := h.validator.Struct(&req); err != nil {
-		response.ValidationError(w, response.CodeValidationFailed, "Validation failed", h.formatValidationErrors(err))
+	// Get user ID from context
+	userID := auth.UserID(r.Context())
+
+	fb, err := h.fbUsecase.Create(r.Context(), req, userID)
+	if err != nil {
+		h.handleError(w, r, err)
 		return
 	}
 
-	// Get user ID from context
-	userID, _ := r.Context().Value("user_id").(string)
+	response.Created(w, response.CodeCreated, "Feedback created successfully", fb)
+}
+
+// CreateAnonymous accepts feedback from a visitor who is not signed in. It is
+// published once the visitor confirms their email and a moderator approves it.
+func (h *Handler) CreateAnonymous(w http.ResponseWriter, r *http.Request) {
+	req, ok := httputil.Decode[fbDomain.CreateAnonymousFeedbackRequest](w, r)
+	if !ok {
+		return
+	}
 
-	fb, err := h.fbUsecase.Create(r.Context(), &req, userID)
+	remoteIP, _, err := net.SplitHostPort(r.RemoteAddr)
 	if err != nil {
-		h.handleError(w, err)
+		remoteIP = r.RemoteAddr
+	}
+
+	if err := h.fbUsecase.CreateAnonymous(r.Context(), req, remoteIP); err != nil {
+		h.handleError(w, r, err)
 		return
 	}
 
-	response.Created(w, response.CodeCreated, "Feedback created successfully", fb)
+	response.JSON(w, http.StatusAccepted, response.CodeSuccess, "Feedback received, please confirm it through the link sent to your email", nil)
 }
 
-func (h *Handler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Feedback ID is required", nil)
+func (h *Handler) Confirm(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		response.BadRequest(w, response.CodeBadRequest, "Confirmation token is required", nil)
+		return
+	}
+
+	if err := h.fbUsecase.Confirm(r.Context(), token); err != nil {
+		h.handleError(w, r, err)
 		return
 	}
 
-	var req fbDomain.UpdateFeedbackStatusRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, response.CodeBadRequest, "Invalid request body", nil)
+	response.OK(w, response.CodeSuccess, "Feedback confirmed, it will be published after moderation", nil)
+}
+
+func (h *Handler) Moderate(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
-	if err := h.validator.Struct(&req); err != nil {
-		response.ValidationError(w, response.CodeValidationFailed, "Validation failed", h.formatValidationErrors(err))
+	req, ok := httputil.Decode[fbDomain.ModerateFeedbackRequest](w, r)
+	if !ok {
+		return
+	}
+
+	userID := auth.UserID(r.Context())
+
+	if err := h.fbUsecase.Moderate(r.Context(), id, req.Status, userID); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Feedback moderated successfully", nil)
+}
+
+func (h *Handler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	req, ok := httputil.Decode[fbDomain.UpdateFeedbackStatusRequest](w, r)
+	if !ok {
 		return
 	}
 
 	if err := h.fbUsecase.UpdateStatus(r.Context(), id, req.Status); err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -123,57 +173,111 @@ func (h *Handler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Feedback ID is required", nil)
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
 	if err := h.fbUsecase.Delete(r.Context(), id); err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
 	response.OK(w, response.CodeSuccess, "Feedback deleted successfully", nil)
 }
 
-func (h *Handler) handleError(w http.ResponseWriter, err error) {
-	if err == nil {
+// ListPublic lists the feedback published as Q&A, optionally of one dataset
+func (h *Handler) ListPublic(w http.ResponseWriter, r *http.Request) {
+	req := &fbDomain.ListFeedbacksRequest{
+		Page:  parseIntQuery(r, "page", 1),
+		Limit: parseIntQuery(r, "limit", 20),
+	}
+	if datasetID := r.URL.Query().Get("dataset_id"); datasetID != "" {
+		req.DatasetID = &datasetID
+	}
+	if category := r.URL.Query().Get("category"); category != "" {
+		req.Category = &category
+	}
+
+	resp, err := h.fbUsecase.ListPublic(r.Context(), req)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Feedbacks retrieved successfully", resp)
+}
+
+func (h *Handler) Reply(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
-	switch {
-	case errors.Is(err, pkgErrors.ErrNotFound):
-		response.NotFound(w, response.CodeNotFound, "Feedback not found", nil)
-	default:
-		response.InternalError(w, response.CodeInternalServerError, "Internal server error", nil)
+	req, ok := httputil.Decode[fbDomain.CreateFeedbackReplyRequest](w, r)
+	if !ok {
+		return
 	}
+
+	userID := auth.UserID(r.Context())
+
+	reply, err := h.fbUsecase.Reply(r.Context(), id, req, userID)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.Created(w, response.CodeCreated, "Feedback reply created successfully", reply)
 }
 
-func (h *Handler) formatValidationErrors(err error) []response.ErrorDetail {
-	var details []response.ErrorDetail
-	if validationErrors, ok := err.(validator.ValidationErrors); ok {
-		for _, fieldErr := range validationErrors {
-			details = append(details, response.ErrorDetail{
-				Field:   fieldErr.Field(),
-				Message: h.getValidationErrorMessage(fieldErr),
-			})
-		}
+func (h *Handler) Resolve(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
 	}
-	return details
+
+	req, ok := httputil.Decode[fbDomain.ResolveFeedbackRequest](w, r)
+	if !ok {
+		return
+	}
+
+	userID := auth.UserID(r.Context())
+
+	fb, err := h.fbUsecase.Resolve(r.Context(), id, req, userID)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Feedback resolved successfully", fb)
 }
 
-func (h *Handler) getValidationErrorMessage(fieldErr validator.FieldError) string {
-	switch fieldErr.Tag() {
-	case "required":
-		return fieldErr.Field() + " is required"
-	case "min":
-		return fieldErr.Field() + " must be at least " + fieldErr.Param() + " characters"
-	case "max":
-		return fieldErr.Field() + " must be at most " + fieldErr.Param() + " characters"
-	default:
-		return fieldErr.Field() + " is invalid"
+func (h *Handler) UpdateVisibility(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	req, ok := httputil.Decode[fbDomain.UpdateFeedbackVisibilityRequest](w, r)
+	if !ok {
+		return
 	}
+
+	if err := h.fbUsecase.UpdateVisibility(r.Context(), id, *req.IsPublic); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Feedback visibility updated successfully", nil)
+}
+
+// errorMapper maps the errors of the feedback module on top of the shared ones
+var errorMapper = problem.Default.With(
+	problem.Mapping{Err: pkgErrors.ErrNotFound, Status: http.StatusNotFound, Code: response.CodeNotFound, Message: "Feedback not found"},
+)
+
+func (h *Handler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	errorMapper.Write(w, r, err)
 }
 
 func parseIntQuery(r *http.Request, key string, defaultValue int) int {
@@ -185,12 +289,31 @@ func parseIntQuery(r *http.Request, key string, defaultValue int) int {
 	return defaultValue
 }
 
-func RegisterRoutes(r chi.Router, handler *Handler) {
+// RegisterRoutes registers feedback routes. The public Q&A, email
+// confirmation and anonymous submissions are open to visitors, with anonymous
+// submissions going through submitLimit, which should rate limit them. The
+// other routes go through auth; replying to, resolving, deleting and changing
+// the status of feedback and publishing it as Q&A require the feedback:write
+// permission, and moderating it one of moderatorRoles, like the moderation
+// queue.
+func RegisterRoutes(r chi.Router, handler *Handler, auth, submitLimit func(http.Handler) http.Handler, moderatorRoles []string) {
 	r.Route("/feedbacks", func(r chi.Router) {
-		r.Get("/", handler.List)
-		r.Post("/", handler.Create)
-		r.Get("/{id}", handler.GetByID)
-		r.Patch("/{id}/status", handler.UpdateStatus)
-		r.Delete("/{id}", handler.Delete)
+		r.Get("/public", handler.ListPublic)
+		r.Get("/confirm", handler.Confirm)
+		r.With(submitLimit).Post("/anonymous", handler.CreateAnonymous)
+
+		r.Group(func(r chi.Router) {
+			r.Use(auth)
+			r.Get("/", handler.List)
+			r.Post("/", handler.Create)
+			r.Get("/{id}", handler.GetByID)
+			write := middleware.RequirePermission(roleDomain.PermissionFeedbackWrite)
+			r.With(write).Patch("/{id}/status", handler.UpdateStatus)
+			r.With(write).Post("/{id}/replies", handler.Reply)
+			r.With(write).Patch("/{id}/resolve", handler.Resolve)
+			r.With(write).Patch("/{id}/visibility", handler.UpdateVisibility)
+			r.With(middleware.RequireRole(moderatorRoles...)).Patch("/{id}/moderation", handler.Moderate)
+			r.With(write).Delete("/{id}", handler.Delete)
+		})
 	})
 }
@@ -0,0 +1,30 @@
+package http
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	fbDomain "portal-data-backend/internal/feedback/domain"
+)
+
+// Describe adds the feedback routes to the API specification
+func Describe(spec *openapi.Spec) {
+	api := spec.Tag("feedbacks", "Feedback on datasets and the portal")
+	api.Get("/feedbacks", "List feedback").
+		Query(fbDomain.ListFeedbacksRequest{}, "page", "limit", "dataset_id", "category", "status", "is_public", "moderation_status", "search", "sort_by", "sort_order").
+		Returns(http.StatusOK, fbDomain.FeedbackListResponse{})
+	api.Post("/feedbacks", "Create feedback").Body(fbDomain.CreateFeedbackRequest{}).Returns(http.StatusCreated, fbDomain.FeedbackResponse{})
+	api.Get("/feedbacks/{id}", "Get feedback").Returns(http.StatusOK, fbDomain.FeedbackResponse{})
+	api.Patch("/feedbacks/{id}/status", "Update feedback status").Body(fbDomain.UpdateFeedbackStatusRequest{}).Returns(http.StatusOK, nil)
+	api.Post("/feedbacks/{id}/replies", "Reply to feedback").Body(fbDomain.CreateFeedbackReplyRequest{}).Returns(http.StatusCreated, fbDomain.FeedbackReplyResponse{})
+	api.Patch("/feedbacks/{id}/resolve", "Resolve feedback").Body(fbDomain.ResolveFeedbackRequest{}).Returns(http.StatusOK, fbDomain.FeedbackResponse{})
+	api.Patch("/feedbacks/{id}/visibility", "Show or hide feedback as public Q&A").Body(fbDomain.UpdateFeedbackVisibilityRequest{}).Returns(http.StatusOK, nil)
+	api.Patch("/feedbacks/{id}/moderation", "Moderate feedback").Body(fbDomain.ModerateFeedbackRequest{}).Returns(http.StatusOK, nil)
+	api.Delete("/feedbacks/{id}", "Delete feedback").Returns(http.StatusOK, nil)
+
+	api.Get("/feedbacks/public", "List public Q&A").Public().
+		Query(fbDomain.ListFeedbacksRequest{}, "page", "limit", "dataset_id", "category").
+		Returns(http.StatusOK, fbDomain.FeedbackListResponse{})
+	api.Get("/feedbacks/confirm", "Confirm anonymous feedback").Public().Param("token", true).Returns(http.StatusOK, nil)
+	api.Post("/feedbacks/anonymous", "Submit anonymous feedback").Public().Body(fbDomain.CreateAnonymousFeedbackRequest{}).Returns(http.StatusAccepted, nil)
+}
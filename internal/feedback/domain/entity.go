@@ -7,7 +7,7 @@ import (
 // Feedback represents user feedback
 type Feedback struct {
 	ID          string       `db:"id" json:"id"`
-	UserID      string       `db:"user_id" json:"user_id"`
+	UserID      *string      `db:"user_id" json:"user_id,omitempty"` // nil for anonymous feedback
 	DatasetID   *string      `db:"dataset_id" json:"dataset_id,omitempty"`
 	Rating      int          `db:"rating" json:"rating" validate:"min=1,max=5"`
 	Comment     string       `db:"comment" json:"comment"`
@@ -15,6 +15,26 @@ type Feedback struct {
 	Status      FeedbackStatus `db:"status" json:"status"`
 	CreatedAt   time.Time    `db:"created_at" json:"created_at"`
 	UpdatedAt   time.Time    `db:"updated_at" json:"updated_at"`
+	ResolutionNote        *string          `db:"resolution_note" json:"resolution_note,omitempty"`
+	ResolvedBy            *string          `db:"resolved_by" json:"resolved_by,omitempty"`
+	ResolvedAt            *time.Time       `db:"resolved_at" json:"resolved_at,omitempty"`
+	IsPublic              bool             `db:"is_public" json:"is_public"` // shown as Q&A on the dataset page
+	Email                 *string          `db:"email" json:"email,omitempty"` // submitter of anonymous feedback
+	ModerationStatus      ModerationStatus `db:"moderation_status" json:"moderation_status"`
+	ConfirmationTokenHash *string          `db:"confirmation_token_hash" json:"-"`
+	ConfirmationExpiresAt *time.Time       `db:"confirmation_expires_at" json:"-"`
+}
+
+// ContentTypeFeedback is the content type feedback comments are screened as
+const ContentTypeFeedback = "feedback"
+
+// FeedbackReply represents a reply to feedback, visible to its submitter
+type FeedbackReply struct {
+	ID         string    `db:"id" json:"id"`
+	FeedbackID string    `db:"feedback_id" json:"feedback_id"`
+	UserID     string    `db:"user_id" json:"user_id"`
+	Message    string    `db:"message" json:"message"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
 }
 
 // FeedbackCategory represents feedback category
@@ -38,6 +58,18 @@ const (
 	FeedbackStatusClosed   FeedbackStatus = "closed"
 )
 
+// ModerationStatus represents how far feedback is through moderation.
+// Anonymous feedback waits for its submitter to confirm their email, then for
+// a moderator; only approved feedback is visible.
+type ModerationStatus string
+
+const (
+	ModerationStatusUnconfirmed ModerationStatus = "unconfirmed"
+	ModerationStatusPending     ModerationStatus = "pending"
+	ModerationStatusApproved    ModerationStatus = "approved"
+	ModerationStatusRejected    ModerationStatus = "rejected"
+)
+
 // CreateFeedbackRequest represents feedback creation input
 type CreateFeedbackRequest struct {
 	DatasetID *string            `json:"dataset_id,omitempty"`
@@ -51,6 +83,38 @@ type UpdateFeedbackStatusRequest struct {
 	Status FeedbackStatus `json:"status" validate:"required"`
 }
 
+// CreateAnonymousFeedbackRequest represents feedback input from a visitor
+// who is not signed in. Website is a honeypot that people leave empty.
+type CreateAnonymousFeedbackRequest struct {
+	DatasetID    string           `json:"dataset_id" validate:"required"`
+	Email        string           `json:"email" validate:"required,email,max=254"`
+	Rating       int              `json:"rating" validate:"required,min=1,max=5"`
+	Comment      string           `json:"comment" validate:"required,min=10,max=1000"`
+	Category     FeedbackCategory `json:"category" validate:"required"`
+	CaptchaToken string           `json:"captcha_token,omitempty"`
+	Website      string           `json:"website,omitempty"`
+}
+
+// ModerateFeedbackRequest represents a moderation decision
+type ModerateFeedbackRequest struct {
+	Status ModerationStatus `json:"status" validate:"required,oneof=approved rejected"`
+}
+
+// CreateFeedbackReplyRequest represents feedback reply input
+type CreateFeedbackReplyRequest struct {
+	Message string `json:"message" validate:"required,min=2,max=2000"`
+}
+
+// ResolveFeedbackRequest represents feedback resolution input
+type ResolveFeedbackRequest struct {
+	ResolutionNote string `json:"resolution_note" validate:"required,min=2,max=2000"`
+}
+
+// UpdateFeedbackVisibilityRequest represents feedback visibility update
+type UpdateFeedbackVisibilityRequest struct {
+	IsPublic *bool `json:"is_public" validate:"required"`
+}
+
 // ListFeedbacksRequest represents list feedbacks input
 type ListFeedbacksRequest struct {
 	Page       int                `json:"page" validate:"min=1"`
@@ -59,6 +123,8 @@ type ListFeedbacksRequest struct {
 	Category   *string             `json:"category,omitempty"`
 	Status     *string             `json:"status,omitempty"`
 	UserID     *string             `json:"user_id,omitempty"`
+	IsPublic   *bool               `json:"is_public,omitempty"`
+	ModerationStatus *string           `json:"moderation_status,omitempty"` // approved when not set
 	Search     string             `json:"search,omitempty"`
 	SortBy     string             `json:"sort_by,omitempty"`
 	SortOrder  string             `json:"sort_order,omitempty"`
@@ -67,7 +133,7 @@ type ListFeedbacksRequest struct {
 // FeedbackResponse represents feedback response
 type FeedbackResponse struct {
 	ID        string            `json:"id"`
-	UserID    string            `json:"user_id"`
+	UserID    *string           `json:"user_id,omitempty"`
 	DatasetID *string           `json:"dataset_id,omitempty"`
 	Rating    int               `json:"rating"`
 	Comment   string            `json:"comment"`
@@ -75,6 +141,21 @@ type FeedbackResponse struct {
 	Status    string            `json:"status"`
 	CreatedAt time.Time         `json:"created_at"`
 	UpdatedAt time.Time         `json:"updated_at"`
+	ResolutionNote   *string                 `json:"resolution_note,omitempty"`
+	ResolvedBy       *string                 `json:"resolved_by,omitempty"`
+	ResolvedAt       *time.Time              `json:"resolved_at,omitempty"`
+	IsPublic         bool                    `json:"is_public"`
+	Email            *string                 `json:"email,omitempty"`
+	ModerationStatus string                  `json:"moderation_status"`
+	Replies          []FeedbackReplyResponse `json:"replies,omitempty"`
+}
+
+// FeedbackReplyResponse represents feedback reply response
+type FeedbackReplyResponse struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 // FeedbackListResponse represents paginated feedback list
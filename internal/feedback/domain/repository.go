@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"time"
 )
 
 type Repository interface {
@@ -9,13 +10,25 @@ type Repository interface {
 	List(ctx context.Context, filter *FeedbackFilter, limit, offset int, sortBy, sortOrder string) ([]*Feedback, int, error)
 	Create(ctx context.Context, feedback *Feedback) error
 	UpdateStatus(ctx context.Context, id string, status FeedbackStatus) error
+	Resolve(ctx context.Context, id, note, resolvedBy string) error
+	UpdateVisibility(ctx context.Context, id string, isPublic bool) error
+	// Confirm moves unconfirmed feedback with an unexpired token to moderation
+	// and returns its ID
+	Confirm(ctx context.Context, tokenHash string, now time.Time) (string, error)
+	UpdateModeration(ctx context.Context, id string, status ModerationStatus) error
 	Delete(ctx context.Context, id string) error
+
+	CreateReply(ctx context.Context, reply *FeedbackReply) error
+	// ListReplies returns the replies to the given feedback, oldest first
+	ListReplies(ctx context.Context, feedbackIDs []string) ([]*FeedbackReply, error)
 }
 
 type FeedbackFilter struct {
-	DatasetID *string
-	Category  *string
-	Status    *string
-	UserID    *string
-	Search    string
+	DatasetID        *string
+	Category         *string
+	Status           *string
+	UserID           *string
+	IsPublic         *bool
+	ModerationStatus *string
+	Search           string
 }
@@ -0,0 +1,73 @@
+// Package feedback is the module collecting and moderating feedback on datasets.
+package feedback
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/infrastructure/http/middleware"
+	"portal-data-backend/infrastructure/http/openapi"
+	"portal-data-backend/infrastructure/mail"
+	"portal-data-backend/infrastructure/security"
+	"portal-data-backend/internal/app"
+	delivery "portal-data-backend/internal/feedback/delivery/http"
+	"portal-data-backend/internal/feedback/domain"
+	"portal-data-backend/internal/feedback/repository"
+	"portal-data-backend/internal/feedback/usecase"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Module collects feedback from users and visitors
+type Module struct {
+	handler        *delivery.Handler
+	limiter        *middleware.RateLimiter
+	moderatorRoles []string
+}
+
+// Name implements app.Module
+func (m *Module) Name() string {
+	return "feedback"
+}
+
+// Register implements app.Module
+func (m *Module) Register(deps *app.Deps) error {
+	if deps.Services.Notifications == nil {
+		return app.MissingServiceError("notification")
+	}
+	if deps.Services.Datasets == nil {
+		return app.MissingServiceError("dataset")
+	}
+	if deps.Services.Templates == nil {
+		return app.MissingServiceError("message template")
+	}
+	deps.Services.Templates.Define(usecase.Messages...)
+
+	cfg := deps.Config.Feedback
+	repo := repository.NewFeedbackPostgresRepository(deps.DB)
+	mailSender := mail.NewSender(deps.Config.Mail)
+	captchaVerifier := security.NewCaptchaVerifier(cfg.CaptchaVerifyURL, cfg.CaptchaSecret)
+	feedbacks := usecase.NewFeedbackUsecase(repo, deps.Tx, deps.Services.Notifications, deps.Services.Datasets, deps.Services.Moderation, mailSender, deps.Services.Templates, captchaVerifier, cfg)
+	m.handler = delivery.NewHandler(feedbacks)
+	m.moderatorRoles = deps.Config.Moderation.ModeratorRoles
+	if deps.Services.Moderation != nil {
+		deps.Services.Moderation.Handle(domain.ContentTypeFeedback, feedbacks.ApplyModeration)
+	}
+
+	// The submission rate limit follows configuration reloads
+	m.limiter = middleware.NewRateLimiter(cfg.RateLimit, cfg.RateWindow)
+	deps.Reloader.Subscribe(func(cfg *config.Config) {
+		m.limiter.Set(cfg.Feedback.RateLimit, cfg.Feedback.RateWindow)
+	})
+	return nil
+}
+
+// Routes implements app.Module
+func (m *Module) Routes(r chi.Router, auth func(http.Handler) http.Handler) {
+	delivery.RegisterRoutes(r, m.handler, auth, m.limiter.Handler, m.moderatorRoles)
+}
+
+// Describe implements app.Module
+func (m *Module) Describe(spec *openapi.Spec) {
+	delivery.Describe(spec)
+}
@@ -2,10 +2,12 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"strings"
 	"time"
 
+	"portal-data-backend/infrastructure/db"
 	"portal-data-backend/internal/feedback/domain"
 	"portal-data-backend/pkg/errors"
 
@@ -22,13 +24,14 @@ func NewFeedbackPostgresRepository(db *sqlx.DB) domain.Repository {
 
 func (r *feedbackPostgresRepository) GetByID(ctx context.Context, id string) (*domain.Feedback, error) {
 	query := `
-		SELECT id, user_id, dataset_id, rating, comment, category, status, created_at, updated_at
+		SELECT id, user_id, dataset_id, rating, comment, category, status, created_at, updated_at,
+		       resolution_note, resolved_by, resolved_at, is_public, email, moderation_status
 		FROM feedbacks
 		WHERE id = $1
 	`
 
 	var feedback domain.Feedback
-	err := r.db.GetContext(ctx, &feedback, query, id)
+	err := db.Conn(ctx, r.db).GetContext(ctx, &feedback, query, id)
 	if err != nil {
 		return nil, r.handleError(err)
 	}
@@ -61,6 +64,16 @@ func (r *feedbackPostgresRepository) List(ctx context.Context, filter *domain.Fe
 			args = append(args, filter.UserID)
 			argCount++
 		}
+		if filter.IsPublic != nil {
+			whereClause += fmt.Sprintf(" AND is_public = $%d", argCount)
+			args = append(args, *filter.IsPublic)
+			argCount++
+		}
+		if filter.ModerationStatus != nil {
+			whereClause += fmt.Sprintf(" AND moderation_status = $%d", argCount)
+			args = append(args, *filter.ModerationStatus)
+			argCount++
+		}
 		if filter.Search != "" {
 			whereClause += fmt.Sprintf(" AND (comment ILIKE $%d)", argCount)
 			searchTerm := "%" + filter.Search + "%"
@@ -71,21 +84,22 @@ func (r *feedbackPostgresRepository) List(ctx context.Context, filter *domain.Fe
 
 	countQuery := "SELECT COUNT(*) FROM feedbacks " + whereClause
 	var total int
-	err := r.db.GetContext(ctx, &total, countQuery, args...)
+	err := db.Conn(ctx, r.db).GetContext(ctx, &total, countQuery, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count feedbacks: %w", err)
 	}
 
 	orderClause := r.buildOrderClause(sortBy, sortOrder)
 	query := `
-		SELECT id, user_id, dataset_id, rating, comment, category, status, created_at, updated_at
+		SELECT id, user_id, dataset_id, rating, comment, category, status, created_at, updated_at,
+		       resolution_note, resolved_by, resolved_at, is_public, email, moderation_status
 		FROM feedbacks
 	` + whereClause + " " + orderClause + " LIMIT $" + fmt.Sprintf("%d", argCount) + " OFFSET $" + fmt.Sprintf("%d", argCount+1)
 
 	args = append(args, limit, offset)
 
 	var feedbacks []*domain.Feedback
-	err = r.db.SelectContext(ctx, &feedbacks, query, args...)
+	err = db.Conn(ctx, r.db).SelectContext(ctx, &feedbacks, query, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to list feedbacks: %w", err)
 	}
@@ -96,13 +110,15 @@ func (r *feedbackPostgresRepository) List(ctx context.Context, filter *domain.Fe
 func (r *feedbackPostgresRepository) Create(ctx context.Context, feedback *domain.Feedback) error {
 	query := `
 		INSERT INTO feedbacks (
-			id, user_id, dataset_id, rating, comment, category, status, created_at, updated_at
+			id, user_id, dataset_id, rating, comment, category, status, created_at, updated_at, is_public,
+			email, moderation_status, confirmation_token_hash, confirmation_expires_at
 		) VALUES (
-			:id, :user_id, :dataset_id, :rating, :comment, :category, :status, :created_at, :updated_at
+			:id, :user_id, :dataset_id, :rating, :comment, :category, :status, :created_at, :updated_at, :is_public,
+			:email, :moderation_status, :confirmation_token_hash, :confirmation_expires_at
 		)
 	`
 
-	_, err := r.db.NamedExecContext(ctx, query, feedback)
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, feedback)
 	if err != nil {
 		return fmt.Errorf("failed to create feedback: %w", err)
 	}
@@ -111,7 +127,7 @@ func (r *feedbackPostgresRepository) Create(ctx context.Context, feedback *domai
 
 func (r *feedbackPostgresRepository) UpdateStatus(ctx context.Context, id string, status domain.FeedbackStatus) error {
 	query := `UPDATE feedbacks SET status = $1, updated_at = $2 WHERE id = $3`
-	result, err := r.db.ExecContext(ctx, query, status, time.Now(), id)
+	result, err := db.Conn(ctx, r.db).ExecContext(ctx, query, status, time.Now(), id)
 	if err != nil {
 		return fmt.Errorf("failed to update feedback status: %w", err)
 	}
@@ -123,9 +139,73 @@ func (r *feedbackPostgresRepository) UpdateStatus(ctx context.Context, id string
 	return nil
 }
 
+func (r *feedbackPostgresRepository) Resolve(ctx context.Context, id, note, resolvedBy string) error {
+	query := `
+		UPDATE feedbacks
+		SET status = $1, resolution_note = $2, resolved_by = $3, resolved_at = $4, updated_at = $4
+		WHERE id = $5
+	`
+	result, err := db.Conn(ctx, r.db).ExecContext(ctx, query, domain.FeedbackStatusResolved, note, resolvedBy, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to resolve feedback: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+func (r *feedbackPostgresRepository) UpdateVisibility(ctx context.Context, id string, isPublic bool) error {
+	query := `UPDATE feedbacks SET is_public = $1, updated_at = $2 WHERE id = $3`
+	result, err := db.Conn(ctx, r.db).ExecContext(ctx, query, isPublic, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to update feedback visibility: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+func (r *feedbackPostgresRepository) Confirm(ctx context.Context, tokenHash string, now time.Time) (string, error) {
+	query := `
+		UPDATE feedbacks
+		SET moderation_status = $1, confirmation_token_hash = NULL, confirmation_expires_at = NULL, updated_at = $2
+		WHERE confirmation_token_hash = $3 AND moderation_status = $4 AND confirmation_expires_at > $2
+		RETURNING id
+	`
+	var id string
+	err := db.Conn(ctx, r.db).GetContext(ctx, &id, query, domain.ModerationStatusPending, now, tokenHash, domain.ModerationStatusUnconfirmed)
+	if err == sql.ErrNoRows {
+		return "", errors.ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to confirm feedback: %w", err)
+	}
+	return id, nil
+}
+
+func (r *feedbackPostgresRepository) UpdateModeration(ctx context.Context, id string, status domain.ModerationStatus) error {
+	query := `UPDATE feedbacks SET moderation_status = $1, updated_at = $2 WHERE id = $3`
+	result, err := db.Conn(ctx, r.db).ExecContext(ctx, query, status, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to update feedback moderation: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
 func (r *feedbackPostgresRepository) Delete(ctx context.Context, id string) error {
 	query := `DELETE FROM feedbacks WHERE id = $1`
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := db.Conn(ctx, r.db).ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete feedback: %w", err)
 	}
@@ -137,6 +217,41 @@ func (r *feedbackPostgresRepository) Delete(ctx context.Context, id string) erro
 	return nil
 }
 
+func (r *feedbackPostgresRepository) CreateReply(ctx context.Context, reply *domain.FeedbackReply) error {
+	query := `
+		INSERT INTO feedback_replies (id, feedback_id, user_id, message, created_at)
+		VALUES (:id, :feedback_id, :user_id, :message, :created_at)
+	`
+
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, reply)
+	if err != nil {
+		return fmt.Errorf("failed to create feedback reply: %w", err)
+	}
+	return nil
+}
+
+func (r *feedbackPostgresRepository) ListReplies(ctx context.Context, feedbackIDs []string) ([]*domain.FeedbackReply, error) {
+	replies := []*domain.FeedbackReply{}
+	if len(feedbackIDs) == 0 {
+		return replies, nil
+	}
+
+	query, args, err := sqlx.In(`
+		SELECT id, feedback_id, user_id, message, created_at
+		FROM feedback_replies
+		WHERE feedback_id IN (?)
+		ORDER BY created_at ASC
+	`, feedbackIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feedback replies: %w", err)
+	}
+
+	if err := db.Conn(ctx, r.db).SelectContext(ctx, &replies, r.db.Rebind(query), args...); err != nil {
+		return nil, fmt.Errorf("failed to list feedback replies: %w", err)
+	}
+	return replies, nil
+}
+
 func (r *feedbackPostgresRepository) buildOrderClause(sortBy, sortOrder string) string {
 	allowedColumns := map[string]bool{
 		"rating":     true,
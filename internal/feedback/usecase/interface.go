@@ -4,12 +4,31 @@ import (
 	"context"
 
 	"portal-data-backend/internal/feedback/domain"
+	moderationDomain "portal-data-backend/internal/moderation/domain"
 )
 
 type Usecase interface {
 	GetByID(ctx context.Context, id string) (*domain.FeedbackResponse, error)
 	List(ctx context.Context, req *domain.ListFeedbacksRequest) (*domain.FeedbackListResponse, error)
 	Create(ctx context.Context, req *domain.CreateFeedbackRequest, userID string) (*domain.FeedbackResponse, error)
+	// CreateAnonymous checks feedback of a visitor at remoteIP for spam, stores
+	// it unconfirmed and mails its submitter a confirmation link
+	CreateAnonymous(ctx context.Context, req *domain.CreateAnonymousFeedbackRequest, remoteIP string) error
+	// Confirm queues the anonymous feedback of a confirmation token for moderation
+	Confirm(ctx context.Context, token string) error
+	// Moderate publishes or hides feedback by the decision of moderatorID
+	Moderate(ctx context.Context, id string, status domain.ModerationStatus, moderatorID string) error
+	// ApplyModeration publishes or hides feedback as the moderation queue
+	// decides it
+	ApplyModeration(ctx context.Context, id string, status moderationDomain.Status) error
 	UpdateStatus(ctx context.Context, id string, status domain.FeedbackStatus) error
 	Delete(ctx context.Context, id string) error
+
+	// Reply adds a reply to feedback and notifies its submitter
+	Reply(ctx context.Context, id string, req *domain.CreateFeedbackReplyRequest, userID string) (*domain.FeedbackReplyResponse, error)
+	// Resolve marks feedback resolved with a resolution note and notifies its submitter
+	Resolve(ctx context.Context, id string, req *domain.ResolveFeedbackRequest, userID string) (*domain.FeedbackResponse, error)
+	UpdateVisibility(ctx context.Context, id string, isPublic bool) error
+	// ListPublic lists the feedback published as Q&A, with its replies
+	ListPublic(ctx context.Context, req *domain.ListFeedbacksRequest) (*domain.FeedbackListResponse, error)
 }
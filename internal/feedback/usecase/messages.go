@@ -0,0 +1,59 @@
+package usecase
+
+import templateDomain "portal-data-backend/internal/message_template/domain"
+
+// Keys of the messages submitters are sent
+const (
+	MessageConfirmation = "feedback.confirmation"
+	MessageReply        = "feedback.reply"
+	MessageResolved     = "feedback.resolved"
+)
+
+// Messages are the messages submitters are sent, with their built-in
+// wording. Replies and resolutions reach anonymous submitters by mail and
+// signed-in ones as notifications. The module defines them with the message
+// template registry.
+var Messages = []templateDomain.Definition{
+	{
+		Key:         MessageConfirmation,
+		Channel:     templateDomain.ChannelEmail,
+		Description: "Confirmation link mailed to the submitter of anonymous feedback",
+		Variables:   []string{"dataset", "link", "ttl"},
+		Subject:     "Confirm your feedback",
+		Body: "Thank you for your feedback on {{.dataset}}.\n\n" +
+			"Please confirm it within {{.ttl}} by opening this link:\n{{.link}}\n\n" +
+			"Your feedback is published after a moderator has reviewed it. If you did not send it, ignore this message.",
+	},
+	{
+		Key:         MessageReply,
+		Channel:     templateDomain.ChannelEmail,
+		Description: "Reply to the feedback of an anonymous submitter",
+		Variables:   []string{"message"},
+		Subject:     "New reply to your feedback",
+		Body:        "{{.message}}",
+	},
+	{
+		Key:         MessageReply,
+		Channel:     templateDomain.ChannelNotification,
+		Description: "Reply to the feedback of a signed-in submitter",
+		Variables:   []string{"message"},
+		Subject:     "New reply to your feedback",
+		Body:        "{{.message}}",
+	},
+	{
+		Key:         MessageResolved,
+		Channel:     templateDomain.ChannelEmail,
+		Description: "Resolution of the feedback of an anonymous submitter",
+		Variables:   []string{"note"},
+		Subject:     "Your feedback was resolved",
+		Body:        "{{.note}}",
+	},
+	{
+		Key:         MessageResolved,
+		Channel:     templateDomain.ChannelNotification,
+		Description: "Resolution of the feedback of a signed-in submitter",
+		Variables:   []string{"note"},
+		Subject:     "Your feedback was resolved",
+		Body:        "{{.note}}",
+	},
+}
@@ -2,21 +2,92 @@ package usecase
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"math"
+	"net/url"
 	"time"
 
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/infrastructure/db"
+	"portal-data-backend/infrastructure/logger"
+	"portal-data-backend/infrastructure/security"
+	datasetDomain "portal-data-backend/internal/dataset/domain"
 	"portal-data-backend/internal/feedback/domain"
+	templateDomain "portal-data-backend/internal/message_template/domain"
+	moderationDomain "portal-data-backend/internal/moderation/domain"
+	notifDomain "portal-data-backend/internal/notification/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
 
 	"github.com/google/uuid"
 )
 
+// NotificationSender is the part of the notification module submitters are
+// notified through
+type NotificationSender interface {
+	Create(ctx context.Context, req *notifDomain.CreateNotificationRequest) (*notifDomain.NotificationInfo, error)
+}
+
+// DatasetReader is the part of the dataset module anonymous feedback is checked against
+type DatasetReader interface {
+	GetByID(ctx context.Context, id string) (*datasetDomain.DatasetResponse, error)
+}
+
+// MailSender sends mail to the submitters of anonymous feedback
+type MailSender interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// MessageRenderer renders the wording of the mail and notifications
+// submitters are sent from the templates of the message template module
+type MessageRenderer interface {
+	Render(ctx context.Context, channel templateDomain.Channel, key string, vars map[string]string) (*templateDomain.Message, error)
+}
+
+// CaptchaVerifier checks the captcha solved by the submitter of anonymous feedback
+type CaptchaVerifier interface {
+	Verify(ctx context.Context, token, remoteIP string) error
+}
+
+// Moderator is the part of the moderation module feedback comments are
+// screened by
+type Moderator interface {
+	Submit(ctx context.Context, sub *moderationDomain.Submission) (moderationDomain.Status, error)
+	Settle(ctx context.Context, contentType, contentID string, status moderationDomain.Status, moderatorID string) error
+}
+
 type feedbackUsecase struct {
-	feedbackRepo domain.Repository
+	feedbackRepo  domain.Repository
+	tx            db.Transactor
+	notifications NotificationSender
+	datasets      DatasetReader
+	moderation    Moderator
+	mailer        MailSender
+	messages      MessageRenderer
+	captcha       CaptchaVerifier
+	cfg           config.FeedbackConfig
 }
 
-func NewFeedbackUsecase(feedbackRepo domain.Repository) Usecase {
-	return &feedbackUsecase{feedbackRepo: feedbackRepo}
+// NewFeedbackUsecase creates the feedback usecase. notifications may be nil.
+// moderation may be nil, then feedback of signed-in users is published right
+// away and anonymous feedback waits for a moderator through Moderate.
+func NewFeedbackUsecase(feedbackRepo domain.Repository, tx db.Transactor, notifications NotificationSender, datasets DatasetReader, moderation Moderator, mailer MailSender, messages MessageRenderer, captcha CaptchaVerifier, cfg config.FeedbackConfig) Usecase {
+	return &feedbackUsecase{
+		feedbackRepo:  feedbackRepo,
+		tx:            tx,
+		notifications: notifications,
+		datasets:      datasets,
+		moderation:    moderation,
+		mailer:        mailer,
+		messages:      messages,
+		captcha:       captcha,
+		cfg:           cfg,
+	}
 }
 
 func (u *feedbackUsecase) GetByID(ctx context.Context, id string) (*domain.FeedbackResponse, error) {
@@ -24,7 +95,12 @@ func (u *feedbackUsecase) GetByID(ctx context.Context, id string) (*domain.Feedb
 	if err != nil {
 		return nil, fmt.Errorf("failed to get feedback: %w", err)
 	}
-	return u.toResponse(feedback), nil
+
+	responses, err := u.withReplies(ctx, []*domain.Feedback{feedback})
+	if err != nil {
+		return nil, err
+	}
+	return &responses[0], nil
 }
 
 func (u *feedbackUsecase) List(ctx context.Context, req *domain.ListFeedbacksRequest) (*domain.FeedbackListResponse, error) {
@@ -37,12 +113,20 @@ func (u *feedbackUsecase) List(ctx context.Context, req *domain.ListFeedbacksReq
 
 	offset := (req.Page - 1) * req.Limit
 
+	// Feedback still in moderation is only listed when asked for
+	if req.ModerationStatus == nil {
+		approved := string(domain.ModerationStatusApproved)
+		req.ModerationStatus = &approved
+	}
+
 	filter := &domain.FeedbackFilter{
-		DatasetID: req.DatasetID,
-		Category:  req.Category,
-		Status:    req.Status,
-		UserID:    req.UserID,
-		Search:    req.Search,
+		DatasetID:        req.DatasetID,
+		Category:         req.Category,
+		Status:           req.Status,
+		UserID:           req.UserID,
+		IsPublic:         req.IsPublic,
+		ModerationStatus: req.ModerationStatus,
+		Search:           req.Search,
 	}
 
 	sortBy := req.SortBy
@@ -79,24 +163,155 @@ func (u *feedbackUsecase) List(ctx context.Context, req *domain.ListFeedbacksReq
 
 func (u *feedbackUsecase) Create(ctx context.Context, req *domain.CreateFeedbackRequest, userID string) (*domain.FeedbackResponse, error) {
 	feedback := &domain.Feedback{
-		ID:        uuid.New().String(),
-		UserID:    userID,
-		DatasetID: req.DatasetID,
-		Rating:    req.Rating,
-		Comment:   req.Comment,
-		Category:  req.Category,
-		Status:    domain.FeedbackStatusPending,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ID:               uuid.New().String(),
+		UserID:           &userID,
+		DatasetID:        req.DatasetID,
+		Rating:           req.Rating,
+		Comment:          req.Comment,
+		Category:         req.Category,
+		Status:           domain.FeedbackStatusPending,
+		ModerationStatus: domain.ModerationStatusApproved,
+		CreatedAt:        time.Now(),
+		UpdatedAt:        time.Now(),
 	}
 
-	if err := u.feedbackRepo.Create(ctx, feedback); err != nil {
-		return nil, fmt.Errorf("failed to create feedback: %w", err)
+	// Comments the screening holds are published once a moderator approves them
+	err := u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		status, err := u.submit(ctx, feedback, false)
+		if err != nil {
+			return err
+		}
+		feedback.ModerationStatus = status
+		if err := u.feedbackRepo.Create(ctx, feedback); err != nil {
+			return fmt.Errorf("failed to create feedback: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return u.toResponse(feedback), nil
 }
 
+func (u *feedbackUsecase) CreateAnonymous(ctx context.Context, req *domain.CreateAnonymousFeedbackRequest, remoteIP string) error {
+	// Only bots fill in the honeypot. They are not told their feedback was dropped.
+	if req.Website != "" {
+		logger.FromContext(ctx).Warn("dropped anonymous feedback from %s: honeypot filled in", remoteIP)
+		return nil
+	}
+
+	if err := u.captcha.Verify(ctx, req.CaptchaToken, remoteIP); err != nil {
+		if errors.Is(err, security.ErrCaptchaFailed) {
+			return fmt.Errorf("%w: %v", pkgErrors.ErrInvalidInput, err)
+		}
+		return err
+	}
+
+	dataset, err := u.datasets.GetByID(ctx, req.DatasetID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) && !errors.Is(err, pkgErrors.ErrNotFound) {
+		return fmt.Errorf("failed to get dataset: %w", err)
+	}
+	if err != nil || dataset.Status != string(datasetDomain.DatasetStatusPublished) || dataset.Classification != "public" {
+		return fmt.Errorf("%w: anonymous feedback can only be left on public datasets", pkgErrors.ErrInvalidInput)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return fmt.Errorf("failed to generate confirmation token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(secret)
+	tokenHash := hashConfirmationToken(token)
+
+	now := time.Now()
+	expiresAt := now.Add(u.cfg.ConfirmationTTL)
+	feedback := &domain.Feedback{
+		ID:                    uuid.New().String(),
+		DatasetID:             &dataset.ID,
+		Rating:                req.Rating,
+		Comment:               req.Comment,
+		Category:              req.Category,
+		Status:                domain.FeedbackStatusPending,
+		Email:                 &req.Email,
+		ModerationStatus:      domain.ModerationStatusUnconfirmed,
+		ConfirmationTokenHash: &tokenHash,
+		ConfirmationExpiresAt: &expiresAt,
+		CreatedAt:             now,
+		UpdatedAt:             now,
+	}
+	if err := u.feedbackRepo.Create(ctx, feedback); err != nil {
+		return fmt.Errorf("failed to create feedback: %w", err)
+	}
+
+	msg, err := u.messages.Render(ctx, templateDomain.ChannelEmail, MessageConfirmation, map[string]string{
+		"dataset": dataset.Name,
+		"link":    u.cfg.ConfirmURL + "?token=" + url.QueryEscape(token),
+		"ttl":     u.cfg.ConfirmationTTL.String(),
+	})
+	if err == nil {
+		err = u.mailer.Send(ctx, req.Email, msg.Subject, msg.Body)
+	}
+	if err != nil {
+		// Feedback nobody can confirm would only wait for expiry
+		if delErr := u.feedbackRepo.Delete(ctx, feedback.ID); delErr != nil {
+			logger.FromContext(ctx).Error("failed to delete unconfirmable feedback %s: %v", feedback.ID, delErr)
+		}
+		return fmt.Errorf("failed to send feedback confirmation: %w", err)
+	}
+	return nil
+}
+
+func (u *feedbackUsecase) Confirm(ctx context.Context, token string) error {
+	return u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		id, err := u.feedbackRepo.Confirm(ctx, hashConfirmationToken(token), time.Now())
+		if err != nil {
+			if errors.Is(err, pkgErrors.ErrNotFound) {
+				return fmt.Errorf("%w: confirmation link is invalid or has expired", pkgErrors.ErrInvalidInput)
+			}
+			return fmt.Errorf("failed to confirm feedback: %w", err)
+		}
+
+		// Anonymous feedback always waits for a moderator
+		feedback, err := u.feedbackRepo.GetByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get feedback: %w", err)
+		}
+		_, err = u.submit(ctx, feedback, true)
+		return err
+	})
+}
+
+func (u *feedbackUsecase) Moderate(ctx context.Context, id string, status domain.ModerationStatus, moderatorID string) error {
+	feedback, err := u.feedbackRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get feedback: %w", err)
+	}
+	if feedback.ModerationStatus == domain.ModerationStatusUnconfirmed {
+		return fmt.Errorf("%w: feedback has not been confirmed by its submitter", pkgErrors.ErrInvalidInput)
+	}
+
+	return u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.feedbackRepo.UpdateModeration(ctx, id, status); err != nil {
+			return fmt.Errorf("failed to moderate feedback: %w", err)
+		}
+		// The decision also settles the feedback in the moderation queue
+		if u.moderation == nil {
+			return nil
+		}
+		if err := u.moderation.Settle(ctx, domain.ContentTypeFeedback, id, moderationDomain.Status(status), moderatorID); err != nil {
+			return fmt.Errorf("failed to settle feedback moderation: %w", err)
+		}
+		return nil
+	})
+}
+
+func (u *feedbackUsecase) ApplyModeration(ctx context.Context, id string, status moderationDomain.Status) error {
+	if err := u.feedbackRepo.UpdateModeration(ctx, id, domain.ModerationStatus(status)); err != nil {
+		return fmt.Errorf("failed to moderate feedback: %w", err)
+	}
+	return nil
+}
+
 func (u *feedbackUsecase) UpdateStatus(ctx context.Context, id string, status domain.FeedbackStatus) error {
 	if err := u.feedbackRepo.UpdateStatus(ctx, id, status); err != nil {
 		return fmt.Errorf("failed to update feedback status: %w", err)
@@ -111,16 +326,212 @@ func (u *feedbackUsecase) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+func (u *feedbackUsecase) Reply(ctx context.Context, id string, req *domain.CreateFeedbackReplyRequest, userID string) (*domain.FeedbackReplyResponse, error) {
+	feedback, err := u.feedbackRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feedback: %w", err)
+	}
+
+	reply := &domain.FeedbackReply{
+		ID:         uuid.New().String(),
+		FeedbackID: feedback.ID,
+		UserID:     userID,
+		Message:    req.Message,
+		CreatedAt:  time.Now(),
+	}
+	if err := u.feedbackRepo.CreateReply(ctx, reply); err != nil {
+		return nil, fmt.Errorf("failed to reply to feedback: %w", err)
+	}
+
+	// A reply is being worked on, unless the feedback was already settled
+	if feedback.Status == domain.FeedbackStatusPending {
+		if err := u.feedbackRepo.UpdateStatus(ctx, feedback.ID, domain.FeedbackStatusReview); err != nil {
+			return nil, fmt.Errorf("failed to update feedback status: %w", err)
+		}
+	}
+
+	u.notify(ctx, feedback, userID, MessageReply, map[string]string{"message": reply.Message})
+	return u.toReplyResponse(reply), nil
+}
+
+func (u *feedbackUsecase) Resolve(ctx context.Context, id string, req *domain.ResolveFeedbackRequest, userID string) (*domain.FeedbackResponse, error) {
+	if err := u.feedbackRepo.Resolve(ctx, id, req.ResolutionNote, userID); err != nil {
+		return nil, fmt.Errorf("failed to resolve feedback: %w", err)
+	}
+
+	feedback, err := u.feedbackRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feedback: %w", err)
+	}
+
+	u.notify(ctx, feedback, userID, MessageResolved, map[string]string{"note": req.ResolutionNote})
+
+	responses, err := u.withReplies(ctx, []*domain.Feedback{feedback})
+	if err != nil {
+		return nil, err
+	}
+	return &responses[0], nil
+}
+
+func (u *feedbackUsecase) UpdateVisibility(ctx context.Context, id string, isPublic bool) error {
+	if err := u.feedbackRepo.UpdateVisibility(ctx, id, isPublic); err != nil {
+		return fmt.Errorf("failed to update feedback visibility: %w", err)
+	}
+	return nil
+}
+
+func (u *feedbackUsecase) ListPublic(ctx context.Context, req *domain.ListFeedbacksRequest) (*domain.FeedbackListResponse, error) {
+	public := true
+	approved := string(domain.ModerationStatusApproved)
+	req.IsPublic = &public
+	req.ModerationStatus = &approved
+	req.UserID = nil
+
+	resp, err := u.List(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(resp.Feedbacks))
+	for i, fb := range resp.Feedbacks {
+		ids[i] = fb.ID
+	}
+	replies, err := u.listReplies(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range resp.Feedbacks {
+		resp.Feedbacks[i].Replies = replies[resp.Feedbacks[i].ID]
+		resp.Feedbacks[i].Email = nil
+	}
+	return resp, nil
+}
+
+// notify tells the submitter of feedback about a change made by userID with
+// the message key. Anonymous submitters are told by mail.
+func (u *feedbackUsecase) notify(ctx context.Context, feedback *domain.Feedback, userID, key string, vars map[string]string) {
+	if feedback.UserID == nil {
+		if feedback.Email == nil {
+			return
+		}
+		msg, err := u.messages.Render(ctx, templateDomain.ChannelEmail, key, vars)
+		if err == nil {
+			err = u.mailer.Send(ctx, *feedback.Email, msg.Subject, msg.Body)
+		}
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to mail the submitter of feedback %s: %v", feedback.ID, err)
+		}
+		return
+	}
+	if u.notifications == nil || *feedback.UserID == userID {
+		return
+	}
+
+	msg, err := u.messages.Render(ctx, templateDomain.ChannelNotification, key, vars)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to notify the submitter of feedback %s: %v", feedback.ID, err)
+		return
+	}
+	req := &notifDomain.CreateNotificationRequest{
+		UserID:   *feedback.UserID,
+		Title:    msg.Subject,
+		Message:  msg.Body,
+		Type:     string(notifDomain.NotificationTypeInfo),
+		Category: string(notifDomain.NotificationCategoryFeedback),
+	}
+	if _, err := u.notifications.Create(ctx, req); err != nil {
+		logger.FromContext(ctx).Error("failed to notify the submitter of feedback %s: %v", feedback.ID, err)
+	}
+}
+
+// withReplies converts feedback to responses including their replies
+func (u *feedbackUsecase) withReplies(ctx context.Context, feedbacks []*domain.Feedback) ([]domain.FeedbackResponse, error) {
+	ids := make([]string, len(feedbacks))
+	for i, fb := range feedbacks {
+		ids[i] = fb.ID
+	}
+	replies, err := u.listReplies(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]domain.FeedbackResponse, len(feedbacks))
+	for i, fb := range feedbacks {
+		responses[i] = *u.toResponse(fb)
+		responses[i].Replies = replies[fb.ID]
+	}
+	return responses, nil
+}
+
+// listReplies returns the replies to the given feedback by feedback ID
+func (u *feedbackUsecase) listReplies(ctx context.Context, ids []string) (map[string][]domain.FeedbackReplyResponse, error) {
+	replies, err := u.feedbackRepo.ListReplies(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feedback replies: %w", err)
+	}
+
+	byFeedback := make(map[string][]domain.FeedbackReplyResponse, len(ids))
+	for _, reply := range replies {
+		byFeedback[reply.FeedbackID] = append(byFeedback[reply.FeedbackID], *u.toReplyResponse(reply))
+	}
+	return byFeedback, nil
+}
+
 func (u *feedbackUsecase) toResponse(feedback *domain.Feedback) *domain.FeedbackResponse {
 	return &domain.FeedbackResponse{
-		ID:        feedback.ID,
-		UserID:    feedback.UserID,
-		DatasetID: feedback.DatasetID,
-		Rating:    feedback.Rating,
-		Comment:   feedback.Comment,
-		Category:  string(feedback.Category),
-		Status:    string(feedback.Status),
-		CreatedAt: feedback.CreatedAt,
-		UpdatedAt: feedback.UpdatedAt,
+		ID:               feedback.ID,
+		UserID:           feedback.UserID,
+		DatasetID:        feedback.DatasetID,
+		Rating:           feedback.Rating,
+		Comment:          feedback.Comment,
+		Category:         string(feedback.Category),
+		Status:           string(feedback.Status),
+		CreatedAt:        feedback.CreatedAt,
+		UpdatedAt:        feedback.UpdatedAt,
+		ResolutionNote:   feedback.ResolutionNote,
+		ResolvedBy:       feedback.ResolvedBy,
+		ResolvedAt:       feedback.ResolvedAt,
+		IsPublic:         feedback.IsPublic,
+		Email:            feedback.Email,
+		ModerationStatus: string(feedback.ModerationStatus),
+	}
+}
+
+func (u *feedbackUsecase) toReplyResponse(reply *domain.FeedbackReply) *domain.FeedbackReplyResponse {
+	return &domain.FeedbackReplyResponse{
+		ID:        reply.ID,
+		UserID:    reply.UserID,
+		Message:   reply.Message,
+		CreatedAt: reply.CreatedAt,
 	}
 }
+
+// submit screens the comment of feedback, held for a moderator when hold is
+// set, and returns the moderation status the feedback starts with. Without
+// moderation, held feedback is pending and other feedback approved.
+func (u *feedbackUsecase) submit(ctx context.Context, feedback *domain.Feedback, hold bool) (domain.ModerationStatus, error) {
+	if u.moderation == nil {
+		if hold {
+			return domain.ModerationStatusPending, nil
+		}
+		return domain.ModerationStatusApproved, nil
+	}
+
+	status, err := u.moderation.Submit(ctx, &moderationDomain.Submission{
+		ContentType: domain.ContentTypeFeedback,
+		ContentID:   feedback.ID,
+		Text:        feedback.Comment,
+		AuthorID:    feedback.UserID,
+		AuthorEmail: feedback.Email,
+		Hold:        hold,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to screen feedback: %w", err)
+	}
+	return domain.ModerationStatus(status), nil
+}
+
+func hashConfirmationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -2,20 +2,22 @@ package http
 
 import (
 	"bytes"
-	"encoding/json"
-	"errors"
-	"fmt"
 	"io"
 	"net/http"
 	"strconv"
 
+	"portal-data-backend/infrastructure/auth"
+	datasetDomain "portal-data-backend/internal/dataset/domain"
 	fileDomain "portal-data-backend/internal/file/domain"
 	"portal-data-backend/internal/file/usecase"
+	"portal-data-backend/infrastructure/http/httputil"
+	"portal-data-backend/infrastructure/http/middleware"
+	"portal-data-backend/infrastructure/http/problem"
 	"portal-data-backend/infrastructure/http/response"
+	roleDomain "portal-data-backend/internal/role/domain"
 	pkgErrors "portal-data-backend/pkg/errors"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/go-playground/validator/v10"
 )
 
 // readCloser wraps a bytes.Reader to implement multipart.File interface
@@ -29,26 +31,30 @@ func (rc *readCloser) Close() error {
 
 type Handler struct {
 	fileUsecase usecase.Usecase
-	validator   *validator.Validate
+	datasets    datasetDomain.Authorizer
+	adminRoles  []string
 }
 
-func NewHandler(fileUsecase usecase.Usecase) *Handler {
+// NewHandler creates a file handler. Changing the files of a dataset
+// requires the edit role on it, which users with one of adminRoles have on
+// every dataset.
+func NewHandler(fileUsecase usecase.Usecase, datasets datasetDomain.Authorizer, adminRoles []string) *Handler {
 	return &Handler{
 		fileUsecase: fileUsecase,
-		validator:   validator.New(),
+		datasets:    datasets,
+		adminRoles:  adminRoles,
 	}
 }
 
 func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "File ID is required", nil)
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
 	file, err := h.fileUsecase.GetByID(r.Context(), id)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -72,7 +78,7 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 
 	resp, err := h.fileUsecase.List(r.Context(), req)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -82,6 +88,9 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) Upload(w http.ResponseWriter, r *http.Request) {
 	// Parse multipart form (max 32MB)
 	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		if httputil.BodyTooLarge(w, err) {
+			return
+		}
 		response.BadRequest(w, response.CodeBadRequest, "Failed to parse form data", nil)
 		return
 	}
@@ -121,13 +130,17 @@ func (h *Handler) Upload(w http.ResponseWriter, r *http.Request) {
 	if dsID := r.FormValue("dataset_id"); dsID != "" {
 		datasetID = &dsID
 	}
+	if err := h.authorize(r, datasetID); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
 
 	// Get user ID from context
-	userID, _ := r.Context().Value("user_id").(string)
+	userID := auth.UserID(r.Context())
 
 	uploadResp, err := h.fileUsecase.Upload(r.Context(), fileName, fileSize, mimeType, file, datasetID, userID)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -135,27 +148,24 @@ func (h *Handler) Upload(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "File ID is required", nil)
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
 	var req struct {
 		Status string `json:"status" validate:"required"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, response.CodeBadRequest, "Invalid request body", nil)
+	if !httputil.DecodeInto(w, r, &req) {
 		return
 	}
 
-	if err := h.validator.Struct(&req); err != nil {
-		response.ValidationError(w, response.CodeValidationFailed, "Validation failed", h.formatValidationErrors(err))
+	if !h.authorizeFile(w, r, id) {
 		return
 	}
 
 	if err := h.fileUsecase.UpdateStatus(r.Context(), id, fileDomain.FileStatus(req.Status)); err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -163,14 +173,17 @@ func (h *Handler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "File ID is required", nil)
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if !h.authorizeFile(w, r, id) {
 		return
 	}
 
 	if err := h.fileUsecase.Delete(r.Context(), id); err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -178,9 +191,8 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) GetByDatasetID(w http.ResponseWriter, r *http.Request) {
-	datasetID := chi.URLParam(r, "datasetId")
-	if datasetID == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Dataset ID is required", nil)
+	datasetID, ok := httputil.UUIDParam(w, r, "datasetId")
+	if !ok {
 		return
 	}
 
@@ -189,46 +201,49 @@ func (h *Handler) GetByDatasetID(w http.ResponseWriter, r *http.Request) {
 
 	resp, err := h.fileUsecase.GetByDatasetID(r.Context(), datasetID, page, limit)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
 	response.OK(w, response.CodeSuccess, "Dataset files retrieved successfully", resp)
 }
 
-func (h *Handler) handleError(w http.ResponseWriter, err error) {
-	if err == nil {
-		return
-	}
+// errorMapper maps the errors of the file module on top of the shared ones
+var errorMapper = problem.Default.With(
+	problem.Mapping{Err: pkgErrors.ErrNotFound, Status: http.StatusNotFound, Code: response.CodeNotFound, Message: "File not found"},
+)
 
-	switch {
-	case errors.Is(err, pkgErrors.ErrNotFound):
-		response.NotFound(w, response.CodeNotFound, "File not found", nil)
-	default:
-		response.InternalError(w, response.CodeInternalServerError, "Internal server error", nil)
-	}
+func (h *Handler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	errorMapper.Write(w, r, err)
 }
 
-func (h *Handler) formatValidationErrors(err error) []response.ErrorDetail {
-	var details []response.ErrorDetail
-	if validationErrors, ok := err.(validator.ValidationErrors); ok {
-		for _, fieldErr := range validationErrors {
-			details = append(details, response.ErrorDetail{
-				Field:   fieldErr.Field(),
-				Message: h.getValidationErrorMessage(fieldErr),
-			})
-		}
+// authorize checks the caller has the edit role on the dataset datasetID,
+// if the file belongs to one
+func (h *Handler) authorize(r *http.Request, datasetID *string) error {
+	if datasetID == nil {
+		return nil
+	}
+	user := auth.FromContext(r.Context())
+	caller := datasetDomain.Caller{
+		UserID:         user.UserID,
+		OrganizationID: user.OrganizationID,
+		Admin:          user.HasRole(h.adminRoles...),
 	}
-	return details
+	return h.datasets.Authorize(r.Context(), *datasetID, caller, datasetDomain.CollaboratorEdit)
 }
 
-func (h *Handler) getValidationErrorMessage(fieldErr validator.FieldError) string {
-	switch fieldErr.Tag() {
-	case "required":
-		return fmt.Sprintf("%s is required", fieldErr.Field())
-	default:
-		return fmt.Sprintf("%s is invalid", fieldErr.Field())
+// authorizeFile checks the caller may change the file id, writing the error
+// and returning false when not
+func (h *Handler) authorizeFile(w http.ResponseWriter, r *http.Request, id string) bool {
+	file, err := h.fileUsecase.GetByID(r.Context(), id)
+	if err == nil {
+		err = h.authorize(r, file.DatasetID)
+	}
+	if err != nil {
+		h.handleError(w, r, err)
+		return false
 	}
+	return true
 }
 
 func parseIntQuery(r *http.Request, key string, defaultValue int) int {
@@ -240,13 +255,18 @@ func parseIntQuery(r *http.Request, key string, defaultValue int) int {
 	return defaultValue
 }
 
-func RegisterRoutes(r chi.Router, handler *Handler) {
+// RegisterRoutes registers file routes, which all go through auth. Changing
+// files requires the files:write permission, and the edit role on their
+// dataset for the files of a dataset.
+func RegisterRoutes(r chi.Router, handler *Handler, auth func(http.Handler) http.Handler) {
 	r.Route("/files", func(r chi.Router) {
+		r.Use(auth)
 		r.Get("/", handler.List)
-		r.Post("/upload", handler.Upload)
+		write := middleware.RequirePermission(roleDomain.PermissionFilesWrite)
+		r.With(write).Post("/upload", handler.Upload)
 		r.Get("/{id}", handler.GetByID)
-		r.Patch("/{id}/status", handler.UpdateStatus)
-		r.Delete("/{id}", handler.Delete)
+		r.With(write).Patch("/{id}/status", handler.UpdateStatus)
+		r.With(write).Delete("/{id}", handler.Delete)
 		r.Get("/dataset/{datasetId}", handler.GetByDatasetID)
 	})
 }
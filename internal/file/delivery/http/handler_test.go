@@ -0,0 +1,146 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"portal-data-backend/infrastructure/auth"
+	"portal-data-backend/infrastructure/http/middleware"
+	datasetDomain "portal-data-backend/internal/dataset/domain"
+	delivery "portal-data-backend/internal/file/delivery/http"
+	"portal-data-backend/internal/file/domain"
+	"portal-data-backend/internal/file/usecase"
+	roleDomain "portal-data-backend/internal/role/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	datasetID = "6f1c2a8e-3b7d-4c5e-9a1f-2d3e4f5a6b7c"
+	fileID    = "0d9e8f7a-6b5c-4d3e-8f2a-1b0c9d8e7f6a"
+)
+
+// mockUsecase counts the writes it is asked for; its one file belongs to
+// datasetID
+type mockUsecase struct {
+	usecase.Usecase
+	writes int
+}
+
+func (m *mockUsecase) GetByID(ctx context.Context, id string) (*domain.FileInfo, error) {
+	if id != fileID {
+		return nil, pkgErrors.ErrNotFound
+	}
+	dataset := datasetID
+	return &domain.FileInfo{ID: fileID, DatasetID: &dataset}, nil
+}
+
+func (m *mockUsecase) Upload(ctx context.Context, fileName string, fileSize int64, mimeType string, reader io.Reader, datasetID *string, userID string) (*domain.UploadResponse, error) {
+	m.writes++
+	return &domain.UploadResponse{}, nil
+}
+
+func (m *mockUsecase) UpdateStatus(ctx context.Context, id string, status domain.FileStatus) error {
+	m.writes++
+	return nil
+}
+
+func (m *mockUsecase) Delete(ctx context.Context, id string) error {
+	m.writes++
+	return nil
+}
+
+// grants authorizes the users it grants a role on datasetID
+type grants map[string]datasetDomain.CollaboratorRole
+
+func (g grants) Authorize(ctx context.Context, id string, caller datasetDomain.Caller, role datasetDomain.CollaboratorRole) error {
+	if caller.Admin || (id == datasetID && g[caller.UserID].Grants(role)) {
+		return nil
+	}
+	return fmt.Errorf("%w: you need the %s role on the dataset", pkgErrors.ErrForbidden, role)
+}
+
+// upload returns a multipart upload of a file to datasetID
+func upload(t *testing.T) (string, *bytes.Buffer) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", "data.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write([]byte("a,b\n1,2\n"))
+	form.WriteField("dataset_id", datasetID)
+	form.Close()
+	return form.FormDataContentType(), &body
+}
+
+// Test only the collaborators with the edit role on a dataset change its
+// files, even when their role grants files:write
+func TestFiles_RequireEdit(t *testing.T) {
+	files := &mockUsecase{}
+	// The tests sign in as the user of their header
+	signIn := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims := auth.Claims{UserID: r.Header.Get("X-User"), RoleID: "editor"}
+			next.ServeHTTP(w, r.WithContext(auth.WithClaims(r.Context(), claims)))
+		})
+	}
+	lookup := func(ctx context.Context, roleID string) ([]string, error) {
+		return []string{roleDomain.PermissionFilesWrite}, nil
+	}
+	collaborators := grants{"viewer": datasetDomain.CollaboratorView, "editor": datasetDomain.CollaboratorEdit}
+
+	r := chi.NewRouter()
+	r.Use(middleware.Permissions(lookup, "admin"))
+	delivery.RegisterRoutes(r, delivery.NewHandler(files, collaborators, []string{"admin"}), signIn)
+
+	writes := []struct {
+		name    string
+		method  string
+		path    string
+		request func() (string, io.Reader)
+	}{
+		{"uploading", http.MethodPost, "/files/upload", func() (string, io.Reader) { return upload(t) }},
+		{"updating the status", http.MethodPatch, "/files/" + fileID + "/status", func() (string, io.Reader) {
+			return "application/json", strings.NewReader(`{"status": "active"}`)
+		}},
+		{"deleting", http.MethodDelete, "/files/" + fileID, func() (string, io.Reader) { return "", nil }},
+	}
+	users := []struct {
+		user   string
+		status int
+	}{
+		{"viewer", http.StatusForbidden},
+		{"stranger", http.StatusForbidden},
+		{"editor", 0},
+	}
+	for _, write := range writes {
+		for _, u := range users {
+			t.Run(write.name+" as "+u.user, func(t *testing.T) {
+				contentType, body := write.request()
+				req := httptest.NewRequest(write.method, write.path, body)
+				req.Header.Set("Content-Type", contentType)
+				req.Header.Set("X-User", u.user)
+				rec := httptest.NewRecorder()
+				r.ServeHTTP(rec, req)
+				if u.status != 0 && rec.Code != u.status {
+					t.Errorf("Expected %d, got %d: %s", u.status, rec.Code, rec.Body.String())
+				}
+				if u.status == 0 && rec.Code >= http.StatusBadRequest {
+					t.Errorf("Expected the write to succeed, got %d: %s", rec.Code, rec.Body.String())
+				}
+			})
+		}
+	}
+	if files.writes != len(writes) {
+		t.Errorf("Expected the editor alone to write, got %d writes", files.writes)
+	}
+}
@@ -0,0 +1,23 @@
+package http
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	fileDomain "portal-data-backend/internal/file/domain"
+)
+
+// Describe adds the file routes to the API specification
+func Describe(spec *openapi.Spec) {
+	api := spec.Tag("files", "Uploaded files")
+	api.Get("/files", "List files").Query(fileDomain.ListFilesRequest{}).Returns(http.StatusOK, fileDomain.FileListResponse{})
+	api.Post("/files/upload", "Upload file").Upload("file", "filename", "mime_type", "dataset_id").Returns(http.StatusCreated, fileDomain.UploadResponse{})
+	api.Get("/files/{id}", "Get file").Returns(http.StatusOK, fileDomain.FileInfo{})
+	api.Patch("/files/{id}/status", "Update file status").Body(struct {
+		Status string `json:"status" validate:"required"`
+	}{}).Returns(http.StatusOK, nil)
+	api.Delete("/files/{id}", "Delete file").Returns(http.StatusOK, nil)
+	api.Get("/files/dataset/{datasetId}", "List files of a dataset").
+		Query(fileDomain.ListFilesRequest{}, "page", "limit").
+		Returns(http.StatusOK, fileDomain.FileListResponse{})
+}
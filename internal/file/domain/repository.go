@@ -12,6 +12,8 @@ type Repository interface {
 	UpdateStatus(ctx context.Context, id string, status FileStatus) error
 	Delete(ctx context.Context, id string) error
 	GetByDatasetID(ctx context.Context, datasetID string, limit, offset int) ([]*File, int, error)
+	// GetByDatasetIDs returns the files of several datasets in one query
+	GetByDatasetIDs(ctx context.Context, datasetIDs []string) ([]*File, error)
 }
 
 type FileFilter struct {
@@ -24,5 +26,9 @@ type FileFilter struct {
 type StorageService interface {
 	Upload(ctx context.Context, fileName string, reader io.Reader, contentType string, path string) (string, error)
 	Delete(ctx context.Context, path string) error
+	// Open returns a reader of the file at path, which the caller closes
+	Open(ctx context.Context, path string) (io.ReadCloser, error)
 	GetURL(ctx context.Context, path string) (string, error)
+	// Ping checks the storage can be reached and its bucket accessed
+	Ping(ctx context.Context) error
 }
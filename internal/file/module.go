@@ -0,0 +1,66 @@
+// Package file is the module storing uploaded files in object storage.
+package file
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	"portal-data-backend/infrastructure/storage"
+	"portal-data-backend/internal/app"
+	datasetDomain "portal-data-backend/internal/dataset/domain"
+	delivery "portal-data-backend/internal/file/delivery/http"
+	"portal-data-backend/internal/file/repository"
+	"portal-data-backend/internal/file/usecase"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Module stores files and provides uploads to other modules
+type Module struct {
+	handler *delivery.Handler
+}
+
+// Name implements app.Module
+func (m *Module) Name() string {
+	return "file"
+}
+
+// Register implements app.Module
+func (m *Module) Register(deps *app.Deps) error {
+	minioStorage, err := storage.NewMinIOStorage(&deps.Config.MinIO)
+	if err != nil {
+		return fmt.Errorf("failed to connect to MinIO: %w", err)
+	}
+	deps.Logger.Info("MinIO connected successfully")
+	deps.Health.Register("minio", minioStorage.Ping)
+
+	repo := repository.NewFilePostgresRepository(deps.DB)
+	files := usecase.NewFileUsecase(repo, minioStorage, "files")
+	deps.Services.Files = files
+	m.handler = delivery.NewHandler(files, datasets{&deps.Services}, deps.Config.Audit.AdminRoles)
+	return nil
+}
+
+// Routes implements app.Module
+func (m *Module) Routes(r chi.Router, auth func(http.Handler) http.Handler) {
+	delivery.RegisterRoutes(r, m.handler, auth)
+}
+
+// Describe implements app.Module
+func (m *Module) Describe(spec *openapi.Spec) {
+	delivery.Describe(spec)
+}
+
+// datasets authorizes with the dataset service. The dataset module uses
+// files, so it is registered after this one and its service is only read
+// once requests come in.
+type datasets struct {
+	services *app.Services
+}
+
+// Authorize implements datasetDomain.Authorizer
+func (d datasets) Authorize(ctx context.Context, id string, caller datasetDomain.Caller, role datasetDomain.CollaboratorRole) error {
+	return d.services.Datasets.Authorize(ctx, id, caller, role)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"portal-data-backend/infrastructure/db"
 	"portal-data-backend/internal/file/domain"
 	"portal-data-backend/pkg/errors"
 
@@ -28,7 +29,7 @@ func (r *filePostgresRepository) GetByID(ctx context.Context, id string) (*domai
 	`
 
 	var file domain.File
-	err := r.db.GetContext(ctx, &file, query, id)
+	err := db.Conn(ctx, r.db).GetContext(ctx, &file, query, id)
 	if err != nil {
 		return nil, r.handleError(err)
 	}
@@ -61,7 +62,7 @@ func (r *filePostgresRepository) List(ctx context.Context, filter *domain.FileFi
 
 	countQuery := "SELECT COUNT(*) FROM files " + whereClause
 	var total int
-	err := r.db.GetContext(ctx, &total, countQuery, args...)
+	err := db.Conn(ctx, r.db).GetContext(ctx, &total, countQuery, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count files: %w", err)
 	}
@@ -75,7 +76,7 @@ func (r *filePostgresRepository) List(ctx context.Context, filter *domain.FileFi
 	args = append(args, limit, offset)
 
 	var files []*domain.File
-	err = r.db.SelectContext(ctx, &files, query, args...)
+	err = db.Conn(ctx, r.db).SelectContext(ctx, &files, query, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to list files: %w", err)
 	}
@@ -94,7 +95,7 @@ func (r *filePostgresRepository) Create(ctx context.Context, file *domain.File)
 		)
 	`
 
-	_, err := r.db.NamedExecContext(ctx, query, file)
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, file)
 	if err != nil {
 		return fmt.Errorf("failed to create file: %w", err)
 	}
@@ -103,7 +104,7 @@ func (r *filePostgresRepository) Create(ctx context.Context, file *domain.File)
 
 func (r *filePostgresRepository) UpdateStatus(ctx context.Context, id string, status domain.FileStatus) error {
 	query := `UPDATE files SET status = $1, updated_at = $2 WHERE id = $3`
-	result, err := r.db.ExecContext(ctx, query, status, time.Now(), id)
+	result, err := db.Conn(ctx, r.db).ExecContext(ctx, query, status, time.Now(), id)
 	if err != nil {
 		return fmt.Errorf("failed to update file status: %w", err)
 	}
@@ -117,7 +118,7 @@ func (r *filePostgresRepository) UpdateStatus(ctx context.Context, id string, st
 
 func (r *filePostgresRepository) Delete(ctx context.Context, id string) error {
 	query := `DELETE FROM files WHERE id = $1`
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := db.Conn(ctx, r.db).ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete file: %w", err)
 	}
@@ -140,14 +141,14 @@ func (r *filePostgresRepository) GetByDatasetID(ctx context.Context, datasetID s
 	`
 
 	var files []*domain.File
-	err := r.db.SelectContext(ctx, &files, query, datasetID, limit, offset)
+	err := db.Conn(ctx, r.db).SelectContext(ctx, &files, query, datasetID, limit, offset)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to get dataset files: %w", err)
 	}
 
 	countQuery := `SELECT COUNT(*) FROM files WHERE dataset_id = $1 AND status != 'deleted'`
 	var total int
-	err = r.db.GetContext(ctx, &total, countQuery, datasetID)
+	err = db.Conn(ctx, r.db).GetContext(ctx, &total, countQuery, datasetID)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count dataset files: %w", err)
 	}
@@ -155,6 +156,30 @@ func (r *filePostgresRepository) GetByDatasetID(ctx context.Context, datasetID s
 	return files, total, nil
 }
 
+func (r *filePostgresRepository) GetByDatasetIDs(ctx context.Context, datasetIDs []string) ([]*domain.File, error) {
+	files := []*domain.File{}
+	if len(datasetIDs) == 0 {
+		return files, nil
+	}
+
+	query, args, err := sqlx.In(`
+		SELECT id, name, original_name, extension, size, mime_type, path, storage_path,
+		       storage_type, dataset_id, uploaded_by, status, created_at, updated_at
+		FROM files
+		WHERE dataset_id IN (?) AND status != 'deleted'
+		ORDER BY created_at DESC
+	`, datasetIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dataset files: %w", err)
+	}
+
+	conn := db.Conn(ctx, r.db)
+	if err := conn.SelectContext(ctx, &files, conn.Rebind(query), args...); err != nil {
+		return nil, fmt.Errorf("failed to get dataset files: %w", err)
+	}
+	return files, nil
+}
+
 func (r *filePostgresRepository) handleError(err error) error {
 	if err == nil {
 		return nil
@@ -10,8 +10,13 @@ import (
 type Usecase interface {
 	GetByID(ctx context.Context, id string) (*domain.FileInfo, error)
 	List(ctx context.Context, req *domain.ListFilesRequest) (*domain.FileListResponse, error)
+	// Open returns the file with id and a reader of its content, which the
+	// caller closes
+	Open(ctx context.Context, id string) (*domain.FileInfo, io.ReadCloser, error)
 	Upload(ctx context.Context, fileName string, fileSize int64, mimeType string, reader io.Reader, datasetID *string, userID string) (*domain.UploadResponse, error)
 	UpdateStatus(ctx context.Context, id string, status domain.FileStatus) error
 	Delete(ctx context.Context, id string) error
 	GetByDatasetID(ctx context.Context, datasetID string, page, limit int) (*domain.FileListResponse, error)
+	// GetByDatasetIDs returns the files of several datasets by dataset ID
+	GetByDatasetIDs(ctx context.Context, datasetIDs []string) (map[string][]domain.FileInfo, error)
 }
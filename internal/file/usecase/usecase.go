@@ -106,8 +106,8 @@ func (u *fileUsecase) Upload(ctx context.Context, fileName string, fileSize int6
 	}
 
 	if err := u.fileRepo.Create(ctx, file); err != nil {
-		// Rollback storage upload
-		_ = u.storage.Delete(ctx, uploadedPath)
+		// Rollback storage upload, even when the client is gone
+		_ = u.storage.Delete(context.WithoutCancel(ctx), uploadedPath)
 		return nil, fmt.Errorf("failed to create file record: %w", err)
 	}
 
@@ -122,6 +122,19 @@ func (u *fileUsecase) Upload(ctx context.Context, fileName string, fileSize int6
 	}, nil
 }
 
+func (u *fileUsecase) Open(ctx context.Context, id string) (*domain.FileInfo, io.ReadCloser, error) {
+	file, err := u.fileRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get file: %w", err)
+	}
+
+	content, err := u.storage.Open(ctx, file.Path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	return u.toInfo(file), content, nil
+}
+
 func (u *fileUsecase) UpdateStatus(ctx context.Context, id string, status domain.FileStatus) error {
 	if err := u.fileRepo.UpdateStatus(ctx, id, status); err != nil {
 		return fmt.Errorf("failed to update file status: %w", err)
@@ -181,6 +194,21 @@ func (u *fileUsecase) GetByDatasetID(ctx context.Context, datasetID string, page
 	}, nil
 }
 
+func (u *fileUsecase) GetByDatasetIDs(ctx context.Context, datasetIDs []string) (map[string][]domain.FileInfo, error) {
+	files, err := u.fileRepo.GetByDatasetIDs(ctx, datasetIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dataset files: %w", err)
+	}
+
+	byDataset := make(map[string][]domain.FileInfo, len(datasetIDs))
+	for _, file := range files {
+		if file.DatasetID != nil {
+			byDataset[*file.DatasetID] = append(byDataset[*file.DatasetID], *u.toInfo(file))
+		}
+	}
+	return byDataset, nil
+}
+
 func (u *fileUsecase) toInfo(file *domain.File) *domain.FileInfo {
 	return &domain.FileInfo{
 		ID:           file.ID,
@@ -0,0 +1,251 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"time"
+
+	"portal-data-backend/internal/gateway/domain"
+	"portal-data-backend/internal/gateway/usecase"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"golang.org/x/net/webdav"
+)
+
+// fileSystem shows the tree of the gateway to webdav. It lives for one
+// request and keeps the entries it listed, as webdav opens every entry of
+// a listing again to read its properties.
+type fileSystem struct {
+	gateway usecase.Usecase
+	entries map[string]*domain.Entry
+}
+
+func newFileSystem(gateway usecase.Usecase) *fileSystem {
+	return &fileSystem{gateway: gateway, entries: map[string]*domain.Entry{}}
+}
+
+// entry returns the entry at name
+func (fs *fileSystem) entry(ctx context.Context, name string) (*domain.Entry, error) {
+	name = path.Clean("/" + name)
+	if entry, ok := fs.entries[name]; ok {
+		return entry, nil
+	}
+	entry, err := fs.gateway.Stat(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	fs.entries[name] = entry
+	return entry, nil
+}
+
+// list lists the entries of the folder at name
+func (fs *fileSystem) list(ctx context.Context, name string) ([]*domain.Entry, error) {
+	name = path.Clean("/" + name)
+	entries, err := fs.gateway.List(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		fs.entries[path.Join(name, entry.Name)] = entry
+	}
+	return entries, nil
+}
+
+func (fs *fileSystem) Stat(ctx context.Context, name string) (os.FileInfo, error) {
+	entry, err := fs.entry(ctx, name)
+	if err != nil {
+		return nil, osError(err)
+	}
+	return fileInfo{entry}, nil
+}
+
+func (fs *fileSystem) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_APPEND) != 0 {
+		return nil, os.ErrPermission
+	}
+	entry, err := fs.entry(ctx, name)
+	if err != nil {
+		return nil, osError(err)
+	}
+	if entry.Folder {
+		return &folder{ctx: ctx, fs: fs, name: name, entry: entry}, nil
+	}
+	return &file{ctx: ctx, gateway: fs.gateway, name: name, entry: entry}, nil
+}
+
+func (fs *fileSystem) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
+	return os.ErrPermission
+}
+
+func (fs *fileSystem) RemoveAll(ctx context.Context, name string) error {
+	return os.ErrPermission
+}
+
+func (fs *fileSystem) Rename(ctx context.Context, oldName, newName string) error {
+	return os.ErrPermission
+}
+
+// osError returns the errors webdav tells apart as those of package os
+func osError(err error) error {
+	if errors.Is(err, pkgErrors.ErrNotFound) {
+		return os.ErrNotExist
+	}
+	return err
+}
+
+// fileInfo shows an entry as a file or a directory
+type fileInfo struct {
+	entry *domain.Entry
+}
+
+func (fi fileInfo) Name() string       { return fi.entry.Name }
+func (fi fileInfo) Size() int64        { return fi.entry.Size }
+func (fi fileInfo) ModTime() time.Time { return fi.entry.ModTime }
+func (fi fileInfo) IsDir() bool        { return fi.entry.Folder }
+func (fi fileInfo) Sys() interface{}   { return nil }
+
+func (fi fileInfo) Mode() os.FileMode {
+	if fi.entry.Folder {
+		return os.ModeDir | 0555
+	}
+	return 0444
+}
+
+// ContentType implements webdav.ContentTyper, so that listings do not read
+// files to detect their type
+func (fi fileInfo) ContentType(ctx context.Context) (string, error) {
+	if fi.entry.ContentType == "" {
+		return "application/octet-stream", nil
+	}
+	return fi.entry.ContentType, nil
+}
+
+// etag is the entity tag of entry, the one webdav gives it
+func etag(entry *domain.Entry) string {
+	return fmt.Sprintf(`"%x%x"`, entry.ModTime.UnixNano(), entry.Size)
+}
+
+// folder is an open folder
+type folder struct {
+	ctx   context.Context
+	fs    *fileSystem
+	name  string
+	entry *domain.Entry
+	// listed are the entries Readdir has not returned yet, nil until the
+	// folder is listed
+	listed []os.FileInfo
+}
+
+func (f *folder) Readdir(count int) ([]os.FileInfo, error) {
+	if f.listed == nil {
+		entries, err := f.fs.list(f.ctx, f.name)
+		if err != nil {
+			return nil, osError(err)
+		}
+		f.listed = make([]os.FileInfo, len(entries))
+		for i, entry := range entries {
+			f.listed[i] = fileInfo{entry}
+		}
+	}
+
+	if count <= 0 || count >= len(f.listed) {
+		infos := f.listed
+		f.listed = f.listed[len(f.listed):]
+		if count > 0 && len(infos) == 0 {
+			return nil, io.EOF
+		}
+		return infos, nil
+	}
+	infos := f.listed[:count]
+	f.listed = f.listed[count:]
+	return infos, nil
+}
+
+func (f *folder) Stat() (os.FileInfo, error) { return fileInfo{f.entry}, nil }
+func (f *folder) Read(p []byte) (int, error) { return 0, fmt.Errorf("%s is a folder", f.name) }
+func (f *folder) Seek(offset int64, whence int) (int64, error) {
+	return 0, fmt.Errorf("%s is a folder", f.name)
+}
+func (f *folder) Write(p []byte) (int, error) { return 0, os.ErrPermission }
+func (f *folder) Close() error                { return nil }
+
+// file is an open file. Its content is opened on the first read, so that
+// files opened only to read their properties are not fetched. Content is
+// streamed from the start, so reads after a seek forward skip to the
+// offset and reads after a seek back open it again.
+type file struct {
+	ctx     context.Context
+	gateway usecase.Usecase
+	name    string
+	entry   *domain.Entry
+
+	content io.ReadCloser
+	// offset is where the next read starts, read where content is at
+	offset int64
+	read   int64
+}
+
+func (f *file) Read(p []byte) (int, error) {
+	if f.content != nil && f.read > f.offset {
+		f.content.Close()
+		f.content = nil
+	}
+	if f.content == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+	if f.read < f.offset {
+		skipped, err := io.CopyN(io.Discard, f.content, f.offset-f.read)
+		f.read += skipped
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.content.Read(p)
+	f.offset += int64(n)
+	f.read += int64(n)
+	return n, err
+}
+
+// open opens the content of the file from the start
+func (f *file) open() error {
+	content, err := f.gateway.Open(f.ctx, f.name)
+	if err != nil {
+		return err
+	}
+	f.content, f.read = content, 0
+	return nil
+}
+
+func (f *file) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += f.entry.Size
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("seek %s: negative offset", f.name)
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+func (f *file) Close() error {
+	if f.content == nil {
+		return nil
+	}
+	return f.content.Close()
+}
+
+func (f *file) Stat() (os.FileInfo, error) { return fileInfo{f.entry}, nil }
+func (f *file) Readdir(count int) ([]os.FileInfo, error) {
+	return nil, fmt.Errorf("%s is not a folder", f.name)
+}
+func (f *file) Write(p []byte) (int, error) { return 0, os.ErrPermission }
@@ -0,0 +1,158 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+
+	"portal-data-backend/infrastructure/http/middleware"
+	"portal-data-backend/infrastructure/http/problem"
+	"portal-data-backend/infrastructure/http/response"
+	"portal-data-backend/infrastructure/logger"
+	"portal-data-backend/internal/gateway/usecase"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"github.com/go-chi/chi/v5"
+	"golang.org/x/net/webdav"
+)
+
+func init() {
+	// chi routes the methods it knows only
+	chi.RegisterMethod("PROPFIND")
+}
+
+type Handler struct {
+	gatewayUsecase usecase.Usecase
+	// locks is never written, as locking is not allowed, but webdav
+	// requires one to answer PROPFIND
+	locks webdav.LockSystem
+}
+
+func NewHandler(gatewayUsecase usecase.Usecase) *Handler {
+	return &Handler{
+		gatewayUsecase: gatewayUsecase,
+		locks:          webdav.NewMemLS(),
+	}
+}
+
+// Options tells WebDAV clients the gateway speaks WebDAV, read-only: the
+// methods writing or locking are not allowed
+func (h *Handler) Options(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("DAV", "1")
+	w.Header().Set("Allow", "OPTIONS, GET, HEAD, PROPFIND")
+	w.WriteHeader(http.StatusOK)
+}
+
+// Get streams a file, with ranges, or lists a folder
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	_, name := splitPath(r)
+	fs := newFileSystem(h.gatewayUsecase)
+	entry, err := fs.entry(r.Context(), name)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	if entry.Folder {
+		entries, err := fs.list(r.Context(), name)
+		if err != nil {
+			h.handleError(w, r, err)
+			return
+		}
+		response.OK(w, response.CodeSuccess, "Folder listed successfully", entries)
+		return
+	}
+
+	f := &file{ctx: r.Context(), gateway: h.gatewayUsecase, name: name, entry: entry}
+	defer f.Close()
+	// Files are opened before any header is sent, so that storage errors
+	// are still answered as such
+	if r.Method == http.MethodGet {
+		if err := f.open(); err != nil {
+			h.handleError(w, r, err)
+			return
+		}
+	}
+
+	if entry.ContentType != "" {
+		w.Header().Set("Content-Type", entry.ContentType)
+	}
+	w.Header().Set("ETag", etag(entry))
+	http.ServeContent(w, r, entry.Name, entry.ModTime, f)
+}
+
+// Propfind answers PROPFIND with webdav. Listing the whole catalog at once
+// is refused: requests have a Depth of 0 or 1.
+func (h *Handler) Propfind(w http.ResponseWriter, r *http.Request) {
+	if depth := r.Header.Get("Depth"); depth != "0" && depth != "1" {
+		response.Forbidden(w, response.CodeForbidden, "PROPFIND requests must have a Depth of 0 or 1", nil)
+		return
+	}
+
+	prefix, name := splitPath(r)
+	fs := newFileSystem(h.gatewayUsecase)
+	// webdav answers every error but a missing entry with 405
+	if _, err := fs.entry(r.Context(), name); err != nil && !errors.Is(err, pkgErrors.ErrNotFound) {
+		h.handleError(w, r, err)
+		return
+	}
+
+	dav := &webdav.Handler{
+		Prefix:     prefix,
+		FileSystem: fs,
+		LockSystem: h.locks,
+		Logger: func(r *http.Request, err error) {
+			if err != nil && !os.IsNotExist(err) {
+				logger.FromContext(r.Context()).Error("Failed to answer PROPFIND of %s: %v", r.URL.Path, err)
+			}
+		},
+	}
+	dav.ServeHTTP(w, r)
+}
+
+// splitPath splits the path of r into the prefix the gateway is mounted at,
+// which depends on the API version, and the path of an entry
+func splitPath(r *http.Request) (prefix, name string) {
+	prefix = strings.TrimSuffix(chi.RouteContext(r.Context()).RoutePattern(), "/*")
+	name = strings.TrimPrefix(r.URL.Path, prefix)
+	if name == "" {
+		name = "/"
+	}
+	return prefix, name
+}
+
+// errorMapper maps the errors of the gateway on top of the shared ones
+var errorMapper = problem.Default.With(
+	problem.Mapping{Err: pkgErrors.ErrNotFound, Status: http.StatusNotFound, Code: response.CodeNotFound, Message: "No published dataset file at this path"},
+)
+
+func (h *Handler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	errorMapper.Write(w, r, err)
+}
+
+// requireAPIKey refuses requests without an API key, asking for one as the
+// password of Basic auth, which is how WebDAV clients send credentials
+func requireAPIKey(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if middleware.APIClientFrom(r.Context()) == nil {
+			w.Header().Set("WWW-Authenticate", `Basic realm="datasets", charset="UTF-8"`)
+			response.Unauthorized(w, response.CodeUnauthorized, "An API key is required, sent in the "+middleware.APIKeyHeader+" header or as the password of Basic auth", nil)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RegisterRoutes mounts the gateway at /dav for developer applications
+func RegisterRoutes(r chi.Router, handler *Handler) {
+	r.Group(func(r chi.Router) {
+		r.Use(requireAPIKey)
+		for _, pattern := range []string{"/dav", "/dav/*"} {
+			r.Options(pattern, handler.Options)
+			r.Get(pattern, handler.Get)
+			r.Head(pattern, handler.Get)
+			r.MethodFunc("PROPFIND", pattern, handler.Propfind)
+		}
+	})
+}
@@ -0,0 +1,18 @@
+package http
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+)
+
+// Describe adds the gateway routes to the API specification. Paths below
+// /dav name the folders and files of the gateway; folders are listed as
+// JSON. WebDAV clients also send PROPFIND, which OpenAPI cannot describe.
+func Describe(spec *openapi.Spec) {
+	api := spec.Tag("gateway", "Read-only WebDAV gateway over the files of published datasets")
+	api.Options("/dav", "Get the WebDAV methods of the gateway").Secured(openapi.APIKey).ReturnsNothing(http.StatusOK)
+	api.Get("/dav", "Download a published dataset file").Secured(openapi.APIKey).
+		ReturnsFile(http.StatusOK, "application/octet-stream")
+	api.Head("/dav", "Get the size and type of a published dataset file").Secured(openapi.APIKey).ReturnsNothing(http.StatusOK)
+}
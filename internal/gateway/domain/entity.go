@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"time"
+)
+
+// Entry is a folder or a file of the gateway. The root folder holds a
+// folder for each organization with published datasets, named by its
+// slug; those hold a folder for each published dataset, named by its slug,
+// holding the ready files of the dataset.
+type Entry struct {
+	Name   string `db:"name" json:"name"`
+	Folder bool   `db:"-" json:"folder"`
+	// Size and ContentType are those of files; folders have neither
+	Size        int64     `db:"size" json:"size,omitempty"`
+	ContentType string    `db:"content_type" json:"content_type,omitempty"`
+	ModTime     time.Time `db:"mod_time" json:"modified_at"`
+	// FileID is the uploaded file a file entry serves
+	FileID string `db:"file_id" json:"file_id,omitempty"`
+}
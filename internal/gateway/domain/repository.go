@@ -0,0 +1,19 @@
+package domain
+
+import (
+	"context"
+)
+
+// Repository lists the published part of the catalog as folders and files.
+// Folders are sorted by name, files by upload time.
+type Repository interface {
+	// Organizations lists the folders of the organizations with published
+	// datasets
+	Organizations(ctx context.Context) ([]*Entry, error)
+	// Datasets lists the folders of the published datasets of the
+	// organization with slug organization
+	Datasets(ctx context.Context, organization string) ([]*Entry, error)
+	// Files lists the ready files of the published dataset with slug
+	// dataset, or returns ErrNotFound when there is no such dataset
+	Files(ctx context.Context, organization, dataset string) ([]*Entry, error)
+}
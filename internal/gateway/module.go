@@ -0,0 +1,48 @@
+// Package gateway is the module serving the files of published datasets
+// over WebDAV, for analysts to mount.
+package gateway
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	"portal-data-backend/internal/app"
+	delivery "portal-data-backend/internal/gateway/delivery/http"
+	"portal-data-backend/internal/gateway/repository"
+	"portal-data-backend/internal/gateway/usecase"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Module maps organizations, their published datasets and the files of
+// those to read-only folders, served to developer applications with the
+// file module
+type Module struct {
+	handler *delivery.Handler
+}
+
+// Name implements app.Module
+func (m *Module) Name() string {
+	return "gateway"
+}
+
+// Register implements app.Module
+func (m *Module) Register(deps *app.Deps) error {
+	if deps.Services.Files == nil {
+		return app.MissingServiceError("file")
+	}
+
+	repo := repository.NewGatewayPostgresRepository(deps.DB)
+	m.handler = delivery.NewHandler(usecase.NewGatewayUsecase(repo, deps.Services.Files))
+	return nil
+}
+
+// Routes implements app.Module
+func (m *Module) Routes(r chi.Router, auth func(http.Handler) http.Handler) {
+	delivery.RegisterRoutes(r, m.handler)
+}
+
+// Describe implements app.Module
+func (m *Module) Describe(spec *openapi.Spec) {
+	delivery.Describe(spec)
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"portal-data-backend/infrastructure/db"
+	"portal-data-backend/internal/gateway/domain"
+	"portal-data-backend/pkg/errors"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type gatewayPostgresRepository struct {
+	db *sqlx.DB
+}
+
+func NewGatewayPostgresRepository(db *sqlx.DB) domain.Repository {
+	return &gatewayPostgresRepository{db: db}
+}
+
+// published is the condition keeping d, a dataset of organization o, to
+// the published datasets of live organizations
+const published = `o.deleted_at IS NULL AND d.deleted_at IS NULL AND d.status = 'published'`
+
+func (r *gatewayPostgresRepository) Organizations(ctx context.Context) ([]*domain.Entry, error) {
+	query := `
+		SELECT o.slug AS name, MAX(d.updated_at) AS mod_time
+		FROM organizations o
+		JOIN datasets d ON d.organization_id = o.id
+		WHERE ` + published + ` AND ` + db.InTenant(ctx, "o.tenant_id") + `
+		GROUP BY o.slug
+		ORDER BY o.slug
+	`
+
+	var entries []*domain.Entry
+	if err := db.Conn(ctx, r.db).SelectContext(ctx, &entries, query); err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+	return folders(entries), nil
+}
+
+func (r *gatewayPostgresRepository) Datasets(ctx context.Context, organization string) ([]*domain.Entry, error) {
+	query := `
+		SELECT d.slug AS name, d.updated_at AS mod_time
+		FROM datasets d
+		JOIN organizations o ON o.id = d.organization_id
+		WHERE o.slug = $1 AND ` + published + ` AND ` + db.InTenant(ctx, "o.tenant_id") + `
+		ORDER BY d.slug
+	`
+
+	var entries []*domain.Entry
+	if err := db.Conn(ctx, r.db).SelectContext(ctx, &entries, query, organization); err != nil {
+		return nil, fmt.Errorf("failed to list datasets: %w", err)
+	}
+	return folders(entries), nil
+}
+
+func (r *gatewayPostgresRepository) Files(ctx context.Context, organization, dataset string) ([]*domain.Entry, error) {
+	query := `
+		SELECT d.id
+		FROM datasets d
+		JOIN organizations o ON o.id = d.organization_id
+		WHERE o.slug = $1 AND d.slug = $2 AND ` + published + ` AND ` + db.InTenant(ctx, "o.tenant_id")
+
+	var datasetID string
+	if err := db.Conn(ctx, r.db).GetContext(ctx, &datasetID, query, organization, dataset); err != nil {
+		return nil, r.handleError(err)
+	}
+
+	query = `
+		SELECT id AS file_id, original_name AS name, size, mime_type AS content_type, updated_at AS mod_time
+		FROM files
+		WHERE dataset_id = $1 AND status = 'ready'
+		ORDER BY created_at, id
+	`
+
+	var entries []*domain.Entry
+	if err := db.Conn(ctx, r.db).SelectContext(ctx, &entries, query, datasetID); err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+	return entries, nil
+}
+
+// folders marks entries as folders
+func folders(entries []*domain.Entry) []*domain.Entry {
+	for _, entry := range entries {
+		entry.Folder = true
+	}
+	return entries
+}
+
+func (r *gatewayPostgresRepository) handleError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("dataset not found: %w", errors.ErrNotFound)
+	}
+	return fmt.Errorf("database error: %w", err)
+}
@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	fileDomain "portal-data-backend/internal/file/domain"
+	"portal-data-backend/internal/gateway/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+)
+
+// FileOpener is the part of the file module file contents are read through
+type FileOpener interface {
+	Open(ctx context.Context, id string) (*fileDomain.FileInfo, io.ReadCloser, error)
+}
+
+// Usecase maps the published datasets of the catalog to a tree of folders,
+// like /<organization>/<dataset>/<file>, for analysts to mount read-only.
+// Paths that are not in the tree are not found.
+type Usecase interface {
+	// Stat returns the entry at path
+	Stat(ctx context.Context, path string) (*domain.Entry, error)
+	// List lists the entries of the folder at path
+	List(ctx context.Context, path string) ([]*domain.Entry, error)
+	// Open returns a reader of the content of the file at path, which the
+	// caller closes
+	Open(ctx context.Context, path string) (io.ReadCloser, error)
+}
+
+type gatewayUsecase struct {
+	repo  domain.Repository
+	files FileOpener
+}
+
+// NewGatewayUsecase creates the gateway usecase
+func NewGatewayUsecase(repo domain.Repository, files FileOpener) Usecase {
+	return &gatewayUsecase{repo: repo, files: files}
+}
+
+func (u *gatewayUsecase) Stat(ctx context.Context, name string) (*domain.Entry, error) {
+	parent, base := path.Split(clean(name))
+	if base == "" {
+		return &domain.Entry{Name: "/", Folder: true}, nil
+	}
+
+	entries, err := u.List(ctx, parent)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.Name == base {
+			return entry, nil
+		}
+	}
+	return nil, fmt.Errorf("%s: %w", name, pkgErrors.ErrNotFound)
+}
+
+func (u *gatewayUsecase) List(ctx context.Context, name string) ([]*domain.Entry, error) {
+	var segments []string
+	if name = clean(name); name != "/" {
+		segments = strings.Split(strings.TrimPrefix(name, "/"), "/")
+	}
+
+	var entries []*domain.Entry
+	var err error
+	switch len(segments) {
+	case 0:
+		entries, err = u.repo.Organizations(ctx)
+	case 1:
+		entries, err = u.repo.Datasets(ctx, segments[0])
+		// Organizations without published datasets are not in the tree
+		if err == nil && len(entries) == 0 {
+			err = fmt.Errorf("%s: %w", name, pkgErrors.ErrNotFound)
+		}
+	case 2:
+		entries, err = u.repo.Files(ctx, segments[0], segments[1])
+		if err == nil {
+			entries = fileNames(entries)
+		}
+	default:
+		err = fmt.Errorf("%s: %w", name, pkgErrors.ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (u *gatewayUsecase) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	entry, err := u.Stat(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Folder {
+		return nil, fmt.Errorf("%w: %s is a folder", pkgErrors.ErrInvalidInput, name)
+	}
+
+	_, content, err := u.files.Open(ctx, entry.FileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	return content, nil
+}
+
+// clean returns name as an absolute path without trailing slash
+func clean(name string) string {
+	return path.Clean("/" + name)
+}
+
+// fileNames names files by their original name, made safe for a path and
+// numbered like "data (2).csv" when an earlier file of the dataset has the
+// same name, and sorts them by name
+func fileNames(entries []*domain.Entry) []*domain.Entry {
+	used := map[string]bool{}
+	for _, entry := range entries {
+		name := strings.Map(func(r rune) rune {
+			if r == '/' || r == '\\' {
+				return '-'
+			}
+			return r
+		}, strings.TrimSpace(entry.Name))
+		if name == "" || name == "." || name == ".." {
+			name = entry.FileID
+		}
+
+		unique := name
+		ext := path.Ext(name)
+		for n := 2; used[unique]; n++ {
+			unique = fmt.Sprintf("%s (%d)%s", strings.TrimSuffix(name, ext), n, ext)
+		}
+		used[unique] = true
+		entry.Name = unique
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+	return entries
+}
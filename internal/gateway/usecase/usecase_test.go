@@ -0,0 +1,107 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	fileDomain "portal-data-backend/internal/file/domain"
+	"portal-data-backend/internal/gateway/domain"
+	"portal-data-backend/internal/gateway/usecase"
+	pkgerrors "portal-data-backend/pkg/errors"
+)
+
+// mockRepository publishes a population dataset of bappeda with two files
+// uploaded under the same name, and nothing else
+type mockRepository struct{}
+
+func (mockRepository) Organizations(ctx context.Context) ([]*domain.Entry, error) {
+	return []*domain.Entry{{Name: "bappeda", Folder: true}}, nil
+}
+
+func (mockRepository) Datasets(ctx context.Context, organization string) ([]*domain.Entry, error) {
+	if organization != "bappeda" {
+		return nil, nil
+	}
+	return []*domain.Entry{{Name: "population", Folder: true}}, nil
+}
+
+func (mockRepository) Files(ctx context.Context, organization, dataset string) ([]*domain.Entry, error) {
+	if organization != "bappeda" || dataset != "population" {
+		return nil, pkgerrors.ErrNotFound
+	}
+	return []*domain.Entry{
+		{Name: "data.csv", Size: 5, FileID: "file-1"},
+		{Name: "notes/2024.txt", Size: 3, FileID: "file-2"},
+		{Name: "data.csv", Size: 4, FileID: "file-3"},
+	}, nil
+}
+
+// mockFiles serves the ID of a file as its content
+type mockFiles struct{}
+
+func (mockFiles) Open(ctx context.Context, id string) (*fileDomain.FileInfo, io.ReadCloser, error) {
+	return &fileDomain.FileInfo{ID: id}, io.NopCloser(strings.NewReader(id)), nil
+}
+
+// Test published datasets are listed as folders of their organization,
+// holding their files under names unique within the dataset
+func TestGateway_List(t *testing.T) {
+	ctx := context.Background()
+	gateway := usecase.NewGatewayUsecase(mockRepository{}, mockFiles{})
+
+	entries, err := gateway.List(ctx, "/")
+	if err != nil || len(entries) != 1 || entries[0].Name != "bappeda" || !entries[0].Folder {
+		t.Fatalf("Expected the organization folder at the root, got %v (%v)", entries, err)
+	}
+
+	entries, err = gateway.List(ctx, "/bappeda/population/")
+	if err != nil {
+		t.Fatalf("Failed to list dataset: %v", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name)
+	}
+	if got := strings.Join(names, ","); got != "data (2).csv,data.csv,notes-2024.txt" {
+		t.Errorf("Expected unique file names sorted, got %s", got)
+	}
+	if entries[0].FileID != "file-3" {
+		t.Errorf("Expected the later file numbered, got %s", entries[0].FileID)
+	}
+
+	for _, path := range []string{"/dinkes", "/bappeda/budget", "/bappeda/population/data.csv/more"} {
+		if _, err := gateway.List(ctx, path); !errors.Is(err, pkgerrors.ErrNotFound) {
+			t.Errorf("Expected %s not found, got %v", path, err)
+		}
+	}
+}
+
+// Test files are opened by path and folders are not
+func TestGateway_Open(t *testing.T) {
+	ctx := context.Background()
+	gateway := usecase.NewGatewayUsecase(mockRepository{}, mockFiles{})
+
+	entry, err := gateway.Stat(ctx, "/bappeda/population/data (2).csv")
+	if err != nil || entry.FileID != "file-3" {
+		t.Fatalf("Expected the numbered file, got %+v (%v)", entry, err)
+	}
+
+	content, err := gateway.Open(ctx, "/bappeda/population/data.csv")
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	defer content.Close()
+	if data, _ := io.ReadAll(content); string(data) != "file-1" {
+		t.Errorf("Expected the content of file-1, got %q", data)
+	}
+
+	if _, err := gateway.Open(ctx, "/bappeda/population"); !errors.Is(err, pkgerrors.ErrInvalidInput) {
+		t.Errorf("Expected a folder not to be opened, got %v", err)
+	}
+	if _, err := gateway.Stat(ctx, "/bappeda/population/missing.csv"); !errors.Is(err, pkgerrors.ErrNotFound) {
+		t.Errorf("Expected a missing file not found, got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package http
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/auth"
+	"portal-data-backend/infrastructure/http/httputil"
+	"portal-data-backend/infrastructure/http/middleware"
+	"portal-data-backend/infrastructure/http/problem"
+	"portal-data-backend/infrastructure/http/response"
+	impersonationDomain "portal-data-backend/internal/impersonation/domain"
+	"portal-data-backend/internal/impersonation/usecase"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	impersonationUsecase usecase.Usecase
+}
+
+func NewHandler(impersonationUsecase usecase.Usecase) *Handler {
+	return &Handler{
+		impersonationUsecase: impersonationUsecase,
+	}
+}
+
+// Impersonate issues the admin signed in a token acting as a user
+func (h *Handler) Impersonate(w http.ResponseWriter, r *http.Request) {
+	userID, ok := httputil.UUIDParam(w, r, "userId")
+	if !ok {
+		return
+	}
+
+	actor := impersonationDomain.Actor{ImpersonatorID: middleware.Impersonator(r.Context())}
+	user := auth.FromContext(r.Context())
+	actor.UserID = user.UserID
+	actor.Email = user.Email
+
+	resp, err := h.impersonationUsecase.Impersonate(r.Context(), userID, actor)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Impersonation token issued successfully", resp)
+}
+
+// errorMapper maps the errors of the impersonation module on top of the
+// shared ones
+var errorMapper = problem.Default.With(
+	problem.Mapping{Err: pkgErrors.ErrNotFound, Status: http.StatusNotFound, Code: response.CodeNotFound, Message: "User not found"},
+)
+
+func (h *Handler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	errorMapper.Write(w, r, err)
+}
+
+// RegisterRoutes registers impersonation for users with one of adminRoles
+func RegisterRoutes(r chi.Router, handler *Handler, auth func(http.Handler) http.Handler, adminRoles []string) {
+	r.Route("/admin/impersonate", func(r chi.Router) {
+		r.Use(auth)
+		r.Use(middleware.RequireRole(adminRoles...))
+		r.Post("/{userId}", handler.Impersonate)
+	})
+}
@@ -0,0 +1,14 @@
+package http
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	impersonationDomain "portal-data-backend/internal/impersonation/domain"
+)
+
+// Describe adds the impersonation routes to the API specification
+func Describe(spec *openapi.Spec) {
+	api := spec.Tag("impersonation", "Admins acting as users")
+	api.Post("/admin/impersonate/{userId}", "Impersonate user").Returns(http.StatusOK, impersonationDomain.ImpersonationResponse{})
+}
@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"time"
+
+	userDomain "portal-data-backend/internal/user/domain"
+)
+
+// Actor is the admin asking to impersonate a user. ImpersonatorID is set
+// when the admin is impersonated already.
+type Actor struct {
+	UserID         string
+	Email          string
+	ImpersonatorID string
+}
+
+// ImpersonationResponse is an access token acting as a user on behalf of
+// the admin ImpersonatorID. It expires at ExpiresAt and cannot be refreshed.
+type ImpersonationResponse struct {
+	AccessToken    string              `json:"access_token"`
+	TokenType      string              `json:"token_type"`
+	ExpiresIn      int64               `json:"expires_in"`
+	ExpiresAt      time.Time           `json:"expires_at"`
+	User           userDomain.UserInfo `json:"user"`
+	ImpersonatorID string              `json:"impersonator_id"`
+}
@@ -0,0 +1,47 @@
+// Package impersonation is the module letting admins act as users.
+package impersonation
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	"portal-data-backend/internal/app"
+	delivery "portal-data-backend/internal/impersonation/delivery/http"
+	"portal-data-backend/internal/impersonation/usecase"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Module issues admins short-lived tokens acting as users; every request
+// made with them is audited under both
+type Module struct {
+	handler    *delivery.Handler
+	adminRoles []string
+}
+
+// Name implements app.Module
+func (m *Module) Name() string {
+	return "impersonation"
+}
+
+// Register implements app.Module
+func (m *Module) Register(deps *app.Deps) error {
+	if deps.Services.Users == nil {
+		return app.MissingServiceError("user")
+	}
+
+	m.adminRoles = deps.Config.Audit.AdminRoles
+	impersonations := usecase.NewImpersonationUsecase(deps.Services.Users, deps.JWT, deps.Services.Audit, m.adminRoles)
+	m.handler = delivery.NewHandler(impersonations)
+	return nil
+}
+
+// Routes implements app.Module
+func (m *Module) Routes(r chi.Router, auth func(http.Handler) http.Handler) {
+	delivery.RegisterRoutes(r, m.handler, auth, m.adminRoles)
+}
+
+// Describe implements app.Module
+func (m *Module) Describe(spec *openapi.Spec) {
+	delivery.Describe(spec)
+}
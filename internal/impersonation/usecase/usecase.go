@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"portal-data-backend/infrastructure/audit"
+	"portal-data-backend/infrastructure/security"
+	"portal-data-backend/infrastructure/tenant"
+	"portal-data-backend/internal/impersonation/domain"
+	userDomain "portal-data-backend/internal/user/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+)
+
+// TokenIssuer signs the tokens admins impersonate users with
+type TokenIssuer interface {
+	GenerateImpersonationToken(userID, organizationID, roleID, email, tenantID string, actor security.Actor) (*security.TokenPair, error)
+}
+
+// UserReader is the part of the user module impersonated users are read
+// through
+type UserReader interface {
+	GetUserByID(ctx context.Context, id string) (*userDomain.UserInfo, error)
+}
+
+// Usecase lets admins act as users to see the portal as they do
+type Usecase interface {
+	// Impersonate issues actor a short-lived token acting as the user of
+	// userID. Admins cannot be impersonated, and impersonated requests cannot
+	// impersonate further.
+	Impersonate(ctx context.Context, userID string, actor domain.Actor) (*domain.ImpersonationResponse, error)
+}
+
+type impersonationUsecase struct {
+	users      UserReader
+	tokens     TokenIssuer
+	audit      *audit.Recorder
+	adminRoles map[string]bool
+	now        func() time.Time
+}
+
+// NewImpersonationUsecase creates the impersonation usecase. Users with one
+// of adminRoles cannot be impersonated. Impersonations are audited through
+// recorder, which may be nil.
+func NewImpersonationUsecase(users UserReader, tokens TokenIssuer, recorder *audit.Recorder, adminRoles []string) Usecase {
+	roles := make(map[string]bool, len(adminRoles))
+	for _, role := range adminRoles {
+		roles[role] = true
+	}
+	return &impersonationUsecase{
+		users:      users,
+		tokens:     tokens,
+		audit:      recorder,
+		adminRoles: roles,
+		now:        time.Now,
+	}
+}
+
+func (u *impersonationUsecase) Impersonate(ctx context.Context, userID string, actor domain.Actor) (*domain.ImpersonationResponse, error) {
+	if actor.ImpersonatorID != "" {
+		return nil, fmt.Errorf("%w: impersonated requests cannot impersonate", pkgErrors.ErrForbidden)
+	}
+	if userID == actor.UserID {
+		return nil, fmt.Errorf("%w: admins cannot impersonate themselves", pkgErrors.ErrInvalidInput)
+	}
+
+	user, err := u.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if u.adminRoles[user.RoleID] {
+		return nil, fmt.Errorf("%w: admins cannot be impersonated", pkgErrors.ErrForbidden)
+	}
+	if user.Status != "active" {
+		return nil, fmt.Errorf("%w: only active users can be impersonated", pkgErrors.ErrInvalidInput)
+	}
+
+	issuedAt := u.now()
+	token, err := u.tokens.GenerateImpersonationToken(user.ID, user.OrganizationID, user.RoleID, user.Email, tenant.ID(ctx),
+		security.Actor{UserID: actor.UserID, Email: actor.Email})
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate impersonation token: %w", err)
+	}
+	u.audit.Record(ctx, "users", user.ID, audit.ActionImpersonate, nil, nil)
+
+	return &domain.ImpersonationResponse{
+		AccessToken:    token.AccessToken,
+		TokenType:      token.TokenType,
+		ExpiresIn:      token.ExpiresIn,
+		ExpiresAt:      issuedAt.Add(time.Duration(token.ExpiresIn) * time.Second),
+		User:           *user,
+		ImpersonatorID: actor.UserID,
+	}, nil
+}
@@ -0,0 +1,99 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/infrastructure/security"
+	"portal-data-backend/internal/impersonation/domain"
+	"portal-data-backend/internal/impersonation/usecase"
+	userDomain "portal-data-backend/internal/user/domain"
+	pkgerrors "portal-data-backend/pkg/errors"
+)
+
+// mockUsers is an in-memory implementation of UserReader
+type mockUsers map[string]*userDomain.UserInfo
+
+func (m mockUsers) GetUserByID(ctx context.Context, id string) (*userDomain.UserInfo, error) {
+	user, ok := m[id]
+	if !ok {
+		return nil, pkgerrors.ErrNotFound
+	}
+	return user, nil
+}
+
+func newImpersonationUsecase() (usecase.Usecase, *security.JWTManager) {
+	users := mockUsers{
+		"user-1":  {ID: "user-1", OrganizationID: "org-1", RoleID: "editor", Email: "editor@example.com", Status: "active"},
+		"user-2":  {ID: "user-2", RoleID: "editor", Status: "inactive"},
+		"admin-2": {ID: "admin-2", RoleID: "admin", Status: "active"},
+	}
+	jwtManager := security.NewJWTManager(&config.JWTConfig{
+		Secret:              "secret",
+		AccessTokenExpiry:   time.Hour,
+		RefreshTokenExpiry:  time.Hour,
+		ImpersonationExpiry: 10 * time.Minute,
+		Issuer:              "test",
+	})
+	return usecase.NewImpersonationUsecase(users, jwtManager, nil, []string{"admin"}), jwtManager
+}
+
+// Test the token acts as the user on behalf of the admin and cannot be
+// refreshed
+func TestImpersonation_Impersonate(t *testing.T) {
+	ctx := context.Background()
+	impersonations, jwtManager := newImpersonationUsecase()
+
+	resp, err := impersonations.Impersonate(ctx, "user-1", domain.Actor{UserID: "admin-1", Email: "admin@example.com"})
+	if err != nil {
+		t.Fatalf("Failed to impersonate: %v", err)
+	}
+	if resp.ExpiresIn != 600 || resp.ImpersonatorID != "admin-1" {
+		t.Errorf("Expected a 10 minute token of admin-1, got %+v", resp)
+	}
+
+	claims, err := jwtManager.ValidateToken(resp.AccessToken)
+	if err != nil {
+		t.Fatalf("Failed to validate token: %v", err)
+	}
+	if claims.Subject != "user-1" || claims.RoleID != "editor" || claims.OrganizationID != "org-1" {
+		t.Errorf("Expected the token to act as user-1, got %+v", claims)
+	}
+	if claims.Actor == nil || claims.Actor.UserID != "admin-1" || claims.Actor.Email != "admin@example.com" {
+		t.Errorf("Expected admin-1 as the actor, got %+v", claims.Actor)
+	}
+	if _, err := jwtManager.RefreshAccessToken(resp.AccessToken); err == nil {
+		t.Errorf("Expected the impersonation token not to be refreshed")
+	}
+}
+
+// Test admins, inactive users and the admin themselves cannot be
+// impersonated, nor can impersonated requests impersonate
+func TestImpersonation_Refused(t *testing.T) {
+	ctx := context.Background()
+	impersonations, _ := newImpersonationUsecase()
+	admin := domain.Actor{UserID: "admin-1"}
+
+	tests := []struct {
+		name   string
+		userID string
+		actor  domain.Actor
+		want   error
+	}{
+		{"admin", "admin-2", admin, pkgerrors.ErrForbidden},
+		{"inactive user", "user-2", admin, pkgerrors.ErrInvalidInput},
+		{"themselves", "admin-1", admin, pkgerrors.ErrInvalidInput},
+		{"unknown user", "user-3", admin, pkgerrors.ErrNotFound},
+		{"impersonated", "user-1", domain.Actor{UserID: "user-4", ImpersonatorID: "admin-1"}, pkgerrors.ErrForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := impersonations.Impersonate(ctx, tt.userID, tt.actor); !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"portal-data-backend/internal/integration/domain"
+)
+
+// arcgisPageSize is used when the connector config has no page_size; it is
+// the default maximum record count of ArcGIS services
+const arcgisPageSize = 1000
+
+// arcgisConnector queries the features of an ArcGIS FeatureServer or
+// MapServer layer as GeoJSON. Each record holds the feature attributes and
+// its geometry as a GeoJSON geometry object under "geometry".
+type arcgisConnector struct {
+	client *http.Client
+	cfg    *domain.ConnectorConfig
+	apiKey string
+}
+
+type arcgisFeatureCollection struct {
+	Features []struct {
+		ID         interface{}            `json:"id"`
+		Geometry   map[string]interface{} `json:"geometry"`
+		Properties map[string]interface{} `json:"properties"`
+	} `json:"features"`
+	// Services report a truncated page either at the top level or in properties
+	ExceededTransferLimit bool `json:"exceededTransferLimit"`
+	Properties            struct {
+		ExceededTransferLimit bool `json:"exceededTransferLimit"`
+	} `json:"properties"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (c *arcgisConnector) Fetch(ctx context.Context, limit int) ([]Record, error) {
+	pageSize := c.cfg.PageSize
+	if pageSize <= 0 {
+		pageSize = arcgisPageSize
+	}
+
+	var records []Record
+	for limit <= 0 || len(records) < limit {
+		body, err := get(ctx, c.client, c.queryURL(len(records), pageSize), c.headers())
+		if err != nil {
+			return nil, err
+		}
+		var page arcgisFeatureCollection
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("invalid ArcGIS response: %w", err)
+		}
+		if page.Error != nil {
+			return nil, fmt.Errorf("arcgis error %d: %s", page.Error.Code, page.Error.Message)
+		}
+
+		for _, feature := range page.Features {
+			record := Record{}
+			for key, value := range feature.Properties {
+				record[key] = value
+			}
+			if _, ok := record["id"]; !ok && feature.ID != nil {
+				record["id"] = feature.ID
+			}
+			if feature.Geometry != nil {
+				record["geometry"] = feature.Geometry
+			}
+			records = append(records, record)
+		}
+
+		if len(page.Features) == 0 || !(page.ExceededTransferLimit || page.Properties.ExceededTransferLimit) {
+			break
+		}
+	}
+
+	if limit > 0 && len(records) > limit {
+		records = records[:limit]
+	}
+	return records, nil
+}
+
+// queryURL builds the query of one page of features in WGS 84
+func (c *arcgisConnector) queryURL(offset, pageSize int) string {
+	where := c.cfg.Where
+	if where == "" {
+		where = "1=1"
+	}
+
+	query := url.Values{}
+	query.Set("where", where)
+	query.Set("outFields", "*")
+	query.Set("returnGeometry", "true")
+	query.Set("outSR", "4326")
+	query.Set("f", "geojson")
+	query.Set("resultOffset", strconv.Itoa(offset))
+	query.Set("resultRecordCount", strconv.Itoa(pageSize))
+	return strings.TrimSuffix(c.cfg.URL, "/") + "/query?" + query.Encode()
+}
+
+// headers sends the API key as an ArcGIS token in a header, so it never
+// appears in request URLs recorded in run errors
+func (c *arcgisConnector) headers() map[string]string {
+	headers := requestHeaders(c.cfg, "")
+	if _, ok := headers["X-Esri-Authorization"]; !ok && c.apiKey != "" {
+		headers["X-Esri-Authorization"] = "Bearer " + c.apiKey
+	}
+	return headers
+}
@@ -0,0 +1,258 @@
+package connector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"portal-data-backend/internal/integration/domain"
+)
+
+const (
+	// bpsAPIBase is used when the connector config has no url override
+	bpsAPIBase = "https://webapi.bps.go.id/v1"
+	// bpsNationalDomain is the BPS domain code for national statistics
+	bpsNationalDomain = "0000"
+	// bpsAvailable is the data-availability value of a response with content
+	bpsAvailable = "available"
+)
+
+// bpsConnector harvests indicator tables from the BPS (Statistics Indonesia)
+// web API. Each record is one variable with its metadata and its table
+// flattened into rows under "rows".
+type bpsConnector struct {
+	client *http.Client
+	cfg    *domain.ConnectorConfig
+	apiKey string
+}
+
+type bpsVariable struct {
+	VarID   interface{} `json:"var_id"`
+	Title   string      `json:"title"`
+	SubID   interface{} `json:"sub_id"`
+	SubName string      `json:"sub_name"`
+	Def     string      `json:"def"`
+	Notes   string      `json:"notes"`
+	Unit    string      `json:"unit"`
+}
+
+type bpsPage struct {
+	Page  int `json:"page"`
+	Pages int `json:"pages"`
+}
+
+type bpsLabel struct {
+	Val   interface{} `json:"val"`
+	Label string      `json:"label"`
+}
+
+type bpsTable struct {
+	Status       string                 `json:"status"`
+	Availability string                 `json:"data-availability"`
+	Var          []bpsLabel             `json:"var"`
+	VerVar       []bpsLabel             `json:"vervar"`
+	TurVar       []bpsLabel             `json:"turvar"`
+	Tahun        []bpsLabel             `json:"tahun"`
+	TurTahun     []bpsLabel             `json:"turtahun"`
+	DataContent  map[string]interface{} `json:"datacontent"`
+}
+
+func (c *bpsConnector) Fetch(ctx context.Context, limit int) ([]Record, error) {
+	variables, err := c.listVariables(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]bool, len(c.cfg.Variables))
+	for _, id := range c.cfg.Variables {
+		wanted[id] = true
+	}
+
+	var records []Record
+	for _, variable := range variables {
+		if limit > 0 && len(records) >= limit {
+			break
+		}
+		id := fmt.Sprint(variable.VarID)
+		if len(wanted) > 0 && !wanted[id] {
+			continue
+		}
+
+		table, err := c.fetchTable(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("variable %s: %w", id, err)
+		}
+		records = append(records, bpsRecord(c.cfg.BPSDomain, variable, table))
+	}
+
+	return records, nil
+}
+
+// listVariables pages through the variables of the configured domain, limited
+// to the configured subjects if any
+func (c *bpsConnector) listVariables(ctx context.Context) ([]bpsVariable, error) {
+	subjects := c.cfg.Subjects
+	if len(subjects) == 0 {
+		subjects = []string{""}
+	}
+
+	var variables []bpsVariable
+	for _, subject := range subjects {
+		for page := 1; ; page++ {
+			path := "model/var/domain/" + url.PathEscape(c.cfg.BPSDomain)
+			if subject != "" {
+				path += "/subject/" + url.PathEscape(subject)
+			}
+			path += fmt.Sprintf("/page/%d", page)
+
+			var resp struct {
+				Status       string            `json:"status"`
+				Availability string            `json:"data-availability"`
+				Data         []json.RawMessage `json:"data"`
+			}
+			if err := c.call(ctx, path, &resp); err != nil {
+				return nil, err
+			}
+			if resp.Status != "OK" {
+				return nil, fmt.Errorf("BPS variable list failed with status %q", resp.Status)
+			}
+			if resp.Availability != bpsAvailable || len(resp.Data) < 2 {
+				break
+			}
+
+			var meta bpsPage
+			var items []bpsVariable
+			if err := decodeBPS(resp.Data[0], &meta); err != nil {
+				return nil, fmt.Errorf("invalid BPS response: %w", err)
+			}
+			if err := decodeBPS(resp.Data[1], &items); err != nil {
+				return nil, fmt.Errorf("invalid BPS response: %w", err)
+			}
+			variables = append(variables, items...)
+
+			if page >= meta.Pages {
+				break
+			}
+		}
+	}
+
+	return variables, nil
+}
+
+// fetchTable returns the dynamic table of a variable
+func (c *bpsConnector) fetchTable(ctx context.Context, varID string) (*bpsTable, error) {
+	var table bpsTable
+	path := "model/data/domain/" + url.PathEscape(c.cfg.BPSDomain) + "/var/" + url.PathEscape(varID)
+	if err := c.call(ctx, path, &table); err != nil {
+		return nil, err
+	}
+	if table.Status != "OK" {
+		return nil, fmt.Errorf("BPS data request failed with status %q", table.Status)
+	}
+	return &table, nil
+}
+
+// call requests an /api/list path. The key is a path segment in the BPS API.
+func (c *bpsConnector) call(ctx context.Context, path string, out interface{}) error {
+	endpoint := strings.TrimRight(c.cfg.URL, "/") + "/api/list/" + path + "/key/" + url.PathEscape(c.apiKey) + "/"
+
+	body, err := get(ctx, c.client, endpoint, c.cfg.Headers)
+	if err != nil {
+		return c.redact(err)
+	}
+	if err := decodeBPS(body, out); err != nil {
+		return fmt.Errorf("invalid BPS response: %w", err)
+	}
+	return nil
+}
+
+// redact removes the API key from errors so it does not end up in run errors
+func (c *bpsConnector) redact(err error) error {
+	key := url.PathEscape(c.apiKey)
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		statusErr.URL = strings.ReplaceAll(statusErr.URL, key, "***")
+		return err
+	}
+	if strings.Contains(err.Error(), key) {
+		return errors.New(strings.ReplaceAll(err.Error(), key, "***"))
+	}
+	return err
+}
+
+// decodeBPS decodes keeping numbers exact so IDs and values are not reformatted
+func decodeBPS(data []byte, out interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(out)
+}
+
+// bpsRecord flattens a variable and its table into a record. Each cell of the
+// table becomes a row with its region, category, year and period labels.
+func bpsRecord(bpsDomain string, variable bpsVariable, table *bpsTable) Record {
+	varVal := fmt.Sprint(variable.VarID)
+	if len(table.Var) > 0 {
+		varVal = fmt.Sprint(table.Var[0].Val)
+	}
+
+	// Tables without a breakdown still have a single cell per year
+	verVars := orBlank(table.VerVar)
+	turVars := orBlank(table.TurVar)
+	turTahun := orBlank(table.TurTahun)
+
+	rows := []interface{}{}
+	for _, region := range verVars {
+		for _, category := range turVars {
+			for _, year := range table.Tahun {
+				for _, period := range turTahun {
+					key := fmt.Sprint(region.Val) + varVal + fmt.Sprint(category.Val) + fmt.Sprint(year.Val) + fmt.Sprint(period.Val)
+					value, ok := table.DataContent[key]
+					if !ok {
+						continue
+					}
+					rows = append(rows, map[string]interface{}{
+						"region":   region.Label,
+						"category": category.Label,
+						"year":     year.Label,
+						"period":   period.Label,
+						"value":    value,
+					})
+				}
+			}
+		}
+	}
+
+	var period string
+	if len(table.Tahun) > 0 {
+		first, last := table.Tahun[0].Label, table.Tahun[len(table.Tahun)-1].Label
+		period = first
+		if last != first {
+			period = first + " - " + last
+		}
+	}
+
+	return Record{
+		"id":         varVal,
+		"domain":     bpsDomain,
+		"title":      variable.Title,
+		"subject_id": fmt.Sprint(variable.SubID),
+		"subject":    variable.SubName,
+		"unit":       variable.Unit,
+		"definition": variable.Def,
+		"notes":      variable.Notes,
+		"period":     period,
+		"rows":       rows,
+	}
+}
+
+func orBlank(labels []bpsLabel) []bpsLabel {
+	if len(labels) == 0 {
+		return []bpsLabel{{Val: "", Label: ""}}
+	}
+	return labels
+}
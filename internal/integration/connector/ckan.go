@@ -0,0 +1,84 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"portal-data-backend/internal/integration/domain"
+)
+
+// ckanPageSize is the number of packages requested per package_search call
+const ckanPageSize = 100
+
+// ckanConnector harvests packages from a remote CKAN catalogue
+type ckanConnector struct {
+	client *http.Client
+	cfg    *domain.ConnectorConfig
+	apiKey string
+}
+
+type ckanSearchResponse struct {
+	Success bool `json:"success"`
+	Result  struct {
+		Count   int           `json:"count"`
+		Results []interface{} `json:"results"`
+	} `json:"result"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+func (c *ckanConnector) Fetch(ctx context.Context, limit int) ([]Record, error) {
+	headers := make(map[string]string, len(c.cfg.Headers)+1)
+	for key, value := range c.cfg.Headers {
+		headers[key] = value
+	}
+	// CKAN expects the raw API token, not a bearer token
+	if _, ok := headers["Authorization"]; !ok && c.apiKey != "" {
+		headers["Authorization"] = c.apiKey
+	}
+
+	base := strings.TrimRight(c.cfg.URL, "/") + "/api/3/action/package_search"
+
+	var records []Record
+	for start := 0; ; start += ckanPageSize {
+		params := url.Values{}
+		params.Set("rows", strconv.Itoa(ckanPageSize))
+		params.Set("start", strconv.Itoa(start))
+		if c.cfg.Query != "" {
+			params.Set("fq", c.cfg.Query)
+		}
+
+		body, err := get(ctx, c.client, base+"?"+params.Encode(), headers)
+		if err != nil {
+			return nil, err
+		}
+
+		var resp ckanSearchResponse
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return nil, fmt.Errorf("invalid CKAN response: %w", err)
+		}
+		if !resp.Success {
+			message := "unknown error"
+			if resp.Error != nil {
+				message = resp.Error.Message
+			}
+			return nil, fmt.Errorf("CKAN package_search failed: %s", message)
+		}
+
+		records = append(records, toRecords(resp.Result.Results, limit-len(records))...)
+		if len(resp.Result.Results) < ckanPageSize || start+ckanPageSize >= resp.Result.Count {
+			break
+		}
+		if limit > 0 && len(records) >= limit {
+			break
+		}
+	}
+
+	return records, nil
+}
@@ -0,0 +1,368 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+
+	"portal-data-backend/internal/integration/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"github.com/robfig/cron/v3"
+)
+
+// maxResponseBytes caps how much of a remote response is read into memory
+const maxResponseBytes = 64 << 20
+
+// secretPlaceholder matches {{secrets.name}} references in connector configs
+var secretPlaceholder = regexp.MustCompile(`\{\{\s*secrets\.([A-Za-z0-9_\-]+)\s*\}\}`)
+
+// StatusError is returned when a source answers with a non-2xx status
+type StatusError struct {
+	StatusCode int
+	URL        string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d from %s", e.StatusCode, e.URL)
+}
+
+// Record is a single remote record keyed by source field name
+type Record map[string]interface{}
+
+// Connector fetches records from an external source
+type Connector interface {
+	// Fetch returns at most limit records
+	Fetch(ctx context.Context, limit int) ([]Record, error)
+}
+
+// ParseConfig decodes and validates the connector configuration of an
+// integration. {{secrets.name}} placeholders are replaced with the
+// integration's decrypted secrets so credentials never live in the config.
+func ParseConfig(integration *domain.Integration) (*domain.ConnectorConfig, error) {
+	raw, err := expandSecrets(integration.Config, integration.Secrets)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg domain.ConnectorConfig
+	if err := json.Unmarshal([]byte(raw), &cfg); err != nil {
+		return nil, fmt.Errorf("%w: invalid connector config: %v", pkgErrors.ErrInvalidInput, err)
+	}
+
+	if cfg.URL == "" && integration.Endpoint != nil {
+		cfg.URL = *integration.Endpoint
+	}
+
+	switch cfg.Connector {
+	case domain.ConnectorTypeCKAN, domain.ConnectorTypeREST, domain.ConnectorTypeCSV, domain.ConnectorTypeArcGIS, domain.ConnectorTypeOData:
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("%w: %s connector requires a url", pkgErrors.ErrInvalidInput, cfg.Connector)
+		}
+	case domain.ConnectorTypeSQL:
+		if cfg.DSN == "" || cfg.Query == "" {
+			return nil, fmt.Errorf("%w: sql connector requires dsn and query", pkgErrors.ErrInvalidInput)
+		}
+	case domain.ConnectorTypeGoogleSheets:
+		if cfg.SpreadsheetID == "" || cfg.Range == "" {
+			return nil, fmt.Errorf("%w: google_sheets connector requires spreadsheet_id and range", pkgErrors.ErrInvalidInput)
+		}
+		if cfg.CredentialsSecret == "" {
+			cfg.CredentialsSecret = defaultCredentialsSecret
+		}
+		if _, ok := integration.Secrets[cfg.CredentialsSecret]; !ok {
+			return nil, fmt.Errorf("%w: google_sheets connector requires the %q secret", pkgErrors.ErrInvalidInput, cfg.CredentialsSecret)
+		}
+	case domain.ConnectorTypeS3:
+		if cfg.URL == "" || cfg.Bucket == "" {
+			return nil, fmt.Errorf("%w: s3 connector requires url and bucket", pkgErrors.ErrInvalidInput)
+		}
+		if _, _, err := s3Endpoint(cfg.URL); err != nil {
+			return nil, err
+		}
+		if err := parseFilesTarget(&cfg); err != nil {
+			return nil, err
+		}
+	case domain.ConnectorTypeSFTP:
+		if _, _, _, err := sftpAddress(cfg.URL); err != nil {
+			return nil, err
+		}
+		if _, err := sftpHostKey(cfg.HostKey); err != nil {
+			return nil, err
+		}
+		if integration.Secrets[sftpPrivateKeySecret] == "" && integration.Secrets[sftpPasswordSecret] == "" {
+			return nil, fmt.Errorf("%w: sftp connector requires a %q or %q secret", pkgErrors.ErrInvalidInput, sftpPrivateKeySecret, sftpPasswordSecret)
+		}
+		if cfg.Pattern == "" {
+			cfg.Pattern = "*"
+		}
+		if _, err := path.Match(cfg.Pattern, ""); err != nil {
+			return nil, fmt.Errorf("%w: invalid pattern: %v", pkgErrors.ErrInvalidInput, err)
+		}
+		if err := parseFilesTarget(&cfg); err != nil {
+			return nil, err
+		}
+	case domain.ConnectorTypeBPS:
+		if integration.APIKey == nil || *integration.APIKey == "" {
+			return nil, fmt.Errorf("%w: bps connector requires an api_key", pkgErrors.ErrInvalidInput)
+		}
+		if cfg.URL == "" {
+			cfg.URL = bpsAPIBase
+		}
+		if cfg.BPSDomain == "" {
+			cfg.BPSDomain = bpsNationalDomain
+		}
+		if cfg.RowsField == "" {
+			cfg.RowsField = "rows"
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported connector %q", pkgErrors.ErrInvalidInput, cfg.Connector)
+	}
+
+	if cfg.Dedup != nil && cfg.Target != domain.HarvestTargetDatasets {
+		return nil, fmt.Errorf("%w: dedup requires the datasets target", pkgErrors.ErrInvalidInput)
+	}
+
+	switch cfg.Target {
+	case domain.HarvestTargetDatasets:
+		if err := parseDedup(cfg.Dedup); err != nil {
+			return nil, err
+		}
+	case domain.HarvestTargetDataRows:
+		if cfg.DatasetID == "" {
+			return nil, fmt.Errorf("%w: data_rows target requires dataset_id", pkgErrors.ErrInvalidInput)
+		}
+		if err := parseRowMode(&cfg); err != nil {
+			return nil, err
+		}
+	case domain.HarvestTargetFiles:
+		if cfg.Connector != domain.ConnectorTypeS3 && cfg.Connector != domain.ConnectorTypeSFTP {
+			return nil, fmt.Errorf("%w: files target requires the s3 or sftp connector", pkgErrors.ErrInvalidInput)
+		}
+		if cfg.IngestRows {
+			if cfg.DatasetID == "" {
+				return nil, fmt.Errorf("%w: ingest_rows requires dataset_id", pkgErrors.ErrInvalidInput)
+			}
+			if err := parseRowMode(&cfg); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported target %q", pkgErrors.ErrInvalidInput, cfg.Target)
+	}
+
+	if cfg.Schedule != "" {
+		if _, err := cron.ParseStandard(cfg.Schedule); err != nil {
+			return nil, fmt.Errorf("%w: invalid schedule: %v", pkgErrors.ErrInvalidInput, err)
+		}
+	}
+
+	return &cfg, nil
+}
+
+// New builds the connector configured on an integration
+func New(integration *domain.Integration, cfg *domain.ConnectorConfig, client *http.Client) (Connector, error) {
+	var apiKey string
+	if integration.APIKey != nil {
+		apiKey = *integration.APIKey
+	}
+
+	switch cfg.Connector {
+	case domain.ConnectorTypeCKAN:
+		return &ckanConnector{client: client, cfg: cfg, apiKey: apiKey}, nil
+	case domain.ConnectorTypeREST:
+		return &restConnector{client: client, cfg: cfg, apiKey: apiKey}, nil
+	case domain.ConnectorTypeCSV:
+		return &csvConnector{client: client, cfg: cfg, apiKey: apiKey}, nil
+	case domain.ConnectorTypeSQL:
+		return &sqlConnector{cfg: cfg}, nil
+	case domain.ConnectorTypeGoogleSheets:
+		return &sheetsConnector{client: client, cfg: cfg, credentials: integration.Secrets[cfg.CredentialsSecret]}, nil
+	case domain.ConnectorTypeArcGIS:
+		return &arcgisConnector{client: client, cfg: cfg, apiKey: apiKey}, nil
+	case domain.ConnectorTypeOData:
+		return &odataConnector{client: client, cfg: cfg, apiKey: apiKey}, nil
+	case domain.ConnectorTypeBPS:
+		return &bpsConnector{client: client, cfg: cfg, apiKey: apiKey}, nil
+	case domain.ConnectorTypeS3:
+		bucket, err := newS3Connector(integration, cfg, client)
+		if err != nil {
+			return nil, err
+		}
+		return bucket, nil
+	case domain.ConnectorTypeSFTP:
+		server, err := newSFTPConnector(integration, cfg, client)
+		if err != nil {
+			return nil, err
+		}
+		return server, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported connector %q", pkgErrors.ErrInvalidInput, cfg.Connector)
+	}
+}
+
+// parseFilesTarget defaults the target of a file connector to files, the only
+// target it supports
+func parseFilesTarget(cfg *domain.ConnectorConfig) error {
+	if cfg.Target == "" {
+		cfg.Target = domain.HarvestTargetFiles
+	}
+	if cfg.Target != domain.HarvestTargetFiles {
+		return fmt.Errorf("%w: %s connector only supports the files target", pkgErrors.ErrInvalidInput, cfg.Connector)
+	}
+	return nil
+}
+
+// parseRowMode defaults and validates how rows are written to a dataset
+func parseRowMode(cfg *domain.ConnectorConfig) error {
+	switch cfg.Mode {
+	case "":
+		cfg.Mode = domain.RowWriteReplace
+	case domain.RowWriteReplace:
+	case domain.RowWriteUpsert:
+		if cfg.KeyField == "" {
+			return fmt.Errorf("%w: upsert mode requires key_field", pkgErrors.ErrInvalidInput)
+		}
+	default:
+		return fmt.Errorf("%w: unsupported mode %q", pkgErrors.ErrInvalidInput, cfg.Mode)
+	}
+	return nil
+}
+
+// parseDedup validates dedup and fills its defaults
+func parseDedup(dedup *domain.DedupConfig) error {
+	if dedup == nil {
+		return nil
+	}
+
+	switch dedup.Policy {
+	case "":
+		dedup.Policy = domain.ConflictPolicyReview
+	case domain.ConflictPolicyReview, domain.ConflictPolicyLink, domain.ConflictPolicyMerge, domain.ConflictPolicyOverwrite:
+	default:
+		return fmt.Errorf("%w: unsupported dedup policy %q", pkgErrors.ErrInvalidInput, dedup.Policy)
+	}
+
+	if dedup.TitleThreshold == 0 {
+		dedup.TitleThreshold = domain.DefaultTitleThreshold
+	}
+	if dedup.ReviewThreshold == 0 {
+		dedup.ReviewThreshold = min(domain.DefaultReviewThreshold, dedup.TitleThreshold)
+	}
+	if dedup.TitleThreshold < 0 || dedup.TitleThreshold > 1 || dedup.ReviewThreshold < 0 || dedup.ReviewThreshold > dedup.TitleThreshold {
+		return fmt.Errorf("%w: dedup thresholds must satisfy 0 <= review_threshold <= title_threshold <= 1", pkgErrors.ErrInvalidInput)
+	}
+	return nil
+}
+
+// expandSecrets substitutes secret placeholders inside JSON string values
+func expandSecrets(config string, secrets map[string]string) (string, error) {
+	var missing string
+	expanded := secretPlaceholder.ReplaceAllStringFunc(config, func(match string) string {
+		name := secretPlaceholder.FindStringSubmatch(match)[1]
+		value, ok := secrets[name]
+		if !ok {
+			missing = name
+			return match
+		}
+		// Escape the value for embedding in a JSON string
+		encoded, _ := json.Marshal(value)
+		return string(encoded[1 : len(encoded)-1])
+	})
+	if missing != "" {
+		return "", fmt.Errorf("%w: config references unknown secret %q", pkgErrors.ErrInvalidInput, missing)
+	}
+	return expanded, nil
+}
+
+// Lookup resolves a dot separated path such as "organization.title"
+func (r Record) Lookup(path string) (interface{}, bool) {
+	var current interface{} = map[string]interface{}(r)
+	for _, part := range strings.Split(path, ".") {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current, ok = object[part]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, current != nil
+}
+
+// String returns the value at path as text. Objects and arrays are JSON encoded.
+func (r Record) String(path string) string {
+	value, ok := r.Lookup(path)
+	if !ok {
+		return ""
+	}
+	switch v := value.(type) {
+	case string:
+		return v
+	case map[string]interface{}, []interface{}:
+		encoded, _ := json.Marshal(v)
+		return string(encoded)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// get performs an authenticated GET and returns the response body
+func get(ctx context.Context, client *http.Client, url string, headers map[string]string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid url: %v", pkgErrors.ErrInvalidInput, err)
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &StatusError{StatusCode: resp.StatusCode, URL: url}
+	}
+	return body, nil
+}
+
+// requestHeaders merges configured headers with a bearer token from the API key
+func requestHeaders(cfg *domain.ConnectorConfig, apiKey string) map[string]string {
+	headers := make(map[string]string, len(cfg.Headers)+1)
+	for key, value := range cfg.Headers {
+		headers[key] = value
+	}
+	if _, ok := headers["Authorization"]; !ok && apiKey != "" {
+		headers["Authorization"] = "Bearer " + apiKey
+	}
+	return headers
+}
+
+// toRecords converts decoded JSON array items into records
+func toRecords(items []interface{}, limit int) []Record {
+	records := make([]Record, 0, len(items))
+	for _, item := range items {
+		if limit > 0 && len(records) >= limit {
+			break
+		}
+		if object, ok := item.(map[string]interface{}); ok {
+			records = append(records, Record(object))
+		} else {
+			records = append(records, Record{"value": item})
+		}
+	}
+	return records
+}
@@ -0,0 +1,73 @@
+package connector
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"portal-data-backend/internal/integration/domain"
+)
+
+// csvConnector downloads a CSV file whose first row holds the column names
+type csvConnector struct {
+	client *http.Client
+	cfg    *domain.ConnectorConfig
+	apiKey string
+}
+
+func (c *csvConnector) Fetch(ctx context.Context, limit int) ([]Record, error) {
+	body, err := get(ctx, c.client, c.cfg.URL, requestHeaders(c.cfg, c.apiKey))
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseCSV(body, c.cfg.Delimiter, limit)
+}
+
+// ParseCSV reads CSV data whose first row holds the column names. A UTF-8 BOM
+// is ignored and delimiter defaults to a comma.
+func ParseCSV(body []byte, delimiter string, limit int) ([]Record, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(body, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	if delimiter != "" {
+		comma, _ := utf8.DecodeRuneInString(delimiter)
+		reader.Comma = comma
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+
+	var records []Record
+	for limit <= 0 || len(records) < limit {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV row %d: %w", len(records)+2, err)
+		}
+
+		record := make(Record, len(header))
+		for i, column := range header {
+			if i < len(row) {
+				record[column] = row[i]
+			}
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
@@ -0,0 +1,141 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"portal-data-backend/internal/integration/domain"
+)
+
+// odataConnector reads the entities of an OData entity set, following
+// server-driven paging. Both the OData v4 JSON format and the v2/v3 verbose
+// format are understood. Geography and geometry properties are returned by
+// OData as GeoJSON objects and are kept as such.
+type odataConnector struct {
+	client *http.Client
+	cfg    *domain.ConnectorConfig
+	apiKey string
+}
+
+type odataPage struct {
+	Value    []interface{} `json:"value"`
+	NextLink string        `json:"@odata.nextLink"`
+	// Verbose format: d is either the result array or an object holding it
+	D json.RawMessage `json:"d"`
+}
+
+type odataVerbosePage struct {
+	Results []interface{} `json:"results"`
+	Next    string        `json:"__next"`
+}
+
+func (c *odataConnector) Fetch(ctx context.Context, limit int) ([]Record, error) {
+	next, err := c.firstPageURL()
+	if err != nil {
+		return nil, err
+	}
+
+	headers := requestHeaders(c.cfg, c.apiKey)
+	if _, ok := headers["Accept"]; !ok {
+		headers["Accept"] = "application/json"
+	}
+
+	var records []Record
+	visited := make(map[string]bool)
+	for next != "" && !visited[next] {
+		if limit > 0 && len(records) >= limit {
+			break
+		}
+		visited[next] = true
+
+		body, err := get(ctx, c.client, next, headers)
+		if err != nil {
+			return nil, err
+		}
+		items, nextLink, err := parseODataPage(body)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range toRecords(items, 0) {
+			records = append(records, stripODataAnnotations(record))
+		}
+
+		next, err = resolveLink(next, nextLink)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if limit > 0 && len(records) > limit {
+		records = records[:limit]
+	}
+	return records, nil
+}
+
+// firstPageURL adds the page size to the entity set URL unless the URL
+// already sets $top
+func (c *odataConnector) firstPageURL() (string, error) {
+	parsed, err := url.Parse(c.cfg.URL)
+	if err != nil {
+		return "", fmt.Errorf("invalid odata url: %w", err)
+	}
+	if c.cfg.PageSize > 0 && !strings.Contains(parsed.RawQuery, "$top") {
+		query := parsed.Query()
+		query.Set("$top", fmt.Sprint(c.cfg.PageSize))
+		parsed.RawQuery = query.Encode()
+	}
+	return parsed.String(), nil
+}
+
+// parseODataPage returns the entities and the next link of a response page
+func parseODataPage(body []byte) ([]interface{}, string, error) {
+	var page odataPage
+	if err := json.Unmarshal(body, &page); err != nil {
+		return nil, "", fmt.Errorf("invalid OData response: %w", err)
+	}
+	if len(page.D) == 0 {
+		return page.Value, page.NextLink, nil
+	}
+
+	var items []interface{}
+	if err := json.Unmarshal(page.D, &items); err == nil {
+		return items, "", nil
+	}
+	var verbose odataVerbosePage
+	if err := json.Unmarshal(page.D, &verbose); err != nil {
+		return nil, "", fmt.Errorf("invalid OData response: %w", err)
+	}
+	return verbose.Results, verbose.Next, nil
+}
+
+// stripODataAnnotations removes control information such as @odata.etag and
+// __metadata from an entity
+func stripODataAnnotations(record Record) Record {
+	for key := range record {
+		if strings.Contains(key, "@") || key == "__metadata" {
+			delete(record, key)
+		}
+	}
+	return record
+}
+
+// resolveLink resolves a next link, which may be relative, against the URL
+// of the page it was returned with
+func resolveLink(base, link string) (string, error) {
+	if link == "" {
+		return "", nil
+	}
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	next, err := baseURL.Parse(link)
+	if err != nil {
+		return "", fmt.Errorf("invalid next link %q: %w", link, err)
+	}
+	return next.String(), nil
+}
@@ -0,0 +1,53 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"portal-data-backend/internal/integration/domain"
+)
+
+// restConnector reads an array of records from a JSON endpoint
+type restConnector struct {
+	client *http.Client
+	cfg    *domain.ConnectorConfig
+	apiKey string
+}
+
+func (c *restConnector) Fetch(ctx context.Context, limit int) ([]Record, error) {
+	body, err := get(ctx, c.client, c.cfg.URL, requestHeaders(c.cfg, c.apiKey))
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseJSON(body, c.cfg.RecordsPath, limit)
+}
+
+// ParseJSON reads records from a JSON array, or from the array at recordsPath
+// when it is set
+func ParseJSON(body []byte, recordsPath string, limit int) ([]Record, error) {
+	var decoded interface{}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	if recordsPath != "" {
+		for _, part := range strings.Split(recordsPath, ".") {
+			object, ok := decoded.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("records_path %q not found", recordsPath)
+			}
+			decoded = object[part]
+		}
+	}
+
+	items, ok := decoded.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected a JSON array of records")
+	}
+
+	return toRecords(items, limit), nil
+}
@@ -0,0 +1,144 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"portal-data-backend/internal/integration/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+const (
+	// s3AccessKeySecret and s3SecretKeySecret are the integration secrets
+	// holding the bucket credentials
+	s3AccessKeySecret = "access_key_id"
+	s3SecretKeySecret = "secret_access_key"
+)
+
+// Object is a file stored in a bucket
+type Object struct {
+	Key          string
+	ETag         string
+	Size         int64
+	ContentType  string
+	LastModified time.Time
+}
+
+// Bucket is implemented by connectors that sync files instead of records.
+// Buckets holding a connection also implement io.Closer.
+type Bucket interface {
+	// List returns every object under the configured prefix, ordered by key
+	List(ctx context.Context) ([]Object, error)
+	// Read returns the content of an object
+	Read(ctx context.Context, key string) ([]byte, error)
+}
+
+// listingRecords returns an object listing as records, which is what bucket
+// connectors fetch as a record source
+func listingRecords(objects []Object, limit int) []Record {
+	records := make([]Record, 0, len(objects))
+	for _, object := range objects {
+		if limit > 0 && len(records) >= limit {
+			break
+		}
+		records = append(records, Record{
+			"key":           object.Key,
+			"etag":          object.ETag,
+			"size":          object.Size,
+			"last_modified": object.LastModified.UTC().Format(time.RFC3339),
+		})
+	}
+	return records
+}
+
+// s3Connector lists and reads the objects under a prefix of an S3-compatible
+// bucket. As a record source it returns the object listing.
+type s3Connector struct {
+	client *minio.Client
+	cfg    *domain.ConnectorConfig
+}
+
+func newS3Connector(integration *domain.Integration, cfg *domain.ConnectorConfig, client *http.Client) (*s3Connector, error) {
+	host, secure, err := s3Endpoint(cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	s3Client, err := minio.New(host, &minio.Options{
+		Creds:     credentials.NewStaticV4(integration.Secrets[s3AccessKeySecret], integration.Secrets[s3SecretKeySecret], ""),
+		Secure:    secure,
+		Region:    cfg.Region,
+		Transport: client.Transport,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid s3 endpoint: %v", pkgErrors.ErrInvalidInput, err)
+	}
+	return &s3Connector{client: s3Client, cfg: cfg}, nil
+}
+
+func (c *s3Connector) Fetch(ctx context.Context, limit int) ([]Record, error) {
+	objects, err := c.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return listingRecords(objects, limit), nil
+}
+
+func (c *s3Connector) List(ctx context.Context) ([]Object, error) {
+	var objects []Object
+	for info := range c.client.ListObjects(ctx, c.cfg.Bucket, minio.ListObjectsOptions{Prefix: c.cfg.Prefix, Recursive: true}) {
+		if info.Err != nil {
+			return nil, fmt.Errorf("failed to list bucket %s: %w", c.cfg.Bucket, info.Err)
+		}
+		// Folder placeholders hold no content
+		if strings.HasSuffix(info.Key, "/") {
+			continue
+		}
+		objects = append(objects, Object{
+			Key:          info.Key,
+			ETag:         strings.Trim(info.ETag, `"`),
+			Size:         info.Size,
+			ContentType:  info.ContentType,
+			LastModified: info.LastModified,
+		})
+	}
+
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, nil
+}
+
+func (c *s3Connector) Read(ctx context.Context, key string) ([]byte, error) {
+	object, err := c.client.GetObject(ctx, c.cfg.Bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object %s: %w", key, err)
+	}
+	defer object.Close()
+
+	body, err := io.ReadAll(io.LimitReader(object, maxResponseBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object %s: %w", key, err)
+	}
+	if len(body) > maxResponseBytes {
+		return nil, fmt.Errorf("object %s is larger than %d bytes", key, maxResponseBytes)
+	}
+	return body, nil
+}
+
+// s3Endpoint splits an endpoint URL into the host the S3 client connects to
+// and whether it uses TLS
+func s3Endpoint(raw string) (string, bool, error) {
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "https" && parsed.Scheme != "http") {
+		return "", false, fmt.Errorf("%w: s3 url must be an http or https endpoint", pkgErrors.ErrInvalidInput)
+	}
+	return parsed.Host, parsed.Scheme == "https", nil
+}
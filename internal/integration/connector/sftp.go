@@ -0,0 +1,495 @@
+package connector
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"time"
+
+	"portal-data-backend/internal/integration/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// sftpPasswordSecret and sftpPrivateKeySecret are the integration secrets
+	// the SFTP user authenticates with
+	sftpPasswordSecret   = "password"
+	sftpPrivateKeySecret = "private_key"
+
+	sftpDefaultPort = "22"
+	// sftpReadChunk is the size of a single read request
+	sftpReadChunk = 32 << 10
+	// sftpMaxPacket caps the length of a packet sent by the server
+	sftpMaxPacket = 1 << 20
+)
+
+// SFTP version 3 packet types, status codes and attribute flags
+// (draft-ietf-secsh-filexfer-02)
+const (
+	sshFxpInit    = 1
+	sshFxpVersion = 2
+	sshFxpOpen    = 3
+	sshFxpClose   = 4
+	sshFxpRead    = 5
+	sshFxpOpendir = 11
+	sshFxpReaddir = 12
+	sshFxpStatus  = 101
+	sshFxpHandle  = 102
+	sshFxpData    = 103
+	sshFxpName    = 104
+
+	sshFxOK  = 0
+	sshFxEOF = 1
+
+	sshFxfRead = 0x1
+
+	sshFileXferAttrSize        = 0x1
+	sshFileXferAttrUIDGID      = 0x2
+	sshFileXferAttrPermissions = 0x4
+	sshFileXferAttrACModTime   = 0x8
+	sshFileXferAttrExtended    = 0x80000000
+
+	sftpFileTypeMask = 0170000
+	sftpRegularFile  = 0100000
+)
+
+// sftpConnector lists and reads the files of a directory on an SFTP server
+// that match a glob. It speaks the subset of SFTP version 3 needed to list
+// and download files over one SSH connection, opened on first use.
+type sftpConnector struct {
+	cfg     *domain.ConnectorConfig
+	config  *ssh.ClientConfig
+	address string
+	dir     string
+
+	client *ssh.Client
+	stdin  io.WriteCloser
+	stdout io.Reader
+	stop   func() bool
+	nextID uint32
+}
+
+func newSFTPConnector(integration *domain.Integration, cfg *domain.ConnectorConfig, client *http.Client) (*sftpConnector, error) {
+	user, address, dir, err := sftpAddress(cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+	hostKey, err := sftpHostKey(cfg.HostKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var auth []ssh.AuthMethod
+	if key := integration.Secrets[sftpPrivateKeySecret]; key != "" {
+		signer, err := ssh.ParsePrivateKey([]byte(key))
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid sftp private key: %v", pkgErrors.ErrInvalidInput, err)
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	if password := integration.Secrets[sftpPasswordSecret]; password != "" {
+		auth = append(auth, ssh.Password(password))
+	}
+
+	return &sftpConnector{
+		cfg: cfg,
+		config: &ssh.ClientConfig{
+			User:            user,
+			Auth:            auth,
+			HostKeyCallback: ssh.FixedHostKey(hostKey),
+			Timeout:         client.Timeout,
+		},
+		address: address,
+		dir:     dir,
+	}, nil
+}
+
+func (c *sftpConnector) Fetch(ctx context.Context, limit int) ([]Record, error) {
+	defer c.Close()
+
+	objects, err := c.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return listingRecords(objects, limit), nil
+}
+
+func (c *sftpConnector) List(ctx context.Context) ([]Object, error) {
+	if err := c.connect(ctx); err != nil {
+		return nil, err
+	}
+
+	handle, err := c.openHandle(sshFxpOpendir, sftpString(c.dir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open directory %s: %w", c.dir, err)
+	}
+	defer c.closeHandle(handle)
+
+	var objects []Object
+	for {
+		packetType, payload, err := c.call(sshFxpReaddir, sftpString(handle))
+		if err != nil {
+			return nil, err
+		}
+		if packetType == sshFxpStatus {
+			if err := sftpStatus(payload); err != io.EOF {
+				return nil, fmt.Errorf("failed to list directory %s: %w", c.dir, err)
+			}
+			break
+		}
+		if packetType != sshFxpName {
+			return nil, fmt.Errorf("sftp: unexpected packet %d listing a directory", packetType)
+		}
+
+		buf := &sftpBuffer{data: payload}
+		count := buf.uint32()
+		for i := uint32(0); i < count && buf.err == nil; i++ {
+			name := buf.string()
+			buf.string() // long name
+			attrs := buf.attrs()
+
+			if attrs.permissions != nil && *attrs.permissions&sftpFileTypeMask != sftpRegularFile {
+				continue
+			}
+			if matched, _ := path.Match(c.cfg.Pattern, name); !matched {
+				continue
+			}
+			objects = append(objects, Object{
+				Key:          path.Join(c.dir, name),
+				ETag:         fmt.Sprintf("%d-%d", attrs.size, attrs.modTime),
+				Size:         int64(attrs.size),
+				LastModified: time.Unix(int64(attrs.modTime), 0),
+			})
+		}
+		if buf.err != nil {
+			return nil, buf.err
+		}
+	}
+
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, nil
+}
+
+func (c *sftpConnector) Read(ctx context.Context, key string) ([]byte, error) {
+	if err := c.connect(ctx); err != nil {
+		return nil, err
+	}
+
+	handle, err := c.openHandle(sshFxpOpen, sftpString(key), sftpUint32(sshFxfRead), sftpUint32(0))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", key, err)
+	}
+	defer c.closeHandle(handle)
+
+	var body []byte
+	for {
+		packetType, payload, err := c.call(sshFxpRead, sftpString(handle), sftpUint64(uint64(len(body))), sftpUint32(sftpReadChunk))
+		if err != nil {
+			return nil, err
+		}
+		if packetType == sshFxpStatus {
+			if err := sftpStatus(payload); err != io.EOF {
+				return nil, fmt.Errorf("failed to read file %s: %w", key, err)
+			}
+			return body, nil
+		}
+		if packetType != sshFxpData {
+			return nil, fmt.Errorf("sftp: unexpected packet %d reading a file", packetType)
+		}
+
+		buf := &sftpBuffer{data: payload}
+		data := buf.string()
+		if buf.err != nil {
+			return nil, buf.err
+		}
+		body = append(body, data...)
+		if len(body) > maxResponseBytes {
+			return nil, fmt.Errorf("file %s is larger than %d bytes", key, maxResponseBytes)
+		}
+	}
+}
+
+// Close closes the SSH connection
+func (c *sftpConnector) Close() error {
+	if c.client == nil {
+		return nil
+	}
+	c.stop()
+	err := c.client.Close()
+	c.client = nil
+	return err
+}
+
+// connect opens the SSH connection and starts the sftp subsystem. The
+// connection is closed when ctx is done.
+func (c *sftpConnector) connect(ctx context.Context) error {
+	if c.client != nil {
+		return nil
+	}
+
+	dialer := &net.Dialer{Timeout: c.config.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", c.address)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", c.address, err)
+	}
+	if c.config.Timeout > 0 {
+		conn.SetDeadline(time.Now().Add(c.config.Timeout))
+	}
+	sshConn, channels, requests, err := ssh.NewClientConn(conn, c.address, c.config)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("ssh handshake with %s failed: %w", c.address, err)
+	}
+	conn.SetDeadline(time.Time{})
+	client := ssh.NewClient(sshConn, channels, requests)
+	c.client = client
+	c.stop = context.AfterFunc(ctx, func() { client.Close() })
+
+	if err := c.startSubsystem(); err != nil {
+		c.Close()
+		return err
+	}
+	return nil
+}
+
+func (c *sftpConnector) startSubsystem() error {
+	session, err := c.client.NewSession()
+	if err != nil {
+		return fmt.Errorf("failed to open ssh session: %w", err)
+	}
+	if c.stdin, err = session.StdinPipe(); err != nil {
+		return err
+	}
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	c.stdout = stdout
+	if err := session.RequestSubsystem("sftp"); err != nil {
+		return fmt.Errorf("server does not support sftp: %w", err)
+	}
+
+	// INIT carries the protocol version instead of a request id
+	if err := c.writePacket(append([]byte{sshFxpInit}, sftpUint32(3)...)); err != nil {
+		return err
+	}
+	packetType, _, err := c.readPacket()
+	if err != nil {
+		return err
+	}
+	if packetType != sshFxpVersion {
+		return fmt.Errorf("sftp: unexpected packet %d during init", packetType)
+	}
+	return nil
+}
+
+// call sends a request and returns the type and payload of its response,
+// without the request id
+func (c *sftpConnector) call(packetType byte, fields ...[]byte) (byte, []byte, error) {
+	c.nextID++
+	packet := append([]byte{packetType}, sftpUint32(c.nextID)...)
+	for _, field := range fields {
+		packet = append(packet, field...)
+	}
+	if err := c.writePacket(packet); err != nil {
+		return 0, nil, err
+	}
+
+	respType, payload, err := c.readPacket()
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(payload) < 4 || binary.BigEndian.Uint32(payload) != c.nextID {
+		return 0, nil, errors.New("sftp: response does not match the request")
+	}
+	return respType, payload[4:], nil
+}
+
+// openHandle sends an OPEN or OPENDIR request and returns the handle
+func (c *sftpConnector) openHandle(packetType byte, fields ...[]byte) (string, error) {
+	respType, payload, err := c.call(packetType, fields...)
+	if err != nil {
+		return "", err
+	}
+	switch respType {
+	case sshFxpHandle:
+		buf := &sftpBuffer{data: payload}
+		handle := buf.string()
+		return handle, buf.err
+	case sshFxpStatus:
+		return "", sftpStatus(payload)
+	default:
+		return "", fmt.Errorf("sftp: unexpected packet %d opening a handle", respType)
+	}
+}
+
+func (c *sftpConnector) closeHandle(handle string) {
+	_, _, _ = c.call(sshFxpClose, sftpString(handle))
+}
+
+func (c *sftpConnector) writePacket(packet []byte) error {
+	if _, err := c.stdin.Write(append(sftpUint32(uint32(len(packet))), packet...)); err != nil {
+		return fmt.Errorf("sftp: failed to send request: %w", err)
+	}
+	return nil
+}
+
+func (c *sftpConnector) readPacket() (byte, []byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(c.stdout, header[:]); err != nil {
+		return 0, nil, fmt.Errorf("sftp: failed to read response: %w", err)
+	}
+	length := binary.BigEndian.Uint32(header[:])
+	if length == 0 || length > sftpMaxPacket {
+		return 0, nil, fmt.Errorf("sftp: invalid packet length %d", length)
+	}
+
+	packet := make([]byte, length)
+	if _, err := io.ReadFull(c.stdout, packet); err != nil {
+		return 0, nil, fmt.Errorf("sftp: failed to read response: %w", err)
+	}
+	return packet[0], packet[1:], nil
+}
+
+// sftpStatus converts a STATUS payload into an error; EOF becomes io.EOF
+func sftpStatus(payload []byte) error {
+	buf := &sftpBuffer{data: payload}
+	code := buf.uint32()
+	message := buf.string()
+	switch {
+	case buf.err != nil:
+		return buf.err
+	case code == sshFxOK:
+		return nil
+	case code == sshFxEOF:
+		return io.EOF
+	default:
+		return fmt.Errorf("sftp status %d: %s", code, message)
+	}
+}
+
+// sftpAttrs holds the file attributes the connector uses
+type sftpAttrs struct {
+	size        uint64
+	permissions *uint32
+	modTime     uint32
+}
+
+// sftpBuffer decodes SFTP fields, remembering the first decoding error
+type sftpBuffer struct {
+	data []byte
+	err  error
+}
+
+func (b *sftpBuffer) next(n int) []byte {
+	if b.err != nil {
+		return nil
+	}
+	if len(b.data) < n {
+		b.err = errors.New("sftp: truncated packet")
+		return nil
+	}
+	field := b.data[:n]
+	b.data = b.data[n:]
+	return field
+}
+
+func (b *sftpBuffer) uint32() uint32 {
+	if field := b.next(4); field != nil {
+		return binary.BigEndian.Uint32(field)
+	}
+	return 0
+}
+
+func (b *sftpBuffer) uint64() uint64 {
+	if field := b.next(8); field != nil {
+		return binary.BigEndian.Uint64(field)
+	}
+	return 0
+}
+
+func (b *sftpBuffer) string() string {
+	return string(b.next(int(b.uint32())))
+}
+
+func (b *sftpBuffer) attrs() sftpAttrs {
+	var attrs sftpAttrs
+	flags := b.uint32()
+	if flags&sshFileXferAttrSize != 0 {
+		attrs.size = b.uint64()
+	}
+	if flags&sshFileXferAttrUIDGID != 0 {
+		b.next(8)
+	}
+	if flags&sshFileXferAttrPermissions != 0 {
+		permissions := b.uint32()
+		attrs.permissions = &permissions
+	}
+	if flags&sshFileXferAttrACModTime != 0 {
+		b.uint32() // access time
+		attrs.modTime = b.uint32()
+	}
+	if flags&sshFileXferAttrExtended != 0 {
+		count := b.uint32()
+		for i := uint32(0); i < count && b.err == nil; i++ {
+			b.string()
+			b.string()
+		}
+	}
+	return attrs
+}
+
+func sftpUint32(v uint32) []byte {
+	return binary.BigEndian.AppendUint32(nil, v)
+}
+
+func sftpUint64(v uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, v)
+}
+
+func sftpString(s string) []byte {
+	return append(sftpUint32(uint32(len(s))), s...)
+}
+
+// sftpAddress splits an sftp://user@host[:port]/directory URL into the user,
+// the host:port address and the directory, "." for the login directory
+func sftpAddress(raw string) (string, string, string, error) {
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Scheme != "sftp" || parsed.Hostname() == "" {
+		return "", "", "", fmt.Errorf("%w: sftp url must look like sftp://user@host/directory", pkgErrors.ErrInvalidInput)
+	}
+	if parsed.User == nil || parsed.User.Username() == "" {
+		return "", "", "", fmt.Errorf("%w: sftp url requires a user", pkgErrors.ErrInvalidInput)
+	}
+
+	port := parsed.Port()
+	if port == "" {
+		port = sftpDefaultPort
+	}
+	dir := parsed.Path
+	if dir == "" {
+		dir = "."
+	}
+	return parsed.User.Username(), net.JoinHostPort(parsed.Hostname(), port), dir, nil
+}
+
+// sftpHostKey parses the server public key in authorized_keys format
+func sftpHostKey(raw string) (ssh.PublicKey, error) {
+	if raw == "" {
+		return nil, fmt.Errorf("%w: sftp connector requires the server host_key", pkgErrors.ErrInvalidInput)
+	}
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(raw))
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid sftp host_key: %v", pkgErrors.ErrInvalidInput, err)
+	}
+	return key, nil
+}
@@ -0,0 +1,154 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"portal-data-backend/internal/integration/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// defaultCredentialsSecret is the integration secret holding the service account key
+	defaultCredentialsSecret = "service_account"
+	// sheetsAPIBase is used when the connector config has no url override
+	sheetsAPIBase = "https://sheets.googleapis.com"
+	// sheetsScope grants read-only access to spreadsheets
+	sheetsScope = "https://www.googleapis.com/auth/spreadsheets.readonly"
+)
+
+// serviceAccountKey is the subset of a Google service account key file we use
+type serviceAccountKey struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// sheetsConnector reads a range of a Google spreadsheet whose first row holds
+// the column names
+type sheetsConnector struct {
+	client      *http.Client
+	cfg         *domain.ConnectorConfig
+	credentials string
+}
+
+type sheetsValuesResponse struct {
+	Values [][]interface{} `json:"values"`
+}
+
+func (c *sheetsConnector) Fetch(ctx context.Context, limit int) ([]Record, error) {
+	token, err := c.accessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	base := c.cfg.URL
+	if base == "" {
+		base = sheetsAPIBase
+	}
+	endpoint := fmt.Sprintf("%s/v4/spreadsheets/%s/values/%s",
+		strings.TrimRight(base, "/"), url.PathEscape(c.cfg.SpreadsheetID), url.PathEscape(c.cfg.Range))
+
+	body, err := get(ctx, c.client, endpoint, map[string]string{"Authorization": "Bearer " + token})
+	if err != nil {
+		return nil, err
+	}
+
+	var resp sheetsValuesResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("invalid Sheets response: %w", err)
+	}
+	if len(resp.Values) == 0 {
+		return nil, nil
+	}
+
+	header := make([]string, len(resp.Values[0]))
+	for i, cell := range resp.Values[0] {
+		header[i] = strings.TrimSpace(fmt.Sprint(cell))
+	}
+
+	var records []Record
+	for _, row := range resp.Values[1:] {
+		if limit > 0 && len(records) >= limit {
+			break
+		}
+		// Sheets omits trailing empty cells, so short rows are padded with nil
+		record := make(Record, len(header))
+		for i, column := range header {
+			if column == "" {
+				continue
+			}
+			if i < len(row) {
+				record[column] = row[i]
+			} else {
+				record[column] = nil
+			}
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// accessToken exchanges a signed service account assertion for an OAuth token
+func (c *sheetsConnector) accessToken(ctx context.Context) (string, error) {
+	var key serviceAccountKey
+	if err := json.Unmarshal([]byte(c.credentials), &key); err != nil {
+		return "", fmt.Errorf("%w: invalid service account key: %v", pkgErrors.ErrInvalidInput, err)
+	}
+	if key.ClientEmail == "" || key.PrivateKey == "" || key.TokenURI == "" {
+		return "", fmt.Errorf("%w: service account key requires client_email, private_key and token_uri", pkgErrors.ErrInvalidInput)
+	}
+
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(key.PrivateKey))
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid service account private key: %v", pkgErrors.ErrInvalidInput, err)
+	}
+
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   key.ClientEmail,
+		"scope": sheetsScope,
+		"aud":   key.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(privateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token assertion: %w", err)
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "urn:ietf:params:oauth:grant-type:jwt-bearer")
+	form.Set("assertion", assertion)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, key.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid token_uri: %v", pkgErrors.ErrInvalidInput, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", &StatusError{StatusCode: resp.StatusCode, URL: key.TokenURI}
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil || token.AccessToken == "" {
+		return "", fmt.Errorf("invalid token response from %s", key.TokenURI)
+	}
+	return token.AccessToken, nil
+}
@@ -0,0 +1,81 @@
+package connector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"portal-data-backend/internal/integration/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	_ "github.com/lib/pq"
+)
+
+// sqlConnector runs a read query against an external database
+type sqlConnector struct {
+	cfg *domain.ConnectorConfig
+}
+
+func (c *sqlConnector) Fetch(ctx context.Context, limit int) ([]Record, error) {
+	driver := c.cfg.Driver
+	if driver == "" {
+		driver = "postgres"
+	}
+	if !driverRegistered(driver) {
+		return nil, fmt.Errorf("%w: unsupported sql driver %q", pkgErrors.ErrInvalidInput, driver)
+	}
+
+	db, err := sql.Open(driver, c.cfg.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	rows, err := db.QueryContext(ctx, c.cfg.Query)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns: %w", err)
+	}
+
+	var records []Record
+	for rows.Next() {
+		if limit > 0 && len(records) >= limit {
+			break
+		}
+
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		record := make(Record, len(columns))
+		for i, column := range columns {
+			if b, ok := values[i].([]byte); ok {
+				record[column] = string(b)
+			} else {
+				record[column] = values[i]
+			}
+		}
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
+
+func driverRegistered(name string) bool {
+	for _, driver := range sql.Drivers() {
+		if driver == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+// Package cli holds the portalctl commands of the integration module.
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"portal-data-backend/internal/app"
+	"portal-data-backend/internal/integration/usecase"
+)
+
+// Commands returns the integration commands
+func Commands(scheduler usecase.SchedulerUsecase) []app.Command {
+	return []app.Command{
+		{
+			Name:    "run-integration",
+			Usage:   "run-integration ID",
+			Summary: "Run the harvest or push of an integration now and wait for it",
+			Run: func(ctx context.Context, in io.Reader, out io.Writer, args []string) error {
+				if len(args) != 1 {
+					return errors.New("expected the ID of the integration")
+				}
+				run, err := scheduler.RunNow(ctx, args[0])
+				if err != nil {
+					return fmt.Errorf("failed to run integration: %w", err)
+				}
+				fmt.Fprintf(out, "Run %s %s: %d fetched, %d created, %d updated, %d failed\n",
+					run.ID, run.Status, run.RecordsFetched, run.RecordsCreated, run.RecordsUpdated, run.RecordsFailed)
+				if len(run.Errors) > 0 {
+					fmt.Fprintf(out, "Errors:\n  %s\n", strings.Join(run.Errors, "\n  "))
+				}
+				return nil
+			},
+		},
+	}
+}
@@ -1,42 +1,55 @@
 package http
 
 import (
-	"encoding/json"
-	"errors"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 
+	"portal-data-backend/infrastructure/auth"
 	integrationDomain "portal-data-backend/internal/integration/domain"
 	"portal-data-backend/internal/integration/usecase"
+	"portal-data-backend/infrastructure/http/httputil"
+	"portal-data-backend/infrastructure/http/middleware"
+	"portal-data-backend/infrastructure/http/problem"
 	"portal-data-backend/infrastructure/http/response"
+	roleDomain "portal-data-backend/internal/role/domain"
 	pkgErrors "portal-data-backend/pkg/errors"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/go-playground/validator/v10"
 )
 
 type Handler struct {
 	integrationUsecase usecase.Usecase
-	validator           *validator.Validate
+	webhookUsecase     usecase.WebhookUsecase
+	harvestUsecase     usecase.HarvestUsecase
+	pushUsecase        usecase.PushUsecase
+	ingestUsecase      usecase.IngestUsecase
+	schedulerUsecase   usecase.SchedulerUsecase
+	healthUsecase      usecase.HealthUsecase
 }
 
-func NewHandler(integrationUsecase usecase.Usecase) *Handler {
+func NewHandler(integrationUsecase usecase.Usecase, webhookUsecase usecase.WebhookUsecase, harvestUsecase usecase.HarvestUsecase, pushUsecase usecase.PushUsecase, ingestUsecase usecase.IngestUsecase, schedulerUsecase usecase.SchedulerUsecase, healthUsecase usecase.HealthUsecase) *Handler {
 	return &Handler{
 		integrationUsecase: integrationUsecase,
-		validator:           validator.New(),
+		webhookUsecase:     webhookUsecase,
+		harvestUsecase:     harvestUsecase,
+		pushUsecase:        pushUsecase,
+		ingestUsecase:      ingestUsecase,
+		schedulerUsecase:   schedulerUsecase,
+		healthUsecase:      healthUsecase,
 	}
 }
 
 func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Integration ID is required", nil)
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
 	integration, err := h.integrationUsecase.GetByID(r.Context(), id)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -63,7 +76,7 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 
 	resp, err := h.integrationUsecase.List(r.Context(), req)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -71,22 +84,16 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
-	var req integrationDomain.CreateIntegrationRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, response.CodeBadRequest, "Invalid request body", nil)
+	req, ok := httputil.Decode[integrationDomain.CreateIntegrationRequest](w, r)
+	if !ok {
 		return
 	}
 
-	if err := h.validator.Struct(&req); err != nil {
-		response.ValidationError(w, response.CodeValidationFailed, "Validation failed", h.formatValidationErrors(err))
-		return
-	}
+	userID := auth.UserID(r.Context())
 
-	userID, _ := r.Context().Value("user_id").(string)
-
-	integration, err := h.integrationUsecase.Create(r.Context(), &req, userID)
+	integration, err := h.integrationUsecase.Create(r.Context(), req, userID)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -94,26 +101,19 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Integration ID is required", nil)
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
-	var req integrationDomain.UpdateIntegrationRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, response.CodeBadRequest, "Invalid request body", nil)
+	req, ok := httputil.Decode[integrationDomain.UpdateIntegrationRequest](w, r)
+	if !ok {
 		return
 	}
 
-	if err := h.validator.Struct(&req); err != nil {
-		response.ValidationError(w, response.CodeValidationFailed, "Validation failed", h.formatValidationErrors(err))
-		return
-	}
-
-	integration, err := h.integrationUsecase.Update(r.Context(), id, &req)
+	integration, err := h.integrationUsecase.Update(r.Context(), id, req)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -121,42 +121,49 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Integration ID is required", nil)
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
 	if err := h.integrationUsecase.Delete(r.Context(), id); err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
 	response.OK(w, response.CodeSuccess, "Integration deleted successfully", nil)
 }
 
-func (h *Handler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Integration ID is required", nil)
+// Restore handles restoring a deleted integration
+func (h *Handler) Restore(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
-	var req struct {
-		Status string `json:"status" validate:"required"`
+	if err := h.integrationUsecase.Restore(r.Context(), id); err != nil {
+		h.handleError(w, r, err)
+		return
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, response.CodeBadRequest, "Invalid request body", nil)
+
+	response.OK(w, response.CodeSuccess, "Integration restored successfully", nil)
+}
+
+func (h *Handler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
-	if err := h.validator.Struct(&req); err != nil {
-		response.ValidationError(w, response.CodeValidationFailed, "Validation failed", h.formatValidationErrors(err))
+	var req struct {
+		Status string `json:"status" validate:"required"`
+	}
+	if !httputil.DecodeInto(w, r, &req) {
 		return
 	}
 
 	if err := h.integrationUsecase.UpdateStatus(r.Context(), id, req.Status); err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -164,55 +171,450 @@ func (h *Handler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) Sync(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Integration ID is required", nil)
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
 	if err := h.integrationUsecase.Sync(r.Context(), id); err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
 	response.OK(w, response.CodeSuccess, "Integration synced successfully", nil)
 }
 
-func (h *Handler) handleError(w http.ResponseWriter, err error) {
-	if err == nil {
+func (h *Handler) TestConnection(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
-	switch {
-	case errors.Is(err, pkgErrors.ErrNotFound):
-		response.NotFound(w, response.CodeNotFound, "Integration not found", nil)
-	default:
-		response.InternalError(w, response.CodeInternalServerError, "Internal server error", nil)
+	result, err := h.integrationUsecase.TestConnection(r.Context(), id)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
 	}
+
+	response.OK(w, response.CodeSuccess, "Integration connection tested", result)
 }
 
-func (h *Handler) formatValidationErrors(err error) []response.ErrorDetail {
-	var details []response.ErrorDetail
-	if validationErrors, ok := err.(validator.ValidationErrors); ok {
-		for _, fieldErr := range validationErrors {
-			details = append(details, response.ErrorDetail{
-				Field:   fieldErr.Field(),
-				Message: h.getValidationErrorMessage(fieldErr),
-			})
-		}
+func (h *Handler) RotateSecrets(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	req, ok := httputil.Decode[integrationDomain.RotateSecretsRequest](w, r)
+	if !ok {
+		return
+	}
+
+	integration, err := h.integrationUsecase.RotateSecrets(r.Context(), id, req)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Integration secrets updated successfully", integration)
+}
+
+func (h *Handler) ReencryptSecrets(w http.ResponseWriter, r *http.Request) {
+	changed, err := h.integrationUsecase.ReencryptSecrets(r.Context())
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Integration secrets re-encrypted successfully", map[string]int{"reencrypted": changed})
+}
+
+func (h *Handler) ListSubscriptions(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	subscriptions, err := h.webhookUsecase.ListSubscriptions(r.Context(), id)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Subscriptions retrieved successfully", subscriptions)
+}
+
+func (h *Handler) Subscribe(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	req, ok := httputil.Decode[integrationDomain.CreateSubscriptionRequest](w, r)
+	if !ok {
+		return
+	}
+
+	subscription, err := h.webhookUsecase.Subscribe(r.Context(), id, req)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.Created(w, response.CodeCreated, "Subscription created successfully", subscription)
+}
+
+func (h *Handler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+	subscriptionID, ok := httputil.UUIDParam(w, r, "subscriptionId")
+	if !ok {
+		return
+	}
+
+	if err := h.webhookUsecase.Unsubscribe(r.Context(), id, subscriptionID); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Subscription deleted successfully", nil)
+}
+
+func (h *Handler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	req := &integrationDomain.ListDeliveriesRequest{
+		Page:  parseIntQuery(r, "page", 1),
+		Limit: parseIntQuery(r, "limit", 20),
+	}
+	if status := r.URL.Query().Get("status"); status != "" {
+		req.Status = &status
+	}
+
+	resp, err := h.webhookUsecase.ListDeliveries(r.Context(), id, req)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Deliveries retrieved successfully", resp)
+}
+
+func (h *Handler) GetDelivery(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+	deliveryID, ok := httputil.UUIDParam(w, r, "deliveryId")
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhookUsecase.GetDelivery(r.Context(), id, deliveryID)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Delivery retrieved successfully", delivery)
+}
+
+func (h *Handler) RetryDelivery(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+	deliveryID, ok := httputil.UUIDParam(w, r, "deliveryId")
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhookUsecase.RetryDelivery(r.Context(), id, deliveryID)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Delivery retried", delivery)
+}
+
+// Run queues a manual run of a connector or publisher integration
+func (h *Handler) Run(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	job, err := h.schedulerUsecase.Enqueue(r.Context(), id)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.JSON(w, http.StatusAccepted, response.CodeSuccess, "Run queued", job)
+}
+
+func (h *Handler) ListRuns(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	req := &integrationDomain.ListRunsRequest{
+		Page:  parseIntQuery(r, "page", 1),
+		Limit: parseIntQuery(r, "limit", 20),
+	}
+
+	resp, err := h.harvestUsecase.ListRuns(r.Context(), id, req)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
 	}
-	return details
+
+	response.OK(w, response.CodeSuccess, "Runs retrieved successfully", resp)
 }
 
-func (h *Handler) getValidationErrorMessage(fieldErr validator.FieldError) string {
-	switch fieldErr.Tag() {
-	case "required":
-		return fieldErr.Field() + " is required"
-	case "min":
-		return fieldErr.Field() + " must be at least " + fieldErr.Param() + " characters"
+// ListMatches lists the harvested records of a connector matching existing
+// datasets, pending review by default
+func (h *Handler) ListMatches(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	req := &integrationDomain.ListMatchesRequest{
+		Page:  parseIntQuery(r, "page", 1),
+		Limit: parseIntQuery(r, "limit", 20),
+	}
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = string(integrationDomain.MatchStatusPending)
+	}
+	if status != "all" {
+		req.Status = &status
+	}
+
+	resp, err := h.harvestUsecase.ListMatches(r.Context(), id, req)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Harvest matches retrieved successfully", resp)
+}
+
+// ResolveMatch writes a matched record with the policy the reviewer chose
+func (h *Handler) ResolveMatch(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+	matchID, ok := httputil.UUIDParam(w, r, "matchId")
+	if !ok {
+		return
+	}
+
+	req, ok := httputil.Decode[integrationDomain.ResolveMatchRequest](w, r)
+	if !ok {
+		return
+	}
+
+	userID := auth.UserID(r.Context())
+	match, err := h.harvestUsecase.ResolveMatch(r.Context(), id, matchID, req, userID)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Harvest match resolved successfully", match)
+}
+
+// Health reports the run health of connector and publisher integrations
+func (h *Handler) Health(w http.ResponseWriter, r *http.Request) {
+	var orgID *string
+	if value := r.URL.Query().Get("organization_id"); value != "" {
+		orgID = &value
+	}
+
+	resp, err := h.healthUsecase.Health(r.Context(), orgID)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Integration health retrieved successfully", resp)
+}
+
+func (h *Handler) Push(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	run, err := h.pushUsecase.Push(r.Context(), id, integrationDomain.RunTriggerManual)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Push completed", run)
+}
+
+func (h *Handler) PushDataset(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+	datasetID, ok := httputil.UUIDParam(w, r, "datasetId")
+	if !ok {
+		return
+	}
+
+	record, err := h.pushUsecase.PushDataset(r.Context(), id, datasetID)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Dataset pushed", record)
+}
+
+func (h *Handler) ListPushRecords(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	req := &integrationDomain.ListPushRecordsRequest{
+		Page:  parseIntQuery(r, "page", 1),
+		Limit: parseIntQuery(r, "limit", 20),
+	}
+	if status := r.URL.Query().Get("status"); status != "" {
+		req.Status = &status
+	}
+
+	resp, err := h.pushUsecase.ListPushRecords(r.Context(), id, req)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Push records retrieved successfully", resp)
+}
+
+// Ingest receives a JSON or CSV payload from an external system. It is
+// authenticated with an ingest token instead of a user session.
+func (h *Handler) Ingest(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	token := r.Header.Get("X-Integration-Token")
+	if bearer := r.Header.Get("Authorization"); strings.HasPrefix(bearer, "Bearer ") {
+		token = strings.TrimPrefix(bearer, "Bearer ")
+	}
+
+	// The body is bounded by the limit of imports
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		if httputil.BodyTooLarge(w, err) {
+			return
+		}
+		response.BadRequest(w, response.CodeBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	payload := &integrationDomain.IngestPayload{
+		ContentType:    r.Header.Get("Content-Type"),
+		Body:           body,
+		IdempotencyKey: r.Header.Get("Idempotency-Key"),
+	}
+
+	report, err := h.ingestUsecase.Ingest(r.Context(), id, token, payload)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	switch report.Result {
+	case integrationDomain.IngestResultRejected:
+		response.JSON(w, http.StatusUnprocessableEntity, response.CodeValidationFailed, "Payload rejected", report)
+	case integrationDomain.IngestResultPartial:
+		response.OK(w, response.CodeSuccess, "Payload partially ingested", report)
 	default:
-		return fieldErr.Field() + " is invalid"
+		response.OK(w, response.CodeSuccess, "Payload ingested", report)
+	}
+}
+
+func (h *Handler) ListIngestTokens(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
 	}
+
+	tokens, err := h.ingestUsecase.ListTokens(r.Context(), id)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Ingest tokens retrieved successfully", tokens)
+}
+
+func (h *Handler) CreateIngestToken(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	req, ok := httputil.Decode[integrationDomain.CreateIngestTokenRequest](w, r)
+	if !ok {
+		return
+	}
+
+	userID := auth.UserID(r.Context())
+
+	token, err := h.ingestUsecase.CreateToken(r.Context(), id, req, userID)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.Created(w, response.CodeCreated, "Ingest token created successfully", token)
+}
+
+func (h *Handler) RevokeIngestToken(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+	tokenID, ok := httputil.UUIDParam(w, r, "tokenId")
+	if !ok {
+		return
+	}
+
+	if err := h.ingestUsecase.RevokeToken(r.Context(), id, tokenID); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Ingest token revoked successfully", nil)
+}
+
+// errorMapper maps the errors of the integration module on top of the shared ones
+var errorMapper = problem.Default.With(
+	problem.Mapping{Err: pkgErrors.ErrNotFound, Status: http.StatusNotFound, Code: response.CodeNotFound, Message: "Integration not found"},
+	problem.Mapping{Err: pkgErrors.ErrUnauthorized, Status: http.StatusUnauthorized, Code: response.CodeUnauthorized, Message: "Invalid or missing token"},
+)
+
+func (h *Handler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	errorMapper.Write(w, r, err)
 }
 
 func parseIntQuery(r *http.Request, key string, defaultValue int) int {
@@ -224,14 +626,58 @@ func parseIntQuery(r *http.Request, key string, defaultValue int) int {
 	return defaultValue
 }
 
-func RegisterRoutes(r chi.Router, handler *Handler) {
+// RegisterRoutes registers integration routes. Ingest authenticates with
+// ingest tokens; the other routes go through auth, changes require the
+// integrations:write permission, and restoring is left to users with one of
+// adminRoles.
+func RegisterRoutes(r chi.Router, handler *Handler, auth func(http.Handler) http.Handler, adminRoles []string) {
 	r.Route("/integrations", func(r chi.Router) {
-		r.Get("/", handler.List)
-		r.Post("/", handler.Create)
-		r.Get("/{id}", handler.GetByID)
-		r.Put("/{id}", handler.Update)
-		r.Delete("/{id}", handler.Delete)
-		r.Patch("/{id}/status", handler.UpdateStatus)
-		r.Post("/{id}/sync", handler.Sync)
+		// Inbound ingest, authenticated with ingest tokens instead
+		r.Post("/{id}/ingest", handler.Ingest)
+
+		r.Group(func(r chi.Router) {
+			r.Use(auth)
+			write := middleware.RequirePermission(roleDomain.PermissionIntegrationsWrite)
+			r.Get("/", handler.List)
+			r.With(write).Post("/", handler.Create)
+			r.Get("/{id}", handler.GetByID)
+			r.With(write).Put("/{id}", handler.Update)
+			r.With(write).Delete("/{id}", handler.Delete)
+			r.With(middleware.RequireRole(adminRoles...)).Post("/{id}/restore", handler.Restore)
+			r.With(write).Patch("/{id}/status", handler.UpdateStatus)
+			r.With(write).Post("/{id}/sync", handler.Sync)
+			r.With(write).Post("/{id}/test", handler.TestConnection)
+
+			// Credentials
+			r.With(write).Put("/{id}/secrets", handler.RotateSecrets)
+			r.With(write).Post("/secrets/reencrypt", handler.ReencryptSecrets)
+
+			// Webhook subscriptions and deliveries
+			r.Get("/{id}/subscriptions", handler.ListSubscriptions)
+			r.With(write).Post("/{id}/subscriptions", handler.Subscribe)
+			r.With(write).Delete("/{id}/subscriptions/{subscriptionId}", handler.Unsubscribe)
+			r.Get("/{id}/deliveries", handler.ListDeliveries)
+			r.Get("/{id}/deliveries/{deliveryId}", handler.GetDelivery)
+			r.With(write).Post("/{id}/deliveries/{deliveryId}/retry", handler.RetryDelivery)
+
+			// Connector harvest and publisher push runs
+			r.With(write).Post("/{id}/run", handler.Run)
+			r.Get("/{id}/runs", handler.ListRuns)
+			r.Get("/health", handler.Health)
+
+			// Harvested records matching existing datasets
+			r.Get("/{id}/matches", handler.ListMatches)
+			r.With(write).Post("/{id}/matches/{matchId}/resolve", handler.ResolveMatch)
+
+			// Publisher pushes
+			r.With(write).Post("/{id}/push", handler.Push)
+			r.With(write).Post("/{id}/push/{datasetId}", handler.PushDataset)
+			r.Get("/{id}/push-records", handler.ListPushRecords)
+
+			// Inbound ingest tokens
+			r.Get("/{id}/ingest-tokens", handler.ListIngestTokens)
+			r.With(write).Post("/{id}/ingest-tokens", handler.CreateIngestToken)
+			r.With(write).Delete("/{id}/ingest-tokens/{tokenId}", handler.RevokeIngestToken)
+		})
 	})
 }
@@ -0,0 +1,61 @@
+package http
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	integrationDomain "portal-data-backend/internal/integration/domain"
+)
+
+// Describe adds the integration routes to the API specification
+func Describe(spec *openapi.Spec) {
+	api := spec.Tag("integrations", "Harvesting, publishing, webhooks and ingest")
+	api.Get("/integrations", "List integrations").Query(integrationDomain.ListIntegrationsRequest{}).Param("include_deleted", false).Returns(http.StatusOK, integrationDomain.IntegrationListResponse{})
+	api.Post("/integrations", "Create integration").Body(integrationDomain.CreateIntegrationRequest{}).Returns(http.StatusCreated, integrationDomain.IntegrationInfo{})
+	api.Get("/integrations/{id}", "Get integration").Param("include_deleted", false).Returns(http.StatusOK, integrationDomain.IntegrationInfo{})
+	api.Put("/integrations/{id}", "Update integration").Body(integrationDomain.UpdateIntegrationRequest{}).Returns(http.StatusOK, integrationDomain.IntegrationInfo{})
+	api.Delete("/integrations/{id}", "Delete integration").Returns(http.StatusOK, nil)
+	api.Post("/integrations/{id}/restore", "Restore deleted integration").Returns(http.StatusOK, nil)
+	api.Patch("/integrations/{id}/status", "Update integration status").Body(struct {
+		Status string `json:"status" validate:"required"`
+	}{}).Returns(http.StatusOK, nil)
+	api.Post("/integrations/{id}/sync", "Sync integration").Returns(http.StatusOK, nil)
+	api.Post("/integrations/{id}/test", "Test integration connection").Returns(http.StatusOK, integrationDomain.ConnectionTestResult{})
+	api.Put("/integrations/{id}/secrets", "Rotate integration secrets").Body(integrationDomain.RotateSecretsRequest{}).Returns(http.StatusOK, integrationDomain.IntegrationInfo{})
+	api.Post("/integrations/secrets/reencrypt", "Re-encrypt all integration secrets").Returns(http.StatusOK, map[string]int{})
+
+	api.Get("/integrations/{id}/subscriptions", "List webhook subscriptions").Returns(http.StatusOK, []integrationDomain.Subscription{})
+	api.Post("/integrations/{id}/subscriptions", "Subscribe to events").Body(integrationDomain.CreateSubscriptionRequest{}).Returns(http.StatusCreated, integrationDomain.Subscription{})
+	api.Delete("/integrations/{id}/subscriptions/{subscriptionId}", "Unsubscribe from events").Returns(http.StatusOK, nil)
+	api.Get("/integrations/{id}/deliveries", "List webhook deliveries").
+		Query(integrationDomain.ListDeliveriesRequest{}).
+		Returns(http.StatusOK, integrationDomain.DeliveryListResponse{})
+	api.Get("/integrations/{id}/deliveries/{deliveryId}", "Get webhook delivery").Returns(http.StatusOK, integrationDomain.DeliveryDetailResponse{})
+	api.Post("/integrations/{id}/deliveries/{deliveryId}/retry", "Retry webhook delivery").Returns(http.StatusOK, integrationDomain.Delivery{})
+
+	api.Post("/integrations/{id}/run", "Queue a harvest run").Returns(http.StatusAccepted, integrationDomain.RunJob{})
+	api.Get("/integrations/{id}/runs", "List harvest runs").Query(integrationDomain.ListRunsRequest{}).Returns(http.StatusOK, integrationDomain.RunListResponse{})
+	api.Get("/integrations/{id}/matches", "List harvested records matching existing datasets").
+		Query(integrationDomain.ListMatchesRequest{}).
+		Returns(http.StatusOK, integrationDomain.HarvestMatchListResponse{})
+	api.Post("/integrations/{id}/matches/{matchId}/resolve", "Resolve a harvest match").
+		Body(integrationDomain.ResolveMatchRequest{}).
+		Returns(http.StatusOK, integrationDomain.HarvestMatchInfo{})
+	api.Get("/integrations/health", "Get integration health").Param("organization_id", false).Returns(http.StatusOK, integrationDomain.HealthResponse{})
+
+	api.Post("/integrations/{id}/push", "Push datasets to the remote catalog").Returns(http.StatusOK, integrationDomain.RunInfo{})
+	api.Post("/integrations/{id}/push/{datasetId}", "Push one dataset to the remote catalog").Returns(http.StatusOK, integrationDomain.PushRecordInfo{})
+	api.Get("/integrations/{id}/push-records", "List push records").
+		Query(integrationDomain.ListPushRecordsRequest{}).
+		Returns(http.StatusOK, integrationDomain.PushRecordListResponse{})
+
+	api.Get("/integrations/{id}/ingest-tokens", "List ingest tokens").Returns(http.StatusOK, []integrationDomain.IngestTokenInfo{})
+	api.Post("/integrations/{id}/ingest-tokens", "Create ingest token").Body(integrationDomain.CreateIngestTokenRequest{}).Returns(http.StatusCreated, integrationDomain.IngestTokenCreated{})
+	api.Delete("/integrations/{id}/ingest-tokens/{tokenId}", "Revoke ingest token").Returns(http.StatusOK, nil)
+
+	api.Post("/integrations/{id}/ingest", "Ingest a payload").Secured(openapi.IngestToken).
+		Header("Idempotency-Key", false).
+		RawBody("application/json", "text/csv").
+		Returns(http.StatusOK, integrationDomain.IngestReport{}).
+		Returns(http.StatusUnprocessableEntity, integrationDomain.IngestReport{})
+}
@@ -10,16 +10,25 @@ type Integration struct {
 	Description    *string    `db:"description" json:"description,omitempty"`
 	Config         string     `db:"config" json:"config"` // JSON config
 	Endpoint       *string    `db:"endpoint" json:"endpoint,omitempty"`
-	APIKey         *string    `db:"api_key" json:"api_key,omitempty"`
+	APIKey         *string    `db:"api_key" json:"-"`
 	Status         string     `db:"status" json:"status"`
 	LastSyncAt     *time.Time `db:"last_sync_at" json:"last_sync_at,omitempty"`
+	NextRunAt      *time.Time `db:"next_run_at" json:"next_run_at,omitempty"` // next scheduled run, nil until the scheduler computes it
 	OrganizationID *string    `db:"organization_id" json:"organization_id,omitempty"`
 	CreatedBy      string     `db:"created_by" json:"created_by"`
 	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
 	UpdatedAt      time.Time  `db:"updated_at" json:"updated_at"`
 	DeletedAt      *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
+
+	// Secrets holds named credentials such as passwords or tokens. The repository
+	// stores them encrypted in EncryptedSecrets and never returns ciphertext.
+	Secrets          map[string]string `db:"-" json:"-"`
+	EncryptedSecrets *string           `db:"secrets" json:"-"`
 }
 
+// RedactedSecret replaces secret values in API responses
+const RedactedSecret = "********"
+
 // IntegrationType represents integration type
 type IntegrationType string
 
@@ -28,6 +37,11 @@ const (
 	IntegrationTypeWebhook IntegrationType = "webhook"
 	IntegrationTypeDatabase IntegrationType = "database"
 	IntegrationTypeCustom  IntegrationType = "custom"
+	IntegrationTypeConnector IntegrationType = "connector" // pulls records from an external source
+	IntegrationTypePublisher IntegrationType = "publisher" // pushes published datasets to an external catalogue
+	IntegrationTypeInbound   IntegrationType = "inbound"   // receives records on its ingest endpoint
+	IntegrationTypeSlack     IntegrationType = "slack"     // posts event messages to Slack incoming webhooks
+	IntegrationTypeTeams     IntegrationType = "teams"     // posts event messages to Microsoft Teams incoming webhooks
 )
 
 // IntegrationStatus represents integration status
@@ -51,13 +65,14 @@ type ListIntegrationsRequest struct {
 
 // CreateIntegrationRequest represents create integration input
 type CreateIntegrationRequest struct {
-	Name           string  `json:"name" validate:"required,min=2,max=100"`
-	Type           string  `json:"type" validate:"required"`
-	Description    *string `json:"description,omitempty"`
-	Config         string  `json:"config" validate:"required"`
-	Endpoint       *string `json:"endpoint,omitempty"`
-	APIKey         *string `json:"api_key,omitempty"`
-	OrganizationID *string `json:"organization_id,omitempty"`
+	Name           string            `json:"name" validate:"required,min=2,max=100"`
+	Type           string            `json:"type" validate:"required"`
+	Description    *string           `json:"description,omitempty"`
+	Config         string            `json:"config" validate:"required"`
+	Endpoint       *string           `json:"endpoint,omitempty"`
+	APIKey         *string           `json:"api_key,omitempty"`
+	Secrets        map[string]string `json:"secrets,omitempty"`
+	OrganizationID *string           `json:"organization_id,omitempty"`
 }
 
 // UpdateIntegrationRequest represents update integration input
@@ -70,19 +85,31 @@ type UpdateIntegrationRequest struct {
 	Status         *string `json:"status,omitempty"`
 }
 
+// RotateSecretsRequest replaces individual credentials without touching the
+// rest of the integration. A null secret value removes that secret.
+type RotateSecretsRequest struct {
+	APIKey  *string            `json:"api_key,omitempty"`
+	Secrets map[string]*string `json:"secrets,omitempty"`
+}
+
 // IntegrationInfo represents integration information for API responses
 type IntegrationInfo struct {
-	ID             string     `json:"id"`
-	Name           string     `json:"name"`
-	Type           string     `json:"type"`
-	Description    *string    `json:"description,omitempty"`
-	Endpoint       *string    `json:"endpoint,omitempty"`
-	Status         string     `json:"status"`
-	LastSyncAt     *time.Time `json:"last_sync_at,omitempty"`
-	OrganizationID *string    `json:"organization_id,omitempty"`
-	CreatedBy      string     `json:"created_by"`
-	CreatedAt      time.Time  `json:"created_at"`
-	UpdatedAt      time.Time  `json:"updated_at"`
+	ID             string            `json:"id"`
+	Name           string            `json:"name"`
+	Type           string            `json:"type"`
+	Description    *string           `json:"description,omitempty"`
+	Endpoint       *string           `json:"endpoint,omitempty"`
+	Status         string            `json:"status"`
+	HasAPIKey      bool              `json:"has_api_key"`
+	Secrets        map[string]string `json:"secrets,omitempty"` // values are redacted
+	LastSyncAt     *time.Time        `json:"last_sync_at,omitempty"`
+	NextRunAt      *time.Time        `json:"next_run_at,omitempty"`
+	OrganizationID *string           `json:"organization_id,omitempty"`
+	CreatedBy      string            `json:"created_by"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+	// DeletedAt is set on deleted integrations, which only admins list
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
 // IntegrationListResponse represents paginated integration list
@@ -98,3 +125,660 @@ type ListMeta struct {
 	Total     int `json:"total"`
 	TotalPage int `json:"total_page"`
 }
+
+// Subscription links an integration to an event type it should receive.
+// EventType "*" subscribes to every event.
+type Subscription struct {
+	ID            string    `db:"id" json:"id"`
+	IntegrationID string    `db:"integration_id" json:"integration_id"`
+	EventType     string    `db:"event_type" json:"event_type"`
+	CreatedAt     time.Time `db:"created_at" json:"created_at"`
+}
+
+// SubscriptionWildcard matches every event type
+const SubscriptionWildcard = "*"
+
+// Event is a domain event fanned out to subscribed webhook integrations
+type Event struct {
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+// Delivery tracks the delivery of one event to one integration across attempts
+type Delivery struct {
+	ID             string     `db:"id" json:"id"`
+	IntegrationID  string     `db:"integration_id" json:"integration_id"`
+	EventID        string     `db:"event_id" json:"event_id"`
+	EventType      string     `db:"event_type" json:"event_type"`
+	Channel        *string    `db:"channel" json:"channel,omitempty"` // chat channel of Slack and Teams deliveries
+	Payload        string     `db:"payload" json:"payload"`
+	Status         string     `db:"status" json:"status"`
+	Attempts       int        `db:"attempts" json:"attempts"`
+	NextAttemptAt  *time.Time `db:"next_attempt_at" json:"next_attempt_at,omitempty"`
+	LastError      *string    `db:"last_error" json:"last_error,omitempty"`
+	ResponseStatus *int       `db:"response_status" json:"response_status,omitempty"`
+	DeliveredAt    *time.Time `db:"delivered_at" json:"delivered_at,omitempty"`
+	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// DeliveryStatus represents webhook delivery status
+type DeliveryStatus string
+
+const (
+	DeliveryStatusPending   DeliveryStatus = "pending"
+	DeliveryStatusSucceeded DeliveryStatus = "succeeded"
+	DeliveryStatusFailed    DeliveryStatus = "failed" // failed, retry scheduled
+	DeliveryStatusDead      DeliveryStatus = "dead"   // retries exhausted
+)
+
+// DeliveryAttempt is the log entry of a single HTTP attempt
+type DeliveryAttempt struct {
+	ID             string    `db:"id" json:"id"`
+	DeliveryID     string    `db:"delivery_id" json:"delivery_id"`
+	Attempt        int       `db:"attempt" json:"attempt"`
+	ResponseStatus *int      `db:"response_status" json:"response_status,omitempty"`
+	ResponseBody   *string   `db:"response_body" json:"response_body,omitempty"`
+	Error          *string   `db:"error" json:"error,omitempty"`
+	DurationMs     int64     `db:"duration_ms" json:"duration_ms"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+}
+
+// CreateSubscriptionRequest represents subscribe input
+type CreateSubscriptionRequest struct {
+	EventType string `json:"event_type" validate:"required,max=100"`
+}
+
+// ListDeliveriesRequest represents list deliveries input
+type ListDeliveriesRequest struct {
+	Page   int     `json:"page" validate:"min=1"`
+	Limit  int     `json:"limit" validate:"min=1,max=100"`
+	Status *string `json:"status,omitempty"`
+}
+
+// DeliveryListResponse represents paginated delivery list
+type DeliveryListResponse struct {
+	Deliveries []Delivery `json:"deliveries"`
+	Meta       ListMeta   `json:"meta"`
+}
+
+// DeliveryDetailResponse represents a delivery with its attempt log
+type DeliveryDetailResponse struct {
+	Delivery
+	AttemptLog []DeliveryAttempt `json:"attempt_log"`
+}
+
+// ConnectorType represents the kind of external source a connector pulls from
+type ConnectorType string
+
+const (
+	ConnectorTypeCKAN         ConnectorType = "ckan"
+	ConnectorTypeREST         ConnectorType = "rest"
+	ConnectorTypeCSV          ConnectorType = "csv"
+	ConnectorTypeSQL          ConnectorType = "sql"
+	ConnectorTypeGoogleSheets ConnectorType = "google_sheets"
+	ConnectorTypeBPS          ConnectorType = "bps"
+	ConnectorTypeS3           ConnectorType = "s3"     // files dropped in an S3-compatible bucket
+	ConnectorTypeSFTP         ConnectorType = "sftp"   // files exported to an SFTP server
+	ConnectorTypeArcGIS       ConnectorType = "arcgis" // features of an ArcGIS FeatureServer layer
+	ConnectorTypeOData        ConnectorType = "odata"  // entities of an OData entity set
+)
+
+// HarvestTarget represents where harvested records are written
+type HarvestTarget string
+
+const (
+	HarvestTargetDatasets HarvestTarget = "datasets"  // one dataset per record
+	HarvestTargetDataRows HarvestTarget = "data_rows" // records are written as rows of one dataset
+	HarvestTargetFiles    HarvestTarget = "files"     // source files are imported into the file module
+)
+
+// RowWriteMode represents how harvested rows are written to the data_rows target
+type RowWriteMode string
+
+const (
+	RowWriteReplace RowWriteMode = "replace" // existing rows are replaced by the harvested ones
+	RowWriteUpsert  RowWriteMode = "upsert"  // rows are matched on KeyField, unmatched rows are kept
+)
+
+// ConnectorConfig is the JSON stored in Integration.Config for connector integrations
+type ConnectorConfig struct {
+	Connector ConnectorType `json:"connector"`
+	Schedule  string        `json:"schedule,omitempty"` // standard 5-field cron expression
+	Target    HarvestTarget `json:"target"`
+	DatasetID string        `json:"dataset_id,omitempty"` // required for the data_rows target, files are attached to it
+	Mode      RowWriteMode  `json:"mode,omitempty"`       // data_rows target and ingest_rows, defaults to replace
+	KeyField  string        `json:"key_field,omitempty"`  // row column matched in upsert mode
+
+	// Source settings. URL falls back to Integration.Endpoint.
+	URL         string            `json:"url,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	RecordsPath string            `json:"records_path,omitempty"` // dot path to the record array in a JSON response
+	Delimiter   string            `json:"delimiter,omitempty"`
+	Driver      string            `json:"driver,omitempty"`
+	DSN         string            `json:"dsn,omitempty"`
+	Query       string            `json:"query,omitempty"`
+	PageSize    int               `json:"page_size,omitempty"` // records per request of paged arcgis and odata sources
+	// Where filters ArcGIS features with a SQL expression, "1=1" by default.
+	// Feature geometry is returned as a GeoJSON object under "geometry".
+	Where string `json:"where,omitempty"`
+
+	// Google Sheets settings. The service account key JSON is read from the
+	// integration secret named by CredentialsSecret ("service_account" by default).
+	SpreadsheetID     string `json:"spreadsheet_id,omitempty"`
+	Range             string `json:"range,omitempty"` // A1 notation, e.g. "Sheet1!A1:F"
+	CredentialsSecret string `json:"credentials_secret,omitempty"`
+
+	// BPS (Statistics Indonesia) settings. The integration API key is the BPS web API key.
+	BPSDomain string   `json:"bps_domain,omitempty"` // domain code, "0000" for national statistics
+	Subjects  []string `json:"subjects,omitempty"`   // subject IDs to harvest, all subjects when empty
+	Variables []string `json:"variables,omitempty"`  // variable IDs to harvest, all variables when empty
+
+	// S3 bucket settings. URL is the S3-compatible endpoint, e.g.
+	// https://s3.ap-southeast-3.amazonaws.com. Credentials are read from the
+	// "access_key_id" and "secret_access_key" secrets; without them the bucket
+	// is read anonymously.
+	Bucket string `json:"bucket,omitempty"`
+	Prefix string `json:"prefix,omitempty"`
+	Region string `json:"region,omitempty"`
+	// SFTP settings. URL is sftp://user@host[:port]/directory and Pattern a
+	// glob matched against the file names in that directory, "*" by default.
+	// The user authenticates with the "private_key" or "password" secret and
+	// HostKey is the server public key in authorized_keys format.
+	Pattern string `json:"pattern,omitempty"`
+	HostKey string `json:"host_key,omitempty"`
+
+	// IngestRows also writes the rows of imported CSV and JSON files to
+	// DatasetID using Mode (files target only)
+	IngestRows bool `json:"ingest_rows,omitempty"`
+
+	// Mapping from target field to source field, and fallback values for missing fields
+	Mapping  map[string]string `json:"mapping,omitempty"`
+	Defaults map[string]string `json:"defaults,omitempty"`
+	// IDField identifies a remote record across runs (datasets target only)
+	IDField string `json:"id_field,omitempty"`
+	// RowsField names a record field holding a table that is written as the
+	// rows of the harvested dataset (datasets target only)
+	RowsField string `json:"rows_field,omitempty"`
+	// TopicMap maps source topic names to portal topic IDs. Unmapped names are
+	// matched to existing topics by name, or created.
+	TopicMap map[string]string `json:"topic_map,omitempty"`
+	// Dedup matches new records to datasets that exist already, like those
+	// other harvesters imported (datasets target only)
+	Dedup *DedupConfig `json:"dedup,omitempty"`
+}
+
+// DedupConfig configures how a harvest matches new records to existing
+// datasets. A record matches the dataset another integration harvested from
+// a record with the same external ID, or else the dataset of the
+// organization whose name is the most similar. Matches from TitleThreshold
+// on are resolved by Policy; weaker ones from ReviewThreshold on are queued
+// for review.
+type DedupConfig struct {
+	// ExternalIDField names a record field identifying the record across
+	// sources, like a DOI. Without it records are matched by name only, as
+	// the IDs of different sources may collide.
+	ExternalIDField string `json:"external_id_field,omitempty"`
+	// Similarities of names from 0 to 1, 0.9 and 0.6 by default
+	TitleThreshold  float64 `json:"title_threshold,omitempty"`
+	ReviewThreshold float64 `json:"review_threshold,omitempty"`
+	// AnyOrganization matches names of datasets of every organization
+	AnyOrganization bool           `json:"any_organization,omitempty"`
+	Policy          ConflictPolicy `json:"policy,omitempty"`
+}
+
+// Default similarities of dataset names for DedupConfig
+const (
+	DefaultTitleThreshold  = 0.9
+	DefaultReviewThreshold = 0.6
+)
+
+// ConflictPolicy represents what a harvest does with a record matching an
+// existing dataset. The policy resolving a match is kept for the record, so
+// later runs write it the same way.
+type ConflictPolicy string
+
+const (
+	ConflictPolicyReview    ConflictPolicy = "review"    // queue the match for review (default)
+	ConflictPolicyLink      ConflictPolicy = "link"      // track the dataset without changing it
+	ConflictPolicyMerge     ConflictPolicy = "merge"     // fill the empty fields of the dataset
+	ConflictPolicyOverwrite ConflictPolicy = "overwrite" // replace the fields and rows of the dataset
+	ConflictPolicyCreate    ConflictPolicy = "create"    // create a new dataset, as without a match
+)
+
+// HarvestRecord links a remote record of a connector to the dataset it is
+// harvested into. Policy is how later runs write the record to the dataset:
+// create and overwrite update it, merge fills its empty fields and link
+// leaves it alone.
+type HarvestRecord struct {
+	IntegrationID string    `db:"integration_id" json:"integration_id"`
+	RemoteID      string    `db:"remote_id" json:"remote_id"`
+	ExternalID    *string   `db:"external_id" json:"external_id,omitempty"`
+	DatasetID     string    `db:"dataset_id" json:"dataset_id"`
+	Policy        string    `db:"policy" json:"policy"`
+	HarvestedAt   time.Time `db:"harvested_at" json:"harvested_at"`
+}
+
+// HarvestMatch is a harvested record matching an existing dataset that waits
+// for review, or was reviewed
+type HarvestMatch struct {
+	ID            string     `db:"id" json:"id"`
+	IntegrationID string     `db:"integration_id" json:"integration_id"`
+	RemoteID      string     `db:"remote_id" json:"remote_id"`
+	ExternalID    *string    `db:"external_id" json:"external_id,omitempty"`
+	DatasetID     string     `db:"dataset_id" json:"dataset_id"`
+	Reason        string     `db:"reason" json:"reason"`
+	Score         float64    `db:"score" json:"score"`
+	Record        string     `db:"record" json:"-"` // JSON of the remote record
+	Status        string     `db:"status" json:"status"`
+	Resolution    *string    `db:"resolution" json:"resolution,omitempty"`
+	ResolvedBy    *string    `db:"resolved_by" json:"resolved_by,omitempty"`
+	ResolvedAt    *time.Time `db:"resolved_at" json:"resolved_at,omitempty"`
+	CreatedAt     time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt     time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// MatchReason represents why a record matched a dataset
+type MatchReason string
+
+const (
+	MatchReasonExternalID MatchReason = "external_id" // another integration harvested the same external ID
+	MatchReasonTitle      MatchReason = "title"       // the names are similar
+)
+
+// MatchStatus represents the review status of a harvest match
+type MatchStatus string
+
+const (
+	MatchStatusPending  MatchStatus = "pending"
+	MatchStatusResolved MatchStatus = "resolved"
+)
+
+// ListMatchesRequest represents list harvest matches input
+type ListMatchesRequest struct {
+	Page   int     `json:"page" validate:"min=1"`
+	Limit  int     `json:"limit" validate:"min=1,max=100"`
+	Status *string `json:"status,omitempty"`
+}
+
+// ResolveMatchRequest represents how a reviewer resolves a harvest match
+type ResolveMatchRequest struct {
+	Policy ConflictPolicy `json:"policy" validate:"required,oneof=link merge overwrite create"`
+}
+
+// HarvestMatchInfo represents harvest match information for API responses,
+// with the remote record
+type HarvestMatchInfo struct {
+	HarvestMatch
+	Record map[string]interface{} `json:"record"`
+}
+
+// HarvestMatchListResponse represents paginated harvest matches
+type HarvestMatchListResponse struct {
+	Matches []HarvestMatchInfo `json:"matches"`
+	Meta    ListMeta           `json:"meta"`
+}
+
+// SyncedObject records the version of a bucket object imported by a connector,
+// so unchanged objects are not imported again
+type SyncedObject struct {
+	IntegrationID string    `db:"integration_id" json:"integration_id"`
+	Key           string    `db:"object_key" json:"key"`
+	ETag          string    `db:"etag" json:"etag"`
+	Size          int64     `db:"size" json:"size"`
+	FileID        string    `db:"file_id" json:"file_id"`
+	SyncedAt      time.Time `db:"synced_at" json:"synced_at"`
+}
+
+// Run records a single harvest execution of a connector integration
+type Run struct {
+	ID             string     `db:"id" json:"id"`
+	IntegrationID  string     `db:"integration_id" json:"integration_id"`
+	Trigger        string     `db:"trigger" json:"trigger"`
+	Status         string     `db:"status" json:"status"`
+	RecordsFetched int        `db:"records_fetched" json:"records_fetched"`
+	RecordsCreated int        `db:"records_created" json:"records_created"`
+	RecordsUpdated int        `db:"records_updated" json:"records_updated"`
+	RecordsFailed  int        `db:"records_failed" json:"records_failed"`
+	RecordsQueued  int        `db:"records_queued" json:"records_queued"` // matches queued for review
+	Errors         string     `db:"errors" json:"-"` // JSON array of error messages
+	StartedAt      time.Time  `db:"started_at" json:"started_at"`
+	FinishedAt     *time.Time `db:"finished_at" json:"finished_at,omitempty"`
+}
+
+// RunStatus represents harvest run status
+type RunStatus string
+
+const (
+	RunStatusRunning   RunStatus = "running"
+	RunStatusSucceeded RunStatus = "succeeded"
+	RunStatusPartial   RunStatus = "partial" // some records failed
+	RunStatusFailed    RunStatus = "failed"
+)
+
+// RunTrigger represents what started a harvest run
+type RunTrigger string
+
+const (
+	RunTriggerSchedule RunTrigger = "schedule"
+	RunTriggerManual   RunTrigger = "manual"
+)
+
+// RunJob is a run waiting on the shared job queue
+type RunJob struct {
+	IntegrationID string    `json:"integration_id"`
+	Type          string    `json:"type"`
+	Trigger       string    `json:"trigger"`
+	QueuedAt      time.Time `json:"queued_at"`
+}
+
+// ListRunsRequest represents list runs input
+type ListRunsRequest struct {
+	Page  int `json:"page" validate:"min=1"`
+	Limit int `json:"limit" validate:"min=1,max=100"`
+}
+
+// RunInfo represents run information for API responses
+type RunInfo struct {
+	ID             string     `json:"id"`
+	IntegrationID  string     `json:"integration_id"`
+	Trigger        string     `json:"trigger"`
+	Status         string     `json:"status"`
+	RecordsFetched int        `json:"records_fetched"`
+	RecordsCreated int        `json:"records_created"`
+	RecordsUpdated int        `json:"records_updated"`
+	RecordsFailed  int        `json:"records_failed"`
+	RecordsQueued  int        `json:"records_queued"`
+	Errors         []string   `json:"errors"`
+	StartedAt      time.Time  `json:"started_at"`
+	FinishedAt     *time.Time `json:"finished_at,omitempty"`
+	DurationMs     int64      `json:"duration_ms,omitempty"`
+}
+
+// RunListResponse represents paginated run history
+type RunListResponse struct {
+	Runs []RunInfo `json:"runs"`
+	Meta ListMeta  `json:"meta"`
+}
+
+// HealthState summarizes how the recent runs of an integration went
+type HealthState string
+
+const (
+	HealthStateHealthy  HealthState = "healthy"
+	HealthStateDegraded HealthState = "degraded" // the last run failed or partly failed
+	HealthStateFailing  HealthState = "failing"  // consecutive failures reached the alert threshold
+	HealthStateUnknown  HealthState = "unknown"  // no finished run yet
+)
+
+// IntegrationHealth represents the run health of a connector or publisher integration
+type IntegrationHealth struct {
+	IntegrationID       string     `json:"integration_id"`
+	Name                string     `json:"name"`
+	Type                string     `json:"type"`
+	Status              string     `json:"status"`
+	State               string     `json:"state"`
+	Running             bool       `json:"running"`
+	LastRunStatus       *string    `json:"last_run_status,omitempty"`
+	LastRunAt           *time.Time `json:"last_run_at,omitempty"`
+	LastError           *string    `json:"last_error,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	// Latency of the recent finished runs
+	AvgDurationMs  int64      `json:"avg_duration_ms"`
+	LastDurationMs int64      `json:"last_duration_ms"`
+	MaxDurationMs  int64      `json:"max_duration_ms"`
+	NextRunAt      *time.Time `json:"next_run_at,omitempty"`
+}
+
+// HealthResponse represents the integration health dashboard
+type HealthResponse struct {
+	Integrations     []IntegrationHealth `json:"integrations"`
+	States           map[string]int      `json:"states"` // integration count per health state
+	FailureThreshold int                 `json:"failure_threshold"`
+	RunWindow        int                 `json:"run_window"` // recent runs considered per integration
+}
+
+// PublisherType represents the external catalogue a publisher integration pushes to
+type PublisherType string
+
+const (
+	PublisherTypeCKAN PublisherType = "ckan"
+)
+
+// PublisherConfig is the JSON stored in Integration.Config for publisher integrations
+type PublisherConfig struct {
+	Publisher PublisherType `json:"publisher"`
+	Schedule  string        `json:"schedule,omitempty"` // standard 5-field cron expression
+
+	// URL of the catalogue, falls back to Integration.Endpoint. The API key is
+	// the integration API key.
+	URL      string `json:"url,omitempty"`
+	OwnerOrg string `json:"owner_org"` // remote organization the packages belong to
+	// NamePrefix is prepended to dataset slugs to build unique package names
+	NamePrefix string `json:"name_prefix,omitempty"`
+	// DatasetURL links packages back to the portal; {id} and {slug} are replaced
+	DatasetURL string `json:"dataset_url,omitempty"`
+	// ResourceBaseURL is the public base URL of uploaded files. Files are only
+	// published as resources when it is set.
+	ResourceBaseURL string `json:"resource_base_url,omitempty"`
+	// Extras are added to every package
+	Extras map[string]string `json:"extras,omitempty"`
+}
+
+// PushRecord tracks the remote copy of a dataset pushed by a publisher integration
+type PushRecord struct {
+	IntegrationID    string    `db:"integration_id" json:"integration_id"`
+	DatasetID        string    `db:"dataset_id" json:"dataset_id"`
+	RemoteID         *string   `db:"remote_id" json:"remote_id,omitempty"`
+	RemoteName       *string   `db:"remote_name" json:"remote_name,omitempty"`
+	Status           string    `db:"status" json:"status"`
+	Error            *string   `db:"error" json:"error,omitempty"`
+	DatasetUpdatedAt time.Time `db:"dataset_updated_at" json:"dataset_updated_at"` // dataset version last pushed
+	PushedAt         time.Time `db:"pushed_at" json:"pushed_at"`
+}
+
+// PushStatus represents the sync status of a pushed dataset
+type PushStatus string
+
+const (
+	PushStatusSynced PushStatus = "synced"
+	PushStatusFailed PushStatus = "failed"
+)
+
+// ListPushRecordsRequest represents list push records input
+type ListPushRecordsRequest struct {
+	Page   int     `json:"page" validate:"min=1"`
+	Limit  int     `json:"limit" validate:"min=1,max=100"`
+	Status *string `json:"status,omitempty"`
+}
+
+// PushRecordInfo represents push record information for API responses
+type PushRecordInfo struct {
+	DatasetID        string    `json:"dataset_id"`
+	RemoteID         *string   `json:"remote_id,omitempty"`
+	RemoteName       *string   `json:"remote_name,omitempty"`
+	Status           string    `json:"status"`
+	Error            *string   `json:"error,omitempty"`
+	DatasetUpdatedAt time.Time `json:"dataset_updated_at"`
+	PushedAt         time.Time `json:"pushed_at"`
+}
+
+// PushRecordListResponse represents paginated push records
+type PushRecordListResponse struct {
+	Records []PushRecordInfo `json:"records"`
+	Meta    ListMeta         `json:"meta"`
+}
+
+// IngestMode represents how ingested rows are written to the target dataset
+type IngestMode string
+
+const (
+	IngestModeAppend  IngestMode = "append"  // rows are added after the existing rows
+	IngestModeUpsert  IngestMode = "upsert"  // rows are matched on KeyField
+	IngestModeReplace IngestMode = "replace" // the payload replaces all rows
+)
+
+// FieldType represents the type an ingested field is validated and converted to
+type FieldType string
+
+const (
+	FieldTypeString  FieldType = "string"
+	FieldTypeNumber  FieldType = "number"
+	FieldTypeInteger FieldType = "integer"
+	FieldTypeBoolean FieldType = "boolean"
+	FieldTypeDate    FieldType = "date" // YYYY-MM-DD or RFC 3339
+)
+
+// IngestConfig is the JSON stored in Integration.Config for inbound integrations.
+// It is the field-mapping template applied to every ingested payload.
+type IngestConfig struct {
+	DatasetID   string     `json:"dataset_id"`
+	Mode        IngestMode `json:"mode,omitempty"`         // defaults to append
+	KeyField    string     `json:"key_field,omitempty"`    // row column matched in upsert mode
+	RecordsPath string     `json:"records_path,omitempty"` // dot path to the record array in a JSON payload
+	Delimiter   string     `json:"delimiter,omitempty"`    // CSV payloads only
+
+	// Mapping from row column to payload field, and fallback values for missing fields
+	Mapping  map[string]string `json:"mapping,omitempty"`
+	Defaults map[string]string `json:"defaults,omitempty"`
+	// Required columns must be present and non-empty
+	Required []string `json:"required,omitempty"`
+	// Types validates and converts columns
+	Types map[string]FieldType `json:"types,omitempty"`
+	// RejectOnError rejects the whole payload when any row is invalid,
+	// instead of writing the valid rows
+	RejectOnError bool `json:"reject_on_error,omitempty"`
+}
+
+// IngestToken authenticates an external system to the ingest endpoint of one integration
+type IngestToken struct {
+	ID            string     `db:"id" json:"id"`
+	IntegrationID string     `db:"integration_id" json:"integration_id"`
+	Name          string     `db:"name" json:"name"`
+	TokenHash     string     `db:"token_hash" json:"-"` // SHA-256 of the token
+	Prefix        string     `db:"prefix" json:"prefix"`
+	LastUsedAt    *time.Time `db:"last_used_at" json:"last_used_at,omitempty"`
+	ExpiresAt     *time.Time `db:"expires_at" json:"expires_at,omitempty"`
+	RevokedAt     *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+	CreatedBy     string     `db:"created_by" json:"created_by"`
+	CreatedAt     time.Time  `db:"created_at" json:"created_at"`
+}
+
+// CreateIngestTokenRequest represents create ingest token input
+type CreateIngestTokenRequest struct {
+	Name      string     `json:"name" validate:"required,min=2,max=100"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// IngestTokenInfo represents ingest token information for API responses
+type IngestTokenInfo struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedBy  string     `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// IngestTokenCreated is returned once when a token is created. The token
+// itself cannot be retrieved again.
+type IngestTokenCreated struct {
+	IngestTokenInfo
+	Token string `json:"token"`
+}
+
+// Ingestion records one request to an ingest endpoint
+type Ingestion struct {
+	ID             string     `db:"id" json:"id"`
+	IntegrationID  string     `db:"integration_id" json:"integration_id"`
+	IdempotencyKey *string    `db:"idempotency_key" json:"idempotency_key,omitempty"`
+	Status         string     `db:"status" json:"status"`
+	Report         *string    `db:"report" json:"-"` // JSON encoded IngestReport
+	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
+	CompletedAt    *time.Time `db:"completed_at" json:"completed_at,omitempty"`
+}
+
+// IngestionStatus represents the processing status of an ingestion
+type IngestionStatus string
+
+const (
+	IngestionStatusProcessing IngestionStatus = "processing"
+	IngestionStatusCompleted  IngestionStatus = "completed"
+	IngestionStatusFailed     IngestionStatus = "failed"
+)
+
+// IngestPayload is a raw payload sent to an ingest endpoint
+type IngestPayload struct {
+	ContentType    string
+	Body           []byte
+	IdempotencyKey string
+}
+
+// IngestResult represents the outcome of a payload as a whole
+type IngestResult string
+
+const (
+	IngestResultAccepted IngestResult = "accepted" // every row was written
+	IngestResultPartial  IngestResult = "partial"  // invalid rows were skipped
+	IngestResultRejected IngestResult = "rejected" // nothing was written
+)
+
+// IngestRowError describes why a payload row was not written
+type IngestRowError struct {
+	Row     int    `json:"row"` // zero-based position in the payload
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// IngestReport is the validation report returned for every ingested payload
+type IngestReport struct {
+	IngestionID string           `json:"ingestion_id"`
+	Result      IngestResult     `json:"result"`
+	Received    int              `json:"received"`
+	Accepted    int              `json:"accepted"`
+	Rejected    int              `json:"rejected"`
+	Created     int              `json:"created"`
+	Updated     int              `json:"updated"`
+	Errors      []IngestRowError `json:"errors"`
+	Replayed    bool             `json:"replayed"` // answered from an earlier request with the same idempotency key
+}
+
+// NotifierConfig is the JSON stored in Integration.Config for Slack and Teams
+// integrations. Each channel is an incoming webhook whose URL is kept in the
+// integration secret named after the channel.
+type NotifierConfig struct {
+	Channels []NotifierChannel `json:"channels"`
+}
+
+// NotifierChannel routes a set of events to one chat channel
+type NotifierChannel struct {
+	Name   string   `json:"name"`
+	Events []string `json:"events"` // event types, "*" routes every event
+}
+
+// ConnectionCheck is the outcome of one step of a connection test
+type ConnectionCheck struct {
+	Name       string `json:"name"`   // config, auth, fetch, delivery
+	Status     string `json:"status"` // passed, failed, skipped
+	Message    string `json:"message,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// Connection check statuses
+const (
+	CheckPassed  = "passed"
+	CheckFailed  = "failed"
+	CheckSkipped = "skipped"
+)
+
+// ConnectionTestResult represents the diagnostics of a dry-run against an integration
+type ConnectionTestResult struct {
+	Success    bool                     `json:"success"`
+	Checks     []ConnectionCheck        `json:"checks"`
+	Sample     []map[string]interface{} `json:"sample,omitempty"`
+	DurationMs int64                    `json:"duration_ms"`
+}
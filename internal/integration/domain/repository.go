@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"time"
 )
 
 type Repository interface {
@@ -10,8 +11,12 @@ type Repository interface {
 	Create(ctx context.Context, integration *Integration) error
 	Update(ctx context.Context, id string, integration *Integration) error
 	Delete(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) error
 	UpdateStatus(ctx context.Context, id string, status string) error
 	Sync(ctx context.Context, id string) error
+	// ReencryptSecrets rewrites every stored credential with the current
+	// encryption key and returns how many integrations changed
+	ReencryptSecrets(ctx context.Context) (int, error)
 }
 
 type IntegrationFilter struct {
@@ -20,3 +25,100 @@ type IntegrationFilter struct {
 	Status         *string
 	Search         string
 }
+
+// EventPublisher emits integration health events to webhooks and sinks
+type EventPublisher interface {
+	Publish(ctx context.Context, eventType string, data interface{}) error
+}
+
+// Integration health event types
+const (
+	EventIntegrationFailing   = "integration.failing"
+	EventIntegrationRecovered = "integration.recovered"
+)
+
+// DeliveryRepository persists webhook subscriptions and deliveries
+type DeliveryRepository interface {
+	ListSubscriptions(ctx context.Context, integrationID string) ([]*Subscription, error)
+	CreateSubscription(ctx context.Context, subscription *Subscription) error
+	DeleteSubscription(ctx context.Context, integrationID, id string) error
+	// ListSubscribers returns active webhook integrations subscribed to the event type
+	ListSubscribers(ctx context.Context, eventType string) ([]*Integration, error)
+
+	CreateDelivery(ctx context.Context, delivery *Delivery) error
+	GetDelivery(ctx context.Context, id string) (*Delivery, error)
+	UpdateDelivery(ctx context.Context, delivery *Delivery) error
+	ListDeliveries(ctx context.Context, integrationID string, status *string, limit, offset int) ([]*Delivery, int, error)
+	// ClaimDueDeliveries locks up to limit due deliveries and pushes their next
+	// attempt out by lease so concurrent workers do not pick them up twice
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*Delivery, error)
+
+	CreateAttempt(ctx context.Context, attempt *DeliveryAttempt) error
+	ListAttempts(ctx context.Context, deliveryID string) ([]*DeliveryAttempt, error)
+}
+
+// RunRepository persists connector harvest runs and the remote records they produced
+type RunRepository interface {
+	CreateRun(ctx context.Context, run *Run) error
+	UpdateRun(ctx context.Context, run *Run) error
+	ListRuns(ctx context.Context, integrationID string, limit, offset int) ([]*Run, int, error)
+	// ListLatestRuns returns up to perIntegration of the most recent runs of
+	// every integration, newest first
+	ListLatestRuns(ctx context.Context, perIntegration int) ([]*Run, error)
+	// ClaimNextRun moves next_run_at from nextRunAt to next and reports whether
+	// this caller won, so only one instance queues a scheduled run
+	ClaimNextRun(ctx context.Context, integrationID string, nextRunAt *time.Time, next time.Time) (bool, error)
+	// AcquireRunLock marks the integration as running and reports false if it
+	// already holds a lock taken after staleBefore, so runs never overlap
+	AcquireRunLock(ctx context.Context, integrationID string, now, staleBefore time.Time) (bool, error)
+	ReleaseRunLock(ctx context.Context, integrationID string) error
+
+	// GetHarvestRecord returns the record of the dataset a remote record is
+	// harvested into
+	GetHarvestRecord(ctx context.Context, integrationID, remoteID string) (*HarvestRecord, error)
+	// FindHarvestRecord returns the most recent record another integration
+	// than integrationID harvested with the external ID
+	FindHarvestRecord(ctx context.Context, externalID, integrationID string) (*HarvestRecord, error)
+	// SaveHarvestRecord inserts or replaces the record of a remote record
+	SaveHarvestRecord(ctx context.Context, record *HarvestRecord) error
+
+	// ListSyncedObjects returns the bucket objects imported by a connector
+	ListSyncedObjects(ctx context.Context, integrationID string) ([]*SyncedObject, error)
+	// SaveSyncedObject inserts or replaces the record of a bucket object
+	SaveSyncedObject(ctx context.Context, object *SyncedObject) error
+}
+
+// MatchRepository persists the harvested records matching existing datasets
+type MatchRepository interface {
+	// SaveMatch stores a pending match, replacing the pending match of the
+	// same remote record
+	SaveMatch(ctx context.Context, match *HarvestMatch) error
+	GetMatch(ctx context.Context, integrationID, id string) (*HarvestMatch, error)
+	ListMatches(ctx context.Context, integrationID string, status *string, limit, offset int) ([]*HarvestMatch, int, error)
+	// ResolveMatch records the resolution of a pending match, or returns
+	// ErrNotFound when it is no longer pending
+	ResolveMatch(ctx context.Context, match *HarvestMatch) error
+}
+
+// PushRepository tracks datasets pushed to external catalogues by publisher integrations
+type PushRepository interface {
+	GetPushRecord(ctx context.Context, integrationID, datasetID string) (*PushRecord, error)
+	// SavePushRecord inserts or replaces the record of a dataset
+	SavePushRecord(ctx context.Context, record *PushRecord) error
+	ListPushRecords(ctx context.Context, integrationID string, status *string, limit, offset int) ([]*PushRecord, int, error)
+}
+
+// IngestRepository persists inbound ingest tokens and ingestions
+type IngestRepository interface {
+	CreateToken(ctx context.Context, token *IngestToken) error
+	ListTokens(ctx context.Context, integrationID string) ([]*IngestToken, error)
+	GetTokenByHash(ctx context.Context, tokenHash string) (*IngestToken, error)
+	RevokeToken(ctx context.Context, integrationID, id string, at time.Time) error
+	TouchToken(ctx context.Context, id string, at time.Time) error
+
+	// CreateIngestion stores a new ingestion and reports false without storing
+	// it if the integration already has one with the same idempotency key
+	CreateIngestion(ctx context.Context, ingestion *Ingestion) (bool, error)
+	GetIngestionByKey(ctx context.Context, integrationID, idempotencyKey string) (*Ingestion, error)
+	UpdateIngestion(ctx context.Context, ingestion *Ingestion) error
+}
@@ -0,0 +1,121 @@
+// Package integration is the module connecting the portal to external
+// systems: harvesting from connectors, pushing to publishers, inbound ingest
+// and webhooks.
+package integration
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"time"
+
+	"portal-data-backend/infrastructure/db"
+	"portal-data-backend/infrastructure/http/openapi"
+	"portal-data-backend/infrastructure/security"
+	"portal-data-backend/internal/app"
+	"portal-data-backend/internal/integration/delivery/cli"
+	delivery "portal-data-backend/internal/integration/delivery/http"
+	"portal-data-backend/internal/integration/repository"
+	"portal-data-backend/internal/integration/usecase"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jmoiron/sqlx"
+)
+
+// Module manages integrations, delivers webhooks and runs scheduled
+// harvests and pushes in the background
+type Module struct {
+	handler    *delivery.Handler
+	webhooks   usecase.WebhookUsecase
+	scheduler  usecase.SchedulerUsecase
+	db         *sqlx.DB
+	adminRoles []string
+}
+
+// Name implements app.Module
+func (m *Module) Name() string {
+	return "integration"
+}
+
+// Register implements app.Module
+func (m *Module) Register(deps *app.Deps) error {
+	m.db = deps.DB
+	services := deps.Services
+	switch {
+	case services.Datasets == nil:
+		return app.MissingServiceError("dataset")
+	case services.DataRows == nil:
+		return app.MissingServiceError("data row")
+	case services.Files == nil:
+		return app.MissingServiceError("file")
+	case services.Topics == nil:
+		return app.MissingServiceError("topic")
+	case services.Units == nil:
+		return app.MissingServiceError("unit")
+	case services.Notifications == nil:
+		return app.MissingServiceError("notification")
+	case services.Templates == nil:
+		return app.MissingServiceError("message template")
+	}
+	services.Templates.Define(usecase.Messages...)
+
+	// Initialize encryption for stored integration credentials
+	cfg := deps.Config
+	if cfg.Secrets.Key == "" {
+		// Only reachable outside production; config validation requires a key there
+		deps.Logger.Info("SECRETS_ENCRYPTION_KEY not set, deriving a development key from the JWT secret")
+		devKey := sha256.Sum256([]byte(cfg.JWT.Secret))
+		cfg.Secrets.Key = base64.StdEncoding.EncodeToString(devKey[:])
+	}
+	secretCipher, err := security.NewSecretCipherFromConfig(&cfg.Secrets)
+	if err != nil {
+		return fmt.Errorf("failed to initialize secret encryption: %w", err)
+	}
+
+	repo := repository.NewIntegrationPostgresRepository(deps.DB, secretCipher)
+	runRepo := repository.NewRunPostgresRepository(deps.DB)
+
+	// Webhooks are delivered for the events of every module
+	m.webhooks = usecase.NewWebhookUsecase(repo, repository.NewDeliveryPostgresRepository(deps.DB), cfg.Webhook)
+	deps.Events.Subscribe(m.webhooks)
+
+	integrations := usecase.NewIntegrationUsecase(repo)
+	health := usecase.NewHealthUsecase(repo, runRepo, deps.Outbox, services.Notifications, services.Templates, cfg.Scheduler)
+	harvests := usecase.NewHarvestUsecase(repo, runRepo, repository.NewMatchPostgresRepository(deps.DB), deps.Tx, services.Datasets, services.DataRows, services.Files, services.Topics, services.Units, health, cfg.Harvest)
+	pushes := usecase.NewPushUsecase(repo, runRepo, repository.NewPushPostgresRepository(deps.DB), services.Datasets, services.Files, health, cfg.Harvest)
+	ingests := usecase.NewIngestUsecase(repo, repository.NewIngestPostgresRepository(deps.DB), deps.Tx, services.DataRows, cfg.Harvest)
+	m.scheduler = usecase.NewSchedulerUsecase(repo, runRepo, harvests, pushes, cfg.Scheduler)
+	deps.Health.Register("job_queue", m.scheduler.Ping)
+
+	m.handler = delivery.NewHandler(integrations, m.webhooks, harvests, pushes, ingests, m.scheduler, health)
+	m.adminRoles = cfg.Audit.AdminRoles
+	return nil
+}
+
+// Routes implements app.Module
+func (m *Module) Routes(r chi.Router, auth func(http.Handler) http.Handler) {
+	delivery.RegisterRoutes(r, m.handler, auth, m.adminRoles)
+}
+
+// Describe implements app.Module
+func (m *Module) Describe(spec *openapi.Spec) {
+	delivery.Describe(spec)
+}
+
+// Purge implements app.Purger
+func (m *Module) Purge(ctx context.Context, before time.Time) (int64, error) {
+	return db.PurgeDeleted(ctx, m.db, "integrations", before)
+}
+
+// Run implements app.Runner
+func (m *Module) Run(ctx context.Context) {
+	go m.webhooks.Run(ctx)
+	m.scheduler.Run(ctx)
+}
+
+// Commands implements app.Commander
+func (m *Module) Commands() []app.Command {
+	return cli.Commands(m.scheduler)
+}
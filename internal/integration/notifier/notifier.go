@@ -0,0 +1,191 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"portal-data-backend/internal/integration/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+)
+
+// eventTitles are the message headlines of the events chat channels usually follow
+var eventTitles = map[string]string{
+	"ticket.created":         "New helpdesk ticket",
+	"ticket.sla_breached":    "Helpdesk ticket SLA breached",
+	"dataset.pending_review": "Dataset pending review",
+	"dataset.published":      "Dataset published",
+	"integration.failing":    "Integration failing",
+	"integration.recovered":  "Integration recovered",
+	"ping":                   "Portal connection test",
+}
+
+// summaryFields are the event data fields shown in a message, in order
+var summaryFields = []string{
+	"priority", "status", "category", "validation_status", "classification",
+	"assigned_to", "sla_breached_at", "consecutive_failures", "last_error", "created_at",
+}
+
+// message is the chat-agnostic content of a notification
+type message struct {
+	Title  string
+	Text   string
+	Fields []field
+	Footer string
+}
+
+type field struct {
+	Name  string
+	Value string
+}
+
+// ParseConfig decodes and validates the channel routing of a Slack or Teams
+// integration. Every channel needs a secret holding its incoming webhook URL.
+func ParseConfig(integration *domain.Integration) (*domain.NotifierConfig, error) {
+	var cfg domain.NotifierConfig
+	if err := json.Unmarshal([]byte(integration.Config), &cfg); err != nil {
+		return nil, fmt.Errorf("%w: invalid %s config: %v", pkgErrors.ErrInvalidInput, integration.Type, err)
+	}
+
+	if len(cfg.Channels) == 0 {
+		return nil, fmt.Errorf("%w: %s integration requires at least one channel", pkgErrors.ErrInvalidInput, integration.Type)
+	}
+
+	seen := make(map[string]bool, len(cfg.Channels))
+	for _, channel := range cfg.Channels {
+		if channel.Name == "" {
+			return nil, fmt.Errorf("%w: channel name is required", pkgErrors.ErrInvalidInput)
+		}
+		if seen[channel.Name] {
+			return nil, fmt.Errorf("%w: duplicate channel %q", pkgErrors.ErrInvalidInput, channel.Name)
+		}
+		seen[channel.Name] = true
+
+		if len(channel.Events) == 0 {
+			return nil, fmt.Errorf("%w: channel %q must route at least one event", pkgErrors.ErrInvalidInput, channel.Name)
+		}
+		if _, err := WebhookURL(integration, channel.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	return &cfg, nil
+}
+
+// Route returns the channels of cfg that receive eventType
+func Route(cfg *domain.NotifierConfig, eventType string) []string {
+	var channels []string
+	for _, channel := range cfg.Channels {
+		for _, routed := range channel.Events {
+			if routed == eventType || routed == domain.SubscriptionWildcard {
+				channels = append(channels, channel.Name)
+				break
+			}
+		}
+	}
+	return channels
+}
+
+// WebhookURL returns the incoming webhook URL of a channel, stored in the
+// integration secret named after the channel
+func WebhookURL(integration *domain.Integration, channel string) (string, error) {
+	raw, ok := integration.Secrets[channel]
+	if !ok || raw == "" {
+		return "", fmt.Errorf("%w: channel %q requires a secret with its webhook url", pkgErrors.ErrInvalidInput, channel)
+	}
+
+	parsed, err := url.Parse(raw)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+		return "", fmt.Errorf("%w: channel %q has an invalid webhook url", pkgErrors.ErrInvalidInput, channel)
+	}
+	return raw, nil
+}
+
+// Format renders an event as the message body posted to the webhooks of a
+// Slack or Teams integration
+func Format(integrationType string, event *domain.Event) ([]byte, error) {
+	msg, err := summarize(event)
+	if err != nil {
+		return nil, err
+	}
+
+	switch integrationType {
+	case string(domain.IntegrationTypeSlack):
+		return encode(slackMessage(msg))
+	case string(domain.IntegrationTypeTeams):
+		return encode(teamsMessage(msg))
+	default:
+		return nil, fmt.Errorf("%w: unsupported notifier %q", pkgErrors.ErrInvalidInput, integrationType)
+	}
+}
+
+// encode marshals a message body without escaping HTML characters, which chat
+// services would otherwise show literally
+func encode(payload interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(payload); err != nil {
+		return nil, fmt.Errorf("failed to encode message: %w", err)
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+// summarize picks a headline, the name of the subject and its key fields out
+// of the event data
+func summarize(event *domain.Event) (*message, error) {
+	msg := &message{
+		Title:  eventTitles[event.Type],
+		Footer: event.Type + " at " + event.OccurredAt.UTC().Format(time.RFC3339),
+	}
+	if msg.Title == "" {
+		msg.Title = "Portal event " + event.Type
+	}
+
+	raw, err := json.Marshal(event.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode event data: %w", err)
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		// Data that is not an object has no fields to show
+		return msg, nil
+	}
+
+	for _, key := range []string{"title", "name", "id"} {
+		if value, ok := scalar(data[key]); ok {
+			msg.Text = value
+			break
+		}
+	}
+	for _, key := range summaryFields {
+		if value, ok := scalar(data[key]); ok {
+			msg.Fields = append(msg.Fields, field{Name: label(key), Value: value})
+		}
+	}
+	return msg, nil
+}
+
+// scalar formats a JSON string, number or boolean; other values are skipped
+func scalar(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, v != ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(v), true
+	default:
+		return "", false
+	}
+}
+
+// label turns a field name such as validation_status into "Validation status"
+func label(key string) string {
+	words := strings.ReplaceAll(key, "_", " ")
+	return strings.ToUpper(words[:1]) + words[1:]
+}
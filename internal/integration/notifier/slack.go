@@ -0,0 +1,63 @@
+package notifier
+
+import "strings"
+
+// slackMaxFields is the most fields Slack accepts in one section block
+const slackMaxFields = 10
+
+// slackEscaper escapes the control characters of Slack mrkdwn text
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type slackBlock struct {
+	Type     string      `json:"type"`
+	Text     *slackText  `json:"text,omitempty"`
+	Fields   []slackText `json:"fields,omitempty"`
+	Elements []slackText `json:"elements,omitempty"`
+}
+
+type slackPayload struct {
+	Text   string       `json:"text"` // fallback shown in notifications
+	Blocks []slackBlock `json:"blocks"`
+}
+
+// slackMessage builds a Block Kit message for a Slack incoming webhook
+func slackMessage(msg *message) *slackPayload {
+	fallback := msg.Title
+	if msg.Text != "" {
+		fallback += ": " + msg.Text
+	}
+
+	payload := &slackPayload{
+		Text:   slackEscaper.Replace(fallback),
+		Blocks: []slackBlock{{Type: "header", Text: &slackText{Type: "plain_text", Text: msg.Title}}},
+	}
+	if msg.Text != "" {
+		payload.Blocks = append(payload.Blocks, slackBlock{
+			Type: "section",
+			Text: &slackText{Type: "mrkdwn", Text: slackEscaper.Replace(msg.Text)},
+		})
+	}
+	if len(msg.Fields) > 0 {
+		section := slackBlock{Type: "section"}
+		for i, f := range msg.Fields {
+			if i == slackMaxFields {
+				break
+			}
+			section.Fields = append(section.Fields, slackText{
+				Type: "mrkdwn",
+				Text: "*" + slackEscaper.Replace(f.Name) + "*\n" + slackEscaper.Replace(f.Value),
+			})
+		}
+		payload.Blocks = append(payload.Blocks, section)
+	}
+	payload.Blocks = append(payload.Blocks, slackBlock{
+		Type:     "context",
+		Elements: []slackText{{Type: "mrkdwn", Text: slackEscaper.Replace(msg.Footer)}},
+	})
+	return payload
+}
@@ -0,0 +1,46 @@
+package notifier
+
+// teamsThemeColor is the accent color of the message card
+const teamsThemeColor = "0076D7"
+
+type teamsFact struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type teamsSection struct {
+	Facts []teamsFact `json:"facts,omitempty"`
+	Text  string      `json:"text,omitempty"`
+}
+
+type teamsPayload struct {
+	Type       string         `json:"@type"`
+	Context    string         `json:"@context"`
+	Summary    string         `json:"summary"`
+	ThemeColor string         `json:"themeColor"`
+	Title      string         `json:"title"`
+	Text       string         `json:"text,omitempty"`
+	Sections   []teamsSection `json:"sections,omitempty"`
+}
+
+// teamsMessage builds a message card for a Microsoft Teams incoming webhook
+func teamsMessage(msg *message) *teamsPayload {
+	payload := &teamsPayload{
+		Type:       "MessageCard",
+		Context:    "https://schema.org/extensions",
+		Summary:    msg.Title,
+		ThemeColor: teamsThemeColor,
+		Title:      msg.Title,
+		Text:       msg.Text,
+	}
+
+	var facts []teamsFact
+	for _, f := range msg.Fields {
+		facts = append(facts, teamsFact{Name: f.Name, Value: f.Value})
+	}
+	if len(facts) > 0 {
+		payload.Sections = append(payload.Sections, teamsSection{Facts: facts})
+	}
+	payload.Sections = append(payload.Sections, teamsSection{Text: msg.Footer})
+	return payload
+}
@@ -0,0 +1,185 @@
+package publisher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"portal-data-backend/internal/integration/domain"
+)
+
+// maxResponseBytes caps how much of a CKAN response is read into memory
+const maxResponseBytes = 1 << 20
+
+// ckanPublisher pushes packages through the CKAN action API
+type ckanPublisher struct {
+	client *http.Client
+	cfg    *domain.PublisherConfig
+	apiKey string
+}
+
+type ckanPackage struct {
+	ID        string         `json:"id,omitempty"`
+	Name      string         `json:"name"`
+	Title     string         `json:"title"`
+	Notes     string         `json:"notes,omitempty"`
+	URL       string         `json:"url,omitempty"`
+	OwnerOrg  string         `json:"owner_org"`
+	Tags      []ckanTag      `json:"tags"`
+	Extras    []ckanExtra    `json:"extras"`
+	Resources []ckanResource `json:"resources"`
+}
+
+type ckanTag struct {
+	Name string `json:"name"`
+}
+
+type ckanExtra struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type ckanResource struct {
+	Name     string `json:"name"`
+	URL      string `json:"url"`
+	Format   string `json:"format,omitempty"`
+	MimeType string `json:"mimetype,omitempty"`
+	Size     int64  `json:"size,omitempty"`
+}
+
+type ckanActionResponse struct {
+	Success bool `json:"success"`
+	Result  struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"result"`
+	Error map[string]interface{} `json:"error,omitempty"`
+}
+
+func (p *ckanPublisher) Publish(ctx context.Context, pkg *Package, remoteID string) (*Published, error) {
+	body := ckanPackage{
+		ID:        remoteID,
+		Name:      ckanName(pkg.Name),
+		Title:     pkg.Title,
+		Notes:     pkg.Notes,
+		URL:       pkg.URL,
+		OwnerOrg:  p.cfg.OwnerOrg,
+		Tags:      []ckanTag{},
+		Extras:    []ckanExtra{},
+		Resources: []ckanResource{},
+	}
+	for _, tag := range pkg.Tags {
+		body.Tags = append(body.Tags, ckanTag{Name: tag})
+	}
+	for key, value := range pkg.Extras {
+		body.Extras = append(body.Extras, ckanExtra{Key: key, Value: value})
+	}
+	// Stable ordering keeps updates from looking like changes on the remote side
+	sort.Slice(body.Extras, func(i, j int) bool { return body.Extras[i].Key < body.Extras[j].Key })
+	for _, resource := range pkg.Resources {
+		body.Resources = append(body.Resources, ckanResource(resource))
+	}
+
+	action := "package_create"
+	if remoteID != "" {
+		// package_update replaces the whole package, including its resources
+		action = "package_update"
+	}
+
+	resp, err := p.call(ctx, action, body)
+	if err != nil {
+		return nil, err
+	}
+	return &Published{ID: resp.Result.ID, Name: resp.Result.Name}, nil
+}
+
+func (p *ckanPublisher) call(ctx context.Context, action string, body interface{}) (*ckanActionResponse, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode package: %w", err)
+	}
+
+	endpoint := strings.TrimRight(p.cfg.URL, "/") + "/api/3/action/" + action
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	// CKAN expects the raw API token, not a bearer token
+	req.Header.Set("Authorization", p.apiKey)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var decoded ckanActionResponse
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return nil, fmt.Errorf("unexpected status %d from CKAN %s", resp.StatusCode, action)
+	}
+	if !decoded.Success {
+		if resp.StatusCode == http.StatusNotFound || decoded.Error["__type"] == "Not Found Error" {
+			return nil, ErrRemoteNotFound
+		}
+		return nil, fmt.Errorf("CKAN %s failed: %s", action, ckanErrorMessage(decoded.Error))
+	}
+	return &decoded, nil
+}
+
+// ckanErrorMessage flattens a CKAN error object, which holds either a message
+// or a list of messages per invalid field
+func ckanErrorMessage(details map[string]interface{}) string {
+	if message, ok := details["message"].(string); ok && message != "" {
+		return message
+	}
+
+	var parts []string
+	for field, value := range details {
+		if field == "__type" {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s: %v", field, value))
+	}
+	if len(parts) == 0 {
+		return "unknown error"
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, "; ")
+}
+
+// ckanName converts text to a valid package name: 2-100 lowercase
+// alphanumeric characters, dashes or underscores
+func ckanName(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('-')
+		}
+	}
+
+	name := strings.Trim(b.String(), "-")
+	for strings.Contains(name, "--") {
+		name = strings.ReplaceAll(name, "--", "-")
+	}
+	if len(name) > 100 {
+		name = strings.TrimRight(name[:100], "-")
+	}
+	for len(name) < 2 {
+		name += "_"
+	}
+	return name
+}
@@ -0,0 +1,100 @@
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"portal-data-backend/internal/integration/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"github.com/robfig/cron/v3"
+)
+
+// ErrRemoteNotFound is returned when the remote package of an update no longer exists
+var ErrRemoteNotFound = errors.New("remote package not found")
+
+// Package is a dataset as published to an external catalogue
+type Package struct {
+	Name      string
+	Title     string
+	Notes     string
+	URL       string
+	Tags      []string
+	Extras    map[string]string
+	Resources []Resource
+}
+
+// Resource is a downloadable file of a package
+type Resource struct {
+	Name     string
+	URL      string
+	Format   string
+	MimeType string
+	Size     int64
+}
+
+// Published identifies a package in the remote catalogue
+type Published struct {
+	ID   string
+	Name string
+}
+
+// Publisher creates and updates packages in an external catalogue
+type Publisher interface {
+	// Publish creates pkg, or replaces the package remoteID when it is set.
+	// It returns ErrRemoteNotFound if remoteID no longer exists.
+	Publish(ctx context.Context, pkg *Package, remoteID string) (*Published, error)
+}
+
+// ParseConfig decodes and validates the publisher configuration of an integration
+func ParseConfig(integration *domain.Integration) (*domain.PublisherConfig, error) {
+	var cfg domain.PublisherConfig
+	if err := json.Unmarshal([]byte(integration.Config), &cfg); err != nil {
+		return nil, fmt.Errorf("%w: invalid publisher config: %v", pkgErrors.ErrInvalidInput, err)
+	}
+
+	if cfg.URL == "" && integration.Endpoint != nil {
+		cfg.URL = *integration.Endpoint
+	}
+
+	switch cfg.Publisher {
+	case domain.PublisherTypeCKAN:
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("%w: ckan publisher requires a url", pkgErrors.ErrInvalidInput)
+		}
+		if cfg.OwnerOrg == "" {
+			return nil, fmt.Errorf("%w: ckan publisher requires owner_org", pkgErrors.ErrInvalidInput)
+		}
+		if integration.APIKey == nil || *integration.APIKey == "" {
+			return nil, fmt.Errorf("%w: ckan publisher requires an api_key", pkgErrors.ErrInvalidInput)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported publisher %q", pkgErrors.ErrInvalidInput, cfg.Publisher)
+	}
+
+	if cfg.Schedule != "" {
+		if _, err := cron.ParseStandard(cfg.Schedule); err != nil {
+			return nil, fmt.Errorf("%w: invalid schedule: %v", pkgErrors.ErrInvalidInput, err)
+		}
+	}
+
+	return &cfg, nil
+}
+
+// New builds the publisher configured on an integration
+func New(integration *domain.Integration, cfg *domain.PublisherConfig, client *http.Client) (Publisher, error) {
+	var apiKey string
+	if integration.APIKey != nil {
+		apiKey = *integration.APIKey
+	}
+
+	switch cfg.Publisher {
+	case domain.PublisherTypeCKAN:
+		return &ckanPublisher{client: client, cfg: cfg, apiKey: apiKey}, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported publisher %q", pkgErrors.ErrInvalidInput, cfg.Publisher)
+	}
+}
@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	integrationDomain "portal-data-backend/internal/integration/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"portal-data-backend/infrastructure/db"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type deliveryPostgresRepository struct {
+	db *sqlx.DB
+}
+
+func NewDeliveryPostgresRepository(db *sqlx.DB) integrationDomain.DeliveryRepository {
+	return &deliveryPostgresRepository{db: db}
+}
+
+const deliveryColumns = `id, integration_id, event_id, event_type, channel, payload, status, attempts, next_attempt_at,
+		       last_error, response_status, delivered_at, created_at, updated_at`
+
+func (r *deliveryPostgresRepository) ListSubscriptions(ctx context.Context, integrationID string) ([]*integrationDomain.Subscription, error) {
+	query := `
+		SELECT id, integration_id, event_type, created_at
+		FROM integration_subscriptions
+		WHERE integration_id = $1
+		ORDER BY event_type ASC
+	`
+
+	var subscriptions []*integrationDomain.Subscription
+	err := db.Conn(ctx, r.db).SelectContext(ctx, &subscriptions, query, integrationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+	return subscriptions, nil
+}
+
+func (r *deliveryPostgresRepository) CreateSubscription(ctx context.Context, subscription *integrationDomain.Subscription) error {
+	query := `
+		INSERT INTO integration_subscriptions (id, integration_id, event_type, created_at)
+		VALUES (:id, :integration_id, :event_type, :created_at)
+		ON CONFLICT (integration_id, event_type) DO NOTHING
+	`
+
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, subscription)
+	if err != nil {
+		return fmt.Errorf("failed to create subscription: %w", err)
+	}
+	return nil
+}
+
+func (r *deliveryPostgresRepository) DeleteSubscription(ctx context.Context, integrationID, id string) error {
+	query := `DELETE FROM integration_subscriptions WHERE id = $1 AND integration_id = $2`
+
+	result, err := db.Conn(ctx, r.db).ExecContext(ctx, query, id, integrationID)
+	if err != nil {
+		return fmt.Errorf("failed to delete subscription: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return pkgErrors.ErrNotFound
+	}
+	return nil
+}
+
+func (r *deliveryPostgresRepository) ListSubscribers(ctx context.Context, eventType string) ([]*integrationDomain.Integration, error) {
+	query := `
+		SELECT DISTINCT i.id, i.name, i.type, i.description, i.config, i.endpoint, i.status,
+		       i.last_sync_at, i.organization_id, i.created_by, i.created_at, i.updated_at, i.deleted_at
+		FROM integrations i
+		JOIN integration_subscriptions s ON s.integration_id = i.id
+		WHERE i.deleted_at IS NULL AND i.type = $1 AND i.status = $2
+		  AND (s.event_type = $3 OR s.event_type = $4)
+	`
+
+	var integrations []*integrationDomain.Integration
+	err := db.Conn(ctx, r.db).SelectContext(ctx, &integrations, query,
+		integrationDomain.IntegrationTypeWebhook,
+		integrationDomain.IntegrationStatusActive,
+		eventType,
+		integrationDomain.SubscriptionWildcard,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscribers: %w", err)
+	}
+	return integrations, nil
+}
+
+func (r *deliveryPostgresRepository) CreateDelivery(ctx context.Context, delivery *integrationDomain.Delivery) error {
+	query := `
+		INSERT INTO integration_deliveries (id, integration_id, event_id, event_type, channel, payload, status, attempts,
+		                                    next_attempt_at, created_at, updated_at)
+		VALUES (:id, :integration_id, :event_id, :event_type, :channel, :payload, :status, :attempts,
+		        :next_attempt_at, :created_at, :updated_at)
+	`
+
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, delivery)
+	if err != nil {
+		return fmt.Errorf("failed to create delivery: %w", err)
+	}
+	return nil
+}
+
+func (r *deliveryPostgresRepository) GetDelivery(ctx context.Context, id string) (*integrationDomain.Delivery, error) {
+	query := `SELECT ` + deliveryColumns + ` FROM integration_deliveries WHERE id = $1`
+
+	var delivery integrationDomain.Delivery
+	err := db.Conn(ctx, r.db).GetContext(ctx, &delivery, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, pkgErrors.ErrNotFound
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &delivery, nil
+}
+
+func (r *deliveryPostgresRepository) UpdateDelivery(ctx context.Context, delivery *integrationDomain.Delivery) error {
+	query := `
+		UPDATE integration_deliveries
+		SET status = :status, attempts = :attempts, next_attempt_at = :next_attempt_at, last_error = :last_error,
+		    response_status = :response_status, delivered_at = :delivered_at, updated_at = :updated_at
+		WHERE id = :id
+	`
+
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, delivery)
+	if err != nil {
+		return fmt.Errorf("failed to update delivery: %w", err)
+	}
+	return nil
+}
+
+func (r *deliveryPostgresRepository) ListDeliveries(ctx context.Context, integrationID string, status *string, limit, offset int) ([]*integrationDomain.Delivery, int, error) {
+	whereClause := "WHERE integration_id = $1"
+	args := []interface{}{integrationID}
+	argCount := 2
+
+	if status != nil {
+		whereClause += fmt.Sprintf(" AND status = $%d", argCount)
+		args = append(args, status)
+		argCount++
+	}
+
+	countQuery := "SELECT COUNT(*) FROM integration_deliveries " + whereClause
+	var total int
+	err := db.Conn(ctx, r.db).GetContext(ctx, &total, countQuery, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count deliveries: %w", err)
+	}
+
+	query := `SELECT ` + deliveryColumns + ` FROM integration_deliveries ` + whereClause +
+		fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", argCount, argCount+1)
+	args = append(args, limit, offset)
+
+	var deliveries []*integrationDomain.Delivery
+	err = db.Conn(ctx, r.db).SelectContext(ctx, &deliveries, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list deliveries: %w", err)
+	}
+
+	return deliveries, total, nil
+}
+
+func (r *deliveryPostgresRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*integrationDomain.Delivery, error) {
+	now := time.Now()
+	query := `
+		UPDATE integration_deliveries
+		SET next_attempt_at = $1
+		WHERE id IN (
+			SELECT id FROM integration_deliveries
+			WHERE status IN ($2, $3) AND next_attempt_at <= $4
+			ORDER BY next_attempt_at ASC
+			LIMIT $5
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + deliveryColumns
+
+	var deliveries []*integrationDomain.Delivery
+	err := db.Conn(ctx, r.db).SelectContext(ctx, &deliveries, query,
+		now.Add(lease),
+		integrationDomain.DeliveryStatusPending,
+		integrationDomain.DeliveryStatusFailed,
+		now,
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim due deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+func (r *deliveryPostgresRepository) CreateAttempt(ctx context.Context, attempt *integrationDomain.DeliveryAttempt) error {
+	query := `
+		INSERT INTO integration_delivery_attempts (id, delivery_id, attempt, response_status, response_body,
+		                                           error, duration_ms, created_at)
+		VALUES (:id, :delivery_id, :attempt, :response_status, :response_body,
+		        :error, :duration_ms, :created_at)
+	`
+
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, attempt)
+	if err != nil {
+		return fmt.Errorf("failed to create delivery attempt: %w", err)
+	}
+	return nil
+}
+
+func (r *deliveryPostgresRepository) ListAttempts(ctx context.Context, deliveryID string) ([]*integrationDomain.DeliveryAttempt, error) {
+	query := `
+		SELECT id, delivery_id, attempt, response_status, response_body, error, duration_ms, created_at
+		FROM integration_delivery_attempts
+		WHERE delivery_id = $1
+		ORDER BY attempt ASC
+	`
+
+	var attempts []*integrationDomain.DeliveryAttempt
+	err := db.Conn(ctx, r.db).SelectContext(ctx, &attempts, query, deliveryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list delivery attempts: %w", err)
+	}
+	return attempts, nil
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	integrationDomain "portal-data-backend/internal/integration/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"portal-data-backend/infrastructure/db"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type ingestPostgresRepository struct {
+	db *sqlx.DB
+}
+
+func NewIngestPostgresRepository(db *sqlx.DB) integrationDomain.IngestRepository {
+	return &ingestPostgresRepository{db: db}
+}
+
+const ingestTokenColumns = `id, integration_id, name, token_hash, prefix, last_used_at, expires_at, revoked_at,
+		       created_by, created_at`
+
+func (r *ingestPostgresRepository) CreateToken(ctx context.Context, token *integrationDomain.IngestToken) error {
+	query := `
+		INSERT INTO integration_ingest_tokens (id, integration_id, name, token_hash, prefix, expires_at,
+		                                       created_by, created_at)
+		VALUES (:id, :integration_id, :name, :token_hash, :prefix, :expires_at, :created_by, :created_at)
+	`
+
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, token)
+	if err != nil {
+		return fmt.Errorf("failed to create ingest token: %w", err)
+	}
+	return nil
+}
+
+func (r *ingestPostgresRepository) ListTokens(ctx context.Context, integrationID string) ([]*integrationDomain.IngestToken, error) {
+	query := `SELECT ` + ingestTokenColumns + ` FROM integration_ingest_tokens
+		WHERE integration_id = $1 ORDER BY created_at DESC`
+
+	var tokens []*integrationDomain.IngestToken
+	err := db.Conn(ctx, r.db).SelectContext(ctx, &tokens, query, integrationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ingest tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+func (r *ingestPostgresRepository) GetTokenByHash(ctx context.Context, tokenHash string) (*integrationDomain.IngestToken, error) {
+	query := `SELECT ` + ingestTokenColumns + ` FROM integration_ingest_tokens WHERE token_hash = $1`
+
+	var token integrationDomain.IngestToken
+	err := db.Conn(ctx, r.db).GetContext(ctx, &token, query, tokenHash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, pkgErrors.ErrNotFound
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &token, nil
+}
+
+func (r *ingestPostgresRepository) RevokeToken(ctx context.Context, integrationID, id string, at time.Time) error {
+	query := `
+		UPDATE integration_ingest_tokens SET revoked_at = $1
+		WHERE id = $2 AND integration_id = $3 AND revoked_at IS NULL
+	`
+
+	result, err := db.Conn(ctx, r.db).ExecContext(ctx, query, at, id, integrationID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke ingest token: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return pkgErrors.ErrNotFound
+	}
+	return nil
+}
+
+func (r *ingestPostgresRepository) TouchToken(ctx context.Context, id string, at time.Time) error {
+	query := "UPDATE integration_ingest_tokens SET last_used_at = $1 WHERE id = $2"
+
+	_, err := db.Conn(ctx, r.db).ExecContext(ctx, query, at, id)
+	if err != nil {
+		return fmt.Errorf("failed to update ingest token: %w", err)
+	}
+	return nil
+}
+
+func (r *ingestPostgresRepository) CreateIngestion(ctx context.Context, ingestion *integrationDomain.Ingestion) (bool, error) {
+	query := `
+		INSERT INTO integration_ingestions (id, integration_id, idempotency_key, status, report, created_at, completed_at)
+		VALUES (:id, :integration_id, :idempotency_key, :status, :report, :created_at, :completed_at)
+		ON CONFLICT (integration_id, idempotency_key) DO NOTHING
+	`
+
+	result, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, ingestion)
+	if err != nil {
+		return false, fmt.Errorf("failed to create ingestion: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	return rows == 1, nil
+}
+
+func (r *ingestPostgresRepository) GetIngestionByKey(ctx context.Context, integrationID, idempotencyKey string) (*integrationDomain.Ingestion, error) {
+	query := `
+		SELECT id, integration_id, idempotency_key, status, report, created_at, completed_at
+		FROM integration_ingestions
+		WHERE integration_id = $1 AND idempotency_key = $2
+	`
+
+	var ingestion integrationDomain.Ingestion
+	err := db.Conn(ctx, r.db).GetContext(ctx, &ingestion, query, integrationID, idempotencyKey)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, pkgErrors.ErrNotFound
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &ingestion, nil
+}
+
+func (r *ingestPostgresRepository) UpdateIngestion(ctx context.Context, ingestion *integrationDomain.Ingestion) error {
+	query := `
+		UPDATE integration_ingestions
+		SET status = :status, report = :report, completed_at = :completed_at
+		WHERE id = :id
+	`
+
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, ingestion)
+	if err != nil {
+		return fmt.Errorf("failed to update ingestion: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	integrationDomain "portal-data-backend/internal/integration/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"portal-data-backend/infrastructure/db"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type matchPostgresRepository struct {
+	db *sqlx.DB
+}
+
+func NewMatchPostgresRepository(db *sqlx.DB) integrationDomain.MatchRepository {
+	return &matchPostgresRepository{db: db}
+}
+
+const matchColumns = `id, integration_id, remote_id, external_id, dataset_id, reason, score, record, status,
+	resolution, resolved_by, resolved_at, created_at, updated_at`
+
+func (r *matchPostgresRepository) SaveMatch(ctx context.Context, match *integrationDomain.HarvestMatch) error {
+	// A record matched again while its match is pending refreshes that match
+	query := `
+		INSERT INTO integration_harvest_matches (id, integration_id, remote_id, external_id, dataset_id, reason, score,
+		                                         record, status, created_at, updated_at)
+		VALUES (:id, :integration_id, :remote_id, :external_id, :dataset_id, :reason, :score,
+		        :record, :status, :created_at, :updated_at)
+		ON CONFLICT (integration_id, remote_id) WHERE status = 'pending' DO UPDATE
+		SET external_id = EXCLUDED.external_id, dataset_id = EXCLUDED.dataset_id, reason = EXCLUDED.reason,
+		    score = EXCLUDED.score, record = EXCLUDED.record, updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, match)
+	if err != nil {
+		return fmt.Errorf("failed to save harvest match: %w", err)
+	}
+	return nil
+}
+
+func (r *matchPostgresRepository) GetMatch(ctx context.Context, integrationID, id string) (*integrationDomain.HarvestMatch, error) {
+	query := `SELECT ` + matchColumns + ` FROM integration_harvest_matches WHERE integration_id = $1 AND id = $2`
+
+	var match integrationDomain.HarvestMatch
+	err := db.Conn(ctx, r.db).GetContext(ctx, &match, query, integrationID, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, pkgErrors.ErrNotFound
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &match, nil
+}
+
+func (r *matchPostgresRepository) ListMatches(ctx context.Context, integrationID string, status *string, limit, offset int) ([]*integrationDomain.HarvestMatch, int, error) {
+	whereClause := "WHERE integration_id = $1"
+	args := []interface{}{integrationID}
+	argCount := 2
+
+	if status != nil {
+		whereClause += fmt.Sprintf(" AND status = $%d", argCount)
+		args = append(args, *status)
+		argCount++
+	}
+
+	countQuery := "SELECT COUNT(*) FROM integration_harvest_matches " + whereClause
+	var total int
+	err := db.Conn(ctx, r.db).GetContext(ctx, &total, countQuery, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count harvest matches: %w", err)
+	}
+
+	query := `SELECT ` + matchColumns + ` FROM integration_harvest_matches ` + whereClause +
+		fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", argCount, argCount+1)
+	args = append(args, limit, offset)
+
+	var matches []*integrationDomain.HarvestMatch
+	err = db.Conn(ctx, r.db).SelectContext(ctx, &matches, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list harvest matches: %w", err)
+	}
+
+	return matches, total, nil
+}
+
+func (r *matchPostgresRepository) ResolveMatch(ctx context.Context, match *integrationDomain.HarvestMatch) error {
+	query := `
+		UPDATE integration_harvest_matches
+		SET status = :status, resolution = :resolution, resolved_by = :resolved_by, resolved_at = :resolved_at,
+		    updated_at = :updated_at
+		WHERE id = :id AND status = 'pending'
+	`
+
+	result, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, match)
+	if err != nil {
+		return fmt.Errorf("failed to resolve harvest match: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return pkgErrors.ErrNotFound
+	}
+	return nil
+}
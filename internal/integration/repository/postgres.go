@@ -3,40 +3,49 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"time"
 
+	"portal-data-backend/infrastructure/db"
+	"portal-data-backend/infrastructure/security"
 	integrationDomain "portal-data-backend/internal/integration/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
 
 	"github.com/jmoiron/sqlx"
 )
 
 type integrationPostgresRepository struct {
-	db *sqlx.DB
+	db     *sqlx.DB
+	cipher *security.SecretCipher
 }
 
-func NewIntegrationPostgresRepository(db *sqlx.DB) integrationDomain.Repository {
-	return &integrationPostgresRepository{db: db}
+// NewIntegrationPostgresRepository creates a repository that encrypts API keys
+// and secrets with cipher before they are written
+func NewIntegrationPostgresRepository(db *sqlx.DB, cipher *security.SecretCipher) integrationDomain.Repository {
+	return &integrationPostgresRepository{db: db, cipher: cipher}
 }
 
 func (r *integrationPostgresRepository) GetByID(ctx context.Context, id string) (*integrationDomain.Integration, error) {
 	query := `
-		SELECT id, name, type, description, config, endpoint, api_key, status, last_sync_at,
-		       organization_id, created_by, created_at, updated_at, deleted_at
+		SELECT id, name, type, description, config, endpoint, api_key, secrets, status, last_sync_at,
+		       next_run_at, organization_id, created_by, created_at, updated_at, deleted_at
 		FROM integrations
-		WHERE id = $1 AND deleted_at IS NULL
-	`
+		WHERE id = $1 AND ` + db.NotDeleted(ctx, "deleted_at")
 
 	var integration integrationDomain.Integration
-	err := r.db.GetContext(ctx, &integration, query, id)
+	err := db.Conn(ctx, r.db).GetContext(ctx, &integration, query, id)
 	if err != nil {
 		return nil, r.handleError(err)
 	}
+	if err := r.decrypt(&integration); err != nil {
+		return nil, err
+	}
 	return &integration, nil
 }
 
 func (r *integrationPostgresRepository) List(ctx context.Context, filter *integrationDomain.IntegrationFilter, limit, offset int) ([]*integrationDomain.Integration, int, error) {
-	whereClause := "WHERE deleted_at IS NULL"
+	whereClause := "WHERE " + db.NotDeleted(ctx, "deleted_at")
 	args := []interface{}{}
 	argCount := 1
 
@@ -66,37 +75,46 @@ func (r *integrationPostgresRepository) List(ctx context.Context, filter *integr
 
 	countQuery := "SELECT COUNT(*) FROM integrations " + whereClause
 	var total int
-	err := r.db.GetContext(ctx, &total, countQuery, args...)
+	err := db.Conn(ctx, r.db).GetContext(ctx, &total, countQuery, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count integrations: %w", err)
 	}
 
 	query := `
-		SELECT id, name, type, description, config, endpoint, api_key, status, last_sync_at,
-		       organization_id, created_by, created_at, updated_at, deleted_at
+		SELECT id, name, type, description, config, endpoint, api_key, secrets, status, last_sync_at,
+		       next_run_at, organization_id, created_by, created_at, updated_at, deleted_at
 		FROM integrations
 	` + whereClause + " ORDER BY created_at DESC LIMIT $" + fmt.Sprintf("%d", argCount) + " OFFSET $" + fmt.Sprintf("%d", argCount+1)
 
 	args = append(args, limit, offset)
 
 	var integrations []*integrationDomain.Integration
-	err = r.db.SelectContext(ctx, &integrations, query, args...)
+	err = db.Conn(ctx, r.db).SelectContext(ctx, &integrations, query, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to list integrations: %w", err)
 	}
+	for _, integration := range integrations {
+		if err := r.decrypt(integration); err != nil {
+			return nil, 0, err
+		}
+	}
 
 	return integrations, total, nil
 }
 
 func (r *integrationPostgresRepository) Create(ctx context.Context, integration *integrationDomain.Integration) error {
 	query := `
-		INSERT INTO integrations (id, name, type, description, config, endpoint, api_key, status,
+		INSERT INTO integrations (id, name, type, description, config, endpoint, api_key, secrets, status,
 		                        organization_id, created_by, created_at, updated_at)
-		VALUES (:id, :name, :type, :description, :config, :endpoint, :api_key, :status,
+		VALUES (:id, :name, :type, :description, :config, :endpoint, :api_key, :secrets, :status,
 		        :organization_id, :created_by, :created_at, :updated_at)
 	`
 
-	_, err := r.db.NamedExecContext(ctx, query, integration)
+	encrypted, err := r.encrypt(integration)
+	if err != nil {
+		return err
+	}
+	_, err = db.Conn(ctx, r.db).NamedExecContext(ctx, query, encrypted)
 	if err != nil {
 		return fmt.Errorf("failed to create integration: %w", err)
 	}
@@ -107,12 +125,17 @@ func (r *integrationPostgresRepository) Update(ctx context.Context, id string, i
 	query := `
 		UPDATE integrations
 		SET name = :name, description = :description, config = :config, endpoint = :endpoint,
-		    api_key = :api_key, status = :status, updated_at = :updated_at
+		    api_key = :api_key, secrets = :secrets, status = :status, next_run_at = :next_run_at,
+		    updated_at = :updated_at
 		WHERE id = :id
 	`
 
 	integration.ID = id
-	_, err := r.db.NamedExecContext(ctx, query, integration)
+	encrypted, err := r.encrypt(integration)
+	if err != nil {
+		return err
+	}
+	_, err = db.Conn(ctx, r.db).NamedExecContext(ctx, query, encrypted)
 	if err != nil {
 		return fmt.Errorf("failed to update integration: %w", err)
 	}
@@ -120,17 +143,16 @@ func (r *integrationPostgresRepository) Update(ctx context.Context, id string, i
 }
 
 func (r *integrationPostgresRepository) Delete(ctx context.Context, id string) error {
-	query := `UPDATE integrations SET deleted_at = $1 WHERE id = $2`
-	_, err := r.db.ExecContext(ctx, query, time.Now(), id)
-	if err != nil {
-		return fmt.Errorf("failed to delete integration: %w", err)
-	}
-	return nil
+	return db.SoftDelete(ctx, db.Conn(ctx, r.db), "integrations", id)
+}
+
+func (r *integrationPostgresRepository) Restore(ctx context.Context, id string) error {
+	return db.Restore(ctx, db.Conn(ctx, r.db), "integrations", id)
 }
 
 func (r *integrationPostgresRepository) UpdateStatus(ctx context.Context, id string, status string) error {
 	query := `UPDATE integrations SET status = $1, updated_at = $2 WHERE id = $3`
-	_, err := r.db.ExecContext(ctx, query, status, time.Now(), id)
+	_, err := db.Conn(ctx, r.db).ExecContext(ctx, query, status, time.Now(), id)
 	if err != nil {
 		return fmt.Errorf("failed to update integration status: %w", err)
 	}
@@ -141,19 +163,121 @@ func (r *integrationPostgresRepository) Sync(ctx context.Context, id string) err
 	// Update last_sync_at
 	now := time.Now()
 	query := `UPDATE integrations SET last_sync_at = $1, updated_at = $2 WHERE id = $3`
-	_, err := r.db.ExecContext(ctx, query, now, now, id)
+	_, err := db.Conn(ctx, r.db).ExecContext(ctx, query, now, now, id)
 	if err != nil {
 		return fmt.Errorf("failed to sync integration: %w", err)
 	}
 	return nil
 }
 
+func (r *integrationPostgresRepository) ReencryptSecrets(ctx context.Context) (int, error) {
+	var rows []struct {
+		ID      string  `db:"id"`
+		APIKey  *string `db:"api_key"`
+		Secrets *string `db:"secrets"`
+	}
+	err := db.Conn(ctx, r.db).SelectContext(ctx, &rows, `SELECT id, api_key, secrets FROM integrations`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list integration secrets: %w", err)
+	}
+
+	changed := 0
+	for _, row := range rows {
+		apiKey, apiKeyChanged, err := r.reencrypt(row.APIKey)
+		if err != nil {
+			return changed, fmt.Errorf("integration %s: %w", row.ID, err)
+		}
+		secrets, secretsChanged, err := r.reencrypt(row.Secrets)
+		if err != nil {
+			return changed, fmt.Errorf("integration %s: %w", row.ID, err)
+		}
+		if !apiKeyChanged && !secretsChanged {
+			continue
+		}
+
+		query := `UPDATE integrations SET api_key = $1, secrets = $2 WHERE id = $3`
+		if _, err := db.Conn(ctx, r.db).ExecContext(ctx, query, apiKey, secrets, row.ID); err != nil {
+			return changed, fmt.Errorf("failed to reencrypt integration %s: %w", row.ID, err)
+		}
+		changed++
+	}
+	return changed, nil
+}
+
+// reencrypt moves a stored value to the primary key, reporting whether it changed
+func (r *integrationPostgresRepository) reencrypt(value *string) (*string, bool, error) {
+	if value == nil || *value == "" || !r.cipher.NeedsReencryption(*value) {
+		return value, false, nil
+	}
+	plaintext, err := r.cipher.Decrypt(*value)
+	if err != nil {
+		return nil, false, err
+	}
+	ciphertext, err := r.cipher.Encrypt(plaintext)
+	if err != nil {
+		return nil, false, err
+	}
+	return &ciphertext, true, nil
+}
+
+// encrypt returns a copy of integration with its API key and secrets encrypted
+func (r *integrationPostgresRepository) encrypt(integration *integrationDomain.Integration) (*integrationDomain.Integration, error) {
+	encrypted := *integration
+	encrypted.EncryptedSecrets = nil
+
+	if integration.APIKey != nil && *integration.APIKey != "" {
+		apiKey, err := r.cipher.Encrypt(*integration.APIKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt api key: %w", err)
+		}
+		encrypted.APIKey = &apiKey
+	}
+
+	if len(integration.Secrets) > 0 {
+		plaintext, err := json.Marshal(integration.Secrets)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode secrets: %w", err)
+		}
+		secrets, err := r.cipher.Encrypt(string(plaintext))
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt secrets: %w", err)
+		}
+		encrypted.EncryptedSecrets = &secrets
+	}
+
+	return &encrypted, nil
+}
+
+// decrypt replaces the stored ciphertext on integration with plaintext values
+func (r *integrationPostgresRepository) decrypt(integration *integrationDomain.Integration) error {
+	if integration.APIKey != nil {
+		apiKey, err := r.cipher.Decrypt(*integration.APIKey)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt api key of integration %s: %w", integration.ID, err)
+		}
+		integration.APIKey = &apiKey
+	}
+
+	if integration.EncryptedSecrets != nil && *integration.EncryptedSecrets != "" {
+		plaintext, err := r.cipher.Decrypt(*integration.EncryptedSecrets)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt secrets of integration %s: %w", integration.ID, err)
+		}
+		if err := json.Unmarshal([]byte(plaintext), &integration.Secrets); err != nil {
+			return fmt.Errorf("failed to decode secrets of integration %s: %w", integration.ID, err)
+		}
+	}
+	integration.EncryptedSecrets = nil
+
+	return nil
+}
+
 func (r *integrationPostgresRepository) handleError(err error) error {
 	if err == nil {
 		return nil
 	}
 	if err == sql.ErrNoRows {
-		return fmt.Errorf("integration not found")
+		return fmt.Errorf("integration not found: %w", pkgErrors.ErrNotFound)
 	}
 	return fmt.Errorf("database error: %w", err)
 }
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	integrationDomain "portal-data-backend/internal/integration/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"portal-data-backend/infrastructure/db"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type pushPostgresRepository struct {
+	db *sqlx.DB
+}
+
+func NewPushPostgresRepository(db *sqlx.DB) integrationDomain.PushRepository {
+	return &pushPostgresRepository{db: db}
+}
+
+const pushRecordColumns = `integration_id, dataset_id, remote_id, remote_name, status, error, dataset_updated_at, pushed_at`
+
+func (r *pushPostgresRepository) GetPushRecord(ctx context.Context, integrationID, datasetID string) (*integrationDomain.PushRecord, error) {
+	query := `SELECT ` + pushRecordColumns + ` FROM integration_push_records WHERE integration_id = $1 AND dataset_id = $2`
+
+	var record integrationDomain.PushRecord
+	err := db.Conn(ctx, r.db).GetContext(ctx, &record, query, integrationID, datasetID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, pkgErrors.ErrNotFound
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &record, nil
+}
+
+func (r *pushPostgresRepository) SavePushRecord(ctx context.Context, record *integrationDomain.PushRecord) error {
+	query := `
+		INSERT INTO integration_push_records (integration_id, dataset_id, remote_id, remote_name, status, error,
+		                                      dataset_updated_at, pushed_at)
+		VALUES (:integration_id, :dataset_id, :remote_id, :remote_name, :status, :error,
+		        :dataset_updated_at, :pushed_at)
+		ON CONFLICT (integration_id, dataset_id) DO UPDATE
+		SET remote_id = EXCLUDED.remote_id, remote_name = EXCLUDED.remote_name, status = EXCLUDED.status,
+		    error = EXCLUDED.error, dataset_updated_at = EXCLUDED.dataset_updated_at, pushed_at = EXCLUDED.pushed_at
+	`
+
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, record)
+	if err != nil {
+		return fmt.Errorf("failed to save push record: %w", err)
+	}
+	return nil
+}
+
+func (r *pushPostgresRepository) ListPushRecords(ctx context.Context, integrationID string, status *string, limit, offset int) ([]*integrationDomain.PushRecord, int, error) {
+	whereClause := "WHERE integration_id = $1"
+	args := []interface{}{integrationID}
+	argCount := 2
+
+	if status != nil {
+		whereClause += fmt.Sprintf(" AND status = $%d", argCount)
+		args = append(args, *status)
+		argCount++
+	}
+
+	countQuery := "SELECT COUNT(*) FROM integration_push_records " + whereClause
+	var total int
+	err := db.Conn(ctx, r.db).GetContext(ctx, &total, countQuery, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count push records: %w", err)
+	}
+
+	query := `SELECT ` + pushRecordColumns + ` FROM integration_push_records ` + whereClause +
+		fmt.Sprintf(" ORDER BY pushed_at DESC LIMIT $%d OFFSET $%d", argCount, argCount+1)
+	args = append(args, limit, offset)
+
+	var records []*integrationDomain.PushRecord
+	err = db.Conn(ctx, r.db).SelectContext(ctx, &records, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list push records: %w", err)
+	}
+
+	return records, total, nil
+}
@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	integrationDomain "portal-data-backend/internal/integration/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"portal-data-backend/infrastructure/db"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type runPostgresRepository struct {
+	db *sqlx.DB
+}
+
+func NewRunPostgresRepository(db *sqlx.DB) integrationDomain.RunRepository {
+	return &runPostgresRepository{db: db}
+}
+
+func (r *runPostgresRepository) CreateRun(ctx context.Context, run *integrationDomain.Run) error {
+	query := `
+		INSERT INTO integration_runs (id, integration_id, trigger, status, records_fetched, records_created,
+		                              records_updated, records_failed, records_queued, errors, started_at, finished_at)
+		VALUES (:id, :integration_id, :trigger, :status, :records_fetched, :records_created,
+		        :records_updated, :records_failed, :records_queued, :errors, :started_at, :finished_at)
+	`
+
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, run)
+	if err != nil {
+		return fmt.Errorf("failed to create run: %w", err)
+	}
+	return nil
+}
+
+func (r *runPostgresRepository) UpdateRun(ctx context.Context, run *integrationDomain.Run) error {
+	query := `
+		UPDATE integration_runs
+		SET status = :status, records_fetched = :records_fetched, records_created = :records_created,
+		    records_updated = :records_updated, records_failed = :records_failed, records_queued = :records_queued,
+		    errors = :errors,
+		    finished_at = :finished_at
+		WHERE id = :id
+	`
+
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, run)
+	if err != nil {
+		return fmt.Errorf("failed to update run: %w", err)
+	}
+	return nil
+}
+
+func (r *runPostgresRepository) ListRuns(ctx context.Context, integrationID string, limit, offset int) ([]*integrationDomain.Run, int, error) {
+	countQuery := "SELECT COUNT(*) FROM integration_runs WHERE integration_id = $1"
+	var total int
+	err := db.Conn(ctx, r.db).GetContext(ctx, &total, countQuery, integrationID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count runs: %w", err)
+	}
+
+	query := `
+		SELECT id, integration_id, trigger, status, records_fetched, records_created, records_updated,
+		       records_failed, records_queued, errors, started_at, finished_at
+		FROM integration_runs
+		WHERE integration_id = $1
+		ORDER BY started_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	var runs []*integrationDomain.Run
+	err = db.Conn(ctx, r.db).SelectContext(ctx, &runs, query, integrationID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list runs: %w", err)
+	}
+
+	return runs, total, nil
+}
+
+func (r *runPostgresRepository) ListLatestRuns(ctx context.Context, perIntegration int) ([]*integrationDomain.Run, error) {
+	query := `
+		SELECT id, integration_id, trigger, status, records_fetched, records_created, records_updated,
+		       records_failed, records_queued, errors, started_at, finished_at
+		FROM (
+			SELECT *, ROW_NUMBER() OVER (PARTITION BY integration_id ORDER BY started_at DESC) AS position
+			FROM integration_runs
+		) latest
+		WHERE position <= $1
+		ORDER BY integration_id, started_at DESC
+	`
+
+	var runs []*integrationDomain.Run
+	err := db.Conn(ctx, r.db).SelectContext(ctx, &runs, query, perIntegration)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list latest runs: %w", err)
+	}
+	return runs, nil
+}
+
+func (r *runPostgresRepository) ClaimNextRun(ctx context.Context, integrationID string, nextRunAt *time.Time, next time.Time) (bool, error) {
+	query := `
+		UPDATE integrations
+		SET next_run_at = $1
+		WHERE id = $2 AND next_run_at IS NOT DISTINCT FROM $3
+	`
+
+	result, err := db.Conn(ctx, r.db).ExecContext(ctx, query, next, integrationID, nextRunAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim schedule: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	return rows == 1, nil
+}
+
+func (r *runPostgresRepository) AcquireRunLock(ctx context.Context, integrationID string, now, staleBefore time.Time) (bool, error) {
+	// A lock older than staleBefore was left by a crashed run and is taken over
+	query := `
+		UPDATE integrations
+		SET run_started_at = $1
+		WHERE id = $2 AND (run_started_at IS NULL OR run_started_at < $3)
+	`
+
+	result, err := db.Conn(ctx, r.db).ExecContext(ctx, query, now, integrationID, staleBefore)
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire run lock: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	return rows == 1, nil
+}
+
+func (r *runPostgresRepository) ReleaseRunLock(ctx context.Context, integrationID string) error {
+	query := "UPDATE integrations SET run_started_at = NULL WHERE id = $1"
+
+	_, err := db.Conn(ctx, r.db).ExecContext(ctx, query, integrationID)
+	if err != nil {
+		return fmt.Errorf("failed to release run lock: %w", err)
+	}
+	return nil
+}
+
+func (r *runPostgresRepository) GetHarvestRecord(ctx context.Context, integrationID, remoteID string) (*integrationDomain.HarvestRecord, error) {
+	query := `
+		SELECT integration_id, remote_id, external_id, dataset_id, policy, harvested_at
+		FROM integration_harvest_records
+		WHERE integration_id = $1 AND remote_id = $2
+	`
+
+	var record integrationDomain.HarvestRecord
+	err := db.Conn(ctx, r.db).GetContext(ctx, &record, query, integrationID, remoteID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, pkgErrors.ErrNotFound
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &record, nil
+}
+
+func (r *runPostgresRepository) FindHarvestRecord(ctx context.Context, externalID, integrationID string) (*integrationDomain.HarvestRecord, error) {
+	query := `
+		SELECT integration_id, remote_id, external_id, dataset_id, policy, harvested_at
+		FROM integration_harvest_records
+		WHERE external_id = $1 AND integration_id <> $2
+		ORDER BY harvested_at DESC
+		LIMIT 1
+	`
+
+	var record integrationDomain.HarvestRecord
+	err := db.Conn(ctx, r.db).GetContext(ctx, &record, query, externalID, integrationID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, pkgErrors.ErrNotFound
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &record, nil
+}
+
+func (r *runPostgresRepository) SaveHarvestRecord(ctx context.Context, record *integrationDomain.HarvestRecord) error {
+	query := `
+		INSERT INTO integration_harvest_records (integration_id, remote_id, external_id, dataset_id, policy, harvested_at)
+		VALUES (:integration_id, :remote_id, :external_id, :dataset_id, :policy, :harvested_at)
+		ON CONFLICT (integration_id, remote_id) DO UPDATE
+		SET external_id = EXCLUDED.external_id, dataset_id = EXCLUDED.dataset_id, policy = EXCLUDED.policy,
+		    harvested_at = EXCLUDED.harvested_at
+	`
+
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, record)
+	if err != nil {
+		return fmt.Errorf("failed to save harvest record: %w", err)
+	}
+	return nil
+}
+
+func (r *runPostgresRepository) ListSyncedObjects(ctx context.Context, integrationID string) ([]*integrationDomain.SyncedObject, error) {
+	query := `
+		SELECT integration_id, object_key, etag, size, file_id, synced_at
+		FROM integration_synced_objects
+		WHERE integration_id = $1
+	`
+
+	var objects []*integrationDomain.SyncedObject
+	err := db.Conn(ctx, r.db).SelectContext(ctx, &objects, query, integrationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list synced objects: %w", err)
+	}
+	return objects, nil
+}
+
+func (r *runPostgresRepository) SaveSyncedObject(ctx context.Context, object *integrationDomain.SyncedObject) error {
+	query := `
+		INSERT INTO integration_synced_objects (integration_id, object_key, etag, size, file_id, synced_at)
+		VALUES (:integration_id, :object_key, :etag, :size, :file_id, :synced_at)
+		ON CONFLICT (integration_id, object_key) DO UPDATE
+		SET etag = EXCLUDED.etag, size = EXCLUDED.size, file_id = EXCLUDED.file_id, synced_at = EXCLUDED.synced_at
+	`
+
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, object)
+	if err != nil {
+		return fmt.Errorf("failed to save synced object: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,300 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	datasetDomain "portal-data-backend/internal/dataset/domain"
+	"portal-data-backend/internal/integration/connector"
+	"portal-data-backend/internal/integration/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// harvestOutcome is what writing a harvested record did
+type harvestOutcome int
+
+const (
+	outcomeCreated   harvestOutcome = iota + 1
+	outcomeUpdated                  // the dataset was updated or merged into
+	outcomeQueued                   // the match waits for review
+	outcomeUnchanged                // the record is linked to a dataset it does not change
+)
+
+// findMatch returns the existing dataset a new record duplicates, or nil.
+// The dataset another integration harvested with the same external ID
+// matches for sure; otherwise the dataset with the most similar name matches
+// from the review threshold on.
+func (u *harvestUsecase) findMatch(ctx context.Context, integration *domain.Integration, dedup *domain.DedupConfig, externalID, name string) (*domain.HarvestMatch, error) {
+	if externalID != "" {
+		harvested, err := u.runRepo.FindHarvestRecord(ctx, externalID, integration.ID)
+		if err != nil && !errors.Is(err, pkgErrors.ErrNotFound) {
+			return nil, err
+		}
+		if harvested != nil {
+			_, err := u.datasets.GetByID(ctx, harvested.DatasetID)
+			if err == nil {
+				return &domain.HarvestMatch{DatasetID: harvested.DatasetID, Reason: string(domain.MatchReasonExternalID), Score: 1}, nil
+			}
+			if !errors.Is(err, pkgErrors.ErrNotFound) {
+				return nil, err
+			}
+			// The dataset was removed; look for another one by name
+		}
+	}
+
+	var orgID string
+	if !dedup.AnyOrganization && integration.OrganizationID != nil {
+		orgID = *integration.OrganizationID
+	}
+	similar, err := u.datasets.FindSimilar(ctx, name, orgID, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(similar) == 0 || similar[0].Similarity < dedup.ReviewThreshold {
+		return nil, nil
+	}
+	return &domain.HarvestMatch{DatasetID: similar[0].ID, Reason: string(domain.MatchReasonTitle), Score: similar[0].Similarity}, nil
+}
+
+// queueMatch queues match of a record for review, replacing the match the
+// record waits on from an earlier run
+func (u *harvestUsecase) queueMatch(ctx context.Context, integration *domain.Integration, remoteID, externalID string, match *domain.HarvestMatch, record connector.Record) error {
+	encoded, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode record: %w", err)
+	}
+
+	now := u.now()
+	match.ID = uuid.New().String()
+	match.IntegrationID = integration.ID
+	match.RemoteID = remoteID
+	match.Record = string(encoded)
+	match.Status = string(domain.MatchStatusPending)
+	match.CreatedAt = now
+	match.UpdatedAt = now
+	if externalID != "" {
+		match.ExternalID = &externalID
+	}
+	return u.matches.SaveMatch(ctx, match)
+}
+
+func (u *harvestUsecase) ListMatches(ctx context.Context, integrationID string, req *domain.ListMatchesRequest) (*domain.HarvestMatchListResponse, error) {
+	if _, err := u.repo.GetByID(ctx, integrationID); err != nil {
+		return nil, fmt.Errorf("failed to get integration: %w", err)
+	}
+
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.Limit < 1 {
+		req.Limit = 20
+	}
+
+	offset := (req.Page - 1) * req.Limit
+
+	matches, total, err := u.matches.ListMatches(ctx, integrationID, req.Status, req.Limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list harvest matches: %w", err)
+	}
+
+	infos := make([]domain.HarvestMatchInfo, len(matches))
+	for i, match := range matches {
+		infos[i] = *toHarvestMatchInfo(match)
+	}
+
+	totalPage := int(math.Ceil(float64(total) / float64(req.Limit)))
+
+	return &domain.HarvestMatchListResponse{
+		Matches: infos,
+		Meta: domain.ListMeta{
+			Page:      req.Page,
+			Limit:     req.Limit,
+			Total:     total,
+			TotalPage: totalPage,
+		},
+	}, nil
+}
+
+// ResolveMatch writes the record of a pending match with the policy of the
+// reviewer, which later runs keep writing it with
+func (u *harvestUsecase) ResolveMatch(ctx context.Context, integrationID, matchID string, req *domain.ResolveMatchRequest, userID string) (*domain.HarvestMatchInfo, error) {
+	integration, err := u.repo.GetByID(ctx, integrationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get integration: %w", err)
+	}
+	cfg, err := connector.ParseConfig(integration)
+	if err != nil {
+		return nil, err
+	}
+
+	match, err := u.matches.GetMatch(ctx, integrationID, matchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get harvest match: %w", err)
+	}
+	if match.Status != string(domain.MatchStatusPending) {
+		return nil, fmt.Errorf("%w: the match is already resolved", pkgErrors.ErrInvalidInput)
+	}
+
+	var record connector.Record
+	if err := json.Unmarshal([]byte(match.Record), &record); err != nil {
+		return nil, fmt.Errorf("stored record of match %s is unreadable: %w", match.ID, err)
+	}
+	fields, err := u.datasetFields(ctx, cfg, record)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", pkgErrors.ErrInvalidInput, err)
+	}
+	var externalID string
+	if match.ExternalID != nil {
+		externalID = *match.ExternalID
+	}
+
+	err = u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := u.resolve(ctx, integration, cfg, match.RemoteID, externalID, req.Policy, match.DatasetID, fields, record); err != nil {
+			return err
+		}
+
+		now := u.now()
+		resolution := string(req.Policy)
+		match.Status = string(domain.MatchStatusResolved)
+		match.Resolution = &resolution
+		match.ResolvedBy = &userID
+		match.ResolvedAt = &now
+		match.UpdatedAt = now
+		return u.matches.ResolveMatch(ctx, match)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve harvest match: %w", err)
+	}
+	return toHarvestMatchInfo(match), nil
+}
+
+func createDatasetRequest(fields map[string]string) *datasetDomain.CreateDatasetRequest {
+	start, end, frequency := harvestedCoverage(fields)
+	return &datasetDomain.CreateDatasetRequest{
+		Name:            fields["name"],
+		Description:     fields["description"],
+		CoverageStart:   start,
+		CoverageEnd:     end,
+		Frequency:       frequency,
+		UnitID:          fields["unit_id"],
+		BusinessFieldID: fields["business_field_id"],
+		Image:           fields["image"],
+		TopicID:         fields["topic_id"],
+		ReferenceID:     fields["reference_id"],
+		SourceURL:       fields["source_url"],
+		Classification:  fields["classification"],
+		Category:        fields["category"],
+		Metadata:        fields["metadata"],
+	}
+}
+
+func updateDatasetRequest(fields map[string]string) *datasetDomain.UpdateDatasetRequest {
+	start, end, frequency := harvestedCoverage(fields)
+	return &datasetDomain.UpdateDatasetRequest{
+		Name:            fields["name"],
+		Description:     fields["description"],
+		CoverageStart:   start,
+		CoverageEnd:     end,
+		Frequency:       frequency,
+		UnitID:          fields["unit_id"],
+		BusinessFieldID: fields["business_field_id"],
+		Image:           fields["image"],
+		TopicID:         fields["topic_id"],
+		ReferenceID:     fields["reference_id"],
+		SourceURL:       fields["source_url"],
+		Classification:  fields["classification"],
+		Category:        fields["category"],
+		Metadata:        fields["metadata"],
+	}
+}
+
+// harvestedCoverage returns the coverage dates and frequency of harvested
+// fields. Without mapped coverage dates they are read from the period, like
+// "2020 - 2023", and without a frequency from the period too, like
+// "tahunan". Values the dataset would refuse are left out.
+func harvestedCoverage(fields map[string]string) (start, end, frequency string) {
+	date := func(value string) string {
+		if _, err := time.Parse(time.DateOnly, value); err != nil {
+			return ""
+		}
+		return value
+	}
+	start, end = date(fields["coverage_start"]), date(fields["coverage_end"])
+	if start != "" && end != "" && end < start {
+		start, end = "", ""
+	}
+	if start == "" && end == "" {
+		if first, last, ok := datasetDomain.ParsePeriod(fields["period"]); ok {
+			start, end = first.Format(time.DateOnly), last.Format(time.DateOnly)
+		}
+	}
+
+	if parsed, ok := datasetDomain.ParseFrequency(fields["frequency"]); ok {
+		frequency = string(parsed)
+	} else if parsed, ok := datasetDomain.ParseFrequency(fields["period"]); ok {
+		frequency = string(parsed)
+	}
+	return start, end, frequency
+}
+
+// mergeDatasetRequest keeps everything set on existing and fills its empty
+// fields from the harvested fields
+func mergeDatasetRequest(existing *datasetDomain.DatasetResponse, fields map[string]string) *datasetDomain.UpdateDatasetRequest {
+	req := &datasetDomain.UpdateDatasetRequest{
+		Name:           existing.Name,
+		Classification: existing.Classification,
+		Category:       existing.Category,
+		DataFixed:      existing.DataFixed,
+		IsHighlight:    existing.IsHighlight,
+		Names:          existing.Names,
+		Descriptions:   existing.Descriptions,
+	}
+	fill := func(value *string, field string) string {
+		if value != nil && *value != "" {
+			return *value
+		}
+		return fields[field]
+	}
+	req.Description = fill(existing.Description, "description")
+	req.CoverageStart, req.CoverageEnd, req.Frequency = harvestedCoverage(fields)
+	if existing.CoverageStart != nil || existing.CoverageEnd != nil {
+		req.CoverageStart, req.CoverageEnd = "", ""
+		if existing.CoverageStart != nil {
+			req.CoverageStart = *existing.CoverageStart
+		}
+		if existing.CoverageEnd != nil {
+			req.CoverageEnd = *existing.CoverageEnd
+		}
+	}
+	if existing.Frequency != nil {
+		req.Frequency = string(*existing.Frequency)
+	}
+	req.Image = fill(existing.Image, "image")
+	req.ReferenceID = fill(existing.ReferenceID, "reference_id")
+	req.SourceURL = fill(existing.SourceURL, "source_url")
+	req.Metadata = fill(existing.Metadata, "metadata")
+
+	req.UnitID, req.BusinessFieldID, req.TopicID = fields["unit_id"], fields["business_field_id"], fields["topic_id"]
+	if existing.Unit != nil {
+		req.UnitID = existing.Unit.ID
+	}
+	if existing.BusinessField != nil {
+		req.BusinessFieldID = existing.BusinessField.ID
+	}
+	if existing.Topic != nil {
+		req.TopicID = existing.Topic.ID
+	}
+	return req
+}
+
+func toHarvestMatchInfo(match *domain.HarvestMatch) *domain.HarvestMatchInfo {
+	info := &domain.HarvestMatchInfo{HarvestMatch: *match, Record: map[string]interface{}{}}
+	_ = json.Unmarshal([]byte(match.Record), &info.Record)
+	return info
+}
@@ -0,0 +1,229 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"portal-data-backend/internal/integration/connector"
+	"portal-data-backend/internal/integration/domain"
+	"portal-data-backend/internal/integration/notifier"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// connectionTestTimeout bounds a whole connection test
+	connectionTestTimeout = 15 * time.Second
+	// connectionTestSampleSize is how many records a connector test fetches
+	connectionTestSampleSize = 5
+	// EventPing is sent to webhook endpoints by a connection test
+	EventPing = "ping"
+)
+
+// connectionTest collects checks for a single dry-run
+type connectionTest struct {
+	result *domain.ConnectionTestResult
+}
+
+func (t *connectionTest) record(name, status, message string, started time.Time) {
+	t.result.Checks = append(t.result.Checks, domain.ConnectionCheck{
+		Name:       name,
+		Status:     status,
+		Message:    message,
+		DurationMs: time.Since(started).Milliseconds(),
+	})
+}
+
+func (u *integrationUsecase) TestConnection(ctx context.Context, id string) (*domain.ConnectionTestResult, error) {
+	integration, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get integration: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, connectionTestTimeout)
+	defer cancel()
+
+	started := time.Now()
+	test := &connectionTest{result: &domain.ConnectionTestResult{Checks: []domain.ConnectionCheck{}}}
+
+	switch integration.Type {
+	case string(domain.IntegrationTypeConnector):
+		u.testConnector(ctx, integration, test)
+	case string(domain.IntegrationTypeWebhook):
+		u.testWebhook(ctx, integration, test)
+	case string(domain.IntegrationTypeSlack), string(domain.IntegrationTypeTeams):
+		u.testNotifier(ctx, integration, test)
+	default:
+		u.testEndpoint(ctx, integration, test)
+	}
+
+	test.result.Success = true
+	for _, check := range test.result.Checks {
+		if check.Status == domain.CheckFailed {
+			test.result.Success = false
+		}
+	}
+	test.result.DurationMs = time.Since(started).Milliseconds()
+
+	return test.result, nil
+}
+
+// testConnector validates the config and fetches a small sample of records
+func (u *integrationUsecase) testConnector(ctx context.Context, integration *domain.Integration, test *connectionTest) {
+	started := time.Now()
+	cfg, err := connector.ParseConfig(integration)
+	if err != nil {
+		test.record("config", domain.CheckFailed, err.Error(), started)
+		return
+	}
+	source, err := connector.New(integration, cfg, u.client)
+	if err != nil {
+		test.record("config", domain.CheckFailed, err.Error(), started)
+		return
+	}
+	test.record("config", domain.CheckPassed, "", started)
+
+	started = time.Now()
+	records, err := source.Fetch(ctx, connectionTestSampleSize)
+	if err != nil {
+		var statusErr *connector.StatusError
+		if errors.As(err, &statusErr) && isAuthStatus(statusErr.StatusCode) {
+			test.record("auth", domain.CheckFailed, err.Error(), started)
+			test.record("fetch", domain.CheckSkipped, "", started)
+			return
+		}
+		test.record("auth", domain.CheckSkipped, "", started)
+		test.record("fetch", domain.CheckFailed, err.Error(), started)
+		return
+	}
+	test.record("auth", domain.CheckPassed, "", started)
+	test.record("fetch", domain.CheckPassed, fmt.Sprintf("fetched %d sample records", len(records)), started)
+
+	for _, record := range records {
+		test.result.Sample = append(test.result.Sample, record)
+	}
+}
+
+// testWebhook sends a signed ping event to the webhook endpoint
+func (u *integrationUsecase) testWebhook(ctx context.Context, integration *domain.Integration, test *connectionTest) {
+	started := time.Now()
+	if integration.Endpoint == nil || *integration.Endpoint == "" {
+		test.record("config", domain.CheckFailed, "webhook endpoint is not configured", started)
+		return
+	}
+	if integration.APIKey == nil || *integration.APIKey == "" {
+		test.record("config", domain.CheckPassed, "no signing secret configured, ping will be unsigned", started)
+	} else {
+		test.record("config", domain.CheckPassed, "", started)
+	}
+
+	started = time.Now()
+	payload, _ := json.Marshal(domain.Event{
+		ID:         uuid.New().String(),
+		Type:       EventPing,
+		OccurredAt: started,
+		Data:       map[string]string{"integration_id": integration.ID},
+	})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, *integration.Endpoint, bytes.NewReader(payload))
+	if err != nil {
+		test.record("delivery", domain.CheckFailed, err.Error(), started)
+		return
+	}
+	timestamp := strconv.FormatInt(started.Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, EventPing)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	if integration.APIKey != nil && *integration.APIKey != "" {
+		req.Header.Set(HeaderSignature, Sign(*integration.APIKey, timestamp, payload))
+	}
+
+	u.recordResponse(req, "delivery", test, started)
+}
+
+// testNotifier posts a ping message to the webhook of every channel
+func (u *integrationUsecase) testNotifier(ctx context.Context, integration *domain.Integration, test *connectionTest) {
+	started := time.Now()
+	cfg, err := notifier.ParseConfig(integration)
+	if err != nil {
+		test.record("config", domain.CheckFailed, err.Error(), started)
+		return
+	}
+	message, err := notifier.Format(integration.Type, &domain.Event{
+		ID:         uuid.New().String(),
+		Type:       EventPing,
+		OccurredAt: started,
+		Data:       map[string]string{"name": "Connection test of " + integration.Name},
+	})
+	if err != nil {
+		test.record("config", domain.CheckFailed, err.Error(), started)
+		return
+	}
+	test.record("config", domain.CheckPassed, "", started)
+
+	for _, channel := range cfg.Channels {
+		started = time.Now()
+		endpoint, _ := notifier.WebhookURL(integration, channel.Name)
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(message))
+		if err != nil {
+			test.record("channel "+channel.Name, domain.CheckFailed, err.Error(), started)
+			continue
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		u.recordResponse(req, "channel "+channel.Name, test, started)
+	}
+}
+
+// testEndpoint checks that a generic endpoint is reachable and accepts the API key
+func (u *integrationUsecase) testEndpoint(ctx context.Context, integration *domain.Integration, test *connectionTest) {
+	started := time.Now()
+	if integration.Endpoint == nil || *integration.Endpoint == "" {
+		test.record("config", domain.CheckSkipped, "no endpoint configured", started)
+		return
+	}
+	test.record("config", domain.CheckPassed, "", started)
+
+	started = time.Now()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, *integration.Endpoint, nil)
+	if err != nil {
+		test.record("auth", domain.CheckFailed, err.Error(), started)
+		return
+	}
+	if integration.APIKey != nil && *integration.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+*integration.APIKey)
+	}
+
+	u.recordResponse(req, "auth", test, started)
+}
+
+// recordResponse performs req and records the check from its response status
+func (u *integrationUsecase) recordResponse(req *http.Request, name string, test *connectionTest, started time.Time) {
+	resp, err := u.client.Do(req)
+	if err != nil {
+		test.record(name, domain.CheckFailed, err.Error(), started)
+		return
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxLoggedBody))
+
+	message := fmt.Sprintf("endpoint responded with %d", resp.StatusCode)
+	switch {
+	case isAuthStatus(resp.StatusCode):
+		test.record(name, domain.CheckFailed, message+", credentials were rejected", started)
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		test.record(name, domain.CheckPassed, message, started)
+	default:
+		test.record(name, domain.CheckFailed, message, started)
+	}
+}
+
+func isAuthStatus(status int) bool {
+	return status == http.StatusUnauthorized || status == http.StatusForbidden
+}
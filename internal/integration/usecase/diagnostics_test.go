@@ -0,0 +1,118 @@
+package usecase_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"portal-data-backend/internal/integration/domain"
+	"portal-data-backend/internal/integration/usecase"
+)
+
+func newDiagnosticsFixture(integration *domain.Integration) usecase.Usecase {
+	repo := &mockIntegrationRepository{integrations: map[string]*domain.Integration{integration.ID: integration}}
+	return usecase.NewIntegrationUsecase(repo)
+}
+
+func findCheck(result *domain.ConnectionTestResult, name string) *domain.ConnectionCheck {
+	for i := range result.Checks {
+		if result.Checks[i].Name == name {
+			return &result.Checks[i]
+		}
+	}
+	return nil
+}
+
+// Test a rejected API key is reported as a failed auth check
+func TestTestConnection_ConnectorAuthFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	apiKey := "wrong-key"
+	integrations := newDiagnosticsFixture(&domain.Integration{
+		ID:     "connector-1",
+		Type:   string(domain.IntegrationTypeConnector),
+		Config: `{"connector": "rest", "url": "` + server.URL + `", "target": "datasets"}`,
+		APIKey: &apiKey,
+	})
+
+	result, err := integrations.TestConnection(context.Background(), "connector-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Success {
+		t.Error("Expected test to fail")
+	}
+	if check := findCheck(result, "config"); check == nil || check.Status != domain.CheckPassed {
+		t.Errorf("Expected config check to pass, got %+v", check)
+	}
+	if check := findCheck(result, "auth"); check == nil || check.Status != domain.CheckFailed {
+		t.Errorf("Expected auth check to fail, got %+v", check)
+	}
+}
+
+// Test a connector dry-run returns a sample of records
+func TestTestConnection_ConnectorSample(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id": 1}, {"id": 2}, {"id": 3}, {"id": 4}, {"id": 5}, {"id": 6}]`))
+	}))
+	defer server.Close()
+
+	integrations := newDiagnosticsFixture(&domain.Integration{
+		ID:     "connector-1",
+		Type:   string(domain.IntegrationTypeConnector),
+		Config: `{"connector": "rest", "url": "` + server.URL + `", "target": "datasets"}`,
+	})
+
+	result, err := integrations.TestConnection(context.Background(), "connector-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !result.Success {
+		t.Errorf("Expected test to succeed, got %+v", result.Checks)
+	}
+	if len(result.Sample) != 5 {
+		t.Errorf("Expected 5 sample records, got %d", len(result.Sample))
+	}
+}
+
+// Test a webhook dry-run sends a signed ping
+func TestTestConnection_WebhookPing(t *testing.T) {
+	var gotEvent, gotSignature, gotTimestamp string
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotEvent = r.Header.Get(usecase.HeaderEvent)
+		gotSignature = r.Header.Get(usecase.HeaderSignature)
+		gotTimestamp = r.Header.Get(usecase.HeaderTimestamp)
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	endpoint := server.URL
+	secret := "webhook-secret"
+	integrations := newDiagnosticsFixture(&domain.Integration{
+		ID:       "webhook-1",
+		Type:     string(domain.IntegrationTypeWebhook),
+		Endpoint: &endpoint,
+		APIKey:   &secret,
+	})
+
+	result, err := integrations.TestConnection(context.Background(), "webhook-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !result.Success {
+		t.Errorf("Expected test to succeed, got %+v", result.Checks)
+	}
+	if gotEvent != usecase.EventPing {
+		t.Errorf("Expected ping event, got %q", gotEvent)
+	}
+	if want := usecase.Sign(secret, gotTimestamp, gotBody); gotSignature != want {
+		t.Errorf("Expected signature %s, got %s", want, gotSignature)
+	}
+}
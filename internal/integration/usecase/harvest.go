@@ -0,0 +1,825 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/infrastructure/db"
+	dataRowDomain "portal-data-backend/internal/data_row/domain"
+	datasetDomain "portal-data-backend/internal/dataset/domain"
+	fileDomain "portal-data-backend/internal/file/domain"
+	"portal-data-backend/internal/integration/connector"
+	"portal-data-backend/internal/integration/domain"
+	topicDomain "portal-data-backend/internal/topic/domain"
+	unitDomain "portal-data-backend/internal/unit/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// maxRunErrors bounds how many per-record errors are kept on a run
+	maxRunErrors = 100
+	// maxSyncObjects bounds how many new or changed bucket objects one run
+	// imports; the rest are imported by the following runs
+	maxSyncObjects = 100
+)
+
+// DatasetWriter is the part of the dataset module harvests write through
+type DatasetWriter interface {
+	GetByID(ctx context.Context, id string) (*datasetDomain.DatasetResponse, error)
+	FindSimilar(ctx context.Context, name, orgID string, limit int) ([]datasetDomain.DatasetSuggestion, error)
+	Create(ctx context.Context, req *datasetDomain.CreateDatasetRequest, creatorID, orgID string) (*datasetDomain.DatasetResponse, error)
+	Update(ctx context.Context, id string, req *datasetDomain.UpdateDatasetRequest, updaterID string) (*datasetDomain.DatasetResponse, error)
+}
+
+// DataRowWriter is the part of the data row module harvests write through
+type DataRowWriter interface {
+	List(ctx context.Context, req *dataRowDomain.ListDataRowsRequest) (*dataRowDomain.DataRowListResponse, error)
+	Create(ctx context.Context, req *dataRowDomain.CreateDataRowRequest, userID string) (*dataRowDomain.DataRowInfo, error)
+	Update(ctx context.Context, id string, req *dataRowDomain.UpdateDataRowRequest) (*dataRowDomain.DataRowInfo, error)
+	DeleteByDatasetID(ctx context.Context, datasetID string) error
+	BulkCreate(ctx context.Context, req *dataRowDomain.BulkCreateDataRowsRequest, userID string) error
+}
+
+// FileUploader is the part of the file module bucket syncs import files through
+type FileUploader interface {
+	Upload(ctx context.Context, fileName string, fileSize int64, mimeType string, reader io.Reader, datasetID *string, userID string) (*fileDomain.UploadResponse, error)
+}
+
+// TopicStore is the part of the topic module harvests resolve topic names through
+type TopicStore interface {
+	List(ctx context.Context, req *topicDomain.ListTopicsRequest) (*topicDomain.TopicListResponse, error)
+	Create(ctx context.Context, req *topicDomain.CreateTopicRequest) (*topicDomain.TopicResponse, error)
+}
+
+// UnitStore is the part of the unit module harvests resolve unit names through
+type UnitStore interface {
+	List(ctx context.Context, req *unitDomain.ListUnitsRequest) (*unitDomain.UnitListResponse, error)
+	Create(ctx context.Context, req *unitDomain.CreateUnitRequest) (*unitDomain.UnitResponse, error)
+}
+
+// HarvestUsecase pulls records from connector integrations into datasets and
+// data rows, and files from bucket and SFTP connectors into the file module, and keeps
+// a run history. Scheduled and manual runs are queued by
+// the scheduler. Records matching existing datasets wait in a review queue
+// unless the dedup policy resolves them.
+type HarvestUsecase interface {
+	Harvest(ctx context.Context, integrationID string, trigger domain.RunTrigger) (*domain.RunInfo, error)
+	ListRuns(ctx context.Context, integrationID string, req *domain.ListRunsRequest) (*domain.RunListResponse, error)
+
+	ListMatches(ctx context.Context, integrationID string, req *domain.ListMatchesRequest) (*domain.HarvestMatchListResponse, error)
+	ResolveMatch(ctx context.Context, integrationID, matchID string, req *domain.ResolveMatchRequest, userID string) (*domain.HarvestMatchInfo, error)
+}
+
+type harvestUsecase struct {
+	repo     domain.Repository
+	runRepo  domain.RunRepository
+	matches  domain.MatchRepository
+	tx       db.Transactor
+	datasets DatasetWriter
+	rows     DataRowWriter
+	files    FileUploader
+	topics   TopicStore
+	units    UnitStore
+	observer RunObserver
+	client   *http.Client
+	cfg      config.HarvestConfig
+	now      func() time.Time
+}
+
+func NewHarvestUsecase(repo domain.Repository, runRepo domain.RunRepository, matches domain.MatchRepository, tx db.Transactor, datasets DatasetWriter, rows DataRowWriter, files FileUploader, topics TopicStore, units UnitStore, observer RunObserver, cfg config.HarvestConfig) HarvestUsecase {
+	return &harvestUsecase{
+		repo:     repo,
+		runRepo:  runRepo,
+		matches:  matches,
+		tx:       tx,
+		datasets: datasets,
+		rows:     rows,
+		files:    files,
+		topics:   topics,
+		units:    units,
+		observer: observer,
+		client:   &http.Client{Timeout: cfg.Timeout},
+		cfg:      cfg,
+		now:      time.Now,
+	}
+}
+
+func (u *harvestUsecase) Harvest(ctx context.Context, integrationID string, trigger domain.RunTrigger) (*domain.RunInfo, error) {
+	integration, err := u.repo.GetByID(ctx, integrationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get integration: %w", err)
+	}
+	if integration.Type != string(domain.IntegrationTypeConnector) {
+		return nil, fmt.Errorf("%w: integration is not a connector", pkgErrors.ErrInvalidInput)
+	}
+
+	cfg, err := connector.ParseConfig(integration)
+	if err != nil {
+		return nil, err
+	}
+
+	run, err := u.execute(ctx, integration, cfg, trigger)
+	if err != nil {
+		return nil, err
+	}
+	return toRunInfo(run), nil
+}
+
+func (u *harvestUsecase) ListRuns(ctx context.Context, integrationID string, req *domain.ListRunsRequest) (*domain.RunListResponse, error) {
+	if _, err := u.repo.GetByID(ctx, integrationID); err != nil {
+		return nil, fmt.Errorf("failed to get integration: %w", err)
+	}
+
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.Limit < 1 {
+		req.Limit = 20
+	}
+
+	offset := (req.Page - 1) * req.Limit
+
+	runs, total, err := u.runRepo.ListRuns(ctx, integrationID, req.Limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list runs: %w", err)
+	}
+
+	infos := make([]domain.RunInfo, len(runs))
+	for i, run := range runs {
+		infos[i] = *toRunInfo(run)
+	}
+
+	totalPage := int(math.Ceil(float64(total) / float64(req.Limit)))
+
+	return &domain.RunListResponse{
+		Runs: infos,
+		Meta: domain.ListMeta{
+			Page:      req.Page,
+			Limit:     req.Limit,
+			Total:     total,
+			TotalPage: totalPage,
+		},
+	}, nil
+}
+
+// execute fetches and writes records, recording the outcome as a run. A failed
+// harvest is reported on the run; only persistence failures are returned.
+func (u *harvestUsecase) execute(ctx context.Context, integration *domain.Integration, cfg *domain.ConnectorConfig, trigger domain.RunTrigger) (*domain.Run, error) {
+	run, err := startRun(ctx, u.runRepo, integration.ID, trigger, u.now(), u.cfg.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer u.runRepo.ReleaseRunLock(context.WithoutCancel(ctx), integration.ID)
+
+	runCtx, cancel := context.WithTimeout(ctx, u.cfg.Timeout)
+	defer cancel()
+
+	var runErrors []string
+	var records []connector.Record
+	if cfg.Target == domain.HarvestTargetFiles {
+		runErrors, err = u.syncBucket(runCtx, integration, cfg, run)
+	} else if records, err = u.fetch(runCtx, integration, cfg); err == nil {
+		run.RecordsFetched = len(records)
+		switch cfg.Target {
+		case domain.HarvestTargetDatasets:
+			runErrors = u.writeDatasets(runCtx, integration, cfg, records, run)
+		case domain.HarvestTargetDataRows:
+			runErrors = u.writeDataRows(runCtx, integration, cfg, records, run)
+		}
+	}
+	if err != nil {
+		runErrors = append(runErrors, err.Error())
+	}
+
+	finishRun(run, err != nil, runErrors, u.now())
+
+	// Record the outcome even if the run context has expired
+	if err := u.runRepo.UpdateRun(context.WithoutCancel(ctx), run); err != nil {
+		return nil, fmt.Errorf("failed to update run: %w", err)
+	}
+	if err := u.repo.Sync(context.WithoutCancel(ctx), integration.ID); err != nil {
+		return nil, fmt.Errorf("failed to update last sync: %w", err)
+	}
+	if u.observer != nil {
+		u.observer.RunFinished(context.WithoutCancel(ctx), integration, run)
+	}
+
+	return run, nil
+}
+
+// finishRun sets the final status, errors and finish time of a run. A run fails
+// when it was aborted or when nothing was written despite errors.
+func finishRun(run *domain.Run, aborted bool, runErrors []string, now time.Time) {
+	switch {
+	case aborted || (len(runErrors) > 0 && run.RecordsCreated+run.RecordsUpdated == 0):
+		run.Status = string(domain.RunStatusFailed)
+	case len(runErrors) > 0:
+		run.Status = string(domain.RunStatusPartial)
+	default:
+		run.Status = string(domain.RunStatusSucceeded)
+	}
+
+	if len(runErrors) > maxRunErrors {
+		runErrors = append(runErrors[:maxRunErrors], fmt.Sprintf("... %d more errors", len(runErrors)-maxRunErrors))
+	}
+	if len(runErrors) > 0 {
+		encoded, _ := json.Marshal(runErrors)
+		run.Errors = string(encoded)
+	}
+	run.FinishedAt = &now
+}
+
+// startRun takes the run lock of an integration and records a new running run.
+// The caller releases the lock when the run finishes.
+func startRun(ctx context.Context, runRepo domain.RunRepository, integrationID string, trigger domain.RunTrigger, now time.Time, timeout time.Duration) (*domain.Run, error) {
+	// Runs are cancelled after timeout, so a lock held twice as long was abandoned
+	locked, err := runRepo.AcquireRunLock(ctx, integrationID, now, now.Add(-2*timeout))
+	if err != nil {
+		return nil, err
+	}
+	if !locked {
+		return nil, fmt.Errorf("%w: a run of this integration is already in progress", pkgErrors.ErrAlreadyExists)
+	}
+
+	run := &domain.Run{
+		ID:            uuid.New().String(),
+		IntegrationID: integrationID,
+		Trigger:       string(trigger),
+		Status:        string(domain.RunStatusRunning),
+		Errors:        "[]",
+		StartedAt:     now,
+	}
+	if err := runRepo.CreateRun(ctx, run); err != nil {
+		_ = runRepo.ReleaseRunLock(context.WithoutCancel(ctx), integrationID)
+		return nil, fmt.Errorf("failed to create run: %w", err)
+	}
+	return run, nil
+}
+
+func (u *harvestUsecase) fetch(ctx context.Context, integration *domain.Integration, cfg *domain.ConnectorConfig) ([]connector.Record, error) {
+	source, err := connector.New(integration, cfg, u.client)
+	if err != nil {
+		return nil, err
+	}
+	records, err := source.Fetch(ctx, u.cfg.MaxRecords)
+	if err != nil {
+		return nil, fmt.Errorf("fetch failed: %w", err)
+	}
+	return records, nil
+}
+
+// writeDatasets upserts one dataset per record, matching earlier runs by the
+// remote record ID and, with dedup, other datasets by external ID and name
+func (u *harvestUsecase) writeDatasets(ctx context.Context, integration *domain.Integration, cfg *domain.ConnectorConfig, records []connector.Record, run *domain.Run) []string {
+	if integration.OrganizationID == nil {
+		run.RecordsFailed = len(records)
+		return []string{"datasets target requires the integration to belong to an organization"}
+	}
+
+	idField := cfg.IDField
+	if idField == "" {
+		idField = "id"
+	}
+
+	var runErrors []string
+	for i, record := range records {
+		remoteID := record.String(idField)
+		if remoteID == "" {
+			run.RecordsFailed++
+			runErrors = append(runErrors, fmt.Sprintf("record %d: missing %s", i, idField))
+			continue
+		}
+
+		outcome, err := u.upsertDataset(ctx, integration, cfg, remoteID, record)
+		if err != nil {
+			run.RecordsFailed++
+			runErrors = append(runErrors, fmt.Sprintf("record %s: %v", remoteID, err))
+			continue
+		}
+		switch outcome {
+		case outcomeCreated:
+			run.RecordsCreated++
+		case outcomeUpdated:
+			run.RecordsUpdated++
+		case outcomeQueued:
+			run.RecordsQueued++
+		}
+	}
+	return runErrors
+}
+
+func (u *harvestUsecase) upsertDataset(ctx context.Context, integration *domain.Integration, cfg *domain.ConnectorConfig, remoteID string, record connector.Record) (harvestOutcome, error) {
+	fields, err := u.datasetFields(ctx, cfg, record)
+	if err != nil {
+		return 0, err
+	}
+	var externalID string
+	if cfg.Dedup != nil && cfg.Dedup.ExternalIDField != "" {
+		externalID = record.String(cfg.Dedup.ExternalIDField)
+	}
+
+	// The dataset, its harvest record and its rows are written together, so a
+	// failed record leaves nothing behind and is retried on the next run
+	var outcome harvestOutcome
+	err = u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		harvested, err := u.runRepo.GetHarvestRecord(ctx, integration.ID, remoteID)
+		if err != nil && !errors.Is(err, pkgErrors.ErrNotFound) {
+			return err
+		}
+
+		if harvested != nil {
+			outcome, err = u.writeDataset(ctx, integration, cfg, domain.ConflictPolicy(harvested.Policy), harvested.DatasetID, fields, record)
+			if !errors.Is(err, pkgErrors.ErrNotFound) {
+				return err
+			}
+			// The harvested dataset was removed locally; harvest the record again below
+		}
+
+		policy, datasetID := domain.ConflictPolicyCreate, ""
+		if cfg.Dedup != nil {
+			match, err := u.findMatch(ctx, integration, cfg.Dedup, externalID, fields["name"])
+			if err != nil {
+				return err
+			}
+			if match != nil {
+				policy = cfg.Dedup.Policy
+				if match.Reason == string(domain.MatchReasonTitle) && match.Score < cfg.Dedup.TitleThreshold {
+					policy = domain.ConflictPolicyReview
+				}
+				if policy == domain.ConflictPolicyReview {
+					outcome = outcomeQueued
+					return u.queueMatch(ctx, integration, remoteID, externalID, match, record)
+				}
+				datasetID = match.DatasetID
+			}
+		}
+
+		outcome, err = u.resolve(ctx, integration, cfg, remoteID, externalID, policy, datasetID, fields, record)
+		return err
+	})
+	return outcome, err
+}
+
+// datasetFields maps a record to the fields of its dataset, resolving topic
+// and unit names
+func (u *harvestUsecase) datasetFields(ctx context.Context, cfg *domain.ConnectorConfig, record connector.Record) (map[string]string, error) {
+	fields := mapDatasetFields(cfg, record)
+	if len(fields["name"]) < 2 {
+		return nil, fmt.Errorf("name is missing or too short")
+	}
+	if fields["classification"] == "" || fields["category"] == "" {
+		return nil, fmt.Errorf("classification and category are required")
+	}
+	if err := u.resolveTaxonomy(ctx, cfg, fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// resolve writes a record with policy, to the dataset datasetID or to a new
+// dataset with the create policy, and keeps the policy for later runs
+func (u *harvestUsecase) resolve(ctx context.Context, integration *domain.Integration, cfg *domain.ConnectorConfig, remoteID, externalID string, policy domain.ConflictPolicy, datasetID string, fields map[string]string, record connector.Record) (harvestOutcome, error) {
+	var outcome harvestOutcome
+	if policy == domain.ConflictPolicyCreate {
+		if integration.OrganizationID == nil {
+			return 0, fmt.Errorf("%w: creating datasets requires the integration to belong to an organization", pkgErrors.ErrInvalidInput)
+		}
+		dataset, err := u.datasets.Create(ctx, createDatasetRequest(fields), integration.CreatedBy, *integration.OrganizationID)
+		if err != nil {
+			return 0, err
+		}
+		datasetID = dataset.ID
+		outcome = outcomeCreated
+		if err := u.writeRecordRows(ctx, integration, cfg, datasetID, record); err != nil {
+			return 0, err
+		}
+	} else {
+		var err error
+		if outcome, err = u.writeDataset(ctx, integration, cfg, policy, datasetID, fields, record); err != nil {
+			return 0, err
+		}
+	}
+
+	harvested := &domain.HarvestRecord{
+		IntegrationID: integration.ID,
+		RemoteID:      remoteID,
+		DatasetID:     datasetID,
+		Policy:        string(policy),
+		HarvestedAt:   u.now(),
+	}
+	if externalID != "" {
+		harvested.ExternalID = &externalID
+	}
+	if err := u.runRepo.SaveHarvestRecord(ctx, harvested); err != nil {
+		return 0, err
+	}
+	return outcome, nil
+}
+
+// writeDataset writes a record to the dataset it was harvested into before
+// with the policy kept for it. It returns ErrNotFound when the dataset was
+// removed.
+func (u *harvestUsecase) writeDataset(ctx context.Context, integration *domain.Integration, cfg *domain.ConnectorConfig, policy domain.ConflictPolicy, datasetID string, fields map[string]string, record connector.Record) (harvestOutcome, error) {
+	switch policy {
+	case domain.ConflictPolicyLink:
+		if _, err := u.datasets.GetByID(ctx, datasetID); err != nil {
+			return 0, err
+		}
+		return outcomeUnchanged, nil
+	case domain.ConflictPolicyMerge:
+		existing, err := u.datasets.GetByID(ctx, datasetID)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := u.datasets.Update(ctx, datasetID, mergeDatasetRequest(existing, fields), integration.CreatedBy); err != nil {
+			return 0, err
+		}
+		return outcomeUpdated, nil
+	default:
+		if _, err := u.datasets.Update(ctx, datasetID, updateDatasetRequest(fields), integration.CreatedBy); err != nil {
+			return 0, err
+		}
+		return outcomeUpdated, u.writeRecordRows(ctx, integration, cfg, datasetID, record)
+	}
+}
+
+// writeRecordRows replaces the rows of a harvested dataset with the table held
+// in the record's RowsField, if the connector provides one
+func (u *harvestUsecase) writeRecordRows(ctx context.Context, integration *domain.Integration, cfg *domain.ConnectorConfig, datasetID string, record connector.Record) error {
+	if cfg.RowsField == "" {
+		return nil
+	}
+	value, ok := record.Lookup(cfg.RowsField)
+	if !ok {
+		return nil
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		return fmt.Errorf("%s is not a list of rows", cfg.RowsField)
+	}
+
+	rows := make([]map[string]interface{}, len(items))
+	for i, item := range items {
+		if row, ok := item.(map[string]interface{}); ok {
+			rows[i] = row
+		} else {
+			rows[i] = map[string]interface{}{"value": item}
+		}
+	}
+
+	writer := &rowWriter{rows: u.rows, tx: u.tx, userID: integration.CreatedBy, datasetID: datasetID}
+	result, err := writer.replace(ctx, rows)
+	if err != nil {
+		return fmt.Errorf("failed to write rows: %w", err)
+	}
+	if errs := result.errors(); len(errs) > 0 {
+		return fmt.Errorf("failed to write rows: %s", errs[0])
+	}
+	return nil
+}
+
+// resolveTaxonomy fills topic_id and unit_id from mapped topic and unit names
+// when no ID was mapped
+func (u *harvestUsecase) resolveTaxonomy(ctx context.Context, cfg *domain.ConnectorConfig, fields map[string]string) error {
+	if fields["topic_id"] == "" && fields["topic"] != "" {
+		topicID, err := u.resolveTopic(ctx, cfg, fields["topic"])
+		if err != nil {
+			return err
+		}
+		fields["topic_id"] = topicID
+	}
+	if fields["unit_id"] == "" && fields["unit"] != "" {
+		unitID, err := u.resolveUnit(ctx, fields["unit"])
+		if err != nil {
+			return err
+		}
+		fields["unit_id"] = unitID
+	}
+	return nil
+}
+
+// resolveTopic maps a source topic name to a topic ID through cfg.TopicMap, an
+// existing topic of the same name, or a newly created topic
+func (u *harvestUsecase) resolveTopic(ctx context.Context, cfg *domain.ConnectorConfig, name string) (string, error) {
+	if topicID, ok := cfg.TopicMap[name]; ok {
+		return topicID, nil
+	}
+
+	resp, err := u.topics.List(ctx, &topicDomain.ListTopicsRequest{Page: 1, Limit: 100, Search: name})
+	if err != nil {
+		return "", fmt.Errorf("failed to find topic %q: %w", name, err)
+	}
+	for _, topic := range resp.Topics {
+		if strings.EqualFold(topic.Name, name) {
+			return topic.ID, nil
+		}
+	}
+
+	topic, err := u.topics.Create(ctx, &topicDomain.CreateTopicRequest{Name: name})
+	if err != nil {
+		return "", fmt.Errorf("failed to create topic %q: %w", name, err)
+	}
+	return topic.ID, nil
+}
+
+// resolveUnit maps a source unit to a unit ID by name or symbol, creating the
+// unit if it does not exist yet
+func (u *harvestUsecase) resolveUnit(ctx context.Context, name string) (string, error) {
+	resp, err := u.units.List(ctx, &unitDomain.ListUnitsRequest{Page: 1, Limit: 100, Search: name})
+	if err != nil {
+		return "", fmt.Errorf("failed to find unit %q: %w", name, err)
+	}
+	for _, unit := range resp.Units {
+		if strings.EqualFold(unit.Name, name) || strings.EqualFold(unit.Symbol, name) {
+			return unit.ID, nil
+		}
+	}
+
+	unit, err := u.units.Create(ctx, &unitDomain.CreateUnitRequest{Name: name, Symbol: name})
+	if err != nil {
+		return "", fmt.Errorf("failed to create unit %q: %w", name, err)
+	}
+	return unit.ID, nil
+}
+
+// writeDataRows writes the harvested records as rows of the target dataset,
+// replacing all rows or upserting them depending on the configured mode
+func (u *harvestUsecase) writeDataRows(ctx context.Context, integration *domain.Integration, cfg *domain.ConnectorConfig, records []connector.Record, run *domain.Run) []string {
+	rows := make([]map[string]interface{}, len(records))
+	for i, record := range records {
+		rows[i] = mapRowFields(cfg.Mapping, cfg.Defaults, record)
+	}
+
+	result, err := u.writeRows(ctx, integration, cfg, rows)
+	if err != nil {
+		run.RecordsFailed += len(rows)
+		return []string{err.Error()}
+	}
+
+	run.RecordsCreated += result.Created
+	run.RecordsUpdated += result.Updated
+	run.RecordsFailed += len(result.Failed)
+	return result.errors()
+}
+
+// writeRows replaces or upserts rows of the configured dataset depending on the mode
+func (u *harvestUsecase) writeRows(ctx context.Context, integration *domain.Integration, cfg *domain.ConnectorConfig, rows []map[string]interface{}) (*rowResult, error) {
+	writer := &rowWriter{rows: u.rows, tx: u.tx, userID: integration.CreatedBy, datasetID: cfg.DatasetID}
+	if cfg.Mode == domain.RowWriteUpsert {
+		return writer.upsert(ctx, cfg.KeyField, rows)
+	}
+	return writer.replace(ctx, rows)
+}
+
+// syncBucket imports new and changed objects of a bucket connector into the
+// file module. Objects are tracked by key and ETag so every version of an
+// object is imported once.
+func (u *harvestUsecase) syncBucket(ctx context.Context, integration *domain.Integration, cfg *domain.ConnectorConfig, run *domain.Run) ([]string, error) {
+	source, err := connector.New(integration, cfg, u.client)
+	if err != nil {
+		return nil, err
+	}
+	bucket, ok := source.(connector.Bucket)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s connector does not sync files", pkgErrors.ErrInvalidInput, cfg.Connector)
+	}
+	if closer, ok := source.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	objects, err := bucket.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetch failed: %w", err)
+	}
+	synced, err := u.runRepo.ListSyncedObjects(ctx, integration.ID)
+	if err != nil {
+		return nil, err
+	}
+	etags := make(map[string]string, len(synced))
+	for _, object := range synced {
+		etags[object.Key] = object.ETag
+	}
+
+	var runErrors []string
+	for _, object := range objects {
+		etag, seen := etags[object.Key]
+		if seen && etag == object.ETag {
+			continue
+		}
+		if run.RecordsFetched == maxSyncObjects {
+			break
+		}
+		run.RecordsFetched++
+
+		if err := u.importObject(ctx, integration, cfg, bucket, object); err != nil {
+			run.RecordsFailed++
+			runErrors = append(runErrors, fmt.Sprintf("object %s: %v", object.Key, err))
+			continue
+		}
+		if seen {
+			run.RecordsUpdated++
+		} else {
+			run.RecordsCreated++
+		}
+	}
+	return runErrors, nil
+}
+
+// importObject uploads an object to the file module and, with ingest_rows,
+// writes the rows of CSV and JSON files to the dataset. The object is recorded
+// as synced once its file exists, so a row failure does not import it twice.
+func (u *harvestUsecase) importObject(ctx context.Context, integration *domain.Integration, cfg *domain.ConnectorConfig, bucket connector.Bucket, object connector.Object) error {
+	body, err := bucket.Read(ctx, object.Key)
+	if err != nil {
+		return err
+	}
+
+	contentType := object.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(path.Ext(object.Key))
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	var datasetID *string
+	if cfg.DatasetID != "" {
+		datasetID = &cfg.DatasetID
+	}
+
+	file, err := u.files.Upload(ctx, path.Base(object.Key), int64(len(body)), contentType, bytes.NewReader(body), datasetID, integration.CreatedBy)
+	if err != nil {
+		return fmt.Errorf("failed to import file: %w", err)
+	}
+
+	err = u.runRepo.SaveSyncedObject(ctx, &domain.SyncedObject{
+		IntegrationID: integration.ID,
+		Key:           object.Key,
+		ETag:          object.ETag,
+		Size:          int64(len(body)),
+		FileID:        file.ID,
+		SyncedAt:      u.now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	if !cfg.IngestRows {
+		return nil
+	}
+	return u.ingestObjectRows(ctx, integration, cfg, object.Key, body)
+}
+
+// ingestObjectRows writes the records of a CSV or JSON file as dataset rows.
+// Files of other types are imported without rows.
+func (u *harvestUsecase) ingestObjectRows(ctx context.Context, integration *domain.Integration, cfg *domain.ConnectorConfig, key string, body []byte) error {
+	var records []connector.Record
+	var err error
+	switch strings.ToLower(path.Ext(key)) {
+	case ".csv":
+		records, err = connector.ParseCSV(body, cfg.Delimiter, u.cfg.MaxRecords)
+	case ".json":
+		records, err = connector.ParseJSON(body, cfg.RecordsPath, u.cfg.MaxRecords)
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	rows := make([]map[string]interface{}, len(records))
+	for i, record := range records {
+		rows[i] = mapRowFields(cfg.Mapping, cfg.Defaults, record)
+	}
+
+	result, err := u.writeRows(ctx, integration, cfg, rows)
+	if err != nil {
+		return fmt.Errorf("failed to write rows: %w", err)
+	}
+	if errs := result.errors(); len(errs) > 0 {
+		return fmt.Errorf("failed to write rows: %s", errs[0])
+	}
+	return nil
+}
+
+// defaultDatasetMappings maps dataset fields to source fields when a connector
+// config has no explicit mapping for them
+var defaultDatasetMappings = map[domain.ConnectorType]map[string]string{
+	domain.ConnectorTypeCKAN: {
+		"name":        "title",
+		"description": "notes",
+		"source_url":  "url",
+	},
+	domain.ConnectorTypeBPS: {
+		"name":        "title",
+		"description": "definition",
+		"topic":       "subject",
+		"unit":        "unit",
+	},
+}
+
+// mapDatasetFields resolves dataset fields from a record using the configured
+// mapping, connector defaults, and fallback values. Unmapped metadata keeps the
+// raw record for provenance.
+func mapDatasetFields(cfg *domain.ConnectorConfig, record connector.Record) map[string]string {
+	fields := map[string]string{}
+	for _, field := range []string{"name", "description", "period", "coverage_start", "coverage_end", "frequency", "unit_id", "business_field_id",
+		"image", "topic_id", "reference_id", "source_url", "classification", "category", "metadata"} {
+		source, ok := cfg.Mapping[field]
+		if !ok {
+			source, ok = defaultDatasetMappings[cfg.Connector][field]
+		}
+		if !ok {
+			source = field
+		}
+
+		value := record.String(source)
+		if value == "" {
+			value = cfg.Defaults[field]
+		}
+		fields[field] = value
+	}
+
+	// Topic and unit names are only read when mapped; the harvest resolves them to IDs
+	for _, field := range []string{"topic", "unit"} {
+		source, ok := cfg.Mapping[field]
+		if !ok {
+			source, ok = defaultDatasetMappings[cfg.Connector][field]
+		}
+		if ok {
+			fields[field] = record.String(source)
+		}
+	}
+
+	if _, mapped := cfg.Mapping["metadata"]; !mapped && fields["metadata"] == "" {
+		provenance := record
+		if cfg.RowsField != "" {
+			// The table is stored as data rows, not in the metadata
+			provenance = make(connector.Record, len(record))
+			for key, value := range record {
+				if key != cfg.RowsField {
+					provenance[key] = value
+				}
+			}
+		}
+		raw, _ := json.Marshal(provenance)
+		fields["metadata"] = string(raw)
+	}
+	return fields
+}
+
+// mapRowFields projects a record onto the mapped columns, or keeps it whole
+// when no mapping is configured
+func mapRowFields(mapping, defaults map[string]string, record connector.Record) map[string]interface{} {
+	if len(mapping) == 0 {
+		return record
+	}
+
+	row := make(map[string]interface{}, len(mapping))
+	for column, source := range mapping {
+		if value, ok := record.Lookup(source); ok {
+			row[column] = value
+		} else if fallback, ok := defaults[column]; ok {
+			row[column] = fallback
+		} else {
+			row[column] = nil
+		}
+	}
+	return row
+}
+
+func toRunInfo(run *domain.Run) *domain.RunInfo {
+	info := &domain.RunInfo{
+		ID:             run.ID,
+		IntegrationID:  run.IntegrationID,
+		Trigger:        run.Trigger,
+		Status:         run.Status,
+		RecordsFetched: run.RecordsFetched,
+		RecordsCreated: run.RecordsCreated,
+		RecordsUpdated: run.RecordsUpdated,
+		RecordsFailed:  run.RecordsFailed,
+		RecordsQueued:  run.RecordsQueued,
+		Errors:         []string{},
+		StartedAt:      run.StartedAt,
+		FinishedAt:     run.FinishedAt,
+	}
+	if run.Errors != "" {
+		_ = json.Unmarshal([]byte(run.Errors), &info.Errors)
+	}
+	if run.FinishedAt != nil {
+		info.DurationMs = run.FinishedAt.Sub(run.StartedAt).Milliseconds()
+	}
+	return info
+}
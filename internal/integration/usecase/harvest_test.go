@@ -0,0 +1,1068 @@
+package usecase_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+	dataRowDomain "portal-data-backend/internal/data_row/domain"
+	datasetDomain "portal-data-backend/internal/dataset/domain"
+	fileDomain "portal-data-backend/internal/file/domain"
+	"portal-data-backend/internal/integration/domain"
+	"portal-data-backend/internal/integration/usecase"
+	topicDomain "portal-data-backend/internal/topic/domain"
+	unitDomain "portal-data-backend/internal/unit/domain"
+	pkgerrors "portal-data-backend/pkg/errors"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// mockRunRepository is an in-memory implementation of RunRepository
+type mockRunRepository struct {
+	runs    []*domain.Run
+	records map[string]*domain.HarvestRecord
+	claims  map[string]time.Time
+	locks   map[string]bool
+	objects map[string]*domain.SyncedObject
+}
+
+func (m *mockRunRepository) CreateRun(ctx context.Context, run *domain.Run) error {
+	m.runs = append(m.runs, run)
+	return nil
+}
+
+func (m *mockRunRepository) UpdateRun(ctx context.Context, run *domain.Run) error {
+	return nil
+}
+
+func (m *mockRunRepository) ListRuns(ctx context.Context, integrationID string, limit, offset int) ([]*domain.Run, int, error) {
+	var runs []*domain.Run
+	for i := len(m.runs) - 1; i >= 0; i-- {
+		if m.runs[i].IntegrationID == integrationID {
+			runs = append(runs, m.runs[i])
+		}
+	}
+	total := len(runs)
+	if offset < len(runs) {
+		runs = runs[offset:]
+	} else {
+		runs = nil
+	}
+	if len(runs) > limit {
+		runs = runs[:limit]
+	}
+	return runs, total, nil
+}
+
+func (m *mockRunRepository) ListLatestRuns(ctx context.Context, perIntegration int) ([]*domain.Run, error) {
+	var runs []*domain.Run
+	counts := make(map[string]int)
+	for i := len(m.runs) - 1; i >= 0; i-- {
+		run := m.runs[i]
+		if counts[run.IntegrationID] < perIntegration {
+			counts[run.IntegrationID]++
+			runs = append(runs, run)
+		}
+	}
+	return runs, nil
+}
+
+func (m *mockRunRepository) ClaimNextRun(ctx context.Context, integrationID string, nextRunAt *time.Time, next time.Time) (bool, error) {
+	if m.claims == nil {
+		m.claims = make(map[string]time.Time)
+	}
+	m.claims[integrationID] = next
+	return true, nil
+}
+
+func (m *mockRunRepository) AcquireRunLock(ctx context.Context, integrationID string, now, staleBefore time.Time) (bool, error) {
+	if m.locks == nil {
+		m.locks = make(map[string]bool)
+	}
+	if m.locks[integrationID] {
+		return false, nil
+	}
+	m.locks[integrationID] = true
+	return true, nil
+}
+
+func (m *mockRunRepository) ReleaseRunLock(ctx context.Context, integrationID string) error {
+	delete(m.locks, integrationID)
+	return nil
+}
+
+func (m *mockRunRepository) GetHarvestRecord(ctx context.Context, integrationID, remoteID string) (*domain.HarvestRecord, error) {
+	record, ok := m.records[integrationID+"/"+remoteID]
+	if !ok {
+		return nil, pkgerrors.ErrNotFound
+	}
+	return record, nil
+}
+
+func (m *mockRunRepository) FindHarvestRecord(ctx context.Context, externalID, integrationID string) (*domain.HarvestRecord, error) {
+	for _, record := range m.records {
+		if record.ExternalID != nil && *record.ExternalID == externalID && record.IntegrationID != integrationID {
+			return record, nil
+		}
+	}
+	return nil, pkgerrors.ErrNotFound
+}
+
+func (m *mockRunRepository) SaveHarvestRecord(ctx context.Context, record *domain.HarvestRecord) error {
+	m.records[record.IntegrationID+"/"+record.RemoteID] = record
+	return nil
+}
+
+func (m *mockRunRepository) ListSyncedObjects(ctx context.Context, integrationID string) ([]*domain.SyncedObject, error) {
+	var objects []*domain.SyncedObject
+	for _, object := range m.objects {
+		objects = append(objects, object)
+	}
+	return objects, nil
+}
+
+func (m *mockRunRepository) SaveSyncedObject(ctx context.Context, object *domain.SyncedObject) error {
+	if m.objects == nil {
+		m.objects = make(map[string]*domain.SyncedObject)
+	}
+	m.objects[object.Key] = object
+	return nil
+}
+
+// mockDatasetWriter records dataset writes made by a harvest. existing holds
+// datasets to match, similar the datasets whose name is close to a name.
+type mockDatasetWriter struct {
+	created  []*datasetDomain.CreateDatasetRequest
+	updated  []string
+	requests []*datasetDomain.UpdateDatasetRequest
+	existing map[string]*datasetDomain.DatasetResponse
+	similar  map[string]datasetDomain.DatasetSuggestion
+}
+
+func (m *mockDatasetWriter) GetByID(ctx context.Context, id string) (*datasetDomain.DatasetResponse, error) {
+	dataset, ok := m.existing[id]
+	if !ok {
+		return nil, pkgerrors.ErrNotFound
+	}
+	return dataset, nil
+}
+
+func (m *mockDatasetWriter) FindSimilar(ctx context.Context, name, orgID string, limit int) ([]datasetDomain.DatasetSuggestion, error) {
+	if suggestion, ok := m.similar[name]; ok {
+		return []datasetDomain.DatasetSuggestion{suggestion}, nil
+	}
+	return nil, nil
+}
+
+func (m *mockDatasetWriter) Create(ctx context.Context, req *datasetDomain.CreateDatasetRequest, creatorID, orgID string) (*datasetDomain.DatasetResponse, error) {
+	m.created = append(m.created, req)
+	return &datasetDomain.DatasetResponse{ID: "dataset-" + req.Name}, nil
+}
+
+func (m *mockDatasetWriter) Update(ctx context.Context, id string, req *datasetDomain.UpdateDatasetRequest, updaterID string) (*datasetDomain.DatasetResponse, error) {
+	m.updated = append(m.updated, id)
+	m.requests = append(m.requests, req)
+	return &datasetDomain.DatasetResponse{ID: id}, nil
+}
+
+// mockMatchRepository is an in-memory implementation of MatchRepository
+type mockMatchRepository struct {
+	matches []*domain.HarvestMatch
+}
+
+func (m *mockMatchRepository) SaveMatch(ctx context.Context, match *domain.HarvestMatch) error {
+	for i, existing := range m.matches {
+		if existing.IntegrationID == match.IntegrationID && existing.RemoteID == match.RemoteID && existing.Status == match.Status {
+			match.ID, match.CreatedAt = existing.ID, existing.CreatedAt
+			m.matches[i] = match
+			return nil
+		}
+	}
+	m.matches = append(m.matches, match)
+	return nil
+}
+
+func (m *mockMatchRepository) GetMatch(ctx context.Context, integrationID, id string) (*domain.HarvestMatch, error) {
+	for _, match := range m.matches {
+		if match.IntegrationID == integrationID && match.ID == id {
+			copied := *match
+			return &copied, nil
+		}
+	}
+	return nil, pkgerrors.ErrNotFound
+}
+
+func (m *mockMatchRepository) ListMatches(ctx context.Context, integrationID string, status *string, limit, offset int) ([]*domain.HarvestMatch, int, error) {
+	var matches []*domain.HarvestMatch
+	for _, match := range m.matches {
+		if match.IntegrationID == integrationID && (status == nil || match.Status == *status) {
+			matches = append(matches, match)
+		}
+	}
+	return matches, len(matches), nil
+}
+
+func (m *mockMatchRepository) ResolveMatch(ctx context.Context, match *domain.HarvestMatch) error {
+	for i, existing := range m.matches {
+		if existing.ID == match.ID && existing.Status == string(domain.MatchStatusPending) {
+			m.matches[i] = match
+			return nil
+		}
+	}
+	return pkgerrors.ErrNotFound
+}
+
+// mockDataRowWriter records row writes made by a harvest
+type mockDataRowWriter struct {
+	rows     []dataRowDomain.DataRowDataInput
+	existing []dataRowDomain.DataRowInfo
+	updated  []string
+}
+
+func (m *mockDataRowWriter) List(ctx context.Context, req *dataRowDomain.ListDataRowsRequest) (*dataRowDomain.DataRowListResponse, error) {
+	return &dataRowDomain.DataRowListResponse{
+		Rows: m.existing,
+		Meta: dataRowDomain.ListMeta{Page: req.Page, Limit: req.Limit, Total: len(m.existing), TotalPage: 1},
+	}, nil
+}
+
+func (m *mockDataRowWriter) Create(ctx context.Context, req *dataRowDomain.CreateDataRowRequest, userID string) (*dataRowDomain.DataRowInfo, error) {
+	m.rows = append(m.rows, dataRowDomain.DataRowDataInput{RowIndex: req.RowIndex, Data: req.Data})
+	return &dataRowDomain.DataRowInfo{ID: "row-new", DatasetID: req.DatasetID, RowIndex: req.RowIndex, Data: req.Data}, nil
+}
+
+func (m *mockDataRowWriter) Update(ctx context.Context, id string, req *dataRowDomain.UpdateDataRowRequest) (*dataRowDomain.DataRowInfo, error) {
+	m.updated = append(m.updated, id)
+	return &dataRowDomain.DataRowInfo{ID: id, Data: *req.Data}, nil
+}
+
+func (m *mockDataRowWriter) DeleteByDatasetID(ctx context.Context, datasetID string) error {
+	m.rows = nil
+	return nil
+}
+
+func (m *mockDataRowWriter) BulkCreate(ctx context.Context, req *dataRowDomain.BulkCreateDataRowsRequest, userID string) error {
+	m.rows = append(m.rows, req.Rows...)
+	return nil
+}
+
+// mockFileUploader records files imported by a harvest
+type mockFileUploader struct {
+	names    []string
+	contents []string
+}
+
+func (m *mockFileUploader) Upload(ctx context.Context, fileName string, fileSize int64, mimeType string, reader io.Reader, datasetID *string, userID string) (*fileDomain.UploadResponse, error) {
+	content, _ := io.ReadAll(reader)
+	m.names = append(m.names, fileName)
+	m.contents = append(m.contents, string(content))
+	return &fileDomain.UploadResponse{ID: fmt.Sprintf("file-%d", len(m.names)), Name: fileName, Size: fileSize}, nil
+}
+
+// mockTopicStore is an in-memory topic module
+type mockTopicStore struct {
+	topics []topicDomain.TopicResponse
+}
+
+func (m *mockTopicStore) List(ctx context.Context, req *topicDomain.ListTopicsRequest) (*topicDomain.TopicListResponse, error) {
+	return &topicDomain.TopicListResponse{Topics: m.topics}, nil
+}
+
+func (m *mockTopicStore) Create(ctx context.Context, req *topicDomain.CreateTopicRequest) (*topicDomain.TopicResponse, error) {
+	topic := topicDomain.TopicResponse{ID: "topic-" + req.Name, Name: req.Name}
+	m.topics = append(m.topics, topic)
+	return &topic, nil
+}
+
+// mockUnitStore is an in-memory unit module
+type mockUnitStore struct {
+	units []unitDomain.UnitResponse
+}
+
+func (m *mockUnitStore) List(ctx context.Context, req *unitDomain.ListUnitsRequest) (*unitDomain.UnitListResponse, error) {
+	return &unitDomain.UnitListResponse{Units: m.units}, nil
+}
+
+func (m *mockUnitStore) Create(ctx context.Context, req *unitDomain.CreateUnitRequest) (*unitDomain.UnitResponse, error) {
+	unit := unitDomain.UnitResponse{ID: "unit-" + req.Name, Name: req.Name, Symbol: req.Symbol}
+	m.units = append(m.units, unit)
+	return &unit, nil
+}
+
+// mockTransactor runs units of work without a database and counts those that
+// failed, which a transaction would roll back
+type mockTransactor struct {
+	rolledBack int
+}
+
+func (m *mockTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	err := fn(ctx)
+	if err != nil {
+		m.rolledBack++
+	}
+	return err
+}
+
+// harvestEnv is a harvest usecase together with the mocks it writes through
+type harvestEnv struct {
+	harvests usecase.HarvestUsecase
+	runRepo  *mockRunRepository
+	matches  *mockMatchRepository
+	tx       *mockTransactor
+	datasets *mockDatasetWriter
+	rows     *mockDataRowWriter
+	files    *mockFileUploader
+	topics   *mockTopicStore
+	units    *mockUnitStore
+}
+
+func newConnectorIntegration(connectorConfig string) *domain.Integration {
+	orgID := "org-1"
+	return &domain.Integration{
+		ID:             "connector-1",
+		Type:           string(domain.IntegrationTypeConnector),
+		Status:         string(domain.IntegrationStatusActive),
+		Config:         connectorConfig,
+		OrganizationID: &orgID,
+		CreatedBy:      "user-1",
+	}
+}
+
+func newHarvestEnv(integration *domain.Integration) *harvestEnv {
+	env := &harvestEnv{
+		runRepo:  &mockRunRepository{records: make(map[string]*domain.HarvestRecord)},
+		matches:  &mockMatchRepository{},
+		tx:       &mockTransactor{},
+		datasets: &mockDatasetWriter{},
+		rows:     &mockDataRowWriter{},
+		files:    &mockFileUploader{},
+		topics:   &mockTopicStore{},
+		units:    &mockUnitStore{},
+	}
+	repo := &mockIntegrationRepository{integrations: map[string]*domain.Integration{integration.ID: integration}}
+
+	cfg := config.HarvestConfig{
+		Timeout:    5 * time.Second,
+		MaxRecords: 100,
+	}
+
+	env.harvests = usecase.NewHarvestUsecase(repo, env.runRepo, env.matches, env.tx, env.datasets, env.rows, env.files, env.topics, env.units, nil, cfg)
+	return env
+}
+
+func newHarvestFixture(connectorConfig string) (usecase.HarvestUsecase, *mockRunRepository, *mockDatasetWriter, *mockDataRowWriter) {
+	env := newHarvestEnv(newConnectorIntegration(connectorConfig))
+	return env.harvests, env.runRepo, env.datasets, env.rows
+}
+
+// Test a REST harvest creates datasets and updates them on the next run
+func TestHarvest_RESTDatasetsUpsert(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {"items": [
+			{"code": "a1", "title": "Population 2024"},
+			{"code": "a2", "title": "Rainfall"},
+			{"title": "No identifier"}
+		]}}`))
+	}))
+	defer server.Close()
+
+	harvests, _, datasets, _ := newHarvestFixture(`{
+		"connector": "rest", "url": "` + server.URL + `", "target": "datasets",
+		"records_path": "data.items", "id_field": "code",
+		"mapping": {"name": "title"},
+		"defaults": {"classification": "public", "category": "statistics"}
+	}`)
+	ctx := context.Background()
+
+	run, err := harvests.Harvest(ctx, "connector-1", domain.RunTriggerManual)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if run.Status != string(domain.RunStatusPartial) {
+		t.Errorf("Expected status partial, got %s", run.Status)
+	}
+	if run.RecordsFetched != 3 || run.RecordsCreated != 2 || run.RecordsFailed != 1 {
+		t.Errorf("Expected 3 fetched, 2 created, 1 failed, got %d/%d/%d", run.RecordsFetched, run.RecordsCreated, run.RecordsFailed)
+	}
+	if len(run.Errors) != 1 {
+		t.Errorf("Expected 1 run error, got %v", run.Errors)
+	}
+	if len(datasets.created) != 2 || datasets.created[0].Classification != "public" {
+		t.Errorf("Expected 2 datasets created with default classification, got %+v", datasets.created)
+	}
+
+	run, err = harvests.Harvest(ctx, "connector-1", domain.RunTriggerManual)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if run.RecordsCreated != 0 || run.RecordsUpdated != 2 {
+		t.Errorf("Expected 2 updated on second run, got created=%d updated=%d", run.RecordsCreated, run.RecordsUpdated)
+	}
+}
+
+// Test a harvested dataset whose rows cannot be written is rolled back with them
+func TestHarvest_DatasetRowsInOneTransaction(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[
+			{"code": "a1", "title": "Population 2024", "rows": [{"region": "North", "value": 10}]},
+			{"code": "a2", "title": "Rainfall", "rows": "not a table"}
+		]`))
+	}))
+	defer server.Close()
+
+	env := newHarvestEnv(newConnectorIntegration(`{
+		"connector": "rest", "url": "` + server.URL + `", "target": "datasets",
+		"id_field": "code", "rows_field": "rows",
+		"mapping": {"name": "title"},
+		"defaults": {"classification": "public", "category": "statistics"}
+	}`))
+
+	run, err := env.harvests.Harvest(context.Background(), "connector-1", domain.RunTriggerManual)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if run.RecordsCreated != 1 || run.RecordsFailed != 1 {
+		t.Errorf("Expected 1 created and 1 failed, got created=%d failed=%d", run.RecordsCreated, run.RecordsFailed)
+	}
+	if env.tx.rolledBack != 1 {
+		t.Errorf("Expected the failed record to be rolled back, got %d rollbacks", env.tx.rolledBack)
+	}
+	if len(env.rows.rows) != 1 {
+		t.Errorf("Expected the rows of the created dataset, got %d", len(env.rows.rows))
+	}
+}
+
+// Test dedup merges into the dataset of the same external ID and the dataset
+// with a close name, queues weaker matches and creates the rest
+func TestHarvest_DedupMatchesExistingDatasets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[
+			{"code": "a1", "doi": "10.1/pop", "title": "Population by Regency", "notes": "From BPS"},
+			{"code": "a2", "title": "Poverty Rate", "notes": "Yearly"},
+			{"code": "a3", "title": "Poverty Line"},
+			{"code": "a4", "title": "Rainfall"}
+		]`))
+	}))
+	defer server.Close()
+
+	env := newHarvestEnv(newConnectorIntegration(`{
+		"connector": "rest", "url": "` + server.URL + `", "target": "datasets",
+		"id_field": "code", "mapping": {"name": "title", "description": "notes"},
+		"defaults": {"classification": "public", "category": "statistics"},
+		"dedup": {"external_id_field": "doi", "policy": "merge"}
+	}`))
+	description := "Kept"
+	env.datasets.existing = map[string]*datasetDomain.DatasetResponse{
+		"ds-population": {ID: "ds-population", Name: "Penduduk", Classification: "public", Category: "statistics"},
+		"ds-poverty":    {ID: "ds-poverty", Name: "Poverty rate", Description: &description, Classification: "public", Category: "statistics"},
+	}
+	env.datasets.similar = map[string]datasetDomain.DatasetSuggestion{
+		"Poverty Rate": {ID: "ds-poverty", Similarity: 0.95},
+		"Poverty Line": {ID: "ds-poverty", Similarity: 0.7},
+	}
+	externalID := "10.1/pop"
+	env.runRepo.records["other/x"] = &domain.HarvestRecord{IntegrationID: "other", RemoteID: "x", ExternalID: &externalID, DatasetID: "ds-population"}
+	ctx := context.Background()
+
+	run, err := env.harvests.Harvest(ctx, "connector-1", domain.RunTriggerManual)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if run.RecordsCreated != 1 || run.RecordsUpdated != 2 || run.RecordsQueued != 1 {
+		t.Errorf("Expected 1 created, 2 updated and 1 queued, got %d/%d/%d", run.RecordsCreated, run.RecordsUpdated, run.RecordsQueued)
+	}
+	if len(env.datasets.updated) != 2 || env.datasets.updated[0] != "ds-population" || env.datasets.updated[1] != "ds-poverty" {
+		t.Fatalf("Expected the matched datasets updated, got %v", env.datasets.updated)
+	}
+	if merged := env.datasets.requests[0]; merged.Name != "Penduduk" || merged.Description != "From BPS" {
+		t.Errorf("Expected the name kept and the description filled, got %+v", merged)
+	}
+	if merged := env.datasets.requests[1]; merged.Description != "Kept" {
+		t.Errorf("Expected the description kept, got %q", merged.Description)
+	}
+	if record := env.runRepo.records["connector-1/a1"]; record == nil || record.Policy != string(domain.ConflictPolicyMerge) || *record.ExternalID != externalID {
+		t.Errorf("Expected the record kept with the merge policy, got %+v", record)
+	}
+
+	// A weak match queued again replaces its pending match
+	if _, err := env.harvests.Harvest(ctx, "connector-1", domain.RunTriggerManual); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	pending := "pending"
+	matches, err := env.harvests.ListMatches(ctx, "connector-1", &domain.ListMatchesRequest{Status: &pending})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(matches.Matches) != 1 || matches.Matches[0].RemoteID != "a3" || matches.Matches[0].Record["title"] != "Poverty Line" {
+		t.Fatalf("Expected the pending match of a3 with its record, got %+v", matches.Matches)
+	}
+
+	match, err := env.harvests.ResolveMatch(ctx, "connector-1", matches.Matches[0].ID, &domain.ResolveMatchRequest{Policy: domain.ConflictPolicyCreate}, "reviewer-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if match.Status != string(domain.MatchStatusResolved) || *match.ResolvedBy != "reviewer-1" {
+		t.Errorf("Expected the match resolved by the reviewer, got %+v", match)
+	}
+	if record := env.runRepo.records["connector-1/a3"]; record == nil || record.DatasetID != "dataset-Poverty Line" {
+		t.Errorf("Expected a3 harvested into a new dataset, got %+v", record)
+	}
+	if _, err := env.harvests.ResolveMatch(ctx, "connector-1", match.ID, &domain.ResolveMatchRequest{Policy: domain.ConflictPolicyLink}, "reviewer-1"); !errors.Is(err, pkgerrors.ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput resolving twice, got %v", err)
+	}
+
+	run, err = env.harvests.Harvest(ctx, "connector-1", domain.RunTriggerManual)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if run.RecordsCreated != 0 || run.RecordsUpdated != 4 || run.RecordsQueued != 0 {
+		t.Errorf("Expected every record updated once resolved, got %d/%d/%d", run.RecordsCreated, run.RecordsUpdated, run.RecordsQueued)
+	}
+}
+
+// Test records linked to a dataset leave it alone on later runs
+func TestHarvest_DedupLinkPolicy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"code": "a1", "title": "Rainfall", "rows": [{"value": 1}]}]`))
+	}))
+	defer server.Close()
+
+	env := newHarvestEnv(newConnectorIntegration(`{
+		"connector": "rest", "url": "` + server.URL + `", "target": "datasets",
+		"id_field": "code", "rows_field": "rows", "mapping": {"name": "title"},
+		"defaults": {"classification": "public", "category": "statistics"},
+		"dedup": {"policy": "link"}
+	}`))
+	env.datasets.existing = map[string]*datasetDomain.DatasetResponse{"ds-rainfall": {ID: "ds-rainfall", Name: "Rainfall"}}
+	env.datasets.similar = map[string]datasetDomain.DatasetSuggestion{"Rainfall": {ID: "ds-rainfall", Similarity: 1}}
+
+	for i := 0; i < 2; i++ {
+		run, err := env.harvests.Harvest(context.Background(), "connector-1", domain.RunTriggerManual)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if run.Status != string(domain.RunStatusSucceeded) || run.RecordsCreated+run.RecordsUpdated != 0 {
+			t.Errorf("Expected a successful run writing nothing, got %+v", run)
+		}
+	}
+	if len(env.datasets.created) != 0 || len(env.datasets.updated) != 0 || len(env.rows.rows) != 0 {
+		t.Errorf("Expected the linked dataset untouched, got %d created, %v updated, %d rows", len(env.datasets.created), env.datasets.updated, len(env.rows.rows))
+	}
+	if record := env.runRepo.records["connector-1/a1"]; record == nil || record.DatasetID != "ds-rainfall" {
+		t.Errorf("Expected the record linked to the dataset, got %+v", record)
+	}
+}
+
+// Test a CSV harvest replaces the rows of the target dataset
+func TestHarvest_CSVDataRows(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("region;value\nNorth;10\nSouth;20\n"))
+	}))
+	defer server.Close()
+
+	harvests, _, _, rows := newHarvestFixture(`{
+		"connector": "csv", "url": "` + server.URL + `", "delimiter": ";",
+		"target": "data_rows", "dataset_id": "dataset-1"
+	}`)
+
+	run, err := harvests.Harvest(context.Background(), "connector-1", domain.RunTriggerManual)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if run.Status != string(domain.RunStatusSucceeded) {
+		t.Errorf("Expected status succeeded, got %s (%v)", run.Status, run.Errors)
+	}
+	if len(rows.rows) != 2 {
+		t.Fatalf("Expected 2 rows, got %d", len(rows.rows))
+	}
+	if rows.rows[1].RowIndex != 1 || rows.rows[1].Data != `{"region":"South","value":"20"}` {
+		t.Errorf("Unexpected second row: %+v", rows.rows[1])
+	}
+}
+
+// Test a failing source marks the run failed instead of returning an error
+func TestHarvest_FetchFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	harvests, runRepo, _, _ := newHarvestFixture(`{"connector": "rest", "url": "` + server.URL + `", "target": "datasets"}`)
+
+	run, err := harvests.Harvest(context.Background(), "connector-1", domain.RunTriggerSchedule)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if run.Status != string(domain.RunStatusFailed) {
+		t.Errorf("Expected status failed, got %s", run.Status)
+	}
+	if len(run.Errors) != 1 {
+		t.Errorf("Expected 1 run error, got %v", run.Errors)
+	}
+	if len(runRepo.runs) != 1 {
+		t.Errorf("Expected the run to be recorded, got %d runs", len(runRepo.runs))
+	}
+}
+
+// Test a Google Sheets sync authenticates with the service account and upserts
+// rows on the key field
+func TestHarvest_GoogleSheetsUpsert(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer" || r.FormValue("assertion") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"access_token": "sheets-token", "token_type": "Bearer"}`))
+	})
+	mux.HandleFunc("/v4/spreadsheets/sheet-1/values/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer sheets-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"values": [
+			["Region", "Total"],
+			["North", "15"],
+			["South", "20"],
+			["East"]
+		]}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	serviceAccount, _ := json.Marshal(map[string]string{
+		"client_email": "sync@example.iam.gserviceaccount.com",
+		"private_key":  string(keyPEM),
+		"token_uri":    server.URL + "/token",
+	})
+
+	integration := newConnectorIntegration(`{
+		"connector": "google_sheets", "url": "` + server.URL + `",
+		"spreadsheet_id": "sheet-1", "range": "Data!A1:B10",
+		"target": "data_rows", "dataset_id": "dataset-1", "mode": "upsert", "key_field": "region",
+		"mapping": {"region": "Region", "total": "Total"}
+	}`)
+	integration.Secrets = map[string]string{"service_account": string(serviceAccount)}
+	env := newHarvestEnv(integration)
+	harvests, rows := env.harvests, env.rows
+
+	rows.existing = []dataRowDomain.DataRowInfo{
+		{ID: "row-north", RowIndex: 0, Data: `{"total": "10", "region": "North"}`},
+		{ID: "row-south", RowIndex: 1, Data: `{"region": "South", "total": "20"}`},
+		{ID: "row-west", RowIndex: 2, Data: `{"region": "West", "total": "5"}`},
+	}
+
+	run, err := harvests.Harvest(context.Background(), "connector-1", domain.RunTriggerManual)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if run.Status != string(domain.RunStatusSucceeded) {
+		t.Errorf("Expected status succeeded, got %s (%v)", run.Status, run.Errors)
+	}
+	if run.RecordsFetched != 3 || run.RecordsCreated != 1 || run.RecordsUpdated != 1 {
+		t.Errorf("Expected 3 fetched, 1 created, 1 updated, got %d/%d/%d", run.RecordsFetched, run.RecordsCreated, run.RecordsUpdated)
+	}
+	if len(rows.updated) != 1 || rows.updated[0] != "row-north" {
+		t.Errorf("Expected only row-north to be updated, got %v", rows.updated)
+	}
+	if len(rows.rows) != 1 || rows.rows[0].RowIndex != 3 || rows.rows[0].Data != `{"region":"East","total":null}` {
+		t.Errorf("Expected East appended at index 3, got %+v", rows.rows)
+	}
+}
+
+// Test a BPS harvest creates one dataset per indicator with its table as rows
+// and resolves subjects and units to topics and units
+func TestHarvest_BPSIndicators(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/list/model/var/domain/0000/subject/12/page/1/key/bps-key/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status": "OK", "data-availability": "available", "data": [
+			{"page": 1, "pages": 1, "per_page": 10, "count": 1, "total": 1},
+			[{"var_id": 1975, "title": "Jumlah Penduduk", "sub_id": 12, "sub_name": "Kependudukan",
+			  "def": "Jumlah penduduk pertengahan tahun", "notes": "", "unit": "Ribu Jiwa"}]
+		]}`))
+	})
+	mux.HandleFunc("/api/list/model/data/domain/0000/var/1975/key/bps-key/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status": "OK", "data-availability": "available",
+			"var": [{"val": 1975, "label": "Jumlah Penduduk", "unit": "Ribu Jiwa"}],
+			"turvar": [{"val": 0, "label": "Tidak ada"}],
+			"vervar": [{"val": 1100, "label": "ACEH"}, {"val": 1200, "label": "SUMATERA UTARA"}],
+			"tahun": [{"val": 123, "label": "2023"}, {"val": 124, "label": "2024"}],
+			"turtahun": [{"val": 0, "label": "Tahun"}],
+			"datacontent": {"1100197501230": 5482.5, "1100197501240": 5554.8, "1200197501240": 15588.5}
+		}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	apiKey := "bps-key"
+	integration := newConnectorIntegration(`{
+		"connector": "bps", "url": "` + server.URL + `", "subjects": ["12"], "target": "datasets",
+		"defaults": {"classification": "public", "category": "statistics"}
+	}`)
+	integration.APIKey = &apiKey
+	env := newHarvestEnv(integration)
+	env.units.units = []unitDomain.UnitResponse{{ID: "unit-1", Name: "Ribu Jiwa", Symbol: "rb"}}
+
+	run, err := env.harvests.Harvest(context.Background(), "connector-1", domain.RunTriggerManual)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if run.Status != string(domain.RunStatusSucceeded) || run.RecordsCreated != 1 {
+		t.Fatalf("Expected 1 dataset created, got %s created=%d (%v)", run.Status, run.RecordsCreated, run.Errors)
+	}
+
+	created := env.datasets.created[0]
+	if created.Name != "Jumlah Penduduk" || created.CoverageStart != "2023-01-01" || created.CoverageEnd != "2024-12-31" {
+		t.Errorf("Unexpected dataset: %+v", created)
+	}
+	if created.TopicID != "topic-Kependudukan" || len(env.topics.topics) != 1 {
+		t.Errorf("Expected the subject to create a topic, got topic_id=%s", created.TopicID)
+	}
+	if created.UnitID != "unit-1" || len(env.units.units) != 1 {
+		t.Errorf("Expected the existing unit to be reused, got unit_id=%s", created.UnitID)
+	}
+	if len(env.rows.rows) != 3 {
+		t.Fatalf("Expected 3 rows, got %d", len(env.rows.rows))
+	}
+	if env.rows.rows[0].Data != `{"category":"Tidak ada","period":"Tahun","region":"ACEH","value":5482.5,"year":"2023"}` {
+		t.Errorf("Unexpected first row: %s", env.rows.rows[0].Data)
+	}
+}
+
+// Test an ArcGIS harvest pages through features and keeps their geometry as GeoJSON
+func TestHarvest_ArcGISFeatureRows(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query().Get("resultOffset")+"/"+r.Header.Get("X-Esri-Authorization"))
+		if r.URL.Query().Get("resultOffset") == "0" {
+			w.Write([]byte(`{"type": "FeatureCollection", "properties": {"exceededTransferLimit": true}, "features": [
+				{"type": "Feature", "id": 1, "geometry": {"type": "Point", "coordinates": [107.6, -6.9]}, "properties": {"name": "Bandung"}}
+			]}`))
+			return
+		}
+		w.Write([]byte(`{"type": "FeatureCollection", "features": [
+			{"type": "Feature", "id": 2, "geometry": {"type": "Point", "coordinates": [106.8, -6.6]}, "properties": {"name": "Bogor"}}
+		]}`))
+	}))
+	defer server.Close()
+
+	integration := newConnectorIntegration(`{
+		"connector": "arcgis", "url": "` + server.URL + `/FeatureServer/0", "page_size": 1,
+		"target": "data_rows", "dataset_id": "dataset-1"
+	}`)
+	apiKey := "test-key"
+	integration.APIKey = &apiKey
+	env := newHarvestEnv(integration)
+	rows := env.rows
+
+	run, err := env.harvests.Harvest(context.Background(), "connector-1", domain.RunTriggerManual)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if run.Status != string(domain.RunStatusSucceeded) || len(rows.rows) != 2 {
+		t.Fatalf("Expected 2 rows from 2 pages, got %+v (%d rows)", run, len(rows.rows))
+	}
+	if rows.rows[1].Data != `{"geometry":{"coordinates":[106.8,-6.6],"type":"Point"},"id":2,"name":"Bogor"}` {
+		t.Errorf("Unexpected second row: %s", rows.rows[1].Data)
+	}
+	if len(queries) != 2 || queries[1] != "1/Bearer test-key" {
+		t.Errorf("Expected the second page at offset 1 with the token header, got %v", queries)
+	}
+}
+
+// Test an OData harvest follows next links and drops annotations
+func TestHarvest_ODataNextLinks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("$skiptoken") == "" {
+			w.Write([]byte(`{"@odata.context": "$metadata#Stations", "value": [
+				{"@odata.etag": "W/\"1\"", "Code": "ST1", "Location": {"type": "Point", "coordinates": [107.6, -6.9]}}
+			], "@odata.nextLink": "Stations?$skiptoken=ST1"}`))
+			return
+		}
+		w.Write([]byte(`{"value": [{"Code": "ST2", "Location": null}]}`))
+	}))
+	defer server.Close()
+
+	harvests, _, _, rows := newHarvestFixture(`{
+		"connector": "odata", "url": "` + server.URL + `/odata/Stations",
+		"target": "data_rows", "dataset_id": "dataset-1"
+	}`)
+
+	run, err := harvests.Harvest(context.Background(), "connector-1", domain.RunTriggerManual)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if run.Status != string(domain.RunStatusSucceeded) || len(rows.rows) != 2 {
+		t.Fatalf("Expected 2 rows from 2 pages, got %+v (%d rows)", run, len(rows.rows))
+	}
+	if rows.rows[0].Data != `{"Code":"ST1","Location":{"coordinates":[107.6,-6.9],"type":"Point"}}` {
+		t.Errorf("Unexpected first row: %s", rows.rows[0].Data)
+	}
+}
+
+// Test an S3 sync imports new and changed objects once and ingests CSV rows
+func TestHarvest_S3BucketSync(t *testing.T) {
+	objects := map[string]struct{ etag, body string }{
+		"exports/2026-01.csv": {"etag-1", "region,total\nBandung,10\n"},
+		"exports/notes.txt":   {"etag-2", "monthly export"},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("list-type") == "2" {
+			var contents string
+			for _, key := range []string{"exports/", "exports/2026-01.csv", "exports/notes.txt"} {
+				object := objects[key]
+				contents += fmt.Sprintf(`<Contents><Key>%s</Key><LastModified>2026-02-01T00:00:00.000Z</LastModified>`+
+					`<ETag>"%s"</ETag><Size>%d</Size></Contents>`, key, object.etag, len(object.body))
+			}
+			w.Header().Set("Content-Type", "application/xml")
+			fmt.Fprintf(w, `<ListBucketResult><Name>drops</Name><Prefix>exports/</Prefix><KeyCount>3</KeyCount>`+
+				`<MaxKeys>1000</MaxKeys><IsTruncated>false</IsTruncated>%s</ListBucketResult>`, contents)
+			return
+		}
+
+		object, ok := objects[r.URL.Path[len("/drops/"):]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("ETag", `"`+object.etag+`"`)
+		w.Header().Set("Last-Modified", "Sun, 01 Feb 2026 00:00:00 GMT")
+		w.Header().Set("Content-Length", fmt.Sprint(len(object.body)))
+		w.Write([]byte(object.body))
+	}))
+	defer server.Close()
+
+	integration := newConnectorIntegration(`{
+		"connector": "s3", "url": "` + server.URL + `", "bucket": "drops", "prefix": "exports/",
+		"region": "ap-southeast-3", "dataset_id": "dataset-1", "ingest_rows": true
+	}`)
+	integration.Secrets = map[string]string{"access_key_id": "AKIAEXAMPLE", "secret_access_key": "secret"}
+	env := newHarvestEnv(integration)
+	ctx := context.Background()
+
+	run, err := env.harvests.Harvest(ctx, "connector-1", domain.RunTriggerManual)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if run.Status != string(domain.RunStatusSucceeded) || run.RecordsCreated != 2 {
+		t.Fatalf("Expected 2 imported objects, got %+v", run)
+	}
+	if len(env.files.names) != 2 || env.files.names[0] != "2026-01.csv" || env.files.names[1] != "notes.txt" {
+		t.Errorf("Expected both files imported by name, got %v", env.files.names)
+	}
+	if len(env.rows.rows) != 1 || env.rows.rows[0].Data != `{"region":"Bandung","total":"10"}` {
+		t.Errorf("Expected the CSV row to be ingested, got %+v", env.rows.rows)
+	}
+
+	// Unchanged objects are skipped and a changed object is imported again
+	objects["exports/2026-01.csv"] = struct{ etag, body string }{"etag-3", "region,total\nBandung,12\n"}
+	run, err = env.harvests.Harvest(ctx, "connector-1", domain.RunTriggerManual)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if run.RecordsFetched != 1 || run.RecordsUpdated != 1 || len(env.files.names) != 3 {
+		t.Errorf("Expected only the changed object to be imported, got %+v and files %v", run, env.files.names)
+	}
+	if env.runRepo.objects["exports/2026-01.csv"].ETag != "etag-3" {
+		t.Errorf("Expected the new ETag to be tracked, got %+v", env.runRepo.objects["exports/2026-01.csv"])
+	}
+}
+
+// startSFTPServer serves files from memory over SFTP to the user "etl" with the
+// password "secret" and returns the server address and host key
+func startSFTPServer(t *testing.T, files map[string]string) (string, string) {
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatalf("Expected a host key, got %v", err)
+	}
+	serverConfig := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if conn.User() != "etl" || string(password) != "secret" {
+				return nil, fmt.Errorf("access denied")
+			}
+			return nil, nil
+		},
+	}
+	serverConfig.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Expected a listener, got %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, channels, requests, err := ssh.NewServerConn(conn, serverConfig)
+				if err != nil {
+					return
+				}
+				go ssh.DiscardRequests(requests)
+				for newChannel := range channels {
+					channel, channelRequests, _ := newChannel.Accept()
+					go func() {
+						for req := range channelRequests {
+							req.Reply(req.Type == "subsystem", nil)
+							if req.Type == "subsystem" {
+								go serveSFTP(channel, files)
+							}
+						}
+					}()
+				}
+			}()
+		}
+	}()
+
+	return listener.Addr().String(), string(ssh.MarshalAuthorizedKey(signer.PublicKey()))
+}
+
+// serveSFTP answers the SFTP requests a connector sends for a flat directory
+func serveSFTP(channel ssh.Channel, files map[string]string) {
+	defer channel.Close()
+	u32 := func(v uint32) []byte { return binary.BigEndian.AppendUint32(nil, v) }
+	str := func(s string) []byte { return append(u32(uint32(len(s))), s...) }
+	reply := func(packetType byte, fields ...[]byte) {
+		packet := []byte{packetType}
+		for _, field := range fields {
+			packet = append(packet, field...)
+		}
+		channel.Write(append(u32(uint32(len(packet))), packet...))
+	}
+
+	listed := false
+	for {
+		var header [4]byte
+		if _, err := io.ReadFull(channel, header[:]); err != nil {
+			return
+		}
+		packet := make([]byte, binary.BigEndian.Uint32(header[:]))
+		if _, err := io.ReadFull(channel, packet); err != nil {
+			return
+		}
+		if packet[0] == 1 { // INIT
+			reply(2, u32(3))
+			continue
+		}
+		id, body := packet[1:5], packet[5:]
+		handle := string(body[4 : 4+binary.BigEndian.Uint32(body)])
+
+		switch packet[0] {
+		case 11: // OPENDIR
+			reply(102, id, str("dir"))
+		case 12: // READDIR
+			if listed {
+				reply(101, id, u32(1), str("EOF"), str(""))
+				continue
+			}
+			listed = true
+			entries := []byte{}
+			for name, content := range files {
+				entries = append(entries, str(name)...)
+				entries = append(entries, str(name)...)
+				entries = append(entries, u32(0x1|0x4|0x8)...)
+				entries = binary.BigEndian.AppendUint64(entries, uint64(len(content)))
+				entries = append(entries, u32(0100644)...)
+				entries = append(entries, u32(1767225600)...)
+				entries = append(entries, u32(1767225600)...)
+			}
+			entries = append(entries, str("archive")...)
+			entries = append(entries, str("archive")...)
+			entries = append(entries, u32(0x4)...)
+			entries = append(entries, u32(040755)...)
+			reply(104, id, u32(uint32(len(files)+1)), entries)
+		case 3: // OPEN
+			if _, ok := files[strings.TrimPrefix(handle, "/exports/")]; !ok {
+				reply(101, id, u32(2), str("no such file"), str(""))
+				continue
+			}
+			reply(102, id, str(handle))
+		case 5: // READ
+			content := files[strings.TrimPrefix(handle, "/exports/")]
+			offset := binary.BigEndian.Uint64(body[4+len(handle):])
+			if offset >= uint64(len(content)) {
+				reply(101, id, u32(1), str("EOF"), str(""))
+				continue
+			}
+			reply(103, id, str(content[offset:]))
+		default: // CLOSE
+			reply(101, id, u32(0), str(""), str(""))
+		}
+	}
+}
+
+// Test an SFTP connector imports the files matching its pattern and ingests their rows
+func TestHarvest_SFTPFileSync(t *testing.T) {
+	address, hostKey := startSFTPServer(t, map[string]string{
+		"sales-2026-01.csv": "region,total\nBandung,10\n",
+		"sales-2026-02.csv": "region,total\nBogor,7\n",
+		"readme.txt":        "daily sales exports",
+	})
+
+	config, _ := json.Marshal(map[string]interface{}{
+		"connector": "sftp", "url": "sftp://etl@" + address + "/exports", "pattern": "sales-*.csv",
+		"host_key": hostKey, "dataset_id": "dataset-1", "ingest_rows": true, "mode": "upsert", "key_field": "region",
+	})
+	integration := newConnectorIntegration(string(config))
+	integration.Secrets = map[string]string{"password": "secret"}
+	env := newHarvestEnv(integration)
+
+	run, err := env.harvests.Harvest(context.Background(), "connector-1", domain.RunTriggerManual)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if run.Status != string(domain.RunStatusSucceeded) || run.RecordsCreated != 2 {
+		t.Fatalf("Expected 2 imported files, got %+v", run)
+	}
+	if len(env.files.names) != 2 || env.files.names[0] != "sales-2026-01.csv" || env.files.contents[1] != "region,total\nBogor,7\n" {
+		t.Errorf("Expected both sales exports imported, got %v", env.files.names)
+	}
+	if len(env.rows.rows) != 2 {
+		t.Errorf("Expected a row per file, got %+v", env.rows.rows)
+	}
+	if _, ok := env.runRepo.objects["/exports/sales-2026-01.csv"]; !ok {
+		t.Errorf("Expected files to be tracked by path, got %v", env.runRepo.objects)
+	}
+
+	// A wrong host key is rejected before credentials are sent
+	otherKey, _, _ := ed25519.GenerateKey(rand.Reader)
+	impostor, _ := ssh.NewPublicKey(otherKey)
+	integration.Config = strings.Replace(integration.Config, strings.TrimSpace(hostKey), strings.TrimSpace(string(ssh.MarshalAuthorizedKey(impostor))), 1)
+	run, err = env.harvests.Harvest(context.Background(), "connector-1", domain.RunTriggerManual)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if run.Status != string(domain.RunStatusFailed) {
+		t.Errorf("Expected the run to fail with a wrong host key, got %+v", run)
+	}
+}
@@ -0,0 +1,256 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/infrastructure/logger"
+	"portal-data-backend/internal/integration/domain"
+	templateDomain "portal-data-backend/internal/message_template/domain"
+	notifDomain "portal-data-backend/internal/notification/domain"
+)
+
+// RunObserver is told about every finished harvest and push run
+type RunObserver interface {
+	RunFinished(ctx context.Context, integration *domain.Integration, run *domain.Run)
+}
+
+// NotificationSender is the part of the notification module health alerts are sent through
+type NotificationSender interface {
+	Create(ctx context.Context, req *notifDomain.CreateNotificationRequest) (*notifDomain.NotificationInfo, error)
+}
+
+// MessageRenderer renders the wording of the health alerts from the templates
+// of the message template module
+type MessageRenderer interface {
+	Render(ctx context.Context, channel templateDomain.Channel, key string, vars map[string]string) (*templateDomain.Message, error)
+}
+
+// HealthUsecase reports the run health of connector and publisher integrations
+// and alerts their owner, webhooks and chat channels when one keeps failing
+type HealthUsecase interface {
+	Health(ctx context.Context, organizationID *string) (*domain.HealthResponse, error)
+	// RunFinished raises the failing alert when a run completes a streak of
+	// FailureThreshold failed runs, and the recovered alert when a run ends one
+	RunFinished(ctx context.Context, integration *domain.Integration, run *domain.Run)
+}
+
+type healthUsecase struct {
+	repo          domain.Repository
+	runRepo       domain.RunRepository
+	events        domain.EventPublisher
+	notifications NotificationSender
+	messages      MessageRenderer
+	cfg           config.SchedulerConfig
+}
+
+// NewHealthUsecase creates the integration health usecase. events and
+// notifications may be nil; notifications are worded by messages.
+func NewHealthUsecase(repo domain.Repository, runRepo domain.RunRepository, events domain.EventPublisher, notifications NotificationSender, messages MessageRenderer, cfg config.SchedulerConfig) HealthUsecase {
+	if cfg.HealthWindow < 1 {
+		cfg.HealthWindow = 1
+	}
+
+	return &healthUsecase{
+		repo:          repo,
+		runRepo:       runRepo,
+		events:        events,
+		notifications: notifications,
+		messages:      messages,
+		cfg:           cfg,
+	}
+}
+
+// healthStateOrder lists the health states from the most to the least urgent
+var healthStateOrder = map[string]int{
+	string(domain.HealthStateFailing):  0,
+	string(domain.HealthStateDegraded): 1,
+	string(domain.HealthStateUnknown):  2,
+	string(domain.HealthStateHealthy):  3,
+}
+
+func (u *healthUsecase) Health(ctx context.Context, organizationID *string) (*domain.HealthResponse, error) {
+	filter := &domain.IntegrationFilter{OrganizationID: organizationID}
+	integrations, _, err := u.repo.List(ctx, filter, 1000, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list integrations: %w", err)
+	}
+
+	runs, err := u.runRepo.ListLatestRuns(ctx, u.cfg.HealthWindow)
+	if err != nil {
+		return nil, err
+	}
+	runsByIntegration := make(map[string][]*domain.Run)
+	for _, run := range runs {
+		runsByIntegration[run.IntegrationID] = append(runsByIntegration[run.IntegrationID], run)
+	}
+
+	resp := &domain.HealthResponse{
+		Integrations:     []domain.IntegrationHealth{},
+		States:           make(map[string]int),
+		FailureThreshold: u.cfg.FailureThreshold,
+		RunWindow:        u.cfg.HealthWindow,
+	}
+	for _, integration := range integrations {
+		if !schedulable(integration) {
+			continue
+		}
+		health := u.summarize(integration, runsByIntegration[integration.ID])
+		resp.Integrations = append(resp.Integrations, *health)
+		resp.States[health.State]++
+	}
+
+	sort.SliceStable(resp.Integrations, func(i, j int) bool {
+		a, b := resp.Integrations[i], resp.Integrations[j]
+		if a.State != b.State {
+			return healthStateOrder[a.State] < healthStateOrder[b.State]
+		}
+		return a.Name < b.Name
+	})
+
+	return resp, nil
+}
+
+func (u *healthUsecase) RunFinished(ctx context.Context, integration *domain.Integration, run *domain.Run) {
+	if u.cfg.FailureThreshold < 1 {
+		return
+	}
+
+	// The finished run is the newest; one more run shows whether a streak ended
+	runs, _, err := u.runRepo.ListRuns(ctx, integration.ID, u.cfg.FailureThreshold+1, 0)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to check the health of integration %s: %v", integration.ID, err)
+		return
+	}
+
+	if run.Status == string(domain.RunStatusFailed) {
+		if consecutiveFailures(runs) == u.cfg.FailureThreshold {
+			u.alert(ctx, integration, runs, domain.EventIntegrationFailing)
+		}
+		return
+	}
+	if len(runs) > 1 && consecutiveFailures(runs[1:]) >= u.cfg.FailureThreshold {
+		u.alert(ctx, integration, runs, domain.EventIntegrationRecovered)
+	}
+}
+
+// alert publishes a health event and notifies the owner of the integration
+func (u *healthUsecase) alert(ctx context.Context, integration *domain.Integration, runs []*domain.Run, eventType string) {
+	health := u.summarize(integration, runs)
+
+	if u.events != nil {
+		if err := u.events.Publish(ctx, eventType, health); err != nil {
+			logger.FromContext(ctx).Error("failed to publish %s event: %v", eventType, err)
+		}
+	}
+
+	if u.notifications == nil || integration.CreatedBy == "" {
+		return
+	}
+	key, notifType := MessageIntegrationRecovered, notifDomain.NotificationTypeSuccess
+	vars := map[string]string{"name": integration.Name}
+	if eventType == domain.EventIntegrationFailing {
+		key, notifType = MessageIntegrationFailing, notifDomain.NotificationTypeError
+		vars["failures"] = strconv.Itoa(health.ConsecutiveFailures)
+		vars["error"] = ""
+		if health.LastError != nil {
+			vars["error"] = *health.LastError
+		}
+	} else {
+		vars["failures"] = strconv.Itoa(consecutiveFailures(runs[1:]))
+	}
+	msg, err := u.messages.Render(ctx, templateDomain.ChannelNotification, key, vars)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to notify the owner of integration %s: %v", integration.ID, err)
+		return
+	}
+
+	req := &notifDomain.CreateNotificationRequest{
+		UserID:   integration.CreatedBy,
+		Title:    msg.Subject,
+		Message:  msg.Body,
+		Type:     string(notifType),
+		Category: string(notifDomain.NotificationCategorySystem),
+	}
+	if _, err := u.notifications.Create(ctx, req); err != nil {
+		logger.FromContext(ctx).Error("failed to notify the owner of integration %s: %v", integration.ID, err)
+	}
+}
+
+// summarize computes the health of an integration from its recent runs,
+// newest first
+func (u *healthUsecase) summarize(integration *domain.Integration, runs []*domain.Run) *domain.IntegrationHealth {
+	health := &domain.IntegrationHealth{
+		IntegrationID:       integration.ID,
+		Name:                integration.Name,
+		Type:                integration.Type,
+		Status:              integration.Status,
+		State:               string(domain.HealthStateUnknown),
+		Running:             len(runs) > 0 && runs[0].FinishedAt == nil,
+		ConsecutiveFailures: consecutiveFailures(runs),
+		NextRunAt:           integration.NextRunAt,
+	}
+
+	var finished int
+	var total int64
+	for _, run := range runs {
+		if run.FinishedAt == nil {
+			continue
+		}
+		duration := run.FinishedAt.Sub(run.StartedAt).Milliseconds()
+		if finished == 0 {
+			health.LastRunStatus = &run.Status
+			health.LastRunAt = run.FinishedAt
+			health.LastError = firstRunError(run)
+			health.LastDurationMs = duration
+		}
+		if duration > health.MaxDurationMs {
+			health.MaxDurationMs = duration
+		}
+		total += duration
+		finished++
+	}
+	if finished == 0 {
+		return health
+	}
+	health.AvgDurationMs = total / int64(finished)
+
+	switch {
+	case u.cfg.FailureThreshold > 0 && health.ConsecutiveFailures >= u.cfg.FailureThreshold:
+		health.State = string(domain.HealthStateFailing)
+	case *health.LastRunStatus == string(domain.RunStatusFailed) || *health.LastRunStatus == string(domain.RunStatusPartial):
+		health.State = string(domain.HealthStateDegraded)
+	default:
+		health.State = string(domain.HealthStateHealthy)
+	}
+	return health
+}
+
+// consecutiveFailures counts the failed runs since the last run that did not
+// fail. Runs still in progress are skipped.
+func consecutiveFailures(runs []*domain.Run) int {
+	failures := 0
+	for _, run := range runs {
+		if run.FinishedAt == nil {
+			continue
+		}
+		if run.Status != string(domain.RunStatusFailed) {
+			break
+		}
+		failures++
+	}
+	return failures
+}
+
+// firstRunError returns the first error recorded on a run
+func firstRunError(run *domain.Run) *string {
+	var runErrors []string
+	if err := json.Unmarshal([]byte(run.Errors), &runErrors); err != nil || len(runErrors) == 0 {
+		return nil
+	}
+	return &runErrors[0]
+}
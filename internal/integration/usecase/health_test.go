@@ -0,0 +1,158 @@
+package usecase_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/internal/integration/domain"
+	"portal-data-backend/internal/integration/usecase"
+	templateDomain "portal-data-backend/internal/message_template/domain"
+	notifDomain "portal-data-backend/internal/notification/domain"
+)
+
+// mockEventRecorder records the events published through it
+type mockEventRecorder struct {
+	events []string
+}
+
+func (m *mockEventRecorder) Publish(ctx context.Context, eventType string, data interface{}) error {
+	m.events = append(m.events, eventType)
+	return nil
+}
+
+// mockNotificationSender records the notifications sent through it
+type mockNotificationSender struct {
+	sent []*notifDomain.CreateNotificationRequest
+}
+
+func (m *mockNotificationSender) Create(ctx context.Context, req *notifDomain.CreateNotificationRequest) (*notifDomain.NotificationInfo, error) {
+	m.sent = append(m.sent, req)
+	return &notifDomain.NotificationInfo{UserID: req.UserID, Title: req.Title}, nil
+}
+
+// mockMessageRenderer renders the built-in wording of the messages
+type mockMessageRenderer struct{}
+
+func (mockMessageRenderer) Render(ctx context.Context, channel templateDomain.Channel, key string, vars map[string]string) (*templateDomain.Message, error) {
+	for _, def := range usecase.Messages {
+		if def.Channel == channel && def.Key == key {
+			return templateDomain.Render(def.Subject, def.Body, vars)
+		}
+	}
+	return nil, fmt.Errorf("undefined message %s", key)
+}
+
+func newFinishedRun(integrationID string, status domain.RunStatus, startedAt time.Time, duration time.Duration) *domain.Run {
+	finishedAt := startedAt.Add(duration)
+	runErrors := "[]"
+	if status == domain.RunStatusFailed {
+		runErrors = `["fetch failed: connection refused"]`
+	}
+	return &domain.Run{
+		ID:            integrationID + "-" + startedAt.Format(time.RFC3339),
+		IntegrationID: integrationID,
+		Status:        string(status),
+		Errors:        runErrors,
+		StartedAt:     startedAt,
+		FinishedAt:    &finishedAt,
+	}
+}
+
+// Test Health reports state, failures and latency of run-based integrations only
+func TestHealth_SummarizesRecentRuns(t *testing.T) {
+	integrations := map[string]*domain.Integration{}
+	for _, id := range []string{"flaky", "broken", "new"} {
+		integration := newConnectorIntegration(`{"connector": "rest", "url": "http://example.org", "target": "datasets"}`)
+		integration.ID = id
+		integration.Name = id
+		integrations[id] = integration
+	}
+	integrations["hook"] = &domain.Integration{ID: "hook", Name: "hook", Type: string(domain.IntegrationTypeWebhook)}
+
+	start := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	runRepo := &mockRunRepository{runs: []*domain.Run{
+		newFinishedRun("flaky", domain.RunStatusSucceeded, start, 2*time.Second),
+		newFinishedRun("flaky", domain.RunStatusFailed, start.Add(time.Hour), 4*time.Second),
+		newFinishedRun("broken", domain.RunStatusSucceeded, start, time.Second),
+		newFinishedRun("broken", domain.RunStatusFailed, start.Add(time.Hour), time.Second),
+		newFinishedRun("broken", domain.RunStatusFailed, start.Add(2*time.Hour), time.Second),
+		newFinishedRun("broken", domain.RunStatusFailed, start.Add(3*time.Hour), time.Second),
+	}}
+
+	cfg := config.SchedulerConfig{FailureThreshold: 3, HealthWindow: 10}
+	health := usecase.NewHealthUsecase(&mockIntegrationRepository{integrations: integrations}, runRepo, nil, nil, mockMessageRenderer{}, cfg)
+
+	resp, err := health.Health(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(resp.Integrations) != 3 {
+		t.Fatalf("Expected 3 integrations, got %d", len(resp.Integrations))
+	}
+
+	broken, flaky, unknown := resp.Integrations[0], resp.Integrations[1], resp.Integrations[2]
+	if broken.IntegrationID != "broken" || broken.State != string(domain.HealthStateFailing) || broken.ConsecutiveFailures != 3 {
+		t.Errorf("Expected broken to be failing after 3 failures, got %+v", broken)
+	}
+	if broken.LastError == nil || *broken.LastError != "fetch failed: connection refused" {
+		t.Errorf("Expected the last error of broken, got %v", broken.LastError)
+	}
+	if flaky.State != string(domain.HealthStateDegraded) || flaky.AvgDurationMs != 3000 || flaky.LastDurationMs != 4000 || flaky.MaxDurationMs != 4000 {
+		t.Errorf("Expected flaky to be degraded with 3s average latency, got %+v", flaky)
+	}
+	if unknown.IntegrationID != "new" || unknown.State != string(domain.HealthStateUnknown) || unknown.LastRunStatus != nil {
+		t.Errorf("Expected new to have an unknown state, got %+v", unknown)
+	}
+	if resp.States["failing"] != 1 || resp.States["degraded"] != 1 || resp.States["unknown"] != 1 {
+		t.Errorf("Expected one integration per state, got %v", resp.States)
+	}
+}
+
+// Test a failure streak raises one failing alert and its end one recovered alert
+func TestHealth_AlertsOnConsecutiveFailures(t *testing.T) {
+	integration := newConnectorIntegration(`{"connector": "rest", "url": "http://example.org", "target": "datasets"}`)
+	runRepo := &mockRunRepository{}
+	events := &mockEventRecorder{}
+	notifications := &mockNotificationSender{}
+
+	cfg := config.SchedulerConfig{FailureThreshold: 2, HealthWindow: 10}
+	health := usecase.NewHealthUsecase(&mockIntegrationRepository{}, runRepo, events, notifications, mockMessageRenderer{}, cfg)
+
+	start := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	statuses := []domain.RunStatus{domain.RunStatusFailed, domain.RunStatusFailed, domain.RunStatusFailed, domain.RunStatusSucceeded}
+	var published [][]string
+	for i, status := range statuses {
+		run := newFinishedRun(integration.ID, status, start.Add(time.Duration(i)*time.Hour), time.Second)
+		runRepo.runs = append(runRepo.runs, run)
+		health.RunFinished(context.Background(), integration, run)
+		published = append(published, append([]string(nil), events.events...))
+	}
+
+	if len(published[0]) != 0 {
+		t.Errorf("Expected no alert below the threshold, got %v", published[0])
+	}
+	if len(published[1]) != 1 || published[1][0] != domain.EventIntegrationFailing {
+		t.Errorf("Expected a failing alert at the threshold, got %v", published[1])
+	}
+	if len(published[2]) != 1 {
+		t.Errorf("Expected no repeated alert while failing, got %v", published[2])
+	}
+	if len(published[3]) != 2 || published[3][1] != domain.EventIntegrationRecovered {
+		t.Errorf("Expected a recovered alert, got %v", published[3])
+	}
+
+	if len(notifications.sent) != 2 {
+		t.Fatalf("Expected 2 notifications, got %d", len(notifications.sent))
+	}
+	failing := notifications.sent[0]
+	if failing.UserID != integration.CreatedBy || failing.Type != "error" || !strings.Contains(failing.Message, "connection refused") {
+		t.Errorf("Expected an error notification for the owner, got %+v", failing)
+	}
+	if notifications.sent[1].Type != "success" {
+		t.Errorf("Expected a success notification on recovery, got %+v", notifications.sent[1])
+	}
+}
@@ -0,0 +1,487 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/infrastructure/db"
+	"portal-data-backend/internal/integration/connector"
+	"portal-data-backend/internal/integration/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// ingestTokenPrefix marks ingest tokens so they are recognisable in logs and secret scanners
+const ingestTokenPrefix = "ing_"
+
+// IngestUsecase receives records pushed by external systems to the ingest
+// endpoint of inbound integrations and writes them as dataset rows
+type IngestUsecase interface {
+	// Ingest authenticates token, applies the field-mapping template of the
+	// integration to payload and returns the validation report. A payload
+	// repeating an idempotency key returns the report of the first request.
+	Ingest(ctx context.Context, integrationID, token string, payload *domain.IngestPayload) (*domain.IngestReport, error)
+
+	CreateToken(ctx context.Context, integrationID string, req *domain.CreateIngestTokenRequest, userID string) (*domain.IngestTokenCreated, error)
+	ListTokens(ctx context.Context, integrationID string) ([]*domain.IngestTokenInfo, error)
+	RevokeToken(ctx context.Context, integrationID, tokenID string) error
+}
+
+type ingestUsecase struct {
+	repo       domain.Repository
+	ingestRepo domain.IngestRepository
+	tx         db.Transactor
+	rows       DataRowWriter
+	cfg        config.HarvestConfig
+	now        func() time.Time
+}
+
+func NewIngestUsecase(repo domain.Repository, ingestRepo domain.IngestRepository, tx db.Transactor, rows DataRowWriter, cfg config.HarvestConfig) IngestUsecase {
+	return &ingestUsecase{
+		repo:       repo,
+		ingestRepo: ingestRepo,
+		tx:         tx,
+		rows:       rows,
+		cfg:        cfg,
+		now:        time.Now,
+	}
+}
+
+func (u *ingestUsecase) Ingest(ctx context.Context, integrationID, token string, payload *domain.IngestPayload) (*domain.IngestReport, error) {
+	integration, err := u.authenticate(ctx, integrationID, token)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := parseIngestConfig(integration)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := u.parsePayload(cfg, payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid payload: %v", pkgErrors.ErrInvalidInput, err)
+	}
+
+	ingestion, replay, err := u.begin(ctx, integration.ID, payload.IdempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+	if replay != nil {
+		return replay, nil
+	}
+
+	report, err := u.write(ctx, integration, cfg, records)
+	completedAt := u.now()
+	ingestion.CompletedAt = &completedAt
+	if err != nil {
+		ingestion.Status = string(domain.IngestionStatusFailed)
+		_ = u.ingestRepo.UpdateIngestion(ctx, ingestion)
+		return nil, err
+	}
+
+	report.IngestionID = ingestion.ID
+	encoded, err := json.Marshal(report)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode report: %w", err)
+	}
+	data := string(encoded)
+	ingestion.Status = string(domain.IngestionStatusCompleted)
+	ingestion.Report = &data
+	if err := u.ingestRepo.UpdateIngestion(ctx, ingestion); err != nil {
+		return nil, fmt.Errorf("failed to update ingestion: %w", err)
+	}
+
+	return report, nil
+}
+
+func (u *ingestUsecase) CreateToken(ctx context.Context, integrationID string, req *domain.CreateIngestTokenRequest, userID string) (*domain.IngestTokenCreated, error) {
+	integration, err := u.repo.GetByID(ctx, integrationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get integration: %w", err)
+	}
+	if integration.Type != string(domain.IntegrationTypeInbound) {
+		return nil, fmt.Errorf("%w: ingest tokens require an inbound integration", pkgErrors.ErrInvalidInput)
+	}
+
+	now := u.now()
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+		return nil, fmt.Errorf("%w: expires_at must be in the future", pkgErrors.ErrInvalidInput)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+	raw := ingestTokenPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	token := &domain.IngestToken{
+		ID:            uuid.New().String(),
+		IntegrationID: integration.ID,
+		Name:          req.Name,
+		TokenHash:     hashIngestToken(raw),
+		Prefix:        raw[:len(ingestTokenPrefix)+6],
+		ExpiresAt:     req.ExpiresAt,
+		CreatedBy:     userID,
+		CreatedAt:     now,
+	}
+
+	if err := u.ingestRepo.CreateToken(ctx, token); err != nil {
+		return nil, fmt.Errorf("failed to create ingest token: %w", err)
+	}
+
+	return &domain.IngestTokenCreated{IngestTokenInfo: *toIngestTokenInfo(token), Token: raw}, nil
+}
+
+func (u *ingestUsecase) ListTokens(ctx context.Context, integrationID string) ([]*domain.IngestTokenInfo, error) {
+	if _, err := u.repo.GetByID(ctx, integrationID); err != nil {
+		return nil, fmt.Errorf("failed to get integration: %w", err)
+	}
+
+	tokens, err := u.ingestRepo.ListTokens(ctx, integrationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ingest tokens: %w", err)
+	}
+
+	infos := make([]*domain.IngestTokenInfo, len(tokens))
+	for i, token := range tokens {
+		infos[i] = toIngestTokenInfo(token)
+	}
+	return infos, nil
+}
+
+func (u *ingestUsecase) RevokeToken(ctx context.Context, integrationID, tokenID string) error {
+	if err := u.ingestRepo.RevokeToken(ctx, integrationID, tokenID, u.now()); err != nil {
+		return fmt.Errorf("failed to revoke ingest token: %w", err)
+	}
+	return nil
+}
+
+// authenticate returns the integration when token is a live ingest token of it.
+// Every failure is reported as unauthorized so callers cannot probe for integrations.
+func (u *ingestUsecase) authenticate(ctx context.Context, integrationID, token string) (*domain.Integration, error) {
+	if token == "" {
+		return nil, pkgErrors.ErrUnauthorized
+	}
+
+	stored, err := u.ingestRepo.GetTokenByHash(ctx, hashIngestToken(token))
+	if err != nil {
+		if errors.Is(err, pkgErrors.ErrNotFound) {
+			return nil, pkgErrors.ErrUnauthorized
+		}
+		return nil, fmt.Errorf("failed to get ingest token: %w", err)
+	}
+
+	now := u.now()
+	if stored.IntegrationID != integrationID || stored.RevokedAt != nil ||
+		(stored.ExpiresAt != nil && !stored.ExpiresAt.After(now)) {
+		return nil, pkgErrors.ErrUnauthorized
+	}
+
+	integration, err := u.repo.GetByID(ctx, integrationID)
+	if err != nil {
+		if errors.Is(err, pkgErrors.ErrNotFound) {
+			return nil, pkgErrors.ErrUnauthorized
+		}
+		return nil, fmt.Errorf("failed to get integration: %w", err)
+	}
+	if integration.Type != string(domain.IntegrationTypeInbound) {
+		return nil, pkgErrors.ErrUnauthorized
+	}
+	if integration.Status != string(domain.IntegrationStatusActive) {
+		return nil, fmt.Errorf("%w: integration is not active", pkgErrors.ErrForbidden)
+	}
+
+	_ = u.ingestRepo.TouchToken(ctx, stored.ID, now)
+	return integration, nil
+}
+
+// begin records a new ingestion. When the idempotency key was already used it
+// returns the stored report instead, or ErrAlreadyExists while the first
+// request is still processing.
+func (u *ingestUsecase) begin(ctx context.Context, integrationID, idempotencyKey string) (*domain.Ingestion, *domain.IngestReport, error) {
+	ingestion := &domain.Ingestion{
+		ID:            uuid.New().String(),
+		IntegrationID: integrationID,
+		Status:        string(domain.IngestionStatusProcessing),
+		CreatedAt:     u.now(),
+	}
+	if idempotencyKey == "" {
+		if _, err := u.ingestRepo.CreateIngestion(ctx, ingestion); err != nil {
+			return nil, nil, err
+		}
+		return ingestion, nil, nil
+	}
+
+	ingestion.IdempotencyKey = &idempotencyKey
+	created, err := u.ingestRepo.CreateIngestion(ctx, ingestion)
+	if err != nil {
+		return nil, nil, err
+	}
+	if created {
+		return ingestion, nil, nil
+	}
+
+	existing, err := u.ingestRepo.GetIngestionByKey(ctx, integrationID, idempotencyKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get ingestion: %w", err)
+	}
+
+	switch existing.Status {
+	case string(domain.IngestionStatusCompleted):
+		var report domain.IngestReport
+		if existing.Report == nil || json.Unmarshal([]byte(*existing.Report), &report) != nil {
+			return nil, nil, fmt.Errorf("stored report of ingestion %s is unreadable", existing.ID)
+		}
+		report.Replayed = true
+		return nil, &report, nil
+	case string(domain.IngestionStatusFailed):
+		// Nothing was committed by a failed ingestion, so the key may be retried
+		existing.Status = string(domain.IngestionStatusProcessing)
+		existing.CompletedAt = nil
+		if err := u.ingestRepo.UpdateIngestion(ctx, existing); err != nil {
+			return nil, nil, fmt.Errorf("failed to update ingestion: %w", err)
+		}
+		return existing, nil, nil
+	default:
+		return nil, nil, fmt.Errorf("%w: a request with this idempotency key is still processing", pkgErrors.ErrAlreadyExists)
+	}
+}
+
+// parsePayload decodes a CSV or JSON payload. A single JSON object is one record.
+func (u *ingestUsecase) parsePayload(cfg *domain.IngestConfig, payload *domain.IngestPayload) ([]connector.Record, error) {
+	if strings.Contains(strings.ToLower(payload.ContentType), "csv") {
+		return connector.ParseCSV(payload.Body, cfg.Delimiter, u.cfg.MaxRecords)
+	}
+
+	body := bytes.TrimSpace(payload.Body)
+	if cfg.RecordsPath == "" && len(body) > 0 && body[0] == '{' {
+		var record connector.Record
+		if err := json.Unmarshal(body, &record); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		return []connector.Record{record}, nil
+	}
+	return connector.ParseJSON(body, cfg.RecordsPath, u.cfg.MaxRecords)
+}
+
+// write validates the mapped records and writes the valid rows according to the ingest mode
+func (u *ingestUsecase) write(ctx context.Context, integration *domain.Integration, cfg *domain.IngestConfig, records []connector.Record) (*domain.IngestReport, error) {
+	report := &domain.IngestReport{Received: len(records), Errors: []domain.IngestRowError{}}
+
+	var rows []map[string]interface{}
+	var positions []int
+	for i, record := range records {
+		row := mapRowFields(cfg.Mapping, cfg.Defaults, record)
+		if rowErrors := validateIngestRow(cfg, i, row); len(rowErrors) > 0 {
+			report.Rejected++
+			addIngestErrors(report, rowErrors...)
+			continue
+		}
+		rows = append(rows, row)
+		positions = append(positions, i)
+	}
+
+	if report.Rejected > 0 && cfg.RejectOnError {
+		report.Rejected = report.Received
+		report.Result = domain.IngestResultRejected
+		return report, nil
+	}
+
+	writer := &rowWriter{rows: u.rows, tx: u.tx, userID: integration.CreatedBy, datasetID: cfg.DatasetID}
+	var result *rowResult
+	var err error
+	switch cfg.Mode {
+	case domain.IngestModeReplace:
+		result, err = writer.replace(ctx, rows)
+	case domain.IngestModeUpsert:
+		result, err = writer.upsert(ctx, cfg.KeyField, rows)
+	default:
+		result, err = writer.append(ctx, rows)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write rows: %w", err)
+	}
+
+	failed := make([]int, 0, len(result.Failed))
+	for i := range result.Failed {
+		failed = append(failed, i)
+	}
+	sort.Ints(failed)
+	for _, i := range failed {
+		report.Rejected++
+		addIngestErrors(report, domain.IngestRowError{Row: positions[i], Message: result.Failed[i]})
+	}
+	report.Accepted = report.Received - report.Rejected
+	report.Created = result.Created
+	report.Updated = result.Updated
+
+	switch {
+	case report.Rejected == 0:
+		report.Result = domain.IngestResultAccepted
+	case report.Accepted == 0:
+		report.Result = domain.IngestResultRejected
+	default:
+		report.Result = domain.IngestResultPartial
+	}
+	return report, nil
+}
+
+// validateIngestRow checks the required columns of row and converts typed
+// columns in place
+func validateIngestRow(cfg *domain.IngestConfig, position int, row map[string]interface{}) []domain.IngestRowError {
+	var rowErrors []domain.IngestRowError
+	for _, column := range cfg.Required {
+		if isEmptyValue(row[column]) {
+			rowErrors = append(rowErrors, domain.IngestRowError{Row: position, Field: column, Message: "is required"})
+		}
+	}
+
+	for column, fieldType := range cfg.Types {
+		value, ok := row[column]
+		if !ok || isEmptyValue(value) {
+			continue
+		}
+		converted, err := coerceField(value, fieldType)
+		if err != nil {
+			rowErrors = append(rowErrors, domain.IngestRowError{Row: position, Field: column, Message: err.Error()})
+			continue
+		}
+		row[column] = converted
+	}
+	return rowErrors
+}
+
+// coerceField converts value to fieldType. Strings are parsed, so CSV payloads
+// validate the same way as JSON payloads.
+func coerceField(value interface{}, fieldType domain.FieldType) (interface{}, error) {
+	text, isText := value.(string)
+	text = strings.TrimSpace(text)
+
+	switch fieldType {
+	case domain.FieldTypeNumber:
+		if number, ok := value.(float64); ok {
+			return number, nil
+		}
+		if number, err := strconv.ParseFloat(text, 64); isText && err == nil {
+			return number, nil
+		}
+		return nil, fmt.Errorf("must be a number")
+	case domain.FieldTypeInteger:
+		if number, ok := value.(float64); ok && number == math.Trunc(number) {
+			return int64(number), nil
+		}
+		if number, err := strconv.ParseInt(text, 10, 64); isText && err == nil {
+			return number, nil
+		}
+		return nil, fmt.Errorf("must be an integer")
+	case domain.FieldTypeBoolean:
+		if flag, ok := value.(bool); ok {
+			return flag, nil
+		}
+		if flag, err := strconv.ParseBool(text); isText && err == nil {
+			return flag, nil
+		}
+		return nil, fmt.Errorf("must be a boolean")
+	case domain.FieldTypeDate:
+		if isText {
+			if _, err := time.Parse("2006-01-02", text); err == nil {
+				return text, nil
+			}
+			if _, err := time.Parse(time.RFC3339, text); err == nil {
+				return text, nil
+			}
+		}
+		return nil, fmt.Errorf("must be a date (YYYY-MM-DD or RFC 3339)")
+	default:
+		if isText {
+			return value, nil
+		}
+		return fmt.Sprint(value), nil
+	}
+}
+
+// addIngestErrors appends to the report errors, keeping at most maxRunErrors
+func addIngestErrors(report *domain.IngestReport, rowErrors ...domain.IngestRowError) {
+	for _, rowError := range rowErrors {
+		if len(report.Errors) >= maxRunErrors {
+			return
+		}
+		report.Errors = append(report.Errors, rowError)
+	}
+}
+
+func isEmptyValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	text, ok := value.(string)
+	return ok && strings.TrimSpace(text) == ""
+}
+
+// parseIngestConfig decodes and validates the field-mapping template of an inbound integration
+func parseIngestConfig(integration *domain.Integration) (*domain.IngestConfig, error) {
+	var cfg domain.IngestConfig
+	if err := json.Unmarshal([]byte(integration.Config), &cfg); err != nil {
+		return nil, fmt.Errorf("%w: invalid ingest config: %v", pkgErrors.ErrInvalidInput, err)
+	}
+
+	if cfg.DatasetID == "" {
+		return nil, fmt.Errorf("%w: inbound integration requires a dataset_id", pkgErrors.ErrInvalidInput)
+	}
+
+	switch cfg.Mode {
+	case "":
+		cfg.Mode = domain.IngestModeAppend
+	case domain.IngestModeAppend, domain.IngestModeReplace:
+	case domain.IngestModeUpsert:
+		if cfg.KeyField == "" {
+			return nil, fmt.Errorf("%w: upsert mode requires a key_field", pkgErrors.ErrInvalidInput)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported ingest mode %q", pkgErrors.ErrInvalidInput, cfg.Mode)
+	}
+
+	for column, fieldType := range cfg.Types {
+		switch fieldType {
+		case domain.FieldTypeString, domain.FieldTypeNumber, domain.FieldTypeInteger,
+			domain.FieldTypeBoolean, domain.FieldTypeDate:
+		default:
+			return nil, fmt.Errorf("%w: unsupported type %q for %s", pkgErrors.ErrInvalidInput, fieldType, column)
+		}
+	}
+
+	return &cfg, nil
+}
+
+func hashIngestToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func toIngestTokenInfo(token *domain.IngestToken) *domain.IngestTokenInfo {
+	return &domain.IngestTokenInfo{
+		ID:         token.ID,
+		Name:       token.Name,
+		Prefix:     token.Prefix,
+		LastUsedAt: token.LastUsedAt,
+		ExpiresAt:  token.ExpiresAt,
+		RevokedAt:  token.RevokedAt,
+		CreatedBy:  token.CreatedBy,
+		CreatedAt:  token.CreatedAt,
+	}
+}
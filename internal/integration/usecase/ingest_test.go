@@ -0,0 +1,214 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/internal/integration/domain"
+	"portal-data-backend/internal/integration/usecase"
+	pkgerrors "portal-data-backend/pkg/errors"
+)
+
+// mockIngestRepository is an in-memory implementation of IngestRepository
+type mockIngestRepository struct {
+	tokens     map[string]*domain.IngestToken
+	ingestions map[string]*domain.Ingestion
+}
+
+func (m *mockIngestRepository) CreateToken(ctx context.Context, token *domain.IngestToken) error {
+	m.tokens[token.TokenHash] = token
+	return nil
+}
+
+func (m *mockIngestRepository) ListTokens(ctx context.Context, integrationID string) ([]*domain.IngestToken, error) {
+	var tokens []*domain.IngestToken
+	for _, token := range m.tokens {
+		if token.IntegrationID == integrationID {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens, nil
+}
+
+func (m *mockIngestRepository) GetTokenByHash(ctx context.Context, tokenHash string) (*domain.IngestToken, error) {
+	token, ok := m.tokens[tokenHash]
+	if !ok {
+		return nil, pkgerrors.ErrNotFound
+	}
+	return token, nil
+}
+
+func (m *mockIngestRepository) RevokeToken(ctx context.Context, integrationID, id string, at time.Time) error {
+	for _, token := range m.tokens {
+		if token.ID == id && token.IntegrationID == integrationID {
+			token.RevokedAt = &at
+			return nil
+		}
+	}
+	return pkgerrors.ErrNotFound
+}
+
+func (m *mockIngestRepository) TouchToken(ctx context.Context, id string, at time.Time) error {
+	return nil
+}
+
+func (m *mockIngestRepository) CreateIngestion(ctx context.Context, ingestion *domain.Ingestion) (bool, error) {
+	if ingestion.IdempotencyKey != nil {
+		if _, ok := m.ingestions[*ingestion.IdempotencyKey]; ok {
+			return false, nil
+		}
+		m.ingestions[*ingestion.IdempotencyKey] = ingestion
+	}
+	return true, nil
+}
+
+func (m *mockIngestRepository) GetIngestionByKey(ctx context.Context, integrationID, idempotencyKey string) (*domain.Ingestion, error) {
+	ingestion, ok := m.ingestions[idempotencyKey]
+	if !ok {
+		return nil, pkgerrors.ErrNotFound
+	}
+	return ingestion, nil
+}
+
+func (m *mockIngestRepository) UpdateIngestion(ctx context.Context, ingestion *domain.Ingestion) error {
+	return nil
+}
+
+// newIngestFixture returns the usecase with an inbound integration "inbound-1"
+// and a second integration "inbound-2", and a token created for each
+func newIngestFixture(t *testing.T, ingestConfig string) (usecase.IngestUsecase, *mockDataRowWriter, string, string) {
+	repo := &mockIntegrationRepository{integrations: map[string]*domain.Integration{}}
+	for _, id := range []string{"inbound-1", "inbound-2"} {
+		repo.integrations[id] = &domain.Integration{
+			ID:        id,
+			Type:      string(domain.IntegrationTypeInbound),
+			Status:    string(domain.IntegrationStatusActive),
+			Config:    ingestConfig,
+			CreatedBy: "user-1",
+		}
+	}
+
+	rows := &mockDataRowWriter{}
+	ingestRepo := &mockIngestRepository{tokens: make(map[string]*domain.IngestToken), ingestions: make(map[string]*domain.Ingestion)}
+	ingests := usecase.NewIngestUsecase(repo, ingestRepo, &mockTransactor{}, rows, config.HarvestConfig{MaxRecords: 100})
+
+	var tokens []string
+	for _, id := range []string{"inbound-1", "inbound-2"} {
+		created, err := ingests.CreateToken(context.Background(), id, &domain.CreateIngestTokenRequest{Name: "sensor feed"}, "user-1")
+		if err != nil {
+			t.Fatalf("Expected no error creating token, got %v", err)
+		}
+		tokens = append(tokens, created.Token)
+	}
+	return ingests, rows, tokens[0], tokens[1]
+}
+
+// Test ingestion only accepts live tokens issued for the integration
+func TestIngest_TokenAuthentication(t *testing.T) {
+	ingests, rows, token, otherToken := newIngestFixture(t, `{"dataset_id": "dataset-1"}`)
+	ctx := context.Background()
+	payload := &domain.IngestPayload{ContentType: "application/json", Body: []byte(`{"value": 1}`)}
+
+	for _, candidate := range []string{"", "ing_unknown", otherToken} {
+		if _, err := ingests.Ingest(ctx, "inbound-1", candidate, payload); !errors.Is(err, pkgerrors.ErrUnauthorized) {
+			t.Errorf("Expected ErrUnauthorized for %q, got %v", candidate, err)
+		}
+	}
+
+	if _, err := ingests.Ingest(ctx, "inbound-1", token, payload); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	tokens, _ := ingests.ListTokens(ctx, "inbound-1")
+	if err := ingests.RevokeToken(ctx, "inbound-1", tokens[0].ID); err != nil {
+		t.Fatalf("Expected no error revoking, got %v", err)
+	}
+	if _, err := ingests.Ingest(ctx, "inbound-1", token, payload); !errors.Is(err, pkgerrors.ErrUnauthorized) {
+		t.Errorf("Expected ErrUnauthorized after revoke, got %v", err)
+	}
+	if len(rows.rows) != 1 {
+		t.Errorf("Expected 1 row written, got %d", len(rows.rows))
+	}
+}
+
+// Test a CSV payload is mapped, validated and reported row by row
+func TestIngest_CSVMappingValidationReport(t *testing.T) {
+	ingests, rows, token, _ := newIngestFixture(t, `{
+		"dataset_id": "dataset-1", "delimiter": ";",
+		"mapping": {"station": "Station", "reading": "Value", "measured_on": "Date"},
+		"required": ["station"], "types": {"reading": "number", "measured_on": "date"}
+	}`)
+
+	payload := &domain.IngestPayload{
+		ContentType: "text/csv",
+		Body:        []byte("Station;Value;Date\nA1;12.5;2026-01-01\n;3;2026-01-02\nB2;high;01/03/2026\n"),
+	}
+
+	report, err := ingests.Ingest(context.Background(), "inbound-1", token, payload)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if report.Result != domain.IngestResultPartial || report.Received != 3 || report.Accepted != 1 || report.Rejected != 2 {
+		t.Fatalf("Unexpected report: %+v", report)
+	}
+	if len(report.Errors) != 3 {
+		t.Fatalf("Expected 3 errors, got %+v", report.Errors)
+	}
+	if report.Errors[0].Row != 1 || report.Errors[0].Field != "station" {
+		t.Errorf("Expected missing station on row 1, got %+v", report.Errors[0])
+	}
+	if len(rows.rows) != 1 || rows.rows[0].Data != `{"measured_on":"2026-01-01","reading":12.5,"station":"A1"}` {
+		t.Errorf("Unexpected rows: %+v", rows.rows)
+	}
+}
+
+// Test reject_on_error writes nothing when any row is invalid
+func TestIngest_RejectOnError(t *testing.T) {
+	ingests, rows, token, _ := newIngestFixture(t, `{
+		"dataset_id": "dataset-1", "types": {"count": "integer"}, "reject_on_error": true
+	}`)
+
+	payload := &domain.IngestPayload{ContentType: "application/json", Body: []byte(`[{"count": 1}, {"count": 1.5}]`)}
+
+	report, err := ingests.Ingest(context.Background(), "inbound-1", token, payload)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if report.Result != domain.IngestResultRejected || report.Rejected != 2 {
+		t.Errorf("Expected the payload to be rejected, got %+v", report)
+	}
+	if len(rows.rows) != 0 {
+		t.Errorf("Expected no rows written, got %d", len(rows.rows))
+	}
+}
+
+// Test repeating an idempotency key returns the first report without writing again
+func TestIngest_IdempotencyKeyReplay(t *testing.T) {
+	ingests, rows, token, _ := newIngestFixture(t, `{"dataset_id": "dataset-1", "records_path": "data"}`)
+	ctx := context.Background()
+
+	payload := &domain.IngestPayload{
+		ContentType:    "application/json",
+		Body:           []byte(`{"data": [{"id": 1}, {"id": 2}]}`),
+		IdempotencyKey: "batch-42",
+	}
+
+	first, err := ingests.Ingest(ctx, "inbound-1", token, payload)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	second, err := ingests.Ingest(ctx, "inbound-1", token, payload)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !second.Replayed || second.IngestionID != first.IngestionID || second.Created != 2 {
+		t.Errorf("Expected the first report to be replayed, got %+v", second)
+	}
+	if len(rows.rows) != 2 {
+		t.Errorf("Expected 2 rows written once, got %d", len(rows.rows))
+	}
+}
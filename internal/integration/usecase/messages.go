@@ -0,0 +1,31 @@
+package usecase
+
+import templateDomain "portal-data-backend/internal/message_template/domain"
+
+// Keys of the notifications the owners of integrations are sent
+const (
+	MessageIntegrationFailing   = "integration.failing"
+	MessageIntegrationRecovered = "integration.recovered"
+)
+
+// Messages are the notifications the owners of integrations are sent, with
+// their built-in wording. The module defines them with the message template
+// registry.
+var Messages = []templateDomain.Definition{
+	{
+		Key:         MessageIntegrationFailing,
+		Channel:     templateDomain.ChannelNotification,
+		Description: "Alert that an integration failed its last runs; error is empty when the last run recorded none",
+		Variables:   []string{"name", "failures", "error"},
+		Subject:     "Integration failing",
+		Body:        "{{.name}} failed {{.failures}} runs in a row{{if .error}}: {{.error}}{{end}}",
+	},
+	{
+		Key:         MessageIntegrationRecovered,
+		Channel:     templateDomain.ChannelNotification,
+		Description: "Notice that a failing integration ran successfully again",
+		Variables:   []string{"name", "failures"},
+		Subject:     "Integration recovered",
+		Body:        "{{.name}} ran successfully again after {{.failures}} failed runs",
+	},
+}
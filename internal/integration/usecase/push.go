@@ -0,0 +1,411 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+	datasetDomain "portal-data-backend/internal/dataset/domain"
+	fileDomain "portal-data-backend/internal/file/domain"
+	"portal-data-backend/internal/integration/domain"
+	"portal-data-backend/internal/integration/publisher"
+	pkgErrors "portal-data-backend/pkg/errors"
+)
+
+// DatasetReader is the part of the dataset module publishers read through
+type DatasetReader interface {
+	GetByID(ctx context.Context, id string) (*datasetDomain.DatasetResponse, error)
+	List(ctx context.Context, req *datasetDomain.ListDatasetsRequest) (*datasetDomain.DatasetListResponse, error)
+}
+
+// FileLister is the part of the file module publishers read resources through
+type FileLister interface {
+	GetByDatasetID(ctx context.Context, datasetID string, page, limit int) (*fileDomain.FileListResponse, error)
+}
+
+// PushUsecase pushes published datasets to external catalogues through
+// publisher integrations and tracks the sync status of every dataset
+type PushUsecase interface {
+	// Push sends every published dataset changed since its last push
+	Push(ctx context.Context, integrationID string, trigger domain.RunTrigger) (*domain.RunInfo, error)
+	// PushDataset sends one dataset regardless of whether it changed
+	PushDataset(ctx context.Context, integrationID, datasetID string) (*domain.PushRecordInfo, error)
+	ListPushRecords(ctx context.Context, integrationID string, req *domain.ListPushRecordsRequest) (*domain.PushRecordListResponse, error)
+}
+
+type pushUsecase struct {
+	repo     domain.Repository
+	runRepo  domain.RunRepository
+	pushRepo domain.PushRepository
+	datasets DatasetReader
+	files    FileLister
+	observer RunObserver
+	client   *http.Client
+	cfg      config.HarvestConfig
+	now      func() time.Time
+}
+
+func NewPushUsecase(repo domain.Repository, runRepo domain.RunRepository, pushRepo domain.PushRepository, datasets DatasetReader, files FileLister, observer RunObserver, cfg config.HarvestConfig) PushUsecase {
+	return &pushUsecase{
+		repo:     repo,
+		runRepo:  runRepo,
+		pushRepo: pushRepo,
+		datasets: datasets,
+		files:    files,
+		observer: observer,
+		client:   &http.Client{Timeout: cfg.Timeout},
+		cfg:      cfg,
+		now:      time.Now,
+	}
+}
+
+func (u *pushUsecase) Push(ctx context.Context, integrationID string, trigger domain.RunTrigger) (*domain.RunInfo, error) {
+	integration, cfg, err := u.load(ctx, integrationID)
+	if err != nil {
+		return nil, err
+	}
+
+	run, err := u.execute(ctx, integration, cfg, trigger)
+	if err != nil {
+		return nil, err
+	}
+	return toRunInfo(run), nil
+}
+
+func (u *pushUsecase) PushDataset(ctx context.Context, integrationID, datasetID string) (*domain.PushRecordInfo, error) {
+	integration, cfg, err := u.load(ctx, integrationID)
+	if err != nil {
+		return nil, err
+	}
+
+	dataset, err := u.datasets.GetByID(ctx, datasetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dataset: %w", err)
+	}
+	if dataset.Status != string(datasetDomain.DatasetStatusPublished) {
+		return nil, fmt.Errorf("%w: only published datasets can be pushed", pkgErrors.ErrInvalidInput)
+	}
+	if integration.OrganizationID != nil && *integration.OrganizationID != dataset.OrganizationID {
+		return nil, fmt.Errorf("%w: dataset belongs to another organization", pkgErrors.ErrInvalidInput)
+	}
+
+	target, err := publisher.New(integration, cfg, u.client)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := u.pushRecord(ctx, integration.ID, dataset.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, u.cfg.Timeout)
+	defer cancel()
+
+	record, _, err := u.pushOne(ctx, integration, cfg, target, dataset, previous)
+	if err != nil {
+		return nil, err
+	}
+	return toPushRecordInfo(record), nil
+}
+
+func (u *pushUsecase) ListPushRecords(ctx context.Context, integrationID string, req *domain.ListPushRecordsRequest) (*domain.PushRecordListResponse, error) {
+	if _, err := u.repo.GetByID(ctx, integrationID); err != nil {
+		return nil, fmt.Errorf("failed to get integration: %w", err)
+	}
+
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.Limit < 1 {
+		req.Limit = 20
+	}
+
+	offset := (req.Page - 1) * req.Limit
+
+	records, total, err := u.pushRepo.ListPushRecords(ctx, integrationID, req.Status, req.Limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list push records: %w", err)
+	}
+
+	infos := make([]domain.PushRecordInfo, len(records))
+	for i, record := range records {
+		infos[i] = *toPushRecordInfo(record)
+	}
+
+	totalPage := int(math.Ceil(float64(total) / float64(req.Limit)))
+
+	return &domain.PushRecordListResponse{
+		Records: infos,
+		Meta: domain.ListMeta{
+			Page:      req.Page,
+			Limit:     req.Limit,
+			Total:     total,
+			TotalPage: totalPage,
+		},
+	}, nil
+}
+
+func (u *pushUsecase) load(ctx context.Context, integrationID string) (*domain.Integration, *domain.PublisherConfig, error) {
+	integration, err := u.repo.GetByID(ctx, integrationID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get integration: %w", err)
+	}
+	if integration.Type != string(domain.IntegrationTypePublisher) {
+		return nil, nil, fmt.Errorf("%w: integration is not a publisher", pkgErrors.ErrInvalidInput)
+	}
+
+	cfg, err := publisher.ParseConfig(integration)
+	if err != nil {
+		return nil, nil, err
+	}
+	return integration, cfg, nil
+}
+
+// execute pushes every changed dataset and records the outcome as a run
+func (u *pushUsecase) execute(ctx context.Context, integration *domain.Integration, cfg *domain.PublisherConfig, trigger domain.RunTrigger) (*domain.Run, error) {
+	run, err := startRun(ctx, u.runRepo, integration.ID, trigger, u.now(), u.cfg.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer u.runRepo.ReleaseRunLock(context.WithoutCancel(ctx), integration.ID)
+
+	runCtx, cancel := context.WithTimeout(ctx, u.cfg.Timeout)
+	defer cancel()
+
+	var runErrors []string
+	target, err := publisher.New(integration, cfg, u.client)
+	if err == nil {
+		var datasets []datasetDomain.DatasetResponse
+		datasets, err = u.publishedDatasets(runCtx, integration)
+		if err == nil {
+			runErrors = u.pushChanged(runCtx, integration, cfg, target, datasets, run)
+		}
+	}
+	if err != nil {
+		runErrors = append(runErrors, err.Error())
+	}
+
+	finishRun(run, err != nil, runErrors, u.now())
+
+	// Record the outcome even if the run context has expired
+	if err := u.runRepo.UpdateRun(context.WithoutCancel(ctx), run); err != nil {
+		return nil, fmt.Errorf("failed to update run: %w", err)
+	}
+	if err := u.repo.Sync(context.WithoutCancel(ctx), integration.ID); err != nil {
+		return nil, fmt.Errorf("failed to update last sync: %w", err)
+	}
+	if u.observer != nil {
+		u.observer.RunFinished(context.WithoutCancel(ctx), integration, run)
+	}
+
+	return run, nil
+}
+
+// pushChanged pushes the datasets that changed since they were last pushed
+// successfully. Failed pushes are retried on every run.
+func (u *pushUsecase) pushChanged(ctx context.Context, integration *domain.Integration, cfg *domain.PublisherConfig, target publisher.Publisher, datasets []datasetDomain.DatasetResponse, run *domain.Run) []string {
+	var runErrors []string
+	for i := range datasets {
+		dataset := &datasets[i]
+
+		previous, err := u.pushRecord(ctx, integration.ID, dataset.ID)
+		if err != nil {
+			run.RecordsFailed++
+			runErrors = append(runErrors, fmt.Sprintf("dataset %s: %v", dataset.ID, err))
+			continue
+		}
+		if previous != nil && previous.Status == string(domain.PushStatusSynced) && !dataset.UpdatedAt.After(previous.DatasetUpdatedAt) {
+			continue
+		}
+		run.RecordsFetched++
+
+		record, created, err := u.pushOne(ctx, integration, cfg, target, dataset, previous)
+		switch {
+		case err != nil:
+			run.RecordsFailed++
+			runErrors = append(runErrors, fmt.Sprintf("dataset %s: %v", dataset.ID, err))
+		case record.Status == string(domain.PushStatusFailed):
+			run.RecordsFailed++
+			runErrors = append(runErrors, fmt.Sprintf("dataset %s: %s", dataset.ID, *record.Error))
+		case created:
+			run.RecordsCreated++
+		default:
+			run.RecordsUpdated++
+		}
+	}
+	return runErrors
+}
+
+// pushOne publishes a dataset and saves its push record. A rejected push is
+// reported on the record; only persistence failures are returned.
+func (u *pushUsecase) pushOne(ctx context.Context, integration *domain.Integration, cfg *domain.PublisherConfig, target publisher.Publisher, dataset *datasetDomain.DatasetResponse, previous *domain.PushRecord) (*domain.PushRecord, bool, error) {
+	record := &domain.PushRecord{
+		IntegrationID: integration.ID,
+		DatasetID:     dataset.ID,
+	}
+	var remoteID string
+	if previous != nil {
+		record.RemoteID = previous.RemoteID
+		record.RemoteName = previous.RemoteName
+		record.DatasetUpdatedAt = previous.DatasetUpdatedAt
+		if previous.RemoteID != nil {
+			remoteID = *previous.RemoteID
+		}
+	}
+
+	pkg, err := u.buildPackage(ctx, cfg, dataset)
+	var published *publisher.Published
+	if err == nil {
+		published, err = target.Publish(ctx, pkg, remoteID)
+		if errors.Is(err, publisher.ErrRemoteNotFound) {
+			// The remote package was deleted; publish it again
+			remoteID = ""
+			published, err = target.Publish(ctx, pkg, "")
+		}
+	}
+
+	record.PushedAt = u.now()
+	if err != nil {
+		message := err.Error()
+		record.Status = string(domain.PushStatusFailed)
+		record.Error = &message
+	} else {
+		record.Status = string(domain.PushStatusSynced)
+		record.RemoteID = &published.ID
+		record.RemoteName = &published.Name
+		record.DatasetUpdatedAt = dataset.UpdatedAt
+	}
+
+	if err := u.pushRepo.SavePushRecord(context.WithoutCancel(ctx), record); err != nil {
+		return nil, false, fmt.Errorf("failed to save push record: %w", err)
+	}
+	return record, remoteID == "", nil
+}
+
+// buildPackage maps dataset metadata and ready files to a catalogue package
+func (u *pushUsecase) buildPackage(ctx context.Context, cfg *domain.PublisherConfig, dataset *datasetDomain.DatasetResponse) (*publisher.Package, error) {
+	pkg := &publisher.Package{
+		Name:  cfg.NamePrefix + dataset.Slug,
+		Title: dataset.Name,
+		Extras: map[string]string{
+			"portal_id":      dataset.ID,
+			"classification": dataset.Classification,
+			"category":       dataset.Category,
+		},
+	}
+	if dataset.Description != nil {
+		pkg.Notes = *dataset.Description
+	}
+	if cfg.DatasetURL != "" {
+		pkg.URL = strings.NewReplacer("{id}", dataset.ID, "{slug}", dataset.Slug).Replace(cfg.DatasetURL)
+	}
+	for _, tag := range dataset.Tags {
+		pkg.Tags = append(pkg.Tags, tag.Name)
+	}
+	if dataset.CoverageStart != nil {
+		pkg.Extras["temporal_start"] = *dataset.CoverageStart
+	}
+	if dataset.CoverageEnd != nil {
+		pkg.Extras["temporal_end"] = *dataset.CoverageEnd
+	}
+	if dataset.Frequency != nil {
+		pkg.Extras["frequency"] = string(*dataset.Frequency)
+	}
+	if dataset.Topic != nil {
+		pkg.Extras["topic"] = dataset.Topic.Name
+	}
+	if dataset.Unit != nil {
+		pkg.Extras["unit"] = dataset.Unit.Name
+	}
+	if dataset.BusinessField != nil {
+		pkg.Extras["business_field"] = dataset.BusinessField.Name
+	}
+	for key, value := range cfg.Extras {
+		pkg.Extras[key] = value
+	}
+
+	if cfg.ResourceBaseURL == "" {
+		return pkg, nil
+	}
+	base := strings.TrimRight(cfg.ResourceBaseURL, "/")
+	for page := 1; ; page++ {
+		resp, err := u.files.GetByDatasetID(ctx, dataset.ID, page, 100)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list dataset files: %w", err)
+		}
+		for _, file := range resp.Files {
+			if file.Status != string(fileDomain.FileStatusReady) {
+				continue
+			}
+			pkg.Resources = append(pkg.Resources, publisher.Resource{
+				Name:     file.OriginalName,
+				URL:      base + "/" + strings.TrimLeft(file.Path, "/"),
+				Format:   strings.ToUpper(strings.TrimPrefix(file.Extension, ".")),
+				MimeType: file.MimeType,
+				Size:     file.Size,
+			})
+		}
+		if page >= resp.Meta.TotalPage {
+			break
+		}
+	}
+
+	return pkg, nil
+}
+
+// publishedDatasets lists every published dataset the integration may push,
+// limited to its organization when it belongs to one
+func (u *pushUsecase) publishedDatasets(ctx context.Context, integration *domain.Integration) ([]datasetDomain.DatasetResponse, error) {
+	req := &datasetDomain.ListDatasetsRequest{
+		Limit:     100,
+		Status:    string(datasetDomain.DatasetStatusPublished),
+		SortOrder: "ASC",
+	}
+	if integration.OrganizationID != nil {
+		req.OrganizationID = *integration.OrganizationID
+	}
+
+	var datasets []datasetDomain.DatasetResponse
+	for page := 1; ; page++ {
+		req.Page = page
+		resp, err := u.datasets.List(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list datasets: %w", err)
+		}
+		datasets = append(datasets, resp.Datasets...)
+		if page >= resp.Meta.TotalPage {
+			break
+		}
+	}
+	return datasets, nil
+}
+
+// pushRecord returns the push record of a dataset, or nil if it was never pushed
+func (u *pushUsecase) pushRecord(ctx context.Context, integrationID, datasetID string) (*domain.PushRecord, error) {
+	record, err := u.pushRepo.GetPushRecord(ctx, integrationID, datasetID)
+	if err != nil {
+		if errors.Is(err, pkgErrors.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get push record: %w", err)
+	}
+	return record, nil
+}
+
+func toPushRecordInfo(record *domain.PushRecord) *domain.PushRecordInfo {
+	return &domain.PushRecordInfo{
+		DatasetID:        record.DatasetID,
+		RemoteID:         record.RemoteID,
+		RemoteName:       record.RemoteName,
+		Status:           record.Status,
+		Error:            record.Error,
+		DatasetUpdatedAt: record.DatasetUpdatedAt,
+		PushedAt:         record.PushedAt,
+	}
+}
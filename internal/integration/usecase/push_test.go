@@ -0,0 +1,230 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+	datasetDomain "portal-data-backend/internal/dataset/domain"
+	fileDomain "portal-data-backend/internal/file/domain"
+	"portal-data-backend/internal/integration/domain"
+	"portal-data-backend/internal/integration/usecase"
+	tagDomain "portal-data-backend/internal/tag/domain"
+	pkgerrors "portal-data-backend/pkg/errors"
+)
+
+// mockPushRepository is an in-memory implementation of PushRepository
+type mockPushRepository struct {
+	records map[string]*domain.PushRecord
+}
+
+func (m *mockPushRepository) GetPushRecord(ctx context.Context, integrationID, datasetID string) (*domain.PushRecord, error) {
+	record, ok := m.records[datasetID]
+	if !ok {
+		return nil, pkgerrors.ErrNotFound
+	}
+	return record, nil
+}
+
+func (m *mockPushRepository) SavePushRecord(ctx context.Context, record *domain.PushRecord) error {
+	m.records[record.DatasetID] = record
+	return nil
+}
+
+func (m *mockPushRepository) ListPushRecords(ctx context.Context, integrationID string, status *string, limit, offset int) ([]*domain.PushRecord, int, error) {
+	var records []*domain.PushRecord
+	for _, record := range m.records {
+		records = append(records, record)
+	}
+	return records, len(records), nil
+}
+
+// mockDatasetReader serves a fixed set of datasets
+type mockDatasetReader struct {
+	datasets []datasetDomain.DatasetResponse
+}
+
+func (m *mockDatasetReader) GetByID(ctx context.Context, id string) (*datasetDomain.DatasetResponse, error) {
+	for i := range m.datasets {
+		if m.datasets[i].ID == id {
+			return &m.datasets[i], nil
+		}
+	}
+	return nil, pkgerrors.ErrNotFound
+}
+
+func (m *mockDatasetReader) List(ctx context.Context, req *datasetDomain.ListDatasetsRequest) (*datasetDomain.DatasetListResponse, error) {
+	return &datasetDomain.DatasetListResponse{
+		Datasets: m.datasets,
+		Meta:     datasetDomain.ListMeta{Page: req.Page, Limit: req.Limit, Total: len(m.datasets), TotalPage: 1},
+	}, nil
+}
+
+// mockFileLister gives every dataset one ready CSV file
+type mockFileLister struct{}
+
+func (m *mockFileLister) GetByDatasetID(ctx context.Context, datasetID string, page, limit int) (*fileDomain.FileListResponse, error) {
+	return &fileDomain.FileListResponse{
+		Files: []fileDomain.FileInfo{{
+			ID:           "file-" + datasetID,
+			OriginalName: datasetID + ".csv",
+			Extension:    ".csv",
+			MimeType:     "text/csv",
+			Path:         "files/" + datasetID + ".csv",
+			Status:       string(fileDomain.FileStatusReady),
+		}},
+		Meta: fileDomain.ListMeta{Page: page, Limit: limit, Total: 1, TotalPage: 1},
+	}, nil
+}
+
+// ckanStub records package actions and assigns remote IDs
+type ckanStub struct {
+	actions  []string
+	packages map[string]map[string]interface{}
+}
+
+func (s *ckanStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	action := strings.TrimPrefix(r.URL.Path, "/api/3/action/")
+	s.actions = append(s.actions, action)
+
+	var pkg map[string]interface{}
+	json.NewDecoder(r.Body).Decode(&pkg)
+
+	id, _ := pkg["id"].(string)
+	if action == "package_update" {
+		if _, ok := s.packages[id]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"success": false, "error": {"__type": "Not Found Error", "message": "Not found"}}`))
+			return
+		}
+	} else {
+		id = "remote-" + pkg["name"].(string)
+	}
+	s.packages[id] = pkg
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"result":  map[string]string{"id": id, "name": pkg["name"].(string)},
+	})
+}
+
+func newPushFixture(ckanURL string, datasets []datasetDomain.DatasetResponse) (usecase.PushUsecase, *mockPushRepository) {
+	apiKey := "ckan-token"
+	integration := &domain.Integration{
+		ID:     "publisher-1",
+		Type:   string(domain.IntegrationTypePublisher),
+		Status: string(domain.IntegrationStatusActive),
+		Config: `{"publisher": "ckan", "url": "` + ckanURL + `", "owner_org": "portal",
+			"name_prefix": "portal-", "resource_base_url": "https://files.example.org/",
+			"dataset_url": "https://portal.example.org/datasets/{slug}"}`,
+		APIKey:    &apiKey,
+		CreatedBy: "user-1",
+	}
+
+	repo := &mockIntegrationRepository{integrations: map[string]*domain.Integration{integration.ID: integration}}
+	runRepo := &mockRunRepository{records: make(map[string]*domain.HarvestRecord)}
+	pushRepo := &mockPushRepository{records: make(map[string]*domain.PushRecord)}
+
+	cfg := config.HarvestConfig{
+		Timeout:    5 * time.Second,
+		MaxRecords: 100,
+	}
+
+	return usecase.NewPushUsecase(repo, runRepo, pushRepo, &mockDatasetReader{datasets: datasets}, &mockFileLister{}, nil, cfg), pushRepo
+}
+
+// Test a push creates packages, skips unchanged datasets and updates changed ones
+func TestPush_CreatesThenUpdatesChanged(t *testing.T) {
+	ckan := &ckanStub{packages: make(map[string]map[string]interface{})}
+	server := httptest.NewServer(ckan)
+	defer server.Close()
+
+	updated := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	description := "Annual population"
+	datasets := []datasetDomain.DatasetResponse{
+		{ID: "ds-1", Name: "Population", Slug: "population", Description: &description, Status: "published",
+			Classification: "public", Category: "statistics", UpdatedAt: updated,
+			Tags: []tagDomain.TagResponse{{Name: "census"}}},
+		{ID: "ds-2", Name: "Rainfall", Slug: "rainfall", Status: "published",
+			Classification: "public", Category: "climate", UpdatedAt: updated},
+	}
+	pushes, pushRepo := newPushFixture(server.URL, datasets)
+	ctx := context.Background()
+
+	run, err := pushes.Push(ctx, "publisher-1", domain.RunTriggerManual)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if run.Status != string(domain.RunStatusSucceeded) || run.RecordsCreated != 2 {
+		t.Fatalf("Expected 2 packages created, got %s created=%d (%v)", run.Status, run.RecordsCreated, run.Errors)
+	}
+
+	pkg := ckan.packages["remote-portal-population"]
+	if pkg == nil || pkg["owner_org"] != "portal" || pkg["url"] != "https://portal.example.org/datasets/population" {
+		t.Fatalf("Unexpected package: %+v", pkg)
+	}
+	resources, _ := pkg["resources"].([]interface{})
+	if len(resources) != 1 || resources[0].(map[string]interface{})["url"] != "https://files.example.org/files/ds-1.csv" {
+		t.Errorf("Expected one file resource, got %+v", resources)
+	}
+
+	run, err = pushes.Push(ctx, "publisher-1", domain.RunTriggerManual)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if run.RecordsFetched != 0 {
+		t.Errorf("Expected unchanged datasets to be skipped, got %d pushed", run.RecordsFetched)
+	}
+
+	datasets[0].UpdatedAt = updated.Add(time.Hour)
+	run, err = pushes.Push(ctx, "publisher-1", domain.RunTriggerManual)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if run.RecordsFetched != 1 || run.RecordsUpdated != 1 {
+		t.Errorf("Expected 1 package updated, got fetched=%d updated=%d", run.RecordsFetched, run.RecordsUpdated)
+	}
+	if last := ckan.actions[len(ckan.actions)-1]; last != "package_update" {
+		t.Errorf("Expected package_update, got %s", last)
+	}
+	if record := pushRepo.records["ds-1"]; record.Status != string(domain.PushStatusSynced) || !record.DatasetUpdatedAt.Equal(datasets[0].UpdatedAt) {
+		t.Errorf("Unexpected push record: %+v", record)
+	}
+}
+
+// Test pushing a dataset whose remote package was deleted creates it again
+func TestPushDataset_RecreatesDeletedPackage(t *testing.T) {
+	ckan := &ckanStub{packages: make(map[string]map[string]interface{})}
+	server := httptest.NewServer(ckan)
+	defer server.Close()
+
+	datasets := []datasetDomain.DatasetResponse{
+		{ID: "ds-1", Name: "Population", Slug: "population", Status: "published",
+			Classification: "public", Category: "statistics", UpdatedAt: time.Now()},
+	}
+	pushes, pushRepo := newPushFixture(server.URL, datasets)
+
+	remoteID := "deleted-package"
+	pushRepo.records["ds-1"] = &domain.PushRecord{
+		IntegrationID: "publisher-1",
+		DatasetID:     "ds-1",
+		RemoteID:      &remoteID,
+		Status:        string(domain.PushStatusSynced),
+	}
+
+	record, err := pushes.PushDataset(context.Background(), "publisher-1", "ds-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if record.Status != string(domain.PushStatusSynced) || record.RemoteID == nil || *record.RemoteID != "remote-portal-population" {
+		t.Errorf("Expected the package to be recreated, got %+v", record)
+	}
+	if strings.Join(ckan.actions, ",") != "package_update,package_create" {
+		t.Errorf("Expected update then create, got %v", ckan.actions)
+	}
+}
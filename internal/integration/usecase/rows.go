@@ -0,0 +1,198 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"portal-data-backend/infrastructure/db"
+	dataRowDomain "portal-data-backend/internal/data_row/domain"
+)
+
+// rowWriter writes mapped records as the data rows of one dataset. It is
+// shared by connector harvests and inbound ingestion.
+type rowWriter struct {
+	rows      DataRowWriter
+	tx        db.Transactor
+	userID    string
+	datasetID string
+}
+
+// rowResult counts the rows a write changed. Failed maps the position of
+// every row that was not written to the reason.
+type rowResult struct {
+	Created int
+	Updated int
+	Failed  map[int]string
+}
+
+// errors lists the failed rows in input order
+func (r *rowResult) errors() []string {
+	positions := make([]int, 0, len(r.Failed))
+	for i := range r.Failed {
+		positions = append(positions, i)
+	}
+	sort.Ints(positions)
+
+	messages := make([]string, len(positions))
+	for n, i := range positions {
+		messages[n] = fmt.Sprintf("record %d: %s", i, r.Failed[i])
+	}
+	return messages
+}
+
+// replace swaps all rows of the dataset for rows
+func (w *rowWriter) replace(ctx context.Context, rows []map[string]interface{}) (*rowResult, error) {
+	result := &rowResult{Failed: map[int]string{}}
+	inputs := w.encode(rows, 0, result)
+	if len(inputs) == 0 {
+		return result, nil
+	}
+
+	// The old rows stay when the new ones cannot be written
+	err := w.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := w.rows.DeleteByDatasetID(ctx, w.datasetID); err != nil {
+			return err
+		}
+		req := &dataRowDomain.BulkCreateDataRowsRequest{DatasetID: w.datasetID, Rows: inputs}
+		return w.rows.BulkCreate(ctx, req, w.userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Created = len(inputs)
+	return result, nil
+}
+
+// append adds rows after the existing rows of the dataset
+func (w *rowWriter) append(ctx context.Context, rows []map[string]interface{}) (*rowResult, error) {
+	_, nextIndex, err := w.existing(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	result := &rowResult{Failed: map[int]string{}}
+	inputs := w.encode(rows, nextIndex, result)
+	if len(inputs) == 0 {
+		return result, nil
+	}
+
+	req := &dataRowDomain.BulkCreateDataRowsRequest{DatasetID: w.datasetID, Rows: inputs}
+	if err := w.rows.BulkCreate(ctx, req, w.userID); err != nil {
+		return nil, err
+	}
+
+	result.Created = len(inputs)
+	return result, nil
+}
+
+// upsert matches rows on keyField, updating changed rows and appending new
+// ones. Existing rows missing from rows are kept.
+func (w *rowWriter) upsert(ctx context.Context, keyField string, rows []map[string]interface{}) (*rowResult, error) {
+	existing, nextIndex, err := w.existing(ctx, keyField)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &rowResult{Failed: map[int]string{}}
+	for i, row := range rows {
+		if row[keyField] == nil {
+			result.Failed[i] = "missing " + keyField
+			continue
+		}
+		key := fmt.Sprint(row[keyField])
+
+		encoded, err := json.Marshal(row)
+		if err != nil {
+			result.Failed[i] = err.Error()
+			continue
+		}
+		data := string(encoded)
+
+		if current, ok := existing[key]; ok {
+			if sameJSON(current.Data, data) {
+				continue
+			}
+			if _, err := w.rows.Update(ctx, current.ID, &dataRowDomain.UpdateDataRowRequest{Data: &data}); err != nil {
+				result.Failed[i] = err.Error()
+				continue
+			}
+			current.Data = data
+			result.Updated++
+			continue
+		}
+
+		req := &dataRowDomain.CreateDataRowRequest{DatasetID: w.datasetID, RowIndex: nextIndex, Data: data}
+		created, err := w.rows.Create(ctx, req, w.userID)
+		if err != nil {
+			result.Failed[i] = err.Error()
+			continue
+		}
+		existing[key] = created
+		nextIndex++
+		result.Created++
+	}
+	return result, nil
+}
+
+// encode converts rows to bulk inputs numbered from firstIndex, recording
+// rows that cannot be encoded in result
+func (w *rowWriter) encode(rows []map[string]interface{}, firstIndex int, result *rowResult) []dataRowDomain.DataRowDataInput {
+	inputs := make([]dataRowDomain.DataRowDataInput, 0, len(rows))
+	for i, row := range rows {
+		data, err := json.Marshal(row)
+		if err != nil {
+			result.Failed[i] = err.Error()
+			continue
+		}
+		inputs = append(inputs, dataRowDomain.DataRowDataInput{RowIndex: firstIndex + len(inputs), Data: string(data)})
+	}
+	return inputs
+}
+
+// existing indexes the rows of the dataset by the value of keyField and
+// returns the next free row index. Rows are not indexed when keyField is empty.
+func (w *rowWriter) existing(ctx context.Context, keyField string) (map[string]*dataRowDomain.DataRowInfo, int, error) {
+	existing := make(map[string]*dataRowDomain.DataRowInfo)
+	nextIndex := 0
+
+	for page := 1; ; page++ {
+		resp, err := w.rows.List(ctx, &dataRowDomain.ListDataRowsRequest{Page: page, Limit: 1000, DatasetID: w.datasetID})
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to list existing rows: %w", err)
+		}
+
+		for i := range resp.Rows {
+			row := &resp.Rows[i]
+			if row.RowIndex >= nextIndex {
+				nextIndex = row.RowIndex + 1
+			}
+			if keyField == "" {
+				continue
+			}
+			var data map[string]interface{}
+			if err := json.Unmarshal([]byte(row.Data), &data); err != nil || data[keyField] == nil {
+				continue
+			}
+			existing[fmt.Sprint(data[keyField])] = row
+		}
+
+		if page >= resp.Meta.TotalPage {
+			break
+		}
+	}
+
+	return existing, nextIndex, nil
+}
+
+// sameJSON compares two JSON documents ignoring formatting and key order
+func sameJSON(a, b string) bool {
+	var left, right interface{}
+	if json.Unmarshal([]byte(a), &left) != nil || json.Unmarshal([]byte(b), &right) != nil {
+		return false
+	}
+	return reflect.DeepEqual(left, right)
+}
@@ -0,0 +1,285 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/infrastructure/logger"
+	"portal-data-backend/internal/integration/connector"
+	"portal-data-backend/internal/integration/domain"
+	"portal-data-backend/internal/integration/publisher"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"github.com/robfig/cron/v3"
+)
+
+// SchedulerUsecase runs connector harvests and publisher pushes from one shared
+// job queue, on the cron schedule of each integration or on demand
+type SchedulerUsecase interface {
+	// Enqueue queues a manual run of a connector or publisher integration
+	Enqueue(ctx context.Context, integrationID string) (*domain.RunJob, error)
+	// RunNow runs a connector or publisher integration manually in the
+	// caller, bypassing the queue, and returns the finished run
+	RunNow(ctx context.Context, integrationID string) (*domain.RunInfo, error)
+	// RunDue queues every integration whose next run is due and returns how many were queued
+	RunDue(ctx context.Context) (int, error)
+	// Run serves the job queue and checks schedules until ctx is cancelled
+	Run(ctx context.Context)
+	// Ping reports an error while the job queue is not served or is full
+	Ping(ctx context.Context) error
+}
+
+type schedulerUsecase struct {
+	repo     domain.Repository
+	runRepo  domain.RunRepository
+	harvests HarvestUsecase
+	pushes   PushUsecase
+	cfg      config.SchedulerConfig
+	jobs     chan domain.RunJob
+	now      func() time.Time
+	jitter   func() time.Duration
+
+	mu      sync.Mutex
+	running bool
+	// pending holds the integrations queued or running on this instance
+	pending map[string]bool
+}
+
+func NewSchedulerUsecase(repo domain.Repository, runRepo domain.RunRepository, harvests HarvestUsecase, pushes PushUsecase, cfg config.SchedulerConfig) SchedulerUsecase {
+	if cfg.Workers < 1 {
+		cfg.Workers = 1
+	}
+	if cfg.QueueSize < 1 {
+		cfg.QueueSize = 1
+	}
+
+	return &schedulerUsecase{
+		repo:     repo,
+		runRepo:  runRepo,
+		harvests: harvests,
+		pushes:   pushes,
+		cfg:      cfg,
+		jobs:     make(chan domain.RunJob, cfg.QueueSize),
+		now:      time.Now,
+		jitter: func() time.Duration {
+			if cfg.Jitter <= 0 {
+				return 0
+			}
+			return rand.N(cfg.Jitter)
+		},
+		pending: make(map[string]bool),
+	}
+}
+
+func (u *schedulerUsecase) Enqueue(ctx context.Context, integrationID string) (*domain.RunJob, error) {
+	integration, err := u.repo.GetByID(ctx, integrationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get integration: %w", err)
+	}
+	if !schedulable(integration) {
+		return nil, fmt.Errorf("%w: only connector and publisher integrations can be run", pkgErrors.ErrInvalidInput)
+	}
+
+	return u.enqueue(integration, domain.RunTriggerManual)
+}
+
+func (u *schedulerUsecase) RunNow(ctx context.Context, integrationID string) (*domain.RunInfo, error) {
+	integration, err := u.repo.GetByID(ctx, integrationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get integration: %w", err)
+	}
+
+	// The run lock still keeps the run from overlapping one of the server
+	switch integration.Type {
+	case string(domain.IntegrationTypeConnector):
+		return u.harvests.Harvest(ctx, integration.ID, domain.RunTriggerManual)
+	case string(domain.IntegrationTypePublisher):
+		return u.pushes.Push(ctx, integration.ID, domain.RunTriggerManual)
+	}
+	return nil, fmt.Errorf("%w: only connector and publisher integrations can be run", pkgErrors.ErrInvalidInput)
+}
+
+func (u *schedulerUsecase) RunDue(ctx context.Context) (int, error) {
+	activeStatus := string(domain.IntegrationStatusActive)
+	filter := &domain.IntegrationFilter{Status: &activeStatus}
+
+	integrations, _, err := u.repo.List(ctx, filter, 1000, 0)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list integrations: %w", err)
+	}
+
+	queued := 0
+	now := u.now()
+	for _, integration := range integrations {
+		if !schedulable(integration) {
+			continue
+		}
+		schedule := scheduleOf(integration)
+		if schedule == nil {
+			continue
+		}
+
+		// A new or reconfigured integration first gets its next run computed
+		due := integration.NextRunAt != nil && !integration.NextRunAt.After(now)
+		if integration.NextRunAt != nil && !due {
+			continue
+		}
+
+		claimed, err := u.runRepo.ClaimNextRun(ctx, integration.ID, integration.NextRunAt, u.nextRun(schedule, now))
+		if err != nil {
+			return queued, err
+		}
+		if !claimed || !due {
+			continue
+		}
+
+		if _, err := u.enqueue(integration, domain.RunTriggerSchedule); err != nil {
+			logger.FromContext(ctx).Warn("scheduled run of integration %s skipped: %v", integration.ID, err)
+			continue
+		}
+		queued++
+	}
+
+	return queued, nil
+}
+
+func (u *schedulerUsecase) Run(ctx context.Context) {
+	u.setRunning(true)
+	defer u.setRunning(false)
+
+	for i := 0; i < u.cfg.Workers; i++ {
+		go u.work(ctx)
+	}
+
+	ticker := time.NewTicker(u.cfg.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := u.RunDue(ctx); err != nil {
+				logger.FromContext(ctx).Error("run scheduling failed: %v", err)
+			}
+		}
+	}
+}
+
+func (u *schedulerUsecase) Ping(ctx context.Context) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if len(u.jobs) == cap(u.jobs) {
+		return fmt.Errorf("run queue is full with %d jobs", len(u.jobs))
+	}
+	if !u.running {
+		return fmt.Errorf("run queue is not served")
+	}
+	return nil
+}
+
+func (u *schedulerUsecase) setRunning(running bool) {
+	u.mu.Lock()
+	u.running = running
+	u.mu.Unlock()
+}
+
+// enqueue adds a run to the queue without blocking. An integration is queued
+// at most once at a time; the run lock also guards against other instances.
+func (u *schedulerUsecase) enqueue(integration *domain.Integration, trigger domain.RunTrigger) (*domain.RunJob, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.pending[integration.ID] {
+		return nil, fmt.Errorf("%w: a run of this integration is already queued", pkgErrors.ErrAlreadyExists)
+	}
+
+	job := domain.RunJob{
+		IntegrationID: integration.ID,
+		Type:          integration.Type,
+		Trigger:       string(trigger),
+		QueuedAt:      u.now(),
+	}
+	select {
+	case u.jobs <- job:
+		u.pending[integration.ID] = true
+		return &job, nil
+	default:
+		return nil, fmt.Errorf("run queue is full")
+	}
+}
+
+func (u *schedulerUsecase) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-u.jobs:
+			u.execute(ctx, job)
+		}
+	}
+}
+
+func (u *schedulerUsecase) execute(ctx context.Context, job domain.RunJob) {
+	defer func() {
+		u.mu.Lock()
+		delete(u.pending, job.IntegrationID)
+		u.mu.Unlock()
+	}()
+
+	var err error
+	switch job.Type {
+	case string(domain.IntegrationTypeConnector):
+		_, err = u.harvests.Harvest(ctx, job.IntegrationID, domain.RunTrigger(job.Trigger))
+	case string(domain.IntegrationTypePublisher):
+		_, err = u.pushes.Push(ctx, job.IntegrationID, domain.RunTrigger(job.Trigger))
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("%s run of integration %s failed: %v", job.Trigger, job.IntegrationID, err)
+	}
+}
+
+// nextRun returns the next time schedule fires after now, delayed by a random
+// jitter so integrations sharing a schedule do not all start at once
+func (u *schedulerUsecase) nextRun(schedule cron.Schedule, now time.Time) time.Time {
+	return schedule.Next(now).Add(u.jitter())
+}
+
+func schedulable(integration *domain.Integration) bool {
+	return integration.Type == string(domain.IntegrationTypeConnector) ||
+		integration.Type == string(domain.IntegrationTypePublisher)
+}
+
+// scheduleOf parses the cron schedule of a connector or publisher integration.
+// It returns nil when the integration has no valid schedule.
+func scheduleOf(integration *domain.Integration) cron.Schedule {
+	var expr string
+	switch integration.Type {
+	case string(domain.IntegrationTypeConnector):
+		cfg, err := connector.ParseConfig(integration)
+		if err != nil {
+			return nil
+		}
+		expr = cfg.Schedule
+	case string(domain.IntegrationTypePublisher):
+		cfg, err := publisher.ParseConfig(integration)
+		if err != nil {
+			return nil
+		}
+		expr = cfg.Schedule
+	}
+	if expr == "" {
+		return nil
+	}
+
+	schedule, err := cron.ParseStandard(expr)
+	if err != nil {
+		return nil
+	}
+	return schedule
+}
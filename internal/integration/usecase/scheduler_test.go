@@ -0,0 +1,180 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/internal/integration/domain"
+	"portal-data-backend/internal/integration/usecase"
+	pkgerrors "portal-data-backend/pkg/errors"
+)
+
+// mockHarvester reports every harvest it is asked to run
+type mockHarvester struct {
+	started chan string
+	release chan struct{}
+}
+
+func (m *mockHarvester) Harvest(ctx context.Context, integrationID string, trigger domain.RunTrigger) (*domain.RunInfo, error) {
+	m.started <- integrationID + ":" + string(trigger)
+	<-m.release
+	return &domain.RunInfo{IntegrationID: integrationID, Trigger: string(trigger)}, nil
+}
+
+func (m *mockHarvester) ListRuns(ctx context.Context, integrationID string, req *domain.ListRunsRequest) (*domain.RunListResponse, error) {
+	return &domain.RunListResponse{}, nil
+}
+
+func (m *mockHarvester) ListMatches(ctx context.Context, integrationID string, req *domain.ListMatchesRequest) (*domain.HarvestMatchListResponse, error) {
+	return &domain.HarvestMatchListResponse{}, nil
+}
+
+func (m *mockHarvester) ResolveMatch(ctx context.Context, integrationID, matchID string, req *domain.ResolveMatchRequest, userID string) (*domain.HarvestMatchInfo, error) {
+	return nil, nil
+}
+
+func newScheduledConnector(id string, nextRunAt *time.Time) *domain.Integration {
+	integration := newConnectorIntegration(`{"connector": "rest", "url": "http://example.org", "target": "datasets", "schedule": "0 * * * *"}`)
+	integration.ID = id
+	integration.NextRunAt = nextRunAt
+	return integration
+}
+
+// Test RunDue queues due integrations and computes the next run with jitter
+func TestScheduler_RunDueQueuesDueIntegrations(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Hour)
+
+	inactive := newScheduledConnector("inactive", &past)
+	inactive.Status = string(domain.IntegrationStatusInactive)
+	repo := &mockIntegrationRepository{integrations: map[string]*domain.Integration{
+		"new":      newScheduledConnector("new", nil),
+		"due":      newScheduledConnector("due", &past),
+		"later":    newScheduledConnector("later", &future),
+		"inactive": inactive,
+	}}
+	runRepo := &mockRunRepository{records: make(map[string]*domain.HarvestRecord)}
+
+	cfg := config.SchedulerConfig{CheckInterval: time.Minute, Jitter: time.Minute, Workers: 1, QueueSize: 10}
+	scheduler := usecase.NewSchedulerUsecase(repo, runRepo, &mockHarvester{}, nil, cfg)
+
+	queued, err := scheduler.RunDue(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if queued != 1 {
+		t.Errorf("Expected 1 queued run, got %d", queued)
+	}
+
+	if len(runRepo.claims) != 2 {
+		t.Fatalf("Expected next runs for the new and due integrations, got %v", runRepo.claims)
+	}
+	nextHour := now.Truncate(time.Hour).Add(time.Hour)
+	for id, next := range runRepo.claims {
+		if next.Before(nextHour) || !next.Before(nextHour.Add(time.Minute)) {
+			t.Errorf("Expected next run of %s within the jitter of %v, got %v", id, nextHour, next)
+		}
+	}
+
+	if _, err := scheduler.Enqueue(context.Background(), "due"); !errors.Is(err, pkgerrors.ErrAlreadyExists) {
+		t.Errorf("Expected ErrAlreadyExists for an integration already queued, got %v", err)
+	}
+}
+
+// Test a manual run is executed by a worker and cannot be queued twice
+func TestScheduler_EnqueueManualRun(t *testing.T) {
+	repo := &mockIntegrationRepository{integrations: map[string]*domain.Integration{
+		"connector-1": newScheduledConnector("connector-1", nil),
+	}}
+	harvester := &mockHarvester{started: make(chan string, 1), release: make(chan struct{})}
+
+	cfg := config.SchedulerConfig{CheckInterval: time.Hour, Workers: 2, QueueSize: 10}
+	scheduler := usecase.NewSchedulerUsecase(repo, &mockRunRepository{}, harvester, nil, cfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go scheduler.Run(ctx)
+
+	job, err := scheduler.Enqueue(ctx, "connector-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if job.Trigger != string(domain.RunTriggerManual) {
+		t.Errorf("Expected manual trigger, got %s", job.Trigger)
+	}
+
+	select {
+	case started := <-harvester.started:
+		if started != "connector-1:manual" {
+			t.Errorf("Expected manual harvest of connector-1, got %s", started)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the queued run to start")
+	}
+
+	if _, err := scheduler.Enqueue(ctx, "connector-1"); !errors.Is(err, pkgerrors.ErrAlreadyExists) {
+		t.Errorf("Expected ErrAlreadyExists while the run is in progress, got %v", err)
+	}
+	close(harvester.release)
+}
+
+// Test a run started now is executed in the caller, outside the queue
+func TestScheduler_RunNow(t *testing.T) {
+	repo := &mockIntegrationRepository{integrations: map[string]*domain.Integration{
+		"connector-1": newScheduledConnector("connector-1", nil),
+	}}
+	harvester := &mockHarvester{started: make(chan string, 1), release: make(chan struct{})}
+	close(harvester.release)
+
+	cfg := config.SchedulerConfig{CheckInterval: time.Hour, Workers: 1, QueueSize: 1}
+	scheduler := usecase.NewSchedulerUsecase(repo, &mockRunRepository{}, harvester, nil, cfg)
+
+	run, err := scheduler.RunNow(context.Background(), "connector-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if run.IntegrationID != "connector-1" || run.Trigger != string(domain.RunTriggerManual) {
+		t.Errorf("Expected a manual run of connector-1, got %+v", run)
+	}
+	if started := <-harvester.started; started != "connector-1:manual" {
+		t.Errorf("Expected manual harvest of connector-1, got %s", started)
+	}
+}
+
+// Test the job queue is reported down until it is served and while it is full
+func TestScheduler_Ping(t *testing.T) {
+	repo := &mockIntegrationRepository{integrations: map[string]*domain.Integration{
+		"connector-1": newScheduledConnector("connector-1", nil),
+	}}
+	cfg := config.SchedulerConfig{CheckInterval: time.Hour, Workers: 1, QueueSize: 1}
+	scheduler := usecase.NewSchedulerUsecase(repo, &mockRunRepository{}, &mockHarvester{}, nil, cfg)
+
+	if err := scheduler.Ping(context.Background()); err == nil || !strings.Contains(err.Error(), "not served") {
+		t.Errorf("Expected the queue not to be served, got %v", err)
+	}
+	if _, err := scheduler.Enqueue(context.Background(), "connector-1"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := scheduler.Ping(context.Background()); err == nil || !strings.Contains(err.Error(), "full") {
+		t.Errorf("Expected the queue to be full, got %v", err)
+	}
+}
+
+// Test a harvest does not start while another run of the integration holds the lock
+func TestHarvest_RejectsOverlappingRun(t *testing.T) {
+	harvests, runRepo, _, _ := newHarvestFixture(`{"connector": "rest", "url": "http://example.org", "target": "datasets"}`)
+	runRepo.locks = map[string]bool{"connector-1": true}
+
+	_, err := harvests.Harvest(context.Background(), "connector-1", domain.RunTriggerManual)
+	if !errors.Is(err, pkgerrors.ErrAlreadyExists) {
+		t.Errorf("Expected ErrAlreadyExists, got %v", err)
+	}
+	if len(runRepo.runs) != 0 {
+		t.Errorf("Expected no run to be recorded, got %d", len(runRepo.runs))
+	}
+}
@@ -4,9 +4,14 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"net/http"
 	"time"
 
+	"portal-data-backend/internal/integration/connector"
 	"portal-data-backend/internal/integration/domain"
+	"portal-data-backend/internal/integration/notifier"
+	"portal-data-backend/internal/integration/publisher"
+	pkgErrors "portal-data-backend/pkg/errors"
 
 	"github.com/google/uuid"
 )
@@ -17,17 +22,26 @@ type Usecase interface {
 	Create(ctx context.Context, req *domain.CreateIntegrationRequest, userID string) (*domain.IntegrationInfo, error)
 	Update(ctx context.Context, id string, req *domain.UpdateIntegrationRequest) (*domain.IntegrationInfo, error)
 	Delete(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) error
 	UpdateStatus(ctx context.Context, id string, status string) error
 	Sync(ctx context.Context, id string) error
+	// RotateSecrets replaces individual credentials in place
+	RotateSecrets(ctx context.Context, id string, req *domain.RotateSecretsRequest) (*domain.IntegrationInfo, error)
+	// ReencryptSecrets rewrites stored credentials with the current encryption key
+	ReencryptSecrets(ctx context.Context) (int, error)
+	// TestConnection dry-runs the configured endpoint and credentials without syncing
+	TestConnection(ctx context.Context, id string) (*domain.ConnectionTestResult, error)
 }
 
 type integrationUsecase struct {
-	repo domain.Repository
+	repo   domain.Repository
+	client *http.Client
 }
 
 func NewIntegrationUsecase(repo domain.Repository) Usecase {
 	return &integrationUsecase{
-		repo: repo,
+		repo:   repo,
+		client: &http.Client{Timeout: connectionTestTimeout},
 	}
 }
 
@@ -89,6 +103,7 @@ func (u *integrationUsecase) Create(ctx context.Context, req *domain.CreateInteg
 		Config:         req.Config,
 		Endpoint:       req.Endpoint,
 		APIKey:         req.APIKey,
+		Secrets:        req.Secrets,
 		Status:         string(domain.IntegrationStatusActive),
 		OrganizationID: req.OrganizationID,
 		CreatedBy:      userID,
@@ -96,6 +111,10 @@ func (u *integrationUsecase) Create(ctx context.Context, req *domain.CreateInteg
 		UpdatedAt:      now,
 	}
 
+	if err := u.validateConfig(integration); err != nil {
+		return nil, err
+	}
+
 	if err := u.repo.Create(ctx, integration); err != nil {
 		return nil, fmt.Errorf("failed to create integration: %w", err)
 	}
@@ -118,6 +137,8 @@ func (u *integrationUsecase) Update(ctx context.Context, id string, req *domain.
 	}
 	if req.Config != nil {
 		existing.Config = *req.Config
+		// The scheduler recomputes the next run from the new schedule
+		existing.NextRunAt = nil
 	}
 	if req.Endpoint != nil {
 		existing.Endpoint = req.Endpoint
@@ -130,6 +151,10 @@ func (u *integrationUsecase) Update(ctx context.Context, id string, req *domain.
 	}
 	existing.UpdatedAt = time.Now()
 
+	if err := u.validateConfig(existing); err != nil {
+		return nil, err
+	}
+
 	if err := u.repo.Update(ctx, id, existing); err != nil {
 		return nil, fmt.Errorf("failed to update integration: %w", err)
 	}
@@ -144,6 +169,13 @@ func (u *integrationUsecase) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+func (u *integrationUsecase) Restore(ctx context.Context, id string) error {
+	if err := u.repo.Restore(ctx, id); err != nil {
+		return fmt.Errorf("failed to restore integration: %w", err)
+	}
+	return nil
+}
+
 func (u *integrationUsecase) UpdateStatus(ctx context.Context, id string, status string) error {
 	if err := u.repo.UpdateStatus(ctx, id, status); err != nil {
 		return fmt.Errorf("failed to update integration status: %w", err)
@@ -158,7 +190,79 @@ func (u *integrationUsecase) Sync(ctx context.Context, id string) error {
 	return nil
 }
 
+func (u *integrationUsecase) RotateSecrets(ctx context.Context, id string, req *domain.RotateSecretsRequest) (*domain.IntegrationInfo, error) {
+	existing, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get integration: %w", err)
+	}
+
+	if req.APIKey != nil {
+		existing.APIKey = req.APIKey
+	}
+	if len(req.Secrets) > 0 && existing.Secrets == nil {
+		existing.Secrets = make(map[string]string, len(req.Secrets))
+	}
+	for name, value := range req.Secrets {
+		if name == "" {
+			return nil, fmt.Errorf("%w: secret name is required", pkgErrors.ErrInvalidInput)
+		}
+		if value == nil {
+			delete(existing.Secrets, name)
+		} else {
+			existing.Secrets[name] = *value
+		}
+	}
+	existing.UpdatedAt = time.Now()
+
+	// Config may reference the secrets that were just removed
+	if err := u.validateConfig(existing); err != nil {
+		return nil, err
+	}
+
+	if err := u.repo.Update(ctx, id, existing); err != nil {
+		return nil, fmt.Errorf("failed to update integration secrets: %w", err)
+	}
+
+	return u.toInfo(existing), nil
+}
+
+func (u *integrationUsecase) ReencryptSecrets(ctx context.Context) (int, error) {
+	changed, err := u.repo.ReencryptSecrets(ctx)
+	if err != nil {
+		return changed, fmt.Errorf("failed to reencrypt secrets: %w", err)
+	}
+	return changed, nil
+}
+
+// validateConfig rejects integrations whose config cannot be used by their type
+func (u *integrationUsecase) validateConfig(integration *domain.Integration) error {
+	switch integration.Type {
+	case string(domain.IntegrationTypeConnector):
+		_, err := connector.ParseConfig(integration)
+		return err
+	case string(domain.IntegrationTypePublisher):
+		_, err := publisher.ParseConfig(integration)
+		return err
+	case string(domain.IntegrationTypeInbound):
+		_, err := parseIngestConfig(integration)
+		return err
+	case string(domain.IntegrationTypeSlack), string(domain.IntegrationTypeTeams):
+		_, err := notifier.ParseConfig(integration)
+		return err
+	default:
+		return nil
+	}
+}
+
 func (u *integrationUsecase) toInfo(integration *domain.Integration) *domain.IntegrationInfo {
+	var secrets map[string]string
+	if len(integration.Secrets) > 0 {
+		secrets = make(map[string]string, len(integration.Secrets))
+		for name := range integration.Secrets {
+			secrets[name] = domain.RedactedSecret
+		}
+	}
+
 	return &domain.IntegrationInfo{
 		ID:             integration.ID,
 		Name:           integration.Name,
@@ -166,10 +270,14 @@ func (u *integrationUsecase) toInfo(integration *domain.Integration) *domain.Int
 		Description:    integration.Description,
 		Endpoint:       integration.Endpoint,
 		Status:         integration.Status,
+		HasAPIKey:      integration.APIKey != nil && *integration.APIKey != "",
+		Secrets:        secrets,
 		LastSyncAt:     integration.LastSyncAt,
+		NextRunAt:      integration.NextRunAt,
 		OrganizationID: integration.OrganizationID,
 		CreatedBy:      integration.CreatedBy,
 		CreatedAt:      integration.CreatedAt,
 		UpdatedAt:      integration.UpdatedAt,
+		DeletedAt:      integration.DeletedAt,
 	}
 }
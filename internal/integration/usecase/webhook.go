@@ -0,0 +1,470 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/infrastructure/events"
+	"portal-data-backend/infrastructure/logger"
+	"portal-data-backend/internal/integration/domain"
+	"portal-data-backend/internal/integration/notifier"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// Webhook request headers
+const (
+	HeaderEvent     = "X-Portal-Event"
+	HeaderDelivery  = "X-Portal-Delivery"
+	HeaderTimestamp = "X-Portal-Timestamp"
+	HeaderSignature = "X-Portal-Signature"
+)
+
+const (
+	// baseBackoff is the delay before the first retry; it doubles per attempt
+	baseBackoff = 30 * time.Second
+	// maxBackoff caps the delay between two attempts
+	maxBackoff = 6 * time.Hour
+	// maxLoggedBody bounds how much of a response body is kept in the attempt log
+	maxLoggedBody = 2048
+)
+
+// WebhookUsecase fans events out to subscribed webhook integrations and to the
+// channels of Slack and Teams integrations, and delivers them with signing,
+// retries and a dead-letter state
+type WebhookUsecase interface {
+	Publish(ctx context.Context, eventType string, data interface{}) error
+
+	ListSubscriptions(ctx context.Context, integrationID string) ([]*domain.Subscription, error)
+	Subscribe(ctx context.Context, integrationID string, req *domain.CreateSubscriptionRequest) (*domain.Subscription, error)
+	Unsubscribe(ctx context.Context, integrationID, id string) error
+
+	ListDeliveries(ctx context.Context, integrationID string, req *domain.ListDeliveriesRequest) (*domain.DeliveryListResponse, error)
+	GetDelivery(ctx context.Context, integrationID, id string) (*domain.DeliveryDetailResponse, error)
+	RetryDelivery(ctx context.Context, integrationID, id string) (*domain.Delivery, error)
+
+	// DispatchDue attempts every delivery whose next attempt is due
+	DispatchDue(ctx context.Context) (int, error)
+	// Run dispatches due deliveries periodically until ctx is cancelled
+	Run(ctx context.Context)
+}
+
+type webhookUsecase struct {
+	repo         domain.Repository
+	deliveryRepo domain.DeliveryRepository
+	client       *http.Client
+	cfg          config.WebhookConfig
+	now          func() time.Time
+}
+
+func NewWebhookUsecase(repo domain.Repository, deliveryRepo domain.DeliveryRepository, cfg config.WebhookConfig) WebhookUsecase {
+	return &webhookUsecase{
+		repo:         repo,
+		deliveryRepo: deliveryRepo,
+		client:       &http.Client{Timeout: cfg.Timeout},
+		cfg:          cfg,
+		now:          time.Now,
+	}
+}
+
+func (u *webhookUsecase) Publish(ctx context.Context, eventType string, data interface{}) error {
+	subscribers, err := u.deliveryRepo.ListSubscribers(ctx, eventType)
+	if err != nil {
+		return fmt.Errorf("failed to list subscribers: %w", err)
+	}
+	chats, err := u.listChatIntegrations(ctx)
+	if err != nil {
+		return err
+	}
+	if len(subscribers) == 0 && len(chats) == 0 {
+		return nil
+	}
+
+	event := domain.Event{
+		ID:         uuid.New().String(),
+		Type:       eventType,
+		OccurredAt: u.now(),
+		Data:       data,
+	}
+	// Events relayed from the outbox keep their ID, so receivers recognize
+	// redeliveries
+	if meta, ok := events.MetaFrom(ctx); ok {
+		event.ID, event.OccurredAt = meta.ID, meta.OccurredAt
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	for _, integration := range subscribers {
+		if err := u.queue(ctx, integration, &event, nil, payload); err != nil {
+			return err
+		}
+	}
+
+	// Chat integrations route events to channels through their config and
+	// receive a formatted message instead of the raw event
+	for _, integration := range chats {
+		cfg, err := notifier.ParseConfig(integration)
+		if err != nil {
+			logger.FromContext(ctx).Warn("skipping %s integration %s: %v", integration.Type, integration.ID, err)
+			continue
+		}
+		channels := notifier.Route(cfg, eventType)
+		if len(channels) == 0 {
+			continue
+		}
+
+		message, err := notifier.Format(integration.Type, &event)
+		if err != nil {
+			return fmt.Errorf("failed to format %s message: %w", integration.Type, err)
+		}
+		for _, channel := range channels {
+			if err := u.queue(ctx, integration, &event, &channel, message); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// queue stores a pending delivery of an event to an integration, and to one
+// of its channels for chat integrations
+func (u *webhookUsecase) queue(ctx context.Context, integration *domain.Integration, event *domain.Event, channel *string, payload []byte) error {
+	now := u.now()
+	delivery := &domain.Delivery{
+		ID:            uuid.New().String(),
+		IntegrationID: integration.ID,
+		EventID:       event.ID,
+		EventType:     event.Type,
+		Channel:       channel,
+		Payload:       string(payload),
+		Status:        string(domain.DeliveryStatusPending),
+		NextAttemptAt: &now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := u.deliveryRepo.CreateDelivery(ctx, delivery); err != nil {
+		return fmt.Errorf("failed to queue delivery: %w", err)
+	}
+	return nil
+}
+
+// listChatIntegrations returns the active Slack and Teams integrations
+func (u *webhookUsecase) listChatIntegrations(ctx context.Context) ([]*domain.Integration, error) {
+	activeStatus := string(domain.IntegrationStatusActive)
+
+	var chats []*domain.Integration
+	for _, chatType := range []domain.IntegrationType{domain.IntegrationTypeSlack, domain.IntegrationTypeTeams} {
+		integrationType := string(chatType)
+		filter := &domain.IntegrationFilter{Type: &integrationType, Status: &activeStatus}
+		integrations, _, err := u.repo.List(ctx, filter, 1000, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s integrations: %w", chatType, err)
+		}
+		chats = append(chats, integrations...)
+	}
+	return chats, nil
+}
+
+func (u *webhookUsecase) ListSubscriptions(ctx context.Context, integrationID string) ([]*domain.Subscription, error) {
+	if _, err := u.repo.GetByID(ctx, integrationID); err != nil {
+		return nil, fmt.Errorf("failed to get integration: %w", err)
+	}
+
+	subscriptions, err := u.deliveryRepo.ListSubscriptions(ctx, integrationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+	return subscriptions, nil
+}
+
+func (u *webhookUsecase) Subscribe(ctx context.Context, integrationID string, req *domain.CreateSubscriptionRequest) (*domain.Subscription, error) {
+	integration, err := u.repo.GetByID(ctx, integrationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get integration: %w", err)
+	}
+	if integration.Type != string(domain.IntegrationTypeWebhook) {
+		return nil, fmt.Errorf("only webhook integrations can subscribe to events: %w", pkgErrors.ErrInvalidInput)
+	}
+	if integration.Endpoint == nil || *integration.Endpoint == "" {
+		return nil, fmt.Errorf("webhook integration has no endpoint: %w", pkgErrors.ErrInvalidInput)
+	}
+
+	subscription := &domain.Subscription{
+		ID:            uuid.New().String(),
+		IntegrationID: integrationID,
+		EventType:     req.EventType,
+		CreatedAt:     u.now(),
+	}
+	if err := u.deliveryRepo.CreateSubscription(ctx, subscription); err != nil {
+		return nil, fmt.Errorf("failed to create subscription: %w", err)
+	}
+	return subscription, nil
+}
+
+func (u *webhookUsecase) Unsubscribe(ctx context.Context, integrationID, id string) error {
+	if err := u.deliveryRepo.DeleteSubscription(ctx, integrationID, id); err != nil {
+		return fmt.Errorf("failed to delete subscription: %w", err)
+	}
+	return nil
+}
+
+func (u *webhookUsecase) ListDeliveries(ctx context.Context, integrationID string, req *domain.ListDeliveriesRequest) (*domain.DeliveryListResponse, error) {
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.Limit < 1 {
+		req.Limit = 20
+	}
+
+	offset := (req.Page - 1) * req.Limit
+
+	deliveries, total, err := u.deliveryRepo.ListDeliveries(ctx, integrationID, req.Status, req.Limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deliveries: %w", err)
+	}
+
+	items := make([]domain.Delivery, len(deliveries))
+	for i, delivery := range deliveries {
+		items[i] = *delivery
+	}
+
+	totalPage := int(math.Ceil(float64(total) / float64(req.Limit)))
+
+	return &domain.DeliveryListResponse{
+		Deliveries: items,
+		Meta: domain.ListMeta{
+			Page:      req.Page,
+			Limit:     req.Limit,
+			Total:     total,
+			TotalPage: totalPage,
+		},
+	}, nil
+}
+
+func (u *webhookUsecase) GetDelivery(ctx context.Context, integrationID, id string) (*domain.DeliveryDetailResponse, error) {
+	delivery, err := u.getIntegrationDelivery(ctx, integrationID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	attempts, err := u.deliveryRepo.ListAttempts(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list delivery attempts: %w", err)
+	}
+
+	attemptLog := make([]domain.DeliveryAttempt, len(attempts))
+	for i, attempt := range attempts {
+		attemptLog[i] = *attempt
+	}
+
+	return &domain.DeliveryDetailResponse{Delivery: *delivery, AttemptLog: attemptLog}, nil
+}
+
+// RetryDelivery attempts a failed or dead-lettered delivery immediately. A
+// delivery that has exhausted its attempts goes back to the dead-letter state
+// if the manual attempt fails too.
+func (u *webhookUsecase) RetryDelivery(ctx context.Context, integrationID, id string) (*domain.Delivery, error) {
+	delivery, err := u.getIntegrationDelivery(ctx, integrationID, id)
+	if err != nil {
+		return nil, err
+	}
+	if delivery.Status == string(domain.DeliveryStatusSucceeded) {
+		return nil, fmt.Errorf("delivery already succeeded: %w", pkgErrors.ErrInvalidInput)
+	}
+
+	if err := u.deliver(ctx, delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+func (u *webhookUsecase) DispatchDue(ctx context.Context) (int, error) {
+	// The lease must outlive a full batch of attempts
+	lease := u.cfg.Timeout*time.Duration(u.cfg.BatchSize) + time.Minute
+
+	deliveries, err := u.deliveryRepo.ClaimDueDeliveries(ctx, u.cfg.BatchSize, lease)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim deliveries: %w", err)
+	}
+
+	for _, delivery := range deliveries {
+		if err := u.deliver(ctx, delivery); err != nil {
+			return 0, err
+		}
+	}
+	return len(deliveries), nil
+}
+
+func (u *webhookUsecase) Run(ctx context.Context) {
+	ticker := time.NewTicker(u.cfg.DispatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := u.DispatchDue(ctx); err != nil {
+				logger.FromContext(ctx).Error("webhook dispatch failed: %v", err)
+			}
+		}
+	}
+}
+
+func (u *webhookUsecase) getIntegrationDelivery(ctx context.Context, integrationID, id string) (*domain.Delivery, error) {
+	delivery, err := u.deliveryRepo.GetDelivery(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get delivery: %w", err)
+	}
+	if delivery.IntegrationID != integrationID {
+		return nil, pkgErrors.ErrNotFound
+	}
+	return delivery, nil
+}
+
+// deliver performs one HTTP attempt, logs it and moves the delivery to its
+// next state. A failed attempt is not an error; only persistence failures are.
+func (u *webhookUsecase) deliver(ctx context.Context, delivery *domain.Delivery) error {
+	integration, err := u.repo.GetByID(ctx, delivery.IntegrationID)
+	if err != nil {
+		if !pkgErrors.Is(err, pkgErrors.ErrNotFound) {
+			return fmt.Errorf("failed to get integration: %w", err)
+		}
+		// The integration was deleted after the event was queued
+		integration = nil
+	}
+
+	delivery.Attempts++
+	attempt := &domain.DeliveryAttempt{
+		ID:         uuid.New().String(),
+		DeliveryID: delivery.ID,
+		Attempt:    delivery.Attempts,
+		CreatedAt:  u.now(),
+	}
+
+	var sendErr error
+	if integration == nil {
+		sendErr = fmt.Errorf("integration has no endpoint")
+	} else if endpoint, err := deliveryEndpoint(integration, delivery); err != nil {
+		sendErr = err
+	} else {
+		start := time.Now()
+		status, body, err := u.send(ctx, integration, endpoint, delivery)
+		attempt.DurationMs = time.Since(start).Milliseconds()
+		if status != 0 {
+			attempt.ResponseStatus = &status
+			delivery.ResponseStatus = &status
+		}
+		if body != "" {
+			attempt.ResponseBody = &body
+		}
+		sendErr = err
+	}
+
+	now := u.now()
+	delivery.UpdatedAt = now
+	if sendErr == nil {
+		delivery.Status = string(domain.DeliveryStatusSucceeded)
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = nil
+		delivery.LastError = nil
+	} else {
+		msg := sendErr.Error()
+		attempt.Error = &msg
+		delivery.LastError = &msg
+		if delivery.Attempts >= u.cfg.MaxAttempts {
+			delivery.Status = string(domain.DeliveryStatusDead)
+			delivery.NextAttemptAt = nil
+		} else {
+			next := now.Add(backoff(delivery.Attempts))
+			delivery.Status = string(domain.DeliveryStatusFailed)
+			delivery.NextAttemptAt = &next
+		}
+	}
+
+	if err := u.deliveryRepo.CreateAttempt(ctx, attempt); err != nil {
+		return fmt.Errorf("failed to log delivery attempt: %w", err)
+	}
+	if err := u.deliveryRepo.UpdateDelivery(ctx, delivery); err != nil {
+		return fmt.Errorf("failed to update delivery: %w", err)
+	}
+	return nil
+}
+
+// deliveryEndpoint returns the URL a delivery is posted to: the incoming
+// webhook of its channel for chat integrations, the endpoint otherwise
+func deliveryEndpoint(integration *domain.Integration, delivery *domain.Delivery) (string, error) {
+	if delivery.Channel != nil {
+		return notifier.WebhookURL(integration, *delivery.Channel)
+	}
+	if integration.Endpoint == nil || *integration.Endpoint == "" {
+		return "", fmt.Errorf("integration has no endpoint")
+	}
+	return *integration.Endpoint, nil
+}
+
+// send POSTs the payload and treats any 2xx response as success
+func (u *webhookUsecase) send(ctx context.Context, integration *domain.Integration, endpoint string, delivery *domain.Delivery) (int, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewBufferString(delivery.Payload))
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to build request: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(u.now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderDelivery, delivery.ID)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	if integration.APIKey != nil && *integration.APIKey != "" {
+		req.Header.Set(HeaderSignature, Sign(*integration.APIKey, timestamp, []byte(delivery.Payload)))
+	}
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxLoggedBody))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, string(body), fmt.Errorf("endpoint responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, string(body), nil
+}
+
+// Sign returns the signature header value for a payload: an HMAC-SHA256 over
+// "<timestamp>.<payload>" keyed with the integration secret
+func Sign(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// backoff returns the delay before the attempt following the given one
+func backoff(attempts int) time.Duration {
+	delay := baseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return delay
+}
@@ -0,0 +1,329 @@
+package usecase_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/internal/integration/domain"
+	"portal-data-backend/internal/integration/usecase"
+	pkgerrors "portal-data-backend/pkg/errors"
+)
+
+// mockIntegrationRepository is a mock implementation of Repository
+type mockIntegrationRepository struct {
+	integrations map[string]*domain.Integration
+}
+
+func (m *mockIntegrationRepository) GetByID(ctx context.Context, id string) (*domain.Integration, error) {
+	integration, ok := m.integrations[id]
+	if !ok {
+		return nil, pkgerrors.ErrNotFound
+	}
+	return integration, nil
+}
+
+func (m *mockIntegrationRepository) List(ctx context.Context, filter *domain.IntegrationFilter, limit, offset int) ([]*domain.Integration, int, error) {
+	var integrations []*domain.Integration
+	for _, integration := range m.integrations {
+		if filter != nil && filter.Type != nil && integration.Type != *filter.Type {
+			continue
+		}
+		if filter != nil && filter.Status != nil && integration.Status != *filter.Status {
+			continue
+		}
+		integrations = append(integrations, integration)
+	}
+	return integrations, len(integrations), nil
+}
+
+func (m *mockIntegrationRepository) Create(ctx context.Context, integration *domain.Integration) error {
+	m.integrations[integration.ID] = integration
+	return nil
+}
+
+func (m *mockIntegrationRepository) Update(ctx context.Context, id string, integration *domain.Integration) error {
+	m.integrations[id] = integration
+	return nil
+}
+
+func (m *mockIntegrationRepository) Delete(ctx context.Context, id string) error {
+	delete(m.integrations, id)
+	return nil
+}
+
+func (m *mockIntegrationRepository) Restore(ctx context.Context, id string) error {
+	return nil
+}
+
+func (m *mockIntegrationRepository) UpdateStatus(ctx context.Context, id string, status string) error {
+	return nil
+}
+
+func (m *mockIntegrationRepository) Sync(ctx context.Context, id string) error {
+	return nil
+}
+
+func (m *mockIntegrationRepository) ReencryptSecrets(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+// mockDeliveryRepository is an in-memory implementation of DeliveryRepository
+type mockDeliveryRepository struct {
+	subscribers []*domain.Integration
+	deliveries  map[string]*domain.Delivery
+	attempts    []*domain.DeliveryAttempt
+}
+
+func (m *mockDeliveryRepository) ListSubscriptions(ctx context.Context, integrationID string) ([]*domain.Subscription, error) {
+	return nil, nil
+}
+
+func (m *mockDeliveryRepository) CreateSubscription(ctx context.Context, subscription *domain.Subscription) error {
+	return nil
+}
+
+func (m *mockDeliveryRepository) DeleteSubscription(ctx context.Context, integrationID, id string) error {
+	return nil
+}
+
+func (m *mockDeliveryRepository) ListSubscribers(ctx context.Context, eventType string) ([]*domain.Integration, error) {
+	return m.subscribers, nil
+}
+
+func (m *mockDeliveryRepository) CreateDelivery(ctx context.Context, delivery *domain.Delivery) error {
+	m.deliveries[delivery.ID] = delivery
+	return nil
+}
+
+func (m *mockDeliveryRepository) GetDelivery(ctx context.Context, id string) (*domain.Delivery, error) {
+	delivery, ok := m.deliveries[id]
+	if !ok {
+		return nil, pkgerrors.ErrNotFound
+	}
+	return delivery, nil
+}
+
+func (m *mockDeliveryRepository) UpdateDelivery(ctx context.Context, delivery *domain.Delivery) error {
+	m.deliveries[delivery.ID] = delivery
+	return nil
+}
+
+func (m *mockDeliveryRepository) ListDeliveries(ctx context.Context, integrationID string, status *string, limit, offset int) ([]*domain.Delivery, int, error) {
+	return nil, 0, nil
+}
+
+func (m *mockDeliveryRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*domain.Delivery, error) {
+	var due []*domain.Delivery
+	for _, delivery := range m.deliveries {
+		if delivery.NextAttemptAt != nil && !delivery.NextAttemptAt.After(time.Now()) &&
+			(delivery.Status == string(domain.DeliveryStatusPending) || delivery.Status == string(domain.DeliveryStatusFailed)) {
+			due = append(due, delivery)
+		}
+	}
+	return due, nil
+}
+
+func (m *mockDeliveryRepository) CreateAttempt(ctx context.Context, attempt *domain.DeliveryAttempt) error {
+	m.attempts = append(m.attempts, attempt)
+	return nil
+}
+
+func (m *mockDeliveryRepository) ListAttempts(ctx context.Context, deliveryID string) ([]*domain.DeliveryAttempt, error) {
+	return m.attempts, nil
+}
+
+func newWebhookFixture(endpoint string, maxAttempts int) (usecase.WebhookUsecase, *mockDeliveryRepository) {
+	secret := "webhook-secret"
+	integration := &domain.Integration{
+		ID:       "integration-1",
+		Type:     string(domain.IntegrationTypeWebhook),
+		Status:   string(domain.IntegrationStatusActive),
+		Endpoint: &endpoint,
+		APIKey:   &secret,
+	}
+
+	repo := &mockIntegrationRepository{integrations: map[string]*domain.Integration{integration.ID: integration}}
+	deliveryRepo := &mockDeliveryRepository{
+		subscribers: []*domain.Integration{integration},
+		deliveries:  make(map[string]*domain.Delivery),
+	}
+
+	cfg := config.WebhookConfig{
+		Timeout:          time.Second,
+		MaxAttempts:      maxAttempts,
+		DispatchInterval: time.Second,
+		BatchSize:        10,
+	}
+
+	return usecase.NewWebhookUsecase(repo, deliveryRepo, cfg), deliveryRepo
+}
+
+func onlyDelivery(t *testing.T, repo *mockDeliveryRepository) *domain.Delivery {
+	t.Helper()
+	if len(repo.deliveries) != 1 {
+		t.Fatalf("Expected 1 delivery, got %d", len(repo.deliveries))
+	}
+	for _, delivery := range repo.deliveries {
+		return delivery
+	}
+	return nil
+}
+
+// Test a successful delivery is signed and marked succeeded
+func TestWebhookDispatch_Success(t *testing.T) {
+	var gotSignature, gotTimestamp string
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSignature = r.Header.Get(usecase.HeaderSignature)
+		gotTimestamp = r.Header.Get(usecase.HeaderTimestamp)
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	webhooks, repo := newWebhookFixture(server.URL, 3)
+	ctx := context.Background()
+
+	if err := webhooks.Publish(ctx, "dataset.created", map[string]string{"id": "ds-1"}); err != nil {
+		t.Fatalf("Expected no error publishing, got %v", err)
+	}
+
+	n, err := webhooks.DispatchDue(ctx)
+	if err != nil {
+		t.Fatalf("Expected no error dispatching, got %v", err)
+	}
+	if n != 1 {
+		t.Errorf("Expected 1 dispatched delivery, got %d", n)
+	}
+
+	delivery := onlyDelivery(t, repo)
+	if delivery.Status != string(domain.DeliveryStatusSucceeded) {
+		t.Errorf("Expected status succeeded, got %s", delivery.Status)
+	}
+	if delivery.DeliveredAt == nil {
+		t.Error("Expected delivered_at to be set")
+	}
+
+	want := usecase.Sign("webhook-secret", gotTimestamp, gotBody)
+	if gotSignature != want {
+		t.Errorf("Expected signature %s, got %s", want, gotSignature)
+	}
+}
+
+// Test failed deliveries are retried with backoff and then dead-lettered
+func TestWebhookDispatch_RetryThenDead(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	webhooks, repo := newWebhookFixture(server.URL, 2)
+	ctx := context.Background()
+
+	if err := webhooks.Publish(ctx, "dataset.updated", nil); err != nil {
+		t.Fatalf("Expected no error publishing, got %v", err)
+	}
+	if _, err := webhooks.DispatchDue(ctx); err != nil {
+		t.Fatalf("Expected no error dispatching, got %v", err)
+	}
+
+	delivery := onlyDelivery(t, repo)
+	if delivery.Status != string(domain.DeliveryStatusFailed) {
+		t.Errorf("Expected status failed, got %s", delivery.Status)
+	}
+	if delivery.NextAttemptAt == nil || !delivery.NextAttemptAt.After(time.Now()) {
+		t.Error("Expected next attempt to be scheduled in the future")
+	}
+	if delivery.ResponseStatus == nil || *delivery.ResponseStatus != http.StatusInternalServerError {
+		t.Errorf("Expected response status 500, got %v", delivery.ResponseStatus)
+	}
+
+	// Second attempt exhausts the retries
+	if _, err := webhooks.RetryDelivery(ctx, delivery.IntegrationID, delivery.ID); err != nil {
+		t.Fatalf("Expected no error retrying, got %v", err)
+	}
+	if delivery.Status != string(domain.DeliveryStatusDead) {
+		t.Errorf("Expected status dead, got %s", delivery.Status)
+	}
+	if len(repo.attempts) != 2 {
+		t.Errorf("Expected 2 logged attempts, got %d", len(repo.attempts))
+	}
+}
+
+// Test a delivery cannot be retried through another integration
+func TestWebhookRetry_WrongIntegration(t *testing.T) {
+	webhooks, repo := newWebhookFixture("http://127.0.0.1:0", 3)
+	ctx := context.Background()
+
+	if err := webhooks.Publish(ctx, "dataset.deleted", nil); err != nil {
+		t.Fatalf("Expected no error publishing, got %v", err)
+	}
+	delivery := onlyDelivery(t, repo)
+
+	_, err := webhooks.RetryDelivery(ctx, "other-integration", delivery.ID)
+	if !pkgerrors.Is(err, pkgerrors.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+// Test chat integrations get a formatted message on each channel routed to the event
+func TestWebhookPublish_RoutesChatChannels(t *testing.T) {
+	bodies := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies[r.URL.Path] = string(body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	integration := &domain.Integration{
+		ID:     "slack-1",
+		Type:   string(domain.IntegrationTypeSlack),
+		Status: string(domain.IntegrationStatusActive),
+		Config: `{"channels": [
+			{"name": "helpdesk", "events": ["ticket.created", "ticket.sla_breached"]},
+			{"name": "data-team", "events": ["dataset.pending_review"]}
+		]}`,
+		Secrets: map[string]string{"helpdesk": server.URL + "/helpdesk", "data-team": server.URL + "/data-team"},
+	}
+	repo := &mockIntegrationRepository{integrations: map[string]*domain.Integration{integration.ID: integration}}
+	deliveryRepo := &mockDeliveryRepository{deliveries: make(map[string]*domain.Delivery)}
+	cfg := config.WebhookConfig{Timeout: time.Second, MaxAttempts: 3, DispatchInterval: time.Second, BatchSize: 10}
+	webhooks := usecase.NewWebhookUsecase(repo, deliveryRepo, cfg)
+	ctx := context.Background()
+
+	ticket := map[string]string{"title": "Cannot download <dataset>", "priority": "high"}
+	if err := webhooks.Publish(ctx, "ticket.created", ticket); err != nil {
+		t.Fatalf("Expected no error publishing, got %v", err)
+	}
+
+	delivery := onlyDelivery(t, deliveryRepo)
+	if delivery.Channel == nil || *delivery.Channel != "helpdesk" {
+		t.Fatalf("Expected delivery to the helpdesk channel, got %v", delivery.Channel)
+	}
+	if _, err := webhooks.DispatchDue(ctx); err != nil {
+		t.Fatalf("Expected no error dispatching, got %v", err)
+	}
+	if delivery.Status != string(domain.DeliveryStatusSucceeded) {
+		t.Errorf("Expected succeeded, got %s", delivery.Status)
+	}
+
+	body, ok := bodies["/helpdesk"]
+	if !ok || len(bodies) != 1 {
+		t.Fatalf("Expected a single post to the helpdesk webhook, got %v", bodies)
+	}
+	for _, want := range []string{
+		`"text":"New helpdesk ticket: Cannot download &lt;dataset&gt;"`,
+		`"text":"*Priority*\nhigh"`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected message to contain %s, got %s", want, body)
+		}
+	}
+}
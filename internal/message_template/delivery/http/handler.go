@@ -0,0 +1,167 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"portal-data-backend/infrastructure/auth"
+	"portal-data-backend/infrastructure/http/httputil"
+	"portal-data-backend/infrastructure/http/middleware"
+	"portal-data-backend/infrastructure/http/problem"
+	"portal-data-backend/infrastructure/http/response"
+	templateDomain "portal-data-backend/internal/message_template/domain"
+	"portal-data-backend/internal/message_template/usecase"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	templateUsecase usecase.Usecase
+}
+
+func NewHandler(templateUsecase usecase.Usecase) *Handler {
+	return &Handler{templateUsecase: templateUsecase}
+}
+
+// Definitions lists the messages templates can be stored for, with their
+// variables and built-in wording
+func (h *Handler) Definitions(w http.ResponseWriter, r *http.Request) {
+	response.OK(w, response.CodeSuccess, "Message definitions retrieved successfully", h.templateUsecase.Definitions(r.Context()))
+}
+
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	req := &templateDomain.ListTemplatesRequest{
+		Page:    parseIntQuery(r, "page", 1),
+		Limit:   parseIntQuery(r, "limit", 20),
+		Key:     r.URL.Query().Get("key"),
+		Channel: r.URL.Query().Get("channel"),
+		Locale:  r.URL.Query().Get("locale"),
+	}
+
+	resp, err := h.templateUsecase.List(r.Context(), req)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Message templates retrieved successfully", resp)
+}
+
+func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	tmpl, err := h.templateUsecase.GetByID(r.Context(), id)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Message template retrieved successfully", tmpl)
+}
+
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	req, ok := httputil.Decode[templateDomain.CreateTemplateRequest](w, r)
+	if !ok {
+		return
+	}
+
+	userID := auth.UserID(r.Context())
+
+	tmpl, err := h.templateUsecase.Create(r.Context(), req, userID)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.Created(w, response.CodeCreated, "Message template created successfully", tmpl)
+}
+
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	req, ok := httputil.Decode[templateDomain.UpdateTemplateRequest](w, r)
+	if !ok {
+		return
+	}
+
+	userID := auth.UserID(r.Context())
+
+	tmpl, err := h.templateUsecase.Update(r.Context(), id, req, userID)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Message template updated successfully", tmpl)
+}
+
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.templateUsecase.Delete(r.Context(), id); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Message template deleted successfully", nil)
+}
+
+// Preview renders a message, stored or drafted, without sending it
+func (h *Handler) Preview(w http.ResponseWriter, r *http.Request) {
+	req, ok := httputil.Decode[templateDomain.PreviewRequest](w, r)
+	if !ok {
+		return
+	}
+
+	msg, err := h.templateUsecase.Preview(r.Context(), req)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Message rendered successfully", msg)
+}
+
+// errorMapper maps the errors of the message template module on top of the shared ones
+var errorMapper = problem.Default.With(
+	problem.Mapping{Err: pkgErrors.ErrNotFound, Status: http.StatusNotFound, Code: response.CodeNotFound, Message: "Message template not found"},
+)
+
+func (h *Handler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	errorMapper.Write(w, r, err)
+}
+
+func parseIntQuery(r *http.Request, key string, defaultValue int) int {
+	if value := r.URL.Query().Get(key); value != "" {
+		if intValue, err := strconv.Atoi(value); err == nil {
+			return intValue
+		}
+	}
+	return defaultValue
+}
+
+// RegisterRoutes registers the message template routes, for users with one
+// of adminRoles
+func RegisterRoutes(r chi.Router, handler *Handler, auth func(http.Handler) http.Handler, adminRoles []string) {
+	r.Route("/admin/message-templates", func(r chi.Router) {
+		r.Use(auth)
+		r.Use(middleware.RequireRole(adminRoles...))
+		r.Get("/definitions", handler.Definitions)
+		r.Post("/preview", handler.Preview)
+		r.Get("/", handler.List)
+		r.Post("/", handler.Create)
+		r.Get("/{id}", handler.GetByID)
+		r.Put("/{id}", handler.Update)
+		r.Delete("/{id}", handler.Delete)
+	})
+}
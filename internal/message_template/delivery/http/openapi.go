@@ -0,0 +1,20 @@
+package http
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	templateDomain "portal-data-backend/internal/message_template/domain"
+)
+
+// Describe adds the message template routes to the API specification
+func Describe(spec *openapi.Spec) {
+	api := spec.Tag("message-templates", "Wording of the mail and notifications users are sent")
+	api.Get("/admin/message-templates/definitions", "List message definitions").Returns(http.StatusOK, templateDomain.DefinitionListResponse{})
+	api.Post("/admin/message-templates/preview", "Preview message").Body(templateDomain.PreviewRequest{}).Returns(http.StatusOK, templateDomain.Message{})
+	api.Get("/admin/message-templates", "List message templates").Query(templateDomain.ListTemplatesRequest{}).Returns(http.StatusOK, templateDomain.TemplateListResponse{})
+	api.Post("/admin/message-templates", "Create message template").Body(templateDomain.CreateTemplateRequest{}).Returns(http.StatusCreated, templateDomain.Template{})
+	api.Get("/admin/message-templates/{id}", "Get message template").Returns(http.StatusOK, templateDomain.Template{})
+	api.Put("/admin/message-templates/{id}", "Update message template").Body(templateDomain.UpdateTemplateRequest{}).Returns(http.StatusOK, templateDomain.Template{})
+	api.Delete("/admin/message-templates/{id}", "Delete message template").Returns(http.StatusOK, nil)
+}
@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Channel is the way a message reaches its recipient
+type Channel string
+
+const (
+	ChannelEmail        Channel = "email"
+	ChannelNotification Channel = "notification"
+)
+
+// Definition is a message a module sends. Its templates may refer to
+// Variables as {{.name}}; Subject and Body are the built-in wording, used
+// when no template is stored for the locale of the recipient.
+type Definition struct {
+	Key         string   `json:"key"`
+	Channel     Channel  `json:"channel"`
+	Description string   `json:"description"`
+	Variables   []string `json:"variables"`
+	Subject     string   `json:"subject"`
+	Body        string   `json:"body"`
+}
+
+// Template is the wording of a message in a locale, replacing the built-in
+// one. Subject is the subject of mail and the title of notifications.
+type Template struct {
+	ID        string    `db:"id" json:"id"`
+	Key       string    `db:"key" json:"key"`
+	Channel   Channel   `db:"channel" json:"channel"`
+	Locale    string    `db:"locale" json:"locale"`
+	Subject   string    `db:"subject" json:"subject"`
+	Body      string    `db:"body" json:"body"`
+	UpdatedBy *string   `db:"updated_by" json:"updated_by,omitempty"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// Message is a rendered message. Locale is the locale of the template it was
+// rendered from, empty for the built-in wording.
+type Message struct {
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+	Locale  string `json:"locale,omitempty"`
+}
+
+// Render renders subject and body with vars. Referring to a variable vars
+// does not have fails.
+func Render(subject, body string, vars map[string]string) (*Message, error) {
+	renderedSubject, err := render("subject", subject, vars)
+	if err != nil {
+		return nil, err
+	}
+	renderedBody, err := render("body", body, vars)
+	if err != nil {
+		return nil, err
+	}
+	if strings.ContainsAny(renderedSubject, "\r\n") {
+		return nil, fmt.Errorf("subject must be a single line")
+	}
+	return &Message{Subject: renderedSubject, Body: renderedBody}, nil
+}
+
+func render(name, text string, vars map[string]string) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, vars); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// ListTemplatesRequest represents list templates input
+type ListTemplatesRequest struct {
+	Page    int    `json:"page" validate:"min=1"`
+	Limit   int    `json:"limit" validate:"min=1,max=100"`
+	Key     string `json:"key,omitempty"`
+	Channel string `json:"channel,omitempty"`
+	Locale  string `json:"locale,omitempty"`
+}
+
+// CreateTemplateRequest represents create template input
+type CreateTemplateRequest struct {
+	Key     string  `json:"key" validate:"required,max=100"`
+	Channel Channel `json:"channel" validate:"required,oneof=email notification"`
+	Locale  string  `json:"locale" validate:"required,max=35"`
+	Subject string  `json:"subject" validate:"required,max=200"`
+	Body    string  `json:"body" validate:"required,max=10000"`
+}
+
+// UpdateTemplateRequest represents update template input
+type UpdateTemplateRequest struct {
+	Subject string `json:"subject" validate:"required,max=200"`
+	Body    string `json:"body" validate:"required,max=10000"`
+}
+
+// PreviewRequest represents a message to render without sending it. Without
+// Subject and Body, the template stored for Locale is rendered, or else the
+// built-in wording. Variables missing from Variables show as {name}.
+type PreviewRequest struct {
+	Key       string            `json:"key" validate:"required"`
+	Channel   Channel           `json:"channel" validate:"required,oneof=email notification"`
+	Locale    string            `json:"locale,omitempty" validate:"max=35"`
+	Subject   string            `json:"subject,omitempty" validate:"max=200"`
+	Body      string            `json:"body,omitempty" validate:"max=10000"`
+	Variables map[string]string `json:"variables,omitempty"`
+}
+
+// DefinitionListResponse represents the messages templates can be stored for
+type DefinitionListResponse struct {
+	Definitions []Definition `json:"definitions"`
+}
+
+// TemplateListResponse represents paginated templates
+type TemplateListResponse struct {
+	Templates []Template `json:"templates"`
+	Meta      ListMeta   `json:"meta"`
+}
+
+// ListMeta represents pagination metadata
+type ListMeta struct {
+	Page      int `json:"page"`
+	Limit     int `json:"limit"`
+	Total     int `json:"total"`
+	TotalPage int `json:"total_page"`
+}
@@ -0,0 +1,21 @@
+package domain
+
+import "context"
+
+// Repository defines the interface for message template data access
+type Repository interface {
+	GetByID(ctx context.Context, id string) (*Template, error)
+	List(ctx context.Context, filter *TemplateFilter, limit, offset int) ([]*Template, int, error)
+	// Find returns the templates of a message in any of locales
+	Find(ctx context.Context, channel Channel, key string, locales []string) ([]*Template, error)
+	Create(ctx context.Context, tmpl *Template) error
+	Update(ctx context.Context, tmpl *Template) error
+	Delete(ctx context.Context, id string) error
+}
+
+// TemplateFilter selects templates; empty fields match every template
+type TemplateFilter struct {
+	Key     string
+	Channel string
+	Locale  string
+}
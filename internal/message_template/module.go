@@ -0,0 +1,47 @@
+// Package messagetemplate is the module keeping the wording of the mail and
+// notifications other modules send.
+package messagetemplate
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	"portal-data-backend/internal/app"
+	delivery "portal-data-backend/internal/message_template/delivery/http"
+	"portal-data-backend/internal/message_template/repository"
+	"portal-data-backend/internal/message_template/usecase"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Module renders the messages of the modules sending mail and notifications,
+// which define them with their built-in wording in Register
+type Module struct {
+	handler    *delivery.Handler
+	adminRoles []string
+}
+
+// Name implements app.Module
+func (m *Module) Name() string {
+	return "message_template"
+}
+
+// Register implements app.Module
+func (m *Module) Register(deps *app.Deps) error {
+	repo := repository.NewMessageTemplatePostgresRepository(deps.DB)
+	templates := usecase.NewMessageTemplateUsecase(repo, deps.Services.Audit, deps.Config.I18n.DefaultLocale)
+	deps.Services.Templates = templates
+	m.handler = delivery.NewHandler(templates)
+	m.adminRoles = deps.Config.Audit.AdminRoles
+	return nil
+}
+
+// Routes implements app.Module
+func (m *Module) Routes(r chi.Router, auth func(http.Handler) http.Handler) {
+	delivery.RegisterRoutes(r, m.handler, auth, m.adminRoles)
+}
+
+// Describe implements app.Module
+func (m *Module) Describe(spec *openapi.Spec) {
+	delivery.Describe(spec)
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"portal-data-backend/infrastructure/db"
+	"portal-data-backend/internal/message_template/domain"
+	"portal-data-backend/pkg/errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type messageTemplatePostgresRepository struct {
+	db *sqlx.DB
+}
+
+// NewMessageTemplatePostgresRepository creates a new message template repository
+func NewMessageTemplatePostgresRepository(db *sqlx.DB) domain.Repository {
+	return &messageTemplatePostgresRepository{db: db}
+}
+
+const templateColumns = `id, key, channel, locale, subject, body, updated_by, created_at, updated_at`
+
+func (r *messageTemplatePostgresRepository) GetByID(ctx context.Context, id string) (*domain.Template, error) {
+	query := `SELECT ` + templateColumns + ` FROM message_templates WHERE id = $1`
+	var tmpl domain.Template
+	err := db.Conn(ctx, r.db).GetContext(ctx, &tmpl, query, id)
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+	return &tmpl, nil
+}
+
+func (r *messageTemplatePostgresRepository) List(ctx context.Context, filter *domain.TemplateFilter, limit, offset int) ([]*domain.Template, int, error) {
+	whereClause := "WHERE 1=1"
+	args := []interface{}{}
+	argCount := 1
+
+	if filter.Key != "" {
+		whereClause += fmt.Sprintf(" AND key = $%d", argCount)
+		args = append(args, filter.Key)
+		argCount++
+	}
+	if filter.Channel != "" {
+		whereClause += fmt.Sprintf(" AND channel = $%d", argCount)
+		args = append(args, filter.Channel)
+		argCount++
+	}
+	if filter.Locale != "" {
+		whereClause += fmt.Sprintf(" AND locale = $%d", argCount)
+		args = append(args, filter.Locale)
+		argCount++
+	}
+
+	countQuery := "SELECT COUNT(*) FROM message_templates " + whereClause
+	var total int
+	err := db.Conn(ctx, r.db).GetContext(ctx, &total, countQuery, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count message templates: %w", err)
+	}
+
+	query := "SELECT " + templateColumns + " FROM message_templates " + whereClause +
+		fmt.Sprintf(" ORDER BY key ASC, channel ASC, locale ASC LIMIT $%d OFFSET $%d", argCount, argCount+1)
+	args = append(args, limit, offset)
+
+	var templates []*domain.Template
+	err = db.Conn(ctx, r.db).SelectContext(ctx, &templates, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list message templates: %w", err)
+	}
+	return templates, total, nil
+}
+
+func (r *messageTemplatePostgresRepository) Find(ctx context.Context, channel domain.Channel, key string, locales []string) ([]*domain.Template, error) {
+	query := `SELECT ` + templateColumns + ` FROM message_templates WHERE channel = $1 AND key = $2 AND locale = ANY($3)`
+	var templates []*domain.Template
+	err := db.Conn(ctx, r.db).SelectContext(ctx, &templates, query, channel, key, pq.Array(locales))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find message templates: %w", err)
+	}
+	return templates, nil
+}
+
+func (r *messageTemplatePostgresRepository) Create(ctx context.Context, tmpl *domain.Template) error {
+	query := `
+		INSERT INTO message_templates (id, key, channel, locale, subject, body, updated_by, created_at, updated_at)
+		VALUES (:id, :key, :channel, :locale, :subject, :body, :updated_by, :created_at, :updated_at)
+	`
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, tmpl)
+	if err != nil {
+		return fmt.Errorf("failed to create message template: %w", err)
+	}
+	return nil
+}
+
+func (r *messageTemplatePostgresRepository) Update(ctx context.Context, tmpl *domain.Template) error {
+	query := `
+		UPDATE message_templates
+		SET subject = :subject, body = :body, updated_by = :updated_by, updated_at = :updated_at
+		WHERE id = :id
+	`
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, tmpl)
+	if err != nil {
+		return fmt.Errorf("failed to update message template: %w", err)
+	}
+	return nil
+}
+
+func (r *messageTemplatePostgresRepository) Delete(ctx context.Context, id string) error {
+	_, err := db.Conn(ctx, r.db).ExecContext(ctx, `DELETE FROM message_templates WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete message template: %w", err)
+	}
+	return nil
+}
+
+func (r *messageTemplatePostgresRepository) handleError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if err == sql.ErrNoRows {
+		return errors.ErrNotFound
+	}
+	return errors.Wrap(err, "database error")
+}
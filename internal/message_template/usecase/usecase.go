@@ -0,0 +1,312 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"portal-data-backend/infrastructure/audit"
+	"portal-data-backend/infrastructure/logger"
+	"portal-data-backend/internal/message_template/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+	"portal-data-backend/pkg/i18n"
+
+	"github.com/google/uuid"
+)
+
+// Usecase keeps the registry of the messages modules send and the templates
+// content teams word them with
+type Usecase interface {
+	// Define registers messages a module sends, with their built-in wording
+	Define(defs ...domain.Definition)
+	// Render renders a defined message with vars, from the template for the
+	// first language ctx prefers that has one, else the template for the
+	// default locale, else the built-in wording
+	Render(ctx context.Context, channel domain.Channel, key string, vars map[string]string) (*domain.Message, error)
+
+	Definitions(ctx context.Context) *domain.DefinitionListResponse
+	List(ctx context.Context, req *domain.ListTemplatesRequest) (*domain.TemplateListResponse, error)
+	GetByID(ctx context.Context, id string) (*domain.Template, error)
+	// Create stores the wording of a defined message in a locale. The
+	// template must render with the variables of the message.
+	Create(ctx context.Context, req *domain.CreateTemplateRequest, userID string) (*domain.Template, error)
+	Update(ctx context.Context, id string, req *domain.UpdateTemplateRequest, userID string) (*domain.Template, error)
+	// Delete removes a template, so the message falls back to the built-in
+	// wording
+	Delete(ctx context.Context, id string) error
+	// Preview renders a message without sending it
+	Preview(ctx context.Context, req *domain.PreviewRequest) (*domain.Message, error)
+}
+
+type messageTemplateUsecase struct {
+	repo          domain.Repository
+	audit         *audit.Recorder
+	defaultLocale string
+
+	mu          sync.RWMutex
+	definitions map[string]domain.Definition
+}
+
+// NewMessageTemplateUsecase creates the message template usecase. Changes
+// are audited through recorder, which may be nil.
+func NewMessageTemplateUsecase(repo domain.Repository, recorder *audit.Recorder, defaultLocale string) Usecase {
+	return &messageTemplateUsecase{
+		repo:          repo,
+		audit:         recorder,
+		defaultLocale: normalizeLocale(defaultLocale),
+		definitions:   map[string]domain.Definition{},
+	}
+}
+
+func (u *messageTemplateUsecase) Define(defs ...domain.Definition) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for _, def := range defs {
+		u.definitions[definitionKey(def.Channel, def.Key)] = def
+	}
+}
+
+func (u *messageTemplateUsecase) Render(ctx context.Context, channel domain.Channel, key string, vars map[string]string) (*domain.Message, error) {
+	def, err := u.definition(channel, key)
+	if err != nil {
+		return nil, err
+	}
+
+	locales := append(append([]string(nil), i18n.Languages(ctx)...), u.defaultLocale)
+	templates, err := u.repo.Find(ctx, channel, key, locales)
+	if err != nil {
+		// The built-in wording still reaches the recipient
+		logger.FromContext(ctx).Error("failed to find templates of %s message %s: %v", channel, key, err)
+	}
+	if tmpl := pick(templates, locales); tmpl != nil {
+		msg, err := domain.Render(tmpl.Subject, tmpl.Body, vars)
+		if err == nil {
+			msg.Locale = tmpl.Locale
+			return msg, nil
+		}
+		logger.FromContext(ctx).Error("failed to render template %s, using the built-in wording: %v", tmpl.ID, err)
+	}
+
+	msg, err := domain.Render(def.Subject, def.Body, vars)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render %s message %s: %w", channel, key, err)
+	}
+	return msg, nil
+}
+
+func (u *messageTemplateUsecase) Definitions(ctx context.Context) *domain.DefinitionListResponse {
+	u.mu.RLock()
+	defs := make([]domain.Definition, 0, len(u.definitions))
+	for _, def := range u.definitions {
+		defs = append(defs, def)
+	}
+	u.mu.RUnlock()
+
+	sort.Slice(defs, func(i, j int) bool {
+		if defs[i].Key != defs[j].Key {
+			return defs[i].Key < defs[j].Key
+		}
+		return defs[i].Channel < defs[j].Channel
+	})
+	return &domain.DefinitionListResponse{Definitions: defs}
+}
+
+func (u *messageTemplateUsecase) List(ctx context.Context, req *domain.ListTemplatesRequest) (*domain.TemplateListResponse, error) {
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.Limit < 1 {
+		req.Limit = 20
+	}
+
+	offset := (req.Page - 1) * req.Limit
+	filter := &domain.TemplateFilter{Key: req.Key, Channel: req.Channel, Locale: normalizeLocale(req.Locale)}
+
+	templates, total, err := u.repo.List(ctx, filter, req.Limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list message templates: %w", err)
+	}
+
+	items := make([]domain.Template, len(templates))
+	for i, tmpl := range templates {
+		items[i] = *tmpl
+	}
+
+	return &domain.TemplateListResponse{
+		Templates: items,
+		Meta: domain.ListMeta{
+			Page:      req.Page,
+			Limit:     req.Limit,
+			Total:     total,
+			TotalPage: int(math.Ceil(float64(total) / float64(req.Limit))),
+		},
+	}, nil
+}
+
+func (u *messageTemplateUsecase) GetByID(ctx context.Context, id string) (*domain.Template, error) {
+	tmpl, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message template: %w", err)
+	}
+	return tmpl, nil
+}
+
+func (u *messageTemplateUsecase) Create(ctx context.Context, req *domain.CreateTemplateRequest, userID string) (*domain.Template, error) {
+	def, err := u.definition(req.Channel, req.Key)
+	if err != nil {
+		return nil, err
+	}
+	if err := validate(def, req.Subject, req.Body); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	tmpl := &domain.Template{
+		ID:        uuid.New().String(),
+		Key:       req.Key,
+		Channel:   req.Channel,
+		Locale:    normalizeLocale(req.Locale),
+		Subject:   req.Subject,
+		Body:      req.Body,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if userID != "" {
+		tmpl.UpdatedBy = &userID
+	}
+
+	if err := u.repo.Create(ctx, tmpl); err != nil {
+		return nil, fmt.Errorf("failed to create message template: %w", err)
+	}
+	u.audit.Record(ctx, "message_templates", tmpl.ID, audit.ActionCreate, nil, tmpl)
+	return tmpl, nil
+}
+
+func (u *messageTemplateUsecase) Update(ctx context.Context, id string, req *domain.UpdateTemplateRequest, userID string) (*domain.Template, error) {
+	tmpl, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message template: %w", err)
+	}
+	def, err := u.definition(tmpl.Channel, tmpl.Key)
+	if err != nil {
+		return nil, err
+	}
+	if err := validate(def, req.Subject, req.Body); err != nil {
+		return nil, err
+	}
+	before := *tmpl
+
+	tmpl.Subject = req.Subject
+	tmpl.Body = req.Body
+	tmpl.UpdatedAt = time.Now()
+	if userID != "" {
+		tmpl.UpdatedBy = &userID
+	}
+
+	if err := u.repo.Update(ctx, tmpl); err != nil {
+		return nil, fmt.Errorf("failed to update message template: %w", err)
+	}
+	u.audit.Record(ctx, "message_templates", tmpl.ID, audit.ActionUpdate, &before, tmpl)
+	return tmpl, nil
+}
+
+func (u *messageTemplateUsecase) Delete(ctx context.Context, id string) error {
+	tmpl, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get message template: %w", err)
+	}
+	if err := u.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete message template: %w", err)
+	}
+	u.audit.Record(ctx, "message_templates", id, audit.ActionDelete, tmpl, nil)
+	return nil
+}
+
+func (u *messageTemplateUsecase) Preview(ctx context.Context, req *domain.PreviewRequest) (*domain.Message, error) {
+	def, err := u.definition(req.Channel, req.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	subject, body, locale := def.Subject, def.Body, ""
+	if req.Locale != "" && (req.Subject == "" || req.Body == "") {
+		templates, err := u.repo.Find(ctx, req.Channel, req.Key, []string{normalizeLocale(req.Locale)})
+		if err != nil {
+			return nil, err
+		}
+		if len(templates) > 0 {
+			subject, body, locale = templates[0].Subject, templates[0].Body, templates[0].Locale
+		}
+	}
+	if req.Subject != "" {
+		subject, locale = req.Subject, normalizeLocale(req.Locale)
+	}
+	if req.Body != "" {
+		body, locale = req.Body, normalizeLocale(req.Locale)
+	}
+
+	vars := sampleVariables(def)
+	for name, value := range req.Variables {
+		vars[name] = value
+	}
+	msg, err := domain.Render(subject, body, vars)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid template: %v", pkgErrors.ErrInvalidInput, err)
+	}
+	msg.Locale = locale
+	return msg, nil
+}
+
+// definition returns the definition of a message, failing with
+// errors.ErrInvalidInput when no module sends it
+func (u *messageTemplateUsecase) definition(channel domain.Channel, key string) (domain.Definition, error) {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	def, ok := u.definitions[definitionKey(channel, key)]
+	if !ok {
+		return def, fmt.Errorf("%w: no %s message %s is defined", pkgErrors.ErrInvalidInput, channel, key)
+	}
+	return def, nil
+}
+
+// validate checks that subject and body render with the variables of def
+// and refer to no others
+func validate(def domain.Definition, subject, body string) error {
+	if _, err := domain.Render(subject, body, sampleVariables(def)); err != nil {
+		return fmt.Errorf("%w: invalid template: %v", pkgErrors.ErrInvalidInput, err)
+	}
+	return nil
+}
+
+// sampleVariables gives each variable of def its name in braces as value
+func sampleVariables(def domain.Definition) map[string]string {
+	vars := make(map[string]string, len(def.Variables))
+	for _, name := range def.Variables {
+		vars[name] = "{" + name + "}"
+	}
+	return vars
+}
+
+// pick returns the template in the first of locales that has one
+func pick(templates []*domain.Template, locales []string) *domain.Template {
+	for _, locale := range locales {
+		for _, tmpl := range templates {
+			if tmpl.Locale == locale {
+				return tmpl
+			}
+		}
+	}
+	return nil
+}
+
+func definitionKey(channel domain.Channel, key string) string {
+	return string(channel) + ":" + key
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.TrimSpace(locale))
+}
@@ -0,0 +1,170 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"portal-data-backend/internal/message_template/domain"
+	"portal-data-backend/internal/message_template/usecase"
+	pkgerrors "portal-data-backend/pkg/errors"
+	"portal-data-backend/pkg/i18n"
+)
+
+// mockRepository is an in-memory implementation of Repository
+type mockRepository struct {
+	templates []*domain.Template
+}
+
+func (m *mockRepository) GetByID(ctx context.Context, id string) (*domain.Template, error) {
+	for _, tmpl := range m.templates {
+		if tmpl.ID == id {
+			copied := *tmpl
+			return &copied, nil
+		}
+	}
+	return nil, pkgerrors.ErrNotFound
+}
+
+func (m *mockRepository) List(ctx context.Context, filter *domain.TemplateFilter, limit, offset int) ([]*domain.Template, int, error) {
+	return m.templates, len(m.templates), nil
+}
+
+func (m *mockRepository) Find(ctx context.Context, channel domain.Channel, key string, locales []string) ([]*domain.Template, error) {
+	var found []*domain.Template
+	for _, tmpl := range m.templates {
+		for _, locale := range locales {
+			if tmpl.Channel == channel && tmpl.Key == key && tmpl.Locale == locale {
+				found = append(found, tmpl)
+			}
+		}
+	}
+	return found, nil
+}
+
+func (m *mockRepository) Create(ctx context.Context, tmpl *domain.Template) error {
+	m.templates = append(m.templates, tmpl)
+	return nil
+}
+
+func (m *mockRepository) Update(ctx context.Context, tmpl *domain.Template) error {
+	for i, stored := range m.templates {
+		if stored.ID == tmpl.ID {
+			m.templates[i] = tmpl
+		}
+	}
+	return nil
+}
+
+func (m *mockRepository) Delete(ctx context.Context, id string) error {
+	for i, tmpl := range m.templates {
+		if tmpl.ID == id {
+			m.templates = append(m.templates[:i], m.templates[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+var welcome = domain.Definition{
+	Key:       "test.welcome",
+	Channel:   domain.ChannelEmail,
+	Variables: []string{"name"},
+	Subject:   "Welcome",
+	Body:      "Hello {{.name}}",
+}
+
+func newUsecase() (usecase.Usecase, *mockRepository) {
+	repo := &mockRepository{}
+	templates := usecase.NewMessageTemplateUsecase(repo, nil, "id")
+	templates.Define(welcome)
+	return templates, repo
+}
+
+// Test messages use the template of the preferred language, then the default
+// locale, then the built-in wording
+func TestRender_FallsBack(t *testing.T) {
+	ctx := context.Background()
+	templates, _ := newUsecase()
+	vars := map[string]string{"name": "Ana"}
+
+	msg, err := templates.Render(ctx, domain.ChannelEmail, welcome.Key, vars)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if msg.Subject != "Welcome" || msg.Body != "Hello Ana" || msg.Locale != "" {
+		t.Errorf("Expected the built-in wording, got %+v", msg)
+	}
+
+	for _, req := range []*domain.CreateTemplateRequest{
+		{Key: welcome.Key, Channel: domain.ChannelEmail, Locale: "ID", Subject: "Selamat datang", Body: "Halo {{.name}}"},
+		{Key: welcome.Key, Channel: domain.ChannelEmail, Locale: "fr", Subject: "Bienvenue", Body: "Bonjour {{.name}}"},
+	} {
+		if _, err := templates.Create(ctx, req, "admin"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	msg, _ = templates.Render(ctx, domain.ChannelEmail, welcome.Key, vars)
+	if msg.Body != "Halo Ana" || msg.Locale != "id" {
+		t.Errorf("Expected the default locale, got %+v", msg)
+	}
+	msg, _ = templates.Render(i18n.WithLanguages(ctx, []string{"de", "fr"}), domain.ChannelEmail, welcome.Key, vars)
+	if msg.Body != "Bonjour Ana" || msg.Locale != "fr" {
+		t.Errorf("Expected the preferred language, got %+v", msg)
+	}
+
+	if _, err := templates.Render(ctx, domain.ChannelNotification, welcome.Key, vars); !errors.Is(err, pkgerrors.ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for an undefined message, got %v", err)
+	}
+}
+
+// Test templates must render with the variables of their message
+func TestCreate_ValidatesTemplate(t *testing.T) {
+	ctx := context.Background()
+	templates, repo := newUsecase()
+
+	for name, req := range map[string]*domain.CreateTemplateRequest{
+		"undefined message": {Key: "test.unknown", Channel: domain.ChannelEmail, Locale: "en", Subject: "Hi", Body: "Hi"},
+		"unknown variable":  {Key: welcome.Key, Channel: domain.ChannelEmail, Locale: "en", Subject: "Hi", Body: "Hi {{.email}}"},
+		"syntax error":      {Key: welcome.Key, Channel: domain.ChannelEmail, Locale: "en", Subject: "Hi", Body: "Hi {{.name"},
+		"multiline subject": {Key: welcome.Key, Channel: domain.ChannelEmail, Locale: "en", Subject: "Hi\n{{.name}}", Body: "Hi"},
+	} {
+		if _, err := templates.Create(ctx, req, "admin"); !errors.Is(err, pkgerrors.ErrInvalidInput) {
+			t.Errorf("Expected ErrInvalidInput for %s, got %v", name, err)
+		}
+	}
+	if len(repo.templates) != 0 {
+		t.Errorf("Expected no template stored, got %d", len(repo.templates))
+	}
+}
+
+// Test previews render drafts and stored templates with sample variables
+func TestPreview(t *testing.T) {
+	ctx := context.Background()
+	templates, _ := newUsecase()
+	if _, err := templates.Create(ctx, &domain.CreateTemplateRequest{Key: welcome.Key, Channel: domain.ChannelEmail, Locale: "en", Subject: "Hi", Body: "Hi {{.name}}!"}, "admin"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	msg, err := templates.Preview(ctx, &domain.PreviewRequest{Key: welcome.Key, Channel: domain.ChannelEmail, Locale: "en"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if msg.Subject != "Hi" || msg.Body != "Hi {name}!" || msg.Locale != "en" {
+		t.Errorf("Expected the stored template with sample variables, got %+v", msg)
+	}
+
+	msg, err = templates.Preview(ctx, &domain.PreviewRequest{
+		Key:       welcome.Key,
+		Channel:   domain.ChannelEmail,
+		Body:      "Dear {{.name}}",
+		Variables: map[string]string{"name": "Ana"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if msg.Subject != "Welcome" || msg.Body != "Dear Ana" {
+		t.Errorf("Expected the draft body with the built-in subject, got %+v", msg)
+	}
+}
@@ -0,0 +1,144 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"portal-data-backend/infrastructure/auth"
+	"portal-data-backend/infrastructure/http/httputil"
+	"portal-data-backend/infrastructure/http/middleware"
+	"portal-data-backend/infrastructure/http/problem"
+	"portal-data-backend/infrastructure/http/response"
+	moderationDomain "portal-data-backend/internal/moderation/domain"
+	"portal-data-backend/internal/moderation/usecase"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	moderationUsecase usecase.Usecase
+}
+
+func NewHandler(moderationUsecase usecase.Usecase) *Handler {
+	return &Handler{
+		moderationUsecase: moderationUsecase,
+	}
+}
+
+// List lists the moderation queue: the pending items, oldest first, unless
+// another status is asked for
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	req := &moderationDomain.ListItemsRequest{
+		Page:  parseIntQuery(r, "page", 1),
+		Limit: parseIntQuery(r, "limit", 20),
+	}
+
+	// Parse optional filters
+	if status := r.URL.Query().Get("status"); status != "" {
+		req.Status = &status
+	}
+	if contentType := r.URL.Query().Get("content_type"); contentType != "" {
+		req.ContentType = &contentType
+	}
+	if decidedBy := r.URL.Query().Get("decided_by"); decidedBy != "" {
+		req.DecidedBy = &decidedBy
+	}
+
+	resp, err := h.moderationUsecase.List(r.Context(), req)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Moderation items retrieved successfully", resp)
+}
+
+func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	item, err := h.moderationUsecase.GetByID(r.Context(), id)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Moderation item retrieved successfully", item)
+}
+
+func (h *Handler) Approve(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, h.moderationUsecase.Approve, "Moderation item approved successfully")
+}
+
+func (h *Handler) Reject(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, h.moderationUsecase.Reject, "Moderation item rejected successfully")
+}
+
+// decide takes the decision of the signed-in moderator on an item
+func (h *Handler) decide(w http.ResponseWriter, r *http.Request, decide func(ctx context.Context, id string, req *moderationDomain.DecideRequest, moderatorID string) (*moderationDomain.ItemInfo, error), message string) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	req, ok := httputil.Decode[moderationDomain.DecideRequest](w, r)
+	if !ok {
+		return
+	}
+
+	moderatorID := auth.UserID(r.Context())
+
+	item, err := decide(r.Context(), id, req, moderatorID)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, message, item)
+}
+
+// Screen tries the heuristics on a text without holding anything
+func (h *Handler) Screen(w http.ResponseWriter, r *http.Request) {
+	req, ok := httputil.Decode[moderationDomain.ScreenRequest](w, r)
+	if !ok {
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Text screened successfully", h.moderationUsecase.Screen(r.Context(), req))
+}
+
+// errorMapper maps the errors of the moderation module on top of the shared ones
+var errorMapper = problem.Default.With(
+	problem.Mapping{Err: pkgErrors.ErrNotFound, Status: http.StatusNotFound, Code: response.CodeNotFound, Message: "Moderation item not found"},
+)
+
+func (h *Handler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	errorMapper.Write(w, r, err)
+}
+
+func parseIntQuery(r *http.Request, key string, defaultValue int) int {
+	if value := r.URL.Query().Get(key); value != "" {
+		if intValue, err := strconv.Atoi(value); err == nil {
+			return intValue
+		}
+	}
+	return defaultValue
+}
+
+// RegisterRoutes registers moderation routes, for users with one of
+// moderatorRoles
+func RegisterRoutes(r chi.Router, handler *Handler, auth func(http.Handler) http.Handler, moderatorRoles []string) {
+	r.Route("/moderation", func(r chi.Router) {
+		r.Use(auth)
+		r.Use(middleware.RequireRole(moderatorRoles...))
+		r.Get("/items", handler.List)
+		r.Get("/items/{id}", handler.GetByID)
+		r.Post("/items/{id}/approve", handler.Approve)
+		r.Post("/items/{id}/reject", handler.Reject)
+		r.Post("/screen", handler.Screen)
+	})
+}
@@ -0,0 +1,20 @@
+package http
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	moderationDomain "portal-data-backend/internal/moderation/domain"
+)
+
+// Describe adds the moderation routes to the API specification
+func Describe(spec *openapi.Spec) {
+	api := spec.Tag("moderation", "Screening and moderation of the text the public submits")
+	api.Get("/moderation/items", "List moderation queue").
+		Query(moderationDomain.ListItemsRequest{}, "page", "limit", "status", "content_type", "decided_by").
+		Returns(http.StatusOK, moderationDomain.ItemListResponse{})
+	api.Get("/moderation/items/{id}", "Get moderation item").Returns(http.StatusOK, moderationDomain.ItemInfo{})
+	api.Post("/moderation/items/{id}/approve", "Approve moderation item").Body(moderationDomain.DecideRequest{}).Returns(http.StatusOK, moderationDomain.ItemInfo{})
+	api.Post("/moderation/items/{id}/reject", "Reject moderation item").Body(moderationDomain.DecideRequest{}).Returns(http.StatusOK, moderationDomain.ItemInfo{})
+	api.Post("/moderation/screen", "Screen text").Body(moderationDomain.ScreenRequest{}).Returns(http.StatusOK, moderationDomain.ScreenResponse{})
+}
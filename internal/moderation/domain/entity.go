@@ -0,0 +1,100 @@
+package domain
+
+import "time"
+
+// Item is a submission of public text held for a moderator, or decided by
+// one. ContentType and ContentID name the content it holds back, like a
+// feedback.
+type Item struct {
+	ID          string     `db:"id" json:"id"`
+	ContentType string     `db:"content_type" json:"content_type"`
+	ContentID   string     `db:"content_id" json:"content_id"`
+	Text        string     `db:"text" json:"text"`
+	AuthorID    *string    `db:"author_id" json:"author_id,omitempty"`
+	AuthorEmail *string    `db:"author_email" json:"author_email,omitempty"`
+	Reasons     string     `db:"reasons" json:"-"` // JSON of the heuristics the text matched
+	Status      Status     `db:"status" json:"status"`
+	Note        *string    `db:"note" json:"note,omitempty"`
+	DecidedBy   *string    `db:"decided_by" json:"decided_by,omitempty"`
+	DecidedAt   *time.Time `db:"decided_at" json:"decided_at,omitempty"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// Status represents how far a submission is through moderation
+type Status string
+
+const (
+	StatusPending  Status = "pending"
+	StatusApproved Status = "approved"
+	StatusRejected Status = "rejected"
+)
+
+// Reason is a heuristic a text matched: Rule is "keyword", "blocked_domain"
+// or "links", Match what matched it
+type Reason struct {
+	Rule  string `json:"rule"`
+	Match string `json:"match"`
+}
+
+const (
+	RuleKeyword       = "keyword"
+	RuleBlockedDomain = "blocked_domain"
+	RuleLinks         = "links" // Match is the number of links
+)
+
+// Submission is public text submitted for screening. Hold holds it for a
+// moderator even when it matches no heuristic.
+type Submission struct {
+	ContentType string
+	ContentID   string
+	Text        string
+	AuthorID    *string
+	AuthorEmail *string
+	Hold        bool
+}
+
+// ListItemsRequest represents list moderation items input
+type ListItemsRequest struct {
+	Page        int     `json:"page" validate:"min=1"`
+	Limit       int     `json:"limit" validate:"min=1,max=100"`
+	Status      *string `json:"status,omitempty"` // pending when not set
+	ContentType *string `json:"content_type,omitempty"`
+	DecidedBy   *string `json:"decided_by,omitempty"`
+}
+
+// DecideRequest represents a moderator's approval or rejection
+type DecideRequest struct {
+	Note string `json:"note,omitempty" validate:"max=1000"`
+}
+
+// ScreenRequest represents text to try the heuristics on
+type ScreenRequest struct {
+	Text string `json:"text" validate:"required,max=10000"`
+}
+
+// ScreenResponse represents whether text would be held, and why
+type ScreenResponse struct {
+	Held    bool     `json:"held"`
+	Reasons []Reason `json:"reasons"`
+}
+
+// ItemInfo represents moderation item information for API responses
+type ItemInfo struct {
+	Item
+	Reasons []Reason `json:"reasons"`
+}
+
+// ItemListResponse represents paginated moderation items
+type ItemListResponse struct {
+	Items []ItemInfo `json:"items"`
+	Meta  ListMeta   `json:"meta"`
+}
+
+// ListMeta represents pagination metadata
+type ListMeta struct {
+	Page      int `json:"page"`
+	Limit     int `json:"limit"`
+	Total     int `json:"total"`
+	TotalPage int `json:"total_page"`
+}
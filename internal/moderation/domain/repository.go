@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Repository defines the interface for moderation data access
+type Repository interface {
+	Create(ctx context.Context, item *Item) error
+	GetByID(ctx context.Context, id string) (*Item, error)
+	List(ctx context.Context, filter *ItemFilter, limit, offset int) ([]*Item, int, error)
+	// Decide records the decision on a pending item, failing with
+	// errors.ErrNotFound when the item is not pending
+	Decide(ctx context.Context, item *Item) error
+	// DecideContent records a decision on the pending items of a content and
+	// returns them as they were before
+	DecideContent(ctx context.Context, contentType, contentID string, status Status, decidedBy string, at time.Time) ([]*Item, error)
+}
+
+// ItemFilter selects moderation items; nil fields match every item
+type ItemFilter struct {
+	Status      *string
+	ContentType *string
+	DecidedBy   *string
+}
@@ -0,0 +1,54 @@
+// Package moderation is the module screening the text the public submits
+// and keeping the queue of text held for moderators.
+package moderation
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/infrastructure/http/openapi"
+	"portal-data-backend/internal/app"
+	delivery "portal-data-backend/internal/moderation/delivery/http"
+	"portal-data-backend/internal/moderation/repository"
+	"portal-data-backend/internal/moderation/usecase"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Module screens submissions for the modules collecting public text, which
+// register how decisions apply to their content
+type Module struct {
+	handler        *delivery.Handler
+	moderatorRoles []string
+}
+
+// Name implements app.Module
+func (m *Module) Name() string {
+	return "moderation"
+}
+
+// Register implements app.Module
+func (m *Module) Register(deps *app.Deps) error {
+	cfg := deps.Config.Moderation
+	repo := repository.NewModerationPostgresRepository(deps.DB)
+	moderation := usecase.NewModerationUsecase(repo, deps.Tx, deps.Services.Audit, cfg)
+	deps.Services.Moderation = moderation
+	m.handler = delivery.NewHandler(moderation)
+	m.moderatorRoles = cfg.ModeratorRoles
+
+	// The heuristics follow configuration reloads
+	deps.Reloader.Subscribe(func(cfg *config.Config) {
+		moderation.SetConfig(cfg.Moderation)
+	})
+	return nil
+}
+
+// Routes implements app.Module
+func (m *Module) Routes(r chi.Router, auth func(http.Handler) http.Handler) {
+	delivery.RegisterRoutes(r, m.handler, auth, m.moderatorRoles)
+}
+
+// Describe implements app.Module
+func (m *Module) Describe(spec *openapi.Spec) {
+	delivery.Describe(spec)
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"portal-data-backend/infrastructure/db"
+	moderationDomain "portal-data-backend/internal/moderation/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type moderationPostgresRepository struct {
+	db *sqlx.DB
+}
+
+func NewModerationPostgresRepository(db *sqlx.DB) moderationDomain.Repository {
+	return &moderationPostgresRepository{db: db}
+}
+
+const itemColumns = `id, content_type, content_id, text, author_id, author_email, reasons, status, note,
+	decided_by, decided_at, created_at, updated_at`
+
+func (r *moderationPostgresRepository) Create(ctx context.Context, item *moderationDomain.Item) error {
+	query := `
+		INSERT INTO moderation_items (id, content_type, content_id, text, author_id, author_email, reasons, status,
+		                              created_at, updated_at)
+		VALUES (:id, :content_type, :content_id, :text, :author_id, :author_email, :reasons, :status,
+		        :created_at, :updated_at)
+	`
+
+	if _, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, item); err != nil {
+		return fmt.Errorf("failed to create moderation item: %w", err)
+	}
+	return nil
+}
+
+func (r *moderationPostgresRepository) GetByID(ctx context.Context, id string) (*moderationDomain.Item, error) {
+	query := `SELECT ` + itemColumns + ` FROM moderation_items WHERE id = $1`
+
+	var item moderationDomain.Item
+	err := db.Conn(ctx, r.db).GetContext(ctx, &item, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, pkgErrors.ErrNotFound
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &item, nil
+}
+
+func (r *moderationPostgresRepository) List(ctx context.Context, filter *moderationDomain.ItemFilter, limit, offset int) ([]*moderationDomain.Item, int, error) {
+	whereClause := "WHERE TRUE"
+	args := []interface{}{}
+	argCount := 1
+
+	if filter != nil {
+		if filter.Status != nil {
+			whereClause += fmt.Sprintf(" AND status = $%d", argCount)
+			args = append(args, *filter.Status)
+			argCount++
+		}
+		if filter.ContentType != nil {
+			whereClause += fmt.Sprintf(" AND content_type = $%d", argCount)
+			args = append(args, *filter.ContentType)
+			argCount++
+		}
+		if filter.DecidedBy != nil {
+			whereClause += fmt.Sprintf(" AND decided_by = $%d", argCount)
+			args = append(args, *filter.DecidedBy)
+			argCount++
+		}
+	}
+
+	countQuery := "SELECT COUNT(*) FROM moderation_items " + whereClause
+	var total int
+	err := db.Conn(ctx, r.db).GetContext(ctx, &total, countQuery, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count moderation items: %w", err)
+	}
+
+	// The queue is worked through oldest first
+	query := `SELECT ` + itemColumns + ` FROM moderation_items ` + whereClause +
+		fmt.Sprintf(" ORDER BY created_at ASC LIMIT $%d OFFSET $%d", argCount, argCount+1)
+	args = append(args, limit, offset)
+
+	var items []*moderationDomain.Item
+	err = db.Conn(ctx, r.db).SelectContext(ctx, &items, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list moderation items: %w", err)
+	}
+
+	return items, total, nil
+}
+
+func (r *moderationPostgresRepository) Decide(ctx context.Context, item *moderationDomain.Item) error {
+	query := `
+		UPDATE moderation_items
+		SET status = :status, note = :note, decided_by = :decided_by, decided_at = :decided_at, updated_at = :updated_at
+		WHERE id = :id AND status = 'pending'
+	`
+
+	result, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, item)
+	if err != nil {
+		return fmt.Errorf("failed to decide moderation item: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return pkgErrors.ErrNotFound
+	}
+	return nil
+}
+
+func (r *moderationPostgresRepository) DecideContent(ctx context.Context, contentType, contentID string, status moderationDomain.Status, decidedBy string, at time.Time) ([]*moderationDomain.Item, error) {
+	conn := db.Conn(ctx, r.db)
+
+	query := `SELECT ` + itemColumns + ` FROM moderation_items
+		WHERE content_type = $1 AND content_id = $2 AND status = 'pending' FOR UPDATE`
+	var items []*moderationDomain.Item
+	if err := conn.SelectContext(ctx, &items, query, contentType, contentID); err != nil {
+		return nil, fmt.Errorf("failed to get moderation items: %w", err)
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	update := `
+		UPDATE moderation_items SET status = $1, decided_by = $2, decided_at = $3, updated_at = $3
+		WHERE content_type = $4 AND content_id = $5 AND status = 'pending'
+	`
+	if _, err := conn.ExecContext(ctx, update, status, decidedBy, at, contentType, contentID); err != nil {
+		return nil, fmt.Errorf("failed to decide moderation items: %w", err)
+	}
+	return items, nil
+}
@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/internal/moderation/domain"
+)
+
+// linkPattern finds the web addresses in text, with or without a scheme
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"']+`)
+
+// screener matches text against the moderation heuristics, which are
+// replaced as the configuration is reloaded
+type screener struct {
+	mu             sync.RWMutex
+	keywords       []*regexp.Regexp
+	blockedDomains []string
+	maxLinks       int
+	holdAll        bool
+}
+
+func newScreener(cfg config.ModerationConfig) *screener {
+	s := &screener{}
+	s.set(cfg)
+	return s
+}
+
+// set replaces the heuristics. Keywords match whole words, ignoring case.
+func (s *screener) set(cfg config.ModerationConfig) {
+	keywords := make([]*regexp.Regexp, 0, len(cfg.Keywords))
+	for _, keyword := range cfg.Keywords {
+		keywords = append(keywords, regexp.MustCompile(`(?i)\b`+regexp.QuoteMeta(keyword)+`\b`))
+	}
+	domains := make([]string, len(cfg.BlockedDomains))
+	for i, domain := range cfg.BlockedDomains {
+		domains[i] = strings.ToLower(strings.TrimPrefix(domain, "."))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keywords = keywords
+	s.blockedDomains = domains
+	s.maxLinks = cfg.MaxLinks
+	s.holdAll = cfg.HoldAll
+}
+
+// screen returns the heuristics text matches, and whether it is held
+func (s *screener) screen(text string) ([]domain.Reason, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	reasons := []domain.Reason{}
+	for _, keyword := range s.keywords {
+		if match := keyword.FindString(text); match != "" {
+			reasons = append(reasons, domain.Reason{Rule: domain.RuleKeyword, Match: match})
+		}
+	}
+
+	links := linkPattern.FindAllString(text, -1)
+	blocked := map[string]bool{}
+	for _, link := range links {
+		host := linkHost(link)
+		for _, domainName := range s.blockedDomains {
+			if (host == domainName || strings.HasSuffix(host, "."+domainName)) && !blocked[host] {
+				blocked[host] = true
+				reasons = append(reasons, domain.Reason{Rule: domain.RuleBlockedDomain, Match: host})
+			}
+		}
+	}
+	if len(links) > s.maxLinks {
+		reasons = append(reasons, domain.Reason{Rule: domain.RuleLinks, Match: strconv.Itoa(len(links))})
+	}
+
+	return reasons, s.holdAll || len(reasons) > 0
+}
+
+// linkHost returns the lower case host of a link found in text
+func linkHost(link string) string {
+	if !strings.Contains(link, "://") {
+		link = "http://" + link
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
@@ -0,0 +1,230 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"portal-data-backend/infrastructure/audit"
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/infrastructure/db"
+	"portal-data-backend/internal/moderation/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// Applier publishes or hides a content of the module owning it as the
+// moderation items holding it back are decided
+type Applier func(ctx context.Context, contentID string, status domain.Status) error
+
+// Usecase screens public text and keeps the queue of text held for
+// moderators
+type Usecase interface {
+	// Handle makes apply publish or hide the contents of contentType as their
+	// items are decided
+	Handle(contentType string, apply Applier)
+	// Screen returns the heuristics text matches and whether it would be held
+	Screen(ctx context.Context, req *domain.ScreenRequest) *domain.ScreenResponse
+	// Submit screens a submission and holds it for a moderator when it is
+	// held, returning the status its content starts with. It runs in the
+	// transaction ctx carries, if any, so the item is kept with the content.
+	Submit(ctx context.Context, sub *domain.Submission) (domain.Status, error)
+	// Settle records a decision taken on a content outside the queue on its
+	// pending items
+	Settle(ctx context.Context, contentType, contentID string, status domain.Status, moderatorID string) error
+
+	List(ctx context.Context, req *domain.ListItemsRequest) (*domain.ItemListResponse, error)
+	GetByID(ctx context.Context, id string) (*domain.ItemInfo, error)
+	// Approve publishes the content of a pending item
+	Approve(ctx context.Context, id string, req *domain.DecideRequest, moderatorID string) (*domain.ItemInfo, error)
+	// Reject keeps the content of a pending item hidden
+	Reject(ctx context.Context, id string, req *domain.DecideRequest, moderatorID string) (*domain.ItemInfo, error)
+
+	// SetConfig replaces the heuristics, as the configuration is reloaded
+	SetConfig(cfg config.ModerationConfig)
+}
+
+type moderationUsecase struct {
+	repo     domain.Repository
+	tx       db.Transactor
+	audit    *audit.Recorder
+	screener *screener
+	now      func() time.Time
+
+	mu       sync.RWMutex
+	appliers map[string]Applier
+}
+
+// NewModerationUsecase creates the moderation usecase. Decisions are audited
+// through recorder, which may be nil.
+func NewModerationUsecase(repo domain.Repository, tx db.Transactor, recorder *audit.Recorder, cfg config.ModerationConfig) Usecase {
+	return &moderationUsecase{
+		repo:     repo,
+		tx:       tx,
+		audit:    recorder,
+		screener: newScreener(cfg),
+		now:      time.Now,
+		appliers: map[string]Applier{},
+	}
+}
+
+func (u *moderationUsecase) Handle(contentType string, apply Applier) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.appliers[contentType] = apply
+}
+
+func (u *moderationUsecase) Screen(ctx context.Context, req *domain.ScreenRequest) *domain.ScreenResponse {
+	reasons, held := u.screener.screen(req.Text)
+	return &domain.ScreenResponse{Held: held, Reasons: reasons}
+}
+
+func (u *moderationUsecase) Submit(ctx context.Context, sub *domain.Submission) (domain.Status, error) {
+	reasons, held := u.screener.screen(sub.Text)
+	if !held && !sub.Hold {
+		return domain.StatusApproved, nil
+	}
+
+	encoded, err := json.Marshal(reasons)
+	if err != nil {
+		return "", err
+	}
+	now := u.now()
+	item := &domain.Item{
+		ID:          uuid.New().String(),
+		ContentType: sub.ContentType,
+		ContentID:   sub.ContentID,
+		Text:        sub.Text,
+		AuthorID:    sub.AuthorID,
+		AuthorEmail: sub.AuthorEmail,
+		Reasons:     string(encoded),
+		Status:      domain.StatusPending,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := u.repo.Create(ctx, item); err != nil {
+		return "", err
+	}
+	return domain.StatusPending, nil
+}
+
+func (u *moderationUsecase) Settle(ctx context.Context, contentType, contentID string, status domain.Status, moderatorID string) error {
+	now := u.now()
+	items, err := u.repo.DecideContent(ctx, contentType, contentID, status, moderatorID, now)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		decided := *item
+		decided.Status, decided.DecidedBy, decided.DecidedAt, decided.UpdatedAt = status, &moderatorID, &now, now
+		u.audit.Record(ctx, "moderation_items", item.ID, audit.ActionUpdate, item, &decided)
+	}
+	return nil
+}
+
+func (u *moderationUsecase) List(ctx context.Context, req *domain.ListItemsRequest) (*domain.ItemListResponse, error) {
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.Limit < 1 {
+		req.Limit = 20
+	}
+	if req.Status == nil {
+		pending := string(domain.StatusPending)
+		req.Status = &pending
+	}
+
+	offset := (req.Page - 1) * req.Limit
+	filter := &domain.ItemFilter{Status: req.Status, ContentType: req.ContentType, DecidedBy: req.DecidedBy}
+
+	items, total, err := u.repo.List(ctx, filter, req.Limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list moderation items: %w", err)
+	}
+
+	infos := make([]domain.ItemInfo, len(items))
+	for i, item := range items {
+		infos[i] = *toInfo(item)
+	}
+
+	return &domain.ItemListResponse{
+		Items: infos,
+		Meta: domain.ListMeta{
+			Page:      req.Page,
+			Limit:     req.Limit,
+			Total:     total,
+			TotalPage: int(math.Ceil(float64(total) / float64(req.Limit))),
+		},
+	}, nil
+}
+
+func (u *moderationUsecase) GetByID(ctx context.Context, id string) (*domain.ItemInfo, error) {
+	item, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get moderation item: %w", err)
+	}
+	return toInfo(item), nil
+}
+
+func (u *moderationUsecase) Approve(ctx context.Context, id string, req *domain.DecideRequest, moderatorID string) (*domain.ItemInfo, error) {
+	return u.decide(ctx, id, domain.StatusApproved, req, moderatorID)
+}
+
+func (u *moderationUsecase) Reject(ctx context.Context, id string, req *domain.DecideRequest, moderatorID string) (*domain.ItemInfo, error) {
+	return u.decide(ctx, id, domain.StatusRejected, req, moderatorID)
+}
+
+// decide records the decision of a moderator on a pending item and applies
+// it to the content, in one transaction
+func (u *moderationUsecase) decide(ctx context.Context, id string, status domain.Status, req *domain.DecideRequest, moderatorID string) (*domain.ItemInfo, error) {
+	var decided domain.Item
+	err := u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		item, err := u.repo.GetByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get moderation item: %w", err)
+		}
+		if item.Status != domain.StatusPending {
+			return fmt.Errorf("%w: the item was already %s", pkgErrors.ErrInvalidInput, item.Status)
+		}
+
+		now := u.now()
+		decided = *item
+		decided.Status, decided.DecidedBy, decided.DecidedAt, decided.UpdatedAt = status, &moderatorID, &now, now
+		if req.Note != "" {
+			decided.Note = &req.Note
+		}
+		if err := u.repo.Decide(ctx, &decided); err != nil {
+			return fmt.Errorf("failed to decide moderation item: %w", err)
+		}
+
+		u.mu.RLock()
+		apply := u.appliers[item.ContentType]
+		u.mu.RUnlock()
+		if apply != nil {
+			if err := apply(ctx, item.ContentID, status); err != nil {
+				return fmt.Errorf("failed to apply moderation to %s %s: %w", item.ContentType, item.ContentID, err)
+			}
+		}
+
+		u.audit.Record(ctx, "moderation_items", item.ID, audit.ActionUpdate, item, &decided)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toInfo(&decided), nil
+}
+
+func (u *moderationUsecase) SetConfig(cfg config.ModerationConfig) {
+	u.screener.set(cfg)
+}
+
+func toInfo(item *domain.Item) *domain.ItemInfo {
+	info := &domain.ItemInfo{Item: *item, Reasons: []domain.Reason{}}
+	_ = json.Unmarshal([]byte(item.Reasons), &info.Reasons)
+	return info
+}
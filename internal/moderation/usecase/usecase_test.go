@@ -0,0 +1,175 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/internal/moderation/domain"
+	"portal-data-backend/internal/moderation/usecase"
+	pkgerrors "portal-data-backend/pkg/errors"
+)
+
+// mockRepository is an in-memory implementation of Repository
+type mockRepository struct {
+	items []*domain.Item
+}
+
+func (m *mockRepository) Create(ctx context.Context, item *domain.Item) error {
+	m.items = append(m.items, item)
+	return nil
+}
+
+func (m *mockRepository) GetByID(ctx context.Context, id string) (*domain.Item, error) {
+	for _, item := range m.items {
+		if item.ID == id {
+			copied := *item
+			return &copied, nil
+		}
+	}
+	return nil, pkgerrors.ErrNotFound
+}
+
+func (m *mockRepository) List(ctx context.Context, filter *domain.ItemFilter, limit, offset int) ([]*domain.Item, int, error) {
+	var items []*domain.Item
+	for _, item := range m.items {
+		if filter.Status == nil || string(item.Status) == *filter.Status {
+			items = append(items, item)
+		}
+	}
+	return items, len(items), nil
+}
+
+func (m *mockRepository) Decide(ctx context.Context, item *domain.Item) error {
+	for i, stored := range m.items {
+		if stored.ID == item.ID && stored.Status == domain.StatusPending {
+			m.items[i] = item
+			return nil
+		}
+	}
+	return pkgerrors.ErrNotFound
+}
+
+func (m *mockRepository) DecideContent(ctx context.Context, contentType, contentID string, status domain.Status, decidedBy string, at time.Time) ([]*domain.Item, error) {
+	var decided []*domain.Item
+	for _, item := range m.items {
+		if item.ContentType == contentType && item.ContentID == contentID && item.Status == domain.StatusPending {
+			copied := *item
+			decided = append(decided, &copied)
+			item.Status, item.DecidedBy, item.DecidedAt = status, &decidedBy, &at
+		}
+	}
+	return decided, nil
+}
+
+type mockTransactor struct{}
+
+func (mockTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func newModerationUsecase(repo *mockRepository, cfg config.ModerationConfig) usecase.Usecase {
+	return usecase.NewModerationUsecase(repo, mockTransactor{}, nil, cfg)
+}
+
+// Test text is held for keywords, blocked domains and too many links, and
+// the heuristics follow configuration changes
+func TestModeration_Screen(t *testing.T) {
+	ctx := context.Background()
+	u := newModerationUsecase(&mockRepository{}, config.ModerationConfig{
+		Keywords:       []string{"casino", "free money"},
+		BlockedDomains: []string{"spam.example"},
+		MaxLinks:       2,
+	})
+
+	tests := []struct {
+		text  string
+		held  bool
+		rules []string
+	}{
+		{"The rainfall data of 2024 is missing March", false, nil},
+		{"Occasional gaps, see the classic dataset", false, nil},
+		{"Win FREE MONEY at our Casino", true, []string{domain.RuleKeyword, domain.RuleKeyword}},
+		{"Details at https://www.spam.example/offer", true, []string{domain.RuleBlockedDomain}},
+		{"See https://a.example, www.b.example and http://c.example", true, []string{domain.RuleLinks}},
+	}
+	for _, tt := range tests {
+		resp := u.Screen(ctx, &domain.ScreenRequest{Text: tt.text})
+		if resp.Held != tt.held || len(resp.Reasons) != len(tt.rules) {
+			t.Errorf("Expected %q held %v for %v, got %v for %+v", tt.text, tt.held, tt.rules, resp.Held, resp.Reasons)
+			continue
+		}
+		for i, rule := range tt.rules {
+			if resp.Reasons[i].Rule != rule {
+				t.Errorf("Expected rule %s for %q, got %s", rule, tt.text, resp.Reasons[i].Rule)
+			}
+		}
+	}
+
+	u.SetConfig(config.ModerationConfig{HoldAll: true, MaxLinks: 2})
+	if resp := u.Screen(ctx, &domain.ScreenRequest{Text: "Win at our casino"}); !resp.Held || len(resp.Reasons) != 0 {
+		t.Errorf("Expected every text held without reasons after the reload, got %+v", resp)
+	}
+}
+
+// Test held submissions wait in the queue until a moderator decides them,
+// which applies the decision to their content
+func TestModeration_Decide(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockRepository{}
+	u := newModerationUsecase(repo, config.ModerationConfig{Keywords: []string{"casino"}, MaxLinks: 2})
+
+	applied := map[string]domain.Status{}
+	u.Handle("feedback", func(ctx context.Context, contentID string, status domain.Status) error {
+		applied[contentID] = status
+		return nil
+	})
+
+	status, err := u.Submit(ctx, &domain.Submission{ContentType: "feedback", ContentID: "fb-1", Text: "Helpful dataset"})
+	if err != nil || status != domain.StatusApproved || len(repo.items) != 0 {
+		t.Errorf("Expected clean text approved without an item, got %s, %v", status, err)
+	}
+	status, _ = u.Submit(ctx, &domain.Submission{ContentType: "feedback", ContentID: "fb-2", Text: "Play casino games"})
+	if status != domain.StatusPending {
+		t.Errorf("Expected flagged text pending, got %s", status)
+	}
+	status, _ = u.Submit(ctx, &domain.Submission{ContentType: "feedback", ContentID: "fb-3", Text: "Helpful dataset", Hold: true})
+	if status != domain.StatusPending {
+		t.Errorf("Expected held text pending, got %s", status)
+	}
+
+	queue, err := u.List(ctx, &domain.ListItemsRequest{})
+	if err != nil || queue.Meta.Total != 2 {
+		t.Fatalf("Expected 2 pending items, got %v, %v", queue, err)
+	}
+	if len(queue.Items[0].Reasons) != 1 || queue.Items[0].Reasons[0].Match != "casino" {
+		t.Errorf("Expected the keyword as reason, got %+v", queue.Items[0].Reasons)
+	}
+
+	rejected, err := u.Reject(ctx, queue.Items[0].ID, &domain.DecideRequest{Note: "Advertising"}, "moderator-1")
+	if err != nil {
+		t.Fatalf("Expected the item to be rejected, got %v", err)
+	}
+	if rejected.Status != domain.StatusRejected || *rejected.DecidedBy != "moderator-1" || *rejected.Note != "Advertising" {
+		t.Errorf("Expected the rejection by the moderator, got %+v", rejected.Item)
+	}
+	if applied["fb-2"] != domain.StatusRejected {
+		t.Errorf("Expected the rejection applied to the feedback, got %v", applied)
+	}
+	if _, err := u.Approve(ctx, queue.Items[0].ID, &domain.DecideRequest{}, "moderator-2"); !errors.Is(err, pkgerrors.ErrInvalidInput) {
+		t.Errorf("Expected a decided item not to be decided again, got %v", err)
+	}
+
+	// A decision taken on the feedback itself settles its item
+	if err := u.Settle(ctx, "feedback", "fb-3", domain.StatusApproved, "moderator-2"); err != nil {
+		t.Fatalf("Expected the item to be settled, got %v", err)
+	}
+	if queue, _ := u.List(ctx, &domain.ListItemsRequest{}); queue.Meta.Total != 0 {
+		t.Errorf("Expected an empty queue, got %d items", queue.Meta.Total)
+	}
+	if _, ok := applied["fb-3"]; ok {
+		t.Error("Expected a settled decision not to be applied again")
+	}
+}
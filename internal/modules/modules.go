@@ -0,0 +1,83 @@
+// Package modules lists the modules the API is composed of
+package modules
+
+import (
+	"portal-data-backend/internal/analytics"
+	"portal-data-backend/internal/app"
+	"portal-data-backend/internal/audit"
+	"portal-data-backend/internal/auth"
+	"portal-data-backend/internal/business_field"
+	"portal-data-backend/internal/catalog"
+	"portal-data-backend/internal/data_row"
+	"portal-data-backend/internal/dataset"
+	"portal-data-backend/internal/dataset_package"
+	"portal-data-backend/internal/deprecation"
+	"portal-data-backend/internal/desk"
+	"portal-data-backend/internal/developer"
+	"portal-data-backend/internal/feedback"
+	"portal-data-backend/internal/file"
+	"portal-data-backend/internal/gateway"
+	"portal-data-backend/internal/impersonation"
+	"portal-data-backend/internal/integration"
+	"portal-data-backend/internal/message_template"
+	"portal-data-backend/internal/moderation"
+	"portal-data-backend/internal/notification"
+	"portal-data-backend/internal/organization"
+	"portal-data-backend/internal/preview"
+	"portal-data-backend/internal/publication"
+	"portal-data-backend/internal/report"
+	"portal-data-backend/internal/review"
+	"portal-data-backend/internal/role"
+	"portal-data-backend/internal/search"
+	"portal-data-backend/internal/settings"
+	"portal-data-backend/internal/submission"
+	"portal-data-backend/internal/tag"
+	"portal-data-backend/internal/tenant"
+	"portal-data-backend/internal/topic"
+	"portal-data-backend/internal/unit"
+	"portal-data-backend/internal/user"
+	"portal-data-backend/internal/visualization"
+	"portal-data-backend/internal/widget"
+)
+
+// All returns the modules of the API in registration order. A module is
+// registered after the modules providing the services it uses.
+func All() []app.Module {
+	return []app.Module{
+		&audit.Module{},
+		&role.Module{},
+		&tenant.Module{},
+		&messagetemplate.Module{},
+		&auth.Module{},
+		&user.Module{},
+		&impersonation.Module{},
+		&organization.Module{},
+		&notification.Module{},
+		&file.Module{},
+		&tag.Module{},
+		&businessfield.Module{},
+		&topic.Module{},
+		&unit.Module{},
+		&search.Module{},
+		&dataset.Module{},
+		&moderation.Module{},
+		&feedback.Module{},
+		&submission.Module{},
+		&analytics.Module{},
+		&visualization.Module{},
+		&publication.Module{},
+		&review.Module{},
+		&settings.Module{},
+		&datarow.Module{},
+		&desk.Module{},
+		&report.Module{},
+		&integration.Module{},
+		&datasetpackage.Module{},
+		&catalog.Module{},
+		&widget.Module{},
+		&developer.Module{},
+		&gateway.Module{},
+		&deprecation.Module{},
+		&preview.Module{},
+	}
+}
@@ -1,42 +1,40 @@
 package http
 
 import (
-	"encoding/json"
-	"errors"
 	"net/http"
 	"strconv"
 
+	"portal-data-backend/infrastructure/auth"
 	notifDomain "portal-data-backend/internal/notification/domain"
 	"portal-data-backend/internal/notification/usecase"
+	"portal-data-backend/infrastructure/http/bulk"
+	"portal-data-backend/infrastructure/http/httputil"
+	"portal-data-backend/infrastructure/http/problem"
 	"portal-data-backend/infrastructure/http/response"
 	pkgErrors "portal-data-backend/pkg/errors"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/go-playground/validator/v10"
 )
 
 type Handler struct {
 	notifUsecase usecase.Usecase
-	validator    *validator.Validate
 }
 
 func NewHandler(notifUsecase usecase.Usecase) *Handler {
 	return &Handler{
 		notifUsecase: notifUsecase,
-		validator:    validator.New(),
 	}
 }
 
 func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Notification ID is required", nil)
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
 	notif, err := h.notifUsecase.GetByID(r.Context(), id)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -50,7 +48,7 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get user ID from context
-	userID, _ := r.Context().Value("user_id").(string)
+	userID := auth.UserID(r.Context())
 	req.UserID = &userID
 
 	// Parse optional filters
@@ -73,7 +71,7 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 
 	resp, err := h.notifUsecase.List(r.Context(), req)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -81,20 +79,14 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
-	var req notifDomain.CreateNotificationRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, response.CodeBadRequest, "Invalid request body", nil)
+	req, ok := httputil.Decode[notifDomain.CreateNotificationRequest](w, r)
+	if !ok {
 		return
 	}
 
-	if err := h.validator.Struct(&req); err != nil {
-		response.ValidationError(w, response.CodeValidationFailed, "Validation failed", h.formatValidationErrors(err))
-		return
-	}
-
-	notif, err := h.notifUsecase.Create(r.Context(), &req)
+	notif, err := h.notifUsecase.Create(r.Context(), req)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -102,19 +94,13 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) BulkCreate(w http.ResponseWriter, r *http.Request) {
-	var req notifDomain.BulkCreateNotificationRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, response.CodeBadRequest, "Invalid request body", nil)
-		return
-	}
-
-	if err := h.validator.Struct(&req); err != nil {
-		response.ValidationError(w, response.CodeValidationFailed, "Validation failed", h.formatValidationErrors(err))
+	req, ok := httputil.Decode[notifDomain.BulkCreateNotificationRequest](w, r)
+	if !ok {
 		return
 	}
 
-	if err := h.notifUsecase.BulkCreate(r.Context(), &req); err != nil {
-		h.handleError(w, err)
+	if err := h.notifUsecase.BulkCreate(r.Context(), req); err != nil {
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -122,21 +108,15 @@ func (h *Handler) BulkCreate(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) MarkAsRead(w http.ResponseWriter, r *http.Request) {
-	var req notifDomain.MarkAsReadRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, response.CodeBadRequest, "Invalid request body", nil)
+	req, ok := httputil.Decode[notifDomain.MarkAsReadRequest](w, r)
+	if !ok {
 		return
 	}
 
-	if err := h.validator.Struct(&req); err != nil {
-		response.ValidationError(w, response.CodeValidationFailed, "Validation failed", h.formatValidationErrors(err))
-		return
-	}
-
-	userID, _ := r.Context().Value("user_id").(string)
+	userID := auth.UserID(r.Context())
 
 	if err := h.notifUsecase.MarkAsRead(r.Context(), req.NotificationIDs, userID); err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -144,10 +124,10 @@ func (h *Handler) MarkAsRead(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) MarkAllAsRead(w http.ResponseWriter, r *http.Request) {
-	userID, _ := r.Context().Value("user_id").(string)
+	userID := auth.UserID(r.Context())
 
 	if err := h.notifUsecase.MarkAllAsRead(r.Context(), userID); err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -155,14 +135,13 @@ func (h *Handler) MarkAllAsRead(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Notification ID is required", nil)
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
 	if err := h.notifUsecase.Delete(r.Context(), id); err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -170,52 +149,62 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) GetUnreadCount(w http.ResponseWriter, r *http.Request) {
-	userID, _ := r.Context().Value("user_id").(string)
+	userID := auth.UserID(r.Context())
 
 	count, err := h.notifUsecase.GetUnreadCount(r.Context(), userID)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
 	response.OK(w, response.CodeSuccess, "Unread count retrieved successfully", notifDomain.UnreadCountResponse{Count: count})
 }
 
-func (h *Handler) handleError(w http.ResponseWriter, err error) {
-	if err == nil {
+// Sync answers what changed in the notifications of the current user since
+// the time in the since query parameter
+func (h *Handler) Sync(w http.ResponseWriter, r *http.Request) {
+	req := &notifDomain.SyncNotificationsRequest{Since: r.URL.Query().Get("since")}
+	if !httputil.Validate(w, req) {
 		return
 	}
 
-	switch {
-	case errors.Is(err, pkgErrors.ErrNotFound):
-		response.NotFound(w, response.CodeNotFound, "Notification not found", nil)
-	default:
-		response.InternalError(w, response.CodeInternalServerError, "Internal server error", nil)
+	userID := auth.UserID(r.Context())
+
+	resp, err := h.notifUsecase.Sync(r.Context(), req, userID)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
 	}
+
+	response.OK(w, response.CodeSuccess, "Notifications synced successfully", resp)
 }
 
-func (h *Handler) formatValidationErrors(err error) []response.ErrorDetail {
-	var details []response.ErrorDetail
-	if validationErrors, ok := err.(validator.ValidationErrors); ok {
-		for _, fieldErr := range validationErrors {
-			details = append(details, response.ErrorDetail{
-				Field:   fieldErr.Field(),
-				Message: h.getValidationErrorMessage(fieldErr),
-			})
-		}
+// BulkDelete deletes several notifications of the current user in one
+// transaction, answering the outcome for each
+func (h *Handler) BulkDelete(w http.ResponseWriter, r *http.Request) {
+	req, ok := httputil.Decode[bulk.Request](w, r)
+	if !ok {
+		return
 	}
-	return details
-}
 
-func (h *Handler) getValidationErrorMessage(fieldErr validator.FieldError) string {
-	switch fieldErr.Tag() {
-	case "required":
-		return fieldErr.Field() + " is required"
-	case "min":
-		return fieldErr.Field() + " must be at least " + fieldErr.Param() + " characters"
-	default:
-		return fieldErr.Field() + " is invalid"
+	userID := auth.UserID(r.Context())
+
+	failed, err := h.notifUsecase.BulkDelete(r.Context(), req.IDs, userID)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
 	}
+
+	bulk.Write(w, r, errorMapper, req.IDs, failed, "notifications deleted")
+}
+
+// errorMapper maps the errors of the notification module on top of the shared ones
+var errorMapper = problem.Default.With(
+	problem.Mapping{Err: pkgErrors.ErrNotFound, Status: http.StatusNotFound, Code: response.CodeNotFound, Message: "Notification not found"},
+)
+
+func (h *Handler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	errorMapper.Write(w, r, err)
 }
 
 func parseIntQuery(r *http.Request, key string, defaultValue int) int {
@@ -227,15 +216,19 @@ func parseIntQuery(r *http.Request, key string, defaultValue int) int {
 	return defaultValue
 }
 
-func RegisterRoutes(r chi.Router, handler *Handler) {
+// RegisterRoutes registers notification routes, which all go through auth
+func RegisterRoutes(r chi.Router, handler *Handler, auth func(http.Handler) http.Handler) {
 	r.Route("/notifications", func(r chi.Router) {
+		r.Use(auth)
 		r.Get("/", handler.List)
 		r.Post("/", handler.Create)
 		r.Post("/bulk", handler.BulkCreate)
 		r.Post("/mark-read", handler.MarkAsRead)
 		r.Post("/mark-all-read", handler.MarkAllAsRead)
 		r.Get("/unread-count", handler.GetUnreadCount)
+		r.Get("/sync", handler.Sync)
 		r.Get("/{id}", handler.GetByID)
+		r.Delete("/bulk", handler.BulkDelete)
 		r.Delete("/{id}", handler.Delete)
 	})
 }
@@ -0,0 +1,28 @@
+package http
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/bulk"
+	"portal-data-backend/infrastructure/http/openapi"
+	notifDomain "portal-data-backend/internal/notification/domain"
+)
+
+// Describe adds the notification routes to the API specification
+func Describe(spec *openapi.Spec) {
+	api := spec.Tag("notifications", "Notifications of the current user")
+	api.Get("/notifications", "List notifications").
+		Query(notifDomain.ListNotificationsRequest{}, "page", "limit", "type", "category", "is_read", "start_date", "end_date").
+		Returns(http.StatusOK, notifDomain.NotificationListResponse{})
+	api.Post("/notifications", "Create notification").Body(notifDomain.CreateNotificationRequest{}).Returns(http.StatusCreated, notifDomain.NotificationInfo{})
+	api.Post("/notifications/bulk", "Create notifications in bulk").Body(notifDomain.BulkCreateNotificationRequest{}).Returns(http.StatusCreated, nil)
+	api.Post("/notifications/mark-read", "Mark notifications as read").Body(notifDomain.MarkAsReadRequest{}).Returns(http.StatusOK, nil)
+	api.Post("/notifications/mark-all-read", "Mark all notifications as read").Returns(http.StatusOK, nil)
+	api.Get("/notifications/unread-count", "Count unread notifications").Returns(http.StatusOK, notifDomain.UnreadCountResponse{})
+	api.Get("/notifications/sync", "List the notifications created, read and deleted since a time").
+		Query(notifDomain.SyncNotificationsRequest{}, "since").
+		Returns(http.StatusOK, notifDomain.NotificationSyncResponse{})
+	api.Get("/notifications/{id}", "Get notification").Returns(http.StatusOK, notifDomain.NotificationInfo{})
+	api.Delete("/notifications/bulk", "Delete notifications in bulk").Body(bulk.Request{}).Returns(http.StatusOK, bulk.Response{})
+	api.Delete("/notifications/{id}", "Delete notification").Returns(http.StatusOK, nil)
+}
@@ -103,6 +103,26 @@ type ListMeta struct {
 	TotalPage int `json:"total_page"`
 }
 
+// SyncNotificationsRequest asks what changed in the notifications of the
+// current user since an RFC 3339 time, the synced_at of the previous sync
+type SyncNotificationsRequest struct {
+	Since string `json:"since" validate:"required"`
+}
+
+// NotificationSyncResponse lists the IDs of the notifications created, read
+// and deleted since the time asked. Clients pass SyncedAt as the since of
+// their next sync; IDs may come again in it. Reset tells the changes could
+// not all be told, because they go back further than read and deleted
+// notifications are kept or are too many, and the client should refetch
+// its notifications instead.
+type NotificationSyncResponse struct {
+	Created  []string  `json:"created"`
+	Read     []string  `json:"read"`
+	Deleted  []string  `json:"deleted"`
+	SyncedAt time.Time `json:"synced_at"`
+	Reset    bool      `json:"reset"`
+}
+
 // UnreadCountResponse represents unread count response
 type UnreadCountResponse struct {
 	Count int64 `json:"count"`
@@ -14,7 +14,10 @@ type Repository interface {
 	MarkAllAsRead(ctx context.Context, userID string) error
 	Delete(ctx context.Context, id string) error
 	GetUnreadCount(ctx context.Context, userID string) (int64, error)
-	DeleteOldReadNotifications(ctx context.Context, olderThan time.Duration) error
+	DeleteOldReadNotifications(ctx context.Context, olderThan time.Duration) (int64, error)
+	// ListChanged lists up to limit notifications of userID, deleted ones
+	// included, created, read or deleted at or after since
+	ListChanged(ctx context.Context, userID string, since time.Time, limit int) ([]*Notification, error)
 }
 
 type NotificationFilter struct {
@@ -0,0 +1,81 @@
+// Package notification is the module delivering in-app notifications.
+package notification
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/infrastructure/db"
+	"portal-data-backend/infrastructure/http/openapi"
+	"portal-data-backend/internal/app"
+	delivery "portal-data-backend/internal/notification/delivery/http"
+	"portal-data-backend/internal/notification/domain"
+	"portal-data-backend/internal/notification/repository"
+	"portal-data-backend/internal/notification/usecase"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jmoiron/sqlx"
+)
+
+// Module stores notifications and provides sending them to other modules
+type Module struct {
+	handler *delivery.Handler
+	db      *sqlx.DB
+	repo    domain.Repository
+	cleanup config.CleanupConfig
+}
+
+// Name implements app.Module
+func (m *Module) Name() string {
+	return "notification"
+}
+
+// Register implements app.Module
+func (m *Module) Register(deps *app.Deps) error {
+	m.db = deps.DB
+	m.cleanup = deps.Config.Cleanup
+	repo := repository.NewNotificationPostgresRepository(deps.DB)
+	m.repo = repo
+	notifications := usecase.NewNotificationUsecase(repo, deps.Tx, syncWindow(deps.Config))
+	deps.Services.Notifications = notifications
+	m.handler = delivery.NewHandler(notifications)
+	return nil
+}
+
+// Routes implements app.Module
+func (m *Module) Routes(r chi.Router, auth func(http.Handler) http.Handler) {
+	delivery.RegisterRoutes(r, m.handler, auth)
+}
+
+// Describe implements app.Module
+func (m *Module) Describe(spec *openapi.Spec) {
+	delivery.Describe(spec)
+}
+
+// Purge implements app.Purger
+func (m *Module) Purge(ctx context.Context, before time.Time) (int64, error) {
+	return db.PurgeDeleted(ctx, m.db, "notifications", before)
+}
+
+// syncWindow is how long read and deleted notifications are kept before
+// being deleted for good, by the cleanup job and the trash purge
+func syncWindow(cfg *config.Config) time.Duration {
+	window := cfg.Trash.Retention
+	if cfg.Cleanup.NotificationInterval > 0 && cfg.Cleanup.NotificationRetention < window {
+		window = cfg.Cleanup.NotificationRetention
+	}
+	return window
+}
+
+// Jobs implements app.Scheduler
+func (m *Module) Jobs() []app.Job {
+	return []app.Job{{
+		Name:     "read notification cleanup",
+		Interval: m.cleanup.NotificationInterval,
+		Run: func(ctx context.Context) (int64, error) {
+			return m.repo.DeleteOldReadNotifications(ctx, m.cleanup.NotificationRetention)
+		},
+	}}
+}
@@ -8,6 +8,9 @@ import (
 
 	notifDomain "portal-data-backend/internal/notification/domain"
 
+	"portal-data-backend/infrastructure/db"
+	"portal-data-backend/pkg/errors"
+
 	"github.com/jmoiron/sqlx"
 )
 
@@ -27,7 +30,7 @@ func (r *notificationPostgresRepository) GetByID(ctx context.Context, id string)
 	`
 
 	var notif notifDomain.Notification
-	err := r.db.GetContext(ctx, &notif, query, id)
+	err := db.Conn(ctx, r.db).GetContext(ctx, &notif, query, id)
 	if err != nil {
 		return nil, r.handleError(err)
 	}
@@ -74,7 +77,7 @@ func (r *notificationPostgresRepository) List(ctx context.Context, filter *notif
 
 	countQuery := "SELECT COUNT(*) FROM notifications " + whereClause
 	var total int
-	err := r.db.GetContext(ctx, &total, countQuery, args...)
+	err := db.Conn(ctx, r.db).GetContext(ctx, &total, countQuery, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count notifications: %w", err)
 	}
@@ -87,7 +90,7 @@ func (r *notificationPostgresRepository) List(ctx context.Context, filter *notif
 	args = append(args, limit, offset)
 
 	var notifs []*notifDomain.Notification
-	err = r.db.SelectContext(ctx, &notifs, query, args...)
+	err = db.Conn(ctx, r.db).SelectContext(ctx, &notifs, query, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to list notifications: %w", err)
 	}
@@ -101,7 +104,7 @@ func (r *notificationPostgresRepository) Create(ctx context.Context, notif *noti
 		VALUES (:id, :user_id, :title, :message, :type, :category, :action_url, :read, :created_at)
 	`
 
-	_, err := r.db.NamedExecContext(ctx, query, notif)
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, notif)
 	if err != nil {
 		return fmt.Errorf("failed to create notification: %w", err)
 	}
@@ -114,7 +117,7 @@ func (r *notificationPostgresRepository) BulkCreate(ctx context.Context, notifs
 		VALUES (:id, :user_id, :title, :message, :type, :category, :action_url, :read, :created_at)
 	`
 
-	_, err := r.db.NamedExecContext(ctx, query, notifs)
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, notifs)
 	if err != nil {
 		return fmt.Errorf("failed to bulk create notifications: %w", err)
 	}
@@ -127,7 +130,7 @@ func (r *notificationPostgresRepository) MarkAsRead(ctx context.Context, ids []s
 		SET read = true, read_at = $1
 		WHERE id = ANY($2) AND user_id = $3
 	`
-	_, err := r.db.ExecContext(ctx, query, time.Now(), ids, userID)
+	_, err := db.Conn(ctx, r.db).ExecContext(ctx, query, time.Now(), ids, userID)
 	if err != nil {
 		return fmt.Errorf("failed to mark notifications as read: %w", err)
 	}
@@ -140,7 +143,7 @@ func (r *notificationPostgresRepository) MarkAllAsRead(ctx context.Context, user
 		SET read = true, read_at = $1
 		WHERE user_id = $2 AND read = false AND deleted_at IS NULL
 	`
-	_, err := r.db.ExecContext(ctx, query, time.Now(), userID)
+	_, err := db.Conn(ctx, r.db).ExecContext(ctx, query, time.Now(), userID)
 	if err != nil {
 		return fmt.Errorf("failed to mark all notifications as read: %w", err)
 	}
@@ -149,7 +152,7 @@ func (r *notificationPostgresRepository) MarkAllAsRead(ctx context.Context, user
 
 func (r *notificationPostgresRepository) Delete(ctx context.Context, id string) error {
 	query := `UPDATE notifications SET deleted_at = $1 WHERE id = $2`
-	_, err := r.db.ExecContext(ctx, query, time.Now(), id)
+	_, err := db.Conn(ctx, r.db).ExecContext(ctx, query, time.Now(), id)
 	if err != nil {
 		return fmt.Errorf("failed to delete notification: %w", err)
 	}
@@ -163,21 +166,37 @@ func (r *notificationPostgresRepository) GetUnreadCount(ctx context.Context, use
 		WHERE user_id = $1 AND read = false AND deleted_at IS NULL
 	`
 	var count int64
-	err := r.db.GetContext(ctx, &count, query, userID)
+	err := db.Conn(ctx, r.db).GetContext(ctx, &count, query, userID)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get unread count: %w", err)
 	}
 	return count, nil
 }
 
-func (r *notificationPostgresRepository) DeleteOldReadNotifications(ctx context.Context, olderThan time.Duration) error {
+func (r *notificationPostgresRepository) DeleteOldReadNotifications(ctx context.Context, olderThan time.Duration) (int64, error) {
 	cutoff := time.Now().Add(-olderThan)
 	query := `DELETE FROM notifications WHERE read = true AND read_at < $1`
-	_, err := r.db.ExecContext(ctx, query, cutoff)
+	result, err := db.Conn(ctx, r.db).ExecContext(ctx, query, cutoff)
 	if err != nil {
-		return fmt.Errorf("failed to delete old notifications: %w", err)
+		return 0, fmt.Errorf("failed to delete old notifications: %w", err)
 	}
-	return nil
+	return result.RowsAffected()
+}
+
+func (r *notificationPostgresRepository) ListChanged(ctx context.Context, userID string, since time.Time, limit int) ([]*notifDomain.Notification, error) {
+	query := `
+		SELECT id, user_id, title, message, type, category, action_url, read, read_at, created_at, deleted_at
+		FROM notifications
+		WHERE user_id = $1 AND (created_at >= $2 OR read_at >= $2 OR deleted_at >= $2)
+		ORDER BY created_at ASC, id ASC
+		LIMIT $3
+	`
+	var notifs []*notifDomain.Notification
+	err := db.Conn(ctx, r.db).SelectContext(ctx, &notifs, query, userID, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list changed notifications: %w", err)
+	}
+	return notifs, nil
 }
 
 func (r *notificationPostgresRepository) handleError(err error) error {
@@ -185,7 +204,7 @@ func (r *notificationPostgresRepository) handleError(err error) error {
 		return nil
 	}
 	if err == sql.ErrNoRows {
-		return fmt.Errorf("notification not found")
+		return fmt.Errorf("notification not found: %w", errors.ErrNotFound)
 	}
 	return fmt.Errorf("database error: %w", err)
 }
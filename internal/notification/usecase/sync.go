@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"portal-data-backend/internal/notification/domain"
+	"portal-data-backend/pkg/errors"
+)
+
+const (
+	// syncLimit is how many changed notifications a sync tells at most;
+	// clients behind by more refetch their notifications
+	syncLimit = 500
+	// syncOverlap moves synced_at back to cover the changes committed by
+	// transactions still running when a sync reads
+	syncOverlap = 5 * time.Second
+)
+
+// Sync lists the IDs of the notifications of userID created, read and
+// deleted since req.Since
+func (u *notificationUsecase) Sync(ctx context.Context, req *domain.SyncNotificationsRequest, userID string) (*domain.NotificationSyncResponse, error) {
+	since, err := time.Parse(time.RFC3339, req.Since)
+	if err != nil {
+		return nil, fmt.Errorf("%w: since must be an RFC 3339 time like 2026-01-02T15:04:05Z", errors.ErrInvalidInput)
+	}
+
+	now := u.now()
+	resp := &domain.NotificationSyncResponse{
+		Created:  []string{},
+		Read:     []string{},
+		Deleted:  []string{},
+		SyncedAt: now.Add(-syncOverlap).UTC(),
+	}
+	// Read and deleted notifications are deleted for good after syncWindow,
+	// without a trace to tell
+	if u.syncWindow > 0 && since.Before(now.Add(-u.syncWindow)) {
+		resp.Reset = true
+		return resp, nil
+	}
+
+	notifs, err := u.repo.ListChanged(ctx, userID, since, syncLimit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sync notifications: %w", err)
+	}
+	if len(notifs) > syncLimit {
+		resp.Reset = true
+		return resp, nil
+	}
+
+	for _, notif := range notifs {
+		if notif.DeletedAt != nil {
+			if !notif.DeletedAt.Before(since) {
+				resp.Deleted = append(resp.Deleted, notif.ID)
+			}
+			continue
+		}
+		if !notif.CreatedAt.Before(since) {
+			resp.Created = append(resp.Created, notif.ID)
+		}
+		if notif.ReadAt != nil && !notif.ReadAt.Before(since) {
+			resp.Read = append(resp.Read, notif.ID)
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"portal-data-backend/internal/notification/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+)
+
+// changedStore lists the notifications it holds as changed
+type changedStore struct {
+	domain.Repository
+	notifs []*domain.Notification
+}
+
+func (s *changedStore) ListChanged(ctx context.Context, userID string, since time.Time, limit int) ([]*domain.Notification, error) {
+	if len(s.notifs) > limit {
+		return s.notifs[:limit], nil
+	}
+	return s.notifs, nil
+}
+
+// Test a sync tells created, read and deleted notifications apart, and asks
+// for a reset when the changes cannot all be told
+func TestNotificationUsecase_Sync(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	since := now.Add(-time.Hour)
+	before, after := since.Add(-time.Minute), since.Add(time.Minute)
+	store := &changedStore{notifs: []*domain.Notification{
+		{ID: "new", CreatedAt: after},
+		{ID: "new-read", CreatedAt: after, Read: true, ReadAt: &after},
+		{ID: "read", CreatedAt: before, Read: true, ReadAt: &after},
+		{ID: "deleted", CreatedAt: before, DeletedAt: &after},
+	}}
+	u := NewNotificationUsecase(store, nil, 24*time.Hour).(*notificationUsecase)
+	u.now = func() time.Time { return now }
+
+	resp, err := u.Sync(context.Background(), &domain.SyncNotificationsRequest{Since: since.Format(time.RFC3339)}, "user-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(resp.Created) != 2 || resp.Created[0] != "new" || resp.Created[1] != "new-read" {
+		t.Errorf("Expected the new notifications created, got %v", resp.Created)
+	}
+	if len(resp.Read) != 2 || resp.Read[0] != "new-read" || resp.Read[1] != "read" {
+		t.Errorf("Expected the read notifications read, got %v", resp.Read)
+	}
+	if len(resp.Deleted) != 1 || resp.Deleted[0] != "deleted" || resp.Reset {
+		t.Errorf("Expected the deleted notification deleted without a reset, got %v", resp.Deleted)
+	}
+	if !resp.SyncedAt.Equal(now.Add(-syncOverlap)) {
+		t.Errorf("Expected to sync from %v next, got %v", now.Add(-syncOverlap), resp.SyncedAt)
+	}
+
+	stale := &domain.SyncNotificationsRequest{Since: now.Add(-48 * time.Hour).Format(time.RFC3339)}
+	if resp, err := u.Sync(context.Background(), stale, "user-1"); err != nil || !resp.Reset {
+		t.Errorf("Expected a reset syncing from before the sync window, got %+v (%v)", resp, err)
+	}
+
+	store.notifs = make([]*domain.Notification, syncLimit+1)
+	for i := range store.notifs {
+		store.notifs[i] = &domain.Notification{CreatedAt: after}
+	}
+	if resp, err := u.Sync(context.Background(), &domain.SyncNotificationsRequest{Since: since.Format(time.RFC3339)}, "user-1"); err != nil || !resp.Reset || len(resp.Created) != 0 {
+		t.Errorf("Expected a reset past the sync limit, got reset %v (%v)", resp.Reset, err)
+	}
+
+	if _, err := u.Sync(context.Background(), &domain.SyncNotificationsRequest{Since: "yesterday"}, "user-1"); !errors.Is(err, pkgErrors.ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for an invalid since, got %v", err)
+	}
+}
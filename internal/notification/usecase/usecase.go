@@ -6,7 +6,9 @@ import (
 	"math"
 	"time"
 
+	"portal-data-backend/infrastructure/db"
 	"portal-data-backend/internal/notification/domain"
+	"portal-data-backend/pkg/errors"
 
 	"github.com/google/uuid"
 )
@@ -19,16 +21,31 @@ type Usecase interface {
 	MarkAsRead(ctx context.Context, ids []string, userID string) error
 	MarkAllAsRead(ctx context.Context, userID string) error
 	Delete(ctx context.Context, id string) error
+	// BulkDelete deletes notifications of userID in one transaction,
+	// returning the errors of those it could not delete by ID
+	BulkDelete(ctx context.Context, ids []string, userID string) (map[string]error, error)
 	GetUnreadCount(ctx context.Context, userID string) (int64, error)
+	// Sync lists what changed in the notifications of userID since a time,
+	// for clients on several devices to reconcile theirs
+	Sync(ctx context.Context, req *domain.SyncNotificationsRequest, userID string) (*domain.NotificationSyncResponse, error)
 }
 
 type notificationUsecase struct {
-	repo domain.Repository
+	repo       domain.Repository
+	tx         db.Transactor
+	syncWindow time.Duration
+	now        func() time.Time
 }
 
-func NewNotificationUsecase(repo domain.Repository) Usecase {
+// NewNotificationUsecase creates a new notification usecase. syncWindow is
+// how far back syncs tell the notifications read and deleted, as long as
+// they are kept before being deleted for good; zero is no limit.
+func NewNotificationUsecase(repo domain.Repository, tx db.Transactor, syncWindow time.Duration) Usecase {
 	return &notificationUsecase{
-		repo: repo,
+		repo:       repo,
+		tx:         tx,
+		syncWindow: syncWindow,
+		now:        time.Now,
 	}
 }
 
@@ -149,6 +166,20 @@ func (u *notificationUsecase) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+func (u *notificationUsecase) BulkDelete(ctx context.Context, ids []string, userID string) (map[string]error, error) {
+	return db.Each(ctx, u.tx, ids, func(ctx context.Context, id string) error {
+		notif, err := u.repo.GetByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get notification: %w", err)
+		}
+		// Notifications of other users are not theirs to see
+		if notif.UserID != userID {
+			return fmt.Errorf("notification of another user: %w", errors.ErrNotFound)
+		}
+		return u.Delete(ctx, id)
+	})
+}
+
 func (u *notificationUsecase) GetUnreadCount(ctx context.Context, userID string) (int64, error) {
 	count, err := u.repo.GetUnreadCount(ctx, userID)
 	if err != nil {
@@ -0,0 +1,30 @@
+// Package cli holds the portalctl commands of the organization module.
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"portal-data-backend/internal/app"
+	"portal-data-backend/internal/organization/usecase"
+)
+
+// Commands returns the organization commands
+func Commands(orgUsecase usecase.Usecase) []app.Command {
+	return []app.Command{
+		{
+			Name:    "recount-org-stats",
+			Usage:   "recount-org-stats",
+			Summary: "Recount the datasets of every organization",
+			Run: func(ctx context.Context, in io.Reader, out io.Writer, args []string) error {
+				updated, err := orgUsecase.RecountDatasets(ctx)
+				if err != nil {
+					return fmt.Errorf("failed to recount organization stats: %w", err)
+				}
+				fmt.Fprintf(out, "Recounted the datasets of %d organizations\n", updated)
+				return nil
+			},
+		},
+	}
+}
@@ -1,48 +1,48 @@
 package http
 
 import (
-	"encoding/json"
-	"errors"
 	"net/http"
 	"strconv"
 
+	"portal-data-backend/infrastructure/auth"
 	orgDomain "portal-data-backend/internal/organization/domain"
 	"portal-data-backend/internal/organization/usecase"
+	"portal-data-backend/infrastructure/http/httputil"
+	"portal-data-backend/infrastructure/http/middleware"
+	"portal-data-backend/infrastructure/http/problem"
 	"portal-data-backend/infrastructure/http/response"
+	roleDomain "portal-data-backend/internal/role/domain"
 	pkgErrors "portal-data-backend/pkg/errors"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/go-playground/validator/v10"
 )
 
 // Handler handles HTTP requests for organization
 type Handler struct {
 	orgUsecase usecase.Usecase
-	validator  *validator.Validate
 }
 
 // NewHandler creates a new organization handler
 func NewHandler(orgUsecase usecase.Usecase) *Handler {
 	return &Handler{
 		orgUsecase: orgUsecase,
-		validator:  validator.New(),
 	}
 }
 
 // GetByID handles getting an organization by ID
 func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Organization ID is required", nil)
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
 	org, err := h.orgUsecase.GetByID(r.Context(), id)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
+	middleware.SurrogateKeys(w, orgDomain.SurrogateKey(org.ID))
 	response.OK(w, response.CodeSuccess, "Organization retrieved successfully", org)
 }
 
@@ -56,10 +56,11 @@ func (h *Handler) GetByCode(w http.ResponseWriter, r *http.Request) {
 
 	org, err := h.orgUsecase.GetByCode(r.Context(), code)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
+	middleware.SurrogateKeys(w, orgDomain.SurrogateKey(org.ID))
 	response.OK(w, response.CodeSuccess, "Organization retrieved successfully", org)
 }
 
@@ -76,7 +77,7 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 
 	resp, err := h.orgUsecase.List(r.Context(), req)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -85,23 +86,17 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 
 // Create handles creating a new organization
 func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
-	var req orgDomain.CreateOrganizationRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, response.CodeBadRequest, "Invalid request body", nil)
-		return
-	}
-
-	if err := h.validator.Struct(&req); err != nil {
-		response.ValidationError(w, response.CodeValidationFailed, "Validation failed", h.formatValidationErrors(err))
+	req, ok := httputil.Decode[orgDomain.CreateOrganizationRequest](w, r)
+	if !ok {
 		return
 	}
 
 	// Get creator ID from context
-	creatorID, _ := r.Context().Value("user_id").(string)
+	creatorID := auth.UserID(r.Context())
 
-	org, err := h.orgUsecase.Create(r.Context(), &req, creatorID)
+	org, err := h.orgUsecase.Create(r.Context(), req, creatorID)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -110,117 +105,111 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 
 // Update handles updating an organization
 func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Organization ID is required", nil)
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
-	var req orgDomain.UpdateOrganizationRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, response.CodeBadRequest, "Invalid request body", nil)
+	req, ok := httputil.Decode[orgDomain.UpdateOrganizationRequest](w, r)
+	if !ok {
 		return
 	}
 
-	if err := h.validator.Struct(&req); err != nil {
-		response.ValidationError(w, response.CodeValidationFailed, "Validation failed", h.formatValidationErrors(err))
+	// Get updater ID from context
+	updaterID := auth.UserID(r.Context())
+
+	org, err := h.orgUsecase.Update(r.Context(), id, req, updaterID)
+	if err != nil {
+		h.handleError(w, r, err)
 		return
 	}
 
-	// Get updater ID from context
-	updaterID, _ := r.Context().Value("user_id").(string)
+	response.OK(w, response.CodeSuccess, "Organization updated successfully", org)
+}
+
+// UpdateBranding handles replacing the branding of an organization
+func (h *Handler) UpdateBranding(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	req, ok := httputil.Decode[orgDomain.UpdateBrandingRequest](w, r)
+	if !ok {
+		return
+	}
 
-	org, err := h.orgUsecase.Update(r.Context(), id, &req, updaterID)
+	updaterID := auth.UserID(r.Context())
+
+	org, err := h.orgUsecase.UpdateBranding(r.Context(), id, req, updaterID)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
-	response.OK(w, response.CodeSuccess, "Organization updated successfully", org)
+	response.OK(w, response.CodeSuccess, "Organization branding updated successfully", org)
 }
 
 // Delete handles deleting an organization
 func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Organization ID is required", nil)
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
 	if err := h.orgUsecase.Delete(r.Context(), id); err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
 	response.OK(w, response.CodeSuccess, "Organization deleted successfully", nil)
 }
 
+// Restore handles restoring a deleted organization
+func (h *Handler) Restore(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.orgUsecase.Restore(r.Context(), id); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Organization restored successfully", nil)
+}
+
 // UpdateStatus handles updating organization status
 func (h *Handler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Organization ID is required", nil)
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
 	var req struct {
 		Status orgDomain.OrgStatus `json:"status" validate:"required"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, response.CodeBadRequest, "Invalid request body", nil)
-		return
-	}
-
-	if err := h.validator.Struct(&req); err != nil {
-		response.ValidationError(w, response.CodeValidationFailed, "Validation failed", h.formatValidationErrors(err))
+	if !httputil.DecodeInto(w, r, &req) {
 		return
 	}
 
 	if err := h.orgUsecase.UpdateStatus(r.Context(), id, req.Status); err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
 	response.OK(w, response.CodeSuccess, "Organization status updated successfully", nil)
 }
 
-func (h *Handler) handleError(w http.ResponseWriter, err error) {
-	if err == nil {
-		return
-	}
-
-	switch {
-	case errors.Is(err, pkgErrors.ErrNotFound):
-		response.NotFound(w, response.CodeNotFound, "Organization not found", nil)
-	case errors.Is(err, pkgErrors.ErrAlreadyExists):
-		response.Conflict(w, response.CodeConflict, "Organization code already exists", nil)
-	default:
-		response.InternalError(w, response.CodeInternalServerError, "Internal server error", nil)
-	}
-}
-
-func (h *Handler) formatValidationErrors(err error) []response.ErrorDetail {
-	var details []response.ErrorDetail
-	if validationErrors, ok := err.(validator.ValidationErrors); ok {
-		for _, fieldErr := range validationErrors {
-			details = append(details, response.ErrorDetail{
-				Field:   fieldErr.Field(),
-				Message: h.getValidationErrorMessage(fieldErr),
-			})
-		}
-	}
-	return details
-}
+// errorMapper maps the errors of the organization module on top of the shared ones
+var errorMapper = problem.Default.With(
+	problem.Mapping{Err: pkgErrors.ErrNotFound, Status: http.StatusNotFound, Code: response.CodeNotFound, Message: "Organization not found"},
+	problem.Mapping{Err: pkgErrors.ErrAlreadyExists, Status: http.StatusConflict, Code: response.CodeConflict, Message: "Organization code already exists"},
+)
 
-func (h *Handler) getValidationErrorMessage(fieldErr validator.FieldError) string {
-	switch fieldErr.Tag() {
-	case "required":
-		return fieldErr.Field() + " is required"
-	case "min":
-		return fieldErr.Field() + " must be at least " + fieldErr.Param() + " characters"
-	default:
-		return fieldErr.Field() + " is invalid"
-	}
+func (h *Handler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	errorMapper.Write(w, r, err)
 }
 
 func parseIntQuery(r *http.Request, key string, defaultValue int) int {
@@ -232,15 +221,26 @@ func parseIntQuery(r *http.Request, key string, defaultValue int) int {
 	return defaultValue
 }
 
-// RegisterRoutes registers organization routes
-func RegisterRoutes(r chi.Router, handler *Handler) {
+// RegisterRoutes registers organization routes. Reads are public and may
+// pick their fields; writes go through auth and require the
+// organizations:write permission, and restoring is left to users with one of
+// adminRoles.
+func RegisterRoutes(r chi.Router, handler *Handler, auth func(http.Handler) http.Handler, adminRoles []string, cached func(keys ...string) func(http.Handler) http.Handler) {
 	r.Route("/organizations", func(r chi.Router) {
-		r.Get("/", handler.List)
-		r.Post("/", handler.Create)
-		r.Get("/code/{code}", handler.GetByCode)
-		r.Get("/{id}", handler.GetByID)
-		r.Put("/{id}", handler.Update)
-		r.Delete("/{id}", handler.Delete)
-		r.Patch("/{id}/status", handler.UpdateStatus)
+		shape := middleware.Shape(nil)
+		r.With(cached(orgDomain.SurrogateKeyOrganizations), shape).Get("/", handler.List)
+		r.With(cached(), shape).Get("/code/{code}", handler.GetByCode)
+		r.With(cached(), shape).Get("/{id}", handler.GetByID)
+
+		r.Group(func(r chi.Router) {
+			r.Use(auth)
+			r.Use(middleware.RequirePermission(roleDomain.PermissionOrganizationsWrite))
+			r.Post("/", handler.Create)
+			r.Put("/{id}", handler.Update)
+			r.Put("/{id}/branding", handler.UpdateBranding)
+			r.Delete("/{id}", handler.Delete)
+			r.Patch("/{id}/status", handler.UpdateStatus)
+			r.With(middleware.RequireRole(adminRoles...)).Post("/{id}/restore", handler.Restore)
+		})
 	})
 }
@@ -0,0 +1,26 @@
+package http
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	orgDomain "portal-data-backend/internal/organization/domain"
+)
+
+// Describe adds the organization routes to the API specification
+func Describe(spec *openapi.Spec) {
+	api := spec.Tag("organizations", "Organizations publishing datasets")
+	api.Get("/organizations", "List organizations").Public().Shaped().
+		Query(orgDomain.ListOrganizationsRequest{}).Param("include_deleted", false).
+		Returns(http.StatusOK, orgDomain.OrganizationListResponse{})
+	api.Post("/organizations", "Create organization").Body(orgDomain.CreateOrganizationRequest{}).Returns(http.StatusCreated, orgDomain.OrganizationResponse{})
+	api.Get("/organizations/code/{code}", "Get organization by code").Public().Shaped().Param("include_deleted", false).Returns(http.StatusOK, orgDomain.OrganizationResponse{})
+	api.Get("/organizations/{id}", "Get organization").Public().Shaped().Param("include_deleted", false).Returns(http.StatusOK, orgDomain.OrganizationResponse{})
+	api.Put("/organizations/{id}", "Update organization").Body(orgDomain.UpdateOrganizationRequest{}).Returns(http.StatusOK, orgDomain.OrganizationResponse{})
+	api.Put("/organizations/{id}/branding", "Update organization branding").Body(orgDomain.UpdateBrandingRequest{}).Returns(http.StatusOK, orgDomain.OrganizationResponse{})
+	api.Delete("/organizations/{id}", "Delete organization").Returns(http.StatusOK, nil)
+	api.Patch("/organizations/{id}/status", "Update organization status").Body(struct {
+		Status orgDomain.OrgStatus `json:"status" validate:"required"`
+	}{}).Returns(http.StatusOK, nil)
+	api.Post("/organizations/{id}/restore", "Restore deleted organization").Returns(http.StatusOK, nil)
+}
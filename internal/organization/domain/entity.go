@@ -25,6 +25,12 @@ type Organization struct {
 	CreatedAt       time.Time  `db:"created_at" json:"created_at"`
 	UpdatedBy       *string    `db:"updated_by" json:"updated_by,omitempty"`
 	UpdatedAt       time.Time  `db:"updated_at" json:"updated_at"`
+	Names           string     `db:"names" json:"names"`               // JSON object of names by language code
+	Descriptions    string     `db:"descriptions" json:"descriptions"` // JSON object of descriptions by language code
+	Branding        string     `db:"branding" json:"branding"`         // JSON encoded Branding
+	DeletedAt       *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
+	// TenantID is the portal the organization belongs to
+	TenantID string `db:"tenant_id" json:"tenant_id"`
 }
 
 // OrgStatus represents organization status
@@ -45,6 +51,8 @@ type CreateOrganizationRequest struct {
 	Address     string `json:"address,omitempty"`
 	WebsiteURL  string `json:"website_url,omitempty"`
 	Email       string `json:"email,omitempty"`
+	Names        map[string]string `json:"names,omitempty"`
+	Descriptions map[string]string `json:"descriptions,omitempty"`
 }
 
 // UpdateOrganizationRequest represents organization update input
@@ -55,6 +63,29 @@ type UpdateOrganizationRequest struct {
 	Address     string `json:"address,omitempty"`
 	WebsiteURL  string `json:"website_url,omitempty"`
 	Email       string `json:"email,omitempty"`
+	Names        map[string]string `json:"names,omitempty"`
+	Descriptions map[string]string `json:"descriptions,omitempty"`
+}
+
+// Branding is the look of the pages of an organization
+type Branding struct {
+	PrimaryColor   string `json:"primary_color,omitempty"`
+	SecondaryColor string `json:"secondary_color,omitempty"`
+	BannerURL      string `json:"banner_url,omitempty"`
+	// Intro introduces the organization on its page, in the language the
+	// client prefers when Intros has it
+	Intro  string            `json:"intro,omitempty"`
+	Intros map[string]string `json:"intros,omitempty"`
+}
+
+// UpdateBrandingRequest represents organization branding input. It replaces
+// the whole branding; colors are hex codes like #1d4ed8.
+type UpdateBrandingRequest struct {
+	PrimaryColor   string            `json:"primary_color,omitempty" validate:"omitempty,hexcolor"`
+	SecondaryColor string            `json:"secondary_color,omitempty" validate:"omitempty,hexcolor"`
+	BannerURL      string            `json:"banner_url,omitempty" validate:"omitempty,url,max=2048"`
+	Intro          string            `json:"intro,omitempty" validate:"max=5000"`
+	Intros         map[string]string `json:"intros,omitempty" validate:"omitempty,dive,max=5000"`
 }
 
 // ListOrganizationsRequest represents list organizations input
@@ -67,7 +98,9 @@ type ListOrganizationsRequest struct {
 	SortOrder string `json:"sort_order,omitempty"`
 }
 
-// OrganizationResponse represents organization response
+// OrganizationResponse represents organization response. Read endpoints
+// return name and description in the language the client prefers when the
+// organization has it.
 type OrganizationResponse struct {
 	ID             string     `json:"id"`
 	Code           string     `json:"code"`
@@ -86,6 +119,11 @@ type OrganizationResponse struct {
 	Status         string     `json:"status"`
 	CreatedAt      time.Time  `json:"created_at"`
 	UpdatedAt      time.Time  `json:"updated_at"`
+	Names          map[string]string `json:"names"`
+	Descriptions   map[string]string `json:"descriptions"`
+	Branding       Branding          `json:"branding"`
+	// DeletedAt is set on deleted organizations, which only admins list
+	DeletedAt      *time.Time `json:"deleted_at,omitempty"`
 }
 
 // OrganizationListResponse represents paginated organization list
@@ -9,6 +9,10 @@ type Repository interface {
 	// GetByID retrieves an organization by ID
 	GetByID(ctx context.Context, id string) (*Organization, error)
 
+	// GetByIDs retrieves the organizations with ids, in no particular order.
+	// IDs of no organization are left out.
+	GetByIDs(ctx context.Context, ids []string) ([]*Organization, error)
+
 	// GetByCode retrieves an organization by code
 	GetByCode(ctx context.Context, code string) (*Organization, error)
 
@@ -27,6 +31,9 @@ type Repository interface {
 	// Delete soft deletes an organization
 	Delete(ctx context.Context, id string) error
 
+	// Restore brings back a soft deleted organization
+	Restore(ctx context.Context, id string) error
+
 	// UpdateStatus updates organization status
 	UpdateStatus(ctx context.Context, id string, status OrgStatus) error
 
@@ -35,4 +42,16 @@ type Repository interface {
 
 	// DecrementDatasetCount decrements dataset counters
 	DecrementDatasetCount(ctx context.Context, id string, isPublic bool) error
+
+	// RecountDatasets recomputes the dataset counters of every organization
+	// and returns how many were wrong
+	RecountDatasets(ctx context.Context) (int64, error)
+}
+
+// SurrogateKeyOrganizations tags cached responses listing organizations
+const SurrogateKeyOrganizations = "organizations"
+
+// SurrogateKey tags cached responses showing the organization of id
+func SurrogateKey(id string) string {
+	return "organization:" + id
 }
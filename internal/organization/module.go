@@ -0,0 +1,59 @@
+// Package organization is the module managing the organizations that publish data.
+package organization
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/cache"
+	"portal-data-backend/infrastructure/http/middleware"
+	"portal-data-backend/infrastructure/http/openapi"
+	"portal-data-backend/internal/app"
+	"portal-data-backend/internal/organization/delivery/cli"
+	delivery "portal-data-backend/internal/organization/delivery/http"
+	"portal-data-backend/internal/organization/repository"
+	"portal-data-backend/internal/organization/usecase"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Module manages organizations
+type Module struct {
+	handler    *delivery.Handler
+	usecase    usecase.Usecase
+	adminRoles []string
+	responses  *cache.Namespace
+	surrogates *cache.Surrogates
+}
+
+// Name implements app.Module
+func (m *Module) Name() string {
+	return "organization"
+}
+
+// Register implements app.Module
+func (m *Module) Register(deps *app.Deps) error {
+	repo := repository.NewOrgPostgresRepository(deps.DBRouter)
+	deps.Services.OrganizationCounters = repo
+	m.responses = deps.Cache.Namespace("responses", deps.Config.Cache.ResponseTTL)
+	m.surrogates = deps.Cache.Surrogates(deps.Config.Cache.ResponseTTL)
+	m.usecase = usecase.NewOrgUsecase(repo, deps.Cache.Namespace("organizations", deps.Config.Cache.OrganizationTTL), m.surrogates, deps.Services.Audit)
+	m.handler = delivery.NewHandler(m.usecase)
+	deps.Services.Organizations = m.usecase
+	m.adminRoles = deps.Config.Audit.AdminRoles
+	return nil
+}
+
+// Routes implements app.Module
+func (m *Module) Routes(r chi.Router, auth func(http.Handler) http.Handler) {
+	delivery.RegisterRoutes(r, m.handler, auth, m.adminRoles, middleware.ResponseCache(m.responses, m.surrogates))
+}
+
+// Describe implements app.Module
+func (m *Module) Describe(spec *openapi.Spec) {
+	delivery.Describe(spec)
+}
+
+// Commands implements app.Commander
+func (m *Module) Commands() []app.Command {
+	return cli.Commands(m.usecase)
+}
@@ -6,50 +6,79 @@ import (
 	"strings"
 	"time"
 
+	"portal-data-backend/infrastructure/db"
 	"portal-data-backend/internal/organization/domain"
 	"portal-data-backend/pkg/errors"
 
 	"github.com/jmoiron/sqlx"
 )
 
-// orgPostgresRepository implements Repository for PostgreSQL
+// orgPostgresRepository implements Repository for PostgreSQL. GetBySlug,
+// GetByIDs and List go to read replicas and may lag briefly behind writes.
 type orgPostgresRepository struct {
-	db *sqlx.DB
+	db *db.Router
 }
 
 // NewOrgPostgresRepository creates a new organization repository
-func NewOrgPostgresRepository(db *sqlx.DB) domain.Repository {
-	return &orgPostgresRepository{db: db}
+func NewOrgPostgresRepository(router *db.Router) domain.Repository {
+	return &orgPostgresRepository{db: router}
 }
 
 func (r *orgPostgresRepository) GetByID(ctx context.Context, id string) (*domain.Organization, error) {
-	query := `
+	query := fmt.Sprintf(`
 		SELECT id, code, name, slug, description, logo_url, phone_number, address,
 		       website_url, email, total_datasets, public_datasets, total_mapsets,
-		       public_mapsets, status, created_by, created_at, updated_by, updated_at
+		       public_mapsets, status, created_by, created_at, updated_by, updated_at,
+		       names, descriptions, branding, deleted_at, tenant_id
 		FROM organizations
-		WHERE id = $1
-	`
+		WHERE id = $1 AND %s AND %s
+	`, db.NotDeleted(ctx, "deleted_at"), db.InTenant(ctx, "tenant_id"))
 
 	var org domain.Organization
-	err := r.db.GetContext(ctx, &org, query, id)
+	err := r.db.Write(ctx).GetContext(ctx, &org, query, id)
 	if err != nil {
 		return nil, r.handleError(err)
 	}
 	return &org, nil
 }
 
+func (r *orgPostgresRepository) GetByIDs(ctx context.Context, ids []string) ([]*domain.Organization, error) {
+	orgs := []*domain.Organization{}
+	if len(ids) == 0 {
+		return orgs, nil
+	}
+
+	query, args, err := sqlx.In(fmt.Sprintf(`
+		SELECT id, code, name, slug, description, logo_url, phone_number, address,
+		       website_url, email, total_datasets, public_datasets, total_mapsets,
+		       public_mapsets, status, created_by, created_at, updated_by, updated_at,
+		       names, descriptions, branding, deleted_at, tenant_id
+		FROM organizations
+		WHERE id IN (?) AND %s AND %s
+	`, db.NotDeleted(ctx, "deleted_at"), db.InTenant(ctx, "tenant_id")), ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organizations: %w", err)
+	}
+
+	conn := r.db.Read(ctx)
+	if err := conn.SelectContext(ctx, &orgs, conn.Rebind(query), args...); err != nil {
+		return nil, fmt.Errorf("failed to get organizations: %w", err)
+	}
+	return orgs, nil
+}
+
 func (r *orgPostgresRepository) GetByCode(ctx context.Context, code string) (*domain.Organization, error) {
-	query := `
+	query := fmt.Sprintf(`
 		SELECT id, code, name, slug, description, logo_url, phone_number, address,
 		       website_url, email, total_datasets, public_datasets, total_mapsets,
-		       public_mapsets, status, created_by, created_at, updated_by, updated_at
+		       public_mapsets, status, created_by, created_at, updated_by, updated_at,
+		       names, descriptions, branding, deleted_at, tenant_id
 		FROM organizations
-		WHERE code = $1
-	`
+		WHERE code = $1 AND %s AND %s
+	`, db.NotDeleted(ctx, "deleted_at"), db.InTenant(ctx, "tenant_id"))
 
 	var org domain.Organization
-	err := r.db.GetContext(ctx, &org, query, code)
+	err := r.db.Write(ctx).GetContext(ctx, &org, query, code)
 	if err != nil {
 		return nil, r.handleError(err)
 	}
@@ -57,16 +86,17 @@ func (r *orgPostgresRepository) GetByCode(ctx context.Context, code string) (*do
 }
 
 func (r *orgPostgresRepository) GetBySlug(ctx context.Context, slug string) (*domain.Organization, error) {
-	query := `
+	query := fmt.Sprintf(`
 		SELECT id, code, name, slug, description, logo_url, phone_number, address,
 		       website_url, email, total_datasets, public_datasets, total_mapsets,
-		       public_mapsets, status, created_by, created_at, updated_by, updated_at
+		       public_mapsets, status, created_by, created_at, updated_by, updated_at,
+		       names, descriptions, branding, deleted_at, tenant_id
 		FROM organizations
-		WHERE slug = $1
-	`
+		WHERE slug = $1 AND %s AND %s
+	`, db.NotDeleted(ctx, "deleted_at"), db.InTenant(ctx, "tenant_id"))
 
 	var org domain.Organization
-	err := r.db.GetContext(ctx, &org, query, slug)
+	err := r.db.Read(ctx).GetContext(ctx, &org, query, slug)
 	if err != nil {
 		return nil, r.handleError(err)
 	}
@@ -74,7 +104,7 @@ func (r *orgPostgresRepository) GetBySlug(ctx context.Context, slug string) (*do
 }
 
 func (r *orgPostgresRepository) List(ctx context.Context, status, search string, limit, offset int, sortBy, sortOrder string) ([]*domain.Organization, int, error) {
-	whereClause := "WHERE 1=1"
+	whereClause := "WHERE " + db.NotDeleted(ctx, "deleted_at") + " AND " + db.InTenant(ctx, "tenant_id")
 	args := []interface{}{}
 	argCount := 1
 
@@ -86,14 +116,13 @@ func (r *orgPostgresRepository) List(ctx context.Context, status, search string,
 
 	if search != "" {
 		whereClause += fmt.Sprintf(" AND (name ILIKE $%d OR code ILIKE $%d)", argCount, argCount)
-		searchTerm := "%" + search + "%"
-		args = append(args, searchTerm, searchTerm)
+		args = append(args, "%"+search+"%")
 		argCount++
 	}
 
 	countQuery := "SELECT COUNT(*) FROM organizations " + whereClause
 	var total int
-	err := r.db.GetContext(ctx, &total, countQuery, args...)
+	err := r.db.Read(ctx).GetContext(ctx, &total, countQuery, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count organizations: %w", err)
 	}
@@ -102,14 +131,15 @@ func (r *orgPostgresRepository) List(ctx context.Context, status, search string,
 	query := `
 		SELECT id, code, name, slug, description, logo_url, phone_number, address,
 		       website_url, email, total_datasets, public_datasets, total_mapsets,
-		       public_mapsets, status, created_by, created_at, updated_by, updated_at
+		       public_mapsets, status, created_by, created_at, updated_by, updated_at,
+		       names, descriptions, branding, deleted_at, tenant_id
 		FROM organizations
 	` + whereClause + " " + orderClause + " LIMIT $" + fmt.Sprintf("%d", argCount) + " OFFSET $" + fmt.Sprintf("%d", argCount+1)
 
 	args = append(args, limit, offset)
 
 	var orgs []*domain.Organization
-	err = r.db.SelectContext(ctx, &orgs, query, args...)
+	err = r.db.Read(ctx).SelectContext(ctx, &orgs, query, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to list organizations: %w", err)
 	}
@@ -122,15 +152,17 @@ func (r *orgPostgresRepository) Create(ctx context.Context, org *domain.Organiza
 		INSERT INTO organizations (
 			id, code, name, slug, description, logo_url, phone_number, address,
 			website_url, email, total_datasets, public_datasets, total_mapsets,
-			public_mapsets, status, created_by, created_at, updated_by, updated_at
+			public_mapsets, status, created_by, created_at, updated_by, updated_at,
+			names, descriptions, branding, tenant_id
 		) VALUES (
 			:id, :code, :name, :slug, :description, :logo_url, :phone_number, :address,
 			:website_url, :email, :total_datasets, :public_datasets, :total_mapsets,
-			:public_mapsets, :status, :created_by, :created_at, :updated_by, :updated_at
+			:public_mapsets, :status, :created_by, :created_at, :updated_by, :updated_at,
+			:names, :descriptions, :branding, :tenant_id
 		)
 	`
 
-	_, err := r.db.NamedExecContext(ctx, query, org)
+	_, err := r.db.Write(ctx).NamedExecContext(ctx, query, org)
 	if err != nil {
 		return fmt.Errorf("failed to create organization: %w", err)
 	}
@@ -145,11 +177,12 @@ func (r *orgPostgresRepository) Update(ctx context.Context, org *domain.Organiza
 			code = :code, name = :name, slug = :slug, description = :description,
 			logo_url = :logo_url, phone_number = :phone_number, address = :address,
 			website_url = :website_url, email = :email, status = :status,
-			updated_by = :updated_by, updated_at = :updated_at
+			updated_by = :updated_by, updated_at = :updated_at,
+			names = :names, descriptions = :descriptions, branding = :branding
 		WHERE id = :id
 	`
 
-	result, err := r.db.NamedExecContext(ctx, query, org)
+	result, err := r.db.Write(ctx).NamedExecContext(ctx, query, org)
 	if err != nil {
 		return fmt.Errorf("failed to update organization: %w", err)
 	}
@@ -162,22 +195,16 @@ func (r *orgPostgresRepository) Update(ctx context.Context, org *domain.Organiza
 }
 
 func (r *orgPostgresRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM organizations WHERE id = $1`
-	result, err := r.db.ExecContext(ctx, query, id)
-	if err != nil {
-		return fmt.Errorf("failed to delete organization: %w", err)
-	}
+	return db.SoftDelete(ctx, r.db.Write(ctx), "organizations", id)
+}
 
-	rows, _ := result.RowsAffected()
-	if rows == 0 {
-		return errors.ErrNotFound
-	}
-	return nil
+func (r *orgPostgresRepository) Restore(ctx context.Context, id string) error {
+	return db.Restore(ctx, r.db.Write(ctx), "organizations", id)
 }
 
 func (r *orgPostgresRepository) UpdateStatus(ctx context.Context, id string, status domain.OrgStatus) error {
 	query := `UPDATE organizations SET status = $1, updated_at = NOW() WHERE id = $2`
-	result, err := r.db.ExecContext(ctx, query, status, id)
+	result, err := r.db.Write(ctx).ExecContext(ctx, query, status, id)
 	if err != nil {
 		return fmt.Errorf("failed to update organization status: %w", err)
 	}
@@ -198,7 +225,7 @@ func (r *orgPostgresRepository) IncrementDatasetCount(ctx context.Context, id st
 			    updated_at = NOW()
 			WHERE id = $1
 		`
-		_, err := r.db.ExecContext(ctx, query, id)
+		_, err := r.db.Write(ctx).ExecContext(ctx, query, id)
 		return err
 	}
 
@@ -207,7 +234,7 @@ func (r *orgPostgresRepository) IncrementDatasetCount(ctx context.Context, id st
 		SET total_datasets = total_datasets + 1, updated_at = NOW()
 		WHERE id = $1
 	`
-	_, err := r.db.ExecContext(ctx, query, id)
+	_, err := r.db.Write(ctx).ExecContext(ctx, query, id)
 	return err
 }
 
@@ -220,7 +247,7 @@ func (r *orgPostgresRepository) DecrementDatasetCount(ctx context.Context, id st
 			    updated_at = NOW()
 			WHERE id = $1
 		`
-		_, err := r.db.ExecContext(ctx, query, id)
+		_, err := r.db.Write(ctx).ExecContext(ctx, query, id)
 		return err
 	}
 
@@ -230,10 +257,37 @@ func (r *orgPostgresRepository) DecrementDatasetCount(ctx context.Context, id st
 		    updated_at = NOW()
 		WHERE id = $1
 	`
-	_, err := r.db.ExecContext(ctx, query, id)
+	_, err := r.db.Write(ctx).ExecContext(ctx, query, id)
 	return err
 }
 
+// RecountDatasets counts the datasets of each organization as the dataset
+// usecase does: archived and deleted datasets are left out and public ones
+// are classified public
+func (r *orgPostgresRepository) RecountDatasets(ctx context.Context) (int64, error) {
+	query := `
+		UPDATE organizations o
+		SET total_datasets = c.total,
+		    public_datasets = c.public,
+		    updated_at = NOW()
+		FROM (
+			SELECT org.id,
+			       COUNT(d.id) AS total,
+			       COUNT(d.id) FILTER (WHERE d.classification = 'public') AS public
+			FROM organizations org
+			LEFT JOIN datasets d ON d.organization_id = org.id AND d.status <> 'archived' AND d.deleted_at IS NULL
+			GROUP BY org.id
+		) c
+		WHERE o.id = c.id
+		  AND (o.total_datasets <> c.total OR o.public_datasets <> c.public)
+	`
+	result, err := r.db.Write(ctx).ExecContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to recount datasets: %w", err)
+	}
+	return result.RowsAffected()
+}
+
 func (r *orgPostgresRepository) buildOrderClause(sortBy, sortOrder string) string {
 	allowedColumns := map[string]bool{
 		"name":        true,
@@ -0,0 +1,154 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"portal-data-backend/infrastructure/db"
+	"portal-data-backend/infrastructure/tenant"
+	"portal-data-backend/internal/organization/domain"
+	"portal-data-backend/internal/testenv"
+	pkgErrors "portal-data-backend/pkg/errors"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(testenv.Main(m))
+}
+
+func newTestRepository(t *testing.T) domain.Repository {
+	t.Helper()
+	conn := testenv.Postgres(t)
+	testenv.Fixtures(t, conn, "catalog")
+	return NewOrgPostgresRepository(db.NewRouter(conn))
+}
+
+// listResult is a page of organizations as golden files record it
+type listResult struct {
+	Total         int                    `json:"total"`
+	Organizations []*domain.Organization `json:"organizations"`
+}
+
+// Test reads leave out deleted organizations and those of other tenants
+func TestOrgPostgresRepository_Reads(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	org, err := repo.GetByID(ctx, "10000000-0000-0000-0000-000000000001")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	testenv.Golden(t, "organization", org)
+
+	bySlug, err := repo.GetBySlug(ctx, "diskominfo")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if bySlug.ID != org.ID {
+		t.Errorf("Expected organization %s by slug, got %s", org.ID, bySlug.ID)
+	}
+
+	lists := map[string]listResult{}
+	for name, list := range map[string]struct {
+		ctx            context.Context
+		status, search string
+	}{
+		"all":            {ctx: ctx},
+		"active":         {ctx: ctx, status: "active"},
+		"search":         {ctx: ctx, search: "kesehatan"},
+		"tenant":         {ctx: tenant.WithTenant(ctx, &tenant.Tenant{ID: "jabar"})},
+		"includeDeleted": {ctx: db.WithDeleted(ctx), status: "active"},
+	} {
+		orgs, total, err := repo.List(list.ctx, list.status, list.search, 10, 0, "name", "asc")
+		if err != nil {
+			t.Fatalf("Expected no error listing %s, got %v", name, err)
+		}
+		lists[name] = listResult{Total: total, Organizations: orgs}
+	}
+	testenv.Golden(t, "organization_lists", lists)
+
+	_, err = repo.GetByID(tenant.WithTenant(ctx, &tenant.Tenant{ID: "jabar"}), org.ID)
+	if err == nil {
+		t.Errorf("Expected organizations of other tenants to be left out")
+	}
+}
+
+// Test created organizations read back as written, and deleted ones are
+// restored
+func TestOrgPostgresRepository_Writes(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	created := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	email := "disdik@example.go.id"
+	org := &domain.Organization{
+		ID:           "10000000-0000-0000-0000-000000000005",
+		Code:         "DISDIK",
+		Name:         "Dinas Pendidikan",
+		Slug:         "disdik",
+		Email:        &email,
+		Status:       domain.OrgStatusActive,
+		CreatedAt:    created,
+		UpdatedAt:    created,
+		Names:        `{"en": "Education Office"}`,
+		Descriptions: `{}`,
+		Branding:     `{}`,
+		TenantID:     "default",
+	}
+	if err := repo.Create(ctx, org); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	got, err := repo.GetByCode(ctx, "DISDIK")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	testenv.Golden(t, "organization_created", got)
+
+	if err := repo.Delete(ctx, org.ID); err != nil {
+		t.Fatalf("Expected no error deleting, got %v", err)
+	}
+	if _, err := repo.GetByID(ctx, org.ID); err == nil {
+		t.Errorf("Expected deleted organization to be left out")
+	}
+	if err := repo.Delete(ctx, org.ID); !errors.Is(err, pkgErrors.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound deleting twice, got %v", err)
+	}
+	if err := repo.Restore(ctx, org.ID); err != nil {
+		t.Fatalf("Expected no error restoring, got %v", err)
+	}
+	if _, err := repo.GetByID(ctx, org.ID); err != nil {
+		t.Errorf("Expected restored organization, got %v", err)
+	}
+
+	if err := repo.UpdateStatus(ctx, "10000000-0000-0000-0000-000000000009", domain.OrgStatusSuspended); !errors.Is(err, pkgErrors.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound updating a missing organization, got %v", err)
+	}
+}
+
+// Test dataset counts are recounted from the datasets left, leaving correct
+// counts alone
+func TestOrgPostgresRepository_RecountDatasets(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	updated, err := repo.RecountDatasets(ctx)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// Only the communication office counts its archived and deleted datasets
+	if updated != 1 {
+		t.Errorf("Expected 1 organization recounted, got %d", updated)
+	}
+
+	org, err := repo.GetByID(ctx, "10000000-0000-0000-0000-000000000001")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if org.TotalDatasets != 1 || org.PublicDatasets != 1 {
+		t.Errorf("Expected 1 dataset, 1 public, got %d, %d public", org.TotalDatasets, org.PublicDatasets)
+	}
+}
@@ -2,51 +2,107 @@ package usecase
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"math"
 	"strings"
 	"time"
 
+	"portal-data-backend/infrastructure/audit"
+	"portal-data-backend/infrastructure/cache"
+	"portal-data-backend/infrastructure/db"
+	"portal-data-backend/infrastructure/tenant"
 	"portal-data-backend/internal/organization/domain"
 	"portal-data-backend/pkg/errors"
+	"portal-data-backend/pkg/i18n"
 
 	"github.com/google/uuid"
 )
 
 // orgUsecase implements Usecase interface
 type orgUsecase struct {
-	orgRepo domain.Repository
+	orgRepo  domain.Repository
+	profiles   *cache.Namespace
+	surrogates *cache.Surrogates
+	audit      *audit.Recorder
 }
 
-// NewOrgUsecase creates a new organization usecase
-func NewOrgUsecase(orgRepo domain.Repository) Usecase {
+// NewOrgUsecase creates a new organization usecase. profiles caches
+// organizations looked up by ID, code or slug and may be nil, as may
+// surrogates, which purges the cached responses showing organizations, and
+// recorder.
+func NewOrgUsecase(orgRepo domain.Repository, profiles *cache.Namespace, surrogates *cache.Surrogates, recorder *audit.Recorder) Usecase {
 	return &orgUsecase{
-		orgRepo: orgRepo,
+		orgRepo:    orgRepo,
+		profiles:   profiles,
+		surrogates: surrogates,
+		audit:      recorder,
 	}
 }
 
 func (u *orgUsecase) GetByID(ctx context.Context, id string) (*domain.OrganizationResponse, error) {
-	org, err := u.orgRepo.GetByID(ctx, id)
+	return u.getProfile(ctx, "id:"+id, func() (*domain.Organization, error) {
+		return u.orgRepo.GetByID(ctx, id)
+	})
+}
+
+// GetByIDs loads the organizations in one query, bypassing the profile
+// cache that serves single lookups
+func (u *orgUsecase) GetByIDs(ctx context.Context, ids []string) ([]*domain.OrganizationResponse, error) {
+	orgs, err := u.orgRepo.GetByIDs(ctx, ids)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get organization: %w", err)
+		return nil, fmt.Errorf("failed to get organizations: %w", err)
 	}
-	return u.toResponse(org), nil
+
+	langs := i18n.Languages(ctx)
+	responses := make([]*domain.OrganizationResponse, len(orgs))
+	for i, org := range orgs {
+		responses[i] = u.toResponse(org, langs)
+	}
+	return responses, nil
 }
 
 func (u *orgUsecase) GetByCode(ctx context.Context, code string) (*domain.OrganizationResponse, error) {
-	org, err := u.orgRepo.GetByCode(ctx, code)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get organization: %w", err)
-	}
-	return u.toResponse(org), nil
+	return u.getProfile(ctx, "code:"+code, func() (*domain.Organization, error) {
+		return u.orgRepo.GetByCode(ctx, code)
+	})
 }
 
 func (u *orgUsecase) GetBySlug(ctx context.Context, slug string) (*domain.OrganizationResponse, error) {
-	org, err := u.orgRepo.GetBySlug(ctx, slug)
+	return u.getProfile(ctx, "slug:"+slug, func() (*domain.Organization, error) {
+		return u.orgRepo.GetBySlug(ctx, slug)
+	})
+}
+
+// getProfile returns the organization cached under key, loading and caching
+// it when it is not. Organizations are cached untranslated and translated per
+// request. Reads including deleted organizations skip the cache, which only
+// holds live ones.
+func (u *orgUsecase) getProfile(ctx context.Context, key string, load func() (*domain.Organization, error)) (*domain.OrganizationResponse, error) {
+	cacheable := !db.IncludesDeleted(ctx)
+	var org domain.Organization
+	if cacheable && u.profiles.Get(ctx, key, &org) {
+		return u.toResponse(&org, i18n.Languages(ctx)), nil
+	}
+
+	loaded, err := load()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get organization: %w", err)
 	}
-	return u.toResponse(org), nil
+	if cacheable {
+		u.profiles.Set(ctx, key, loaded)
+	}
+	return u.toResponse(loaded, i18n.Languages(ctx)), nil
+}
+
+// forgetProfile removes the cached lookups of org
+func (u *orgUsecase) forgetProfile(ctx context.Context, org *domain.Organization) {
+	u.profiles.Delete(ctx, "id:"+org.ID, "code:"+org.Code, "slug:"+org.Slug)
+}
+
+// purge invalidates the cached responses showing the organization of id
+func (u *orgUsecase) purge(ctx context.Context, id string) {
+	u.surrogates.Purge(ctx, domain.SurrogateKeyOrganizations, domain.SurrogateKey(id))
 }
 
 func (u *orgUsecase) List(ctx context.Context, req *domain.ListOrganizationsRequest) (*domain.OrganizationListResponse, error) {
@@ -69,7 +125,7 @@ func (u *orgUsecase) List(ctx context.Context, req *domain.ListOrganizationsRequ
 
 	responses := make([]domain.OrganizationResponse, len(orgs))
 	for i, org := range orgs {
-		responses[i] = *u.toResponse(org)
+		responses[i] = *u.toResponse(org, i18n.Languages(ctx))
 	}
 
 	totalPage := int(math.Ceil(float64(total) / float64(req.Limit)))
@@ -101,6 +157,11 @@ func (u *orgUsecase) Create(ctx context.Context, req *domain.CreateOrganizationR
 		Status:    domain.OrgStatusActive,
 		CreatedAt: now,
 		UpdatedAt: now,
+		Branding:  "{}",
+		TenantID:  tenant.DefaultID,
+	}
+	if id := tenant.ID(ctx); id != "" {
+		org.TenantID = id
 	}
 
 	if creatorID != "" {
@@ -121,12 +182,17 @@ func (u *orgUsecase) Create(ctx context.Context, req *domain.CreateOrganizationR
 	if req.Email != "" {
 		org.Email = &req.Email
 	}
+	if err := u.setTranslations(org, req.Names, req.Descriptions); err != nil {
+		return nil, err
+	}
 
 	if err := u.orgRepo.Create(ctx, org); err != nil {
 		return nil, fmt.Errorf("failed to create organization: %w", err)
 	}
+	u.purge(ctx, org.ID)
+	u.audit.Record(ctx, "organizations", org.ID, audit.ActionCreate, nil, org)
 
-	return u.toResponse(org), nil
+	return u.toResponse(org, nil), nil
 }
 
 func (u *orgUsecase) Update(ctx context.Context, id string, req *domain.UpdateOrganizationRequest, updaterID string) (*domain.OrganizationResponse, error) {
@@ -134,6 +200,8 @@ func (u *orgUsecase) Update(ctx context.Context, id string, req *domain.UpdateOr
 	if err != nil {
 		return nil, fmt.Errorf("failed to get organization: %w", err)
 	}
+	u.forgetProfile(ctx, org)
+	before := *org
 
 	org.Name = req.Name
 	org.Slug = u.generateSlug(req.Name)
@@ -167,35 +235,127 @@ func (u *orgUsecase) Update(ctx context.Context, id string, req *domain.UpdateOr
 	} else {
 		org.Email = nil
 	}
+	if err := u.setTranslations(org, req.Names, req.Descriptions); err != nil {
+		return nil, err
+	}
 
 	if err := u.orgRepo.Update(ctx, org); err != nil {
 		return nil, fmt.Errorf("failed to update organization: %w", err)
 	}
+	u.forgetProfile(ctx, org)
+	u.purge(ctx, org.ID)
+	u.audit.Record(ctx, "organizations", org.ID, audit.ActionUpdate, &before, org)
 
-	return u.toResponse(org), nil
+	return u.toResponse(org, nil), nil
+}
+
+func (u *orgUsecase) UpdateBranding(ctx context.Context, id string, req *domain.UpdateBrandingRequest, updaterID string) (*domain.OrganizationResponse, error) {
+	org, err := u.orgRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+	branding, err := json.Marshal(domain.Branding{
+		PrimaryColor:   strings.ToLower(req.PrimaryColor),
+		SecondaryColor: strings.ToLower(req.SecondaryColor),
+		BannerURL:      req.BannerURL,
+		Intro:          req.Intro,
+		Intros:         req.Intros,
+	})
+	if err != nil {
+		return nil, err
+	}
+	u.forgetProfile(ctx, org)
+	before := *org
+
+	org.Branding = string(branding)
+	org.UpdatedAt = time.Now()
+	if updaterID != "" {
+		org.UpdatedBy = &updaterID
+	}
+
+	if err := u.orgRepo.Update(ctx, org); err != nil {
+		return nil, fmt.Errorf("failed to update organization branding: %w", err)
+	}
+	u.forgetProfile(ctx, org)
+	u.purge(ctx, org.ID)
+	u.audit.Record(ctx, "organizations", org.ID, audit.ActionUpdate, &before, org)
+
+	return u.toResponse(org, nil), nil
 }
 
 func (u *orgUsecase) Delete(ctx context.Context, id string) error {
+	org, err := u.orgRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get organization: %w", err)
+	}
 	if err := u.orgRepo.Delete(ctx, id); err != nil {
 		return fmt.Errorf("failed to delete organization: %w", err)
 	}
+	u.forgetProfile(ctx, org)
+	u.purge(ctx, id)
+	u.audit.Record(ctx, "organizations", id, audit.ActionDelete, org, nil)
+	return nil
+}
+
+func (u *orgUsecase) Restore(ctx context.Context, id string) error {
+	if err := u.orgRepo.Restore(ctx, id); err != nil {
+		return fmt.Errorf("failed to restore organization: %w", err)
+	}
+	u.purge(ctx, id)
+	u.audit.Record(ctx, "organizations", id, audit.ActionRestore, nil, nil)
 	return nil
 }
 
 func (u *orgUsecase) UpdateStatus(ctx context.Context, id string, status domain.OrgStatus) error {
+	org, err := u.orgRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get organization: %w", err)
+	}
 	if err := u.orgRepo.UpdateStatus(ctx, id, status); err != nil {
 		return fmt.Errorf("failed to update organization status: %w", err)
 	}
+	u.forgetProfile(ctx, org)
+	u.purge(ctx, id)
+	updated := *org
+	updated.Status = status
+	u.audit.Record(ctx, "organizations", id, audit.ActionUpdate, org, &updated)
 	return nil
 }
 
-func (u *orgUsecase) toResponse(org *domain.Organization) *domain.OrganizationResponse {
+// RecountDatasets repairs counters that drifted from the datasets. Cached
+// profiles show the repaired counters once they expire, as they do after
+// any dataset change.
+func (u *orgUsecase) RecountDatasets(ctx context.Context) (int64, error) {
+	return u.orgRepo.RecountDatasets(ctx)
+}
+
+// toResponse converts an organization, translating its name and description
+// to the first of langs it has them in
+func (u *orgUsecase) toResponse(org *domain.Organization, langs []string) *domain.OrganizationResponse {
+	names := i18n.Decode(org.Names)
+	descriptions := i18n.Decode(org.Descriptions)
+
+	// Branding is written by this usecase; a value that does not decode is
+	// served as no branding
+	var branding domain.Branding
+	_ = json.Unmarshal([]byte(org.Branding), &branding)
+	branding.Intro = i18n.Pick(branding.Intros, langs, branding.Intro)
+
+	description := org.Description
+	base := ""
+	if description != nil {
+		base = *description
+	}
+	if localized := i18n.Pick(descriptions, langs, base); localized != "" {
+		description = &localized
+	}
+
 	return &domain.OrganizationResponse{
 		ID:             org.ID,
 		Code:           org.Code,
-		Name:           org.Name,
+		Name:           i18n.Pick(names, langs, org.Name),
 		Slug:           org.Slug,
-		Description:    org.Description,
+		Description:    description,
 		LogoURL:        org.LogoURL,
 		PhoneNumber:    org.PhoneNumber,
 		Address:        org.Address,
@@ -208,9 +368,30 @@ func (u *orgUsecase) toResponse(org *domain.Organization) *domain.OrganizationRe
 		Status:         string(org.Status),
 		CreatedAt:      org.CreatedAt,
 		UpdatedAt:      org.UpdatedAt,
+		Names:          names,
+		Descriptions:   descriptions,
+		Branding:       branding,
+		DeletedAt:      org.DeletedAt,
 	}
 }
 
+// setTranslations stores the localized names and descriptions of a request.
+// Nil maps keep the stored translations.
+func (u *orgUsecase) setTranslations(org *domain.Organization, names, descriptions map[string]string) error {
+	var err error
+	if names != nil || org.Names == "" {
+		if org.Names, err = i18n.Encode(names); err != nil {
+			return err
+		}
+	}
+	if descriptions != nil || org.Descriptions == "" {
+		if org.Descriptions, err = i18n.Encode(descriptions); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (u *orgUsecase) generateSlug(name string) string {
 	slug := strings.ToLower(name)
 	slug = strings.ReplaceAll(slug, " ", "-")
@@ -11,6 +11,9 @@ type Usecase interface {
 	// GetByID retrieves an organization by ID
 	GetByID(ctx context.Context, id string) (*domain.OrganizationResponse, error)
 
+	// GetByIDs retrieves the organizations with ids, in no particular order
+	GetByIDs(ctx context.Context, ids []string) ([]*domain.OrganizationResponse, error)
+
 	// GetByCode retrieves an organization by code
 	GetByCode(ctx context.Context, code string) (*domain.OrganizationResponse, error)
 
@@ -26,9 +29,19 @@ type Usecase interface {
 	// Update updates an existing organization
 	Update(ctx context.Context, id string, req *domain.UpdateOrganizationRequest, updaterID string) (*domain.OrganizationResponse, error)
 
+	// UpdateBranding replaces the colors, banner and intro of an organization
+	UpdateBranding(ctx context.Context, id string, req *domain.UpdateBrandingRequest, updaterID string) (*domain.OrganizationResponse, error)
+
 	// Delete soft deletes an organization
 	Delete(ctx context.Context, id string) error
 
+	// Restore brings back a soft deleted organization
+	Restore(ctx context.Context, id string) error
+
 	// UpdateStatus updates organization status
 	UpdateStatus(ctx context.Context, id string, status domain.OrgStatus) error
+
+	// RecountDatasets repairs the dataset counters of every organization and
+	// returns how many were wrong
+	RecountDatasets(ctx context.Context) (int64, error)
 }
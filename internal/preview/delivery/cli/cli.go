@@ -0,0 +1,31 @@
+// Package cli holds the portalctl commands of the preview module.
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"portal-data-backend/internal/app"
+	"portal-data-backend/internal/preview/usecase"
+)
+
+// Commands returns the preview commands
+func Commands(previewUsecase usecase.Usecase) []app.Command {
+	return []app.Command{
+		{
+			Name:    "previews",
+			Usage:   "previews",
+			Summary: "Generate preview images of the datasets without an image",
+			Run: func(ctx context.Context, in io.Reader, out io.Writer, args []string) error {
+				fmt.Fprintln(out, "Generating previews...")
+				generated, err := previewUsecase.Backfill(ctx)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(out, "Generated %d previews\n", generated)
+				return nil
+			},
+		},
+	}
+}
@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// Kind is what a preview image shows
+type Kind string
+
+const (
+	// KindChart is a chart of the first numeric columns of a dataset
+	KindChart Kind = "chart"
+	// KindCard is a card with the title of a dataset without numeric data
+	KindCard Kind = "card"
+)
+
+// Preview is the image generated for a dataset without one. Checksum
+// identifies what the image shows, so it is not rendered again unchanged.
+type Preview struct {
+	DatasetID   string    `db:"dataset_id" json:"dataset_id"`
+	FileID      string    `db:"file_id" json:"file_id"`
+	Image       string    `db:"image" json:"image"`
+	Kind        Kind      `db:"kind" json:"kind"`
+	Checksum    string    `db:"checksum" json:"checksum"`
+	GeneratedAt time.Time `db:"generated_at" json:"generated_at"`
+}
@@ -0,0 +1,12 @@
+package domain
+
+import "context"
+
+// Repository defines the interface for preview data access
+type Repository interface {
+	// Get retrieves the preview of a dataset
+	Get(ctx context.Context, datasetID string) (*Preview, error)
+
+	// Save creates or replaces the preview of a dataset
+	Save(ctx context.Context, preview *Preview) error
+}
@@ -0,0 +1,71 @@
+// Package preview is the module generating preview images for datasets
+// without an image of their own.
+package preview
+
+import (
+	"context"
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	"portal-data-backend/infrastructure/render"
+	"portal-data-backend/internal/app"
+	"portal-data-backend/internal/preview/delivery/cli"
+	"portal-data-backend/internal/preview/repository"
+	"portal-data-backend/internal/preview/usecase"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Module renders a chart or a title card for datasets left without an image
+// as they are created, updated and filled with rows, and stores it as their
+// image
+type Module struct {
+	usecase usecase.Usecase
+	enabled bool
+}
+
+// Name implements app.Module
+func (m *Module) Name() string {
+	return "preview"
+}
+
+// Register implements app.Module
+func (m *Module) Register(deps *app.Deps) error {
+	switch {
+	case deps.Services.Datasets == nil:
+		return app.MissingServiceError("dataset")
+	case deps.Services.DataRows == nil:
+		return app.MissingServiceError("data row")
+	case deps.Services.Files == nil:
+		return app.MissingServiceError("file")
+	}
+
+	cfg := deps.Config.Preview
+	repo := repository.NewPreviewPostgresRepository(deps.DB)
+	m.usecase = usecase.NewPreviewUsecase(repo, deps.Services.Datasets, deps.Services.DataRows, deps.Services.Files, render.NewRenderer(&cfg), cfg)
+	m.enabled = cfg.Enabled
+	if m.enabled {
+		deps.Events.Subscribe(m.usecase)
+	}
+	return nil
+}
+
+// Routes implements app.Module; previews have no endpoints
+func (m *Module) Routes(r chi.Router, auth func(http.Handler) http.Handler) {
+}
+
+// Describe implements app.Module
+func (m *Module) Describe(spec *openapi.Spec) {
+}
+
+// Run implements app.Runner
+func (m *Module) Run(ctx context.Context) {
+	if m.enabled {
+		m.usecase.Run(ctx)
+	}
+}
+
+// Commands implements app.Commander
+func (m *Module) Commands() []app.Command {
+	return cli.Commands(m.usecase)
+}
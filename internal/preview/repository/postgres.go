@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"portal-data-backend/infrastructure/db"
+	previewDomain "portal-data-backend/internal/preview/domain"
+	"portal-data-backend/pkg/errors"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type previewPostgresRepository struct {
+	db *sqlx.DB
+}
+
+func NewPreviewPostgresRepository(db *sqlx.DB) previewDomain.Repository {
+	return &previewPostgresRepository{db: db}
+}
+
+func (r *previewPostgresRepository) Get(ctx context.Context, datasetID string) (*previewDomain.Preview, error) {
+	query := `
+		SELECT dataset_id, file_id, image, kind, checksum, generated_at
+		FROM dataset_previews WHERE dataset_id = $1`
+
+	var preview previewDomain.Preview
+	err := db.Conn(ctx, r.db).GetContext(ctx, &preview, query, datasetID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("preview not found: %w", errors.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &preview, nil
+}
+
+func (r *previewPostgresRepository) Save(ctx context.Context, preview *previewDomain.Preview) error {
+	query := `
+		INSERT INTO dataset_previews (dataset_id, file_id, image, kind, checksum, generated_at)
+		VALUES (:dataset_id, :file_id, :image, :kind, :checksum, :generated_at)
+		ON CONFLICT (dataset_id) DO UPDATE
+		SET file_id = EXCLUDED.file_id, image = EXCLUDED.image, kind = EXCLUDED.kind,
+		    checksum = EXCLUDED.checksum, generated_at = EXCLUDED.generated_at`
+
+	if _, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, preview); err != nil {
+		return fmt.Errorf("failed to save preview: %w", err)
+	}
+	return nil
+}
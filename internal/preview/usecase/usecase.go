@@ -0,0 +1,265 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/infrastructure/logger"
+	"portal-data-backend/infrastructure/render"
+	dataRowDomain "portal-data-backend/internal/data_row/domain"
+	datasetDomain "portal-data-backend/internal/dataset/domain"
+	fileDomain "portal-data-backend/internal/file/domain"
+	"portal-data-backend/internal/preview/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+)
+
+// backfillPageSize is how many datasets a backfill reads at once
+const backfillPageSize = 100
+
+// DatasetStore is the part of the dataset module previews are made for
+type DatasetStore interface {
+	GetByID(ctx context.Context, id string) (*datasetDomain.DatasetResponse, error)
+	List(ctx context.Context, req *datasetDomain.ListDatasetsRequest) (*datasetDomain.DatasetListResponse, error)
+	SetGeneratedImage(ctx context.Context, id, image, previous string) (bool, error)
+}
+
+// RowSource is the part of the data row module charts are drawn from
+type RowSource interface {
+	List(ctx context.Context, req *dataRowDomain.ListDataRowsRequest) (*dataRowDomain.DataRowListResponse, error)
+}
+
+// ImageStore is the part of the file module preview images are stored through
+type ImageStore interface {
+	Upload(ctx context.Context, fileName string, fileSize int64, mimeType string, reader io.Reader, datasetID *string, userID string) (*fileDomain.UploadResponse, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// Usecase generates preview images for datasets without an image
+type Usecase interface {
+	// Publish queues the preview of the dataset of a dataset or row import
+	// event. Events fan out to it like to any other publisher.
+	Publish(ctx context.Context, eventType string, data interface{}) error
+	// Generate generates the preview of a dataset that has no image or
+	// still has its previous preview, reporting whether it set one
+	Generate(ctx context.Context, id string) (bool, error)
+	// Backfill generates the previews of every dataset without an image,
+	// returning how many it set
+	Backfill(ctx context.Context) (int, error)
+	// Run generates queued previews until ctx is done
+	Run(ctx context.Context)
+}
+
+type previewUsecase struct {
+	repo     domain.Repository
+	datasets DatasetStore
+	rows     RowSource
+	images   ImageStore
+	renderer render.Renderer
+	cfg      config.PreviewConfig
+	queue    chan string
+	now      func() time.Time
+}
+
+// NewPreviewUsecase creates the preview usecase
+func NewPreviewUsecase(repo domain.Repository, datasets DatasetStore, rows RowSource, images ImageStore, renderer render.Renderer, cfg config.PreviewConfig) Usecase {
+	queueSize := cfg.QueueSize
+	if queueSize < 1 {
+		queueSize = 1
+	}
+	return &previewUsecase{
+		repo:     repo,
+		datasets: datasets,
+		rows:     rows,
+		images:   images,
+		renderer: renderer,
+		cfg:      cfg,
+		queue:    make(chan string, queueSize),
+		now:      time.Now,
+	}
+}
+
+func (u *previewUsecase) Publish(ctx context.Context, eventType string, data interface{}) error {
+	key := "id"
+	switch eventType {
+	case datasetDomain.EventDatasetCreated, datasetDomain.EventDatasetUpdated:
+	case dataRowDomain.EventRowsImported:
+		key = "dataset_id"
+	default:
+		return nil
+	}
+
+	var id string
+	switch d := data.(type) {
+	case *datasetDomain.DatasetResponse:
+		id = d.ID
+	case map[string]interface{}:
+		id, _ = d[key].(string)
+	case json.RawMessage:
+		// Events relayed from the outbox carry their recorded JSON
+		var event map[string]interface{}
+		if err := json.Unmarshal(d, &event); err == nil {
+			id, _ = event[key].(string)
+		}
+	}
+	if id == "" {
+		return nil
+	}
+
+	select {
+	case u.queue <- id:
+	default:
+		logger.FromContext(ctx).Warn("preview queue is full, dataset %s gets no preview until the next backfill", id)
+	}
+	return nil
+}
+
+func (u *previewUsecase) Generate(ctx context.Context, id string) (bool, error) {
+	dataset, err := u.datasets.GetByID(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to get dataset: %w", err)
+	}
+	previous, err := u.repo.Get(ctx, id)
+	if err != nil && !errors.Is(err, pkgErrors.ErrNotFound) {
+		return false, fmt.Errorf("failed to get preview: %w", err)
+	}
+
+	// Images of their own are never replaced
+	current := ""
+	if dataset.Image != nil {
+		current = *dataset.Image
+	}
+	if current != "" && (previous == nil || previous.Image != current) {
+		return false, nil
+	}
+
+	card, kind, err := u.card(ctx, dataset)
+	if err != nil {
+		return false, err
+	}
+	checksum := checksumOf(card)
+	if previous != nil && previous.Image == current && previous.Checksum == checksum {
+		return false, nil
+	}
+
+	image, err := u.renderer.Render(ctx, card)
+	if err != nil {
+		return false, fmt.Errorf("failed to render preview: %w", err)
+	}
+	file, err := u.images.Upload(ctx, "preview-"+dataset.Slug+image.Extension(), int64(len(image.Data)), image.ContentType, bytes.NewReader(image.Data), nil, dataset.CreatedBy)
+	if err != nil {
+		return false, fmt.Errorf("failed to store preview: %w", err)
+	}
+
+	set, err := u.datasets.SetGeneratedImage(ctx, id, file.Path, current)
+	if err != nil || !set {
+		// The dataset got an image of its own meanwhile
+		u.deleteFile(ctx, file.ID)
+		if err != nil {
+			return false, fmt.Errorf("failed to set preview: %w", err)
+		}
+		return false, nil
+	}
+
+	preview := &domain.Preview{DatasetID: id, FileID: file.ID, Image: file.Path, Kind: kind, Checksum: checksum, GeneratedAt: u.now()}
+	if err := u.repo.Save(ctx, preview); err != nil {
+		return true, err
+	}
+	if previous != nil {
+		u.deleteFile(ctx, previous.FileID)
+	}
+	return true, nil
+}
+
+func (u *previewUsecase) Backfill(ctx context.Context) (int, error) {
+	var ids []string
+	for page := 1; ; page++ {
+		datasets, err := u.datasets.List(ctx, &datasetDomain.ListDatasetsRequest{Page: page, Limit: backfillPageSize})
+		if err != nil {
+			return 0, fmt.Errorf("failed to list datasets: %w", err)
+		}
+		for _, dataset := range datasets.Datasets {
+			if dataset.Image == nil || *dataset.Image == "" {
+				ids = append(ids, dataset.ID)
+			}
+		}
+		if page >= datasets.Meta.TotalPage {
+			break
+		}
+	}
+
+	generated := 0
+	for _, id := range ids {
+		set, err := u.Generate(ctx, id)
+		if err != nil {
+			return generated, fmt.Errorf("failed to generate preview of dataset %s: %w", id, err)
+		}
+		if set {
+			generated++
+		}
+	}
+	return generated, nil
+}
+
+func (u *previewUsecase) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-u.queue:
+			if _, err := u.Generate(ctx, id); err != nil && !errors.Is(err, pkgErrors.ErrNotFound) {
+				logger.FromContext(ctx).Error("failed to generate preview of dataset %s: %v", id, err)
+			}
+		}
+	}
+}
+
+// card returns the card of a dataset: a chart of the first numeric columns
+// of its first rows, or a title card when it has none. Rows are read as an
+// unprivileged reader, so masked columns are never drawn.
+func (u *previewUsecase) card(ctx context.Context, dataset *datasetDomain.DatasetResponse) (*render.Card, domain.Kind, error) {
+	card := &render.Card{
+		Title:  dataset.Name,
+		Brand:  u.cfg.Brand,
+		Color:  u.cfg.Color,
+		Width:  u.cfg.Width,
+		Height: u.cfg.Height,
+	}
+	if dataset.Topic != nil {
+		card.Subtitle = dataset.Topic.Name
+	}
+
+	rows, err := u.rows.List(ctx, &dataRowDomain.ListDataRowsRequest{DatasetID: dataset.ID, Page: 1, Limit: u.cfg.SampleRows})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list data rows: %w", err)
+	}
+	data := make([]string, len(rows.Rows))
+	for i, row := range rows.Rows {
+		data[i] = row.Data
+	}
+	card.Labels, card.Series = render.Chart(data, u.cfg.MaxSeries)
+	if len(card.Series) == 0 {
+		return card, domain.KindCard, nil
+	}
+	return card, domain.KindChart, nil
+}
+
+func (u *previewUsecase) deleteFile(ctx context.Context, id string) {
+	if err := u.images.Delete(ctx, id); err != nil {
+		logger.FromContext(ctx).Warn("failed to delete preview file %s: %v", id, err)
+	}
+}
+
+// checksumOf identifies what a card shows
+func checksumOf(card *render.Card) string {
+	data, _ := json.Marshal(card)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
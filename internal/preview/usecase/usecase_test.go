@@ -0,0 +1,176 @@
+package usecase_test
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"testing"
+
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/infrastructure/render"
+	dataRowDomain "portal-data-backend/internal/data_row/domain"
+	datasetDomain "portal-data-backend/internal/dataset/domain"
+	fileDomain "portal-data-backend/internal/file/domain"
+	"portal-data-backend/internal/preview/domain"
+	"portal-data-backend/internal/preview/usecase"
+	pkgerrors "portal-data-backend/pkg/errors"
+)
+
+// mockRepository is an in-memory implementation of Repository
+type mockRepository map[string]*domain.Preview
+
+func (m mockRepository) Get(ctx context.Context, datasetID string) (*domain.Preview, error) {
+	preview, ok := m[datasetID]
+	if !ok {
+		return nil, pkgerrors.ErrNotFound
+	}
+	return preview, nil
+}
+
+func (m mockRepository) Save(ctx context.Context, preview *domain.Preview) error {
+	m[preview.DatasetID] = preview
+	return nil
+}
+
+// mockDatasets keeps datasets in memory
+type mockDatasets map[string]*datasetDomain.DatasetResponse
+
+func (m mockDatasets) GetByID(ctx context.Context, id string) (*datasetDomain.DatasetResponse, error) {
+	dataset, ok := m[id]
+	if !ok {
+		return nil, pkgerrors.ErrNotFound
+	}
+	return dataset, nil
+}
+
+func (m mockDatasets) List(ctx context.Context, req *datasetDomain.ListDatasetsRequest) (*datasetDomain.DatasetListResponse, error) {
+	resp := &datasetDomain.DatasetListResponse{Meta: datasetDomain.ListMeta{TotalPage: 1}}
+	for _, dataset := range m {
+		resp.Datasets = append(resp.Datasets, *dataset)
+	}
+	return resp, nil
+}
+
+func (m mockDatasets) SetGeneratedImage(ctx context.Context, id, image, previous string) (bool, error) {
+	dataset := m[id]
+	if dataset.Image != nil && *dataset.Image != "" && *dataset.Image != previous {
+		return false, nil
+	}
+	dataset.Image = &image
+	return true, nil
+}
+
+// mockRows returns the rows of datasets by dataset ID
+type mockRows map[string][]string
+
+func (m mockRows) List(ctx context.Context, req *dataRowDomain.ListDataRowsRequest) (*dataRowDomain.DataRowListResponse, error) {
+	resp := &dataRowDomain.DataRowListResponse{}
+	for i, data := range m[req.DatasetID] {
+		resp.Rows = append(resp.Rows, dataRowDomain.DataRowInfo{DatasetID: req.DatasetID, RowIndex: i, Data: data})
+	}
+	return resp, nil
+}
+
+// mockImages stores uploaded images by file ID
+type mockImages struct {
+	files   map[string]string
+	deleted []string
+}
+
+func (m *mockImages) Upload(ctx context.Context, fileName string, fileSize int64, mimeType string, reader io.Reader, datasetID *string, userID string) (*fileDomain.UploadResponse, error) {
+	id := "file-" + strconv.Itoa(len(m.files)+len(m.deleted)+1)
+	m.files[id] = fileName
+	return &fileDomain.UploadResponse{ID: id, Path: "https://minio/" + id, MimeType: mimeType}, nil
+}
+
+func (m *mockImages) Delete(ctx context.Context, id string) error {
+	delete(m.files, id)
+	m.deleted = append(m.deleted, id)
+	return nil
+}
+
+// mockRenderer records the cards it renders
+type mockRenderer struct {
+	cards []*render.Card
+}
+
+func (m *mockRenderer) Render(ctx context.Context, card *render.Card) (*render.Image, error) {
+	m.cards = append(m.cards, card)
+	return &render.Image{Data: []byte("<svg/>"), ContentType: "image/svg+xml"}, nil
+}
+
+func newPreviewUsecase(datasets mockDatasets, rows mockRows) (usecase.Usecase, mockRepository, *mockImages, *mockRenderer) {
+	repo := mockRepository{}
+	images := &mockImages{files: map[string]string{}}
+	renderer := &mockRenderer{}
+	u := usecase.NewPreviewUsecase(repo, datasets, rows, images, renderer, config.PreviewConfig{
+		Width: 1200, Height: 630, SampleRows: 10, MaxSeries: 2, Brand: "Portal", Color: "#1d4ed8", QueueSize: 10,
+	})
+	return u, repo, images, renderer
+}
+
+// Test datasets without numeric data get a title card, which is replaced by
+// a chart of their first numeric columns once they have rows
+func TestPreview_CardThenChart(t *testing.T) {
+	ctx := context.Background()
+	datasets := mockDatasets{"ds-1": {ID: "ds-1", Name: "Rainfall", Slug: "rainfall"}}
+	rows := mockRows{}
+	u, repo, images, renderer := newPreviewUsecase(datasets, rows)
+
+	set, err := u.Generate(ctx, "ds-1")
+	if err != nil || !set {
+		t.Fatalf("Expected a preview to be set, got %v, %v", set, err)
+	}
+	if repo["ds-1"].Kind != domain.KindCard || len(renderer.cards[0].Series) != 0 {
+		t.Errorf("Expected a title card, got %s", repo["ds-1"].Kind)
+	}
+	if *datasets["ds-1"].Image != repo["ds-1"].Image {
+		t.Errorf("Expected the preview as image of the dataset, got %s", *datasets["ds-1"].Image)
+	}
+
+	// Nothing changed, so nothing is rendered again
+	if set, _ := u.Generate(ctx, "ds-1"); set || len(renderer.cards) != 1 {
+		t.Errorf("Expected an unchanged preview not to be rendered again, got %d renders", len(renderer.cards))
+	}
+
+	rows["ds-1"] = []string{
+		`{"month": "Jan", "rainfall": 12.5, "station": "North", "days": "4", "humidity": 80}`,
+		`{"month": "Feb", "rainfall": null, "station": "South", "days": "6", "humidity": 75}`,
+	}
+	if set, err := u.Generate(ctx, "ds-1"); err != nil || !set {
+		t.Fatalf("Expected the card to be replaced, got %v, %v", set, err)
+	}
+	card := renderer.cards[1]
+	if len(card.Series) != 2 || card.Series[0].Name != "rainfall" || card.Series[1].Name != "days" || card.Series[1].Values[1] != 6 {
+		t.Errorf("Expected the first two numeric columns, got %+v", card.Series)
+	}
+	if len(card.Labels) != 2 || card.Labels[0] != "Jan" {
+		t.Errorf("Expected the rows labelled by month, got %v", card.Labels)
+	}
+	if repo["ds-1"].Kind != domain.KindChart || len(images.files) != 1 || len(images.deleted) != 1 {
+		t.Errorf("Expected a chart with the card file deleted, got %s, %v and %v", repo["ds-1"].Kind, images.files, images.deleted)
+	}
+}
+
+// Test images of their own are never replaced, also not by a backfill
+func TestPreview_KeepsOwnImages(t *testing.T) {
+	ctx := context.Background()
+	own := "https://minio/own.png"
+	datasets := mockDatasets{
+		"ds-1": {ID: "ds-1", Name: "Rainfall", Image: &own},
+		"ds-2": {ID: "ds-2", Name: "Population"},
+	}
+	u, _, _, renderer := newPreviewUsecase(datasets, mockRows{})
+
+	if set, err := u.Generate(ctx, "ds-1"); err != nil || set || len(renderer.cards) != 0 {
+		t.Errorf("Expected a dataset with an image of its own to be left alone, got %v, %v", set, err)
+	}
+
+	generated, err := u.Backfill(ctx)
+	if err != nil || generated != 1 {
+		t.Errorf("Expected 1 preview to be generated, got %d, %v", generated, err)
+	}
+	if *datasets["ds-1"].Image != own {
+		t.Errorf("Expected the own image to be kept, got %s", *datasets["ds-1"].Image)
+	}
+}
@@ -1,42 +1,41 @@
 package http
 
 import (
-	"encoding/json"
-	"errors"
 	"net/http"
 	"strconv"
 
+	"portal-data-backend/infrastructure/auth"
 	pubDomain "portal-data-backend/internal/publication/domain"
 	"portal-data-backend/internal/publication/usecase"
+	"portal-data-backend/infrastructure/http/httputil"
+	"portal-data-backend/infrastructure/http/middleware"
+	"portal-data-backend/infrastructure/http/problem"
 	"portal-data-backend/infrastructure/http/response"
+	roleDomain "portal-data-backend/internal/role/domain"
 	pkgErrors "portal-data-backend/pkg/errors"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/go-playground/validator/v10"
 )
 
 type Handler struct {
 	pubUsecase usecase.Usecase
-	validator  *validator.Validate
 }
 
 func NewHandler(pubUsecase usecase.Usecase) *Handler {
 	return &Handler{
 		pubUsecase: pubUsecase,
-		validator:  validator.New(),
 	}
 }
 
 func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Publication ID is required", nil)
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
 	pub, err := h.pubUsecase.GetByID(r.Context(), id)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -67,7 +66,7 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 
 	resp, err := h.pubUsecase.List(r.Context(), req)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -75,22 +74,16 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
-	var req pubDomain.CreatePublicationRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, response.CodeBadRequest, "Invalid request body", nil)
+	req, ok := httputil.Decode[pubDomain.CreatePublicationRequest](w, r)
+	if !ok {
 		return
 	}
 
-	if err := h.validator.Struct(&req); err != nil {
-		response.ValidationError(w, response.CodeValidationFailed, "Validation failed", h.formatValidationErrors(err))
-		return
-	}
-
-	userID, _ := r.Context().Value("user_id").(string)
+	userID := auth.UserID(r.Context())
 
-	pub, err := h.pubUsecase.Create(r.Context(), &req, userID)
+	pub, err := h.pubUsecase.Create(r.Context(), req, userID)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -98,28 +91,21 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Publication ID is required", nil)
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
-	var req pubDomain.UpdatePublicationRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, response.CodeBadRequest, "Invalid request body", nil)
+	req, ok := httputil.Decode[pubDomain.UpdatePublicationRequest](w, r)
+	if !ok {
 		return
 	}
 
-	if err := h.validator.Struct(&req); err != nil {
-		response.ValidationError(w, response.CodeValidationFailed, "Validation failed", h.formatValidationErrors(err))
-		return
-	}
+	userID := auth.UserID(r.Context())
 
-	userID, _ := r.Context().Value("user_id").(string)
-
-	pub, err := h.pubUsecase.Update(r.Context(), id, &req, userID)
+	pub, err := h.pubUsecase.Update(r.Context(), id, req, userID)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -127,42 +113,66 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Publication ID is required", nil)
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
 	if err := h.pubUsecase.Delete(r.Context(), id); err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
 	response.OK(w, response.CodeSuccess, "Publication deleted successfully", nil)
 }
 
-func (h *Handler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Publication ID is required", nil)
+// Restore handles restoring a deleted publication
+func (h *Handler) Restore(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
-	var req struct {
-		Status string `json:"status" validate:"required"`
+	if err := h.pubUsecase.Restore(r.Context(), id); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Publication restored successfully", nil)
+}
+
+// ListTrash handles listing the deleted publications waiting to be purged
+func (h *Handler) ListTrash(w http.ResponseWriter, r *http.Request) {
+	req := &pubDomain.ListTrashRequest{
+		Page:           parseIntQuery(r, "page", 1),
+		Limit:          parseIntQuery(r, "limit", 20),
+		OrganizationID: r.URL.Query().Get("organization_id"),
+	}
+
+	resp, err := h.pubUsecase.ListTrash(r.Context(), req)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, response.CodeBadRequest, "Invalid request body", nil)
+
+	response.OK(w, response.CodeSuccess, "Trash retrieved successfully", resp)
+}
+
+func (h *Handler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
-	if err := h.validator.Struct(&req); err != nil {
-		response.ValidationError(w, response.CodeValidationFailed, "Validation failed", h.formatValidationErrors(err))
+	var req struct {
+		Status string `json:"status" validate:"required"`
+	}
+	if !httputil.DecodeInto(w, r, &req) {
 		return
 	}
 
 	if err := h.pubUsecase.UpdateStatus(r.Context(), id, req.Status); err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -170,14 +180,13 @@ func (h *Handler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) IncrementDownloadCount(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Publication ID is required", nil)
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
 	if err := h.pubUsecase.IncrementDownloadCount(r.Context(), id); err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -185,9 +194,8 @@ func (h *Handler) IncrementDownloadCount(w http.ResponseWriter, r *http.Request)
 }
 
 func (h *Handler) GetByDatasetID(w http.ResponseWriter, r *http.Request) {
-	datasetID := chi.URLParam(r, "datasetId")
-	if datasetID == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Dataset ID is required", nil)
+	datasetID, ok := httputil.UUIDParam(w, r, "datasetId")
+	if !ok {
 		return
 	}
 
@@ -196,7 +204,7 @@ func (h *Handler) GetByDatasetID(w http.ResponseWriter, r *http.Request) {
 
 	resp, err := h.pubUsecase.GetByDatasetID(r.Context(), datasetID, page, limit)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -204,9 +212,8 @@ func (h *Handler) GetByDatasetID(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) GetByOrganizationID(w http.ResponseWriter, r *http.Request) {
-	orgID := chi.URLParam(r, "orgId")
-	if orgID == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Organization ID is required", nil)
+	orgID, ok := httputil.UUIDParam(w, r, "orgId")
+	if !ok {
 		return
 	}
 
@@ -215,50 +222,20 @@ func (h *Handler) GetByOrganizationID(w http.ResponseWriter, r *http.Request) {
 
 	resp, err := h.pubUsecase.GetByOrganizationID(r.Context(), orgID, page, limit)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
 	response.OK(w, response.CodeSuccess, "Organization publications retrieved successfully", resp)
 }
 
-func (h *Handler) handleError(w http.ResponseWriter, err error) {
-	if err == nil {
-		return
-	}
-
-	switch {
-	case errors.Is(err, pkgErrors.ErrNotFound):
-		response.NotFound(w, response.CodeNotFound, "Publication not found", nil)
-	default:
-		response.InternalError(w, response.CodeInternalServerError, "Internal server error", nil)
-	}
-}
-
-func (h *Handler) formatValidationErrors(err error) []response.ErrorDetail {
-	var details []response.ErrorDetail
-	if validationErrors, ok := err.(validator.ValidationErrors); ok {
-		for _, fieldErr := range validationErrors {
-			details = append(details, response.ErrorDetail{
-				Field:   fieldErr.Field(),
-				Message: h.getValidationErrorMessage(fieldErr),
-			})
-		}
-	}
-	return details
-}
+// errorMapper maps the errors of the publication module on top of the shared ones
+var errorMapper = problem.Default.With(
+	problem.Mapping{Err: pkgErrors.ErrNotFound, Status: http.StatusNotFound, Code: response.CodeNotFound, Message: "Publication not found"},
+)
 
-func (h *Handler) getValidationErrorMessage(fieldErr validator.FieldError) string {
-	switch fieldErr.Tag() {
-	case "required":
-		return fieldErr.Field() + " is required"
-	case "min":
-		return fieldErr.Field() + " must be at least " + fieldErr.Param() + " characters"
-	case "max":
-		return fieldErr.Field() + " must be at most " + fieldErr.Param() + " characters"
-	default:
-		return fieldErr.Field() + " is invalid"
-	}
+func (h *Handler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	errorMapper.Write(w, r, err)
 }
 
 func parseIntQuery(r *http.Request, key string, defaultValue int) int {
@@ -270,16 +247,30 @@ func parseIntQuery(r *http.Request, key string, defaultValue int) int {
 	return defaultValue
 }
 
-func RegisterRoutes(r chi.Router, handler *Handler) {
+// RegisterRoutes registers publication routes. Reads are public and may expand
+// relations, and lists are cached by cached; a publication itself is not, as
+// reading it counts a view. Writes go through auth and require the
+// publications:write permission, and the trash and restoring are left to
+// users with one of adminRoles. Any signed-in user may count a download.
+func RegisterRoutes(r chi.Router, handler *Handler, auth func(http.Handler) http.Handler, relations response.Relations, adminRoles []string, cached func(keys ...string) func(http.Handler) http.Handler) {
 	r.Route("/publications", func(r chi.Router) {
-		r.Get("/", handler.List)
-		r.Post("/", handler.Create)
-		r.Get("/dataset/{datasetId}", handler.GetByDatasetID)
-		r.Get("/organization/{orgId}", handler.GetByOrganizationID)
-		r.Get("/{id}", handler.GetByID)
-		r.Put("/{id}", handler.Update)
-		r.Delete("/{id}", handler.Delete)
-		r.Patch("/{id}/status", handler.UpdateStatus)
-		r.Post("/{id}/download", handler.IncrementDownloadCount)
+		shape := middleware.Shape(relations)
+		lists := cached(pubDomain.SurrogateKeyPublications)
+		r.With(lists, shape).Get("/", handler.List)
+		r.With(lists, shape).Get("/dataset/{datasetId}", handler.GetByDatasetID)
+		r.With(lists, shape).Get("/organization/{orgId}", handler.GetByOrganizationID)
+		r.With(shape).Get("/{id}", handler.GetByID)
+
+		r.Group(func(r chi.Router) {
+			r.Use(auth)
+			write := middleware.RequirePermission(roleDomain.PermissionPublicationsWrite)
+			r.With(write).Post("/", handler.Create)
+			r.With(write).Put("/{id}", handler.Update)
+			r.With(write).Delete("/{id}", handler.Delete)
+			r.With(middleware.RequireRole(adminRoles...)).Get("/trash", handler.ListTrash)
+			r.With(middleware.RequireRole(adminRoles...)).Post("/{id}/restore", handler.Restore)
+			r.With(write).Patch("/{id}/status", handler.UpdateStatus)
+			r.Post("/{id}/download", handler.IncrementDownloadCount)
+		})
 	})
 }
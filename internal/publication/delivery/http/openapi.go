@@ -0,0 +1,30 @@
+package http
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	pubDomain "portal-data-backend/internal/publication/domain"
+)
+
+// Describe adds the publication routes to the API specification
+func Describe(spec *openapi.Spec) {
+	api := spec.Tag("publications", "Publications based on datasets")
+	api.Get("/publications", "List publications").Public().Shaped("dataset", "organization").Query(pubDomain.ListPublicationsRequest{}).Param("include_deleted", false).Returns(http.StatusOK, pubDomain.PublicationListResponse{})
+	api.Post("/publications", "Create publication").Body(pubDomain.CreatePublicationRequest{}).Returns(http.StatusCreated, pubDomain.PublicationInfo{})
+	api.Get("/publications/dataset/{datasetId}", "List publications of a dataset").Public().Shaped("dataset", "organization").
+		Query(pubDomain.ListPublicationsRequest{}, "page", "limit").
+		Returns(http.StatusOK, pubDomain.PublicationListResponse{})
+	api.Get("/publications/organization/{orgId}", "List publications of an organization").Public().Shaped("dataset", "organization").
+		Query(pubDomain.ListPublicationsRequest{}, "page", "limit").
+		Returns(http.StatusOK, pubDomain.PublicationListResponse{})
+	api.Get("/publications/{id}", "Get publication").Public().Shaped("dataset", "organization").Param("include_deleted", false).Returns(http.StatusOK, pubDomain.PublicationInfo{})
+	api.Put("/publications/{id}", "Update publication").Body(pubDomain.UpdatePublicationRequest{}).Returns(http.StatusOK, pubDomain.PublicationInfo{})
+	api.Delete("/publications/{id}", "Delete publication").Returns(http.StatusOK, nil)
+	api.Get("/publications/trash", "List deleted publications").Query(pubDomain.ListTrashRequest{}).Returns(http.StatusOK, pubDomain.TrashListResponse{})
+	api.Post("/publications/{id}/restore", "Restore deleted publication").Returns(http.StatusOK, nil)
+	api.Patch("/publications/{id}/status", "Update publication status").Body(struct {
+		Status string `json:"status" validate:"required"`
+	}{}).Returns(http.StatusOK, nil)
+	api.Post("/publications/{id}/download", "Count a publication download").Returns(http.StatusOK, nil)
+}
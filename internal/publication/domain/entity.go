@@ -97,6 +97,8 @@ type PublicationInfo struct {
 	CreatedBy      string     `json:"created_by"`
 	CreatedAt      time.Time  `json:"created_at"`
 	UpdatedAt      time.Time  `json:"updated_at"`
+	// DeletedAt is set on deleted publications, which only admins list
+	DeletedAt      *time.Time `json:"deleted_at,omitempty"`
 }
 
 // PublicationListResponse represents paginated publication list
@@ -105,6 +107,32 @@ type PublicationListResponse struct {
 	Meta         ListMeta          `json:"meta"`
 }
 
+// TrashedPublication is a deleted publication in the trash, which can be
+// restored until PurgeAt
+type TrashedPublication struct {
+	ID             string    `db:"id" json:"id"`
+	Title          string    `db:"title" json:"title"`
+	DatasetID      *string   `db:"dataset_id" json:"dataset_id,omitempty"`
+	OrganizationID *string   `db:"organization_id" json:"organization_id,omitempty"`
+	Status         string    `db:"status" json:"status"`
+	DeletedAt      time.Time `db:"deleted_at" json:"deleted_at"`
+	PurgeAt        time.Time `db:"-" json:"purge_at"`
+}
+
+// ListTrashRequest represents list trash input
+type ListTrashRequest struct {
+	Page           int    `json:"page" validate:"min=1"`
+	Limit          int    `json:"limit" validate:"min=1,max=100"`
+	OrganizationID string `json:"organization_id,omitempty"`
+}
+
+// TrashListResponse represents a page of the deleted publications, the most
+// recently deleted first
+type TrashListResponse struct {
+	Publications []TrashedPublication `json:"publications"`
+	Meta         ListMeta             `json:"meta"`
+}
+
 // ListMeta represents pagination metadata
 type ListMeta struct {
 	Page      int `json:"page"`
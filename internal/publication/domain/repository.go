@@ -10,6 +10,10 @@ type Repository interface {
 	Create(ctx context.Context, pub *Publication) error
 	Update(ctx context.Context, id string, pub *Publication) error
 	Delete(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) error
+	// Trash retrieves the deleted publications, of the organization orgID
+	// unless it is empty, the most recently deleted first
+	Trash(ctx context.Context, orgID string, limit, offset int) ([]*TrashedPublication, int, error)
 	UpdateStatus(ctx context.Context, id string, status string) error
 	IncrementViewCount(ctx context.Context, id string) error
 	IncrementDownloadCount(ctx context.Context, id string) error
@@ -24,3 +28,6 @@ type PublicationFilter struct {
 	IsFeatured     *bool
 	Search         string
 }
+
+// SurrogateKeyPublications tags cached responses listing publications
+const SurrogateKeyPublications = "publications"
@@ -0,0 +1,87 @@
+// Package publication is the module managing publications based on datasets.
+package publication
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"portal-data-backend/infrastructure/cache"
+	"portal-data-backend/infrastructure/db"
+	"portal-data-backend/infrastructure/http/middleware"
+	"portal-data-backend/infrastructure/http/openapi"
+	"portal-data-backend/infrastructure/http/response"
+	"portal-data-backend/internal/app"
+	datasetDomain "portal-data-backend/internal/dataset/domain"
+	orgDomain "portal-data-backend/internal/organization/domain"
+	delivery "portal-data-backend/internal/publication/delivery/http"
+	"portal-data-backend/internal/publication/repository"
+	"portal-data-backend/internal/publication/usecase"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jmoiron/sqlx"
+)
+
+// Module manages publications
+type Module struct {
+	handler    *delivery.Handler
+	db         *sqlx.DB
+	relations  response.Relations
+	adminRoles []string
+	responses  *cache.Namespace
+	surrogates *cache.Surrogates
+}
+
+// Name implements app.Module
+func (m *Module) Name() string {
+	return "publication"
+}
+
+// Register implements app.Module
+func (m *Module) Register(deps *app.Deps) error {
+	if deps.Services.Datasets == nil {
+		return app.MissingServiceError("dataset")
+	}
+	if deps.Services.Organizations == nil {
+		return app.MissingServiceError("organization")
+	}
+
+	m.db = deps.DB
+	repo := repository.NewPublicationPostgresRepository(deps.DB)
+	m.responses = deps.Cache.Namespace("responses", deps.Config.Cache.ResponseTTL)
+	m.surrogates = deps.Cache.Surrogates(deps.Config.Cache.ResponseTTL)
+	publications := usecase.NewPublicationUsecase(repo, m.surrogates, deps.Config.Trash, deps.Services.Analytics)
+	deps.Services.Publications = publications
+	m.handler = delivery.NewHandler(publications)
+	m.relations = response.Relations{
+		"dataset": {
+			Key: "dataset_id",
+			Load: response.LoadBy(deps.Services.Datasets.GetByIDs, func(dataset *datasetDomain.DatasetResponse) string {
+				return dataset.ID
+			}),
+		},
+		"organization": {
+			Key: "organization_id",
+			Load: response.LoadBy(deps.Services.Organizations.GetByIDs, func(org *orgDomain.OrganizationResponse) string {
+				return org.ID
+			}),
+		},
+	}
+	m.adminRoles = deps.Config.Audit.AdminRoles
+	return nil
+}
+
+// Routes implements app.Module
+func (m *Module) Routes(r chi.Router, auth func(http.Handler) http.Handler) {
+	delivery.RegisterRoutes(r, m.handler, auth, m.relations, m.adminRoles, middleware.ResponseCache(m.responses, m.surrogates))
+}
+
+// Describe implements app.Module
+func (m *Module) Describe(spec *openapi.Spec) {
+	delivery.Describe(spec)
+}
+
+// Purge implements app.Purger
+func (m *Module) Purge(ctx context.Context, before time.Time) (int64, error) {
+	return db.PurgeDeleted(ctx, m.db, "publications", before)
+}
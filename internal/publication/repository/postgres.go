@@ -8,6 +8,8 @@ import (
 
 	pubDomain "portal-data-backend/internal/publication/domain"
 
+	"portal-data-backend/infrastructure/db"
+
 	"github.com/jmoiron/sqlx"
 )
 
@@ -25,11 +27,10 @@ func (r *publicationPostgresRepository) GetByID(ctx context.Context, id string)
 		       authors, tags, status, is_featured, view_count, download_count,
 		       created_by, updated_by, created_at, updated_at, deleted_at
 		FROM publications
-		WHERE id = $1 AND deleted_at IS NULL
-	`
+		WHERE id = $1 AND ` + db.NotDeleted(ctx, "deleted_at") + " AND " + db.InTenantOrganizations(ctx, "organization_id")
 
 	var pub pubDomain.Publication
-	err := r.db.GetContext(ctx, &pub, query, id)
+	err := db.Conn(ctx, r.db).GetContext(ctx, &pub, query, id)
 	if err != nil {
 		return nil, r.handleError(err)
 	}
@@ -37,7 +38,7 @@ func (r *publicationPostgresRepository) GetByID(ctx context.Context, id string)
 }
 
 func (r *publicationPostgresRepository) List(ctx context.Context, filter *pubDomain.PublicationFilter, limit, offset int) ([]*pubDomain.Publication, int, error) {
-	whereClause := "WHERE deleted_at IS NULL"
+	whereClause := "WHERE " + db.NotDeleted(ctx, "deleted_at") + " AND " + db.InTenantOrganizations(ctx, "organization_id")
 	args := []interface{}{}
 	argCount := 1
 
@@ -72,7 +73,7 @@ func (r *publicationPostgresRepository) List(ctx context.Context, filter *pubDom
 
 	countQuery := "SELECT COUNT(*) FROM publications " + whereClause
 	var total int
-	err := r.db.GetContext(ctx, &total, countQuery, args...)
+	err := db.Conn(ctx, r.db).GetContext(ctx, &total, countQuery, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count publications: %w", err)
 	}
@@ -87,7 +88,7 @@ func (r *publicationPostgresRepository) List(ctx context.Context, filter *pubDom
 	args = append(args, limit, offset)
 
 	var pubs []*pubDomain.Publication
-	err = r.db.SelectContext(ctx, &pubs, query, args...)
+	err = db.Conn(ctx, r.db).SelectContext(ctx, &pubs, query, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to list publications: %w", err)
 	}
@@ -108,7 +109,7 @@ func (r *publicationPostgresRepository) Create(ctx context.Context, pub *pubDoma
 		)
 	`
 
-	_, err := r.db.NamedExecContext(ctx, query, pub)
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, pub)
 	if err != nil {
 		return fmt.Errorf("failed to create publication: %w", err)
 	}
@@ -126,7 +127,7 @@ func (r *publicationPostgresRepository) Update(ctx context.Context, id string, p
 	`
 
 	pub.ID = id
-	_, err := r.db.NamedExecContext(ctx, query, pub)
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, pub)
 	if err != nil {
 		return fmt.Errorf("failed to update publication: %w", err)
 	}
@@ -134,17 +135,39 @@ func (r *publicationPostgresRepository) Update(ctx context.Context, id string, p
 }
 
 func (r *publicationPostgresRepository) Delete(ctx context.Context, id string) error {
-	query := `UPDATE publications SET deleted_at = $1 WHERE id = $2`
-	_, err := r.db.ExecContext(ctx, query, time.Now(), id)
-	if err != nil {
-		return fmt.Errorf("failed to delete publication: %w", err)
+	return db.SoftDelete(ctx, db.Conn(ctx, r.db), "publications", id)
+}
+
+func (r *publicationPostgresRepository) Restore(ctx context.Context, id string) error {
+	return db.Restore(ctx, db.Conn(ctx, r.db), "publications", id)
+}
+
+func (r *publicationPostgresRepository) Trash(ctx context.Context, orgID string, limit, offset int) ([]*pubDomain.TrashedPublication, int, error) {
+	whereClause := "WHERE deleted_at IS NOT NULL AND " + db.InTenantOrganizations(ctx, "organization_id") +
+		" AND ($1 = '' OR organization_id::text = $1)"
+
+	var total int
+	if err := db.Conn(ctx, r.db).GetContext(ctx, &total, "SELECT COUNT(*) FROM publications "+whereClause, orgID); err != nil {
+		return nil, 0, fmt.Errorf("failed to count deleted publications: %w", err)
 	}
-	return nil
+
+	query := `
+		SELECT id, title, dataset_id, organization_id, status, deleted_at
+		FROM publications
+		` + whereClause + `
+		ORDER BY deleted_at DESC, id
+		LIMIT $2 OFFSET $3
+	`
+	pubs := []*pubDomain.TrashedPublication{}
+	if err := db.Conn(ctx, r.db).SelectContext(ctx, &pubs, query, orgID, limit, offset); err != nil {
+		return nil, 0, fmt.Errorf("failed to list deleted publications: %w", err)
+	}
+	return pubs, total, nil
 }
 
 func (r *publicationPostgresRepository) UpdateStatus(ctx context.Context, id string, status string) error {
 	query := `UPDATE publications SET status = $1, updated_at = $2 WHERE id = $3`
-	_, err := r.db.ExecContext(ctx, query, status, time.Now(), id)
+	_, err := db.Conn(ctx, r.db).ExecContext(ctx, query, status, time.Now(), id)
 	if err != nil {
 		return fmt.Errorf("failed to update publication status: %w", err)
 	}
@@ -153,7 +176,7 @@ func (r *publicationPostgresRepository) UpdateStatus(ctx context.Context, id str
 
 func (r *publicationPostgresRepository) IncrementViewCount(ctx context.Context, id string) error {
 	query := `UPDATE publications SET view_count = view_count + 1 WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
+	_, err := db.Conn(ctx, r.db).ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to increment view count: %w", err)
 	}
@@ -162,7 +185,7 @@ func (r *publicationPostgresRepository) IncrementViewCount(ctx context.Context,
 
 func (r *publicationPostgresRepository) IncrementDownloadCount(ctx context.Context, id string) error {
 	query := `UPDATE publications SET download_count = download_count + 1 WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
+	_, err := db.Conn(ctx, r.db).ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to increment download count: %w", err)
 	}
@@ -181,14 +204,14 @@ func (r *publicationPostgresRepository) GetByDatasetID(ctx context.Context, data
 	`
 
 	var pubs []*pubDomain.Publication
-	err := r.db.SelectContext(ctx, &pubs, query, datasetID, limit, offset)
+	err := db.Conn(ctx, r.db).SelectContext(ctx, &pubs, query, datasetID, limit, offset)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to get dataset publications: %w", err)
 	}
 
 	countQuery := `SELECT COUNT(*) FROM publications WHERE dataset_id = $1 AND deleted_at IS NULL`
 	var total int
-	err = r.db.GetContext(ctx, &total, countQuery, datasetID)
+	err = db.Conn(ctx, r.db).GetContext(ctx, &total, countQuery, datasetID)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count dataset publications: %w", err)
 	}
@@ -208,14 +231,14 @@ func (r *publicationPostgresRepository) GetByOrganizationID(ctx context.Context,
 	`
 
 	var pubs []*pubDomain.Publication
-	err := r.db.SelectContext(ctx, &pubs, query, orgID, limit, offset)
+	err := db.Conn(ctx, r.db).SelectContext(ctx, &pubs, query, orgID, limit, offset)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to get organization publications: %w", err)
 	}
 
 	countQuery := `SELECT COUNT(*) FROM publications WHERE organization_id = $1 AND deleted_at IS NULL`
 	var total int
-	err = r.db.GetContext(ctx, &total, countQuery, orgID)
+	err = db.Conn(ctx, r.db).GetContext(ctx, &total, countQuery, orgID)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count organization publications: %w", err)
 	}
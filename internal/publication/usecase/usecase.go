@@ -6,7 +6,10 @@ import (
 	"math"
 	"time"
 
+	"portal-data-backend/infrastructure/cache"
+	"portal-data-backend/infrastructure/config"
 	"portal-data-backend/internal/publication/domain"
+	"portal-data-backend/pkg/tracking"
 
 	"github.com/google/uuid"
 )
@@ -17,6 +20,10 @@ type Usecase interface {
 	Create(ctx context.Context, req *domain.CreatePublicationRequest, userID string) (*domain.PublicationInfo, error)
 	Update(ctx context.Context, id string, req *domain.UpdatePublicationRequest, userID string) (*domain.PublicationInfo, error)
 	Delete(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) error
+	// ListTrash retrieves a page of the deleted publications with when each
+	// is purged
+	ListTrash(ctx context.Context, req *domain.ListTrashRequest) (*domain.TrashListResponse, error)
 	UpdateStatus(ctx context.Context, id string, status string) error
 	IncrementViewCount(ctx context.Context, id string) error
 	IncrementDownloadCount(ctx context.Context, id string) error
@@ -24,13 +31,29 @@ type Usecase interface {
 	GetByOrganizationID(ctx context.Context, orgID string, page, limit int) (*domain.PublicationListResponse, error)
 }
 
+// TrafficRecorder counts the views and downloads of publications by the
+// channel they came from
+type TrafficRecorder interface {
+	RecordTraffic(ctx context.Context, entityType, entityID, metric string) error
+}
+
 type publicationUsecase struct {
-	repo domain.Repository
+	repo       domain.Repository
+	surrogates *cache.Surrogates
+	trash      config.TrashConfig
+	traffic    TrafficRecorder
 }
 
-func NewPublicationUsecase(repo domain.Repository) Usecase {
+// NewPublicationUsecase creates a new publication usecase. surrogates purges
+// the cached responses listing publications and traffic counts views and
+// downloads by channel; both may be nil. Deleted publications stay in the
+// trash as trash configures.
+func NewPublicationUsecase(repo domain.Repository, surrogates *cache.Surrogates, trash config.TrashConfig, traffic TrafficRecorder) Usecase {
 	return &publicationUsecase{
-		repo: repo,
+		repo:       repo,
+		surrogates: surrogates,
+		trash:      trash,
+		traffic:    traffic,
 	}
 }
 
@@ -39,8 +62,8 @@ func (u *publicationUsecase) GetByID(ctx context.Context, id string) (*domain.Pu
 	if err != nil {
 		return nil, fmt.Errorf("failed to get publication: %w", err)
 	}
-	// Increment view count
-	go u.repo.IncrementViewCount(ctx, id)
+	// Increment view count, unless the client opted out of analytics
+	go u.IncrementViewCount(ctx, id)
 	return u.toInfo(pub), nil
 }
 
@@ -112,6 +135,7 @@ func (u *publicationUsecase) Create(ctx context.Context, req *domain.CreatePubli
 	if err := u.repo.Create(ctx, pub); err != nil {
 		return nil, fmt.Errorf("failed to create publication: %w", err)
 	}
+	u.purge(ctx)
 
 	return u.toInfo(pub), nil
 }
@@ -165,6 +189,7 @@ func (u *publicationUsecase) Update(ctx context.Context, id string, req *domain.
 	if err := u.repo.Update(ctx, id, existing); err != nil {
 		return nil, fmt.Errorf("failed to update publication: %w", err)
 	}
+	u.purge(ctx)
 
 	return u.toInfo(existing), nil
 }
@@ -173,28 +198,85 @@ func (u *publicationUsecase) Delete(ctx context.Context, id string) error {
 	if err := u.repo.Delete(ctx, id); err != nil {
 		return fmt.Errorf("failed to delete publication: %w", err)
 	}
+	u.purge(ctx)
 	return nil
 }
 
+func (u *publicationUsecase) Restore(ctx context.Context, id string) error {
+	if err := u.repo.Restore(ctx, id); err != nil {
+		return fmt.Errorf("failed to restore publication: %w", err)
+	}
+	u.purge(ctx)
+	return nil
+}
+
+func (u *publicationUsecase) ListTrash(ctx context.Context, req *domain.ListTrashRequest) (*domain.TrashListResponse, error) {
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.Limit < 1 || req.Limit > 100 {
+		req.Limit = 20
+	}
+
+	pubs, total, err := u.repo.Trash(ctx, req.OrganizationID, req.Limit, (req.Page-1)*req.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deleted publications: %w", err)
+	}
+
+	resp := &domain.TrashListResponse{
+		Publications: make([]domain.TrashedPublication, len(pubs)),
+		Meta: domain.ListMeta{
+			Page:      req.Page,
+			Limit:     req.Limit,
+			Total:     total,
+			TotalPage: int(math.Ceil(float64(total) / float64(req.Limit))),
+		},
+	}
+	for i, pub := range pubs {
+		pub.PurgeAt = pub.DeletedAt.Add(u.trash.Retention)
+		resp.Publications[i] = *pub
+	}
+	return resp, nil
+}
+
 func (u *publicationUsecase) UpdateStatus(ctx context.Context, id string, status string) error {
 	if err := u.repo.UpdateStatus(ctx, id, status); err != nil {
 		return fmt.Errorf("failed to update publication status: %w", err)
 	}
+	u.purge(ctx)
 	return nil
 }
 
+// IncrementViewCount counts a view, unless the client opted out of
+// analytics
 func (u *publicationUsecase) IncrementViewCount(ctx context.Context, id string) error {
+	if tracking.OptedOut(ctx) {
+		return nil
+	}
 	if err := u.repo.IncrementViewCount(ctx, id); err != nil {
 		return fmt.Errorf("failed to increment view count: %w", err)
 	}
-	return nil
+	return u.recordTraffic(ctx, id, "view")
 }
 
+// IncrementDownloadCount counts a download, unless the client opted out of
+// analytics
 func (u *publicationUsecase) IncrementDownloadCount(ctx context.Context, id string) error {
+	if tracking.OptedOut(ctx) {
+		return nil
+	}
 	if err := u.repo.IncrementDownloadCount(ctx, id); err != nil {
 		return fmt.Errorf("failed to increment download count: %w", err)
 	}
-	return nil
+	return u.recordTraffic(ctx, id, "download")
+}
+
+// recordTraffic counts a view or download of the publication id by channel
+func (u *publicationUsecase) recordTraffic(ctx context.Context, id, metric string) error {
+	if u.traffic == nil {
+		return nil
+	}
+	return u.traffic.RecordTraffic(ctx, "publication", id, metric)
 }
 
 func (u *publicationUsecase) GetByDatasetID(ctx context.Context, datasetID string, page, limit int) (*domain.PublicationListResponse, error) {
@@ -263,6 +345,12 @@ func (u *publicationUsecase) GetByOrganizationID(ctx context.Context, orgID stri
 	}, nil
 }
 
+// purge invalidates the cached responses listing publications. View and
+// download counts are left to expire with them.
+func (u *publicationUsecase) purge(ctx context.Context) {
+	u.surrogates.Purge(ctx, domain.SurrogateKeyPublications)
+}
+
 func (u *publicationUsecase) toInfo(pub *domain.Publication) *domain.PublicationInfo {
 	return &domain.PublicationInfo{
 		ID:            pub.ID,
@@ -283,5 +371,6 @@ func (u *publicationUsecase) toInfo(pub *domain.Publication) *domain.Publication
 		CreatedBy:     pub.CreatedBy,
 		CreatedAt:     pub.CreatedAt,
 		UpdatedAt:     pub.UpdatedAt,
+		DeletedAt:     pub.DeletedAt,
 	}
 }
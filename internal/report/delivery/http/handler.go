@@ -0,0 +1,135 @@
+package http
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"portal-data-backend/infrastructure/auth"
+	"portal-data-backend/infrastructure/document"
+	"portal-data-backend/infrastructure/http/httputil"
+	"portal-data-backend/infrastructure/http/middleware"
+	"portal-data-backend/infrastructure/http/problem"
+	"portal-data-backend/infrastructure/http/response"
+	"portal-data-backend/infrastructure/logger"
+	reportDomain "portal-data-backend/internal/report/domain"
+	"portal-data-backend/internal/report/usecase"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	reportUsecase usecase.Usecase
+}
+
+func NewHandler(reportUsecase usecase.Usecase) *Handler {
+	return &Handler{
+		reportUsecase: reportUsecase,
+	}
+}
+
+// List lists past reports, the latest month first
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	req := &reportDomain.ListReportsRequest{
+		Page:  parseIntQuery(r, "page", 1),
+		Limit: parseIntQuery(r, "limit", 20),
+	}
+
+	resp, err := h.reportUsecase.List(r.Context(), req)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Reports retrieved successfully", resp)
+}
+
+// Generate queues the report of a month, generated in the background
+func (h *Handler) Generate(w http.ResponseWriter, r *http.Request) {
+	req, ok := httputil.Decode[reportDomain.GenerateReportRequest](w, r)
+	if !ok {
+		return
+	}
+
+	userID := auth.UserID(r.Context())
+
+	report, err := h.reportUsecase.Request(r.Context(), req, userID)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.JSON(w, http.StatusAccepted, response.CodeSuccess, "Report queued for generation", report)
+}
+
+func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	report, err := h.reportUsecase.GetByID(r.Context(), id)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Report retrieved successfully", report)
+}
+
+// Download streams the file of a ready report
+func (h *Handler) Download(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	report, file, err := h.reportUsecase.Download(r.Context(), id)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", document.Format(report.Format).ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, report.FileName()))
+	if report.Size != nil {
+		w.Header().Set("Content-Length", strconv.FormatInt(*report.Size, 10))
+	}
+	if _, err := io.Copy(w, file); err != nil {
+		logger.FromContext(r.Context()).Warn("failed to send report %s: %v", id, err)
+	}
+}
+
+// errorMapper maps the errors of the report module on top of the shared ones
+var errorMapper = problem.Default.With(
+	problem.Mapping{Err: pkgErrors.ErrNotFound, Status: http.StatusNotFound, Code: response.CodeNotFound, Message: "Report not found"},
+)
+
+func (h *Handler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	errorMapper.Write(w, r, err)
+}
+
+func parseIntQuery(r *http.Request, key string, defaultValue int) int {
+	if value := r.URL.Query().Get(key); value != "" {
+		if intValue, err := strconv.Atoi(value); err == nil {
+			return intValue
+		}
+	}
+	return defaultValue
+}
+
+// RegisterRoutes registers the monthly reports for users with one of
+// adminRoles
+func RegisterRoutes(r chi.Router, handler *Handler, auth func(http.Handler) http.Handler, adminRoles []string) {
+	r.Route("/admin/reports", func(r chi.Router) {
+		r.Use(auth)
+		r.Use(middleware.RequireRole(adminRoles...))
+		r.Get("/", handler.List)
+		r.Post("/", handler.Generate)
+		r.Get("/{id}", handler.GetByID)
+		r.Get("/{id}/download", handler.Download)
+	})
+}
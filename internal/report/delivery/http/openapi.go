@@ -0,0 +1,19 @@
+package http
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/document"
+	"portal-data-backend/infrastructure/http/openapi"
+	reportDomain "portal-data-backend/internal/report/domain"
+)
+
+// Describe adds the report routes to the API specification
+func Describe(spec *openapi.Spec) {
+	api := spec.Tag("reports", "Monthly open data reports")
+	api.Get("/admin/reports", "List reports").Query(reportDomain.ListReportsRequest{}).Returns(http.StatusOK, reportDomain.ReportListResponse{})
+	api.Post("/admin/reports", "Queue the report of a month").Body(reportDomain.GenerateReportRequest{}).Returns(http.StatusAccepted, reportDomain.ReportInfo{})
+	api.Get("/admin/reports/{id}", "Get report").Returns(http.StatusOK, reportDomain.ReportInfo{})
+	api.Get("/admin/reports/{id}/download", "Download report").
+		ReturnsFile(http.StatusOK, document.FormatPDF.ContentType(), document.FormatXLSX.ContentType())
+}
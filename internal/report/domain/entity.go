@@ -0,0 +1,123 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Status is how far the generation of a report got
+type Status string
+
+const (
+	StatusPending Status = "pending"
+	StatusReady   Status = "ready"
+	StatusFailed  Status = "failed"
+)
+
+// MonthLayout is the layout months of reports are written in
+const MonthLayout = "2006-01"
+
+// Report is the report of the KPIs of the portal over the month starting
+// at Period, in a format. Path is where its file is stored once it is
+// ready; KPIs keeps what it reported as JSON.
+type Report struct {
+	ID          string          `db:"id"`
+	Period      time.Time       `db:"period"`
+	Format      string          `db:"format"`
+	Status      Status          `db:"status"`
+	Path        *string         `db:"path"`
+	Size        *int64          `db:"size"`
+	KPIs        json.RawMessage `db:"kpis"`
+	Error       *string         `db:"error"`
+	RequestedBy *string         `db:"requested_by"`
+	CreatedAt   time.Time       `db:"created_at"`
+	CompletedAt *time.Time      `db:"completed_at"`
+}
+
+// KPIs are the key figures of the portal over a month
+type KPIs struct {
+	Datasets      DatasetKPIs       `json:"datasets"`
+	Downloads     DownloadKPIs      `json:"downloads"`
+	Organizations []OrganizationKPI `json:"organizations"`
+	Tickets       TicketKPIs        `json:"tickets"`
+}
+
+// DatasetKPIs counts the datasets created over the month, and all of them
+type DatasetKPIs struct {
+	New   int `db:"new" json:"new"`
+	Total int `db:"total" json:"total"`
+}
+
+// DownloadKPIs counts the downloads of every dataset. Datasets only count
+// their downloads in total, so the downloads of the month are those since
+// the previous report, nil without one.
+type DownloadKPIs struct {
+	Total         int64  `db:"total" json:"total"`
+	SincePrevious *int64 `db:"-" json:"since_previous"`
+}
+
+// OrganizationKPI is an organization ranked by the datasets it created over
+// the month, then by the downloads of its datasets
+type OrganizationKPI struct {
+	ID          string `db:"id" json:"id"`
+	Name        string `db:"name" json:"name"`
+	NewDatasets int    `db:"new_datasets" json:"new_datasets"`
+	Datasets    int    `db:"datasets" json:"datasets"`
+	Downloads   int64  `db:"downloads" json:"downloads"`
+}
+
+// TicketKPIs counts the help desk tickets opened, resolved and breaching
+// their SLA over the month, and those open when the report was generated
+type TicketKPIs struct {
+	Opened             int      `db:"opened" json:"opened"`
+	Resolved           int      `db:"resolved" json:"resolved"`
+	Breached           int      `db:"breached" json:"breached"`
+	Open               int      `db:"open" json:"open"`
+	AvgResolutionHours *float64 `db:"avg_resolution_hours" json:"avg_resolution_hours"`
+}
+
+// ReportInfo represents a report as the API answers it
+type ReportInfo struct {
+	ID          string     `json:"id"`
+	Month       string     `json:"month"`
+	Format      string     `json:"format"`
+	Status      Status     `json:"status"`
+	Size        *int64     `json:"size,omitempty"`
+	KPIs        *KPIs      `json:"kpis,omitempty"`
+	Error       *string    `json:"error,omitempty"`
+	RequestedBy *string    `json:"requested_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// FileName names the file of the report, like open-data-report-2026-09.pdf
+func (r *ReportInfo) FileName() string {
+	return "open-data-report-" + r.Month + "." + r.Format
+}
+
+// GenerateReportRequest asks for the report of a month like 2006-01, the
+// previous month when empty, in a format
+type GenerateReportRequest struct {
+	Month  string `json:"month,omitempty"`
+	Format string `json:"format" validate:"required,oneof=pdf xlsx"`
+}
+
+// ListReportsRequest represents list reports input
+type ListReportsRequest struct {
+	Page  int `json:"page" validate:"min=1"`
+	Limit int `json:"limit" validate:"min=1,max=100"`
+}
+
+// ReportListResponse represents a page of reports, the latest month first
+type ReportListResponse struct {
+	Reports []ReportInfo `json:"reports"`
+	Meta    ListMeta     `json:"meta"`
+}
+
+// ListMeta represents pagination metadata
+type ListMeta struct {
+	Page      int `json:"page"`
+	Limit     int `json:"limit"`
+	Total     int `json:"total"`
+	TotalPage int `json:"total_page"`
+}
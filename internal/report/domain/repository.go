@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Repository keeps reports and compiles the KPIs they report
+type Repository interface {
+	Create(ctx context.Context, report *Report) error
+	GetByID(ctx context.Context, id string) (*Report, error)
+	// List retrieves a page of reports, the latest month first, with their
+	// total
+	List(ctx context.Context, limit, offset int) ([]*Report, int, error)
+	// Pending retrieves the reports waiting to be generated, oldest first
+	Pending(ctx context.Context) ([]*Report, error)
+	// Exists reports whether the report of period in format is pending or
+	// ready
+	Exists(ctx context.Context, period time.Time, format string) (bool, error)
+	// Previous retrieves the latest ready report of a month before period,
+	// or ErrNotFound
+	Previous(ctx context.Context, period time.Time) (*Report, error)
+	// Complete saves the status, file, KPIs and error of a generated report
+	Complete(ctx context.Context, report *Report) error
+	// KPIs compiles the KPIs of the portal from start until end, ranking
+	// top organizations
+	KPIs(ctx context.Context, start, end time.Time, top int) (*KPIs, error)
+}
@@ -0,0 +1,76 @@
+// Package report is the module generating the monthly open data reports.
+package report
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	"portal-data-backend/infrastructure/render"
+	"portal-data-backend/infrastructure/storage"
+	"portal-data-backend/internal/app"
+	delivery "portal-data-backend/internal/report/delivery/http"
+	"portal-data-backend/internal/report/repository"
+	"portal-data-backend/internal/report/usecase"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Module compiles the KPIs of the portal into monthly PDF or XLSX reports,
+// generated in the background and stored in MinIO under reports/. Reports
+// have no uploader, so they are kept apart from the files of users. PDF
+// reports draw the charts of REPORT_VISUALIZATIONS from the rows of their
+// datasets.
+type Module struct {
+	usecase    usecase.Usecase
+	handler    *delivery.Handler
+	adminRoles []string
+}
+
+// Name implements app.Module
+func (m *Module) Name() string {
+	return "report"
+}
+
+// Register implements app.Module
+func (m *Module) Register(deps *app.Deps) error {
+	switch {
+	case deps.Services.Visualizations == nil:
+		return app.MissingServiceError("visualization")
+	case deps.Services.DataRows == nil:
+		return app.MissingServiceError("data row")
+	}
+
+	reportStorage, err := storage.NewMinIOStorage(&deps.Config.MinIO)
+	if err != nil {
+		return fmt.Errorf("failed to connect to MinIO: %w", err)
+	}
+
+	charts := usecase.Charts{
+		Visualizations: deps.Services.Visualizations,
+		Rows:           deps.Services.DataRows,
+		Renderer:       render.NewRenderer(&deps.Config.Preview),
+		Preview:        deps.Config.Preview,
+	}
+	repo := repository.NewReportPostgresRepository(deps.DB)
+	m.usecase = usecase.NewReportUsecase(repo, reportStorage, charts, deps.Config.Report)
+	m.handler = delivery.NewHandler(m.usecase)
+	m.adminRoles = deps.Config.Audit.AdminRoles
+	return nil
+}
+
+// Routes implements app.Module
+func (m *Module) Routes(r chi.Router, auth func(http.Handler) http.Handler) {
+	delivery.RegisterRoutes(r, m.handler, auth, m.adminRoles)
+}
+
+// Describe implements app.Module
+func (m *Module) Describe(spec *openapi.Spec) {
+	delivery.Describe(spec)
+}
+
+// Run implements app.Runner
+func (m *Module) Run(ctx context.Context) {
+	m.usecase.Run(ctx)
+}
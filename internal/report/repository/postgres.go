@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	reportDomain "portal-data-backend/internal/report/domain"
+
+	"portal-data-backend/infrastructure/db"
+	"portal-data-backend/pkg/errors"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type reportPostgresRepository struct {
+	db *sqlx.DB
+}
+
+func NewReportPostgresRepository(db *sqlx.DB) reportDomain.Repository {
+	return &reportPostgresRepository{db: db}
+}
+
+const reportColumns = `id, period, format, status, path, size, kpis, error, requested_by, created_at, completed_at`
+
+func (r *reportPostgresRepository) Create(ctx context.Context, report *reportDomain.Report) error {
+	query := `
+		INSERT INTO reports (id, period, format, status, requested_by, created_at)
+		VALUES (:id, :period, :format, :status, :requested_by, :created_at)
+	`
+
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, report)
+	if err != nil {
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+	return nil
+}
+
+func (r *reportPostgresRepository) GetByID(ctx context.Context, id string) (*reportDomain.Report, error) {
+	query := `SELECT ` + reportColumns + ` FROM reports WHERE id = $1`
+
+	var report reportDomain.Report
+	err := db.Conn(ctx, r.db).GetContext(ctx, &report, query, id)
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+	return &report, nil
+}
+
+func (r *reportPostgresRepository) List(ctx context.Context, limit, offset int) ([]*reportDomain.Report, int, error) {
+	var total int
+	err := db.Conn(ctx, r.db).GetContext(ctx, &total, `SELECT COUNT(*) FROM reports`)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count reports: %w", err)
+	}
+
+	query := `SELECT ` + reportColumns + ` FROM reports ORDER BY period DESC, format, created_at DESC LIMIT $1 OFFSET $2`
+
+	var reports []*reportDomain.Report
+	err = db.Conn(ctx, r.db).SelectContext(ctx, &reports, query, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list reports: %w", err)
+	}
+	return reports, total, nil
+}
+
+func (r *reportPostgresRepository) Pending(ctx context.Context) ([]*reportDomain.Report, error) {
+	query := `SELECT ` + reportColumns + ` FROM reports WHERE status = 'pending' ORDER BY created_at`
+
+	var reports []*reportDomain.Report
+	err := db.Conn(ctx, r.db).SelectContext(ctx, &reports, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending reports: %w", err)
+	}
+	return reports, nil
+}
+
+func (r *reportPostgresRepository) Exists(ctx context.Context, period time.Time, format string) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM reports WHERE period = $1 AND format = $2 AND status <> 'failed')`
+
+	var exists bool
+	err := db.Conn(ctx, r.db).GetContext(ctx, &exists, query, period, format)
+	if err != nil {
+		return false, fmt.Errorf("failed to check report: %w", err)
+	}
+	return exists, nil
+}
+
+func (r *reportPostgresRepository) Previous(ctx context.Context, period time.Time) (*reportDomain.Report, error) {
+	query := `
+		SELECT ` + reportColumns + ` FROM reports
+		WHERE status = 'ready' AND period < $1
+		ORDER BY period DESC, completed_at DESC
+		LIMIT 1
+	`
+
+	var report reportDomain.Report
+	err := db.Conn(ctx, r.db).GetContext(ctx, &report, query, period)
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+	return &report, nil
+}
+
+func (r *reportPostgresRepository) Complete(ctx context.Context, report *reportDomain.Report) error {
+	query := `
+		UPDATE reports
+		SET status = :status, path = :path, size = :size, kpis = :kpis, error = :error, completed_at = :completed_at
+		WHERE id = :id
+	`
+
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, report)
+	if err != nil {
+		return fmt.Errorf("failed to complete report: %w", err)
+	}
+	return nil
+}
+
+func (r *reportPostgresRepository) KPIs(ctx context.Context, start, end time.Time, top int) (*reportDomain.KPIs, error) {
+	var kpis reportDomain.KPIs
+
+	datasets := `
+		SELECT
+			COUNT(*) FILTER (WHERE created_at >= $1) AS new,
+			COUNT(*) AS total
+		FROM datasets
+		WHERE deleted_at IS NULL AND created_at < $2
+	`
+	err := db.Conn(ctx, r.db).GetContext(ctx, &kpis.Datasets, datasets, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count datasets: %w", err)
+	}
+
+	// Datasets count their downloads in total only
+	downloads := `SELECT COALESCE(SUM(downloads), 0) AS total FROM datasets WHERE deleted_at IS NULL`
+	err = db.Conn(ctx, r.db).GetContext(ctx, &kpis.Downloads, downloads)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count downloads: %w", err)
+	}
+
+	organizations := `
+		SELECT o.id, o.name,
+		       COUNT(d.id) FILTER (WHERE d.created_at >= $1) AS new_datasets,
+		       COUNT(d.id) AS datasets,
+		       COALESCE(SUM(d.downloads), 0) AS downloads
+		FROM organizations o
+		LEFT JOIN datasets d ON d.organization_id = o.id AND d.deleted_at IS NULL AND d.created_at < $2
+		WHERE o.deleted_at IS NULL
+		GROUP BY o.id, o.name
+		ORDER BY new_datasets DESC, downloads DESC, o.name
+		LIMIT $3
+	`
+	err = db.Conn(ctx, r.db).SelectContext(ctx, &kpis.Organizations, organizations, start, end, top)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rank organizations: %w", err)
+	}
+
+	tickets := `
+		SELECT
+			COUNT(*) FILTER (WHERE created_at >= $1 AND created_at < $2) AS opened,
+			COUNT(*) FILTER (WHERE resolved_at >= $1 AND resolved_at < $2) AS resolved,
+			COUNT(*) FILTER (WHERE sla_breached_at >= $1 AND sla_breached_at < $2) AS breached,
+			COUNT(*) FILTER (WHERE status IN ('open', 'in_progress')) AS open,
+			AVG(EXTRACT(EPOCH FROM resolved_at - created_at) / 3600)
+				FILTER (WHERE resolved_at >= $1 AND resolved_at < $2) AS avg_resolution_hours
+		FROM tickets
+		WHERE deleted_at IS NULL
+	`
+	err = db.Conn(ctx, r.db).GetContext(ctx, &kpis.Tickets, tickets, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tickets: %w", err)
+	}
+
+	return &kpis, nil
+}
+
+func (r *reportPostgresRepository) handleError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("report not found: %w", errors.ErrNotFound)
+	}
+	return fmt.Errorf("database error: %w", err)
+}
@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/infrastructure/document"
+	"portal-data-backend/infrastructure/logger"
+	"portal-data-backend/infrastructure/render"
+	dataRowDomain "portal-data-backend/internal/data_row/domain"
+	vizDomain "portal-data-backend/internal/visualization/domain"
+)
+
+// VisualizationReader is the part of the visualization module the charts of
+// reports are drawn for
+type VisualizationReader interface {
+	GetByID(ctx context.Context, id string) (*vizDomain.VisualizationInfo, error)
+}
+
+// RowSource is the part of the data row module charts are drawn from
+type RowSource interface {
+	List(ctx context.Context, req *dataRowDomain.ListDataRowsRequest) (*dataRowDomain.DataRowListResponse, error)
+}
+
+// Charts draw the visualizations of reports as charts of the rows of their
+// dataset, rendered by Renderer like the previews of Preview
+type Charts struct {
+	Visualizations VisualizationReader
+	Rows           RowSource
+	Renderer       render.Renderer
+	Preview        config.PreviewConfig
+}
+
+// chartSections returns a section with the chart of each of the configured
+// visualizations. A chart that cannot be drawn is left out of the report
+// rather than failing it.
+func (u *reportUsecase) chartSections(ctx context.Context) []document.Section {
+	if u.charts.Renderer == nil {
+		return nil
+	}
+	var sections []document.Section
+	for _, id := range u.cfg.Visualizations {
+		section, err := u.chartSection(ctx, id)
+		if err != nil {
+			logger.FromContext(ctx).Warn("report leaves out the chart of visualization %s: %v", id, err)
+			continue
+		}
+		sections = append(sections, *section)
+	}
+	return sections
+}
+
+// chartSection renders the chart of the visualization id under its title,
+// with the names of its series below it. Rows are read as an unprivileged
+// reader, so masked columns are never drawn.
+func (u *reportUsecase) chartSection(ctx context.Context, id string) (*document.Section, error) {
+	viz, err := u.charts.Visualizations.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if viz.DatasetID == nil {
+		return nil, fmt.Errorf("the visualization has no dataset")
+	}
+
+	rows, err := u.charts.Rows.List(ctx, &dataRowDomain.ListDataRowsRequest{DatasetID: *viz.DatasetID, Page: 1, Limit: u.charts.Preview.SampleRows})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list data rows: %w", err)
+	}
+	data := make([]string, len(rows.Rows))
+	for i, row := range rows.Rows {
+		data[i] = row.Data
+	}
+
+	card := &render.Card{
+		Title:  viz.Title,
+		Brand:  u.charts.Preview.Brand,
+		Color:  u.charts.Preview.Color,
+		Width:  u.charts.Preview.Width,
+		Height: u.charts.Preview.Height,
+		Format: render.FormatPNG,
+	}
+	card.Labels, card.Series = render.Chart(data, u.charts.Preview.MaxSeries)
+	if len(card.Series) == 0 {
+		return nil, fmt.Errorf("the dataset of the visualization has no numeric column")
+	}
+	image, err := u.charts.Renderer.Render(ctx, card)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render chart: %w", err)
+	}
+	if image.ContentType != "image/png" && image.ContentType != "image/jpeg" {
+		return nil, fmt.Errorf("the chart was rendered as %s rather than PNG or JPEG", image.ContentType)
+	}
+
+	section := &document.Section{Heading: viz.Title, Image: image.Data}
+	for _, series := range card.Series {
+		section.Columns = append(section.Columns, series.Name)
+	}
+	return section, nil
+}
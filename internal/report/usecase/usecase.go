@@ -0,0 +1,420 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/infrastructure/document"
+	"portal-data-backend/infrastructure/logger"
+	"portal-data-backend/internal/report/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// Storage is where the files of reports are kept
+type Storage interface {
+	Upload(ctx context.Context, fileName string, reader io.Reader, contentType string, path string) (string, error)
+	// Open returns a reader of the file at path, which the caller closes
+	Open(ctx context.Context, path string) (io.ReadCloser, error)
+}
+
+// Usecase compiles the KPIs of the portal over a month into reports,
+// generated one at a time in the background
+type Usecase interface {
+	// Request queues the report of a past month in a format
+	Request(ctx context.Context, req *domain.GenerateReportRequest, requesterID string) (*domain.ReportInfo, error)
+	GetByID(ctx context.Context, id string) (*domain.ReportInfo, error)
+	List(ctx context.Context, req *domain.ListReportsRequest) (*domain.ReportListResponse, error)
+	// Download returns the file of a ready report, which the caller closes
+	Download(ctx context.Context, id string) (*domain.ReportInfo, io.ReadCloser, error)
+	// Generate generates the pending report of id and stores its file,
+	// marking it ready, or failed with the error it returns
+	Generate(ctx context.Context, id string) error
+	// Schedule queues the reports of the previous month missing in the
+	// configured formats and returns how many it queued
+	Schedule(ctx context.Context) (int, error)
+	// Run generates queued reports, and schedules them periodically, until
+	// ctx is cancelled
+	Run(ctx context.Context)
+}
+
+type reportUsecase struct {
+	repo    domain.Repository
+	storage Storage
+	charts  Charts
+	cfg     config.ReportConfig
+	formats []document.Format
+	queue   chan string
+	now     func() time.Time
+}
+
+// NewReportUsecase creates the report usecase. PDF reports draw the charts
+// of the configured visualizations with charts.
+func NewReportUsecase(repo domain.Repository, storage Storage, charts Charts, cfg config.ReportConfig) Usecase {
+	formats := document.Formats
+	if len(cfg.Formats) > 0 {
+		formats = make([]document.Format, len(cfg.Formats))
+		for i, format := range cfg.Formats {
+			formats[i] = document.Format(format)
+		}
+	}
+	queueSize := cfg.QueueSize
+	if queueSize < 1 {
+		queueSize = 1
+	}
+	return &reportUsecase{
+		repo:    repo,
+		storage: storage,
+		charts:  charts,
+		cfg:     cfg,
+		formats: formats,
+		queue:   make(chan string, queueSize),
+		now:     time.Now,
+	}
+}
+
+func (u *reportUsecase) Request(ctx context.Context, req *domain.GenerateReportRequest, requesterID string) (*domain.ReportInfo, error) {
+	format := document.Format(req.Format)
+	if !format.Valid() {
+		return nil, fmt.Errorf("%w: format must be one of %v", pkgErrors.ErrInvalidInput, document.Formats)
+	}
+
+	current := monthOf(u.now())
+	period := current.AddDate(0, -1, 0)
+	if req.Month != "" {
+		month, err := time.Parse(domain.MonthLayout, req.Month)
+		if err != nil {
+			return nil, fmt.Errorf("%w: month must look like 2006-01", pkgErrors.ErrInvalidInput)
+		}
+		if !month.Before(current) {
+			return nil, fmt.Errorf("%w: only the reports of past months are generated", pkgErrors.ErrInvalidInput)
+		}
+		period = month
+	}
+
+	exists, err := u.repo.Exists(ctx, period, string(format))
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, fmt.Errorf("%w: the %s report of %s is already generated or pending", pkgErrors.ErrAlreadyExists, format, period.Format(domain.MonthLayout))
+	}
+
+	var requestedBy *string
+	if requesterID != "" {
+		requestedBy = &requesterID
+	}
+	report, err := u.create(ctx, period, format, requestedBy)
+	if err != nil {
+		return nil, err
+	}
+	return u.toInfo(report), nil
+}
+
+func (u *reportUsecase) GetByID(ctx context.Context, id string) (*domain.ReportInfo, error) {
+	report, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return u.toInfo(report), nil
+}
+
+func (u *reportUsecase) List(ctx context.Context, req *domain.ListReportsRequest) (*domain.ReportListResponse, error) {
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.Limit < 1 {
+		req.Limit = 20
+	}
+
+	offset := (req.Page - 1) * req.Limit
+	reports, total, err := u.repo.List(ctx, req.Limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]domain.ReportInfo, len(reports))
+	for i, report := range reports {
+		infos[i] = *u.toInfo(report)
+	}
+
+	totalPage := int(math.Ceil(float64(total) / float64(req.Limit)))
+
+	return &domain.ReportListResponse{
+		Reports: infos,
+		Meta: domain.ListMeta{
+			Page:      req.Page,
+			Limit:     req.Limit,
+			Total:     total,
+			TotalPage: totalPage,
+		},
+	}, nil
+}
+
+func (u *reportUsecase) Download(ctx context.Context, id string) (*domain.ReportInfo, io.ReadCloser, error) {
+	report, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if report.Status != domain.StatusReady || report.Path == nil {
+		return nil, nil, fmt.Errorf("%w: the report is %s", pkgErrors.ErrInvalidInput, report.Status)
+	}
+
+	file, err := u.storage.Open(ctx, *report.Path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open report: %w", err)
+	}
+	return u.toInfo(report), file, nil
+}
+
+func (u *reportUsecase) Generate(ctx context.Context, id string) error {
+	report, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if report.Status != domain.StatusPending {
+		return nil
+	}
+
+	kpis, err := u.generate(ctx, report)
+	now := u.now()
+	report.CompletedAt = &now
+	if err != nil {
+		message := err.Error()
+		report.Status = domain.StatusFailed
+		report.Error = &message
+		if completeErr := u.repo.Complete(ctx, report); completeErr != nil {
+			return completeErr
+		}
+		return err
+	}
+
+	report.Status = domain.StatusReady
+	report.KPIs, err = json.Marshal(kpis)
+	if err != nil {
+		return fmt.Errorf("failed to encode report kpis: %w", err)
+	}
+	return u.repo.Complete(ctx, report)
+}
+
+// generate compiles the KPIs of report, writes them in its format and
+// stores the file, setting the path and size of report
+func (u *reportUsecase) generate(ctx context.Context, report *domain.Report) (*domain.KPIs, error) {
+	start := monthOf(report.Period)
+	kpis, err := u.repo.KPIs(ctx, start, start.AddDate(0, 1, 0), u.cfg.TopOrganizations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile kpis: %w", err)
+	}
+
+	previous, err := u.repo.Previous(ctx, start)
+	if err != nil && !errors.Is(err, pkgErrors.ErrNotFound) {
+		return nil, fmt.Errorf("failed to get previous report: %w", err)
+	}
+	if previous != nil {
+		var before domain.KPIs
+		if err := json.Unmarshal(previous.KPIs, &before); err == nil {
+			// Downloads of deleted datasets leave the total, so it may shrink
+			since := kpis.Downloads.Total - before.Downloads.Total
+			if since < 0 {
+				since = 0
+			}
+			kpis.Downloads.SincePrevious = &since
+		}
+	}
+
+	format := document.Format(report.Format)
+	doc := u.document(start, kpis)
+	if format == document.FormatPDF {
+		doc.Sections = append(doc.Sections, u.chartSections(ctx)...)
+	}
+	var file bytes.Buffer
+	if err := document.Write(&file, doc, format); err != nil {
+		return nil, fmt.Errorf("failed to write report: %w", err)
+	}
+
+	size := int64(file.Len())
+	path := fmt.Sprintf("reports/%s.%s", report.ID, report.Format)
+	if _, err := u.storage.Upload(ctx, u.toInfo(report).FileName(), &file, format.ContentType(), path); err != nil {
+		return nil, fmt.Errorf("failed to store report: %w", err)
+	}
+	report.Path, report.Size = &path, &size
+	return kpis, nil
+}
+
+// document lays out the KPIs of the month starting at period
+func (u *reportUsecase) document(period time.Time, kpis *domain.KPIs) *document.Document {
+	summary := document.Section{
+		Heading: "Summary",
+		Columns: []string{"Indicator", "Value"},
+		Rows: [][]string{
+			{"New datasets", strconv.Itoa(kpis.Datasets.New)},
+			{"Datasets", strconv.Itoa(kpis.Datasets.Total)},
+			{"Downloads since the previous report", optionalInt(kpis.Downloads.SincePrevious)},
+			{"Downloads", strconv.FormatInt(kpis.Downloads.Total, 10)},
+		},
+	}
+
+	organizations := document.Section{
+		Heading: "Top organizations",
+		Columns: []string{"Rank", "Organization", "New datasets", "Datasets", "Downloads"},
+	}
+	for i, organization := range kpis.Organizations {
+		organizations.Rows = append(organizations.Rows, []string{
+			strconv.Itoa(i + 1),
+			organization.Name,
+			strconv.Itoa(organization.NewDatasets),
+			strconv.Itoa(organization.Datasets),
+			strconv.FormatInt(organization.Downloads, 10),
+		})
+	}
+
+	resolution := "-"
+	if kpis.Tickets.AvgResolutionHours != nil {
+		resolution = strconv.FormatFloat(*kpis.Tickets.AvgResolutionHours, 'f', 1, 64)
+	}
+	tickets := document.Section{
+		Heading: "Help desk",
+		Columns: []string{"Indicator", "Value"},
+		Rows: [][]string{
+			{"Tickets opened", strconv.Itoa(kpis.Tickets.Opened)},
+			{"Tickets resolved", strconv.Itoa(kpis.Tickets.Resolved)},
+			{"SLA breaches", strconv.Itoa(kpis.Tickets.Breached)},
+			{"Open tickets", strconv.Itoa(kpis.Tickets.Open)},
+			{"Average resolution (hours)", resolution},
+		},
+	}
+
+	return &document.Document{
+		Title:    "Open Data Report, " + period.Format("January 2006"),
+		Subtitle: "Generated " + u.now().UTC().Format("2006-01-02 15:04 MST"),
+		Sections: []document.Section{summary, organizations, tickets},
+	}
+}
+
+func (u *reportUsecase) Schedule(ctx context.Context) (int, error) {
+	period := monthOf(u.now()).AddDate(0, -1, 0)
+
+	queued := 0
+	for _, format := range u.formats {
+		exists, err := u.repo.Exists(ctx, period, string(format))
+		if err != nil {
+			return queued, err
+		}
+		if exists {
+			continue
+		}
+		if _, err := u.create(ctx, period, format, nil); err != nil {
+			return queued, err
+		}
+		queued++
+	}
+	return queued, nil
+}
+
+func (u *reportUsecase) Run(ctx context.Context) {
+	// Reports left pending by a restart, or by a full queue, are queued
+	// again on every check
+	check := func() {
+		pending, err := u.repo.Pending(ctx)
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to list pending reports: %v", err)
+		}
+		for _, report := range pending {
+			u.enqueue(ctx, report.ID)
+		}
+		if u.cfg.CheckInterval > 0 {
+			if _, err := u.Schedule(ctx); err != nil {
+				logger.FromContext(ctx).Error("report schedule failed: %v", err)
+			}
+		}
+	}
+	check()
+
+	var tick <-chan time.Time
+	if u.cfg.CheckInterval > 0 {
+		ticker := time.NewTicker(u.cfg.CheckInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-u.queue:
+			if err := u.Generate(ctx, id); err != nil {
+				logger.FromContext(ctx).Error("failed to generate report %s: %v", id, err)
+			}
+		case <-tick:
+			check()
+		}
+	}
+}
+
+// create saves a pending report of period in format and queues it
+func (u *reportUsecase) create(ctx context.Context, period time.Time, format document.Format, requestedBy *string) (*domain.Report, error) {
+	report := &domain.Report{
+		ID:          uuid.New().String(),
+		Period:      period,
+		Format:      string(format),
+		Status:      domain.StatusPending,
+		RequestedBy: requestedBy,
+		CreatedAt:   u.now(),
+	}
+	if err := u.repo.Create(ctx, report); err != nil {
+		return nil, err
+	}
+	u.enqueue(ctx, report.ID)
+	return report, nil
+}
+
+func (u *reportUsecase) enqueue(ctx context.Context, id string) {
+	select {
+	case u.queue <- id:
+	default:
+		logger.FromContext(ctx).Warn("report queue is full, report %s waits for the next check", id)
+	}
+}
+
+func (u *reportUsecase) toInfo(report *domain.Report) *domain.ReportInfo {
+	info := &domain.ReportInfo{
+		ID:          report.ID,
+		Month:       report.Period.Format(domain.MonthLayout),
+		Format:      report.Format,
+		Status:      report.Status,
+		Size:        report.Size,
+		Error:       report.Error,
+		RequestedBy: report.RequestedBy,
+		CreatedAt:   report.CreatedAt,
+		CompletedAt: report.CompletedAt,
+	}
+	if report.Status == domain.StatusReady {
+		var kpis domain.KPIs
+		if err := json.Unmarshal(report.KPIs, &kpis); err == nil {
+			info.KPIs = &kpis
+		}
+	}
+	return info
+}
+
+// monthOf returns the first day of the month of t, in UTC
+func monthOf(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func optionalInt(value *int64) string {
+	if value == nil {
+		return "-"
+	}
+	return strconv.FormatInt(*value, 10)
+}
@@ -0,0 +1,339 @@
+package usecase_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/infrastructure/render"
+	dataRowDomain "portal-data-backend/internal/data_row/domain"
+	"portal-data-backend/internal/report/domain"
+	"portal-data-backend/internal/report/usecase"
+	vizDomain "portal-data-backend/internal/visualization/domain"
+	pkgerrors "portal-data-backend/pkg/errors"
+)
+
+// mockRepository is an in-memory implementation of Repository reporting
+// fixed KPIs
+type mockRepository struct {
+	reports map[string]*domain.Report
+	kpis    domain.KPIs
+}
+
+func newMockRepository() *mockRepository {
+	return &mockRepository{
+		reports: map[string]*domain.Report{},
+		kpis: domain.KPIs{
+			Datasets:      domain.DatasetKPIs{New: 3, Total: 40},
+			Downloads:     domain.DownloadKPIs{Total: 1500},
+			Organizations: []domain.OrganizationKPI{{ID: "org", Name: "Dinas Kesehatan", NewDatasets: 3, Datasets: 12, Downloads: 900}},
+			Tickets:       domain.TicketKPIs{Opened: 5, Resolved: 4, Open: 2},
+		},
+	}
+}
+
+func (m *mockRepository) Create(ctx context.Context, report *domain.Report) error {
+	copied := *report
+	m.reports[report.ID] = &copied
+	return nil
+}
+
+func (m *mockRepository) GetByID(ctx context.Context, id string) (*domain.Report, error) {
+	report, ok := m.reports[id]
+	if !ok {
+		return nil, pkgerrors.ErrNotFound
+	}
+	copied := *report
+	return &copied, nil
+}
+
+func (m *mockRepository) List(ctx context.Context, limit, offset int) ([]*domain.Report, int, error) {
+	var reports []*domain.Report
+	for _, report := range m.reports {
+		reports = append(reports, report)
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].Period.After(reports[j].Period) })
+	return reports, len(reports), nil
+}
+
+func (m *mockRepository) Pending(ctx context.Context) ([]*domain.Report, error) {
+	var reports []*domain.Report
+	for _, report := range m.reports {
+		if report.Status == domain.StatusPending {
+			reports = append(reports, report)
+		}
+	}
+	return reports, nil
+}
+
+func (m *mockRepository) Exists(ctx context.Context, period time.Time, format string) (bool, error) {
+	for _, report := range m.reports {
+		if report.Period.Equal(period) && report.Format == format && report.Status != domain.StatusFailed {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *mockRepository) Previous(ctx context.Context, period time.Time) (*domain.Report, error) {
+	var previous *domain.Report
+	for _, report := range m.reports {
+		if report.Status == domain.StatusReady && report.Period.Before(period) && (previous == nil || report.Period.After(previous.Period)) {
+			previous = report
+		}
+	}
+	if previous == nil {
+		return nil, pkgerrors.ErrNotFound
+	}
+	return previous, nil
+}
+
+func (m *mockRepository) Complete(ctx context.Context, report *domain.Report) error {
+	copied := *report
+	m.reports[report.ID] = &copied
+	return nil
+}
+
+func (m *mockRepository) KPIs(ctx context.Context, start, end time.Time, top int) (*domain.KPIs, error) {
+	kpis := m.kpis
+	return &kpis, nil
+}
+
+// mockStorage keeps uploaded files in memory, failing uploads when err is
+// set
+type mockStorage struct {
+	files map[string][]byte
+	err   error
+}
+
+func (m *mockStorage) Upload(ctx context.Context, fileName string, reader io.Reader, contentType string, path string) (string, error) {
+	if m.err != nil {
+		return "", m.err
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+	m.files[path] = data
+	return path, nil
+}
+
+func (m *mockStorage) Open(ctx context.Context, path string) (io.ReadCloser, error) {
+	data, ok := m.files[path]
+	if !ok {
+		return nil, pkgerrors.ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func newReportUsecase(repo *mockRepository, storage *mockStorage) usecase.Usecase {
+	return usecase.NewReportUsecase(repo, storage, usecase.Charts{}, config.ReportConfig{TopOrganizations: 10, QueueSize: 10})
+}
+
+// previousMonth is the month before the current one, like 2006-01
+func previousMonth() string {
+	now := time.Now().UTC()
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0).Format(domain.MonthLayout)
+}
+
+// Test reports are requested for past months only, once per format
+func TestReport_Request(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	reports := newReportUsecase(repo, &mockStorage{files: map[string][]byte{}})
+
+	report, err := reports.Request(ctx, &domain.GenerateReportRequest{Format: "pdf"}, "admin")
+	if err != nil {
+		t.Fatalf("Failed to request report: %v", err)
+	}
+	if report.Month != previousMonth() || report.Status != domain.StatusPending {
+		t.Errorf("Expected a pending report of %s, got %s %s", previousMonth(), report.Status, report.Month)
+	}
+
+	if _, err := reports.Request(ctx, &domain.GenerateReportRequest{Month: previousMonth(), Format: "pdf"}, "admin"); !errors.Is(err, pkgerrors.ErrAlreadyExists) {
+		t.Errorf("Expected a second pdf report of the month to be refused, got %v", err)
+	}
+	if _, err := reports.Request(ctx, &domain.GenerateReportRequest{Month: previousMonth(), Format: "xlsx"}, "admin"); err != nil {
+		t.Errorf("Expected the xlsx report of the month to be queued, got %v", err)
+	}
+
+	current := time.Now().UTC().Format(domain.MonthLayout)
+	for _, req := range []domain.GenerateReportRequest{
+		{Month: current, Format: "pdf"},
+		{Month: "last month", Format: "pdf"},
+		{Format: "docx"},
+	} {
+		if _, err := reports.Request(ctx, &req, "admin"); !errors.Is(err, pkgerrors.ErrInvalidInput) {
+			t.Errorf("Expected %+v to be refused, got %v", req, err)
+		}
+	}
+}
+
+// Test generated reports are stored with their KPIs, counting downloads
+// since the previous report
+func TestReport_Generate(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	storage := &mockStorage{files: map[string][]byte{}}
+	reports := newReportUsecase(repo, storage)
+
+	before, _ := time.Parse(domain.MonthLayout, previousMonth())
+	kpis, _ := json.Marshal(domain.KPIs{Downloads: domain.DownloadKPIs{Total: 1200}})
+	repo.reports["previous"] = &domain.Report{ID: "previous", Period: before.AddDate(0, -1, 0), Format: "pdf", Status: domain.StatusReady, KPIs: kpis}
+
+	for _, format := range []string{"pdf", "xlsx"} {
+		report, err := reports.Request(ctx, &domain.GenerateReportRequest{Format: format}, "admin")
+		if err != nil {
+			t.Fatalf("Failed to request %s report: %v", format, err)
+		}
+		if err := reports.Generate(ctx, report.ID); err != nil {
+			t.Fatalf("Failed to generate %s report: %v", format, err)
+		}
+
+		ready, err := reports.GetByID(ctx, report.ID)
+		if err != nil {
+			t.Fatalf("Failed to get report: %v", err)
+		}
+		if ready.Status != domain.StatusReady || ready.KPIs == nil {
+			t.Fatalf("Expected the %s report ready with its KPIs, got %s", format, ready.Status)
+		}
+		if since := ready.KPIs.Downloads.SincePrevious; since == nil || *since != 300 {
+			t.Errorf("Expected 300 downloads since the previous report, got %v", since)
+		}
+
+		_, file, err := reports.Download(ctx, report.ID)
+		if err != nil {
+			t.Fatalf("Failed to download %s report: %v", format, err)
+		}
+		data, _ := io.ReadAll(file)
+		file.Close()
+		if int64(len(data)) != *ready.Size {
+			t.Errorf("Expected a file of %d bytes, got %d", *ready.Size, len(data))
+		}
+	}
+}
+
+// Test reports that cannot be stored are marked failed and can be
+// requested again
+func TestReport_GenerateFails(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	storage := &mockStorage{files: map[string][]byte{}, err: errors.New("bucket unreachable")}
+	reports := newReportUsecase(repo, storage)
+
+	report, err := reports.Request(ctx, &domain.GenerateReportRequest{Format: "pdf"}, "admin")
+	if err != nil {
+		t.Fatalf("Failed to request report: %v", err)
+	}
+	if err := reports.Generate(ctx, report.ID); err == nil {
+		t.Fatal("Expected generation to fail")
+	}
+
+	failed, _ := reports.GetByID(ctx, report.ID)
+	if failed.Status != domain.StatusFailed || failed.Error == nil {
+		t.Errorf("Expected the report failed with its error, got %s", failed.Status)
+	}
+	if _, _, err := reports.Download(ctx, report.ID); !errors.Is(err, pkgerrors.ErrInvalidInput) {
+		t.Errorf("Expected a failed report not to be downloadable, got %v", err)
+	}
+	if _, err := reports.Request(ctx, &domain.GenerateReportRequest{Format: "pdf"}, "admin"); err != nil {
+		t.Errorf("Expected a failed report to be requested again, got %v", err)
+	}
+}
+
+// Test the reports of the previous month are scheduled once in every format
+func TestReport_Schedule(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	reports := newReportUsecase(repo, &mockStorage{files: map[string][]byte{}})
+
+	queued, err := reports.Schedule(ctx)
+	if err != nil {
+		t.Fatalf("Failed to schedule reports: %v", err)
+	}
+	if queued != 2 {
+		t.Errorf("Expected the pdf and xlsx reports queued, got %d", queued)
+	}
+
+	queued, err = reports.Schedule(ctx)
+	if err != nil {
+		t.Fatalf("Failed to schedule reports: %v", err)
+	}
+	if queued != 0 {
+		t.Errorf("Expected no report queued again, got %d", queued)
+	}
+}
+
+// mockVisualizations serves visualizations of datasets from memory
+type mockVisualizations map[string]*vizDomain.VisualizationInfo
+
+func (m mockVisualizations) GetByID(ctx context.Context, id string) (*vizDomain.VisualizationInfo, error) {
+	viz, ok := m[id]
+	if !ok {
+		return nil, pkgerrors.ErrNotFound
+	}
+	return viz, nil
+}
+
+// mockRows serves the rows of datasets from memory
+type mockRows map[string][]string
+
+func (m mockRows) List(ctx context.Context, req *dataRowDomain.ListDataRowsRequest) (*dataRowDomain.DataRowListResponse, error) {
+	resp := &dataRowDomain.DataRowListResponse{}
+	for _, data := range m[req.DatasetID] {
+		resp.Rows = append(resp.Rows, dataRowDomain.DataRowInfo{DatasetID: req.DatasetID, Data: data})
+	}
+	return resp, nil
+}
+
+// Test PDF reports draw the charts of the configured visualizations that
+// can be drawn, and XLSX reports draw none
+func TestReport_GenerateCharts(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	storage := &mockStorage{files: map[string][]byte{}}
+	rainfall, names := "dataset-1", "dataset-2"
+	charts := usecase.Charts{
+		Visualizations: mockVisualizations{
+			"viz-1": {ID: "viz-1", Title: "Rainfall", DatasetID: &rainfall},
+			"viz-2": {ID: "viz-2", Title: "Names", DatasetID: &names},
+		},
+		Rows: mockRows{
+			rainfall: {`{"month": "Jan", "mm": 12}`, `{"month": "Feb", "mm": 30}`},
+			names:    {`{"name": "Ani"}`},
+		},
+		Renderer: render.NewRenderer(&config.PreviewConfig{}),
+		Preview:  config.PreviewConfig{Width: 600, Height: 300, SampleRows: 10, MaxSeries: 3, Color: "#1d4ed8"},
+	}
+	reports := usecase.NewReportUsecase(repo, storage, charts, config.ReportConfig{
+		TopOrganizations: 10,
+		QueueSize:        10,
+		Visualizations:   []string{"viz-1", "viz-2", "viz-missing"},
+	})
+
+	for format, images := range map[string]int{"pdf": 1, "xlsx": 0} {
+		report, err := reports.Request(ctx, &domain.GenerateReportRequest{Format: format}, "admin")
+		if err != nil {
+			t.Fatalf("Failed to request %s report: %v", format, err)
+		}
+		if err := reports.Generate(ctx, report.ID); err != nil {
+			t.Fatalf("Failed to generate %s report: %v", format, err)
+		}
+		_, file, err := reports.Download(ctx, report.ID)
+		if err != nil {
+			t.Fatalf("Failed to download %s report: %v", format, err)
+		}
+		data, _ := io.ReadAll(file)
+		file.Close()
+		if drawn := strings.Count(string(data), "/Subtype /Image"); drawn != images {
+			t.Errorf("Expected %d charts in the %s report, got %d", images, format, drawn)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"portal-data-backend/infrastructure/auth"
+	"portal-data-backend/infrastructure/http/bulk"
+	"portal-data-backend/infrastructure/http/httputil"
+	"portal-data-backend/infrastructure/http/middleware"
+	"portal-data-backend/infrastructure/http/problem"
+	"portal-data-backend/infrastructure/http/response"
+	reviewDomain "portal-data-backend/internal/review/domain"
+	"portal-data-backend/internal/review/usecase"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	reviewUsecase usecase.Usecase
+}
+
+func NewHandler(reviewUsecase usecase.Usecase) *Handler {
+	return &Handler{
+		reviewUsecase: reviewUsecase,
+	}
+}
+
+// List lists the items waiting for review with the workload of every
+// assignee
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	req := &reviewDomain.ListReviewsRequest{
+		Page:  parseIntQuery(r, "page", 1),
+		Limit: parseIntQuery(r, "limit", 20),
+	}
+
+	if contentType := r.URL.Query().Get("content_type"); contentType != "" {
+		req.ContentType = &contentType
+	}
+	if assignedTo := r.URL.Query().Get("assigned_to"); assignedTo != "" {
+		req.AssignedTo = &assignedTo
+	}
+	if organizationID := r.URL.Query().Get("organization_id"); organizationID != "" {
+		req.OrganizationID = &organizationID
+	}
+	if slaState := r.URL.Query().Get("sla_state"); slaState != "" {
+		req.SLAState = &slaState
+	}
+
+	resp, err := h.reviewUsecase.List(r.Context(), req)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Reviews retrieved successfully", resp)
+}
+
+// Assign assigns an item to a reviewer, or unassigns it
+func (h *Handler) Assign(w http.ResponseWriter, r *http.Request) {
+	req, ok := httputil.Decode[reviewDomain.AssignRequest](w, r)
+	if !ok {
+		return
+	}
+
+	userID := auth.UserID(r.Context())
+
+	review, err := h.reviewUsecase.Assign(r.Context(), chi.URLParam(r, "key"), req, userID)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Review assigned successfully", review)
+}
+
+// BulkApprove approves several items of trusted organizations in one
+// transaction, answering the outcome for each
+func (h *Handler) BulkApprove(w http.ResponseWriter, r *http.Request) {
+	req, ok := httputil.Decode[bulk.Request](w, r)
+	if !ok {
+		return
+	}
+
+	userID := auth.UserID(r.Context())
+
+	failed, err := h.reviewUsecase.BulkApprove(r.Context(), req.IDs, userID)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	bulk.Write(w, r, errorMapper, req.IDs, failed, "items approved")
+}
+
+// errorMapper maps the errors of the review module on top of the shared ones
+var errorMapper = problem.Default.With(
+	problem.Mapping{Err: pkgErrors.ErrNotFound, Status: http.StatusNotFound, Code: response.CodeNotFound, Message: "Item not waiting for review"},
+)
+
+func (h *Handler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	errorMapper.Write(w, r, err)
+}
+
+func parseIntQuery(r *http.Request, key string, defaultValue int) int {
+	if value := r.URL.Query().Get(key); value != "" {
+		if intValue, err := strconv.Atoi(value); err == nil {
+			return intValue
+		}
+	}
+	return defaultValue
+}
+
+// RegisterRoutes registers the review dashboard for users with one of
+// adminRoles
+func RegisterRoutes(r chi.Router, handler *Handler, auth func(http.Handler) http.Handler, adminRoles []string) {
+	r.Route("/admin/reviews", func(r chi.Router) {
+		r.Use(auth)
+		r.Use(middleware.RequireRole(adminRoles...))
+		r.Get("/", handler.List)
+		r.Put("/{key}/assignee", handler.Assign)
+		r.Post("/approve", handler.BulkApprove)
+	})
+}
@@ -0,0 +1,17 @@
+package http
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/bulk"
+	"portal-data-backend/infrastructure/http/openapi"
+	reviewDomain "portal-data-backend/internal/review/domain"
+)
+
+// Describe adds the review routes to the API specification
+func Describe(spec *openapi.Spec) {
+	api := spec.Tag("reviews", "Datasets, visualizations and publications waiting for review")
+	api.Get("/admin/reviews", "List items waiting for review").Query(reviewDomain.ListReviewsRequest{}).Returns(http.StatusOK, reviewDomain.ReviewListResponse{})
+	api.Put("/admin/reviews/{key}/assignee", "Assign item to reviewer").Body(reviewDomain.AssignRequest{}).Returns(http.StatusOK, reviewDomain.Review{})
+	api.Post("/admin/reviews/approve", "Approve several items of trusted organizations").Body(bulk.Request{}).Returns(http.StatusOK, bulk.Response{})
+}
@@ -0,0 +1,95 @@
+package domain
+
+import "time"
+
+// ContentType is a kind of content reviewers approve
+type ContentType string
+
+const (
+	ContentTypeDataset       ContentType = "dataset"
+	ContentTypeVisualization ContentType = "visualization"
+	ContentTypePublication   ContentType = "publication"
+)
+
+// ContentTypes lists the kinds of content waiting for review
+var ContentTypes = []ContentType{ContentTypeDataset, ContentTypeVisualization, ContentTypePublication}
+
+// SLAState tells how close the review of an item is to being overdue
+type SLAState string
+
+const (
+	SLAStateOnTrack  SLAState = "on_track"
+	SLAStateAtRisk   SLAState = "at_risk"
+	SLAStateBreached SLAState = "breached"
+)
+
+// Item is content waiting for review: a dataset pending validation, or a
+// visualization or publication still in draft. It waits since it was
+// created and is due once the SLA of its content type passed.
+type Item struct {
+	ContentType    ContentType `db:"content_type" json:"content_type"`
+	ContentID      string      `db:"content_id" json:"content_id"`
+	Title          string      `db:"title" json:"title"`
+	OrganizationID *string     `db:"organization_id" json:"organization_id,omitempty"`
+	AssignedTo     *string     `db:"assigned_to" json:"assigned_to,omitempty"`
+	AssignedAt     *time.Time  `db:"assigned_at" json:"assigned_at,omitempty"`
+	WaitingSince   time.Time   `db:"waiting_since" json:"waiting_since"`
+	DueAt          time.Time   `db:"due_at" json:"due_at"`
+}
+
+// Key identifies the item across content types, like "dataset:<id>"
+func (i *Item) Key() string {
+	return string(i.ContentType) + ":" + i.ContentID
+}
+
+// Review is an item waiting for review as the dashboard shows it. Trusted
+// items belong to an organization whose content admins approve in bulk.
+type Review struct {
+	Key string `json:"key"`
+	Item
+	AgeSeconds int64    `json:"age_seconds"`
+	SLAState   SLAState `json:"sla_state"`
+	Trusted    bool     `json:"trusted"`
+}
+
+// Workload counts the items waiting for an assignee, or for no one when
+// AssignedTo is nil
+type Workload struct {
+	AssignedTo *string `db:"assigned_to" json:"assigned_to"`
+	Pending    int     `db:"pending" json:"pending"`
+	AtRisk     int     `db:"at_risk" json:"at_risk"`
+	Breached   int     `db:"breached" json:"breached"`
+}
+
+// ListReviewsRequest represents list reviews input. AssignedTo "none" lists
+// the unassigned items.
+type ListReviewsRequest struct {
+	Page           int     `json:"page" validate:"min=1"`
+	Limit          int     `json:"limit" validate:"min=1,max=100"`
+	ContentType    *string `json:"content_type,omitempty"`
+	AssignedTo     *string `json:"assigned_to,omitempty"`
+	OrganizationID *string `json:"organization_id,omitempty"`
+	SLAState       *string `json:"sla_state,omitempty"`
+}
+
+// ReviewListResponse represents a page of the items waiting for review, the
+// soonest due first, with the workload of every assignee
+type ReviewListResponse struct {
+	Reviews  []Review   `json:"reviews"`
+	Workload []Workload `json:"workload"`
+	Meta     ListMeta   `json:"meta"`
+}
+
+// ListMeta represents pagination metadata
+type ListMeta struct {
+	Page      int `json:"page"`
+	Limit     int `json:"limit"`
+	Total     int `json:"total"`
+	TotalPage int `json:"total_page"`
+}
+
+// AssignRequest represents the reassignment of an item; a null AssignedTo
+// leaves it unassigned
+type AssignRequest struct {
+	AssignedTo *string `json:"assigned_to" validate:"omitempty,uuid"`
+}
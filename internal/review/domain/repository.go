@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Repository reads the items waiting for review from the tables of their
+// content types, and keeps who is assigned to them
+type Repository interface {
+	// List retrieves a page of the items matching filter, the soonest due
+	// first, with their total
+	List(ctx context.Context, filter *Filter, limit, offset int) ([]*Item, int, error)
+	// Get retrieves the item of a content type and ID, or ErrNotFound when it
+	// is not waiting for review
+	Get(ctx context.Context, sla map[ContentType]time.Duration, contentType ContentType, contentID string) (*Item, error)
+	// Workload counts the items waiting for each assignee: all of them, those
+	// due by atRisk and those due by now
+	Workload(ctx context.Context, sla map[ContentType]time.Duration, atRisk, now time.Time) ([]Workload, error)
+	// Assign assigns an item to the user assignedTo, replacing its assignee
+	Assign(ctx context.Context, contentType ContentType, contentID, assignedTo, assignedBy string) error
+	// Unassign removes the assignee of an item, if it has one
+	Unassign(ctx context.Context, contentType ContentType, contentID string) error
+}
+
+// Filter narrows the items waiting for review. SLA maps each content type
+// to how long after WaitingSince its items are due.
+type Filter struct {
+	SLA            map[ContentType]time.Duration
+	ContentType    *string
+	AssignedTo     *string
+	Unassigned     bool
+	OrganizationID *string
+	// DueAfter and DueBefore bound the due time, excluding DueAfter
+	DueAfter  *time.Time
+	DueBefore *time.Time
+}
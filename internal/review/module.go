@@ -0,0 +1,59 @@
+// Package review is the module following the review of the datasets,
+// visualizations and publications waiting for one.
+package review
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	"portal-data-backend/internal/app"
+	delivery "portal-data-backend/internal/review/delivery/http"
+	"portal-data-backend/internal/review/repository"
+	"portal-data-backend/internal/review/usecase"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Module lets admins assign the items waiting for review, follow them
+// against their SLA and approve those of trusted organizations in bulk
+type Module struct {
+	handler    *delivery.Handler
+	adminRoles []string
+}
+
+// Name implements app.Module
+func (m *Module) Name() string {
+	return "review"
+}
+
+// Register implements app.Module
+func (m *Module) Register(deps *app.Deps) error {
+	if deps.Services.Datasets == nil {
+		return app.MissingServiceError("dataset")
+	}
+	if deps.Services.Visualizations == nil {
+		return app.MissingServiceError("visualization")
+	}
+	if deps.Services.Publications == nil {
+		return app.MissingServiceError("publication")
+	}
+	if deps.Services.Users == nil {
+		return app.MissingServiceError("user")
+	}
+
+	repo := repository.NewReviewPostgresRepository(deps.DB)
+	reviews := usecase.NewReviewUsecase(repo, deps.Tx, deps.Services.Datasets, deps.Services.Visualizations, deps.Services.Publications, deps.Services.Users, deps.Services.Audit, deps.Config.Review)
+	m.handler = delivery.NewHandler(reviews)
+	m.adminRoles = deps.Config.Audit.AdminRoles
+	return nil
+}
+
+// Routes implements app.Module
+func (m *Module) Routes(r chi.Router, auth func(http.Handler) http.Handler) {
+	delivery.RegisterRoutes(r, m.handler, auth, m.adminRoles)
+}
+
+// Describe implements app.Module
+func (m *Module) Describe(spec *openapi.Spec) {
+	delivery.Describe(spec)
+}
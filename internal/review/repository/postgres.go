@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	reviewDomain "portal-data-backend/internal/review/domain"
+
+	"portal-data-backend/infrastructure/db"
+	"portal-data-backend/pkg/errors"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type reviewPostgresRepository struct {
+	db *sqlx.DB
+}
+
+func NewReviewPostgresRepository(db *sqlx.DB) reviewDomain.Repository {
+	return &reviewPostgresRepository{db: db}
+}
+
+// reviews is the query of the items waiting for review with their assignee
+// and due time, taking the SLA seconds of datasets, visualizations and
+// publications as $1, $2 and $3
+func reviews(ctx context.Context) string {
+	return `
+		WITH pending AS (
+			SELECT 'dataset' AS content_type, d.id AS content_id, d.name AS title,
+			       d.organization_id, d.created_at AS waiting_since
+			FROM datasets d
+			WHERE d.validation_status = 'pending' AND d.deleted_at IS NULL AND ` + db.InTenantOrganizations(ctx, "d.organization_id") + `
+			UNION ALL
+			SELECT 'visualization', v.id, v.title, v.organization_id, v.created_at
+			FROM visualizations v
+			WHERE v.status = 'draft' AND v.deleted_at IS NULL AND ` + db.InTenantOrganizations(ctx, "v.organization_id") + `
+			UNION ALL
+			SELECT 'publication', p.id, p.title, p.organization_id, p.created_at
+			FROM publications p
+			WHERE p.status = 'draft' AND p.deleted_at IS NULL AND ` + db.InTenantOrganizations(ctx, "p.organization_id") + `
+		), reviews AS (
+			SELECT pending.*, a.assigned_to, a.assigned_at,
+			       pending.waiting_since + make_interval(secs => CASE pending.content_type
+			           WHEN 'dataset' THEN $1::float8 WHEN 'visualization' THEN $2::float8 ELSE $3::float8 END) AS due_at
+			FROM pending
+			LEFT JOIN review_assignments a ON a.content_type = pending.content_type AND a.content_id = pending.content_id
+		)`
+}
+
+// slaArgs are the arguments of reviews for sla
+func slaArgs(sla map[reviewDomain.ContentType]time.Duration) []interface{} {
+	return []interface{}{
+		sla[reviewDomain.ContentTypeDataset].Seconds(),
+		sla[reviewDomain.ContentTypeVisualization].Seconds(),
+		sla[reviewDomain.ContentTypePublication].Seconds(),
+	}
+}
+
+const reviewColumns = `content_type, content_id, title, organization_id, assigned_to, assigned_at, waiting_since, due_at`
+
+func (r *reviewPostgresRepository) List(ctx context.Context, filter *reviewDomain.Filter, limit, offset int) ([]*reviewDomain.Item, int, error) {
+	whereClause := "WHERE TRUE"
+	args := slaArgs(filter.SLA)
+	argCount := len(args) + 1
+
+	if filter.ContentType != nil {
+		whereClause += fmt.Sprintf(" AND content_type = $%d", argCount)
+		args = append(args, filter.ContentType)
+		argCount++
+	}
+	if filter.AssignedTo != nil {
+		whereClause += fmt.Sprintf(" AND assigned_to = $%d", argCount)
+		args = append(args, filter.AssignedTo)
+		argCount++
+	}
+	if filter.Unassigned {
+		whereClause += " AND assigned_to IS NULL"
+	}
+	if filter.OrganizationID != nil {
+		whereClause += fmt.Sprintf(" AND organization_id = $%d", argCount)
+		args = append(args, filter.OrganizationID)
+		argCount++
+	}
+	if filter.DueAfter != nil {
+		whereClause += fmt.Sprintf(" AND due_at > $%d", argCount)
+		args = append(args, filter.DueAfter)
+		argCount++
+	}
+	if filter.DueBefore != nil {
+		whereClause += fmt.Sprintf(" AND due_at <= $%d", argCount)
+		args = append(args, filter.DueBefore)
+		argCount++
+	}
+
+	countQuery := reviews(ctx) + " SELECT COUNT(*) FROM reviews " + whereClause
+	var total int
+	err := db.Conn(ctx, r.db).GetContext(ctx, &total, countQuery, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count reviews: %w", err)
+	}
+
+	query := reviews(ctx) + " SELECT " + reviewColumns + " FROM reviews " + whereClause +
+		fmt.Sprintf(" ORDER BY due_at, content_type, content_id LIMIT $%d OFFSET $%d", argCount, argCount+1)
+	args = append(args, limit, offset)
+
+	var items []*reviewDomain.Item
+	err = db.Conn(ctx, r.db).SelectContext(ctx, &items, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list reviews: %w", err)
+	}
+
+	return items, total, nil
+}
+
+func (r *reviewPostgresRepository) Get(ctx context.Context, sla map[reviewDomain.ContentType]time.Duration, contentType reviewDomain.ContentType, contentID string) (*reviewDomain.Item, error) {
+	query := reviews(ctx) + " SELECT " + reviewColumns + " FROM reviews WHERE content_type = $4 AND content_id = $5"
+
+	var item reviewDomain.Item
+	err := db.Conn(ctx, r.db).GetContext(ctx, &item, query, append(slaArgs(sla), contentType, contentID)...)
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+	return &item, nil
+}
+
+func (r *reviewPostgresRepository) Workload(ctx context.Context, sla map[reviewDomain.ContentType]time.Duration, atRisk, now time.Time) ([]reviewDomain.Workload, error) {
+	query := reviews(ctx) + `
+		SELECT assigned_to, COUNT(*) AS pending,
+		       COUNT(*) FILTER (WHERE due_at > $5 AND due_at <= $4) AS at_risk,
+		       COUNT(*) FILTER (WHERE due_at <= $5) AS breached
+		FROM reviews
+		GROUP BY assigned_to
+		ORDER BY pending DESC, assigned_to NULLS FIRST`
+
+	var workload []reviewDomain.Workload
+	err := db.Conn(ctx, r.db).SelectContext(ctx, &workload, query, append(slaArgs(sla), atRisk, now)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count workload: %w", err)
+	}
+	return workload, nil
+}
+
+func (r *reviewPostgresRepository) Assign(ctx context.Context, contentType reviewDomain.ContentType, contentID, assignedTo, assignedBy string) error {
+	query := `
+		INSERT INTO review_assignments (content_type, content_id, assigned_to, assigned_by, assigned_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (content_type, content_id) DO UPDATE
+		SET assigned_to = EXCLUDED.assigned_to, assigned_by = EXCLUDED.assigned_by, assigned_at = EXCLUDED.assigned_at
+	`
+
+	_, err := db.Conn(ctx, r.db).ExecContext(ctx, query, contentType, contentID, assignedTo, assignedBy)
+	if err != nil {
+		return fmt.Errorf("failed to assign review: %w", err)
+	}
+	return nil
+}
+
+func (r *reviewPostgresRepository) Unassign(ctx context.Context, contentType reviewDomain.ContentType, contentID string) error {
+	query := `DELETE FROM review_assignments WHERE content_type = $1 AND content_id = $2`
+
+	_, err := db.Conn(ctx, r.db).ExecContext(ctx, query, contentType, contentID)
+	if err != nil {
+		return fmt.Errorf("failed to unassign review: %w", err)
+	}
+	return nil
+}
+
+func (r *reviewPostgresRepository) handleError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("review not found: %w", errors.ErrNotFound)
+	}
+	return fmt.Errorf("database error: %w", err)
+}
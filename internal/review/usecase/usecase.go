@@ -0,0 +1,290 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"portal-data-backend/infrastructure/audit"
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/infrastructure/db"
+	datasetDomain "portal-data-backend/internal/dataset/domain"
+	"portal-data-backend/internal/review/domain"
+	userDomain "portal-data-backend/internal/user/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// DatasetValidator is the part of the dataset module datasets are approved
+// through
+type DatasetValidator interface {
+	Validate(ctx context.Context, id string, status datasetDomain.ValidationStatus, updaterID string) (*datasetDomain.DatasetResponse, error)
+}
+
+// StatusUpdater is the part of the visualization and publication modules
+// their drafts are published through
+type StatusUpdater interface {
+	UpdateStatus(ctx context.Context, id string, status string) error
+}
+
+// UserReader is the part of the user module assignees are checked through
+type UserReader interface {
+	GetUserByID(ctx context.Context, id string) (*userDomain.UserInfo, error)
+}
+
+// Usecase lets admins follow the review of the datasets, visualizations
+// and publications waiting for one. Items are identified by keys like
+// "dataset:<id>".
+type Usecase interface {
+	// List lists the items waiting for review, the soonest due first, with
+	// the workload of every assignee
+	List(ctx context.Context, req *domain.ListReviewsRequest) (*domain.ReviewListResponse, error)
+	// Assign assigns the item of key to a reviewer, or unassigns it
+	Assign(ctx context.Context, key string, req *domain.AssignRequest, assignerID string) (*domain.Review, error)
+	// BulkApprove approves the items of keys belonging to trusted
+	// organizations: datasets are validated and drafts published. It
+	// returns the errors of the keys it could not approve.
+	BulkApprove(ctx context.Context, keys []string, approverID string) (map[string]error, error)
+}
+
+type reviewUsecase struct {
+	repo           domain.Repository
+	tx             db.Transactor
+	datasets       DatasetValidator
+	visualizations StatusUpdater
+	publications   StatusUpdater
+	users          UserReader
+	audit          *audit.Recorder
+	sla            map[domain.ContentType]time.Duration
+	warning        time.Duration
+	trusted        map[string]bool
+	now            func() time.Time
+}
+
+// NewReviewUsecase creates the review usecase. Assignments are audited
+// through recorder, which may be nil.
+func NewReviewUsecase(repo domain.Repository, tx db.Transactor, datasets DatasetValidator, visualizations, publications StatusUpdater, users UserReader, recorder *audit.Recorder, cfg config.ReviewConfig) Usecase {
+	sla := make(map[domain.ContentType]time.Duration, len(domain.ContentTypes))
+	for _, contentType := range domain.ContentTypes {
+		sla[contentType] = cfg.SLA[string(contentType)]
+	}
+	trusted := make(map[string]bool, len(cfg.TrustedOrganizations))
+	for _, id := range cfg.TrustedOrganizations {
+		trusted[id] = true
+	}
+	return &reviewUsecase{
+		repo:           repo,
+		tx:             tx,
+		datasets:       datasets,
+		visualizations: visualizations,
+		publications:   publications,
+		users:          users,
+		audit:          recorder,
+		sla:            sla,
+		warning:        cfg.Warning,
+		trusted:        trusted,
+		now:            time.Now,
+	}
+}
+
+func (u *reviewUsecase) List(ctx context.Context, req *domain.ListReviewsRequest) (*domain.ReviewListResponse, error) {
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.Limit < 1 {
+		req.Limit = 20
+	}
+
+	offset := (req.Page - 1) * req.Limit
+	now := u.now()
+	atRisk := now.Add(u.warning)
+
+	filter := &domain.Filter{
+		SLA:            u.sla,
+		OrganizationID: req.OrganizationID,
+	}
+	if req.ContentType != nil {
+		if _, err := parseContentType(*req.ContentType); err != nil {
+			return nil, err
+		}
+		filter.ContentType = req.ContentType
+	}
+	if req.AssignedTo != nil {
+		if *req.AssignedTo == "none" {
+			filter.Unassigned = true
+		} else {
+			filter.AssignedTo = req.AssignedTo
+		}
+	}
+	if req.SLAState != nil {
+		switch domain.SLAState(*req.SLAState) {
+		case domain.SLAStateOnTrack:
+			filter.DueAfter = &atRisk
+		case domain.SLAStateAtRisk:
+			filter.DueAfter, filter.DueBefore = &now, &atRisk
+		case domain.SLAStateBreached:
+			filter.DueBefore = &now
+		default:
+			return nil, fmt.Errorf("%w: sla_state must be on_track, at_risk or breached", pkgErrors.ErrInvalidInput)
+		}
+	}
+
+	items, total, err := u.repo.List(ctx, filter, req.Limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reviews: %w", err)
+	}
+	workload, err := u.repo.Workload(ctx, u.sla, atRisk, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count workload: %w", err)
+	}
+
+	reviews := make([]domain.Review, len(items))
+	for i, item := range items {
+		reviews[i] = *u.toReview(item, now)
+	}
+	if workload == nil {
+		workload = []domain.Workload{}
+	}
+
+	totalPage := int(math.Ceil(float64(total) / float64(req.Limit)))
+
+	return &domain.ReviewListResponse{
+		Reviews:  reviews,
+		Workload: workload,
+		Meta: domain.ListMeta{
+			Page:      req.Page,
+			Limit:     req.Limit,
+			Total:     total,
+			TotalPage: totalPage,
+		},
+	}, nil
+}
+
+func (u *reviewUsecase) Assign(ctx context.Context, key string, req *domain.AssignRequest, assignerID string) (*domain.Review, error) {
+	contentType, contentID, err := parseKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if req.AssignedTo != nil {
+		if _, err := u.users.GetUserByID(ctx, *req.AssignedTo); err != nil {
+			if errors.Is(err, pkgErrors.ErrNotFound) {
+				return nil, fmt.Errorf("%w: user %s does not exist", pkgErrors.ErrInvalidInput, *req.AssignedTo)
+			}
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+	}
+
+	var assigned *domain.Item
+	err = u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		item, err := u.repo.Get(ctx, u.sla, contentType, contentID)
+		if err != nil {
+			return err
+		}
+
+		if req.AssignedTo == nil {
+			err = u.repo.Unassign(ctx, contentType, contentID)
+		} else {
+			err = u.repo.Assign(ctx, contentType, contentID, *req.AssignedTo, assignerID)
+		}
+		if err != nil {
+			return err
+		}
+
+		assigned, err = u.repo.Get(ctx, u.sla, contentType, contentID)
+		if err != nil {
+			return err
+		}
+		u.audit.Record(ctx, "review_assignments", key, audit.ActionUpdate, item, assigned)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return u.toReview(assigned, u.now()), nil
+}
+
+func (u *reviewUsecase) BulkApprove(ctx context.Context, keys []string, approverID string) (map[string]error, error) {
+	return db.Each(ctx, u.tx, keys, func(ctx context.Context, key string) error {
+		return u.approve(ctx, key, approverID)
+	})
+}
+
+// approve approves the item of key, if it waits for review and belongs to
+// a trusted organization, and drops its assignment
+func (u *reviewUsecase) approve(ctx context.Context, key, approverID string) error {
+	contentType, contentID, err := parseKey(key)
+	if err != nil {
+		return err
+	}
+	item, err := u.repo.Get(ctx, u.sla, contentType, contentID)
+	if err != nil {
+		return err
+	}
+	if !u.isTrusted(item) {
+		return fmt.Errorf("%w: only the items of trusted organizations are approved in bulk", pkgErrors.ErrForbidden)
+	}
+
+	switch contentType {
+	case domain.ContentTypeDataset:
+		_, err = u.datasets.Validate(ctx, contentID, datasetDomain.ValidationStatusValid, approverID)
+	case domain.ContentTypeVisualization:
+		err = u.visualizations.UpdateStatus(ctx, contentID, "published")
+	case domain.ContentTypePublication:
+		err = u.publications.UpdateStatus(ctx, contentID, "published")
+	}
+	if err != nil {
+		return err
+	}
+	return u.repo.Unassign(ctx, contentType, contentID)
+}
+
+func (u *reviewUsecase) isTrusted(item *domain.Item) bool {
+	return item.OrganizationID != nil && u.trusted[*item.OrganizationID]
+}
+
+// toReview shows item as of now
+func (u *reviewUsecase) toReview(item *domain.Item, now time.Time) *domain.Review {
+	state := domain.SLAStateOnTrack
+	switch {
+	case !item.DueAt.After(now):
+		state = domain.SLAStateBreached
+	case !item.DueAt.After(now.Add(u.warning)):
+		state = domain.SLAStateAtRisk
+	}
+	return &domain.Review{
+		Key:        item.Key(),
+		Item:       *item,
+		AgeSeconds: int64(now.Sub(item.WaitingSince).Seconds()),
+		SLAState:   state,
+		Trusted:    u.isTrusted(item),
+	}
+}
+
+// parseKey reads the content type and ID of a key like "dataset:<id>"
+func parseKey(key string) (domain.ContentType, string, error) {
+	value, id, ok := strings.Cut(key, ":")
+	if !ok {
+		return "", "", fmt.Errorf("%w: review keys look like dataset:<id>", pkgErrors.ErrInvalidInput)
+	}
+	contentType, err := parseContentType(value)
+	if err != nil {
+		return "", "", err
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return "", "", fmt.Errorf("%w: %s is not a valid ID", pkgErrors.ErrInvalidInput, id)
+	}
+	return contentType, id, nil
+}
+
+func parseContentType(value string) (domain.ContentType, error) {
+	for _, contentType := range domain.ContentTypes {
+		if value == string(contentType) {
+			return contentType, nil
+		}
+	}
+	return "", fmt.Errorf("%w: content type must be one of %v", pkgErrors.ErrInvalidInput, domain.ContentTypes)
+}
@@ -0,0 +1,237 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+	datasetDomain "portal-data-backend/internal/dataset/domain"
+	"portal-data-backend/internal/review/domain"
+	"portal-data-backend/internal/review/usecase"
+	userDomain "portal-data-backend/internal/user/domain"
+	pkgerrors "portal-data-backend/pkg/errors"
+)
+
+const (
+	trustedOrg   = "11111111-1111-1111-1111-111111111111"
+	untrustedOrg = "22222222-2222-2222-2222-222222222222"
+	reviewer     = "33333333-3333-3333-3333-333333333333"
+	datasetID    = "44444444-4444-4444-4444-444444444444"
+	chartID      = "55555555-5555-5555-5555-555555555555"
+	reportID     = "66666666-6666-6666-6666-666666666666"
+)
+
+// mockRepository is an in-memory implementation of Repository
+type mockRepository struct {
+	items map[string]*domain.Item
+}
+
+func newMockRepository() *mockRepository {
+	return &mockRepository{items: map[string]*domain.Item{}}
+}
+
+// add adds an item of orgID waiting for review since waitingSince
+func (m *mockRepository) add(contentType domain.ContentType, id, orgID string, waitingSince time.Time) {
+	item := &domain.Item{ContentType: contentType, ContentID: id, OrganizationID: &orgID, WaitingSince: waitingSince}
+	m.items[item.Key()] = item
+}
+
+func (m *mockRepository) due(item *domain.Item, sla map[domain.ContentType]time.Duration) *domain.Item {
+	copied := *item
+	copied.DueAt = item.WaitingSince.Add(sla[item.ContentType])
+	return &copied
+}
+
+func (m *mockRepository) List(ctx context.Context, filter *domain.Filter, limit, offset int) ([]*domain.Item, int, error) {
+	var items []*domain.Item
+	for _, item := range m.items {
+		item = m.due(item, filter.SLA)
+		if filter.DueAfter != nil && !item.DueAt.After(*filter.DueAfter) {
+			continue
+		}
+		if filter.DueBefore != nil && item.DueAt.After(*filter.DueBefore) {
+			continue
+		}
+		if filter.Unassigned && item.AssignedTo != nil {
+			continue
+		}
+		items = append(items, item)
+	}
+	return items, len(items), nil
+}
+
+func (m *mockRepository) Get(ctx context.Context, sla map[domain.ContentType]time.Duration, contentType domain.ContentType, contentID string) (*domain.Item, error) {
+	item, ok := m.items[string(contentType)+":"+contentID]
+	if !ok {
+		return nil, pkgerrors.ErrNotFound
+	}
+	return m.due(item, sla), nil
+}
+
+func (m *mockRepository) Workload(ctx context.Context, sla map[domain.ContentType]time.Duration, atRisk, now time.Time) ([]domain.Workload, error) {
+	return nil, nil
+}
+
+func (m *mockRepository) Assign(ctx context.Context, contentType domain.ContentType, contentID, assignedTo, assignedBy string) error {
+	m.items[string(contentType)+":"+contentID].AssignedTo = &assignedTo
+	return nil
+}
+
+func (m *mockRepository) Unassign(ctx context.Context, contentType domain.ContentType, contentID string) error {
+	if item, ok := m.items[string(contentType)+":"+contentID]; ok {
+		item.AssignedTo = nil
+	}
+	return nil
+}
+
+type mockTransactor struct{}
+
+func (mockTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// mockContent approves items by taking them off the repository
+type mockContent struct {
+	repo        *mockRepository
+	contentType domain.ContentType
+}
+
+func (m *mockContent) Validate(ctx context.Context, id string, status datasetDomain.ValidationStatus, updaterID string) (*datasetDomain.DatasetResponse, error) {
+	delete(m.repo.items, string(m.contentType)+":"+id)
+	return &datasetDomain.DatasetResponse{}, nil
+}
+
+func (m *mockContent) UpdateStatus(ctx context.Context, id string, status string) error {
+	delete(m.repo.items, string(m.contentType)+":"+id)
+	return nil
+}
+
+// mockUsers knows the reviewer only
+type mockUsers struct{}
+
+func (mockUsers) GetUserByID(ctx context.Context, id string) (*userDomain.UserInfo, error) {
+	if id != reviewer {
+		return nil, pkgerrors.ErrNotFound
+	}
+	return &userDomain.UserInfo{ID: id}, nil
+}
+
+func newReviewUsecase(repo *mockRepository) usecase.Usecase {
+	cfg := config.ReviewConfig{
+		SLA:                  map[string]time.Duration{"dataset": 72 * time.Hour, "visualization": 48 * time.Hour, "publication": 48 * time.Hour},
+		Warning:              24 * time.Hour,
+		TrustedOrganizations: []string{trustedOrg},
+	}
+	return usecase.NewReviewUsecase(repo, mockTransactor{}, &mockContent{repo, domain.ContentTypeDataset},
+		&mockContent{repo, domain.ContentTypeVisualization}, &mockContent{repo, domain.ContentTypePublication}, mockUsers{}, nil, cfg)
+}
+
+// Test items are on track, at risk or breached by how long they waited
+// against the SLA of their content type
+func TestReview_SLAState(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	reviews := newReviewUsecase(repo)
+	now := time.Now()
+
+	repo.add(domain.ContentTypeDataset, datasetID, trustedOrg, now.Add(-10*time.Hour))
+	repo.add(domain.ContentTypeVisualization, chartID, trustedOrg, now.Add(-30*time.Hour))
+	repo.add(domain.ContentTypePublication, reportID, untrustedOrg, now.Add(-50*time.Hour))
+
+	resp, err := reviews.List(ctx, &domain.ListReviewsRequest{})
+	if err != nil {
+		t.Fatalf("Failed to list reviews: %v", err)
+	}
+	want := map[string]domain.SLAState{
+		"dataset:" + datasetID:     domain.SLAStateOnTrack,
+		"visualization:" + chartID: domain.SLAStateAtRisk,
+		"publication:" + reportID:  domain.SLAStateBreached,
+	}
+	for _, review := range resp.Reviews {
+		if review.SLAState != want[review.Key] {
+			t.Errorf("Expected %s %s, got %s", review.Key, want[review.Key], review.SLAState)
+		}
+		if review.Trusted != (*review.OrganizationID == trustedOrg) {
+			t.Errorf("Expected %s trusted only for the trusted organization", review.Key)
+		}
+	}
+
+	breached := string(domain.SLAStateBreached)
+	resp, err = reviews.List(ctx, &domain.ListReviewsRequest{SLAState: &breached})
+	if err != nil {
+		t.Fatalf("Failed to list breached reviews: %v", err)
+	}
+	if len(resp.Reviews) != 1 || resp.Reviews[0].Key != "publication:"+reportID {
+		t.Errorf("Expected the publication breached only, got %+v", resp.Reviews)
+	}
+}
+
+// Test items are assigned to existing users only
+func TestReview_Assign(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	reviews := newReviewUsecase(repo)
+	repo.add(domain.ContentTypeDataset, datasetID, trustedOrg, time.Now())
+
+	assignee := reviewer
+	review, err := reviews.Assign(ctx, "dataset:"+datasetID, &domain.AssignRequest{AssignedTo: &assignee}, "admin")
+	if err != nil {
+		t.Fatalf("Failed to assign review: %v", err)
+	}
+	if review.AssignedTo == nil || *review.AssignedTo != reviewer {
+		t.Errorf("Expected the dataset assigned to the reviewer, got %v", review.AssignedTo)
+	}
+
+	unknown := trustedOrg
+	if _, err := reviews.Assign(ctx, "dataset:"+datasetID, &domain.AssignRequest{AssignedTo: &unknown}, "admin"); !errors.Is(err, pkgerrors.ErrInvalidInput) {
+		t.Errorf("Expected an unknown assignee to be refused, got %v", err)
+	}
+	if _, err := reviews.Assign(ctx, "chart:"+chartID, &domain.AssignRequest{}, "admin"); !errors.Is(err, pkgerrors.ErrInvalidInput) {
+		t.Errorf("Expected an unknown content type to be refused, got %v", err)
+	}
+
+	review, err = reviews.Assign(ctx, "dataset:"+datasetID, &domain.AssignRequest{}, "admin")
+	if err != nil {
+		t.Fatalf("Failed to unassign review: %v", err)
+	}
+	if review.AssignedTo != nil {
+		t.Errorf("Expected the dataset unassigned, got %v", *review.AssignedTo)
+	}
+}
+
+// Test only the items of trusted organizations waiting for review are
+// approved in bulk
+func TestReview_BulkApprove(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	reviews := newReviewUsecase(repo)
+	now := time.Now()
+
+	repo.add(domain.ContentTypeDataset, datasetID, trustedOrg, now)
+	repo.add(domain.ContentTypeVisualization, chartID, trustedOrg, now)
+	repo.add(domain.ContentTypePublication, reportID, untrustedOrg, now)
+
+	failed, err := reviews.BulkApprove(ctx, []string{
+		"dataset:" + datasetID,
+		"visualization:" + chartID,
+		"publication:" + reportID,
+		"publication:" + datasetID,
+	}, "admin")
+	if err != nil {
+		t.Fatalf("Failed to approve reviews: %v", err)
+	}
+	if len(failed) != 2 {
+		t.Fatalf("Expected 2 items left unapproved, got %v", failed)
+	}
+	if !errors.Is(failed["publication:"+reportID], pkgerrors.ErrForbidden) {
+		t.Errorf("Expected the untrusted publication refused, got %v", failed["publication:"+reportID])
+	}
+	if !errors.Is(failed["publication:"+datasetID], pkgerrors.ErrNotFound) {
+		t.Errorf("Expected an item not waiting for review not found, got %v", failed["publication:"+datasetID])
+	}
+	if len(repo.items) != 1 {
+		t.Errorf("Expected the trusted items approved, %d items left", len(repo.items))
+	}
+}
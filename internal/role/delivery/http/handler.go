@@ -0,0 +1,153 @@
+package http
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/httputil"
+	"portal-data-backend/infrastructure/http/middleware"
+	"portal-data-backend/infrastructure/http/problem"
+	"portal-data-backend/infrastructure/http/response"
+	roleDomain "portal-data-backend/internal/role/domain"
+	"portal-data-backend/internal/role/usecase"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	roleUsecase usecase.Usecase
+}
+
+func NewHandler(roleUsecase usecase.Usecase) *Handler {
+	return &Handler{
+		roleUsecase: roleUsecase,
+	}
+}
+
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	roles, err := h.roleUsecase.List(r.Context())
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Roles retrieved successfully", roles)
+}
+
+func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	role, err := h.roleUsecase.GetByID(r.Context(), id)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Role retrieved successfully", role)
+}
+
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	req, ok := httputil.Decode[roleDomain.CreateRoleRequest](w, r)
+	if !ok {
+		return
+	}
+
+	role, err := h.roleUsecase.Create(r.Context(), req)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.Created(w, response.CodeCreated, "Role created successfully", role)
+}
+
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	req, ok := httputil.Decode[roleDomain.UpdateRoleRequest](w, r)
+	if !ok {
+		return
+	}
+
+	role, err := h.roleUsecase.Update(r.Context(), id, req)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Role updated successfully", role)
+}
+
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.roleUsecase.Delete(r.Context(), id); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Role deleted successfully", nil)
+}
+
+func (h *Handler) SetPermissions(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	req, ok := httputil.Decode[roleDomain.SetPermissionsRequest](w, r)
+	if !ok {
+		return
+	}
+
+	role, err := h.roleUsecase.SetPermissions(r.Context(), id, req)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Role permissions updated successfully", role)
+}
+
+func (h *Handler) Catalog(w http.ResponseWriter, r *http.Request) {
+	response.OK(w, response.CodeSuccess, "Permissions retrieved successfully", h.roleUsecase.Catalog())
+}
+
+// errorMapper maps the errors of the role module on top of the shared ones
+var errorMapper = problem.Default.With(
+	problem.Mapping{Err: pkgErrors.ErrNotFound, Status: http.StatusNotFound, Code: response.CodeNotFound, Message: "Role not found"},
+)
+
+func (h *Handler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	errorMapper.Write(w, r, err)
+}
+
+// RegisterRoutes registers role routes. Signed-in users may read roles and
+// the permission catalog; managing roles requires the roles:write
+// permission.
+func RegisterRoutes(r chi.Router, handler *Handler, auth func(http.Handler) http.Handler) {
+	r.With(auth).Get("/admin/permissions", handler.Catalog)
+
+	r.Route("/admin/roles", func(r chi.Router) {
+		r.Use(auth)
+		r.Get("/", handler.List)
+		r.Get("/{id}", handler.GetByID)
+
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RequirePermission(roleDomain.PermissionRolesWrite))
+			r.Post("/", handler.Create)
+			r.Put("/{id}", handler.Update)
+			r.Delete("/{id}", handler.Delete)
+			r.Put("/{id}/permissions", handler.SetPermissions)
+		})
+	})
+}
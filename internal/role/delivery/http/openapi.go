@@ -0,0 +1,20 @@
+package http
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	roleDomain "portal-data-backend/internal/role/domain"
+)
+
+// Describe adds the role routes to the API specification
+func Describe(spec *openapi.Spec) {
+	api := spec.Tag("roles", "Roles of users and the permissions they grant")
+	api.Get("/admin/permissions", "List the permissions roles may grant").Returns(http.StatusOK, []roleDomain.Permission{})
+	api.Get("/admin/roles", "List roles").Returns(http.StatusOK, []roleDomain.RoleResponse{})
+	api.Post("/admin/roles", "Create role").Body(roleDomain.CreateRoleRequest{}).Returns(http.StatusCreated, roleDomain.RoleResponse{})
+	api.Get("/admin/roles/{id}", "Get role").Returns(http.StatusOK, roleDomain.RoleResponse{})
+	api.Put("/admin/roles/{id}", "Update role").Body(roleDomain.UpdateRoleRequest{}).Returns(http.StatusOK, roleDomain.RoleResponse{})
+	api.Delete("/admin/roles/{id}", "Delete role").Returns(http.StatusOK, nil)
+	api.Put("/admin/roles/{id}/permissions", "Set the permissions of a role").Body(roleDomain.SetPermissionsRequest{}).Returns(http.StatusOK, roleDomain.RoleResponse{})
+}
@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"time"
+)
+
+// Permissions granted by roles. Routes changing records of a kind require
+// the write permission of that kind.
+const (
+	PermissionDatasetsWrite       = "datasets:write"
+	PermissionDataRowsWrite       = "data_rows:write"
+	PermissionFilesWrite          = "files:write"
+	PermissionOrganizationsWrite  = "organizations:write"
+	PermissionVisualizationsWrite = "visualizations:write"
+	PermissionPublicationsWrite   = "publications:write"
+	PermissionTaxonomiesWrite     = "taxonomies:write"
+	PermissionIntegrationsWrite   = "integrations:write"
+	PermissionUsersWrite          = "users:write"
+	PermissionRolesWrite          = "roles:write"
+	PermissionFeedbackWrite       = "feedback:write"
+	PermissionSettingsWrite       = "settings:write"
+	PermissionTicketsWrite        = "tickets:write"
+	// PermissionOrganizationSettingsWrite lets org admins manage the settings
+	// of their own organization
+	PermissionOrganizationSettingsWrite = "organization_settings:write"
+)
+
+// Permission describes a permission roles may grant
+type Permission struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Catalog lists every permission roles may grant
+var Catalog = []Permission{
+	{Name: PermissionDatasetsWrite, Description: "Create, update, delete and change the status of datasets"},
+	{Name: PermissionDataRowsWrite, Description: "Add, update and delete the data rows of datasets and mask their columns"},
+	{Name: PermissionFilesWrite, Description: "Upload, delete and change the status of files"},
+	{Name: PermissionOrganizationsWrite, Description: "Create, update, brand and delete organizations"},
+	{Name: PermissionVisualizationsWrite, Description: "Create, update, delete and change the status of visualizations"},
+	{Name: PermissionPublicationsWrite, Description: "Create, update, delete and change the status of publications"},
+	{Name: PermissionTaxonomiesWrite, Description: "Manage tags, units, business fields and topics"},
+	{Name: PermissionIntegrationsWrite, Description: "Manage integrations, their secrets, subscriptions and runs"},
+	{Name: PermissionUsersWrite, Description: "Update, delete and change the status of users"},
+	{Name: PermissionRolesWrite, Description: "Manage roles and the permissions they grant"},
+	{Name: PermissionFeedbackWrite, Description: "Reply to, resolve, delete and change the status of feedback and publish it as Q&A"},
+	{Name: PermissionSettingsWrite, Description: "Create, update and delete global and user settings, including the public configuration"},
+	{Name: PermissionTicketsWrite, Description: "Update, delete, assign, reply to and change the status of help desk tickets"},
+	{Name: PermissionOrganizationSettingsWrite, Description: "Create, update and delete the settings of the user's own organization"},
+}
+
+// IsPermission reports whether name is a permission of the catalog
+func IsPermission(name string) bool {
+	for _, permission := range Catalog {
+		if permission.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Role represents a role users are assigned, granting permissions
+type Role struct {
+	ID          string    `db:"id" json:"id"`
+	Name        string    `db:"name" json:"name"`
+	Description string    `db:"description" json:"description"`
+	Permissions []string  `db:"-" json:"permissions"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// CreateRoleRequest represents role creation input
+type CreateRoleRequest struct {
+	Name        string   `json:"name" validate:"required,min=2,max=100"`
+	Description string   `json:"description,omitempty" validate:"max=500"`
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+// UpdateRoleRequest represents role update input
+type UpdateRoleRequest struct {
+	Name        string `json:"name" validate:"required,min=2,max=100"`
+	Description string `json:"description,omitempty" validate:"max=500"`
+}
+
+// SetPermissionsRequest replaces the permissions a role grants
+type SetPermissionsRequest struct {
+	Permissions []string `json:"permissions"`
+}
+
+// RoleResponse represents role response
+type RoleResponse struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
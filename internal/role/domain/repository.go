@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"context"
+)
+
+// Repository defines the interface for role data operations
+type Repository interface {
+	// GetByID retrieves a role with its permissions
+	GetByID(ctx context.Context, id string) (*Role, error)
+	// List retrieves every role with its permissions, by name
+	List(ctx context.Context) ([]*Role, error)
+	// Create stores a role with its permissions
+	Create(ctx context.Context, role *Role) error
+	Update(ctx context.Context, role *Role) error
+	Delete(ctx context.Context, id string) error
+	// Permissions retrieves the permissions of the role id, none for an
+	// unknown role
+	Permissions(ctx context.Context, id string) ([]string, error)
+	// SetPermissions replaces the permissions of the role id
+	SetPermissions(ctx context.Context, id string, permissions []string) error
+	// CountUsers counts the users assigned the role
+	CountUsers(ctx context.Context, id string) (int, error)
+}
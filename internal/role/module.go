@@ -0,0 +1,44 @@
+// Package role is the module managing the roles users are assigned and the
+// permissions they grant.
+package role
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	"portal-data-backend/internal/app"
+	delivery "portal-data-backend/internal/role/delivery/http"
+	"portal-data-backend/internal/role/repository"
+	"portal-data-backend/internal/role/usecase"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Module manages roles and their permissions
+type Module struct {
+	handler *delivery.Handler
+}
+
+// Name implements app.Module
+func (m *Module) Name() string {
+	return "role"
+}
+
+// Register implements app.Module
+func (m *Module) Register(deps *app.Deps) error {
+	repo := repository.NewRolePostgresRepository(deps.DBRouter)
+	roles := usecase.NewRoleUsecase(repo, deps.Cache.Namespace("roles", deps.Config.Cache.RoleTTL), deps.Services.Audit)
+	deps.Services.Roles = roles
+	m.handler = delivery.NewHandler(roles)
+	return nil
+}
+
+// Routes implements app.Module
+func (m *Module) Routes(r chi.Router, auth func(http.Handler) http.Handler) {
+	delivery.RegisterRoutes(r, m.handler, auth)
+}
+
+// Describe implements app.Module
+func (m *Module) Describe(spec *openapi.Spec) {
+	delivery.Describe(spec)
+}
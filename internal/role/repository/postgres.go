@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"portal-data-backend/infrastructure/db"
+	"portal-data-backend/internal/role/domain"
+	"portal-data-backend/pkg/errors"
+)
+
+type rolePostgresRepository struct {
+	db *db.Router
+}
+
+func NewRolePostgresRepository(router *db.Router) domain.Repository {
+	return &rolePostgresRepository{db: router}
+}
+
+func (r *rolePostgresRepository) GetByID(ctx context.Context, id string) (*domain.Role, error) {
+	query := `SELECT id, name, description, created_at, updated_at FROM roles WHERE id = $1`
+	var role domain.Role
+	if err := r.db.Write(ctx).GetContext(ctx, &role, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+
+	permissions, err := r.Permissions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	role.Permissions = permissions
+	return &role, nil
+}
+
+func (r *rolePostgresRepository) List(ctx context.Context) ([]*domain.Role, error) {
+	conn := r.db.Read(ctx)
+
+	roles := []*domain.Role{}
+	query := `SELECT id, name, description, created_at, updated_at FROM roles ORDER BY name ASC`
+	if err := conn.SelectContext(ctx, &roles, query); err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+
+	var grants []struct {
+		RoleID     string `db:"role_id"`
+		Permission string `db:"permission"`
+	}
+	query = `SELECT role_id, permission FROM role_permissions ORDER BY permission ASC`
+	if err := conn.SelectContext(ctx, &grants, query); err != nil {
+		return nil, fmt.Errorf("failed to list role permissions: %w", err)
+	}
+
+	byID := make(map[string]*domain.Role, len(roles))
+	for _, role := range roles {
+		role.Permissions = []string{}
+		byID[role.ID] = role
+	}
+	for _, grant := range grants {
+		if role, ok := byID[grant.RoleID]; ok {
+			role.Permissions = append(role.Permissions, grant.Permission)
+		}
+	}
+	return roles, nil
+}
+
+func (r *rolePostgresRepository) Create(ctx context.Context, role *domain.Role) error {
+	return r.db.WithinTx(ctx, func(ctx context.Context) error {
+		query := `
+			INSERT INTO roles (id, name, description, created_at, updated_at)
+			VALUES (:id, :name, :description, :created_at, :updated_at)
+			ON CONFLICT (name) DO NOTHING
+		`
+		result, err := r.db.Write(ctx).NamedExecContext(ctx, query, role)
+		if err != nil {
+			return fmt.Errorf("failed to create role: %w", err)
+		}
+		if rows, _ := result.RowsAffected(); rows == 0 {
+			return fmt.Errorf("%w: a role named %q exists already", errors.ErrAlreadyExists, role.Name)
+		}
+		return r.SetPermissions(ctx, role.ID, role.Permissions)
+	})
+}
+
+func (r *rolePostgresRepository) Update(ctx context.Context, role *domain.Role) error {
+	return r.db.WithinTx(ctx, func(ctx context.Context) error {
+		tx := r.db.Write(ctx)
+
+		var taken bool
+		if err := tx.GetContext(ctx, &taken, `SELECT EXISTS (SELECT 1 FROM roles WHERE name = $1 AND id <> $2)`, role.Name, role.ID); err != nil {
+			return fmt.Errorf("failed to update role: %w", err)
+		}
+		if taken {
+			return fmt.Errorf("%w: a role named %q exists already", errors.ErrAlreadyExists, role.Name)
+		}
+
+		query := `UPDATE roles SET name = :name, description = :description, updated_at = :updated_at WHERE id = :id`
+		result, err := tx.NamedExecContext(ctx, query, role)
+		if err != nil {
+			return fmt.Errorf("failed to update role: %w", err)
+		}
+		if rows, _ := result.RowsAffected(); rows == 0 {
+			return errors.ErrNotFound
+		}
+		return nil
+	})
+}
+
+func (r *rolePostgresRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.Write(ctx).ExecContext(ctx, `DELETE FROM roles WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete role: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+func (r *rolePostgresRepository) Permissions(ctx context.Context, id string) ([]string, error) {
+	permissions := []string{}
+	query := `SELECT permission FROM role_permissions WHERE role_id = $1 ORDER BY permission ASC`
+	if err := r.db.Write(ctx).SelectContext(ctx, &permissions, query, id); err != nil {
+		return nil, fmt.Errorf("failed to get role permissions: %w", err)
+	}
+	return permissions, nil
+}
+
+func (r *rolePostgresRepository) SetPermissions(ctx context.Context, id string, permissions []string) error {
+	return r.db.WithinTx(ctx, func(ctx context.Context) error {
+		tx := r.db.Write(ctx)
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM role_permissions WHERE role_id = $1`, id); err != nil {
+			return fmt.Errorf("failed to clear role permissions: %w", err)
+		}
+		if len(permissions) == 0 {
+			return nil
+		}
+
+		grants := make([]map[string]interface{}, len(permissions))
+		for i, permission := range permissions {
+			grants[i] = map[string]interface{}{"role_id": id, "permission": permission}
+		}
+		query := `INSERT INTO role_permissions (role_id, permission) VALUES (:role_id, :permission) ON CONFLICT DO NOTHING`
+		if _, err := tx.NamedExecContext(ctx, query, grants); err != nil {
+			return fmt.Errorf("failed to set role permissions: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *rolePostgresRepository) CountUsers(ctx context.Context, id string) (int, error) {
+	var count int
+	if err := r.db.Write(ctx).GetContext(ctx, &count, `SELECT COUNT(*) FROM users WHERE role_id = $1`, id); err != nil {
+		return 0, fmt.Errorf("failed to count role users: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,33 @@
+package usecase
+
+import (
+	"context"
+
+	"portal-data-backend/internal/role/domain"
+)
+
+// PermissionReader is the part of the role module permissions are checked
+// through
+type PermissionReader interface {
+	// Permissions returns the permissions the role id grants, none for an
+	// unknown role
+	Permissions(ctx context.Context, id string) ([]string, error)
+}
+
+// Usecase defines the interface for role business logic
+type Usecase interface {
+	PermissionReader
+	GetByID(ctx context.Context, id string) (*domain.RoleResponse, error)
+	List(ctx context.Context) ([]domain.RoleResponse, error)
+	Create(ctx context.Context, req *domain.CreateRoleRequest) (*domain.RoleResponse, error)
+	Update(ctx context.Context, id string, req *domain.UpdateRoleRequest) (*domain.RoleResponse, error)
+	// Delete refuses to delete a role users are still assigned
+	Delete(ctx context.Context, id string) error
+	// SetPermissions replaces the permissions a role grants with permissions
+	// of the catalog
+	SetPermissions(ctx context.Context, id string, req *domain.SetPermissionsRequest) (*domain.RoleResponse, error)
+	// Catalog lists the permissions roles may grant
+	Catalog() []domain.Permission
+}
+
+var _ Usecase = (*roleUsecase)(nil)
@@ -0,0 +1,188 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"portal-data-backend/infrastructure/audit"
+	"portal-data-backend/infrastructure/cache"
+	"portal-data-backend/internal/role/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+type roleUsecase struct {
+	roleRepo    domain.Repository
+	permissions *cache.Namespace
+	audit       *audit.Recorder
+}
+
+// NewRoleUsecase creates the role usecase. The permissions of roles are
+// cached in permissions, which may be nil like recorder.
+func NewRoleUsecase(roleRepo domain.Repository, permissions *cache.Namespace, recorder *audit.Recorder) *roleUsecase {
+	return &roleUsecase{roleRepo: roleRepo, permissions: permissions, audit: recorder}
+}
+
+func (u *roleUsecase) GetByID(ctx context.Context, id string) (*domain.RoleResponse, error) {
+	role, err := u.roleRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+	return u.toResponse(role), nil
+}
+
+func (u *roleUsecase) List(ctx context.Context) ([]domain.RoleResponse, error) {
+	roles, err := u.roleRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+
+	responses := make([]domain.RoleResponse, len(roles))
+	for i, role := range roles {
+		responses[i] = *u.toResponse(role)
+	}
+	return responses, nil
+}
+
+func (u *roleUsecase) Create(ctx context.Context, req *domain.CreateRoleRequest) (*domain.RoleResponse, error) {
+	permissions, err := validPermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	role := &domain.Role{
+		ID:          uuid.New().String(),
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: permissions,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := u.roleRepo.Create(ctx, role); err != nil {
+		return nil, fmt.Errorf("failed to create role: %w", err)
+	}
+	u.audit.Record(ctx, "roles", role.ID, audit.ActionCreate, nil, role)
+
+	return u.toResponse(role), nil
+}
+
+func (u *roleUsecase) Update(ctx context.Context, id string, req *domain.UpdateRoleRequest) (*domain.RoleResponse, error) {
+	role, err := u.roleRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+	before := *role
+
+	role.Name = req.Name
+	role.Description = req.Description
+	role.UpdatedAt = time.Now()
+
+	if err := u.roleRepo.Update(ctx, role); err != nil {
+		return nil, fmt.Errorf("failed to update role: %w", err)
+	}
+	u.audit.Record(ctx, "roles", role.ID, audit.ActionUpdate, &before, role)
+
+	return u.toResponse(role), nil
+}
+
+func (u *roleUsecase) Delete(ctx context.Context, id string) error {
+	role, err := u.roleRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get role: %w", err)
+	}
+
+	count, err := u.roleRepo.CountUsers(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete role: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("%w: role is assigned to %d users, assign them another role first", pkgErrors.ErrInUse, count)
+	}
+
+	if err := u.roleRepo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete role: %w", err)
+	}
+	u.permissions.Delete(ctx, id)
+	u.audit.Record(ctx, "roles", id, audit.ActionDelete, role, nil)
+	return nil
+}
+
+func (u *roleUsecase) SetPermissions(ctx context.Context, id string, req *domain.SetPermissionsRequest) (*domain.RoleResponse, error) {
+	permissions, err := validPermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := u.roleRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+	before := *role
+
+	if err := u.roleRepo.SetPermissions(ctx, id, permissions); err != nil {
+		return nil, fmt.Errorf("failed to set role permissions: %w", err)
+	}
+	u.permissions.Delete(ctx, id)
+	role.Permissions = permissions
+	u.audit.Record(ctx, "roles", id, audit.ActionUpdate, &before, role)
+
+	return u.toResponse(role), nil
+}
+
+// Permissions returns the permissions of the role id, cached for a while as
+// every write request checks them
+func (u *roleUsecase) Permissions(ctx context.Context, id string) ([]string, error) {
+	var permissions []string
+	if u.permissions.Get(ctx, id, &permissions) {
+		return permissions, nil
+	}
+
+	permissions, err := u.roleRepo.Permissions(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role permissions: %w", err)
+	}
+	u.permissions.Set(ctx, id, permissions)
+	return permissions, nil
+}
+
+func (u *roleUsecase) Catalog() []domain.Permission {
+	return domain.Catalog
+}
+
+// validPermissions returns permissions sorted without duplicates, refusing
+// ones missing from the catalog
+func validPermissions(permissions []string) ([]string, error) {
+	seen := make(map[string]bool, len(permissions))
+	valid := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		if !domain.IsPermission(permission) {
+			return nil, fmt.Errorf("%w: unknown permission %q", pkgErrors.ErrInvalidInput, permission)
+		}
+		if !seen[permission] {
+			seen[permission] = true
+			valid = append(valid, permission)
+		}
+	}
+	sort.Strings(valid)
+	return valid, nil
+}
+
+func (u *roleUsecase) toResponse(role *domain.Role) *domain.RoleResponse {
+	permissions := role.Permissions
+	if permissions == nil {
+		permissions = []string{}
+	}
+	return &domain.RoleResponse{
+		ID:          role.ID,
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: permissions,
+		CreatedAt:   role.CreatedAt,
+		UpdatedAt:   role.UpdatedAt,
+	}
+}
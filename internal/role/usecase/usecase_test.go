@@ -0,0 +1,183 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"portal-data-backend/infrastructure/cache"
+	"portal-data-backend/internal/role/domain"
+	"portal-data-backend/internal/role/usecase"
+	pkgerrors "portal-data-backend/pkg/errors"
+)
+
+// mockRepository is an in-memory implementation of Repository
+type mockRepository struct {
+	roles  map[string]*domain.Role
+	users  map[string]int
+	lookup int
+}
+
+func newMockRepository() *mockRepository {
+	return &mockRepository{roles: map[string]*domain.Role{}, users: map[string]int{}}
+}
+
+func (m *mockRepository) GetByID(ctx context.Context, id string) (*domain.Role, error) {
+	role, ok := m.roles[id]
+	if !ok {
+		return nil, pkgerrors.ErrNotFound
+	}
+	copied := *role
+	return &copied, nil
+}
+
+func (m *mockRepository) List(ctx context.Context) ([]*domain.Role, error) {
+	var roles []*domain.Role
+	for _, role := range m.roles {
+		roles = append(roles, role)
+	}
+	return roles, nil
+}
+
+func (m *mockRepository) Create(ctx context.Context, role *domain.Role) error {
+	for _, existing := range m.roles {
+		if existing.Name == role.Name {
+			return pkgerrors.ErrAlreadyExists
+		}
+	}
+	copied := *role
+	m.roles[role.ID] = &copied
+	return nil
+}
+
+func (m *mockRepository) Update(ctx context.Context, role *domain.Role) error {
+	if _, ok := m.roles[role.ID]; !ok {
+		return pkgerrors.ErrNotFound
+	}
+	copied := *role
+	m.roles[role.ID] = &copied
+	return nil
+}
+
+func (m *mockRepository) Delete(ctx context.Context, id string) error {
+	if _, ok := m.roles[id]; !ok {
+		return pkgerrors.ErrNotFound
+	}
+	delete(m.roles, id)
+	return nil
+}
+
+func (m *mockRepository) Permissions(ctx context.Context, id string) ([]string, error) {
+	m.lookup++
+	if role, ok := m.roles[id]; ok {
+		return role.Permissions, nil
+	}
+	return []string{}, nil
+}
+
+func (m *mockRepository) SetPermissions(ctx context.Context, id string, permissions []string) error {
+	role, ok := m.roles[id]
+	if !ok {
+		return pkgerrors.ErrNotFound
+	}
+	role.Permissions = permissions
+	return nil
+}
+
+func (m *mockRepository) CountUsers(ctx context.Context, id string) (int, error) {
+	return m.users[id], nil
+}
+
+func newRoleUsecase(repo *mockRepository) usecase.Usecase {
+	return usecase.NewRoleUsecase(repo, cache.NewStore(cache.NewMemory(), "test:").Namespace("roles", time.Minute), nil)
+}
+
+// Test roles only grant permissions of the catalog, without duplicates
+func TestRole_Create(t *testing.T) {
+	ctx := context.Background()
+	roles := newRoleUsecase(newMockRepository())
+
+	role, err := roles.Create(ctx, &domain.CreateRoleRequest{
+		Name:        "Editor",
+		Permissions: []string{domain.PermissionDatasetsWrite, domain.PermissionFilesWrite, domain.PermissionDatasetsWrite},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create role: %v", err)
+	}
+	if want := []string{domain.PermissionDatasetsWrite, domain.PermissionFilesWrite}; !reflect.DeepEqual(role.Permissions, want) {
+		t.Errorf("Expected permissions %v, got %v", want, role.Permissions)
+	}
+
+	_, err = roles.Create(ctx, &domain.CreateRoleRequest{Name: "Owner", Permissions: []string{"everything:write"}})
+	if !errors.Is(err, pkgerrors.ErrInvalidInput) {
+		t.Errorf("Expected an unknown permission to be refused, got %v", err)
+	}
+}
+
+// Test permissions are cached and reread once they change
+func TestRole_Permissions(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	roles := newRoleUsecase(repo)
+
+	role, err := roles.Create(ctx, &domain.CreateRoleRequest{Name: "Editor", Permissions: []string{domain.PermissionDatasetsWrite}})
+	if err != nil {
+		t.Fatalf("Failed to create role: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		permissions, err := roles.Permissions(ctx, role.ID)
+		if err != nil {
+			t.Fatalf("Failed to get permissions: %v", err)
+		}
+		if !reflect.DeepEqual(permissions, []string{domain.PermissionDatasetsWrite}) {
+			t.Errorf("Expected datasets:write, got %v", permissions)
+		}
+	}
+	if repo.lookup != 1 {
+		t.Errorf("Expected permissions read once, read %d times", repo.lookup)
+	}
+
+	if _, err := roles.SetPermissions(ctx, role.ID, &domain.SetPermissionsRequest{Permissions: []string{domain.PermissionTaxonomiesWrite}}); err != nil {
+		t.Fatalf("Failed to set permissions: %v", err)
+	}
+	permissions, err := roles.Permissions(ctx, role.ID)
+	if err != nil {
+		t.Fatalf("Failed to get permissions: %v", err)
+	}
+	if !reflect.DeepEqual(permissions, []string{domain.PermissionTaxonomiesWrite}) {
+		t.Errorf("Expected taxonomies:write after the change, got %v", permissions)
+	}
+
+	unknown, err := roles.Permissions(ctx, "unknown")
+	if err != nil || len(unknown) != 0 {
+		t.Errorf("Expected no permissions for an unknown role, got %v, %v", unknown, err)
+	}
+}
+
+// Test roles assigned to users cannot be deleted
+func TestRole_Delete(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	roles := newRoleUsecase(repo)
+
+	role, err := roles.Create(ctx, &domain.CreateRoleRequest{Name: "Editor"})
+	if err != nil {
+		t.Fatalf("Failed to create role: %v", err)
+	}
+
+	repo.users[role.ID] = 2
+	if err := roles.Delete(ctx, role.ID); !errors.Is(err, pkgerrors.ErrInUse) {
+		t.Errorf("Expected a role in use to be kept, got %v", err)
+	}
+
+	repo.users[role.ID] = 0
+	if err := roles.Delete(ctx, role.ID); err != nil {
+		t.Fatalf("Failed to delete role: %v", err)
+	}
+	if _, err := roles.GetByID(ctx, role.ID); !errors.Is(err, pkgerrors.ErrNotFound) {
+		t.Errorf("Expected the role to be deleted, got %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+// Package cli holds the portalctl commands of the search module.
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"portal-data-backend/internal/app"
+	"portal-data-backend/internal/search/usecase"
+)
+
+// pollInterval is how often reindex checks whether the rebuild finished
+const pollInterval = time.Second
+
+// Commands returns the search commands
+func Commands(searchUsecase usecase.Usecase) []app.Command {
+	return []app.Command{
+		{
+			Name:    "reindex",
+			Usage:   "reindex",
+			Summary: "Rebuild the search index from the database and wait for it",
+			Run: func(ctx context.Context, in io.Reader, out io.Writer, args []string) error {
+				return reindex(ctx, searchUsecase, out)
+			},
+		},
+	}
+}
+
+// reindex starts the rebuild, which runs in the background, and waits until
+// it is done
+func reindex(ctx context.Context, searchUsecase usecase.Usecase, out io.Writer) error {
+	if _, err := searchUsecase.Reindex(ctx); err != nil {
+		return fmt.Errorf("failed to start reindex: %w", err)
+	}
+	fmt.Fprintln(out, "Reindexing datasets...")
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		status := searchUsecase.Status(ctx).Reindex
+		if status.Running {
+			continue
+		}
+		if status.Error != nil {
+			return fmt.Errorf("reindex failed after %d datasets: %s", status.Indexed, *status.Error)
+		}
+		if status.FinishedAt == nil {
+			return errors.New("reindex did not start")
+		}
+		fmt.Fprintf(out, "Indexed %d datasets in %s\n", status.Indexed, status.FinishedAt.Sub(*status.StartedAt).Round(time.Millisecond))
+		return nil
+	}
+}
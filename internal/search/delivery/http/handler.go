@@ -0,0 +1,52 @@
+package http
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/middleware"
+	"portal-data-backend/infrastructure/http/problem"
+	"portal-data-backend/infrastructure/http/response"
+	"portal-data-backend/internal/search/usecase"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	searchUsecase usecase.Usecase
+}
+
+func NewHandler(searchUsecase usecase.Usecase) *Handler {
+	return &Handler{
+		searchUsecase: searchUsecase,
+	}
+}
+
+func (h *Handler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	response.OK(w, response.CodeSuccess, "Search status retrieved successfully", h.searchUsecase.Status(r.Context()))
+}
+
+// Reindex starts rebuilding the search index; its progress is reported by GetStatus
+func (h *Handler) Reindex(w http.ResponseWriter, r *http.Request) {
+	status, err := h.searchUsecase.Reindex(r.Context())
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.JSON(w, http.StatusAccepted, response.CodeSuccess, "Search reindex started", status)
+}
+
+func (h *Handler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	problem.Write(w, r, err)
+}
+
+// RegisterRoutes registers search index routes for users with one of
+// adminRoles
+func RegisterRoutes(r chi.Router, handler *Handler, auth func(http.Handler) http.Handler, adminRoles []string) {
+	r.Route("/search", func(r chi.Router) {
+		r.Use(auth)
+		r.Use(middleware.RequireRole(adminRoles...))
+		r.Get("/status", handler.GetStatus)
+		r.Post("/reindex", handler.Reindex)
+	})
+}
@@ -0,0 +1,72 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"portal-data-backend/infrastructure/auth"
+	delivery "portal-data-backend/internal/search/delivery/http"
+	"portal-data-backend/internal/search/domain"
+	"portal-data-backend/internal/search/usecase"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// mockUsecase counts the reindexes it is asked for
+type mockUsecase struct {
+	usecase.Usecase
+	reindexed int
+}
+
+func (m *mockUsecase) Reindex(ctx context.Context) (*domain.ReindexStatus, error) {
+	m.reindexed++
+	return &domain.ReindexStatus{}, nil
+}
+
+func (m *mockUsecase) Status(ctx context.Context) *domain.SearchStatus {
+	return &domain.SearchStatus{}
+}
+
+// Test only admins read the status of the search index and rebuild it
+func TestSearch_RequireAdmin(t *testing.T) {
+	search := &mockUsecase{}
+	// The tests sign in as the role of their header
+	signIn := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims := auth.Claims{UserID: "user-1", RoleID: r.Header.Get("X-Role")}
+			next.ServeHTTP(w, r.WithContext(auth.WithClaims(r.Context(), claims)))
+		})
+	}
+
+	r := chi.NewRouter()
+	delivery.RegisterRoutes(r, delivery.NewHandler(search), signIn, []string{"admin"})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		role   string
+		status int
+	}{
+		{"member reindexing", http.MethodPost, "/search/reindex", "member", http.StatusForbidden},
+		{"member reading the status", http.MethodGet, "/search/status", "member", http.StatusForbidden},
+		{"admin reindexing", http.MethodPost, "/search/reindex", "admin", http.StatusAccepted},
+		{"admin reading the status", http.MethodGet, "/search/status", "admin", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("X-Role", tt.role)
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Errorf("Expected %d, got %d: %s", tt.status, rec.Code, rec.Body.String())
+			}
+		})
+	}
+	if search.reindexed != 1 {
+		t.Errorf("Expected the admin alone to reindex, got %d reindexes", search.reindexed)
+	}
+}
@@ -0,0 +1,15 @@
+package http
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	"portal-data-backend/internal/search/domain"
+)
+
+// Describe adds the search index routes to the API specification
+func Describe(spec *openapi.Spec) {
+	api := spec.Tag("search", "Search index management")
+	api.Get("/search/status", "Get search index status").Returns(http.StatusOK, domain.SearchStatus{})
+	api.Post("/search/reindex", "Rebuild the search index").Returns(http.StatusAccepted, domain.ReindexStatus{})
+}
@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// ReindexStatus reports the progress of rebuilding the search index
+type ReindexStatus struct {
+	Running    bool       `json:"running"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Indexed    int        `json:"indexed"`
+	Error      *string    `json:"error,omitempty"`
+}
+
+// SearchStatus reports the search backend and its pending work
+type SearchStatus struct {
+	Backend     string        `json:"backend"`
+	QueueLength int           `json:"queue_length"` // dataset changes waiting to be indexed
+	Reindex     ReindexStatus `json:"reindex"`
+}
@@ -0,0 +1,73 @@
+// Package search is the module indexing datasets in the optional search backend.
+package search
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	"portal-data-backend/infrastructure/search"
+	"portal-data-backend/internal/app"
+	datasetRepo "portal-data-backend/internal/dataset/repository"
+	"portal-data-backend/internal/search/delivery/cli"
+	delivery "portal-data-backend/internal/search/delivery/http"
+	"portal-data-backend/internal/search/usecase"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Module keeps the search index in step with dataset events and searches
+// datasets when a search backend is configured
+type Module struct {
+	usecase    usecase.Usecase
+	handler    *delivery.Handler
+	adminRoles []string
+}
+
+// Name implements app.Module
+func (m *Module) Name() string {
+	return "search"
+}
+
+// Register implements app.Module
+func (m *Module) Register(deps *app.Deps) error {
+	if deps.Services.Tags == nil {
+		return app.MissingServiceError("tag")
+	}
+
+	index, err := search.NewIndex(&deps.Config.Search)
+	if err != nil {
+		return fmt.Errorf("failed to initialize search index: %w", err)
+	}
+	m.usecase = usecase.NewSearchUsecase(index, datasetRepo.NewDatasetPostgresRepository(deps.DBRouter), deps.Services.Tags, deps.Config.Search)
+	m.handler = delivery.NewHandler(m.usecase)
+	m.adminRoles = deps.Config.Audit.AdminRoles
+	deps.Events.Subscribe(m.usecase)
+
+	if index != nil {
+		deps.Services.DatasetSearcher = m.usecase
+		deps.Logger.Info("Searching datasets with %s", deps.Config.Search.Backend)
+	}
+	return nil
+}
+
+// Routes implements app.Module
+func (m *Module) Routes(r chi.Router, auth func(http.Handler) http.Handler) {
+	delivery.RegisterRoutes(r, m.handler, auth, m.adminRoles)
+}
+
+// Describe implements app.Module
+func (m *Module) Describe(spec *openapi.Spec) {
+	delivery.Describe(spec)
+}
+
+// Run implements app.Runner
+func (m *Module) Run(ctx context.Context) {
+	m.usecase.Run(ctx)
+}
+
+// Commands implements app.Commander
+func (m *Module) Commands() []app.Command {
+	return cli.Commands(m.usecase)
+}
@@ -0,0 +1,296 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+	"portal-data-backend/infrastructure/logger"
+	"portal-data-backend/infrastructure/search"
+	datasetDomain "portal-data-backend/internal/dataset/domain"
+	"portal-data-backend/internal/search/domain"
+	tagDomain "portal-data-backend/internal/tag/domain"
+	tagUsecase "portal-data-backend/internal/tag/usecase"
+	pkgErrors "portal-data-backend/pkg/errors"
+	"portal-data-backend/pkg/i18n"
+)
+
+// reindexPageSize is how many datasets a reindex reads and indexes at once
+const reindexPageSize = 100
+
+// DatasetSource is the part of the dataset module the index is built from
+type DatasetSource interface {
+	GetByID(ctx context.Context, id string) (*datasetDomain.Dataset, error)
+	List(ctx context.Context, filter *datasetDomain.DatasetFilter, limit, offset int, sortBy, sortOrder string) ([]*datasetDomain.Dataset, int, error)
+}
+
+// Usecase keeps the search index in step with the datasets and searches it
+type Usecase interface {
+	// Publish queues an index update for a dataset event. Events fan out to it
+	// like to any other publisher.
+	Publish(ctx context.Context, eventType string, data interface{}) error
+	// Search finds datasets for the dataset module
+	Search(ctx context.Context, filter *datasetDomain.DatasetFilter, limit, offset int) ([]string, int, error)
+	// Reindex starts rebuilding the index from the database in the background
+	Reindex(ctx context.Context) (*domain.ReindexStatus, error)
+	Status(ctx context.Context) *domain.SearchStatus
+	// Run applies queued index updates until ctx is done
+	Run(ctx context.Context)
+}
+
+type searchUsecase struct {
+	index    search.Index
+	datasets DatasetSource
+	tags     tagUsecase.TagReader
+	cfg      config.SearchConfig
+	queue    chan string
+	now      func() time.Time
+
+	mu      sync.Mutex
+	reindex domain.ReindexStatus
+}
+
+// NewSearchUsecase creates the search usecase. index is nil for the postgres
+// backend, which needs no index. The tags of datasets are read through tags.
+func NewSearchUsecase(index search.Index, datasets DatasetSource, tags tagUsecase.TagReader, cfg config.SearchConfig) Usecase {
+	queueSize := cfg.QueueSize
+	if queueSize < 1 {
+		queueSize = 1
+	}
+	return &searchUsecase{
+		index:    index,
+		datasets: datasets,
+		tags:     tags,
+		cfg:      cfg,
+		queue:    make(chan string, queueSize),
+		now:      time.Now,
+	}
+}
+
+func (u *searchUsecase) Publish(ctx context.Context, eventType string, data interface{}) error {
+	if u.index == nil || !strings.HasPrefix(eventType, "dataset.") {
+		return nil
+	}
+
+	var id string
+	switch d := data.(type) {
+	case *datasetDomain.DatasetResponse:
+		id = d.ID
+	case map[string]string:
+		id = d["id"]
+	case json.RawMessage:
+		// Events relayed from the outbox carry their recorded JSON
+		var event struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal(d, &event); err == nil {
+			id = event.ID
+		}
+	}
+	if id == "" {
+		return nil
+	}
+
+	select {
+	case u.queue <- id:
+	default:
+		logger.FromContext(ctx).Warn("search index queue is full, dataset %s is not reindexed until the next full reindex", id)
+	}
+	return nil
+}
+
+func (u *searchUsecase) Search(ctx context.Context, filter *datasetDomain.DatasetFilter, limit, offset int) ([]string, int, error) {
+	if u.index == nil {
+		return nil, 0, fmt.Errorf("the %s search backend has no index", u.cfg.Backend)
+	}
+
+	query := &search.Query{Text: filter.Search, Filters: map[string]string{}, Limit: limit, Offset: offset}
+	for field, value := range map[string]string{
+		"organization_id":   filter.OrganizationID,
+		"topic_id":          filter.TopicID,
+		"business_field_id": filter.BusinessFieldID,
+		"tag_ids":           filter.TagID,
+		"status":            filter.Status,
+		"validation_status": filter.ValidationStatus,
+		"classification":    filter.Classification,
+	} {
+		if value != "" {
+			query.Filters[field] = value
+		}
+	}
+
+	result, err := u.index.Search(ctx, query)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search datasets: %w", err)
+	}
+	return result.IDs, result.Total, nil
+}
+
+func (u *searchUsecase) Reindex(ctx context.Context) (*domain.ReindexStatus, error) {
+	if u.index == nil {
+		return nil, fmt.Errorf("%w: the %s search backend has no index to rebuild", pkgErrors.ErrInvalidInput, u.cfg.Backend)
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.reindex.Running {
+		return nil, fmt.Errorf("%w: a reindex is already running", pkgErrors.ErrInUse)
+	}
+
+	startedAt := u.now()
+	u.reindex = domain.ReindexStatus{Running: true, StartedAt: &startedAt}
+	go u.rebuild(context.WithoutCancel(ctx), startedAt)
+
+	status := u.reindex
+	return &status, nil
+}
+
+func (u *searchUsecase) Status(ctx context.Context) *domain.SearchStatus {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return &domain.SearchStatus{
+		Backend:     u.cfg.Backend,
+		QueueLength: len(u.queue),
+		Reindex:     u.reindex,
+	}
+}
+
+func (u *searchUsecase) Run(ctx context.Context) {
+	if u.index == nil {
+		return
+	}
+	if err := u.index.EnsureIndex(ctx); err != nil {
+		logger.FromContext(ctx).Error("failed to create search index: %v", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-u.queue:
+			if err := u.sync(ctx, id); err != nil {
+				logger.FromContext(ctx).Error("failed to update dataset %s in the search index: %v", id, err)
+			}
+		}
+	}
+}
+
+// sync indexes the current state of a dataset, or removes it when it is gone
+func (u *searchUsecase) sync(ctx context.Context, id string) error {
+	dataset, err := u.datasets.GetByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) || errors.Is(err, pkgErrors.ErrNotFound) {
+		return u.index.Delete(ctx, id)
+	}
+	if err != nil {
+		return err
+	}
+	tags, err := u.tags.GetByIDs(ctx, dataset.TagIDs)
+	if err != nil {
+		return fmt.Errorf("failed to get tags: %w", err)
+	}
+	return u.index.Upsert(ctx, []search.Document{toDocument(dataset, tags, u.now())})
+}
+
+// rebuild indexes every dataset and then removes the documents it did not
+// touch, so searches keep working while it runs
+func (u *searchUsecase) rebuild(ctx context.Context, startedAt time.Time) {
+	indexed, err := u.indexAll(ctx, startedAt)
+	if err == nil {
+		err = u.index.DeleteIndexedBefore(ctx, startedAt)
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	finishedAt := u.now()
+	u.reindex.Running = false
+	u.reindex.FinishedAt = &finishedAt
+	u.reindex.Indexed = indexed
+	if err != nil {
+		message := err.Error()
+		u.reindex.Error = &message
+		logger.FromContext(ctx).Error("search reindex failed after %d datasets: %v", indexed, err)
+		return
+	}
+	logger.FromContext(ctx).Info("search reindex indexed %d datasets in %s", indexed, finishedAt.Sub(startedAt))
+}
+
+func (u *searchUsecase) indexAll(ctx context.Context, indexedAt time.Time) (int, error) {
+	if err := u.index.EnsureIndex(ctx); err != nil {
+		return 0, err
+	}
+
+	indexed := 0
+	for offset := 0; ; offset += reindexPageSize {
+		page, _, err := u.datasets.List(ctx, &datasetDomain.DatasetFilter{}, reindexPageSize, offset, "created_at", "ASC")
+		if err != nil {
+			return indexed, fmt.Errorf("failed to list datasets: %w", err)
+		}
+
+		docs := make([]search.Document, 0, len(page))
+		for _, listed := range page {
+			// Listed datasets come without their tags
+			dataset, err := u.datasets.GetByID(ctx, listed.ID)
+			if err != nil {
+				return indexed, fmt.Errorf("failed to get dataset %s: %w", listed.ID, err)
+			}
+			tags, err := u.tags.GetByIDs(ctx, dataset.TagIDs)
+			if err != nil {
+				return indexed, fmt.Errorf("failed to get tags of dataset %s: %w", listed.ID, err)
+			}
+			docs = append(docs, toDocument(dataset, tags, indexedAt))
+		}
+		if err := u.index.Upsert(ctx, docs); err != nil {
+			return indexed, err
+		}
+		indexed += len(docs)
+
+		u.mu.Lock()
+		u.reindex.Indexed = indexed
+		u.mu.Unlock()
+
+		if len(page) < reindexPageSize {
+			return indexed, nil
+		}
+	}
+}
+
+func toDocument(dataset *datasetDomain.Dataset, tags []*tagDomain.TagResponse, indexedAt time.Time) search.Document {
+	doc := search.Document{
+		ID:               dataset.ID,
+		Title:            dataset.Name,
+		Tags:             []string{},
+		TagIDs:           []string{},
+		Translations:     []string{},
+		OrganizationID:   dataset.OrganizationID,
+		Status:           string(dataset.Status),
+		ValidationStatus: string(dataset.ValidationStatus),
+		Classification:   dataset.Classification,
+		UpdatedAt:        dataset.UpdatedAt,
+		IndexedAt:        indexedAt,
+	}
+	if dataset.Description != nil {
+		doc.Description = *dataset.Description
+	}
+	if dataset.TopicID != nil {
+		doc.TopicID = *dataset.TopicID
+	}
+	if dataset.BusinessFieldID != nil {
+		doc.BusinessFieldID = *dataset.BusinessFieldID
+	}
+	for _, tag := range tags {
+		doc.Tags = append(doc.Tags, tag.Name)
+		doc.TagIDs = append(doc.TagIDs, tag.ID)
+	}
+	for _, texts := range []string{dataset.Names, dataset.Descriptions} {
+		for _, text := range i18n.Decode(texts) {
+			doc.Translations = append(doc.Translations, text)
+		}
+	}
+	return doc
+}
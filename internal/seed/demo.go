@@ -0,0 +1,282 @@
+package seed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+
+	businessFieldDomain "portal-data-backend/internal/business_field/domain"
+	dataRowDomain "portal-data-backend/internal/data_row/domain"
+	datasetDomain "portal-data-backend/internal/dataset/domain"
+	tagDomain "portal-data-backend/internal/tag/domain"
+	topicDomain "portal-data-backend/internal/topic/domain"
+	unitDomain "portal-data-backend/internal/unit/domain"
+	vizDomain "portal-data-backend/internal/visualization/domain"
+)
+
+// demoTemplate is a demo dataset. Its taxonomies refer to the seeded ones
+// by slug, and its unit by name.
+type demoTemplate struct {
+	Name          string
+	Slug          string
+	Description   string
+	Topic         string
+	BusinessField string
+	Unit          string
+	Tags          []string
+	// Base is the value of an average region in the first year, which
+	// grows by Growth every year
+	Base     float64
+	Growth   float64
+	Decimals int
+	Chart    vizDomain.VisualizationType
+}
+
+var demoTemplates = []demoTemplate{
+	{
+		Name: "Jumlah Penduduk", Slug: "jumlah-penduduk",
+		Description: "Jumlah penduduk per kabupaten/kota menurut tahun",
+		Topic:       "kependudukan", BusinessField: "kependudukan-dan-pencatatan-sipil", Unit: "Jiwa",
+		Tags: []string{"penduduk", "tahunan"}, Base: 1200000, Growth: 0.012, Chart: vizDomain.VisualizationTypeLineChart,
+	},
+	{
+		Name: "Angka Partisipasi Sekolah", Slug: "angka-partisipasi-sekolah",
+		Description: "Angka partisipasi sekolah usia 7-18 tahun per kabupaten/kota",
+		Topic:       "pendidikan", BusinessField: "pendidikan", Unit: "Persen",
+		Tags: []string{"sekolah", "tahunan"}, Base: 88, Growth: 0.004, Decimals: 2, Chart: vizDomain.VisualizationTypeBarChart,
+	},
+	{
+		Name: "Jumlah Puskesmas", Slug: "jumlah-puskesmas",
+		Description: "Jumlah puskesmas per kabupaten/kota",
+		Topic:       "kesehatan", BusinessField: "kesehatan", Unit: "Unit",
+		Tags: []string{"kesehatan"}, Base: 40, Growth: 0.02, Chart: vizDomain.VisualizationTypeBarChart,
+	},
+	{
+		Name: "Persentase Penduduk Miskin", Slug: "persentase-penduduk-miskin",
+		Description: "Persentase penduduk di bawah garis kemiskinan per kabupaten/kota",
+		Topic:       "sosial", BusinessField: "sosial", Unit: "Persen",
+		Tags: []string{"kemiskinan", "penduduk"}, Base: 9, Growth: -0.03, Decimals: 2, Chart: vizDomain.VisualizationTypeLineChart,
+	},
+	{
+		Name: "Realisasi Pendapatan Daerah", Slug: "realisasi-pendapatan-daerah",
+		Description: "Realisasi pendapatan asli daerah per kabupaten/kota",
+		Topic:       "ekonomi", BusinessField: "keuangan", Unit: "Rupiah",
+		Tags: []string{"anggaran", "tahunan"}, Base: 850000000000, Growth: 0.06, Chart: vizDomain.VisualizationTypeArea,
+	},
+	{
+		Name: "Panjang Jalan Kondisi Baik", Slug: "panjang-jalan-kondisi-baik",
+		Description: "Panjang jalan kabupaten/kota dalam kondisi baik",
+		Topic:       "infrastruktur", BusinessField: "pekerjaan-umum-dan-penataan-ruang", Unit: "Kilometer",
+		Tags: []string{"jalan"}, Base: 620, Growth: 0.025, Decimals: 1, Chart: vizDomain.VisualizationTypeBarChart,
+	},
+	{
+		Name: "Timbulan Sampah", Slug: "timbulan-sampah",
+		Description: "Timbulan sampah harian per kabupaten/kota",
+		Topic:       "lingkungan-hidup", BusinessField: "lingkungan-hidup", Unit: "Ton",
+		Tags: []string{"sampah"}, Base: 780, Growth: 0.018, Decimals: 1, Chart: vizDomain.VisualizationTypeLineChart,
+	},
+	{
+		Name: "Kunjungan Wisatawan", Slug: "kunjungan-wisatawan",
+		Description: "Kunjungan wisatawan nusantara dan mancanegara per kabupaten/kota",
+		Topic:       "pariwisata", BusinessField: "pariwisata", Unit: "Kunjungan",
+		Tags: []string{"wisatawan", "tahunan"}, Base: 2400000, Growth: 0.07, Chart: vizDomain.VisualizationTypeBarChart,
+	},
+	{
+		Name: "Produksi Padi", Slug: "produksi-padi",
+		Description: "Produksi padi sawah dan ladang per kabupaten/kota",
+		Topic:       "pertanian", BusinessField: "pertanian", Unit: "Ton",
+		Tags: []string{"padi", "tahunan"}, Base: 410000, Growth: 0.01, Chart: vizDomain.VisualizationTypeArea,
+	},
+}
+
+var demoRegions = []string{
+	"Kota Bandung", "Kota Bekasi", "Kota Bogor", "Kota Depok", "Kota Cimahi",
+	"Kabupaten Bandung", "Kabupaten Bogor", "Kabupaten Garut", "Kabupaten Cirebon", "Kabupaten Sukabumi",
+}
+
+const (
+	demoFirstYear = 2019
+	demoYears     = 6
+
+	// demoChartConfig plots the value per year with a series per region
+	demoChartConfig = `{"x":"tahun","y":"nilai","series":"wilayah"}`
+)
+
+// demoRow is a row of a demo dataset: the value of a region in a year
+type demoRow struct {
+	Region string  `json:"wilayah"`
+	Year   int     `json:"tahun"`
+	Value  float64 `json:"nilai"`
+}
+
+// demoDataset is a generated demo dataset
+type demoDataset struct {
+	demoTemplate
+	Rows []demoRow
+}
+
+// generateDemo generates count demo datasets, at most one per template and
+// one per template when count is 0. Regions differ in size and values drift
+// around their trend, both drawn from rng.
+func generateDemo(rng *rand.Rand, count int) []demoDataset {
+	if count <= 0 || count > len(demoTemplates) {
+		count = len(demoTemplates)
+	}
+
+	datasets := make([]demoDataset, count)
+	for i, template := range demoTemplates[:count] {
+		dataset := demoDataset{demoTemplate: template}
+		scale := math.Pow(10, float64(template.Decimals))
+		for _, region := range demoRegions {
+			// Rates vary less between regions than counts do
+			size := 0.4 + 1.2*rng.Float64()
+			if template.Decimals > 0 {
+				size = 0.85 + 0.3*rng.Float64()
+			}
+			for year := 0; year < demoYears; year++ {
+				trend := template.Base * size * math.Pow(1+template.Growth, float64(year))
+				noise := 1 + 0.04*(rng.Float64()-0.5)
+				dataset.Rows = append(dataset.Rows, demoRow{
+					Region: region,
+					Year:   demoFirstYear + year,
+					Value:  math.Round(trend*noise*scale) / scale,
+				})
+			}
+		}
+		datasets[i] = dataset
+	}
+	return datasets
+}
+
+// demo seeds the demo datasets of opts in the seeded organization, as
+// published datasets with their rows and a published visualization.
+// Datasets whose slug exists are skipped.
+func (s *Seeder) demo(ctx context.Context, report *Report, opts Options) error {
+	taxonomies, err := s.taxonomyIDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, dataset := range generateDemo(opts.Rand, opts.DemoDatasets) {
+		if _, err := s.services.Datasets.GetBySlug(ctx, dataset.Slug); err == nil {
+			continue
+		} else if !notFound(err) {
+			return err
+		}
+
+		var tagIDs []string
+		for _, tag := range dataset.Tags {
+			if id, ok := taxonomies.tags[tag]; ok {
+				tagIDs = append(tagIDs, id)
+			}
+		}
+		created, err := s.services.Datasets.Create(ctx, &datasetDomain.CreateDatasetRequest{
+			Name:             dataset.Name,
+			Description:      dataset.Description,
+			CoverageStart:    fmt.Sprintf("%d-01-01", demoFirstYear),
+			CoverageEnd:      fmt.Sprintf("%d-12-31", demoFirstYear+demoYears-1),
+			Frequency:        string(datasetDomain.FrequencyAnnual),
+			UnitID:           taxonomies.units[dataset.Unit],
+			BusinessFieldID:  taxonomies.businessFields[dataset.BusinessField],
+			TopicID:          taxonomies.topics[dataset.Topic],
+			Classification:   "public",
+			Category:         "statistik",
+			ValidationStatus: string(datasetDomain.ValidationStatusValid),
+			TagIDs:           tagIDs,
+		}, report.AdminID, report.OrganizationID)
+		if err != nil {
+			return fmt.Errorf("failed to seed dataset %s: %w", dataset.Slug, err)
+		}
+
+		rows := make([]dataRowDomain.DataRowDataInput, len(dataset.Rows))
+		for i, row := range dataset.Rows {
+			encoded, err := json.Marshal(row)
+			if err != nil {
+				return err
+			}
+			rows[i] = dataRowDomain.DataRowDataInput{RowIndex: i, Data: string(encoded)}
+		}
+		if err := s.services.DataRows.BulkCreate(ctx, &dataRowDomain.BulkCreateDataRowsRequest{DatasetID: created.ID, Rows: rows}, report.AdminID); err != nil {
+			return fmt.Errorf("failed to seed rows of %s: %w", dataset.Slug, err)
+		}
+		if err := s.services.Datasets.UpdateStatus(ctx, created.ID, datasetDomain.DatasetStatusPublished); err != nil {
+			return fmt.Errorf("failed to publish dataset %s: %w", dataset.Slug, err)
+		}
+
+		topicID := taxonomies.topics[dataset.Topic]
+		viz, err := s.services.Visualizations.Create(ctx, &vizDomain.CreateVisualizationRequest{
+			Title:          dataset.Name,
+			Description:    &dataset.Description,
+			Type:           string(dataset.Chart),
+			Config:         demoChartConfig,
+			DatasetID:      &created.ID,
+			OrganizationID: &report.OrganizationID,
+			TopicID:        &topicID,
+		}, report.AdminID)
+		if err != nil {
+			return fmt.Errorf("failed to seed visualization of %s: %w", dataset.Slug, err)
+		}
+		if err := s.services.Visualizations.UpdateStatus(ctx, viz.ID, string(vizDomain.VisualizationStatusPublished)); err != nil {
+			return fmt.Errorf("failed to publish visualization of %s: %w", dataset.Slug, err)
+		}
+
+		report.Datasets++
+		report.Rows += len(rows)
+		report.Visualizations++
+	}
+	return nil
+}
+
+// taxonomyIDs are the IDs of the seeded taxonomies
+type taxonomyIDs struct {
+	topics         map[string]string
+	businessFields map[string]string
+	units          map[string]string
+	tags           map[string]string
+}
+
+// taxonomyIDs looks up the IDs of the taxonomies demo datasets refer to.
+// The seeded taxonomies fit on the first page of each list.
+func (s *Seeder) taxonomyIDs(ctx context.Context) (*taxonomyIDs, error) {
+	ids := &taxonomyIDs{
+		topics:         make(map[string]string),
+		businessFields: make(map[string]string),
+		units:          make(map[string]string),
+		tags:           make(map[string]string),
+	}
+
+	topics, err := s.services.Topics.List(ctx, &topicDomain.ListTopicsRequest{Page: 1, Limit: 100})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list topics: %w", err)
+	}
+	for _, topic := range topics.Topics {
+		ids.topics[topic.Slug] = topic.ID
+	}
+
+	businessFields, err := s.services.BusinessFields.List(ctx, &businessFieldDomain.ListBusinessFieldsRequest{Page: 1, Limit: 100})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list business fields: %w", err)
+	}
+	for _, businessField := range businessFields.BusinessFields {
+		ids.businessFields[businessField.Slug] = businessField.ID
+	}
+
+	units, err := s.services.Units.List(ctx, &unitDomain.ListUnitsRequest{Page: 1, Limit: 100})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list units: %w", err)
+	}
+	for _, unit := range units.Units {
+		ids.units[unit.Name] = unit.ID
+	}
+
+	tags, err := s.services.Tags.List(ctx, &tagDomain.ListTagsRequest{Page: 1, Limit: 100})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	for _, tag := range tags.Tags {
+		ids.tags[tag.Slug] = tag.ID
+	}
+	return ids, nil
+}
@@ -0,0 +1,218 @@
+// Package seed provisions the records a new environment starts from: an
+// organization with its admin account and the taxonomies datasets are
+// classified with, optionally followed by demo datasets. Seeding goes
+// through the usecases of the modules and can run again: records that
+// exist are kept, and taxonomies are updated to the seeded values.
+package seed
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"portal-data-backend/internal/app"
+	authDomain "portal-data-backend/internal/auth/domain"
+	orgDomain "portal-data-backend/internal/organization/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+)
+
+//go:embed data/*.csv
+var data embed.FS
+
+// Options are what to seed
+type Options struct {
+	OrganizationCode string
+	OrganizationName string
+
+	// AdminRole is the role ID of the admin account. Roles are IDs the
+	// configuration grants rights to, like AUDIT_ADMIN_ROLES.
+	AdminRole     string
+	AdminName     string
+	AdminUsername string
+	AdminEmail    string
+	AdminPassword string
+
+	// Demo seeds demo datasets, each with its rows and a visualization.
+	// DemoDatasets limits how many; 0 seeds one per template.
+	Demo         bool
+	DemoDatasets int
+	// Rand generates the demo data; the same seed generates the same data
+	Rand *rand.Rand
+}
+
+// Report tells what a run seeded
+type Report struct {
+	OrganizationID string
+	AdminID        string
+	// AdminCreated is false when the admin account already existed
+	AdminCreated bool
+	// Taxonomies counts the records created and updated per taxonomy
+	Taxonomies []TaxonomyReport
+
+	Datasets       int
+	Rows           int
+	Visualizations int
+}
+
+// TaxonomyReport counts the records a taxonomy import created and updated
+type TaxonomyReport struct {
+	Name    string
+	Created int
+	Updated int
+}
+
+// Seeder seeds through the services of the registered modules
+type Seeder struct {
+	services app.Services
+}
+
+// New creates a seeder for the services of every module
+func New(services app.Services) (*Seeder, error) {
+	switch {
+	case services.Accounts == nil:
+		return nil, app.MissingServiceError("account")
+	case services.Organizations == nil:
+		return nil, app.MissingServiceError("organization")
+	case services.Topics == nil, services.BusinessFields == nil, services.Units == nil, services.Tags == nil:
+		return nil, app.MissingServiceError("taxonomy")
+	case services.Datasets == nil, services.DataRows == nil, services.Visualizations == nil:
+		return nil, app.MissingServiceError("dataset")
+	}
+	return &Seeder{services: services}, nil
+}
+
+// Run seeds the organization, its admin and the taxonomies, then the demo
+// datasets when opts asks for them
+func (s *Seeder) Run(ctx context.Context, opts Options) (*Report, error) {
+	report := &Report{}
+
+	orgID, err := s.organization(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	report.OrganizationID = orgID
+
+	report.AdminID, report.AdminCreated, err = s.admin(ctx, orgID, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if report.Taxonomies, err = s.taxonomies(ctx); err != nil {
+		return nil, err
+	}
+
+	if opts.Demo {
+		if err := s.demo(ctx, report, opts); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// organization returns the ID of the organization of opts, creating it
+// when it does not exist
+func (s *Seeder) organization(ctx context.Context, opts Options) (string, error) {
+	org, err := s.services.Organizations.GetByCode(ctx, strings.ToUpper(opts.OrganizationCode))
+	if err == nil {
+		return org.ID, nil
+	}
+	if !notFound(err) {
+		return "", err
+	}
+
+	org, err = s.services.Organizations.Create(ctx, &orgDomain.CreateOrganizationRequest{
+		Code: opts.OrganizationCode,
+		Name: opts.OrganizationName,
+	}, "")
+	if err != nil {
+		return "", fmt.Errorf("failed to seed organization: %w", err)
+	}
+	return org.ID, nil
+}
+
+// admin returns the ID of the admin account of opts and whether it was
+// created. An existing account is kept as it is, password included.
+func (s *Seeder) admin(ctx context.Context, orgID string, opts Options) (string, bool, error) {
+	user, err := s.services.Accounts.GetUserByEmail(ctx, opts.AdminEmail)
+	if err == nil {
+		return user.ID, false, nil
+	}
+	if !notFound(err) {
+		return "", false, err
+	}
+
+	user, err = s.services.Accounts.CreateUser(ctx, &authDomain.RegisterRequest{
+		OrganizationID: orgID,
+		RoleID:         opts.AdminRole,
+		Name:           opts.AdminName,
+		Username:       opts.AdminUsername,
+		Email:          opts.AdminEmail,
+		Password:       opts.AdminPassword,
+	})
+	if err != nil {
+		return "", false, fmt.Errorf("failed to seed admin: %w", err)
+	}
+	return user.ID, true, nil
+}
+
+// taxonomies imports the topics, business fields, units and tags of the
+// data directory. Imports match existing records by slug, or by name for
+// units, so they are not duplicated.
+func (s *Seeder) taxonomies(ctx context.Context) ([]TaxonomyReport, error) {
+	imports := []struct {
+		name string
+		run  func(ctx context.Context, file []byte) (created, updated int, err error)
+	}{
+		{"topics", func(ctx context.Context, file []byte) (int, int, error) {
+			result, err := s.services.Topics.Import(ctx, bytes.NewReader(file))
+			if err != nil {
+				return 0, 0, err
+			}
+			return result.Created, result.Updated, nil
+		}},
+		{"business_fields", func(ctx context.Context, file []byte) (int, int, error) {
+			result, err := s.services.BusinessFields.Import(ctx, bytes.NewReader(file))
+			if err != nil {
+				return 0, 0, err
+			}
+			return result.Created, result.Updated, nil
+		}},
+		{"units", func(ctx context.Context, file []byte) (int, int, error) {
+			result, err := s.services.Units.Import(ctx, bytes.NewReader(file))
+			if err != nil {
+				return 0, 0, err
+			}
+			return result.Created, result.Updated, nil
+		}},
+		{"tags", func(ctx context.Context, file []byte) (int, int, error) {
+			result, err := s.services.Tags.Import(ctx, bytes.NewReader(file))
+			if err != nil {
+				return 0, 0, err
+			}
+			return result.Created, result.Updated, nil
+		}},
+	}
+
+	reports := make([]TaxonomyReport, 0, len(imports))
+	for _, taxonomy := range imports {
+		file, err := data.ReadFile("data/" + taxonomy.name + ".csv")
+		if err != nil {
+			return nil, err
+		}
+		created, updated, err := taxonomy.run(ctx, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to seed %s: %w", taxonomy.name, err)
+		}
+		reports = append(reports, TaxonomyReport{Name: taxonomy.name, Created: created, Updated: updated})
+	}
+	return reports, nil
+}
+
+func notFound(err error) bool {
+	return errors.Is(err, sql.ErrNoRows) || errors.Is(err, pkgErrors.ErrNotFound)
+}
@@ -0,0 +1,77 @@
+package seed
+
+import (
+	"encoding/csv"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// Test the same seed generates the same demo data
+func TestGenerateDemo_Deterministic(t *testing.T) {
+	first := generateDemo(rand.New(rand.NewSource(42)), 3)
+	second := generateDemo(rand.New(rand.NewSource(42)), 3)
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("Expected the same datasets for the same seed")
+	}
+
+	other := generateDemo(rand.New(rand.NewSource(7)), 3)
+	if reflect.DeepEqual(first, other) {
+		t.Errorf("Expected other values for another seed")
+	}
+}
+
+// Test demo datasets have a row per region and year and are capped at the
+// templates
+func TestGenerateDemo_Rows(t *testing.T) {
+	datasets := generateDemo(rand.New(rand.NewSource(1)), 100)
+	if len(datasets) != len(demoTemplates) {
+		t.Fatalf("Expected %d datasets, got %d", len(demoTemplates), len(datasets))
+	}
+	for _, dataset := range datasets {
+		if len(dataset.Rows) != len(demoRegions)*demoYears {
+			t.Errorf("Expected %d rows for %s, got %d", len(demoRegions)*demoYears, dataset.Slug, len(dataset.Rows))
+		}
+		for _, row := range dataset.Rows {
+			if row.Value <= 0 {
+				t.Errorf("Expected positive values for %s, got %v", dataset.Slug, row.Value)
+			}
+		}
+	}
+}
+
+// Test demo datasets refer to seeded taxonomies only
+func TestDemoTemplates_ReferToSeededTaxonomies(t *testing.T) {
+	seeded := map[string]map[string]bool{}
+	for file, column := range map[string]string{"topics": "slug", "business_fields": "slug", "units": "name", "tags": "slug"} {
+		content, err := data.Open("data/" + file + ".csv")
+		if err != nil {
+			t.Fatalf("Expected the %s file, got %v", file, err)
+		}
+		records, err := csv.NewReader(content).ReadAll()
+		if err != nil {
+			t.Fatalf("Expected valid CSV in %s, got %v", file, err)
+		}
+		index := -1
+		for i, name := range records[0] {
+			if name == column {
+				index = i
+			}
+		}
+		seeded[file] = map[string]bool{}
+		for _, record := range records[1:] {
+			seeded[file][record[index]] = true
+		}
+	}
+
+	for _, template := range demoTemplates {
+		if !seeded["topics"][template.Topic] || !seeded["business_fields"][template.BusinessField] || !seeded["units"][template.Unit] {
+			t.Errorf("Expected the taxonomies of %s to be seeded", template.Slug)
+		}
+		for _, tag := range template.Tags {
+			if !seeded["tags"][tag] {
+				t.Errorf("Expected tag %s of %s to be seeded", tag, template.Slug)
+			}
+		}
+	}
+}
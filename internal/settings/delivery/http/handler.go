@@ -1,43 +1,43 @@
 package http
 
 import (
-	"encoding/json"
-	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 
+	"portal-data-backend/infrastructure/auth"
 	settingsDomain "portal-data-backend/internal/settings/domain"
 	"portal-data-backend/internal/settings/usecase"
+	"portal-data-backend/infrastructure/http/httputil"
+	"portal-data-backend/infrastructure/http/middleware"
+	"portal-data-backend/infrastructure/http/problem"
 	"portal-data-backend/infrastructure/http/response"
+	roleDomain "portal-data-backend/internal/role/domain"
 	pkgErrors "portal-data-backend/pkg/errors"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/go-playground/validator/v10"
 )
 
 type Handler struct {
 	settingsUsecase usecase.Usecase
-	validator        *validator.Validate
 }
 
 func NewHandler(settingsUsecase usecase.Usecase) *Handler {
 	return &Handler{
 		settingsUsecase: settingsUsecase,
-		validator:        validator.New(),
 	}
 }
 
 func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Setting ID is required", nil)
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
 	setting, err := h.settingsUsecase.GetByID(r.Context(), id)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -58,7 +58,7 @@ func (h *Handler) GetByKey(w http.ResponseWriter, r *http.Request) {
 
 	setting, err := h.settingsUsecase.GetByKey(r.Context(), key, userID)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -79,13 +79,16 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 	if userID := r.URL.Query().Get("user_id"); userID != "" {
 		req.UserID = &userID
 	}
+	if orgID := r.URL.Query().Get("organization_id"); orgID != "" {
+		req.OrganizationID = &orgID
+	}
 	if settingType := r.URL.Query().Get("type"); settingType != "" {
 		req.Type = &settingType
 	}
 
 	resp, err := h.settingsUsecase.List(r.Context(), req)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -93,20 +96,14 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
-	var req settingsDomain.CreateSettingRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, response.CodeBadRequest, "Invalid request body", nil)
-		return
-	}
-
-	if err := h.validator.Struct(&req); err != nil {
-		response.ValidationError(w, response.CodeValidationFailed, "Validation failed", h.formatValidationErrors(err))
+	req, ok := httputil.Decode[settingsDomain.CreateSettingRequest](w, r)
+	if !ok {
 		return
 	}
 
-	setting, err := h.settingsUsecase.Create(r.Context(), &req)
+	setting, err := h.settingsUsecase.Create(r.Context(), req)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -114,26 +111,19 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Setting ID is required", nil)
-		return
-	}
-
-	var req settingsDomain.UpdateSettingRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, response.CodeBadRequest, "Invalid request body", nil)
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
-	if err := h.validator.Struct(&req); err != nil {
-		response.ValidationError(w, response.CodeValidationFailed, "Validation failed", h.formatValidationErrors(err))
+	req, ok := httputil.Decode[settingsDomain.UpdateSettingRequest](w, r)
+	if !ok {
 		return
 	}
 
-	setting, err := h.settingsUsecase.Update(r.Context(), id, &req)
+	setting, err := h.settingsUsecase.Update(r.Context(), id, req)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -141,14 +131,13 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Setting ID is required", nil)
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
 	if err := h.settingsUsecase.Delete(r.Context(), id); err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -172,9 +161,14 @@ func (h *Handler) GetByKeys(w http.ResponseWriter, r *http.Request) {
 		userID = &uid
 	}
 
-	settings, err := h.settingsUsecase.GetByKeys(r.Context(), keys, userID)
+	var orgID *string
+	if oid := r.URL.Query().Get("organization_id"); oid != "" {
+		orgID = &oid
+	}
+
+	settings, err := h.settingsUsecase.GetByKeys(r.Context(), keys, userID, orgID)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -198,50 +192,185 @@ func (h *Handler) GetByCategory(w http.ResponseWriter, r *http.Request) {
 
 	resp, err := h.settingsUsecase.GetByCategory(r.Context(), category, userID, page, limit)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
 	response.OK(w, response.CodeSuccess, "Settings retrieved successfully", resp)
 }
 
-func (h *Handler) handleError(w http.ResponseWriter, err error) {
-	if err == nil {
+func (h *Handler) ListOrganizationSettings(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := h.authorizeOrganization(w, r)
+	if !ok {
 		return
 	}
 
-	switch {
-	case errors.Is(err, pkgErrors.ErrNotFound):
-		response.NotFound(w, response.CodeNotFound, "Setting not found", nil)
-	default:
-		response.InternalError(w, response.CodeInternalServerError, "Internal server error", nil)
+	req := &settingsDomain.ListSettingsRequest{
+		Page:   parseIntQuery(r, "page", 1),
+		Limit:  parseIntQuery(r, "limit", 20),
+		Search: r.URL.Query().Get("search"),
+	}
+	if settingType := r.URL.Query().Get("type"); settingType != "" {
+		req.Type = &settingType
+	}
+
+	resp, err := h.settingsUsecase.ListOrganizationSettings(r.Context(), orgID, req)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
 	}
+
+	response.OK(w, response.CodeSuccess, "Settings retrieved successfully", resp)
 }
 
-func (h *Handler) formatValidationErrors(err error) []response.ErrorDetail {
-	var details []response.ErrorDetail
-	if validationErrors, ok := err.(validator.ValidationErrors); ok {
-		for _, fieldErr := range validationErrors {
-			details = append(details, response.ErrorDetail{
-				Field:   fieldErr.Field(),
-				Message: h.getValidationErrorMessage(fieldErr),
-			})
-		}
+func (h *Handler) CreateOrganizationSetting(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := h.authorizeOrganization(w, r)
+	if !ok {
+		return
+	}
+
+	// The category is implied by the endpoint; the usecase enforces it
+	req := &settingsDomain.CreateSettingRequest{Category: string(settingsDomain.SettingCategoryOrganization)}
+	if !httputil.DecodeInto(w, r, req) {
+		return
 	}
-	return details
+
+	setting, err := h.settingsUsecase.CreateOrganizationSetting(r.Context(), orgID, req)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.Created(w, response.CodeCreated, "Setting created successfully", setting)
 }
 
-func (h *Handler) getValidationErrorMessage(fieldErr validator.FieldError) string {
-	switch fieldErr.Tag() {
-	case "required":
-		return fieldErr.Field() + " is required"
-	case "min":
-		return fieldErr.Field() + " must be at least " + fieldErr.Param() + " characters"
-	case "max":
-		return fieldErr.Field() + " must be at most " + fieldErr.Param() + " characters"
-	default:
-		return fieldErr.Field() + " is invalid"
+func (h *Handler) UpdateOrganizationSetting(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := h.authorizeOrganization(w, r)
+	if !ok {
+		return
 	}
+
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	req, ok := httputil.Decode[settingsDomain.UpdateSettingRequest](w, r)
+	if !ok {
+		return
+	}
+
+	setting, err := h.settingsUsecase.UpdateOrganizationSetting(r.Context(), orgID, id, req)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Setting updated successfully", setting)
+}
+
+func (h *Handler) DeleteOrganizationSetting(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := h.authorizeOrganization(w, r)
+	if !ok {
+		return
+	}
+
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.settingsUsecase.DeleteOrganizationSetting(r.Context(), orgID, id); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Setting deleted successfully", nil)
+}
+
+// authorizeOrganization ensures the caller belongs to the organization in the
+// path. Changing its settings also takes the organization_settings:write
+// permission, which the routes require.
+func (h *Handler) authorizeOrganization(w http.ResponseWriter, r *http.Request) (string, bool) {
+	orgID, ok := httputil.UUIDParam(w, r, "orgId")
+	if !ok {
+		return "", false
+	}
+
+	callerOrgID := auth.FromContext(r.Context()).OrganizationID
+	if callerOrgID != orgID {
+		response.Forbidden(w, response.CodeForbidden, "You can only manage settings of your own organization", nil)
+		return "", false
+	}
+
+	return orgID, true
+}
+
+// GetPublicConfig returns public site configuration for unauthenticated clients
+func (h *Handler) GetPublicConfig(w http.ResponseWriter, r *http.Request) {
+	config, err := h.settingsUsecase.GetPublicConfig(r.Context())
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	etag := fmt.Sprintf(`W/"%d-%d"`, config.UpdatedAt.UnixNano(), len(config.Settings))
+	w.Header().Set("Cache-Control", "public, max-age=300, stale-while-revalidate=600")
+	w.Header().Set("ETag", etag)
+
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Public configuration retrieved successfully", config)
+}
+
+// GetMaintenance returns the maintenance mode of the API
+func (h *Handler) GetMaintenance(w http.ResponseWriter, r *http.Request) {
+	maintenance, err := h.settingsUsecase.GetMaintenance(r.Context())
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Maintenance mode retrieved successfully", maintenance)
+}
+
+// UpdateMaintenance turns the maintenance mode of the API on or off
+func (h *Handler) UpdateMaintenance(w http.ResponseWriter, r *http.Request) {
+	req, ok := httputil.Decode[settingsDomain.UpdateMaintenanceRequest](w, r)
+	if !ok {
+		return
+	}
+
+	maintenance, err := h.settingsUsecase.UpdateMaintenance(r.Context(), req)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Maintenance mode updated successfully", maintenance)
+}
+
+// PurgeCache invalidates the cached responses tagged with the given
+// surrogate keys
+func (h *Handler) PurgeCache(w http.ResponseWriter, r *http.Request) {
+	req, ok := httputil.Decode[settingsDomain.PurgeCacheRequest](w, r)
+	if !ok {
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Cache purged successfully", h.settingsUsecase.PurgeCache(r.Context(), req))
+}
+
+// errorMapper maps the errors of the settings module on top of the shared ones
+var errorMapper = problem.Default.With(
+	problem.Mapping{Err: pkgErrors.ErrNotFound, Status: http.StatusNotFound, Code: response.CodeNotFound, Message: "Setting not found"},
+)
+
+func (h *Handler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	errorMapper.Write(w, r, err)
 }
 
 func parseIntQuery(r *http.Request, key string, defaultValue int) int {
@@ -253,15 +382,45 @@ func parseIntQuery(r *http.Request, key string, defaultValue int) int {
 	return defaultValue
 }
 
-func RegisterRoutes(r chi.Router, handler *Handler) {
+// RegisterRoutes registers settings routes. The public site configuration is
+// open to visitors; managing settings goes through auth, and the maintenance
+// mode and the response cache are managed by users with one of adminRoles.
+// Changing settings takes settings:write. Members of an organization read its
+// settings, and those whose role grants organization_settings:write change
+// them.
+func RegisterRoutes(r chi.Router, handler *Handler, auth func(http.Handler) http.Handler, adminRoles []string) {
+	r.Get("/public/config", handler.GetPublicConfig)
+
 	r.Route("/settings", func(r chi.Router) {
+		r.Use(auth)
+		write := middleware.RequirePermission(roleDomain.PermissionSettingsWrite)
 		r.Get("/", handler.List)
-		r.Post("/", handler.Create)
+		r.With(write).Post("/", handler.Create)
 		r.Get("/keys", handler.GetByKeys)
 		r.Get("/category/{category}", handler.GetByCategory)
+		r.Route("/organizations/{orgId}", func(r chi.Router) {
+			r.Get("/", handler.ListOrganizationSettings)
+			write := middleware.RequirePermission(roleDomain.PermissionOrganizationSettingsWrite)
+			r.With(write).Post("/", handler.CreateOrganizationSetting)
+			r.With(write).Put("/{id}", handler.UpdateOrganizationSetting)
+			r.With(write).Delete("/{id}", handler.DeleteOrganizationSetting)
+		})
 		r.Get("/key/{key}", handler.GetByKey)
 		r.Get("/{id}", handler.GetByID)
-		r.Put("/{id}", handler.Update)
-		r.Delete("/{id}", handler.Delete)
+		r.With(write).Put("/{id}", handler.Update)
+		r.With(write).Delete("/{id}", handler.Delete)
+	})
+
+	r.Route("/admin/maintenance", func(r chi.Router) {
+		r.Use(auth)
+		r.Use(middleware.RequireRole(adminRoles...))
+		r.Get("/", handler.GetMaintenance)
+		r.Put("/", handler.UpdateMaintenance)
+	})
+
+	r.Route("/admin/cache", func(r chi.Router) {
+		r.Use(auth)
+		r.Use(middleware.RequireRole(adminRoles...))
+		r.Post("/purge", handler.PurgeCache)
 	})
 }
@@ -0,0 +1,83 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"portal-data-backend/infrastructure/auth"
+	"portal-data-backend/infrastructure/http/middleware"
+	roleDomain "portal-data-backend/internal/role/domain"
+	delivery "portal-data-backend/internal/settings/delivery/http"
+	"portal-data-backend/internal/settings/domain"
+	"portal-data-backend/internal/settings/usecase"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// mockUsecase creates organization settings; the other methods are not
+// reached by the test
+type mockUsecase struct {
+	usecase.Usecase
+	created int
+}
+
+func (m *mockUsecase) CreateOrganizationSetting(ctx context.Context, orgID string, req *domain.CreateSettingRequest) (*domain.SettingInfo, error) {
+	m.created++
+	return &domain.SettingInfo{Key: req.Key, Value: req.Value}, nil
+}
+
+const orgID = "6f1c2a8e-3b7d-4c5e-9a1f-2d3e4f5a6b7c"
+
+// Test only members of the organization whose role grants
+// organization_settings:write create its settings
+func TestOrganizationSettings_RequirePermission(t *testing.T) {
+	settings := &mockUsecase{}
+	// The tests sign in as the organization and role of their headers
+	signIn := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims := auth.Claims{UserID: "user-1", OrganizationID: r.Header.Get("X-Org"), RoleID: r.Header.Get("X-Role")}
+			next.ServeHTTP(w, r.WithContext(auth.WithClaims(r.Context(), claims)))
+		})
+	}
+	lookup := func(ctx context.Context, roleID string) ([]string, error) {
+		if roleID == "org-admin" {
+			return []string{roleDomain.PermissionOrganizationSettingsWrite}, nil
+		}
+		return nil, nil
+	}
+
+	r := chi.NewRouter()
+	r.Use(middleware.Permissions(lookup, "admin"))
+	delivery.RegisterRoutes(r, delivery.NewHandler(settings), signIn, []string{"admin"})
+
+	tests := []struct {
+		name   string
+		org    string
+		role   string
+		status int
+	}{
+		{"member", orgID, "member", http.StatusForbidden},
+		{"org admin", orgID, "org-admin", http.StatusCreated},
+		{"org admin of another organization", "0d9e8f7a-6b5c-4d3e-8f2a-1b0c9d8e7f6a", "org-admin", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := strings.NewReader(`{"key": "theme", "value": "dark", "type": "string", "category": "appearance"}`)
+			req := httptest.NewRequest(http.MethodPost, "/settings/organizations/"+orgID+"/", body)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Org", tt.org)
+			req.Header.Set("X-Role", tt.role)
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Errorf("Expected %d, got %d: %s", tt.status, rec.Code, rec.Body.String())
+			}
+		})
+	}
+	if settings.created != 1 {
+		t.Errorf("Expected the org admin alone to create a setting, got %d created", settings.created)
+	}
+}
@@ -0,0 +1,37 @@
+package http
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	settingsDomain "portal-data-backend/internal/settings/domain"
+)
+
+// Describe adds the settings routes to the API specification
+func Describe(spec *openapi.Spec) {
+	api := spec.Tag("settings", "Site, user and organization settings")
+	api.Get("/settings", "List settings").Query(settingsDomain.ListSettingsRequest{}).Returns(http.StatusOK, settingsDomain.SettingListResponse{})
+	api.Post("/settings", "Create setting").Body(settingsDomain.CreateSettingRequest{}).Returns(http.StatusCreated, settingsDomain.SettingInfo{})
+	api.Get("/settings/keys", "Get setting values by key").
+		Param("keys", true).Param("user_id", false).Param("organization_id", false).
+		Returns(http.StatusOK, map[string]map[string]string{})
+	api.Get("/settings/category/{category}", "List settings of a category").
+		Query(settingsDomain.ListSettingsRequest{}, "page", "limit", "user_id").
+		Returns(http.StatusOK, settingsDomain.SettingListResponse{})
+	api.Get("/settings/organizations/{orgId}", "List organization settings").
+		Query(settingsDomain.ListSettingsRequest{}, "page", "limit", "search", "type").
+		Returns(http.StatusOK, settingsDomain.SettingListResponse{})
+	api.Post("/settings/organizations/{orgId}", "Create organization setting").Body(settingsDomain.CreateSettingRequest{}).Returns(http.StatusCreated, settingsDomain.SettingInfo{})
+	api.Put("/settings/organizations/{orgId}/{id}", "Update organization setting").Body(settingsDomain.UpdateSettingRequest{}).Returns(http.StatusOK, settingsDomain.SettingInfo{})
+	api.Delete("/settings/organizations/{orgId}/{id}", "Delete organization setting").Returns(http.StatusOK, nil)
+	api.Get("/settings/key/{key}", "Get setting by key").Param("user_id", false).Returns(http.StatusOK, settingsDomain.SettingInfo{})
+	api.Get("/settings/{id}", "Get setting").Returns(http.StatusOK, settingsDomain.SettingInfo{})
+	api.Put("/settings/{id}", "Update setting").Body(settingsDomain.UpdateSettingRequest{}).Returns(http.StatusOK, settingsDomain.SettingInfo{})
+	api.Delete("/settings/{id}", "Delete setting").Returns(http.StatusOK, nil)
+
+	api.Get("/admin/maintenance", "Get the maintenance mode").Returns(http.StatusOK, settingsDomain.Maintenance{})
+	api.Put("/admin/maintenance", "Turn the maintenance mode on or off").Body(settingsDomain.UpdateMaintenanceRequest{}).Returns(http.StatusOK, settingsDomain.Maintenance{})
+	api.Post("/admin/cache/purge", "Purge cached responses by surrogate key").Body(settingsDomain.PurgeCacheRequest{}).Returns(http.StatusOK, settingsDomain.PurgeCacheResponse{})
+
+	api.Get("/public/config", "Get public site configuration").Public().Returns(http.StatusOK, settingsDomain.PublicConfigResponse{})
+}
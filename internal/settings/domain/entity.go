@@ -4,16 +4,19 @@ import "time"
 
 // Setting represents a system or user setting
 type Setting struct {
-	ID        string     `db:"id" json:"id"`
-	Key       string     `db:"key" json:"key"`
-	Value     string     `db:"value" json:"value"`
-	Type      string     `db:"type" json:"type"` // string, number, boolean, json
-	Category  string     `db:"category" json:"category"` // system, user, organization
-	UserID    *string    `db:"user_id" json:"user_id,omitempty"`
-	IsPublic  bool       `db:"is_public" json:"is_public"`
-	CreatedAt time.Time  `db:"created_at" json:"created_at"`
-	UpdatedAt time.Time  `db:"updated_at" json:"updated_at"`
-	DeletedAt *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
+	ID             string     `db:"id" json:"id"`
+	Key            string     `db:"key" json:"key"`
+	Value          string     `db:"value" json:"value"`
+	Type           string     `db:"type" json:"type"`         // string, number, boolean, json
+	Category       string     `db:"category" json:"category"` // system, user, organization
+	UserID         *string    `db:"user_id" json:"user_id,omitempty"`
+	OrganizationID *string    `db:"organization_id" json:"organization_id,omitempty"`
+	IsPublic       bool       `db:"is_public" json:"is_public"`
+	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time  `db:"updated_at" json:"updated_at"`
+	DeletedAt      *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
+	// TenantID is the portal the setting applies to, nil for every portal
+	TenantID *string `db:"tenant_id" json:"tenant_id,omitempty"`
 }
 
 // SettingType represents setting data type
@@ -37,12 +40,13 @@ const (
 
 // ListSettingsRequest represents list settings input
 type ListSettingsRequest struct {
-	Page     int     `json:"page" validate:"min=1"`
-	Limit    int     `json:"limit" validate:"min=1,max=100"`
-	Category *string `json:"category,omitempty"`
-	UserID   *string `json:"user_id,omitempty"`
-	Type     *string `json:"type,omitempty"`
-	Search   string  `json:"search,omitempty"`
+	Page           int     `json:"page" validate:"min=1"`
+	Limit          int     `json:"limit" validate:"min=1,max=100"`
+	Category       *string `json:"category,omitempty"`
+	UserID         *string `json:"user_id,omitempty"`
+	OrganizationID *string `json:"organization_id,omitempty"`
+	Type           *string `json:"type,omitempty"`
+	Search         string  `json:"search,omitempty"`
 }
 
 // CreateSettingRequest represents create setting input
@@ -53,6 +57,9 @@ type CreateSettingRequest struct {
 	Category string `json:"category" validate:"required"`
 	UserID   *string `json:"user_id,omitempty"`
 	IsPublic bool   `json:"is_public"`
+
+	// OrganizationID is set by the organization settings endpoints, not the client
+	OrganizationID *string `json:"-"`
 }
 
 // UpdateSettingRequest represents update setting input
@@ -64,15 +71,16 @@ type UpdateSettingRequest struct {
 
 // SettingInfo represents setting information for API responses
 type SettingInfo struct {
-	ID        string    `json:"id"`
-	Key       string    `json:"key"`
-	Value     string    `json:"value"`
-	Type      string    `json:"type"`
-	Category  string    `json:"category"`
-	UserID    *string   `json:"user_id,omitempty"`
-	IsPublic  bool      `json:"is_public"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID             string    `json:"id"`
+	Key            string    `json:"key"`
+	Value          string    `json:"value"`
+	Type           string    `json:"type"`
+	Category       string    `json:"category"`
+	UserID         *string   `json:"user_id,omitempty"`
+	OrganizationID *string   `json:"organization_id,omitempty"`
+	IsPublic       bool      `json:"is_public"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
 }
 
 // SettingListResponse represents paginated setting list
@@ -93,3 +101,45 @@ type ListMeta struct {
 type GetSettingsByKeysResponse struct {
 	Settings map[string]string `json:"settings"`
 }
+
+// PublicConfigResponse represents the public site configuration
+type PublicConfigResponse struct {
+	Settings  map[string]interface{} `json:"settings"`
+	UpdatedAt time.Time              `json:"updated_at"`
+}
+
+// MaintenanceKey is the key of the global setting holding the maintenance
+// mode of the API
+const MaintenanceKey = "maintenance"
+
+// DefaultMaintenanceRetryAfter is how many seconds clients are told to wait
+// when maintenance is turned on without saying
+const DefaultMaintenanceRetryAfter = 300
+
+// Maintenance is the maintenance mode of the API. While it is enabled, only
+// admins, sign-ins and health checks are served; other requests answer 503.
+type Maintenance struct {
+	Enabled bool   `json:"enabled"`
+	Message string `json:"message,omitempty"`
+	// RetryAfter is how many seconds clients are told to wait
+	RetryAfter int       `json:"retry_after"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// UpdateMaintenanceRequest represents update maintenance mode input
+type UpdateMaintenanceRequest struct {
+	Enabled    bool   `json:"enabled"`
+	Message    string `json:"message" validate:"max=500"`
+	RetryAfter int    `json:"retry_after" validate:"min=0,max=86400"`
+}
+
+// PurgeCacheRequest names the surrogate keys of the cached responses to
+// purge, like "datasets", "dataset:<id>" or "all"
+type PurgeCacheRequest struct {
+	Keys []string `json:"keys" validate:"required,min=1,max=100,dive,required,max=255"`
+}
+
+// PurgeCacheResponse lists the surrogate keys purged
+type PurgeCacheResponse struct {
+	Keys []string `json:"keys"`
+}
@@ -11,13 +11,15 @@ type Repository interface {
 	Create(ctx context.Context, setting *Setting) error
 	Update(ctx context.Context, id string, setting *Setting) error
 	Delete(ctx context.Context, id string) error
-	GetByKeys(ctx context.Context, keys []string, userID *string) (map[string]string, error)
+	GetByKeys(ctx context.Context, keys []string, userID, orgID *string) (map[string]string, error)
 	GetByCategory(ctx context.Context, category string, userID *string, limit, offset int) ([]*Setting, int, error)
+	GetPublic(ctx context.Context) ([]*Setting, error)
 }
 
 type SettingFilter struct {
-	Category *string
-	UserID   *string
-	Type     *string
-	Search   string
+	Category       *string
+	UserID         *string
+	OrganizationID *string
+	Type           *string
+	Search         string
 }
@@ -0,0 +1,62 @@
+// Package settings is the module managing site and organization settings.
+package settings
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"portal-data-backend/infrastructure/db"
+	"portal-data-backend/infrastructure/http/openapi"
+	"portal-data-backend/internal/app"
+	delivery "portal-data-backend/internal/settings/delivery/http"
+	"portal-data-backend/internal/settings/repository"
+	"portal-data-backend/internal/settings/usecase"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jmoiron/sqlx"
+)
+
+// Module manages settings and serves the public site configuration
+type Module struct {
+	handler    *delivery.Handler
+	db         *sqlx.DB
+	adminRoles []string
+}
+
+// Name implements app.Module
+func (m *Module) Name() string {
+	return "settings"
+}
+
+// Register implements app.Module
+func (m *Module) Register(deps *app.Deps) error {
+	m.db = deps.DB
+	repo := repository.NewSettingsPostgresRepository(deps.DB)
+	settings := usecase.NewSettingsUsecase(
+		repo,
+		deps.Cache.Namespace("settings", deps.Config.Cache.SettingsTTL),
+		deps.Cache.Namespace("maintenance", deps.Config.Cache.MaintenanceTTL),
+		deps.Cache.Surrogates(deps.Config.Cache.ResponseTTL),
+		deps.Services.Audit,
+	)
+	deps.Services.Settings = settings
+	m.handler = delivery.NewHandler(settings)
+	m.adminRoles = deps.Config.Audit.AdminRoles
+	return nil
+}
+
+// Routes implements app.Module
+func (m *Module) Routes(r chi.Router, auth func(http.Handler) http.Handler) {
+	delivery.RegisterRoutes(r, m.handler, auth, m.adminRoles)
+}
+
+// Describe implements app.Module
+func (m *Module) Describe(spec *openapi.Spec) {
+	delivery.Describe(spec)
+}
+
+// Purge implements app.Purger
+func (m *Module) Purge(ctx context.Context, before time.Time) (int64, error) {
+	return db.PurgeDeleted(ctx, m.db, "settings", before)
+}
@@ -8,6 +8,9 @@ import (
 	"time"
 
 	settingsDomain "portal-data-backend/internal/settings/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"portal-data-backend/infrastructure/db"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -22,13 +25,13 @@ func NewSettingsPostgresRepository(db *sqlx.DB) settingsDomain.Repository {
 
 func (r *settingsPostgresRepository) GetByID(ctx context.Context, id string) (*settingsDomain.Setting, error) {
 	query := `
-		SELECT id, key, value, type, category, user_id, is_public, created_at, updated_at, deleted_at
+		SELECT id, key, value, type, category, user_id, organization_id, is_public, created_at, updated_at, deleted_at, tenant_id
 		FROM settings
 		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	var setting settingsDomain.Setting
-	err := r.db.GetContext(ctx, &setting, query, id)
+	err := db.Conn(ctx, r.db).GetContext(ctx, &setting, query, id)
 	if err != nil {
 		return nil, r.handleError(err)
 	}
@@ -37,7 +40,7 @@ func (r *settingsPostgresRepository) GetByID(ctx context.Context, id string) (*s
 
 func (r *settingsPostgresRepository) GetByKey(ctx context.Context, key string, userID *string) (*settingsDomain.Setting, error) {
 	query := `
-		SELECT id, key, value, type, category, user_id, is_public, created_at, updated_at, deleted_at
+		SELECT id, key, value, type, category, user_id, organization_id, is_public, created_at, updated_at, deleted_at, tenant_id
 		FROM settings
 		WHERE key = $1 AND deleted_at IS NULL
 	`
@@ -50,11 +53,11 @@ func (r *settingsPostgresRepository) GetByKey(ctx context.Context, key string, u
 		args = append(args, userID)
 		argCount++
 	} else {
-		query += " AND user_id IS NULL"
+		query += " AND user_id IS NULL AND organization_id IS NULL"
 	}
 
 	var setting settingsDomain.Setting
-	err := r.db.GetContext(ctx, &setting, query, args...)
+	err := db.Conn(ctx, r.db).GetContext(ctx, &setting, query, args...)
 	if err != nil {
 		return nil, r.handleError(err)
 	}
@@ -62,7 +65,7 @@ func (r *settingsPostgresRepository) GetByKey(ctx context.Context, key string, u
 }
 
 func (r *settingsPostgresRepository) List(ctx context.Context, filter *settingsDomain.SettingFilter, limit, offset int) ([]*settingsDomain.Setting, int, error) {
-	whereClause := "WHERE deleted_at IS NULL"
+	whereClause := "WHERE deleted_at IS NULL AND " + db.InTenantOrGlobal(ctx, "tenant_id")
 	args := []interface{}{}
 	argCount := 1
 
@@ -77,6 +80,11 @@ func (r *settingsPostgresRepository) List(ctx context.Context, filter *settingsD
 			args = append(args, filter.UserID)
 			argCount++
 		}
+		if filter.OrganizationID != nil {
+			whereClause += fmt.Sprintf(" AND organization_id = $%d", argCount)
+			args = append(args, filter.OrganizationID)
+			argCount++
+		}
 		if filter.Type != nil {
 			whereClause += fmt.Sprintf(" AND type = $%d", argCount)
 			args = append(args, filter.Type)
@@ -92,20 +100,20 @@ func (r *settingsPostgresRepository) List(ctx context.Context, filter *settingsD
 
 	countQuery := "SELECT COUNT(*) FROM settings " + whereClause
 	var total int
-	err := r.db.GetContext(ctx, &total, countQuery, args...)
+	err := db.Conn(ctx, r.db).GetContext(ctx, &total, countQuery, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count settings: %w", err)
 	}
 
 	query := `
-		SELECT id, key, value, type, category, user_id, is_public, created_at, updated_at, deleted_at
+		SELECT id, key, value, type, category, user_id, organization_id, is_public, created_at, updated_at, deleted_at, tenant_id
 		FROM settings
 	` + whereClause + " ORDER BY key ASC LIMIT $" + fmt.Sprintf("%d", argCount) + " OFFSET $" + fmt.Sprintf("%d", argCount+1)
 
 	args = append(args, limit, offset)
 
 	var settings []*settingsDomain.Setting
-	err = r.db.SelectContext(ctx, &settings, query, args...)
+	err = db.Conn(ctx, r.db).SelectContext(ctx, &settings, query, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to list settings: %w", err)
 	}
@@ -115,11 +123,11 @@ func (r *settingsPostgresRepository) List(ctx context.Context, filter *settingsD
 
 func (r *settingsPostgresRepository) Create(ctx context.Context, setting *settingsDomain.Setting) error {
 	query := `
-		INSERT INTO settings (id, key, value, type, category, user_id, is_public, created_at, updated_at)
-		VALUES (:id, :key, :value, :type, :category, :user_id, :is_public, :created_at, :updated_at)
+		INSERT INTO settings (id, key, value, type, category, user_id, organization_id, is_public, created_at, updated_at, tenant_id)
+		VALUES (:id, :key, :value, :type, :category, :user_id, :organization_id, :is_public, :created_at, :updated_at, :tenant_id)
 	`
 
-	_, err := r.db.NamedExecContext(ctx, query, setting)
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, setting)
 	if err != nil {
 		return fmt.Errorf("failed to create setting: %w", err)
 	}
@@ -134,7 +142,7 @@ func (r *settingsPostgresRepository) Update(ctx context.Context, id string, sett
 	`
 
 	setting.ID = id
-	_, err := r.db.NamedExecContext(ctx, query, setting)
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, setting)
 	if err != nil {
 		return fmt.Errorf("failed to update setting: %w", err)
 	}
@@ -143,52 +151,97 @@ func (r *settingsPostgresRepository) Update(ctx context.Context, id string, sett
 
 func (r *settingsPostgresRepository) Delete(ctx context.Context, id string) error {
 	query := `UPDATE settings SET deleted_at = $1 WHERE id = $2`
-	_, err := r.db.ExecContext(ctx, query, time.Now(), id)
+	_, err := db.Conn(ctx, r.db).ExecContext(ctx, query, time.Now(), id)
 	if err != nil {
 		return fmt.Errorf("failed to delete setting: %w", err)
 	}
 	return nil
 }
 
-func (r *settingsPostgresRepository) GetByKeys(ctx context.Context, keys []string, userID *string) (map[string]string, error) {
+func (r *settingsPostgresRepository) GetByKeys(ctx context.Context, keys []string, userID, orgID *string) (map[string]string, error) {
 	if len(keys) == 0 {
 		return make(map[string]string), nil
 	}
 
+	query, args, err := buildGetByKeysQuery(keys, userID, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build settings query: %w", err)
+	}
+
+	var rows []scopedSetting
+	err = db.Conn(ctx, r.db).SelectContext(ctx, &rows, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get settings by keys: %w", err)
+	}
+
+	return mergeScopedSettings(rows), nil
+}
+
+// scopedSetting is a key/value row together with the scope it belongs to
+type scopedSetting struct {
+	Key            string  `db:"key"`
+	Value          string  `db:"value"`
+	UserID         *string `db:"user_id"`
+	OrganizationID *string `db:"organization_id"`
+}
+
+// precedence ranks a row by scope: user > organization > global
+func (s scopedSetting) precedence() int {
+	switch {
+	case s.UserID != nil:
+		return 2
+	case s.OrganizationID != nil:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// buildGetByKeysQuery builds the lookup for a set of keys. Global settings are
+// always included; the organization's and the user's own settings are included
+// when their IDs are given.
+func buildGetByKeysQuery(keys []string, userID, orgID *string) (string, []interface{}, error) {
 	query := `
-		SELECT key, value
+		SELECT key, value, user_id, organization_id
 		FROM settings
 		WHERE deleted_at IS NULL AND key IN (?)
 	`
+	args := []interface{}{keys}
 
-	args := []interface{}{userID}
-	query, args, _ = sqlx.In(query, keys)
-
+	scopes := []string{"(user_id IS NULL AND organization_id IS NULL)"}
+	if orgID != nil {
+		scopes = append(scopes, "(user_id IS NULL AND organization_id = ?)")
+		args = append(args, *orgID)
+	}
 	if userID != nil {
-		query = strings.Replace(query, "?", "$1", 1)
-		query += " AND (user_id = $2 OR user_id IS NULL)"
-		args = append([]interface{}{userID}, args...)
-	} else {
-		query += " AND user_id IS NULL"
+		scopes = append(scopes, "user_id = ?")
+		args = append(args, *userID)
 	}
+	query += " AND (" + strings.Join(scopes, " OR ") + ")"
 
-	query = r.db.Rebind(query)
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	query, args, err := sqlx.In(query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get settings by keys: %w", err)
+		return "", nil, err
 	}
-	defer rows.Close()
 
-	result := make(map[string]string)
-	for rows.Next() {
-		var key, value string
-		if err := rows.Scan(&key, &value); err != nil {
-			return nil, fmt.Errorf("failed to scan setting: %w", err)
+	return sqlx.Rebind(sqlx.DOLLAR, query), args, nil
+}
+
+// mergeScopedSettings flattens rows into a key/value map where the most
+// specific scope wins for each key, regardless of row order.
+func mergeScopedSettings(rows []scopedSetting) map[string]string {
+	result := make(map[string]string, len(rows))
+	rank := make(map[string]int, len(rows))
+
+	for _, row := range rows {
+		if current, ok := rank[row.Key]; ok && current > row.precedence() {
+			continue
 		}
-		result[key] = value
+		result[row.Key] = row.Value
+		rank[row.Key] = row.precedence()
 	}
 
-	return result, nil
+	return result
 }
 
 func (r *settingsPostgresRepository) GetByCategory(ctx context.Context, category string, userID *string, limit, offset int) ([]*settingsDomain.Setting, int, error) {
@@ -201,25 +254,25 @@ func (r *settingsPostgresRepository) GetByCategory(ctx context.Context, category
 		args = append(args, userID)
 		argCount++
 	} else {
-		whereClause += " AND user_id IS NULL"
+		whereClause += " AND user_id IS NULL AND organization_id IS NULL"
 	}
 
 	countQuery := "SELECT COUNT(*) FROM settings " + whereClause
 	var total int
-	err := r.db.GetContext(ctx, &total, countQuery, args...)
+	err := db.Conn(ctx, r.db).GetContext(ctx, &total, countQuery, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count settings: %w", err)
 	}
 
 	query := `
-		SELECT id, key, value, type, category, user_id, is_public, created_at, updated_at, deleted_at
+		SELECT id, key, value, type, category, user_id, organization_id, is_public, created_at, updated_at, deleted_at, tenant_id
 		FROM settings
 	` + whereClause + " ORDER BY key ASC LIMIT $" + fmt.Sprintf("%d", argCount) + " OFFSET $" + fmt.Sprintf("%d", argCount+1)
 
 	args = append(args, limit, offset)
 
 	var settings []*settingsDomain.Setting
-	err = r.db.SelectContext(ctx, &settings, query, args...)
+	err = db.Conn(ctx, r.db).SelectContext(ctx, &settings, query, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to get settings by category: %w", err)
 	}
@@ -227,12 +280,30 @@ func (r *settingsPostgresRepository) GetByCategory(ctx context.Context, category
 	return settings, total, nil
 }
 
+func (r *settingsPostgresRepository) GetPublic(ctx context.Context) ([]*settingsDomain.Setting, error) {
+	query := `
+		SELECT id, key, value, type, category, user_id, organization_id, is_public, created_at, updated_at, deleted_at, tenant_id
+		FROM settings
+		WHERE deleted_at IS NULL AND is_public = true AND user_id IS NULL AND organization_id IS NULL
+		  AND ` + db.InTenantOrGlobal(ctx, "tenant_id") + `
+		ORDER BY key ASC, tenant_id NULLS FIRST
+	`
+
+	var settings []*settingsDomain.Setting
+	err := db.Conn(ctx, r.db).SelectContext(ctx, &settings, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get public settings: %w", err)
+	}
+
+	return settings, nil
+}
+
 func (r *settingsPostgresRepository) handleError(err error) error {
 	if err == nil {
 		return nil
 	}
 	if err == sql.ErrNoRows {
-		return fmt.Errorf("setting not found")
+		return fmt.Errorf("setting not found: %w", pkgErrors.ErrNotFound)
 	}
 	return fmt.Errorf("database error: %w", err)
 }
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func newMockRepository(t *testing.T) (*settingsPostgresRepository, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return &settingsPostgresRepository{db: sqlx.NewDb(db, "postgres")}, mock
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+// Test GetByKeys with no keys does not hit the database
+func TestGetByKeys_EmptyKeys(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	result, err := repo.GetByKeys(context.Background(), nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(result) != 0 {
+		t.Errorf("Expected empty result, got %v", result)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unexpected database calls: %v", err)
+	}
+}
+
+// Test GetByKeys without a user only reads global settings
+func TestGetByKeys_GlobalOnly(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta("key IN ($1, $2) AND ((user_id IS NULL AND organization_id IS NULL))")).
+		WithArgs("site_title", "contact_email").
+		WillReturnRows(sqlmock.NewRows([]string{"key", "value", "user_id", "organization_id"}).
+			AddRow("site_title", "Portal Data", nil, nil).
+			AddRow("contact_email", "info@example.com", nil, nil))
+
+	result, err := repo.GetByKeys(context.Background(), []string{"site_title", "contact_email"}, nil, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result["site_title"] != "Portal Data" {
+		t.Errorf("Expected site_title 'Portal Data', got %q", result["site_title"])
+	}
+	if result["contact_email"] != "info@example.com" {
+		t.Errorf("Expected contact_email 'info@example.com', got %q", result["contact_email"])
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
+
+// Test GetByKeys with a user lets user settings shadow global ones
+func TestGetByKeys_UserOverridesGlobal(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	userID := "user-1"
+
+	mock.ExpectQuery(regexp.QuoteMeta("key IN ($1, $2) AND ((user_id IS NULL AND organization_id IS NULL) OR user_id = $3)")).
+		WithArgs("theme", "language", userID).
+		WillReturnRows(sqlmock.NewRows([]string{"key", "value", "user_id", "organization_id"}).
+			AddRow("theme", "dark", userID, nil).
+			AddRow("theme", "light", nil, nil).
+			AddRow("language", "id", nil, nil))
+
+	result, err := repo.GetByKeys(context.Background(), []string{"theme", "language"}, strPtr(userID), nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result["theme"] != "dark" {
+		t.Errorf("Expected user theme 'dark' to win, got %q", result["theme"])
+	}
+	if result["language"] != "id" {
+		t.Errorf("Expected global language 'id', got %q", result["language"])
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
+
+// Test GetByKeys resolves user > organization > global
+func TestGetByKeys_OrganizationPrecedence(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	userID := "user-1"
+	orgID := "org-1"
+
+	mock.ExpectQuery(regexp.QuoteMeta("((user_id IS NULL AND organization_id IS NULL) OR (user_id IS NULL AND organization_id = $4) OR user_id = $5)")).
+		WithArgs("theme", "language", "logo", orgID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"key", "value", "user_id", "organization_id"}).
+			AddRow("theme", "light", nil, nil).
+			AddRow("theme", "blue", nil, orgID).
+			AddRow("theme", "dark", userID, nil).
+			AddRow("language", "en", nil, orgID).
+			AddRow("language", "id", nil, nil).
+			AddRow("logo", "global.png", nil, nil))
+
+	result, err := repo.GetByKeys(context.Background(), []string{"theme", "language", "logo"}, strPtr(userID), strPtr(orgID))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result["theme"] != "dark" {
+		t.Errorf("Expected user theme 'dark', got %q", result["theme"])
+	}
+	if result["language"] != "en" {
+		t.Errorf("Expected organization language 'en', got %q", result["language"])
+	}
+	if result["logo"] != "global.png" {
+		t.Errorf("Expected global logo 'global.png', got %q", result["logo"])
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
+
+// Test mergeScopedSettings is independent of row order
+func TestMergeScopedSettings_OrderIndependent(t *testing.T) {
+	rows := []scopedSetting{
+		{Key: "theme", Value: "light"},
+		{Key: "theme", Value: "dark", UserID: strPtr("user-1")},
+		{Key: "theme", Value: "blue", OrganizationID: strPtr("org-1")},
+		{Key: "page_size", Value: "20"},
+	}
+
+	forward := mergeScopedSettings(rows)
+	reversed := mergeScopedSettings([]scopedSetting{rows[3], rows[2], rows[1], rows[0]})
+
+	for _, result := range []map[string]string{forward, reversed} {
+		if result["theme"] != "dark" {
+			t.Errorf("Expected theme 'dark', got %q", result["theme"])
+		}
+		if result["page_size"] != "20" {
+			t.Errorf("Expected page_size '20', got %q", result["page_size"])
+		}
+	}
+}
@@ -2,11 +2,18 @@ package usecase
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
+	"strconv"
 	"time"
 
+	"portal-data-backend/infrastructure/audit"
+	"portal-data-backend/infrastructure/cache"
+	"portal-data-backend/infrastructure/tenant"
 	"portal-data-backend/internal/settings/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
 
 	"github.com/google/uuid"
 )
@@ -18,17 +25,45 @@ type Usecase interface {
 	Create(ctx context.Context, req *domain.CreateSettingRequest) (*domain.SettingInfo, error)
 	Update(ctx context.Context, id string, req *domain.UpdateSettingRequest) (*domain.SettingInfo, error)
 	Delete(ctx context.Context, id string) error
-	GetByKeys(ctx context.Context, keys []string, userID *string) (map[string]string, error)
+	GetByKeys(ctx context.Context, keys []string, userID, orgID *string) (map[string]string, error)
 	GetByCategory(ctx context.Context, category string, userID *string, page, limit int) (*domain.SettingListResponse, error)
+	GetPublicConfig(ctx context.Context) (*domain.PublicConfigResponse, error)
+
+	// Organization-scoped settings management
+	ListOrganizationSettings(ctx context.Context, orgID string, req *domain.ListSettingsRequest) (*domain.SettingListResponse, error)
+	CreateOrganizationSetting(ctx context.Context, orgID string, req *domain.CreateSettingRequest) (*domain.SettingInfo, error)
+	UpdateOrganizationSetting(ctx context.Context, orgID, id string, req *domain.UpdateSettingRequest) (*domain.SettingInfo, error)
+	DeleteOrganizationSetting(ctx context.Context, orgID, id string) error
+
+	// Maintenance mode of the API, shared by every portal
+	GetMaintenance(ctx context.Context) (*domain.Maintenance, error)
+	UpdateMaintenance(ctx context.Context, req *domain.UpdateMaintenanceRequest) (*domain.Maintenance, error)
+
+	// Purge of the cached responses of the portal by surrogate key
+	PurgeCache(ctx context.Context, req *domain.PurgeCacheRequest) *domain.PurgeCacheResponse
 }
 
+// publicConfigKey is the cache key of the public configuration
+const publicConfigKey = "public"
+
 type settingsUsecase struct {
-	repo domain.Repository
+	repo        domain.Repository
+	cache       *cache.Namespace
+	maintenance *cache.Namespace
+	surrogates  *cache.Surrogates
+	audit       *audit.Recorder
 }
 
-func NewSettingsUsecase(repo domain.Repository) Usecase {
+// NewSettingsUsecase creates a new settings usecase. cache holds the public
+// configuration and maintenance the maintenance mode; either may be nil, as
+// may surrogates, which purges cached responses, and recorder.
+func NewSettingsUsecase(repo domain.Repository, cache, maintenance *cache.Namespace, surrogates *cache.Surrogates, recorder *audit.Recorder) Usecase {
 	return &settingsUsecase{
-		repo: repo,
+		repo:        repo,
+		cache:       cache,
+		maintenance: maintenance,
+		surrogates:  surrogates,
+		audit:       recorder,
 	}
 }
 
@@ -59,10 +94,11 @@ func (u *settingsUsecase) List(ctx context.Context, req *domain.ListSettingsRequ
 	offset := (req.Page - 1) * req.Limit
 
 	filter := &domain.SettingFilter{
-		Category: req.Category,
-		UserID:   req.UserID,
-		Type:     req.Type,
-		Search:   req.Search,
+		Category:       req.Category,
+		UserID:         req.UserID,
+		OrganizationID: req.OrganizationID,
+		Type:           req.Type,
+		Search:         req.Search,
 	}
 
 	settings, total, err := u.repo.List(ctx, filter, req.Limit, offset)
@@ -89,22 +125,32 @@ func (u *settingsUsecase) List(ctx context.Context, req *domain.ListSettingsRequ
 }
 
 func (u *settingsUsecase) Create(ctx context.Context, req *domain.CreateSettingRequest) (*domain.SettingInfo, error) {
+	if isMaintenance(req.Key, req.UserID, req.OrganizationID) {
+		return nil, errMaintenance
+	}
+
 	now := time.Now()
 	setting := &domain.Setting{
-		ID:        uuid.New().String(),
-		Key:       req.Key,
-		Value:     req.Value,
-		Type:      req.Type,
-		Category:  req.Category,
-		UserID:    req.UserID,
-		IsPublic:  req.IsPublic,
-		CreatedAt: now,
-		UpdatedAt: now,
+		ID:             uuid.New().String(),
+		Key:            req.Key,
+		Value:          req.Value,
+		Type:           req.Type,
+		Category:       req.Category,
+		UserID:         req.UserID,
+		OrganizationID: req.OrganizationID,
+		IsPublic:       req.IsPublic,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if id := tenant.ID(ctx); id != "" {
+		setting.TenantID = &id
 	}
 
 	if err := u.repo.Create(ctx, setting); err != nil {
 		return nil, fmt.Errorf("failed to create setting: %w", err)
 	}
+	u.invalidatePublicConfig(ctx)
+	u.audit.Record(ctx, "settings", setting.ID, audit.ActionCreate, nil, setting)
 
 	return u.toInfo(setting), nil
 }
@@ -114,6 +160,11 @@ func (u *settingsUsecase) Update(ctx context.Context, id string, req *domain.Upd
 	if err != nil {
 		return nil, fmt.Errorf("failed to get setting: %w", err)
 	}
+	if isMaintenance(existing.Key, existing.UserID, existing.OrganizationID) {
+		return nil, errMaintenance
+	}
+
+	before := *existing
 
 	// Update fields
 	if req.Value != nil {
@@ -130,19 +181,30 @@ func (u *settingsUsecase) Update(ctx context.Context, id string, req *domain.Upd
 	if err := u.repo.Update(ctx, id, existing); err != nil {
 		return nil, fmt.Errorf("failed to update setting: %w", err)
 	}
+	u.invalidatePublicConfig(ctx)
+	u.audit.Record(ctx, "settings", id, audit.ActionUpdate, &before, existing)
 
 	return u.toInfo(existing), nil
 }
 
 func (u *settingsUsecase) Delete(ctx context.Context, id string) error {
+	setting, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get setting: %w", err)
+	}
+	if isMaintenance(setting.Key, setting.UserID, setting.OrganizationID) {
+		return errMaintenance
+	}
 	if err := u.repo.Delete(ctx, id); err != nil {
 		return fmt.Errorf("failed to delete setting: %w", err)
 	}
+	u.invalidatePublicConfig(ctx)
+	u.audit.Record(ctx, "settings", id, audit.ActionDelete, setting, nil)
 	return nil
 }
 
-func (u *settingsUsecase) GetByKeys(ctx context.Context, keys []string, userID *string) (map[string]string, error) {
-	settings, err := u.repo.GetByKeys(ctx, keys, userID)
+func (u *settingsUsecase) GetByKeys(ctx context.Context, keys []string, userID, orgID *string) (map[string]string, error) {
+	settings, err := u.repo.GetByKeys(ctx, keys, userID, orgID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get settings: %w", err)
 	}
@@ -182,16 +244,206 @@ func (u *settingsUsecase) GetByCategory(ctx context.Context, category string, us
 	}, nil
 }
 
+func (u *settingsUsecase) ListOrganizationSettings(ctx context.Context, orgID string, req *domain.ListSettingsRequest) (*domain.SettingListResponse, error) {
+	req.OrganizationID = &orgID
+	req.UserID = nil
+	return u.List(ctx, req)
+}
+
+func (u *settingsUsecase) CreateOrganizationSetting(ctx context.Context, orgID string, req *domain.CreateSettingRequest) (*domain.SettingInfo, error) {
+	req.OrganizationID = &orgID
+	req.UserID = nil
+	req.Category = string(domain.SettingCategoryOrganization)
+	return u.Create(ctx, req)
+}
+
+func (u *settingsUsecase) UpdateOrganizationSetting(ctx context.Context, orgID, id string, req *domain.UpdateSettingRequest) (*domain.SettingInfo, error) {
+	if err := u.ensureOrganizationSetting(ctx, orgID, id); err != nil {
+		return nil, err
+	}
+	return u.Update(ctx, id, req)
+}
+
+func (u *settingsUsecase) DeleteOrganizationSetting(ctx context.Context, orgID, id string) error {
+	if err := u.ensureOrganizationSetting(ctx, orgID, id); err != nil {
+		return err
+	}
+	return u.Delete(ctx, id)
+}
+
+// ensureOrganizationSetting reports ErrNotFound for settings outside the
+// organization so other scopes cannot be probed through these endpoints
+func (u *settingsUsecase) ensureOrganizationSetting(ctx context.Context, orgID, id string) error {
+	setting, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get setting: %w", err)
+	}
+	if setting.OrganizationID == nil || *setting.OrganizationID != orgID || setting.UserID != nil {
+		return pkgErrors.ErrNotFound
+	}
+	return nil
+}
+
+// GetPublicConfig returns the global public settings keyed by name. The result
+// is cached until it expires or any setting is written.
+func (u *settingsUsecase) GetPublicConfig(ctx context.Context) (*domain.PublicConfigResponse, error) {
+	var cached domain.PublicConfigResponse
+	if u.cache.Get(ctx, publicConfigKey, &cached) {
+		return &cached, nil
+	}
+
+	settings, err := u.repo.GetPublic(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get public settings: %w", err)
+	}
+
+	config := &domain.PublicConfigResponse{
+		Settings: make(map[string]interface{}, len(settings)),
+	}
+	// Settings of the tenant come after the shared ones and override them
+	for _, setting := range settings {
+		config.Settings[setting.Key] = typedValue(setting)
+		if setting.UpdatedAt.After(config.UpdatedAt) {
+			config.UpdatedAt = setting.UpdatedAt
+		}
+	}
+
+	u.cache.Set(ctx, publicConfigKey, config)
+	return config, nil
+}
+
+// errMaintenance refuses writes of the maintenance row through the settings
+// endpoints, which are not kept to admins and do not refresh its cache
+var errMaintenance = fmt.Errorf("%w: the maintenance mode is changed through /admin/maintenance", pkgErrors.ErrForbidden)
+
+// isMaintenance reports whether a setting is the global row holding the
+// maintenance mode
+func isMaintenance(key string, userID, orgID *string) bool {
+	return key == domain.MaintenanceKey && userID == nil && orgID == nil
+}
+
+func (u *settingsUsecase) invalidatePublicConfig(ctx context.Context) {
+	u.cache.Delete(ctx, publicConfigKey)
+}
+
+// GetMaintenance returns the maintenance mode of the API, disabled until it is
+// first set. It is read by every request, so it is cached until it expires or
+// is updated.
+func (u *settingsUsecase) GetMaintenance(ctx context.Context) (*domain.Maintenance, error) {
+	// Maintenance applies to every portal
+	ctx = tenant.WithTenant(ctx, nil)
+
+	var cached domain.Maintenance
+	if u.maintenance.Get(ctx, domain.MaintenanceKey, &cached) {
+		return &cached, nil
+	}
+
+	maintenance := &domain.Maintenance{}
+	setting, err := u.repo.GetByKey(ctx, domain.MaintenanceKey, nil)
+	switch {
+	case errors.Is(err, pkgErrors.ErrNotFound):
+	case err != nil:
+		return nil, fmt.Errorf("failed to get maintenance mode: %w", err)
+	default:
+		if err := json.Unmarshal([]byte(setting.Value), maintenance); err != nil {
+			return nil, fmt.Errorf("failed to decode maintenance mode: %w", err)
+		}
+		maintenance.UpdatedAt = setting.UpdatedAt
+	}
+
+	u.maintenance.Set(ctx, domain.MaintenanceKey, maintenance)
+	return maintenance, nil
+}
+
+// UpdateMaintenance turns the maintenance mode of the API on or off, storing
+// it in the global maintenance setting
+func (u *settingsUsecase) UpdateMaintenance(ctx context.Context, req *domain.UpdateMaintenanceRequest) (*domain.Maintenance, error) {
+	ctx = tenant.WithTenant(ctx, nil)
+
+	if req.RetryAfter == 0 {
+		req.RetryAfter = domain.DefaultMaintenanceRetryAfter
+	}
+	value, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode maintenance mode: %w", err)
+	}
+
+	now := time.Now()
+	existing, err := u.repo.GetByKey(ctx, domain.MaintenanceKey, nil)
+	switch {
+	case errors.Is(err, pkgErrors.ErrNotFound):
+		setting := &domain.Setting{
+			ID:        uuid.New().String(),
+			Key:       domain.MaintenanceKey,
+			Value:     string(value),
+			Type:      string(domain.SettingTypeJSON),
+			Category:  string(domain.SettingCategorySystem),
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if err := u.repo.Create(ctx, setting); err != nil {
+			return nil, fmt.Errorf("failed to create maintenance mode: %w", err)
+		}
+		u.audit.Record(ctx, "settings", setting.ID, audit.ActionCreate, nil, setting)
+	case err != nil:
+		return nil, fmt.Errorf("failed to get maintenance mode: %w", err)
+	default:
+		before := *existing
+		existing.Value = string(value)
+		existing.UpdatedAt = now
+		if err := u.repo.Update(ctx, existing.ID, existing); err != nil {
+			return nil, fmt.Errorf("failed to update maintenance mode: %w", err)
+		}
+		u.audit.Record(ctx, "settings", existing.ID, audit.ActionUpdate, &before, existing)
+	}
+	u.maintenance.Delete(ctx, domain.MaintenanceKey)
+
+	return &domain.Maintenance{
+		Enabled:    req.Enabled,
+		Message:    req.Message,
+		RetryAfter: req.RetryAfter,
+		UpdatedAt:  now,
+	}, nil
+}
+
+// PurgeCache invalidates the cached responses tagged with any of the
+// surrogate keys of req, for the tenant of ctx
+func (u *settingsUsecase) PurgeCache(ctx context.Context, req *domain.PurgeCacheRequest) *domain.PurgeCacheResponse {
+	u.surrogates.Purge(ctx, req.Keys...)
+	return &domain.PurgeCacheResponse{Keys: req.Keys}
+}
+
+// typedValue decodes a stored setting value according to its declared type,
+// falling back to the raw string when the value does not parse.
+func typedValue(setting *domain.Setting) interface{} {
+	switch domain.SettingType(setting.Type) {
+	case domain.SettingTypeNumber:
+		if n, err := strconv.ParseFloat(setting.Value, 64); err == nil {
+			return n
+		}
+	case domain.SettingTypeBoolean:
+		if b, err := strconv.ParseBool(setting.Value); err == nil {
+			return b
+		}
+	case domain.SettingTypeJSON:
+		if json.Valid([]byte(setting.Value)) {
+			return json.RawMessage(setting.Value)
+		}
+	}
+	return setting.Value
+}
+
 func (u *settingsUsecase) toInfo(setting *domain.Setting) *domain.SettingInfo {
 	return &domain.SettingInfo{
-		ID:        setting.ID,
-		Key:       setting.Key,
-		Value:     setting.Value,
-		Type:      setting.Type,
-		Category:  setting.Category,
-		UserID:    setting.UserID,
-		IsPublic:  setting.IsPublic,
-		CreatedAt: setting.CreatedAt,
-		UpdatedAt: setting.UpdatedAt,
+		ID:             setting.ID,
+		Key:            setting.Key,
+		Value:          setting.Value,
+		Type:           setting.Type,
+		Category:       setting.Category,
+		UserID:         setting.UserID,
+		OrganizationID: setting.OrganizationID,
+		IsPublic:       setting.IsPublic,
+		CreatedAt:      setting.CreatedAt,
+		UpdatedAt:      setting.UpdatedAt,
 	}
 }
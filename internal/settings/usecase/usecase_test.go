@@ -0,0 +1,70 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"portal-data-backend/internal/settings/domain"
+	"portal-data-backend/internal/settings/usecase"
+	pkgErrors "portal-data-backend/pkg/errors"
+)
+
+// mockRepository holds settings by ID; the other methods are not reached by
+// the test
+type mockRepository struct {
+	domain.Repository
+	settings map[string]*domain.Setting
+	writes   int
+}
+
+func (m *mockRepository) GetByID(ctx context.Context, id string) (*domain.Setting, error) {
+	if setting, ok := m.settings[id]; ok {
+		return setting, nil
+	}
+	return nil, pkgErrors.ErrNotFound
+}
+
+func (m *mockRepository) Create(ctx context.Context, setting *domain.Setting) error {
+	m.writes++
+	return nil
+}
+
+func (m *mockRepository) Update(ctx context.Context, id string, setting *domain.Setting) error {
+	m.writes++
+	return nil
+}
+
+func (m *mockRepository) Delete(ctx context.Context, id string) error {
+	m.writes++
+	return nil
+}
+
+// Test the maintenance mode is not changed through the settings endpoints
+func TestSettings_RefuseMaintenance(t *testing.T) {
+	ctx := context.Background()
+	orgID := "org-1"
+	repo := &mockRepository{settings: map[string]*domain.Setting{
+		"maintenance": {ID: "maintenance", Key: domain.MaintenanceKey, Value: `{"enabled": false}`},
+		"org":         {ID: "org", Key: domain.MaintenanceKey, Value: "weekends", OrganizationID: &orgID},
+	}}
+	settings := usecase.NewSettingsUsecase(repo, nil, nil, nil, nil)
+
+	value := `{"enabled": true}`
+	if _, err := settings.Update(ctx, "maintenance", &domain.UpdateSettingRequest{Value: &value}); !errors.Is(err, pkgErrors.ErrForbidden) {
+		t.Errorf("Expected updating the maintenance mode to be forbidden, got %v", err)
+	}
+	if err := settings.Delete(ctx, "maintenance"); !errors.Is(err, pkgErrors.ErrForbidden) {
+		t.Errorf("Expected deleting the maintenance mode to be forbidden, got %v", err)
+	}
+	if _, err := settings.Create(ctx, &domain.CreateSettingRequest{Key: domain.MaintenanceKey, Value: value, Type: "json"}); !errors.Is(err, pkgErrors.ErrForbidden) {
+		t.Errorf("Expected creating the maintenance mode to be forbidden, got %v", err)
+	}
+	if repo.writes != 0 {
+		t.Fatalf("Expected no write, got %d", repo.writes)
+	}
+
+	if _, err := settings.Update(ctx, "org", &domain.UpdateSettingRequest{Value: &value}); err != nil {
+		t.Errorf("Expected a setting of the same key in an organization to be updated, got %v", err)
+	}
+}
@@ -0,0 +1,182 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"portal-data-backend/infrastructure/auth"
+	"portal-data-backend/infrastructure/http/httputil"
+	"portal-data-backend/infrastructure/http/middleware"
+	"portal-data-backend/infrastructure/http/problem"
+	"portal-data-backend/infrastructure/http/response"
+	roleDomain "portal-data-backend/internal/role/domain"
+	submissionDomain "portal-data-backend/internal/submission/domain"
+	"portal-data-backend/internal/submission/usecase"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	submissionUsecase usecase.Usecase
+	adminRoles        []string
+}
+
+// NewHandler creates the submission handler. Users with one of adminRoles
+// act on behalf of any organization.
+func NewHandler(submissionUsecase usecase.Usecase, adminRoles []string) *Handler {
+	return &Handler{
+		submissionUsecase: submissionUsecase,
+		adminRoles:        adminRoles,
+	}
+}
+
+// caller returns the signed-in user making the request
+func (h *Handler) caller(r *http.Request) submissionDomain.Caller {
+	user := auth.FromContext(r.Context())
+	return submissionDomain.Caller{
+		UserID:         user.UserID,
+		OrganizationID: user.OrganizationID,
+		Admin:          user.HasRole(h.adminRoles...),
+	}
+}
+
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	req, ok := httputil.Decode[submissionDomain.CreateSubmissionRequest](w, r)
+	if !ok {
+		return
+	}
+
+	submission, err := h.submissionUsecase.Submit(r.Context(), req, h.caller(r))
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.Created(w, response.CodeCreated, "Dataset submitted successfully", submission)
+}
+
+func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	submission, err := h.submissionUsecase.GetByID(r.Context(), id, h.caller(r))
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Submission retrieved successfully", submission)
+}
+
+// Inbox lists the submissions made to the organization of the signed-in user
+func (h *Handler) Inbox(w http.ResponseWriter, r *http.Request) {
+	h.list(w, r, h.submissionUsecase.Inbox)
+}
+
+// Sent lists the submissions the organization of the signed-in user made
+func (h *Handler) Sent(w http.ResponseWriter, r *http.Request) {
+	h.list(w, r, h.submissionUsecase.Sent)
+}
+
+func (h *Handler) list(w http.ResponseWriter, r *http.Request, list func(ctx context.Context, req *submissionDomain.ListSubmissionsRequest, caller submissionDomain.Caller) (*submissionDomain.SubmissionListResponse, error)) {
+	req := &submissionDomain.ListSubmissionsRequest{
+		Page:  parseIntQuery(r, "page", 1),
+		Limit: parseIntQuery(r, "limit", 20),
+	}
+
+	// Parse optional filters
+	if status := r.URL.Query().Get("status"); status != "" {
+		req.Status = &status
+	}
+	if orgID := r.URL.Query().Get("organization_id"); orgID != "" {
+		req.OrganizationID = &orgID
+	}
+
+	resp, err := list(r.Context(), req, h.caller(r))
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Submissions retrieved successfully", resp)
+}
+
+func (h *Handler) Accept(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	req, ok := httputil.Decode[submissionDomain.AcceptRequest](w, r)
+	if !ok {
+		return
+	}
+
+	submission, err := h.submissionUsecase.Accept(r.Context(), id, req, h.caller(r))
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Submission accepted successfully", submission)
+}
+
+func (h *Handler) Reject(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	req, ok := httputil.Decode[submissionDomain.RejectRequest](w, r)
+	if !ok {
+		return
+	}
+
+	submission, err := h.submissionUsecase.Reject(r.Context(), id, req, h.caller(r))
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Submission rejected successfully", submission)
+}
+
+// errorMapper maps the errors of the submission module on top of the shared ones
+var errorMapper = problem.Default.With(
+	problem.Mapping{Err: pkgErrors.ErrNotFound, Status: http.StatusNotFound, Code: response.CodeNotFound, Message: "Submission not found"},
+)
+
+func (h *Handler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	errorMapper.Write(w, r, err)
+}
+
+func parseIntQuery(r *http.Request, key string, defaultValue int) int {
+	if value := r.URL.Query().Get(key); value != "" {
+		if intValue, err := strconv.Atoi(value); err == nil {
+			return intValue
+		}
+	}
+	return defaultValue
+}
+
+// RegisterRoutes registers submission routes for signed-in users; making
+// and reviewing submissions takes the datasets:write permission
+func RegisterRoutes(r chi.Router, handler *Handler, auth func(http.Handler) http.Handler) {
+	r.Route("/submissions", func(r chi.Router) {
+		r.Use(auth)
+		r.Get("/inbox", handler.Inbox)
+		r.Get("/sent", handler.Sent)
+		r.Get("/{id}", handler.GetByID)
+
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RequirePermission(roleDomain.PermissionDatasetsWrite))
+			r.Post("/", handler.Create)
+			r.Post("/{id}/accept", handler.Accept)
+			r.Post("/{id}/reject", handler.Reject)
+		})
+	})
+}
@@ -0,0 +1,23 @@
+package http
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	submissionDomain "portal-data-backend/internal/submission/domain"
+)
+
+// Describe adds the submission routes to the API specification
+func Describe(spec *openapi.Spec) {
+	api := spec.Tag("submissions", "Datasets organizations propose to each other")
+	api.Get("/submissions/inbox", "List submissions made to the organization").
+		Query(submissionDomain.ListSubmissionsRequest{}, "page", "limit", "status", "organization_id").
+		Returns(http.StatusOK, submissionDomain.SubmissionListResponse{})
+	api.Get("/submissions/sent", "List submissions the organization made").
+		Query(submissionDomain.ListSubmissionsRequest{}, "page", "limit", "status", "organization_id").
+		Returns(http.StatusOK, submissionDomain.SubmissionListResponse{})
+	api.Get("/submissions/{id}", "Get submission").Returns(http.StatusOK, submissionDomain.Submission{})
+	api.Post("/submissions", "Propose a dataset to an organization").Body(submissionDomain.CreateSubmissionRequest{}).Returns(http.StatusCreated, submissionDomain.Submission{})
+	api.Post("/submissions/{id}/accept", "Accept submission").Body(submissionDomain.AcceptRequest{}).Returns(http.StatusOK, submissionDomain.Submission{})
+	api.Post("/submissions/{id}/reject", "Reject submission").Body(submissionDomain.RejectRequest{}).Returns(http.StatusOK, submissionDomain.Submission{})
+}
@@ -0,0 +1,78 @@
+package domain
+
+import "time"
+
+// Submission is a dataset an organization proposes to another one, which
+// takes it over once it accepts
+type Submission struct {
+	ID                   string     `db:"id" json:"id"`
+	DatasetID            string     `db:"dataset_id" json:"dataset_id"`
+	SourceOrganizationID string     `db:"source_organization_id" json:"source_organization_id"`
+	TargetOrganizationID string     `db:"target_organization_id" json:"target_organization_id"`
+	Status               Status     `db:"status" json:"status"`
+	Message              *string    `db:"message" json:"message,omitempty"`
+	ReviewNote           *string    `db:"review_note" json:"review_note,omitempty"`
+	SubmittedBy          string     `db:"submitted_by" json:"submitted_by"`
+	ReviewedBy           *string    `db:"reviewed_by" json:"reviewed_by,omitempty"`
+	ReviewedAt           *time.Time `db:"reviewed_at" json:"reviewed_at,omitempty"`
+	CreatedAt            time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt            time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// Status represents how far a submission is through review
+type Status string
+
+const (
+	StatusPending  Status = "pending"
+	StatusAccepted Status = "accepted"
+	StatusRejected Status = "rejected"
+)
+
+// Caller is the signed-in user acting on submissions. Admin callers act on
+// behalf of any organization.
+type Caller struct {
+	UserID         string
+	OrganizationID string
+	Admin          bool
+}
+
+// CreateSubmissionRequest represents a dataset proposed to an organization
+type CreateSubmissionRequest struct {
+	DatasetID      string  `json:"dataset_id" validate:"required,uuid"`
+	OrganizationID string  `json:"organization_id" validate:"required,uuid"`
+	Message        *string `json:"message,omitempty" validate:"omitempty,max=2000"`
+}
+
+// AcceptRequest represents the acceptance of a submission
+type AcceptRequest struct {
+	Note string `json:"note,omitempty" validate:"max=1000"`
+}
+
+// RejectRequest represents the rejection of a submission, which tells the
+// submitter why
+type RejectRequest struct {
+	Note string `json:"note" validate:"required,max=1000"`
+}
+
+// ListSubmissionsRequest represents list submissions input. OrganizationID
+// is the caller's organization unless an admin sets it.
+type ListSubmissionsRequest struct {
+	Page           int     `json:"page" validate:"min=1"`
+	Limit          int     `json:"limit" validate:"min=1,max=100"`
+	Status         *string `json:"status,omitempty"`
+	OrganizationID *string `json:"organization_id,omitempty"`
+}
+
+// SubmissionListResponse represents paginated submissions
+type SubmissionListResponse struct {
+	Submissions []*Submission `json:"submissions"`
+	Meta        ListMeta      `json:"meta"`
+}
+
+// ListMeta represents pagination metadata
+type ListMeta struct {
+	Page      int `json:"page"`
+	Limit     int `json:"limit"`
+	Total     int `json:"total"`
+	TotalPage int `json:"total_page"`
+}
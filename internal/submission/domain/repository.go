@@ -0,0 +1,22 @@
+package domain
+
+import "context"
+
+// Repository defines the interface for submission data access
+type Repository interface {
+	// Create stores a submission, failing with errors.ErrAlreadyExists when
+	// its dataset is proposed already
+	Create(ctx context.Context, submission *Submission) error
+	GetByID(ctx context.Context, id string) (*Submission, error)
+	List(ctx context.Context, filter *Filter, limit, offset int) ([]*Submission, int, error)
+	// Review records the review of a pending submission, failing with
+	// errors.ErrNotFound when the submission is not pending
+	Review(ctx context.Context, submission *Submission) error
+}
+
+// Filter selects submissions; nil fields match every submission
+type Filter struct {
+	SourceOrganizationID *string
+	TargetOrganizationID *string
+	Status               *string
+}
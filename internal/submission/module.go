@@ -0,0 +1,61 @@
+// Package submission is the module keeping the datasets organizations
+// propose to each other.
+package submission
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	"portal-data-backend/internal/app"
+	delivery "portal-data-backend/internal/submission/delivery/http"
+	"portal-data-backend/internal/submission/repository"
+	"portal-data-backend/internal/submission/usecase"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Module lets organizations propose datasets to each other; the receiving
+// organization takes a dataset over once it accepts
+type Module struct {
+	handler *delivery.Handler
+}
+
+// Name implements app.Module
+func (m *Module) Name() string {
+	return "submission"
+}
+
+// Register implements app.Module
+func (m *Module) Register(deps *app.Deps) error {
+	if deps.Services.Datasets == nil {
+		return app.MissingServiceError("dataset")
+	}
+	if deps.Services.Organizations == nil {
+		return app.MissingServiceError("organization")
+	}
+	if deps.Services.Users == nil {
+		return app.MissingServiceError("user")
+	}
+	if deps.Services.Notifications == nil {
+		return app.MissingServiceError("notification")
+	}
+	if deps.Services.Templates == nil {
+		return app.MissingServiceError("message template")
+	}
+	deps.Services.Templates.Define(usecase.Messages...)
+
+	repo := repository.NewSubmissionPostgresRepository(deps.DB)
+	submissions := usecase.NewSubmissionUsecase(repo, deps.Tx, deps.Services.Datasets, deps.Services.Organizations, deps.Services.Users, deps.Services.Notifications, deps.Services.Templates, deps.Services.Audit)
+	m.handler = delivery.NewHandler(submissions, deps.Config.Audit.AdminRoles)
+	return nil
+}
+
+// Routes implements app.Module
+func (m *Module) Routes(r chi.Router, auth func(http.Handler) http.Handler) {
+	delivery.RegisterRoutes(r, m.handler, auth)
+}
+
+// Describe implements app.Module
+func (m *Module) Describe(spec *openapi.Spec) {
+	delivery.Describe(spec)
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"portal-data-backend/infrastructure/db"
+	submissionDomain "portal-data-backend/internal/submission/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type submissionPostgresRepository struct {
+	db *sqlx.DB
+}
+
+func NewSubmissionPostgresRepository(db *sqlx.DB) submissionDomain.Repository {
+	return &submissionPostgresRepository{db: db}
+}
+
+const submissionColumns = `id, dataset_id, source_organization_id, target_organization_id, status, message, review_note,
+	submitted_by, reviewed_by, reviewed_at, created_at, updated_at`
+
+func (r *submissionPostgresRepository) Create(ctx context.Context, submission *submissionDomain.Submission) error {
+	query := `
+		INSERT INTO dataset_submissions (id, dataset_id, source_organization_id, target_organization_id, status, message,
+		                                 submitted_by, created_at, updated_at)
+		VALUES (:id, :dataset_id, :source_organization_id, :target_organization_id, :status, :message,
+		        :submitted_by, :created_at, :updated_at)
+		ON CONFLICT (dataset_id) WHERE status = 'pending' DO NOTHING
+	`
+
+	result, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, submission)
+	if err != nil {
+		return fmt.Errorf("failed to create submission: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("%w: the dataset is awaiting the review of another submission", pkgErrors.ErrAlreadyExists)
+	}
+	return nil
+}
+
+func (r *submissionPostgresRepository) GetByID(ctx context.Context, id string) (*submissionDomain.Submission, error) {
+	query := `SELECT ` + submissionColumns + ` FROM dataset_submissions WHERE id = $1`
+
+	var submission submissionDomain.Submission
+	err := db.Conn(ctx, r.db).GetContext(ctx, &submission, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, pkgErrors.ErrNotFound
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &submission, nil
+}
+
+func (r *submissionPostgresRepository) List(ctx context.Context, filter *submissionDomain.Filter, limit, offset int) ([]*submissionDomain.Submission, int, error) {
+	whereClause := "WHERE TRUE"
+	args := []interface{}{}
+	argCount := 1
+
+	if filter != nil {
+		if filter.SourceOrganizationID != nil {
+			whereClause += fmt.Sprintf(" AND source_organization_id = $%d", argCount)
+			args = append(args, *filter.SourceOrganizationID)
+			argCount++
+		}
+		if filter.TargetOrganizationID != nil {
+			whereClause += fmt.Sprintf(" AND target_organization_id = $%d", argCount)
+			args = append(args, *filter.TargetOrganizationID)
+			argCount++
+		}
+		if filter.Status != nil {
+			whereClause += fmt.Sprintf(" AND status = $%d", argCount)
+			args = append(args, *filter.Status)
+			argCount++
+		}
+	}
+
+	countQuery := "SELECT COUNT(*) FROM dataset_submissions " + whereClause
+	var total int
+	err := db.Conn(ctx, r.db).GetContext(ctx, &total, countQuery, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count submissions: %w", err)
+	}
+
+	query := `SELECT ` + submissionColumns + ` FROM dataset_submissions ` + whereClause +
+		fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", argCount, argCount+1)
+	args = append(args, limit, offset)
+
+	submissions := []*submissionDomain.Submission{}
+	err = db.Conn(ctx, r.db).SelectContext(ctx, &submissions, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list submissions: %w", err)
+	}
+
+	return submissions, total, nil
+}
+
+func (r *submissionPostgresRepository) Review(ctx context.Context, submission *submissionDomain.Submission) error {
+	query := `
+		UPDATE dataset_submissions
+		SET status = :status, review_note = :review_note, reviewed_by = :reviewed_by, reviewed_at = :reviewed_at,
+		    updated_at = :updated_at
+		WHERE id = :id AND status = 'pending'
+	`
+
+	result, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, submission)
+	if err != nil {
+		return fmt.Errorf("failed to review submission: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return pkgErrors.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package usecase
+
+import templateDomain "portal-data-backend/internal/message_template/domain"
+
+// Keys of the notifications sent as submissions are made and reviewed
+const (
+	MessageReceived = "submission.received"
+	MessageAccepted = "submission.accepted"
+	MessageRejected = "submission.rejected"
+)
+
+// Messages are the notifications sent as submissions are made and reviewed,
+// with their built-in wording. The module defines them with the message
+// template registry.
+var Messages = []templateDomain.Definition{
+	{
+		Key:         MessageReceived,
+		Channel:     templateDomain.ChannelNotification,
+		Description: "Dataset proposed to the organization of the recipient",
+		Variables:   []string{"dataset", "organization", "message"},
+		Subject:     "A dataset was proposed to your organization",
+		Body:        "{{.organization}} proposes the dataset {{.dataset}} to your organization.\n\n{{.message}}",
+	},
+	{
+		Key:         MessageAccepted,
+		Channel:     templateDomain.ChannelNotification,
+		Description: "Acceptance of a dataset the recipient proposed",
+		Variables:   []string{"dataset", "organization", "note"},
+		Subject:     "Your dataset submission was accepted",
+		Body:        "{{.organization}} accepted the dataset {{.dataset}} and now owns it.\n\n{{.note}}",
+	},
+	{
+		Key:         MessageRejected,
+		Channel:     templateDomain.ChannelNotification,
+		Description: "Rejection of a dataset the recipient proposed",
+		Variables:   []string{"dataset", "organization", "note"},
+		Subject:     "Your dataset submission was rejected",
+		Body:        "{{.organization}} rejected the dataset {{.dataset}}.\n\n{{.note}}",
+	},
+}
@@ -0,0 +1,354 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"portal-data-backend/infrastructure/audit"
+	"portal-data-backend/infrastructure/db"
+	"portal-data-backend/infrastructure/logger"
+	datasetDomain "portal-data-backend/internal/dataset/domain"
+	templateDomain "portal-data-backend/internal/message_template/domain"
+	notifDomain "portal-data-backend/internal/notification/domain"
+	orgDomain "portal-data-backend/internal/organization/domain"
+	"portal-data-backend/internal/submission/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// DatasetTransferer is the part of the dataset module submissions propose
+// and hand over datasets through
+type DatasetTransferer interface {
+	GetByID(ctx context.Context, id string) (*datasetDomain.DatasetResponse, error)
+	Transfer(ctx context.Context, id, orgID, updaterID string) (*datasetDomain.DatasetResponse, error)
+}
+
+// OrganizationReader is the part of the organization module the
+// organizations of submissions are read through
+type OrganizationReader interface {
+	GetByID(ctx context.Context, id string) (*orgDomain.OrganizationResponse, error)
+}
+
+// MemberReader is the part of the user module the members of the receiving
+// organization are found through
+type MemberReader interface {
+	MemberIDs(ctx context.Context, organizationID string) ([]string, error)
+}
+
+// NotificationSender is the part of the notification module both sides of
+// a submission are notified through
+type NotificationSender interface {
+	Create(ctx context.Context, req *notifDomain.CreateNotificationRequest) (*notifDomain.NotificationInfo, error)
+	BulkCreate(ctx context.Context, req *notifDomain.BulkCreateNotificationRequest) error
+}
+
+// MessageRenderer renders the wording of the notifications from the
+// templates of the message template module
+type MessageRenderer interface {
+	Render(ctx context.Context, channel templateDomain.Channel, key string, vars map[string]string) (*templateDomain.Message, error)
+}
+
+// Usecase keeps the datasets organizations propose to each other. Callers
+// act on the submissions of their own organization only, unless they are
+// admins.
+type Usecase interface {
+	// Submit proposes a dataset of the caller's organization to another
+	// organization, whose members are notified
+	Submit(ctx context.Context, req *domain.CreateSubmissionRequest, caller domain.Caller) (*domain.Submission, error)
+	GetByID(ctx context.Context, id string, caller domain.Caller) (*domain.Submission, error)
+	// Inbox lists the submissions made to the caller's organization
+	Inbox(ctx context.Context, req *domain.ListSubmissionsRequest, caller domain.Caller) (*domain.SubmissionListResponse, error)
+	// Sent lists the submissions the caller's organization made
+	Sent(ctx context.Context, req *domain.ListSubmissionsRequest, caller domain.Caller) (*domain.SubmissionListResponse, error)
+	// Accept hands the dataset of a pending submission over to the
+	// organization it was made to
+	Accept(ctx context.Context, id string, req *domain.AcceptRequest, caller domain.Caller) (*domain.Submission, error)
+	// Reject leaves the dataset of a pending submission where it is
+	Reject(ctx context.Context, id string, req *domain.RejectRequest, caller domain.Caller) (*domain.Submission, error)
+}
+
+type submissionUsecase struct {
+	repo          domain.Repository
+	tx            db.Transactor
+	datasets      DatasetTransferer
+	orgs          OrganizationReader
+	members       MemberReader
+	notifications NotificationSender
+	messages      MessageRenderer
+	audit         *audit.Recorder
+	now           func() time.Time
+}
+
+// NewSubmissionUsecase creates the submission usecase. Reviews are audited
+// through recorder, which may be nil.
+func NewSubmissionUsecase(repo domain.Repository, tx db.Transactor, datasets DatasetTransferer, orgs OrganizationReader, members MemberReader, notifications NotificationSender, messages MessageRenderer, recorder *audit.Recorder) Usecase {
+	return &submissionUsecase{
+		repo:          repo,
+		tx:            tx,
+		datasets:      datasets,
+		orgs:          orgs,
+		members:       members,
+		notifications: notifications,
+		messages:      messages,
+		audit:         recorder,
+		now:           time.Now,
+	}
+}
+
+func (u *submissionUsecase) Submit(ctx context.Context, req *domain.CreateSubmissionRequest, caller domain.Caller) (*domain.Submission, error) {
+	dataset, err := u.datasets.GetByID(ctx, req.DatasetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dataset: %w", err)
+	}
+	if !caller.Admin && dataset.OrganizationID != caller.OrganizationID {
+		return nil, fmt.Errorf("%w: only the organization owning a dataset may propose it", pkgErrors.ErrForbidden)
+	}
+	if dataset.OrganizationID == req.OrganizationID {
+		return nil, fmt.Errorf("%w: the dataset belongs to the organization already", pkgErrors.ErrInvalidInput)
+	}
+
+	source, err := u.orgs.GetByID(ctx, dataset.OrganizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+	if _, err := u.orgs.GetByID(ctx, req.OrganizationID); err != nil {
+		if errors.Is(err, pkgErrors.ErrNotFound) {
+			return nil, fmt.Errorf("%w: organization %s does not exist", pkgErrors.ErrInvalidInput, req.OrganizationID)
+		}
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	now := u.now()
+	submission := &domain.Submission{
+		ID:                   uuid.New().String(),
+		DatasetID:            dataset.ID,
+		SourceOrganizationID: dataset.OrganizationID,
+		TargetOrganizationID: req.OrganizationID,
+		Status:               domain.StatusPending,
+		Message:              req.Message,
+		SubmittedBy:          caller.UserID,
+		CreatedAt:            now,
+		UpdatedAt:            now,
+	}
+	if err := u.repo.Create(ctx, submission); err != nil {
+		return nil, err
+	}
+	u.audit.Record(ctx, "dataset_submissions", submission.ID, audit.ActionCreate, nil, submission)
+
+	message := ""
+	if req.Message != nil {
+		message = *req.Message
+	}
+	u.notifyMembers(ctx, submission, caller.UserID, map[string]string{
+		"dataset":      dataset.Name,
+		"organization": source.Name,
+		"message":      message,
+	})
+	return submission, nil
+}
+
+func (u *submissionUsecase) GetByID(ctx context.Context, id string, caller domain.Caller) (*domain.Submission, error) {
+	submission, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get submission: %w", err)
+	}
+	if !caller.Admin && caller.OrganizationID != submission.SourceOrganizationID && caller.OrganizationID != submission.TargetOrganizationID {
+		return nil, fmt.Errorf("%w: the submission concerns other organizations", pkgErrors.ErrForbidden)
+	}
+	return submission, nil
+}
+
+func (u *submissionUsecase) Inbox(ctx context.Context, req *domain.ListSubmissionsRequest, caller domain.Caller) (*domain.SubmissionListResponse, error) {
+	orgID := organizationOf(req, caller)
+	return u.list(ctx, req, &domain.Filter{TargetOrganizationID: &orgID, Status: req.Status})
+}
+
+func (u *submissionUsecase) Sent(ctx context.Context, req *domain.ListSubmissionsRequest, caller domain.Caller) (*domain.SubmissionListResponse, error) {
+	orgID := organizationOf(req, caller)
+	return u.list(ctx, req, &domain.Filter{SourceOrganizationID: &orgID, Status: req.Status})
+}
+
+// organizationOf returns the organization whose submissions are listed: the
+// caller's, unless an admin asks for another one
+func organizationOf(req *domain.ListSubmissionsRequest, caller domain.Caller) string {
+	if caller.Admin && req.OrganizationID != nil {
+		return *req.OrganizationID
+	}
+	return caller.OrganizationID
+}
+
+func (u *submissionUsecase) list(ctx context.Context, req *domain.ListSubmissionsRequest, filter *domain.Filter) (*domain.SubmissionListResponse, error) {
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.Limit < 1 {
+		req.Limit = 20
+	}
+
+	offset := (req.Page - 1) * req.Limit
+	submissions, total, err := u.repo.List(ctx, filter, req.Limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list submissions: %w", err)
+	}
+
+	return &domain.SubmissionListResponse{
+		Submissions: submissions,
+		Meta: domain.ListMeta{
+			Page:      req.Page,
+			Limit:     req.Limit,
+			Total:     total,
+			TotalPage: int(math.Ceil(float64(total) / float64(req.Limit))),
+		},
+	}, nil
+}
+
+func (u *submissionUsecase) Accept(ctx context.Context, id string, req *domain.AcceptRequest, caller domain.Caller) (*domain.Submission, error) {
+	var datasetName string
+	reviewed, err := u.review(ctx, id, domain.StatusAccepted, req.Note, caller, func(ctx context.Context, submission *domain.Submission) error {
+		dataset, err := u.datasets.Transfer(ctx, submission.DatasetID, submission.TargetOrganizationID, caller.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to transfer dataset: %w", err)
+		}
+		datasetName = dataset.Name
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	u.notifySubmitter(ctx, reviewed, caller.UserID, MessageAccepted, datasetName)
+	return reviewed, nil
+}
+
+func (u *submissionUsecase) Reject(ctx context.Context, id string, req *domain.RejectRequest, caller domain.Caller) (*domain.Submission, error) {
+	reviewed, err := u.review(ctx, id, domain.StatusRejected, req.Note, caller, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	datasetName := reviewed.DatasetID
+	if dataset, err := u.datasets.GetByID(ctx, reviewed.DatasetID); err == nil {
+		datasetName = dataset.Name
+	}
+	u.notifySubmitter(ctx, reviewed, caller.UserID, MessageRejected, datasetName)
+	return reviewed, nil
+}
+
+// review records the review of a pending submission made to the caller's
+// organization and applies it, in one transaction
+func (u *submissionUsecase) review(ctx context.Context, id string, status domain.Status, note string, caller domain.Caller, apply func(ctx context.Context, submission *domain.Submission) error) (*domain.Submission, error) {
+	var reviewed domain.Submission
+	err := u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		submission, err := u.repo.GetByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get submission: %w", err)
+		}
+		if !caller.Admin && caller.OrganizationID != submission.TargetOrganizationID {
+			return fmt.Errorf("%w: only the organization a dataset was proposed to may review it", pkgErrors.ErrForbidden)
+		}
+		if submission.Status != domain.StatusPending {
+			return fmt.Errorf("%w: the submission was already %s", pkgErrors.ErrInvalidInput, submission.Status)
+		}
+
+		now := u.now()
+		reviewed = *submission
+		reviewed.Status, reviewed.ReviewedBy, reviewed.ReviewedAt, reviewed.UpdatedAt = status, &caller.UserID, &now, now
+		if note != "" {
+			reviewed.ReviewNote = &note
+		}
+		if err := u.repo.Review(ctx, &reviewed); err != nil {
+			return fmt.Errorf("failed to review submission: %w", err)
+		}
+		if apply != nil {
+			if err := apply(ctx, &reviewed); err != nil {
+				return err
+			}
+		}
+
+		u.audit.Record(ctx, "dataset_submissions", submission.ID, audit.ActionUpdate, submission, &reviewed)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &reviewed, nil
+}
+
+// notifyMembers tells the members of the organization a submission was made
+// to about it, except submitterID
+func (u *submissionUsecase) notifyMembers(ctx context.Context, submission *domain.Submission, submitterID string, vars map[string]string) {
+	members, err := u.members.MemberIDs(ctx, submission.TargetOrganizationID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to notify the organization of submission %s: %v", submission.ID, err)
+		return
+	}
+	recipients := make([]string, 0, len(members))
+	for _, member := range members {
+		if member != submitterID {
+			recipients = append(recipients, member)
+		}
+	}
+	if len(recipients) == 0 {
+		return
+	}
+
+	msg, err := u.messages.Render(ctx, templateDomain.ChannelNotification, MessageReceived, vars)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to notify the organization of submission %s: %v", submission.ID, err)
+		return
+	}
+	req := &notifDomain.BulkCreateNotificationRequest{
+		UserIDs:  recipients,
+		Title:    msg.Subject,
+		Message:  msg.Body,
+		Type:     string(notifDomain.NotificationTypeInfo),
+		Category: string(notifDomain.NotificationCategoryDataset),
+	}
+	if err := u.notifications.BulkCreate(ctx, req); err != nil {
+		logger.FromContext(ctx).Error("failed to notify the organization of submission %s: %v", submission.ID, err)
+	}
+}
+
+// notifySubmitter tells the submitter of a submission reviewed by
+// reviewerID about the review with the message key
+func (u *submissionUsecase) notifySubmitter(ctx context.Context, submission *domain.Submission, reviewerID, key, datasetName string) {
+	if submission.SubmittedBy == reviewerID {
+		return
+	}
+
+	organization := submission.TargetOrganizationID
+	if org, err := u.orgs.GetByID(ctx, submission.TargetOrganizationID); err == nil {
+		organization = org.Name
+	}
+	note := ""
+	if submission.ReviewNote != nil {
+		note = *submission.ReviewNote
+	}
+	msg, err := u.messages.Render(ctx, templateDomain.ChannelNotification, key, map[string]string{
+		"dataset":      datasetName,
+		"organization": organization,
+		"note":         note,
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to notify the submitter of submission %s: %v", submission.ID, err)
+		return
+	}
+	msgType := notifDomain.NotificationTypeSuccess
+	if submission.Status == domain.StatusRejected {
+		msgType = notifDomain.NotificationTypeWarning
+	}
+	req := &notifDomain.CreateNotificationRequest{
+		UserID:   submission.SubmittedBy,
+		Title:    msg.Subject,
+		Message:  msg.Body,
+		Type:     string(msgType),
+		Category: string(notifDomain.NotificationCategoryDataset),
+	}
+	if _, err := u.notifications.Create(ctx, req); err != nil {
+		logger.FromContext(ctx).Error("failed to notify the submitter of submission %s: %v", submission.ID, err)
+	}
+}
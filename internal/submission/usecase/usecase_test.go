@@ -0,0 +1,256 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	datasetDomain "portal-data-backend/internal/dataset/domain"
+	templateDomain "portal-data-backend/internal/message_template/domain"
+	notifDomain "portal-data-backend/internal/notification/domain"
+	orgDomain "portal-data-backend/internal/organization/domain"
+	"portal-data-backend/internal/submission/domain"
+	"portal-data-backend/internal/submission/usecase"
+	pkgerrors "portal-data-backend/pkg/errors"
+)
+
+// mockRepository is an in-memory implementation of Repository
+type mockRepository struct {
+	submissions []*domain.Submission
+}
+
+func (m *mockRepository) Create(ctx context.Context, submission *domain.Submission) error {
+	for _, stored := range m.submissions {
+		if stored.DatasetID == submission.DatasetID && stored.Status == domain.StatusPending {
+			return pkgerrors.ErrAlreadyExists
+		}
+	}
+	copied := *submission
+	m.submissions = append(m.submissions, &copied)
+	return nil
+}
+
+func (m *mockRepository) GetByID(ctx context.Context, id string) (*domain.Submission, error) {
+	for _, submission := range m.submissions {
+		if submission.ID == id {
+			copied := *submission
+			return &copied, nil
+		}
+	}
+	return nil, pkgerrors.ErrNotFound
+}
+
+func (m *mockRepository) List(ctx context.Context, filter *domain.Filter, limit, offset int) ([]*domain.Submission, int, error) {
+	var submissions []*domain.Submission
+	for _, submission := range m.submissions {
+		if filter.TargetOrganizationID != nil && submission.TargetOrganizationID != *filter.TargetOrganizationID {
+			continue
+		}
+		if filter.SourceOrganizationID != nil && submission.SourceOrganizationID != *filter.SourceOrganizationID {
+			continue
+		}
+		submissions = append(submissions, submission)
+	}
+	return submissions, len(submissions), nil
+}
+
+func (m *mockRepository) Review(ctx context.Context, submission *domain.Submission) error {
+	for i, stored := range m.submissions {
+		if stored.ID == submission.ID && stored.Status == domain.StatusPending {
+			copied := *submission
+			m.submissions[i] = &copied
+			return nil
+		}
+	}
+	return pkgerrors.ErrNotFound
+}
+
+type mockTransactor struct{}
+
+func (mockTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// mockDatasets keeps the organization of each dataset
+type mockDatasets struct {
+	owners map[string]string
+}
+
+func (m *mockDatasets) GetByID(ctx context.Context, id string) (*datasetDomain.DatasetResponse, error) {
+	owner, ok := m.owners[id]
+	if !ok {
+		return nil, pkgerrors.ErrNotFound
+	}
+	return &datasetDomain.DatasetResponse{ID: id, Name: "Dataset " + id, OrganizationID: owner}, nil
+}
+
+func (m *mockDatasets) Transfer(ctx context.Context, id, orgID, updaterID string) (*datasetDomain.DatasetResponse, error) {
+	if _, ok := m.owners[id]; !ok {
+		return nil, pkgerrors.ErrNotFound
+	}
+	m.owners[id] = orgID
+	return m.GetByID(ctx, id)
+}
+
+type mockOrganizations struct{}
+
+func (mockOrganizations) GetByID(ctx context.Context, id string) (*orgDomain.OrganizationResponse, error) {
+	if id == "unknown" {
+		return nil, pkgerrors.ErrNotFound
+	}
+	return &orgDomain.OrganizationResponse{ID: id, Name: "Organization " + id}, nil
+}
+
+type mockMembers map[string][]string
+
+func (m mockMembers) MemberIDs(ctx context.Context, organizationID string) ([]string, error) {
+	return m[organizationID], nil
+}
+
+// mockNotifications collects the users notified
+type mockNotifications struct {
+	notified []string
+}
+
+func (m *mockNotifications) Create(ctx context.Context, req *notifDomain.CreateNotificationRequest) (*notifDomain.NotificationInfo, error) {
+	m.notified = append(m.notified, req.UserID)
+	return &notifDomain.NotificationInfo{}, nil
+}
+
+func (m *mockNotifications) BulkCreate(ctx context.Context, req *notifDomain.BulkCreateNotificationRequest) error {
+	m.notified = append(m.notified, req.UserIDs...)
+	return nil
+}
+
+type mockMessages struct{}
+
+func (mockMessages) Render(ctx context.Context, channel templateDomain.Channel, key string, vars map[string]string) (*templateDomain.Message, error) {
+	return &templateDomain.Message{Subject: key, Body: vars["dataset"]}, nil
+}
+
+func newSubmissionUsecase(datasets *mockDatasets, notifications *mockNotifications) usecase.Usecase {
+	members := mockMembers{"org-b": {"reviewer", "member"}}
+	return usecase.NewSubmissionUsecase(&mockRepository{}, mockTransactor{}, datasets, mockOrganizations{}, members, notifications, mockMessages{}, nil)
+}
+
+var (
+	submitter = domain.Caller{UserID: "submitter", OrganizationID: "org-a"}
+	reviewer  = domain.Caller{UserID: "reviewer", OrganizationID: "org-b"}
+	outsider  = domain.Caller{UserID: "outsider", OrganizationID: "org-c"}
+)
+
+// Test only the owning organization proposes a dataset, once at a time, and
+// the receiving organization is notified
+func TestSubmission_Submit(t *testing.T) {
+	ctx := context.Background()
+	notifications := &mockNotifications{}
+	submissions := newSubmissionUsecase(&mockDatasets{owners: map[string]string{"ds-1": "org-a"}}, notifications)
+
+	req := &domain.CreateSubmissionRequest{DatasetID: "ds-1", OrganizationID: "org-b"}
+	if _, err := submissions.Submit(ctx, req, outsider); !errors.Is(err, pkgerrors.ErrForbidden) {
+		t.Errorf("Expected a dataset of another organization to be refused, got %v", err)
+	}
+	if _, err := submissions.Submit(ctx, &domain.CreateSubmissionRequest{DatasetID: "ds-1", OrganizationID: "org-a"}, submitter); !errors.Is(err, pkgerrors.ErrInvalidInput) {
+		t.Errorf("Expected a submission to the owning organization to be refused, got %v", err)
+	}
+	if _, err := submissions.Submit(ctx, &domain.CreateSubmissionRequest{DatasetID: "ds-1", OrganizationID: "unknown"}, submitter); !errors.Is(err, pkgerrors.ErrInvalidInput) {
+		t.Errorf("Expected a submission to an unknown organization to be refused, got %v", err)
+	}
+
+	submission, err := submissions.Submit(ctx, req, submitter)
+	if err != nil {
+		t.Fatalf("Failed to submit dataset: %v", err)
+	}
+	if submission.Status != domain.StatusPending || submission.SourceOrganizationID != "org-a" || submission.TargetOrganizationID != "org-b" {
+		t.Errorf("Expected a pending submission from org-a to org-b, got %+v", submission)
+	}
+	if len(notifications.notified) != 2 {
+		t.Errorf("Expected the 2 members of org-b to be notified, got %v", notifications.notified)
+	}
+
+	if _, err := submissions.Submit(ctx, req, submitter); !errors.Is(err, pkgerrors.ErrAlreadyExists) {
+		t.Errorf("Expected a second pending submission to be refused, got %v", err)
+	}
+
+	inbox, err := submissions.Inbox(ctx, &domain.ListSubmissionsRequest{}, reviewer)
+	if err != nil || inbox.Meta.Total != 1 {
+		t.Errorf("Expected 1 submission in the inbox of org-b, got %v, %v", inbox, err)
+	}
+	sent, err := submissions.Sent(ctx, &domain.ListSubmissionsRequest{}, reviewer)
+	if err != nil || sent.Meta.Total != 0 {
+		t.Errorf("Expected no submission sent by org-b, got %v, %v", sent, err)
+	}
+}
+
+// Test the receiving organization takes the dataset over as it accepts, and
+// the submitter is told
+func TestSubmission_Accept(t *testing.T) {
+	ctx := context.Background()
+	datasets := &mockDatasets{owners: map[string]string{"ds-1": "org-a"}}
+	notifications := &mockNotifications{}
+	submissions := newSubmissionUsecase(datasets, notifications)
+
+	submission, err := submissions.Submit(ctx, &domain.CreateSubmissionRequest{DatasetID: "ds-1", OrganizationID: "org-b"}, submitter)
+	if err != nil {
+		t.Fatalf("Failed to submit dataset: %v", err)
+	}
+	notifications.notified = nil
+
+	if _, err := submissions.Accept(ctx, submission.ID, &domain.AcceptRequest{}, submitter); !errors.Is(err, pkgerrors.ErrForbidden) {
+		t.Errorf("Expected the submitting organization not to accept, got %v", err)
+	}
+	if _, err := submissions.GetByID(ctx, submission.ID, outsider); !errors.Is(err, pkgerrors.ErrForbidden) {
+		t.Errorf("Expected other organizations not to see the submission, got %v", err)
+	}
+
+	accepted, err := submissions.Accept(ctx, submission.ID, &domain.AcceptRequest{Note: "Welcome"}, reviewer)
+	if err != nil {
+		t.Fatalf("Failed to accept submission: %v", err)
+	}
+	if accepted.Status != domain.StatusAccepted || accepted.ReviewedBy == nil || *accepted.ReviewedBy != "reviewer" {
+		t.Errorf("Expected the submission accepted by reviewer, got %+v", accepted)
+	}
+	if datasets.owners["ds-1"] != "org-b" {
+		t.Errorf("Expected org-b to own the dataset, got %s", datasets.owners["ds-1"])
+	}
+	if len(notifications.notified) != 1 || notifications.notified[0] != "submitter" {
+		t.Errorf("Expected the submitter to be notified, got %v", notifications.notified)
+	}
+
+	if _, err := submissions.Reject(ctx, submission.ID, &domain.RejectRequest{Note: "Too late"}, reviewer); !errors.Is(err, pkgerrors.ErrInvalidInput) {
+		t.Errorf("Expected an accepted submission not to be rejected, got %v", err)
+	}
+}
+
+// Test a rejection leaves the dataset with its organization
+func TestSubmission_Reject(t *testing.T) {
+	ctx := context.Background()
+	datasets := &mockDatasets{owners: map[string]string{"ds-1": "org-a"}}
+	notifications := &mockNotifications{}
+	submissions := newSubmissionUsecase(datasets, notifications)
+
+	submission, err := submissions.Submit(ctx, &domain.CreateSubmissionRequest{DatasetID: "ds-1", OrganizationID: "org-b"}, submitter)
+	if err != nil {
+		t.Fatalf("Failed to submit dataset: %v", err)
+	}
+	notifications.notified = nil
+
+	rejected, err := submissions.Reject(ctx, submission.ID, &domain.RejectRequest{Note: "Out of scope"}, reviewer)
+	if err != nil {
+		t.Fatalf("Failed to reject submission: %v", err)
+	}
+	if rejected.Status != domain.StatusRejected || rejected.ReviewNote == nil || *rejected.ReviewNote != "Out of scope" {
+		t.Errorf("Expected the submission rejected with its note, got %+v", rejected)
+	}
+	if datasets.owners["ds-1"] != "org-a" {
+		t.Errorf("Expected org-a to keep the dataset, got %s", datasets.owners["ds-1"])
+	}
+	if len(notifications.notified) != 1 || notifications.notified[0] != "submitter" {
+		t.Errorf("Expected the submitter to be notified, got %v", notifications.notified)
+	}
+
+	// The dataset may be proposed again
+	if _, err := submissions.Submit(ctx, &domain.CreateSubmissionRequest{DatasetID: "ds-1", OrganizationID: "org-b"}, submitter); err != nil {
+		t.Errorf("Expected a rejected dataset to be proposed again, got %v", err)
+	}
+}
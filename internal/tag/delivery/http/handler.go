@@ -1,42 +1,40 @@
 package http
 
 import (
-	"encoding/json"
-	"errors"
 	"net/http"
 	"strconv"
 
 	tagDomain "portal-data-backend/internal/tag/domain"
 	"portal-data-backend/internal/tag/usecase"
+	"portal-data-backend/infrastructure/http/httputil"
+	"portal-data-backend/infrastructure/http/middleware"
+	"portal-data-backend/infrastructure/http/problem"
 	"portal-data-backend/infrastructure/http/response"
+	roleDomain "portal-data-backend/internal/role/domain"
 	pkgErrors "portal-data-backend/pkg/errors"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/go-playground/validator/v10"
 )
 
 type Handler struct {
 	tagUsecase usecase.Usecase
-	validator  *validator.Validate
 }
 
 func NewHandler(tagUsecase usecase.Usecase) *Handler {
 	return &Handler{
 		tagUsecase: tagUsecase,
-		validator:  validator.New(),
 	}
 }
 
 func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Tag ID is required", nil)
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
 	tag, err := h.tagUsecase.GetByID(r.Context(), id)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -52,55 +50,76 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 
 	resp, err := h.tagUsecase.List(r.Context(), req)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
 	response.OK(w, response.CodeSuccess, "Tags retrieved successfully", resp)
 }
 
-func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
-	var req tagDomain.CreateTagRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, response.CodeBadRequest, "Invalid request body", nil)
+func (h *Handler) Suggest(w http.ResponseWriter, r *http.Request) {
+	req := &tagDomain.SuggestTagsRequest{
+		Query: r.URL.Query().Get("q"),
+		Limit: parseIntQuery(r, "limit", 10),
+	}
+
+	if !httputil.Validate(w, req) {
+		return
+	}
+
+	suggestions, err := h.tagUsecase.Suggest(r.Context(), req)
+	if err != nil {
+		h.handleError(w, r, err)
 		return
 	}
 
-	if err := h.validator.Struct(&req); err != nil {
-		response.ValidationError(w, response.CodeValidationFailed, "Validation failed", h.formatValidationErrors(err))
+	response.OK(w, response.CodeSuccess, "Tag suggestions retrieved successfully", suggestions)
+}
+
+func (h *Handler) SuggestForDataset(w http.ResponseWriter, r *http.Request) {
+	req, ok := httputil.Decode[tagDomain.SuggestDatasetTagsRequest](w, r)
+	if !ok {
 		return
 	}
 
-	tag, err := h.tagUsecase.Create(r.Context(), &req)
+	suggestions, err := h.tagUsecase.SuggestForDataset(r.Context(), req)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
-	response.Created(w, response.CodeCreated, "Tag created successfully", tag)
+	response.OK(w, response.CodeSuccess, "Tag suggestions retrieved successfully", suggestions)
 }
 
-func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Tag ID is required", nil)
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	req, ok := httputil.Decode[tagDomain.CreateTagRequest](w, r)
+	if !ok {
 		return
 	}
 
-	var req tagDomain.UpdateTagRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, response.CodeBadRequest, "Invalid request body", nil)
+	tag, err := h.tagUsecase.Create(r.Context(), req)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.Created(w, response.CodeCreated, "Tag created successfully", tag)
+}
+
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
-	if err := h.validator.Struct(&req); err != nil {
-		response.ValidationError(w, response.CodeValidationFailed, "Validation failed", h.formatValidationErrors(err))
+	req, ok := httputil.Decode[tagDomain.UpdateTagRequest](w, r)
+	if !ok {
 		return
 	}
 
-	tag, err := h.tagUsecase.Update(r.Context(), id, &req)
+	tag, err := h.tagUsecase.Update(r.Context(), id, req)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -108,55 +127,69 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Tag ID is required", nil)
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
-	if err := h.tagUsecase.Delete(r.Context(), id); err != nil {
-		h.handleError(w, err)
+	var reassignTo *string
+	if target := r.URL.Query().Get("reassign_to"); target != "" {
+		reassignTo = &target
+	}
+
+	if err := h.tagUsecase.Delete(r.Context(), id, reassignTo); err != nil {
+		h.handleError(w, r, err)
 		return
 	}
 
 	response.OK(w, response.CodeSuccess, "Tag deleted successfully", nil)
 }
 
-func (h *Handler) handleError(w http.ResponseWriter, err error) {
-	if err == nil {
+func (h *Handler) Export(w http.ResponseWriter, r *http.Request) {
+	data, err := h.tagUsecase.Export(r.Context())
+	if err != nil {
+		h.handleError(w, r, err)
 		return
 	}
 
-	switch {
-	case errors.Is(err, pkgErrors.ErrNotFound):
-		response.NotFound(w, response.CodeNotFound, "Tag not found", nil)
-	default:
-		response.InternalError(w, response.CodeInternalServerError, "Internal server error", nil)
-	}
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="tags.csv"`)
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
 }
 
-func (h *Handler) formatValidationErrors(err error) []response.ErrorDetail {
-	var details []response.ErrorDetail
-	if validationErrors, ok := err.(validator.ValidationErrors); ok {
-		for _, fieldErr := range validationErrors {
-			details = append(details, response.ErrorDetail{
-				Field:   fieldErr.Field(),
-				Message: h.getValidationErrorMessage(fieldErr),
-			})
+func (h *Handler) Import(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(10 << 20); err != nil {
+		if httputil.BodyTooLarge(w, err) {
+			return
 		}
+		response.BadRequest(w, response.CodeBadRequest, "Failed to parse form data", nil)
+		return
 	}
-	return details
-}
 
-func (h *Handler) getValidationErrorMessage(fieldErr validator.FieldError) string {
-	switch fieldErr.Tag() {
-	case "required":
-		return fieldErr.Field() + " is required"
-	case "min":
-		return fieldErr.Field() + " must be at least " + fieldErr.Param() + " characters"
-	default:
-		return fieldErr.Field() + " is invalid"
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		response.BadRequest(w, response.CodeBadRequest, "File is required", nil)
+		return
 	}
+	defer file.Close()
+
+	result, err := h.tagUsecase.Import(r.Context(), file)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Tags imported successfully", result)
+}
+
+// errorMapper maps the errors of the tag module on top of the shared ones
+var errorMapper = problem.Default.With(
+	problem.Mapping{Err: pkgErrors.ErrNotFound, Status: http.StatusNotFound, Code: response.CodeNotFound, Message: "Tag not found"},
+)
+
+func (h *Handler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	errorMapper.Write(w, r, err)
 }
 
 func parseIntQuery(r *http.Request, key string, defaultValue int) int {
@@ -168,12 +201,24 @@ func parseIntQuery(r *http.Request, key string, defaultValue int) int {
 	return defaultValue
 }
 
-func RegisterRoutes(r chi.Router, handler *Handler) {
+// RegisterRoutes registers tag routes. Reads and suggestions are public;
+// managing tags goes through auth and requires the taxonomies:write
+// permission.
+func RegisterRoutes(r chi.Router, handler *Handler, auth func(http.Handler) http.Handler) {
 	r.Route("/tags", func(r chi.Router) {
 		r.Get("/", handler.List)
-		r.Post("/", handler.Create)
+		r.Get("/suggest", handler.Suggest)
+		r.Post("/suggest/dataset", handler.SuggestForDataset)
 		r.Get("/{id}", handler.GetByID)
-		r.Put("/{id}", handler.Update)
-		r.Delete("/{id}", handler.Delete)
+
+		r.Group(func(r chi.Router) {
+			r.Use(auth)
+			r.Use(middleware.RequirePermission(roleDomain.PermissionTaxonomiesWrite))
+			r.Post("/", handler.Create)
+			r.Get("/export", handler.Export)
+			r.Post("/import", handler.Import)
+			r.Put("/{id}", handler.Update)
+			r.Delete("/{id}", handler.Delete)
+		})
 	})
 }
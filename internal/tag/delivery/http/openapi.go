@@ -0,0 +1,24 @@
+package http
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	tagDomain "portal-data-backend/internal/tag/domain"
+)
+
+// Describe adds the tag routes to the API specification
+func Describe(spec *openapi.Spec) {
+	api := spec.Tag("tags", "Tags for datasets")
+	api.Get("/tags", "List tags").Public().Query(tagDomain.ListTagsRequest{}).Returns(http.StatusOK, tagDomain.TagListResponse{})
+	api.Post("/tags", "Create tag").Body(tagDomain.CreateTagRequest{}).Returns(http.StatusCreated, tagDomain.TagResponse{})
+	api.Get("/tags/export", "Export tags as CSV").ReturnsFile(http.StatusOK, "text/csv")
+	api.Post("/tags/import", "Import tags from CSV").Upload("file").Returns(http.StatusOK, tagDomain.ImportResult{})
+	api.Get("/tags/suggest", "Suggest tags").Public().Query(tagDomain.SuggestTagsRequest{}).Returns(http.StatusOK, []tagDomain.TagSuggestion{})
+	api.Post("/tags/suggest/dataset", "Suggest tags for a dataset").Public().
+		Body(tagDomain.SuggestDatasetTagsRequest{}).
+		Returns(http.StatusOK, []tagDomain.TagSuggestion{})
+	api.Get("/tags/{id}", "Get tag").Public().Returns(http.StatusOK, tagDomain.TagResponse{})
+	api.Put("/tags/{id}", "Update tag").Body(tagDomain.UpdateTagRequest{}).Returns(http.StatusOK, tagDomain.TagResponse{})
+	api.Delete("/tags/{id}", "Delete tag").Param("reassign_to", false).Returns(http.StatusOK, nil)
+}
@@ -43,6 +43,51 @@ type TagResponse struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// TagUsage is a tag with the number of datasets it is attached to
+type TagUsage struct {
+	Tag
+	Usage int `db:"usage"`
+}
+
+// SuggestTagsRequest represents tag autocomplete input
+type SuggestTagsRequest struct {
+	Query string `json:"q" validate:"required"`
+	Limit int    `json:"limit" validate:"min=1,max=50"`
+}
+
+// SuggestDatasetTagsRequest represents input for suggesting tags from the
+// title and description of a dataset. Tags in TagIDs are already attached and
+// are not suggested.
+type SuggestDatasetTagsRequest struct {
+	Title       string   `json:"title" validate:"required"`
+	Description string   `json:"description,omitempty"`
+	TagIDs      []string `json:"tag_ids,omitempty"`
+	Limit       int      `json:"limit,omitempty"`
+}
+
+// TagSuggestion represents a suggested tag
+type TagSuggestion struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Slug  string `json:"slug"`
+	Usage int    `json:"usage"` // datasets using the tag
+	Match string `json:"match"` // prefix, contains, fuzzy or text
+}
+
+// ImportResult reports the outcome of a CSV import
+type ImportResult struct {
+	Created int           `json:"created"`
+	Updated int           `json:"updated"`
+	Skipped int           `json:"skipped"`
+	Errors  []ImportError `json:"errors,omitempty"`
+}
+
+// ImportError describes a CSV row that was skipped
+type ImportError struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
 // ListMeta represents pagination metadata
 type ListMeta struct {
 	Page      int `json:"page"`
@@ -7,8 +7,17 @@ import (
 // Repository defines the interface for tag data operations
 type Repository interface {
 	GetByID(ctx context.Context, id string) (*Tag, error)
+	// GetByIDs retrieves the tags with ids in one query
+	GetByIDs(ctx context.Context, ids []string) ([]*Tag, error)
 	List(ctx context.Context, search string, limit, offset int) ([]*Tag, int, error)
 	Create(ctx context.Context, tag *Tag) error
 	Update(ctx context.Context, tag *Tag) error
 	Delete(ctx context.Context, id string) error
+	// CountDatasets counts the datasets referencing the tag
+	CountDatasets(ctx context.Context, id string) (int, error)
+	// ReassignAndDelete moves the datasets of a tag to the tag targetID
+	// and deletes it in one transaction
+	ReassignAndDelete(ctx context.Context, id, targetID string) error
+	// ListUsage returns every tag with its dataset count, most used first
+	ListUsage(ctx context.Context) ([]*TagUsage, error)
 }
@@ -0,0 +1,43 @@
+// Package tag is the module managing dataset tags and suggesting them.
+package tag
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	"portal-data-backend/internal/app"
+	delivery "portal-data-backend/internal/tag/delivery/http"
+	"portal-data-backend/internal/tag/repository"
+	"portal-data-backend/internal/tag/usecase"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Module manages tags
+type Module struct {
+	handler *delivery.Handler
+}
+
+// Name implements app.Module
+func (m *Module) Name() string {
+	return "tag"
+}
+
+// Register implements app.Module
+func (m *Module) Register(deps *app.Deps) error {
+	repo := repository.NewTagPostgresRepository(deps.DBRouter)
+	tags := usecase.NewTagUsecase(repo, deps.Services.Audit)
+	deps.Services.Tags = tags
+	m.handler = delivery.NewHandler(tags)
+	return nil
+}
+
+// Routes implements app.Module
+func (m *Module) Routes(r chi.Router, auth func(http.Handler) http.Handler) {
+	delivery.RegisterRoutes(r, m.handler, auth)
+}
+
+// Describe implements app.Module
+func (m *Module) Describe(spec *openapi.Spec) {
+	delivery.Describe(spec)
+}
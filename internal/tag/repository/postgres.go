@@ -2,8 +2,10 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 
+	"portal-data-backend/infrastructure/db"
 	"portal-data-backend/internal/tag/domain"
 	"portal-data-backend/pkg/errors"
 
@@ -11,23 +13,41 @@ import (
 )
 
 type tagPostgresRepository struct {
-	db *sqlx.DB
+	db *db.Router
 }
 
-func NewTagPostgresRepository(db *sqlx.DB) domain.Repository {
-	return &tagPostgresRepository{db: db}
+func NewTagPostgresRepository(router *db.Router) domain.Repository {
+	return &tagPostgresRepository{db: router}
 }
 
 func (r *tagPostgresRepository) GetByID(ctx context.Context, id string) (*domain.Tag, error) {
 	query := `SELECT id, name, slug, created_at FROM tags WHERE id = $1`
 	var tag domain.Tag
-	err := r.db.GetContext(ctx, &tag, query, id)
+	err := r.db.Write(ctx).GetContext(ctx, &tag, query, id)
 	if err != nil {
 		return nil, r.handleError(err)
 	}
 	return &tag, nil
 }
 
+func (r *tagPostgresRepository) GetByIDs(ctx context.Context, ids []string) ([]*domain.Tag, error) {
+	tags := []*domain.Tag{}
+	if len(ids) == 0 {
+		return tags, nil
+	}
+
+	query, args, err := sqlx.In(`SELECT id, name, slug, created_at FROM tags WHERE id IN (?)`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+
+	conn := r.db.Read(ctx)
+	if err := conn.SelectContext(ctx, &tags, conn.Rebind(query), args...); err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+	return tags, nil
+}
+
 func (r *tagPostgresRepository) List(ctx context.Context, search string, limit, offset int) ([]*domain.Tag, int, error) {
 	whereClause := "WHERE 1=1"
 	args := []interface{}{}
@@ -42,7 +62,7 @@ func (r *tagPostgresRepository) List(ctx context.Context, search string, limit,
 
 	countQuery := "SELECT COUNT(*) FROM tags " + whereClause
 	var total int
-	err := r.db.GetContext(ctx, &total, countQuery, args...)
+	err := r.db.Read(ctx).GetContext(ctx, &total, countQuery, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count tags: %w", err)
 	}
@@ -51,7 +71,7 @@ func (r *tagPostgresRepository) List(ctx context.Context, search string, limit,
 	args = append(args, limit, offset)
 
 	var tags []*domain.Tag
-	err = r.db.SelectContext(ctx, &tags, query, args...)
+	err = r.db.Read(ctx).SelectContext(ctx, &tags, query, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to list tags: %w", err)
 	}
@@ -61,7 +81,7 @@ func (r *tagPostgresRepository) List(ctx context.Context, search string, limit,
 
 func (r *tagPostgresRepository) Create(ctx context.Context, tag *domain.Tag) error {
 	query := `INSERT INTO tags (id, name, slug, created_at) VALUES (:id, :name, :slug, :created_at)`
-	_, err := r.db.NamedExecContext(ctx, query, tag)
+	_, err := r.db.Write(ctx).NamedExecContext(ctx, query, tag)
 	if err != nil {
 		return fmt.Errorf("failed to create tag: %w", err)
 	}
@@ -70,7 +90,7 @@ func (r *tagPostgresRepository) Create(ctx context.Context, tag *domain.Tag) err
 
 func (r *tagPostgresRepository) Update(ctx context.Context, tag *domain.Tag) error {
 	query := `UPDATE tags SET name = :name, slug = :slug WHERE id = :id`
-	result, err := r.db.NamedExecContext(ctx, query, tag)
+	result, err := r.db.Write(ctx).NamedExecContext(ctx, query, tag)
 	if err != nil {
 		return fmt.Errorf("failed to update tag: %w", err)
 	}
@@ -84,7 +104,7 @@ func (r *tagPostgresRepository) Update(ctx context.Context, tag *domain.Tag) err
 
 func (r *tagPostgresRepository) Delete(ctx context.Context, id string) error {
 	query := `DELETE FROM tags WHERE id = $1`
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := r.db.Write(ctx).ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete tag: %w", err)
 	}
@@ -96,9 +116,80 @@ func (r *tagPostgresRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+func (r *tagPostgresRepository) ListUsage(ctx context.Context) ([]*domain.TagUsage, error) {
+	query := `
+		SELECT t.id, t.name, t.slug, t.created_at, COUNT(dtl.dataset_id) AS usage
+		FROM tags t
+		LEFT JOIN dataset_tag_link dtl ON t.id = dtl.tag_id
+		GROUP BY t.id, t.name, t.slug, t.created_at
+		ORDER BY usage DESC, t.name ASC
+	`
+
+	var tags []*domain.TagUsage
+	err := r.db.Read(ctx).SelectContext(ctx, &tags, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tag usage: %w", err)
+	}
+	return tags, nil
+}
+
+func (r *tagPostgresRepository) CountDatasets(ctx context.Context, id string) (int, error) {
+	query := `SELECT COUNT(DISTINCT dataset_id) FROM dataset_tag_link WHERE tag_id = $1`
+	var count int
+	if err := r.db.Write(ctx).GetContext(ctx, &count, query, id); err != nil {
+		return 0, fmt.Errorf("failed to count tag datasets: %w", err)
+	}
+	return count, nil
+}
+
+func (r *tagPostgresRepository) ReassignAndDelete(ctx context.Context, id, targetID string) error {
+	return r.db.WithinTx(ctx, func(ctx context.Context) error {
+		tx := r.db.Write(ctx)
+
+		var exists bool
+		if err := tx.GetContext(ctx, &exists, `SELECT EXISTS (SELECT 1 FROM tags WHERE id = $1)`, targetID); err != nil {
+			return fmt.Errorf("failed to get tag: %w", err)
+		}
+		if !exists {
+			return fmt.Errorf("%w: tag %s to reassign datasets to does not exist", errors.ErrInvalidInput, targetID)
+		}
+
+		// Datasets already linked to the target keep a single link
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO dataset_tag_link (dataset_id, tag_id)
+			SELECT l.dataset_id, $2 FROM dataset_tag_link l
+			WHERE l.tag_id = $1 AND NOT EXISTS (
+				SELECT 1 FROM dataset_tag_link t WHERE t.dataset_id = l.dataset_id AND t.tag_id = $2
+			)
+		`, id, targetID)
+		if err != nil {
+			return fmt.Errorf("failed to reassign datasets: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `DELETE FROM dataset_tag_link WHERE tag_id = $1`, id)
+		if err != nil {
+			return fmt.Errorf("failed to unlink datasets: %w", err)
+		}
+
+		result, err := tx.ExecContext(ctx, `DELETE FROM tags WHERE id = $1`, id)
+		if err != nil {
+			return fmt.Errorf("failed to delete tag: %w", err)
+		}
+
+		rows, _ := result.RowsAffected()
+		if rows == 0 {
+			return errors.ErrNotFound
+		}
+		return nil
+	})
+}
+
 func (r *tagPostgresRepository) handleError(err error) error {
 	if err == nil {
 		return nil
 	}
+	if err == sql.ErrNoRows {
+		return errors.ErrNotFound
+	}
 	return errors.Wrap(err, "database error")
 }
@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"portal-data-backend/internal/tag/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// csvColumns are the columns of a tag export. usage is informational and
+// ignored on import.
+var csvColumns = []string{"id", "name", "slug", "usage"}
+
+func (u *tagUsecase) Export(ctx context.Context) ([]byte, error) {
+	tags, err := u.tagRepo.ListUsage(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export tags: %w", err)
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write(csvColumns)
+	for _, tag := range tags {
+		writer.Write([]string{tag.ID, tag.Name, tag.Slug, strconv.Itoa(tag.Usage)})
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("failed to export tags: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (u *tagUsecase) Import(ctx context.Context, reader io.Reader) (*domain.ImportResult, error) {
+	rows, err := readCSV(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := u.tagRepo.ListUsage(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to import tags: %w", err)
+	}
+	bySlug := make(map[string]*domain.Tag, len(tags))
+	for _, tag := range tags {
+		bySlug[tag.Slug] = &tag.Tag
+	}
+
+	result := &domain.ImportResult{}
+	seen := map[string]int{}
+	for i, row := range rows {
+		line := i + 2
+		skip := func(format string, args ...interface{}) {
+			result.Skipped++
+			result.Errors = append(result.Errors, domain.ImportError{Row: line, Message: fmt.Sprintf(format, args...)})
+		}
+
+		name := strings.TrimSpace(row["name"])
+		if len([]rune(name)) < 2 {
+			skip("name must be at least 2 characters")
+			continue
+		}
+		slug := strings.TrimSpace(row["slug"])
+		if slug == "" {
+			slug = u.generateSlug(name)
+		}
+		if first, ok := seen[slug]; ok {
+			skip("slug %s already appears on row %d", slug, first)
+			continue
+		}
+		seen[slug] = line
+
+		if tag, ok := bySlug[slug]; ok {
+			tag.Name = name
+			if err := u.tagRepo.Update(ctx, tag); err != nil {
+				return nil, fmt.Errorf("failed to update tag %s: %w", slug, err)
+			}
+			result.Updated++
+			continue
+		}
+
+		tag := &domain.Tag{ID: uuid.New().String(), Name: name, Slug: slug, CreatedAt: time.Now()}
+		if err := u.tagRepo.Create(ctx, tag); err != nil {
+			return nil, fmt.Errorf("failed to create tag %s: %w", slug, err)
+		}
+		bySlug[slug] = tag
+		result.Created++
+	}
+
+	return result, nil
+}
+
+// readCSV reads CSV rows keyed by their lower case column name. The first row
+// holds the column names and must include name.
+func readCSV(reader io.Reader) ([]map[string]string, error) {
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV: %w", err)
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(body, []byte("\xef\xbb\xbf")))).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", pkgErrors.ErrInvalidInput, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: CSV is empty", pkgErrors.ErrInvalidInput)
+	}
+
+	header := records[0]
+	hasName := false
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(header[i]))
+		hasName = hasName || header[i] == "name"
+	}
+	if !hasName {
+		return nil, fmt.Errorf("%w: CSV has no name column", pkgErrors.ErrInvalidInput)
+	}
+
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(header))
+		for i, column := range header {
+			row[column] = record[i]
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
@@ -2,17 +2,36 @@ package usecase
 
 import (
 	"context"
+	"io"
 
 	"portal-data-backend/internal/tag/domain"
 )
 
+// TagReader is the part of the tag module other modules read tags through
+type TagReader interface {
+	// GetByIDs loads the tags with ids in one query, skipping unknown ones
+	GetByIDs(ctx context.Context, ids []string) ([]*domain.TagResponse, error)
+}
+
 // Usecase defines the interface for tag business logic
 type Usecase interface {
+	TagReader
 	GetByID(ctx context.Context, id string) (*domain.TagResponse, error)
 	List(ctx context.Context, req *domain.ListTagsRequest) (*domain.TagListResponse, error)
 	Create(ctx context.Context, req *domain.CreateTagRequest) (*domain.TagResponse, error)
 	Update(ctx context.Context, id string, req *domain.UpdateTagRequest) (*domain.TagResponse, error)
-	Delete(ctx context.Context, id string) error
+	// Delete refuses to delete a tag datasets still reference unless
+	// reassignTo names the tag to move them to
+	Delete(ctx context.Context, id string, reassignTo *string) error
+	// Export writes every tag as CSV
+	Export(ctx context.Context) ([]byte, error)
+	// Import creates or, matched on slug, updates tags from CSV
+	Import(ctx context.Context, reader io.Reader) (*domain.ImportResult, error)
+	// Suggest autocompletes a partial tag name with prefix, substring and
+	// typo-tolerant matches, most used first
+	Suggest(ctx context.Context, req *domain.SuggestTagsRequest) ([]domain.TagSuggestion, error)
+	// SuggestForDataset suggests existing tags mentioned in a dataset title or description
+	SuggestForDataset(ctx context.Context, req *domain.SuggestDatasetTagsRequest) ([]domain.TagSuggestion, error)
 }
 
 var _ Usecase = (*tagUsecase)(nil)
@@ -0,0 +1,218 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"portal-data-backend/internal/tag/domain"
+)
+
+const (
+	defaultSuggestLimit = 10
+	maxSuggestLimit     = 50
+)
+
+// Match kinds of a suggestion, best first
+const (
+	matchPrefix   = "prefix"
+	matchContains = "contains"
+	matchFuzzy    = "fuzzy"
+	matchText     = "text"
+)
+
+var matchRank = map[string]int{matchPrefix: 0, matchContains: 1, matchFuzzy: 2}
+
+type scoredSuggestion struct {
+	suggestion domain.TagSuggestion
+	score      int
+}
+
+func (u *tagUsecase) Suggest(ctx context.Context, req *domain.SuggestTagsRequest) ([]domain.TagSuggestion, error) {
+	query := strings.ToLower(strings.TrimSpace(req.Query))
+	if query == "" {
+		return []domain.TagSuggestion{}, nil
+	}
+
+	tags, err := u.tagRepo.ListUsage(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest tags: %w", err)
+	}
+
+	var scored []scoredSuggestion
+	for _, tag := range tags {
+		if match := matchQuery(tag, query); match != "" {
+			scored = append(scored, scoredSuggestion{suggestion: toSuggestion(tag, match), score: -matchRank[match]})
+		}
+	}
+	return topSuggestions(scored, req.Limit), nil
+}
+
+func (u *tagUsecase) SuggestForDataset(ctx context.Context, req *domain.SuggestDatasetTagsRequest) ([]domain.TagSuggestion, error) {
+	title := words(req.Title)
+	description := words(req.Description)
+	attached := make(map[string]bool, len(req.TagIDs))
+	for _, id := range req.TagIDs {
+		attached[id] = true
+	}
+
+	tags, err := u.tagRepo.ListUsage(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest tags: %w", err)
+	}
+
+	var scored []scoredSuggestion
+	for _, tag := range tags {
+		if attached[tag.ID] {
+			continue
+		}
+		phrase := words(tag.Name)
+		if len(phrase) == 0 {
+			continue
+		}
+		// A mention in the title weighs more than one in the description
+		score := 2*countPhrase(title, phrase) + countPhrase(description, phrase)
+		if score > 0 {
+			scored = append(scored, scoredSuggestion{suggestion: toSuggestion(tag, matchText), score: score})
+		}
+	}
+	return topSuggestions(scored, req.Limit), nil
+}
+
+// matchQuery reports how a tag matches a partial tag name, or "" if it does not
+func matchQuery(tag *domain.TagUsage, query string) string {
+	name := strings.ToLower(tag.Name)
+	slug := strings.ToLower(tag.Slug)
+	nameWords := words(tag.Name)
+
+	if strings.HasPrefix(name, query) || strings.HasPrefix(slug, query) {
+		return matchPrefix
+	}
+	for _, word := range nameWords {
+		if strings.HasPrefix(word, query) {
+			return matchPrefix
+		}
+	}
+	if strings.Contains(name, query) || strings.Contains(slug, query) {
+		return matchContains
+	}
+
+	// The query may still be typed, so it is compared with the start of a word
+	typos := allowedTypos(query)
+	if typos == 0 {
+		return ""
+	}
+	for _, word := range append([]string{name}, nameWords...) {
+		start := []rune(word)
+		if len(start) > len([]rune(query)) {
+			start = start[:len([]rune(query))]
+		}
+		if levenshtein([]rune(query), start) <= typos {
+			return matchFuzzy
+		}
+	}
+	return ""
+}
+
+// allowedTypos is how many edits a fuzzy match of word may need
+func allowedTypos(word string) int {
+	switch length := len([]rune(word)); {
+	case length < 4:
+		return 0
+	case length < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// countPhrase counts the occurrences of phrase in text. Words of five or more
+// letters also match with one typo or inflection, such as a plural.
+func countPhrase(text, phrase []string) int {
+	count := 0
+	for i := 0; i+len(phrase) <= len(text); i++ {
+		matched := true
+		for j, word := range phrase {
+			if !similarWord(text[i+j], word) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			count++
+		}
+	}
+	return count
+}
+
+func similarWord(a, b string) bool {
+	if a == b {
+		return true
+	}
+	if len([]rune(a)) < 5 || len([]rune(b)) < 5 {
+		return false
+	}
+	return levenshtein([]rune(a), []rune(b)) <= 1
+}
+
+// words splits text into lower case words of letters and digits
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// levenshtein returns the edit distance between a and b
+func levenshtein(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// topSuggestions orders suggestions by score, keeping the usage order of the
+// repository between equal scores, and returns at most limit of them
+func topSuggestions(scored []scoredSuggestion, limit int) []domain.TagSuggestion {
+	if limit < 1 {
+		limit = defaultSuggestLimit
+	}
+	if limit > maxSuggestLimit {
+		limit = maxSuggestLimit
+	}
+
+	sort.SliceStable(scored, func(i, j int) bool { return scored[i].score > scored[j].score })
+	if len(scored) > limit {
+		scored = scored[:limit]
+	}
+
+	suggestions := make([]domain.TagSuggestion, len(scored))
+	for i, s := range scored {
+		suggestions[i] = s.suggestion
+	}
+	return suggestions
+}
+
+func toSuggestion(tag *domain.TagUsage, match string) domain.TagSuggestion {
+	return domain.TagSuggestion{
+		ID:    tag.ID,
+		Name:  tag.Name,
+		Slug:  tag.Slug,
+		Usage: tag.Usage,
+		Match: match,
+	}
+}
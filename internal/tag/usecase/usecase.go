@@ -7,17 +7,21 @@ import (
 	"strings"
 	"time"
 
+	"portal-data-backend/infrastructure/audit"
 	"portal-data-backend/internal/tag/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
 
 	"github.com/google/uuid"
 )
 
 type tagUsecase struct {
 	tagRepo domain.Repository
+	audit   *audit.Recorder
 }
 
-func NewTagUsecase(tagRepo domain.Repository) *tagUsecase {
-	return &tagUsecase{tagRepo: tagRepo}
+// NewTagUsecase creates the tag usecase. recorder may be nil.
+func NewTagUsecase(tagRepo domain.Repository, recorder *audit.Recorder) *tagUsecase {
+	return &tagUsecase{tagRepo: tagRepo, audit: recorder}
 }
 
 func (u *tagUsecase) GetByID(ctx context.Context, id string) (*domain.TagResponse, error) {
@@ -28,6 +32,19 @@ func (u *tagUsecase) GetByID(ctx context.Context, id string) (*domain.TagRespons
 	return u.toResponse(tag), nil
 }
 
+func (u *tagUsecase) GetByIDs(ctx context.Context, ids []string) ([]*domain.TagResponse, error) {
+	tags, err := u.tagRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+
+	responses := make([]*domain.TagResponse, len(tags))
+	for i, tag := range tags {
+		responses[i] = u.toResponse(tag)
+	}
+	return responses, nil
+}
+
 func (u *tagUsecase) List(ctx context.Context, req *domain.ListTagsRequest) (*domain.TagListResponse, error) {
 	if req.Page < 1 {
 		req.Page = 1
@@ -72,6 +89,7 @@ func (u *tagUsecase) Create(ctx context.Context, req *domain.CreateTagRequest) (
 	if err := u.tagRepo.Create(ctx, tag); err != nil {
 		return nil, fmt.Errorf("failed to create tag: %w", err)
 	}
+	u.audit.Record(ctx, "tags", tag.ID, audit.ActionCreate, nil, tag)
 
 	return u.toResponse(tag), nil
 }
@@ -81,6 +99,7 @@ func (u *tagUsecase) Update(ctx context.Context, id string, req *domain.UpdateTa
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tag: %w", err)
 	}
+	before := *tag
 
 	tag.Name = req.Name
 	tag.Slug = u.generateSlug(req.Name)
@@ -88,14 +107,40 @@ func (u *tagUsecase) Update(ctx context.Context, id string, req *domain.UpdateTa
 	if err := u.tagRepo.Update(ctx, tag); err != nil {
 		return nil, fmt.Errorf("failed to update tag: %w", err)
 	}
+	u.audit.Record(ctx, "tags", tag.ID, audit.ActionUpdate, &before, tag)
 
 	return u.toResponse(tag), nil
 }
 
-func (u *tagUsecase) Delete(ctx context.Context, id string) error {
+func (u *tagUsecase) Delete(ctx context.Context, id string, reassignTo *string) error {
+	tag, err := u.tagRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get tag: %w", err)
+	}
+
+	if reassignTo != nil {
+		if *reassignTo == id {
+			return fmt.Errorf("%w: cannot reassign datasets to the tag being deleted", pkgErrors.ErrInvalidInput)
+		}
+		if err := u.tagRepo.ReassignAndDelete(ctx, id, *reassignTo); err != nil {
+			return fmt.Errorf("failed to delete tag: %w", err)
+		}
+		u.audit.Record(ctx, "tags", id, audit.ActionDelete, tag, nil)
+		return nil
+	}
+
+	count, err := u.tagRepo.CountDatasets(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("%w: tag is used by %d datasets, pass reassign_to to move them to another tag", pkgErrors.ErrInUse, count)
+	}
+
 	if err := u.tagRepo.Delete(ctx, id); err != nil {
 		return fmt.Errorf("failed to delete tag: %w", err)
 	}
+	u.audit.Record(ctx, "tags", id, audit.ActionDelete, tag, nil)
 	return nil
 }
 
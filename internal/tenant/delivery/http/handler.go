@@ -0,0 +1,133 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"portal-data-backend/infrastructure/http/httputil"
+	"portal-data-backend/infrastructure/http/middleware"
+	"portal-data-backend/infrastructure/http/problem"
+	"portal-data-backend/infrastructure/http/response"
+	tenantDomain "portal-data-backend/internal/tenant/domain"
+	"portal-data-backend/internal/tenant/usecase"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	tenantUsecase usecase.Usecase
+}
+
+func NewHandler(tenantUsecase usecase.Usecase) *Handler {
+	return &Handler{tenantUsecase: tenantUsecase}
+}
+
+func (h *Handler) Current(w http.ResponseWriter, r *http.Request) {
+	portal, err := h.tenantUsecase.Current(r.Context())
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Portal retrieved successfully", portal)
+}
+
+func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
+	t, err := h.tenantUsecase.GetByID(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Tenant retrieved successfully", t)
+}
+
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	req := &tenantDomain.ListTenantsRequest{
+		Page:   parseIntQuery(r, "page", 1),
+		Limit:  parseIntQuery(r, "limit", 20),
+		Status: r.URL.Query().Get("status"),
+	}
+
+	resp, err := h.tenantUsecase.List(r.Context(), req)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Tenants retrieved successfully", resp)
+}
+
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	req, ok := httputil.Decode[tenantDomain.CreateTenantRequest](w, r)
+	if !ok {
+		return
+	}
+
+	t, err := h.tenantUsecase.Create(r.Context(), req)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.Created(w, response.CodeCreated, "Tenant created successfully", t)
+}
+
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	req, ok := httputil.Decode[tenantDomain.UpdateTenantRequest](w, r)
+	if !ok {
+		return
+	}
+
+	t, err := h.tenantUsecase.Update(r.Context(), chi.URLParam(r, "id"), req)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Tenant updated successfully", t)
+}
+
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.tenantUsecase.Delete(r.Context(), chi.URLParam(r, "id")); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Tenant deleted successfully", nil)
+}
+
+// errorMapper maps the errors of the tenant module on top of the shared ones
+var errorMapper = problem.Default.With(
+	problem.Mapping{Err: pkgErrors.ErrNotFound, Status: http.StatusNotFound, Code: response.CodeNotFound, Message: "Tenant not found"},
+)
+
+func (h *Handler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	errorMapper.Write(w, r, err)
+}
+
+func parseIntQuery(r *http.Request, key string, defaultValue int) int {
+	if value := r.URL.Query().Get(key); value != "" {
+		if intValue, err := strconv.Atoi(value); err == nil {
+			return intValue
+		}
+	}
+	return defaultValue
+}
+
+// RegisterRoutes registers the tenant routes. The portal a request is for is
+// public; tenants are managed by users with one of superAdminRoles.
+func RegisterRoutes(r chi.Router, handler *Handler, auth func(http.Handler) http.Handler, superAdminRoles []string) {
+	r.Get("/tenant", handler.Current)
+
+	r.Route("/admin/tenants", func(r chi.Router) {
+		r.Use(auth)
+		r.Use(middleware.RequireRole(superAdminRoles...))
+		r.Get("/", handler.List)
+		r.Post("/", handler.Create)
+		r.Get("/{id}", handler.GetByID)
+		r.Put("/{id}", handler.Update)
+		r.Delete("/{id}", handler.Delete)
+	})
+}
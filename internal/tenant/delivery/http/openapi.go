@@ -0,0 +1,19 @@
+package http
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	tenantDomain "portal-data-backend/internal/tenant/domain"
+)
+
+// Describe adds the tenant routes to the API specification
+func Describe(spec *openapi.Spec) {
+	api := spec.Tag("tenants", "Portals hosted by the deployment and their branding")
+	api.Get("/tenant", "Get the portal of the request").Public().Returns(http.StatusOK, tenantDomain.PortalResponse{})
+	api.Get("/admin/tenants", "List tenants").Query(tenantDomain.ListTenantsRequest{}).Returns(http.StatusOK, tenantDomain.TenantListResponse{})
+	api.Post("/admin/tenants", "Create tenant").Body(tenantDomain.CreateTenantRequest{}).Returns(http.StatusCreated, tenantDomain.TenantResponse{})
+	api.Get("/admin/tenants/{id}", "Get tenant").Returns(http.StatusOK, tenantDomain.TenantResponse{})
+	api.Put("/admin/tenants/{id}", "Update tenant").Body(tenantDomain.UpdateTenantRequest{}).Returns(http.StatusOK, tenantDomain.TenantResponse{})
+	api.Delete("/admin/tenants/{id}", "Delete tenant").Returns(http.StatusOK, nil)
+}
@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"time"
+
+	"portal-data-backend/infrastructure/tenant"
+
+	"github.com/lib/pq"
+)
+
+// Tenant is a portal hosted by the deployment as stored
+type Tenant struct {
+	ID     string         `db:"id" json:"id"`
+	Name   string         `db:"name" json:"name"`
+	Hosts  pq.StringArray `db:"hosts" json:"hosts"`
+	Status string         `db:"status" json:"status"`
+	// Branding is a JSON encoded tenant.Branding
+	Branding  string    `db:"branding" json:"branding"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// CreateTenantRequest represents create tenant input
+type CreateTenantRequest struct {
+	// ID is the code of the tenant: lowercase letters, digits and hyphens
+	ID       string          `json:"id" validate:"required,max=63"`
+	Name     string          `json:"name" validate:"required,min=2,max=255"`
+	Hosts    []string        `json:"hosts,omitempty" validate:"omitempty,dive,hostname"`
+	Branding tenant.Branding `json:"branding"`
+}
+
+// UpdateTenantRequest represents update tenant input
+type UpdateTenantRequest struct {
+	Name     string          `json:"name" validate:"required,min=2,max=255"`
+	Hosts    []string        `json:"hosts,omitempty" validate:"omitempty,dive,hostname"`
+	Status   string          `json:"status" validate:"required,oneof=active disabled"`
+	Branding tenant.Branding `json:"branding"`
+}
+
+// ListTenantsRequest represents list tenants input
+type ListTenantsRequest struct {
+	Page   int    `json:"page" validate:"min=1"`
+	Limit  int    `json:"limit" validate:"min=1,max=100"`
+	Status string `json:"status,omitempty"`
+}
+
+// TenantResponse represents a tenant
+type TenantResponse struct {
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Hosts     []string        `json:"hosts"`
+	Status    string          `json:"status"`
+	Branding  tenant.Branding `json:"branding"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// TenantListResponse represents a page of tenants
+type TenantListResponse struct {
+	Tenants []TenantResponse `json:"tenants"`
+	Meta    ListMeta         `json:"meta"`
+}
+
+// PortalResponse is the public view of the tenant a request is for
+type PortalResponse struct {
+	ID       string          `json:"id"`
+	Name     string          `json:"name"`
+	Branding tenant.Branding `json:"branding"`
+}
+
+type ListMeta struct {
+	Page      int `json:"page"`
+	Limit     int `json:"limit"`
+	Total     int `json:"total"`
+	TotalPage int `json:"total_page"`
+}
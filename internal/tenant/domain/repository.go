@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"context"
+
+	"portal-data-backend/infrastructure/tenant"
+)
+
+type Repository interface {
+	// Store lists the active tenants requests are resolved to
+	tenant.Store
+
+	GetByID(ctx context.Context, id string) (*Tenant, error)
+	List(ctx context.Context, status string, limit, offset int) ([]*Tenant, int, error)
+	Create(ctx context.Context, t *Tenant) error
+	Update(ctx context.Context, t *Tenant) error
+	Delete(ctx context.Context, id string) error
+	// CountOrganizations counts the organizations of the tenant
+	CountOrganizations(ctx context.Context, id string) (int, error)
+}
@@ -0,0 +1,46 @@
+// Package tenant is the module managing the portals a deployment hosts.
+package tenant
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	"portal-data-backend/infrastructure/tenant"
+	"portal-data-backend/internal/app"
+	delivery "portal-data-backend/internal/tenant/delivery/http"
+	"portal-data-backend/internal/tenant/repository"
+	"portal-data-backend/internal/tenant/usecase"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Module manages tenants and provides the resolver requests are scoped with
+type Module struct {
+	handler         *delivery.Handler
+	superAdminRoles []string
+}
+
+// Name implements app.Module
+func (m *Module) Name() string {
+	return "tenant"
+}
+
+// Register implements app.Module
+func (m *Module) Register(deps *app.Deps) error {
+	cfg := deps.Config.Tenant
+	repo := repository.NewTenantPostgresRepository(deps.DB)
+	deps.Services.Tenants = tenant.NewResolver(repo, cfg.CacheTTL)
+	m.handler = delivery.NewHandler(usecase.NewTenantUsecase(repo, deps.Services.Tenants, cfg.Default, deps.Services.Audit))
+	m.superAdminRoles = cfg.SuperAdminRoles
+	return nil
+}
+
+// Routes implements app.Module
+func (m *Module) Routes(r chi.Router, auth func(http.Handler) http.Handler) {
+	delivery.RegisterRoutes(r, m.handler, auth, m.superAdminRoles)
+}
+
+// Describe implements app.Module
+func (m *Module) Describe(spec *openapi.Spec) {
+	delivery.Describe(spec)
+}
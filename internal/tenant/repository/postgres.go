@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"portal-data-backend/infrastructure/db"
+	"portal-data-backend/infrastructure/tenant"
+	"portal-data-backend/internal/tenant/domain"
+	"portal-data-backend/pkg/errors"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type tenantPostgresRepository struct {
+	db *sqlx.DB
+}
+
+// NewTenantPostgresRepository creates a new tenant repository
+func NewTenantPostgresRepository(db *sqlx.DB) domain.Repository {
+	return &tenantPostgresRepository{db: db}
+}
+
+const tenantColumns = `id, name, hosts, status, branding, created_at, updated_at`
+
+func (r *tenantPostgresRepository) GetByID(ctx context.Context, id string) (*domain.Tenant, error) {
+	query := `SELECT ` + tenantColumns + ` FROM tenants WHERE id = $1`
+	var t domain.Tenant
+	err := db.Conn(ctx, r.db).GetContext(ctx, &t, query, id)
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+	return &t, nil
+}
+
+func (r *tenantPostgresRepository) List(ctx context.Context, status string, limit, offset int) ([]*domain.Tenant, int, error) {
+	whereClause := "WHERE 1=1"
+	args := []interface{}{}
+	argCount := 1
+
+	if status != "" {
+		whereClause += fmt.Sprintf(" AND status = $%d", argCount)
+		args = append(args, status)
+		argCount++
+	}
+
+	countQuery := "SELECT COUNT(*) FROM tenants " + whereClause
+	var total int
+	err := db.Conn(ctx, r.db).GetContext(ctx, &total, countQuery, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count tenants: %w", err)
+	}
+
+	query := "SELECT " + tenantColumns + " FROM tenants " + whereClause + " ORDER BY id ASC LIMIT $" + fmt.Sprintf("%d", argCount) + " OFFSET $" + fmt.Sprintf("%d", argCount+1)
+	args = append(args, limit, offset)
+
+	var tenants []*domain.Tenant
+	err = db.Conn(ctx, r.db).SelectContext(ctx, &tenants, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list tenants: %w", err)
+	}
+	return tenants, total, nil
+}
+
+// ListActive implements tenant.Store
+func (r *tenantPostgresRepository) ListActive(ctx context.Context) ([]*tenant.Tenant, error) {
+	query := `SELECT ` + tenantColumns + ` FROM tenants WHERE status = $1`
+	var rows []*domain.Tenant
+	if err := db.Conn(ctx, r.db).SelectContext(ctx, &rows, query, tenant.StatusActive); err != nil {
+		return nil, fmt.Errorf("failed to list active tenants: %w", err)
+	}
+
+	tenants := make([]*tenant.Tenant, len(rows))
+	for i, row := range rows {
+		t := &tenant.Tenant{ID: row.ID, Name: row.Name, Hosts: row.Hosts, Status: row.Status}
+		if err := json.Unmarshal([]byte(row.Branding), &t.Branding); err != nil {
+			return nil, fmt.Errorf("failed to decode branding of tenant %s: %w", row.ID, err)
+		}
+		tenants[i] = t
+	}
+	return tenants, nil
+}
+
+func (r *tenantPostgresRepository) Create(ctx context.Context, t *domain.Tenant) error {
+	query := `
+		INSERT INTO tenants (id, name, hosts, status, branding, created_at, updated_at)
+		VALUES (:id, :name, :hosts, :status, :branding, :created_at, :updated_at)
+	`
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, t)
+	if err != nil {
+		return fmt.Errorf("failed to create tenant: %w", err)
+	}
+	return nil
+}
+
+func (r *tenantPostgresRepository) Update(ctx context.Context, t *domain.Tenant) error {
+	query := `
+		UPDATE tenants
+		SET name = :name, hosts = :hosts, status = :status, branding = :branding, updated_at = :updated_at
+		WHERE id = :id
+	`
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, t)
+	if err != nil {
+		return fmt.Errorf("failed to update tenant: %w", err)
+	}
+	return nil
+}
+
+func (r *tenantPostgresRepository) Delete(ctx context.Context, id string) error {
+	_, err := db.Conn(ctx, r.db).ExecContext(ctx, `DELETE FROM tenants WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete tenant: %w", err)
+	}
+	return nil
+}
+
+func (r *tenantPostgresRepository) CountOrganizations(ctx context.Context, id string) (int, error) {
+	var count int
+	err := db.Conn(ctx, r.db).GetContext(ctx, &count, `SELECT COUNT(*) FROM organizations WHERE tenant_id = $1`, id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count organizations: %w", err)
+	}
+	return count, nil
+}
+
+func (r *tenantPostgresRepository) handleError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if err == sql.ErrNoRows {
+		return errors.ErrNotFound
+	}
+	return errors.Wrap(err, "database error")
+}
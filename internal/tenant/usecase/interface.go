@@ -0,0 +1,20 @@
+package usecase
+
+import (
+	"context"
+
+	"portal-data-backend/internal/tenant/domain"
+)
+
+type Usecase interface {
+	GetByID(ctx context.Context, id string) (*domain.TenantResponse, error)
+	List(ctx context.Context, req *domain.ListTenantsRequest) (*domain.TenantListResponse, error)
+	Create(ctx context.Context, req *domain.CreateTenantRequest) (*domain.TenantResponse, error)
+	Update(ctx context.Context, id string, req *domain.UpdateTenantRequest) (*domain.TenantResponse, error)
+	// Delete refuses to delete the default tenant and tenants that still
+	// have organizations
+	Delete(ctx context.Context, id string) error
+	// Current returns the portal the request is for, the default tenant
+	// when the deployment hosts a single portal
+	Current(ctx context.Context) (*domain.PortalResponse, error)
+}
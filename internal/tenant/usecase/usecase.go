@@ -0,0 +1,193 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"portal-data-backend/infrastructure/audit"
+	"portal-data-backend/infrastructure/tenant"
+	"portal-data-backend/internal/tenant/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+)
+
+type tenantUsecase struct {
+	repo      domain.Repository
+	resolver  *tenant.Resolver
+	defaultID string
+	audit     *audit.Recorder
+}
+
+// NewTenantUsecase creates the tenant usecase. Changes invalidate the tenants
+// of resolver; defaultID is the tenant of requests not scoped to one.
+// recorder may be nil.
+func NewTenantUsecase(repo domain.Repository, resolver *tenant.Resolver, defaultID string, recorder *audit.Recorder) Usecase {
+	return &tenantUsecase{repo: repo, resolver: resolver, defaultID: defaultID, audit: recorder}
+}
+
+func (u *tenantUsecase) GetByID(ctx context.Context, id string) (*domain.TenantResponse, error) {
+	t, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+	return u.toResponse(t), nil
+}
+
+func (u *tenantUsecase) List(ctx context.Context, req *domain.ListTenantsRequest) (*domain.TenantListResponse, error) {
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.Limit < 1 {
+		req.Limit = 20
+	}
+
+	offset := (req.Page - 1) * req.Limit
+
+	tenants, total, err := u.repo.List(ctx, req.Status, req.Limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenants: %w", err)
+	}
+
+	responses := make([]domain.TenantResponse, len(tenants))
+	for i, t := range tenants {
+		responses[i] = *u.toResponse(t)
+	}
+
+	totalPage := int(math.Ceil(float64(total) / float64(req.Limit)))
+
+	return &domain.TenantListResponse{
+		Tenants: responses,
+		Meta: domain.ListMeta{
+			Page:      req.Page,
+			Limit:     req.Limit,
+			Total:     total,
+			TotalPage: totalPage,
+		},
+	}, nil
+}
+
+func (u *tenantUsecase) Create(ctx context.Context, req *domain.CreateTenantRequest) (*domain.TenantResponse, error) {
+	if !tenant.ValidID(req.ID) {
+		return nil, fmt.Errorf("%w: tenant id must be lowercase letters, digits and hyphens", pkgErrors.ErrInvalidInput)
+	}
+	branding, err := json.Marshal(req.Branding)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	t := &domain.Tenant{
+		ID:        req.ID,
+		Name:      req.Name,
+		Hosts:     normalizeHosts(req.Hosts),
+		Status:    tenant.StatusActive,
+		Branding:  string(branding),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := u.repo.Create(ctx, t); err != nil {
+		return nil, fmt.Errorf("failed to create tenant: %w", err)
+	}
+	u.resolver.Invalidate()
+	u.audit.Record(ctx, "tenants", t.ID, audit.ActionCreate, nil, t)
+
+	return u.toResponse(t), nil
+}
+
+func (u *tenantUsecase) Update(ctx context.Context, id string, req *domain.UpdateTenantRequest) (*domain.TenantResponse, error) {
+	t, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+	if id == u.defaultID && req.Status != tenant.StatusActive {
+		return nil, fmt.Errorf("%w: the default tenant cannot be disabled", pkgErrors.ErrInvalidInput)
+	}
+	branding, err := json.Marshal(req.Branding)
+	if err != nil {
+		return nil, err
+	}
+	before := *t
+
+	t.Name = req.Name
+	t.Hosts = normalizeHosts(req.Hosts)
+	t.Status = req.Status
+	t.Branding = string(branding)
+	t.UpdatedAt = time.Now()
+
+	if err := u.repo.Update(ctx, t); err != nil {
+		return nil, fmt.Errorf("failed to update tenant: %w", err)
+	}
+	u.resolver.Invalidate()
+	u.audit.Record(ctx, "tenants", t.ID, audit.ActionUpdate, &before, t)
+
+	return u.toResponse(t), nil
+}
+
+func (u *tenantUsecase) Delete(ctx context.Context, id string) error {
+	t, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get tenant: %w", err)
+	}
+	if id == u.defaultID {
+		return fmt.Errorf("%w: the default tenant cannot be deleted", pkgErrors.ErrInvalidInput)
+	}
+
+	count, err := u.repo.CountOrganizations(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete tenant: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("%w: tenant has %d organizations, disable it instead", pkgErrors.ErrInUse, count)
+	}
+
+	if err := u.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete tenant: %w", err)
+	}
+	u.resolver.Invalidate()
+	u.audit.Record(ctx, "tenants", id, audit.ActionDelete, t, nil)
+	return nil
+}
+
+func (u *tenantUsecase) Current(ctx context.Context) (*domain.PortalResponse, error) {
+	if t := tenant.FromContext(ctx); t != nil {
+		return &domain.PortalResponse{ID: t.ID, Name: t.Name, Branding: t.Branding}, nil
+	}
+
+	t, err := u.repo.GetByID(ctx, u.defaultID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+	response := u.toResponse(t)
+	return &domain.PortalResponse{ID: response.ID, Name: response.Name, Branding: response.Branding}, nil
+}
+
+func (u *tenantUsecase) toResponse(t *domain.Tenant) *domain.TenantResponse {
+	response := &domain.TenantResponse{
+		ID:        t.ID,
+		Name:      t.Name,
+		Hosts:     t.Hosts,
+		Status:    t.Status,
+		CreatedAt: t.CreatedAt,
+		UpdatedAt: t.UpdatedAt,
+	}
+	if response.Hosts == nil {
+		response.Hosts = []string{}
+	}
+	// Branding is written by this usecase; a value that does not decode is
+	// left empty rather than failing the read
+	_ = json.Unmarshal([]byte(t.Branding), &response.Branding)
+	return response
+}
+
+// normalizeHosts lowercases hosts, as hostnames are matched without case
+func normalizeHosts(hosts []string) []string {
+	normalized := make([]string, len(hosts))
+	for i, host := range hosts {
+		normalized[i] = strings.ToLower(host)
+	}
+	return normalized
+}
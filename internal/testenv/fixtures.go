@@ -0,0 +1,27 @@
+package testenv
+
+import (
+	"embed"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+)
+
+//go:embed fixtures/*.sql
+var fixtures embed.FS
+
+// Fixtures loads the named fixtures of fixtures/ into conn, in order:
+// "catalog" holds organizations, datasets and their references,
+// "data_rows" the rows of a catalog dataset
+func Fixtures(t *testing.T, conn *sqlx.DB, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		statements, err := fixtures.ReadFile("fixtures/" + name + ".sql")
+		if err != nil {
+			t.Fatalf("Unknown fixture %q", name)
+		}
+		if _, err := conn.Exec(string(statements)); err != nil {
+			t.Fatalf("Failed to load fixture %q: %v", name, err)
+		}
+	}
+}
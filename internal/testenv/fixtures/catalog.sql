@@ -0,0 +1,40 @@
+-- A small catalog: two organizations of the default tenant and one of the
+-- jabar tenant, their datasets and the references those use. Deleted
+-- records are kept to check they stay out of reads.
+--
+-- IDs start with a digit per table: 1 organizations, 2 datasets, 3 units,
+-- 4 business fields, 5 topics, 6 tags, 9 users.
+
+INSERT INTO tenants (id, name, hosts) VALUES ('jabar', 'Jawa Barat', '{data.jabarprov.go.id}');
+
+INSERT INTO organizations (id, code, name, slug, description, email, total_datasets, public_datasets, status, created_by, created_at, updated_at, names, descriptions, tenant_id, deleted_at) VALUES
+    ('10000000-0000-0000-0000-000000000001', 'DISKOMINFO', 'Dinas Komunikasi dan Informatika', 'diskominfo', 'Communication and informatics office', 'diskominfo@example.go.id', 2, 1, 'active', '90000000-0000-0000-0000-000000000001', '2026-01-01T00:00:00Z', '2026-01-01T00:00:00Z', '{"en": "Communication and Informatics Office"}', '{}', 'default', NULL),
+    ('10000000-0000-0000-0000-000000000002', 'DINKES', 'Dinas Kesehatan', 'dinkes', NULL, NULL, 1, 0, 'inactive', '90000000-0000-0000-0000-000000000001', '2026-01-02T00:00:00Z', '2026-01-02T00:00:00Z', '{}', '{}', 'default', NULL),
+    ('10000000-0000-0000-0000-000000000003', 'BAPPEDA', 'Badan Perencanaan Pembangunan Daerah', 'bappeda', NULL, NULL, 1, 1, 'active', '90000000-0000-0000-0000-000000000002', '2026-01-03T00:00:00Z', '2026-01-03T00:00:00Z', '{}', '{}', 'jabar', NULL),
+    ('10000000-0000-0000-0000-000000000004', 'DISHUB', 'Dinas Perhubungan', 'dishub', NULL, NULL, 0, 0, 'active', '90000000-0000-0000-0000-000000000001', '2026-01-04T00:00:00Z', '2026-01-04T00:00:00Z', '{}', '{}', 'default', '2026-02-01T00:00:00Z');
+
+INSERT INTO units (id, name, symbol, created_at) VALUES
+    ('30000000-0000-0000-0000-000000000001', 'Persen', '%', '2026-01-01T00:00:00Z'),
+    ('30000000-0000-0000-0000-000000000002', 'Jiwa', 'jiwa', '2026-01-01T00:00:00Z');
+
+INSERT INTO business_fields (id, name, slug, created_at) VALUES
+    ('40000000-0000-0000-0000-000000000001', 'Kesehatan', 'kesehatan', '2026-01-01T00:00:00Z');
+
+INSERT INTO topics (id, name, slug, created_at) VALUES
+    ('50000000-0000-0000-0000-000000000001', 'Kependudukan', 'kependudukan', '2026-01-01T00:00:00Z');
+
+INSERT INTO tags (id, name, slug, created_at) VALUES
+    ('60000000-0000-0000-0000-000000000001', 'penduduk', 'penduduk', '2026-01-01T00:00:00Z'),
+    ('60000000-0000-0000-0000-000000000002', 'kesehatan', 'kesehatan', '2026-01-01T00:00:00Z');
+
+INSERT INTO datasets (id, name, slug, description, coverage_start, coverage_end, frequency, unit_id, business_field_id, topic_id, organization_id, classification, category, validation_status, metadatas, created_by, created_at, updated_at, is_highlight, status, names, descriptions, deleted_at) VALUES
+    ('20000000-0000-0000-0000-000000000001', 'Jumlah Penduduk per Kecamatan', 'jumlah-penduduk-per-kecamatan', 'Population by district', '2020-01-01', '2024-12-31', 'annual', '30000000-0000-0000-0000-000000000002', NULL, '50000000-0000-0000-0000-000000000001', '10000000-0000-0000-0000-000000000001', 'public', 'statistik', 'valid', '{"source": "BPS"}', '90000000-0000-0000-0000-000000000001', '2026-01-10T00:00:00Z', '2026-01-10T00:00:00Z', TRUE, 'published', '{"en": "Population by District"}', '{}', NULL),
+    ('20000000-0000-0000-0000-000000000002', 'Cakupan Imunisasi Dasar', 'cakupan-imunisasi-dasar', NULL, NULL, NULL, 'annual', '30000000-0000-0000-0000-000000000001', '40000000-0000-0000-0000-000000000001', NULL, '10000000-0000-0000-0000-000000000002', 'internal', 'statistik', 'pending', NULL, '90000000-0000-0000-0000-000000000001', '2026-01-11T00:00:00Z', '2026-01-11T00:00:00Z', FALSE, 'draft', '{}', '{}', NULL),
+    ('20000000-0000-0000-0000-000000000003', 'Akses Internet Desa', 'akses-internet-desa', 'Villages with internet access', NULL, NULL, NULL, NULL, NULL, NULL, '10000000-0000-0000-0000-000000000001', 'internal', 'survei', 'valid', NULL, '90000000-0000-0000-0000-000000000001', '2026-01-12T00:00:00Z', '2026-01-12T00:00:00Z', FALSE, 'archived', '{}', '{}', NULL),
+    ('20000000-0000-0000-0000-000000000004', 'Indeks Pembangunan Manusia', 'indeks-pembangunan-manusia', NULL, '2010-01-01', NULL, 'annual', NULL, NULL, NULL, '10000000-0000-0000-0000-000000000003', 'public', 'statistik', 'valid', NULL, '90000000-0000-0000-0000-000000000002', '2026-01-13T00:00:00Z', '2026-01-13T00:00:00Z', FALSE, 'published', '{}', '{}', NULL),
+    ('20000000-0000-0000-0000-000000000005', 'Jumlah Penduduk Miskin', 'jumlah-penduduk-miskin', NULL, NULL, NULL, NULL, NULL, NULL, NULL, '10000000-0000-0000-0000-000000000001', 'public', 'statistik', 'valid', NULL, '90000000-0000-0000-0000-000000000001', '2026-01-14T00:00:00Z', '2026-01-14T00:00:00Z', FALSE, 'published', '{}', '{}', '2026-02-01T00:00:00Z');
+
+INSERT INTO dataset_tag_link (dataset_id, tag_id) VALUES
+    ('20000000-0000-0000-0000-000000000001', '60000000-0000-0000-0000-000000000001'),
+    ('20000000-0000-0000-0000-000000000002', '60000000-0000-0000-0000-000000000002'),
+    ('20000000-0000-0000-0000-000000000002', '60000000-0000-0000-0000-000000000001');
@@ -0,0 +1,8 @@
+-- Rows of the population dataset of the catalog fixture, which it needs.
+-- IDs start with 7.
+
+INSERT INTO data_rows (id, dataset_id, row_index, data, created_by, created_at, updated_at, deleted_at) VALUES
+    ('70000000-0000-0000-0000-000000000001', '20000000-0000-0000-0000-000000000001', 0, '{"kecamatan": "Coblong", "jumlah": 131000}', '90000000-0000-0000-0000-000000000001', '2026-01-10T00:00:00Z', '2026-01-10T00:00:00Z', NULL),
+    ('70000000-0000-0000-0000-000000000002', '20000000-0000-0000-0000-000000000001', 1, '{"kecamatan": "Sukajadi", "jumlah": 107000}', '90000000-0000-0000-0000-000000000001', '2026-01-10T00:00:00Z', '2026-01-15T00:00:00Z', NULL),
+    ('70000000-0000-0000-0000-000000000003', '20000000-0000-0000-0000-000000000001', 2, '{"kecamatan": "Cidadap", "jumlah": 58000}', '90000000-0000-0000-0000-000000000001', '2026-01-10T00:00:00Z', '2026-01-10T00:00:00Z', NULL),
+    ('70000000-0000-0000-0000-000000000004', '20000000-0000-0000-0000-000000000001', 3, '{"kecamatan": "Bandung Wetan", "jumlah": 30000}', '90000000-0000-0000-0000-000000000001', '2026-01-10T00:00:00Z', '2026-01-10T00:00:00Z', '2026-01-20T00:00:00Z');
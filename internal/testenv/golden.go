@@ -0,0 +1,44 @@
+package testenv
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of the tests run")
+
+// Golden compares got, encoded as indented JSON, with the golden file
+// testdata/<name>.golden.json of the package tested. Running the tests with
+// -update writes the files instead, to be reviewed like code.
+func Golden(t *testing.T, name string, got interface{}) {
+	t.Helper()
+
+	body, err := json.MarshalIndent(got, "", "  ")
+	if err != nil {
+		t.Fatalf("Failed to encode %s: %v", name, err)
+	}
+	body = append(body, '\n')
+
+	path := filepath.Join("testdata", name+".golden.json")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create testdata: %v", err)
+		}
+		if err := os.WriteFile(path, body, 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s, run the tests with -update to create it: %v", path, err)
+	}
+	if !bytes.Equal(want, body) {
+		t.Errorf("Expected %s to match %s, got:\n%s", name, path, body)
+	}
+}
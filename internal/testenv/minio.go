@@ -0,0 +1,101 @@
+package testenv
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"portal-data-backend/infrastructure/config"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+const minioImage = "minio/minio:latest"
+
+var (
+	minioOnce sync.Once
+	minioErr  error
+	// minioServer holds the endpoint and credentials of the server
+	minioServer config.MinIOConfig
+	buckets     atomic.Int64
+)
+
+// MinIO returns the storage configuration of a new bucket for t, which
+// NewMinIOStorage creates. The bucket is deleted with its objects when t
+// ends. t is skipped when neither TEST_MINIO_ENDPOINT nor docker provide a
+// server.
+//
+// TEST_MINIO_ENDPOINT is the host:port of a server, TEST_MINIO_ACCESS_KEY
+// and TEST_MINIO_SECRET_KEY its credentials.
+func MinIO(t *testing.T) *config.MinIOConfig {
+	t.Helper()
+
+	minioOnce.Do(func() { minioErr = setupMinIO() })
+	if minioErr != nil {
+		if minioErr == errNoService {
+			t.Skip("integration tests need TEST_MINIO_ENDPOINT or docker")
+		}
+		t.Fatalf("Failed to set up MinIO: %v", minioErr)
+	}
+
+	cfg := minioServer
+	cfg.Bucket = fmt.Sprintf("test-%d-%d", os.Getpid(), buckets.Add(1))
+	t.Cleanup(func() {
+		client, err := minio.New(cfg.Endpoint, &minio.Options{
+			Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+			Secure: cfg.UseSSL,
+		})
+		if err == nil {
+			client.RemoveBucketWithOptions(context.Background(), cfg.Bucket, minio.RemoveBucketOptions{ForceDelete: true})
+		}
+	})
+	return &cfg
+}
+
+func setupMinIO() error {
+	minioServer = config.MinIOConfig{
+		Endpoint:      os.Getenv("TEST_MINIO_ENDPOINT"),
+		AccessKey:     os.Getenv("TEST_MINIO_ACCESS_KEY"),
+		SecretKey:     os.Getenv("TEST_MINIO_SECRET_KEY"),
+		Timeout:       10 * time.Second,
+		UploadTimeout: time.Minute,
+	}
+	if minioServer.Endpoint != "" {
+		return nil
+	}
+	if !dockerAvailable() {
+		return errNoService
+	}
+
+	minioServer.AccessKey = "minioadmin"
+	minioServer.SecretKey = "minioadmin"
+	c, err := startContainer(minioImage, "9000", []string{
+		"-e", "MINIO_ROOT_USER=" + minioServer.AccessKey,
+		"-e", "MINIO_ROOT_PASSWORD=" + minioServer.SecretKey,
+	}, "server", "/data")
+	if err != nil {
+		return err
+	}
+	minioServer.Endpoint = c.addr
+
+	deadline := time.Now().Add(time.Minute)
+	for {
+		resp, err := http.Get("http://" + c.addr + "/minio/health/live")
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("MinIO at %s did not start", c.addr)
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
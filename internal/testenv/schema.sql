@@ -0,0 +1,151 @@
+-- Base tables of the portal, as they stood before migrations/ started
+-- tracking changes. Tests create them ahead of the migrations; the
+-- migrations add soft deletes, tenants and the other later columns.
+
+CREATE TABLE organizations (
+    id              UUID PRIMARY KEY,
+    code            TEXT NOT NULL UNIQUE,
+    name            TEXT NOT NULL,
+    slug            TEXT NOT NULL UNIQUE,
+    description     TEXT,
+    logo_url        TEXT,
+    phone_number    TEXT,
+    address         TEXT,
+    website_url     TEXT,
+    email           TEXT,
+    total_datasets  INTEGER NOT NULL DEFAULT 0,
+    public_datasets INTEGER NOT NULL DEFAULT 0,
+    total_mapsets   INTEGER NOT NULL DEFAULT 0,
+    public_mapsets  INTEGER NOT NULL DEFAULT 0,
+    status          TEXT NOT NULL DEFAULT 'active',
+    created_by      UUID,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_by      UUID,
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    names           JSONB NOT NULL DEFAULT '{}',
+    descriptions    JSONB NOT NULL DEFAULT '{}'
+);
+
+CREATE TABLE users (
+    id              UUID PRIMARY KEY,
+    organization_id UUID NOT NULL REFERENCES organizations (id),
+    role_id         UUID NOT NULL,
+    name            TEXT NOT NULL,
+    username        TEXT NOT NULL UNIQUE,
+    employee_id     TEXT,
+    position        TEXT,
+    email           TEXT NOT NULL UNIQUE,
+    password_hash   TEXT NOT NULL,
+    address         TEXT,
+    phone           TEXT,
+    thumbnail       TEXT,
+    status          TEXT NOT NULL DEFAULT 'active',
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE tokens (
+    id            UUID PRIMARY KEY,
+    user_id       UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    access_token  TEXT NOT NULL,
+    refresh_token TEXT NOT NULL,
+    expires_at    TIMESTAMPTZ NOT NULL,
+    revoked       BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE units (
+    id         UUID PRIMARY KEY,
+    name       TEXT NOT NULL,
+    symbol     TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE business_fields (
+    id            UUID PRIMARY KEY,
+    name          TEXT NOT NULL,
+    slug          TEXT NOT NULL UNIQUE,
+    names         JSONB NOT NULL DEFAULT '{}',
+    icon_url      TEXT,
+    display_order INTEGER NOT NULL DEFAULT 0,
+    is_featured   BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE topics (
+    id            UUID PRIMARY KEY,
+    name          TEXT NOT NULL,
+    slug          TEXT NOT NULL UNIQUE,
+    names         JSONB NOT NULL DEFAULT '{}',
+    icon_url      TEXT,
+    display_order INTEGER NOT NULL DEFAULT 0,
+    is_featured   BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE tags (
+    id         UUID PRIMARY KEY,
+    name       TEXT NOT NULL,
+    slug       TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE datasets (
+    id                UUID PRIMARY KEY,
+    name              TEXT NOT NULL,
+    slug              TEXT NOT NULL UNIQUE,
+    description       TEXT,
+    period            TEXT,
+    unit_id           UUID REFERENCES units (id),
+    business_field_id UUID REFERENCES business_fields (id),
+    image             TEXT,
+    topic_id          UUID REFERENCES topics (id),
+    organization_id   UUID NOT NULL REFERENCES organizations (id),
+    reference_id      TEXT,
+    classification    TEXT NOT NULL,
+    category          TEXT NOT NULL,
+    data_fixed        BOOLEAN NOT NULL DEFAULT FALSE,
+    validation_status TEXT NOT NULL DEFAULT 'pending',
+    metadatas         JSONB,
+    created_by        UUID NOT NULL,
+    updated_by        UUID,
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    is_highlight      BOOLEAN NOT NULL DEFAULT FALSE,
+    status            TEXT NOT NULL DEFAULT 'draft',
+    names             JSONB NOT NULL DEFAULT '{}',
+    descriptions      JSONB NOT NULL DEFAULT '{}'
+);
+
+CREATE TABLE dataset_tag_link (
+    dataset_id UUID NOT NULL REFERENCES datasets (id) ON DELETE CASCADE,
+    tag_id     UUID NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+    PRIMARY KEY (dataset_id, tag_id)
+);
+
+CREATE TABLE data_rows (
+    id         UUID PRIMARY KEY,
+    dataset_id UUID NOT NULL REFERENCES datasets (id),
+    row_index  INTEGER NOT NULL,
+    data       JSONB NOT NULL,
+    created_by UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_data_rows_dataset_id ON data_rows (dataset_id, row_index);
+
+CREATE TABLE settings (
+    id              UUID PRIMARY KEY,
+    key             TEXT NOT NULL,
+    value           TEXT NOT NULL,
+    type            TEXT NOT NULL DEFAULT 'string',
+    category        TEXT NOT NULL DEFAULT 'system',
+    user_id         UUID,
+    organization_id UUID,
+    is_public       BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deleted_at      TIMESTAMPTZ
+);
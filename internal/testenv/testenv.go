@@ -0,0 +1,109 @@
+// Package testenv runs integration tests against real services: a Postgres
+// database migrated like a deployment and a MinIO bucket. Services come
+// from the environment, TEST_DATABASE_URL and TEST_MINIO_ENDPOINT, or are
+// started in docker containers for the tests of a package; tests needing a
+// service neither provides are skipped.
+//
+// Integration tests carry the integration build tag and call Main from
+// their TestMain, so the containers started are removed:
+//
+//	func TestMain(m *testing.M) {
+//		os.Exit(testenv.Main(m))
+//	}
+package testenv
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Main runs the tests of m and stops the services started for them,
+// returning the exit code of the tests
+func Main(m *testing.M) int {
+	code := m.Run()
+	dropTemplate()
+	stopContainers()
+	return code
+}
+
+// container is a docker container started for the tests of a package
+type container struct {
+	id string
+	// addr is the host:port the published port of the container is
+	// reachable at
+	addr string
+}
+
+// errNoService is the setup error of a service neither the environment nor
+// docker provide
+var errNoService = errors.New("service not available")
+
+var (
+	containersMu sync.Mutex
+	containers   []*container
+)
+
+// dockerAvailable reports whether containers can be started
+func dockerAvailable() bool {
+	if _, err := exec.LookPath("docker"); err != nil {
+		return false
+	}
+	return exec.Command("docker", "info").Run() == nil
+}
+
+// startContainer runs image, publishing port on a random port of the host.
+// args are passed to docker run ahead of the image, command after it.
+func startContainer(image, port string, args []string, command ...string) (*container, error) {
+	run := append([]string{"run", "-d", "--rm", "-p", "127.0.0.1::" + port}, args...)
+	run = append(run, image)
+	run = append(run, command...)
+	id, err := docker(run...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start %s: %w", image, err)
+	}
+
+	c := &container{id: id}
+	containersMu.Lock()
+	containers = append(containers, c)
+	containersMu.Unlock()
+
+	// docker port lists an address per line, IPv4 first
+	published, err := docker("port", id, port+"/tcp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the port of %s: %w", image, err)
+	}
+	line, _, _ := strings.Cut(published, "\n")
+	_, hostPort, err := net.SplitHostPort(strings.TrimSpace(line))
+	if err != nil {
+		return nil, fmt.Errorf("unexpected port %q of %s: %w", line, image, err)
+	}
+	c.addr = net.JoinHostPort("127.0.0.1", hostPort)
+	return c, nil
+}
+
+func stopContainers() {
+	containersMu.Lock()
+	defer containersMu.Unlock()
+	for _, c := range containers {
+		docker("rm", "-f", "-v", c.id)
+	}
+	containers = nil
+}
+
+// docker runs the docker CLI, returning its trimmed output
+func docker(args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("docker", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("docker %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -0,0 +1,21 @@
+package http
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	topicDomain "portal-data-backend/internal/topic/domain"
+)
+
+// Describe adds the topic routes to the API specification
+func Describe(spec *openapi.Spec) {
+	api := spec.Tag("topics", "Topics of datasets")
+	api.Get("/topics", "List topics").Public().Query(topicDomain.ListTopicsRequest{}).Returns(http.StatusOK, topicDomain.TopicListResponse{})
+	api.Post("/topics", "Create topic").Body(topicDomain.CreateTopicRequest{}).Returns(http.StatusCreated, topicDomain.TopicResponse{})
+	api.Get("/topics/export", "Export topics as CSV").ReturnsFile(http.StatusOK, "text/csv")
+	api.Post("/topics/import", "Import topics from CSV").Upload("file").Returns(http.StatusOK, topicDomain.ImportResult{})
+	api.Get("/topics/{id}", "Get topic").Public().Returns(http.StatusOK, topicDomain.TopicResponse{})
+	api.Put("/topics/{id}", "Update topic").Body(topicDomain.UpdateTopicRequest{}).Returns(http.StatusOK, topicDomain.TopicResponse{})
+	api.Post("/topics/{id}/icon", "Upload topic icon").Upload("file").Returns(http.StatusOK, topicDomain.TopicResponse{})
+	api.Delete("/topics/{id}", "Delete topic").Param("reassign_to", false).Returns(http.StatusOK, nil)
+}
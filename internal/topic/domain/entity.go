@@ -5,31 +5,53 @@ import (
 )
 
 type Topic struct {
-	ID        string    `db:"id" json:"id"`
-	Name      string    `db:"name" json:"name"`
-	Slug      string    `db:"slug" json:"slug"`
-	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	ID           string    `db:"id" json:"id"`
+	Name         string    `db:"name" json:"name"`
+	Slug         string    `db:"slug" json:"slug"`
+	Names        string    `db:"names" json:"names"` // JSON object of names by language code
+	IconURL      *string   `db:"icon_url" json:"icon_url,omitempty"`
+	DisplayOrder int       `db:"display_order" json:"display_order"`
+	IsFeatured   bool      `db:"is_featured" json:"is_featured"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+}
+
+// TopicFilter narrows the topics returned by a list
+type TopicFilter struct {
+	Search   string
+	Featured *bool
 }
 
 type CreateTopicRequest struct {
-	Name string `json:"name" validate:"required,min=2"`
+	Name         string            `json:"name" validate:"required,min=2"`
+	Names        map[string]string `json:"names,omitempty" validate:"omitempty,dive,keys,min=2,max=10,endkeys,required"`
+	DisplayOrder int               `json:"display_order"`
+	IsFeatured   bool              `json:"is_featured"`
 }
 
 type UpdateTopicRequest struct {
-	Name string `json:"name" validate:"required,min=2"`
+	Name         string            `json:"name" validate:"required,min=2"`
+	Names        map[string]string `json:"names,omitempty" validate:"omitempty,dive,keys,min=2,max=10,endkeys,required"`
+	DisplayOrder *int              `json:"display_order,omitempty"`
+	IsFeatured   *bool             `json:"is_featured,omitempty"`
 }
 
+// ListTopicsRequest lists topics by display order
 type ListTopicsRequest struct {
-	Page   int    `json:"page" validate:"min=1"`
-	Limit  int    `json:"limit" validate:"min=1,max=100"`
-	Search string `json:"search,omitempty"`
+	Page     int    `json:"page" validate:"min=1"`
+	Limit    int    `json:"limit" validate:"min=1,max=100"`
+	Search   string `json:"search,omitempty"`
+	Featured *bool  `json:"featured,omitempty"`
 }
 
 type TopicResponse struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	Slug      string    `json:"slug"`
-	CreatedAt time.Time `json:"created_at"`
+	ID           string            `json:"id"`
+	Name         string            `json:"name"`
+	Slug         string            `json:"slug"`
+	Names        map[string]string `json:"names"`
+	IconURL      *string           `json:"icon_url,omitempty"`
+	DisplayOrder int               `json:"display_order"`
+	IsFeatured   bool              `json:"is_featured"`
+	CreatedAt    time.Time         `json:"created_at"`
 }
 
 type TopicListResponse struct {
@@ -37,6 +59,20 @@ type TopicListResponse struct {
 	Meta   ListMeta        `json:"meta"`
 }
 
+// ImportResult reports the outcome of a CSV import
+type ImportResult struct {
+	Created int           `json:"created"`
+	Updated int           `json:"updated"`
+	Skipped int           `json:"skipped"`
+	Errors  []ImportError `json:"errors,omitempty"`
+}
+
+// ImportError describes a CSV row that was skipped
+type ImportError struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
 type ListMeta struct {
 	Page      int `json:"page"`
 	Limit     int `json:"limit"`
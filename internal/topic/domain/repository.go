@@ -6,8 +6,17 @@ import (
 
 type Repository interface {
 	GetByID(ctx context.Context, id string) (*Topic, error)
-	List(ctx context.Context, search string, limit, offset int) ([]*Topic, int, error)
+	// GetByIDs retrieves the topics with ids in one query
+	GetByIDs(ctx context.Context, ids []string) ([]*Topic, error)
+	List(ctx context.Context, filter *TopicFilter, limit, offset int) ([]*Topic, int, error)
+	// ListAll returns every topic in display order
+	ListAll(ctx context.Context) ([]*Topic, error)
 	Create(ctx context.Context, topic *Topic) error
 	Update(ctx context.Context, topic *Topic) error
 	Delete(ctx context.Context, id string) error
+	// CountDatasets counts the datasets referencing the topic
+	CountDatasets(ctx context.Context, id string) (int, error)
+	// ReassignAndDelete moves the datasets of a topic to the topic targetID
+	// and deletes it in one transaction
+	ReassignAndDelete(ctx context.Context, id, targetID string) error
 }
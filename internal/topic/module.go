@@ -0,0 +1,47 @@
+// Package topic is the module managing the topics datasets are grouped in.
+package topic
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	"portal-data-backend/internal/app"
+	delivery "portal-data-backend/internal/topic/delivery/http"
+	"portal-data-backend/internal/topic/repository"
+	"portal-data-backend/internal/topic/usecase"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Module manages topics and provides them to other modules
+type Module struct {
+	handler *delivery.Handler
+}
+
+// Name implements app.Module
+func (m *Module) Name() string {
+	return "topic"
+}
+
+// Register implements app.Module
+func (m *Module) Register(deps *app.Deps) error {
+	if deps.Services.Files == nil {
+		return app.MissingServiceError("file")
+	}
+
+	repo := repository.NewTopicPostgresRepository(deps.DBRouter)
+	topics := usecase.NewTopicUsecase(repo, deps.Services.Files, deps.Services.Audit)
+	deps.Services.Topics = topics
+	m.handler = delivery.NewHandler(topics)
+	return nil
+}
+
+// Routes implements app.Module
+func (m *Module) Routes(r chi.Router, auth func(http.Handler) http.Handler) {
+	delivery.RegisterRoutes(r, m.handler, auth)
+}
+
+// Describe implements app.Module
+func (m *Module) Describe(spec *openapi.Spec) {
+	delivery.Describe(spec)
+}
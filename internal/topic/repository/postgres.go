@@ -2,8 +2,10 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 
+	"portal-data-backend/infrastructure/db"
 	"portal-data-backend/internal/topic/domain"
 	"portal-data-backend/pkg/errors"
 
@@ -11,47 +13,70 @@ import (
 )
 
 type topicPostgresRepository struct {
-	db *sqlx.DB
+	db *db.Router
 }
 
-func NewTopicPostgresRepository(db *sqlx.DB) domain.Repository {
-	return &topicPostgresRepository{db: db}
+func NewTopicPostgresRepository(router *db.Router) domain.Repository {
+	return &topicPostgresRepository{db: router}
 }
 
 func (r *topicPostgresRepository) GetByID(ctx context.Context, id string) (*domain.Topic, error) {
-	query := `SELECT id, name, slug, created_at FROM topics WHERE id = $1`
+	query := `SELECT id, name, slug, names, icon_url, display_order, is_featured, created_at FROM topics WHERE id = $1`
 	var topic domain.Topic
-	err := r.db.GetContext(ctx, &topic, query, id)
+	err := r.db.Write(ctx).GetContext(ctx, &topic, query, id)
 	if err != nil {
 		return nil, r.handleError(err)
 	}
 	return &topic, nil
 }
 
-func (r *topicPostgresRepository) List(ctx context.Context, search string, limit, offset int) ([]*domain.Topic, int, error) {
+func (r *topicPostgresRepository) GetByIDs(ctx context.Context, ids []string) ([]*domain.Topic, error) {
+	topics := []*domain.Topic{}
+	if len(ids) == 0 {
+		return topics, nil
+	}
+
+	query, args, err := sqlx.In(`SELECT id, name, slug, names, icon_url, display_order, is_featured, created_at FROM topics WHERE id IN (?)`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get topics: %w", err)
+	}
+
+	conn := r.db.Read(ctx)
+	if err := conn.SelectContext(ctx, &topics, conn.Rebind(query), args...); err != nil {
+		return nil, fmt.Errorf("failed to get topics: %w", err)
+	}
+	return topics, nil
+}
+
+func (r *topicPostgresRepository) List(ctx context.Context, filter *domain.TopicFilter, limit, offset int) ([]*domain.Topic, int, error) {
 	whereClause := "WHERE 1=1"
 	args := []interface{}{}
 	argCount := 1
 
-	if search != "" {
+	if filter.Search != "" {
 		whereClause += fmt.Sprintf(" AND (name ILIKE $%d OR slug ILIKE $%d)", argCount, argCount)
-		searchTerm := "%" + search + "%"
+		searchTerm := "%" + filter.Search + "%"
 		args = append(args, searchTerm, searchTerm)
 		argCount += 2
 	}
+	if filter.Featured != nil {
+		whereClause += fmt.Sprintf(" AND is_featured = $%d", argCount)
+		args = append(args, *filter.Featured)
+		argCount++
+	}
 
 	countQuery := "SELECT COUNT(*) FROM topics " + whereClause
 	var total int
-	err := r.db.GetContext(ctx, &total, countQuery, args...)
+	err := r.db.Read(ctx).GetContext(ctx, &total, countQuery, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count topics: %w", err)
 	}
 
-	query := "SELECT id, name, slug, created_at FROM topics " + whereClause + " ORDER BY name ASC LIMIT $" + fmt.Sprintf("%d", argCount) + " OFFSET $" + fmt.Sprintf("%d", argCount+1)
+	query := "SELECT id, name, slug, names, icon_url, display_order, is_featured, created_at FROM topics " + whereClause + " ORDER BY display_order ASC, name ASC LIMIT $" + fmt.Sprintf("%d", argCount) + " OFFSET $" + fmt.Sprintf("%d", argCount+1)
 	args = append(args, limit, offset)
 
 	var topics []*domain.Topic
-	err = r.db.SelectContext(ctx, &topics, query, args...)
+	err = r.db.Read(ctx).SelectContext(ctx, &topics, query, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to list topics: %w", err)
 	}
@@ -59,9 +84,19 @@ func (r *topicPostgresRepository) List(ctx context.Context, search string, limit
 	return topics, total, nil
 }
 
+func (r *topicPostgresRepository) ListAll(ctx context.Context) ([]*domain.Topic, error) {
+	query := `SELECT id, name, slug, names, icon_url, display_order, is_featured, created_at FROM topics ORDER BY display_order ASC, name ASC`
+	var topics []*domain.Topic
+	err := r.db.Read(ctx).SelectContext(ctx, &topics, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list topics: %w", err)
+	}
+	return topics, nil
+}
+
 func (r *topicPostgresRepository) Create(ctx context.Context, topic *domain.Topic) error {
-	query := `INSERT INTO topics (id, name, slug, created_at) VALUES (:id, :name, :slug, :created_at)`
-	_, err := r.db.NamedExecContext(ctx, query, topic)
+	query := `INSERT INTO topics (id, name, slug, names, icon_url, display_order, is_featured, created_at) VALUES (:id, :name, :slug, :names, :icon_url, :display_order, :is_featured, :created_at)`
+	_, err := r.db.Write(ctx).NamedExecContext(ctx, query, topic)
 	if err != nil {
 		return fmt.Errorf("failed to create topic: %w", err)
 	}
@@ -69,8 +104,8 @@ func (r *topicPostgresRepository) Create(ctx context.Context, topic *domain.Topi
 }
 
 func (r *topicPostgresRepository) Update(ctx context.Context, topic *domain.Topic) error {
-	query := `UPDATE topics SET name = :name, slug = :slug WHERE id = :id`
-	result, err := r.db.NamedExecContext(ctx, query, topic)
+	query := `UPDATE topics SET name = :name, slug = :slug, names = :names, icon_url = :icon_url, display_order = :display_order, is_featured = :is_featured WHERE id = :id`
+	result, err := r.db.Write(ctx).NamedExecContext(ctx, query, topic)
 	if err != nil {
 		return fmt.Errorf("failed to update topic: %w", err)
 	}
@@ -84,7 +119,7 @@ func (r *topicPostgresRepository) Update(ctx context.Context, topic *domain.Topi
 
 func (r *topicPostgresRepository) Delete(ctx context.Context, id string) error {
 	query := `DELETE FROM topics WHERE id = $1`
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := r.db.Write(ctx).ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete topic: %w", err)
 	}
@@ -96,9 +131,51 @@ func (r *topicPostgresRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+func (r *topicPostgresRepository) CountDatasets(ctx context.Context, id string) (int, error) {
+	query := `SELECT COUNT(*) FROM datasets WHERE topic_id = $1`
+	var count int
+	if err := r.db.Write(ctx).GetContext(ctx, &count, query, id); err != nil {
+		return 0, fmt.Errorf("failed to count topic datasets: %w", err)
+	}
+	return count, nil
+}
+
+func (r *topicPostgresRepository) ReassignAndDelete(ctx context.Context, id, targetID string) error {
+	return r.db.WithinTx(ctx, func(ctx context.Context) error {
+		tx := r.db.Write(ctx)
+
+		var exists bool
+		if err := tx.GetContext(ctx, &exists, `SELECT EXISTS (SELECT 1 FROM topics WHERE id = $1)`, targetID); err != nil {
+			return fmt.Errorf("failed to get topic: %w", err)
+		}
+		if !exists {
+			return fmt.Errorf("%w: topic %s to reassign datasets to does not exist", errors.ErrInvalidInput, targetID)
+		}
+
+		_, err := tx.ExecContext(ctx, `UPDATE datasets SET topic_id = $2, updated_at = NOW() WHERE topic_id = $1`, id, targetID)
+		if err != nil {
+			return fmt.Errorf("failed to reassign datasets: %w", err)
+		}
+
+		result, err := tx.ExecContext(ctx, `DELETE FROM topics WHERE id = $1`, id)
+		if err != nil {
+			return fmt.Errorf("failed to delete topic: %w", err)
+		}
+
+		rows, _ := result.RowsAffected()
+		if rows == 0 {
+			return errors.ErrNotFound
+		}
+		return nil
+	})
+}
+
 func (r *topicPostgresRepository) handleError(err error) error {
 	if err == nil {
 		return nil
 	}
+	if err == sql.ErrNoRows {
+		return errors.ErrNotFound
+	}
 	return errors.Wrap(err, "database error")
 }
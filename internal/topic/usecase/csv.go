@@ -0,0 +1,208 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"portal-data-backend/internal/topic/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+	"portal-data-backend/pkg/i18n"
+
+	"github.com/google/uuid"
+)
+
+// csvColumns are the columns of a topic export. One name_<lang> column
+// follows per language with a localized name.
+var csvColumns = []string{"id", "name", "slug", "display_order", "is_featured", "icon_url"}
+
+const namePrefix = "name_"
+
+func (u *topicUsecase) Export(ctx context.Context) ([]byte, error) {
+	topics, err := u.topicRepo.ListAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export topics: %w", err)
+	}
+
+	responses := make([]*domain.TopicResponse, len(topics))
+	languages := map[string]bool{}
+	for i, topic := range topics {
+		responses[i] = u.toResponse(topic, nil)
+		for lang := range responses[i].Names {
+			languages[lang] = true
+		}
+	}
+	langs := make([]string, 0, len(languages))
+	for lang := range languages {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	header := append([]string{}, csvColumns...)
+	for _, lang := range langs {
+		header = append(header, namePrefix+lang)
+	}
+	writer.Write(header)
+
+	for _, topic := range responses {
+		iconURL := ""
+		if topic.IconURL != nil {
+			iconURL = *topic.IconURL
+		}
+		row := []string{topic.ID, topic.Name, topic.Slug, strconv.Itoa(topic.DisplayOrder), strconv.FormatBool(topic.IsFeatured), iconURL}
+		for _, lang := range langs {
+			row = append(row, topic.Names[lang])
+		}
+		writer.Write(row)
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("failed to export topics: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (u *topicUsecase) Import(ctx context.Context, reader io.Reader) (*domain.ImportResult, error) {
+	rows, err := readCSV(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	topics, err := u.topicRepo.ListAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to import topics: %w", err)
+	}
+	bySlug := make(map[string]*domain.Topic, len(topics))
+	for _, topic := range topics {
+		bySlug[topic.Slug] = topic
+	}
+
+	result := &domain.ImportResult{}
+	seen := map[string]int{}
+	for i, row := range rows {
+		line := i + 2
+		skip := func(format string, args ...interface{}) {
+			result.Skipped++
+			result.Errors = append(result.Errors, domain.ImportError{Row: line, Message: fmt.Sprintf(format, args...)})
+		}
+
+		name := strings.TrimSpace(row["name"])
+		if len([]rune(name)) < 2 {
+			skip("name must be at least 2 characters")
+			continue
+		}
+		slug := strings.TrimSpace(row["slug"])
+		if slug == "" {
+			slug = u.generateSlug(name)
+		}
+		if first, ok := seen[slug]; ok {
+			skip("slug %s already appears on row %d", slug, first)
+			continue
+		}
+		seen[slug] = line
+
+		topic, exists := bySlug[slug]
+		if !exists {
+			topic = &domain.Topic{ID: uuid.New().String(), Names: "{}", CreatedAt: time.Now()}
+		}
+		topic.Name = name
+		topic.Slug = slug
+
+		if value, ok := row["display_order"]; ok && strings.TrimSpace(value) != "" {
+			order, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				skip("display_order %q is not a number", value)
+				continue
+			}
+			topic.DisplayOrder = order
+		}
+		if value, ok := row["is_featured"]; ok && strings.TrimSpace(value) != "" {
+			featured, err := strconv.ParseBool(strings.TrimSpace(value))
+			if err != nil {
+				skip("is_featured %q is not true or false", value)
+				continue
+			}
+			topic.IsFeatured = featured
+		}
+		if iconURL := strings.TrimSpace(row["icon_url"]); iconURL != "" {
+			topic.IconURL = &iconURL
+		}
+		if names := rowNames(row); len(names) > 0 {
+			if topic.Names, err = i18n.Encode(names); err != nil {
+				return nil, err
+			}
+		}
+
+		if exists {
+			if err := u.topicRepo.Update(ctx, topic); err != nil {
+				return nil, fmt.Errorf("failed to update topic %s: %w", slug, err)
+			}
+			result.Updated++
+			continue
+		}
+		if err := u.topicRepo.Create(ctx, topic); err != nil {
+			return nil, fmt.Errorf("failed to create topic %s: %w", slug, err)
+		}
+		bySlug[slug] = topic
+		result.Created++
+	}
+
+	return result, nil
+}
+
+// rowNames collects the localized names of the name_<lang> columns of a row
+func rowNames(row map[string]string) map[string]string {
+	names := map[string]string{}
+	for column, value := range row {
+		if lang := strings.TrimPrefix(column, namePrefix); lang != column && strings.TrimSpace(value) != "" {
+			names[lang] = strings.TrimSpace(value)
+		}
+	}
+	return names
+}
+
+// readCSV reads CSV rows keyed by their lower case column name. The first row
+// holds the column names and must include name.
+func readCSV(reader io.Reader) ([]map[string]string, error) {
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV: %w", err)
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(body, []byte("\xef\xbb\xbf")))).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", pkgErrors.ErrInvalidInput, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: CSV is empty", pkgErrors.ErrInvalidInput)
+	}
+
+	header := records[0]
+	hasName := false
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(header[i]))
+		hasName = hasName || header[i] == "name"
+	}
+	if !hasName {
+		return nil, fmt.Errorf("%w: CSV has no name column", pkgErrors.ErrInvalidInput)
+	}
+
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(header))
+		for i, column := range header {
+			row[column] = record[i]
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
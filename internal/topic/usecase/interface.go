@@ -2,14 +2,36 @@ package usecase
 
 import (
 	"context"
+	"io"
 
+	fileDomain "portal-data-backend/internal/file/domain"
 	"portal-data-backend/internal/topic/domain"
 )
 
+// TopicReader is the part of the topic module other modules read topics through
+type TopicReader interface {
+	// GetByIDs loads the topics with ids in one query, skipping unknown ones
+	GetByIDs(ctx context.Context, ids []string) ([]*domain.TopicResponse, error)
+}
+
 type Usecase interface {
+	TopicReader
 	GetByID(ctx context.Context, id string) (*domain.TopicResponse, error)
 	List(ctx context.Context, req *domain.ListTopicsRequest) (*domain.TopicListResponse, error)
 	Create(ctx context.Context, req *domain.CreateTopicRequest) (*domain.TopicResponse, error)
 	Update(ctx context.Context, id string, req *domain.UpdateTopicRequest) (*domain.TopicResponse, error)
-	Delete(ctx context.Context, id string) error
+	// Delete refuses to delete a topic datasets still reference unless
+	// reassignTo names the topic to move them to
+	Delete(ctx context.Context, id string, reassignTo *string) error
+	// UploadIcon stores an image through the file module and sets it as the icon of a topic
+	UploadIcon(ctx context.Context, id string, fileName string, fileSize int64, mimeType string, reader io.Reader, userID string) (*domain.TopicResponse, error)
+	// Export writes every topic as CSV
+	Export(ctx context.Context) ([]byte, error)
+	// Import creates or, matched on slug, updates topics from CSV
+	Import(ctx context.Context, reader io.Reader) (*domain.ImportResult, error)
+}
+
+// IconUploader is the part of the file module topic icons are stored through
+type IconUploader interface {
+	Upload(ctx context.Context, fileName string, fileSize int64, mimeType string, reader io.Reader, datasetID *string, userID string) (*fileDomain.UploadResponse, error)
 }
@@ -3,21 +3,39 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"io"
 	"math"
 	"strings"
 	"time"
 
+	"portal-data-backend/infrastructure/audit"
 	"portal-data-backend/internal/topic/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+	"portal-data-backend/pkg/i18n"
 
 	"github.com/google/uuid"
 )
 
+// maxIconSize is the largest icon image accepted, in bytes
+const maxIconSize = 2 << 20
+
+// iconTypes lists the image types accepted as icons
+var iconTypes = map[string]bool{
+	"image/png":     true,
+	"image/jpeg":    true,
+	"image/webp":    true,
+	"image/svg+xml": true,
+}
+
 type topicUsecase struct {
 	topicRepo domain.Repository
+	icons     IconUploader
+	audit     *audit.Recorder
 }
 
-func NewTopicUsecase(topicRepo domain.Repository) Usecase {
-	return &topicUsecase{topicRepo: topicRepo}
+// NewTopicUsecase creates the topic usecase. recorder may be nil.
+func NewTopicUsecase(topicRepo domain.Repository, icons IconUploader, recorder *audit.Recorder) Usecase {
+	return &topicUsecase{topicRepo: topicRepo, icons: icons, audit: recorder}
 }
 
 func (u *topicUsecase) GetByID(ctx context.Context, id string) (*domain.TopicResponse, error) {
@@ -25,7 +43,21 @@ func (u *topicUsecase) GetByID(ctx context.Context, id string) (*domain.TopicRes
 	if err != nil {
 		return nil, fmt.Errorf("failed to get topic: %w", err)
 	}
-	return u.toResponse(topic), nil
+	return u.toResponse(topic, i18n.Languages(ctx)), nil
+}
+
+func (u *topicUsecase) GetByIDs(ctx context.Context, ids []string) ([]*domain.TopicResponse, error) {
+	topics, err := u.topicRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get topics: %w", err)
+	}
+
+	langs := i18n.Languages(ctx)
+	responses := make([]*domain.TopicResponse, len(topics))
+	for i, topic := range topics {
+		responses[i] = u.toResponse(topic, langs)
+	}
+	return responses, nil
 }
 
 func (u *topicUsecase) List(ctx context.Context, req *domain.ListTopicsRequest) (*domain.TopicListResponse, error) {
@@ -38,14 +70,15 @@ func (u *topicUsecase) List(ctx context.Context, req *domain.ListTopicsRequest)
 
 	offset := (req.Page - 1) * req.Limit
 
-	topics, total, err := u.topicRepo.List(ctx, req.Search, req.Limit, offset)
+	filter := &domain.TopicFilter{Search: req.Search, Featured: req.Featured}
+	topics, total, err := u.topicRepo.List(ctx, filter, req.Limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list topics: %w", err)
 	}
 
 	responses := make([]domain.TopicResponse, len(topics))
 	for i, topic := range topics {
-		responses[i] = *u.toResponse(topic)
+		responses[i] = *u.toResponse(topic, i18n.Languages(ctx))
 	}
 
 	totalPage := int(math.Ceil(float64(total) / float64(req.Limit)))
@@ -62,18 +95,27 @@ func (u *topicUsecase) List(ctx context.Context, req *domain.ListTopicsRequest)
 }
 
 func (u *topicUsecase) Create(ctx context.Context, req *domain.CreateTopicRequest) (*domain.TopicResponse, error) {
+	names, err := i18n.Encode(req.Names)
+	if err != nil {
+		return nil, err
+	}
+
 	topic := &domain.Topic{
-		ID:        uuid.New().String(),
-		Name:      req.Name,
-		Slug:      u.generateSlug(req.Name),
-		CreatedAt: time.Now(),
+		ID:           uuid.New().String(),
+		Name:         req.Name,
+		Slug:         u.generateSlug(req.Name),
+		Names:        names,
+		DisplayOrder: req.DisplayOrder,
+		IsFeatured:   req.IsFeatured,
+		CreatedAt:    time.Now(),
 	}
 
 	if err := u.topicRepo.Create(ctx, topic); err != nil {
 		return nil, fmt.Errorf("failed to create topic: %w", err)
 	}
+	u.audit.Record(ctx, "topics", topic.ID, audit.ActionCreate, nil, topic)
 
-	return u.toResponse(topic), nil
+	return u.toResponse(topic, nil), nil
 }
 
 func (u *topicUsecase) Update(ctx context.Context, id string, req *domain.UpdateTopicRequest) (*domain.TopicResponse, error) {
@@ -81,30 +123,104 @@ func (u *topicUsecase) Update(ctx context.Context, id string, req *domain.Update
 	if err != nil {
 		return nil, fmt.Errorf("failed to get topic: %w", err)
 	}
+	before := *topic
 
 	topic.Name = req.Name
 	topic.Slug = u.generateSlug(req.Name)
+	if req.Names != nil {
+		if topic.Names, err = i18n.Encode(req.Names); err != nil {
+			return nil, err
+		}
+	}
+	if req.DisplayOrder != nil {
+		topic.DisplayOrder = *req.DisplayOrder
+	}
+	if req.IsFeatured != nil {
+		topic.IsFeatured = *req.IsFeatured
+	}
 
 	if err := u.topicRepo.Update(ctx, topic); err != nil {
 		return nil, fmt.Errorf("failed to update topic: %w", err)
 	}
+	u.audit.Record(ctx, "topics", topic.ID, audit.ActionUpdate, &before, topic)
 
-	return u.toResponse(topic), nil
+	return u.toResponse(topic, nil), nil
 }
 
-func (u *topicUsecase) Delete(ctx context.Context, id string) error {
+func (u *topicUsecase) Delete(ctx context.Context, id string, reassignTo *string) error {
+	topic, err := u.topicRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get topic: %w", err)
+	}
+
+	if reassignTo != nil {
+		if *reassignTo == id {
+			return fmt.Errorf("%w: cannot reassign datasets to the topic being deleted", pkgErrors.ErrInvalidInput)
+		}
+		if err := u.topicRepo.ReassignAndDelete(ctx, id, *reassignTo); err != nil {
+			return fmt.Errorf("failed to delete topic: %w", err)
+		}
+		u.audit.Record(ctx, "topics", id, audit.ActionDelete, topic, nil)
+		return nil
+	}
+
+	count, err := u.topicRepo.CountDatasets(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete topic: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("%w: topic is used by %d datasets, pass reassign_to to move them to another topic", pkgErrors.ErrInUse, count)
+	}
+
 	if err := u.topicRepo.Delete(ctx, id); err != nil {
 		return fmt.Errorf("failed to delete topic: %w", err)
 	}
+	u.audit.Record(ctx, "topics", id, audit.ActionDelete, topic, nil)
 	return nil
 }
 
-func (u *topicUsecase) toResponse(topic *domain.Topic) *domain.TopicResponse {
+func (u *topicUsecase) UploadIcon(ctx context.Context, id string, fileName string, fileSize int64, mimeType string, reader io.Reader, userID string) (*domain.TopicResponse, error) {
+	if !iconTypes[mimeType] {
+		return nil, fmt.Errorf("%w: icon must be a PNG, JPEG, WebP or SVG image", pkgErrors.ErrInvalidInput)
+	}
+	if fileSize > maxIconSize {
+		return nil, fmt.Errorf("%w: icon must be at most %d KB", pkgErrors.ErrInvalidInput, maxIconSize>>10)
+	}
+
+	topic, err := u.topicRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get topic: %w", err)
+	}
+	before := *topic
+
+	file, err := u.icons.Upload(ctx, fileName, fileSize, mimeType, reader, nil, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload topic icon: %w", err)
+	}
+
+	topic.IconURL = &file.Path
+	if err := u.topicRepo.Update(ctx, topic); err != nil {
+		return nil, fmt.Errorf("failed to update topic: %w", err)
+	}
+	u.audit.Record(ctx, "topics", topic.ID, audit.ActionUpdate, &before, topic)
+
+	return u.toResponse(topic, nil), nil
+}
+
+// toResponse converts a topic, naming it in the first of langs it has a name in
+func (u *topicUsecase) toResponse(topic *domain.Topic, langs []string) *domain.TopicResponse {
+	names := i18n.Decode(topic.Names)
+	name := i18n.Pick(names, langs, topic.Name)
+
 	return &domain.TopicResponse{
-		ID:        topic.ID,
-		Name:      topic.Name,
-		Slug:      topic.Slug,
-		CreatedAt: topic.CreatedAt,
+		ID:           topic.ID,
+		Name:         name,
+		Slug:         topic.Slug,
+		Names:        names,
+		IconURL:      topic.IconURL,
+		DisplayOrder: topic.DisplayOrder,
+		IsFeatured:   topic.IsFeatured,
+		CreatedAt:    topic.CreatedAt,
 	}
 }
 
@@ -0,0 +1,20 @@
+package http
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	unitDomain "portal-data-backend/internal/unit/domain"
+)
+
+// Describe adds the unit routes to the API specification
+func Describe(spec *openapi.Spec) {
+	api := spec.Tag("units", "Units of measurement")
+	api.Get("/units", "List units").Public().Query(unitDomain.ListUnitsRequest{}).Returns(http.StatusOK, unitDomain.UnitListResponse{})
+	api.Post("/units", "Create unit").Body(unitDomain.CreateUnitRequest{}).Returns(http.StatusCreated, unitDomain.UnitResponse{})
+	api.Get("/units/export", "Export units as CSV").ReturnsFile(http.StatusOK, "text/csv")
+	api.Post("/units/import", "Import units from CSV").Upload("file").Returns(http.StatusOK, unitDomain.ImportResult{})
+	api.Get("/units/{id}", "Get unit").Public().Returns(http.StatusOK, unitDomain.UnitResponse{})
+	api.Put("/units/{id}", "Update unit").Body(unitDomain.UpdateUnitRequest{}).Returns(http.StatusOK, unitDomain.UnitResponse{})
+	api.Delete("/units/{id}", "Delete unit").Param("reassign_to", false).Returns(http.StatusOK, nil)
+}
@@ -39,6 +39,20 @@ type UnitListResponse struct {
 	Meta  ListMeta       `json:"meta"`
 }
 
+// ImportResult reports the outcome of a CSV import
+type ImportResult struct {
+	Created int           `json:"created"`
+	Updated int           `json:"updated"`
+	Skipped int           `json:"skipped"`
+	Errors  []ImportError `json:"errors,omitempty"`
+}
+
+// ImportError describes a CSV row that was skipped
+type ImportError struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
 type ListMeta struct {
 	Page      int `json:"page"`
 	Limit     int `json:"limit"`
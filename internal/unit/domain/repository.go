@@ -6,8 +6,17 @@ import (
 
 type Repository interface {
 	GetByID(ctx context.Context, id string) (*Unit, error)
+	// GetByIDs retrieves the units with ids in one query
+	GetByIDs(ctx context.Context, ids []string) ([]*Unit, error)
 	List(ctx context.Context, search string, limit, offset int) ([]*Unit, int, error)
+	// ListAll returns every unit by name
+	ListAll(ctx context.Context) ([]*Unit, error)
 	Create(ctx context.Context, unit *Unit) error
 	Update(ctx context.Context, unit *Unit) error
 	Delete(ctx context.Context, id string) error
+	// CountDatasets counts the datasets referencing the unit
+	CountDatasets(ctx context.Context, id string) (int, error)
+	// ReassignAndDelete moves the datasets of a unit to the unit targetID
+	// and deletes it in one transaction
+	ReassignAndDelete(ctx context.Context, id, targetID string) error
 }
@@ -0,0 +1,43 @@
+// Package unit is the module managing the measurement units of data.
+package unit
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	"portal-data-backend/internal/app"
+	delivery "portal-data-backend/internal/unit/delivery/http"
+	"portal-data-backend/internal/unit/repository"
+	"portal-data-backend/internal/unit/usecase"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Module manages measurement units and provides them to other modules
+type Module struct {
+	handler *delivery.Handler
+}
+
+// Name implements app.Module
+func (m *Module) Name() string {
+	return "unit"
+}
+
+// Register implements app.Module
+func (m *Module) Register(deps *app.Deps) error {
+	repo := repository.NewUnitPostgresRepository(deps.DB)
+	units := usecase.NewUnitUsecase(repo, deps.Services.Audit)
+	deps.Services.Units = units
+	m.handler = delivery.NewHandler(units)
+	return nil
+}
+
+// Routes implements app.Module
+func (m *Module) Routes(r chi.Router, auth func(http.Handler) http.Handler) {
+	delivery.RegisterRoutes(r, m.handler, auth)
+}
+
+// Describe implements app.Module
+func (m *Module) Describe(spec *openapi.Spec) {
+	delivery.Describe(spec)
+}
@@ -2,8 +2,10 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 
+	"portal-data-backend/infrastructure/db"
 	"portal-data-backend/internal/unit/domain"
 	"portal-data-backend/pkg/errors"
 
@@ -21,13 +23,31 @@ func NewUnitPostgresRepository(db *sqlx.DB) domain.Repository {
 func (r *unitPostgresRepository) GetByID(ctx context.Context, id string) (*domain.Unit, error) {
 	query := `SELECT id, name, symbol, created_at FROM units WHERE id = $1`
 	var unit domain.Unit
-	err := r.db.GetContext(ctx, &unit, query, id)
+	err := db.Conn(ctx, r.db).GetContext(ctx, &unit, query, id)
 	if err != nil {
 		return nil, r.handleError(err)
 	}
 	return &unit, nil
 }
 
+func (r *unitPostgresRepository) GetByIDs(ctx context.Context, ids []string) ([]*domain.Unit, error) {
+	units := []*domain.Unit{}
+	if len(ids) == 0 {
+		return units, nil
+	}
+
+	query, args, err := sqlx.In(`SELECT id, name, symbol, created_at FROM units WHERE id IN (?)`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get units: %w", err)
+	}
+
+	conn := db.Conn(ctx, r.db)
+	if err := conn.SelectContext(ctx, &units, conn.Rebind(query), args...); err != nil {
+		return nil, fmt.Errorf("failed to get units: %w", err)
+	}
+	return units, nil
+}
+
 func (r *unitPostgresRepository) List(ctx context.Context, search string, limit, offset int) ([]*domain.Unit, int, error) {
 	whereClause := "WHERE 1=1"
 	args := []interface{}{}
@@ -42,7 +62,7 @@ func (r *unitPostgresRepository) List(ctx context.Context, search string, limit,
 
 	countQuery := "SELECT COUNT(*) FROM units " + whereClause
 	var total int
-	err := r.db.GetContext(ctx, &total, countQuery, args...)
+	err := db.Conn(ctx, r.db).GetContext(ctx, &total, countQuery, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count units: %w", err)
 	}
@@ -51,7 +71,7 @@ func (r *unitPostgresRepository) List(ctx context.Context, search string, limit,
 	args = append(args, limit, offset)
 
 	var units []*domain.Unit
-	err = r.db.SelectContext(ctx, &units, query, args...)
+	err = db.Conn(ctx, r.db).SelectContext(ctx, &units, query, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to list units: %w", err)
 	}
@@ -59,9 +79,19 @@ func (r *unitPostgresRepository) List(ctx context.Context, search string, limit,
 	return units, total, nil
 }
 
+func (r *unitPostgresRepository) ListAll(ctx context.Context) ([]*domain.Unit, error) {
+	query := `SELECT id, name, symbol, created_at FROM units ORDER BY name ASC`
+	var units []*domain.Unit
+	err := db.Conn(ctx, r.db).SelectContext(ctx, &units, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list units: %w", err)
+	}
+	return units, nil
+}
+
 func (r *unitPostgresRepository) Create(ctx context.Context, unit *domain.Unit) error {
 	query := `INSERT INTO units (id, name, symbol, created_at) VALUES (:id, :name, :symbol, :created_at)`
-	_, err := r.db.NamedExecContext(ctx, query, unit)
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, unit)
 	if err != nil {
 		return fmt.Errorf("failed to create unit: %w", err)
 	}
@@ -70,7 +100,7 @@ func (r *unitPostgresRepository) Create(ctx context.Context, unit *domain.Unit)
 
 func (r *unitPostgresRepository) Update(ctx context.Context, unit *domain.Unit) error {
 	query := `UPDATE units SET name = :name, symbol = :symbol WHERE id = :id`
-	result, err := r.db.NamedExecContext(ctx, query, unit)
+	result, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, unit)
 	if err != nil {
 		return fmt.Errorf("failed to update unit: %w", err)
 	}
@@ -84,7 +114,7 @@ func (r *unitPostgresRepository) Update(ctx context.Context, unit *domain.Unit)
 
 func (r *unitPostgresRepository) Delete(ctx context.Context, id string) error {
 	query := `DELETE FROM units WHERE id = $1`
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := db.Conn(ctx, r.db).ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete unit: %w", err)
 	}
@@ -96,9 +126,51 @@ func (r *unitPostgresRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+func (r *unitPostgresRepository) CountDatasets(ctx context.Context, id string) (int, error) {
+	query := `SELECT COUNT(*) FROM datasets WHERE unit_id = $1`
+	var count int
+	if err := db.Conn(ctx, r.db).GetContext(ctx, &count, query, id); err != nil {
+		return 0, fmt.Errorf("failed to count unit datasets: %w", err)
+	}
+	return count, nil
+}
+
+func (r *unitPostgresRepository) ReassignAndDelete(ctx context.Context, id, targetID string) error {
+	return db.WithinTx(ctx, r.db, func(ctx context.Context) error {
+		tx := db.Conn(ctx, r.db)
+
+		var exists bool
+		if err := tx.GetContext(ctx, &exists, `SELECT EXISTS (SELECT 1 FROM units WHERE id = $1)`, targetID); err != nil {
+			return fmt.Errorf("failed to get unit: %w", err)
+		}
+		if !exists {
+			return fmt.Errorf("%w: unit %s to reassign datasets to does not exist", errors.ErrInvalidInput, targetID)
+		}
+
+		_, err := tx.ExecContext(ctx, `UPDATE datasets SET unit_id = $2, updated_at = NOW() WHERE unit_id = $1`, id, targetID)
+		if err != nil {
+			return fmt.Errorf("failed to reassign datasets: %w", err)
+		}
+
+		result, err := tx.ExecContext(ctx, `DELETE FROM units WHERE id = $1`, id)
+		if err != nil {
+			return fmt.Errorf("failed to delete unit: %w", err)
+		}
+
+		rows, _ := result.RowsAffected()
+		if rows == 0 {
+			return errors.ErrNotFound
+		}
+		return nil
+	})
+}
+
 func (r *unitPostgresRepository) handleError(err error) error {
 	if err == nil {
 		return nil
 	}
+	if err == sql.ErrNoRows {
+		return errors.ErrNotFound
+	}
 	return errors.Wrap(err, "database error")
 }
@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"portal-data-backend/internal/unit/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// csvColumns are the columns of a unit export
+var csvColumns = []string{"id", "name", "symbol"}
+
+func (u *unitUsecase) Export(ctx context.Context) ([]byte, error) {
+	units, err := u.unitRepo.ListAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export units: %w", err)
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write(csvColumns)
+	for _, unit := range units {
+		writer.Write([]string{unit.ID, unit.Name, unit.Symbol})
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("failed to export units: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Import matches units on their name regardless of case, as units have no slug
+func (u *unitUsecase) Import(ctx context.Context, reader io.Reader) (*domain.ImportResult, error) {
+	rows, err := readCSV(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	units, err := u.unitRepo.ListAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to import units: %w", err)
+	}
+	byName := make(map[string]*domain.Unit, len(units))
+	for _, unit := range units {
+		byName[strings.ToLower(unit.Name)] = unit
+	}
+
+	result := &domain.ImportResult{}
+	seen := map[string]int{}
+	for i, row := range rows {
+		line := i + 2
+		skip := func(format string, args ...interface{}) {
+			result.Skipped++
+			result.Errors = append(result.Errors, domain.ImportError{Row: line, Message: fmt.Sprintf(format, args...)})
+		}
+
+		name := strings.TrimSpace(row["name"])
+		symbol := strings.TrimSpace(row["symbol"])
+		if name == "" || symbol == "" {
+			skip("name and symbol are required")
+			continue
+		}
+		key := strings.ToLower(name)
+		if first, ok := seen[key]; ok {
+			skip("unit %s already appears on row %d", name, first)
+			continue
+		}
+		seen[key] = line
+
+		if unit, ok := byName[key]; ok {
+			unit.Name = name
+			unit.Symbol = symbol
+			if err := u.unitRepo.Update(ctx, unit); err != nil {
+				return nil, fmt.Errorf("failed to update unit %s: %w", name, err)
+			}
+			result.Updated++
+			continue
+		}
+
+		unit := &domain.Unit{ID: uuid.New().String(), Name: name, Symbol: symbol, CreatedAt: time.Now()}
+		if err := u.unitRepo.Create(ctx, unit); err != nil {
+			return nil, fmt.Errorf("failed to create unit %s: %w", name, err)
+		}
+		byName[key] = unit
+		result.Created++
+	}
+
+	return result, nil
+}
+
+// readCSV reads CSV rows keyed by their lower case column name. The first row
+// holds the column names and must include name and symbol.
+func readCSV(reader io.Reader) ([]map[string]string, error) {
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV: %w", err)
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(body, []byte("\xef\xbb\xbf")))).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", pkgErrors.ErrInvalidInput, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: CSV is empty", pkgErrors.ErrInvalidInput)
+	}
+
+	header := records[0]
+	columns := map[string]bool{}
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(header[i]))
+		columns[header[i]] = true
+	}
+	if !columns["name"] || !columns["symbol"] {
+		return nil, fmt.Errorf("%w: CSV needs name and symbol columns", pkgErrors.ErrInvalidInput)
+	}
+
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(header))
+		for i, column := range header {
+			row[column] = record[i]
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
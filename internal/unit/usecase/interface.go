@@ -2,14 +2,28 @@ package usecase
 
 import (
 	"context"
+	"io"
 
 	"portal-data-backend/internal/unit/domain"
 )
 
+// UnitReader is the part of the unit module other modules read units through
+type UnitReader interface {
+	// GetByIDs loads the units with ids in one query, skipping unknown ones
+	GetByIDs(ctx context.Context, ids []string) ([]*domain.UnitResponse, error)
+}
+
 type Usecase interface {
+	UnitReader
 	GetByID(ctx context.Context, id string) (*domain.UnitResponse, error)
 	List(ctx context.Context, req *domain.ListUnitsRequest) (*domain.UnitListResponse, error)
 	Create(ctx context.Context, req *domain.CreateUnitRequest) (*domain.UnitResponse, error)
 	Update(ctx context.Context, id string, req *domain.UpdateUnitRequest) (*domain.UnitResponse, error)
-	Delete(ctx context.Context, id string) error
+	// Delete refuses to delete a unit datasets still reference unless
+	// reassignTo names the unit to move them to
+	Delete(ctx context.Context, id string, reassignTo *string) error
+	// Export writes every unit as CSV
+	Export(ctx context.Context) ([]byte, error)
+	// Import creates or, matched on name, updates units from CSV
+	Import(ctx context.Context, reader io.Reader) (*domain.ImportResult, error)
 }
@@ -6,17 +6,21 @@ import (
 	"math"
 	"time"
 
+	"portal-data-backend/infrastructure/audit"
 	"portal-data-backend/internal/unit/domain"
+	pkgErrors "portal-data-backend/pkg/errors"
 
 	"github.com/google/uuid"
 )
 
 type unitUsecase struct {
 	unitRepo domain.Repository
+	audit    *audit.Recorder
 }
 
-func NewUnitUsecase(unitRepo domain.Repository) Usecase {
-	return &unitUsecase{unitRepo: unitRepo}
+// NewUnitUsecase creates the unit usecase. recorder may be nil.
+func NewUnitUsecase(unitRepo domain.Repository, recorder *audit.Recorder) Usecase {
+	return &unitUsecase{unitRepo: unitRepo, audit: recorder}
 }
 
 func (u *unitUsecase) GetByID(ctx context.Context, id string) (*domain.UnitResponse, error) {
@@ -27,6 +31,19 @@ func (u *unitUsecase) GetByID(ctx context.Context, id string) (*domain.UnitRespo
 	return u.toResponse(unit), nil
 }
 
+func (u *unitUsecase) GetByIDs(ctx context.Context, ids []string) ([]*domain.UnitResponse, error) {
+	units, err := u.unitRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get units: %w", err)
+	}
+
+	responses := make([]*domain.UnitResponse, len(units))
+	for i, unit := range units {
+		responses[i] = u.toResponse(unit)
+	}
+	return responses, nil
+}
+
 func (u *unitUsecase) List(ctx context.Context, req *domain.ListUnitsRequest) (*domain.UnitListResponse, error) {
 	if req.Page < 1 {
 		req.Page = 1
@@ -71,6 +88,7 @@ func (u *unitUsecase) Create(ctx context.Context, req *domain.CreateUnitRequest)
 	if err := u.unitRepo.Create(ctx, unit); err != nil {
 		return nil, fmt.Errorf("failed to create unit: %w", err)
 	}
+	u.audit.Record(ctx, "units", unit.ID, audit.ActionCreate, nil, unit)
 
 	return u.toResponse(unit), nil
 }
@@ -80,6 +98,7 @@ func (u *unitUsecase) Update(ctx context.Context, id string, req *domain.UpdateU
 	if err != nil {
 		return nil, fmt.Errorf("failed to get unit: %w", err)
 	}
+	before := *unit
 
 	unit.Name = req.Name
 	unit.Symbol = req.Symbol
@@ -87,14 +106,40 @@ func (u *unitUsecase) Update(ctx context.Context, id string, req *domain.UpdateU
 	if err := u.unitRepo.Update(ctx, unit); err != nil {
 		return nil, fmt.Errorf("failed to update unit: %w", err)
 	}
+	u.audit.Record(ctx, "units", unit.ID, audit.ActionUpdate, &before, unit)
 
 	return u.toResponse(unit), nil
 }
 
-func (u *unitUsecase) Delete(ctx context.Context, id string) error {
+func (u *unitUsecase) Delete(ctx context.Context, id string, reassignTo *string) error {
+	unit, err := u.unitRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get unit: %w", err)
+	}
+
+	if reassignTo != nil {
+		if *reassignTo == id {
+			return fmt.Errorf("%w: cannot reassign datasets to the unit being deleted", pkgErrors.ErrInvalidInput)
+		}
+		if err := u.unitRepo.ReassignAndDelete(ctx, id, *reassignTo); err != nil {
+			return fmt.Errorf("failed to delete unit: %w", err)
+		}
+		u.audit.Record(ctx, "units", id, audit.ActionDelete, unit, nil)
+		return nil
+	}
+
+	count, err := u.unitRepo.CountDatasets(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete unit: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("%w: unit is used by %d datasets, pass reassign_to to move them to another unit", pkgErrors.ErrInUse, count)
+	}
+
 	if err := u.unitRepo.Delete(ctx, id); err != nil {
 		return fmt.Errorf("failed to delete unit: %w", err)
 	}
+	u.audit.Record(ctx, "units", id, audit.ActionDelete, unit, nil)
 	return nil
 }
 
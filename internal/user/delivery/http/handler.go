@@ -1,45 +1,43 @@
 package http
 
 import (
-	"encoding/json"
-	"errors"
 	"net/http"
 	"strconv"
 
 	userDomain "portal-data-backend/internal/user/domain"
 	"portal-data-backend/internal/user/usecase"
+	"portal-data-backend/infrastructure/http/httputil"
+	"portal-data-backend/infrastructure/http/middleware"
+	"portal-data-backend/infrastructure/http/problem"
 	"portal-data-backend/infrastructure/http/response"
+	roleDomain "portal-data-backend/internal/role/domain"
 	pkgErrors "portal-data-backend/pkg/errors"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/go-playground/validator/v10"
 )
 
 // Handler handles HTTP requests for user
 type Handler struct {
 	userUsecase usecase.Usecase
-	validator   *validator.Validate
 }
 
 // NewHandler creates a new user handler
 func NewHandler(userUsecase usecase.Usecase) *Handler {
 	return &Handler{
 		userUsecase: userUsecase,
-		validator:   validator.New(),
 	}
 }
 
 // GetUserByID handles getting a user by ID
 func (h *Handler) GetUserByID(w http.ResponseWriter, r *http.Request) {
-	userID := chi.URLParam(r, "id")
-	if userID == "" {
-		response.BadRequest(w, response.CodeBadRequest, "User ID is required", nil)
+	userID, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
 	userInfo, err := h.userUsecase.GetUserByID(r.Context(), userID)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -60,7 +58,7 @@ func (h *Handler) ListUsers(w http.ResponseWriter, r *http.Request) {
 
 	resp, err := h.userUsecase.ListUsers(r.Context(), req)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -69,26 +67,19 @@ func (h *Handler) ListUsers(w http.ResponseWriter, r *http.Request) {
 
 // UpdateUser handles updating a user
 func (h *Handler) UpdateUser(w http.ResponseWriter, r *http.Request) {
-	userID := chi.URLParam(r, "id")
-	if userID == "" {
-		response.BadRequest(w, response.CodeBadRequest, "User ID is required", nil)
+	userID, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
-	var req userDomain.UpdateUserRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, response.CodeBadRequest, "Invalid request body", nil)
+	req, ok := httputil.Decode[userDomain.UpdateUserRequest](w, r)
+	if !ok {
 		return
 	}
 
-	if err := h.validator.Struct(&req); err != nil {
-		response.ValidationError(w, response.CodeValidationFailed, "Validation failed", h.formatValidationErrors(err))
-		return
-	}
-
-	userInfo, err := h.userUsecase.UpdateUser(r.Context(), userID, &req)
+	userInfo, err := h.userUsecase.UpdateUser(r.Context(), userID, req)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -97,14 +88,13 @@ func (h *Handler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 
 // DeleteUser handles deleting a user
 func (h *Handler) DeleteUser(w http.ResponseWriter, r *http.Request) {
-	userID := chi.URLParam(r, "id")
-	if userID == "" {
-		response.BadRequest(w, response.CodeBadRequest, "User ID is required", nil)
+	userID, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
 	if err := h.userUsecase.DeleteUser(r.Context(), userID); err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -113,27 +103,20 @@ func (h *Handler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 
 // UpdateUserStatus handles updating user status
 func (h *Handler) UpdateUserStatus(w http.ResponseWriter, r *http.Request) {
-	userID := chi.URLParam(r, "id")
-	if userID == "" {
-		response.BadRequest(w, response.CodeBadRequest, "User ID is required", nil)
+	userID, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
 	var req struct {
 		Status string `json:"status" validate:"required"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, response.CodeBadRequest, "Invalid request body", nil)
-		return
-	}
-
-	if err := h.validator.Struct(&req); err != nil {
-		response.ValidationError(w, response.CodeValidationFailed, "Validation failed", h.formatValidationErrors(err))
+	if !httputil.DecodeInto(w, r, &req) {
 		return
 	}
 
 	if err := h.userUsecase.UpdateUserStatus(r.Context(), userID, req.Status); err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -141,47 +124,17 @@ func (h *Handler) UpdateUserStatus(w http.ResponseWriter, r *http.Request) {
 }
 
 // handleError handles errors
-func (h *Handler) handleError(w http.ResponseWriter, err error) {
-	if err == nil {
-		return
-	}
+// errorMapper maps the errors of the user module on top of the shared ones
+var errorMapper = problem.Default.With(
+	problem.Mapping{Err: pkgErrors.ErrNotFound, Status: http.StatusNotFound, Code: response.CodeNotFound, Message: "User not found"},
+)
 
-	switch {
-	case errors.Is(err, pkgErrors.ErrNotFound):
-		response.NotFound(w, response.CodeNotFound, "User not found", nil)
-	default:
-		response.InternalError(w, response.CodeInternalServerError, "Internal server error", nil)
-	}
+func (h *Handler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	errorMapper.Write(w, r, err)
 }
 
 // formatValidationErrors formats validation errors
-func (h *Handler) formatValidationErrors(err error) []response.ErrorDetail {
-	var details []response.ErrorDetail
-
-	if validationErrors, ok := err.(validator.ValidationErrors); ok {
-		for _, fieldErr := range validationErrors {
-			details = append(details, response.ErrorDetail{
-				Field:   fieldErr.Field(),
-				Message: h.getValidationErrorMessage(fieldErr),
-			})
-		}
-	}
-
-	return details
-}
-
 // getValidationErrorMessage returns validation error message
-func (h *Handler) getValidationErrorMessage(fieldErr validator.FieldError) string {
-	switch fieldErr.Tag() {
-	case "required":
-		return fieldErr.Field() + " is required"
-	case "min":
-		return fieldErr.Field() + " must be at least " + fieldErr.Param() + " characters"
-	default:
-		return fieldErr.Field() + " is invalid"
-	}
-}
-
 // parseIntQuery parses integer query parameter with default value
 func parseIntQuery(r *http.Request, key string, defaultValue int) int {
 	if value := r.URL.Query().Get(key); value != "" {
@@ -192,13 +145,16 @@ func parseIntQuery(r *http.Request, key string, defaultValue int) int {
 	return defaultValue
 }
 
-// RegisterRoutes registers user routes
-func RegisterRoutes(r chi.Router, handler *Handler) {
+// RegisterRoutes registers user routes, which all go through auth. Changing
+// users requires the users:write permission.
+func RegisterRoutes(r chi.Router, handler *Handler, auth func(http.Handler) http.Handler) {
 	r.Route("/users", func(r chi.Router) {
+		r.Use(auth)
 		r.Get("/", handler.ListUsers)
 		r.Get("/{id}", handler.GetUserByID)
-		r.Put("/{id}", handler.UpdateUser)
-		r.Delete("/{id}", handler.DeleteUser)
-		r.Patch("/{id}/status", handler.UpdateUserStatus)
+		write := middleware.RequirePermission(roleDomain.PermissionUsersWrite)
+		r.With(write).Put("/{id}", handler.UpdateUser)
+		r.With(write).Delete("/{id}", handler.DeleteUser)
+		r.With(write).Patch("/{id}/status", handler.UpdateUserStatus)
 	})
 }
@@ -0,0 +1,22 @@
+package http
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	userDomain "portal-data-backend/internal/user/domain"
+)
+
+// Describe adds the user routes to the API specification
+func Describe(spec *openapi.Spec) {
+	api := spec.Tag("users", "User accounts")
+	api.Get("/users", "List users").
+		Query(userDomain.ListUsersRequest{}, "page", "limit", "search", "status", "sort_by", "sort_order").
+		Returns(http.StatusOK, userDomain.UserListResponse{})
+	api.Get("/users/{id}", "Get user").Returns(http.StatusOK, userDomain.UserInfo{})
+	api.Put("/users/{id}", "Update user").Body(userDomain.UpdateUserRequest{}).Returns(http.StatusOK, userDomain.UserInfo{})
+	api.Delete("/users/{id}", "Delete user").Returns(http.StatusOK, map[string]string{})
+	api.Patch("/users/{id}/status", "Update user status").Body(struct {
+		Status string `json:"status" validate:"required"`
+	}{}).Returns(http.StatusOK, nil)
+}
@@ -20,4 +20,7 @@ type Repository interface {
 
 	// UpdateStatus updates user status
 	UpdateStatus(ctx context.Context, id string, status string) error
+
+	// MemberIDs retrieves the IDs of the active users of an organization
+	MemberIDs(ctx context.Context, organizationID string) ([]string, error)
 }
@@ -0,0 +1,43 @@
+// Package user is the module managing user accounts.
+package user
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	"portal-data-backend/internal/app"
+	delivery "portal-data-backend/internal/user/delivery/http"
+	"portal-data-backend/internal/user/repository"
+	"portal-data-backend/internal/user/usecase"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Module manages user accounts
+type Module struct {
+	handler *delivery.Handler
+}
+
+// Name implements app.Module
+func (m *Module) Name() string {
+	return "user"
+}
+
+// Register implements app.Module
+func (m *Module) Register(deps *app.Deps) error {
+	repo := repository.NewUserPostgresRepository(deps.DB)
+	users := usecase.NewUserUsecase(repo, deps.Services.Audit)
+	deps.Services.Users = users
+	m.handler = delivery.NewHandler(users)
+	return nil
+}
+
+// Routes implements app.Module
+func (m *Module) Routes(r chi.Router, auth func(http.Handler) http.Handler) {
+	delivery.RegisterRoutes(r, m.handler, auth)
+}
+
+// Describe implements app.Module
+func (m *Module) Describe(spec *openapi.Spec) {
+	delivery.Describe(spec)
+}
@@ -2,10 +2,12 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"strings"
 	"time"
 
+	"portal-data-backend/infrastructure/db"
 	"portal-data-backend/internal/user/domain"
 	"portal-data-backend/pkg/errors"
 
@@ -29,11 +31,10 @@ func (r *userPostgresRepository) GetUserByID(ctx context.Context, id string) (*d
 		       email, password_hash, address, phone, thumbnail, status, bio, birth_date,
 		       created_at, updated_at
 		FROM users
-		WHERE id = $1 AND status != 'deleted'
-	`
+		WHERE id = $1 AND status != 'deleted' AND ` + db.InTenantOrganizations(ctx, "organization_id")
 
 	var user domain.User
-	err := r.db.GetContext(ctx, &user, query, id)
+	err := db.Conn(ctx, r.db).GetContext(ctx, &user, query, id)
 	if err != nil {
 		return nil, r.handleError(err)
 	}
@@ -44,7 +45,7 @@ func (r *userPostgresRepository) GetUserByID(ctx context.Context, id string) (*d
 // ListUsers retrieves a list of users with filters and pagination
 func (r *userPostgresRepository) ListUsers(ctx context.Context, filter *domain.UserFilter, limit, offset int, sortBy, sortOrder string) ([]*domain.User, int, error) {
 	// Build WHERE clause
-	whereClause := "WHERE status != 'deleted'"
+	whereClause := "WHERE status != 'deleted' AND " + db.InTenantOrganizations(ctx, "organization_id")
 	args := make([]interface{}, 0)
 	argCount := 1
 
@@ -75,7 +76,7 @@ func (r *userPostgresRepository) ListUsers(ctx context.Context, filter *domain.U
 	// Get total count
 	countQuery := "SELECT COUNT(*) FROM users " + whereClause
 	var total int
-	err := r.db.GetContext(ctx, &total, countQuery, args...)
+	err := db.Conn(ctx, r.db).GetContext(ctx, &total, countQuery, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count users: %w", err)
 	}
@@ -94,7 +95,7 @@ func (r *userPostgresRepository) ListUsers(ctx context.Context, filter *domain.U
 	args = append(args, limit, offset)
 
 	var users []*domain.User
-	err = r.db.SelectContext(ctx, &users, query, args...)
+	err = db.Conn(ctx, r.db).SelectContext(ctx, &users, query, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to list users: %w", err)
 	}
@@ -126,7 +127,7 @@ func (r *userPostgresRepository) UpdateUser(ctx context.Context, user *domain.Us
 		WHERE id = :id
 	`
 
-	result, err := r.db.NamedExecContext(ctx, query, user)
+	result, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, user)
 	if err != nil {
 		return fmt.Errorf("failed to update user: %w", err)
 	}
@@ -147,7 +148,7 @@ func (r *userPostgresRepository) DeleteUser(ctx context.Context, id string) erro
 		WHERE id = $1
 	`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := db.Conn(ctx, r.db).ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete user: %w", err)
 	}
@@ -168,7 +169,7 @@ func (r *userPostgresRepository) UpdateStatus(ctx context.Context, id string, st
 		WHERE id = $2
 	`
 
-	result, err := r.db.ExecContext(ctx, query, status, id)
+	result, err := db.Conn(ctx, r.db).ExecContext(ctx, query, status, id)
 	if err != nil {
 		return fmt.Errorf("failed to update user status: %w", err)
 	}
@@ -181,6 +182,17 @@ func (r *userPostgresRepository) UpdateStatus(ctx context.Context, id string, st
 	return nil
 }
 
+// MemberIDs retrieves the IDs of the active users of an organization
+func (r *userPostgresRepository) MemberIDs(ctx context.Context, organizationID string) ([]string, error) {
+	query := `SELECT id FROM users WHERE organization_id = $1 AND status = 'active' ORDER BY created_at`
+
+	ids := []string{}
+	if err := db.Conn(ctx, r.db).SelectContext(ctx, &ids, query, organizationID); err != nil {
+		return nil, fmt.Errorf("failed to get organization members: %w", err)
+	}
+	return ids, nil
+}
+
 // buildOrderClause builds a safe ORDER BY clause
 func (r *userPostgresRepository) buildOrderClause(sortBy, sortOrder string) string {
 	// Whitelist allowed columns
@@ -210,5 +222,8 @@ func (r *userPostgresRepository) handleError(err error) error {
 	if err == nil {
 		return nil
 	}
+	if err == sql.ErrNoRows {
+		return errors.ErrNotFound
+	}
 	return errors.Wrap(err, "database error")
 }
@@ -6,8 +6,17 @@ import (
 	"portal-data-backend/internal/user/domain"
 )
 
+// MemberReader is the part of the user module other modules find the users
+// of organizations through
+type MemberReader interface {
+	// MemberIDs retrieves the IDs of the active users of an organization
+	MemberIDs(ctx context.Context, organizationID string) ([]string, error)
+}
+
 // Usecase defines the interface for user business logic
 type Usecase interface {
+	MemberReader
+
 	// GetUserByID retrieves a user by ID
 	GetUserByID(ctx context.Context, id string) (*domain.UserInfo, error)
 
@@ -6,18 +6,21 @@ import (
 	"math"
 	"time"
 
+	"portal-data-backend/infrastructure/audit"
 	"portal-data-backend/internal/user/domain"
 )
 
 // userUsecase implements the Usecase interface
 type userUsecase struct {
 	userRepo domain.Repository
+	audit    *audit.Recorder
 }
 
-// NewUserUsecase creates a new user usecase
-func NewUserUsecase(userRepo domain.Repository) Usecase {
+// NewUserUsecase creates a new user usecase. recorder may be nil.
+func NewUserUsecase(userRepo domain.Repository, recorder *audit.Recorder) Usecase {
 	return &userUsecase{
 		userRepo: userRepo,
+		audit:    recorder,
 	}
 }
 
@@ -96,6 +99,8 @@ func (u *userUsecase) UpdateUser(ctx context.Context, id string, req *domain.Upd
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
+	before := *user
+
 	// Update fields
 	user.Name = req.Name
 	user.UpdatedAt = time.Now()
@@ -117,15 +122,21 @@ func (u *userUsecase) UpdateUser(ctx context.Context, id string, req *domain.Upd
 	if err := u.userRepo.UpdateUser(ctx, user); err != nil {
 		return nil, fmt.Errorf("failed to update user: %w", err)
 	}
+	u.audit.Record(ctx, "users", id, audit.ActionUpdate, &before, user)
 
 	return u.toUserInfo(user), nil
 }
 
 // DeleteUser soft deletes a user
 func (u *userUsecase) DeleteUser(ctx context.Context, id string) error {
+	user, err := u.userRepo.GetUserByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
 	if err := u.userRepo.DeleteUser(ctx, id); err != nil {
 		return fmt.Errorf("failed to delete user: %w", err)
 	}
+	u.audit.Record(ctx, "users", id, audit.ActionDelete, user, nil)
 	return nil
 }
 
@@ -134,9 +145,21 @@ func (u *userUsecase) UpdateUserStatus(ctx context.Context, id string, status st
 	if err := u.userRepo.UpdateStatus(ctx, id, status); err != nil {
 		return fmt.Errorf("failed to update user status: %w", err)
 	}
+	// GetUserByID hides deleted users, whose status may be changed too, so
+	// only the new status is recorded
+	u.audit.Record(ctx, "users", id, audit.ActionUpdate, nil, map[string]string{"status": status})
 	return nil
 }
 
+// MemberIDs retrieves the IDs of the active users of an organization
+func (u *userUsecase) MemberIDs(ctx context.Context, organizationID string) ([]string, error) {
+	ids, err := u.userRepo.MemberIDs(ctx, organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization members: %w", err)
+	}
+	return ids, nil
+}
+
 // toUserInfo converts User to UserInfo
 func (u *userUsecase) toUserInfo(user *domain.User) *domain.UserInfo {
 	return &domain.UserInfo{
@@ -1,42 +1,49 @@
 package http
 
 import (
-	"encoding/json"
-	"errors"
 	"net/http"
 	"strconv"
 
+	"portal-data-backend/infrastructure/auth"
+	datasetDomain "portal-data-backend/internal/dataset/domain"
 	vizDomain "portal-data-backend/internal/visualization/domain"
 	"portal-data-backend/internal/visualization/usecase"
+	"portal-data-backend/infrastructure/http/httputil"
+	"portal-data-backend/infrastructure/http/middleware"
+	"portal-data-backend/infrastructure/http/problem"
 	"portal-data-backend/infrastructure/http/response"
+	roleDomain "portal-data-backend/internal/role/domain"
 	pkgErrors "portal-data-backend/pkg/errors"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/go-playground/validator/v10"
 )
 
 type Handler struct {
 	vizUsecase usecase.Usecase
-	validator  *validator.Validate
+	datasets   datasetDomain.Authorizer
+	adminRoles []string
 }
 
-func NewHandler(vizUsecase usecase.Usecase) *Handler {
+// NewHandler creates a visualization handler. Changing the visualizations of
+// a dataset requires the edit role on it, which users with one of adminRoles
+// have on every dataset.
+func NewHandler(vizUsecase usecase.Usecase, datasets datasetDomain.Authorizer, adminRoles []string) *Handler {
 	return &Handler{
 		vizUsecase: vizUsecase,
-		validator:  validator.New(),
+		datasets:   datasets,
+		adminRoles: adminRoles,
 	}
 }
 
 func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Visualization ID is required", nil)
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
 	viz, err := h.vizUsecase.GetByID(r.Context(), id)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -73,7 +80,7 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 
 	resp, err := h.vizUsecase.List(r.Context(), req)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -81,22 +88,20 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
-	var req vizDomain.CreateVisualizationRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, response.CodeBadRequest, "Invalid request body", nil)
+	req, ok := httputil.Decode[vizDomain.CreateVisualizationRequest](w, r)
+	if !ok {
 		return
 	}
-
-	if err := h.validator.Struct(&req); err != nil {
-		response.ValidationError(w, response.CodeValidationFailed, "Validation failed", h.formatValidationErrors(err))
+	if err := h.authorize(r, req.DatasetID); err != nil {
+		h.handleError(w, r, err)
 		return
 	}
 
-	userID, _ := r.Context().Value("user_id").(string)
+	userID := auth.UserID(r.Context())
 
-	viz, err := h.vizUsecase.Create(r.Context(), &req, userID)
+	viz, err := h.vizUsecase.Create(r.Context(), req, userID)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -104,28 +109,29 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Visualization ID is required", nil)
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
-	var req vizDomain.UpdateVisualizationRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, response.CodeBadRequest, "Invalid request body", nil)
+	req, ok := httputil.Decode[vizDomain.UpdateVisualizationRequest](w, r)
+	if !ok {
 		return
 	}
-
-	if err := h.validator.Struct(&req); err != nil {
-		response.ValidationError(w, response.CodeValidationFailed, "Validation failed", h.formatValidationErrors(err))
+	if !h.authorizeVisualization(w, r, id) {
+		return
+	}
+	// Moving the visualization to another dataset needs the edit role on both
+	if err := h.authorize(r, req.DatasetID); err != nil {
+		h.handleError(w, r, err)
 		return
 	}
 
-	userID, _ := r.Context().Value("user_id").(string)
+	userID := auth.UserID(r.Context())
 
-	viz, err := h.vizUsecase.Update(r.Context(), id, &req, userID)
+	viz, err := h.vizUsecase.Update(r.Context(), id, req, userID)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -133,42 +139,57 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Visualization ID is required", nil)
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if !h.authorizeVisualization(w, r, id) {
 		return
 	}
 
 	if err := h.vizUsecase.Delete(r.Context(), id); err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
 	response.OK(w, response.CodeSuccess, "Visualization deleted successfully", nil)
 }
 
+// Restore handles restoring a deleted visualization
+func (h *Handler) Restore(w http.ResponseWriter, r *http.Request) {
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.vizUsecase.Restore(r.Context(), id); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.OK(w, response.CodeSuccess, "Visualization restored successfully", nil)
+}
+
 func (h *Handler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Visualization ID is required", nil)
+	id, ok := httputil.UUIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
 	var req struct {
 		Status string `json:"status" validate:"required"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, response.CodeBadRequest, "Invalid request body", nil)
+	if !httputil.DecodeInto(w, r, &req) {
 		return
 	}
 
-	if err := h.validator.Struct(&req); err != nil {
-		response.ValidationError(w, response.CodeValidationFailed, "Validation failed", h.formatValidationErrors(err))
+	if !h.authorizeVisualization(w, r, id) {
 		return
 	}
 
 	if err := h.vizUsecase.UpdateStatus(r.Context(), id, req.Status); err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -178,7 +199,7 @@ func (h *Handler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) GetStats(w http.ResponseWriter, r *http.Request) {
 	stats, err := h.vizUsecase.GetStats(r.Context())
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -186,9 +207,8 @@ func (h *Handler) GetStats(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) GetByDatasetID(w http.ResponseWriter, r *http.Request) {
-	datasetID := chi.URLParam(r, "datasetId")
-	if datasetID == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Dataset ID is required", nil)
+	datasetID, ok := httputil.UUIDParam(w, r, "datasetId")
+	if !ok {
 		return
 	}
 
@@ -197,7 +217,7 @@ func (h *Handler) GetByDatasetID(w http.ResponseWriter, r *http.Request) {
 
 	resp, err := h.vizUsecase.GetByDatasetID(r.Context(), datasetID, page, limit)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -205,9 +225,8 @@ func (h *Handler) GetByDatasetID(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) GetByOrganizationID(w http.ResponseWriter, r *http.Request) {
-	orgID := chi.URLParam(r, "orgId")
-	if orgID == "" {
-		response.BadRequest(w, response.CodeBadRequest, "Organization ID is required", nil)
+	orgID, ok := httputil.UUIDParam(w, r, "orgId")
+	if !ok {
 		return
 	}
 
@@ -216,50 +235,49 @@ func (h *Handler) GetByOrganizationID(w http.ResponseWriter, r *http.Request) {
 
 	resp, err := h.vizUsecase.GetByOrganizationID(r.Context(), orgID, page, limit)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
 	response.OK(w, response.CodeSuccess, "Organization visualizations retrieved successfully", resp)
 }
 
-func (h *Handler) handleError(w http.ResponseWriter, err error) {
-	if err == nil {
-		return
-	}
+// errorMapper maps the errors of the visualization module on top of the shared ones
+var errorMapper = problem.Default.With(
+	problem.Mapping{Err: pkgErrors.ErrNotFound, Status: http.StatusNotFound, Code: response.CodeNotFound, Message: "Visualization not found"},
+)
 
-	switch {
-	case errors.Is(err, pkgErrors.ErrNotFound):
-		response.NotFound(w, response.CodeNotFound, "Visualization not found", nil)
-	default:
-		response.InternalError(w, response.CodeInternalServerError, "Internal server error", nil)
-	}
+func (h *Handler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	errorMapper.Write(w, r, err)
 }
 
-func (h *Handler) formatValidationErrors(err error) []response.ErrorDetail {
-	var details []response.ErrorDetail
-	if validationErrors, ok := err.(validator.ValidationErrors); ok {
-		for _, fieldErr := range validationErrors {
-			details = append(details, response.ErrorDetail{
-				Field:   fieldErr.Field(),
-				Message: h.getValidationErrorMessage(fieldErr),
-			})
-		}
+// authorize checks the caller has the edit role on the dataset datasetID,
+// if the visualization belongs to one
+func (h *Handler) authorize(r *http.Request, datasetID *string) error {
+	if datasetID == nil {
+		return nil
+	}
+	user := auth.FromContext(r.Context())
+	caller := datasetDomain.Caller{
+		UserID:         user.UserID,
+		OrganizationID: user.OrganizationID,
+		Admin:          user.HasRole(h.adminRoles...),
 	}
-	return details
+	return h.datasets.Authorize(r.Context(), *datasetID, caller, datasetDomain.CollaboratorEdit)
 }
 
-func (h *Handler) getValidationErrorMessage(fieldErr validator.FieldError) string {
-	switch fieldErr.Tag() {
-	case "required":
-		return fieldErr.Field() + " is required"
-	case "min":
-		return fieldErr.Field() + " must be at least " + fieldErr.Param() + " characters"
-	case "max":
-		return fieldErr.Field() + " must be at most " + fieldErr.Param() + " characters"
-	default:
-		return fieldErr.Field() + " is invalid"
+// authorizeVisualization checks the caller may change the visualization id,
+// writing the error and returning false when not
+func (h *Handler) authorizeVisualization(w http.ResponseWriter, r *http.Request, id string) bool {
+	viz, err := h.vizUsecase.GetByID(r.Context(), id)
+	if err == nil {
+		err = h.authorize(r, viz.DatasetID)
+	}
+	if err != nil {
+		h.handleError(w, r, err)
+		return false
 	}
+	return true
 }
 
 func parseIntQuery(r *http.Request, key string, defaultValue int) int {
@@ -271,16 +289,27 @@ func parseIntQuery(r *http.Request, key string, defaultValue int) int {
 	return defaultValue
 }
 
-func RegisterRoutes(r chi.Router, handler *Handler) {
+// RegisterRoutes registers visualization routes. Reads are public and may expand
+// relations; writes go through auth and require the visualizations:write
+// permission, plus the edit role on their dataset for the visualizations of
+// a dataset, and restoring is left to users with one of adminRoles.
+func RegisterRoutes(r chi.Router, handler *Handler, auth func(http.Handler) http.Handler, relations response.Relations, adminRoles []string) {
 	r.Route("/visualizations", func(r chi.Router) {
-		r.Get("/", handler.List)
-		r.Post("/", handler.Create)
+		shape := middleware.Shape(relations)
+		r.With(shape).Get("/", handler.List)
 		r.Get("/stats", handler.GetStats)
-		r.Get("/dataset/{datasetId}", handler.GetByDatasetID)
-		r.Get("/organization/{orgId}", handler.GetByOrganizationID)
-		r.Get("/{id}", handler.GetByID)
-		r.Put("/{id}", handler.Update)
-		r.Delete("/{id}", handler.Delete)
-		r.Patch("/{id}/status", handler.UpdateStatus)
+		r.With(shape).Get("/dataset/{datasetId}", handler.GetByDatasetID)
+		r.With(shape).Get("/organization/{orgId}", handler.GetByOrganizationID)
+		r.With(shape).Get("/{id}", handler.GetByID)
+
+		r.Group(func(r chi.Router) {
+			r.Use(auth)
+			r.Use(middleware.RequirePermission(roleDomain.PermissionVisualizationsWrite))
+			r.Post("/", handler.Create)
+			r.Put("/{id}", handler.Update)
+			r.Delete("/{id}", handler.Delete)
+			r.With(middleware.RequireRole(adminRoles...)).Post("/{id}/restore", handler.Restore)
+			r.Patch("/{id}/status", handler.UpdateStatus)
+		})
 	})
 }
@@ -0,0 +1,132 @@
+package http_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"portal-data-backend/infrastructure/auth"
+	"portal-data-backend/infrastructure/http/middleware"
+	datasetDomain "portal-data-backend/internal/dataset/domain"
+	roleDomain "portal-data-backend/internal/role/domain"
+	delivery "portal-data-backend/internal/visualization/delivery/http"
+	"portal-data-backend/internal/visualization/domain"
+	"portal-data-backend/internal/visualization/usecase"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	datasetID = "6f1c2a8e-3b7d-4c5e-9a1f-2d3e4f5a6b7c"
+	vizID     = "0d9e8f7a-6b5c-4d3e-8f2a-1b0c9d8e7f6a"
+)
+
+// mockUsecase counts the writes it is asked for; its one visualization
+// belongs to datasetID
+type mockUsecase struct {
+	usecase.Usecase
+	writes int
+}
+
+func (m *mockUsecase) GetByID(ctx context.Context, id string) (*domain.VisualizationInfo, error) {
+	if id != vizID {
+		return nil, pkgErrors.ErrNotFound
+	}
+	dataset := datasetID
+	return &domain.VisualizationInfo{ID: vizID, DatasetID: &dataset}, nil
+}
+
+func (m *mockUsecase) Create(ctx context.Context, req *domain.CreateVisualizationRequest, userID string) (*domain.VisualizationInfo, error) {
+	m.writes++
+	return &domain.VisualizationInfo{DatasetID: req.DatasetID}, nil
+}
+
+func (m *mockUsecase) Update(ctx context.Context, id string, req *domain.UpdateVisualizationRequest, userID string) (*domain.VisualizationInfo, error) {
+	m.writes++
+	return &domain.VisualizationInfo{ID: id}, nil
+}
+
+func (m *mockUsecase) Delete(ctx context.Context, id string) error {
+	m.writes++
+	return nil
+}
+
+func (m *mockUsecase) UpdateStatus(ctx context.Context, id string, status string) error {
+	m.writes++
+	return nil
+}
+
+// grants authorizes the users it grants a role on datasetID
+type grants map[string]datasetDomain.CollaboratorRole
+
+func (g grants) Authorize(ctx context.Context, id string, caller datasetDomain.Caller, role datasetDomain.CollaboratorRole) error {
+	if caller.Admin || (id == datasetID && g[caller.UserID].Grants(role)) {
+		return nil
+	}
+	return fmt.Errorf("%w: you need the %s role on the dataset", pkgErrors.ErrForbidden, role)
+}
+
+// Test only the collaborators with the edit role on a dataset change its
+// visualizations, even when their role grants visualizations:write
+func TestVisualizations_RequireEdit(t *testing.T) {
+	visualizations := &mockUsecase{}
+	// The tests sign in as the user of their header
+	signIn := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims := auth.Claims{UserID: r.Header.Get("X-User"), RoleID: "editor"}
+			next.ServeHTTP(w, r.WithContext(auth.WithClaims(r.Context(), claims)))
+		})
+	}
+	lookup := func(ctx context.Context, roleID string) ([]string, error) {
+		return []string{roleDomain.PermissionVisualizationsWrite}, nil
+	}
+	collaborators := grants{"viewer": datasetDomain.CollaboratorView, "editor": datasetDomain.CollaboratorEdit}
+
+	r := chi.NewRouter()
+	r.Use(middleware.Permissions(lookup, "admin"))
+	delivery.RegisterRoutes(r, delivery.NewHandler(visualizations, collaborators, []string{"admin"}), signIn, nil, []string{"admin"})
+
+	writes := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		{"creating", http.MethodPost, "/visualizations/", `{"title": "Rainfall", "type": "bar", "config": "{}", "dataset_id": "` + datasetID + `"}`},
+		{"updating", http.MethodPut, "/visualizations/" + vizID, `{"title": "Rainfall by month"}`},
+		{"updating the status", http.MethodPatch, "/visualizations/" + vizID + "/status", `{"status": "published"}`},
+		{"deleting", http.MethodDelete, "/visualizations/" + vizID, ""},
+	}
+	users := []struct {
+		user   string
+		status int
+	}{
+		{"viewer", http.StatusForbidden},
+		{"stranger", http.StatusForbidden},
+		{"editor", 0},
+	}
+	for _, write := range writes {
+		for _, u := range users {
+			t.Run(write.name+" as "+u.user, func(t *testing.T) {
+				req := httptest.NewRequest(write.method, write.path, strings.NewReader(write.body))
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set("X-User", u.user)
+				rec := httptest.NewRecorder()
+				r.ServeHTTP(rec, req)
+				if u.status != 0 && rec.Code != u.status {
+					t.Errorf("Expected %d, got %d: %s", u.status, rec.Code, rec.Body.String())
+				}
+				if u.status == 0 && rec.Code >= http.StatusBadRequest {
+					t.Errorf("Expected the write to succeed, got %d: %s", rec.Code, rec.Body.String())
+				}
+			})
+		}
+	}
+	if visualizations.writes != len(writes) {
+		t.Errorf("Expected the editor alone to write, got %d writes", visualizations.writes)
+	}
+}
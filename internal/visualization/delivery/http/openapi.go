@@ -0,0 +1,29 @@
+package http
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	vizDomain "portal-data-backend/internal/visualization/domain"
+)
+
+// Describe adds the visualization routes to the API specification
+func Describe(spec *openapi.Spec) {
+	api := spec.Tag("visualizations", "Visualizations of datasets")
+	api.Get("/visualizations", "List visualizations").Public().Shaped("dataset", "organization").Query(vizDomain.ListVisualizationsRequest{}).Param("include_deleted", false).Returns(http.StatusOK, vizDomain.VisualizationListResponse{})
+	api.Post("/visualizations", "Create visualization").Body(vizDomain.CreateVisualizationRequest{}).Returns(http.StatusCreated, vizDomain.VisualizationInfo{})
+	api.Get("/visualizations/stats", "Get visualization statistics").Public().Returns(http.StatusOK, vizDomain.VisualizationStats{})
+	api.Get("/visualizations/dataset/{datasetId}", "List visualizations of a dataset").Public().Shaped("dataset", "organization").
+		Query(vizDomain.ListVisualizationsRequest{}, "page", "limit").
+		Returns(http.StatusOK, vizDomain.VisualizationListResponse{})
+	api.Get("/visualizations/organization/{orgId}", "List visualizations of an organization").Public().Shaped("dataset", "organization").
+		Query(vizDomain.ListVisualizationsRequest{}, "page", "limit").
+		Returns(http.StatusOK, vizDomain.VisualizationListResponse{})
+	api.Get("/visualizations/{id}", "Get visualization").Public().Shaped("dataset", "organization").Param("include_deleted", false).Returns(http.StatusOK, vizDomain.VisualizationInfo{})
+	api.Put("/visualizations/{id}", "Update visualization").Body(vizDomain.UpdateVisualizationRequest{}).Returns(http.StatusOK, vizDomain.VisualizationInfo{})
+	api.Delete("/visualizations/{id}", "Delete visualization").Returns(http.StatusOK, nil)
+	api.Post("/visualizations/{id}/restore", "Restore deleted visualization").Returns(http.StatusOK, nil)
+	api.Patch("/visualizations/{id}/status", "Update visualization status").Body(struct {
+		Status string `json:"status" validate:"required"`
+	}{}).Returns(http.StatusOK, nil)
+}
@@ -97,6 +97,8 @@ type VisualizationInfo struct {
 	CreatedBy      string    `json:"created_by"`
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
+	// DeletedAt is set on deleted visualizations, which only admins list
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
 // VisualizationListResponse represents paginated visualization list
@@ -10,6 +10,7 @@ type Repository interface {
 	Create(ctx context.Context, viz *Visualization) error
 	Update(ctx context.Context, id string, viz *Visualization) error
 	Delete(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) error
 	UpdateStatus(ctx context.Context, id string, status string) error
 	GetStats(ctx context.Context) (*VisualizationStats, error)
 	GetByDatasetID(ctx context.Context, datasetID string, limit, offset int) ([]*Visualization, int, error)
@@ -0,0 +1,81 @@
+// Package visualization is the module managing visualizations of datasets.
+package visualization
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"portal-data-backend/infrastructure/db"
+	"portal-data-backend/infrastructure/http/openapi"
+	"portal-data-backend/infrastructure/http/response"
+	"portal-data-backend/internal/app"
+	datasetDomain "portal-data-backend/internal/dataset/domain"
+	orgDomain "portal-data-backend/internal/organization/domain"
+	delivery "portal-data-backend/internal/visualization/delivery/http"
+	"portal-data-backend/internal/visualization/repository"
+	"portal-data-backend/internal/visualization/usecase"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jmoiron/sqlx"
+)
+
+// Module manages visualizations
+type Module struct {
+	handler    *delivery.Handler
+	db         *sqlx.DB
+	relations  response.Relations
+	adminRoles []string
+}
+
+// Name implements app.Module
+func (m *Module) Name() string {
+	return "visualization"
+}
+
+// Register implements app.Module
+func (m *Module) Register(deps *app.Deps) error {
+	if deps.Services.Datasets == nil {
+		return app.MissingServiceError("dataset")
+	}
+	if deps.Services.Organizations == nil {
+		return app.MissingServiceError("organization")
+	}
+
+	m.db = deps.DB
+	repo := repository.NewVisualizationPostgresRepository(deps.DB)
+	visualizations := usecase.NewVisualizationUsecase(repo)
+	deps.Services.Visualizations = visualizations
+	m.handler = delivery.NewHandler(visualizations, deps.Services.Datasets, deps.Config.Audit.AdminRoles)
+	m.relations = response.Relations{
+		"dataset": {
+			Key: "dataset_id",
+			Load: response.LoadBy(deps.Services.Datasets.GetByIDs, func(dataset *datasetDomain.DatasetResponse) string {
+				return dataset.ID
+			}),
+		},
+		"organization": {
+			Key: "organization_id",
+			Load: response.LoadBy(deps.Services.Organizations.GetByIDs, func(org *orgDomain.OrganizationResponse) string {
+				return org.ID
+			}),
+		},
+	}
+	m.adminRoles = deps.Config.Audit.AdminRoles
+	return nil
+}
+
+// Routes implements app.Module
+func (m *Module) Routes(r chi.Router, auth func(http.Handler) http.Handler) {
+	delivery.RegisterRoutes(r, m.handler, auth, m.relations, m.adminRoles)
+}
+
+// Describe implements app.Module
+func (m *Module) Describe(spec *openapi.Spec) {
+	delivery.Describe(spec)
+}
+
+// Purge implements app.Purger
+func (m *Module) Purge(ctx context.Context, before time.Time) (int64, error) {
+	return db.PurgeDeleted(ctx, m.db, "visualizations", before)
+}
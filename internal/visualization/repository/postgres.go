@@ -8,6 +8,8 @@ import (
 
 	visualizationDomain "portal-data-backend/internal/visualization/domain"
 
+	"portal-data-backend/infrastructure/db"
+
 	"github.com/jmoiron/sqlx"
 )
 
@@ -24,11 +26,10 @@ func (r *visualizationPostgresRepository) GetByID(ctx context.Context, id string
 		SELECT id, title, description, type, config, dataset_id, organization_id, topic_id,
 		       is_highlight, status, created_by, updated_by, created_at, updated_at, deleted_at
 		FROM visualizations
-		WHERE id = $1 AND deleted_at IS NULL
-	`
+		WHERE id = $1 AND ` + db.NotDeleted(ctx, "deleted_at") + " AND " + db.InTenantOrganizations(ctx, "organization_id")
 
 	var viz visualizationDomain.Visualization
-	err := r.db.GetContext(ctx, &viz, query, id)
+	err := db.Conn(ctx, r.db).GetContext(ctx, &viz, query, id)
 	if err != nil {
 		return nil, r.handleError(err)
 	}
@@ -36,7 +37,7 @@ func (r *visualizationPostgresRepository) GetByID(ctx context.Context, id string
 }
 
 func (r *visualizationPostgresRepository) List(ctx context.Context, filter *visualizationDomain.VisualizationFilter, limit, offset int) ([]*visualizationDomain.Visualization, int, error) {
-	whereClause := "WHERE deleted_at IS NULL"
+	whereClause := "WHERE " + db.NotDeleted(ctx, "deleted_at") + " AND " + db.InTenantOrganizations(ctx, "organization_id")
 	args := []interface{}{}
 	argCount := 1
 
@@ -81,7 +82,7 @@ func (r *visualizationPostgresRepository) List(ctx context.Context, filter *visu
 
 	countQuery := "SELECT COUNT(*) FROM visualizations " + whereClause
 	var total int
-	err := r.db.GetContext(ctx, &total, countQuery, args...)
+	err := db.Conn(ctx, r.db).GetContext(ctx, &total, countQuery, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count visualizations: %w", err)
 	}
@@ -95,7 +96,7 @@ func (r *visualizationPostgresRepository) List(ctx context.Context, filter *visu
 	args = append(args, limit, offset)
 
 	var vizs []*visualizationDomain.Visualization
-	err = r.db.SelectContext(ctx, &vizs, query, args...)
+	err = db.Conn(ctx, r.db).SelectContext(ctx, &vizs, query, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to list visualizations: %w", err)
 	}
@@ -114,7 +115,7 @@ func (r *visualizationPostgresRepository) Create(ctx context.Context, viz *visua
 		)
 	`
 
-	_, err := r.db.NamedExecContext(ctx, query, viz)
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, viz)
 	if err != nil {
 		return fmt.Errorf("failed to create visualization: %w", err)
 	}
@@ -132,7 +133,7 @@ func (r *visualizationPostgresRepository) Update(ctx context.Context, id string,
 	`
 
 	viz.ID = id
-	_, err := r.db.NamedExecContext(ctx, query, viz)
+	_, err := db.Conn(ctx, r.db).NamedExecContext(ctx, query, viz)
 	if err != nil {
 		return fmt.Errorf("failed to update visualization: %w", err)
 	}
@@ -140,17 +141,16 @@ func (r *visualizationPostgresRepository) Update(ctx context.Context, id string,
 }
 
 func (r *visualizationPostgresRepository) Delete(ctx context.Context, id string) error {
-	query := `UPDATE visualizations SET deleted_at = $1 WHERE id = $2`
-	_, err := r.db.ExecContext(ctx, query, time.Now(), id)
-	if err != nil {
-		return fmt.Errorf("failed to delete visualization: %w", err)
-	}
-	return nil
+	return db.SoftDelete(ctx, db.Conn(ctx, r.db), "visualizations", id)
+}
+
+func (r *visualizationPostgresRepository) Restore(ctx context.Context, id string) error {
+	return db.Restore(ctx, db.Conn(ctx, r.db), "visualizations", id)
 }
 
 func (r *visualizationPostgresRepository) UpdateStatus(ctx context.Context, id string, status string) error {
 	query := `UPDATE visualizations SET status = $1, updated_at = $2 WHERE id = $3`
-	_, err := r.db.ExecContext(ctx, query, status, time.Now(), id)
+	_, err := db.Conn(ctx, r.db).ExecContext(ctx, query, status, time.Now(), id)
 	if err != nil {
 		return fmt.Errorf("failed to update visualization status: %w", err)
 	}
@@ -170,7 +170,7 @@ func (r *visualizationPostgresRepository) GetStats(ctx context.Context) (*visual
 	`
 
 	var stats visualizationDomain.VisualizationStats
-	err := r.db.GetContext(ctx, &stats, query)
+	err := db.Conn(ctx, r.db).GetContext(ctx, &stats, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get visualization stats: %w", err)
 	}
@@ -189,14 +189,14 @@ func (r *visualizationPostgresRepository) GetByDatasetID(ctx context.Context, da
 	`
 
 	var vizs []*visualizationDomain.Visualization
-	err := r.db.SelectContext(ctx, &vizs, query, datasetID, limit, offset)
+	err := db.Conn(ctx, r.db).SelectContext(ctx, &vizs, query, datasetID, limit, offset)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to get dataset visualizations: %w", err)
 	}
 
 	countQuery := `SELECT COUNT(*) FROM visualizations WHERE dataset_id = $1 AND deleted_at IS NULL`
 	var total int
-	err = r.db.GetContext(ctx, &total, countQuery, datasetID)
+	err = db.Conn(ctx, r.db).GetContext(ctx, &total, countQuery, datasetID)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count dataset visualizations: %w", err)
 	}
@@ -215,14 +215,14 @@ func (r *visualizationPostgresRepository) GetByOrganizationID(ctx context.Contex
 	`
 
 	var vizs []*visualizationDomain.Visualization
-	err := r.db.SelectContext(ctx, &vizs, query, orgID, limit, offset)
+	err := db.Conn(ctx, r.db).SelectContext(ctx, &vizs, query, orgID, limit, offset)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to get organization visualizations: %w", err)
 	}
 
 	countQuery := `SELECT COUNT(*) FROM visualizations WHERE organization_id = $1 AND deleted_at IS NULL`
 	var total int
-	err = r.db.GetContext(ctx, &total, countQuery, orgID)
+	err = db.Conn(ctx, r.db).GetContext(ctx, &total, countQuery, orgID)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count organization visualizations: %w", err)
 	}
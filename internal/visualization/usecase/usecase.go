@@ -17,6 +17,7 @@ type Usecase interface {
 	Create(ctx context.Context, req *domain.CreateVisualizationRequest, userID string) (*domain.VisualizationInfo, error)
 	Update(ctx context.Context, id string, req *domain.UpdateVisualizationRequest, userID string) (*domain.VisualizationInfo, error)
 	Delete(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) error
 	UpdateStatus(ctx context.Context, id string, status string) error
 	GetStats(ctx context.Context) (*domain.VisualizationStats, error)
 	GetByDatasetID(ctx context.Context, datasetID string, page, limit int) (*domain.VisualizationListResponse, error)
@@ -161,6 +162,13 @@ func (u *visualizationUsecase) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+func (u *visualizationUsecase) Restore(ctx context.Context, id string) error {
+	if err := u.repo.Restore(ctx, id); err != nil {
+		return fmt.Errorf("failed to restore visualization: %w", err)
+	}
+	return nil
+}
+
 func (u *visualizationUsecase) UpdateStatus(ctx context.Context, id string, status string) error {
 	if err := u.repo.UpdateStatus(ctx, id, status); err != nil {
 		return fmt.Errorf("failed to update visualization status: %w", err)
@@ -257,5 +265,6 @@ func (u *visualizationUsecase) toInfo(viz *domain.Visualization) *domain.Visuali
 		CreatedBy:      viz.CreatedBy,
 		CreatedAt:      viz.CreatedAt,
 		UpdatedAt:      viz.UpdatedAt,
+		DeletedAt:      viz.DeletedAt,
 	}
 }
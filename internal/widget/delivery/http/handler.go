@@ -0,0 +1,84 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"portal-data-backend/infrastructure/http/middleware"
+	"portal-data-backend/infrastructure/http/problem"
+	"portal-data-backend/infrastructure/http/response"
+	datasetDomain "portal-data-backend/internal/dataset/domain"
+	orgDomain "portal-data-backend/internal/organization/domain"
+	widgetDomain "portal-data-backend/internal/widget/domain"
+	"portal-data-backend/internal/widget/usecase"
+	pkgErrors "portal-data-backend/pkg/errors"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	widgetUsecase usecase.Usecase
+	cacheControl  string
+}
+
+// NewHandler creates the widget handler. Browsers and CDNs may keep widgets
+// for maxAge.
+func NewHandler(widgetUsecase usecase.Usecase, maxAge time.Duration) *Handler {
+	return &Handler{
+		widgetUsecase: widgetUsecase,
+		cacheControl:  fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds())),
+	}
+}
+
+// DatasetCount returns the number of public datasets of the portal
+func (h *Handler) DatasetCount(w http.ResponseWriter, r *http.Request) {
+	count, err := h.widgetUsecase.DatasetCount(r.Context())
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", h.cacheControl)
+	response.OK(w, response.CodeSuccess, "Dataset count retrieved successfully", count)
+}
+
+// LatestDatasets lists the public datasets an organization published last
+func (h *Handler) LatestDatasets(w http.ResponseWriter, r *http.Request) {
+	req := &widgetDomain.LatestDatasetsRequest{}
+	if value := r.URL.Query().Get("limit"); value != "" {
+		if limit, err := strconv.Atoi(value); err == nil {
+			req.Limit = limit
+		}
+	}
+
+	latest, err := h.widgetUsecase.LatestDatasets(r.Context(), chi.URLParam(r, "slug"), req)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	middleware.SurrogateKeys(w, orgDomain.SurrogateKey(latest.Organization.ID))
+	w.Header().Set("Cache-Control", h.cacheControl)
+	response.OK(w, response.CodeSuccess, "Latest datasets retrieved successfully", latest)
+}
+
+// errorMapper maps the errors of the widget module on top of the shared ones
+var errorMapper = problem.Default.With(
+	problem.Mapping{Err: pkgErrors.ErrNotFound, Status: http.StatusNotFound, Code: response.CodeNotFound, Message: "Organization not found"},
+)
+
+func (h *Handler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	errorMapper.Write(w, r, err)
+}
+
+// RegisterRoutes registers the public widget routes. Responses are cached
+// by cached until the datasets change and carry an ETag to revalidate them.
+func RegisterRoutes(r chi.Router, handler *Handler, cached func(keys ...string) func(http.Handler) http.Handler) {
+	r.Route("/widgets", func(r chi.Router) {
+		r.Use(middleware.ETag)
+		r.With(cached(datasetDomain.SurrogateKeyDatasets)).Get("/dataset-count", handler.DatasetCount)
+		r.With(cached(datasetDomain.SurrogateKeyDatasets)).Get("/org/{slug}/latest-datasets", handler.LatestDatasets)
+	})
+}
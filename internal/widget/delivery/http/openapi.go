@@ -0,0 +1,17 @@
+package http
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/http/openapi"
+	widgetDomain "portal-data-backend/internal/widget/domain"
+)
+
+// Describe adds the widget routes to the API specification
+func Describe(spec *openapi.Spec) {
+	api := spec.Tag("widgets", "Public statistics embedded on agency websites")
+	api.Get("/widgets/dataset-count", "Count public datasets").Public().Returns(http.StatusOK, widgetDomain.DatasetCount{})
+	api.Get("/widgets/org/{slug}/latest-datasets", "List the latest public datasets of an organization").Public().
+		Query(widgetDomain.LatestDatasetsRequest{}, "limit").
+		Returns(http.StatusOK, widgetDomain.LatestDatasets{})
+}
@@ -0,0 +1,37 @@
+package domain
+
+import "time"
+
+// DatasetCount represents the number of public datasets the portal
+// publishes
+type DatasetCount struct {
+	Count int `json:"count"`
+}
+
+// LatestDatasets represents the datasets an organization published last
+type LatestDatasets struct {
+	Organization Organization `json:"organization"`
+	Datasets     []Dataset    `json:"datasets"`
+}
+
+// Organization is the part of an organization widgets show
+type Organization struct {
+	ID      string  `json:"id"`
+	Name    string  `json:"name"`
+	Slug    string  `json:"slug"`
+	LogoURL *string `json:"logo_url,omitempty"`
+}
+
+// Dataset is the part of a dataset widgets show
+type Dataset struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Slug        string    `json:"slug"`
+	Description *string   `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// LatestDatasetsRequest represents latest datasets input
+type LatestDatasetsRequest struct {
+	Limit int `json:"limit" validate:"min=1,max=20"`
+}
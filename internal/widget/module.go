@@ -0,0 +1,56 @@
+// Package widget is the module serving the statistics widgets agency
+// websites embed.
+package widget
+
+import (
+	"net/http"
+
+	"portal-data-backend/infrastructure/cache"
+	"portal-data-backend/infrastructure/http/middleware"
+	"portal-data-backend/infrastructure/http/openapi"
+	"portal-data-backend/internal/app"
+	delivery "portal-data-backend/internal/widget/delivery/http"
+	"portal-data-backend/internal/widget/usecase"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Module serves small public widgets built with the usecases of the dataset
+// and organization modules
+type Module struct {
+	handler    *delivery.Handler
+	responses  *cache.Namespace
+	surrogates *cache.Surrogates
+}
+
+// Name implements app.Module
+func (m *Module) Name() string {
+	return "widget"
+}
+
+// Register implements app.Module
+func (m *Module) Register(deps *app.Deps) error {
+	if deps.Services.Datasets == nil {
+		return app.MissingServiceError("dataset")
+	}
+	if deps.Services.Organizations == nil {
+		return app.MissingServiceError("organization")
+	}
+
+	cfg := deps.Config.Widget
+	m.responses = deps.Cache.Namespace("responses", deps.Config.Cache.ResponseTTL)
+	m.surrogates = deps.Cache.Surrogates(deps.Config.Cache.ResponseTTL)
+	widgets := usecase.NewWidgetUsecase(deps.Services.Datasets, deps.Services.Organizations, cfg)
+	m.handler = delivery.NewHandler(widgets, cfg.MaxAge)
+	return nil
+}
+
+// Routes implements app.Module
+func (m *Module) Routes(r chi.Router, auth func(http.Handler) http.Handler) {
+	delivery.RegisterRoutes(r, m.handler, middleware.ResponseCache(m.responses, m.surrogates))
+}
+
+// Describe implements app.Module
+func (m *Module) Describe(spec *openapi.Spec) {
+	delivery.Describe(spec)
+}
@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"portal-data-backend/infrastructure/config"
+	datasetDomain "portal-data-backend/internal/dataset/domain"
+	orgDomain "portal-data-backend/internal/organization/domain"
+	"portal-data-backend/internal/widget/domain"
+)
+
+// maxLatest bounds the datasets a list widget shows
+const maxLatest = 20
+
+// DatasetLister is the part of the dataset module widgets count and list
+// datasets with
+type DatasetLister interface {
+	List(ctx context.Context, req *datasetDomain.ListDatasetsRequest) (*datasetDomain.DatasetListResponse, error)
+}
+
+// OrganizationReader is the part of the organization module widgets find
+// organizations with
+type OrganizationReader interface {
+	GetBySlug(ctx context.Context, slug string) (*orgDomain.OrganizationResponse, error)
+}
+
+// Usecase serves the statistics widgets agency websites embed. Widgets
+// only show published public datasets.
+type Usecase interface {
+	// DatasetCount counts the datasets of the portal
+	DatasetCount(ctx context.Context) (*domain.DatasetCount, error)
+	// LatestDatasets lists the datasets the organization of slug published
+	// last, newest first
+	LatestDatasets(ctx context.Context, slug string, req *domain.LatestDatasetsRequest) (*domain.LatestDatasets, error)
+}
+
+type widgetUsecase struct {
+	datasets DatasetLister
+	orgs     OrganizationReader
+	cfg      config.WidgetConfig
+}
+
+// NewWidgetUsecase creates the widget usecase
+func NewWidgetUsecase(datasets DatasetLister, orgs OrganizationReader, cfg config.WidgetConfig) Usecase {
+	return &widgetUsecase{datasets: datasets, orgs: orgs, cfg: cfg}
+}
+
+func (u *widgetUsecase) DatasetCount(ctx context.Context) (*domain.DatasetCount, error) {
+	resp, err := u.datasets.List(ctx, public(&datasetDomain.ListDatasetsRequest{Page: 1, Limit: 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count datasets: %w", err)
+	}
+	return &domain.DatasetCount{Count: resp.Meta.Total}, nil
+}
+
+func (u *widgetUsecase) LatestDatasets(ctx context.Context, slug string, req *domain.LatestDatasetsRequest) (*domain.LatestDatasets, error) {
+	if req.Limit < 1 {
+		req.Limit = u.cfg.LatestLimit
+	}
+	if req.Limit > maxLatest {
+		req.Limit = maxLatest
+	}
+
+	org, err := u.orgs.GetBySlug(ctx, slug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+	resp, err := u.datasets.List(ctx, public(&datasetDomain.ListDatasetsRequest{
+		Page:           1,
+		Limit:          req.Limit,
+		OrganizationID: org.ID,
+		SortBy:         "created_at",
+		SortOrder:      "DESC",
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list datasets: %w", err)
+	}
+
+	latest := &domain.LatestDatasets{
+		Organization: domain.Organization{ID: org.ID, Name: org.Name, Slug: org.Slug, LogoURL: org.LogoURL},
+		Datasets:     make([]domain.Dataset, len(resp.Datasets)),
+	}
+	for i, dataset := range resp.Datasets {
+		latest.Datasets[i] = domain.Dataset{
+			ID:          dataset.ID,
+			Name:        dataset.Name,
+			Slug:        dataset.Slug,
+			Description: dataset.Description,
+			CreatedAt:   dataset.CreatedAt,
+		}
+	}
+	return latest, nil
+}
+
+// public narrows req to the datasets widgets show
+func public(req *datasetDomain.ListDatasetsRequest) *datasetDomain.ListDatasetsRequest {
+	req.Status = string(datasetDomain.DatasetStatusPublished)
+	req.Classification = "public"
+	return req
+}
@@ -0,0 +1,70 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"portal-data-backend/infrastructure/config"
+	datasetDomain "portal-data-backend/internal/dataset/domain"
+	orgDomain "portal-data-backend/internal/organization/domain"
+	"portal-data-backend/internal/widget/domain"
+	"portal-data-backend/internal/widget/usecase"
+	pkgerrors "portal-data-backend/pkg/errors"
+)
+
+// mockDatasets keeps the requests it lists datasets for
+type mockDatasets struct {
+	requests []datasetDomain.ListDatasetsRequest
+}
+
+func (m *mockDatasets) List(ctx context.Context, req *datasetDomain.ListDatasetsRequest) (*datasetDomain.DatasetListResponse, error) {
+	m.requests = append(m.requests, *req)
+	datasets := make([]datasetDomain.DatasetResponse, req.Limit)
+	for i := range datasets {
+		datasets[i] = datasetDomain.DatasetResponse{ID: "ds", Name: "Dataset", OrganizationID: req.OrganizationID}
+	}
+	return &datasetDomain.DatasetListResponse{Datasets: datasets, Meta: datasetDomain.ListMeta{Total: 42}}, nil
+}
+
+type mockOrganizations struct{}
+
+func (mockOrganizations) GetBySlug(ctx context.Context, slug string) (*orgDomain.OrganizationResponse, error) {
+	if slug != "health" {
+		return nil, pkgerrors.ErrNotFound
+	}
+	return &orgDomain.OrganizationResponse{ID: "org-1", Name: "Health Office", Slug: slug}, nil
+}
+
+// Test widgets only count and list published public datasets, as many as
+// asked for within bounds
+func TestWidget(t *testing.T) {
+	ctx := context.Background()
+	datasets := &mockDatasets{}
+	widgets := usecase.NewWidgetUsecase(datasets, mockOrganizations{}, config.WidgetConfig{LatestLimit: 5})
+
+	count, err := widgets.DatasetCount(ctx)
+	if err != nil || count.Count != 42 {
+		t.Fatalf("Expected 42 datasets, got %v, %v", count, err)
+	}
+
+	for limit, want := range map[int]int{0: 5, 3: 3, 500: 20} {
+		latest, err := widgets.LatestDatasets(ctx, "health", &domain.LatestDatasetsRequest{Limit: limit})
+		if err != nil {
+			t.Fatalf("Failed to list latest datasets: %v", err)
+		}
+		if len(latest.Datasets) != want || latest.Organization.Name != "Health Office" {
+			t.Errorf("Expected %d datasets of Health Office for limit %d, got %d of %s", want, limit, len(latest.Datasets), latest.Organization.Name)
+		}
+	}
+
+	for _, req := range datasets.requests {
+		if req.Status != string(datasetDomain.DatasetStatusPublished) || req.Classification != "public" {
+			t.Errorf("Expected only published public datasets, got %+v", req)
+		}
+	}
+
+	if _, err := widgets.LatestDatasets(ctx, "unknown", &domain.LatestDatasetsRequest{}); !errors.Is(err, pkgerrors.ErrNotFound) {
+		t.Errorf("Expected an unknown organization not to be found, got %v", err)
+	}
+}
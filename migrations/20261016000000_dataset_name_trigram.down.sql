@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_datasets_name_trgm;
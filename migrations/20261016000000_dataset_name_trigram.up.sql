@@ -0,0 +1,4 @@
+-- Trigram index behind dataset search suggestions ("did you mean")
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_datasets_name_trgm ON datasets USING gin (name gin_trgm_ops);
@@ -0,0 +1,19 @@
+-- Audit log of changes, written by usecases and the audit middleware
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id              UUID PRIMARY KEY,
+    actor_id        TEXT NOT NULL DEFAULT '',
+    actor_email     TEXT NOT NULL DEFAULT '',
+    organization_id TEXT NOT NULL DEFAULT '',
+    entity_type     TEXT NOT NULL,
+    entity_id       TEXT NOT NULL DEFAULT '',
+    action          TEXT NOT NULL,
+    changes         JSONB,
+    request_id      TEXT NOT NULL DEFAULT '',
+    method          TEXT NOT NULL DEFAULT '',
+    path            TEXT NOT NULL DEFAULT '',
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs (created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_entity ON audit_logs (entity_type, entity_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor ON audit_logs (actor_id, created_at DESC);
@@ -0,0 +1,3 @@
+-- The deleted_at columns are kept: the analytics queries read them too
+DROP INDEX IF EXISTS idx_datasets_deleted_at;
+DROP INDEX IF EXISTS idx_organizations_deleted_at;
@@ -0,0 +1,7 @@
+-- Organizations and datasets are soft deleted like the other records, so
+-- they can be restored
+ALTER TABLE organizations ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE datasets ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_organizations_deleted_at ON organizations (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_datasets_deleted_at ON datasets (deleted_at) WHERE deleted_at IS NOT NULL;
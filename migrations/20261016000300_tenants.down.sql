@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_settings_tenant_id;
+ALTER TABLE settings DROP COLUMN IF EXISTS tenant_id;
+
+DROP INDEX IF EXISTS idx_organizations_tenant_id;
+ALTER TABLE organizations DROP COLUMN IF EXISTS tenant_id;
+
+DROP TABLE IF EXISTS tenants;
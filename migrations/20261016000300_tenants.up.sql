@@ -0,0 +1,22 @@
+-- Portals hosted by the deployment. Existing records belong to the default
+-- tenant.
+CREATE TABLE IF NOT EXISTS tenants (
+    id          TEXT PRIMARY KEY,
+    name        TEXT NOT NULL,
+    hosts       TEXT[] NOT NULL DEFAULT '{}',
+    status      TEXT NOT NULL DEFAULT 'active',
+    branding    JSONB NOT NULL DEFAULT '{}',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO tenants (id, name) VALUES ('default', 'Default') ON CONFLICT (id) DO NOTHING;
+
+-- Organizations belong to a tenant; their datasets, visualizations,
+-- publications and users are scoped through them
+ALTER TABLE organizations ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants (id);
+CREATE INDEX IF NOT EXISTS idx_organizations_tenant_id ON organizations (tenant_id);
+
+-- Settings without a tenant are shared by every tenant
+ALTER TABLE settings ADD COLUMN IF NOT EXISTS tenant_id TEXT REFERENCES tenants (id);
+CREATE INDEX IF NOT EXISTS idx_settings_tenant_id ON settings (tenant_id) WHERE tenant_id IS NOT NULL;
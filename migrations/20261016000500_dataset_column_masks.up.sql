@@ -0,0 +1,11 @@
+-- Sensitive columns of the rows of datasets and how they are masked for
+-- readers without a privileged role
+CREATE TABLE IF NOT EXISTS dataset_column_masks (
+    dataset_id  UUID NOT NULL REFERENCES datasets (id) ON DELETE CASCADE,
+    column_name TEXT NOT NULL,
+    strategy    TEXT NOT NULL CHECK (strategy IN ('hash', 'redact', 'bucket')),
+    bucket_size DOUBLE PRECISION NOT NULL DEFAULT 0,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (dataset_id, column_name)
+);
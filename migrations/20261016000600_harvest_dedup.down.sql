@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS integration_harvest_matches;
+
+ALTER TABLE IF EXISTS integration_runs DROP COLUMN IF EXISTS records_queued;
+
+DROP INDEX IF EXISTS idx_integration_harvest_records_external_id;
+ALTER TABLE IF EXISTS integration_harvest_records DROP COLUMN IF EXISTS policy;
+ALTER TABLE IF EXISTS integration_harvest_records DROP COLUMN IF EXISTS external_id;
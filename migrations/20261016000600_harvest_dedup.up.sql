@@ -0,0 +1,39 @@
+-- Harvest records keep the external ID shared across sources and the
+-- conflict policy later runs write them with. The integration tables predate
+-- tracked migrations, so they are altered only where they exist.
+DO $$
+BEGIN
+    IF to_regclass('integration_harvest_records') IS NOT NULL THEN
+        ALTER TABLE integration_harvest_records ADD COLUMN IF NOT EXISTS external_id TEXT;
+        ALTER TABLE integration_harvest_records ADD COLUMN IF NOT EXISTS policy TEXT NOT NULL DEFAULT 'create';
+        CREATE INDEX IF NOT EXISTS idx_integration_harvest_records_external_id
+            ON integration_harvest_records (external_id) WHERE external_id IS NOT NULL;
+    END IF;
+    IF to_regclass('integration_runs') IS NOT NULL THEN
+        ALTER TABLE integration_runs ADD COLUMN IF NOT EXISTS records_queued INT NOT NULL DEFAULT 0;
+    END IF;
+END $$;
+
+-- Harvested records matching existing datasets, waiting for review. A remote
+-- record has at most one pending match.
+CREATE TABLE IF NOT EXISTS integration_harvest_matches (
+    id             UUID PRIMARY KEY,
+    integration_id UUID NOT NULL,
+    remote_id      TEXT NOT NULL,
+    external_id    TEXT,
+    dataset_id     UUID NOT NULL REFERENCES datasets (id) ON DELETE CASCADE,
+    reason         TEXT NOT NULL CHECK (reason IN ('external_id', 'title')),
+    score          DOUBLE PRECISION NOT NULL,
+    record         JSONB NOT NULL,
+    status         TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'resolved')),
+    resolution     TEXT,
+    resolved_by    UUID,
+    resolved_at    TIMESTAMPTZ,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_integration_harvest_matches_pending
+    ON integration_harvest_matches (integration_id, remote_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_integration_harvest_matches_integration_id
+    ON integration_harvest_matches (integration_id, created_at DESC);
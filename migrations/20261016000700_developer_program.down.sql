@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS developer_api_usage;
+DROP TABLE IF EXISTS developer_api_keys;
+DROP TABLE IF EXISTS developer_applications;
@@ -0,0 +1,38 @@
+-- Applications external developers register to call the API with API keys,
+-- on a rate plan
+CREATE TABLE IF NOT EXISTS developer_applications (
+    id          UUID PRIMARY KEY,
+    user_id     UUID NOT NULL,
+    name        TEXT NOT NULL,
+    description TEXT,
+    website     TEXT,
+    plan        TEXT NOT NULL DEFAULT 'free' CHECK (plan IN ('free', 'partner')),
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_developer_applications_user_id ON developer_applications (user_id);
+
+-- API keys of applications; only the SHA-256 of a key is stored
+CREATE TABLE IF NOT EXISTS developer_api_keys (
+    id             UUID PRIMARY KEY,
+    application_id UUID NOT NULL REFERENCES developer_applications (id) ON DELETE CASCADE,
+    name           TEXT NOT NULL,
+    key_hash       TEXT NOT NULL UNIQUE,
+    prefix         TEXT NOT NULL,
+    last_used_at   TIMESTAMPTZ,
+    expires_at     TIMESTAMPTZ,
+    revoked_at     TIMESTAMPTZ,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_developer_api_keys_application_id ON developer_api_keys (application_id);
+
+-- Requests sent with each key per day, and those refused over the rate limit
+CREATE TABLE IF NOT EXISTS developer_api_usage (
+    key_id    UUID NOT NULL REFERENCES developer_api_keys (id) ON DELETE CASCADE,
+    day       DATE NOT NULL,
+    requests  BIGINT NOT NULL DEFAULT 0,
+    throttled BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (key_id, day)
+);
@@ -0,0 +1,10 @@
+-- Preview images generated for datasets without an image of their own. A
+-- dataset whose image is still its preview gets a new one as it changes.
+CREATE TABLE IF NOT EXISTS dataset_previews (
+    dataset_id   UUID PRIMARY KEY REFERENCES datasets (id) ON DELETE CASCADE,
+    file_id      UUID NOT NULL,
+    image        TEXT NOT NULL,
+    kind         TEXT NOT NULL CHECK (kind IN ('chart', 'card')),
+    checksum     TEXT NOT NULL,
+    generated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS moderation_items;
@@ -0,0 +1,21 @@
+-- Public text held for a moderator by the screening heuristics, and the
+-- decisions moderators took on it
+CREATE TABLE IF NOT EXISTS moderation_items (
+    id           UUID PRIMARY KEY,
+    content_type TEXT NOT NULL,
+    content_id   UUID NOT NULL,
+    text         TEXT NOT NULL,
+    author_id    UUID,
+    author_email TEXT,
+    reasons      JSONB NOT NULL DEFAULT '[]',
+    status       TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    note         TEXT,
+    decided_by   UUID,
+    decided_at   TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_moderation_items_pending ON moderation_items (created_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_moderation_items_content ON moderation_items (content_type, content_id);
+CREATE INDEX IF NOT EXISTS idx_moderation_items_decided_by ON moderation_items (decided_by) WHERE decided_by IS NOT NULL;
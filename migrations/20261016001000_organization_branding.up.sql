@@ -0,0 +1,3 @@
+-- Colors, banner and intro text giving the pages of each organization their
+-- own look, as a JSON encoded branding
+ALTER TABLE organizations ADD COLUMN IF NOT EXISTS branding JSONB NOT NULL DEFAULT '{}';
@@ -0,0 +1,13 @@
+-- The JWTs cannot be recovered from their digests: the tokens stored since
+-- are revoked, so refreshing them fails and their users sign in again
+DROP INDEX IF EXISTS idx_tokens_refresh_token_hash;
+DROP INDEX IF EXISTS idx_tokens_access_token_hash;
+
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS access_token TEXT;
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS refresh_token TEXT;
+UPDATE tokens SET access_token = access_token_hash, refresh_token = refresh_token_hash, revoked = true;
+ALTER TABLE tokens ALTER COLUMN access_token SET NOT NULL;
+ALTER TABLE tokens ALTER COLUMN refresh_token SET NOT NULL;
+
+ALTER TABLE tokens DROP COLUMN IF EXISTS refresh_token_hash;
+ALTER TABLE tokens DROP COLUMN IF EXISTS access_token_hash;
@@ -0,0 +1,18 @@
+-- Tokens are kept as SHA-256 digests of the issued JWTs and looked up by
+-- them. Existing rows are hashed in place, so signed in users stay signed in,
+-- and the JWTs themselves are dropped.
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS access_token_hash TEXT;
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS refresh_token_hash TEXT;
+
+UPDATE tokens
+SET access_token_hash = encode(sha256(convert_to(access_token, 'UTF8')), 'hex'),
+    refresh_token_hash = encode(sha256(convert_to(refresh_token, 'UTF8')), 'hex')
+WHERE access_token_hash IS NULL;
+
+ALTER TABLE tokens ALTER COLUMN access_token_hash SET NOT NULL;
+ALTER TABLE tokens ALTER COLUMN refresh_token_hash SET NOT NULL;
+ALTER TABLE tokens DROP COLUMN IF EXISTS access_token;
+ALTER TABLE tokens DROP COLUMN IF EXISTS refresh_token;
+
+CREATE INDEX IF NOT EXISTS idx_tokens_access_token_hash ON tokens (access_token_hash);
+CREATE INDEX IF NOT EXISTS idx_tokens_refresh_token_hash ON tokens (refresh_token_hash);
@@ -0,0 +1,19 @@
+-- The datasets highlighted on the homepage, in the order curators arranged
+-- them. datasets.is_highlight is kept in step; highlights are removed once
+-- expires_at passes.
+CREATE TABLE IF NOT EXISTS dataset_highlights (
+    dataset_id  UUID PRIMARY KEY REFERENCES datasets (id) ON DELETE CASCADE,
+    position    INT NOT NULL,
+    expires_at  TIMESTAMPTZ,
+    created_by  UUID,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_dataset_highlights_position ON dataset_highlights (position);
+CREATE INDEX IF NOT EXISTS idx_dataset_highlights_expires_at ON dataset_highlights (expires_at) WHERE expires_at IS NOT NULL;
+
+-- Datasets highlighted before keep their place, most recently updated first
+INSERT INTO dataset_highlights (dataset_id, position, created_at)
+SELECT id, ROW_NUMBER() OVER (ORDER BY updated_at DESC, id), NOW()
+FROM datasets
+WHERE is_highlight
+ON CONFLICT (dataset_id) DO NOTHING;
@@ -0,0 +1,12 @@
+-- Single use tokens of the links mailed to registered users to verify their
+-- email, kept as SHA-256 digests. Users awaiting verification have the
+-- pending status.
+CREATE TABLE IF NOT EXISTS email_verification_tokens (
+    id          UUID PRIMARY KEY,
+    user_id     UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    token_hash  TEXT NOT NULL UNIQUE,
+    expires_at  TIMESTAMPTZ NOT NULL,
+    used_at     TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_email_verification_tokens_user_id ON email_verification_tokens (user_id);
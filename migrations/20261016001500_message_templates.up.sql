@@ -0,0 +1,14 @@
+-- Wording of the mail and notifications modules send, per locale, replacing
+-- the built-in wording of the message
+CREATE TABLE IF NOT EXISTS message_templates (
+    id          UUID PRIMARY KEY,
+    key         TEXT NOT NULL,
+    channel     TEXT NOT NULL CHECK (channel IN ('email', 'notification')),
+    locale      TEXT NOT NULL,
+    subject     TEXT NOT NULL,
+    body        TEXT NOT NULL,
+    updated_by  UUID,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (key, channel, locale)
+);
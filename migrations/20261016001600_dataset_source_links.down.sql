@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_datasets_link_checked_at;
+ALTER TABLE datasets DROP COLUMN IF EXISTS link_error;
+ALTER TABLE datasets DROP COLUMN IF EXISTS link_checked_at;
+ALTER TABLE datasets DROP COLUMN IF EXISTS link_status;
+ALTER TABLE datasets DROP COLUMN IF EXISTS source_url;
@@ -0,0 +1,8 @@
+-- The external source each dataset references and the result of the last
+-- check of that link. A NULL link_status means the link was not checked since
+-- it was set.
+ALTER TABLE datasets ADD COLUMN IF NOT EXISTS source_url TEXT;
+ALTER TABLE datasets ADD COLUMN IF NOT EXISTS link_status TEXT CHECK (link_status IN ('ok', 'broken'));
+ALTER TABLE datasets ADD COLUMN IF NOT EXISTS link_checked_at TIMESTAMPTZ;
+ALTER TABLE datasets ADD COLUMN IF NOT EXISTS link_error TEXT;
+CREATE INDEX IF NOT EXISTS idx_datasets_link_checked_at ON datasets (link_checked_at NULLS FIRST) WHERE source_url IS NOT NULL;
@@ -0,0 +1,25 @@
+-- Accounts of users at external OpenID Connect providers, which know them by
+-- subject
+CREATE TABLE IF NOT EXISTS user_identities (
+    id          UUID PRIMARY KEY,
+    user_id     UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    provider    TEXT NOT NULL,
+    subject     TEXT NOT NULL,
+    email       TEXT NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (provider, subject)
+);
+CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities (user_id);
+
+-- Sign ins started with an external provider, waiting for the user to come
+-- back with their state, kept as a SHA-256 digest
+CREATE TABLE IF NOT EXISTS oidc_logins (
+    id             UUID PRIMARY KEY,
+    provider       TEXT NOT NULL,
+    state_hash     TEXT NOT NULL UNIQUE,
+    nonce          TEXT NOT NULL,
+    code_verifier  TEXT NOT NULL,
+    expires_at     TIMESTAMPTZ NOT NULL,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_oidc_logins_expires_at ON oidc_logins (expires_at);
@@ -0,0 +1,39 @@
+-- Roles users are assigned through users.role_id, with the permissions they
+-- grant
+CREATE TABLE IF NOT EXISTS roles (
+    id           UUID PRIMARY KEY,
+    name         TEXT NOT NULL UNIQUE,
+    description  TEXT NOT NULL DEFAULT '',
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id     UUID NOT NULL REFERENCES roles (id) ON DELETE CASCADE,
+    permission  TEXT NOT NULL,
+    PRIMARY KEY (role_id, permission)
+);
+
+-- Every signed-in user could write before permissions were checked, so the
+-- roles users already have keep every permission until they are narrowed
+INSERT INTO roles (id, name, description)
+SELECT DISTINCT role_id, 'role-' || role_id::text, 'Created for the roles assigned before permissions'
+FROM users
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT r.id, p.permission
+FROM roles r
+CROSS JOIN (VALUES
+    ('datasets:write'),
+    ('data_rows:write'),
+    ('files:write'),
+    ('organizations:write'),
+    ('visualizations:write'),
+    ('publications:write'),
+    ('taxonomies:write'),
+    ('integrations:write'),
+    ('users:write'),
+    ('roles:write')
+) AS p (permission)
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,21 @@
+-- Datasets proposed by an organization to another one, which takes them over
+-- once it accepts
+CREATE TABLE IF NOT EXISTS dataset_submissions (
+    id                      UUID PRIMARY KEY,
+    dataset_id              UUID NOT NULL REFERENCES datasets (id) ON DELETE CASCADE,
+    source_organization_id  UUID NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    target_organization_id  UUID NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    status                  TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'rejected')),
+    message                 TEXT,
+    review_note             TEXT,
+    submitted_by            UUID NOT NULL,
+    reviewed_by             UUID,
+    reviewed_at             TIMESTAMPTZ,
+    created_at              TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at              TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- A dataset is proposed to one organization at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_dataset_submissions_pending ON dataset_submissions (dataset_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_dataset_submissions_target ON dataset_submissions (target_organization_id, status, created_at);
+CREATE INDEX IF NOT EXISTS idx_dataset_submissions_source ON dataset_submissions (source_organization_id, status, created_at);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS deprecation_usage;
@@ -0,0 +1,12 @@
+-- Requests made to deprecated routes and parameters per client and day, for
+-- the deprecation report
+CREATE TABLE IF NOT EXISTS deprecation_usage (
+    notice_id     TEXT NOT NULL,
+    client        TEXT NOT NULL,
+    day           DATE NOT NULL,
+    requests      BIGINT NOT NULL DEFAULT 0,
+    last_used_at  TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (notice_id, client, day)
+);
+
+CREATE INDEX IF NOT EXISTS idx_deprecation_usage_day ON deprecation_usage (day);
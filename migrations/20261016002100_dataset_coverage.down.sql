@@ -0,0 +1,28 @@
+-- Coverages of whole years turn back into periods like "2020-2023", others
+-- into dates like "2020-01-01/2023-06-30", with ".." for an open end
+ALTER TABLE datasets ADD COLUMN IF NOT EXISTS period TEXT;
+
+UPDATE datasets
+SET period = CASE
+    WHEN to_char(coverage_start, 'MM-DD') = '01-01' AND to_char(coverage_end, 'MM-DD') = '12-31' THEN
+        CASE WHEN date_part('year', coverage_start) = date_part('year', coverage_end)
+            THEN to_char(coverage_start, 'YYYY')
+            ELSE to_char(coverage_start, 'YYYY') || '-' || to_char(coverage_end, 'YYYY')
+        END
+    ELSE COALESCE(to_char(coverage_start, 'YYYY-MM-DD'), '..') || '/' || COALESCE(to_char(coverage_end, 'YYYY-MM-DD'), '..')
+END
+WHERE coverage_start IS NOT NULL OR coverage_end IS NOT NULL;
+
+-- Datasets with a frequency but no coverage keep the frequency as period
+UPDATE datasets
+SET period = CASE frequency
+    WHEN 'daily' THEN 'harian' WHEN 'weekly' THEN 'mingguan' WHEN 'monthly' THEN 'bulanan'
+    WHEN 'quarterly' THEN 'triwulanan' WHEN 'semiannual' THEN 'semesteran'
+    WHEN 'annual' THEN 'tahunan' WHEN 'irregular' THEN 'tidak tetap'
+END
+WHERE period IS NULL AND frequency IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_datasets_coverage;
+ALTER TABLE datasets DROP COLUMN IF EXISTS frequency;
+ALTER TABLE datasets DROP COLUMN IF EXISTS coverage_end;
+ALTER TABLE datasets DROP COLUMN IF EXISTS coverage_start;
@@ -0,0 +1,53 @@
+-- The time span the data of a dataset covers and how often it is collected
+-- replace the free text period. Periods naming years or dates, like "2020",
+-- "Tahun 2021", "2020 - 2023", "2020 s.d. 2023" or
+-- "2020-01-01/2023-06-30", are read into coverage dates, and those naming a
+-- frequency, like "tahunan", into the frequency; others are dropped.
+ALTER TABLE datasets ADD COLUMN IF NOT EXISTS coverage_start DATE;
+ALTER TABLE datasets ADD COLUMN IF NOT EXISTS coverage_end DATE;
+ALTER TABLE datasets ADD COLUMN IF NOT EXISTS frequency TEXT
+    CHECK (frequency IN ('daily', 'weekly', 'monthly', 'quarterly', 'semiannual', 'annual', 'irregular'));
+
+-- to_date fails on dates like 2021-02-30, which leave the coverage empty
+CREATE FUNCTION pg_temp.period_date(value TEXT) RETURNS DATE AS $$
+BEGIN
+    RETURN to_date(value, 'YYYY-MM-DD');
+EXCEPTION WHEN OTHERS THEN
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+UPDATE datasets d
+SET coverage_start = make_date(p.m[1]::INT, 1, 1),
+    coverage_end = make_date(COALESCE(p.m[2], p.m[1])::INT, 12, 31)
+FROM (
+    SELECT id, regexp_match(btrim(period), '^(?:tahun\s+)?(\d{4})(?:\s*(?:-|–|—|/|s\.?\s?d\.?|sampai|to)\s*(\d{4}))?$', 'i') AS m
+    FROM datasets
+) p
+WHERE p.id = d.id AND p.m IS NOT NULL AND COALESCE(p.m[2], p.m[1]) >= p.m[1];
+
+UPDATE datasets d
+SET coverage_start = pg_temp.period_date(p.m[1]),
+    coverage_end = pg_temp.period_date(COALESCE(p.m[2], p.m[1]))
+FROM (
+    SELECT id, regexp_match(btrim(period), '^(\d{4}-\d{2}-\d{2})(?:\s*(?:-|–|—|/|s\.?\s?d\.?|sampai|to)\s*(\d{4}-\d{2}-\d{2}))?$', 'i') AS m
+    FROM datasets
+) p
+WHERE p.id = d.id AND p.m IS NOT NULL
+    AND pg_temp.period_date(p.m[1]) <= pg_temp.period_date(COALESCE(p.m[2], p.m[1]));
+
+UPDATE datasets
+SET frequency = CASE lower(btrim(period))
+    WHEN 'harian' THEN 'daily' WHEN 'daily' THEN 'daily'
+    WHEN 'mingguan' THEN 'weekly' WHEN 'weekly' THEN 'weekly'
+    WHEN 'bulanan' THEN 'monthly' WHEN 'monthly' THEN 'monthly'
+    WHEN 'triwulanan' THEN 'quarterly' WHEN 'quarterly' THEN 'quarterly'
+    WHEN 'semesteran' THEN 'semiannual' WHEN 'semiannual' THEN 'semiannual'
+    WHEN 'tahunan' THEN 'annual' WHEN 'annual' THEN 'annual' WHEN 'yearly' THEN 'annual'
+    WHEN 'tidak tetap' THEN 'irregular' WHEN 'irregular' THEN 'irregular'
+END
+WHERE frequency IS NULL AND period IS NOT NULL;
+
+ALTER TABLE datasets DROP COLUMN IF EXISTS period;
+
+CREATE INDEX IF NOT EXISTS idx_datasets_coverage ON datasets (coverage_start, coverage_end) WHERE coverage_start IS NOT NULL;
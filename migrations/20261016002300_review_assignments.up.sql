@@ -0,0 +1,12 @@
+-- Reviewers assigned to the datasets, visualizations and publications
+-- waiting for review. Items are identified by content type and ID; the
+-- assignment of an item approved from the review dashboard is dropped.
+CREATE TABLE IF NOT EXISTS review_assignments (
+    content_type TEXT NOT NULL CHECK (content_type IN ('dataset', 'visualization', 'publication')),
+    content_id   UUID NOT NULL,
+    assigned_to  UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    assigned_by  UUID REFERENCES users (id) ON DELETE SET NULL,
+    assigned_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (content_type, content_id)
+);
+CREATE INDEX IF NOT EXISTS idx_review_assignments_assigned_to ON review_assignments (assigned_to);
@@ -0,0 +1,4 @@
+-- Admin impersonating the actor of an entry, empty unless the request was
+-- impersonated
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS impersonator_id TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_audit_logs_impersonator ON audit_logs (impersonator_id, created_at DESC) WHERE impersonator_id <> '';
@@ -0,0 +1,19 @@
+-- Monthly open data reports of the KPIs of the portal. A report is pending
+-- until its file is generated and stored at path, or generation failed;
+-- kpis keeps what it reported, so the downloads of the next month are
+-- counted from its total.
+CREATE TABLE IF NOT EXISTS reports (
+    id           UUID PRIMARY KEY,
+    period       DATE NOT NULL,
+    format       TEXT NOT NULL CHECK (format IN ('pdf', 'xlsx')),
+    status       TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'ready', 'failed')),
+    path         TEXT,
+    size         BIGINT,
+    kpis         JSONB NOT NULL DEFAULT '{}',
+    error        TEXT,
+    requested_by UUID REFERENCES users (id) ON DELETE SET NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_reports_period ON reports (period DESC, format);
+CREATE INDEX IF NOT EXISTS idx_reports_pending ON reports (created_at) WHERE status = 'pending';
@@ -0,0 +1,17 @@
+-- Security audit log of sign ins, sign outs, token refreshes and password
+-- changes, with the IP address and user agent of the client. user_id is
+-- empty for failed sign ins with an unknown email.
+CREATE TABLE IF NOT EXISTS auth_audit (
+    id         UUID PRIMARY KEY,
+    event      TEXT NOT NULL,
+    user_id    TEXT NOT NULL DEFAULT '',
+    email      TEXT NOT NULL DEFAULT '',
+    ip         TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    detail     TEXT NOT NULL DEFAULT '',
+    request_id TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_auth_audit_created_at ON auth_audit (created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_auth_audit_user ON auth_audit (user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_auth_audit_event ON auth_audit (event, created_at DESC);
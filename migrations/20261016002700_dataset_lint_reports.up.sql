@@ -0,0 +1,10 @@
+-- The metadata checks each dataset failed when it was last saved, for the
+-- quality dashboard. warnings is a JSON array of {rule, field, message}.
+CREATE TABLE IF NOT EXISTS dataset_lint_reports (
+    dataset_id     UUID PRIMARY KEY REFERENCES datasets (id) ON DELETE CASCADE,
+    warnings       JSONB NOT NULL DEFAULT '[]',
+    warning_count  INT NOT NULL DEFAULT 0,
+    checked_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_dataset_lint_reports_warning_count ON dataset_lint_reports (warning_count DESC, checked_at DESC);
+CREATE INDEX IF NOT EXISTS idx_dataset_lint_reports_warnings ON dataset_lint_reports USING GIN (warnings jsonb_path_ops);
@@ -0,0 +1,14 @@
+-- Grants of roles on datasets to users or organizations other than their
+-- owners. grantee_id is a user or organization ID as grantee_type says.
+CREATE TABLE IF NOT EXISTS dataset_collaborators (
+    id            UUID PRIMARY KEY,
+    dataset_id    UUID NOT NULL REFERENCES datasets (id) ON DELETE CASCADE,
+    grantee_type  VARCHAR(20) NOT NULL CHECK (grantee_type IN ('user', 'organization')),
+    grantee_id    UUID NOT NULL,
+    role          VARCHAR(20) NOT NULL CHECK (role IN ('view', 'edit', 'publish')),
+    granted_by    UUID REFERENCES users (id) ON DELETE SET NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (dataset_id, grantee_type, grantee_id)
+);
+CREATE INDEX IF NOT EXISTS idx_dataset_collaborators_grantee ON dataset_collaborators (grantee_type, grantee_id);
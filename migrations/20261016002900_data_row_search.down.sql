@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_data_rows_search_vector;
+ALTER TABLE data_rows DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,8 @@
+-- Full-text index over the string and number values of data rows, behind
+-- the ranked row search of the dataset explorer. The 'simple' configuration
+-- matches words as written, without stemming in any language.
+ALTER TABLE data_rows
+    ADD COLUMN IF NOT EXISTS search_vector TSVECTOR
+    GENERATED ALWAYS AS (jsonb_to_tsvector('simple', data, '["string", "numeric"]')) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_data_rows_search_vector ON data_rows USING GIN (search_vector);
@@ -0,0 +1,12 @@
+-- Daily counters of the views and downloads of records, by the channel they
+-- came from: the portal, developer applications or harvesters. Like the
+-- other usage counters they keep nothing about visitors.
+CREATE TABLE IF NOT EXISTS traffic_counters (
+    day          DATE NOT NULL,
+    channel      VARCHAR(20) NOT NULL CHECK (channel IN ('portal', 'api', 'harvester')),
+    entity_type  VARCHAR(50) NOT NULL,
+    entity_id    UUID NOT NULL,
+    metric       VARCHAR(20) NOT NULL CHECK (metric IN ('view', 'download')),
+    count        BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (day, channel, entity_type, entity_id, metric)
+);
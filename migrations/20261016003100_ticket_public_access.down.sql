@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS ticket_replies;
+
+-- Tickets filed through the contact form have no user, so user_id and
+-- created_by stay nullable
+DROP INDEX IF EXISTS idx_tickets_access_token_hash;
+ALTER TABLE tickets DROP COLUMN IF EXISTS access_token_hash;
+ALTER TABLE tickets DROP COLUMN IF EXISTS requester_email;
@@ -0,0 +1,22 @@
+-- Tickets filed through the contact form by requesters who are not signed
+-- in. They have no user; the requester follows them through a secret link
+-- whose token is only kept as a SHA-256 hash.
+ALTER TABLE tickets ALTER COLUMN user_id DROP NOT NULL;
+ALTER TABLE tickets ALTER COLUMN created_by DROP NOT NULL;
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS requester_email TEXT;
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS access_token_hash TEXT;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tickets_access_token_hash ON tickets (access_token_hash) WHERE access_token_hash IS NOT NULL;
+
+-- Replies to tickets, by staff or by the requester. Only public replies are
+-- shown to the requester, except those moderation rejected.
+CREATE TABLE IF NOT EXISTS ticket_replies (
+    id                UUID PRIMARY KEY,
+    ticket_id         UUID NOT NULL REFERENCES tickets (id) ON DELETE CASCADE,
+    user_id           UUID,
+    author_email      TEXT,
+    message           TEXT NOT NULL,
+    is_public         BOOLEAN NOT NULL DEFAULT FALSE,
+    moderation_status VARCHAR(20) NOT NULL DEFAULT 'approved' CHECK (moderation_status IN ('pending', 'approved', 'rejected')),
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_ticket_replies_ticket ON ticket_replies (ticket_id, created_at);
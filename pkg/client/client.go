@@ -0,0 +1,25 @@
+// Package client carries who a request came from, its IP address and user
+// agent, to the usecases recording it.
+package client
+
+import "context"
+
+// Info is the IP address and user agent of the client of a request. Either
+// is empty when unknown.
+type Info struct {
+	IP        string
+	UserAgent string
+}
+
+type contextKey struct{}
+
+// WithInfo returns a context carrying the client info of its request
+func WithInfo(ctx context.Context, info Info) context.Context {
+	return context.WithValue(ctx, contextKey{}, info)
+}
+
+// FromContext returns the client info ctx carries, empty without one
+func FromContext(ctx context.Context) Info {
+	info, _ := ctx.Value(contextKey{}).(Info)
+	return info
+}
@@ -16,6 +16,7 @@ var (
 	ErrInternal       = errors.New("internal server error")
 	ErrDatabase       = errors.New("database error")
 	ErrValidation     = errors.New("validation error")
+	ErrInUse          = errors.New("resource is in use")
 
 	// Auth specific errors
 	ErrInvalidCredentials = errors.New("invalid credentials")
@@ -24,6 +25,7 @@ var (
 	ErrTokenRevoked       = errors.New("token revoked")
 	ErrUserNotFound       = errors.New("user not found")
 	ErrUserDisabled       = errors.New("user is disabled")
+	ErrEmailNotVerified   = errors.New("email is not verified")
 	ErrEmailTaken         = errors.New("email already taken")
 	ErrUsernameTaken      = errors.New("username already taken")
 
@@ -0,0 +1,139 @@
+// Package i18n resolves localized catalog text for the languages a client
+// accepts. Catalog entities keep their text in the default locale in their
+// regular columns and the other languages in JSON objects keyed by language.
+package i18n
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type contextKey struct{}
+
+// WithLanguages returns a context carrying the languages a client prefers,
+// most preferred first
+func WithLanguages(ctx context.Context, langs []string) context.Context {
+	return context.WithValue(ctx, contextKey{}, langs)
+}
+
+// Languages returns the languages stored by WithLanguages
+func Languages(ctx context.Context) []string {
+	langs, _ := ctx.Value(contextKey{}).([]string)
+	return langs
+}
+
+// Negotiate returns the languages a request prefers, from the lang query
+// parameter or else the Accept-Language header. Text in defaultLocale is the
+// base text, so languages after it are dropped.
+func Negotiate(query, acceptLanguage, defaultLocale string) []string {
+	var langs []string
+	if query != "" {
+		langs = expand(strings.ToLower(strings.TrimSpace(query)))
+	} else {
+		langs = ParseAcceptLanguage(acceptLanguage)
+	}
+
+	defaultLocale = strings.ToLower(defaultLocale)
+	for i, lang := range langs {
+		if lang == defaultLocale {
+			return langs[:i]
+		}
+	}
+	return langs
+}
+
+// ParseAcceptLanguage parses an Accept-Language header into lower case
+// language tags by quality. A regional tag such as en-US is followed by its
+// language, en, unless the header lists that language itself.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag     string
+		quality float64
+	}
+
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if tag == "" || tag == "*" {
+			continue
+		}
+		quality := 1.0
+		for _, param := range fields[1:] {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if q, err := strconv.ParseFloat(value, 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		if quality > 0 {
+			tags = append(tags, weighted{tag: tag, quality: quality})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].quality > tags[j].quality })
+
+	listed := map[string]bool{}
+	for _, tag := range tags {
+		listed[tag.tag] = true
+	}
+
+	var langs []string
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		for i, lang := range expand(tag.tag) {
+			if !seen[lang] && (i == 0 || !listed[lang]) {
+				seen[lang] = true
+				langs = append(langs, lang)
+			}
+		}
+	}
+	return langs
+}
+
+// expand returns a language tag followed by its base language
+func expand(tag string) []string {
+	if base, _, found := strings.Cut(tag, "-"); found && base != "" {
+		return []string{tag, base}
+	}
+	return []string{tag}
+}
+
+// Pick returns the text of the first language in langs that texts has, or
+// fallback when it has none of them
+func Pick(texts map[string]string, langs []string, fallback string) string {
+	for _, lang := range langs {
+		if text := texts[lang]; text != "" {
+			return text
+		}
+	}
+	return fallback
+}
+
+// Decode parses texts stored as a JSON object. Empty or invalid JSON gives no texts.
+func Decode(raw string) map[string]string {
+	texts := map[string]string{}
+	if raw != "" {
+		_ = json.Unmarshal([]byte(raw), &texts)
+	}
+	return texts
+}
+
+// Encode stores texts as a JSON object keyed by lower case language, leaving
+// out empty texts
+func Encode(texts map[string]string) (string, error) {
+	normalized := make(map[string]string, len(texts))
+	for lang, text := range texts {
+		if text = strings.TrimSpace(text); text != "" {
+			normalized[strings.ToLower(strings.TrimSpace(lang))] = text
+		}
+	}
+	encoded, err := json.Marshal(normalized)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode localized text: %w", err)
+	}
+	return string(encoded), nil
+}
@@ -0,0 +1,46 @@
+package i18n_test
+
+import (
+	"reflect"
+	"testing"
+
+	"portal-data-backend/pkg/i18n"
+)
+
+// Test Accept-Language tags are ordered by quality and regional tags fall back to their language
+func TestParseAcceptLanguage(t *testing.T) {
+	langs := i18n.ParseAcceptLanguage("en-US,en;q=0.7, id;q=0.9, fr;q=0, *;q=0.1")
+
+	expected := []string{"en-us", "id", "en"}
+	if !reflect.DeepEqual(langs, expected) {
+		t.Errorf("Expected %v, got %v", expected, langs)
+	}
+}
+
+// Test negotiation stops at the default locale and the lang parameter overrides the header
+func TestNegotiate(t *testing.T) {
+	if langs := i18n.Negotiate("", "en;q=0.5, id", "id"); len(langs) != 0 {
+		t.Errorf("Expected no languages before the default locale, got %v", langs)
+	}
+	if langs := i18n.Negotiate("", "en-GB, id;q=0.8", "id"); !reflect.DeepEqual(langs, []string{"en-gb", "en"}) {
+		t.Errorf("Expected en-gb and en, got %v", langs)
+	}
+	if langs := i18n.Negotiate("EN", "id", "id"); !reflect.DeepEqual(langs, []string{"en"}) {
+		t.Errorf("Expected the lang parameter to win, got %v", langs)
+	}
+}
+
+// Test Pick falls back to the base text when no language matches
+func TestPick(t *testing.T) {
+	texts := i18n.Decode(`{"en": "Health", "jv": ""}`)
+
+	if text := i18n.Pick(texts, []string{"fr", "en"}, "Kesehatan"); text != "Health" {
+		t.Errorf("Expected Health, got %s", text)
+	}
+	if text := i18n.Pick(texts, []string{"jv"}, "Kesehatan"); text != "Kesehatan" {
+		t.Errorf("Expected the fallback for an empty text, got %s", text)
+	}
+	if text := i18n.Pick(i18n.Decode("not json"), []string{"en"}, "Kesehatan"); text != "Kesehatan" {
+		t.Errorf("Expected the fallback for invalid JSON, got %s", text)
+	}
+}
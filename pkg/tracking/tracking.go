@@ -0,0 +1,73 @@
+// Package tracking carries whether the client of a request opted out of
+// usage analytics, through the Do Not Track or Global Privacy Control
+// headers.
+package tracking
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+type contextKey struct{}
+
+// WithOptOut returns a context recording that its client opted out of usage
+// analytics
+func WithOptOut(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextKey{}, true)
+}
+
+// OptedOut reports whether the client of ctx opted out of usage analytics
+func OptedOut(ctx context.Context) bool {
+	optedOut, _ := ctx.Value(contextKey{}).(bool)
+	return optedOut
+}
+
+// HeaderOptOut reports whether headers ask not to be tracked, with DNT: 1 or
+// Sec-GPC: 1
+func HeaderOptOut(header http.Header) bool {
+	return strings.TrimSpace(header.Get("DNT")) == "1" || strings.TrimSpace(header.Get("Sec-GPC")) == "1"
+}
+
+// Channel is where the requests counted in usage analytics come from
+type Channel string
+
+const (
+	// ChannelPortal is the portal UI and the other clients of people
+	ChannelPortal Channel = "portal"
+	// ChannelAPI is the developer applications calling with an API key
+	ChannelAPI Channel = "api"
+	// ChannelHarvester is the catalogs and crawlers harvesting the portal
+	ChannelHarvester Channel = "harvester"
+)
+
+// Channels lists the channels usage is counted in
+var Channels = []Channel{ChannelPortal, ChannelAPI, ChannelHarvester}
+
+type channelKey struct{}
+
+// WithChannel returns a context recording the channel its request came from
+func WithChannel(ctx context.Context, channel Channel) context.Context {
+	return context.WithValue(ctx, channelKey{}, channel)
+}
+
+// ChannelOf returns the channel the request of ctx came from, the portal
+// unless told otherwise
+func ChannelOf(ctx context.Context) Channel {
+	if channel, ok := ctx.Value(channelKey{}).(Channel); ok {
+		return channel
+	}
+	return ChannelPortal
+}
+
+// HarvesterAgent reports whether userAgent contains one of agents, like
+// "ckan" or "bot", ignoring case
+func HarvesterAgent(userAgent string, agents []string) bool {
+	userAgent = strings.ToLower(userAgent)
+	for _, agent := range agents {
+		if agent != "" && strings.Contains(userAgent, strings.ToLower(agent)) {
+			return true
+		}
+	}
+	return false
+}
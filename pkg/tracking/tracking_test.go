@@ -0,0 +1,61 @@
+package tracking
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+// Test DNT and Sec-GPC opt out only when set to 1
+func TestHeaderOptOut(t *testing.T) {
+	tests := []struct {
+		name   string
+		header http.Header
+		want   bool
+	}{
+		{"no header", http.Header{}, false},
+		{"do not track", http.Header{"Dnt": {"1"}}, true},
+		{"tracking allowed", http.Header{"Dnt": {"0"}}, false},
+		{"global privacy control", http.Header{"Sec-Gpc": {"1"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HeaderOptOut(tt.header); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+// Test the opt-out is carried by the context
+func TestOptedOut(t *testing.T) {
+	ctx := context.Background()
+	if OptedOut(ctx) {
+		t.Errorf("Expected no opt-out by default")
+	}
+	if !OptedOut(WithOptOut(ctx)) {
+		t.Errorf("Expected the opt-out to be carried")
+	}
+}
+
+// Test harvesters are told by their user agent, whatever its case
+func TestHarvesterAgent(t *testing.T) {
+	agents := []string{"ckan", "Bot"}
+	tests := []struct {
+		userAgent string
+		want      bool
+	}{
+		{"ckanext-harvest/1.5 (+https://ckan.org)", true},
+		{"Mozilla/5.0 (compatible; Googlebot/2.1)", true},
+		{"Mozilla/5.0 (X11; Linux x86_64) Firefox/131.0", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := HarvesterAgent(tt.userAgent, agents); got != tt.want {
+			t.Errorf("Expected %v for %q, got %v", tt.want, tt.userAgent, got)
+		}
+	}
+	if ChannelOf(context.Background()) != ChannelPortal || ChannelOf(WithChannel(context.Background(), ChannelAPI)) != ChannelAPI {
+		t.Errorf("Expected the portal channel by default and the one carried otherwise")
+	}
+}